@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+// annotate flag values
+var annotateMessage string
+
+const (
+	annotateCommand = "annotate"
+	messageFN       = "message"
+)
+
+const annotateCommandExamples = `# Attach a note to backup 1234abcd-12ab-cd34-56de-1234abcd
+corso backup annotate 1234abcd-12ab-cd34-56de-1234abcd -m "pre-migration snapshot"
+
+# Clear the notes on backup 1234abcd-12ab-cd34-56de-1234abcd
+corso backup annotate 1234abcd-12ab-cd34-56de-1234abcd -m ""`
+
+// The backup annotate subcommand.
+// `corso backup annotate <backupId> --message <notes>`
+func annotateCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   annotateCommand + " <backupId>",
+		Short: "Attach notes to a backup",
+		Long: `Attach freeform notes to a backup, replacing any existing notes.  Notes
+are shown when listing backups and viewing backup details.`,
+		RunE:    handleAnnotateCmd,
+		Args:    cobra.ExactArgs(1),
+		Example: annotateCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVarP(
+		&annotateMessage,
+		messageFN, "m", "",
+		"Notes to attach to the backup; an empty message clears the notes. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(messageFN))
+
+	return c
+}
+
+// Handler for calls to `corso backup annotate`.
+func handleAnnotateCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	bID := args[0]
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if err := r.AnnotateBackup(ctx, model.StableID(bID), annotateMessage); err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to annotate backup %s", bID))
+	}
+
+	Info(ctx, "Updated notes for backup ", bID)
+
+	return nil
+}
+
+// printNotes writes any notes attached to the backup ahead of its details.
+// Notes are informational, so failing to look them up is not an error.
+func printNotes(ctx context.Context, r repository.BackupGetter, backupID string) {
+	b, err := r.Backup(ctx, model.StableID(backupID))
+	if err != nil || len(b.Notes) == 0 {
+		return
+	}
+
+	Infof(ctx, "Notes: %s", b.Notes)
+}
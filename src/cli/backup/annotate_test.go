@@ -0,0 +1,34 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type AnnotateSuite struct {
+	tester.Suite
+}
+
+func TestAnnotateSuite(t *testing.T) {
+	suite.Run(t, &AnnotateSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *AnnotateSuite) TestAnnotateCmd() {
+	t := suite.T()
+	c := annotateCmd()
+
+	assert.Equal(t, annotateCommand, c.Name())
+	tester.AreSameFunc(t, handleAnnotateCmd, c.RunE)
+
+	f := c.Flags().Lookup(messageFN)
+	require.NotNil(t, f)
+	assert.Equal(t, "m", f.Shorthand)
+
+	assert.Error(t, c.Args(c, []string{}), "missing backup id")
+	assert.NoError(t, c.Args(c, []string{"backup-ID"}))
+}
@@ -1,9 +1,34 @@
 package backup
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/operations"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
+// group holds the AAD groups whose members are backed up.
+var group []string
+
 var subCommandFuncs = []func() *cobra.Command{
 	createCmd,
 	listCmd,
@@ -30,6 +55,17 @@ func AddCommands(cmd *cobra.Command) {
 			addBackupTo(subCommand)
 		}
 	}
+
+	backupC.AddCommand(exportCmd())
+	backupC.AddCommand(annotateCmd())
+	backupC.AddCommand(describeCmd())
+	backupC.AddCommand(previewCmd())
+	backupC.AddCommand(bundleCmd())
+	backupC.AddCommand(treeCmd())
+	backupC.AddCommand(verifyCmd())
+	backupC.AddCommand(reportCmd())
+	backupC.AddCommand(explainItemCmd())
+	backupC.AddCommand(searchCmd())
 }
 
 // The backup category of commands.
@@ -125,3 +161,295 @@ func deleteCmd() *cobra.Command {
 func handleDeleteCmd(cmd *cobra.Command, args []string) error {
 	return cmd.Help()
 }
+
+// columns holds the details columns selected by the user, if any.
+var columns []string
+
+// addColumnsFlag adds the --columns flag to the details command.
+func addColumnsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(
+		&columns,
+		utils.ColumnsFN, nil,
+		"Columns to display in the details table, in order (ex: ItemName,Size,WebURL). "+
+			"Accepts any of: "+strings.Join(details.Columns(), ", ")+", or an extension key (ex: com.example/label)")
+}
+
+// parallelOwners is the number of owners backed up at once.
+var parallelOwners int
+
+// addParallelOwnersFlag adds the --parallel-owners flag to the create command.
+func addParallelOwnersFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&parallelOwners,
+		utils.ParallelOwnersFN, 1,
+		"Number of users or sites to back up at once, when backing up more than one.")
+}
+
+// ownerResult is the outcome of backing up a single resource owner.
+type ownerResult struct {
+	Owner    string              `json:"owner"`
+	Status   observe.OwnerStatus `json:"status"`
+	BackupID model.StableID      `json:"backupID,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func (or ownerResult) MinimumPrintable() any {
+	return or
+}
+
+func (or ownerResult) Headers() []string {
+	return []string{"Owner", "Status", "Backup ID", "Error"}
+}
+
+func (or ownerResult) Values() []string {
+	return []string{or.Owner, string(or.Status), string(or.BackupID), or.Error}
+}
+
+// backupsOwnedBy produces the backups of the owners.  All of the backups
+// are produced if there are no owners, or if the owners include the
+// wildcard.
+func backupsOwnedBy(bs []*backup.Backup, owners []string) []*backup.Backup {
+	if len(owners) == 0 || slices.Contains(owners, utils.Wildcard) {
+		return bs
+	}
+
+	filtered := make([]*backup.Backup, 0, len(bs))
+
+	for _, b := range bs {
+		for _, o := range owners {
+			if strings.EqualFold(b.Selector.DiscreteOwner, o) {
+				filtered = append(filtered, b)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// runBackups backs up each owner's selector, running up to parallelOwners
+// owners at once.  Once the context is canceled, such as by the user
+// interrupting the command, the remaining owners are skipped.  Runs over
+// many owners display the status of each owner as the run progresses, and
+// end with a table of those statuses.
+func runBackups(
+	ctx context.Context,
+	r repository.Repository,
+	service, ownerKind string,
+	sels []selectors.Selector,
+) error {
+	var (
+		merrs   *multierror.Error
+		bIDs    []model.StableID
+		owners  = make([]string, 0, len(sels))
+		results = make([]ownerResult, len(sels))
+		errs    = make([]error, len(sels))
+		multi   = len(sels) > 1
+		prog    *observe.OwnersProgress
+		wg      sync.WaitGroup
+		limit   = parallelOwners
+	)
+
+	if limit < 1 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+
+	for _, sel := range sels {
+		owners = append(owners, sel.DiscreteOwner)
+	}
+
+	if multi {
+		prog = observe.OwnerProgress(ctx, "Backing up "+service, owners)
+	}
+
+	setStatus := func(owner string, status observe.OwnerStatus) {
+		if prog != nil {
+			prog.Set(owner, status)
+		}
+	}
+
+	// backupOwner backs up the owner's selector, recording the outcome in
+	// its results and errs.
+	backupOwner := func(i int, sel selectors.Selector) {
+		owner := sel.DiscreteOwner
+		res := &results[i]
+
+		setStatus(owner, observe.OwnerRunning)
+
+		bo, err := r.NewBackup(ctx, sel)
+		if err != nil {
+			err = errors.Wrapf(err, "Failed to initialize %s backup for %s %s", service, ownerKind, owner)
+			errs[i] = err
+			res.Status, res.Error = observe.OwnerFailed, err.Error()
+
+			setStatus(owner, res.Status)
+
+			return
+		}
+
+		err = bo.Run(ctx)
+		if err != nil {
+			errs[i] = errors.Wrapf(err, "Failed to run %s backup for %s %s", service, ownerKind, owner)
+
+			// backups interrupted by the cancellation weren't at fault.
+			if ctx.Err() == nil {
+				res.Status, res.Error = observe.OwnerFailed, errs[i].Error()
+			}
+
+			setStatus(owner, res.Status)
+
+			return
+		}
+
+		warnQuota(ctx, owner, bo.Results)
+		warnAlerts(ctx, owner, bo.Results)
+
+		res.Status, res.BackupID = observe.OwnerDone, bo.Results.BackupID
+
+		setStatus(owner, res.Status)
+	}
+
+	for i, sel := range sels {
+		results[i] = ownerResult{Owner: sel.DiscreteOwner, Status: observe.OwnerCanceled}
+
+		sem <- struct{}{}
+
+		if ctx.Err() != nil {
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, sel selectors.Selector) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			backupOwner(i, sel)
+		}(i, sel)
+	}
+
+	wg.Wait()
+
+	printables := make([]Printable, 0, len(results))
+
+	for i, res := range results {
+		if errs[i] != nil {
+			merrs = multierror.Append(merrs, errs[i])
+		}
+
+		if len(res.BackupID) > 0 {
+			bIDs = append(bIDs, res.BackupID)
+		}
+
+		printables = append(printables, res)
+	}
+
+	if prog != nil {
+		prog.Finish()
+	}
+
+	// the backups which did complete are still reported after the run
+	// is interrupted.
+	bups, ferrs := r.Backups(detachedCtx{ctx}, bIDs)
+	// TODO: print/log recoverable errors
+	if ferrs.Err() != nil {
+		return Only(ctx, errors.Wrap(ferrs.Err(), "Unable to retrieve backup results from storage"))
+	}
+
+	backup.PrintAll(ctx, bups)
+
+	if multi && !JSONFormat() {
+		Info(ctx, "")
+		Table(ctx, printables)
+	}
+
+	if ctx.Err() != nil {
+		merrs = multierror.Append(merrs, errors.Errorf("%s backup canceled", service))
+	}
+
+	if e := merrs.ErrorOrNil(); e != nil {
+		return Only(ctx, e)
+	}
+
+	return nil
+}
+
+// detachedCtx retains the values of its parent, but is never cancelled.
+type detachedCtx struct {
+	context.Context
+}
+
+func (detachedCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedCtx) Done() <-chan struct{}       { return nil }
+func (detachedCtx) Err() error                  { return nil }
+
+// warnQuota informs the user when a backup brought the repository to
+// one of its quota limits.
+func warnQuota(ctx context.Context, owner string, res operations.BackupResults) {
+	if len(res.QuotaAlert) == 0 {
+		return
+	}
+
+	Infof(
+		ctx,
+		"Warning: after backing up %s, the repository size (%s) reached the %s quota limit.",
+		owner,
+		humanize.IBytes(uint64(res.ProjectedRepoSize)),
+		res.QuotaAlert)
+}
+
+// warnAlerts informs the user of each alert threshold that a backup reached,
+// and of each anomaly found in its changes.
+func warnAlerts(ctx context.Context, owner string, res operations.BackupResults) {
+	for _, alert := range res.Alerts {
+		switch alert {
+		case operations.AlertDuration:
+			Infof(ctx, "Warning: the backup of %s ran longer than the alert duration.", owner)
+		case operations.AlertChangedItems, operations.AlertChangedPercent:
+			Infof(ctx, "Warning: the backup of %s found more changes than the alert threshold.", owner)
+		}
+	}
+
+	for _, a := range res.Anomalies {
+		switch a.Kind {
+		case details.MassDeletion:
+			Infof(ctx, "Warning: %d of %d items were deleted since the last backup of %s.", a.Items, a.Total, owner)
+		case details.ExtensionChange:
+			Infof(
+				ctx,
+				"Warning: %d of %d files backed up for %s had their extension changed, most often to %q.",
+				a.Items, a.Total, owner, a.Extension)
+		}
+	}
+}
+
+// resolveOwnerGroups adds the current members of the selector's owner
+// groups to its resource owners.
+func resolveOwnerGroups(
+	ctx context.Context,
+	acct account.Account,
+	sel *selectors.Selector,
+	errs *fault.Errors,
+) error {
+	if len(sel.OwnerGroups) == 0 {
+		return nil
+	}
+
+	members, err := m365.GroupMemberPNs(ctx, acct, sel.OwnerGroups, errs)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve M365 group members")
+	}
+
+	if len(members) == 0 {
+		return errors.Errorf("No users are members of the group(s) %s", strings.Join(sel.OwnerGroups, ", "))
+	}
+
+	*sel = sel.ResolveOwnerGroups(members)
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package backup
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/operations"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type BackupUnitSuite struct {
+	tester.Suite
+}
+
+func TestBackupUnitSuite(t *testing.T) {
+	suite.Run(t, &BackupUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// mockRepo fails to create any backup, recording the owners it was
+// asked to back up.
+type mockRepo struct {
+	repository.Repository
+	mu     sync.Mutex
+	owners []string
+}
+
+func (mr *mockRepo) NewBackup(
+	_ context.Context,
+	sel selectors.Selector,
+) (operations.BackupOperation, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.owners = append(mr.owners, sel.DiscreteOwner)
+
+	return operations.BackupOperation{}, errors.New("no backup")
+}
+
+func (mr *mockRepo) Backups(context.Context, []model.StableID) ([]*backup.Backup, *fault.Errors) {
+	return nil, fault.New(false)
+}
+
+func (suite *BackupUnitSuite) TestRunBackups() {
+	owners := []string{"a", "b"}
+	sels := make([]selectors.Selector, 0, len(owners))
+
+	for _, sel := range selectors.NewExchangeBackup(owners).SplitByResourceOwner(owners) {
+		sels = append(sels, sel.Selector)
+	}
+
+	suite.Run("failures", func() {
+		ctx, flush := tester.NewContext()
+		defer flush()
+
+		t := suite.T()
+		r := &mockRepo{}
+
+		err := runBackups(ctx, r, "Exchange", "user", sels)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Failed to initialize Exchange backup for user a")
+		assert.Contains(t, err.Error(), "Failed to initialize Exchange backup for user b")
+		assert.Equal(t, owners, r.owners)
+	})
+
+	suite.Run("parallel", func() {
+		ctx, flush := tester.NewContext()
+		defer flush()
+
+		parallelOwners = 2
+		defer func() { parallelOwners = 1 }()
+
+		t := suite.T()
+		r := &mockRepo{}
+
+		err := runBackups(ctx, r, "Exchange", "user", sels)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Failed to initialize Exchange backup for user a")
+		assert.Contains(t, err.Error(), "Failed to initialize Exchange backup for user b")
+		assert.ElementsMatch(t, owners, r.owners)
+	})
+
+	suite.Run("canceled", func() {
+		ctx, flush := tester.NewContext()
+		defer flush()
+
+		t := suite.T()
+		r := &mockRepo{}
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := runBackups(ctx, r, "Exchange", "user", sels)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Exchange backup canceled")
+		assert.Empty(t, r.owners, "no owners are backed up after cancellation")
+	})
+}
+
+func (suite *BackupUnitSuite) TestOwnerResult_Values() {
+	or := ownerResult{
+		Owner:    "a",
+		Status:   observe.OwnerDone,
+		BackupID: model.StableID("id"),
+	}
+
+	assert.Equal(suite.T(), len(or.Headers()), len(or.Values()))
+	assert.Equal(suite.T(), []string{"a", "done", "id", ""}, or.Values())
+}
+
+func (suite *BackupUnitSuite) TestBackupsOwnedBy() {
+	var (
+		jane = &backup.Backup{Selector: selectors.Selector{DiscreteOwner: "jane@contoso.com"}}
+		bob  = &backup.Backup{Selector: selectors.Selector{DiscreteOwner: "bob@contoso.com"}}
+		bs   = []*backup.Backup{jane, bob}
+	)
+
+	table := []struct {
+		name   string
+		owners []string
+		expect []*backup.Backup
+	}{
+		{"no owners", nil, bs},
+		{"wildcard", []string{"*"}, bs},
+		{"owner", []string{"Jane@contoso.com"}, []*backup.Backup{jane}},
+		{"unknown owner", []string{"alice@contoso.com"}, []*backup.Backup{}},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, backupsOwnedBy(bs, test.owners))
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/export"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+// bundle flag values
+var (
+	bundleBackupID    string
+	bundleOutput      string
+	bundleFile        string
+	bundleFingerprint string
+)
+
+const (
+	bundleCommand       = "bundle"
+	bundleVerifyCommand = "verify"
+	bundleFN            = "bundle"
+	fingerprintFN       = "fingerprint"
+)
+
+const bundleCommandExamples = `# Bundle backup 1234abcd-12ab-cd34-56de-1234abcd into ./backup.zip
+corso backup bundle --backup 1234abcd-12ab-cd34-56de-1234abcd --output ./backup.zip`
+
+const bundleVerifyCommandExamples = `# Verify the contents of ./backup.zip
+corso backup bundle verify --bundle ./backup.zip
+
+# Verify ./backup.zip against the fingerprint reported when it was bundled
+corso backup bundle verify --bundle ./backup.zip --fingerprint 9f86d081884c7d65...`
+
+// The backup bundle subcommand.
+// `corso backup bundle --backup <backupId> --output <file>`
+func bundleCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   bundleCommand,
+		Short: "Bundle a backup for offline verification",
+		Long: `Bundle a backup into a self-contained zip archive, for escrow or third-party audits.
+The bundle holds the exported data of the backup, the backup and its details, and the
+sha256 hash of every file.  Bundles are verified with 'corso backup bundle verify', which
+doesn't need access to the repository, or by extracting the bundle and running
+'sha256sum -c SHA256SUMS'.`,
+		RunE:    handleBundleCmd,
+		Args:    cobra.NoArgs,
+		Example: bundleCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&bundleBackupID,
+		utils.BackupFN, "",
+		"ID of the backup to bundle. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&bundleOutput,
+		outputFN, "",
+		"File to write the bundle into.  The file must not already exist. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(outputFN))
+
+	options.AddReadCacheFlags(c)
+
+	c.AddCommand(bundleVerifyCmd())
+
+	return c
+}
+
+// Handler for calls to `corso backup bundle`.
+func handleBundleCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	fingerprint, items, err := writeBundle(ctx, r, bundleBackupID, bundleOutput)
+	if err != nil {
+		// don't leave a partial bundle behind for someone to escrow.
+		os.Remove(bundleOutput)
+		return Only(ctx, errors.Wrapf(err, "Failed to bundle backup %s", bundleBackupID))
+	}
+
+	Infof(ctx, "Bundled %d items from backup %s into %s", items, bundleBackupID, bundleOutput)
+	Outf(ctx, "Fingerprint: %s", fingerprint)
+
+	return nil
+}
+
+// writeBundle bundles the backup into the output file, producing the
+// bundle's fingerprint and the count of bundled items.
+func writeBundle(
+	ctx context.Context,
+	r repository.Repository,
+	backupID, output string,
+) (string, int, error) {
+	deets, bup, errs := r.BackupDetails(ctx, backupID)
+	if errs.Err() != nil {
+		return "", 0, errors.Wrap(errs.Err(), "retrieving backup details")
+	}
+
+	sel, err := exportSelector(bup.Selector)
+	if err != nil {
+		return "", 0, err
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "creating bundle file")
+	}
+
+	bw, err := export.NewBundleWriter(ctx, f, bup, deets)
+	if err != nil {
+		f.Close()
+		return "", 0, errors.Wrap(err, "initializing bundle")
+	}
+
+	eo, err := r.NewExport(ctx, backupID, sel, bw)
+	if err != nil {
+		bw.Close()
+		return "", 0, errors.Wrap(err, "initializing export")
+	}
+
+	if err := eo.Run(ctx); err != nil {
+		return "", 0, err
+	}
+
+	// a bundle is only useful for an audit if it holds every item.
+	if errs := eo.Errors.Errs(); len(errs) > 0 {
+		return "", 0, errors.Wrapf(errs[0], "%d items could not be bundled", eo.Errors.Count())
+	}
+
+	return bw.Fingerprint(), eo.Results.ItemsWritten, nil
+}
+
+// The backup bundle verify subcommand.
+// `corso backup bundle verify --bundle <file>`
+func bundleVerifyCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   bundleVerifyCommand,
+		Short: "Verify a backup bundle",
+		Long: `Verify that every file in a backup bundle matches the hashes recorded when the
+bundle was created.  Verification only reads the bundle, and doesn't need a repository
+or any corso configuration.`,
+		RunE:    handleBundleVerifyCmd,
+		Args:    cobra.NoArgs,
+		Example: bundleVerifyCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&bundleFile,
+		bundleFN, "",
+		"Bundle file to verify. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(bundleFN))
+
+	fs.StringVar(
+		&bundleFingerprint,
+		fingerprintFN, "",
+		"Fingerprint reported when the bundle was created.  Verification fails if the bundle's "+
+			"fingerprint doesn't match.")
+
+	return c
+}
+
+// Handler for calls to `corso backup bundle verify`.
+func handleBundleVerifyCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	bv, err := verifyBundleFile(bundleFile)
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to verify bundle %s", bundleFile))
+	}
+
+	if len(bundleFingerprint) > 0 && !strings.EqualFold(bundleFingerprint, bv.Fingerprint) {
+		bv.Problems = append(bv.Problems, "fingerprint does not match: "+bv.Fingerprint)
+	}
+
+	if JSONFormat() {
+		Item(ctx, bundleVerification(bv))
+	} else {
+		for _, p := range bv.Problems {
+			Out(ctx, p)
+		}
+	}
+
+	if !bv.Intact() {
+		return Only(ctx, errors.Errorf("Bundle %s failed verification with %d problems", bundleFile, len(bv.Problems)))
+	}
+
+	Infof(
+		ctx,
+		"Verified %d items of backup %s in bundle %s",
+		bv.Manifest.Items,
+		bv.Manifest.BackupID,
+		bundleFile)
+	Outf(ctx, "Fingerprint: %s", bv.Fingerprint)
+
+	return nil
+}
+
+func verifyBundleFile(name string) (export.BundleVerification, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return export.BundleVerification{}, errors.Wrap(err, "opening bundle")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return export.BundleVerification{}, errors.Wrap(err, "reading bundle size")
+	}
+
+	return export.VerifyBundle(f, fi.Size())
+}
+
+// bundleVerification prints the outcome of a bundle verification.
+type bundleVerification export.BundleVerification
+
+func (bv bundleVerification) MinimumPrintable() any {
+	return bv
+}
+
+func (bv bundleVerification) Headers() []string {
+	return []string{"Backup ID", "Items", "Fingerprint", "Problems"}
+}
+
+func (bv bundleVerification) Values() []string {
+	return []string{
+		bv.Manifest.BackupID,
+		strconv.Itoa(bv.Manifest.Items),
+		bv.Fingerprint,
+		strconv.Itoa(len(bv.Problems)),
+	}
+}
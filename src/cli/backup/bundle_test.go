@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/export"
+)
+
+type BundleSuite struct {
+	tester.Suite
+}
+
+func TestBundleSuite(t *testing.T) {
+	suite.Run(t, &BundleSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *BundleSuite) TestBundleCmd() {
+	t := suite.T()
+	c := bundleCmd()
+
+	assert.Equal(t, bundleCommand, c.Use)
+	tester.AreSameFunc(t, handleBundleCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup("backup"))
+	assert.NotNil(t, c.Flags().Lookup(outputFN))
+
+	vc, _, err := c.Find([]string{bundleVerifyCommand})
+	require.NoError(t, err)
+	assert.Equal(t, bundleVerifyCommand, vc.Use)
+	tester.AreSameFunc(t, handleBundleVerifyCmd, vc.RunE)
+	assert.NotNil(t, vc.Flags().Lookup(bundleFN))
+	assert.NotNil(t, vc.Flags().Lookup(fingerprintFN))
+}
+
+func (suite *BundleSuite) TestVerifyBundleFile() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		name = filepath.Join(t.TempDir(), "bundle.zip")
+		bup  = &backup.Backup{BaseModel: model.BaseModel{ID: "bid"}}
+	)
+
+	f, err := os.Create(name)
+	require.NoError(t, err)
+
+	bw, err := export.NewBundleWriter(ctx, f, bup, &details.Details{})
+	require.NoError(t, err)
+	require.NoError(t, bw.Write(ctx, "email/Inbox/a.json", strings.NewReader(`{"a":1}`)))
+	require.NoError(t, bw.Close())
+
+	bv, err := verifyBundleFile(name)
+	require.NoError(t, err)
+	assert.True(t, bv.Intact(), bv.Problems)
+	assert.Equal(t, bw.Fingerprint(), bv.Fingerprint)
+	assert.Equal(t, 1, bv.Manifest.Items)
+
+	_, err = verifyBundleFile(filepath.Join(t.TempDir(), "missing.zip"))
+	assert.Error(t, err)
+}
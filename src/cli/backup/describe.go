@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+const describeCommand = "describe"
+
+const describeCommandExamples = `# Describe backup 1234abcd-12ab-cd34-56de-1234abcd, including the time
+# spent in each phase
+corso backup describe 1234abcd-12ab-cd34-56de-1234abcd
+
+# Also show the folders with the lowest throughput, and the slowest items, in the backup
+corso backup describe 1234abcd-12ab-cd34-56de-1234abcd --performance`
+
+// showPerformance includes the backup's lowest throughput folders and slowest items.
+var showPerformance bool
+
+// The backup describe subcommand.
+// `corso backup describe <backupId>`
+func describeCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   describeCommand + " <backupId>",
+		Short: "Describe a backup",
+		Long: `Show a backup's summary along with a plain-English description of its selector,
+and the timeline of its phases: item discovery, the first upload, the details merge,
+and model persistence.`,
+		RunE:    handleDescribeCmd,
+		Args:    cobra.ExactArgs(1),
+		Example: describeCommandExamples,
+	}
+
+	c.Flags().BoolVar(
+		&showPerformance,
+		"performance", false,
+		"Show the folders with the lowest throughput, and the slowest items by download time, size, and retries")
+
+	return c
+}
+
+// Handler for calls to `corso backup describe`.
+func handleDescribeCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	bID := args[0]
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	b, err := r.Backup(ctx, model.StableID(bID))
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to find backup %s", bID))
+	}
+
+	b.Print(ctx)
+
+	// json output already includes the selector, timeline, and performance within the backup.
+	if !JSONFormat() {
+		Out(ctx, "\n"+b.Selector.Describe()+"\n")
+		b.PrintTimeline(ctx)
+
+		if showPerformance {
+			b.PrintPerformance(ctx)
+		}
+	}
+
+	return nil
+}
@@ -3,7 +3,6 @@ package backup
 import (
 	"context"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -40,8 +39,10 @@ var (
 
 	email               []string
 	emailFolder         []string
+	emailFolderID       []string
 	emailReceivedAfter  string
 	emailReceivedBefore string
+	emailMessageID      string
 	emailSender         string
 	emailSubject        string
 
@@ -52,17 +53,22 @@ var (
 	eventStartsAfter  string
 	eventStartsBefore string
 	eventSubject      string
+
+	task        []string
+	taskFolder  []string
+	taskSubject string
 )
 
 const (
 	dataContacts = "contacts"
 	dataEmail    = "email"
 	dataEvents   = "events"
+	dataTasks    = "tasks"
 )
 
 const (
 	exchangeServiceCommand                 = "exchange"
-	exchangeServiceCommandCreateUseSuffix  = "--user <email> | '" + utils.Wildcard + "'"
+	exchangeServiceCommandCreateUseSuffix  = "--user <email> | '" + utils.Wildcard + "' | --group <name>"
 	exchangeServiceCommandDeleteUseSuffix  = "--backup <backupId>"
 	exchangeServiceCommandDetailsUseSuffix = "--backup <backupId>"
 )
@@ -74,8 +80,20 @@ corso backup create exchange --user alice@example.com
 # Backup only Exchange contacts for Alice and Bob
 corso backup create exchange --user alice@example.com,bob@example.com --data contacts
 
+# Backup only To Do tasks for Alice (tasks are only backed up when requested)
+corso backup create exchange --user alice@example.com --data tasks
+
 # Backup all Exchange data for all M365 users 
-corso backup create exchange --user '*'`
+corso backup create exchange --user '*'
+
+# Backup all Exchange data for the user named Jane Doe
+corso backup create exchange --user "Jane Doe"
+
+# Backup all Exchange data for the members of the Finance Team group
+corso backup create exchange --group "Finance Team"
+
+# Record the folder hierarchy of Alice's mailbox, without backing up any items
+corso backup create exchange --user alice@example.com --structure-only`
 
 	exchangeServiceCommandDeleteExamples = `# Delete Exchange backup with ID 1234abcd-12ab-cd34-56de-1234abcd
 corso backup delete exchange --backup 1234abcd-12ab-cd34-56de-1234abcd`
@@ -107,6 +125,7 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 	case createCommand:
 		c, fs = utils.AddCommand(cmd, exchangeCreateCmd())
 		options.AddFeatureToggle(cmd, options.DisableIncrementals())
+		options.AddFeatureToggle(c, options.SimulateExpiredDeltas())
 
 		c.Use = c.Use + " " + exchangeServiceCommandCreateUseSuffix
 		c.Example = exchangeServiceCommandCreateExamples
@@ -116,12 +135,26 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 		fs.StringSliceVar(
 			&user,
 			utils.UserFN, nil,
-			"Backup Exchange data by a user's email; accepts '"+utils.Wildcard+"' to select all users")
+			"Backup Exchange data by a user's email or display name; accepts '"+utils.Wildcard+"' to select all users")
+		fs.StringSliceVar(
+			&group,
+			utils.GroupFN, nil,
+			"Backup Exchange data for the members of an Azure AD group, by group name or ID. "+
+				"Includes the members of nested groups, as of the time of the backup.")
 		fs.StringSliceVar(
 			&exchangeData,
 			utils.DataFN, nil,
-			"Select one or more types of data to backup: "+dataEmail+", "+dataContacts+", or "+dataEvents)
+			"Select one or more types of data to backup: "+dataEmail+", "+dataContacts+", "+dataEvents+", or "+dataTasks+
+				". Tasks are only included when selected.")
 		options.AddOperationFlags(c)
+		addParallelOwnersFlag(c)
+		options.AddTuningFlags(c)
+		options.AddSpillFlags(c)
+		options.AddAlertFlags(c)
+		options.AddMemoryFlags(c)
+		options.AddHeartbeatFlags(c)
+		options.AddBackupBandwidthFlag(c)
+		options.AddStructureOnlyFlag(c)
 
 	case listCommand:
 		c, fs = utils.AddCommand(cmd, exchangeListCmd())
@@ -129,6 +162,10 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 		fs.StringVar(&backupID,
 			"backup", "",
 			"ID of the backup to retrieve.")
+		fs.StringSliceVar(
+			&user,
+			utils.UserFN, nil,
+			"List the backups of users by ID, email address, or display name.")
 
 	case detailsCommand:
 		c, fs = utils.AddCommand(cmd, exchangeDetailsCmd())
@@ -145,7 +182,8 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 		fs.StringSliceVar(
 			&user,
 			utils.UserFN, nil,
-			"Select backup details by user ID; accepts '"+utils.Wildcard+"' to select all users.")
+			"Select backup details by user ID, email address, or display name; "+
+				"accepts '"+utils.Wildcard+"' to select all users.")
 
 		// email flags
 		fs.StringSliceVar(
@@ -156,6 +194,10 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			&emailFolder,
 			utils.EmailFolderFN, nil,
 			"Select backup details for emails within a folder; accepts '"+utils.Wildcard+"' to select all email folders.")
+		fs.StringSliceVar(
+			&emailFolderID,
+			utils.EmailFolderIDFN, nil,
+			"Select backup details for emails within a folder by folder ID; matches the folder even if it was renamed.")
 		fs.StringVar(
 			&emailSubject,
 			utils.EmailSubjectFN, "",
@@ -172,6 +214,10 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			&emailReceivedBefore,
 			utils.EmailReceivedBeforeFN, "",
 			"Select backup details for emails received before this datetime.")
+		fs.StringVar(
+			&emailMessageID,
+			utils.EmailMessageIDFN, "",
+			"Select backup details for emails with this internet Message-ID.")
 
 		// event flags
 		fs.StringSliceVar(
@@ -218,6 +264,22 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			utils.ContactNameFN, "",
 			"Select backup details for contacts whose contact name contains this value.")
 
+		// task flags
+		fs.StringSliceVar(
+			&task,
+			utils.TaskFN, nil,
+			"Select backup details for tasks by task ID; accepts '"+utils.Wildcard+"' to select all tasks.")
+		fs.StringSliceVar(
+			&taskFolder,
+			utils.TaskFolderFN, nil,
+			"Select backup details for tasks within a To Do list; accepts '"+utils.Wildcard+"' to select all task lists.")
+		fs.StringVar(
+			&taskSubject,
+			utils.TaskSubjectFN, "",
+			"Select backup details for tasks with a subject containing this value.")
+
+		addColumnsFlag(c)
+
 	case deleteCommand:
 		c, fs = utils.AddCommand(cmd, exchangeDeleteCmd())
 
@@ -255,7 +317,7 @@ func createExchangeCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if err := validateExchangeBackupCreateFlags(user, exchangeData); err != nil {
+	if err := validateExchangeBackupCreateFlags(user, group, exchangeData); err != nil {
 		return err
 	}
 
@@ -271,60 +333,36 @@ func createExchangeCmd(cmd *cobra.Command, args []string) error {
 
 	defer utils.CloseRepo(ctx, r)
 
-	sel := exchangeBackupCreateSelectors(user, exchangeData)
-
 	// TODO: log/print recoverable errors
 	errs := fault.New(false)
 
-	users, err := m365.UserPNs(ctx, acct, errs)
+	all, err := m365.Users(ctx, acct, errs)
 	if err != nil {
 		return Only(ctx, errors.Wrap(err, "Failed to retrieve M365 user(s)"))
 	}
 
-	var (
-		merrs *multierror.Error
-		bIDs  []model.StableID
-	)
-
-	for _, discSel := range sel.SplitByResourceOwner(users) {
-		bo, err := r.NewBackup(ctx, discSel.Selector)
-		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to initialize Exchange backup for user %s",
-				discSel.DiscreteOwner,
-			))
-
-			continue
-		}
-
-		err = bo.Run(ctx)
-		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to run Exchange backup for user %s",
-				discSel.DiscreteOwner,
-			))
+	resolved, err := utils.ResolveUserNames(user, all)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
 
-			continue
-		}
+	sel := exchangeBackupCreateSelectors(resolved, exchangeData)
+	sel.IncludeOwnerGroups(group...)
 
-		bIDs = append(bIDs, bo.Results.BackupID)
+	if err := resolveOwnerGroups(ctx, acct, &sel.Selector, errs); err != nil {
+		return Only(ctx, err)
 	}
 
-	bups, ferrs := r.Backups(ctx, bIDs)
-	// TODO: print/log recoverable errors
-	if ferrs.Err() != nil {
-		return Only(ctx, errors.Wrap(ferrs.Err(), "Unable to retrieve backup results from storage"))
-	}
+	users := m365.PrincipalNames(all)
 
-	backup.PrintAll(ctx, bups)
+	discSels := sel.SplitByResourceOwner(users)
+	sels := make([]selectors.Selector, 0, len(discSels))
 
-	if e := merrs.ErrorOrNil(); e != nil {
-		return Only(ctx, e)
+	for _, discSel := range discSels {
+		sels = append(sels, discSel.Selector)
 	}
 
-	return nil
+	return runBackups(ctx, r, "Exchange", "user", sels)
 }
 
 func exchangeBackupCreateSelectors(userIDs, cats []string) *selectors.ExchangeBackup {
@@ -344,21 +382,24 @@ func exchangeBackupCreateSelectors(userIDs, cats []string) *selectors.ExchangeBa
 			sel.Include(sel.MailFolders(selectors.Any()))
 		case dataEvents:
 			sel.Include(sel.EventCalendars(selectors.Any()))
+		case dataTasks:
+			sel.Include(sel.TaskFolders(selectors.Any()))
 		}
 	}
 
 	return sel
 }
 
-func validateExchangeBackupCreateFlags(userIDs, cats []string) error {
-	if len(userIDs) == 0 {
-		return errors.New("--user requires one or more email addresses or the wildcard '*'")
+func validateExchangeBackupCreateFlags(userIDs, groups, cats []string) error {
+	if len(userIDs) == 0 && len(groups) == 0 {
+		return errors.New("--user requires one or more email addresses or the wildcard '*', unless --group is provided")
 	}
 
 	for _, d := range cats {
-		if d != dataContacts && d != dataEmail && d != dataEvents {
+		if d != dataContacts && d != dataEmail && d != dataEvents && d != dataTasks {
 			return errors.New(
-				d + " is an unrecognized data type; must be one of " + dataContacts + ", " + dataEmail + ", or " + dataEvents)
+				d + " is an unrecognized data type; must be one of " +
+					dataContacts + ", " + dataEmail + ", " + dataEvents + ", or " + dataTasks)
 		}
 	}
 
@@ -388,6 +429,11 @@ func listExchangeCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	owners, err := utils.ResolveBackedUpUserNames(ctx, acct, user)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, acct, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -415,7 +461,7 @@ func listExchangeCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to list backups in the repository"))
 	}
 
-	backup.PrintAll(ctx, bs)
+	backup.PrintAll(ctx, backupsOwnedBy(bs, owners))
 
 	return nil
 }
@@ -446,12 +492,14 @@ func detailsExchangeCmd(cmd *cobra.Command, args []string) error {
 		ContactFolder:       contactFolder,
 		Email:               email,
 		EmailFolder:         emailFolder,
+		EmailFolderID:       emailFolderID,
 		Event:               event,
 		EventCalendar:       eventCalendar,
 		Users:               user,
 		ContactName:         contactName,
 		EmailReceivedAfter:  emailReceivedAfter,
 		EmailReceivedBefore: emailReceivedBefore,
+		EmailMessageID:      emailMessageID,
 		EmailSender:         emailSender,
 		EmailSubject:        emailSubject,
 		EventOrganizer:      eventOrganizer,
@@ -459,15 +507,27 @@ func detailsExchangeCmd(cmd *cobra.Command, args []string) error {
 		EventStartsAfter:    eventStartsAfter,
 		EventStartsBefore:   eventStartsBefore,
 		EventSubject:        eventSubject,
+		Task:                task,
+		TaskFolder:          taskFolder,
+		TaskSubject:         taskSubject,
 
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
 
+	if err := details.ValidateColumns(columns); err != nil {
+		return Only(ctx, err)
+	}
+
 	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
 	if err != nil {
 		return Only(ctx, err)
 	}
 
+	opts.Users, err = utils.ResolveBackedUpUserNames(ctx, acct, opts.Users)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, acct, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -480,12 +540,14 @@ func detailsExchangeCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	printNotes(ctx, r, backupID)
+
 	if len(ds.Entries) == 0 {
 		Info(ctx, selectors.ErrorNoMatchingItems)
 		return nil
 	}
 
-	ds.PrintEntries(ctx)
+	ds.PrintEntries(ctx, columns...)
 
 	return nil
 }
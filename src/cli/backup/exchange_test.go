@@ -68,11 +68,20 @@ func (suite *ExchangeSuite) TestAddExchangeCommands() {
 	}
 }
 
+func (suite *ExchangeSuite) TestCreateSimulateExpiredDeltasFlag() {
+	cmd := &cobra.Command{Use: createCommand}
+
+	c := addExchangeCommands(cmd)
+	require.NotNil(suite.T(), c)
+
+	assert.NotNil(suite.T(), c.Flag("simulate-expired-deltas"))
+}
+
 func (suite *ExchangeSuite) TestValidateBackupCreateFlags() {
 	table := []struct {
-		name       string
-		user, data []string
-		expect     assert.ErrorAssertionFunc
+		name              string
+		user, group, data []string
+		expect            assert.ErrorAssertionFunc
 	}{
 		{
 			name:   "no users or data",
@@ -94,12 +103,18 @@ func (suite *ExchangeSuite) TestValidateBackupCreateFlags() {
 			user:   []string{"fnord"},
 			expect: assert.NoError,
 		},
+		{
+			name:   "only groups",
+			group:  []string{"Finance Team"},
+			data:   []string{dataEmail},
+			expect: assert.NoError,
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
 			t := suite.T()
 
-			test.expect(t, validateExchangeBackupCreateFlags(test.user, test.data))
+			test.expect(t, validateExchangeBackupCreateFlags(test.user, test.group, test.data))
 		})
 	}
 }
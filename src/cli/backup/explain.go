@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+// explain-item flag values
+var (
+	explainBackupID string
+	explainItemRef  string
+)
+
+const explainItemCommand = "explain-item"
+
+const explainItemCommandExamples = `# Explain why item AAMkADAw... failed in backup 1234abcd-12ab-cd34-56de-1234abcd
+corso backup explain-item --backup 1234abcd-12ab-cd34-56de-1234abcd --item AAMkADAw...
+
+# Look up the same item by its short ref, as listed in the backup details
+corso backup explain-item --backup 1234abcd-12ab-cd34-56de-1234abcd --item 8f2e1a9c4b`
+
+// The backup explain-item subcommand.
+// `corso backup explain-item --backup <backupId> --item <ref>`
+func explainItemCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   explainItemCommand,
+		Short: "Explain what happened to an item in a backup",
+		Long: `Correlate everything a backup recorded about a single item: its entry in the
+backup details, its failures along with the error codes reported by the service and
+the retries that were made, the other failures in the same folder and with the same
+cause, and how long the item took to retrieve.  The item can be identified by its
+ID, its short ref, or its repo ref.`,
+		RunE:    handleExplainItemCmd,
+		Args:    cobra.NoArgs,
+		Example: explainItemCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&explainBackupID,
+		utils.BackupFN, "",
+		"ID of the backup holding the item. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&explainItemRef,
+		itemFN, "",
+		"ID, short ref, or repo ref of the item. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(itemFN))
+
+	return c
+}
+
+// Handler for calls to `corso backup explain-item`.
+func handleExplainItemCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	deets, b, errs := r.BackupDetails(ctx, explainBackupID)
+	if errs.Err() != nil {
+		if errors.Is(errs.Err(), data.ErrNotFound) {
+			return Only(ctx, errors.Errorf("No backup exists with the id %s", explainBackupID))
+		}
+
+		return Only(ctx, errors.Wrap(errs.Err(), "Failed to get backup details in the repository"))
+	}
+
+	ie := b.ExplainItem(explainItemRef, deets)
+
+	if !JSONFormat() {
+		Out(ctx, ie.String())
+		return nil
+	}
+
+	bs, err := json.MarshalIndent(ie, "", "  ")
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to format the explanation"))
+	}
+
+	Out(ctx, string(bs))
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ExplainSuite struct {
+	tester.Suite
+}
+
+func TestExplainSuite(t *testing.T) {
+	suite.Run(t, &ExplainSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ExplainSuite) TestExplainItemCmd() {
+	t := suite.T()
+	c := explainItemCmd()
+
+	assert.Equal(t, explainItemCommand, c.Use)
+	tester.AreSameFunc(t, handleExplainItemCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup("backup"))
+	assert.NotNil(t, c.Flags().Lookup(itemFN))
+}
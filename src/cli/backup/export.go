@@ -0,0 +1,207 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/export"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+// export flag values
+var (
+	exportBackupID string
+	exportOutput   string
+	exportArchive  string
+)
+
+const (
+	exportCommand = "export"
+	outputFN      = "output"
+	archiveFN     = "archive"
+
+	// stdoutOutput directs archive exports to stdout.
+	stdoutOutput = "-"
+)
+
+const exportCommandExamples = `# Export all data from backup 1234abcd-12ab-cd34-56de-1234abcd into ./export
+corso backup export --backup 1234abcd-12ab-cd34-56de-1234abcd --output ./export
+
+# Export backup 1234abcd-12ab-cd34-56de-1234abcd into a zip archive
+corso backup export --backup 1234abcd-12ab-cd34-56de-1234abcd --archive zip --output ./export.zip
+
+# Stream backup 1234abcd-12ab-cd34-56de-1234abcd to stdout as a zstd compressed tar
+corso backup export --backup 1234abcd-12ab-cd34-56de-1234abcd --archive tar.zst --output - > export.tar.zst`
+
+// The backup export subcommand.
+// `corso backup export --backup <backupId> --output <dir>`
+func exportCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   exportCommand,
+		Short: "Export the data in a backup",
+		Long: `Export the data in a backup to local storage.  Exchange items are exported
+as json documents, while OneDrive and SharePoint files retain their original contents.`,
+		RunE:    handleExportCmd,
+		Args:    cobra.NoArgs,
+		Example: exportCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&exportBackupID,
+		utils.BackupFN, "",
+		"ID of the backup to export. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&exportOutput,
+		outputFN, "",
+		"Directory, or archive file, to export the backup data into; "+
+			"archives accept '"+stdoutOutput+"' to write to stdout. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(outputFN))
+
+	fs.StringVar(
+		&exportArchive,
+		archiveFN, "",
+		"Export into a single archive instead of a directory: "+archiveFormats()+".")
+
+	return c
+}
+
+// Handler for calls to `corso backup export`.
+func handleExportCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if err := validateExportFlags(exportOutput, exportArchive); err != nil {
+		return Only(ctx, err)
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	bup, err := r.Backup(ctx, model.StableID(exportBackupID))
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to find backup %s", exportBackupID))
+	}
+
+	sel, err := exportSelector(bup.Selector)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	w, err := exportWriter(exportOutput, exportArchive)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to prepare the export destination"))
+	}
+
+	eo, err := r.NewExport(ctx, exportBackupID, sel, w)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to initialize export"))
+	}
+
+	if err := eo.Run(ctx); err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to export backup %s", exportBackupID))
+	}
+
+	Infof(ctx, "Exported %d items from backup %s", eo.Results.ItemsWritten, exportBackupID)
+
+	return nil
+}
+
+// exportSelector produces a selector which includes all of the data
+// in the backed up service.
+func exportSelector(backupSel selectors.Selector) (selectors.Selector, error) {
+	switch backupSel.Service {
+	case selectors.ServiceExchange:
+		sel := selectors.NewExchangeRestore(selectors.Any())
+		sel.Include(sel.AllData(), sel.TaskFolders(selectors.Any()))
+
+		return sel.Selector, nil
+
+	case selectors.ServiceOneDrive:
+		sel := selectors.NewOneDriveRestore(selectors.Any())
+		sel.Include(sel.AllData())
+
+		return sel.Selector, nil
+
+	case selectors.ServiceSharePoint:
+		sel := selectors.NewSharePointRestore(selectors.Any())
+		sel.Include(sel.AllData())
+
+		return sel.Selector, nil
+	}
+
+	return selectors.Selector{}, errors.Errorf("export not supported for %s backups", backupSel.Service)
+}
+
+func validateExportFlags(output, archive string) error {
+	if len(archive) == 0 {
+		if output == stdoutOutput {
+			return errors.New("only --" + archiveFN + " exports can be written to stdout")
+		}
+
+		return nil
+	}
+
+	for _, f := range export.ArchiveFormats {
+		if archive == string(f) {
+			return nil
+		}
+	}
+
+	return errors.New(archive + " is an unrecognized archive format; use one of: " + archiveFormats())
+}
+
+// exportWriter produces the export writer for the output destination.
+func exportWriter(output, archive string) (export.Writer, error) {
+	if len(archive) == 0 {
+		return export.NewFilesystemWriter(output)
+	}
+
+	var dst io.WriteCloser = nopWriteCloser{os.Stdout}
+
+	if output != stdoutOutput {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating archive file")
+		}
+
+		dst = f
+	}
+
+	return export.NewArchiveWriter(dst, export.ArchiveFormat(archive))
+}
+
+func archiveFormats() string {
+	fs := make([]string, 0, len(export.ArchiveFormats))
+	for _, f := range export.ArchiveFormats {
+		fs = append(fs, string(f))
+	}
+
+	return strings.Join(fs, ", ")
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
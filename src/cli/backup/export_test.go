@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type ExportSuite struct {
+	tester.Suite
+}
+
+func TestExportSuite(t *testing.T) {
+	suite.Run(t, &ExportSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ExportSuite) TestExportCmd() {
+	t := suite.T()
+	c := exportCmd()
+
+	assert.Equal(t, exportCommand, c.Use)
+	tester.AreSameFunc(t, handleExportCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup(outputFN))
+}
+
+func (suite *ExportSuite) TestExportSelector() {
+	table := []struct {
+		name      string
+		sel       selectors.Selector
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"exchange", selectors.NewExchangeBackup(selectors.Any()).Selector, assert.NoError},
+		{"onedrive", selectors.NewOneDriveBackup(selectors.Any()).Selector, assert.NoError},
+		{"sharepoint", selectors.NewSharePointBackup(selectors.Any()).Selector, assert.NoError},
+		{"unknown", selectors.Selector{}, assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			sel, err := exportSelector(test.sel)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			require.NotEmpty(t, sel.Includes)
+			assert.Equal(t, test.sel.Service, sel.Service)
+		})
+	}
+}
+
+func (suite *ExportSuite) TestValidateExportFlags() {
+	table := []struct {
+		name            string
+		output, archive string
+		expect          assert.ErrorAssertionFunc
+	}{
+		{"directory", "./export", "", assert.NoError},
+		{"directory to stdout", stdoutOutput, "", assert.Error},
+		{"zip", "./export.zip", "zip", assert.NoError},
+		{"tar.zst to stdout", stdoutOutput, "tar.zst", assert.NoError},
+		{"unknown archive", "./export.rar", "rar", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), validateExportFlags(test.output, test.archive))
+		})
+	}
+}
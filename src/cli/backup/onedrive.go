@@ -3,7 +3,6 @@ package backup
 import (
 	"context"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -30,7 +29,7 @@ import (
 
 const (
 	oneDriveServiceCommand                 = "onedrive"
-	oneDriveServiceCommandCreateUseSuffix  = "--user <email> | '" + utils.Wildcard + "'"
+	oneDriveServiceCommandCreateUseSuffix  = "--user <email> | '" + utils.Wildcard + "' | --group <name>"
 	oneDriveServiceCommandDeleteUseSuffix  = "--backup <backupId>"
 	oneDriveServiceCommandDetailsUseSuffix = "--backup <backupId>"
 )
@@ -43,7 +42,13 @@ corso backup create onedrive --user alice@example.com
 corso backup create onedrive --user alice@example.com,bob@example.com
 
 # Backup all OneDrive data for all M365 users 
-corso backup create onedrive --user '*'`
+corso backup create onedrive --user '*'
+
+# Backup OneDrive data for the members of the Finance Team group
+corso backup create onedrive --group "Finance Team"
+
+# Backup only the OneDrive folder shared at a link
+corso backup create onedrive --item-url https://contoso-my.sharepoint.com/:f:/g/personal/alice_contoso_com/EaBcD123`
 
 	oneDriveServiceCommandDeleteExamples = `# Delete OneDrive backup with ID 1234abcd-12ab-cd34-56de-1234abcd
 corso backup delete onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd`
@@ -68,6 +73,13 @@ var (
 	fileCreatedBefore  string
 	fileModifiedAfter  string
 	fileModifiedBefore string
+	fileCreatedBy      string
+	fileContentType    string
+	fileLabel          string
+
+	excludeSyncArtifacts bool
+
+	itemURL string
 )
 
 // called by backup.go to map subcommands to provider-specific handling.
@@ -80,15 +92,53 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 	switch cmd.Use {
 	case createCommand:
 		c, fs = utils.AddCommand(cmd, oneDriveCreateCmd())
-		options.AddFeatureToggle(cmd, options.EnablePermissionsBackup())
+		options.AddFeatureToggle(
+			cmd,
+			options.EnablePermissionsBackup(),
+			options.RawDriveEnumeration(),
+			options.DisableHashSkip())
+		options.AddFeatureToggle(c, options.SimulateExpiredDeltas())
 
 		c.Use = c.Use + " " + oneDriveServiceCommandCreateUseSuffix
 		c.Example = oneDriveServiceCommandCreateExamples
 
 		fs.StringSliceVar(&user,
 			utils.UserFN, nil,
-			"Backup OneDrive data by user's email address; accepts '"+utils.Wildcard+"' to select all users. (required)")
+			"Backup OneDrive data by user's email address or display name; accepts '"+utils.Wildcard+"' to select all users.")
+		fs.StringSliceVar(&group,
+			utils.GroupFN, nil,
+			"Backup OneDrive data for the members of an Azure AD group, by group name or ID. "+
+				"Includes the members of nested groups, as of the time of the backup.")
+		fs.StringVar(
+			&itemURL,
+			utils.ItemURLFN, "",
+			"Backup only the file or folder at this OneDrive web url or sharing link.")
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Only backup files created by a user whose email contains this value.")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Only backup files whose content type contains this value.")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Only backup files with this sensitivity label.")
+		fs.BoolVar(
+			&excludeSyncArtifacts,
+			utils.ExcludeSyncArtifactsFN, false,
+			"Skip office lock files (~$*), temp files (*.tmp), and sync-conflict copies (*-DESKTOP-1A2B3C4.*).")
+		options.AddOneDriveBackupPermissionsFlag(c)
 		options.AddOperationFlags(c)
+		addParallelOwnersFlag(c)
+		options.AddTuningFlags(c)
+		options.AddSpillFlags(c)
+		options.AddDeltaCacheFlags(c)
+		options.AddAlertFlags(c)
+		options.AddMemoryFlags(c)
+		options.AddHeartbeatFlags(c)
+		options.AddBackupBandwidthFlag(c)
 
 	case listCommand:
 		c, fs = utils.AddCommand(cmd, oneDriveListCmd())
@@ -96,6 +146,10 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 		fs.StringVar(&backupID,
 			utils.BackupFN, "",
 			"ID of the backup to retrieve.")
+		fs.StringSliceVar(
+			&user,
+			utils.UserFN, nil,
+			"List the backups of users by ID, email address, or display name.")
 
 	case detailsCommand:
 		c, fs = utils.AddCommand(cmd, oneDriveDetailsCmd())
@@ -107,6 +161,11 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 			utils.BackupFN, "",
 			"ID of the backup to explore. (required)")
 		cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+		fs.StringSliceVar(
+			&user,
+			utils.UserFN, nil,
+			"Select backup details by user ID, email address, or display name; "+
+				"accepts '"+utils.Wildcard+"' to select all users.")
 
 		// onedrive hierarchy flags
 
@@ -140,6 +199,21 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 			utils.FileModifiedBeforeFN, "",
 			"Select backup details for files modified before this datetime.")
 
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Select backup details for files created by a user whose email contains this value.")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Select backup details for files whose content type contains this value.")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Select backup details for files with this sensitivity label.")
+
+		addColumnsFlag(c)
+
 	case deleteCommand:
 		c, fs = utils.AddCommand(cmd, oneDriveDeleteCmd())
 
@@ -178,7 +252,7 @@ func createOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if err := validateOneDriveBackupCreateFlags(user); err != nil {
+	if err := validateOneDriveBackupCreateFlags(user, group, itemURL); err != nil {
 		return err
 	}
 
@@ -194,65 +268,66 @@ func createOneDriveCmd(cmd *cobra.Command, args []string) error {
 
 	defer utils.CloseRepo(ctx, r)
 
-	sel := oneDriveBackupCreateSelectors(user)
-
-	// TODO: log/print recoverable errors
-	errs := fault.New(false)
-
-	users, err := m365.UserPNs(ctx, acct, errs)
-	if err != nil {
-		return Only(ctx, errors.Wrap(err, "Failed to retrieve M365 users"))
-	}
-
 	var (
-		merrs *multierror.Error
-		bIDs  []model.StableID
+		sel   *selectors.OneDriveBackup
+		users []string
 	)
 
-	for _, discSel := range sel.SplitByResourceOwner(users) {
-		bo, err := r.NewBackup(ctx, discSel.Selector)
+	if len(itemURL) > 0 {
+		ref, err := utils.ResolveItemURL(ctx, acct, itemURL, path.OneDriveService)
 		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to initialize OneDrive backup for user %s",
-				discSel.DiscreteOwner,
-			))
+			return Only(ctx, err)
+		}
+
+		sel = oneDriveItemBackupSelectors(*ref)
+		users = []string{ref.Owner}
+	} else {
+		// TODO: log/print recoverable errors
+		errs := fault.New(false)
 
-			continue
+		all, err := m365.Users(ctx, acct, errs)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to retrieve M365 users"))
 		}
 
-		err = bo.Run(ctx)
+		resolved, err := utils.ResolveUserNames(user, all)
 		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to run OneDrive backup for user %s",
-				discSel.DiscreteOwner,
-			))
+			return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+		}
 
-			continue
+		sel = oneDriveBackupCreateSelectors(resolved)
+		sel.IncludeOwnerGroups(group...)
+
+		if err := resolveOwnerGroups(ctx, acct, &sel.Selector, errs); err != nil {
+			return Only(ctx, err)
 		}
 
-		bIDs = append(bIDs, bo.Results.BackupID)
+		users = m365.PrincipalNames(all)
 	}
 
-	bups, ferrs := r.Backups(ctx, bIDs)
-	// TODO: print/log recoverable errors
-	if ferrs.Err() != nil {
-		return Only(ctx, errors.Wrap(ferrs.Err(), "Unable to retrieve backup results from storage"))
-	}
+	filterOneDriveBackup(sel, fileCreatedBy, fileContentType, fileLabel, excludeSyncArtifacts)
 
-	backup.PrintAll(ctx, bups)
+	discSels := sel.SplitByResourceOwner(users)
+	sels := make([]selectors.Selector, 0, len(discSels))
 
-	if e := merrs.ErrorOrNil(); e != nil {
-		return Only(ctx, e)
+	for _, discSel := range discSels {
+		sels = append(sels, discSel.Selector)
 	}
 
-	return nil
+	return runBackups(ctx, r, "OneDrive", "user", sels)
 }
 
-func validateOneDriveBackupCreateFlags(users []string) error {
-	if len(users) == 0 {
-		return errors.New("requires one or more --user ids or the wildcard --user *")
+func validateOneDriveBackupCreateFlags(users, groups []string, itemURL string) error {
+	if len(itemURL) > 0 {
+		if len(users) > 0 || len(groups) > 0 {
+			return errors.New("--" + utils.ItemURLFN + " can't be combined with --user or --group")
+		}
+
+		return nil
+	}
+
+	if len(users) == 0 && len(groups) == 0 {
+		return errors.New("requires one or more --user ids, the wildcard --user *, a --group, or an --item-url")
 	}
 
 	return nil
@@ -265,6 +340,53 @@ func oneDriveBackupCreateSelectors(users []string) *selectors.OneDriveBackup {
 	return sel
 }
 
+// oneDriveItemBackupSelectors selects only the file or folder identified
+// by an item url.
+func oneDriveItemBackupSelectors(ref m365.DriveItemRef) *selectors.OneDriveBackup {
+	sel := selectors.NewOneDriveBackup([]string{ref.Owner})
+
+	switch {
+	case ref.IsFolder && len(ref.FolderPath()) == 0:
+		sel.Include(sel.AllData())
+
+	case ref.IsFolder:
+		sel.Include(sel.Folders([]string{ref.FolderPath()}, selectors.PrefixMatch()))
+
+	default:
+		folders := []string{ref.ParentPath}
+		if len(ref.ParentPath) == 0 {
+			folders = selectors.Any()
+		}
+
+		sel.Include(sel.Items(folders, []string{ref.ItemID}))
+	}
+
+	return sel
+}
+
+// filterOneDriveBackup adds the file filters and exclusions to the selector.
+func filterOneDriveBackup(
+	sel *selectors.OneDriveBackup,
+	createdBy, contentType, label string,
+	excludeArtifacts bool,
+) {
+	if len(createdBy) > 0 {
+		sel.Filter(sel.CreatedBy(createdBy))
+	}
+
+	if len(contentType) > 0 {
+		sel.Filter(sel.ContentType(contentType))
+	}
+
+	if len(label) > 0 {
+		sel.Filter(sel.SensitivityLabel(label))
+	}
+
+	if excludeArtifacts {
+		sel.Exclude(sel.SyncArtifacts())
+	}
+}
+
 // ------------------------------------------------------------------------------------------------
 // backup list
 // ------------------------------------------------------------------------------------------------
@@ -288,6 +410,11 @@ func listOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	owners, err := utils.ResolveBackedUpUserNames(ctx, acct, user)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, acct, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -315,7 +442,7 @@ func listOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to list backups in the repository"))
 	}
 
-	backup.PrintAll(ctx, bs)
+	backup.PrintAll(ctx, backupsOwnedBy(bs, owners))
 
 	return nil
 }
@@ -343,11 +470,20 @@ func detailsOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if err := details.ValidateColumns(columns); err != nil {
+		return Only(ctx, err)
+	}
+
 	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
 	if err != nil {
 		return Only(ctx, err)
 	}
 
+	users, err := utils.ResolveBackedUpUserNames(ctx, acct, user)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, acct, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -356,13 +492,16 @@ func detailsOneDriveCmd(cmd *cobra.Command, args []string) error {
 	defer utils.CloseRepo(ctx, r)
 
 	opts := utils.OneDriveOpts{
-		Users:              user,
+		Users:              users,
 		Paths:              folderPaths,
 		Names:              fileNames,
 		FileCreatedAfter:   fileCreatedAfter,
 		FileCreatedBefore:  fileCreatedBefore,
 		FileModifiedAfter:  fileModifiedAfter,
 		FileModifiedBefore: fileModifiedBefore,
+		FileCreatedBy:      fileCreatedBy,
+		FileContentType:    fileContentType,
+		FileLabel:          fileLabel,
 
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
@@ -372,12 +511,14 @@ func detailsOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	printNotes(ctx, r, backupID)
+
 	if len(ds.Entries) == 0 {
 		Info(ctx, selectors.ErrorNoMatchingItems)
 		return nil
 	}
 
-	ds.PrintEntries(ctx)
+	ds.PrintEntries(ctx, columns...)
 
 	return nil
 }
@@ -79,7 +79,9 @@ func (suite *NoBackupOneDriveIntegrationSuite) SetupSuite() {
 		suite.acct,
 		suite.st,
 		control.Options{
-			ToggleFeatures: control.Toggles{EnablePermissionsBackup: true},
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Backup: true},
+			},
 		})
 	require.NoError(t, err)
 }
@@ -164,7 +166,9 @@ func (suite *BackupDeleteOneDriveIntegrationSuite) SetupSuite() {
 		suite.acct,
 		suite.st,
 		control.Options{
-			ToggleFeatures: control.Toggles{EnablePermissionsBackup: true},
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Backup: true},
+			},
 		})
 	require.NoError(t, err)
 
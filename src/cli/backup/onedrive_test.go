@@ -10,6 +10,8 @@ import (
 
 	"github.com/alcionai/corso/src/cli/utils/testdata"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 type OneDriveSuite struct {
@@ -67,11 +69,21 @@ func (suite *OneDriveSuite) TestAddOneDriveCommands() {
 	}
 }
 
+func (suite *OneDriveSuite) TestCreateSimulateExpiredDeltasFlag() {
+	cmd := &cobra.Command{Use: createCommand}
+
+	c := addOneDriveCommands(cmd)
+	require.NotNil(suite.T(), c)
+
+	assert.NotNil(suite.T(), c.Flag("simulate-expired-deltas"))
+}
+
 func (suite *OneDriveSuite) TestValidateOneDriveBackupCreateFlags() {
 	table := []struct {
-		name   string
-		user   []string
-		expect assert.ErrorAssertionFunc
+		name        string
+		user, group []string
+		itemURL     string
+		expect      assert.ErrorAssertionFunc
 	}{
 		{
 			name:   "no users",
@@ -82,10 +94,79 @@ func (suite *OneDriveSuite) TestValidateOneDriveBackupCreateFlags() {
 			user:   []string{"fnord"},
 			expect: assert.NoError,
 		},
+		{
+			name:   "groups",
+			group:  []string{"Finance Team"},
+			expect: assert.NoError,
+		},
+		{
+			name:    "item url",
+			itemURL: "https://contoso-my.sharepoint.com/personal/alice/Documents/Reports",
+			expect:  assert.NoError,
+		},
+		{
+			name:    "item url and users",
+			user:    []string{"fnord"},
+			itemURL: "https://contoso-my.sharepoint.com/personal/alice/Documents/Reports",
+			expect:  assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), validateOneDriveBackupCreateFlags(test.user, test.group, test.itemURL))
+		})
+	}
+}
+
+func (suite *OneDriveSuite) TestOneDriveItemBackupSelectors() {
+	table := []struct {
+		name         string
+		ref          m365.DriveItemRef
+		expectFolder string
+		expectItem   string
+	}{
+		{
+			name:         "drive root",
+			ref:          m365.DriveItemRef{Owner: "uid", ItemID: "root-id", IsFolder: true, IsRoot: true},
+			expectFolder: "Reports",
+			expectItem:   "any-id",
+		},
+		{
+			name:         "folder",
+			ref:          m365.DriveItemRef{Owner: "uid", ItemID: "fid", Name: "2023", ParentPath: "Reports", IsFolder: true},
+			expectFolder: "Reports/2023/Q1",
+			expectItem:   "any-id",
+		},
+		{
+			name:         "file",
+			ref:          m365.DriveItemRef{Owner: "uid", ItemID: "iid", Name: "plan.docx", ParentPath: "Reports"},
+			expectFolder: "Reports",
+			expectItem:   "iid",
+		},
+		{
+			name:         "file in root",
+			ref:          m365.DriveItemRef{Owner: "uid", ItemID: "iid", Name: "plan.docx"},
+			expectFolder: "Anywhere",
+			expectItem:   "iid",
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
-			test.expect(suite.T(), validateOneDriveBackupCreateFlags(test.user))
+			t := suite.T()
+
+			sel := oneDriveItemBackupSelectors(test.ref)
+			assert.Equal(t, []string{"uid"}, sel.DiscreteResourceOwners())
+
+			scopes := sel.Scopes()
+			require.Len(t, scopes, 1)
+
+			scope := scopes[0]
+			assert.True(t, scope.Matches(selectors.OneDriveFolder, test.expectFolder), "folder")
+			assert.True(t, scope.Matches(selectors.OneDriveItem, test.expectItem), "item")
+
+			if !test.ref.IsFolder {
+				assert.False(t, scope.Matches(selectors.OneDriveItem, "other-id"), "other items")
+			}
 		})
 	}
 }
@@ -0,0 +1,417 @@
+package backup
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alcionai/clues"
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+// preview flag values
+var (
+	previewBackupID string
+	previewItemRef  string
+	previewLines    int
+	previewBytes    int
+)
+
+const (
+	previewCommand = "preview"
+	itemFN         = "item"
+	linesFN        = "lines"
+	bytesFN        = "bytes"
+)
+
+const previewCommandExamples = `# Preview item 5d5e8f1b in backup 1234abcd-12ab-cd34-56de-1234abcd
+# (item IDs are listed by 'corso backup details')
+corso backup preview --backup 1234abcd-12ab-cd34-56de-1234abcd --item 5d5e8f1b
+
+# Preview the first 50 lines of the item
+corso backup preview --backup 1234abcd-12ab-cd34-56de-1234abcd --item 5d5e8f1b --lines 50`
+
+// The backup preview subcommand.
+// `corso backup preview --backup <backupId> --item <ref>`
+func previewCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   previewCommand,
+		Short: "Preview an item in a backup",
+		Long: `Preview a single item in a backup without restoring it.  Mail shows its headers
+and the first lines of its body.  Files show their detected content type along with
+the first lines of text files, or a hex dump of the first bytes of binary files.
+Only as much of the item as the preview needs is read from the repository.`,
+		RunE:    handlePreviewCmd,
+		Args:    cobra.NoArgs,
+		Example: previewCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&previewBackupID,
+		utils.BackupFN, "",
+		"ID of the backup containing the item. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&previewItemRef,
+		itemFN, "",
+		"ID of the item to preview, as shown by 'corso backup details'. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(itemFN))
+
+	fs.IntVar(
+		&previewLines,
+		linesFN, 20,
+		"Maximum number of lines of text to show.")
+
+	fs.IntVar(
+		&previewBytes,
+		bytesFN, 4096,
+		"Maximum number of bytes to read from the head of a file.")
+
+	return c
+}
+
+// Handler for calls to `corso backup preview`.
+func handlePreviewCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if previewLines < 1 || previewBytes < 1 {
+		return Only(ctx, errors.New("--"+linesFN+" and --"+bytesFN+" must be positive"))
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	var ip itemPreview
+
+	err = r.FetchItem(
+		ctx,
+		previewBackupID,
+		previewItemRef,
+		func(ctx context.Context, ent details.DetailsEntry, rdr io.Reader) error {
+			var err error
+
+			ip, err = previewItem(ent, rdr, previewLines, previewBytes)
+
+			return err
+		})
+	if err != nil {
+		return Only(ctx, errors.Wrapf(
+			err,
+			"Failed to preview item %s in backup %s",
+			previewItemRef,
+			previewBackupID))
+	}
+
+	ip.print(ctx)
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// previews
+// ---------------------------------------------------------------------------
+
+type previewProp struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// itemPreview is the readable summary of an item's head.  Text content is
+// held as Lines, while binary content is held as a Hex dump.
+type itemPreview struct {
+	ID          string        `json:"id"`
+	ContentType string        `json:"contentType"`
+	Props       []previewProp `json:"properties,omitempty"`
+	Lines       []string      `json:"lines,omitempty"`
+	Hex         string        `json:"hex,omitempty"`
+	Truncated   bool          `json:"truncated"`
+}
+
+func (ip itemPreview) MinimumPrintable() any {
+	return ip
+}
+
+func (ip itemPreview) Headers() []string {
+	hs := make([]string, 0, len(ip.Props))
+	for _, h := range ip.Props {
+		hs = append(hs, h.Name)
+	}
+
+	return hs
+}
+
+func (ip itemPreview) Values() []string {
+	vs := make([]string, 0, len(ip.Props))
+	for _, h := range ip.Props {
+		vs = append(vs, h.Value)
+	}
+
+	return vs
+}
+
+func (ip itemPreview) print(ctx context.Context) {
+	if JSONFormat() {
+		Item(ctx, ip)
+		return
+	}
+
+	for _, h := range ip.Props {
+		Outf(ctx, "%s: %s", h.Name, h.Value)
+	}
+
+	Out(ctx, "")
+
+	for _, l := range ip.Lines {
+		Out(ctx, l)
+	}
+
+	if len(ip.Hex) > 0 {
+		Out(ctx, strings.TrimSuffix(ip.Hex, "\n"))
+	}
+
+	if ip.Truncated {
+		Info(ctx, "...")
+	}
+}
+
+// previewItem reads just enough of the item to produce its preview.
+func previewItem(ent details.DetailsEntry, r io.Reader, lines, maxBytes int) (itemPreview, error) {
+	if ent.Exchange != nil && ent.Exchange.ItemType == details.ExchangeMail {
+		return previewMail(ent, r, lines)
+	}
+
+	return previewFile(ent, r, lines, maxBytes)
+}
+
+// previewMail shows the mail's headers, as recorded in its details entry,
+// along with the first lines of its body.
+func previewMail(ent details.DetailsEntry, r io.Reader, lines int) (itemPreview, error) {
+	info := ent.Exchange
+	ip := itemPreview{
+		ID:          ent.ShortRef,
+		ContentType: "message/rfc822",
+		Props: []previewProp{
+			{"From", info.Sender},
+			{"Subject", info.Subject},
+			{"Received", info.Received.Format(http.TimeFormat)},
+		},
+	}
+
+	if len(info.MessageID) > 0 {
+		ip.Props = append(ip.Props, previewProp{"Message-ID", info.MessageID})
+	}
+
+	body, err := mailBody(r)
+	if err != nil {
+		return itemPreview{}, clues.Wrap(err, "reading mail body")
+	}
+
+	ip.Lines, ip.Truncated = firstLines(body, lines)
+
+	return ip, nil
+}
+
+type mailBodyContent struct {
+	Content     string `json:"content"`
+	ContentType string `json:"contentType"`
+}
+
+// mailBody produces the plain text of the body of the serialized message.
+// The message is decoded only up to its body, leaving the remainder of
+// the message unread.
+func mailBody(r io.Reader) (string, error) {
+	dec := json.NewDecoder(r)
+
+	if t, err := dec.Token(); err != nil {
+		return "", clues.Stack(err)
+	} else if d, ok := t.(json.Delim); !ok || d != '{' {
+		return "", clues.New("message is not a json object")
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return "", clues.Stack(err)
+		}
+
+		if key, _ := t.(string); key != "body" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return "", clues.Stack(err)
+			}
+
+			continue
+		}
+
+		var body mailBodyContent
+		if err := dec.Decode(&body); err != nil {
+			return "", clues.Stack(err)
+		}
+
+		if strings.EqualFold(body.ContentType, "html") {
+			return htmlText(body.Content), nil
+		}
+
+		return body.Content, nil
+	}
+
+	return "", nil
+}
+
+var (
+	htmlSkipRE  = regexp.MustCompile(`(?is)<(head|style|script)\b.*?</(head|style|script)>`)
+	htmlBreakRE = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/li|/h[1-6])\b[^>]*>`)
+	htmlTagRE   = regexp.MustCompile(`<[^>]*>`)
+	blankRunRE  = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// htmlText reduces an html document to readable plain text.
+func htmlText(s string) string {
+	s = htmlSkipRE.ReplaceAllString(s, "")
+	s = htmlBreakRE.ReplaceAllString(s, "\n")
+	s = htmlTagRE.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = blankRunRE.ReplaceAllString(s, "\n\n")
+
+	return strings.TrimSpace(s)
+}
+
+// previewFile reads up to maxBytes from the head of the file.  Text is
+// shown as its first lines, and anything else as a hex dump.
+func previewFile(ent details.DetailsEntry, r io.Reader, lines, maxBytes int) (itemPreview, error) {
+	head := make([]byte, maxBytes)
+
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return itemPreview{}, clues.Wrap(err, "reading item head")
+	}
+
+	head = head[:n]
+
+	var (
+		name, size = fileNameAndSize(ent)
+		ct         = contentType(name, head)
+		ip         = itemPreview{
+			ID:          ent.ShortRef,
+			ContentType: ct,
+			Truncated:   size > int64(n),
+		}
+	)
+
+	if len(name) > 0 {
+		ip.Props = append(ip.Props, previewProp{"Name", name})
+	}
+
+	ip.Props = append(
+		ip.Props,
+		previewProp{"Content-Type", ct},
+		previewProp{"Size", humanize.Bytes(uint64(size))})
+
+	if !isText(ct, head) {
+		ip.Hex = hex.Dump(head)
+		return ip, nil
+	}
+
+	// a multi-byte rune may be split by the end of the head.
+	text := string(head)
+	if ip.Truncated {
+		text = strings.ToValidUTF8(text, "")
+	}
+
+	ls, more := firstLines(text, lines)
+	ip.Lines = ls
+	ip.Truncated = ip.Truncated || more
+
+	return ip, nil
+}
+
+// fileNameAndSize produces the name and size of the item, as recorded
+// in its details entry.
+func fileNameAndSize(ent details.DetailsEntry) (string, int64) {
+	switch {
+	case ent.OneDrive != nil:
+		return ent.OneDrive.ItemName, ent.OneDrive.Size
+	case ent.SharePoint != nil:
+		return ent.SharePoint.ItemName, ent.SharePoint.Size
+	case ent.Exchange != nil:
+		return "", ent.Exchange.Size
+	default:
+		return "", 0
+	}
+}
+
+// contentType detects the content type from the head of the data, falling
+// back to the file's extension when the content isn't recognized.
+func contentType(name string, head []byte) string {
+	ct := http.DetectContentType(head)
+	if ct != "application/octet-stream" {
+		return ct
+	}
+
+	if byExt := mime.TypeByExtension(filepath.Ext(name)); len(byExt) > 0 {
+		return byExt
+	}
+
+	return ct
+}
+
+func isText(ct string, head []byte) bool {
+	mt, _, _ := mime.ParseMediaType(ct)
+
+	switch {
+	case strings.HasPrefix(mt, "text/"),
+		strings.HasSuffix(mt, "json"),
+		strings.HasSuffix(mt, "xml"):
+		return true
+	case mt == "application/octet-stream":
+		// unrecognized content is treated as text if it decodes as such.
+		return utf8.Valid(head) && !strings.ContainsRune(string(head), 0)
+	default:
+		return false
+	}
+}
+
+// firstLines produces up to n lines of the text, and whether any lines
+// were left out.
+func firstLines(text string, n int) ([]string, bool) {
+	text = strings.TrimRight(text, "\n")
+	if len(text) == 0 {
+		return nil, false
+	}
+
+	ls := strings.SplitN(text, "\n", n+1)
+	if len(ls) > n {
+		return ls[:n], true
+	}
+
+	return ls, false
+}
@@ -0,0 +1,185 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+)
+
+type PreviewSuite struct {
+	tester.Suite
+}
+
+func TestPreviewSuite(t *testing.T) {
+	suite.Run(t, &PreviewSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *PreviewSuite) TestPreviewCmd() {
+	t := suite.T()
+	c := previewCmd()
+
+	assert.Equal(t, previewCommand, c.Use)
+	tester.AreSameFunc(t, handlePreviewCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup(itemFN))
+	assert.NotNil(t, c.Flags().Lookup(linesFN))
+	assert.NotNil(t, c.Flags().Lookup(bytesFN))
+}
+
+func (suite *PreviewSuite) TestMailBody() {
+	table := []struct {
+		name   string
+		msg    string
+		expect string
+	}{
+		{
+			name:   "text",
+			msg:    `{"subject":"hi","body":{"contentType":"text","content":"hello\nthere"}}`,
+			expect: "hello\nthere",
+		},
+		{
+			name: "html after attachments",
+			msg: `{"attachments":[{"name":"a.txt","contentBytes":"aGVsbG8="}],` +
+				`"body":{"contentType":"html","content":"<html><head><style>p{}</style></head>` +
+				`<body><p>hello &amp; welcome</p><p>bye</p></body></html>"}}`,
+			expect: "hello & welcome\nbye",
+		},
+		{
+			name: "no body",
+			msg:  `{"subject":"hi"}`,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			body, err := mailBody(strings.NewReader(test.msg))
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, body)
+		})
+	}
+}
+
+func (suite *PreviewSuite) TestMailBody_stopsAtBody() {
+	t := suite.T()
+
+	// anything following the body is never read, so it can't break decoding.
+	r := strings.NewReader(`{"body":{"contentType":"text","content":"hello"},"subject":<<not json>>`)
+
+	body, err := mailBody(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", body)
+}
+
+func (suite *PreviewSuite) TestPreviewItem() {
+	var (
+		text   = "line 1\nline 2\nline 3\n"
+		binary = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...)
+	)
+
+	table := []struct {
+		name            string
+		ent             details.DetailsEntry
+		data            []byte
+		lines, bytes    int
+		expectType      string
+		expectLines     []string
+		expectHex       bool
+		expectTruncated bool
+	}{
+		{
+			name: "mail",
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{Exchange: &details.ExchangeInfo{
+				ItemType: details.ExchangeMail,
+				Subject:  "hi",
+			}}},
+			data:        []byte(`{"body":{"contentType":"text","content":"line 1\nline 2\nline 3"}}`),
+			lines:       2,
+			bytes:       10,
+			expectType:  "message/rfc822",
+			expectLines: []string{"line 1", "line 2"},
+			// more lines remain in the body.
+			expectTruncated: true,
+		},
+		{
+			name: "text file",
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				ItemName: "notes.txt",
+				Size:     int64(len(text)),
+			}}},
+			data:        []byte(text),
+			lines:       5,
+			bytes:       1024,
+			expectType:  "text/plain; charset=utf-8",
+			expectLines: []string{"line 1", "line 2", "line 3"},
+		},
+		{
+			name: "text file head",
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{SharePoint: &details.SharePointInfo{
+				ItemName: "notes.txt",
+				Size:     int64(len(text)),
+			}}},
+			data:            []byte(text),
+			lines:           5,
+			bytes:           9,
+			expectType:      "text/plain; charset=utf-8",
+			expectLines:     []string{"line 1", "li"},
+			expectTruncated: true,
+		},
+		{
+			name: "binary file",
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				ItemName: "image.png",
+				Size:     int64(len(binary)),
+			}}},
+			data:       binary,
+			lines:      5,
+			bytes:      1024,
+			expectType: "image/png",
+			expectHex:  true,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ip, err := previewItem(test.ent, bytes.NewReader(test.data), test.lines, test.bytes)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectType, ip.ContentType)
+			assert.Equal(t, test.expectLines, ip.Lines)
+			assert.Equal(t, test.expectHex, len(ip.Hex) > 0)
+			assert.Equal(t, test.expectTruncated, ip.Truncated)
+		})
+	}
+}
+
+func (suite *PreviewSuite) TestFirstLines() {
+	table := []struct {
+		name       string
+		text       string
+		n          int
+		expect     []string
+		expectMore bool
+	}{
+		{"empty", "", 2, nil, false},
+		{"fewer", "a\nb\n", 3, []string{"a", "b"}, false},
+		{"exact", "a\nb", 2, []string{"a", "b"}, false},
+		{"more", "a\nb\nc", 2, []string{"a", "b"}, true},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ls, more := firstLines(test.text, test.n)
+			assert.Equal(t, test.expect, ls)
+			assert.Equal(t, test.expectMore, more)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+// report flag values
+var (
+	reportBackupID string
+	reportBaseID   string
+	reportFormat   string
+	reportOutput   string
+)
+
+const (
+	reportCommand = "report"
+	baseFN        = "base"
+	formatFN      = "format"
+)
+
+const reportCommandExamples = `# Summarize the changes from backup 1234abcd-12ab-cd34-56de-1234abcd to a later backup
+corso backup report --base 1234abcd-12ab-cd34-56de-1234abcd --backup 5678abcd-12ab-cd34-56de-1234abcd
+
+# Write the same summary as an html page, for attaching to a change ticket
+corso backup report --base 1234abcd-12ab-cd34-56de-1234abcd --backup 5678abcd-12ab-cd34-56de-1234abcd \
+    --format html --output ./changes.html`
+
+// The backup report subcommand.
+// `corso backup report --base <backupId> --backup <backupId> [--format <format>] [--output <file>]`
+func reportCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   reportCommand,
+		Short: "Report the changes between two backups",
+		Long: `Produce a human-readable report of the items that were added, changed, or
+deleted between two backups, grouped by folder.  Items are compared by their
+storage path, so moved items are reported as deleted from their old folder and
+added to their new one.`,
+		RunE:    handleReportCmd,
+		Args:    cobra.NoArgs,
+		Example: reportCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&reportBaseID,
+		baseFN, "",
+		"ID of the earlier backup, against which changes are reported. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(baseFN))
+
+	fs.StringVar(
+		&reportBackupID,
+		utils.BackupFN, "",
+		"ID of the later backup, whose changes are reported. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&reportFormat,
+		formatFN, string(details.MarkdownReport),
+		"Format of the report: "+reportFormats()+".")
+
+	fs.StringVar(
+		&reportOutput,
+		outputFN, "",
+		"File to write the report into; writes to stdout if unset.")
+
+	return c
+}
+
+// Handler for calls to `corso backup report`.
+func handleReportCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if err := validateReportFormat(reportFormat); err != nil {
+		return Only(ctx, err)
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	base, err := reportDetails(ctx, r, reportBaseID)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	target, err := reportDetails(ctx, r, reportBackupID)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	cr, err := details.NewChangeReport(reportBaseID, reportBackupID, target.Diff(base))
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to build the change report"))
+	}
+
+	var w io.Writer = os.Stdout
+
+	if len(reportOutput) > 0 {
+		f, err := os.OpenFile(reportOutput, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to create the report file"))
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := cr.Write(w, details.ReportFormat(reportFormat)); err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to write the change report"))
+	}
+
+	if len(reportOutput) > 0 {
+		Infof(ctx, "Wrote the change report to %s", reportOutput)
+	}
+
+	return nil
+}
+
+// reportDetails retrieves the details of the backup.
+func reportDetails(ctx context.Context, r repository.BackupGetter, backupID string) (details.DetailsModel, error) {
+	d, _, errs := r.BackupDetails(ctx, backupID)
+	if errs.Err() != nil {
+		if errors.Is(errs.Err(), data.ErrNotFound) {
+			return details.DetailsModel{}, errors.Errorf("No backup exists with the id %s", backupID)
+		}
+
+		return details.DetailsModel{}, errors.Wrap(errs.Err(), "Failed to get backup details in the repository")
+	}
+
+	return d.DetailsModel, nil
+}
+
+func validateReportFormat(format string) error {
+	for _, f := range details.ReportFormats {
+		if format == string(f) {
+			return nil
+		}
+	}
+
+	return errors.New(format + " is an unrecognized report format; use one of: " + reportFormats())
+}
+
+func reportFormats() string {
+	fs := make([]string, 0, len(details.ReportFormats))
+	for _, f := range details.ReportFormats {
+		fs = append(fs, string(f))
+	}
+
+	return strings.Join(fs, ", ")
+}
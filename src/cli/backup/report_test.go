@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ReportSuite struct {
+	tester.Suite
+}
+
+func TestReportSuite(t *testing.T) {
+	suite.Run(t, &ReportSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ReportSuite) TestReportCmd() {
+	t := suite.T()
+	c := reportCmd()
+
+	assert.Equal(t, reportCommand, c.Use)
+	tester.AreSameFunc(t, handleReportCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup("backup"))
+	assert.NotNil(t, c.Flags().Lookup(baseFN))
+	assert.NotNil(t, c.Flags().Lookup(outputFN))
+
+	format := c.Flags().Lookup(formatFN)
+	if assert.NotNil(t, format) {
+		assert.Equal(t, "markdown", format.DefValue)
+	}
+}
+
+func (suite *ReportSuite) TestValidateReportFormat() {
+	table := []struct {
+		format    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"markdown", assert.NoError},
+		{"html", assert.NoError},
+		{"pdf", assert.Error},
+		{"", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.format, func() {
+			test.expectErr(suite.T(), validateReportFormat(test.format))
+		})
+	}
+}
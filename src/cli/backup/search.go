@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// search flag names
+const (
+	queryFN   = "query"
+	serviceFN = "service"
+	ownerFN   = "owner"
+	typeFN    = "type"
+)
+
+// search flag values
+var (
+	searchQuery   string
+	searchService string
+	searchOwner   string
+	searchType    string
+)
+
+const searchCommand = "search"
+
+const searchCommandExamples = `# Find every backed up item that mentions "invoice" and "2023"
+corso backup search --query "invoice 2023"
+
+# Only search the OneDrive backups of Bob
+corso backup search --query "invoice 2023" --service onedrive --owner bob@example.com
+
+# Only list matching mail
+corso backup search --query "invoice 2023" --type mail`
+
+// The backup search subcommand.
+// `corso backup search --query <terms> [--service <service>] [--owner <owner>]`
+func searchCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   searchCommand,
+		Short: "Search the items of all backups",
+		Long: `Search the details of every backup in the repository for items that contain all
+of the query's terms, ignoring case, within their names, locations, or other properties.
+Each matching item is listed once, along with the most recent backup that holds it and
+the number of backups that hold it.  The matches are then counted by service, owner,
+and item type, which can narrow the search down with --service, --owner, and --type.
+
+There is no search index: the search reads the details of each backup in turn, so
+narrowing it down by service and owner makes it faster.`,
+		RunE:    handleSearchCmd,
+		Args:    cobra.NoArgs,
+		Example: searchCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&searchQuery,
+		queryFN, "",
+		"Terms that matching items must contain. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(queryFN))
+
+	fs.StringVar(
+		&searchService,
+		serviceFN, "",
+		"Only search the backups of this service: exchange, onedrive, or sharepoint.")
+
+	fs.StringVar(
+		&searchOwner,
+		ownerFN, "",
+		"Only search the backups of this resource owner.")
+
+	fs.StringVar(
+		&searchType,
+		typeFN, "",
+		"Only list items of this type: mail, events, contacts, tasks, files, or site items.")
+
+	return c
+}
+
+// Handler for calls to `corso backup search`.
+func handleSearchCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	terms := backup.SearchTerms(searchQuery)
+	if len(terms) == 0 {
+		return Only(ctx, errors.New("A --query with at least one term must be provided"))
+	}
+
+	filters, err := searchFilters(searchService)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	bs, err := r.BackupsByTag(ctx, filters...)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to list backups in the repository"))
+	}
+
+	ms := []backup.SearchMatch{}
+
+	for _, b := range bs {
+		if len(searchOwner) > 0 && !strings.EqualFold(b.Selector.DiscreteOwner, searchOwner) {
+			continue
+		}
+
+		deets, _, errs := r.BackupDetails(ctx, string(b.ID))
+		if errs.Err() != nil {
+			Infof(ctx, "Skipping backup %s: failed to get its details: %v", b.ID, errs.Err())
+			continue
+		}
+
+		for _, m := range b.Search(terms, deets) {
+			if len(searchType) == 0 || strings.EqualFold(m.Type(), searchType) {
+				ms = append(ms, m)
+			}
+		}
+	}
+
+	ms = backup.DedupeMatches(ms)
+
+	if len(ms) == 0 {
+		Info(ctx, "No backed up items match the query")
+		return nil
+	}
+
+	ps := make([]Printable, 0, len(ms))
+
+	for _, m := range ms {
+		ps = append(ps, m)
+	}
+
+	Table(ctx, ps)
+
+	f := backup.Facets(ms)
+
+	Info(ctx, "\nMatches by service: "+facetCounts(f.Services))
+	Info(ctx, "Matches by owner:   "+facetCounts(f.Owners))
+	Info(ctx, "Matches by type:    "+facetCounts(f.Types))
+
+	return nil
+}
+
+// facetCounts lists the values of a facet, most frequent first, along
+// with their counts.
+func facetCounts(counts map[string]int) string {
+	vs := maps.Keys(counts)
+
+	sort.Slice(vs, func(i, j int) bool {
+		if counts[vs[i]] != counts[vs[j]] {
+			return counts[vs[i]] > counts[vs[j]]
+		}
+
+		return vs[i] < vs[j]
+	})
+
+	ss := make([]string, 0, len(vs))
+
+	for _, v := range vs {
+		ss = append(ss, fmt.Sprintf("%s (%d)", v, counts[v]))
+	}
+
+	return strings.Join(ss, ", ")
+}
+
+// searchFilters produces the backup filters for the service, if one
+// was provided.
+func searchFilters(service string) ([]store.FilterOption, error) {
+	switch strings.ToLower(service) {
+	case "":
+		return nil, nil
+	case "exchange":
+		return []store.FilterOption{store.Service(path.ExchangeService)}, nil
+	case "onedrive":
+		return []store.FilterOption{store.Service(path.OneDriveService)}, nil
+	case "sharepoint":
+		return []store.FilterOption{store.Service(path.SharePointService)}, nil
+	}
+
+	return nil, errors.Errorf("Unknown --service %q: expected exchange, onedrive, or sharepoint", service)
+}
@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type SearchSuite struct {
+	tester.Suite
+}
+
+func TestSearchSuite(t *testing.T) {
+	suite.Run(t, &SearchSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *SearchSuite) TestSearchCmd() {
+	t := suite.T()
+	c := searchCmd()
+
+	assert.Equal(t, searchCommand, c.Use)
+	tester.AreSameFunc(t, handleSearchCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup(queryFN))
+	assert.NotNil(t, c.Flags().Lookup(serviceFN))
+	assert.NotNil(t, c.Flags().Lookup(ownerFN))
+	assert.NotNil(t, c.Flags().Lookup(typeFN))
+}
+
+func (suite *SearchSuite) TestFacetCounts() {
+	t := suite.T()
+
+	assert.Equal(t, "mail (3), events (1), files (1)", facetCounts(map[string]int{"files": 1, "mail": 3, "events": 1}))
+	assert.Empty(t, facetCounts(map[string]int{}))
+}
+
+func (suite *SearchSuite) TestSearchFilters() {
+	table := []struct {
+		service   string
+		expectLen int
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"", 0, assert.NoError},
+		{"exchange", 1, assert.NoError},
+		{"OneDrive", 1, assert.NoError},
+		{"sharepoint", 1, assert.NoError},
+		{"teams", 0, assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.service, func() {
+			t := suite.T()
+
+			fs, err := searchFilters(test.service)
+			test.expectErr(t, err)
+			assert.Len(t, fs, test.expectLen)
+		})
+	}
+}
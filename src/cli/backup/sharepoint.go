@@ -3,7 +3,6 @@ package backup
 import (
 	"context"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -22,6 +21,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/repository"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 	"github.com/alcionai/corso/src/pkg/store"
 )
 
@@ -63,7 +63,10 @@ corso backup create sharepoint --site <site_id_1>,<site_id_2>
 # TODO: Site IDs may contain commas.  We'll need to warn the site about escaping them.
 
 # Backup all SharePoint data for all sites
-corso backup create sharepoint --site '*'`
+corso backup create sharepoint --site '*'
+
+# Backup only the library folder at a url
+corso backup create sharepoint --item-url "https://contoso.sharepoint.com/sites/Eng/Shared Documents/Specs"`
 
 	sharePointServiceCommandDeleteExamples = `# Delete SharePoint backup with ID 1234abcd-12ab-cd34-56de-1234abcd
 corso backup delete sharepoint --backup 1234abcd-12ab-cd34-56de-1234abcd`
@@ -83,23 +86,54 @@ func addSharePointCommands(cmd *cobra.Command) *cobra.Command {
 	switch cmd.Use {
 	case createCommand:
 		c, fs = utils.AddCommand(cmd, sharePointCreateCmd(), utils.MarkPreReleaseCommand())
+		options.AddFeatureToggle(c, options.SimulateExpiredDeltas())
 
 		c.Use = c.Use + " " + sharePointServiceCommandCreateUseSuffix
 		c.Example = sharePointServiceCommandCreateExamples
 
 		fs.StringArrayVar(&site,
 			utils.SiteFN, nil,
-			"Backup SharePoint data by site ID; accepts '"+utils.Wildcard+"' to select all sites.")
+			"Backup SharePoint data by site ID or display name; accepts '"+utils.Wildcard+"' to select all sites.")
 
 		fs.StringSliceVar(&weburl,
 			utils.WebURLFN, nil,
 			"Restore data by site webURL; accepts '"+utils.Wildcard+"' to select all sites.")
 
+		fs.StringVar(
+			&itemURL,
+			utils.ItemURLFN, "",
+			"Backup only the library file or folder at this SharePoint web url or sharing link.")
+
 		fs.StringSliceVar(
 			&sharepointData,
 			utils.DataFN, nil,
 			"Select one or more types of data to backup: "+dataLibraries+" or "+dataPages+".")
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Only backup library files created by a user whose email contains this value.")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Only backup library files whose content type contains this value.")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Only backup library files with this sensitivity label.")
+		fs.BoolVar(
+			&excludeSyncArtifacts,
+			utils.ExcludeSyncArtifactsFN, false,
+			"Skip office lock files (~$*), temp files (*.tmp), and sync-conflict copies (*-DESKTOP-1A2B3C4.*) in libraries.")
+		options.AddSharePointBackupPermissionsFlag(c)
 		options.AddOperationFlags(c)
+		addParallelOwnersFlag(c)
+		options.AddTuningFlags(c)
+		options.AddSpillFlags(c)
+		options.AddDeltaCacheFlags(c)
+		options.AddAlertFlags(c)
+		options.AddMemoryFlags(c)
+		options.AddHeartbeatFlags(c)
+		options.AddBackupBandwidthFlag(c)
 
 	case listCommand:
 		c, fs = utils.AddCommand(cmd, sharePointListCmd(), utils.MarkPreReleaseCommand())
@@ -157,6 +191,21 @@ func addSharePointCommands(cmd *cobra.Command) *cobra.Command {
 		// 	utils.FileCreatedAfterFN, "",
 		// 	"Select backup details for items created after this datetime.")
 
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Select backup details for library files created by a user whose email contains this value.")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Select backup details for library files whose content type contains this value.")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Select backup details for library files with this sensitivity label.")
+
+		addColumnsFlag(c)
+
 	case deleteCommand:
 		c, fs = utils.AddCommand(cmd, sharePointDeleteCmd(), utils.MarkPreReleaseCommand())
 
@@ -195,7 +244,7 @@ func createSharePointCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if err := validateSharePointBackupCreateFlags(site, weburl, sharepointData); err != nil {
+	if err := validateSharePointBackupCreateFlags(site, weburl, sharepointData, itemURL); err != nil {
 		return err
 	}
 
@@ -214,63 +263,75 @@ func createSharePointCmd(cmd *cobra.Command, args []string) error {
 	// TODO: log/print recoverable errors
 	errs := fault.New(false)
 
-	gc, err := connector.NewGraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
+	gc, err := connector.SharedPool().GraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
 	if err != nil {
 		return Only(ctx, errors.Wrap(err, "Failed to connect to Microsoft APIs"))
 	}
 
-	sel, err := sharePointBackupCreateSelectors(ctx, site, weburl, sharepointData, gc)
-	if err != nil {
-		return Only(ctx, errors.Wrap(err, "Retrieving up sharepoint sites by ID and WebURL"))
-	}
-
-	var (
-		merrs *multierror.Error
-		bIDs  []model.StableID
-	)
+	var sel *selectors.SharePointBackup
 
-	for _, discSel := range sel.SplitByResourceOwner(gc.GetSiteIDs()) {
-		bo, err := r.NewBackup(ctx, discSel.Selector)
+	if len(itemURL) > 0 {
+		ref, err := utils.ResolveItemURL(ctx, acct, itemURL, path.SharePointService)
 		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to initialize SharePoint backup for site %s",
-				discSel.DiscreteOwner,
-			))
-
-			continue
+			return Only(ctx, err)
 		}
 
-		err = bo.Run(ctx)
+		sel, err = sharePointItemBackupSelectors(*ref)
+		if err != nil {
+			return Only(ctx, err)
+		}
+	} else {
+		sites, err := utils.ResolveSiteNames(site, gc.Sites, gc.SiteNames)
 		if err != nil {
-			merrs = multierror.Append(merrs, errors.Wrapf(
-				err,
-				"Failed to run SharePoint backup for site %s",
-				discSel.DiscreteOwner,
-			))
+			return Only(ctx, errors.Wrap(err, "Failed to resolve site names"))
+		}
 
-			continue
+		sel, err = sharePointBackupCreateSelectors(ctx, sites, weburl, sharepointData, gc)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Retrieving up sharepoint sites by ID and WebURL"))
 		}
+	}
+
+	if len(fileCreatedBy) > 0 {
+		sel.Filter(sel.CreatedBy(fileCreatedBy))
+	}
+
+	if len(fileContentType) > 0 {
+		sel.Filter(sel.ContentType(fileContentType))
+	}
 
-		bIDs = append(bIDs, bo.Results.BackupID)
+	if len(fileLabel) > 0 {
+		sel.Filter(sel.SensitivityLabel(fileLabel))
 	}
 
-	bups, ferrs := r.Backups(ctx, bIDs)
-	// TODO: print/log recoverable errors
-	if ferrs.Err() != nil {
-		return Only(ctx, errors.Wrap(ferrs.Err(), "Unable to retrieve backup results from storage"))
+	if excludeSyncArtifacts {
+		sel.Exclude(sel.SyncArtifacts())
 	}
 
-	backup.PrintAll(ctx, bups)
+	discSels := sel.SplitByResourceOwner(gc.GetSiteIDs())
+	sels := make([]selectors.Selector, 0, len(discSels))
 
-	if e := merrs.ErrorOrNil(); e != nil {
-		return Only(ctx, e)
+	for _, discSel := range discSels {
+		sels = append(sels, discSel.Selector)
 	}
 
-	return nil
+	return runBackups(ctx, r, "SharePoint", "site", sels)
 }
 
-func validateSharePointBackupCreateFlags(sites, weburls, cats []string) error {
+func validateSharePointBackupCreateFlags(sites, weburls, cats []string, itemURL string) error {
+	if len(itemURL) > 0 {
+		if len(sites) > 0 || len(weburls) > 0 || len(cats) > 0 {
+			return errors.New(
+				"--" + utils.ItemURLFN + " can't be combined with --" +
+					utils.SiteFN + ", --" +
+					utils.WebURLFN + ", or --" +
+					utils.DataFN,
+			)
+		}
+
+		return nil
+	}
+
 	if len(sites) == 0 && len(weburls) == 0 {
 		return errors.New(
 			"requires one or more --" +
@@ -326,6 +387,25 @@ func sharePointBackupCreateSelectors(
 	return addCategories(sel, cats), nil
 }
 
+// sharePointItemBackupSelectors selects only the library file or folder
+// identified by an item url.
+func sharePointItemBackupSelectors(ref m365.DriveItemRef) (*selectors.SharePointBackup, error) {
+	folderURL, err := ref.FolderURL()
+	if err != nil {
+		return nil, err
+	}
+
+	sel := selectors.NewSharePointBackup([]string{ref.Owner})
+
+	if ref.IsFolder {
+		sel.Include(sel.LibraryFolderURLs([]string{folderURL}))
+	} else {
+		sel.Include(sel.LibraryItemURLs([]string{folderURL}, []string{ref.ItemID}))
+	}
+
+	return sel, nil
+}
+
 func includeAllSitesWithCategories(categories []string) *selectors.SharePointBackup {
 	sel := addCategories(
 		selectors.NewSharePointBackup(selectors.Any()),
@@ -473,6 +553,10 @@ func detailsSharePointCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if err := details.ValidateColumns(columns); err != nil {
+		return Only(ctx, err)
+	}
+
 	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
 	if err != nil {
 		return Only(ctx, err)
@@ -491,6 +575,10 @@ func detailsSharePointCmd(cmd *cobra.Command, args []string) error {
 		Sites:        site,
 		WebURLs:      weburl,
 
+		FileCreatedBy:   fileCreatedBy,
+		FileContentType: fileContentType,
+		FileLabel:       fileLabel,
+
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
 
@@ -499,12 +587,14 @@ func detailsSharePointCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	printNotes(ctx, r, backupID)
+
 	if len(ds.Entries) == 0 {
 		Info(ctx, selectors.ErrorNoMatchingItems)
 		return nil
 	}
 
-	ds.PrintEntries(ctx)
+	ds.PrintEntries(ctx, columns...)
 
 	return nil
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/alcionai/corso/src/internal/connector"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 type SharePointSuite struct {
@@ -70,12 +71,22 @@ func (suite *SharePointSuite) TestAddSharePointCommands() {
 	}
 }
 
+func (suite *SharePointSuite) TestCreateSimulateExpiredDeltasFlag() {
+	cmd := &cobra.Command{Use: createCommand}
+
+	c := addSharePointCommands(cmd)
+	require.NotNil(suite.T(), c)
+
+	assert.NotNil(suite.T(), c.Flag("simulate-expired-deltas"))
+}
+
 func (suite *SharePointSuite) TestValidateSharePointBackupCreateFlags() {
 	table := []struct {
-		name   string
-		site   []string
-		weburl []string
-		expect assert.ErrorAssertionFunc
+		name    string
+		site    []string
+		weburl  []string
+		itemURL string
+		expect  assert.ErrorAssertionFunc
 	}{
 		{
 			name:   "no sites or urls",
@@ -97,10 +108,67 @@ func (suite *SharePointSuite) TestValidateSharePointBackupCreateFlags() {
 			weburl: []string{"fnord"},
 			expect: assert.NoError,
 		},
+		{
+			name:    "item url",
+			itemURL: "https://contoso.sharepoint.com/sites/Eng/Shared Documents/Specs",
+			expect:  assert.NoError,
+		},
+		{
+			name:    "item url and sites",
+			site:    []string{"smarf"},
+			itemURL: "https://contoso.sharepoint.com/sites/Eng/Shared Documents/Specs",
+			expect:  assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), validateSharePointBackupCreateFlags(test.site, test.weburl, nil, test.itemURL))
+		})
+	}
+}
+
+func (suite *SharePointSuite) TestSharePointItemBackupSelectors() {
+	const driveURL = "https://contoso.sharepoint.com/sites/Eng/Shared%20Documents"
+
+	table := []struct {
+		name        string
+		ref         m365.DriveItemRef
+		expectURL   string
+		expectItems []string
+	}{
+		{
+			name:      "folder",
+			ref:       m365.DriveItemRef{Owner: "sid", DriveURL: driveURL, Name: "Specs", IsFolder: true},
+			expectURL: "/sites/Eng/Shared Documents/Specs",
+		},
+		{
+			name:        "file",
+			ref:         m365.DriveItemRef{Owner: "sid", DriveURL: driveURL, ItemID: "iid", ParentPath: "Specs"},
+			expectURL:   "/sites/Eng/Shared Documents/Specs",
+			expectItems: []string{"iid"},
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
-			test.expect(suite.T(), validateSharePointBackupCreateFlags(test.site, test.weburl, nil))
+			t := suite.T()
+
+			sel, err := sharePointItemBackupSelectors(test.ref)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"sid"}, sel.DiscreteResourceOwners())
+
+			scopes := sel.Scopes()
+			require.Len(t, scopes, 1)
+
+			scope := scopes[0]
+			assert.Equal(t, []string{test.expectURL}, scope.LibraryURLs())
+
+			if len(test.expectItems) == 0 {
+				assert.Equal(t, selectors.SharePointLibrary, scope.Category())
+				return
+			}
+
+			assert.Equal(t, selectors.SharePointLibraryItem, scope.Category())
+			assert.Equal(t, test.expectItems, scope.Get(selectors.SharePointLibraryItem))
 		})
 	}
 }
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+const (
+	treeCommand = "tree"
+	pathFN      = "path"
+	depthFN     = "depth"
+)
+
+const treeCommandExamples = `# Show the folders in backup 1234abcd-12ab-cd34-56de-1234abcd
+corso backup tree --backup 1234abcd-12ab-cd34-56de-1234abcd
+
+# Show the top two levels of folders beneath Alice's inbox
+corso backup tree --backup 1234abcd-12ab-cd34-56de-1234abcd --path email/Inbox --depth 2
+
+# Show the folders within a SharePoint document library
+corso backup tree --backup 1234abcd-12ab-cd34-56de-1234abcd --path "libraries/Shared Documents"`
+
+var (
+	treeBackupID string
+	treePath     string
+	treeDepth    int
+)
+
+// The backup tree subcommand.
+// `corso backup tree --backup <backupId> [--path <folders>] [--depth <levels>]`
+func treeCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   treeCommand,
+		Short: "Show the folder hierarchy of a backup",
+		Long: `Show the folders within a backup as a tree, along with the count and size of
+the items within each folder and its subfolders.  Use --path to descend into a
+folder, and --depth to limit how many levels of subfolders are shown.`,
+		RunE:    handleTreeCmd,
+		Args:    cobra.NoArgs,
+		Example: treeCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&treeBackupID,
+		utils.BackupFN, "",
+		"ID of the backup to show. (required)")
+	cobra.CheckErr(c.MarkFlagRequired(utils.BackupFN))
+
+	fs.StringVar(
+		&treePath,
+		pathFN, "",
+		"Folder to show, starting with the category of data (ex: email/Inbox, files/Documents). "+
+			"Escape slashes within folder names with a backslash.")
+
+	fs.IntVar(
+		&treeDepth,
+		depthFN, 0,
+		"Number of levels of subfolders to show; shows all levels if unset.")
+
+	return c
+}
+
+// Handler for calls to `corso backup tree`.
+func handleTreeCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if treeDepth < 0 {
+		return Only(ctx, errors.New("--"+depthFN+" must not be negative"))
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	d, b, errs := r.BackupDetails(ctx, treeBackupID)
+	if errs.Err() != nil {
+		if errors.Is(errs.Err(), data.ErrNotFound) {
+			return Only(ctx, errors.Errorf("No backup exists with the id %s", treeBackupID))
+		}
+
+		return Only(ctx, errors.Wrap(errs.Err(), "Failed to get backup details in the repository"))
+	}
+
+	var tree *details.TreeNode
+
+	// structure-only backups hold no items, so their folders are recorded
+	// apart from the backup details.
+	if b.StructureOnly {
+		tree, err = r.BackupStructure(ctx, treeBackupID)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to get the folder structure of the backup"))
+		}
+	} else {
+		tree, err = d.Tree()
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to build the folder tree"))
+		}
+	}
+
+	node, err := pruneTree(tree, treePath, treeDepth)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	node.PrintTree(ctx)
+
+	return nil
+}
+
+// pruneTree produces the subtree beneath the folder at the path, descending
+// at most depth levels.
+func pruneTree(tree *details.TreeNode, folder string, depth int) (*details.TreeNode, error) {
+	// Prune counts the node itself as the first level.
+	if depth > 0 {
+		depth++
+	}
+
+	if len(folder) == 0 {
+		return tree.Prune(depth), nil
+	}
+
+	pb, err := path.Builder{}.SplitUnescapeAppend(folder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid --%s %s", pathFN, folder)
+	}
+
+	node := tree.Find(pb.Elements()...)
+	if node == nil {
+		return nil, errors.Errorf("No folder exists in the backup at %s", folder)
+	}
+
+	node = node.Prune(depth)
+	node.Name = folder
+
+	return node, nil
+}
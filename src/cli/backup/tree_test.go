@@ -0,0 +1,144 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type TreeSuite struct {
+	tester.Suite
+}
+
+func TestTreeSuite(t *testing.T) {
+	suite.Run(t, &TreeSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *TreeSuite) TestTreeCmd() {
+	t := suite.T()
+	c := treeCmd()
+
+	assert.Equal(t, treeCommand, c.Use)
+	tester.AreSameFunc(t, handleTreeCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup("backup"))
+	assert.NotNil(t, c.Flags().Lookup(pathFN))
+	assert.NotNil(t, c.Flags().Lookup(depthFN))
+}
+
+func (suite *TreeSuite) TestPruneTree() {
+	mail := func(loc string, elems ...string) details.DetailsEntry {
+		p, err := path.Builder{}.
+			Append(elems...).
+			ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, true)
+		require.NoError(suite.T(), err)
+
+		return details.DetailsEntry{
+			RepoRef:     p.String(),
+			LocationRef: loc,
+			ItemInfo: details.ItemInfo{
+				Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail, Size: 1},
+			},
+		}
+	}
+
+	dm := details.DetailsModel{
+		Entries: []details.DetailsEntry{
+			mail("Inbox", "inbox", "m1"),
+			mail("Inbox/a", "inbox", "a", "m2"),
+			mail("Inbox/a/b", "inbox", "a", "b", "m3"),
+			mail(`Inbox/c\/d`, "inbox", "cd", "m4"),
+		},
+	}
+
+	table := []struct {
+		name        string
+		folder      string
+		depth       int
+		expectName  string
+		expectItems int
+		expectDepth int
+		expectErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "entire tree",
+			expectName:  "/",
+			expectItems: 4,
+			expectDepth: 4,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "depth",
+			depth:       1,
+			expectName:  "/",
+			expectItems: 4,
+			expectDepth: 1,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "path",
+			folder:      "email/Inbox/a",
+			expectName:  "email/Inbox/a",
+			expectItems: 2,
+			expectDepth: 1,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "path and depth",
+			folder:      "email/Inbox",
+			depth:       1,
+			expectName:  "email/Inbox",
+			expectItems: 4,
+			expectDepth: 1,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "escaped path",
+			folder:      `email/Inbox/c\/d`,
+			expectName:  `email/Inbox/c\/d`,
+			expectItems: 1,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:      "missing folder",
+			folder:    "email/Outbox",
+			expectErr: assert.Error,
+		},
+	}
+	tree, err := dm.Tree()
+	require.NoError(suite.T(), err)
+
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			node, err := pruneTree(tree, test.folder, test.depth)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expectName, node.Name)
+			assert.Equal(t, test.expectItems, node.Items)
+			assert.Equal(t, test.expectDepth, treeDepthOf(node))
+		})
+	}
+}
+
+func treeDepthOf(node *details.TreeNode) int {
+	var depth int
+
+	for _, c := range node.Children {
+		if d := treeDepthOf(c) + 1; d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/operations"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+const (
+	verifyCommand = "verify"
+	scrubFN       = "scrub"
+	sampleFN      = "sample"
+)
+
+const verifyCommandExamples = `# Verify that every item in backup 1234abcd-12ab-cd34-56de-1234abcd can be read
+corso backup verify --backup 1234abcd-12ab-cd34-56de-1234abcd
+
+# Scrub a sample of 100 items from every backup in the repository
+corso backup verify --scrub --sample 100`
+
+var (
+	verifyBackupIDs []string
+	verifyScrub     bool
+	verifySample    int
+)
+
+// The backup verify subcommand.
+// `corso backup verify [--backup <backupId>,...] [--scrub] [--sample <count>]`
+func verifyCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   verifyCommand,
+		Short: "Check backups for corrupted data",
+		Long: `Read the items within backups out of the repository to confirm that their data
+is present and intact.  With --scrub, each item is also deserialized the way a
+restore would, which catches corrupted data that storage still reads back without
+error.  Every corrupted item is listed along with the backup that holds it.`,
+		RunE:    handleVerifyCmd,
+		Args:    cobra.NoArgs,
+		Example: verifyCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringSliceVar(
+		&verifyBackupIDs,
+		utils.BackupFN, nil,
+		"ID of the backups to verify; verifies every backup in the repository if unset.")
+	fs.BoolVar(
+		&verifyScrub,
+		scrubFN, false,
+		"Deserialize each item (mail, contacts, events, tasks, lists, pages, and file headers) to detect corruption.")
+	fs.IntVar(
+		&verifySample,
+		sampleFN, 0,
+		"Number of randomly sampled items to check in each backup; checks every item if unset.")
+
+	options.AddReadCacheFlags(c)
+
+	return c
+}
+
+// Handler for calls to `corso backup verify`.
+func handleVerifyCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if verifySample < 0 {
+		return Only(ctx, errors.New("--"+sampleFN+" must not be negative"))
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	ids := verifyBackupIDs
+
+	if len(ids) == 0 {
+		bs, err := r.BackupsByTag(ctx)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to list backups in the repository"))
+		}
+
+		for _, b := range bs {
+			ids = append(ids, string(b.ID))
+		}
+	}
+
+	if len(ids) == 0 {
+		Info(ctx, "No backups available to verify")
+		return nil
+	}
+
+	op, err := r.NewVerify(ctx, ids, control.VerifyConfig{Scrub: verifyScrub, SampleSize: verifySample})
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to initialize backup verification"))
+	}
+
+	if err := op.Run(ctx); err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to verify backups"))
+	}
+
+	res := op.Results
+
+	Infof(ctx, "Checked %d items in %d backups", res.ItemsChecked, len(ids))
+
+	if len(res.Corrupted) == 0 {
+		return nil
+	}
+
+	ps := make([]Printable, 0, len(res.Corrupted))
+	for _, ci := range res.Corrupted {
+		ps = append(ps, corruptItem(ci))
+	}
+
+	All(ctx, ps...)
+
+	return Only(ctx, errors.Errorf("Found %d corrupted items", len(res.Corrupted)))
+}
+
+// corruptItem prints a corrupted item found by the verify operation.
+type corruptItem operations.CorruptItem
+
+var _ Printable = corruptItem{}
+
+func (ci corruptItem) MinimumPrintable() any {
+	return operations.CorruptItem(ci)
+}
+
+func (ci corruptItem) Headers() []string {
+	return []string{"Backup ID", "ID", "Reason"}
+}
+
+func (ci corruptItem) Values() []string {
+	return []string{string(ci.BackupID), ci.ShortRef, ci.Reason}
+}
@@ -0,0 +1,29 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type VerifySuite struct {
+	tester.Suite
+}
+
+func TestVerifySuite(t *testing.T) {
+	suite.Run(t, &VerifySuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *VerifySuite) TestVerifyCmd() {
+	t := suite.T()
+	c := verifyCmd()
+
+	assert.Equal(t, verifyCommand, c.Use)
+	tester.AreSameFunc(t, handleVerifyCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup("backup"))
+	assert.NotNil(t, c.Flags().Lookup(scrubFN))
+	assert.NotNil(t, c.Flags().Lookup(sampleFN))
+}
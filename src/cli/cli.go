@@ -3,7 +3,9 @@ package cli
 import (
 	"context"
 	"os"
+	"os/signal"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/alcionai/clues"
@@ -13,13 +15,18 @@ import (
 	"github.com/alcionai/corso/src/cli/backup"
 	"github.com/alcionai/corso/src/cli/config"
 	"github.com/alcionai/corso/src/cli/help"
+	"github.com/alcionai/corso/src/cli/m365"
 	"github.com/alcionai/corso/src/cli/options"
 	"github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/cli/repo"
+	"github.com/alcionai/corso/src/cli/report"
 	"github.com/alcionai/corso/src/cli/restore"
+	"github.com/alcionai/corso/src/cli/setup"
+	"github.com/alcionai/corso/src/cli/update"
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/observe"
 	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/logger"
 )
 
@@ -42,8 +49,23 @@ func preRun(cc *cobra.Command, args []string) error {
 		return err
 	}
 
+	options.SetEventsConfig(config.GetEventsConfig(cc.Context()))
+	options.SetPermissionsConfig(config.GetPermissionsConfig(cc.Context()))
+	options.SetEWSConfig(config.GetEWSConfig(cc.Context()))
+	options.SetCatalogConfig(config.GetCatalogConfig(cc.Context()))
+
+	if err := options.SetTuningConfig(config.GetTuningProfile(cc.Context())); err != nil {
+		return err
+	}
+
+	if err := logger.ValidatePIIHandlingFlag(); err != nil {
+		return clues.Wrap(err, "invalid --pii-handling")
+	}
+
 	log := logger.Ctx(cc.Context())
 
+	fitResources(cc.Context())
+
 	flags := utils.GetPopulatedFlags(cc)
 	flagSl := make([]string, 0, len(flags))
 
@@ -64,6 +86,28 @@ func preRun(cc *cobra.Command, args []string) error {
 	return nil
 }
 
+// fitResources scales corso to the cpu and memory limits of its container,
+// if it runs in one.  Go doesn't otherwise account for cpu quotas, and runs
+// a thread for every cpu of the host.  An explicit GOMAXPROCS is respected.
+func fitResources(ctx context.Context) {
+	rl := control.DetectResources()
+	if !rl.Bounded() {
+		return
+	}
+
+	options.SetResourceLimits(rl)
+
+	if n := rl.MaxProcs(); n > 0 && len(os.Getenv("GOMAXPROCS")) == 0 {
+		runtime.GOMAXPROCS(n)
+	}
+
+	logger.Ctx(ctx).Infow(
+		"detected container resource limits",
+		"cpus", rl.CPUs,
+		"memory_bytes", rl.MemoryBytes,
+		"gomaxprocs", runtime.GOMAXPROCS(0))
+}
+
 // Handler for flat calls to `corso`.
 // Produces the same output as `corso --help`.
 func handleCorsoCmd(cmd *cobra.Command, args []string) error {
@@ -106,6 +150,10 @@ func BuildCommandTree(cmd *cobra.Command) {
 	repo.AddCommands(cmd)
 	backup.AddCommands(cmd)
 	restore.AddCommands(cmd)
+	report.AddCommands(cmd)
+	m365.AddCommands(cmd)
+	setup.AddCommands(cmd)
+	update.AddCommands(cmd)
 	help.AddCommands(cmd)
 }
 
@@ -129,6 +177,8 @@ func Handle() {
 		_ = log.Sync() // flush all logs in the buffer
 	}()
 
+	ctx = cancelOnInterrupt(ctx)
+
 	if err := corsoCmd.ExecuteContext(ctx); err != nil {
 		logger.Ctx(ctx).
 			With("err", err).
@@ -137,6 +187,22 @@ func Handle() {
 	}
 }
 
+// cancelOnInterrupt cancels the context on the first interrupt, letting
+// running operations wind down and report their results.  A second
+// interrupt terminates the process immediately.
+func cancelOnInterrupt(ctx context.Context) context.Context {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+
+	go func() {
+		<-ctx.Done()
+		// restores the default handling of the next interrupt.
+		stop()
+		print.Info(ctx, "\nCanceling, press Ctrl-C again to quit immediately.")
+	}()
+
+	return ctx
+}
+
 // Adjust the default usage template which does not properly indent examples
 func indentExamplesTemplate(template string) string {
 	cobra.AddTemplateFunc("indent", func(spaces int, v string) string {
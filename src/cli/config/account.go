@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -22,10 +23,16 @@ func m365ConfigsFromViper(vpr *viper.Viper) (account.M365Config, error) {
 	}
 
 	m365.AzureTenantID = vpr.GetString(AzureTenantIDKey)
+	m365.AuthMode = account.M365AuthMode(vpr.GetString(AzureAuthModeKey))
+	m365.TokenCachePath = vpr.GetString(AzureTokenCacheKey)
 
 	return m365, nil
 }
 
+// defaultTokenCacheFile is the file, within the config directory, where
+// delegated auth keeps the signed-in user's tokens.
+const defaultTokenCacheFile = ".corso_token_cache.json"
+
 func m365Overrides(in map[string]string) map[string]string {
 	return map[string]string{
 		account.AzureTenantID:  in[account.AzureTenantID],
@@ -57,11 +64,16 @@ func configureAccount(
 		}
 	}
 
+	authMode, err := account.ParseM365AuthMode(common.First(
+		overrides[account.AzureAuthMode],
+		string(m365Cfg.AuthMode),
+		os.Getenv(account.AzureAuthMode)))
+	if err != nil {
+		return acct, err
+	}
+
 	// compose the m365 config and credentials
 	m365 := credentials.GetM365()
-	if err := m365.Validate(); err != nil {
-		return acct, errors.Wrap(err, "validating m365 credentials")
-	}
 
 	m365Cfg = account.M365Config{
 		M365: m365,
@@ -69,14 +81,33 @@ func configureAccount(
 			overrides[account.AzureTenantID],
 			m365Cfg.AzureTenantID,
 			os.Getenv(account.AzureTenantID)),
+		AuthMode: authMode,
+		TokenCachePath: common.First(
+			m365Cfg.TokenCachePath,
+			os.Getenv(account.AzureTokenCache)),
+	}
+
+	required := map[string]string{
+		credentials.AzureClientID: m365Cfg.AzureClientID,
+		account.AzureTenantID:     m365Cfg.AzureTenantID,
+	}
+
+	// delegated auth signs in as a user instead of using the client secret,
+	// and keeps the user's tokens so that they only need to sign in once.
+	if m365Cfg.Delegated() {
+		if len(m365Cfg.TokenCachePath) == 0 {
+			m365Cfg.TokenCachePath = filepath.Join(configDir, defaultTokenCacheFile)
+		}
+	} else {
+		if err := m365.Validate(); err != nil {
+			return acct, errors.Wrap(err, "validating m365 credentials")
+		}
+
+		required[credentials.AzureClientSecret] = m365Cfg.AzureClientSecret
 	}
 
 	// ensure required properties are present
-	if err := utils.RequireProps(map[string]string{
-		credentials.AzureClientID:     m365Cfg.AzureClientID,
-		credentials.AzureClientSecret: m365Cfg.AzureClientSecret,
-		account.AzureTenantID:         m365Cfg.AzureTenantID,
-	}); err != nil {
+	if err := utils.RequireProps(required); err != nil {
 		return acct, err
 	}
 
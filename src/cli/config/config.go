@@ -12,6 +12,7 @@ import (
 
 	. "github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/storage"
 )
@@ -28,6 +29,35 @@ const (
 	// M365 config
 	AccountProviderTypeKey = "account_provider"
 	AzureTenantIDKey       = "azure_tenantid"
+	AzureAuthModeKey       = "azure_auth_mode"
+	AzureTokenCacheKey     = "azure_token_cache"
+
+	// Usage events config
+	EventsLocalOnlyKey = "events_local_only"
+	EventsFileKey      = "events_file"
+
+	// Permissions config
+	OneDriveBackupPermissionsKey   = "onedrive_backup_permissions"
+	OneDriveRestorePermissionsKey  = "onedrive_restore_permissions"
+	SharePointBackupPermissionsKey = "sharepoint_backup_permissions"
+
+	// Tuning config
+	TuningProfileKey = "tuning_profile"
+
+	// Exchange Web Services config
+	EWSFallbackKey = "exchange_ews_fallback"
+	EWSEndpointKey = "exchange_ews_endpoint"
+
+	// Catalog sync config
+	CatalogKindKey      = "catalog_kind"
+	CatalogTableKey     = "catalog_table"
+	CatalogSQLDriverKey = "catalog_sql_driver"
+	CatalogSQLDSNKey    = "catalog_sql_dsn"
+	CatalogRegionKey    = "catalog_region"
+	CatalogEndpointKey  = "catalog_endpoint"
+
+	// Update config
+	UpdateChannelKey = "update_channel"
 )
 
 var (
@@ -176,6 +206,68 @@ func Read(ctx context.Context) error {
 	return nil
 }
 
+// GetEventsConfig retrieves the usage events settings from the config
+// file: whether events are restricted to the local sink, and the file
+// that receives them.
+func GetEventsConfig(ctx context.Context) (bool, string) {
+	vpr := GetViper(ctx)
+	return vpr.GetBool(EventsLocalOnlyKey), vpr.GetString(EventsFileKey)
+}
+
+// GetPermissionsConfig retrieves the default permissions toggles for each
+// service from the config file.
+func GetPermissionsConfig(ctx context.Context) control.PermissionOptions {
+	vpr := GetViper(ctx)
+
+	return control.PermissionOptions{
+		OneDrive: control.PermissionToggles{
+			Backup:  vpr.GetBool(OneDriveBackupPermissionsKey),
+			Restore: vpr.GetBool(OneDriveRestorePermissionsKey),
+		},
+		SharePoint: control.PermissionToggles{
+			Backup: vpr.GetBool(SharePointBackupPermissionsKey),
+		},
+	}
+}
+
+// GetEWSConfig retrieves the Exchange Web Services restore transport
+// settings from the config file.
+func GetEWSConfig(ctx context.Context) control.EWSOptions {
+	vpr := GetViper(ctx)
+
+	return control.EWSOptions{
+		Fallback: vpr.GetBool(EWSFallbackKey),
+		Endpoint: vpr.GetString(EWSEndpointKey),
+	}
+}
+
+// GetCatalogConfig retrieves the settings of the external catalog that
+// mirrors the repository's backup records from the config file.
+func GetCatalogConfig(ctx context.Context) control.CatalogOptions {
+	vpr := GetViper(ctx)
+
+	return control.CatalogOptions{
+		Kind:      control.CatalogKind(strings.ToLower(vpr.GetString(CatalogKindKey))),
+		Table:     vpr.GetString(CatalogTableKey),
+		SQLDriver: vpr.GetString(CatalogSQLDriverKey),
+		SQLDSN:    vpr.GetString(CatalogSQLDSNKey),
+		Region:    vpr.GetString(CatalogRegionKey),
+		Endpoint:  vpr.GetString(CatalogEndpointKey),
+	}
+}
+
+// GetUpdateChannel retrieves the name of the release channel that
+// `corso update` installs from.
+func GetUpdateChannel(ctx context.Context) string {
+	return GetViper(ctx).GetString(UpdateChannelKey)
+}
+
+// GetTuningProfile retrieves the name of the tuning profile used by
+// default for this repository.
+func GetTuningProfile(ctx context.Context) control.TuningProfile {
+	return control.TuningProfile(strings.ToLower(GetViper(ctx).GetString(TuningProfileKey)))
+}
+
 // WriteRepoConfig currently just persists corso config to the config file
 // It does not check for conflicts or existing data.
 func WriteRepoConfig(ctx context.Context, s3Config storage.S3Config, m365Config account.M365Config) error {
@@ -198,6 +290,11 @@ func writeRepoConfigWithViper(vpr *viper.Viper, s3Config storage.S3Config, m365C
 	vpr.Set(AccountProviderTypeKey, account.ProviderM365.String())
 	vpr.Set(AzureTenantIDKey, m365Config.AzureTenantID)
 
+	if m365Config.Delegated() {
+		vpr.Set(AzureAuthModeKey, string(m365Config.AuthMode))
+		vpr.Set(AzureTokenCacheKey, m365Config.TokenCachePath)
+	}
+
 	if err := vpr.SafeWriteConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileAlreadyExistsError); ok {
 			return vpr.WriteConfig()
@@ -259,6 +356,45 @@ func getStorageAndAccountWithViper(
 	return store, acct, nil
 }
 
+// GetAccount creates an account instance by mediating all the possible
+// data sources (config file, env vars, flag overrides) and the config file.
+// Unlike GetStorageAndAccount, storage configuration is not required.
+func GetAccount(
+	ctx context.Context,
+	readFromFile bool,
+	overrides map[string]string,
+) (account.Account, error) {
+	return getAccountWithViper(GetViper(ctx), readFromFile, overrides)
+}
+
+// getAccountWithViper implements GetAccount, but takes in a viper
+// struct for testing.
+func getAccountWithViper(
+	vpr *viper.Viper,
+	readFromFile bool,
+	overrides map[string]string,
+) (account.Account, error) {
+	readConfigFromViper := readFromFile
+
+	// possibly read the prior config from a .corso file
+	if readFromFile {
+		if err := vpr.ReadInConfig(); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+				return account.Account{}, errors.Wrap(err, "reading corso config file: "+vpr.ConfigFileUsed())
+			}
+
+			readConfigFromViper = false
+		}
+	}
+
+	acct, err := configureAccount(vpr, readConfigFromViper, overrides)
+	if err != nil {
+		return acct, errors.Wrap(err, "retrieving account configuration details")
+	}
+
+	return acct, nil
+}
+
 // ---------------------------------------------------------------------------
 // Helper funcs
 // ---------------------------------------------------------------------------
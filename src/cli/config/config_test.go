@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/credentials"
 	"github.com/alcionai/corso/src/pkg/storage"
 )
@@ -70,6 +72,150 @@ func (suite *ConfigSuite) TestReadRepoConfigBasic() {
 	assert.Equal(t, tID, m365.AzureTenantID)
 }
 
+func (suite *ConfigSuite) TestGetEventsConfig() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	ctx = SetViper(ctx, vpr)
+
+	localOnly, file := GetEventsConfig(ctx)
+	assert.False(t, localOnly)
+	assert.Empty(t, file)
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	err := os.WriteFile(
+		testConfigFilePath,
+		[]byte("events_local_only = true\nevents_file = '/tmp/events.jsonl'\n"),
+		0o700)
+	require.NoError(t, err)
+
+	vpr.SetConfigFile(testConfigFilePath)
+	require.NoError(t, vpr.ReadInConfig(), "reading config")
+
+	localOnly, file = GetEventsConfig(ctx)
+	assert.True(t, localOnly)
+	assert.Equal(t, "/tmp/events.jsonl", file)
+}
+
+func (suite *ConfigSuite) TestGetPermissionsConfig() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	ctx = SetViper(ctx, vpr)
+
+	assert.Equal(t, control.PermissionOptions{}, GetPermissionsConfig(ctx))
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	err := os.WriteFile(
+		testConfigFilePath,
+		[]byte("onedrive_backup_permissions = true\nsharepoint_backup_permissions = true\n"),
+		0o700)
+	require.NoError(t, err)
+
+	vpr.SetConfigFile(testConfigFilePath)
+	require.NoError(t, vpr.ReadInConfig(), "reading config")
+
+	expect := control.PermissionOptions{
+		OneDrive:   control.PermissionToggles{Backup: true},
+		SharePoint: control.PermissionToggles{Backup: true},
+	}
+	assert.Equal(t, expect, GetPermissionsConfig(ctx))
+}
+
+func (suite *ConfigSuite) TestGetEWSConfig() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	ctx = SetViper(ctx, vpr)
+
+	assert.Equal(t, control.EWSOptions{}, GetEWSConfig(ctx))
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	err := os.WriteFile(
+		testConfigFilePath,
+		[]byte("exchange_ews_fallback = true\nexchange_ews_endpoint = \"https://mail.contoso.com/EWS/Exchange.asmx\"\n"),
+		0o700)
+	require.NoError(t, err)
+
+	vpr.SetConfigFile(testConfigFilePath)
+	require.NoError(t, vpr.ReadInConfig(), "reading config")
+
+	expect := control.EWSOptions{
+		Fallback: true,
+		Endpoint: "https://mail.contoso.com/EWS/Exchange.asmx",
+	}
+	assert.Equal(t, expect, GetEWSConfig(ctx))
+}
+
+func (suite *ConfigSuite) TestGetCatalogConfig() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	ctx = SetViper(ctx, vpr)
+
+	assert.False(t, GetCatalogConfig(ctx).Enabled())
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	err := os.WriteFile(
+		testConfigFilePath,
+		[]byte("catalog_kind = \"DynamoDB\"\ncatalog_table = \"corso-backups\"\ncatalog_region = \"us-east-1\"\n"),
+		0o700)
+	require.NoError(t, err)
+
+	vpr.SetConfigFile(testConfigFilePath)
+	require.NoError(t, vpr.ReadInConfig(), "reading config")
+
+	expect := control.CatalogOptions{
+		Kind:   control.CatalogDynamoDB,
+		Table:  "corso-backups",
+		Region: "us-east-1",
+	}
+	assert.Equal(t, expect, GetCatalogConfig(ctx))
+}
+
+func (suite *ConfigSuite) TestGetTuningProfile() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	ctx = SetViper(ctx, vpr)
+
+	assert.Empty(t, GetTuningProfile(ctx))
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	err := os.WriteFile(testConfigFilePath, []byte("tuning_profile = \"Throttled\"\n"), 0o700)
+	require.NoError(t, err)
+
+	vpr.SetConfigFile(testConfigFilePath)
+	require.NoError(t, vpr.ReadInConfig(), "reading config")
+
+	assert.Equal(t, control.ThrottledProfile, GetTuningProfile(ctx))
+}
+
 func (suite *ConfigSuite) TestWriteReadConfig() {
 	var (
 		t   = suite.T()
@@ -102,6 +248,56 @@ func (suite *ConfigSuite) TestWriteReadConfig() {
 	assert.Equal(t, readM365.AzureTenantID, m365.AzureTenantID)
 }
 
+func (suite *ConfigSuite) TestWriteReadConfig_delegated() {
+	var (
+		t   = suite.T()
+		vpr = viper.New()
+	)
+
+	testConfigFilePath := filepath.Join(t.TempDir(), "corso.toml")
+	require.NoError(t, initWithViper(vpr, testConfigFilePath), "initializing repo config")
+
+	m365 := account.M365Config{
+		AzureTenantID:  "tid",
+		AuthMode:       account.AuthDeviceCode,
+		TokenCachePath: filepath.Join(t.TempDir(), "token_cache.json"),
+	}
+
+	require.NoError(t, writeRepoConfigWithViper(vpr, storage.S3Config{Bucket: "bkt"}, m365), "writing repo config")
+	require.NoError(t, vpr.ReadInConfig(), "reading repo config")
+
+	readM365, err := m365ConfigsFromViper(vpr)
+	require.NoError(t, err)
+	assert.Equal(t, m365.AuthMode, readM365.AuthMode)
+	assert.Equal(t, m365.TokenCachePath, readM365.TokenCachePath)
+}
+
+func (suite *ConfigSuite) TestConfigureAccount_delegated() {
+	t := suite.T()
+	t.Setenv(credentials.AzureClientID, "cid")
+	t.Setenv(credentials.AzureClientSecret, "")
+	t.Setenv(account.AzureAuthMode, string(account.AuthDeviceCode))
+	t.Setenv(account.AzureTokenCache, "")
+
+	overrides := map[string]string{
+		account.AzureTenantID:  "tid",
+		AccountProviderTypeKey: account.ProviderM365.String(),
+	}
+
+	acct, err := configureAccount(viper.New(), false, overrides)
+	require.NoError(t, err, "delegated auth needs no client secret")
+
+	m365, err := acct.M365Config()
+	require.NoError(t, err)
+	assert.Equal(t, account.AuthDeviceCode, m365.AuthMode)
+	assert.Equal(t, filepath.Join(configDir, defaultTokenCacheFile), m365.TokenCachePath)
+
+	t.Setenv(account.AzureAuthMode, string(account.AuthApplication))
+
+	_, err = configureAccount(viper.New(), false, overrides)
+	assert.Error(t, err, "application auth needs a client secret")
+}
+
 func (suite *ConfigSuite) TestMustMatchConfig() {
 	var (
 		t   = suite.T()
@@ -178,6 +374,98 @@ func (suite *ConfigSuite) TestMustMatchConfig() {
 	}
 }
 
+func (suite *ConfigSuite) TestExportImportConfig() {
+	var (
+		t      = suite.T()
+		srcVpr = viper.New()
+		dstVpr = viper.New()
+	)
+
+	const (
+		bkt = "export-import-config-bucket"
+		tid = "a5d4e8d6-7c1e-4b58-8f3d-1f0b1e0c29d2"
+	)
+
+	srcFP := filepath.Join(t.TempDir(), "corso.toml")
+	require.NoError(t, initWithViper(srcVpr, srcFP), "initializing source config")
+
+	s3Cfg := storage.S3Config{Bucket: bkt, DoNotUseTLS: true}
+	m365 := account.M365Config{AzureTenantID: tid}
+
+	require.NoError(t, writeRepoConfigWithViper(srcVpr, s3Cfg, m365), "writing source config")
+
+	srcVpr.Set(CatalogKindKey, "dynamodb")
+	srcVpr.Set(CatalogTableKey, "corso-backups")
+	require.NoError(t, srcVpr.WriteConfig(), "writing catalog config")
+
+	exp, err := exportConfigWithViper(srcVpr)
+	require.NoError(t, err)
+	assert.Equal(t, ExportVersion, exp.Version)
+	assert.Equal(t, bkt, exp.Settings[BucketNameKey])
+	assert.Equal(t, tid, exp.Settings[AzureTenantIDKey])
+	assert.Equal(t, "dynamodb", exp.Settings[CatalogKindKey])
+	assert.Equal(t, "corso-backups", exp.Settings[CatalogTableKey])
+
+	// round trip the export through its serialized format
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteExport(buf, exp))
+
+	exp, err = ReadExport(buf)
+	require.NoError(t, err)
+
+	dstFP := filepath.Join(t.TempDir(), "corso.toml")
+	require.NoError(t, initWithViper(dstVpr, dstFP), "initializing destination config")
+	require.NoError(t, importConfigWithViper(dstVpr, exp, false))
+
+	readVpr := viper.New()
+	readVpr.SetConfigFile(dstFP)
+	require.NoError(t, readVpr.ReadInConfig(), "reading imported config")
+
+	readS3Cfg, err := s3ConfigsFromViper(readVpr)
+	require.NoError(t, err)
+	assert.Equal(t, bkt, readS3Cfg.Bucket)
+	assert.True(t, readS3Cfg.DoNotUseTLS)
+
+	readM365, err := m365ConfigsFromViper(readVpr)
+	require.NoError(t, err)
+	assert.Equal(t, tid, readM365.AzureTenantID)
+	assert.Equal(t, "corso-backups", readVpr.GetString(CatalogTableKey))
+
+	// re-importing the same settings is a no-op
+	assert.NoError(t, importConfigWithViper(dstVpr, exp, false))
+
+	// differing settings are only replaced when overwriting
+	exp.Settings[BucketNameKey] = "other-bucket"
+	assert.Error(t, importConfigWithViper(dstVpr, exp, false))
+	assert.NoError(t, importConfigWithViper(dstVpr, exp, true))
+	assert.Equal(t, "other-bucket", dstVpr.GetString(BucketNameKey))
+}
+
+func (suite *ConfigSuite) TestImportConfig_invalid() {
+	table := []struct {
+		name string
+		exp  Export
+	}{
+		{"no version", Export{Settings: map[string]any{BucketNameKey: "b"}}},
+		{"future version", Export{Version: ExportVersion + 1}},
+		{"unknown setting", Export{Version: ExportVersion, Settings: map[string]any{"fnords": "smarf"}}},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			vpr := viper.New()
+
+			fp := filepath.Join(t.TempDir(), "corso.toml")
+			require.NoError(t, initWithViper(vpr, fp))
+
+			assert.Error(t, importConfigWithViper(vpr, test.exp, true))
+
+			_, err := os.Stat(fp)
+			assert.True(t, os.IsNotExist(err), "config file should not be written")
+		})
+	}
+}
+
 // ------------------------------------------------------------
 // integration tests
 // ------------------------------------------------------------
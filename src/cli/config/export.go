@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ExportVersion is the version of the configuration export format.
+const ExportVersion = 1
+
+// exportableKeys lists the config file settings carried by an export.
+// Secrets are never written to the config file, so they never appear
+// in an export either.
+var exportableKeys = []string{
+	StorageProviderTypeKey,
+	BucketNameKey,
+	EndpointKey,
+	PrefixKey,
+	DisableTLSKey,
+	DisableTLSVerificationKey,
+	AccountProviderTypeKey,
+	AzureTenantIDKey,
+	AzureAuthModeKey,
+	EventsLocalOnlyKey,
+	EventsFileKey,
+	OneDriveBackupPermissionsKey,
+	OneDriveRestorePermissionsKey,
+	SharePointBackupPermissionsKey,
+	TuningProfileKey,
+	EWSFallbackKey,
+	EWSEndpointKey,
+	CatalogKindKey,
+	CatalogTableKey,
+	CatalogSQLDriverKey,
+	CatalogSQLDSNKey,
+	CatalogRegionKey,
+	CatalogEndpointKey,
+	UpdateChannelKey,
+}
+
+// Export is a portable copy of corso's own configuration, which can be
+// versioned alongside other operational setup and imported to recreate
+// the configuration on a new host.  It holds no backup data.
+type Export struct {
+	Version  int            `json:"version"`
+	Settings map[string]any `json:"settings"`
+}
+
+// ExportConfig produces an export of the settings in the config file.
+func ExportConfig(ctx context.Context) (Export, error) {
+	return exportConfigWithViper(GetViper(ctx))
+}
+
+// exportConfigWithViper implements ExportConfig, but takes in a viper
+// struct for testing.
+func exportConfigWithViper(vpr *viper.Viper) (Export, error) {
+	if err := vpr.ReadInConfig(); err != nil {
+		return Export{}, errors.Wrap(err, "reading corso config file")
+	}
+
+	exp := Export{
+		Version:  ExportVersion,
+		Settings: map[string]any{},
+	}
+
+	for _, k := range exportableKeys {
+		if vpr.IsSet(k) {
+			exp.Settings[k] = vpr.Get(k)
+		}
+	}
+
+	return exp, nil
+}
+
+// ImportConfig writes the settings in the export to the config file.
+// Unless overwrite is true, settings that already hold a different value
+// in the config file produce an error, and nothing is written.
+func ImportConfig(ctx context.Context, exp Export, overwrite bool) error {
+	return importConfigWithViper(GetViper(ctx), exp, overwrite)
+}
+
+// importConfigWithViper implements ImportConfig, but takes in a viper
+// struct for testing.
+func importConfigWithViper(vpr *viper.Viper, exp Export, overwrite bool) error {
+	if exp.Version < 1 || exp.Version > ExportVersion {
+		return errors.Errorf("unsupported config export version %d", exp.Version)
+	}
+
+	exportable := map[string]struct{}{}
+	for _, k := range exportableKeys {
+		exportable[k] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(exp.Settings))
+
+	for k := range exp.Settings {
+		if _, ok := exportable[k]; !ok {
+			return errors.New("config export contains an unknown setting: " + k)
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	if err := vpr.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return errors.Wrap(err, "reading corso config file")
+		}
+	}
+
+	if !overwrite {
+		for _, k := range keys {
+			// compare by string, so that values match regardless of
+			// the types produced by the export and config formats.
+			v := stringOf(exp.Settings[k])
+
+			if vpr.IsSet(k) && vpr.GetString(k) != v {
+				return errors.New("value of " + k + " (" + v +
+					") does not match corso configuration value (" + vpr.GetString(k) + ")")
+			}
+		}
+	}
+
+	for _, k := range keys {
+		vpr.Set(k, exp.Settings[k])
+	}
+
+	if err := vpr.SafeWriteConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileAlreadyExistsError); ok {
+			return vpr.WriteConfig()
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// stringOf renders a setting the way viper renders it as a string.
+func stringOf(v any) string {
+	vpr := viper.New()
+	vpr.Set("v", v)
+
+	return vpr.GetString("v")
+}
+
+// WriteExport writes the export to w as json.
+func WriteExport(w io.Writer, exp Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return errors.Wrap(enc.Encode(exp), "writing config export")
+}
+
+// ReadExport reads a json export from r.
+func ReadExport(r io.Reader) (Export, error) {
+	exp := Export{}
+
+	if err := json.NewDecoder(r).Decode(&exp); err != nil {
+		return Export{}, errors.Wrap(err, "reading config export")
+	}
+
+	return exp, nil
+}
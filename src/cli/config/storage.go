@@ -92,6 +92,8 @@ func configureStorage(
 			overrides[storage.DoNotVerifyTLS],
 			strconv.FormatBool(s3Cfg.DoNotVerifyTLS),
 			os.Getenv(storage.PrefixKey))),
+		DataStorageClass:     overrides[storage.DataStorageClass],
+		MetadataStorageClass: overrides[storage.MetadataStorageClass],
 	}
 
 	// compose the common config and credentials
@@ -0,0 +1,198 @@
+package m365
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/corso/src/cli/config"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/services/m365"
+)
+
+const (
+	probeCommand           = "probe"
+	purgeQuarantineCommand = "purge-quarantine"
+)
+
+var (
+	user []string
+	site []string
+)
+
+// AddCommands attaches all `corso m365 * *` commands to the parent.
+func AddCommands(cmd *cobra.Command) {
+	m365C := m365Cmd()
+	cmd.AddCommand(m365C)
+	m365C.AddCommand(probeCmd())
+
+	purgeC := purgeQuarantineCmd()
+	m365C.AddCommand(purgeC)
+
+	fs := purgeC.Flags()
+	fs.StringSliceVar(
+		&user,
+		utils.UserFN, nil,
+		"Purge quarantine folders belonging to the user's ID; accepts '"+utils.Wildcard+"' to select all users.")
+	fs.StringSliceVar(
+		&site,
+		utils.SiteFN, nil,
+		"Purge quarantine folders, lists, and pages in the site's ID; accepts '"+utils.Wildcard+"' to select all sites.")
+}
+
+// The m365 category of commands.
+// `corso m365 [<subcommand>] [<flag>...]`
+func m365Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "m365",
+		Short: "Inspect your M365 tenant",
+		Long:  `Inspect and validate the M365 tenant accessed by Corso.`,
+		RunE:  handleM365Cmd,
+		Args:  cobra.NoArgs,
+	}
+}
+
+// Handler for flat calls to `corso m365`.
+// Produces the same output as `corso m365 --help`.
+func handleM365Cmd(cmd *cobra.Command, args []string) error {
+	return cmd.Help()
+}
+
+// The m365 probe subcommand.
+// `corso m365 probe [<flag>...]`
+func probeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   probeCommand,
+		Short: "Check tenant access and permissions",
+		Long: `Validate the tenant ID, application credentials, and consented permissions,
+and confirm that users, sites, and drives can be reached before running a backup.`,
+		RunE: handleProbeCmd,
+		Args: cobra.NoArgs,
+	}
+}
+
+// Handler for calls to `corso m365 probe`.
+func handleProbeCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	acct, err := config.GetAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	report, err := m365.Probe(ctx, acct)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to probe the M365 tenant"))
+	}
+
+	ps := make([]Printable, 0, len(report.Results))
+	for _, r := range report.Results {
+		ps = append(ps, probeResult(r))
+	}
+
+	All(ctx, ps...)
+
+	if !report.Passed() {
+		return Only(ctx, errors.New("One or more capability checks failed"))
+	}
+
+	return nil
+}
+
+// The m365 purge-quarantine subcommand.
+// `corso m365 purge-quarantine --user <userID> [<flag>...]`
+func purgeQuarantineCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   purgeQuarantineCommand,
+		Short: "Delete expired quarantine restore folders",
+		Long: `Delete all quarantine restore folders whose expiration has passed.
+Quarantine folders are produced by restores run with the --quarantine flag.
+Users' mail, contacts, calendars, and OneDrive are checked with --user, and
+sites' document libraries, lists, and pages are checked with --site.`,
+		RunE: handlePurgeQuarantineCmd,
+		Args: cobra.NoArgs,
+	}
+}
+
+// Handler for calls to `corso m365 purge-quarantine`.
+func handlePurgeQuarantineCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if len(user) == 0 && len(site) == 0 {
+		return Only(ctx, errors.New("a --user or --site is required"))
+	}
+
+	acct, err := config.GetAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	var (
+		errs  = fault.New(false)
+		users = user
+		sites = site
+	)
+
+	if slices.Contains(user, utils.Wildcard) {
+		users, err = m365.UserIDs(ctx, acct, errs)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to retrieve M365 users"))
+		}
+	}
+
+	if slices.Contains(site, utils.Wildcard) {
+		sites, err = m365.SiteIDs(ctx, acct, errs)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to retrieve M365 sites"))
+		}
+	}
+
+	purged, err := m365.PurgeExpiredQuarantines(ctx, acct, users, sites, time.Now(), errs)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to purge quarantine folders"))
+	}
+
+	for _, p := range purged {
+		Infof(ctx, "Deleted %s", p)
+	}
+
+	if len(purged) == 0 {
+		Info(ctx, "No expired quarantine folders found")
+	}
+
+	if errs.Count() > 0 {
+		return Only(ctx, errors.Errorf("Failed to purge %d quarantine folders", errs.Count()))
+	}
+
+	return nil
+}
+
+type probeResult m365.ProbeResult
+
+// interface compliance check
+var _ Printable = probeResult{}
+
+func (pr probeResult) MinimumPrintable() any {
+	return m365.ProbeResult(pr)
+}
+
+func (pr probeResult) Headers() []string {
+	return []string{"Capability", "Status", "Message"}
+}
+
+func (pr probeResult) Values() []string {
+	status := "failed"
+
+	switch {
+	case pr.Skipped:
+		status = "skipped"
+	case pr.Passed:
+		status = "ok"
+	}
+
+	return []string{pr.Capability, status, pr.Message}
+}
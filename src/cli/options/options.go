@@ -1,6 +1,12 @@
 package options
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -11,13 +17,51 @@ import (
 func Control() control.Options {
 	opt := control.Defaults()
 
+	opt.Alerts.MaxDuration = alertMaxDuration
+	opt.Alerts.MaxChangedItems = alertMaxChangedItems
+	opt.Alerts.MaxChangedPercent = alertMaxChangedPercent
+	opt.Alerts.DetectAnomalies = detectAnomalies
+	opt.Approval.Timeout = approvalTimeout
+	opt.Bandwidth.BackupBytesPerSecond = int64(backupBandwidth)
+	opt.Bandwidth.RestoreBytesPerSecond = int64(restoreBandwidth)
+	opt.Catalog = catalogConfig
+	opt.Collision = control.CollisionPolicy(collisions)
+	opt.DeltaCache.Dir = deltaCacheDir
+	opt.DeltaCache.MaxAge = deltaCacheMaxAge
 	opt.FailFast = fastFail
+	opt.Heartbeat.Interval = heartbeatInterval
+	opt.Heartbeat.StallAfter = stallTimeout
+	opt.Heartbeat.AbortStalled = abortStalled
+	opt.MailRestore.FailOverQuota = mailFailOverQuota
+	opt.MailRestore.SkipQuotaCheck = mailSkipQuotaCheck
+	opt.MailRestore.MessagesPerMinute = mailMessagesPerMinute
+	opt.MaxMemoryMB = maxMemoryMB
 	opt.DisableMetrics = noStats
-	opt.RestorePermissions = restorePermissions
+	opt.EWS = ewsConfig
+	opt.Events.LocalOnly = eventsLocalOnly
+	opt.Events.LocalFile = eventsFile
+	opt.ReadCache.Dir = readCacheDir
+	opt.ReadCache.MaxBytes = int64(readCacheMaxSize)
+	opt.ReadCache.MaxMetadataBytes = int64(readCacheMaxMetadataSize)
+	opt.RestoreMailboxSettings = restoreMailboxSettings
+	opt.RestoreReport.File = reportFile
+	opt.RestoreReport.MailFrom = reportMailFrom
+	opt.RestoreReport.MailTo = reportMailTo
+	opt.Permissions = permissionsConfig
+	opt.Permissions.OneDrive.Backup = oneDriveBackupPermissions.or(opt.Permissions.OneDrive.Backup)
+	opt.Permissions.OneDrive.Restore = oneDriveRestorePermissions.or(opt.Permissions.OneDrive.Restore)
+	opt.Permissions.OneDrive.RestoreOnly = oneDrivePermissionsOnly
+	opt.Permissions.SharePoint.Backup = sharePointBackupPermissions.or(opt.Permissions.SharePoint.Backup)
+	opt.Spill.Dir = spillDir
+	opt.Spill.MaxBytes = int64(spillMaxSize)
+	opt.StructureOnly = structureOnly
 	opt.ToggleFeatures.DisableIncrementals = disableIncrementals
-	opt.ToggleFeatures.EnablePermissionsBackup = enablePermissionsBackup
+	opt.ToggleFeatures.RawDriveEnumeration = rawDriveEnumeration
+	opt.ToggleFeatures.SimulateExpiredDeltas = simulateExpiredDeltas
+	opt.ToggleFeatures.DisableHashSkip = disableHashSkip
+	opt.Tuning = tuning()
 
-	return opt
+	return opt.FitTo(resourceLimits)
 }
 
 // ---------------------------------------------------------------------------
@@ -25,9 +69,10 @@ func Control() control.Options {
 // ---------------------------------------------------------------------------
 
 var (
-	fastFail           bool
-	noStats            bool
-	restorePermissions bool
+	eventsFile      string
+	eventsLocalOnly bool
+	fastFail        bool
+	noStats         bool
 )
 
 // AddOperationFlags adds command-local operation flags
@@ -42,14 +87,612 @@ func AddOperationFlags(cmd *cobra.Command) {
 func AddGlobalOperationFlags(cmd *cobra.Command) {
 	fs := cmd.PersistentFlags()
 	fs.BoolVar(&noStats, "no-stats", false, "disable anonymous usage statistics gathering")
+	fs.BoolVar(
+		&eventsLocalOnly,
+		"events-local-only", false,
+		"record usage statistics locally, without transmitting them to any external service")
+	fs.StringVar(
+		&eventsFile,
+		"events-file", "",
+		"file that receives local-only usage statistics; defaults to the log")
+}
+
+// SetEventsConfig applies the usage statistics settings found in the
+// config file.  Flags take precedence over the config file.
+func SetEventsConfig(localOnly bool, file string) {
+	eventsLocalOnly = eventsLocalOnly || localOnly
+
+	if len(eventsFile) == 0 {
+		eventsFile = file
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Resource Limits
+// ---------------------------------------------------------------------------
+
+var resourceLimits control.ResourceLimits
+
+// SetResourceLimits applies the cpu and memory limits of the process's
+// container, which scale the defaults of any options the user leaves unset.
+func SetResourceLimits(rl control.ResourceLimits) {
+	resourceLimits = rl
+}
+
+// ---------------------------------------------------------------------------
+// Catalog Sync Config
+// ---------------------------------------------------------------------------
+
+var catalogConfig control.CatalogOptions
+
+// SetCatalogConfig applies the external backup catalog settings found in
+// the config file.  There are no matching flags: the catalog is a property
+// of the repository, not of a single operation.
+func SetCatalogConfig(co control.CatalogOptions) {
+	catalogConfig = co
+}
+
+// ---------------------------------------------------------------------------
+// Exchange Web Services Config
+// ---------------------------------------------------------------------------
+
+var ewsConfig control.EWSOptions
+
+// SetEWSConfig applies the Exchange Web Services restore transport
+// settings found in the config file.  There are no matching flags: the
+// transport is a property of the tenant, not of a single restore.
+func SetEWSConfig(eo control.EWSOptions) {
+	ewsConfig = eo
+}
+
+// ---------------------------------------------------------------------------
+// Permissions Flags
+// ---------------------------------------------------------------------------
+
+var (
+	permissionsConfig control.PermissionOptions
+
+	oneDriveBackupPermissions   optionalBool
+	oneDriveRestorePermissions  optionalBool
+	sharePointBackupPermissions optionalBool
+
+	oneDrivePermissionsOnly bool
+)
+
+// SetPermissionsConfig applies the permissions toggles found in the config
+// file.  Flags take precedence over the config file.
+func SetPermissionsConfig(po control.PermissionOptions) {
+	permissionsConfig = po
+}
+
+// AddOneDriveBackupPermissionsFlag adds the flag that toggles backing up
+// the permissions of OneDrive files and folders.
+func AddOneDriveBackupPermissionsFlag(cmd *cobra.Command) {
+	addPermissionsFlag(
+		cmd.Flags(),
+		&oneDriveBackupPermissions,
+		"backup-permissions",
+		"Backup the sharing permissions of files and folders; overrides "+
+			"the config file's onedrive_backup_permissions for this backup")
+}
+
+// AddOneDriveRestorePermissionsFlag adds the flag that toggles reapplying
+// the backed up permissions of OneDrive files and folders.
+func AddOneDriveRestorePermissionsFlag(cmd *cobra.Command) {
+	addPermissionsFlag(
+		cmd.Flags(),
+		&oneDriveRestorePermissions,
+		"restore-permissions",
+		"Restore permissions for files and folders; overrides "+
+			"the config file's onedrive_restore_permissions for this restore")
+}
+
+// AddOneDrivePermissionsOnlyFlag adds the flag that reapplies the backed
+// up permissions to the original OneDrive files and folders, in place of
+// restoring their content.
+func AddOneDrivePermissionsOnlyFlag(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(
+		&oneDrivePermissionsOnly,
+		"permissions-only", false,
+		"Only reapply the backed up permissions to the original files and folders that still exist, "+
+			"without restoring any content")
+}
+
+// AddSharePointBackupPermissionsFlag adds the flag that toggles backing
+// up the access inventory of SharePoint sites.
+func AddSharePointBackupPermissionsFlag(cmd *cobra.Command) {
+	addPermissionsFlag(
+		cmd.Flags(),
+		&sharePointBackupPermissions,
+		"backup-permissions",
+		"Backup the groups and role assignments with access to each site's libraries; overrides "+
+			"the config file's sharepoint_backup_permissions for this backup")
+}
+
+func addPermissionsFlag(fs *pflag.FlagSet, ob *optionalBool, name, usage string) {
+	fs.Var(ob, name, usage)
+	fs.Lookup(name).NoOptDefVal = "true"
+}
+
+var _ pflag.Value = new(optionalBool)
+
+// optionalBool is a boolean flag value that records whether it was set,
+// so that the flag only overrides the config file when provided.
+type optionalBool struct {
+	set bool
+	val bool
+}
+
+func (b *optionalBool) String() string {
+	return strconv.FormatBool(b.val)
+}
+
+func (b *optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+
+	b.set, b.val = true, v
+
+	return nil
+}
+
+func (b *optionalBool) Type() string {
+	return "bool"
+}
+
+// or returns the flag's value if it was set, and the fallback otherwise.
+func (b optionalBool) or(fallback bool) bool {
+	if b.set {
+		return b.val
+	}
+
+	return fallback
+}
+
+// ---------------------------------------------------------------------------
+// Mailbox Settings Flags
+// ---------------------------------------------------------------------------
+
+var restoreMailboxSettings bool
+
+// AddRestoreMailboxSettingsFlag adds the flag that opts in to restoring
+// the backed up automatic replies and working hours of each mailbox.
+func AddRestoreMailboxSettingsFlag(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(
+		&restoreMailboxSettings,
+		"restore-mailbox-settings", false,
+		"Restore the mailbox's automatic replies and working hours, replacing its current settings")
+}
+
+// ---------------------------------------------------------------------------
+// Structure Flags
+// ---------------------------------------------------------------------------
+
+var structureOnly bool
+
+// AddStructureOnlyFlag adds the flag that limits a backup to the folder
+// hierarchy of each mailbox.
+func AddStructureOnlyFlag(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(
+		&structureOnly,
+		"structure-only", false,
+		"Only back up the folder hierarchy, with the count and size of the items in each folder, "+
+			"and skip the item contents")
+}
+
+// ---------------------------------------------------------------------------
+// Restore Collision Flags
+// ---------------------------------------------------------------------------
+
+var collisions collisionPolicy
+
+// AddCollisionsFlag adds the flag that directs how restores handle items
+// which already exist in the restore destination.
+func AddCollisionsFlag(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.Var(
+		&collisions,
+		"collisions",
+		"How to restore items that already exist in the destination: "+
+			"'copy' restores another copy, 'skip' leaves the existing item, and 'replace' overwrites it; defaults to copy")
+}
+
+var _ pflag.Value = new(collisionPolicy)
+
+var collisionPolicies = map[string]control.CollisionPolicy{
+	"copy":    control.Copy,
+	"skip":    control.Skip,
+	"replace": control.Replace,
+}
+
+// collisionPolicy is a flag value that accepts the name of a collision policy.
+type collisionPolicy control.CollisionPolicy
+
+func (c *collisionPolicy) String() string {
+	if control.CollisionPolicy(*c) == control.Unknown {
+		return ""
+	}
+
+	return strings.ToLower(control.CollisionPolicy(*c).String())
+}
+
+func (c *collisionPolicy) Set(s string) error {
+	cp, ok := collisionPolicies[strings.ToLower(s)]
+	if !ok {
+		return errors.New("must be one of: copy, skip, replace")
+	}
+
+	*c = collisionPolicy(cp)
+
+	return nil
+}
+
+func (c *collisionPolicy) Type() string {
+	return "policy"
+}
+
+// ---------------------------------------------------------------------------
+// Restore Approval Flags
+// ---------------------------------------------------------------------------
+
+var approvalTimeout time.Duration
+
+// AddApprovalFlags adds the flags that control how restores wait on the
+// approval required by the repository's restore policy.
+func AddApprovalFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.DurationVar(
+		&approvalTimeout,
+		"approval-timeout", control.DefaultApprovalTimeout,
+		"How long a restore waits on approval before failing; approved restores can be resumed with --resume")
+}
+
+// ---------------------------------------------------------------------------
+// Restore Report Flags
+// ---------------------------------------------------------------------------
+
+var (
+	reportFile     string
+	reportMailFrom string
+	reportMailTo   []string
+)
+
+// AddRestoreReportFlags adds the flags that write or mail a summary of
+// each completed restore.
+func AddRestoreReportFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(
+		&reportFile,
+		"report-file", "",
+		"Write a summary of the restored items, their destination, and any failures to this file")
+	fs.StringVar(
+		&reportMailFrom,
+		"report-mail-from", "",
+		"Mailbox that sends the restore summary to the --report-mail-to addresses; requires the Mail.Send permission")
+	fs.StringSliceVar(
+		&reportMailTo,
+		"report-mail-to", nil,
+		"Addresses that receive the restore summary sent from the --report-mail-from mailbox")
+}
+
+// ---------------------------------------------------------------------------
+// Mail Restore Flags
+// ---------------------------------------------------------------------------
+
+var (
+	mailFailOverQuota     bool
+	mailSkipQuotaCheck    bool
+	mailMessagesPerMinute int
+)
+
+// AddMailRestoreFlags adds the flags that guard the mailboxes written to
+// by Exchange restores.
+func AddMailRestoreFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(
+		&mailFailOverQuota,
+		"fail-over-quota", false,
+		"Fail large restores into a full destination mailbox, instead of warning")
+	fs.BoolVar(
+		&mailSkipQuotaCheck,
+		"skip-quota-check", false,
+		"Skip checking the destination mailbox's quota before large restores")
+	fs.IntVar(
+		&mailMessagesPerMinute,
+		"messages-per-minute", 0,
+		"Restore at most this many messages per minute into each mailbox; 0 is unbounded")
+}
+
+// ---------------------------------------------------------------------------
+// Tuning Profile Flags
+// ---------------------------------------------------------------------------
+
+var (
+	tuningConfig control.TuningProfile
+	tuningFlag   tuningProfile
+)
+
+// SetTuningConfig applies the tuning profile named in the config file.
+// Flags take precedence over the config file.
+func SetTuningConfig(profile control.TuningProfile) error {
+	if len(profile) > 0 {
+		if _, err := control.Tuning(profile); err != nil {
+			return errors.Errorf(
+				"config file's tuning_profile must be one of: %s",
+				strings.Join(control.TuningProfiles(), ", "))
+		}
+	}
+
+	tuningConfig = profile
+
+	return nil
+}
+
+// AddTuningFlags adds the flag that selects the tuning profile used to
+// retrieve data from M365.
+func AddTuningFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.Var(
+		&tuningFlag,
+		"profile",
+		"Tuning of concurrency, page sizes, and retries to suit the tenant: "+
+			strings.Join(control.TuningProfiles(), ", ")+
+			"; overrides the config file's tuning_profile for this backup")
+}
+
+// tuning produces the options of the selected tuning profile, if any.
+func tuning() control.TuningOptions {
+	profile := control.TuningProfile(tuningFlag)
+	if len(profile) == 0 {
+		profile = tuningConfig
+	}
+
+	if len(profile) == 0 {
+		return control.TuningOptions{}
+	}
+
+	// profiles are validated when set, so no error can occur here.
+	to, _ := control.Tuning(profile)
+
+	return to
+}
+
+var _ pflag.Value = new(tuningProfile)
+
+// tuningProfile is a flag value that accepts the name of a tuning profile.
+type tuningProfile control.TuningProfile
+
+func (tp *tuningProfile) String() string {
+	return string(*tp)
+}
+
+func (tp *tuningProfile) Set(s string) error {
+	profile := control.TuningProfile(strings.ToLower(s))
+
+	if _, err := control.Tuning(profile); err != nil {
+		return errors.New("must be one of: " + strings.Join(control.TuningProfiles(), ", "))
+	}
+
+	*tp = tuningProfile(profile)
+
+	return nil
+}
+
+func (tp *tuningProfile) Type() string {
+	return "profile"
+}
+
+// ---------------------------------------------------------------------------
+// Disk Spill Flags
+// ---------------------------------------------------------------------------
+
+var (
+	spillDir     string
+	spillMaxSize byteSize
+)
+
+// AddSpillFlags adds the flags that configure the disk buffer used to
+// hold items awaiting upload.
+func AddSpillFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(
+		&spillDir,
+		"spill-dir", "",
+		"Directory in which items awaiting upload are temporarily buffered; defaults to the system temp directory")
+	fs.Var(
+		&spillMaxSize,
+		"spill-max-size",
+		"Maximum disk space used to buffer items awaiting upload (ex: 500MB, 2GiB); buffering is disabled if unset")
+}
+
+// ---------------------------------------------------------------------------
+// Read Cache Flags
+// ---------------------------------------------------------------------------
+
+var (
+	readCacheDir             string
+	readCacheMaxSize         byteSize
+	readCacheMaxMetadataSize = byteSize(control.DefaultMetadataCacheBytes)
+)
+
+// AddReadCacheFlags adds the flags that configure the local cache of data
+// read out of the repository.
+func AddReadCacheFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(
+		&readCacheDir,
+		"cache-dir", "",
+		"Directory that caches data read from the repository; defaults to the kopia config directory")
+	fs.Var(
+		&readCacheMaxSize,
+		"cache-max-size",
+		"Maximum disk space used to cache data read from the repository (ex: 5GB, 20GiB); "+
+			"defaults to the metadata cache's size")
+	fs.Var(
+		&readCacheMaxMetadataSize,
+		"cache-max-metadata-size",
+		"Maximum disk space used to cache the repository's backup records and snapshot metadata; 0 disables it")
+}
+
+// ---------------------------------------------------------------------------
+// Delta Cache Flags
+// ---------------------------------------------------------------------------
+
+var (
+	deltaCacheDir    string
+	deltaCacheMaxAge time.Duration
+)
+
+// AddDeltaCacheFlags adds the flags that configure the local cache of drive
+// delta pages, which lets a retried backup skip re-enumerating its drives.
+func AddDeltaCacheFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(
+		&deltaCacheDir,
+		"delta-cache-dir", "",
+		"Directory that caches drive enumeration pages; defaults to the user's cache directory")
+	fs.DurationVar(
+		&deltaCacheMaxAge,
+		"delta-cache-max-age", 0,
+		"Reuse cached drive enumeration pages younger than this age (ex: 6h, at most 24h); caching is disabled if unset")
+}
+
+// ---------------------------------------------------------------------------
+// Memory Limit Flags
+// ---------------------------------------------------------------------------
+
+var maxMemoryMB int
+
+// AddMemoryFlags adds the flag that bounds the memory used by a backup.
+func AddMemoryFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.IntVar(
+		&maxMemoryMB,
+		"max-memory-mb", 0,
+		"Memory ceiling, in megabytes, that backups throttle item retrieval to stay beneath; unbounded if unset")
+}
+
+// ---------------------------------------------------------------------------
+// Bandwidth Flags
+// ---------------------------------------------------------------------------
+
+var (
+	backupBandwidth  byteSize
+	restoreBandwidth byteSize
+)
+
+// AddBackupBandwidthFlag adds the flag that bounds the rate at which a
+// backup transfers data.
+func AddBackupBandwidthFlag(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.Var(
+		&backupBandwidth,
+		"max-bandwidth",
+		"Maximum data transferred with M365 per second during the backup (ex: 10MB, 50MiB); unbounded if unset")
 }
 
-// AddRestorePermissionsFlag adds OneDrive flag for restoring permissions
-func AddRestorePermissionsFlag(cmd *cobra.Command) {
+// AddRestoreBandwidthFlag adds the flag that bounds the rate at which a
+// restore transfers data.  Restores don't share the backup limit.
+func AddRestoreBandwidthFlag(cmd *cobra.Command) {
 	fs := cmd.Flags()
-	fs.BoolVar(&restorePermissions, "restore-permissions", false, "Restore permissions for files and folders")
-	// TODO: reveal this flag once backing up permissions becomes default
-	cobra.CheckErr(fs.MarkHidden("restore-permissions"))
+	fs.Var(
+		&restoreBandwidth,
+		"max-bandwidth",
+		"Maximum data transferred with M365 per second during the restore (ex: 10MB, 50MiB); unbounded if unset")
+}
+
+// ---------------------------------------------------------------------------
+// Heartbeat Flags
+// ---------------------------------------------------------------------------
+
+var (
+	heartbeatInterval time.Duration
+	stallTimeout      time.Duration
+	abortStalled      bool
+)
+
+// AddHeartbeatFlags adds the flags that report the progress of a backup's
+// collections, and catch collections that stop making progress.
+func AddHeartbeatFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.DurationVar(
+		&heartbeatInterval,
+		"heartbeat-interval", 0,
+		"Emit a heartbeat event with the progress of each collection at this interval (ex: 5m)")
+	fs.DurationVar(
+		&stallTimeout,
+		"stall-timeout", 0,
+		"Report a collection as stalled, with diagnostics, once its items make no progress for this long (ex: 15m)")
+	fs.BoolVar(
+		&abortStalled,
+		"abort-stalled", false,
+		"Abort stalled collections, recording a failure for each, instead of waiting on them")
+}
+
+// ---------------------------------------------------------------------------
+// Backup Alert Flags
+// ---------------------------------------------------------------------------
+
+var (
+	alertMaxDuration       time.Duration
+	alertMaxChangedItems   int
+	alertMaxChangedPercent float64
+	detectAnomalies        bool
+)
+
+// AddAlertFlags adds the flags that set the thresholds at which backups
+// emit warning events.
+func AddAlertFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.DurationVar(
+		&alertMaxDuration,
+		"alert-max-duration", 0,
+		"Emit a warning event once a backup runs longer than this duration (ex: 2h30m)")
+	fs.IntVar(
+		&alertMaxChangedItems,
+		"alert-max-changed-items", 0,
+		"Emit a warning event when an incremental backup finds at least this many added, changed, or deleted items")
+	fs.Float64Var(
+		&alertMaxChangedPercent,
+		"alert-max-changed-percent", 0,
+		"Emit a warning event when an incremental backup finds at least this percent of its items changed")
+	fs.BoolVar(
+		&detectAnomalies,
+		"detect-anomalies", false,
+		"Compare incremental backups against their bases, and warn of mass deletions or changed file extensions")
+}
+
+var _ pflag.Value = new(byteSize)
+
+// byteSize is a flag value that accepts human-readable byte sizes.
+type byteSize uint64
+
+func (b *byteSize) String() string {
+	if *b == 0 {
+		return ""
+	}
+
+	return humanize.IBytes(uint64(*b))
+}
+
+func (b *byteSize) Set(s string) error {
+	v, err := humanize.ParseBytes(s)
+	if err != nil {
+		return err
+	}
+
+	*b = byteSize(v)
+
+	return nil
+}
+
+func (b *byteSize) Type() string {
+	return "size"
 }
 
 // ---------------------------------------------------------------------------
@@ -57,8 +700,10 @@ func AddRestorePermissionsFlag(cmd *cobra.Command) {
 // ---------------------------------------------------------------------------
 
 var (
-	disableIncrementals     bool
-	enablePermissionsBackup bool
+	disableIncrementals   bool
+	rawDriveEnumeration   bool
+	simulateExpiredDeltas bool
+	disableHashSkip       bool
 )
 
 type exposeFeatureFlag func(*pflag.FlagSet)
@@ -85,14 +730,54 @@ func DisableIncrementals() func(*pflag.FlagSet) {
 	}
 }
 
+// Adds the hidden '--raw-drive-enumeration' cli flag which, when set,
+// enumerates OneDrive and SharePoint drives without the graph sdk's models.
+func RawDriveEnumeration() func(*pflag.FlagSet) {
+	return func(fs *pflag.FlagSet) {
+		fs.BoolVar(
+			&rawDriveEnumeration,
+			"raw-drive-enumeration",
+			false,
+			"Decode drive enumeration responses directly, lowering cpu and memory use on large drives.")
+		cobra.CheckErr(fs.MarkHidden("raw-drive-enumeration"))
+	}
+}
+
+// Adds the hidden '--simulate-expired-deltas' cli flag which, when set,
+// treats every previous delta token as expired.
+func SimulateExpiredDeltas() func(*pflag.FlagSet) {
+	return func(fs *pflag.FlagSet) {
+		fs.BoolVar(
+			&simulateExpiredDeltas,
+			"simulate-expired-deltas",
+			false,
+			"Treat previous delta tokens as expired, exercising the fallback to a full enumeration.")
+		cobra.CheckErr(fs.MarkHidden("simulate-expired-deltas"))
+	}
+}
+
+// Adds the hidden '--disable-hash-skip' cli flag which, when set,
+// downloads changed drive files even if their content is unchanged.
+func DisableHashSkip() func(*pflag.FlagSet) {
+	return func(fs *pflag.FlagSet) {
+		fs.BoolVar(
+			&disableHashSkip,
+			"disable-hash-skip",
+			false,
+			"Download changed files even when their content hash matches the previous backup.")
+		cobra.CheckErr(fs.MarkHidden("disable-hash-skip"))
+	}
+}
+
 // Adds the hidden '--enable-permissions-backup' cli flag which, when
-// set, enables backing up permissions.
+// set, enables backing up permissions for OneDrive.  Retained for
+// compatibility; superseded by '--backup-permissions'.
 func EnablePermissionsBackup() func(*pflag.FlagSet) {
 	return func(fs *pflag.FlagSet) {
-		fs.BoolVar(
-			&enablePermissionsBackup,
+		addPermissionsFlag(
+			fs,
+			&oneDriveBackupPermissions,
 			"enable-permissions-backup",
-			false,
 			"Enable backing up item permissions for OneDrive")
 		cobra.CheckErr(fs.MarkHidden("enable-permissions-backup"))
 	}
@@ -0,0 +1,294 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type OptionsUnitSuite struct {
+	tester.Suite
+}
+
+func TestOptionsUnitSuite(t *testing.T) {
+	suite.Run(t, &OptionsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *OptionsUnitSuite) TestPermissions() {
+	var (
+		backupRestore = control.PermissionToggles{Backup: true, Restore: true}
+		backupOnly    = control.PermissionToggles{Backup: true}
+	)
+
+	table := []struct {
+		name   string
+		config control.PermissionOptions
+		args   []string
+		expect control.PermissionOptions
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:   "config only",
+			config: control.PermissionOptions{OneDrive: backupRestore},
+			expect: control.PermissionOptions{OneDrive: backupRestore},
+		},
+		{
+			name:   "flags only",
+			args:   []string{"--backup-permissions", "--restore-permissions"},
+			expect: control.PermissionOptions{OneDrive: backupRestore},
+		},
+		{
+			name:   "flag disables config",
+			config: control.PermissionOptions{OneDrive: backupRestore},
+			args:   []string{"--restore-permissions=false"},
+			expect: control.PermissionOptions{OneDrive: backupOnly},
+		},
+		{
+			name:   "deprecated backup flag",
+			args:   []string{"--enable-permissions-backup"},
+			expect: control.PermissionOptions{OneDrive: backupOnly},
+		},
+		{
+			name:   "permissions only",
+			args:   []string{"--permissions-only"},
+			expect: control.PermissionOptions{OneDrive: control.PermissionToggles{RestoreOnly: true}},
+		},
+		{
+			name:   "other services are unaffected",
+			config: control.PermissionOptions{SharePoint: backupOnly},
+			args:   []string{"--backup-permissions"},
+			expect: control.PermissionOptions{OneDrive: backupOnly, SharePoint: backupOnly},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			oneDriveBackupPermissions = optionalBool{}
+			oneDriveRestorePermissions = optionalBool{}
+			sharePointBackupPermissions = optionalBool{}
+			oneDrivePermissionsOnly = false
+
+			SetPermissionsConfig(test.config)
+			defer SetPermissionsConfig(control.PermissionOptions{})
+
+			cmd := &cobra.Command{Use: "test"}
+			AddOneDriveBackupPermissionsFlag(cmd)
+			AddOneDriveRestorePermissionsFlag(cmd)
+			AddOneDrivePermissionsOnlyFlag(cmd)
+			AddFeatureToggle(cmd, EnablePermissionsBackup())
+
+			require.NoError(t, cmd.ParseFlags(test.args))
+			assert.Equal(t, test.expect, Control().Permissions)
+		})
+	}
+}
+
+func (suite *OptionsUnitSuite) TestTuning() {
+	throttled, err := control.Tuning(control.ThrottledProfile)
+	require.NoError(suite.T(), err)
+
+	large, err := control.Tuning(control.LargeTenantProfile)
+	require.NoError(suite.T(), err)
+
+	table := []struct {
+		name      string
+		config    control.TuningProfile
+		args      []string
+		expect    control.TuningOptions
+		configErr assert.ErrorAssertionFunc
+		flagErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "defaults",
+			configErr: assert.NoError,
+			flagErr:   assert.NoError,
+		},
+		{
+			name:      "config only",
+			config:    control.ThrottledProfile,
+			expect:    throttled,
+			configErr: assert.NoError,
+			flagErr:   assert.NoError,
+		},
+		{
+			name:      "flag overrides config",
+			config:    control.ThrottledProfile,
+			args:      []string{"--profile", "Large-Tenant"},
+			expect:    large,
+			configErr: assert.NoError,
+			flagErr:   assert.NoError,
+		},
+		{
+			name:      "unknown config profile",
+			config:    "huge-tenant",
+			configErr: assert.Error,
+			flagErr:   assert.NoError,
+		},
+		{
+			name:      "unknown flag profile",
+			args:      []string{"--profile", "huge-tenant"},
+			configErr: assert.NoError,
+			flagErr:   assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			tuningFlag = ""
+
+			test.configErr(t, SetTuningConfig(test.config))
+			defer func() { tuningConfig = "" }()
+
+			cmd := &cobra.Command{Use: "test"}
+			AddTuningFlags(cmd)
+
+			err := cmd.ParseFlags(test.args)
+			test.flagErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expect, Control().Tuning)
+		})
+	}
+}
+
+func (suite *OptionsUnitSuite) TestBandwidth() {
+	table := []struct {
+		name    string
+		addFlag func(*cobra.Command)
+		args    []string
+		expect  control.BandwidthOptions
+	}{
+		{
+			name:    "unbounded",
+			addFlag: AddBackupBandwidthFlag,
+		},
+		{
+			name:    "backup",
+			addFlag: AddBackupBandwidthFlag,
+			args:    []string{"--max-bandwidth", "10MB"},
+			expect:  control.BandwidthOptions{BackupBytesPerSecond: 10_000_000},
+		},
+		{
+			name:    "restore",
+			addFlag: AddRestoreBandwidthFlag,
+			args:    []string{"--max-bandwidth", "1MiB"},
+			expect:  control.BandwidthOptions{RestoreBytesPerSecond: 1 << 20},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			defer func() {
+				backupBandwidth = 0
+				restoreBandwidth = 0
+			}()
+
+			cmd := &cobra.Command{Use: "test"}
+			test.addFlag(cmd)
+
+			require.NoError(t, cmd.ParseFlags(test.args))
+			assert.Equal(t, test.expect, Control().Bandwidth)
+		})
+	}
+}
+
+func (suite *OptionsUnitSuite) TestDeltaCache() {
+	t := suite.T()
+
+	defer func() {
+		deltaCacheDir = ""
+		deltaCacheMaxAge = 0
+	}()
+
+	cmd := &cobra.Command{Use: "test"}
+	AddDeltaCacheFlags(cmd)
+
+	require.NoError(t, cmd.ParseFlags([]string{"--delta-cache-dir", "/tmp/delta", "--delta-cache-max-age", "6h"}))
+
+	dco := Control().DeltaCache
+	assert.Equal(t, control.DeltaCacheOptions{Dir: "/tmp/delta", MaxAge: 6 * time.Hour}, dco)
+	assert.True(t, dco.Enabled())
+}
+
+func (suite *OptionsUnitSuite) TestRestoreReport() {
+	t := suite.T()
+
+	defer func() {
+		reportFile = ""
+		reportMailFrom = ""
+		reportMailTo = nil
+	}()
+
+	cmd := &cobra.Command{Use: "test"}
+	AddRestoreReportFlags(cmd)
+
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--report-file", "report.txt",
+		"--report-mail-from", "svc@contoso.com",
+		"--report-mail-to", "a@contoso.com,b@contoso.com",
+	}))
+
+	rro := Control().RestoreReport
+	assert.Equal(
+		t,
+		control.RestoreReportOptions{
+			File:     "report.txt",
+			MailFrom: "svc@contoso.com",
+			MailTo:   []string{"a@contoso.com", "b@contoso.com"},
+		},
+		rro)
+	assert.True(t, rro.Mails())
+}
+
+func (suite *OptionsUnitSuite) TestReadCache_metadata() {
+	table := []struct {
+		name   string
+		args   []string
+		expect int64
+	}{
+		{
+			name:   "default",
+			expect: control.DefaultMetadataCacheBytes,
+		},
+		{
+			name:   "disabled",
+			args:   []string{"--cache-max-metadata-size", "0"},
+			expect: 0,
+		},
+		{
+			name:   "configured",
+			args:   []string{"--cache-max-metadata-size", "2GiB"},
+			expect: 2 << 30,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			defer func() {
+				readCacheMaxMetadataSize = byteSize(control.DefaultMetadataCacheBytes)
+			}()
+
+			cmd := &cobra.Command{Use: "test"}
+			AddReadCacheFlags(cmd)
+
+			require.NoError(t, cmd.ParseFlags(test.args))
+			assert.Equal(t, test.expect, Control().ReadCache.MaxMetadataBytes)
+		})
+	}
+}
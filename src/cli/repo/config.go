@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	. "github.com/alcionai/corso/src/cli/print"
+)
+
+const (
+	configCommand = "config"
+	exportCommand = "export"
+	importCommand = "import"
+)
+
+// flag values for `corso repo config *`
+var (
+	configFile      string
+	configOverwrite bool
+)
+
+// The repo config subcommand.
+// `corso repo config <subcommand> [<flag>...]`
+func configCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   configCommand,
+		Short: "Export or import the Corso configuration.",
+		Long: `Export or import Corso's own configuration, so that it can be versioned
+and recreated on a new host.  Backup data is not included, and secrets are never
+written to the config file, so they are never exported.`,
+		RunE: handleConfigCmd,
+		Args: cobra.NoArgs,
+	}
+
+	c.AddCommand(configExportCmd())
+	c.AddCommand(configImportCmd())
+
+	return c
+}
+
+// Handler for calls to `corso repo config`.
+func handleConfigCmd(cmd *cobra.Command, args []string) error {
+	return cmd.Help()
+}
+
+// `corso repo config export --file <file>`
+func configExportCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   exportCommand,
+		Short: "Export the Corso configuration to a file.",
+		RunE:  handleConfigExportCmd,
+		Args:  cobra.NoArgs,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(&configFile, "file", "", "File that receives the exported configuration. (required)")
+	cobra.CheckErr(c.MarkFlagRequired("file"))
+
+	return c
+}
+
+// Handler for calls to `corso repo config export`.
+func handleConfigExportCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	exp, err := config.ExportConfig(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to export the configuration"))
+	}
+
+	f, err := os.OpenFile(configFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to create the export file"))
+	}
+
+	defer f.Close()
+
+	if err := config.WriteExport(f, exp); err != nil {
+		return Only(ctx, err)
+	}
+
+	Infof(ctx, "Exported %d configuration settings to %s", len(exp.Settings), configFile)
+
+	return nil
+}
+
+// `corso repo config import --file <file> [--overwrite]`
+func configImportCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   importCommand,
+		Short: "Import the Corso configuration from a file.",
+		Long: `Write the settings in an exported configuration to the Corso config file.
+Settings which already hold a different value are not replaced unless --overwrite is set.`,
+		RunE: handleConfigImportCmd,
+		Args: cobra.NoArgs,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(&configFile, "file", "", "File that holds the exported configuration. (required)")
+	cobra.CheckErr(c.MarkFlagRequired("file"))
+	fs.BoolVar(
+		&configOverwrite,
+		"overwrite", false,
+		"Replace config file settings that differ from the imported values.")
+
+	return c
+}
+
+// Handler for calls to `corso repo config import`.
+func handleConfigImportCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	f, err := os.Open(configFile)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to open the export file"))
+	}
+
+	defer f.Close()
+
+	exp, err := config.ReadExport(f)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	if err := config.ImportConfig(ctx, exp, configOverwrite); err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to import the configuration"))
+	}
+
+	Infof(ctx, "Imported %d configuration settings", len(exp.Settings))
+
+	return nil
+}
@@ -1,14 +1,79 @@
 package repo
 
 import (
+	"context"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/repository"
 )
 
 const (
 	initCommand    = "init"
 	connectCommand = "connect"
+	compactCommand = "compact"
+	eraseCommand   = "erase"
+	policyCommand  = "restore-policy"
+	quotaCommand   = "quota"
+	piiCommand     = "pii-handling"
+	rebuildCommand = "rebuild-models"
+	warmCommand    = "warm"
+	cloneCommand   = "clone-config"
+	catalogCommand = "sync-catalog"
 )
 
+// flag values for `corso repo compact`
+var (
+	keepIncomplete   bool
+	keepUnreferenced bool
+	minAge           = control.DefaultMetadataMinAge
+)
+
+// flag values for `corso repo init` and `corso repo erase`
+var (
+	ownerEncryption bool
+	eraseOwner      string
+	eraseForce      bool
+)
+
+// flag values for `corso repo restore-policy`
+var (
+	policySubtree      string
+	policyPrefix       string
+	policyPattern      string
+	policyAllowInPlace bool
+	policyClear        bool
+	approvalMaxItems   int
+	approvalMaxBytes   string
+	approvalWebhook    string
+)
+
+// flag values for `corso repo quota`
+var (
+	quotaSoftLimit       string
+	quotaHardLimit       string
+	quotaFailAtHardLimit bool
+	quotaClear           bool
+)
+
+// flag values for `corso repo pii-handling`
+var piiClear bool
+
+// flag values for `corso repo rebuild-models`
+var rebuildDryRun bool
+
+// flag values for `corso repo clone-config`
+var clonePrefix string
+
 var repoCommands = []func(cmd *cobra.Command) *cobra.Command{
 	addS3Commands,
 }
@@ -26,6 +91,16 @@ func AddCommands(cmd *cobra.Command) {
 	cmd.AddCommand(repoCmd)
 	repoCmd.AddCommand(initCmd)
 	repoCmd.AddCommand(connectCmd)
+	repoCmd.AddCommand(compactCmd())
+	repoCmd.AddCommand(eraseCmd())
+	repoCmd.AddCommand(restorePolicyCmd())
+	repoCmd.AddCommand(quotaCmd())
+	repoCmd.AddCommand(piiHandlingCmd())
+	repoCmd.AddCommand(rebuildModelsCmd())
+	repoCmd.AddCommand(warmCmd())
+	repoCmd.AddCommand(syncCatalogCmd())
+	repoCmd.AddCommand(configCmd())
+	repoCmd.AddCommand(cloneConfigCmd())
 
 	for _, addRepoTo := range repoCommands {
 		addRepoTo(initCmd)
@@ -84,3 +159,796 @@ func connectCmd() *cobra.Command {
 func handleConnectCmd(cmd *cobra.Command, args []string) error {
 	return cmd.Help()
 }
+
+// The repo compact subcommand.
+// `corso repo compact [<flag>...]`
+func compactCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   compactCommand,
+		Short: "Remove unused backup metadata.",
+		Long: `Remove backup snapshots, and the metadata they hold, which are no longer
+referenced by a backup or used as the base of an incremental backup.`,
+		RunE: handleCompactCmd,
+		Args: cobra.NoArgs,
+	}
+
+	fs := c.Flags()
+	fs.BoolVar(
+		&keepIncomplete,
+		"keep-incomplete", false,
+		"Retain incomplete snapshots that have been replaced by a newer, complete snapshot.")
+	fs.BoolVar(
+		&keepUnreferenced,
+		"keep-unreferenced", false,
+		"Retain complete snapshots that are not referenced by any backup.")
+	fs.DurationVar(
+		&minAge,
+		"min-age", control.DefaultMetadataMinAge,
+		"Only remove snapshots older than the duration.")
+
+	return c
+}
+
+// Handler for calls to `corso repo compact`.
+func handleCompactCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	rules := control.MetadataRetention{
+		KeepIncomplete:   keepIncomplete,
+		KeepUnreferenced: keepUnreferenced,
+		MinAge:           minAge,
+	}
+
+	pruned, err := r.CompactMetadata(ctx, rules)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to compact the repository"))
+	}
+
+	Infof(ctx, "Removed %d unused backup snapshots", len(pruned))
+
+	return nil
+}
+
+const eraseCommandExamples = `# Erase all of the data backed up for user Alice
+corso repo erase --owner alice@example.com
+
+# Erase Alice's data even though the bucket keeps deleted objects
+corso repo erase --owner alice@example.com --force`
+
+// The repo erase subcommand.
+// `corso repo erase --owner <owner> [<flag>...]`
+func eraseCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   eraseCommand,
+		Short: "Erase a resource owner's data.",
+		Long: `Destroy the encryption keys of a user's or site's data, which makes the contents
+of their items unreadable in every backup.  Backup details, such as file names,
+paths, email subjects, and senders, aren't encrypted with the owner's keys, and
+remain readable.  Only available in repositories initialized with
+--owner-encryption.  Erasure cannot be undone.
+
+Erasure is refused when the bucket keeps deleted objects, through versioning
+or an object lock retention, since the erased keys stay recoverable from
+the bucket.  --force erases the keys anyway.`,
+		RunE:    handleEraseCmd,
+		Args:    cobra.NoArgs,
+		Example: eraseCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(&eraseOwner, "owner", "", "ID of the user or site whose data is erased. (required)")
+	cobra.CheckErr(c.MarkFlagRequired("owner"))
+	fs.BoolVar(
+		&eraseForce,
+		"force", false,
+		"Erase the data even though the bucket keeps deleted objects, which leaves the data recoverable.")
+
+	return c
+}
+
+// Handler for calls to `corso repo erase`.
+func handleEraseCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	n, pf, err := r.EraseOwner(ctx, eraseOwner, eraseForce)
+
+	if len(pf) > 0 {
+		printPreflight(ctx, pf)
+	}
+
+	if errors.Is(err, repository.ErrorErasureRetained) {
+		return Only(ctx, errors.Errorf(
+			"Refusing to erase the data of %s: the bucket keeps deleted objects, so the data would remain "+
+				"recoverable.  Resolve the retention above, or rerun with --force.",
+			eraseOwner))
+	}
+
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to erase the data of %s", eraseOwner))
+	}
+
+	if n == 0 {
+		Infof(ctx, "No backed up data found for %s", eraseOwner)
+		return nil
+	}
+
+	Infof(ctx, "Erased the item contents of %s from all backups", eraseOwner)
+
+	if pf.Warned() {
+		Infof(ctx, "WARNING: the erased keys of %s remain recoverable from the bucket's retained objects "+
+			"until the retention above is resolved.", eraseOwner)
+	}
+
+	return nil
+}
+
+const restorePolicyCommandExamples = `# Show the repository's restore policy
+corso repo restore-policy
+
+# Only allow restores into containers named like corso's default restore containers
+corso repo restore-policy --container-prefix Corso_Restore_
+
+# Only allow restores beneath the "Corso Restores" folder
+corso repo restore-policy --container-subtree "Corso Restores"
+
+# Hold restores of more than 1000 items or 10GB until they're approved
+corso repo restore-policy --container-prefix Corso_Restore_ \
+      --approval-max-items 1000 --approval-max-bytes 10GB
+
+# Remove all restrictions on restore destinations
+corso repo restore-policy --clear`
+
+// The repo restore-policy subcommand.
+// `corso repo restore-policy [<flag>...]`
+func restorePolicyCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   policyCommand,
+		Short: "Show or set the repository's restore policy.",
+		Long: `Restrict the destinations that restores from the repository can write to.  Every restore
+creates its items beneath a single root container, and the policy constrains where that
+root is created and how it's named, so that restores never write into production folders.
+Restores into a destination outside of the policy fail before anything is restored.
+
+With --container-subtree, restores must name their destination as a path beneath the
+subtree, such as --destination-folder "Corso Restores/Corso_Restore_1".  Calendars, task
+lists, and SharePoint lists and pages can't be nested, so they can't be restored under
+a subtree policy.
+
+The policy can also hold restores above a size threshold until they're approved with
+'corso restore approve' by someone other than the person who ran the restore.  While
+approval is configured, restores that write to the original items always need approval.
+Setting the policy replaces all of its rules.
+
+Restores made by the CLI use root containers named Corso_Restore_<timestamp>, or
+Corso_Quarantine_Expires_<timestamp> when quarantined.  Without flags, the current
+policy is shown.`,
+		RunE:    handleRestorePolicyCmd,
+		Args:    cobra.NoArgs,
+		Example: restorePolicyCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&policySubtree,
+		"container-subtree", "",
+		"Require each restore's root container to be created directly beneath this \"/\"-separated folder path.")
+	fs.StringVar(
+		&policyPrefix,
+		"container-prefix", "",
+		"Require the name of each restore's root container to start with this prefix.")
+	fs.StringVar(
+		&policyPattern,
+		"container-pattern", "",
+		"Require the name of each restore's root container to entirely match this regular expression.")
+	fs.BoolVar(
+		&policyAllowInPlace,
+		"allow-in-place", false,
+		"Permit restores that write to the original items, such as permissions-only restores.")
+	fs.IntVar(
+		&approvalMaxItems,
+		"approval-max-items", 0,
+		"Hold restores of more than this many items until they're approved with 'corso restore approve'.")
+	fs.StringVar(
+		&approvalMaxBytes,
+		"approval-max-bytes", "",
+		"Hold restores of more than this much data (ex: 10GB) until they're approved with 'corso restore approve'.")
+	fs.StringVar(
+		&approvalWebhook,
+		"approval-webhook", "",
+		"URL that receives a POST describing each restore that is waiting on approval.")
+	fs.BoolVar(
+		&policyClear,
+		"clear", false,
+		"Remove the restore policy, permitting restores to any destination.")
+
+	return c
+}
+
+// Handler for calls to `corso repo restore-policy`.
+func handleRestorePolicyCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	setting := len(policySubtree) > 0 ||
+		len(policyPrefix) > 0 ||
+		len(policyPattern) > 0 ||
+		policyAllowInPlace ||
+		approvalMaxItems > 0 ||
+		len(approvalMaxBytes) > 0 ||
+		len(approvalWebhook) > 0
+
+	if setting && policyClear {
+		return Only(ctx, errors.New("--clear can't be combined with other restore policy flags"))
+	}
+
+	var maxBytes uint64
+
+	if len(approvalMaxBytes) > 0 {
+		mb, err := humanize.ParseBytes(approvalMaxBytes)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Invalid --approval-max-bytes"))
+		}
+
+		maxBytes = mb
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if setting || policyClear {
+		rules := control.RestorePolicy{
+			ContainerSubtree: policySubtree,
+			ContainerPrefix:  policyPrefix,
+			ContainerPattern: policyPattern,
+			AllowInPlace:     policyAllowInPlace,
+			Approval: control.ApprovalPolicy{
+				MaxItems: approvalMaxItems,
+				MaxBytes: int64(maxBytes),
+				Webhook:  approvalWebhook,
+			},
+		}
+
+		if err := r.SetRestorePolicy(ctx, rules); err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to set the restore policy"))
+		}
+	}
+
+	p, err := r.RestorePolicy(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to retrieve the restore policy"))
+	}
+
+	printApprovalPolicy(ctx, p.Approval)
+
+	if p.IsEmpty() {
+		Info(ctx, "Restores may write to any destination")
+		return nil
+	}
+
+	if len(p.ContainerSubtree) > 0 {
+		Infof(ctx, "Restore containers must be created beneath: %s", p.ContainerSubtree)
+	}
+
+	if len(p.ContainerPrefix) > 0 {
+		Infof(ctx, "Restore containers must start with: %s", p.ContainerPrefix)
+	}
+
+	if len(p.ContainerPattern) > 0 {
+		Infof(ctx, "Restore containers must match: %s", p.ContainerPattern)
+	}
+
+	if p.AllowInPlace {
+		Info(ctx, "Restores may write to the original items")
+	} else {
+		Info(ctx, "Restores may not write to the original items")
+	}
+
+	return nil
+}
+
+func printApprovalPolicy(ctx context.Context, ap control.ApprovalPolicy) {
+	if !ap.Enabled() {
+		Info(ctx, "Restores don't need approval")
+		return
+	}
+
+	if ap.MaxItems > 0 {
+		Infof(ctx, "Restores of more than %d items need approval", ap.MaxItems)
+	}
+
+	if ap.MaxBytes > 0 {
+		Infof(ctx, "Restores of more than %s need approval", humanize.Bytes(uint64(ap.MaxBytes)))
+	}
+
+	if len(ap.Webhook) > 0 {
+		Infof(ctx, "Approval requests are posted to: %s", ap.Webhook)
+	}
+}
+
+const quotaCommandExamples = `# Show the repository's quota and its last known size
+corso repo quota
+
+# Warn when backups bring the repository past 500GB, and stop backups at 1TB
+corso repo quota --soft-limit 500GB --hard-limit 1TB --fail-at-hard-limit
+
+# Remove the quota
+corso repo quota --clear`
+
+// The repo quota subcommand.
+// `corso repo quota [<flag>...]`
+func quotaCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   quotaCommand,
+		Short: "Show or set the repository's size quota.",
+		Long: `Bound the size of the repository's storage.  Backups that bring the repository past
+a limit produce a warning.  With --fail-at-hard-limit, backups fail without retrieving
+any data once the repository has reached the hard limit.  The quota is stored in the
+repository, so every backup into it is held to the same limits.
+
+The repository's size is measured at most once a day, and the bytes uploaded by each
+backup are added to it in between.  Setting the quota replaces both of its limits.
+Without flags, the current quota is shown.`,
+		RunE:    handleQuotaCmd,
+		Args:    cobra.NoArgs,
+		Example: quotaCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&quotaSoftLimit,
+		"soft-limit", "",
+		"Repository size at which backups produce a warning (ex: 500GB, 2TiB).")
+	fs.StringVar(
+		&quotaHardLimit,
+		"hard-limit", "",
+		"Repository size that backups should not exceed (ex: 500GB, 2TiB).")
+	fs.BoolVar(
+		&quotaFailAtHardLimit,
+		"fail-at-hard-limit", false,
+		"Fail backups without retrieving any data once the repository has reached the hard limit.")
+	fs.BoolVar(
+		&quotaClear,
+		"clear", false,
+		"Remove the quota, leaving the repository's size unbounded.")
+
+	return c
+}
+
+// Handler for calls to `corso repo quota`.
+func handleQuotaCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	setting := len(quotaSoftLimit) > 0 || len(quotaHardLimit) > 0 || quotaFailAtHardLimit
+
+	if setting && quotaClear {
+		return Only(ctx, errors.New("--clear can't be combined with other quota flags"))
+	}
+
+	limits := control.QuotaOptions{FailAtHardLimit: quotaFailAtHardLimit}
+
+	if len(quotaSoftLimit) > 0 {
+		b, err := humanize.ParseBytes(quotaSoftLimit)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Invalid --soft-limit"))
+		}
+
+		limits.SoftLimitBytes = int64(b)
+	}
+
+	if len(quotaHardLimit) > 0 {
+		b, err := humanize.ParseBytes(quotaHardLimit)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Invalid --hard-limit"))
+		}
+
+		limits.HardLimitBytes = int64(b)
+	}
+
+	if limits.FailAtHardLimit && limits.HardLimitBytes == 0 {
+		return Only(ctx, errors.New("--fail-at-hard-limit requires --hard-limit"))
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if setting || quotaClear {
+		if err := r.SetQuota(ctx, limits); err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to set the repository quota"))
+		}
+	}
+
+	q, err := r.Quota(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to retrieve the repository quota"))
+	}
+
+	if !q.Limits.Enabled() {
+		Info(ctx, "The repository's size is unbounded")
+		return nil
+	}
+
+	if q.Limits.SoftLimitBytes > 0 {
+		Infof(ctx, "Backups warn when the repository reaches %s", humanize.Bytes(uint64(q.Limits.SoftLimitBytes)))
+	}
+
+	if q.Limits.HardLimitBytes > 0 {
+		action := "warn"
+		if q.Limits.FailAtHardLimit {
+			action = "fail"
+		}
+
+		Infof(ctx, "Backups %s when the repository reaches %s", action, humanize.Bytes(uint64(q.Limits.HardLimitBytes)))
+	}
+
+	if !q.MeasuredAt.IsZero() {
+		Infof(
+			ctx,
+			"The repository held %s after its last backup (measured %s)",
+			humanize.Bytes(uint64(q.Size)),
+			humanize.Time(q.MeasuredAt))
+	}
+
+	return nil
+}
+
+const piiHandlingCommandExamples = `# Show how the repository renders pii in logs
+corso repo pii-handling
+
+# Hash user identifiers, item names, and urls in the logs of every connection to the repository
+corso repo pii-handling hash
+
+# Remove the repository's pii handling
+corso repo pii-handling --clear`
+
+// The repo pii-handling subcommand.
+// `corso repo pii-handling [plaintext|hash|mask] [<flag>...]`
+func piiHandlingCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   piiCommand + " [plaintext|hash|mask]",
+		Short: "Show or set how the repository renders pii in logs.",
+		Long: `Set how user identifiers, item names, and urls get rendered in the logs of every
+process connected to the repository.  'plaintext' leaves them unmodified, 'hash'
+replaces them with a stable hash, and 'mask' replaces them with '***'.  The
+--pii-handling flag overrides the repository's policy.  Without a policy, pii is
+logged as plaintext.  Without arguments, the current policy is shown.`,
+		RunE:      handlePIIHandlingCmd,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{string(logger.PIIPlainText), string(logger.PIIHash), string(logger.PIIMask)},
+		Example:   piiHandlingCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.BoolVar(
+		&piiClear,
+		"clear", false,
+		"Remove the repository's pii handling, leaving it to the --pii-handling flag.")
+
+	return c
+}
+
+// Handler for calls to `corso repo pii-handling`.
+func handlePIIHandlingCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var h logger.PIIHandling
+
+	if len(args) > 0 {
+		if piiClear {
+			return Only(ctx, errors.New("--clear can't be combined with a pii handling"))
+		}
+
+		h = logger.PIIHandling(args[0])
+
+		if err := logger.ValidatePIIHandling(h); err != nil {
+			return Only(ctx, err)
+		}
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if len(h) > 0 || piiClear {
+		if err := r.SetPIIHandling(ctx, h); err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to set the pii handling"))
+		}
+	}
+
+	h, err = r.PIIHandling(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to retrieve the pii handling"))
+	}
+
+	if len(h) == 0 {
+		Info(ctx, "The repository doesn't set a pii handling; logs render pii as set by --pii-handling")
+		return nil
+	}
+
+	Infof(ctx, "Logs render pii as: %s", h)
+
+	return nil
+}
+
+const rebuildModelsCommandExamples = `# Show the backups that can be rebuilt, without storing them
+corso repo rebuild-models --dry-run
+
+# Rebuild the backups missing from the repository
+corso repo rebuild-models`
+
+// The repo rebuild-models subcommand.
+// `corso repo rebuild-models [<flag>...]`
+func rebuildModelsCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   rebuildCommand,
+		Short: "Rebuild missing backups from the repository's snapshots.",
+		Long: `Recover backups whose records were lost or damaged.  Every complete backup snapshot
+in the repository without a backup record is paired with the snapshot holding its
+details, and its backup record is recreated.  Backups whose details can't be found
+are reported, and left as they are.
+
+Rebuilt backups select all of their user's or site's data, and are annotated as
+rebuilt.  Errors recorded by the original backup aren't recovered.`,
+		RunE:    handleRebuildModelsCmd,
+		Args:    cobra.NoArgs,
+		Example: rebuildModelsCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.BoolVar(
+		&rebuildDryRun,
+		"dry-run", false,
+		"Show the backups that would be rebuilt, without storing them.")
+
+	return c
+}
+
+// Handler for calls to `corso repo rebuild-models`.
+func handleRebuildModelsCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	rebuilt, err := r.RebuildModels(ctx, rebuildDryRun)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to rebuild the repository's backups"))
+	}
+
+	for _, id := range rebuilt.Unrecoverable {
+		Infof(ctx, "Could not find the details of backup %s", id)
+	}
+
+	if len(rebuilt.Backups) == 0 {
+		Info(ctx, "No backups to rebuild")
+		return nil
+	}
+
+	backup.PrintAll(ctx, rebuilt.Backups)
+
+	if rebuildDryRun {
+		Infof(ctx, "%d backups can be rebuilt", len(rebuilt.Backups))
+		return nil
+	}
+
+	Infof(ctx, "Rebuilt %d backups", len(rebuilt.Backups))
+
+	return nil
+}
+
+const warmCommandExamples = `# Load the repository's indexes and backup records into the local cache
+corso repo warm
+
+# Warm a cache kept in a specific directory
+corso repo warm --cache-dir /var/cache/corso`
+
+// The repo warm subcommand.
+// `corso repo warm [<flag>...]`
+func warmCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   warmCommand,
+		Short: "Load the repository's metadata into the local cache.",
+		Long: `Retrieve the repository's indexes and backup records into the local cache, so that
+later commands, such as listing or describing backups, load them from disk instead
+of from storage.  Indexes already in the cache are verified, and corrupted ones are
+removed, to be retrieved again on the next connection.`,
+		RunE:    handleWarmCmd,
+		Args:    cobra.NoArgs,
+		Example: warmCommandExamples,
+	}
+
+	options.AddReadCacheFlags(c)
+
+	return c
+}
+
+// Handler for calls to `corso repo warm`.
+func handleWarmCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	cs, err := r.WarmCache(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to warm the repository cache"))
+	}
+
+	if cs.RemovedIndexes > 0 {
+		Infof(ctx, "Removed %d corrupted indexes from the cache", cs.RemovedIndexes)
+	}
+
+	Infof(ctx, "Cached %d indexes and %d manifests", cs.Indexes, cs.Manifests)
+
+	return nil
+}
+
+const syncCatalogCommandExamples = `# Mirror the records of all backups to the configured backup catalog
+corso repo sync-catalog`
+
+// The repo sync-catalog subcommand.
+// `corso repo sync-catalog`
+func syncCatalogCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   catalogCommand,
+		Short: "Mirror all backup records to the backup catalog.",
+		Long: `Write the records of all of the repository's backups to the external backup
+catalog configured in the corso config file.  Existing records are replaced.  Use
+it after configuring a catalog for a repository that already has backups, or
+after the catalog was unreachable during backups.`,
+		RunE:    handleSyncCatalogCmd,
+		Args:    cobra.NoArgs,
+		Example: syncCatalogCommandExamples,
+	}
+}
+
+// Handler for calls to `corso repo sync-catalog`.
+func handleSyncCatalogCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	n, err := r.SyncCatalog(ctx)
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to sync the backup catalog after %d records", n))
+	}
+
+	Infof(ctx, "Synced %d backup records to the catalog", n)
+
+	return nil
+}
+
+const cloneConfigCommandExamples = `# Create a staging repository beneath the staging/ prefix of the repository's bucket
+corso repo clone-config --prefix staging/
+
+# Run backups in the staging repository
+corso repo connect s3 --bucket my-bucket --prefix staging/ --config-file staging.toml
+corso backup create exchange --user '*' --config-file staging.toml`
+
+// The repo clone-config subcommand.
+// `corso repo clone-config --prefix <prefix>`
+func cloneConfigCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   cloneCommand,
+		Short: "Create a staging repository configured like this repository.",
+		Long: `Initialize a new repository beneath another prefix of the repository's bucket, carrying
+over the repository's configuration: owner encryption, when enabled, the restore
+policy, and the quota limits.  Backups are not copied.  Backups created in the staging repository are tagged
+as staging backups, so that upgrades and policy changes can be rehearsed against a
+production-like repository without being mistaken for production backups.
+
+The staging repository uses the same passphrase as the repository.  Connect to it with
+'corso repo connect' and the new prefix, ideally with a separate config file.`,
+		RunE:    handleCloneConfigCmd,
+		Args:    cobra.NoArgs,
+		Example: cloneConfigCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(&clonePrefix, "prefix", "", "Bucket prefix of the staging repository. (required)")
+	cobra.CheckErr(c.MarkFlagRequired("prefix"))
+
+	return c
+}
+
+// Handler for calls to `corso repo clone-config`.
+func handleCloneConfigCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	id, err := r.CloneConfig(ctx, clonePrefix)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to create the staging repository"))
+	}
+
+	Infof(ctx, "Initialized staging repository %s beneath prefix %s", id, clonePrefix)
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -11,6 +13,7 @@ import (
 	"github.com/alcionai/corso/src/cli/options"
 	. "github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/kopia"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/repository"
 	"github.com/alcionai/corso/src/pkg/storage"
@@ -24,6 +27,11 @@ var (
 	doNotUseTLS     bool
 	doNotVerifyTLS  bool
 	succeedIfExists bool
+
+	dataStorageClass     string
+	metadataStorageClass string
+
+	skipPreflight bool
 )
 
 // called by repo.go to map subcommands to provider-specific handling.
@@ -52,6 +60,18 @@ func addS3Commands(cmd *cobra.Command) *cobra.Command {
 	fs.BoolVar(&doNotUseTLS, "disable-tls", false, "Disable TLS (HTTPS)")
 	fs.BoolVar(&doNotVerifyTLS, "disable-tls-verification", false, "Disable TLS (HTTPS) certificate verification.")
 
+	if cmd.Use == initCommand {
+		addS3StorageClassFlags(fs)
+		fs.BoolVar(
+			&ownerEncryption,
+			"owner-encryption", false,
+			"Encrypt each user's and site's data with their own key, so that their data can be erased later.")
+		fs.BoolVar(
+			&skipPreflight,
+			"skip-preflight", false,
+			"Skip validating the bucket's configuration and permissions before initializing the repository.")
+	}
+
 	// In general, we don't want to expose this flag to users and have them mistake it
 	// for a broad-scale idempotency solution.  We can un-hide it later the need arises.
 	fs.BoolVar(&succeedIfExists, "succeed-if-exists", false, "Exit with success if the repo has already been initialized.")
@@ -60,6 +80,19 @@ func addS3Commands(cmd *cobra.Command) *cobra.Command {
 	return c
 }
 
+// storage classes are fixed when the repo is initialized, so they
+// are only accepted by repo init.
+func addS3StorageClassFlags(fs *pflag.FlagSet) {
+	fs.StringVar(
+		&dataStorageClass,
+		"data-storage-class", "",
+		"S3 storage class for item data blobs: STANDARD, STANDARD_IA, INTELLIGENT_TIERING, or GLACIER_IR.")
+	fs.StringVar(
+		&metadataStorageClass,
+		"metadata-storage-class", "",
+		"S3 storage class for index and metadata blobs: STANDARD or INTELLIGENT_TIERING.")
+}
+
 const (
 	s3ProviderCommand          = "s3"
 	s3ProviderCommandUseSuffix = "--bucket <bucket>"
@@ -73,7 +106,16 @@ corso repo init s3 --bucket my-bucket
 corso repo init s3 --bucket my-bucket --prefix my-prefix
 
 # Create a new Corso repo in an S3 compliant storage provider
-corso repo init s3 --bucket my-bucket --endpoint https://my-s3-server-endpoint`
+corso repo init s3 --bucket my-bucket --endpoint https://my-s3-server-endpoint
+
+# Create a new Corso repo which stores item data in the infrequent access tier
+corso repo init s3 --bucket my-bucket --data-storage-class STANDARD_IA
+
+# Create a new Corso repo in which each user's data can be individually erased
+corso repo init s3 --bucket my-bucket --owner-encryption
+
+# Create a new Corso repo without validating the bucket's configuration first
+corso repo init s3 --bucket my-bucket --skip-preflight`
 
 	s3ProviderCommandConnectExamples = `# Connect to a Corso repo in AWS S3 bucket named "my-bucket"
 corso repo connect s3 --bucket my-bucket
@@ -92,9 +134,13 @@ corso repo connect s3 --bucket my-bucket --endpoint https://my-s3-server-endpoin
 // `corso repo init s3 [<flag>...]`
 func s3InitCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:     s3ProviderCommand,
-		Short:   "Initialize a S3 repository",
-		Long:    `Bootstraps a new S3 repository and connects it to your m365 account.`,
+		Use:   s3ProviderCommand,
+		Short: "Initialize a S3 repository",
+		Long: `Bootstraps a new S3 repository and connects it to your m365 account.
+
+Before initializing, the bucket's versioning, encryption, lifecycle rules, object lock,
+and the put/get/list/delete permissions of the credentials are validated, and a
+remediation checklist is shown for any configuration which conflicts with corso.`,
 		RunE:    initS3Cmd,
 		Args:    cobra.NoArgs,
 		Example: s3ProviderCommandInitExamples,
@@ -124,7 +170,23 @@ func initS3Cmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to parse m365 account config"))
 	}
 
-	r, err := repository.Initialize(ctx, a, s, options.Control())
+	if !skipPreflight {
+		pf, err := kopia.PreflightS3(ctx, s)
+		if err != nil {
+			return Only(ctx, errors.Wrap(err, "Failed to validate the S3 bucket"))
+		}
+
+		printPreflight(ctx, pf)
+
+		if pf.Failed() {
+			return Only(ctx, errors.New("The S3 bucket failed pre-flight validation"))
+		}
+	}
+
+	opts := options.Control()
+	opts.OwnerEncryption = ownerEncryption
+
+	r, err := repository.Initialize(ctx, a, s, opts)
 	if err != nil {
 		if succeedIfExists && errors.Is(err, repository.ErrorRepoAlreadyExists) {
 			return nil
@@ -144,6 +206,55 @@ func initS3Cmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// preflightCheck is a printable pre-flight checklist entry.
+type preflightCheck kopia.PreflightCheck
+
+func (pc preflightCheck) MinimumPrintable() any {
+	return kopia.PreflightCheck(pc)
+}
+
+func (pc preflightCheck) Headers() []string {
+	return []string{"Check", "Status", "Detail"}
+}
+
+func (pc preflightCheck) Values() []string {
+	return []string{pc.Name, string(pc.Status), pc.Detail}
+}
+
+// printPreflight prints the pre-flight checks, followed by the remediation
+// checklist for any warnings and failures.
+func printPreflight(ctx context.Context, pf kopia.Preflight) {
+	ps := make([]Printable, 0, len(pf))
+	for _, c := range pf {
+		ps = append(ps, preflightCheck(c))
+	}
+
+	All(ctx, ps...)
+
+	// json output already includes the remediations.
+	if JSONFormat() {
+		return
+	}
+
+	var todo []string
+
+	for _, c := range pf {
+		if len(c.Remediation) > 0 {
+			todo = append(todo, fmt.Sprintf("[ ] %s: %s", c.Name, c.Remediation))
+		}
+	}
+
+	if len(todo) == 0 {
+		return
+	}
+
+	Info(ctx, "\nRemediation checklist:")
+
+	for _, t := range todo {
+		Info(ctx, t)
+	}
+}
+
 // ---------------------------------------------------------------------------------------------------------
 // Connect
 // ---------------------------------------------------------------------------------------------------------
@@ -208,5 +319,7 @@ func s3Overrides() map[string]string {
 		storage.Prefix:                prefix,
 		storage.DoNotUseTLS:           strconv.FormatBool(doNotUseTLS),
 		storage.DoNotVerifyTLS:        strconv.FormatBool(doNotVerifyTLS),
+		storage.DataStorageClass:      dataStorageClass,
+		storage.MetadataStorageClass:  metadataStorageClass,
 	}
 }
@@ -0,0 +1,174 @@
+package report
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/repository"
+)
+
+const trendsCommand = "trends"
+
+// trends flag values
+var (
+	trendsUser string
+	trendsSite string
+)
+
+const trendsCommandExamples = `# Report the backup trends of Exchange and OneDrive for user Alice
+corso report trends --user alice@example.com
+
+# Report the backup trends of SharePoint for a site
+corso report trends --site https://example.com/sites/finance`
+
+// AddCommands attaches all `corso report * *` commands to the parent.
+func AddCommands(cmd *cobra.Command) {
+	reportC := reportCmd()
+	cmd.AddCommand(reportC)
+	reportC.AddCommand(trendsCmd())
+}
+
+// The report category of commands.
+// `corso report [<subcommand>] [<flag>...]`
+func reportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report",
+		Short: "Report on your backups",
+		Long:  `Report on the backups held in the repository.`,
+		RunE:  handleReportCmd,
+		Args:  cobra.NoArgs,
+	}
+}
+
+// Handler for flat calls to `corso report`.
+// Produces the same output as `corso report --help`.
+func handleReportCmd(cmd *cobra.Command, args []string) error {
+	return cmd.Help()
+}
+
+// The report trends subcommand.
+// `corso report trends --user <userID> | --site <siteID>`
+func trendsCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   trendsCommand,
+		Short: "Report backup duration, size, and change-rate trends",
+		Long: `Report the duration, bytes uploaded, and change rate of every backup of a user or site,
+along with how each has trended over time.  Trends compare the newer half of the backups
+against the older half.  Stats are recorded as each backup completes, and are retained
+after the backups themselves are deleted.`,
+		RunE:    handleTrendsCmd,
+		Args:    cobra.NoArgs,
+		Example: trendsCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&trendsUser,
+		utils.UserFN, "",
+		"Report the Exchange and OneDrive backup trends of this user.")
+	fs.StringVar(
+		&trendsSite,
+		utils.SiteFN, "",
+		"Report the SharePoint backup trends of this site.")
+
+	return c
+}
+
+// Handler for calls to `corso report trends`.
+func handleTrendsCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	owner, services, err := trendsScope(trendsUser, trendsSite)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	for _, svc := range services {
+		t, err := r.BackupTrend(ctx, svc, owner)
+		if err != nil {
+			return Only(ctx, errors.Wrapf(err, "Failed to retrieve the %s backup trend of %s", svc, owner))
+		}
+
+		printTrend(ctx, t)
+	}
+
+	return nil
+}
+
+// trendsScope produces the owner, and the services of that owner,
+// whose trends get reported.
+func trendsScope(user, site string) (string, []path.ServiceType, error) {
+	switch {
+	case len(user) > 0 && len(site) > 0:
+		return "", nil, errors.New("Only one of --user or --site can be provided")
+	case len(user) > 0:
+		return user, []path.ServiceType{path.ExchangeService, path.OneDriveService}, nil
+	case len(site) > 0:
+		return site, []path.ServiceType{path.SharePointService}, nil
+	}
+
+	return "", nil, errors.New("A --user or --site must be provided")
+}
+
+func printTrend(ctx context.Context, t *backup.Trend) {
+	if JSONFormat() {
+		Item(ctx, trendReport{Trend: t, Summary: t.Summarize()})
+		return
+	}
+
+	if len(t.Points) == 0 {
+		Infof(ctx, "No %s backups have been recorded for %s", t.Service, t.ResourceOwner)
+		return
+	}
+
+	Infof(ctx, "%s backups of %s", t.Service, t.ResourceOwner)
+
+	ps := make([]Printable, 0, len(t.Points))
+	for _, p := range t.Points {
+		ps = append(ps, p)
+	}
+
+	All(ctx, ps...)
+	Item(ctx, t.Summarize())
+}
+
+// trendReport combines a trend and its summary for json output.
+type trendReport struct {
+	Trend   *backup.Trend       `json:"trend"`
+	Summary backup.TrendSummary `json:"summary"`
+}
+
+// interface compliance check
+var _ Printable = trendReport{}
+
+func (tr trendReport) MinimumPrintable() any {
+	return tr
+}
+
+func (tr trendReport) Headers() []string {
+	return tr.Summary.Headers()
+}
+
+func (tr trendReport) Values() []string {
+	return tr.Summary.Values()
+}
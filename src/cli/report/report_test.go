@@ -0,0 +1,81 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type ReportUnitSuite struct {
+	tester.Suite
+}
+
+func TestReportUnitSuite(t *testing.T) {
+	suite.Run(t, &ReportUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ReportUnitSuite) TestTrendsCmd() {
+	t := suite.T()
+	c := trendsCmd()
+
+	assert.Equal(t, trendsCommand, c.Use)
+	tester.AreSameFunc(t, handleTrendsCmd, c.RunE)
+	assert.NotNil(t, c.Flags().Lookup(utils.UserFN))
+	assert.NotNil(t, c.Flags().Lookup(utils.SiteFN))
+}
+
+func (suite *ReportUnitSuite) TestTrendsScope() {
+	table := []struct {
+		name           string
+		user, site     string
+		expectOwner    string
+		expectServices []path.ServiceType
+		expectErr      assert.ErrorAssertionFunc
+	}{
+		{
+			name:           "user",
+			user:           "u",
+			expectOwner:    "u",
+			expectServices: []path.ServiceType{path.ExchangeService, path.OneDriveService},
+			expectErr:      assert.NoError,
+		},
+		{
+			name:           "site",
+			site:           "s",
+			expectOwner:    "s",
+			expectServices: []path.ServiceType{path.SharePointService},
+			expectErr:      assert.NoError,
+		},
+		{
+			name:      "both",
+			user:      "u",
+			site:      "s",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "neither",
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			owner, services, err := trendsScope(test.user, test.site)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, test.expectOwner, owner)
+			assert.Equal(t, test.expectServices, services)
+		})
+	}
+}
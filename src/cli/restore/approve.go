@@ -0,0 +1,70 @@
+package restore
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+const approveCommand = "approve"
+
+const approveCommandExamples = `# Approve restore 1234abcd-12ab-cd34-56de-1234abcd, which is waiting on approval
+corso restore approve 1234abcd-12ab-cd34-56de-1234abcd`
+
+// The restore approve subcommand.
+// `corso restore approve <restoreID>`
+func approveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   approveCommand + " <restoreID>",
+		Short: "Approve a restore that is waiting on approval",
+		Long: `Approve a restore that exceeded the approval thresholds of the repository's restore
+policy.  Restores must be approved by someone other than the person who ran them.
+A restore that is still waiting proceeds once it observes the approval.
+A restore that stopped waiting can be resumed with --resume <restoreID>, and proceeds
+without waiting again.`,
+		RunE:    handleApproveCmd,
+		Args:    cobra.ExactArgs(1),
+		Example: approveCommandExamples,
+	}
+}
+
+// Handler for calls to `corso restore approve`.
+func handleApproveCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	restoreID := args[0]
+
+	s, acct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, acct, s, options.Control())
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if err := r.ApproveRestore(ctx, restoreID); err != nil {
+		if errors.Is(err, store.ErrNoApprovalRequest) {
+			return Only(ctx, errors.Errorf("Restore %s is not waiting on approval", restoreID))
+		}
+
+		if errors.Is(err, store.ErrSelfApproval) {
+			return Only(ctx, errors.Errorf("Restore %s must be approved by someone other than its requester", restoreID))
+		}
+
+		return Only(ctx, errors.Wrap(err, "Failed to approve the restore"))
+	}
+
+	Infof(ctx, "Approved restore %s", restoreID)
+
+	return nil
+}
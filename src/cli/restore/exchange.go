@@ -11,7 +11,6 @@ import (
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/data"
-	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/repository"
 )
 
@@ -26,8 +25,10 @@ var (
 
 	email               []string
 	emailFolder         []string
+	emailFolderID       []string
 	emailReceivedAfter  string
 	emailReceivedBefore string
+	emailMessageID      string
 	emailSender         string
 	emailSubject        string
 
@@ -38,6 +39,10 @@ var (
 	eventStartsAfter  string
 	eventStartsBefore string
 	eventSubject      string
+
+	task        []string
+	taskFolder  []string
+	taskSubject string
 )
 
 // called by restore.go to map subcommands to provider-specific handling.
@@ -63,7 +68,7 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 
 		fs.StringSliceVar(&user,
 			utils.UserFN, nil,
-			"Restore data by user's email address; accepts '"+utils.Wildcard+"' to select all users.")
+			"Restore data by user's email address or display name; accepts '"+utils.Wildcard+"' to select all users.")
 
 		// email flags
 		fs.StringSliceVar(&email,
@@ -73,6 +78,10 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			&emailFolder,
 			utils.EmailFolderFN, nil,
 			"Restore emails within a folder; accepts '"+utils.Wildcard+"' to select all email folders.")
+		fs.StringSliceVar(
+			&emailFolderID,
+			utils.EmailFolderIDFN, nil,
+			"Restore emails within a folder by folder ID; matches the folder even if it was renamed.")
 		fs.StringVar(
 			&emailSubject,
 			utils.EmailSubjectFN, "",
@@ -89,6 +98,10 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			&emailReceivedBefore,
 			utils.EmailReceivedBeforeFN, "",
 			"Restore emails received before this datetime.")
+		fs.StringVar(
+			&emailMessageID,
+			utils.EmailMessageIDFN, "",
+			"Restore emails with this internet Message-ID.")
 
 		// event flags
 		fs.StringSliceVar(&event,
@@ -133,7 +146,33 @@ func addExchangeCommands(cmd *cobra.Command) *cobra.Command {
 			utils.ContactNameFN, "",
 			"Restore contacts whose contact name contains this value.")
 
+		// tasks flags
+		fs.StringSliceVar(
+			&task,
+			utils.TaskFN, nil,
+			"Restore tasks by task ID; accepts '"+utils.Wildcard+"' to select all tasks.")
+		fs.StringSliceVar(
+			&taskFolder,
+			utils.TaskFolderFN, nil,
+			"Restore tasks within a To Do list; accepts '"+utils.Wildcard+"' to select all task lists.")
+		fs.StringVar(
+			&taskSubject,
+			utils.TaskSubjectFN, "",
+			"Restore tasks with a subject containing this value.")
+
 		// others
+		addQuarantineFlag(c)
+		addDestinationFolderFlag(c)
+		addResumeFlags(c)
+		addAtFlag(c)
+		addTransformRulesFlag(c)
+		options.AddRestoreMailboxSettingsFlag(c)
+		options.AddCollisionsFlag(c)
+		options.AddApprovalFlags(c)
+		options.AddMailRestoreFlags(c)
+		options.AddReadCacheFlags(c)
+		options.AddRestoreBandwidthFlag(c)
+		options.AddRestoreReportFlags(c)
 		options.AddOperationFlags(c)
 	}
 
@@ -155,8 +194,24 @@ corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
 corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
       --user bob@example.com --event-calendar Calendar
 
+# Restore Alice's To Do tasks from a specific backup
+corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --user alice@example.com --task-folder '*'
+
+# Restore Alice's Inbox along with her automatic replies and working hours
+corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --user alice@example.com --email-folder Inbox --restore-mailbox-settings
+
+# Restore Alice's Inbox into the same folder as an earlier restore, skipping the emails already there
+corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --user alice@example.com --email-folder Inbox --destination-folder "Restored Mail" --collisions skip
+
 # Restore contact with ID abdef0101 from a specific backup
-corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd --contact abdef0101`
+corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd --contact abdef0101
+
+# Restore Alice's Inbox after business hours
+corso restore exchange --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --user alice@example.com --email-folder Inbox --at 2024-05-01T01:00Z`
 )
 
 // `corso restore exchange [<flag>...]`
@@ -183,12 +238,14 @@ func restoreExchangeCmd(cmd *cobra.Command, args []string) error {
 		ContactFolder:       contactFolder,
 		Email:               email,
 		EmailFolder:         emailFolder,
+		EmailFolderID:       emailFolderID,
 		Event:               event,
 		EventCalendar:       eventCalendar,
 		Users:               user,
 		ContactName:         contactName,
 		EmailReceivedAfter:  emailReceivedAfter,
 		EmailReceivedBefore: emailReceivedBefore,
+		EmailMessageID:      emailMessageID,
 		EmailSender:         emailSender,
 		EmailSubject:        emailSubject,
 		EventOrganizer:      eventOrganizer,
@@ -196,6 +253,9 @@ func restoreExchangeCmd(cmd *cobra.Command, args []string) error {
 		EventStartsAfter:    eventStartsAfter,
 		EventStartsBefore:   eventStartsBefore,
 		EventSubject:        eventSubject,
+		Task:                task,
+		TaskFolder:          taskFolder,
+		TaskSubject:         taskSubject,
 
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
@@ -209,6 +269,11 @@ func restoreExchangeCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	opts.Users, err = utils.ResolveBackedUpUserNames(ctx, a, opts.Users)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, a, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -216,7 +281,10 @@ func restoreExchangeCmd(cmd *cobra.Command, args []string) error {
 
 	defer utils.CloseRepo(ctx, r)
 
-	dest := control.DefaultRestoreDestination(common.SimpleDateTime)
+	dest, err := restoreDestination(common.SimpleDateTime)
+	if err != nil {
+		return Only(ctx, err)
+	}
 
 	sel := utils.IncludeExchangeRestoreDataSelectors(opts)
 	utils.FilterExchangeRestoreInfoSelectors(sel, opts)
@@ -226,15 +294,25 @@ func restoreExchangeCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to initialize Exchange restore"))
 	}
 
+	resumeRestore(&ro)
+
+	if err := scheduleRestore(ctx, &ro); err != nil {
+		return Only(ctx, err)
+	}
+
 	ds, err := ro.Run(ctx)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			return Only(ctx, errors.Errorf("Backup or backup details missing for id %s", backupID))
 		}
 
+		printResumeHint(ctx, ro)
+
 		return Only(ctx, errors.Wrap(err, "Failed to run Exchange restore"))
 	}
 
+	printReplayed(ctx, ro)
+	printQuotaWarning(ctx, ro)
 	ds.PrintEntries(ctx)
 
 	return nil
@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/cli/options"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
 )
 
 type ExchangeSuite struct {
@@ -50,3 +52,51 @@ func (suite *ExchangeSuite) TestAddExchangeCommands() {
 		})
 	}
 }
+
+func (suite *ExchangeSuite) TestCollisionsFlag() {
+	table := []struct {
+		name      string
+		value     string
+		expect    control.CollisionPolicy
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"copy", "copy", control.Copy, assert.NoError},
+		{"skip", "skip", control.Skip, assert.NoError},
+		{"replace", "Replace", control.Replace, assert.NoError},
+		{"unknown", "merge", control.Unknown, assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			cmd := &cobra.Command{Use: restoreCommand}
+			c := addExchangeCommands(cmd)
+			require.NotNil(t, c)
+
+			fs := c.Flags()
+			defer func() { require.NoError(t, fs.Set("collisions", "copy")) }()
+
+			test.expectErr(t, fs.Set("collisions", test.value))
+
+			if test.expect != control.Unknown {
+				assert.Equal(t, test.expect, options.Control().Collision)
+			}
+		})
+	}
+}
+
+func (suite *ExchangeSuite) TestRestoreMailboxSettingsFlag() {
+	t := suite.T()
+
+	cmd := &cobra.Command{Use: restoreCommand}
+	c := addExchangeCommands(cmd)
+	require.NotNil(t, c)
+
+	fs := c.Flags()
+	defer func() { require.NoError(t, fs.Set("restore-mailbox-settings", "false")) }()
+
+	assert.False(t, options.Control().RestoreMailboxSettings, "settings aren't restored by default")
+
+	require.NoError(t, fs.Set("restore-mailbox-settings", "true"))
+	assert.True(t, options.Control().RestoreMailboxSettings)
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/data"
-	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/repository"
 )
 
@@ -23,6 +23,11 @@ var (
 	fileCreatedBefore  string
 	fileModifiedAfter  string
 	fileModifiedBefore string
+	fileCreatedBy      string
+	fileContentType    string
+	fileLabel          string
+
+	itemURL string
 )
 
 // called by restore.go to map subcommands to provider-specific handling.
@@ -49,7 +54,7 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 
 		fs.StringSliceVar(&user,
 			utils.UserFN, nil,
-			"Restore data by user's email address; accepts '"+utils.Wildcard+"' to select all users.")
+			"Restore data by user's email address or display name; accepts '"+utils.Wildcard+"' to select all users.")
 
 		// onedrive hierarchy (path/name) flags
 
@@ -63,8 +68,14 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 			utils.FileFN, nil,
 			"Restore items by file name or ID")
 
+		fs.StringVar(
+			&itemURL,
+			utils.ItemURLFN, "",
+			"Restore the file or folder at this OneDrive web url or sharing link")
+
 		// permissions restore flag
-		options.AddRestorePermissionsFlag(c)
+		options.AddOneDriveRestorePermissionsFlag(c)
+		options.AddOneDrivePermissionsOnlyFlag(c)
 
 		// onedrive info flags
 
@@ -86,7 +97,29 @@ func addOneDriveCommands(cmd *cobra.Command) *cobra.Command {
 			utils.FileModifiedBeforeFN, "",
 			"Restore files modified before this datetime")
 
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Restore files created by a user whose email contains this value")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Restore files whose content type contains this value")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Restore files with this sensitivity label")
+
 		// others
+		addQuarantineFlag(c)
+		addResumeFlags(c)
+		addAtFlag(c)
+		addTransformRulesFlag(c)
+		addDestinationLibraryFlag(c)
+		options.AddApprovalFlags(c)
+		options.AddReadCacheFlags(c)
+		options.AddRestoreBandwidthFlag(c)
+		options.AddRestoreReportFlags(c)
 		options.AddOperationFlags(c)
 	}
 
@@ -103,13 +136,21 @@ corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd --file 98765abc
 # Restore file with ID 98765abcdef along with its associated permissions
 corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd --file 98765abcdef --restore-permissions
 
+# Undo changes to the sharing of Bob's "Documents" folder, without restoring any content
+corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --user bob@example.com --folder "Documents" --permissions-only
+
 # Restore Alice's file named "FY2021 Planning.xlsx in "Documents/Finance Reports" from a specific backup
 corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd \
       --user alice@example.com --file "FY2021 Planning.xlsx" --folder "Documents/Finance Reports"
 
 # Restore all files from Bob's folder that were created before 2020 when captured in a specific backup
 corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd 
-      --user bob@example.com --folder "Documents/Finance Reports" --file-created-before 2020-01-01T00:00:00`
+      --user bob@example.com --folder "Documents/Finance Reports" --file-created-before 2020-01-01T00:00:00
+
+# Restore the file shared at a link from a specific backup
+corso restore onedrive --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --item-url https://contoso-my.sharepoint.com/:w:/g/personal/alice_contoso_com/EaBcD123`
 )
 
 // `corso restore onedrive [<flag>...]`
@@ -139,6 +180,10 @@ func restoreOneDriveCmd(cmd *cobra.Command, args []string) error {
 		FileCreatedBefore:  fileCreatedBefore,
 		FileModifiedAfter:  fileModifiedAfter,
 		FileModifiedBefore: fileModifiedBefore,
+		FileCreatedBy:      fileCreatedBy,
+		FileContentType:    fileContentType,
+		FileLabel:          fileLabel,
+		ItemURL:            itemURL,
 
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
@@ -152,6 +197,11 @@ func restoreOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
+	opts.Users, err = utils.ResolveBackedUpUserNames(ctx, a, opts.Users)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to resolve user names"))
+	}
+
 	r, err := repository.Connect(ctx, a, s, options.Control())
 	if err != nil {
 		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
@@ -159,7 +209,29 @@ func restoreOneDriveCmd(cmd *cobra.Command, args []string) error {
 
 	defer utils.CloseRepo(ctx, r)
 
-	dest := control.DefaultRestoreDestination(common.SimpleDateTimeOneDrive)
+	if len(opts.ItemURL) > 0 {
+		ref, err := utils.ResolveItemURL(ctx, a, opts.ItemURL, path.OneDriveService)
+		if err != nil {
+			return Only(ctx, err)
+		}
+
+		deets, _, errs := r.BackupDetails(ctx, backupID)
+		if errs.Err() != nil {
+			return Only(ctx, errors.Wrap(errs.Err(), "Failed to get backup details in the repository"))
+		}
+
+		located, err := utils.LocateDriveItem(*ref, deets)
+		if err != nil {
+			return Only(ctx, err)
+		}
+
+		opts = utils.OneDriveItemRefOpts(opts, located)
+	}
+
+	dest, err := restoreDestination(common.SimpleDateTimeOneDrive)
+	if err != nil {
+		return Only(ctx, err)
+	}
 
 	sel := utils.IncludeOneDriveRestoreDataSelectors(opts)
 	utils.FilterOneDriveRestoreInfoSelectors(sel, opts)
@@ -169,15 +241,24 @@ func restoreOneDriveCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to initialize OneDrive restore"))
 	}
 
+	resumeRestore(&ro)
+
+	if err := scheduleRestore(ctx, &ro); err != nil {
+		return Only(ctx, err)
+	}
+
 	ds, err := ro.Run(ctx)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			return Only(ctx, errors.Errorf("Backup or backup details missing for id %s", backupID))
 		}
 
+		printResumeHint(ctx, ro)
+
 		return Only(ctx, errors.Wrap(err, "Failed to run OneDrive restore"))
 	}
 
+	printReplayed(ctx, ro)
 	ds.PrintEntries(ctx)
 
 	return nil
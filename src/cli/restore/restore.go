@@ -1,7 +1,19 @@
 package restore
 
 import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/operations"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/transform"
 )
 
 var restoreCommands = []func(cmd *cobra.Command) *cobra.Command{
@@ -18,6 +30,8 @@ func AddCommands(cmd *cobra.Command) {
 	for _, addRestoreTo := range restoreCommands {
 		addRestoreTo(restoreC)
 	}
+
+	restoreC.AddCommand(approveCmd())
 }
 
 const restoreCommand = "restore"
@@ -39,3 +53,243 @@ func restoreCmd() *cobra.Command {
 func handleRestoreCmd(cmd *cobra.Command, args []string) error {
 	return cmd.Help()
 }
+
+// quarantine holds the lifetime of a quarantine restore, if requested.
+var quarantine string
+
+// addQuarantineFlag adds the --quarantine flag to the restore command.
+func addQuarantineFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&quarantine,
+		utils.QuarantineFN, "",
+		"Restore into a quarantine folder that expires after this duration (ex: 72h). "+
+			"Expired quarantine folders are removed by `corso m365 purge-quarantine`.")
+}
+
+// destinationFolder names the folder to restore items into, if requested.
+var destinationFolder string
+
+// addDestinationFolderFlag adds the --destination-folder flag to the
+// restore command.
+func addDestinationFolderFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&destinationFolder,
+		utils.DestinationFolderFN, "",
+		"Restore into the folder with this name instead of a new, timestamped folder. "+
+			"Names separated by / create the folder beneath the folders before it. "+
+			"An existing folder is reused, and --collisions decides what happens to the items already in it.")
+}
+
+// destinationLibrary names the document library to restore drive items
+// into, if requested.
+var destinationLibrary string
+
+// addDestinationLibraryFlag adds the --destination-library flag to the
+// restore command.
+func addDestinationLibraryFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&destinationLibrary,
+		utils.DestinationLibraryFN, "",
+		"Restore files into the document library with this name instead of the original drive, "+
+			"keeping the restored files isolated for review. The library is created if it does not exist.")
+}
+
+// transformRules is the file of rules that transform items as they're
+// restored, if requested.
+var transformRules string
+
+// addTransformRulesFlag adds the --transform-rules flag to the restore
+// command.
+func addTransformRulesFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&transformRules,
+		utils.TransformRulesFN, "",
+		"Json file of rules that alter items as they're restored: a name prefix (namePrefix), "+
+			"folder renames (folders), and whether to leave out email and event attachments (dropAttachments).")
+}
+
+// restoreDestination produces the restore destination, using a
+// quarantine container if the --quarantine flag was provided, the named
+// container if the --destination-folder flag was provided, a separate
+// drive if the --destination-library flag was provided, and the rules in
+// the --transform-rules file, if provided.
+func restoreDestination(timeFormat common.TimeFormat) (control.RestoreDestination, error) {
+	dest := control.DefaultRestoreDestination(timeFormat)
+
+	if len(destinationFolder) > 0 {
+		if len(quarantine) > 0 {
+			return control.RestoreDestination{}, errors.New("a destination folder can't be quarantined")
+		}
+
+		dest.ContainerName = destinationFolder
+	}
+
+	if len(quarantine) > 0 {
+		ttl, err := time.ParseDuration(quarantine)
+		if err != nil || ttl <= 0 {
+			return control.RestoreDestination{}, errors.New("invalid quarantine duration: " + quarantine)
+		}
+
+		dest = control.QuarantineRestoreDestination(timeFormat, ttl)
+	}
+
+	dest.DriveName = destinationLibrary
+
+	if len(transformRules) > 0 {
+		rules, err := transform.LoadRules(transformRules)
+		if err != nil {
+			return control.RestoreDestination{}, errors.Wrap(err, "loading transform rules")
+		}
+
+		dest.Transform = rules
+	}
+
+	return dest, nil
+}
+
+var (
+	// resumeID identifies a previous restore to resume, if requested.
+	resumeID string
+	// idempotencyKey identifies retries of the same restore, if provided.
+	idempotencyKey string
+)
+
+// addResumeFlags adds the --resume and --idempotency-key flags to the
+// restore command.
+func addResumeFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+
+	fs.StringVar(
+		&resumeID,
+		utils.ResumeFN, "",
+		"ID of a failed or cancelled restore to resume. Items that restore already "+
+			"completed are skipped, and the remaining items are restored to the same destination.")
+
+	fs.StringVar(
+		&idempotencyKey,
+		utils.IdempotencyKeyFN, "",
+		"Key that identifies retries of the same restore. Re-running a restore with the key of a "+
+			"completed restore restores nothing, and the key of a failed restore resumes it.")
+}
+
+// resumeRestore points the restore at the progress of a previous
+// restore, if the --resume or --idempotency-key flags were provided.
+func resumeRestore(ro *operations.RestoreOperation) {
+	if len(resumeID) > 0 {
+		ro.RestoreID = model.StableID(resumeID)
+	}
+
+	ro.IdempotencyKey = idempotencyKey
+}
+
+// printReplayed tells the user when nothing was restored because a
+// restore with the same idempotency key already completed.
+func printReplayed(ctx context.Context, ro operations.RestoreOperation) {
+	if !ro.Replayed {
+		return
+	}
+
+	Infof(
+		ctx,
+		"Restore %s with idempotency key %q already completed; %d items were restored",
+		ro.RestoreID, ro.IdempotencyKey, ro.Results.ItemsWritten)
+}
+
+// printQuotaWarning tells the user when the restore may not fit within
+// the destination's quota.
+func printQuotaWarning(ctx context.Context, ro operations.RestoreOperation) {
+	if len(ro.Results.QuotaWarning) == 0 {
+		return
+	}
+
+	Infof(ctx, "Warning: %s", ro.Results.QuotaWarning)
+}
+
+// printResumeHint tells the user how to resume a restore that did not
+// complete.
+func printResumeHint(ctx context.Context, ro operations.RestoreOperation) {
+	if len(ro.RestoreID) == 0 {
+		return
+	}
+
+	Infof(ctx, "Resume this restore by re-running the command with --%s %s", utils.ResumeFN, ro.RestoreID)
+}
+
+// runAt holds the time at which to run the restore, if requested.
+var runAt string
+
+// addAtFlag adds the --at flag to the restore command.
+func addAtFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&runAt,
+		utils.AtFN, "",
+		"Queue the restore to run at this time (ex: 2024-05-01T01:00Z). Times without a zone "+
+			"(ex: 2024-05-01T01:00) are in the local time zone. The restore is validated "+
+			"immediately, and the command waits until the scheduled time before restoring.")
+}
+
+// scheduleLayouts are the time formats accepted by --at, in addition to
+// corso's standard time formats.
+var scheduleLayouts = []string{
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+}
+
+// scheduledTime parses the --at flag value.  Returns the zero time if no
+// schedule was requested.  Scheduled times must be after now, and times
+// without a zone are in now's location.
+func scheduledTime(at string, now time.Time) (time.Time, error) {
+	if len(at) == 0 {
+		return time.Time{}, nil
+	}
+
+	t, err := common.ParseTime(at)
+	if err != nil {
+		for _, layout := range scheduleLayouts {
+			if t, err = time.ParseInLocation(layout, at, now.Location()); err == nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return time.Time{}, errors.New("invalid restore schedule: " + at)
+	}
+
+	if !t.After(now) {
+		return time.Time{}, errors.New("restore schedule is not in the future: " + at)
+	}
+
+	return t, nil
+}
+
+// scheduleRestore defers the restore until the time requested by the
+// --at flag, if any.  The restore is validated before waiting, so that
+// problems surface when the restore is queued rather than when it runs.
+func scheduleRestore(ctx context.Context, ro *operations.RestoreOperation) error {
+	at, err := scheduledTime(runAt, time.Now())
+	if err != nil || at.IsZero() {
+		return err
+	}
+
+	if err := ro.Validate(ctx); err != nil {
+		return errors.Wrap(err, "Failed to validate the scheduled restore")
+	}
+
+	Infof(ctx, "Restore validated; waiting until %s to run", common.FormatTabularDisplayTime(at))
+
+	return waitUntil(ctx, at)
+}
+
+// waitUntil blocks until the time arrives, or the context is cancelled.
+func waitUntil(ctx context.Context, at time.Time) error {
+	t := time.NewTimer(time.Until(at))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "waiting for the scheduled restore")
+	}
+}
@@ -0,0 +1,179 @@
+package restore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type RestoreUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestoreUnitSuite) TestRestoreDestination() {
+	table := []struct {
+		name             string
+		quarantine       string
+		expectErr        assert.ErrorAssertionFunc
+		expectQuarantine bool
+	}{
+		{"default", "", assert.NoError, false},
+		{"quarantine", "72h", assert.NoError, true},
+		{"bad duration", "three days", assert.Error, false},
+		{"negative duration", "-1h", assert.Error, false},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			quarantine = test.quarantine
+			defer func() { quarantine = "" }()
+
+			dest, err := restoreDestination(common.SimpleDateTimeOneDrive)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expectQuarantine, control.IsQuarantineContainer(dest.ContainerName))
+		})
+	}
+}
+
+func (suite *RestoreUnitSuite) TestRestoreDestination_library() {
+	t := suite.T()
+
+	dest, err := restoreDestination(common.SimpleDateTimeOneDrive)
+	require.NoError(t, err)
+	assert.Empty(t, dest.DriveName)
+
+	destinationLibrary = "Restored Files"
+	defer func() { destinationLibrary = "" }()
+
+	dest, err = restoreDestination(common.SimpleDateTimeOneDrive)
+	require.NoError(t, err)
+	assert.Equal(t, "Restored Files", dest.DriveName)
+	assert.NotEmpty(t, dest.ContainerName)
+}
+
+func (suite *RestoreUnitSuite) TestRestoreDestination_folder() {
+	t := suite.T()
+
+	destinationFolder = "Restored Mail"
+	defer func() { destinationFolder = "" }()
+
+	dest, err := restoreDestination(common.SimpleDateTime)
+	require.NoError(t, err)
+	assert.Equal(t, "Restored Mail", dest.ContainerName)
+
+	quarantine = "72h"
+	defer func() { quarantine = "" }()
+
+	_, err = restoreDestination(common.SimpleDateTime)
+	assert.Error(t, err, "quarantined destination folder")
+}
+
+func (suite *RestoreUnitSuite) TestRestoreDestination_transformRules() {
+	t := suite.T()
+
+	dest, err := restoreDestination(common.SimpleDateTimeOneDrive)
+	require.NoError(t, err)
+	assert.Nil(t, dest.Transform)
+
+	defer func() { transformRules = "" }()
+
+	transformRules = filepath.Join(t.TempDir(), "rules.json")
+
+	_, err = restoreDestination(common.SimpleDateTimeOneDrive)
+	assert.Error(t, err, "missing rules file")
+
+	require.NoError(t, os.WriteFile(transformRules, []byte(`{"namePrefix": "[old] "}`), 0o600))
+
+	dest, err = restoreDestination(common.SimpleDateTimeOneDrive)
+	require.NoError(t, err)
+	require.NotNil(t, dest.Transform)
+	assert.Equal(t, "[old] name", dest.Transform.RestoreName("name"))
+}
+
+func (suite *RestoreUnitSuite) TestQuarantineExpired() {
+	t := suite.T()
+
+	dest := control.QuarantineRestoreDestination(common.SimpleDateTimeOneDrive, time.Hour)
+	require.True(t, control.IsQuarantineContainer(dest.ContainerName))
+
+	assert.False(t, control.QuarantineExpired(dest.ContainerName, time.Now()))
+	assert.True(t, control.QuarantineExpired(dest.ContainerName, time.Now().Add(2*time.Hour)))
+
+	def := control.DefaultRestoreDestination(common.SimpleDateTimeOneDrive)
+	assert.False(t, control.QuarantineExpired(def.ContainerName, time.Now().Add(2*time.Hour)))
+}
+
+func (suite *RestoreUnitSuite) TestScheduledTime() {
+	now := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+
+	table := []struct {
+		name      string
+		at        string
+		expect    time.Time
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"unscheduled", "", time.Time{}, assert.NoError},
+		{"minutes", "2024-05-01T01:00Z", time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), assert.NoError},
+		{"seconds", "2024-05-01T01:00:30Z", time.Date(2024, 5, 1, 1, 0, 30, 0, time.UTC), assert.NoError},
+		{"offset", "2024-05-01T01:00+02:00", time.Date(2024, 4, 30, 23, 0, 0, 0, time.UTC), assert.NoError},
+		{"no zone", "2024-05-01T01:00", time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), assert.NoError},
+		{"in the past", "2024-04-30T11:00Z", time.Time{}, assert.Error},
+		{"now", "2024-04-30T12:00Z", time.Time{}, assert.Error},
+		{"unparsable", "after hours", time.Time{}, assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			result, err := scheduledTime(test.at, now)
+			test.expectErr(t, err)
+			assert.True(t, test.expect.Equal(result), "expected %v, got %v", test.expect, result)
+		})
+	}
+}
+
+func (suite *RestoreUnitSuite) TestScheduledTime_localZone() {
+	var (
+		t   = suite.T()
+		loc = time.FixedZone("UTC-7", -7*60*60)
+		now = time.Date(2024, 4, 30, 12, 0, 0, 0, loc)
+	)
+
+	result, err := scheduledTime("2024-05-01T01:00", now)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC).Equal(result), "zone-less times use now's zone")
+
+	result, err = scheduledTime("2024-05-01T01:00Z", now)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC).Equal(result), "explicit zones are kept")
+}
+
+func (suite *RestoreUnitSuite) TestWaitUntil() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	assert.NoError(t, waitUntil(ctx, time.Now().Add(10*time.Millisecond)))
+	assert.NoError(t, waitUntil(ctx, time.Now().Add(-time.Minute)), "past times return immediately")
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.Error(t, waitUntil(cctx, time.Now().Add(time.Hour)))
+}
@@ -1,6 +1,8 @@
 package restore
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -11,7 +13,8 @@ import (
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/data"
-	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/repository"
 )
 
@@ -24,6 +27,8 @@ var (
 	libraryPaths []string
 	site         []string
 	weburl       []string
+
+	destinationSite string
 )
 
 // called by restore.go to map subcommands to provider-specific handling.
@@ -68,6 +73,11 @@ func addSharePointCommands(cmd *cobra.Command) *cobra.Command {
 			utils.LibraryItemFN, nil,
 			"Restore library items by file name or ID")
 
+		fs.StringVar(
+			&itemURL,
+			utils.ItemURLFN, "",
+			"Restore the library file or folder at this SharePoint web url or sharing link")
+
 		fs.StringSliceVar(
 			&listPaths,
 			utils.ListFN, nil,
@@ -97,7 +107,37 @@ func addSharePointCommands(cmd *cobra.Command) *cobra.Command {
 		// 	utils.FileCreatedAfterFN, "",
 		// 	"Restore files created after this datetime")
 
+		fs.StringVar(
+			&fileCreatedBy,
+			utils.FileCreatedByFN, "",
+			"Restore library files created by a user whose email contains this value")
+		fs.StringVar(
+			&fileContentType,
+			utils.FileContentTypeFN, "",
+			"Restore library files whose content type contains this value")
+		fs.StringVar(
+			&fileLabel,
+			utils.FileLabelFN, "",
+			"Restore library files with this sensitivity label")
+
 		// others
+		addQuarantineFlag(c)
+		addResumeFlags(c)
+		addAtFlag(c)
+		addTransformRulesFlag(c)
+		addDestinationLibraryFlag(c)
+
+		fs.StringVar(
+			&destinationSite,
+			utils.DestinationSiteFN, "",
+			"Restore lists, libraries, and pages into the site with this webURL or ID instead of the original "+
+				"site. Lists and libraries whose names collide with existing ones are handled by --collisions.")
+
+		options.AddCollisionsFlag(c)
+		options.AddApprovalFlags(c)
+		options.AddReadCacheFlags(c)
+		options.AddRestoreBandwidthFlag(c)
+		options.AddRestoreReportFlags(c)
 		options.AddOperationFlags(c)
 	}
 
@@ -118,7 +158,15 @@ corso restore sharepoint --backup 1234abcd-12ab-cd34-56de-1234abcd \
 
 # Restore all files from <site> that were created before 2020 when captured in a specific backup
 corso restore sharepoint --backup 1234abcd-12ab-cd34-56de-1234abcd 
-      --site <siteID> --folder "Display Templates/Style Sheets" --file-created-before 2020-01-01T00:00:00`
+      --site <siteID> --folder "Display Templates/Style Sheets" --file-created-before 2020-01-01T00:00:00
+
+# Restore all lists from a specific backup into another site, skipping lists that already exist there
+corso restore sharepoint --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --list '*' --destination-site https://contoso.sharepoint.com/sites/rebuilt --collisions skip
+
+# Restore the library folder at a url from a specific backup
+corso restore sharepoint --backup 1234abcd-12ab-cd34-56de-1234abcd \
+      --item-url "https://contoso.sharepoint.com/sites/Eng/Shared Documents/Specs"`
 )
 
 // `corso restore sharepoint [<flag>...]`
@@ -150,6 +198,10 @@ func restoreSharePointCmd(cmd *cobra.Command, args []string) error {
 		Sites:        site,
 		WebURLs:      weburl,
 		// FileCreatedAfter:   fileCreatedAfter,
+		FileCreatedBy:   fileCreatedBy,
+		FileContentType: fileContentType,
+		FileLabel:       fileLabel,
+		ItemURL:         itemURL,
 
 		Populated: utils.GetPopulatedFlags(cmd),
 	}
@@ -170,7 +222,31 @@ func restoreSharePointCmd(cmd *cobra.Command, args []string) error {
 
 	defer utils.CloseRepo(ctx, r)
 
-	dest := control.DefaultRestoreDestination(common.SimpleDateTimeOneDrive)
+	if len(opts.ItemURL) > 0 {
+		ref, err := utils.ResolveItemURL(ctx, a, opts.ItemURL, path.SharePointService)
+		if err != nil {
+			return Only(ctx, err)
+		}
+
+		deets, _, errs := r.BackupDetails(ctx, backupID)
+		if errs.Err() != nil {
+			return Only(ctx, errors.Wrap(errs.Err(), "Failed to get backup details in the repository"))
+		}
+
+		located, err := utils.LocateDriveItem(*ref, deets)
+		if err != nil {
+			return Only(ctx, err)
+		}
+
+		opts = utils.SharePointItemRefOpts(opts, located)
+	}
+
+	dest, err := restoreDestination(common.SimpleDateTimeOneDrive)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	dest.ResourceOwnerOverride = destinationSite
 
 	sel := utils.IncludeSharePointRestoreDataSelectors(opts)
 	utils.FilterSharePointRestoreInfoSelectors(sel, opts)
@@ -180,16 +256,139 @@ func restoreSharePointCmd(cmd *cobra.Command, args []string) error {
 		return Only(ctx, errors.Wrap(err, "Failed to initialize SharePoint restore"))
 	}
 
+	resumeRestore(&ro)
+
+	if err := scheduleRestore(ctx, &ro); err != nil {
+		return Only(ctx, err)
+	}
+
 	ds, err := ro.Run(ctx)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			return Only(ctx, errors.Errorf("Backup or backup details missing for id %s", backupID))
 		}
 
+		printResumeHint(ctx, ro)
+
 		return Only(ctx, errors.Wrap(err, "Failed to run SharePoint restore"))
 	}
 
+	printReplayed(ctx, ro)
 	ds.PrintEntries(ctx)
 
+	if len(destinationSite) > 0 {
+		printSiteMappings(ctx, r, ds)
+	}
+
 	return nil
 }
+
+// printSiteMappings reports the list, library, or page in the destination
+// site into which each of the backup's lists, libraries, and pages were
+// restored.
+func printSiteMappings(ctx context.Context, r repository.Repository, restored *details.Details) {
+	backedUp, _, errs := r.BackupDetails(ctx, backupID)
+	if errs.Err() != nil {
+		Infof(ctx, "Unable to report the site mapping: %v", errs.Err())
+		return
+	}
+
+	ms := siteMappings(backedUp, restored)
+	if len(ms) == 0 {
+		return
+	}
+
+	Infof(ctx, "Restored into site %s", destinationSite)
+
+	ps := make([]Printable, 0, len(ms))
+	for _, m := range ms {
+		ps = append(ps, m)
+	}
+
+	All(ctx, ps...)
+}
+
+// siteMapping pairs a list, library, or page from the backup with the one
+// it was restored into.
+type siteMapping struct {
+	Kind        string `json:"kind"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	WebURL      string `json:"webUrl"`
+}
+
+func (m siteMapping) MinimumPrintable() any {
+	return m
+}
+
+func (m siteMapping) Headers() []string {
+	return []string{"Kind", "Source", "Destination", "WebURL"}
+}
+
+func (m siteMapping) Values() []string {
+	return []string{m.Kind, m.Source, m.Destination, m.WebURL}
+}
+
+// siteMappings produces one mapping for each restored list, library, and
+// page, using the backup's details to name the source of each.
+func siteMappings(backedUp, restored *details.Details) []siteMapping {
+	var (
+		ms      = []siteMapping{}
+		seen    = map[siteMapping]struct{}{}
+		sources = map[string]*details.DetailsEntry{}
+	)
+
+	for _, de := range backedUp.Items() {
+		sources[de.RepoRef] = de
+	}
+
+	for _, de := range restored.Items() {
+		if de.SharePoint == nil {
+			continue
+		}
+
+		p, err := path.FromDataLayerPath(de.RepoRef, true)
+		if err != nil {
+			continue
+		}
+
+		var (
+			m      = siteMapping{Source: p.Item(), WebURL: de.SharePoint.WebURL}
+			source = sources[de.RepoRef]
+		)
+
+		switch p.Category() {
+		case path.LibrariesCategory:
+			m.Kind = "library"
+			m.Destination = de.SharePoint.DriveName
+
+			if source != nil && source.SharePoint != nil {
+				m.Source = source.SharePoint.DriveName
+			}
+
+		case path.ListsCategory, path.PagesCategory:
+			m.Kind = "list"
+			if p.Category() == path.PagesCategory {
+				m.Kind = "page"
+			}
+
+			m.Destination = de.SharePoint.ItemName
+
+			if source != nil && source.SharePoint != nil {
+				m.Source = source.SharePoint.ItemName
+			}
+
+		default:
+			continue
+		}
+
+		if _, ok := seen[m]; ok {
+			continue
+		}
+
+		seen[m] = struct{}{}
+		ms = append(ms, m)
+	}
+
+	return ms
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
 )
 
 type SharePointSuite struct {
@@ -47,6 +50,52 @@ func (suite *SharePointSuite) TestAddSharePointCommands() {
 			assert.Equal(t, test.expectUse, child.Use)
 			assert.Equal(t, test.expectShort, child.Short)
 			tester.AreSameFunc(t, test.expectRunE, child.RunE)
+			assert.NotNil(t, child.Flags().Lookup(utils.DestinationSiteFN))
+			assert.NotNil(t, child.Flags().Lookup("collisions"))
 		})
 	}
 }
+
+func (suite *SharePointSuite) TestSiteMappings() {
+	t := suite.T()
+
+	itemRef := func(category path.CategoryType, elems ...string) string {
+		p, err := path.Builder{}.Append(elems...).ToDataLayerSharePointPath("t", "site", category, true)
+		require.NoError(t, err)
+
+		return p.String()
+	}
+
+	var (
+		listRef  = itemRef(path.ListsCategory, "Issues", "list-id")
+		fileRef1 = itemRef(path.LibrariesCategory, "drives", "drive-id", "root:", "a.txt")
+		fileRef2 = itemRef(path.LibrariesCategory, "drives", "drive-id", "root:", "b.txt")
+		pageRef  = itemRef(path.PagesCategory, "Site Pages", "page-id")
+		entry    = func(ref string, info *details.SharePointInfo) details.DetailsEntry {
+			return details.DetailsEntry{RepoRef: ref, ItemInfo: details.ItemInfo{SharePoint: info}}
+		}
+	)
+
+	backedUp := &details.Details{DetailsModel: details.DetailsModel{Entries: []details.DetailsEntry{
+		entry(listRef, &details.SharePointInfo{ItemName: "Issues"}),
+		entry(fileRef1, &details.SharePointInfo{DriveName: "Documents"}),
+		entry(fileRef2, &details.SharePointInfo{DriveName: "Documents"}),
+	}}}
+
+	restored := &details.Details{DetailsModel: details.DetailsModel{Entries: []details.DetailsEntry{
+		entry(listRef, &details.SharePointInfo{ItemName: "Corso_Restore_Issues (1)", WebURL: "url/list"}),
+		entry(fileRef1, &details.SharePointInfo{DriveName: "Documents", WebURL: "url"}),
+		entry(fileRef2, &details.SharePointInfo{DriveName: "Documents", WebURL: "url"}),
+		entry(pageRef, &details.SharePointInfo{ItemName: "Corso_Restore_Home", WebURL: "url/page"}),
+	}}}
+
+	assert.Equal(
+		t,
+		[]siteMapping{
+			{Kind: "list", Source: "Issues", Destination: "Corso_Restore_Issues (1)", WebURL: "url/list"},
+			{Kind: "library", Source: "Documents", Destination: "Documents", WebURL: "url"},
+			// sources missing from the backup details are identified by ID.
+			{Kind: "page", Source: "page-id", Destination: "Corso_Restore_Home", WebURL: "url/page"},
+		},
+		siteMappings(backedUp, restored))
+}
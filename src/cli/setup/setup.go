@@ -0,0 +1,471 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/cli/options"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/credentials"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/services/m365"
+	"github.com/alcionai/corso/src/pkg/storage"
+)
+
+const setupCommand = "setup"
+
+// storageProviders lists the storage providers that can hold a repository.
+var storageProviders = []string{storage.ProviderS3.String()}
+
+// m365AuthModes lists the ways corso can authenticate with M365.
+var m365AuthModes = []string{
+	string(account.AuthApplication),
+	string(account.AuthDeviceCode),
+	string(account.AuthInteractive),
+}
+
+// AddCommands attaches the `corso setup` command to the parent.
+func AddCommands(cmd *cobra.Command) {
+	cmd.AddCommand(setupCmd())
+}
+
+// The setup command.
+// `corso setup [<flag>...]`
+func setupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   setupCommand,
+		Short: "Interactively set up Corso",
+		Long: `Walks through the first-time setup of Corso: gathers and validates your Azure
+application credentials, checks the Graph permissions consented to the application,
+configures a storage provider, writes the Corso config file, and initializes a repository.
+
+Secrets are never written to the config file.  Values already present in the
+environment are used as defaults.`,
+		RunE: handleSetupCmd,
+		Args: cobra.NoArgs,
+	}
+}
+
+// Handler for calls to `corso setup`.
+func handleSetupCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	p := newPrompter(cmd.InOrStdin(), StderrWriter(ctx))
+
+	// -----
+	// M365
+	// -----
+
+	Info(ctx, "\nStep 1 of 4: M365 tenant and Azure application credentials")
+
+	m365Overrides, err := gatherM365(p)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	acct, err := config.GetAccount(ctx, false, m365Overrides)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	Info(ctx, "\nStep 2 of 4: Checking the permissions granted to the application")
+
+	report, err := m365.Probe(ctx, acct)
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to probe the M365 tenant"))
+	}
+
+	for _, r := range report.Results {
+		Infof(ctx, "  %-8s %s %s", probeStatus(r), r.Capability, r.Message)
+	}
+
+	if !report.Passed() {
+		return Only(ctx, errors.New(
+			"One or more capability checks failed; grant the missing permissions and run setup again"))
+	}
+
+	// -----
+	// Storage
+	// -----
+
+	Info(ctx, "\nStep 3 of 4: Repository storage")
+
+	storageOverrides, err := gatherStorage(p)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	if err := gatherAWS(p); err != nil {
+		return Only(ctx, err)
+	}
+
+	for k, v := range storageOverrides {
+		m365Overrides[k] = v
+	}
+
+	s, a, err := config.GetStorageAndAccount(ctx, false, m365Overrides)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	// -----
+	// Config and Repository
+	// -----
+
+	Info(ctx, "\nStep 4 of 4: Config file and repository")
+
+	ok, err := confirmConfigOverwrite(p, config.GetViper(ctx))
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	if !ok {
+		return Only(ctx, errors.New("Setup cancelled; the existing config file was left in place"))
+	}
+
+	if err := initRepo(ctx, s, a); err != nil {
+		return Only(ctx, err)
+	}
+
+	Info(ctx, "\nSetup complete.")
+
+	if len(p.exported) > 0 {
+		Info(ctx, "Corso reads secrets from the environment; export the following before running Corso again:")
+
+		for _, ev := range p.exported {
+			Infof(ctx, "  %s", ev)
+		}
+	}
+
+	return nil
+}
+
+// initRepo initializes the repository, or connects to it if it already
+// exists, and writes the config file.
+func initRepo(ctx context.Context, s storage.Storage, a account.Account) error {
+	s3Cfg, err := s.S3Config()
+	if err != nil {
+		return errors.Wrap(err, "Retrieving s3 configuration")
+	}
+
+	m365Cfg, err := a.M365Config()
+	if err != nil {
+		return errors.Wrap(err, "Failed to parse m365 account config")
+	}
+
+	r, err := repository.Initialize(ctx, a, s, options.Control())
+	if err != nil {
+		if !errors.Is(err, repository.ErrorRepoAlreadyExists) {
+			return errors.Wrap(err, "Failed to initialize a new S3 repository")
+		}
+
+		Infof(ctx, "A repository already exists within bucket %s; connecting to it.", s3Cfg.Bucket)
+
+		r, err = repository.ConnectAndSendConnectEvent(ctx, a, s, options.Control())
+		if err != nil {
+			return errors.Wrap(err, "Failed to connect to the S3 repository")
+		}
+	} else {
+		Infof(ctx, "Initialized a S3 repository within bucket %s.", s3Cfg.Bucket)
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	if err = config.WriteRepoConfig(ctx, s3Cfg, m365Cfg); err != nil {
+		return errors.Wrap(err, "Failed to write repository configuration")
+	}
+
+	Infof(ctx, "Wrote the Corso config to %s.", config.GetViper(ctx).ConfigFileUsed())
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Gathering
+// ---------------------------------------------------------------------------
+
+// gatherM365 prompts for the tenant and application credentials.  Secrets are
+// exported to the process environment, where Corso expects to find them, and
+// the remaining values are returned as config overrides.  Delegated auth
+// signs in as a user instead, and needs no client secret.
+func gatherM365(p *prompter) (map[string]string, error) {
+	tenantID, err := p.askRequired("Azure tenant ID", os.Getenv(account.AzureTenantID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.askEnv("Azure application (client) ID", credentials.AzureClientID, false); err != nil {
+		return nil, err
+	}
+
+	mode, err := p.choose("M365 authentication", m365AuthModes)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]string{
+		config.AccountProviderTypeKey: account.ProviderM365.String(),
+		account.AzureTenantID:         tenantID,
+		account.AzureAuthMode:         mode,
+	}
+
+	if mode != string(account.AuthApplication) {
+		return overrides, nil
+	}
+
+	if err := p.askEnv("Azure client secret", credentials.AzureClientSecret, true); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// gatherStorage prompts for the storage provider and its configuration,
+// along with the repository passphrase.
+func gatherStorage(p *prompter) (map[string]string, error) {
+	provider, err := p.choose("Storage provider", storageProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := p.askRequired("S3 bucket", os.Getenv(storage.BucketKey))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := p.ask("Repository prefix within the bucket", os.Getenv(storage.PrefixKey))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := p.ask(
+		"S3 service endpoint",
+		common.First(os.Getenv(storage.EndpointKey), "s3.amazonaws.com"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.askEnv("Repository encryption passphrase", credentials.CorsoPassphrase, true); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		config.StorageProviderTypeKey: provider,
+		storage.Bucket:                bucket,
+		storage.Prefix:                prefix,
+		storage.Endpoint:              endpoint,
+	}, nil
+}
+
+// gatherAWS prompts for aws credentials if none can be found in the
+// default aws credential chain.
+func gatherAWS(p *prompter) error {
+	_, err := defaults.CredChain(defaults.Config(), defaults.Handlers()).Get()
+	if err == nil {
+		return nil
+	}
+
+	fmt.Fprintln(p.out, "No AWS credentials were found in the environment or the AWS config files.")
+
+	if err := p.askEnv("AWS access key ID", credentials.AWSAccessKeyID, false); err != nil {
+		return err
+	}
+
+	return p.askEnv("AWS secret access key", credentials.AWSSecretAccessKey, true)
+}
+
+// confirmConfigOverwrite asks the user before replacing an existing config file.
+func confirmConfigOverwrite(p *prompter, vpr *viper.Viper) (bool, error) {
+	// a missing or unreadable config file gets written without asking.
+	if err := vpr.ReadInConfig(); err != nil {
+		return true, nil
+	}
+
+	return p.confirm(fmt.Sprintf("Replace the existing config file %s?", vpr.ConfigFileUsed()))
+}
+
+func probeStatus(r m365.ProbeResult) string {
+	switch {
+	case r.Skipped:
+		return "skipped"
+	case r.Passed:
+		return "ok"
+	}
+
+	return "failed"
+}
+
+// ---------------------------------------------------------------------------
+// Prompting
+// ---------------------------------------------------------------------------
+
+var errNoInput = errors.New("setup input ended before all values were provided")
+
+// prompter asks questions on out, and reads the answers from in.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// termFD is the file descriptor of in when in is a terminal, and -1
+	// otherwise.  Secrets typed at a terminal are read without echo.
+	termFD int
+
+	// exported holds the env vars populated by askEnv.
+	exported []string
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	p := &prompter{
+		in:     bufio.NewReader(in),
+		out:    out,
+		termFD: -1,
+	}
+
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.termFD = int(f.Fd())
+	}
+
+	return p
+}
+
+// ask prompts for a value.  If the answer is empty, def is returned.
+func (p *prompter) ask(question, def string) (string, error) {
+	if len(def) > 0 {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return "", errNoInput
+	}
+
+	if ans := strings.TrimSpace(line); len(ans) > 0 {
+		return ans, nil
+	}
+
+	return def, nil
+}
+
+// askRequired repeats the prompt until a non-empty value is provided.
+func (p *prompter) askRequired(question, def string) (string, error) {
+	for {
+		ans, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+
+		if len(ans) > 0 {
+			return ans, nil
+		}
+
+		fmt.Fprintln(p.out, "A value is required.")
+	}
+}
+
+// askSecret prompts for a value without echoing the answer when in is a
+// terminal.  Other inputs, such as pipes, are read like any other answer.
+func (p *prompter) askSecret(question string) (string, error) {
+	if p.termFD < 0 {
+		return p.ask(question, "")
+	}
+
+	fmt.Fprintf(p.out, "%s: ", question)
+
+	bs, err := term.ReadPassword(p.termFD)
+	fmt.Fprintln(p.out)
+
+	if err != nil {
+		return "", errors.Wrap(err, "reading "+question)
+	}
+
+	return strings.TrimSpace(string(bs)), nil
+}
+
+// askEnv prompts for a value that Corso reads from the env var, and
+// exports the answer to the process environment.  If the env var is
+// already populated, no prompt is made.  Secret answers are not echoed.
+func (p *prompter) askEnv(question, envVar string, secret bool) error {
+	if len(os.Getenv(envVar)) > 0 {
+		fmt.Fprintf(p.out, "%s: using the value of %s\n", question, envVar)
+		return nil
+	}
+
+	var (
+		ans string
+		err error
+	)
+
+	for len(ans) == 0 {
+		if secret {
+			ans, err = p.askSecret(question)
+		} else {
+			ans, err = p.ask(question, "")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(ans) == 0 {
+			fmt.Fprintln(p.out, "A value is required.")
+		}
+	}
+
+	if err := os.Setenv(envVar, ans); err != nil {
+		return errors.Wrap(err, "exporting "+envVar)
+	}
+
+	p.exported = append(p.exported, envVar)
+
+	return nil
+}
+
+// choose prompts for one of the options.  The first option is the default.
+func (p *prompter) choose(question string, opts []string) (string, error) {
+	q := fmt.Sprintf("%s (%s)", question, strings.Join(opts, ", "))
+
+	for {
+		ans, err := p.ask(q, opts[0])
+		if err != nil {
+			return "", err
+		}
+
+		for _, o := range opts {
+			if strings.EqualFold(o, ans) {
+				return o, nil
+			}
+		}
+
+		fmt.Fprintf(p.out, "Unsupported choice %q.\n", ans)
+	}
+}
+
+// confirm prompts for a yes or no answer, defaulting to no.
+func (p *prompter) confirm(question string) (bool, error) {
+	ans, err := p.ask(question+" (y/N)", "")
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(ans) {
+	case "y", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}
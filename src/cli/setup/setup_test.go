@@ -0,0 +1,177 @@
+package setup
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/cli/config"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/credentials"
+	"github.com/alcionai/corso/src/pkg/storage"
+)
+
+type SetupUnitSuite struct {
+	tester.Suite
+}
+
+func TestSetupUnitSuite(t *testing.T) {
+	suite.Run(t, &SetupUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func testPrompter(input string) (*prompter, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return newPrompter(strings.NewReader(input), out), out
+}
+
+func (suite *SetupUnitSuite) TestAsk() {
+	table := []struct {
+		name   string
+		input  string
+		def    string
+		expect string
+	}{
+		{"answer", "value\n", "", "value"},
+		{"trims", "  value  \n", "", "value"},
+		{"default", "\n", "def", "def"},
+		{"answer overrides default", "value\n", "def", "value"},
+		{"no trailing newline", "value", "", "value"},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			p, _ := testPrompter(test.input)
+
+			ans, err := p.ask("q", test.def)
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, ans)
+		})
+	}
+}
+
+func (suite *SetupUnitSuite) TestAsk_noInput() {
+	p, _ := testPrompter("")
+
+	_, err := p.ask("q", "def")
+	assert.ErrorIs(suite.T(), err, errNoInput)
+}
+
+func (suite *SetupUnitSuite) TestAskRequired() {
+	t := suite.T()
+	p, out := testPrompter("\n\nvalue\n")
+
+	ans, err := p.askRequired("q", "")
+	require.NoError(t, err)
+	assert.Equal(t, "value", ans)
+	assert.Equal(t, 2, strings.Count(out.String(), "A value is required."))
+}
+
+func (suite *SetupUnitSuite) TestChoose() {
+	t := suite.T()
+	p, out := testPrompter("gcs\ns3\n")
+
+	ans, err := p.choose("q", []string{"S3"})
+	require.NoError(t, err)
+	assert.Equal(t, "S3", ans)
+	assert.Contains(t, out.String(), `Unsupported choice "gcs"`)
+
+	p, _ = testPrompter("\n")
+
+	ans, err = p.choose("q", []string{"S3"})
+	require.NoError(t, err)
+	assert.Equal(t, "S3", ans, "first option is the default")
+}
+
+func (suite *SetupUnitSuite) TestConfirm() {
+	table := []struct {
+		input  string
+		expect assert.BoolAssertionFunc
+	}{
+		{"y\n", assert.True},
+		{"YES\n", assert.True},
+		{"n\n", assert.False},
+		{"\n", assert.False},
+	}
+	for _, test := range table {
+		suite.Run(strings.TrimSpace(test.input), func() {
+			t := suite.T()
+			p, _ := testPrompter(test.input)
+
+			ok, err := p.confirm("q")
+			require.NoError(t, err)
+			test.expect(t, ok)
+		})
+	}
+}
+
+func (suite *SetupUnitSuite) TestAskEnv() {
+	t := suite.T()
+	t.Setenv(credentials.CorsoPassphrase, "")
+
+	p, _ := testPrompter("secret\n")
+
+	require.NoError(t, p.askEnv("q", credentials.CorsoPassphrase, true))
+	assert.Equal(t, "secret", os.Getenv(credentials.CorsoPassphrase))
+	assert.Equal(t, []string{credentials.CorsoPassphrase}, p.exported)
+
+	// already populated values are not prompted
+	p, _ = testPrompter("")
+
+	require.NoError(t, p.askEnv("q", credentials.CorsoPassphrase, true))
+	assert.Empty(t, p.exported)
+}
+
+func (suite *SetupUnitSuite) TestGatherM365() {
+	t := suite.T()
+	t.Setenv(account.AzureTenantID, "")
+	t.Setenv(credentials.AzureClientID, "")
+	t.Setenv(credentials.AzureClientSecret, "")
+
+	p, _ := testPrompter("tid\ncid\n\nsecret\n")
+
+	overrides, err := gatherM365(p)
+	require.NoError(t, err)
+	assert.Equal(t, "tid", overrides[account.AzureTenantID])
+	assert.Equal(t, account.ProviderM365.String(), overrides[config.AccountProviderTypeKey])
+	assert.Equal(t, string(account.AuthApplication), overrides[account.AzureAuthMode])
+	assert.Equal(t, "cid", os.Getenv(credentials.AzureClientID))
+	assert.Equal(t, "secret", os.Getenv(credentials.AzureClientSecret))
+}
+
+func (suite *SetupUnitSuite) TestGatherM365_delegated() {
+	t := suite.T()
+	t.Setenv(account.AzureTenantID, "")
+	t.Setenv(credentials.AzureClientID, "")
+	t.Setenv(credentials.AzureClientSecret, "")
+
+	p, _ := testPrompter("tid\ncid\ndevice-code\n")
+
+	overrides, err := gatherM365(p)
+	require.NoError(t, err)
+	assert.Equal(t, string(account.AuthDeviceCode), overrides[account.AzureAuthMode])
+	assert.Empty(t, os.Getenv(credentials.AzureClientSecret), "no client secret is requested")
+}
+
+func (suite *SetupUnitSuite) TestGatherStorage() {
+	t := suite.T()
+	t.Setenv(storage.BucketKey, "")
+	t.Setenv(storage.PrefixKey, "")
+	t.Setenv(storage.EndpointKey, "")
+	t.Setenv(credentials.CorsoPassphrase, "")
+
+	p, _ := testPrompter("\nbucket\nprefix\n\npassphrase\n")
+
+	overrides, err := gatherStorage(p)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ProviderS3.String(), overrides[config.StorageProviderTypeKey])
+	assert.Equal(t, "bucket", overrides[storage.Bucket])
+	assert.Equal(t, "prefix", overrides[storage.Prefix])
+	assert.Equal(t, "s3.amazonaws.com", overrides[storage.Endpoint])
+	assert.Equal(t, "passphrase", os.Getenv(credentials.CorsoPassphrase))
+}
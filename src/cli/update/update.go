@@ -0,0 +1,124 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/internal/update"
+	"github.com/alcionai/corso/src/internal/version"
+)
+
+const updateCommand = "update"
+
+// update flag values
+var (
+	channel   string
+	checkOnly bool
+)
+
+const updateCommandExamples = `# Update corso to the latest stable release
+corso update
+
+# Check for a newer beta release without installing it
+corso update --channel beta --check`
+
+// AddCommands attaches the `corso update` command to the parent.
+func AddCommands(cmd *cobra.Command) {
+	cmd.AddCommand(updateCmd())
+}
+
+// The update command.
+// `corso update [--channel stable|beta] [--check]`
+func updateCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   updateCommand,
+		Short: "Update Corso to the latest release",
+		Long: `Replaces the running Corso binary with the latest release in the release channel.
+The stable channel only includes full releases, while the beta channel also includes
+pre-releases.  The channel defaults to the config file's update_channel, or stable.
+
+Release checksums are verified against the signature published with each release, and
+the downloaded archive against its checksum, before the binary is replaced.`,
+		RunE:    handleUpdateCmd,
+		Args:    cobra.NoArgs,
+		Example: updateCommandExamples,
+	}
+
+	fs := c.Flags()
+	fs.StringVar(
+		&channel,
+		"channel", "",
+		"Release channel to update from: stable or beta.")
+	fs.BoolVar(
+		&checkOnly,
+		"check", false,
+		"Report whether a newer release is available without installing it.")
+
+	return c
+}
+
+// Handler for calls to `corso update`.
+func handleUpdateCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	name := channel
+	if len(name) == 0 {
+		name = config.GetUpdateChannel(ctx)
+	}
+
+	ch, err := update.ParseChannel(name)
+	if err != nil {
+		return Only(ctx, errors.Errorf("Unknown release channel %q; use stable or beta", name))
+	}
+
+	u, err := update.NewUpdater("")
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to set up the updater"))
+	}
+
+	rel, err := u.Latest(ctx, ch)
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to find the latest %s release", ch))
+	}
+
+	current := version.CurrentVersion()
+
+	if !update.Newer(current, rel.Tag) {
+		Infof(ctx, "Corso %s is up to date; the latest %s release is %s", current, ch, rel.Tag)
+		return nil
+	}
+
+	if checkOnly {
+		Infof(ctx, "Corso %s is available; run 'corso update' to install it", rel.Tag)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to find the Corso binary"))
+	}
+
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return Only(ctx, errors.Wrap(err, "Failed to find the Corso binary"))
+	}
+
+	Infof(ctx, "Downloading Corso %s", rel.Tag)
+
+	bin, err := u.Download(ctx, rel)
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to download Corso %s", rel.Tag))
+	}
+
+	if err := update.Install(exe, bin); err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to install Corso %s", rel.Tag))
+	}
+
+	Infof(ctx, "Updated Corso from %s to %s", current, rel.Tag)
+
+	return nil
+}
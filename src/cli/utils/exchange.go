@@ -12,11 +12,13 @@ const (
 	ContactFolderFN       = "contact-folder"
 	EmailFN               = "email"
 	EmailFolderFN         = "email-folder"
+	EmailFolderIDFN       = "email-folder-id"
 	EventFN               = "event"
 	EventCalendarFN       = "event-calendar"
 	ContactNameFN         = "contact-name"
 	EmailReceivedAfterFN  = "email-received-after"
 	EmailReceivedBeforeFN = "email-received-before"
+	EmailMessageIDFN      = "email-message-id"
 	EmailSenderFN         = "email-sender"
 	EmailSubjectFN        = "email-subject"
 	EventOrganizerFN      = "event-organizer"
@@ -24,6 +26,9 @@ const (
 	EventStartsAfterFN    = "event-starts-after"
 	EventStartsBeforeFN   = "event-starts-before"
 	EventSubjectFN        = "event-subject"
+	TaskFN                = "task"
+	TaskFolderFN          = "task-folder"
+	TaskSubjectFN         = "task-subject"
 )
 
 type ExchangeOpts struct {
@@ -31,12 +36,16 @@ type ExchangeOpts struct {
 	ContactFolder       []string
 	Email               []string
 	EmailFolder         []string
+	EmailFolderID       []string
 	Event               []string
 	EventCalendar       []string
+	Task                []string
+	TaskFolder          []string
 	Users               []string
 	ContactName         string
 	EmailReceivedAfter  string
 	EmailReceivedBefore string
+	EmailMessageID      string
 	EmailSender         string
 	EmailSubject        string
 	EventOrganizer      string
@@ -44,6 +53,7 @@ type ExchangeOpts struct {
 	EventStartsAfter    string
 	EventStartsBefore   string
 	EventSubject        string
+	TaskSubject         string
 
 	Populated PopulatedFlags
 }
@@ -133,11 +143,12 @@ func IncludeExchangeRestoreDataSelectors(opts ExchangeOpts) *selectors.ExchangeR
 	sel := selectors.NewExchangeRestore(users)
 
 	lc, lcf := len(opts.Contact), len(opts.ContactFolder)
-	le, lef := len(opts.Email), len(opts.EmailFolder)
+	le, lef, lefi := len(opts.Email), len(opts.EmailFolder), len(opts.EmailFolderID)
 	lev, lec := len(opts.Event), len(opts.EventCalendar)
+	lt, ltf := len(opts.Task), len(opts.TaskFolder)
 	// either scope the request to a set of users
-	if lc+lcf+le+lef+lev+lec == 0 {
-		sel.Include(sel.AllData())
+	if lc+lcf+le+lef+lefi+lev+lec+lt+ltf == 0 {
+		sel.Include(sel.AllData(), sel.TaskFolders(selectors.Any()))
 		return sel
 	}
 
@@ -147,6 +158,12 @@ func IncludeExchangeRestoreDataSelectors(opts ExchangeOpts) *selectors.ExchangeR
 	AddExchangeInclude(sel, opts.ContactFolder, opts.Contact, sel.Contacts)
 	AddExchangeInclude(sel, opts.EmailFolder, opts.Email, sel.Mails)
 	AddExchangeInclude(sel, opts.EventCalendar, opts.Event, sel.Events)
+	AddExchangeInclude(sel, opts.TaskFolder, opts.Task, sel.Tasks)
+
+	// folder IDs select the same folders even after they get renamed.
+	if lefi > 0 {
+		sel.Include(sel.MailFolderID(opts.EmailFolderID))
+	}
 
 	return sel
 }
@@ -159,6 +176,7 @@ func FilterExchangeRestoreInfoSelectors(
 	AddExchangeFilter(sel, opts.ContactName, sel.ContactName)
 	AddExchangeFilter(sel, opts.EmailReceivedAfter, sel.MailReceivedAfter)
 	AddExchangeFilter(sel, opts.EmailReceivedBefore, sel.MailReceivedBefore)
+	AddExchangeFilter(sel, opts.EmailMessageID, sel.MailMessageID)
 	AddExchangeFilter(sel, opts.EmailSender, sel.MailSender)
 	AddExchangeFilter(sel, opts.EmailSubject, sel.MailSubject)
 	AddExchangeFilter(sel, opts.EventOrganizer, sel.EventOrganizer)
@@ -166,4 +184,5 @@ func FilterExchangeRestoreInfoSelectors(
 	AddExchangeFilter(sel, opts.EventStartsAfter, sel.EventStartsAfter)
 	AddExchangeFilter(sel, opts.EventStartsBefore, sel.EventStartsBefore)
 	AddExchangeFilter(sel, opts.EventSubject, sel.EventSubject)
+	AddExchangeFilter(sel, opts.TaskSubject, sel.TaskSubject)
 }
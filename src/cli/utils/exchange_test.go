@@ -69,28 +69,28 @@ func (suite *ExchangeUtilsSuite) TestIncludeExchangeRestoreDataSelectors() {
 	}{
 		{
 			name:             "no selectors",
-			expectIncludeLen: 3,
+			expectIncludeLen: 4,
 		},
 		{
 			name: "any users",
 			opts: utils.ExchangeOpts{
 				Users: a,
 			},
-			expectIncludeLen: 3,
+			expectIncludeLen: 4,
 		},
 		{
 			name: "single user",
 			opts: utils.ExchangeOpts{
 				Users: stub,
 			},
-			expectIncludeLen: 3,
+			expectIncludeLen: 4,
 		},
 		{
 			name: "multiple users",
 			opts: utils.ExchangeOpts{
 				Users: many,
 			},
-			expectIncludeLen: 3,
+			expectIncludeLen: 4,
 		},
 		{
 			name: "any users, any data",
@@ -192,6 +192,24 @@ func (suite *ExchangeUtilsSuite) TestIncludeExchangeRestoreDataSelectors() {
 			},
 			expectIncludeLen: 1,
 		},
+		{
+			name: "any users, tasks",
+			opts: utils.ExchangeOpts{
+				Task:       a,
+				TaskFolder: a,
+				Users:      a,
+			},
+			expectIncludeLen: 1,
+		},
+		{
+			name: "single user, tasks",
+			opts: utils.ExchangeOpts{
+				Task:       stub,
+				TaskFolder: stub,
+				Users:      stub,
+			},
+			expectIncludeLen: 1,
+		},
 		{
 			name: "any users, contacts + email",
 			opts: utils.ExchangeOpts{
@@ -299,6 +317,21 @@ func (suite *ExchangeUtilsSuite) TestIncludeExchangeRestoreDataSelectors() {
 			},
 			expectIncludeLen: 1,
 		},
+		{
+			name: "mail folder ids",
+			opts: utils.ExchangeOpts{
+				EmailFolderID: many,
+			},
+			expectIncludeLen: 1,
+		},
+		{
+			name: "mail folders and folder ids",
+			opts: utils.ExchangeOpts{
+				EmailFolder:   stub,
+				EmailFolderID: many,
+			},
+			expectIncludeLen: 2,
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
@@ -460,6 +493,13 @@ func (suite *ExchangeUtilsSuite) TestFilterExchangeRestoreInfoSelectors() {
 			},
 			expectFilterLen: 1,
 		},
+		{
+			name: "taskSubject",
+			opts: utils.ExchangeOpts{
+				TaskSubject: stub,
+			},
+			expectFilterLen: 1,
+		},
 		{
 			name: "one of each",
 			opts: utils.ExchangeOpts{
@@ -473,8 +513,9 @@ func (suite *ExchangeUtilsSuite) TestFilterExchangeRestoreInfoSelectors() {
 				EventStartsAfter:    stub,
 				EventStartsBefore:   stub,
 				EventSubject:        stub,
+				TaskSubject:         stub,
 			},
-			expectFilterLen: 10,
+			expectFilterLen: 11,
 		},
 	}
 	for _, test := range table {
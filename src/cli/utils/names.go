@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/services/m365"
+)
+
+// ResolveUserNames replaces the display names among the users (ex: "Jane
+// Doe") with the principal names of the users they identify.  When a name
+// is shared by more than one user, the user is asked to choose between them
+// if the cli is interactive.  Otherwise an error lists the candidates.
+func ResolveUserNames(users []string, all []*m365.User) ([]string, error) {
+	return m365.ResolveDisplayNames(users, m365.UserNames(all), namePicker())
+}
+
+// ResolveBackedUpUserNames resolves the users selected from existing
+// backups, such as by restore and details.  The tenant's users are only
+// retrieved when some value is neither a principal name, an ID, nor the
+// wildcard, so that the backups of deleted users can still be selected by
+// their principal names.
+func ResolveBackedUpUserNames(ctx context.Context, acct account.Account, users []string) ([]string, error) {
+	if !slices.ContainsFunc(users, isDisplayName) {
+		return users, nil
+	}
+
+	// TODO: log/print recoverable errors
+	all, err := m365.Users(ctx, acct, fault.New(false))
+	if err != nil {
+		return nil, clues.Wrap(err, "retrieving m365 users")
+	}
+
+	return ResolveUserNames(users, all)
+}
+
+// isDisplayName is true if the user is identified by neither a principal
+// name, an ID, nor the wildcard.
+func isDisplayName(user string) bool {
+	if user == Wildcard || strings.Contains(user, "@") {
+		return false
+	}
+
+	_, err := uuid.Parse(user)
+
+	return err != nil
+}
+
+// ResolveSiteNames replaces the display names among the sites with the IDs
+// of the sites they identify.  Ambiguous names are handled in the same way
+// as ResolveUserNames.  siteIDs maps each site's webURL to its ID, and
+// siteNames maps each site's ID to its display name.
+func ResolveSiteNames(sites []string, siteIDs, siteNames map[string]string) ([]string, error) {
+	named := make([]m365.Named, 0, len(siteIDs))
+
+	for url, id := range siteIDs {
+		named = append(named, m365.Named{ID: id, Name: siteNames[id], Ref: url})
+	}
+
+	return m365.ResolveDisplayNames(sites, named, namePicker())
+}
+
+// namePicker produces the picker used for ambiguous names.  Replaceable
+// for testing.
+var namePicker = interactivePicker
+
+// interactivePicker asks the user to choose between ambiguous names when
+// stdin is a terminal.  Returns nil otherwise, so that scripted runs fail
+// deterministically instead of waiting on input.
+func interactivePicker() m365.PickFunc {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	return promptPicker(os.Stdin, os.Stderr)
+}
+
+// promptPicker lists the candidates on out, and reads the number of the
+// chosen candidate from in.
+func promptPicker(in io.Reader, out io.Writer) m365.PickFunc {
+	r := bufio.NewReader(in)
+
+	return func(name string, candidates []m365.Named) (m365.Named, error) {
+		fmt.Fprintf(out, "%q matches more than one resource:\n", name)
+
+		for i, c := range candidates {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, c)
+		}
+
+		for {
+			fmt.Fprintf(out, "Choose 1-%d: ", len(candidates))
+
+			line, err := r.ReadString('\n')
+			if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+				return m365.Named{}, m365.AmbiguousNameError{Name: name, Candidates: candidates}
+			}
+
+			n, convErr := strconv.Atoi(strings.TrimSpace(line))
+			if convErr == nil && n >= 1 && n <= len(candidates) {
+				return candidates[n-1], nil
+			}
+
+			if err != nil {
+				return m365.Named{}, m365.AmbiguousNameError{Name: name, Candidates: candidates}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/services/m365"
+)
+
+type NamesUnitSuite struct {
+	tester.Suite
+}
+
+func TestNamesUnitSuite(t *testing.T) {
+	suite.Run(t, &NamesUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *NamesUnitSuite) TestResolveSiteNames() {
+	var (
+		t       = suite.T()
+		siteIDs = map[string]string{
+			"https://contoso.sharepoint.com/sites/eng":     "sid-eng",
+			"https://contoso.sharepoint.com/sites/finance": "sid-fin",
+			"https://contoso.sharepoint.com/sites/fin-old": "sid-fin-old",
+		}
+		siteNames = map[string]string{
+			"sid-eng":     "Engineering",
+			"sid-fin":     "Finance",
+			"sid-fin-old": "Finance",
+		}
+	)
+
+	sites, err := ResolveSiteNames([]string{"engineering", "sid-fin", "*"}, siteIDs, siteNames)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sid-eng", "sid-fin", "*"}, sites)
+
+	// without a terminal, ambiguous names fail.
+	namePicker = func() m365.PickFunc { return nil }
+	defer func() { namePicker = interactivePicker }()
+
+	_, err = ResolveSiteNames([]string{"Finance"}, siteIDs, siteNames)
+	assert.ErrorAs(t, err, &m365.AmbiguousNameError{})
+
+	_, err = ResolveSiteNames([]string{"Marketing"}, siteIDs, siteNames)
+	assert.ErrorAs(t, err, &m365.UnknownNameError{})
+}
+
+func (suite *NamesUnitSuite) TestIsDisplayName() {
+	table := []struct {
+		user   string
+		expect assert.BoolAssertionFunc
+	}{
+		{Wildcard, assert.False},
+		{"jane@contoso.com", assert.False},
+		{"8f3c5d2e-1b4a-4c6d-9e7f-0a1b2c3d4e5f", assert.False},
+		{"Jane Doe", assert.True},
+		{"jane", assert.True},
+	}
+	for _, test := range table {
+		suite.Run(test.user, func() {
+			test.expect(suite.T(), isDisplayName(test.user))
+		})
+	}
+}
+
+func (suite *NamesUnitSuite) TestResolveBackedUpUserNames() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	// principal names, IDs, and wildcards don't require the tenant's users,
+	// which an empty account can't retrieve.
+	users := []string{"jane@contoso.com", "8f3c5d2e-1b4a-4c6d-9e7f-0a1b2c3d4e5f", Wildcard}
+
+	result, err := ResolveBackedUpUserNames(ctx, account.Account{}, users)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), users, result)
+}
+
+func (suite *NamesUnitSuite) TestPromptPicker() {
+	candidates := []m365.Named{
+		{ID: "jane@contoso.com", Name: "Jane Doe", Ref: "jane@contoso.com"},
+		{ID: "jdoe@contoso.com", Name: "Jane Doe", Ref: "jdoe@contoso.com"},
+	}
+
+	table := []struct {
+		name      string
+		input     string
+		expect    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "valid choice",
+			input:     "2\n",
+			expect:    "jdoe@contoso.com",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "choice without a newline",
+			input:     "1",
+			expect:    "jane@contoso.com",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "invalid choices are asked again",
+			input:     "3\njane\n1\n",
+			expect:    "jane@contoso.com",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "input ends",
+			input:     "0\n",
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			var (
+				t   = suite.T()
+				out = &bytes.Buffer{}
+			)
+
+			picked, err := promptPicker(strings.NewReader(test.input), out)("Jane Doe", candidates)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, picked.ID)
+			assert.Contains(t, out.String(), "2) jdoe@contoso.com (Jane Doe)")
+		})
+	}
+}
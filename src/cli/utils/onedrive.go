@@ -3,7 +3,10 @@ package utils
 import (
 	"errors"
 
+	"github.com/alcionai/corso/src/internal/connector/onedrive"
+	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 const (
@@ -15,6 +18,11 @@ const (
 	FileCreatedBeforeFN  = "file-created-before"
 	FileModifiedAfterFN  = "file-modified-after"
 	FileModifiedBeforeFN = "file-modified-before"
+	FileCreatedByFN      = "file-created-by"
+	FileContentTypeFN    = "file-content-type"
+	FileLabelFN          = "file-sensitivity-label"
+
+	ExcludeSyncArtifactsFN = "exclude-sync-artifacts"
 )
 
 type OneDriveOpts struct {
@@ -25,6 +33,10 @@ type OneDriveOpts struct {
 	FileCreatedBefore  string
 	FileModifiedAfter  string
 	FileModifiedBefore string
+	FileCreatedBy      string
+	FileContentType    string
+	FileLabel          string
+	ItemURL            string
 
 	Populated PopulatedFlags
 }
@@ -35,6 +47,10 @@ func ValidateOneDriveRestoreFlags(backupID string, opts OneDriveOpts) error {
 		return errors.New("a backup ID is required")
 	}
 
+	if err := validateItemURLFlags(opts.Populated, FolderFN, FileFN); err != nil {
+		return err
+	}
+
 	if _, ok := opts.Populated[FileCreatedAfterFN]; ok && !IsValidTimeFormat(opts.FileCreatedAfter) {
 		return errors.New("invalid time format for created-after")
 	}
@@ -106,6 +122,28 @@ func IncludeOneDriveRestoreDataSelectors(opts OneDriveOpts) *selectors.OneDriveR
 	return sel
 }
 
+// OneDriveItemRefOpts replaces the folder and file options with the
+// file or folder identified by an item url.
+func OneDriveItemRefOpts(opts OneDriveOpts, ref m365.DriveItemRef) OneDriveOpts {
+	opts.Paths, opts.Names = nil, nil
+
+	if fp := ref.FolderPath(); len(fp) > 0 {
+		opts.Paths = []string{string(path.PathSeparator) + fp}
+	}
+
+	if !ref.IsFolder {
+		opts.Names = driveItemNames(ref)
+	}
+
+	return opts
+}
+
+// driveItemNames are the names under which the item's data is backed up:
+// OneDrive files carry a data suffix, SharePoint library files don't.
+func driveItemNames(ref m365.DriveItemRef) []string {
+	return []string{ref.Name, ref.Name + onedrive.DataFileSuffix}
+}
+
 // FilterOneDriveRestoreInfoSelectors builds the common info-selector filters.
 func FilterOneDriveRestoreInfoSelectors(
 	sel *selectors.OneDriveRestore,
@@ -115,4 +153,7 @@ func FilterOneDriveRestoreInfoSelectors(
 	AddOneDriveFilter(sel, opts.FileCreatedBefore, sel.CreatedBefore)
 	AddOneDriveFilter(sel, opts.FileModifiedAfter, sel.ModifiedAfter)
 	AddOneDriveFilter(sel, opts.FileModifiedBefore, sel.ModifiedBefore)
+	AddOneDriveFilter(sel, opts.FileCreatedBy, sel.CreatedBy)
+	AddOneDriveFilter(sel, opts.FileContentType, sel.ContentType)
+	AddOneDriveFilter(sel, opts.FileLabel, sel.SensitivityLabel)
 }
@@ -8,6 +8,9 @@ import (
 
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 type OneDriveUtilsSuite struct {
@@ -95,3 +98,86 @@ func (suite *OneDriveUtilsSuite) TestIncludeOneDriveRestoreDataSelectors() {
 		})
 	}
 }
+
+func (suite *OneDriveUtilsSuite) TestOneDriveItemRefOpts() {
+	const folder = "tid/onedrive/uid/files/drives/did/root:"
+
+	var (
+		plan    = folder + "/Reports/plan.docx.data"
+		other   = folder + "/Reports/other.data"
+		nested  = folder + "/Reports/2023/nested.data"
+		unowned = folder + "/Archive/old.data"
+		deets   = &details.Details{}
+	)
+
+	for _, rr := range []string{plan, other, nested, unowned} {
+		deets.Entries = append(deets.Entries, details.DetailsEntry{
+			RepoRef:  rr,
+			ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{ItemType: details.OneDriveItem}},
+		})
+	}
+
+	table := []struct {
+		name   string
+		ref    m365.DriveItemRef
+		expect []string
+	}{
+		{
+			name:   "file",
+			ref:    m365.DriveItemRef{ItemID: "iid", Name: "plan.docx", ParentPath: "Reports"},
+			expect: []string{plan},
+		},
+		{
+			name:   "folder",
+			ref:    m365.DriveItemRef{ItemID: "fid", Name: "Reports", IsFolder: true},
+			expect: []string{plan, other, nested},
+		},
+		{
+			name:   "drive root",
+			ref:    m365.DriveItemRef{ItemID: "rid", Name: "root", IsFolder: true, IsRoot: true},
+			expect: []string{plan, other, nested, unowned},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			opts := utils.OneDriveItemRefOpts(utils.OneDriveOpts{Paths: []string{"Archive"}}, test.ref)
+			sel := utils.IncludeOneDriveRestoreDataSelectors(opts)
+
+			result := sel.Reduce(ctx, deets, fault.New(true))
+			assert.ElementsMatch(suite.T(), test.expect, result.Paths())
+		})
+	}
+}
+
+func (suite *OneDriveUtilsSuite) TestValidateOneDriveRestoreFlags_itemURL() {
+	table := []struct {
+		name      string
+		populated utils.PopulatedFlags
+		expect    assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "item url",
+			populated: utils.PopulatedFlags{utils.ItemURLFN: {}},
+			expect:    assert.NoError,
+		},
+		{
+			name:      "item url and folder",
+			populated: utils.PopulatedFlags{utils.ItemURLFN: {}, utils.FolderFN: {}},
+			expect:    assert.Error,
+		},
+		{
+			name:      "item url and file",
+			populated: utils.PopulatedFlags{utils.ItemURLFN: {}, utils.FileFN: {}},
+			expect:    assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			err := utils.ValidateOneDriveRestoreFlags("bid", utils.OneDriveOpts{Populated: test.populated})
+			test.expect(suite.T(), err)
+		})
+	}
+}
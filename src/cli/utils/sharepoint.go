@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 const (
@@ -26,6 +27,11 @@ type SharePointOpts struct {
 	Sites        []string
 	WebURLs      []string
 
+	FileCreatedBy   string
+	FileContentType string
+	FileLabel       string
+	ItemURL         string
+
 	Populated PopulatedFlags
 }
 
@@ -35,6 +41,10 @@ func ValidateSharePointRestoreFlags(backupID string, opts SharePointOpts) error
 		return errors.New("a backup ID is required")
 	}
 
+	if err := validateItemURLFlags(opts.Populated, LibraryFN, LibraryItemFN); err != nil {
+		return err
+	}
+
 	// if _, ok := opts.Populated[FileCreatedAfterFN]; ok && !IsValidTimeFormat(opts.FileCreatedAfter) {
 	// 	return errors.New("invalid time format for created-after")
 	// }
@@ -144,10 +154,32 @@ func IncludeSharePointRestoreDataSelectors(opts SharePointOpts) *selectors.Share
 	return sel
 }
 
+// SharePointItemRefOpts replaces the library options with the library
+// file or folder identified by an item url.
+func SharePointItemRefOpts(opts SharePointOpts, ref m365.DriveItemRef) SharePointOpts {
+	// library folders are identified by their drive, so that identically
+	// named folders in other libraries aren't restored.
+	folder := "/drives/" + ref.DriveID + "/root:"
+	if fp := ref.FolderPath(); len(fp) > 0 {
+		folder += "/" + fp
+	}
+
+	opts.LibraryPaths, opts.LibraryItems = []string{folder}, nil
+
+	if !ref.IsFolder {
+		opts.LibraryItems = driveItemNames(ref)
+	}
+
+	return opts
+}
+
 // FilterSharePointRestoreInfoSelectors builds the common info-selector filters.
 func FilterSharePointRestoreInfoSelectors(
 	sel *selectors.SharePointRestore,
 	opts SharePointOpts,
 ) {
 	// AddSharePointFilter(sel, opts.FileCreatedAfter, sel.CreatedAfter)
+	AddSharePointFilter(sel, opts.FileCreatedBy, sel.CreatedBy)
+	AddSharePointFilter(sel, opts.FileContentType, sel.ContentType)
+	AddSharePointFilter(sel, opts.FileLabel, sel.SensitivityLabel)
 }
@@ -8,6 +8,9 @@ import (
 
 	"github.com/alcionai/corso/src/cli/utils"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 type SharePointUtilsSuite struct {
@@ -188,3 +191,59 @@ func (suite *SharePointUtilsSuite) TestIncludeSharePointRestoreDataSelectors() {
 		})
 	}
 }
+
+func (suite *SharePointUtilsSuite) TestSharePointItemRefOpts() {
+	const (
+		docs  = "tid/sharepoint/sid/libraries/drives/docs/root:"
+		other = "tid/sharepoint/sid/libraries/drives/other/root:"
+	)
+
+	var (
+		spec        = docs + "/Specs/spec.docx"
+		draft       = docs + "/Specs/draft.docx"
+		nested      = docs + "/Specs/Old/nested.docx"
+		otherDrives = other + "/Specs/spec.docx"
+		deets       = &details.Details{}
+	)
+
+	for _, rr := range []string{spec, draft, nested, otherDrives} {
+		deets.Entries = append(deets.Entries, details.DetailsEntry{
+			RepoRef:  rr,
+			ItemInfo: details.ItemInfo{SharePoint: &details.SharePointInfo{ItemType: details.OneDriveItem}},
+		})
+	}
+
+	table := []struct {
+		name   string
+		ref    m365.DriveItemRef
+		expect []string
+	}{
+		{
+			name:   "file",
+			ref:    m365.DriveItemRef{DriveID: "docs", ItemID: "iid", Name: "spec.docx", ParentPath: "Specs"},
+			expect: []string{spec},
+		},
+		{
+			name:   "folder",
+			ref:    m365.DriveItemRef{DriveID: "docs", ItemID: "fid", Name: "Specs", IsFolder: true},
+			expect: []string{spec, draft, nested},
+		},
+		{
+			name:   "library root",
+			ref:    m365.DriveItemRef{DriveID: "docs", ItemID: "rid", Name: "root", IsFolder: true, IsRoot: true},
+			expect: []string{spec, draft, nested},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			opts := utils.SharePointItemRefOpts(utils.SharePointOpts{}, test.ref)
+			sel := utils.IncludeSharePointRestoreDataSelectors(opts)
+
+			result := sel.Reduce(ctx, deets, fault.New(true))
+			assert.ElementsMatch(suite.T(), test.expect, result.Paths())
+		})
+	}
+}
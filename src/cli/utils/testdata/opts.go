@@ -11,6 +11,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/backup"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
 	"github.com/alcionai/corso/src/pkg/selectors/testdata"
 	"github.com/alcionai/corso/src/pkg/store"
@@ -512,6 +513,14 @@ func (MockBackupGetter) BackupsByTag(
 	return nil, errors.New("unexpected call to mock")
 }
 
+func (MockBackupGetter) BackupTrend(
+	context.Context,
+	path.ServiceType,
+	string,
+) (*backup.Trend, error) {
+	return nil, errors.New("unexpected call to mock")
+}
+
 func (bg *MockBackupGetter) BackupDetails(
 	ctx context.Context,
 	backupID string,
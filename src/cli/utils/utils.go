@@ -4,21 +4,38 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/alcionai/corso/src/internal/connector/onedrive"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/repository"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 // common flag names
 const (
-	BackupFN = "backup"
-	DataFN   = "data"
-	SiteFN   = "site"
-	UserFN   = "user"
+	AtFN                 = "at"
+	BackupFN             = "backup"
+	ColumnsFN            = "columns"
+	DataFN               = "data"
+	DestinationFolderFN  = "destination-folder"
+	DestinationLibraryFN = "destination-library"
+	DestinationSiteFN    = "destination-site"
+	GroupFN              = "group"
+	IdempotencyKeyFN     = "idempotency-key"
+	ItemURLFN            = "item-url"
+	ParallelOwnersFN     = "parallel-owners"
+	QuarantineFN         = "quarantine"
+	ResumeFN             = "resume"
+	SiteFN               = "site"
+	TransformRulesFN     = "transform-rules"
+	UserFN               = "user"
 )
 
 const (
@@ -58,6 +75,134 @@ func HasNoFlagsAndShownHelp(cmd *cobra.Command) bool {
 	return false
 }
 
+// ResolveItemURL identifies the OneDrive or SharePoint file or folder at the
+// url provided by --item-url.  Returns an error if the item doesn't belong
+// to the service.
+func ResolveItemURL(
+	ctx context.Context,
+	acct account.Account,
+	itemURL string,
+	service path.ServiceType,
+) (*m365.DriveItemRef, error) {
+	ref, err := m365.ResolveDriveItemURL(ctx, acct, itemURL)
+	if err != nil {
+		return nil, fmt.Errorf("resolving the item url: %w", err)
+	}
+
+	if ref.Service != service {
+		return nil, fmt.Errorf("the item url belongs to %s, not %s", ref.Service, service)
+	}
+
+	return ref, nil
+}
+
+// LocateDriveItem points the ref at the backed up copy of its item.  The
+// backup's entries are matched by the item's ID rather than its current
+// location, so that files and folders moved or renamed since the backup
+// are still restored.  Backups whose details predate item IDs keep the
+// item's current location.
+func LocateDriveItem(ref m365.DriveItemRef, deets *details.Details) (m365.DriveItemRef, error) {
+	if ref.IsRoot {
+		return ref, nil
+	}
+
+	var hasIDs bool
+
+	for _, ent := range deets.Entries {
+		// tombstones of deleted items keep the item's info, but the
+		// item itself isn't in the backup.
+		if ent.Deleted {
+			continue
+		}
+
+		itemID, parentID := driveEntryIDs(ent)
+		if len(itemID) == 0 {
+			continue
+		}
+
+		hasIDs = true
+
+		rr, err := path.FromDataLayerPath(ent.RepoRef, true)
+		if err != nil {
+			return ref, fmt.Errorf("parsing backup details entry: %w", err)
+		}
+
+		// drive folders are prefixed with drives/<driveID>/root:
+		folders := rr.Folders()
+		if len(folders) < 3 || folders[1] != ref.DriveID {
+			continue
+		}
+
+		var (
+			fp   = folders[3:]
+			name = rr.Item()
+		)
+
+		switch {
+		case !ref.IsFolder && itemID == ref.ItemID && !isDriveMetaName(name):
+			ref.ParentPath = strings.Join(fp, "/")
+			ref.Name = strings.TrimSuffix(name, onedrive.DataFileSuffix)
+
+			return ref, nil
+
+		case ref.IsFolder && itemID == ref.ItemID && strings.HasSuffix(name, onedrive.DirMetaFileSuffix):
+			ref.ParentPath = strings.Join(fp, "/")
+			ref.Name = strings.TrimSuffix(name, onedrive.DirMetaFileSuffix)
+
+			return ref, nil
+
+		case ref.IsFolder && parentID == ref.ItemID && len(fp) > 0:
+			ref.ParentPath = strings.Join(fp[:len(fp)-1], "/")
+			ref.Name = fp[len(fp)-1]
+
+			return ref, nil
+		}
+	}
+
+	if !hasIDs {
+		return ref, nil
+	}
+
+	return ref, fmt.Errorf("the item at the url is not in the backup")
+}
+
+// driveEntryIDs returns the drive item and parent folder IDs recorded
+// for the entry, if any.
+func driveEntryIDs(ent details.DetailsEntry) (string, string) {
+	switch {
+	case ent.OneDrive != nil:
+		return ent.OneDrive.ItemID, ent.OneDrive.ParentID
+	case ent.SharePoint != nil:
+		return ent.SharePoint.ItemID, ent.SharePoint.ParentID
+	}
+
+	return "", ""
+}
+
+// isDriveMetaName is true for the names of the entries that hold the
+// metadata of drive files and folders.
+func isDriveMetaName(name string) bool {
+	return strings.HasSuffix(name, onedrive.MetaFileSuffix) ||
+		strings.HasSuffix(name, onedrive.DirMetaFileSuffix) ||
+		strings.HasSuffix(name, onedrive.FieldsFileSuffix)
+}
+
+// validateItemURLFlags ensures --item-url isn't combined with the flags
+// that also select the items' folders and names.
+func validateItemURLFlags(populated PopulatedFlags, exclusive ...string) error {
+	if _, ok := populated[ItemURLFN]; !ok {
+		return nil
+	}
+
+	for _, fn := range exclusive {
+		if _, ok := populated[fn]; ok {
+			return fmt.Errorf("--%s can't be combined with --%s", ItemURLFN, fn)
+		}
+	}
+
+	return nil
+}
+
 type cmdCfg struct {
 	hidden    bool
 	preRelese bool
@@ -7,7 +7,9 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/services/m365"
 )
 
 type CliUtilsSuite struct {
@@ -85,3 +87,107 @@ func (suite *CliUtilsSuite) TestSplitFoldersIntoContainsAndPrefix() {
 		})
 	}
 }
+
+func (suite *CliUtilsSuite) TestLocateDriveItem() {
+	const folder = "tid/onedrive/uid/files/drives/did/root:"
+
+	entry := func(rr, itemID, parentID string) details.DetailsEntry {
+		return details.DetailsEntry{
+			RepoRef: rr,
+			ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				ItemType: details.OneDriveItem,
+				ItemID:   itemID,
+				ParentID: parentID,
+			}},
+		}
+	}
+
+	tombstone := func(rr, itemID, parentID string) details.DetailsEntry {
+		ent := entry(rr, itemID, parentID)
+		ent.Deleted = true
+
+		return ent
+	}
+
+	// the file and its folder have been renamed and moved since the backup.
+	// the backup also holds tombstones of the file's earlier location, and
+	// of a deleted file.
+	deets := &details.Details{}
+	deets.Entries = []details.DetailsEntry{
+		tombstone(folder+"/Drafts/plan.docx.data", "iid", "did0"),
+		tombstone(folder+"/Drafts/gone.txt.data", "gid", "did0"),
+		entry(folder+"/Reports/Q1/plan.docx.meta", "iid", "fid"),
+		entry(folder+"/Reports/Q1/plan.docx.data", "iid", "fid"),
+		entry(folder+"/Reports/Q1.dirmeta", "fid", "rfid"),
+		entry(folder+"/Reports/Q2/notes.txt.data", "nid", "f2id"),
+	}
+
+	table := []struct {
+		name      string
+		deets     *details.Details
+		ref       m365.DriveItemRef
+		expect    m365.DriveItemRef
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "moved file",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "iid", Name: "final.docx", ParentPath: "Archive"},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "iid", Name: "plan.docx", ParentPath: "Reports/Q1"},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "moved folder",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "fid", Name: "2023", IsFolder: true},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "fid", Name: "Q1", ParentPath: "Reports", IsFolder: true},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "folder without a dirmeta",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "f2id", Name: "Q3", IsFolder: true},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "f2id", Name: "Q2", ParentPath: "Reports", IsFolder: true},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "drive root",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "rid", IsFolder: true, IsRoot: true},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "rid", IsFolder: true, IsRoot: true},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "other drive",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did2", ItemID: "iid", Name: "plan.docx"},
+			expect:    m365.DriveItemRef{DriveID: "did2", ItemID: "iid", Name: "plan.docx"},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "not backed up",
+			deets:     deets,
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "new", Name: "new.docx"},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "new", Name: "new.docx"},
+			expectErr: assert.Error,
+		},
+		{
+			name: "details without item IDs",
+			deets: &details.Details{DetailsModel: details.DetailsModel{Entries: []details.DetailsEntry{
+				entry(folder+"/Reports/Q1/plan.docx.data", "", ""),
+			}}},
+			ref:       m365.DriveItemRef{DriveID: "did", ItemID: "iid", Name: "plan.docx", ParentPath: "Archive"},
+			expect:    m365.DriveItemRef{DriveID: "did", ItemID: "iid", Name: "plan.docx", ParentPath: "Archive"},
+			expectErr: assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			result, err := LocateDriveItem(test.ref, test.deets)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
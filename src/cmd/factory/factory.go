@@ -29,6 +29,12 @@ var oneDriveCmd = &cobra.Command{
 	RunE:  handleOneDriveFactory,
 }
 
+var loadTestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Generate data, then record the throughput of backing it up and restoring it",
+	RunE:  handleLoadTestFactory,
+}
+
 // ------------------------------------------------------------------------------------------
 // CLI command handlers
 // ------------------------------------------------------------------------------------------
@@ -48,11 +54,19 @@ func main() {
 	cobra.CheckErr(factoryCmd.MarkPersistentFlagRequired("count"))
 	fs.StringVar(&impl.Destination, "destination", "", "destination of the new data (will create as needed)")
 	cobra.CheckErr(factoryCmd.MarkPersistentFlagRequired("destination"))
+	fs.IntVar(&impl.FolderDepth, "folder-depth", 0, "depth of the folder tree nested beneath the destination")
+	fs.IntVar(&impl.FolderWidth, "folder-width", 1, "count of subfolders within each folder of the tree")
+	fs.StringVar(
+		&impl.SecondaryUser,
+		"secondary-user", "",
+		"m365 user who gets granted a variety of permissions on generated onedrive data")
 
 	factoryCmd.AddCommand(exchangeCmd)
 	impl.AddExchangeCommands(exchangeCmd)
 	factoryCmd.AddCommand(oneDriveCmd)
 	impl.AddOneDriveCommands(oneDriveCmd)
+	factoryCmd.AddCommand(loadTestCmd)
+	impl.AddLoadTestCommands(loadTestCmd)
 
 	if err := factoryCmd.ExecuteContext(ctx); err != nil {
 		logger.Flush(ctx)
@@ -74,3 +88,8 @@ func handleOneDriveFactory(cmd *cobra.Command, args []string) error {
 	Err(cmd.Context(), impl.ErrNotYetImplemeted)
 	return cmd.Help()
 }
+
+func handleLoadTestFactory(cmd *cobra.Command, args []string) error {
+	Err(cmd.Context(), impl.ErrNotYetImplemeted)
+	return cmd.Help()
+}
@@ -1,7 +1,10 @@
 package impl
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -26,10 +29,13 @@ import (
 )
 
 var (
-	Count       int
-	Destination string
-	Tenant      string
-	User        string
+	Count         int
+	Destination   string
+	FolderDepth   int
+	FolderWidth   int
+	SecondaryUser string
+	Tenant        string
+	User          string
 )
 
 // TODO: ErrGenerating       = errors.New("not all items were successfully generated")
@@ -42,6 +48,9 @@ var ErrNotYetImplemeted = errors.New("not yet implemented")
 
 type dataBuilderFunc func(id, now, subject, body string) []byte
 
+// generateAndRestoreItems produces howMany items, spread evenly across the
+// folders, and restores them into the user's account.  Each folder is a set
+// of path elements, beginning with the destination folder.
 func generateAndRestoreItems(
 	ctx context.Context,
 	gc *connector.GraphConnector,
@@ -50,12 +59,20 @@ func generateAndRestoreItems(
 	cat path.CategoryType,
 	sel selectors.Selector,
 	tenantID, userID, destFldr string,
+	folders [][]string,
 	howMany int,
 	dbf dataBuilderFunc,
 	opts control.Options,
 	errs *fault.Errors,
 ) (*details.Details, error) {
-	items := make([]item, 0, howMany)
+	collections := make([]collection, 0, len(folders))
+
+	for _, f := range folders {
+		collections = append(collections, collection{
+			pathElements: f,
+			category:     cat,
+		})
+	}
 
 	for i := 0; i < howMany; i++ {
 		var (
@@ -64,20 +81,15 @@ func generateAndRestoreItems(
 			id        = uuid.NewString()
 			subject   = "automated " + now[:16] + " - " + id[:8]
 			body      = "automated " + cat.String() + " generation for " + userID + " at " + now + " - " + id
+			c         = &collections[i%len(collections)]
 		)
 
-		items = append(items, item{
+		c.items = append(c.items, item{
 			name: id,
 			data: dbf(id, nowLegacy, subject, body),
 		})
 	}
 
-	collections := []collection{{
-		pathElements: []string{destFldr},
-		category:     cat,
-		items:        items,
-	}}
-
 	// TODO: fit the destination to the containers
 	dest := control.DefaultRestoreDestination(common.SimpleTimeTesting)
 	dest.ContainerName = destFldr
@@ -101,6 +113,35 @@ func generateAndRestoreItems(
 // Common Helpers
 // ------------------------------------------------------------------------------------------
 
+// folderTree produces the path elements of the root folder and every folder
+// nested beneath it, with width subfolders in each folder, down to the given
+// depth.  Parents always precede their children.
+func folderTree(root string, depth, width int) [][]string {
+	var (
+		tree  = [][]string{{root}}
+		level = [][]string{{root}}
+	)
+
+	for d := 1; d <= depth; d++ {
+		next := make([][]string, 0, len(level)*width)
+
+		for _, parent := range level {
+			for w := 0; w < width; w++ {
+				child := make([]string, 0, len(parent)+1)
+				child = append(child, parent...)
+				child = append(child, fmt.Sprintf("folder-%d-%d", d, w))
+
+				next = append(next, child)
+			}
+		}
+
+		tree = append(tree, next...)
+		level = next
+	}
+
+	return tree
+}
+
 func getGCAndVerifyUser(ctx context.Context, userID string) (*connector.GraphConnector, account.Account, error) {
 	tid := common.First(Tenant, os.Getenv(account.AzureTenantID))
 
@@ -142,6 +183,22 @@ type item struct {
 	data []byte
 }
 
+// restoreCollection serves its auxiliary items, such as the metadata of
+// onedrive files and folders, through Fetch.
+type restoreCollection struct {
+	data.Collection
+	aux map[string]data.Stream
+}
+
+func (rc restoreCollection) Fetch(ctx context.Context, name string) (data.Stream, error) {
+	s, ok := rc.aux[name]
+	if !ok {
+		return nil, data.ErrNotFound
+	}
+
+	return s, nil
+}
+
 type collection struct {
 	// Elements (in order) for the path representing this collection. Should
 	// only contain elements after the prefix that corso uses for the path. For
@@ -150,6 +207,9 @@ type collection struct {
 	pathElements []string
 	category     path.CategoryType
 	items        []item
+	// aux items are only retrievable through Fetch, and are not
+	// produced by Items().
+	aux []item
 }
 
 func buildCollections(
@@ -180,7 +240,16 @@ func buildCollections(
 			mc.Data[i] = c.items[i].data
 		}
 
-		collections = append(collections, data.NotFoundRestoreCollection{Collection: mc})
+		rc := restoreCollection{Collection: mc, aux: map[string]data.Stream{}}
+
+		for _, a := range c.aux {
+			rc.aux[a.name] = &mockconnector.MockExchangeData{
+				ID:     a.name,
+				Reader: io.NopCloser(bytes.NewReader(a.data)),
+			}
+		}
+
+		collections = append(collections, rc)
 	}
 
 	return collections, nil
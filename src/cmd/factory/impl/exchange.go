@@ -1,12 +1,27 @@
 package impl
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	. "github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector"
 	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
@@ -34,18 +49,31 @@ var (
 	}
 )
 
+var (
+	attachmentSizeMB int
+	recurring        bool
+	exceptions       int
+)
+
 func AddExchangeCommands(cmd *cobra.Command) {
 	cmd.AddCommand(emailsCmd)
+	emailsCmd.Flags().IntVar(
+		&attachmentSizeMB,
+		"attachment-size-mb", 0,
+		"size, in megabytes, of an attachment added to each email (max 24)")
+
 	cmd.AddCommand(eventsCmd)
+	fs := eventsCmd.Flags()
+	fs.BoolVar(&recurring, "recurring", false, "generate weekly recurring event series")
+	fs.IntVar(&exceptions, "exceptions", 2, "count of cancelled or rescheduled occurrences in each recurring series")
+
 	cmd.AddCommand(contactsCmd)
 }
 
 func handleExchangeEmailFactory(cmd *cobra.Command, args []string) error {
 	var (
-		ctx      = cmd.Context()
-		service  = path.ExchangeService
-		category = path.EmailCategory
-		errs     = fault.New(false)
+		ctx  = cmd.Context()
+		errs = fault.New(false)
 	)
 
 	if utils.HasNoFlagsAndShownHelp(cmd) {
@@ -57,16 +85,44 @@ func handleExchangeEmailFactory(cmd *cobra.Command, args []string) error {
 		return Only(ctx, err)
 	}
 
-	deets, err := generateAndRestoreItems(
+	deets, err := generateAndRestoreEmails(ctx, gc, acct, errs)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	log := logger.Ctx(ctx)
+	for _, e := range errs.Errs() {
+		log.Errorw(e.Error(), clues.InErr(err).Slice()...)
+	}
+
+	deets.PrintEntries(ctx)
+
+	return nil
+}
+
+// generateAndRestoreEmails produces Count emails, spread evenly across a
+// folder tree beneath the destination folder.
+func generateAndRestoreEmails(
+	ctx context.Context,
+	gc *connector.GraphConnector,
+	acct account.Account,
+	errs *fault.Errors,
+) (*details.Details, error) {
+	return generateAndRestoreItems(
 		ctx,
 		gc,
 		acct,
-		service,
-		category,
+		path.ExchangeService,
+		path.EmailCategory,
 		selectors.NewExchangeRestore([]string{User}).Selector,
 		Tenant, User, Destination,
+		folderTree(Destination, FolderDepth, FolderWidth),
 		Count,
 		func(id, now, subject, body string) []byte {
+			if attachmentSizeMB > 0 {
+				return mockconnector.GetMockMessageWithSizedAttachment(subject, attachmentSizeMB)
+			}
+
 			return mockconnector.GetMockMessageWith(
 				User, User, User,
 				subject, body, body,
@@ -74,18 +130,6 @@ func handleExchangeEmailFactory(cmd *cobra.Command, args []string) error {
 		},
 		control.Options{},
 		errs)
-	if err != nil {
-		return Only(ctx, err)
-	}
-
-	log := logger.Ctx(ctx)
-	for _, e := range errs.Errs() {
-		log.Errorw(e.Error(), clues.InErr(err).Slice()...)
-	}
-
-	deets.PrintEntries(ctx)
-
-	return nil
 }
 
 func handleExchangeCalendarEventFactory(cmd *cobra.Command, args []string) error {
@@ -105,6 +149,11 @@ func handleExchangeCalendarEventFactory(cmd *cobra.Command, args []string) error
 		return Only(ctx, err)
 	}
 
+	var (
+		start  = time.Now().UTC().Truncate(time.Hour)
+		series = map[string]struct{}{}
+	)
+
 	deets, err := generateAndRestoreItems(
 		ctx,
 		gc,
@@ -113,11 +162,24 @@ func handleExchangeCalendarEventFactory(cmd *cobra.Command, args []string) error
 		category,
 		selectors.NewExchangeRestore([]string{User}).Selector,
 		Tenant, User, Destination,
+		[][]string{{Destination}},
 		Count,
 		func(id, now, subject, body string) []byte {
-			return mockconnector.GetMockEventWith(
+			if !recurring {
+				return mockconnector.GetMockEventWith(
+					User, subject, body, body,
+					now, now, false)
+			}
+
+			series[subject] = struct{}{}
+
+			evt := mockconnector.GetMockEventWith(
 				User, subject, body, body,
-				now, now, false)
+				common.FormatLegacyTime(start),
+				common.FormatLegacyTime(start.Add(30*time.Minute)),
+				false)
+
+			return withWeeklyRecurrence(evt, start)
 		},
 		control.Options{},
 		errs)
@@ -125,6 +187,12 @@ func handleExchangeCalendarEventFactory(cmd *cobra.Command, args []string) error
 		return Only(ctx, err)
 	}
 
+	if recurring && exceptions > 0 {
+		if err := addEventExceptions(ctx, gc, series, start, exceptions); err != nil {
+			return Only(ctx, err)
+		}
+	}
+
 	log := logger.Ctx(ctx)
 	for _, e := range errs.Errs() {
 		log.Errorw(e.Error(), clues.InErr(err).Slice()...)
@@ -160,6 +228,7 @@ func handleExchangeContactFactory(cmd *cobra.Command, args []string) error {
 		category,
 		selectors.NewExchangeRestore([]string{User}).Selector,
 		Tenant, User, Destination,
+		[][]string{{Destination}},
 		Count,
 		func(id, now, subject, body string) []byte {
 			given, mid, sur := id[:8], id[9:13], id[len(id)-12:]
@@ -186,3 +255,138 @@ func handleExchangeContactFactory(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// ------------------------------------------------------------------------------------------
+// Recurring Events
+// ------------------------------------------------------------------------------------------
+
+// count of occurrences in each generated event series.
+const seriesOccurrences = 8
+
+// withWeeklyRecurrence turns the serialized event into the master of a
+// weekly series of occurrences, beginning on the start date.
+func withWeeklyRecurrence(evt []byte, start time.Time) []byte {
+	recurrence := fmt.Sprintf(
+		`"type":"seriesMaster",`+
+			`"recurrence":{`+
+			`"pattern":{"type":"weekly","interval":1,"daysOfWeek":["%s"],"firstDayOfWeek":"sunday"},`+
+			`"range":{"type":"numbered","startDate":"%s","numberOfOccurrences":%d,"recurrenceTimeZone":"UTC"}},`,
+		strings.ToLower(start.Weekday().String()),
+		common.FormatTimeWith(start, common.DateOnly),
+		seriesOccurrences)
+
+	return bytes.Replace(evt, []byte(`"type":"singleInstance",`), []byte(recurrence), 1)
+}
+
+// addEventExceptions adds exceptions to the restored event series whose
+// subjects are in the set.  The first occurrence in each series is left
+// intact.  Following occurrences alternate between being cancelled and being
+// rescheduled an hour later, until each series holds n exceptions.
+func addEventExceptions(
+	ctx context.Context,
+	gc *connector.GraphConnector,
+	series map[string]struct{},
+	start time.Time,
+	n int,
+) error {
+	var (
+		client      = gc.Service.Client()
+		from        = common.FormatTimeWith(start.AddDate(0, 0, -1), common.LegacyTime)
+		to          = common.FormatTimeWith(start.AddDate(0, 0, 7*seriesOccurrences+1), common.LegacyTime)
+		top         = int32(100)
+		occurrences = map[string][]models.Eventable{}
+		builder     = client.UsersById(User).CalendarView()
+		options     = &users.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemCalendarViewRequestBuilderGetQueryParameters{
+				StartDateTime: &from,
+				EndDateTime:   &to,
+				Top:           &top,
+				Select:        []string{"id", "subject", "type", "seriesMasterId", "start", "end"},
+			},
+		}
+	)
+
+	for {
+		resp, err := builder.Get(ctx, options)
+		if err != nil {
+			return errors.Wrap(err, "listing event occurrences")
+		}
+
+		for _, evt := range resp.GetValue() {
+			if evt.GetType() == nil || *evt.GetType() != models.OCCURRENCE_EVENTTYPE {
+				continue
+			}
+
+			if _, ok := series[ptr.Val(evt.GetSubject())]; !ok {
+				continue
+			}
+
+			master := ptr.Val(evt.GetSeriesMasterId())
+			occurrences[master] = append(occurrences[master], evt)
+		}
+
+		link := ptr.Val(resp.GetOdataNextLink())
+		if len(link) == 0 {
+			break
+		}
+
+		// the next link carries the original query parameters.
+		builder = users.NewItemCalendarViewRequestBuilder(link, gc.Service.Adapter())
+		options = nil
+	}
+
+	Infof(ctx, "Adding %d exceptions to each of %d event series\n", n, len(occurrences))
+
+	for _, occs := range occurrences {
+		sort.Slice(occs, func(i, j int) bool {
+			return ptr.Val(occs[i].GetStart().GetDateTime()) < ptr.Val(occs[j].GetStart().GetDateTime())
+		})
+
+		for i := 1; i <= n && i < len(occs); i++ {
+			var (
+				occ = occs[i]
+				id  = ptr.Val(occ.GetId())
+				err error
+			)
+
+			if i%2 == 1 {
+				err = client.UsersById(User).EventsById(id).Delete(ctx, nil)
+			} else {
+				subject := ptr.Val(occ.GetSubject()) + " (rescheduled)"
+
+				patch := models.NewEvent()
+				patch.SetSubject(&subject)
+				patch.SetStart(laterBy(occ.GetStart(), time.Hour))
+				patch.SetEnd(laterBy(occ.GetEnd(), time.Hour))
+
+				_, err = client.UsersById(User).EventsById(id).Patch(ctx, patch, nil)
+			}
+
+			if err != nil {
+				return errors.Wrap(err, "adding exception to event series")
+			}
+		}
+	}
+
+	return nil
+}
+
+// laterBy produces a copy of the dateTimeTimeZone, shifted later by d.
+func laterBy(dt models.DateTimeTimeZoneable, d time.Duration) models.DateTimeTimeZoneable {
+	// fractional seconds are accepted when parsing, even though the
+	// layout doesn't specify them.
+	t, err := time.Parse("2006-01-02T15:04:05", ptr.Val(dt.GetDateTime()))
+	if err != nil {
+		return dt
+	}
+
+	var (
+		shifted = models.NewDateTimeTimeZone()
+		when    = common.FormatTimeWith(t.Add(d), common.M365DateTimeTimeZone)
+	)
+
+	shifted.SetDateTime(&when)
+	shifted.SetTimeZone(dt.GetTimeZone())
+
+	return shifted
+}
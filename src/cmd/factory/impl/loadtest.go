@@ -0,0 +1,386 @@
+package impl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/alcionai/corso/src/cli/config"
+	. "github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/connector"
+	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/repository"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+var (
+	loadTestExchangeCmd = &cobra.Command{
+		Use:   "exchange",
+		Short: "Load test the backup and restore of generated exchange emails",
+		RunE:  handleExchangeLoadTest,
+	}
+
+	loadTestOneDriveCmd = &cobra.Command{
+		Use:   "onedrive",
+		Short: "Load test the backup and restore of generated onedrive files",
+		RunE:  handleOneDriveLoadTest,
+	}
+)
+
+var (
+	baselineFile  string
+	maxRegression float64
+)
+
+// AddLoadTestCommands adds the load test subcommands.  Load tests generate
+// a dataset, back up and restore the dataset with the repository in the
+// corso config file, and record the throughput of each operation as a
+// baseline.
+func AddLoadTestCommands(cmd *cobra.Command) {
+	cmd.PersistentPreRunE = config.InitFunc
+	config.AddConfigFlags(cmd)
+
+	fs := cmd.PersistentFlags()
+	fs.StringVar(
+		&baselineFile,
+		"baseline-file", "",
+		"file to which throughput baselines are appended, one json object per line")
+	fs.Float64Var(
+		&maxRegression,
+		"max-regression", 20,
+		"percent drop in throughput, compared to the previous baseline, which fails the load test")
+
+	cmd.AddCommand(loadTestExchangeCmd)
+	loadTestExchangeCmd.Flags().IntVar(
+		&attachmentSizeMB,
+		"attachment-size-mb", 0,
+		"size, in megabytes, of an attachment added to each email (max 24)")
+
+	cmd.AddCommand(loadTestOneDriveCmd)
+	loadTestOneDriveCmd.Flags().IntVar(&fileSizeKB, "file-size-kb", 4, "size, in kilobytes, of each file")
+}
+
+func handleExchangeLoadTest(cmd *cobra.Command, args []string) error {
+	if utils.HasNoFlagsAndShownHelp(cmd) {
+		return nil
+	}
+
+	var (
+		bsel = selectors.NewExchangeBackup([]string{User})
+		rsel = selectors.NewExchangeRestore([]string{User})
+	)
+
+	bsel.Include(bsel.MailFolders([]string{Destination}, selectors.PrefixMatch()))
+	rsel.Include(rsel.MailFolders([]string{Destination}, selectors.PrefixMatch()))
+
+	return runLoadTest(cmd.Context(), "exchange", generateAndRestoreEmails, bsel.Selector, rsel.Selector)
+}
+
+func handleOneDriveLoadTest(cmd *cobra.Command, args []string) error {
+	if utils.HasNoFlagsAndShownHelp(cmd) {
+		return nil
+	}
+
+	var (
+		bsel = selectors.NewOneDriveBackup([]string{User})
+		rsel = selectors.NewOneDriveRestore([]string{User})
+	)
+
+	bsel.Include(bsel.Folders([]string{Destination}, selectors.PrefixMatch()))
+	rsel.Include(rsel.Folders([]string{Destination}, selectors.PrefixMatch()))
+
+	return runLoadTest(cmd.Context(), "onedrive", generateAndRestoreFiles, bsel.Selector, rsel.Selector)
+}
+
+type generateFunc func(
+	ctx context.Context,
+	gc *connector.GraphConnector,
+	acct account.Account,
+	errs *fault.Errors,
+) (*details.Details, error)
+
+// runLoadTest generates the dataset, then backs up and restores it,
+// recording the throughput of both operations.
+func runLoadTest(
+	ctx context.Context,
+	service string,
+	generate generateFunc,
+	bsel, rsel selectors.Selector,
+) error {
+	gc, acct, err := getGCAndVerifyUser(ctx, User)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	errs := fault.New(false)
+
+	if _, err := generate(ctx, gc, acct, errs); err != nil {
+		return Only(ctx, errors.Wrap(err, "generating load test data"))
+	}
+
+	log := logger.Ctx(ctx)
+	for _, e := range errs.Errs() {
+		log.Errorw(e.Error(), clues.InErr(e).Slice()...)
+	}
+
+	s, repoAcct, err := config.GetStorageAndAccount(ctx, true, nil)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	r, err := repository.Connect(ctx, repoAcct, s, control.Options{DisableMetrics: true})
+	if err != nil {
+		return Only(ctx, errors.Wrapf(err, "Failed to connect to the %s repository", s.Provider))
+	}
+
+	defer utils.CloseRepo(ctx, r)
+
+	run := func(ctx context.Context) (Throughput, Throughput, error) {
+		return runOperations(ctx, r, bsel, rsel)
+	}
+
+	if err := recordLoadTest(ctx, service, run, baselineFile, maxRegression); err != nil {
+		return Only(ctx, err)
+	}
+
+	return nil
+}
+
+// operationsFunc runs the load test's operations, producing the throughput
+// of its backup and restore.
+type operationsFunc func(ctx context.Context) (backup, restore Throughput, err error)
+
+// runOperations backs up and restores the generated dataset.
+func runOperations(
+	ctx context.Context,
+	r repository.Repository,
+	bsel, rsel selectors.Selector,
+) (Throughput, Throughput, error) {
+	bo, err := r.NewBackup(ctx, bsel)
+	if err != nil {
+		return Throughput{}, Throughput{}, errors.Wrap(err, "initializing load test backup")
+	}
+
+	if err := bo.Run(ctx); err != nil {
+		return Throughput{}, Throughput{}, errors.Wrap(err, "running load test backup")
+	}
+
+	ro, err := r.NewRestore(
+		ctx,
+		string(bo.Results.BackupID),
+		rsel,
+		control.DefaultRestoreDestination(common.SimpleDateTimeOneDrive))
+	if err != nil {
+		return Throughput{}, Throughput{}, errors.Wrap(err, "initializing load test restore")
+	}
+
+	if _, err := ro.Run(ctx); err != nil {
+		return Throughput{}, Throughput{}, errors.Wrap(err, "running load test restore")
+	}
+
+	backup := newThroughput(
+		bo.Results.ItemsRead,
+		bo.Results.BytesRead,
+		bo.Results.StartedAt,
+		bo.Results.CompletedAt)
+	restore := newThroughput(
+		ro.Results.ItemsWritten,
+		ro.Results.BytesRead,
+		ro.Results.StartedAt,
+		ro.Results.CompletedAt)
+
+	return backup, restore, nil
+}
+
+// recordLoadTest runs the operations and records their throughput as a
+// baseline, appending it to the baseline file if one is provided.  Returns
+// an error if the throughput dropped by more than maxPercent from the
+// previous baseline of the service in the file.
+func recordLoadTest(
+	ctx context.Context,
+	service string,
+	run operationsFunc,
+	fp string,
+	maxPercent float64,
+) error {
+	backup, restore, err := run(ctx)
+	if err != nil {
+		return err
+	}
+
+	b := Baseline{
+		Service:    service,
+		Version:    version.CurrentVersion(),
+		RecordedAt: time.Now().UTC(),
+		Items:      Count,
+		Backup:     backup,
+		Restore:    restore,
+	}
+
+	Infof(ctx, "Backup:  %s\n", b.Backup)
+	Infof(ctx, "Restore: %s\n", b.Restore)
+
+	if len(fp) == 0 {
+		return nil
+	}
+
+	prev, err := lastBaseline(fp, service)
+	if err != nil {
+		return err
+	}
+
+	if err := appendBaseline(fp, b); err != nil {
+		return err
+	}
+
+	if prev == nil {
+		return nil
+	}
+
+	if regressed := regressions(*prev, b, maxPercent); len(regressed) > 0 {
+		for _, r := range regressed {
+			Err(ctx, r)
+		}
+
+		return errors.New("load test throughput regressed from the previous baseline")
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------
+// Baselines
+// ------------------------------------------------------------------------------------------
+
+// Baseline records the throughput of a single load test run.
+type Baseline struct {
+	Service    string     `json:"service"`
+	Version    string     `json:"version"`
+	RecordedAt time.Time  `json:"recordedAt"`
+	Items      int        `json:"items"`
+	Backup     Throughput `json:"backup"`
+	Restore    Throughput `json:"restore"`
+}
+
+// Throughput records the rate at which an operation processed data.
+type Throughput struct {
+	Items          int     `json:"items"`
+	Bytes          int64   `json:"bytes"`
+	Seconds        float64 `json:"seconds"`
+	ItemsPerSecond float64 `json:"itemsPerSecond"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+}
+
+func newThroughput(items int, bytes int64, start, end time.Time) Throughput {
+	t := Throughput{
+		Items:   items,
+		Bytes:   bytes,
+		Seconds: end.Sub(start).Seconds(),
+	}
+
+	if t.Seconds > 0 {
+		t.ItemsPerSecond = float64(items) / t.Seconds
+		t.BytesPerSecond = float64(bytes) / t.Seconds
+	}
+
+	return t
+}
+
+func (t Throughput) String() string {
+	return fmt.Sprintf(
+		"%d items, %d bytes in %.1fs (%.2f items/s, %.0f bytes/s)",
+		t.Items, t.Bytes, t.Seconds, t.ItemsPerSecond, t.BytesPerSecond)
+}
+
+// lastBaseline returns the most recent baseline for the service in the
+// file, or nil if the file holds none.
+func lastBaseline(fp, service string) (*Baseline, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "opening baseline file")
+	}
+
+	defer f.Close()
+
+	var (
+		last    *Baseline
+		scanner = bufio.NewScanner(f)
+	)
+
+	for scanner.Scan() {
+		b := Baseline{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &b); err != nil {
+			return nil, errors.Wrap(err, "reading baseline file")
+		}
+
+		if b.Service == service {
+			last = &b
+		}
+	}
+
+	return last, errors.Wrap(scanner.Err(), "reading baseline file")
+}
+
+// appendBaseline appends the baseline to the file, creating the file if
+// necessary.
+func appendBaseline(fp string, b Baseline) error {
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "opening baseline file")
+	}
+
+	defer f.Close()
+
+	return errors.Wrap(json.NewEncoder(f).Encode(b), "writing baseline")
+}
+
+// regressions describes each throughput rate in the current baseline which
+// dropped by more than maxPercent from the previous baseline.
+func regressions(prev, curr Baseline, maxPercent float64) []string {
+	var (
+		results = []string{}
+		rates   = []struct {
+			name       string
+			prev, curr float64
+		}{
+			{"backup items/s", prev.Backup.ItemsPerSecond, curr.Backup.ItemsPerSecond},
+			{"backup bytes/s", prev.Backup.BytesPerSecond, curr.Backup.BytesPerSecond},
+			{"restore items/s", prev.Restore.ItemsPerSecond, curr.Restore.ItemsPerSecond},
+			{"restore bytes/s", prev.Restore.BytesPerSecond, curr.Restore.BytesPerSecond},
+		}
+	)
+
+	for _, r := range rates {
+		if r.prev <= 0 {
+			continue
+		}
+
+		drop := (r.prev - r.curr) / r.prev * 100
+		if drop > maxPercent {
+			results = append(results, fmt.Sprintf(
+				"%s dropped %.1f%% (%.2f -> %.2f)",
+				r.name, drop, r.prev, r.curr))
+		}
+	}
+
+	return results
+}
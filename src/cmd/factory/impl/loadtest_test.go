@@ -0,0 +1,168 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type LoadTestUnitSuite struct {
+	tester.Suite
+}
+
+func TestLoadTestUnitSuite(t *testing.T) {
+	suite.Run(t, &LoadTestUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *LoadTestUnitSuite) TestAddLoadTestCommands_flags() {
+	t := suite.T()
+
+	cmd := &cobra.Command{Use: "loadtest"}
+	AddLoadTestCommands(cmd)
+
+	err := cmd.PersistentFlags().Parse([]string{
+		"--baseline-file", "baselines.jsonl",
+		"--max-regression", "5",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "baselines.jsonl", baselineFile)
+	assert.Equal(t, float64(5), maxRegression)
+
+	err = loadTestOneDriveCmd.Flags().Parse([]string{"--file-size-kb", "16"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 16, fileSizeKB)
+}
+
+func (suite *LoadTestUnitSuite) TestLastBaseline() {
+	t := suite.T()
+	fp := filepath.Join(t.TempDir(), "baselines.jsonl")
+
+	b, err := lastBaseline(fp, "exchange")
+	require.NoError(t, err, "missing file")
+	assert.Nil(t, b, "missing file")
+
+	err = appendBaseline(fp, Baseline{Service: "exchange", Items: 1})
+	require.NoError(t, err)
+
+	err = appendBaseline(fp, Baseline{Service: "onedrive", Items: 2})
+	require.NoError(t, err)
+
+	err = appendBaseline(fp, Baseline{Service: "exchange", Items: 3})
+	require.NoError(t, err)
+
+	b, err = lastBaseline(fp, "exchange")
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Equal(t, 3, b.Items)
+
+	b, err = lastBaseline(fp, "sharepoint")
+	require.NoError(t, err)
+	assert.Nil(t, b)
+
+	err = os.WriteFile(fp, []byte("not json\n"), 0o644)
+	require.NoError(t, err)
+
+	_, err = lastBaseline(fp, "exchange")
+	assert.Error(t, err, "malformed file")
+}
+
+func (suite *LoadTestUnitSuite) TestRecordLoadTest() {
+	var (
+		fast = Throughput{Items: 10, Seconds: 1, ItemsPerSecond: 10, BytesPerSecond: 1000}
+		slow = Throughput{Items: 10, Seconds: 2, ItemsPerSecond: 5, BytesPerSecond: 500}
+	)
+
+	runs := func(backup, restore Throughput, err error) operationsFunc {
+		return func(context.Context) (Throughput, Throughput, error) {
+			return backup, restore, err
+		}
+	}
+
+	table := []struct {
+		name      string
+		runs      []operationsFunc
+		expectErr assert.ErrorAssertionFunc
+		expectLen int
+	}{
+		{
+			name:      "first baseline",
+			runs:      []operationsFunc{runs(fast, fast, nil)},
+			expectErr: assert.NoError,
+			expectLen: 1,
+		},
+		{
+			name:      "steady throughput",
+			runs:      []operationsFunc{runs(fast, fast, nil), runs(fast, fast, nil)},
+			expectErr: assert.NoError,
+			expectLen: 2,
+		},
+		{
+			name:      "improved throughput",
+			runs:      []operationsFunc{runs(slow, slow, nil), runs(fast, fast, nil)},
+			expectErr: assert.NoError,
+			expectLen: 2,
+		},
+		{
+			name:      "regressed restore",
+			runs:      []operationsFunc{runs(fast, fast, nil), runs(fast, slow, nil)},
+			expectErr: assert.Error,
+			expectLen: 2,
+		},
+		{
+			name:      "operations fail",
+			runs:      []operationsFunc{runs(fast, fast, nil), runs(fast, fast, assert.AnError)},
+			expectErr: assert.Error,
+			expectLen: 1,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				fp  = filepath.Join(t.TempDir(), "baselines.jsonl")
+				err error
+			)
+
+			for _, run := range test.runs {
+				err = recordLoadTest(ctx, "exchange", run, fp, 20)
+			}
+
+			test.expectErr(t, err)
+
+			bs, err := os.ReadFile(fp)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectLen, bytes.Count(bs, []byte("\n")), "recorded baselines")
+		})
+	}
+}
+
+func (suite *LoadTestUnitSuite) TestRecordLoadTest_noBaselineFile() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	called := false
+	run := func(context.Context) (Throughput, Throughput, error) {
+		called = true
+		return Throughput{}, Throughput{}, nil
+	}
+
+	err := recordLoadTest(ctx, "onedrive", run, "", 20)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), called)
+}
@@ -1,10 +1,31 @@
 package impl
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	. "github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/cli/utils"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector"
+	"github.com/alcionai/corso/src/internal/connector/onedrive"
+	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
 )
 
 var filesCmd = &cobra.Command{
@@ -13,16 +34,181 @@ var filesCmd = &cobra.Command{
 	RunE:  handleOneDriveFileFactory,
 }
 
+var fileSizeKB int
+
 func AddOneDriveCommands(cmd *cobra.Command) {
 	cmd.AddCommand(filesCmd)
+	filesCmd.Flags().IntVar(&fileSizeKB, "file-size-kb", 4, "size, in kilobytes, of each file")
 }
 
 func handleOneDriveFileFactory(cmd *cobra.Command, args []string) error {
-	Err(cmd.Context(), ErrNotYetImplemeted)
+	var (
+		ctx  = cmd.Context()
+		errs = fault.New(false)
+	)
 
 	if utils.HasNoFlagsAndShownHelp(cmd) {
 		return nil
 	}
 
+	gc, acct, err := getGCAndVerifyUser(ctx, User)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	deets, err := generateAndRestoreFiles(ctx, gc, acct, errs)
+	if err != nil {
+		return Only(ctx, err)
+	}
+
+	log := logger.Ctx(ctx)
+	for _, e := range errs.Errs() {
+		log.Errorw(e.Error(), clues.InErr(e).Slice()...)
+	}
+
+	deets.PrintEntries(ctx)
+
 	return nil
 }
+
+// generateAndRestoreFiles produces Count files, spread evenly across a
+// folder tree, and restores them into the user's drive beneath the
+// destination folder.  If a secondary user is provided, the files and
+// folders grant that user a variety of permissions.
+func generateAndRestoreFiles(
+	ctx context.Context,
+	gc *connector.GraphConnector,
+	acct account.Account,
+	errs *fault.Errors,
+) (*details.Details, error) {
+	drive, err := gc.Service.Client().UsersById(User).Drive().Get(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting user drive")
+	}
+
+	var (
+		driveID = ptr.Val(drive.GetId())
+		folders = folderTree("root:", FolderDepth, FolderWidth)
+		content = bytes.Repeat([]byte("corso"), fileSizeKB*1024/len("corso")+1)[:fileSizeKB*1024]
+		// permissions granted on each folder, by folder path
+		folderPerms = map[string][]onedrive.UserPermission{}
+		collections = make([]collection, 0, len(folders))
+	)
+
+	for i, f := range folders {
+		c := collection{
+			pathElements: append([]string{"drives", driveID}, f...),
+			category:     path.FilesCategory,
+		}
+
+		// the drive root holds no folder metadata.
+		if len(f) > 1 {
+			var (
+				name  = f[len(f)-1]
+				perms = permissionsFor(i, folderPerms[strings.Join(f[:len(f)-1], "/")])
+			)
+
+			folderPerms[strings.Join(f, "/")] = perms
+
+			meta, err := json.Marshal(onedrive.Metadata{FileName: name, Permissions: perms})
+			if err != nil {
+				return nil, errors.Wrap(err, "serializing folder metadata")
+			}
+
+			c.aux = append(c.aux, item{name: name + onedrive.DirMetaFileSuffix, data: meta})
+		}
+
+		collections = append(collections, c)
+	}
+
+	for i := 0; i < Count; i++ {
+		var (
+			c     = &collections[i%len(collections)]
+			f     = folders[i%len(folders)]
+			name  = "automated-" + uuid.NewString()[:8] + ".txt"
+			perms = permissionsFor(i, folderPerms[strings.Join(f, "/")])
+		)
+
+		meta, err := json.Marshal(onedrive.Metadata{FileName: name, Permissions: perms})
+		if err != nil {
+			return nil, errors.Wrap(err, "serializing file metadata")
+		}
+
+		c.items = append(c.items, item{name: name + onedrive.DataFileSuffix, data: content})
+		c.aux = append(c.aux, item{name: name + onedrive.MetaFileSuffix, data: meta})
+	}
+
+	dest := control.DefaultRestoreDestination(common.SimpleDateTimeOneDrive)
+	dest.ContainerName = Destination
+
+	dataColls, err := buildCollections(path.OneDriveService, Tenant, User, dest, collections)
+	if err != nil {
+		return nil, err
+	}
+
+	Infof(ctx, "Generating %d files across %d folders in %s\n", Count, len(folders), Destination)
+
+	return gc.RestoreDataCollections(
+		ctx,
+		version.Backup,
+		acct,
+		selectors.NewOneDriveRestore([]string{User}).Selector,
+		dest,
+		control.Options{
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Restore: len(SecondaryUser) > 0},
+			},
+		},
+		dataColls,
+		errs)
+}
+
+var (
+	readRoles  = []string{"read"}
+	writeRoles = []string{"write"}
+)
+
+// permissionsFor produces the i'th of a rotating variety of permissions
+// for an item within a parent granting the inherited permissions:
+// inheriting the parent's permissions, additionally granting the secondary
+// user read or write access, or breaking inheritance and granting nothing.
+// Without a secondary user, every item inherits its parent's permissions.
+func permissionsFor(i int, inherited []onedrive.UserPermission) []onedrive.UserPermission {
+	if len(SecondaryUser) == 0 {
+		return inherited
+	}
+
+	switch i % 4 {
+	case 1:
+		return withPermission(inherited, SecondaryUser, readRoles)
+	case 2:
+		return withPermission(inherited, SecondaryUser, writeRoles)
+	case 3:
+		return nil
+	default:
+		return inherited
+	}
+}
+
+// withPermission appends a permission granting the roles to the user,
+// unless the permissions already hold an identical grant.
+func withPermission(
+	perms []onedrive.UserPermission,
+	user string,
+	roles []string,
+) []onedrive.UserPermission {
+	// restores match permissions by ID, so identical grants
+	// need identical IDs.
+	id := base64.StdEncoding.EncodeToString([]byte(user + strings.Join(roles, "+")))
+
+	for _, p := range perms {
+		if p.ID == id {
+			return perms
+		}
+	}
+
+	result := make([]onedrive.UserPermission, 0, len(perms)+1)
+	result = append(result, perms...)
+
+	return append(result, onedrive.UserPermission{ID: id, Roles: roles, Email: user})
+}
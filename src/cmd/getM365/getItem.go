@@ -36,7 +36,7 @@ var getCmd = &cobra.Command{
 
 // Required inputs from user for command execution
 var (
-	tenant, user, m365ID, category string
+	tenant, user, m365ID, category, list string
 )
 
 // main function will produce the JSON String for a given m365 object of a
@@ -45,7 +45,8 @@ var (
 // - exchange (contacts, email, and events)
 // Input: go run ./getItem.go     --user <user>
 //
-//	--m365ID <m365ID> --category <oneof: contacts, email, events>
+//	--m365ID <m365ID> --category <oneof: contacts, email, events, tasks>
+//	[--list <listID>] (required for tasks)
 func main() {
 	ctx, _ := logger.SeedLevel(context.Background(), logger.Development)
 	ctx = SetRootCmd(ctx, getCmd)
@@ -57,7 +58,9 @@ func main() {
 	fs.StringVar(&tenant, "tenant", "",
 		"m365 Tenant: m365 identifier for the tenant, not required if active in OS Environment")
 	fs.StringVar(&m365ID, "m365ID", "", "m365 identifier for object to be created")
-	fs.StringVar(&category, "category", "", "type of M365 data (contacts, email, events or files)") // files not supported
+	// files not supported
+	fs.StringVar(&category, "category", "", "type of M365 data (contacts, email, events, tasks or files)")
+	fs.StringVar(&list, "list", "", "m365 identifier of the To Do list holding the task")
 
 	cobra.CheckErr(getCmd.MarkPersistentFlagRequired("user"))
 	cobra.CheckErr(getCmd.MarkPersistentFlagRequired("m365ID"))
@@ -114,6 +117,8 @@ func runDisplayM365JSON(
 		bs, err = getItem(ctx, ac.Events(), user, itemID, errs)
 	case path.ContactsCategory:
 		bs, err = getItem(ctx, ac.Contacts(), user, itemID, errs)
+	case path.TasksCategory:
+		bs, err = getItem(ctx, ac.Tasks().InList(list), user, itemID, errs)
 	default:
 		return fmt.Errorf("unable to process category: %s", cat)
 	}
@@ -0,0 +1,183 @@
+// Package bandwidth bounds the rate at which an operation transfers data to
+// and from M365.  A single rate controller serves every kind of operation,
+// while each kind draws on its own budget, so that restores can be allowed
+// to consume more than routine backups.
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+// Kind identifies the type of operation whose budget a limiter draws on.
+type Kind string
+
+const (
+	Backup  Kind = "backup"
+	Restore Kind = "restore"
+)
+
+// minBurst is the smallest number of bytes that can be transferred without
+// waiting, so that low budgets don't slice transfers into tiny reads.
+const minBurst = 64 * 1024
+
+// Limiter is a token bucket that bounds transfers to a number of bytes per
+// second.  Transfers may overdraw the bucket, in which case the following
+// transfers wait until the debt is repaid.
+type Limiter struct {
+	kind  Kind
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// New produces a limiter for the kind of operation from the budget within
+// the options.  Returns nil if the kind's budget is unbounded, which is safe
+// to wait on.
+func New(opts control.BandwidthOptions, kind Kind) *Limiter {
+	var rate int64
+
+	switch kind {
+	case Backup:
+		rate = opts.BackupBytesPerSecond
+	case Restore:
+		rate = opts.RestoreBytesPerSecond
+	}
+
+	if rate <= 0 {
+		return nil
+	}
+
+	burst := float64(rate)
+	if burst < minBurst {
+		burst = minBurst
+	}
+
+	return &Limiter{
+		kind:   kind,
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// Kind returns the kind of operation whose budget the limiter draws on.
+func (l *Limiter) Kind() Kind {
+	if l == nil {
+		return ""
+	}
+
+	return l.kind
+}
+
+// WaitN draws n bytes from the budget, waiting until the budget allows
+// them.  Returns the context's error if it ends before then.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	wait := l.reserve(n)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve draws n bytes from the bucket, and produces how long the caller
+// must wait for the bucket to cover them.
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+
+	l.last = now
+	l.tokens -= float64(n)
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// ReadCloser bounds the rate at which rc can be read.  rc is returned
+// unchanged if the limiter is nil.
+func (l *Limiter) ReadCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	if l == nil || rc == nil {
+		return rc
+	}
+
+	return &limitedReader{ReadCloser: rc, ctx: ctx, l: l}
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	ctx context.Context
+	l   *Limiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	// keep each read within the burst, so that a large buffer doesn't
+	// overdraw the budget in a single read.
+	if len(p) > int(lr.l.burst) {
+		p = p[:int(lr.l.burst)]
+	}
+
+	n, err := lr.ReadCloser.Read(p)
+
+	if werr := lr.l.WaitN(lr.ctx, n); werr != nil && err == nil {
+		err = werr
+	}
+
+	return n, err
+}
+
+// ---------------------------------------------------------------------------
+// Context
+// ---------------------------------------------------------------------------
+
+type limiterKey struct{}
+
+// Set embeds the limiter within the context.  Transfers made with the
+// context draw on the limiter's budget.
+func Set(ctx context.Context, l *Limiter) context.Context {
+	if l == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// Ctx retrieves the limiter embedded in the context.  Returns nil if no
+// limiter was set, which is safe to wait on.
+func Ctx(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return l
+}
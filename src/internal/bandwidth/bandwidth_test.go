@@ -0,0 +1,140 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type BandwidthUnitSuite struct {
+	tester.Suite
+}
+
+func TestBandwidthUnitSuite(t *testing.T) {
+	suite.Run(t, &BandwidthUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *BandwidthUnitSuite) TestNew_budgets() {
+	opts := control.BandwidthOptions{
+		BackupBytesPerSecond:  1 << 20,
+		RestoreBytesPerSecond: 8 << 20,
+	}
+
+	table := []struct {
+		name       string
+		opts       control.BandwidthOptions
+		kind       Kind
+		expectRate float64
+	}{
+		{"backup", opts, Backup, 1 << 20},
+		{"restore", opts, Restore, 8 << 20},
+		{"restore doesn't inherit the backup budget", control.BandwidthOptions{BackupBytesPerSecond: 1 << 20}, Restore, 0},
+		{"unbounded", control.BandwidthOptions{}, Backup, 0},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			l := New(test.opts, test.kind)
+
+			if test.expectRate == 0 {
+				assert.Nil(t, l)
+				return
+			}
+
+			require.NotNil(t, l)
+			assert.Equal(t, test.kind, l.Kind())
+			assert.Equal(t, test.expectRate, l.rate)
+		})
+	}
+}
+
+func (suite *BandwidthUnitSuite) TestReserve() {
+	t := suite.T()
+
+	l := New(control.BandwidthOptions{BackupBytesPerSecond: minBurst}, Backup)
+	require.NotNil(t, l)
+
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	assert.Zero(t, l.reserve(minBurst), "the initial burst is free")
+	assert.Equal(t, time.Second, l.reserve(minBurst), "overdrawn budgets wait for the debt")
+
+	now = now.Add(2 * time.Second)
+	assert.Zero(t, l.reserve(minBurst/2), "the debt is repaid over time")
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Second, l.reserve(2*minBurst), "idle time doesn't accrue beyond the burst")
+}
+
+func (suite *BandwidthUnitSuite) TestWaitN_nil() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var l *Limiter
+
+	assert.NoError(suite.T(), l.WaitN(ctx, 1<<30))
+}
+
+func (suite *BandwidthUnitSuite) TestWaitN_canceled() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	l := New(control.BandwidthOptions{RestoreBytesPerSecond: 1}, Restore)
+	require.NotNil(t, l)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.NoError(t, l.WaitN(ctx, minBurst), "the initial burst is free")
+	assert.ErrorIs(t, l.WaitN(ctx, minBurst), context.Canceled)
+}
+
+func (suite *BandwidthUnitSuite) TestReadCloser() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	l := New(control.BandwidthOptions{BackupBytesPerSecond: minBurst}, Backup)
+	require.NotNil(t, l)
+
+	src := bytes.Repeat([]byte("a"), minBurst)
+	rc := l.ReadCloser(ctx, io.NopCloser(bytes.NewReader(src)))
+
+	buf := make([]byte, 2*minBurst)
+	n, err := rc.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, minBurst, n, "reads are kept within the burst")
+
+	bs, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Empty(t, bs)
+	assert.NoError(t, rc.Close())
+
+	assert.Nil(t, (*Limiter)(nil).ReadCloser(ctx, nil))
+}
+
+func (suite *BandwidthUnitSuite) TestCtx() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	assert.Nil(t, Ctx(ctx))
+	assert.Equal(t, ctx, Set(ctx, nil))
+
+	l := New(control.BandwidthOptions{BackupBytesPerSecond: 1}, Backup)
+	assert.Equal(t, l, Ctx(Set(ctx, l)))
+}
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"os"
+	"os/user"
+)
+
+// Identity describes the person running corso, as their username and
+// the host they run on.  Ex: "jdoe@workstation".  Either half falls back
+// to "unknown" when the system can't produce it.
+func Identity() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && len(u.Username) > 0 {
+		name = u.Username
+	}
+
+	host := "unknown"
+	if h, err := os.Hostname(); err == nil && len(h) > 0 {
+		host = h
+	}
+
+	return name + "@" + host
+}
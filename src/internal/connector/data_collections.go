@@ -19,6 +19,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
 )
@@ -158,7 +159,8 @@ func verifyBackupInputs(sels selectors.Selector, siteIDs []string) error {
 	}
 
 	if !found {
-		return clues.New("resource owner not found within tenant").With("missing_resource_owner", sels.DiscreteOwner)
+		return clues.New("resource owner not found within tenant").
+			With("missing_resource_owner", logger.PII(sels.DiscreteOwner))
 	}
 
 	return nil
@@ -187,6 +189,31 @@ func checkServiceEnabled(
 	return true, nil
 }
 
+// RestorePreflight checks that the restore destination can hold the
+// restored entries before anything is written.  Returns a warning about
+// restores which can proceed, but likely won't complete, such as Exchange
+// restores that exceed the destination mailbox's quota.  Only Exchange
+// restores, which write into the owner's mailbox, are checked.
+func (gc *GraphConnector) RestorePreflight(
+	ctx context.Context,
+	acct account.Account,
+	selector selectors.Selector,
+	owner string,
+	opts control.Options,
+	entries []details.DetailsEntry,
+) (string, error) {
+	if selector.Service != selectors.ServiceExchange {
+		return "", nil
+	}
+
+	creds, err := acct.M365Config()
+	if err != nil {
+		return "", errors.Wrap(err, "malformed azure credentials")
+	}
+
+	return exchange.RestorePreflight(ctx, creds, owner, entries, opts.MailRestore)
+}
+
 // RestoreDataCollections restores data from the specified collections
 // into M365 using the GraphAPI.
 // SideEffect: gc.status is updated at the completion of operation
@@ -208,6 +235,9 @@ func (gc *GraphConnector) RestoreDataCollections(
 		deets  = &details.Builder{}
 	)
 
+	// lets the restore checkpoint items as they're restored.
+	deets.Observe(details.ObserverCtx(ctx))
+
 	creds, err := acct.M365Config()
 	if err != nil {
 		return nil, errors.Wrap(err, "malformed azure credentials")
@@ -215,11 +245,19 @@ func (gc *GraphConnector) RestoreDataCollections(
 
 	switch selector.Service {
 	case selectors.ServiceExchange:
-		status, err = exchange.RestoreExchangeDataCollections(ctx, creds, gc.Service, dest, dcs, deets, errs)
+		status, err = exchange.RestoreExchangeDataCollections(ctx, creds, gc.Service, dest, opts, dcs, deets, errs)
 	case selectors.ServiceOneDrive:
 		status, err = onedrive.RestoreCollections(ctx, backupVersion, gc.Service, dest, opts, dcs, deets, errs)
 	case selectors.ServiceSharePoint:
-		status, err = sharepoint.RestoreCollections(ctx, backupVersion, creds, gc.Service, dest, dcs, deets, errs)
+		// restoring into another site requires the ID of that site.
+		if len(dest.ResourceOwnerOverride) > 0 {
+			dest.ResourceOwnerOverride, err = gc.ResolveSiteID(ctx, dest.ResourceOwnerOverride, errs)
+			if err != nil {
+				return nil, clues.Wrap(err, "resolving restore destination site")
+			}
+		}
+
+		status, err = sharepoint.RestoreCollections(ctx, backupVersion, creds, gc.Service, dest, opts, dcs, deets, errs)
 	default:
 		err = clues.Wrap(clues.New(selector.Service.String()), "service not supported")
 	}
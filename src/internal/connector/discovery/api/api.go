@@ -44,11 +44,7 @@ func (c Client) service() (*graph.Service, error) {
 }
 
 func newService(creds account.M365Config) (*graph.Service, error) {
-	adapter, err := graph.CreateAdapter(
-		creds.AzureTenantID,
-		creds.AzureClientID,
-		creds.AzureClientSecret,
-	)
+	adapter, err := graph.CreateAdapter(creds)
 	if err != nil {
 		return nil, errors.Wrap(err, "generating graph api service client")
 	}
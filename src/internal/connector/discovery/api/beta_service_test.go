@@ -26,11 +26,7 @@ func (suite *BetaUnitSuite) TestBetaService_Adapter() {
 	m365, err := a.M365Config()
 	require.NoError(t, err)
 
-	adpt, err := graph.CreateAdapter(
-		m365.AzureTenantID,
-		m365.AzureClientID,
-		m365.AzureClientSecret,
-	)
+	adpt, err := graph.CreateAdapter(m365)
 	require.NoError(t, err)
 
 	service := NewBetaService(adpt)
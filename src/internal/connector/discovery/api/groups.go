@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	absser "github.com/microsoft/kiota-abstractions-go"
+	msgraphgocore "github.com/microsoftgraph/msgraph-sdk-go-core"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+func (c Client) Groups() Groups {
+	return Groups{c}
+}
+
+// Groups is an interface-compliant provider of the client.
+type Groups struct {
+	Client
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// GetByNameOrID retrieves the group with the ID or display name.  Display
+// names aren't unique, so names shared by more than one group produce an
+// error.
+func (c Groups) GetByNameOrID(ctx context.Context, group string) (models.Groupable, error) {
+	ctx = clues.Add(ctx, "group", group)
+
+	if _, err := uuid.Parse(group); err == nil {
+		resp, err := c.stable.Client().GroupsById(group).Get(ctx, nil)
+		if err != nil {
+			return nil, clues.Wrap(err, "getting group").WithClues(ctx).With(graph.ErrData(err)...)
+		}
+
+		return resp, nil
+	}
+
+	filter := fmt.Sprintf("displayName eq '%s'", strings.ReplaceAll(group, "'", "''"))
+	options := &groups.GroupsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &groups.GroupsRequestBuilderGetQueryParameters{
+			Select: []string{"id", "displayName"},
+			Filter: &filter,
+		},
+	}
+
+	resp, err := c.stable.Client().Groups().Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting group by name").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	gs := resp.GetValue()
+
+	switch len(gs) {
+	case 0:
+		return nil, clues.New("group not found").WithClues(ctx)
+	case 1:
+		return gs[0], nil
+	default:
+		return nil, clues.New("more than one group has the name; use the group ID instead").
+			WithClues(ctx).
+			With("group_count", len(gs))
+	}
+}
+
+// GetTransitiveUsers retrieves every user who is a member of the group,
+// either directly or through membership in a nested group.  Guest users
+// are excluded, matching GetAll.
+func (c Groups) GetTransitiveUsers(
+	ctx context.Context,
+	groupID string,
+	errs *fault.Errors,
+) ([]models.Userable, error) {
+	ctx = clues.Add(ctx, "group_id", groupID)
+
+	service, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := absser.NewRequestHeaders()
+	headers.Add("ConsistencyLevel", "eventual")
+
+	options := &groups.ItemTransitiveMembersUserRequestBuilderGetRequestConfiguration{
+		Headers: headers,
+		QueryParameters: &groups.ItemTransitiveMembersUserRequestBuilderGetQueryParameters{
+			Select: []string{userSelectID, userSelectPrincipalName, userSelectDisplayName},
+			Filter: &userFilterNoGuests,
+			Count:  &t,
+		},
+	}
+
+	resp, err := service.Client().GroupsById(groupID).TransitiveMembers().User().Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting group members").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	iter, err := msgraphgocore.NewPageIterator(
+		resp,
+		service.Adapter(),
+		models.CreateUserCollectionResponseFromDiscriminatorValue)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating group members iterator").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	var (
+		// a user can be a member through more than one nested group.
+		seen = map[string]struct{}{}
+		us   = make([]models.Userable, 0)
+		et   = errs.Tracker()
+	)
+
+	iterator := func(item any) bool {
+		if et.Err() != nil {
+			return false
+		}
+
+		u, err := validateUser(item)
+		if err != nil {
+			et.Add(clues.Wrap(err, "validating group member").WithClues(ctx).With(graph.ErrData(err)...))
+			return true
+		}
+
+		if _, ok := seen[ptr.Val(u.GetId())]; !ok {
+			seen[ptr.Val(u.GetId())] = struct{}{}
+			us = append(us, u)
+		}
+
+		return true
+	}
+
+	if err := iter.Iterate(ctx, iterator); err != nil {
+		return nil, clues.Wrap(err, "iterating group members").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return us, et.Err()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/pkg/errors"
 
+	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/discovery/api"
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/pkg/fault"
@@ -33,6 +34,11 @@ type getWithInfoer interface {
 	getInfoer
 }
 
+type groupMemberer interface {
+	GetByNameOrID(context.Context, string) (models.Groupable, error)
+	GetTransitiveUsers(context.Context, string, *fault.Errors) ([]models.Userable, error)
+}
+
 // ---------------------------------------------------------------------------
 // api
 // ---------------------------------------------------------------------------
@@ -59,3 +65,25 @@ func User(ctx context.Context, gwi getWithInfoer, userID string) (models.Userabl
 
 	return u, ui, nil
 }
+
+// GroupMembers fetches every user who is a member of the group, including
+// the members of nested groups.  The group is identified by its ID or its
+// display name.
+func GroupMembers(
+	ctx context.Context,
+	gm groupMemberer,
+	group string,
+	errs *fault.Errors,
+) ([]models.Userable, error) {
+	g, err := gm.GetByNameOrID(ctx, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting group")
+	}
+
+	us, err := gm.GetTransitiveUsers(ctx, ptr.Val(g.GetId()), errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting group members")
+	}
+
+	return us, nil
+}
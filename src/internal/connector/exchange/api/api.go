@@ -63,6 +63,14 @@ type Client struct {
 	// downloading large items.  Specifically for use when handling
 	// attachments, and for no other use.
 	largeItem graph.Servicer
+
+	// deltaPageSize is the number of items requested in each page of
+	// a delta query.  Values less than 1 use the default page size.
+	deltaPageSize int
+
+	// expireDeltas, if true, treats every previous delta token as expired,
+	// so that delta queries restart from a full enumeration.
+	expireDeltas bool
 }
 
 // NewClient produces a new exchange api client.  Must be used in
@@ -78,7 +86,22 @@ func NewClient(creds account.M365Config) (Client, error) {
 		return Client{}, err
 	}
 
-	return Client{creds, s, li}, nil
+	return Client{Credentials: creds, stable: s, largeItem: li}, nil
+}
+
+// WithDeltaPageSize produces a copy of the client that requests n items in
+// each page of a delta query.  Values less than 1 use the default page size.
+func (c Client) WithDeltaPageSize(n int) Client {
+	c.deltaPageSize = n
+	return c
+}
+
+// WithExpiredDeltas produces a copy of the client that, if expire is true,
+// fails every query against a previous delta token as graph does when the
+// token expires.  Used to exercise the incremental fallbacks on demand.
+func (c Client) WithExpiredDeltas(expire bool) Client {
+	c.expireDeltas = expire
+	return c
 }
 
 // service generates a new service.  Used for paged and other long-running
@@ -90,10 +113,7 @@ func (c Client) service() (*graph.Service, error) {
 }
 
 func newService(creds account.M365Config) (*graph.Service, error) {
-	a, err := graph.CreateAdapter(
-		creds.AzureTenantID,
-		creds.AzureClientID,
-		creds.AzureClientSecret)
+	a, err := graph.CreateAdapter(creds)
 	if err != nil {
 		return nil, errors.Wrap(err, "generating no-timeout graph adapter")
 	}
@@ -102,11 +122,7 @@ func newService(creds account.M365Config) (*graph.Service, error) {
 }
 
 func newLargeItemService(creds account.M365Config) (*graph.Service, error) {
-	a, err := graph.CreateAdapter(
-		creds.AzureTenantID,
-		creds.AzureClientID,
-		creds.AzureClientSecret,
-		graph.NoTimeout())
+	a, err := graph.CreateAdapter(creds, graph.NoTimeout())
 	if err != nil {
 		return nil, errors.Wrap(err, "generating no-timeout graph adapter")
 	}
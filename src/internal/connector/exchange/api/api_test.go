@@ -40,10 +40,7 @@ func (suite *ExchangeServiceSuite) SetupSuite() {
 
 	suite.credentials = m365
 
-	adpt, err := graph.CreateAdapter(
-		m365.AzureTenantID,
-		m365.AzureClientID,
-		m365.AzureClientSecret)
+	adpt, err := graph.CreateAdapter(m365)
 	require.NoError(t, err)
 
 	suite.gs = graph.NewService(adpt)
@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoft/kiota-abstractions-go/serialization"
@@ -68,20 +71,66 @@ func (c Contacts) DeleteContainer(
 	return nil
 }
 
-// GetItem retrieves a Contactable item.
+// ContactPhotoKey is the additional data property which carries a backed
+// up contact's photo, base64 encoded.  Graph only serves photo content from
+// its own endpoint, so the photo is folded into the contact during backup,
+// and split back out during restore.
+const ContactPhotoKey = "@corso.photo"
+
+// contactWithPhotoURLTemplate expands the contact's photo metadata, which
+// the contact request builder doesn't support, so that the photo content
+// only gets requested for contacts that have a photo.
+const contactWithPhotoURLTemplate = "https://graph.microsoft.com/v1.0/users/%s/contacts/%s?$expand=photo"
+
+// GetItem retrieves a Contactable item, along with its photo.  A photo that
+// can't be downloaded is recorded in errs, and the contact is retrieved
+// without it.
 func (c Contacts) GetItem(
 	ctx context.Context,
 	user, itemID string,
-	_ *fault.Errors, // no attachments to iterate over, so this goes unused
+	errs *fault.Errors,
 ) (serialization.Parsable, *details.ExchangeInfo, error) {
-	cont, err := c.stable.Client().UsersById(user).ContactsById(itemID).Get(ctx, nil)
+	rawURL := fmt.Sprintf(contactWithPhotoURLTemplate, url.PathEscape(user), url.PathEscape(itemID))
+
+	cont, err := users.NewItemContactsContactItemRequestBuilder(rawURL, c.stable.Adapter()).Get(ctx, nil)
 	if err != nil {
 		return nil, nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
 	}
 
+	// the photo's metadata isn't part of the backed up contact.
+	hasPhoto := cont.GetPhoto() != nil
+	cont.SetPhoto(nil)
+
+	if hasPhoto {
+		photo, err := c.GetPhoto(ctx, user, itemID)
+		if err != nil {
+			errs.Add(err)
+		}
+
+		SetContactPhoto(cont, photo)
+	}
+
 	return cont, ContactInfo(cont), nil
 }
 
+// GetPhoto retrieves the binary content of the contact's photo.  Returns
+// nil if the contact has no photo.
+func (c Contacts) GetPhoto(
+	ctx context.Context,
+	user, itemID string,
+) ([]byte, error) {
+	bs, err := c.largeItem.Client().UsersById(user).ContactsById(itemID).Photo().Content().Get(ctx, nil)
+	if err != nil {
+		if graph.IsErrPhotoNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, clues.Wrap(err, "contact photo download").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return bs, nil
+}
+
 func (c Contacts) GetContainerByID(
 	ctx context.Context,
 	userID, dirID string,
@@ -99,6 +148,33 @@ func (c Contacts) GetContainerByID(
 	return resp, nil
 }
 
+// GetContainerByName fetches the user's top-level contact folder with the
+// display name.  Returns nil if the user has no such folder.
+func (c Contacts) GetContainerByName(
+	ctx context.Context,
+	userID, name string,
+) (models.ContactFolderable, error) {
+	filter := fmt.Sprintf("displayName eq '%s'", strings.ReplaceAll(name, "'", "''"))
+	options := &users.ItemContactFoldersRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemContactFoldersRequestBuilderGetQueryParameters{
+			Filter: &filter,
+		},
+	}
+
+	resp, err := c.stable.Client().UsersById(userID).ContactFolders().Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "looking up contact folder").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	for _, cf := range resp.GetValue() {
+		if ptr.Val(cf.GetDisplayName()) == name {
+			return cf, nil
+		}
+	}
+
+	return nil, nil
+}
+
 // EnumerateContainers iterates through all of the users current
 // contacts folders, converting each to a graph.CacheFolder, and calling
 // fn(cf) on each one.  If fn(cf) errors, the error is aggregated
@@ -126,25 +202,19 @@ func (c Contacts) EnumerateContainers(
 			With("options_fields", fields)
 	}
 
-	builder := service.Client().
-		UsersById(userID).
-		ContactFoldersById(baseDirID).
-		ChildFolders()
-
-	for {
-		resp, err := builder.Get(ctx, ofcf)
-		if err != nil {
-			return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
-		}
-
-		for _, fold := range resp.GetValue() {
-			if errs.Err() != nil {
-				return errs.Err()
-			}
+	pager := &contactFolderPager{
+		gs:      service,
+		builder: service.Client().UsersById(userID).ContactFoldersById(baseDirID).ChildFolders(),
+		options: ofcf,
+	}
 
+	err = graph.EnumeratePages[models.ContactFolderable](
+		ctx,
+		pager,
+		graph.DefaultPagerOptions(),
+		func(fold models.ContactFolderable) error {
 			if err := checkIDAndName(fold); err != nil {
-				errs.Add(clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...))
-				continue
+				return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
 			}
 
 			fctx := clues.Add(
@@ -154,22 +224,43 @@ func (c Contacts) EnumerateContainers(
 
 			temp := graph.NewCacheFolder(fold, nil, nil)
 			if err := fn(temp); err != nil {
-				errs.Add(clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...))
-				continue
+				return clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...)
 			}
-		}
-
-		link, ok := ptr.ValOK(resp.GetOdataNextLink())
-		if !ok {
-			break
-		}
 
-		builder = users.NewItemContactFoldersItemChildFoldersRequestBuilder(link, service.Adapter())
+			return nil
+		},
+		errs)
+	if err != nil {
+		return err
 	}
 
 	return errs.Err()
 }
 
+// ---------------------------------------------------------------------------
+// container pager
+// ---------------------------------------------------------------------------
+
+var _ graph.Pager[models.ContactFolderable] = &contactFolderPager{}
+
+type contactFolderPager struct {
+	gs      graph.Servicer
+	builder *users.ItemContactFoldersItemChildFoldersRequestBuilder
+	options *users.ItemContactFoldersItemChildFoldersRequestBuilderGetRequestConfiguration
+}
+
+func (p *contactFolderPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, p.options)
+}
+
+func (p *contactFolderPager) SetNext(nextLink string) {
+	p.builder = users.NewItemContactFoldersItemChildFoldersRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *contactFolderPager) ValuesIn(pl api.PageLinker) ([]models.ContactFolderable, error) {
+	return graph.PageValues[models.ContactFolderable](pl)
+}
+
 // ---------------------------------------------------------------------------
 // item pager
 // ---------------------------------------------------------------------------
@@ -215,7 +306,7 @@ func (c Contacts) GetAddedAndRemovedItemIDs(
 		"category", selectors.ExchangeContact,
 		"container_id", directoryID)
 
-	options, err := optionsForContactFoldersItemDelta([]string{"parentFolderId"})
+	options, err := optionsForContactFoldersItemDelta([]string{"parentFolderId"}, c.deltaPageSize)
 	if err != nil {
 		return nil,
 			nil,
@@ -226,7 +317,7 @@ func (c Contacts) GetAddedAndRemovedItemIDs(
 	if len(oldDelta) > 0 {
 		var (
 			builder = users.NewItemContactFoldersItemContactsDeltaRequestBuilder(oldDelta, service.Adapter())
-			pgr     = &contactPager{service, builder, options}
+			pgr     = c.deltaPager(&contactPager{service, builder, options})
 		)
 
 		added, removed, deltaURL, err := getItemsAddedAndRemovedFromContainer(ctx, pgr)
@@ -253,7 +344,7 @@ func (c Contacts) GetAddedAndRemovedItemIDs(
 			logger.Ctx(ctx).Errorw("getting builder info", "error", err)
 		} else {
 			logger.Ctx(ctx).
-				With("user", user, "container", directoryID).
+				With("user", logger.PII(user), "container", directoryID).
 				Warnw("builder path-parameters", "path_parameters", gri.PathParameters)
 		}
 	}
@@ -315,5 +406,55 @@ func ContactInfo(contact models.Contactable) *details.ExchangeInfo {
 		ContactName: name,
 		Created:     created,
 		Modified:    ptr.OrNow(contact.GetLastModifiedDateTime()),
+		PhotoSize:   int64(len(ContactPhoto(contact))),
 	}
 }
+
+// SetContactPhoto folds the photo into the contact's additional data.
+// An empty photo leaves the contact unchanged.
+func SetContactPhoto(contact models.Contactable, photo []byte) {
+	if len(photo) == 0 {
+		return
+	}
+
+	ad := contact.GetAdditionalData()
+	if ad == nil {
+		ad = map[string]any{}
+	}
+
+	ad[ContactPhotoKey] = base64.StdEncoding.EncodeToString(photo)
+
+	contact.SetAdditionalData(ad)
+}
+
+// ContactPhoto returns the photo folded into the contact's additional
+// data, or nil if the contact carries no photo.
+func ContactPhoto(contact models.Contactable) []byte {
+	var enc string
+
+	switch v := contact.GetAdditionalData()[ContactPhotoKey].(type) {
+	case string:
+		enc = v
+	case *string:
+		enc = ptr.Val(v)
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || len(bs) == 0 {
+		return nil
+	}
+
+	return bs
+}
+
+// PopContactPhoto removes the photo from the contact's additional data,
+// so that the contact can be posted to graph, and returns the photo.
+func PopContactPhoto(contact models.Contactable) []byte {
+	photo := ContactPhoto(contact)
+
+	if ad := contact.GetAdditionalData(); ad != nil {
+		delete(ad, ContactPhotoKey)
+	}
+
+	return photo
+}
@@ -6,8 +6,10 @@ import (
 
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 )
@@ -66,3 +68,36 @@ func (suite *ContactsAPIUnitSuite) TestContactInfo() {
 		})
 	}
 }
+
+func (suite *ContactsAPIUnitSuite) TestContactPhoto() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		id      = "id"
+		name    = "contact"
+		photo   = []byte("not really a jpeg")
+		contact = models.NewContact()
+	)
+
+	contact.SetId(&id)
+	contact.SetDisplayName(&name)
+
+	assert.Nil(t, ContactPhoto(contact), "no photo before setting")
+
+	SetContactPhoto(contact, photo)
+	assert.Equal(t, photo, ContactPhoto(contact))
+	assert.Equal(t, int64(len(photo)), ContactInfo(contact).PhotoSize)
+
+	// the photo survives serialization.
+	bs, err := Contacts{}.Serialize(ctx, contact, "user", "id")
+	require.NoError(t, err)
+
+	restored, err := support.CreateContactFromBytes(bs)
+	require.NoError(t, err)
+
+	assert.Equal(t, photo, PopContactPhoto(restored))
+	assert.Nil(t, ContactPhoto(restored), "photo removed after popping")
+	assert.NotContains(t, restored.GetAdditionalData(), ContactPhotoKey)
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/alcionai/clues"
@@ -153,19 +157,20 @@ func (c Events) EnumerateContainers(
 		return clues.Wrap(err, "setting calendar options").WithClues(ctx).With(graph.ErrData(err)...)
 	}
 
-	builder := service.Client().UsersById(userID).Calendars()
-
-	for {
-		resp, err := builder.Get(ctx, ofc)
-		if err != nil {
-			return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
-		}
+	pager := &calendarPager{
+		gs:      service,
+		builder: service.Client().UsersById(userID).Calendars(),
+		options: ofc,
+	}
 
-		for _, cal := range resp.GetValue() {
+	err = graph.EnumeratePages[models.Calendarable](
+		ctx,
+		pager,
+		graph.DefaultPagerOptions(),
+		func(cal models.Calendarable) error {
 			cd := CalendarDisplayable{Calendarable: cal}
 			if err := checkIDAndName(cd); err != nil {
-				errs.Add(clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...))
-				continue
+				return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
 			}
 
 			fctx := clues.Add(
@@ -178,22 +183,43 @@ func (c Events) EnumerateContainers(
 				path.Builder{}.Append(ptr.Val(cd.GetId())),          // storage path
 				path.Builder{}.Append(ptr.Val(cd.GetDisplayName()))) // display location
 			if err := fn(temp); err != nil {
-				errs.Add(clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...))
-				continue
+				return clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...)
 			}
-		}
 
-		link, ok := ptr.ValOK(resp.GetOdataNextLink())
-		if !ok {
-			break
-		}
-
-		builder = users.NewItemCalendarsRequestBuilder(link, service.Adapter())
+			return nil
+		},
+		errs)
+	if err != nil {
+		return err
 	}
 
 	return errs.Err()
 }
 
+// ---------------------------------------------------------------------------
+// container pager
+// ---------------------------------------------------------------------------
+
+var _ graph.Pager[models.Calendarable] = &calendarPager{}
+
+type calendarPager struct {
+	gs      graph.Servicer
+	builder *users.ItemCalendarsRequestBuilder
+	options *users.ItemCalendarsRequestBuilderGetRequestConfiguration
+}
+
+func (p *calendarPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, p.options)
+}
+
+func (p *calendarPager) SetNext(nextLink string) {
+	p.builder = users.NewItemCalendarsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *calendarPager) ValuesIn(pl api.PageLinker) ([]models.Calendarable, error) {
+	return graph.PageValues[models.Calendarable](pl)
+}
+
 // ---------------------------------------------------------------------------
 // item pager
 // ---------------------------------------------------------------------------
@@ -245,7 +271,7 @@ func (c Events) GetAddedAndRemovedItemIDs(
 	if len(oldDelta) > 0 {
 		var (
 			builder = users.NewItemCalendarsItemEventsDeltaRequestBuilder(oldDelta, service.Adapter())
-			pgr     = &eventPager{service, builder, nil}
+			pgr     = c.deltaPager(&eventPager{service, builder, nil})
 		)
 
 		added, removed, deltaURL, err := getItemsAddedAndRemovedFromContainer(ctx, pgr)
@@ -280,7 +306,7 @@ func (c Events) GetAddedAndRemovedItemIDs(
 			logger.Ctx(ctx).Errorw("getting builder info", "error", err)
 		} else {
 			logger.Ctx(ctx).
-				With("user", user, "container", calendarID).
+				With("user", logger.PII(user), "container", calendarID).
 				Warnw("builder path-parameters", "path_parameters", gri.PathParameters)
 		}
 	}
@@ -294,6 +320,178 @@ func (c Events) GetAddedAndRemovedItemIDs(
 	return added, removed, DeltaUpdate{deltaURL, resetDelta}, nil
 }
 
+// ---------------------------------------------------------------------------
+// recurring series
+// ---------------------------------------------------------------------------
+
+const (
+	// the calendar view request builder does not support the required start
+	// and end query parameters, so we construct the url ourselves.
+	eventCalendarViewURLTemplate = "https://graph.microsoft.com/v1.0/users/%s/calendars/%s/calendarView?%s"
+
+	// the expansion of recurring series is bounded to this window around the
+	// current time.  Changes to occurrences outside of the window are only
+	// captured when the series master itself changes.
+	seriesHistoryWindow  = 90 * 24 * time.Hour
+	seriesUpcomingWindow = 365 * 24 * time.Hour
+)
+
+var (
+	_ graph.Pager[models.Eventable] = &seriesMasterPager{}
+	_ graph.Pager[models.Eventable] = &calendarViewPager{}
+)
+
+type seriesMasterPager struct {
+	gs      graph.Servicer
+	builder *users.ItemCalendarsItemEventsRequestBuilder
+	options *users.ItemCalendarsItemEventsRequestBuilderGetRequestConfiguration
+}
+
+func (p *seriesMasterPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, p.options)
+}
+
+func (p *seriesMasterPager) SetNext(nextLink string) {
+	p.builder = users.NewItemCalendarsItemEventsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *seriesMasterPager) ValuesIn(pl api.PageLinker) ([]models.Eventable, error) {
+	return graph.PageValues[models.Eventable](pl)
+}
+
+type calendarViewPager struct {
+	gs      graph.Servicer
+	builder *users.ItemCalendarsItemCalendarViewRequestBuilder
+}
+
+func (p *calendarViewPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *calendarViewPager) SetNext(nextLink string) {
+	p.builder = users.NewItemCalendarsItemCalendarViewRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *calendarViewPager) ValuesIn(pl api.PageLinker) ([]models.Eventable, error) {
+	return graph.PageValues[models.Eventable](pl)
+}
+
+// calendarViewURL produces the url of the calendar's view between start
+// and end, selecting only the properties needed to fingerprint a series.
+func calendarViewURL(user, calendarID string, start, end time.Time) string {
+	q := url.Values{}
+	q.Set("startDateTime", start.UTC().Format(time.RFC3339))
+	q.Set("endDateTime", end.UTC().Format(time.RFC3339))
+	q.Set("$select", "id,changeKey,seriesMasterId")
+
+	return fmt.Sprintf(
+		eventCalendarViewURLTemplate,
+		url.PathEscape(user),
+		url.PathEscape(calendarID),
+		q.Encode())
+}
+
+// GetSeriesFingerprints produces a fingerprint for each recurring series in
+// the calendar, keyed by the ID of the series master.  Event delta queries
+// don't reliably report changes to individual occurrences of a series, so
+// the fingerprint combines the change keys of the master and its expanded
+// occurrences.  A changed fingerprint means the series needs to be backed
+// up again.  The occurrences of every series are retrieved together, from
+// a single calendar view.
+func (c Events) GetSeriesFingerprints(
+	ctx context.Context,
+	user, calendarID string,
+) (map[string]string, error) {
+	service, err := c.service()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = clues.Add(ctx, "container_id", calendarID)
+
+	filter := "type eq 'seriesMaster'"
+	pgr := &seriesMasterPager{
+		gs:      service,
+		builder: service.Client().UsersById(user).CalendarsById(calendarID).Events(),
+		options: &users.ItemCalendarsItemEventsRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemCalendarsItemEventsRequestBuilderGetQueryParameters{
+				Filter: &filter,
+				Select: []string{"id", "changeKey"},
+			},
+		},
+	}
+
+	masters, err := graph.GetAllValues[models.Eventable](ctx, pgr, graph.DefaultPagerOptions())
+	if err != nil {
+		return nil, clues.Wrap(err, "getting series masters").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	if len(masters) == 0 {
+		return map[string]string{}, nil
+	}
+
+	now := time.Now()
+	vpgr := &calendarViewPager{
+		gs: service,
+		builder: users.NewItemCalendarsItemCalendarViewRequestBuilder(
+			calendarViewURL(user, calendarID, now.Add(-seriesHistoryWindow), now.Add(seriesUpcomingWindow)),
+			service.Adapter()),
+	}
+
+	occurrences, err := graph.GetAllValues[models.Eventable](ctx, vpgr, graph.DefaultPagerOptions())
+	if err != nil {
+		return nil, clues.Wrap(err, "getting series occurrences").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return seriesFingerprints(masters, occurrences), nil
+}
+
+// seriesFingerprints fingerprints each series master along with the
+// occurrences that belong to it.  Occurrences of other events are ignored.
+func seriesFingerprints(masters, occurrences []models.Eventable) map[string]string {
+	bySeries := map[string][]models.Eventable{}
+
+	for _, o := range occurrences {
+		if smID := ptr.Val(o.GetSeriesMasterId()); len(smID) > 0 {
+			bySeries[smID] = append(bySeries[smID], o)
+		}
+	}
+
+	fps := make(map[string]string, len(masters))
+
+	for _, m := range masters {
+		mID := ptr.Val(m.GetId())
+		if len(mID) == 0 {
+			continue
+		}
+
+		fps[mID] = SeriesFingerprint(ptr.Val(m.GetChangeKey()), bySeries[mID])
+	}
+
+	return fps
+}
+
+// SeriesFingerprint hashes the change keys of a series master and its
+// occurrences.  The order of the occurrences does not affect the result.
+func SeriesFingerprint(masterChangeKey string, instances []models.Eventable) string {
+	keys := make([]string, 0, len(instances))
+
+	for _, inst := range instances {
+		keys = append(keys, ptr.Val(inst.GetId())+":"+ptr.Val(inst.GetChangeKey()))
+	}
+
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(masterChangeKey))
+
+	for _, k := range keys {
+		h.Write([]byte("\n" + k))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // ---------------------------------------------------------------------------
 // Serialization
 // ---------------------------------------------------------------------------
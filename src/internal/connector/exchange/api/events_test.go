@@ -1,6 +1,7 @@
 package api
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
@@ -175,3 +176,78 @@ func (suite *EventsAPIUnitSuite) TestEventInfo() {
 		})
 	}
 }
+
+func (suite *EventsAPIUnitSuite) TestSeriesFingerprint() {
+	instance := func(id, changeKey string) models.Eventable {
+		evt := models.NewEvent()
+		evt.SetId(&id)
+		evt.SetChangeKey(&changeKey)
+
+		return evt
+	}
+
+	var (
+		t        = suite.T()
+		base     = SeriesFingerprint("master", []models.Eventable{instance("i1", "k1"), instance("i2", "k2")})
+		reversed = SeriesFingerprint("master", []models.Eventable{instance("i2", "k2"), instance("i1", "k1")})
+		edited   = SeriesFingerprint("master", []models.Eventable{instance("i1", "k1"), instance("i2", "k2b")})
+		master   = SeriesFingerprint("master2", []models.Eventable{instance("i1", "k1"), instance("i2", "k2")})
+		dropped  = SeriesFingerprint("master", []models.Eventable{instance("i1", "k1")})
+	)
+
+	assert.Equal(t, base, reversed, "instance order is irrelevant")
+	assert.NotEqual(t, base, edited, "instance change")
+	assert.NotEqual(t, base, master, "master change")
+	assert.NotEqual(t, base, dropped, "removed instance")
+}
+
+func (suite *EventsAPIUnitSuite) TestSeriesFingerprints() {
+	event := func(id, changeKey, seriesMasterID string) models.Eventable {
+		evt := models.NewEvent()
+		evt.SetId(&id)
+		evt.SetChangeKey(&changeKey)
+
+		if len(seriesMasterID) > 0 {
+			evt.SetSeriesMasterId(&seriesMasterID)
+		}
+
+		return evt
+	}
+
+	var (
+		t       = suite.T()
+		masters = []models.Eventable{event("m1", "mk1", ""), event("m2", "mk2", "")}
+		view    = []models.Eventable{
+			event("i1", "k1", "m1"),
+			event("single", "sk", ""),
+			event("i2", "k2", "m1"),
+			event("other", "ok", "unknown"),
+		}
+	)
+
+	fps := seriesFingerprints(masters, view)
+	assert.Equal(
+		t,
+		map[string]string{
+			"m1": SeriesFingerprint("mk1", []models.Eventable{view[0], view[2]}),
+			"m2": SeriesFingerprint("mk2", nil),
+		},
+		fps)
+}
+
+func (suite *EventsAPIUnitSuite) TestCalendarViewURL() {
+	var (
+		t     = suite.T()
+		start = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		end   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	u, err := url.Parse(calendarViewURL("us/er", "AAMk+a/b==", start, end))
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1.0/users/us/er/calendars/AAMk+a/b==/calendarView", u.Path)
+	assert.Equal(t, "/v1.0/users/us%2Fer/calendars/AAMk+a%2Fb==/calendarView", u.EscapedPath())
+	assert.Equal(t, "2023-01-01T00:00:00Z", u.Query().Get("startDateTime"))
+	assert.Equal(t, "2024-01-01T00:00:00Z", u.Query().Get("endDateTime"))
+	assert.Equal(t, "id,changeKey,seriesMasterId", u.Query().Get("$select"))
+}
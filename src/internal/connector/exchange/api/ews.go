@@ -0,0 +1,411 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// DefaultEWSEndpoint is the Exchange Web Services url of Exchange Online.
+const DefaultEWSEndpoint = "https://outlook.office365.com/EWS/Exchange.asmx"
+
+const (
+	soapNS     = "http://schemas.xmlsoap.org/soap/envelope/"
+	ewsTypesNS = "http://schemas.microsoft.com/exchange/services/2006/types"
+	ewsMsgsNS  = "http://schemas.microsoft.com/exchange/services/2006/messages"
+
+	// time zones on calendar items need at least the 2010 schema.
+	ewsServerVersion = "Exchange2013_SP1"
+
+	// responses only echo the IDs of the created or found items.
+	maxEWSResponseBytes = 1 << 20
+)
+
+// The distinguished folders which root the containers created through ews.
+const (
+	EWSMailRoot     = "msgfolderroot"
+	EWSContactsRoot = "contacts"
+	EWSCalendarRoot = "calendar"
+)
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+// EWS creates folders and items through Exchange Web Services.  Hybrid
+// tenants can host mailboxes that graph won't read or write, but which
+// still accept requests through EWS.
+type EWS struct {
+	cred     azcore.TokenCredential
+	client   *http.Client
+	endpoint string
+}
+
+// NewEWS creates an EWS client which authenticates as the M365
+// application, and impersonates each mailbox that it writes into.
+// If the endpoint is empty, Exchange Online's endpoint is used.
+func NewEWS(creds account.M365Config, endpoint string) (*EWS, error) {
+	cred, err := graph.NewCredential(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(endpoint) == 0 {
+		endpoint = DefaultEWSEndpoint
+	}
+
+	return &EWS{
+		cred: cred,
+		client: &http.Client{
+			Timeout:   3 * time.Minute,
+			Transport: graph.Transport(),
+		},
+		endpoint: endpoint,
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// EnsureFolder produces the ID of the parent's child folder with the
+// given display name, creating the folder when the parent has no such
+// child.  The parentID is an ews ID or one of the distinguished roots.
+// The folderClass sets the kind of folder that gets created, eg:
+// IPF.Note for mail folders.  The produced ID is an ews ID.
+func (e EWS) EnsureFolder(ctx context.Context, mailbox, parentID, name, folderClass string) (string, error) {
+	ctx = clues.Add(ctx, "parent_folder_id", parentID, "folder_name", logger.PII(name))
+
+	find := &ewsFindFolder{
+		Traversal: "Shallow",
+		BaseShape: "IdOnly",
+		Restriction: ewsIsEqualTo{
+			Field:    ewsFieldURI{URI: "folder:DisplayName"},
+			Constant: ewsConstant{Value: name},
+		},
+		Parents: toEWSFolderRef(parentID),
+	}
+
+	rms, err := e.call(ctx, mailbox, ewsRequestBody{FindFolder: find})
+	if err != nil {
+		return "", clues.Wrap(err, "finding ews folder")
+	}
+
+	if id := firstFolderID(rms); len(id) > 0 {
+		return id, nil
+	}
+
+	create := &ewsCreateFolder{
+		Parent: toEWSFolderRef(parentID),
+		Folder: ewsFolder{FolderClass: folderClass, DisplayName: name},
+	}
+
+	rms, err = e.call(ctx, mailbox, ewsRequestBody{CreateFolder: create})
+	if err != nil {
+		return "", clues.Wrap(err, "creating ews folder")
+	}
+
+	id := firstFolderID(rms)
+	if len(id) == 0 {
+		return "", clues.New("ews folder created without an ID").WithClues(ctx)
+	}
+
+	return id, nil
+}
+
+// CreateMessage saves the message, unsent, into the mailbox's folder.
+// The folderID is a graph or ews ID.
+func (e EWS) CreateMessage(ctx context.Context, mailbox, folderID string, msg models.Messageable) error {
+	req := &ewsCreateItem{
+		MessageDisposition: "SaveOnly",
+		SavedItemFolder:    toEWSFolderRef(EWSID(folderID)),
+		Items:              ewsItems{Message: toEWSMessage(msg)},
+	}
+
+	_, err := e.call(ctx, mailbox, ewsRequestBody{CreateItem: req})
+
+	return err
+}
+
+// CreateContact saves the contact into the mailbox's contact folder.
+// The folderID is a graph or ews ID.
+func (e EWS) CreateContact(ctx context.Context, mailbox, folderID string, contact models.Contactable) error {
+	req := &ewsCreateItem{
+		SavedItemFolder: toEWSFolderRef(EWSID(folderID)),
+		Items:           ewsItems{Contact: toEWSContact(contact)},
+	}
+
+	_, err := e.call(ctx, mailbox, ewsRequestBody{CreateItem: req})
+
+	return err
+}
+
+// CreateEvent saves the event into the mailbox's calendar without
+// inviting its attendees.  The calendarID is a graph or ews ID.
+// Recurring events aren't supported.
+func (e EWS) CreateEvent(ctx context.Context, mailbox, calendarID string, event models.Eventable) error {
+	item, err := toEWSCalendarItem(event)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	req := &ewsCreateItem{
+		SendMeetingInvitations: "SendToNone",
+		SavedItemFolder:        toEWSFolderRef(EWSID(calendarID)),
+		Items:                  ewsItems{CalendarItem: item},
+	}
+
+	_, err = e.call(ctx, mailbox, ewsRequestBody{CreateItem: req})
+
+	return err
+}
+
+// EWSID converts a graph ID into the equivalent EWS ID.  Graph IDs are
+// the url-safe base64 encoding of the same identifier.  EWS IDs are
+// returned unchanged.
+func EWSID(graphID string) string {
+	return strings.NewReplacer("-", "/", "_", "+").Replace(graphID)
+}
+
+func isDistinguishedFolder(id string) bool {
+	return id == EWSMailRoot || id == EWSContactsRoot || id == EWSCalendarRoot
+}
+
+func toEWSFolderRef(id string) ewsFolderRef {
+	if isDistinguishedFolder(id) {
+		return ewsFolderRef{Distinguished: &ewsFolderID{ID: id}}
+	}
+
+	return ewsFolderRef{Folder: &ewsFolderID{ID: id}}
+}
+
+// firstFolderID produces the ID of the first folder found or created
+// by the request, or an empty string if there were none.
+func firstFolderID(rms []ewsResponseMessage) string {
+	for _, rm := range rms {
+		for _, f := range append(rm.RootFolder.Folders, rm.Folders.Folders...) {
+			if len(f.ID.ID) > 0 {
+				return f.ID.ID
+			}
+		}
+	}
+
+	return ""
+}
+
+// call sends the request to ews on behalf of the mailbox, and produces
+// the response messages.  Any unsuccessful response message fails the
+// call.
+func (e EWS) call(ctx context.Context, mailbox string, rb ewsRequestBody) ([]ewsResponseMessage, error) {
+	ctx = clues.Add(ctx, "ews_endpoint", e.endpoint)
+
+	env := ewsEnvelope{
+		SoapNS:  soapNS,
+		TypesNS: ewsTypesNS,
+		MsgsNS:  ewsMsgsNS,
+		Header: ewsHeader{
+			Version:       ewsVersion{Version: ewsServerVersion},
+			Impersonating: mailbox,
+		},
+		Body: rb,
+	}
+
+	bs, err := xml.Marshal(env)
+	if err != nil {
+		return nil, clues.Wrap(err, "serializing ews request").WithClues(ctx)
+	}
+
+	ep, err := url.Parse(e.endpoint)
+	if err != nil {
+		return nil, clues.Wrap(err, "parsing ews endpoint").WithClues(ctx)
+	}
+
+	// ews tokens are scoped to the exchange host.
+	tok, err := e.cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{ep.Scheme + "://" + ep.Host + "/.default"},
+	})
+	if err != nil {
+		return nil, clues.Wrap(err, "getting ews token").WithClues(ctx)
+	}
+
+	bs = append([]byte(xml.Header), bs...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(bs))
+	if err != nil {
+		return nil, clues.Wrap(err, "creating ews request").WithClues(ctx)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+tok.Token)
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	// routes the request to the server that hosts the mailbox.
+	req.Header.Set("X-AnchorMailbox", mailbox)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, clues.Wrap(err, "sending ews request").WithClues(ctx)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEWSResponseBytes))
+	if err != nil {
+		return nil, clues.Wrap(err, "reading ews response").WithClues(ctx)
+	}
+
+	var re ewsResponseEnvelope
+
+	// soap faults arrive with a 500 status, and carry the better message.
+	if err := xml.Unmarshal(body, &re); err != nil {
+		return nil, clues.New(resp.Status).WithClues(ctx).With("status_code", resp.StatusCode)
+	}
+
+	if re.Body.Fault != nil {
+		return nil, clues.New(re.Body.Fault.String).WithClues(ctx).With("status_code", resp.StatusCode)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, clues.New(resp.Status).WithClues(ctx).With("status_code", resp.StatusCode)
+	}
+
+	var rms []ewsResponseMessage
+
+	for _, r := range re.Body.Responses {
+		for _, rm := range r.Messages.Messages {
+			if rm.Class != "Success" {
+				return nil, clues.New(rm.Text).WithClues(ctx).With("ews_response_code", rm.Code)
+			}
+
+			rms = append(rms, rm)
+		}
+	}
+
+	return rms, nil
+}
+
+// ---------------------------------------------------------------------------
+// soap envelope
+// ---------------------------------------------------------------------------
+
+type ewsEnvelope struct {
+	XMLName xml.Name       `xml:"soap:Envelope"`
+	SoapNS  string         `xml:"xmlns:soap,attr"`
+	TypesNS string         `xml:"xmlns:t,attr"`
+	MsgsNS  string         `xml:"xmlns:m,attr"`
+	Header  ewsHeader      `xml:"soap:Header"`
+	Body    ewsRequestBody `xml:"soap:Body"`
+}
+
+type ewsHeader struct {
+	Version       ewsVersion `xml:"t:RequestServerVersion"`
+	Impersonating string     `xml:"t:ExchangeImpersonation>t:ConnectingSID>t:PrimarySmtpAddress"`
+}
+
+type ewsVersion struct {
+	Version string `xml:"Version,attr"`
+}
+
+type ewsRequestBody struct {
+	CreateItem   *ewsCreateItem   `xml:"m:CreateItem,omitempty"`
+	FindFolder   *ewsFindFolder   `xml:"m:FindFolder,omitempty"`
+	CreateFolder *ewsCreateFolder `xml:"m:CreateFolder,omitempty"`
+}
+
+type ewsCreateItem struct {
+	MessageDisposition     string       `xml:"MessageDisposition,attr,omitempty"`
+	SendMeetingInvitations string       `xml:"SendMeetingInvitations,attr,omitempty"`
+	SavedItemFolder        ewsFolderRef `xml:"m:SavedItemFolderId"`
+	Items                  ewsItems     `xml:"m:Items"`
+}
+
+type ewsFindFolder struct {
+	Traversal   string       `xml:"Traversal,attr"`
+	BaseShape   string       `xml:"m:FolderShape>t:BaseShape"`
+	Restriction ewsIsEqualTo `xml:"m:Restriction>t:IsEqualTo"`
+	Parents     ewsFolderRef `xml:"m:ParentFolderIds"`
+}
+
+type ewsIsEqualTo struct {
+	Field    ewsFieldURI `xml:"t:FieldURI"`
+	Constant ewsConstant `xml:"t:FieldURIOrConstant>t:Constant"`
+}
+
+type ewsFieldURI struct {
+	URI string `xml:"FieldURI,attr"`
+}
+
+type ewsConstant struct {
+	Value string `xml:"Value,attr"`
+}
+
+type ewsCreateFolder struct {
+	Parent ewsFolderRef `xml:"m:ParentFolderId"`
+	Folder ewsFolder    `xml:"m:Folders>t:Folder"`
+}
+
+type ewsFolder struct {
+	FolderClass string `xml:"t:FolderClass"`
+	DisplayName string `xml:"t:DisplayName"`
+}
+
+// ewsFolderRef holds either a folder's ID or a distinguished folder's name.
+type ewsFolderRef struct {
+	Folder        *ewsFolderID `xml:"t:FolderId,omitempty"`
+	Distinguished *ewsFolderID `xml:"t:DistinguishedFolderId,omitempty"`
+}
+
+type ewsFolderID struct {
+	ID string `xml:"Id,attr"`
+}
+
+type ewsItems struct {
+	Message      *ewsMessage      `xml:"t:Message,omitempty"`
+	Contact      *ewsContact      `xml:"t:Contact,omitempty"`
+	CalendarItem *ewsCalendarItem `xml:"t:CalendarItem,omitempty"`
+}
+
+// responses are matched by local name, ignoring their namespaces.  Each
+// operation names its response and response messages after itself, eg:
+// CreateItemResponse>ResponseMessages>CreateItemResponseMessage.
+type ewsResponseEnvelope struct {
+	Body struct {
+		Responses []struct {
+			Messages struct {
+				Messages []ewsResponseMessage `xml:",any"`
+			} `xml:"ResponseMessages"`
+		} `xml:",any"`
+		Fault *struct {
+			String string `xml:"faultstring"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+type ewsResponseMessage struct {
+	Class string `xml:"ResponseClass,attr"`
+	Code  string `xml:"ResponseCode"`
+	Text  string `xml:"MessageText"`
+	// FindFolder responses.
+	RootFolder ewsFolders `xml:"RootFolder>Folders"`
+	// CreateFolder responses.
+	Folders ewsFolders `xml:"Folders"`
+}
+
+// folders are named after their kind, eg: Folder, CalendarFolder.
+type ewsFolders struct {
+	Folders []struct {
+		ID ewsFolderID `xml:"FolderId"`
+	} `xml:",any"`
+}
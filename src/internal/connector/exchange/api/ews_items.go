@@ -0,0 +1,376 @@
+package api
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/common/ptr"
+)
+
+// EWS validates each item against its schema, which fixes the order of
+// the item's elements.  Fields are declared in that order.
+
+const (
+	// PR_MESSAGE_FLAGS: marks the message as read, and not as a draft.
+	ewsMessageFlagsTag = "0x0E07"
+	ewsMessageFlagRead = "1"
+	// PR_CLIENT_SUBMIT_TIME and PR_MESSAGE_DELIVERY_TIME.
+	ewsSentTimeTag     = "0x0039"
+	ewsReceivedTimeTag = "0x0E06"
+
+	ewsDateTime = "2006-01-02T15:04:05Z"
+	// calendar times are local to the item's time zone.
+	ewsLocalDateTime = "2006-01-02T15:04:05"
+)
+
+type ewsItemBody struct {
+	Type    string `xml:"BodyType,attr"`
+	Content string `xml:",chardata"`
+}
+
+type ewsMailbox struct {
+	Name    string `xml:"t:Name,omitempty"`
+	Address string `xml:"t:EmailAddress,omitempty"`
+}
+
+type ewsExtendedProperty struct {
+	URI   ewsExtendedFieldURI `xml:"t:ExtendedFieldURI"`
+	Value string              `xml:"t:Value"`
+}
+
+type ewsExtendedFieldURI struct {
+	Tag  string `xml:"PropertyTag,attr"`
+	Type string `xml:"PropertyType,attr"`
+}
+
+type ewsAttachments struct {
+	Files []ewsFileAttachment `xml:"t:FileAttachment"`
+}
+
+type ewsFileAttachment struct {
+	Name        string `xml:"t:Name"`
+	ContentType string `xml:"t:ContentType,omitempty"`
+	ContentID   string `xml:"t:ContentId,omitempty"`
+	IsInline    bool   `xml:"t:IsInline"`
+	Content     string `xml:"t:Content"`
+}
+
+type ewsMessage struct {
+	Subject            string                `xml:"t:Subject,omitempty"`
+	Body               *ewsItemBody          `xml:"t:Body,omitempty"`
+	Attachments        *ewsAttachments       `xml:"t:Attachments,omitempty"`
+	Categories         []string              `xml:"t:Categories>t:String,omitempty"`
+	Importance         string                `xml:"t:Importance,omitempty"`
+	ExtendedProperties []ewsExtendedProperty `xml:"t:ExtendedProperty"`
+	Sender             *ewsMailbox           `xml:"t:Sender>t:Mailbox,omitempty"`
+	ToRecipients       []ewsMailbox          `xml:"t:ToRecipients>t:Mailbox,omitempty"`
+	CcRecipients       []ewsMailbox          `xml:"t:CcRecipients>t:Mailbox,omitempty"`
+	BccRecipients      []ewsMailbox          `xml:"t:BccRecipients>t:Mailbox,omitempty"`
+	From               *ewsMailbox           `xml:"t:From>t:Mailbox,omitempty"`
+	InternetMessageID  string                `xml:"t:InternetMessageId,omitempty"`
+	IsRead             bool                  `xml:"t:IsRead"`
+}
+
+type ewsEntry struct {
+	Key   string `xml:"Key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type ewsContact struct {
+	Body           *ewsItemBody `xml:"t:Body,omitempty"`
+	DisplayName    string       `xml:"t:DisplayName,omitempty"`
+	GivenName      string       `xml:"t:GivenName,omitempty"`
+	MiddleName     string       `xml:"t:MiddleName,omitempty"`
+	Nickname       string       `xml:"t:Nickname,omitempty"`
+	CompanyName    string       `xml:"t:CompanyName,omitempty"`
+	EmailAddresses []ewsEntry   `xml:"t:EmailAddresses>t:Entry,omitempty"`
+	PhoneNumbers   []ewsEntry   `xml:"t:PhoneNumbers>t:Entry,omitempty"`
+	Birthday       string       `xml:"t:Birthday,omitempty"`
+	Department     string       `xml:"t:Department,omitempty"`
+	JobTitle       string       `xml:"t:JobTitle,omitempty"`
+	Surname        string       `xml:"t:Surname,omitempty"`
+}
+
+type ewsAttendee struct {
+	Mailbox ewsMailbox `xml:"t:Mailbox"`
+}
+
+type ewsTimeZone struct {
+	ID string `xml:"Id,attr"`
+}
+
+type ewsCalendarItem struct {
+	Subject           string          `xml:"t:Subject,omitempty"`
+	Body              *ewsItemBody    `xml:"t:Body,omitempty"`
+	Attachments       *ewsAttachments `xml:"t:Attachments,omitempty"`
+	Categories        []string        `xml:"t:Categories>t:String,omitempty"`
+	Importance        string          `xml:"t:Importance,omitempty"`
+	Start             string          `xml:"t:Start"`
+	End               string          `xml:"t:End"`
+	IsAllDay          bool            `xml:"t:IsAllDayEvent"`
+	Location          string          `xml:"t:Location,omitempty"`
+	RequiredAttendees []ewsAttendee   `xml:"t:RequiredAttendees>t:Attendee,omitempty"`
+	OptionalAttendees []ewsAttendee   `xml:"t:OptionalAttendees>t:Attendee,omitempty"`
+	Resources         []ewsAttendee   `xml:"t:Resources>t:Attendee,omitempty"`
+	StartTimeZone     *ewsTimeZone    `xml:"t:StartTimeZone,omitempty"`
+	EndTimeZone       *ewsTimeZone    `xml:"t:EndTimeZone,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// conversions
+// ---------------------------------------------------------------------------
+
+func toEWSMessage(msg models.Messageable) *ewsMessage {
+	em := &ewsMessage{
+		Subject:           ptr.Val(msg.GetSubject()),
+		Body:              toEWSBody(msg.GetBody()),
+		Attachments:       toEWSAttachments(msg.GetAttachments()),
+		Categories:        msg.GetCategories(),
+		Importance:        toEWSImportance(msg.GetImportance()),
+		Sender:            toEWSMailbox(msg.GetSender()),
+		ToRecipients:      toEWSMailboxes(msg.GetToRecipients()),
+		CcRecipients:      toEWSMailboxes(msg.GetCcRecipients()),
+		BccRecipients:     toEWSMailboxes(msg.GetBccRecipients()),
+		From:              toEWSMailbox(msg.GetFrom()),
+		InternetMessageID: ptr.Val(msg.GetInternetMessageId()),
+		IsRead:            ptr.Val(msg.GetIsRead()),
+		ExtendedProperties: []ewsExtendedProperty{
+			{
+				URI:   ewsExtendedFieldURI{Tag: ewsMessageFlagsTag, Type: "Integer"},
+				Value: ewsMessageFlagRead,
+			},
+		},
+	}
+
+	if t := msg.GetSentDateTime(); t != nil {
+		em.ExtendedProperties = append(em.ExtendedProperties, ewsExtendedProperty{
+			URI:   ewsExtendedFieldURI{Tag: ewsSentTimeTag, Type: "SystemTime"},
+			Value: ewsTime(*t),
+		})
+	}
+
+	if t := msg.GetReceivedDateTime(); t != nil {
+		em.ExtendedProperties = append(em.ExtendedProperties, ewsExtendedProperty{
+			URI:   ewsExtendedFieldURI{Tag: ewsReceivedTimeTag, Type: "SystemTime"},
+			Value: ewsTime(*t),
+		})
+	}
+
+	return em
+}
+
+func toEWSContact(contact models.Contactable) *ewsContact {
+	ec := &ewsContact{
+		DisplayName: ptr.Val(contact.GetDisplayName()),
+		GivenName:   ptr.Val(contact.GetGivenName()),
+		MiddleName:  ptr.Val(contact.GetMiddleName()),
+		Nickname:    ptr.Val(contact.GetNickName()),
+		CompanyName: ptr.Val(contact.GetCompanyName()),
+		Department:  ptr.Val(contact.GetDepartment()),
+		JobTitle:    ptr.Val(contact.GetJobTitle()),
+		Surname:     ptr.Val(contact.GetSurname()),
+	}
+
+	if notes := ptr.Val(contact.GetPersonalNotes()); len(notes) > 0 {
+		ec.Body = &ewsItemBody{Type: "Text", Content: notes}
+	}
+
+	// ews holds at most three addresses per contact.
+	for i, ea := range contact.GetEmailAddresses() {
+		if i == 3 {
+			break
+		}
+
+		ec.EmailAddresses = append(ec.EmailAddresses, ewsEntry{
+			Key:   "EmailAddress" + string(rune('1'+i)),
+			Value: ptr.Val(ea.GetAddress()),
+		})
+	}
+
+	ec.PhoneNumbers = appendPhones(ec.PhoneNumbers, contact.GetBusinessPhones(), "BusinessPhone", "BusinessPhone2")
+	ec.PhoneNumbers = appendPhones(ec.PhoneNumbers, contact.GetHomePhones(), "HomePhone", "HomePhone2")
+
+	if mobile := ptr.Val(contact.GetMobilePhone()); len(mobile) > 0 {
+		ec.PhoneNumbers = append(ec.PhoneNumbers, ewsEntry{Key: "MobilePhone", Value: mobile})
+	}
+
+	if bday := contact.GetBirthday(); bday != nil {
+		ec.Birthday = ewsTime(*bday)
+	}
+
+	return ec
+}
+
+// appendPhones adds the phones to the entries, up to the number of keys.
+func appendPhones(entries []ewsEntry, phones []string, keys ...string) []ewsEntry {
+	for i, p := range phones {
+		if i == len(keys) {
+			break
+		}
+
+		entries = append(entries, ewsEntry{Key: keys[i], Value: p})
+	}
+
+	return entries
+}
+
+func toEWSCalendarItem(event models.Eventable) (*ewsCalendarItem, error) {
+	if event.GetRecurrence() != nil {
+		return nil, clues.New("recurring events can't be restored through ews")
+	}
+
+	start, startTZ, err := toEWSLocalTime(event.GetStart())
+	if err != nil {
+		return nil, clues.Wrap(err, "converting event start")
+	}
+
+	end, endTZ, err := toEWSLocalTime(event.GetEnd())
+	if err != nil {
+		return nil, clues.Wrap(err, "converting event end")
+	}
+
+	ec := &ewsCalendarItem{
+		Subject:       ptr.Val(event.GetSubject()),
+		Body:          toEWSBody(event.GetBody()),
+		Attachments:   toEWSAttachments(event.GetAttachments()),
+		Categories:    event.GetCategories(),
+		Importance:    toEWSImportance(event.GetImportance()),
+		Start:         start,
+		End:           end,
+		IsAllDay:      ptr.Val(event.GetIsAllDay()),
+		StartTimeZone: startTZ,
+		EndTimeZone:   endTZ,
+	}
+
+	if loc := event.GetLocation(); loc != nil {
+		ec.Location = ptr.Val(loc.GetDisplayName())
+	}
+
+	for _, a := range event.GetAttendees() {
+		att := ewsAttendee{Mailbox: ewsMailbox{
+			Name:    ptr.Val(a.GetEmailAddress().GetName()),
+			Address: ptr.Val(a.GetEmailAddress().GetAddress()),
+		}}
+
+		switch ptr.Val(a.GetType()) {
+		case models.OPTIONAL_ATTENDEETYPE:
+			ec.OptionalAttendees = append(ec.OptionalAttendees, att)
+		case models.RESOURCE_ATTENDEETYPE:
+			ec.Resources = append(ec.Resources, att)
+		default:
+			ec.RequiredAttendees = append(ec.RequiredAttendees, att)
+		}
+	}
+
+	return ec, nil
+}
+
+// toEWSLocalTime produces the time, local to its zone, along with the
+// zone itself.  Graph names zones by their windows ID, same as ews.
+func toEWSLocalTime(dt models.DateTimeTimeZoneable) (string, *ewsTimeZone, error) {
+	if dt == nil {
+		return "", nil, clues.New("missing time")
+	}
+
+	// graph omits the zone designator, which the parser requires.  The
+	// 'Z' only satisfies the parser: the time stays local to its zone.
+	t, err := common.ParseTime(ptr.Val(dt.GetDateTime()) + "Z")
+	if err != nil {
+		return "", nil, clues.Wrap(err, "parsing time")
+	}
+
+	var tz *ewsTimeZone
+
+	if zone := ptr.Val(dt.GetTimeZone()); len(zone) > 0 {
+		tz = &ewsTimeZone{ID: zone}
+	}
+
+	return t.Format(ewsLocalDateTime), tz, nil
+}
+
+func toEWSBody(body models.ItemBodyable) *ewsItemBody {
+	if body == nil {
+		return nil
+	}
+
+	typ := "Text"
+	if ptr.Val(body.GetContentType()) == models.HTML_BODYTYPE {
+		typ = "HTML"
+	}
+
+	return &ewsItemBody{Type: typ, Content: ptr.Val(body.GetContent())}
+}
+
+func toEWSImportance(imp *models.Importance) string {
+	if imp == nil {
+		return ""
+	}
+
+	// graph's lowercase importance (low, normal, high) is capitalized in ews.
+	s := imp.String()
+	if len(s) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func toEWSMailbox(r models.Recipientable) *ewsMailbox {
+	if r == nil || r.GetEmailAddress() == nil {
+		return nil
+	}
+
+	return &ewsMailbox{
+		Name:    ptr.Val(r.GetEmailAddress().GetName()),
+		Address: ptr.Val(r.GetEmailAddress().GetAddress()),
+	}
+}
+
+func toEWSMailboxes(rs []models.Recipientable) []ewsMailbox {
+	var mbs []ewsMailbox
+
+	for _, r := range rs {
+		if mb := toEWSMailbox(r); mb != nil {
+			mbs = append(mbs, *mb)
+		}
+	}
+
+	return mbs
+}
+
+// toEWSAttachments converts the file attachments.  Item attachments
+// (attached mail, events, and contacts) aren't supported by ews item
+// creation, and are dropped, same as when graph rejects them.
+func toEWSAttachments(atts []models.Attachmentable) *ewsAttachments {
+	var files []ewsFileAttachment
+
+	for _, a := range atts {
+		fa, ok := a.(models.FileAttachmentable)
+		if !ok {
+			continue
+		}
+
+		files = append(files, ewsFileAttachment{
+			Name:        ptr.Val(fa.GetName()),
+			ContentType: ptr.Val(fa.GetContentType()),
+			ContentID:   ptr.Val(fa.GetContentId()),
+			IsInline:    ptr.Val(fa.GetIsInline()),
+			Content:     base64.StdEncoding.EncodeToString(fa.GetContentBytes()),
+		})
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	return &ewsAttachments{Files: files}
+}
+
+func ewsTime(t time.Time) string {
+	return t.UTC().Format(ewsDateTime)
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type mockTokenCredential struct{}
+
+func (mockTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+const ewsSuccessResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:CreateItemResponse xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages">
+<m:ResponseMessages>
+<m:CreateItemResponseMessage ResponseClass="Success">
+<m:ResponseCode>NoError</m:ResponseCode>
+</m:CreateItemResponseMessage>
+</m:ResponseMessages>
+</m:CreateItemResponse>
+</s:Body>
+</s:Envelope>`
+
+const ewsErrorResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:CreateItemResponse xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages">
+<m:ResponseMessages>
+<m:CreateItemResponseMessage ResponseClass="Error">
+<m:MessageText>The specified folder could not be found in the store.</m:MessageText>
+<m:ResponseCode>ErrorFolderNotFound</m:ResponseCode>
+</m:CreateItemResponseMessage>
+</m:ResponseMessages>
+</m:CreateItemResponse>
+</s:Body>
+</s:Envelope>`
+
+const ewsFaultResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<s:Fault><faultcode>a:ErrorImpersonateUserDenied</faultcode><faultstring>impersonation denied</faultstring></s:Fault>
+</s:Body>
+</s:Envelope>`
+
+const ewsFindFolderEmptyResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:FindFolderResponse xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages"
+xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types">
+<m:ResponseMessages>
+<m:FindFolderResponseMessage ResponseClass="Success">
+<m:ResponseCode>NoError</m:ResponseCode>
+<m:RootFolder TotalItemsInView="0" IncludesLastItemInRange="true"><t:Folders/></m:RootFolder>
+</m:FindFolderResponseMessage>
+</m:ResponseMessages>
+</m:FindFolderResponse>
+</s:Body>
+</s:Envelope>`
+
+const ewsFindFolderResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:FindFolderResponse xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages"
+xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types">
+<m:ResponseMessages>
+<m:FindFolderResponseMessage ResponseClass="Success">
+<m:ResponseCode>NoError</m:ResponseCode>
+<m:RootFolder TotalItemsInView="1" IncludesLastItemInRange="true">
+<t:Folders><t:CalendarFolder><t:FolderId Id="found/id"/></t:CalendarFolder></t:Folders>
+</m:RootFolder>
+</m:FindFolderResponseMessage>
+</m:ResponseMessages>
+</m:FindFolderResponse>
+</s:Body>
+</s:Envelope>`
+
+const ewsCreateFolderResponse = `<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+<s:Body>
+<m:CreateFolderResponse xmlns:m="http://schemas.microsoft.com/exchange/services/2006/messages"
+xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types">
+<m:ResponseMessages>
+<m:CreateFolderResponseMessage ResponseClass="Success">
+<m:ResponseCode>NoError</m:ResponseCode>
+<m:Folders><t:Folder><t:FolderId Id="created/id"/></t:Folder></m:Folders>
+</m:CreateFolderResponseMessage>
+</m:ResponseMessages>
+</m:CreateFolderResponse>
+</s:Body>
+</s:Envelope>`
+
+type EWSUnitSuite struct {
+	tester.Suite
+}
+
+func TestEWSUnitSuite(t *testing.T) {
+	suite.Run(t, &EWSUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *EWSUnitSuite) TestEWSID() {
+	assert.Equal(suite.T(), "AAMk/a+b==", EWSID("AAMk-a_b=="))
+}
+
+func (suite *EWSUnitSuite) TestCreateMessage() {
+	table := []struct {
+		name      string
+		status    int
+		response  string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "created",
+			status:    http.StatusOK,
+			response:  ewsSuccessResponse,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "error response",
+			status:    http.StatusOK,
+			response:  ewsErrorResponse,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "soap fault",
+			status:    http.StatusInternalServerError,
+			response:  ewsFaultResponse,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "not soap",
+			status:    http.StatusUnauthorized,
+			response:  "unauthorized",
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t      = suite.T()
+				body   string
+				header http.Header
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := io.ReadAll(r.Body)
+				body, header = string(bs), r.Header
+
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer srv.Close()
+
+			msg, err := support.CreateMessageFromBytes(mockconnector.GetMockMessageWithBodyBytes("subject", "body", "body"))
+			require.NoError(t, err)
+
+			e := EWS{cred: mockTokenCredential{}, client: srv.Client(), endpoint: srv.URL}
+
+			err = e.CreateMessage(ctx, "user@contoso.com", "folder-id_1", msg)
+			test.expectErr(t, err)
+
+			assert.Equal(t, "Bearer token", header.Get("Authorization"))
+			assert.Equal(t, "user@contoso.com", header.Get("X-AnchorMailbox"))
+			assert.Contains(t, body, `<m:CreateItem MessageDisposition="SaveOnly">`)
+			assert.Contains(t, body, `<t:PrimarySmtpAddress>user@contoso.com</t:PrimarySmtpAddress>`)
+			assert.Contains(t, body, `<t:FolderId Id="folder/id+1"></t:FolderId>`)
+			assert.Contains(t, body, `<t:Message><t:Subject>subject</t:Subject>`)
+			assert.Contains(t, body, `<t:ExtendedFieldURI PropertyTag="0x0E07" PropertyType="Integer">`)
+		})
+	}
+}
+
+func (suite *EWSUnitSuite) TestEnsureFolder() {
+	table := []struct {
+		name          string
+		parentID      string
+		responses     []string
+		expectErr     assert.ErrorAssertionFunc
+		expectID      string
+		expectParent  string
+		expectRequest []string
+	}{
+		{
+			name:          "existing folder",
+			parentID:      EWSCalendarRoot,
+			responses:     []string{ewsFindFolderResponse},
+			expectErr:     assert.NoError,
+			expectID:      "found/id",
+			expectParent:  `<t:DistinguishedFolderId Id="calendar"></t:DistinguishedFolderId>`,
+			expectRequest: []string{"m:FindFolder"},
+		},
+		{
+			name:          "new folder",
+			parentID:      "parent/id",
+			responses:     []string{ewsFindFolderEmptyResponse, ewsCreateFolderResponse},
+			expectErr:     assert.NoError,
+			expectID:      "created/id",
+			expectParent:  `<t:FolderId Id="parent/id"></t:FolderId>`,
+			expectRequest: []string{"m:FindFolder", "m:CreateFolder"},
+		},
+		{
+			name:          "find fails",
+			parentID:      EWSMailRoot,
+			responses:     []string{ewsErrorResponse},
+			expectErr:     assert.Error,
+			expectParent:  `<t:DistinguishedFolderId Id="msgfolderroot"></t:DistinguishedFolderId>`,
+			expectRequest: []string{"m:FindFolder"},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t      = suite.T()
+				bodies []string
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(bs))
+
+				_, _ = w.Write([]byte(test.responses[len(bodies)-1]))
+			}))
+			defer srv.Close()
+
+			e := EWS{cred: mockTokenCredential{}, client: srv.Client(), endpoint: srv.URL}
+
+			id, err := e.EnsureFolder(ctx, "user@contoso.com", test.parentID, "dest", "IPF.Appointment")
+			test.expectErr(t, err)
+			assert.Equal(t, test.expectID, id)
+
+			require.Len(t, bodies, len(test.expectRequest))
+
+			for i, req := range test.expectRequest {
+				assert.Contains(t, bodies[i], "<"+req)
+				assert.Contains(t, bodies[i], test.expectParent)
+			}
+
+			assert.Contains(t, bodies[0], `<t:Constant Value="dest"></t:Constant>`)
+
+			if len(bodies) > 1 {
+				assert.Contains(
+					t,
+					bodies[1],
+					`<t:Folder><t:FolderClass>IPF.Appointment</t:FolderClass><t:DisplayName>dest</t:DisplayName>`)
+			}
+		})
+	}
+}
+
+func (suite *EWSUnitSuite) TestToEWSContact() {
+	var (
+		t       = suite.T()
+		contact = models.NewContact()
+		name    = "Lyn"
+		mobile  = "555-0100"
+		addrs   = []models.EmailAddressable{}
+	)
+
+	for _, a := range []string{"a@contoso.com", "b@contoso.com", "c@contoso.com", "d@contoso.com"} {
+		addr := a
+		ea := models.NewEmailAddress()
+		ea.SetAddress(&addr)
+		addrs = append(addrs, ea)
+	}
+
+	contact.SetGivenName(&name)
+	contact.SetMobilePhone(&mobile)
+	contact.SetBusinessPhones([]string{"555-0101", "555-0102", "555-0103"})
+	contact.SetEmailAddresses(addrs)
+
+	ec := toEWSContact(contact)
+
+	assert.Equal(t, name, ec.GivenName)
+	assert.Equal(
+		t,
+		[]ewsEntry{
+			{Key: "EmailAddress1", Value: "a@contoso.com"},
+			{Key: "EmailAddress2", Value: "b@contoso.com"},
+			{Key: "EmailAddress3", Value: "c@contoso.com"},
+		},
+		ec.EmailAddresses)
+	assert.Equal(
+		t,
+		[]ewsEntry{
+			{Key: "BusinessPhone", Value: "555-0101"},
+			{Key: "BusinessPhone2", Value: "555-0102"},
+			{Key: "MobilePhone", Value: mobile},
+		},
+		ec.PhoneNumbers)
+}
+
+func (suite *EWSUnitSuite) TestToEWSCalendarItem() {
+	dtz := func(dt, tz string) models.DateTimeTimeZoneable {
+		d := models.NewDateTimeTimeZone()
+		d.SetDateTime(&dt)
+		d.SetTimeZone(&tz)
+
+		return d
+	}
+
+	attendee := func(addr string, typ models.AttendeeType) models.Attendeeable {
+		ea := models.NewEmailAddress()
+		ea.SetAddress(&addr)
+
+		a := models.NewAttendee()
+		a.SetEmailAddress(ea)
+		a.SetType(&typ)
+
+		return a
+	}
+
+	suite.Run("single event", func() {
+		t := suite.T()
+		event := models.NewEvent()
+		event.SetStart(dtz("2023-03-01T09:30:00.0000000", "Pacific Standard Time"))
+		event.SetEnd(dtz("2023-03-01T10:00:00.0000000", "Pacific Standard Time"))
+		event.SetAttendees([]models.Attendeeable{
+			attendee("req@contoso.com", models.REQUIRED_ATTENDEETYPE),
+			attendee("opt@contoso.com", models.OPTIONAL_ATTENDEETYPE),
+		})
+
+		ec, err := toEWSCalendarItem(event)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2023-03-01T09:30:00", ec.Start)
+		assert.Equal(t, "2023-03-01T10:00:00", ec.End)
+		assert.Equal(t, &ewsTimeZone{ID: "Pacific Standard Time"}, ec.StartTimeZone)
+		require.Len(t, ec.RequiredAttendees, 1)
+		assert.Equal(t, "req@contoso.com", ec.RequiredAttendees[0].Mailbox.Address)
+		require.Len(t, ec.OptionalAttendees, 1)
+		assert.Equal(t, "opt@contoso.com", ec.OptionalAttendees[0].Mailbox.Address)
+	})
+
+	suite.Run("recurring event", func() {
+		event := models.NewEvent()
+		event.SetStart(dtz("2023-03-01T09:30:00.0000000", "UTC"))
+		event.SetEnd(dtz("2023-03-01T10:00:00.0000000", "UTC"))
+		event.SetRecurrence(models.NewPatternedRecurrence())
+
+		_, err := toEWSCalendarItem(event)
+		assert.Error(suite.T(), err)
+	})
+}
@@ -3,7 +3,10 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoft/kiota-abstractions-go/serialization"
@@ -119,6 +122,110 @@ func (c Mail) GetContainerByID(
 	return resp, nil
 }
 
+// mailFolderSizeProperty is the extended MAPI property holding the total
+// size, in bytes, of the messages in a folder (PR_MESSAGE_SIZE_EXTENDED).
+const mailFolderSizeProperty = "Long 0x0E08"
+
+// ContainerStats describes the contents of a container, as reported by
+// the container itself ahead of enumerating its items.
+type ContainerStats struct {
+	ItemCount int64
+	// Size is the total size of the items in bytes, or 0 if unknown.
+	Size int64
+}
+
+// GetContainerStats retrieves the item count and size of the mail folder.
+func (c Mail) GetContainerStats(
+	ctx context.Context,
+	userID, dirID string,
+) (ContainerStats, error) {
+	service, err := c.service()
+	if err != nil {
+		return ContainerStats{}, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	// the folder request builder doesn't support $expand, which is needed
+	// to include the extended size property.
+	query := url.Values{
+		"$select": []string{"id,totalItemCount"},
+		"$expand": []string{fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", mailFolderSizeProperty)},
+	}
+
+	rawURL := fmt.Sprintf(
+		"%s/users/%s/mailFolders/%s?%s",
+		service.Adapter().GetBaseUrl(),
+		url.PathEscape(userID),
+		url.PathEscape(dirID),
+		// graph expects spaces within odata expressions to be percent-encoded.
+		strings.ReplaceAll(query.Encode(), "+", "%20"))
+
+	resp, err := users.NewItemMailFoldersMailFolderItemRequestBuilder(rawURL, service.Adapter()).Get(ctx, nil)
+	if err != nil {
+		return ContainerStats{}, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return mailFolderStats(resp), nil
+}
+
+func mailFolderStats(mf models.MailFolderable) ContainerStats {
+	cs := ContainerStats{ItemCount: int64(ptr.Val(mf.GetTotalItemCount()))}
+
+	for _, prop := range mf.GetSingleValueExtendedProperties() {
+		if !strings.EqualFold(ptr.Val(prop.GetId()), mailFolderSizeProperty) {
+			continue
+		}
+
+		// an unparsable size leaves the size unknown.
+		cs.Size, _ = strconv.ParseInt(ptr.Val(prop.GetValue()), 10, 64)
+	}
+
+	return cs
+}
+
+// SendMail sends a plain text message from the user's mailbox to each of
+// the recipients.  The message is saved to the user's sent items.
+// Reference: https://learn.microsoft.com/en-us/graph/api/user-sendmail?view=graph-rest-1.0
+func (c Mail) SendMail(
+	ctx context.Context,
+	user string,
+	to []string,
+	subject, body string,
+) error {
+	recipients := make([]models.Recipientable, 0, len(to))
+
+	for _, addr := range to {
+		addr := addr
+
+		ea := models.NewEmailAddress()
+		ea.SetAddress(&addr)
+
+		r := models.NewRecipient()
+		r.SetEmailAddress(ea)
+
+		recipients = append(recipients, r)
+	}
+
+	contentType := models.TEXT_BODYTYPE
+
+	ib := models.NewItemBody()
+	ib.SetContentType(&contentType)
+	ib.SetContent(&body)
+
+	msg := models.NewMessage()
+	msg.SetSubject(&subject)
+	msg.SetBody(ib)
+	msg.SetToRecipients(recipients)
+
+	rb := users.NewItemSendMailPostRequestBody()
+	rb.SetMessage(msg)
+
+	if err := c.stable.Client().UsersById(user).SendMail().Post(ctx, rb, nil); err != nil {
+		return clues.Wrap(err, "sending mail").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return nil
+}
+
 // GetItem retrieves a Messageable item.  If the item contains an attachment, that
 // attachment is also downloaded.
 func (c Mail) GetItem(
@@ -126,11 +233,22 @@ func (c Mail) GetItem(
 	user, itemID string,
 	errs *fault.Errors,
 ) (serialization.Parsable, *details.ExchangeInfo, error) {
-	mail, err := c.stable.Client().UsersById(user).MessagesById(itemID).Get(ctx, nil)
+	itemOpts := &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: fieldsForMailItem,
+		},
+	}
+
+	mail, err := c.stable.Client().UsersById(user).MessagesById(itemID).Get(ctx, itemOpts)
 	if err != nil {
 		return nil, nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
 	}
 
+	// headers only supplement the item info.  They're kept out of the
+	// backed up message, since restores can't recreate them.
+	hdrs := mail.GetInternetMessageHeaders()
+	mail.SetInternetMessageHeaders(nil)
+
 	if *mail.GetHasAttachments() || HasAttachments(mail.GetBody()) {
 		options := &users.ItemMessagesItemAttachmentsRequestBuilderGetRequestConfiguration{
 			QueryParameters: &users.ItemMessagesItemAttachmentsRequestBuilderGetQueryParameters{
@@ -151,7 +269,10 @@ func (c Mail) GetItem(
 		mail.SetAttachments(attached.GetValue())
 	}
 
-	return mail, MailInfo(mail), nil
+	info := MailInfo(mail)
+	addHeaderInfo(info, hdrs)
+
+	return mail, info, nil
 }
 
 // EnumerateContainers iterates through all of the users current
@@ -171,18 +292,16 @@ func (c Mail) EnumerateContainers(
 		return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
 	}
 
-	builder := service.Client().
-		UsersById(userID).
-		MailFolders().
-		Delta()
-
-	for {
-		resp, err := builder.Get(ctx, nil)
-		if err != nil {
-			return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
-		}
+	pager := &mailFolderPager{
+		gs:      service,
+		builder: service.Client().UsersById(userID).MailFolders().Delta(),
+	}
 
-		for _, v := range resp.GetValue() {
+	err = graph.EnumeratePages[models.MailFolderable](
+		ctx,
+		pager,
+		graph.DefaultPagerOptions(),
+		func(v models.MailFolderable) error {
 			fctx := clues.Add(
 				ctx,
 				"container_id", ptr.Val(v.GetId()),
@@ -190,22 +309,42 @@ func (c Mail) EnumerateContainers(
 
 			temp := graph.NewCacheFolder(v, nil, nil)
 			if err := fn(temp); err != nil {
-				errs.Add(clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...))
-				continue
+				return clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...)
 			}
-		}
-
-		link, ok := ptr.ValOK(resp.GetOdataNextLink())
-		if !ok {
-			break
-		}
 
-		builder = users.NewItemMailFoldersDeltaRequestBuilder(link, service.Adapter())
+			return nil
+		},
+		errs)
+	if err != nil {
+		return err
 	}
 
 	return errs.Err()
 }
 
+// ---------------------------------------------------------------------------
+// container pager
+// ---------------------------------------------------------------------------
+
+var _ graph.Pager[models.MailFolderable] = &mailFolderPager{}
+
+type mailFolderPager struct {
+	gs      graph.Servicer
+	builder *users.ItemMailFoldersDeltaRequestBuilder
+}
+
+func (p *mailFolderPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *mailFolderPager) SetNext(nextLink string) {
+	p.builder = users.NewItemMailFoldersDeltaRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *mailFolderPager) ValuesIn(pl api.PageLinker) ([]models.MailFolderable, error) {
+	return graph.PageValues[models.MailFolderable](pl)
+}
+
 // ---------------------------------------------------------------------------
 // item pager
 // ---------------------------------------------------------------------------
@@ -254,7 +393,7 @@ func (c Mail) GetAddedAndRemovedItemIDs(
 		"category", selectors.ExchangeMail,
 		"container_id", directoryID)
 
-	options, err := optionsForFolderMessagesDelta([]string{"isRead"})
+	options, err := optionsForFolderMessagesDelta([]string{"isRead"}, c.deltaPageSize)
 	if err != nil {
 		return nil,
 			nil,
@@ -265,7 +404,7 @@ func (c Mail) GetAddedAndRemovedItemIDs(
 	if len(oldDelta) > 0 {
 		var (
 			builder = users.NewItemMailFoldersItemMessagesDeltaRequestBuilder(oldDelta, service.Adapter())
-			pgr     = &mailPager{service, builder, options}
+			pgr     = c.deltaPager(&mailPager{service, builder, options})
 		)
 
 		added, removed, deltaURL, err := getItemsAddedAndRemovedFromContainer(ctx, pgr)
@@ -291,7 +430,7 @@ func (c Mail) GetAddedAndRemovedItemIDs(
 			logger.Ctx(ctx).Errorw("getting builder info", "error", err)
 		} else {
 			logger.Ctx(ctx).
-				With("user", user, "container", directoryID).
+				With("user", logger.PII(user), "container", directoryID).
 				Warnw("builder path-parameters", "path_parameters", gri.PathParameters)
 		}
 	}
@@ -355,12 +494,72 @@ func MailInfo(msg models.Messageable) *details.ExchangeInfo {
 		sender = ptr.Val(msg.GetSender().GetEmailAddress().GetAddress())
 	}
 
-	return &details.ExchangeInfo{
-		ItemType: details.ExchangeMail,
-		Sender:   sender,
-		Subject:  subject,
-		Received: received,
-		Created:  created,
-		Modified: ptr.OrNow(msg.GetLastModifiedDateTime()),
+	info := &details.ExchangeInfo{
+		ItemType:  details.ExchangeMail,
+		Sender:    sender,
+		Subject:   subject,
+		Received:  received,
+		Created:   created,
+		Modified:  ptr.OrNow(msg.GetLastModifiedDateTime()),
+		MessageID: ptr.Val(msg.GetInternetMessageId()),
 	}
+
+	addHeaderInfo(info, msg.GetInternetMessageHeaders())
+
+	return info
+}
+
+// maxReceivedHops bounds the length of the recorded received chain.
+const maxReceivedHops = 20
+
+// addHeaderInfo records the audit-relevant internet message headers
+// in the item info.
+func addHeaderInfo(info *details.ExchangeInfo, hdrs []models.InternetMessageHeaderable) {
+	for _, h := range hdrs {
+		val := strings.TrimSpace(ptr.Val(h.GetValue()))
+
+		switch strings.ToLower(ptr.Val(h.GetName())) {
+		case "message-id":
+			if len(info.MessageID) == 0 {
+				info.MessageID = val
+			}
+
+		case "in-reply-to":
+			info.InReplyTo = val
+
+		case "received":
+			if len(info.ReceivedChain) < maxReceivedHops {
+				info.ReceivedChain = append(info.ReceivedChain, receivedHop(val))
+			}
+		}
+	}
+}
+
+// receivedHop summarizes a Received header as the hosts that handed off,
+// and accepted, the message.  Eg: "from a.contoso.com by b.contoso.com".
+// Headers without either host are summarized by their leading clause.
+func receivedHop(val string) string {
+	// the timestamp follows the final semicolon.
+	if i := strings.LastIndexByte(val, ';'); i >= 0 {
+		val = val[:i]
+	}
+
+	var (
+		fields = strings.Fields(val)
+		hop    []string
+	)
+
+	for i := 0; i < len(fields)-1; i++ {
+		switch strings.ToLower(fields[i]) {
+		case "from", "by":
+			hop = append(hop, strings.ToLower(fields[i]), fields[i+1])
+			i++
+		}
+	}
+
+	if len(hop) > 0 {
+		return strings.Join(hop, " ")
+	}
+
+	return strings.Join(fields, " ")
 }
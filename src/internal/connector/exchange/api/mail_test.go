@@ -124,6 +124,35 @@ func (suite *MailAPIUnitSuite) TestMailInfo() {
 				return msg, i
 			},
 		},
+		{
+			name: "Internet message headers",
+			msgAndRP: func() (models.Messageable, *details.ExchangeInfo) {
+				msgID := "<id@contoso.com>"
+				msg := models.NewMessage()
+				msg.SetCreatedDateTime(&initial)
+				msg.SetLastModifiedDateTime(&initial)
+				msg.SetInternetMessageId(&msgID)
+				msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{
+					header("Message-ID", "<ignored@contoso.com>"),
+					header("In-Reply-To", "<parent@contoso.com>"),
+					header("Received", "from a.contoso.com (10.0.0.1) by b.contoso.com; Mon, 1 May 2023 10:00:00 +0000"),
+					header("Received", "from c.contoso.com by a.contoso.com; Mon, 1 May 2023 09:59:59 +0000"),
+					header("Subject", "not recorded"),
+				})
+				i := &details.ExchangeInfo{
+					ItemType:  details.ExchangeMail,
+					MessageID: msgID,
+					InReplyTo: "<parent@contoso.com>",
+					ReceivedChain: []string{
+						"from a.contoso.com by b.contoso.com",
+						"from c.contoso.com by a.contoso.com",
+					},
+					Created:  initial,
+					Modified: initial,
+				}
+				return msg, i
+			},
+		},
 	}
 	for _, tt := range tests {
 		suite.Run(tt.name, func() {
@@ -132,3 +161,87 @@ func (suite *MailAPIUnitSuite) TestMailInfo() {
 		})
 	}
 }
+
+func header(name, value string) models.InternetMessageHeaderable {
+	h := models.NewInternetMessageHeader()
+	h.SetName(&name)
+	h.SetValue(&value)
+
+	return h
+}
+
+func (suite *MailAPIUnitSuite) TestReceivedHop() {
+	table := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{
+			name:   "from and by",
+			input:  "from a.contoso.com (10.0.0.1) by b.contoso.com with SMTP id 1; Mon, 1 May 2023 10:00:00 +0000",
+			expect: "from a.contoso.com by b.contoso.com",
+		},
+		{
+			name:   "only by",
+			input:  "by b.contoso.com via Frontend Transport; Mon, 1 May 2023 10:00:00 +0000",
+			expect: "by b.contoso.com",
+		},
+		{
+			name:   "no hosts",
+			input:  "  with   mapi ; Mon, 1 May 2023 10:00:00 +0000",
+			expect: "with mapi",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, receivedHop(test.input))
+		})
+	}
+}
+
+func (suite *MailAPIUnitSuite) TestMailFolderStats() {
+	sizeProp := func(id, value string) models.SingleValueLegacyExtendedPropertyable {
+		prop := models.NewSingleValueLegacyExtendedProperty()
+		prop.SetId(&id)
+		prop.SetValue(&value)
+
+		return prop
+	}
+
+	table := []struct {
+		name   string
+		props  []models.SingleValueLegacyExtendedPropertyable
+		expect ContainerStats
+	}{
+		{
+			name:   "count only",
+			expect: ContainerStats{ItemCount: 42},
+		},
+		{
+			name:   "count and size",
+			props:  []models.SingleValueLegacyExtendedPropertyable{sizeProp("long 0x0e08", "1048576")},
+			expect: ContainerStats{ItemCount: 42, Size: 1048576},
+		},
+		{
+			name:   "unparsable size",
+			props:  []models.SingleValueLegacyExtendedPropertyable{sizeProp(mailFolderSizeProperty, "big")},
+			expect: ContainerStats{ItemCount: 42},
+		},
+		{
+			name:   "other property",
+			props:  []models.SingleValueLegacyExtendedPropertyable{sizeProp("Long 0x0E07", "7")},
+			expect: ContainerStats{ItemCount: 42},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			count := int32(42)
+
+			mf := models.NewMailFolder()
+			mf.SetTotalItemCount(&count)
+			mf.SetSingleValueExtendedProperties(test.props)
+
+			assert.Equal(suite.T(), test.expect, mailFolderStats(mf))
+		})
+	}
+}
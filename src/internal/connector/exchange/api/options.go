@@ -42,6 +42,42 @@ var (
 		"isRead":            {},
 	}
 
+	// fieldsForMailItem selects the message properties graph returns by
+	// default, along with the internet message headers, which graph only
+	// returns when they're selected.
+	fieldsForMailItem = []string{
+		"bccRecipients",
+		"body",
+		"bodyPreview",
+		"categories",
+		"ccRecipients",
+		"changeKey",
+		"conversationId",
+		"conversationIndex",
+		"createdDateTime",
+		"flag",
+		"from",
+		"hasAttachments",
+		"id",
+		"importance",
+		"inferenceClassification",
+		"internetMessageHeaders",
+		"internetMessageId",
+		"isDeliveryReceiptRequested",
+		"isDraft",
+		"isRead",
+		"isReadReceiptRequested",
+		"lastModifiedDateTime",
+		"parentFolderId",
+		"receivedDateTime",
+		"replyTo",
+		"sender",
+		"sentDateTime",
+		"subject",
+		"toRecipients",
+		"webLink",
+	}
+
 	fieldsForContacts = map[string]struct{}{
 		"id":             {},
 		"companyName":    {},
@@ -60,8 +96,9 @@ const (
 	// maxPageSizeHeaderFmt is used to indicate max page size
 	// preferences
 	maxPageSizeHeaderFmt = "odata.maxpagesize=%d"
-	// deltaMaxPageSize is the max page size to use for delta queries
-	deltaMaxPageSize = 200
+	// defaultDeltaPageSize is the max page size to use for delta queries
+	// when no other size is requested.
+	defaultDeltaPageSize = 200
 )
 
 // -----------------------------------------------------------------------
@@ -73,6 +110,7 @@ const (
 
 func optionsForFolderMessagesDelta(
 	moreOps []string,
+	pageSize int,
 ) (*users.ItemMailFoldersItemMessagesDeltaRequestBuilderGetRequestConfiguration, error) {
 	selecting, err := buildOptions(moreOps, fieldsForMessages)
 	if err != nil {
@@ -85,7 +123,7 @@ func optionsForFolderMessagesDelta(
 
 	options := &users.ItemMailFoldersItemMessagesDeltaRequestBuilderGetRequestConfiguration{
 		QueryParameters: requestParameters,
-		Headers:         buildDeltaRequestHeaders(),
+		Headers:         buildDeltaRequestHeaders(pageSize),
 	}
 
 	return options, nil
@@ -198,6 +236,7 @@ func optionsForMailFoldersItem(
 
 func optionsForContactFoldersItemDelta(
 	moreOps []string,
+	pageSize int,
 ) (*users.ItemContactFoldersItemContactsDeltaRequestBuilderGetRequestConfiguration, error) {
 	selecting, err := buildOptions(moreOps, fieldsForContacts)
 	if err != nil {
@@ -210,7 +249,7 @@ func optionsForContactFoldersItemDelta(
 
 	options := &users.ItemContactFoldersItemContactsDeltaRequestBuilderGetRequestConfiguration{
 		QueryParameters: requestParameters,
-		Headers:         buildDeltaRequestHeaders(),
+		Headers:         buildDeltaRequestHeaders(pageSize),
 	}
 
 	return options, nil
@@ -269,10 +308,15 @@ func buildOptions(fields []string, allowed map[string]struct{}) ([]string, error
 	return append(returnedOptions, fields...), nil
 }
 
-// buildDeltaRequestHeaders returns the headers we add to delta page requests
-func buildDeltaRequestHeaders() *abstractions.RequestHeaders {
+// buildDeltaRequestHeaders returns the headers we add to delta page requests.
+// Page sizes less than 1 use the default page size.
+func buildDeltaRequestHeaders(pageSize int) *abstractions.RequestHeaders {
+	if pageSize < 1 {
+		pageSize = defaultDeltaPageSize
+	}
+
 	headers := abstractions.NewRequestHeaders()
-	headers.Add(headerKeyPrefer, fmt.Sprintf(maxPageSizeHeaderFmt, deltaMaxPageSize))
+	headers.Add(headerKeyPrefer, fmt.Sprintf(maxPageSizeHeaderFmt, pageSize))
 
 	return headers
 }
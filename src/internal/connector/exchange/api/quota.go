@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+)
+
+// ErrQuotaUnknown is returned when exchange doesn't report the state of the
+// user's mailbox, such as when the user has no mailbox.
+var ErrQuotaUnknown = clues.New("mailbox quota unknown")
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+func (c Client) Mailboxes() Mailboxes {
+	return Mailboxes{c}
+}
+
+// Mailboxes is an interface-compliant provider of the client.
+type Mailboxes struct {
+	Client
+}
+
+// MailboxQuota describes the state of a mailbox's quota.
+type MailboxQuota struct {
+	// Full is true if the mailbox reached the size at which it stops
+	// accepting mail.
+	Full bool
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// GetQuota retrieves the quota state of the user's mailbox from the user's
+// mail tips.  Returns ErrQuotaUnknown if exchange doesn't report the state.
+// Reference: https://learn.microsoft.com/en-us/graph/api/user-getmailtips
+func (c Mailboxes) GetQuota(ctx context.Context, user string) (*MailboxQuota, error) {
+	service, err := c.service()
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	u, err := service.Client().UsersById(user).Get(ctx, &users.UserItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.UserItemRequestBuilderGetQueryParameters{
+			Select: []string{"mail", "userPrincipalName"},
+		},
+	})
+	if err != nil {
+		return nil, clues.Wrap(err, "getting user").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	addr := ptr.Val(u.GetMail())
+	if len(addr) == 0 {
+		addr = ptr.Val(u.GetUserPrincipalName())
+	}
+
+	opts := models.MAILBOXFULLSTATUS_MAILTIPSTYPE
+
+	body := users.NewItemGetMailTipsPostRequestBody()
+	body.SetEmailAddresses([]string{addr})
+	body.SetMailTipsOptions(&opts)
+
+	resp, err := service.Client().UsersById(user).GetMailTips().Post(ctx, body, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting mail tips").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	mq, err := quotaFromMailTips(resp.GetValue())
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	return mq, nil
+}
+
+// quotaFromMailTips produces the quota described by the mailbox's mail
+// tips.  Returns ErrQuotaUnknown if the tips don't describe the mailbox's
+// state.
+func quotaFromMailTips(tips []models.MailTipsable) (*MailboxQuota, error) {
+	if len(tips) == 0 {
+		return nil, clues.Stack(ErrQuotaUnknown)
+	}
+
+	mt := tips[0]
+
+	if e := mt.GetError(); e != nil {
+		return nil, clues.Stack(ErrQuotaUnknown).With(
+			"mail_tips_error_code", ptr.Val(e.GetCode()),
+			"mail_tips_error_message", ptr.Val(e.GetMessage()))
+	}
+
+	if mt.GetMailboxFull() == nil {
+		return nil, clues.Stack(ErrQuotaUnknown)
+	}
+
+	return &MailboxQuota{Full: ptr.Val(mt.GetMailboxFull())}, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type QuotaUnitSuite struct {
+	tester.Suite
+}
+
+func TestQuotaUnitSuite(t *testing.T) {
+	suite.Run(t, &QuotaUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *QuotaUnitSuite) TestQuotaFromMailTips() {
+	var (
+		full    = true
+		notFull = false
+	)
+
+	mailTips := func(full *bool, errCode string) []models.MailTipsable {
+		mt := models.NewMailTips()
+		mt.SetMailboxFull(full)
+
+		if len(errCode) > 0 {
+			e := models.NewMailTipsError()
+			e.SetCode(&errCode)
+			mt.SetError(e)
+		}
+
+		return []models.MailTipsable{mt}
+	}
+
+	table := []struct {
+		name      string
+		tips      []models.MailTipsable
+		expect    *MailboxQuota
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "full",
+			tips:      mailTips(&full, ""),
+			expect:    &MailboxQuota{Full: true},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "not full",
+			tips:      mailTips(&notFull, ""),
+			expect:    &MailboxQuota{},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "no tips",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "no mailbox status",
+			tips:      mailTips(nil, ""),
+			expectErr: assert.Error,
+		},
+		{
+			name:      "recipient error",
+			tips:      mailTips(&notFull, "ErrorMailRecipientNotFound"),
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			mq, err := quotaFromMailTips(test.tips)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, mq)
+
+			if err != nil {
+				assert.ErrorIs(t, err, ErrQuotaUnknown)
+			}
+		})
+	}
+}
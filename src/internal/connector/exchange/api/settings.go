@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	kioser "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/graph/betasdk"
+	busers "github.com/alcionai/corso/src/internal/connector/graph/betasdk/users"
+)
+
+// MailboxSettingsFileName is the name of the item that holds a user's
+// mailbox settings within the settings category.
+const MailboxSettingsFileName = "mailboxSettings"
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+func (c Client) MailboxSettings() MailboxSettings {
+	return MailboxSettings{c}
+}
+
+// MailboxSettings is an interface-compliant provider of the client.  Only
+// the automatic replies and working hours are handled: the remaining
+// settings (time zone, locale, formats) are tied to the user more than to
+// the mailbox contents, and are left alone on restore.
+type MailboxSettings struct {
+	Client
+}
+
+// betaClient generates a new beta client, which exposes the user's
+// mailboxSettings endpoint.
+func (c MailboxSettings) betaClient() (*betasdk.BetaClient, error) {
+	a, err := graph.CreateAdapter(c.Credentials)
+	if err != nil {
+		return nil, clues.Wrap(err, "generating beta graph adapter")
+	}
+
+	return betasdk.NewBetaClient(a), nil
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// GetMailboxSettings retrieves the user's automatic replies and working hours.
+// Reference: https://learn.microsoft.com/en-us/graph/api/user-get-mailboxsettings?view=graph-rest-beta
+func (c MailboxSettings) GetMailboxSettings(ctx context.Context, user string) (models.MailboxSettingsable, error) {
+	client, err := c.betaClient()
+	if err != nil {
+		return nil, err
+	}
+
+	options := &busers.ItemMailboxSettingsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &busers.ItemMailboxSettingsRequestBuilderGetQueryParameters{
+			Select: []string{"automaticRepliesSetting", "workingHours"},
+		},
+	}
+
+	ms, err := client.UsersById(user).MailboxSettings().Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting mailbox settings").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return ms, nil
+}
+
+// PatchMailboxSettings replaces the user's automatic replies and working
+// hours with those in the settings.  Other settings are ignored.
+// Reference: https://learn.microsoft.com/en-us/graph/api/user-update-mailboxsettings?view=graph-rest-beta
+func (c MailboxSettings) PatchMailboxSettings(
+	ctx context.Context,
+	user string,
+	settings models.MailboxSettingsable,
+) error {
+	client, err := c.betaClient()
+	if err != nil {
+		return err
+	}
+
+	body := models.NewMailboxSettings()
+	body.SetAutomaticRepliesSetting(settings.GetAutomaticRepliesSetting())
+	body.SetWorkingHours(settings.GetWorkingHours())
+
+	_, err = client.UsersById(user).MailboxSettings().Patch(ctx, body, nil)
+	if err != nil {
+		return clues.Wrap(err, "updating mailbox settings").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Serialization
+// ---------------------------------------------------------------------------
+
+// Serialize converts the mailbox settings into a json byte slice.
+func (c MailboxSettings) Serialize(ctx context.Context, settings models.MailboxSettingsable) ([]byte, error) {
+	writer := kioser.NewJsonSerializationWriter()
+	defer writer.Close()
+
+	if err := writer.WriteObjectValue("", settings); err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	bs, err := writer.GetSerializedContent()
+	if err != nil {
+		return nil, clues.Wrap(err, "serializing mailbox settings").WithClues(ctx)
+	}
+
+	return bs, nil
+}
@@ -23,6 +23,25 @@ type itemPager interface {
 	valuesIn(api.DeltaPageLinker) ([]getIDAndAddtler, error)
 }
 
+// expiredDeltaPager fails to get a page as if its delta token expired.
+type expiredDeltaPager struct {
+	itemPager
+}
+
+func (p expiredDeltaPager) getPage(context.Context) (api.DeltaPageLinker, error) {
+	return nil, graph.SimulatedDeltaExpiry()
+}
+
+// deltaPager wraps the pager used to query a previous delta token,
+// expiring the token if the client is set to expire deltas.
+func (c Client) deltaPager(pgr itemPager) itemPager {
+	if c.expireDeltas {
+		return expiredDeltaPager{pgr}
+	}
+
+	return pgr
+}
+
 type getIDAndAddtler interface {
 	GetId() *string
 	GetAdditionalData() map[string]any
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/graph/api"
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type SharedAPIUnitSuite struct {
+	tester.Suite
+}
+
+func TestSharedAPIUnitSuite(t *testing.T) {
+	suite.Run(t, &SharedAPIUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// failingPager fails every page request with the given error.
+type failingPager struct {
+	err error
+}
+
+func (p failingPager) getPage(context.Context) (api.DeltaPageLinker, error) {
+	return nil, p.err
+}
+
+func (p failingPager) setNext(string) {}
+
+func (p failingPager) valuesIn(api.DeltaPageLinker) ([]getIDAndAddtler, error) {
+	return nil, nil
+}
+
+func (suite *SharedAPIUnitSuite) TestDeltaPager() {
+	pgr := failingPager{err: assert.AnError}
+
+	table := []struct {
+		name         string
+		client       Client
+		expectExpiry assert.BoolAssertionFunc
+	}{
+		{
+			name:         "default",
+			client:       Client{},
+			expectExpiry: assert.False,
+		},
+		{
+			name:         "expired deltas",
+			client:       Client{}.WithExpiredDeltas(true),
+			expectExpiry: assert.True,
+		},
+		{
+			name:         "unexpired deltas",
+			client:       Client{}.WithExpiredDeltas(false),
+			expectExpiry: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			_, _, _, err := getItemsAddedAndRemovedFromContainer(ctx, test.client.deltaPager(pgr))
+			assert.Error(t, err)
+			test.expectExpiry(t, graph.IsErrInvalidDelta(err), "delta is invalid")
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	kioser "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/graph/api"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+func (c Client) Tasks() Tasks {
+	return Tasks{Client: c}
+}
+
+// Tasks is an interface-compliant provider of the client.  Tasks are
+// Microsoft To Do tasks, which are grouped into task lists.  To Do tasks
+// are only addressable within their list, so item lookups require a
+// client scoped to the list with InList.
+//
+// Legacy Outlook tasks are read through the same api: To Do lists are the
+// mailbox's task folders, and the Outlook Tasks folder is the list with
+// the defaultList well known name.  Graph's beta outlook/tasks api, which
+// addressed those folders directly, has been retired.
+type Tasks struct {
+	Client
+	listID string
+}
+
+// InList produces a copy of the client whose item lookups are scoped
+// to the task list with the given ID.
+func (c Tasks) InList(listID string) Tasks {
+	c.listID = listID
+	return c
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// CreateTaskList makes a To Do task list with the name in the user's M365 account.
+// Reference: https://learn.microsoft.com/en-us/graph/api/todo-post-lists?view=graph-rest-1.0
+func (c Tasks) CreateTaskList(
+	ctx context.Context,
+	user, listName string,
+) (models.TodoTaskListable, error) {
+	service, err := c.service()
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	requestbody := models.NewTodoTaskList()
+	requestbody.SetDisplayName(&listName)
+
+	mdl, err := service.Client().UsersById(user).Todo().Lists().Post(ctx, requestbody, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating task list").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return mdl, nil
+}
+
+func (c Tasks) GetContainerByID(
+	ctx context.Context,
+	userID, containerID string,
+) (graph.Container, error) {
+	service, err := c.service()
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	options := &users.ItemTodoListsTodoTaskListItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemTodoListsTodoTaskListItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "displayName"},
+		},
+	}
+
+	l, err := service.Client().UsersById(userID).Todo().ListsById(containerID).Get(ctx, options)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return TaskListDisplayable{TodoTaskListable: l}, nil
+}
+
+// GetItem retrieves a TodoTaskable item from the client's task list.
+func (c Tasks) GetItem(
+	ctx context.Context,
+	user, itemID string,
+	errs *fault.Errors,
+) (serialization.Parsable, *details.ExchangeInfo, error) {
+	if len(c.listID) == 0 {
+		return nil, nil, clues.New("task lookup requires a task list").WithClues(ctx)
+	}
+
+	service, err := c.service()
+	if err != nil {
+		return nil, nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	task, err := service.Client().UsersById(user).Todo().ListsById(c.listID).TasksById(itemID).Get(ctx, nil)
+	if err != nil {
+		return nil, nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return task, TaskInfo(task), nil
+}
+
+// EnumerateContainers iterates through all of the users current
+// task lists, converting each to a graph.CacheFolder, and
+// calling fn(cf) on each one.  Task lists have a flat hierarchy,
+// so the baseDirID is ignored.
+func (c Tasks) EnumerateContainers(
+	ctx context.Context,
+	userID, baseDirID string,
+	fn func(graph.CacheFolder) error,
+	errs *fault.Errors,
+) error {
+	service, err := c.service()
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	pager := &taskListPager{
+		gs:      service,
+		builder: service.Client().UsersById(userID).Todo().Lists(),
+		options: &users.ItemTodoListsRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemTodoListsRequestBuilderGetQueryParameters{
+				Select: []string{"id", "displayName"},
+			},
+		},
+	}
+
+	err = graph.EnumeratePages[models.TodoTaskListable](
+		ctx,
+		pager,
+		graph.DefaultPagerOptions(),
+		func(l models.TodoTaskListable) error {
+			fd := TaskListDisplayable{TodoTaskListable: l}
+			if err := checkIDAndName(fd); err != nil {
+				return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+			}
+
+			fctx := clues.Add(
+				ctx,
+				"container_id", ptr.Val(l.GetId()),
+				"container_name", ptr.Val(l.GetDisplayName()))
+
+			temp := graph.NewCacheFolder(
+				fd,
+				path.Builder{}.Append(ptr.Val(fd.GetId())),          // storage path
+				path.Builder{}.Append(ptr.Val(fd.GetDisplayName()))) // display location
+			if err := fn(temp); err != nil {
+				return clues.Stack(err).WithClues(fctx).With(graph.ErrData(err)...)
+			}
+
+			return nil
+		},
+		errs)
+	if err != nil {
+		return err
+	}
+
+	return errs.Err()
+}
+
+func (c Tasks) GetAddedAndRemovedItemIDs(
+	ctx context.Context,
+	user, listID, oldDelta string,
+) ([]string, []string, DeltaUpdate, error) {
+	service, err := c.service()
+	if err != nil {
+		return nil, nil, DeltaUpdate{}, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	var resetDelta bool
+
+	ctx = clues.Add(
+		ctx,
+		"category", selectors.ExchangeTask,
+		"container_id", listID)
+
+	options := &users.ItemTodoListsItemTasksDeltaRequestBuilderGetRequestConfiguration{
+		Headers: buildDeltaRequestHeaders(c.deltaPageSize),
+	}
+
+	if len(oldDelta) > 0 {
+		var (
+			builder = users.NewItemTodoListsItemTasksDeltaRequestBuilder(oldDelta, service.Adapter())
+			pgr     = c.deltaPager(&taskPager{service, builder, options})
+		)
+
+		added, removed, deltaURL, err := getItemsAddedAndRemovedFromContainer(ctx, pgr)
+		// note: happy path, not the error condition
+		if err == nil {
+			return added, removed, DeltaUpdate{deltaURL, false}, err
+		}
+
+		// only return on error if it is NOT a delta issue.
+		// on bad deltas we retry the call with the regular builder
+		if !graph.IsErrInvalidDelta(err) {
+			return nil, nil, DeltaUpdate{}, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+		}
+
+		resetDelta = true
+	}
+
+	builder := service.Client().UsersById(user).Todo().ListsById(listID).Tasks().Delta()
+	pgr := &taskPager{service, builder, options}
+
+	added, removed, deltaURL, err := getItemsAddedAndRemovedFromContainer(ctx, pgr)
+	if err != nil {
+		return nil, nil, DeltaUpdate{}, err
+	}
+
+	return added, removed, DeltaUpdate{deltaURL, resetDelta}, nil
+}
+
+// ---------------------------------------------------------------------------
+// restore
+// ---------------------------------------------------------------------------
+
+// PostTask creates the task within the user's task list.
+// Reference: https://learn.microsoft.com/en-us/graph/api/todotasklist-post-tasks?view=graph-rest-1.0
+func PostTask(
+	ctx context.Context,
+	service graph.Servicer,
+	user, listID string,
+	task models.TodoTaskable,
+) (models.TodoTaskable, error) {
+	resp, err := service.Client().UsersById(user).Todo().ListsById(listID).Tasks().Post(ctx, task, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "posting task").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return resp, nil
+}
+
+// ---------------------------------------------------------------------------
+// pagers
+// ---------------------------------------------------------------------------
+
+var (
+	_ graph.Pager[models.TodoTaskListable] = &taskListPager{}
+	_ itemPager                            = &taskPager{}
+)
+
+type taskListPager struct {
+	gs      graph.Servicer
+	builder *users.ItemTodoListsRequestBuilder
+	options *users.ItemTodoListsRequestBuilderGetRequestConfiguration
+}
+
+func (p *taskListPager) GetPage(ctx context.Context) (api.PageLinker, error) {
+	return p.builder.Get(ctx, p.options)
+}
+
+func (p *taskListPager) SetNext(nextLink string) {
+	p.builder = users.NewItemTodoListsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *taskListPager) ValuesIn(pl api.PageLinker) ([]models.TodoTaskListable, error) {
+	return graph.PageValues[models.TodoTaskListable](pl)
+}
+
+type taskPager struct {
+	gs      graph.Servicer
+	builder *users.ItemTodoListsItemTasksDeltaRequestBuilder
+	options *users.ItemTodoListsItemTasksDeltaRequestBuilderGetRequestConfiguration
+}
+
+func (p *taskPager) getPage(ctx context.Context) (api.DeltaPageLinker, error) {
+	resp, err := p.builder.Get(ctx, p.options)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return resp, nil
+}
+
+func (p *taskPager) setNext(nextLink string) {
+	p.builder = users.NewItemTodoListsItemTasksDeltaRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *taskPager) valuesIn(pl api.DeltaPageLinker) ([]getIDAndAddtler, error) {
+	return toValues[models.TodoTaskable](pl)
+}
+
+// ---------------------------------------------------------------------------
+// Serialization
+// ---------------------------------------------------------------------------
+
+// Serialize transforms the task into a byte slice.
+func (c Tasks) Serialize(
+	ctx context.Context,
+	item serialization.Parsable,
+	user, itemID string,
+) ([]byte, error) {
+	task, ok := item.(models.TodoTaskable)
+	if !ok {
+		return nil, clues.Wrap(fmt.Errorf("parseable type: %T", item), "parsable is not a TodoTaskable")
+	}
+
+	ctx = clues.Add(ctx, "item_id", ptr.Val(task.GetId()))
+
+	var (
+		err    error
+		writer = kioser.NewJsonSerializationWriter()
+	)
+
+	defer writer.Close()
+
+	if err = writer.WriteObjectValue("", task); err != nil {
+		return nil, clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	bs, err := writer.GetSerializedContent()
+	if err != nil {
+		return nil, clues.Wrap(err, "serializing task").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return bs, nil
+}
+
+// ---------------------------------------------------------------------------
+// helper funcs
+// ---------------------------------------------------------------------------
+
+// TaskListDisplayable is a wrapper that complies with the
+// models.TodoTaskListable interface with the graph.Container
+// interfaces. Task lists do not have a parentFolderID.
+// Therefore, that value will always return nil.
+type TaskListDisplayable struct {
+	models.TodoTaskListable
+}
+
+// GetParentFolderId returns nil.  Task lists have a flat hierarchy.
+//
+//nolint:revive
+func (l TaskListDisplayable) GetParentFolderId() *string {
+	return nil
+}
+
+func TaskInfo(task models.TodoTaskable) *details.ExchangeInfo {
+	var (
+		status string
+		due    = time.Time{}
+	)
+
+	if task.GetStatus() != nil {
+		status = task.GetStatus().String()
+	}
+
+	if task.GetDueDateTime() != nil && len(ptr.Val(task.GetDueDateTime().GetDateTime())) > 0 {
+		// timeString has 'Z' literal added to ensure the stored
+		// DateTime is not: time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		dueTime := ptr.Val(task.GetDueDateTime().GetDateTime()) + "Z"
+
+		output, err := common.ParseTime(dueTime)
+		if err == nil {
+			due = output
+		}
+	}
+
+	return &details.ExchangeInfo{
+		ItemType:   details.ExchangeTask,
+		Subject:    ptr.Val(task.GetTitle()),
+		TaskDue:    due,
+		TaskStatus: status,
+		Created:    ptr.Val(task.GetCreatedDateTime()),
+		Modified:   ptr.OrNow(task.GetLastModifiedDateTime()),
+	}
+}
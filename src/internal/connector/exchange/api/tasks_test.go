@@ -0,0 +1,99 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type TasksAPIUnitSuite struct {
+	tester.Suite
+}
+
+func TestTasksAPIUnitSuite(t *testing.T) {
+	suite.Run(t, &TasksAPIUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *TasksAPIUnitSuite) TestTaskInfo() {
+	initial := time.Now()
+
+	tests := []struct {
+		name      string
+		taskAndRP func() (models.TodoTaskable, *details.ExchangeInfo)
+	}{
+		{
+			name: "Empty Task",
+			taskAndRP: func() (models.TodoTaskable, *details.ExchangeInfo) {
+				task := models.NewTodoTask()
+				task.SetCreatedDateTime(&initial)
+				task.SetLastModifiedDateTime(&initial)
+
+				i := &details.ExchangeInfo{
+					ItemType: details.ExchangeTask,
+					Created:  initial,
+					Modified: initial,
+				}
+				return task, i
+			},
+		},
+		{
+			name: "Title, status, and due date",
+			taskAndRP: func() (models.TodoTaskable, *details.ExchangeInfo) {
+				var (
+					subject = "Hello world"
+					status  = models.INPROGRESS_TASKSTATUS
+					dueStr  = "2023-03-01T00:00:00.0000000"
+					due     = time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+					tz      = "UTC"
+					dueDT   = models.NewDateTimeTimeZone()
+					task    = models.NewTodoTask()
+				)
+
+				dueDT.SetDateTime(&dueStr)
+				dueDT.SetTimeZone(&tz)
+
+				task.SetCreatedDateTime(&initial)
+				task.SetLastModifiedDateTime(&initial)
+				task.SetTitle(&subject)
+				task.SetStatus(&status)
+				task.SetDueDateTime(dueDT)
+
+				i := &details.ExchangeInfo{
+					ItemType:   details.ExchangeTask,
+					Subject:    subject,
+					TaskDue:    due,
+					TaskStatus: "inProgress",
+					Created:    initial,
+					Modified:   initial,
+				}
+				return task, i
+			},
+		},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			task, expected := test.taskAndRP()
+			assert.Equal(suite.T(), expected, TaskInfo(task))
+		})
+	}
+}
+
+func (suite *TasksAPIUnitSuite) TestGetItem_requiresList() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	_, _, err := Client{}.Tasks().GetItem(ctx, "user", "item", fault.New(true))
+	assert.Error(t, err, "item lookup outside of a list")
+
+	scoped := Client{}.Tasks().InList("list")
+	assert.Equal(t, "list", scoped.listID)
+}
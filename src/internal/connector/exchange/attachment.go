@@ -52,7 +52,7 @@ func uploadAttachment(
 		ctx,
 		"attachment_size", ptr.Val(attachment.GetSize()),
 		"attachment_id", ptr.Val(attachment.GetId()),
-		"attachment_name", ptr.Val(attachment.GetName()), // TODO: pii
+		"attachment_name", logger.PII(ptr.Val(attachment.GetName())),
 		"attachment_type", attachmentType,
 		"internal_item_type", getItemAttachmentItemType(attachment),
 		"uploader_item_id", uploader.getItemID())
@@ -105,8 +105,8 @@ func uploadLargeAttachment(
 	}
 
 	url := ptr.Val(session.GetUploadUrl())
-	aw := uploadsession.NewWriter(uploader.getItemID(), url, size)
-	logger.Ctx(ctx).Debugw("uploading large attachment", "attachment_url", url) // TODO: url pii
+	aw := uploadsession.NewWriter(ctx, uploader.getItemID(), url, size)
+	logger.Ctx(ctx).Debugw("uploading large attachment", "attachment_url", logger.PII(url))
 
 	// Upload the stream data
 	copyBuffer := make([]byte, attachmentChunkSize)
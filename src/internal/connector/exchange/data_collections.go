@@ -12,6 +12,7 @@ import (
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
@@ -23,8 +24,10 @@ import (
 // store graph metadata such as delta tokens and folderID->path references.
 func MetadataFileNames(cat path.CategoryType) []string {
 	switch cat {
-	case path.EmailCategory, path.ContactsCategory:
+	case path.EmailCategory, path.ContactsCategory, path.TasksCategory:
 		return []string{graph.DeltaURLsFileName, graph.PreviousPathFileName}
+	case path.EventsCategory:
+		return []string{graph.DeltaURLsFileName, graph.PreviousPathFileName, graph.SeriesFingerprintsFileName}
 	default:
 		return []string{graph.PreviousPathFileName}
 	}
@@ -54,9 +57,22 @@ func (dps DeltaPaths) AddPath(k, p string) {
 	dps[k] = dp
 }
 
+func (dps DeltaPaths) AddSeries(k string, s map[string]string) {
+	dp, ok := dps[k]
+	if !ok {
+		dp = DeltaPath{}
+	}
+
+	dp.series = s
+	dps[k] = dp
+}
+
 type DeltaPath struct {
 	delta string
 	path  string
+	// series holds the fingerprints of recurring event series in
+	// the container, keyed by series master ID.
+	series map[string]string
 }
 
 // ParseMetadataCollections produces a map of structs holding delta
@@ -71,6 +87,7 @@ func parseMetadataCollections(
 		path.ContactsCategory: {},
 		path.EmailCategory:    {},
 		path.EventsCategory:   {},
+		path.TasksCategory:    {},
 	}
 
 	// found tracks the metadata we've loaded, to make sure we don't
@@ -79,6 +96,7 @@ func parseMetadataCollections(
 		path.ContactsCategory: {},
 		path.EmailCategory:    {},
 		path.EventsCategory:   {},
+		path.TasksCategory:    {},
 	}
 
 	for _, coll := range colls {
@@ -100,11 +118,17 @@ func parseMetadataCollections(
 				}
 
 				var (
-					m    = map[string]string{}
-					cdps = cdp[category]
+					m        = map[string]string{}
+					sm       = map[string]map[string]string{}
+					into any = &m
+					cdps     = cdp[category]
 				)
 
-				err := json.NewDecoder(item.ToReader()).Decode(&m)
+				if item.UUID() == graph.SeriesFingerprintsFileName {
+					into = &sm
+				}
+
+				err := json.NewDecoder(item.ToReader()).Decode(into)
 				if err != nil {
 					return nil, clues.New("decoding metadata json").WithClues(ctx)
 				}
@@ -131,6 +155,17 @@ func parseMetadataCollections(
 					}
 
 					found[category]["delta"] = struct{}{}
+
+				case graph.SeriesFingerprintsFileName:
+					if _, ok := found[category]["series"]; ok {
+						return nil, clues.Wrap(clues.New(category.String()), "multiple versions of series metadata").WithClues(ctx)
+					}
+
+					for k, fps := range sm {
+						cdps.AddSeries(k, fps)
+					}
+
+					found[category]["series"] = struct{}{}
 				}
 
 				cdp[category] = cdps
@@ -176,11 +211,23 @@ func DataCollections(
 	}
 
 	var (
-		user        = selector.DiscreteOwner
-		collections = []data.BackupCollection{}
-		et          = errs.Tracker()
+		user            = selector.DiscreteOwner
+		collections     = []data.BackupCollection{}
+		et              = errs.Tracker()
+		backsUpSettings bool
 	)
 
+	// structure-only backups record the folder hierarchy in place of the
+	// mailbox's items and settings.
+	if ctrlOpts.StructureOnly {
+		coll, err := createStructureCollection(ctx, acct, user, eb.Scopes(), su, errs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []data.BackupCollection{coll}, nil, nil
+	}
+
 	cdps, err := parseMetadataCollections(ctx, metadata, errs)
 	if err != nil {
 		return nil, nil, err
@@ -206,6 +253,25 @@ func DataCollections(
 		}
 
 		collections = append(collections, dcs...)
+
+		if scope.Category().PathType() == path.EmailCategory {
+			backsUpSettings = true
+		}
+	}
+
+	// mailbox settings ride along with the user's mail.
+	if backsUpSettings && et.Err() == nil {
+		coll, err := createMailboxSettingsCollection(
+			ctx,
+			api.Client{Credentials: acct}.MailboxSettings(),
+			acct.AzureTenantID,
+			user,
+			su)
+		if err != nil {
+			et.Add(err)
+		} else if coll != nil {
+			collections = append(collections, coll)
+		}
 	}
 
 	return collections, nil, et.Err()
@@ -219,6 +285,8 @@ func getterByType(ac api.Client, category path.CategoryType) (addedAndRemovedIte
 		return ac.Events(), nil
 	case path.ContactsCategory:
 		return ac.Contacts(), nil
+	case path.TasksCategory:
+		return ac.Tasks(), nil
 	default:
 		return nil, clues.New("no api client registered for category")
 	}
@@ -239,11 +307,14 @@ func createCollections(
 ) ([]data.BackupCollection, error) {
 	var (
 		allCollections = make([]data.BackupCollection, 0)
-		ac             = api.Client{Credentials: creds}
 		category       = scope.Category().PathType()
 	)
 
-	ctx = clues.Add(ctx, "category", category)
+	ac := api.Client{Credentials: creds}.
+		WithDeltaPageSize(ctrlOpts.Tuning.DeltaPageSize).
+		WithExpiredDeltas(ctrlOpts.ToggleFeatures.SimulateExpiredDeltas)
+
+	ctx = opctx.Set(ctx, opctx.Metadata{Category: category.String()})
 
 	getter, err := getterByType(ac, category)
 	if err != nil {
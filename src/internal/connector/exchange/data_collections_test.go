@@ -195,6 +195,41 @@ func (suite *DataCollectionsUnitSuite) TestParseMetadataCollections() {
 	}
 }
 
+func (suite *DataCollectionsUnitSuite) TestParseMetadataCollections_seriesFingerprints() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	fps := map[string]string{"series1": "fp1"}
+
+	coll, err := graph.MakeMetadataCollection(
+		"t", "u",
+		path.ExchangeService,
+		path.EventsCategory,
+		[]graph.MetadataCollectionEntry{
+			graph.NewMetadataEntry(graph.DeltaURLsFileName, map[string]string{"cal": "delta-link"}),
+			graph.NewMetadataEntry(graph.PreviousPathFileName, map[string]string{"cal": "prev-path"}),
+			graph.NewMetadataEntry(
+				graph.SeriesFingerprintsFileName,
+				map[string]map[string]string{"cal": fps, "no-delta": fps}),
+		},
+		func(cos *support.ConnectorOperationStatus) {},
+	)
+	require.NoError(t, err)
+
+	cdps, err := parseMetadataCollections(ctx, []data.RestoreCollection{
+		data.NotFoundRestoreCollection{Collection: coll},
+	}, fault.New(true))
+	require.NoError(t, err)
+
+	events := cdps[path.EventsCategory]
+	require.Len(t, events, 1, "entries without delta and path are dropped")
+	assert.Equal(t, "delta-link", events["cal"].delta)
+	assert.Equal(t, "prev-path", events["cal"].path)
+	assert.Equal(t, fps, events["cal"].series)
+}
+
 // ---------------------------------------------------------------------------
 // Integration tests
 // ---------------------------------------------------------------------------
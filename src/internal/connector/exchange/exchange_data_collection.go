@@ -7,17 +7,17 @@ import (
 	"bytes"
 	"context"
 	"io"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoft/kiota-abstractions-go/serialization"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/spill"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
@@ -137,6 +137,16 @@ func (col *Collection) FullPath() path.Path {
 	return col.fullPath
 }
 
+// displayFolder produces the collection's folder, using display names
+// when they're known.
+func (col *Collection) displayFolder() string {
+	if col.locationPath != nil {
+		return col.locationPath.Folder(false)
+	}
+
+	return col.fullPath.Folder(false)
+}
+
 // LocationPath produces the Collection's full path, but with display names
 // instead of IDs in the folders.  Only populated for Calendars.
 func (col *Collection) LocationPath() path.Path {
@@ -165,19 +175,12 @@ func (col Collection) DoNotMergeItems() bool {
 // all the M365IDs defined in the added field. data channel is closed by this function
 func (col *Collection) streamItems(ctx context.Context, errs *fault.Errors) {
 	var (
-		success     int64
-		totalBytes  int64
-		wg          sync.WaitGroup
+		results     data.PipelineResults
 		colProgress chan<- struct{}
-
-		user = col.user
-		log  = logger.Ctx(ctx).With(
-			"service", path.ExchangeService.String(),
-			"category", col.category.String())
 	)
 
 	defer func() {
-		col.finishPopulation(ctx, int(success), totalBytes, errs.Err())
+		col.finishPopulation(ctx, results.Successes, results.Bytes, errs.Err())
 	}()
 
 	if len(col.added)+len(col.removed) > 0 {
@@ -185,7 +188,7 @@ func (col *Collection) streamItems(ctx context.Context, errs *fault.Errors) {
 		colProgress, closer = observe.CollectionProgress(
 			ctx,
 			col.fullPath.Category().String(),
-			observe.PII(user),
+			observe.PII(col.user),
 			observe.PII(col.fullPath.Folder(false)))
 
 		go closer()
@@ -195,110 +198,96 @@ func (col *Collection) streamItems(ctx context.Context, errs *fault.Errors) {
 		}()
 	}
 
-	// Limit the max number of active requests to GC
-	semaphoreCh := make(chan struct{}, urlPrefetchChannelBufferSize)
-	defer close(semaphoreCh)
-
-	// delete all removed items
-	for id := range col.removed {
-		semaphoreCh <- struct{}{}
+	opts := data.PipelineOptions{
+		Concurrency: urlPrefetchChannelBufferSize,
+		MaxRetries:  numberOfRetries,
+		IsRetriable: isRetriableItemErr,
+		// Don't report errors for deleted items as there's no way for us to
+		// back up data that is gone.
+		IsSkippable:  graph.IsErrDeletedInFlight,
+		ErrorCode:    graph.ErrorCode,
+		FinalRetries: data.DefaultFinalRetries,
+		Progress:     colProgress,
+		Folder:       col.displayFolder(),
+		Class:        col.category.String(),
+	}.Tuned(col.ctrl.Tuning)
+
+	// Outlook rejects more than 4 concurrent requests per mailbox.
+	if opts.Concurrency > urlPrefetchChannelBufferSize {
+		opts.Concurrency = urlPrefetchChannelBufferSize
+	}
 
-		wg.Add(1)
+	results = data.RunPipeline(
+		ctx,
+		col.data,
+		maps.Keys(col.added),
+		maps.Keys(col.removed),
+		func(ctx context.Context, id string, emit func(data.Stream)) (data.ItemResult, error) {
+			return col.produceItem(ctx, id, emit, errs)
+		},
+		opts,
+		errs)
+}
 
-		go func(id string) {
-			defer wg.Done()
-			defer func() { <-semaphoreCh }()
+// produceItem retrieves and serializes a single item.  Failures that the
+// item recovers from, such as a contact photo that can't be downloaded,
+// are recorded in errs.
+func (col *Collection) produceItem(
+	ctx context.Context,
+	id string,
+	emit func(data.Stream),
+	errs *fault.Errors,
+) (data.ItemResult, error) {
+	itemErrs := fault.New(true) // temporary way to force a failFast error
 
-			col.data <- &Stream{
-				id:      id,
-				modTime: time.Now().UTC(), // removed items have no modTime entry.
-				deleted: true,
-			}
+	item, info, err := col.items.GetItem(ctx, col.user, id, itemErrs)
+	if err != nil {
+		return data.ItemResult{}, clues.Wrap(err, "fetching item")
+	}
 
-			atomic.AddInt64(&success, 1)
-			atomic.AddInt64(&totalBytes, 0)
+	for _, err := range itemErrs.Errs() {
+		errs.Add(err)
+	}
 
-			if colProgress != nil {
-				colProgress <- struct{}{}
-			}
-		}(id)
+	bs, err := col.items.Serialize(ctx, item, col.user, id)
+	if err != nil {
+		return data.ItemResult{}, clues.Wrap(err, "serializing item")
 	}
 
-	// add any new items
-	for id := range col.added {
-		if errs.Err() != nil {
-			break
-		}
-
-		semaphoreCh <- struct{}{}
-
-		wg.Add(1)
-
-		go func(id string) {
-			defer wg.Done()
-			defer func() { <-semaphoreCh }()
-
-			item, info, err := getItemWithRetries(
-				ctx,
-				user,
-				id,
-				col.items,
-				fault.New(true)) // temporary way to force a failFast error
-			if err != nil {
-				// Don't report errors for deleted items as there's no way for us to
-				// back up data that is gone. Record it as a "success", since there's
-				// nothing else we can do, and not reporting it will make the status
-				// investigation upset.
-				if graph.IsErrDeletedInFlight(err) {
-					atomic.AddInt64(&success, 1)
-					log.With("err", err).Infow("item not found", clues.InErr(err).Slice()...)
-				} else {
-					errs.Add(clues.Wrap(err, "fetching item"))
-				}
-
-				return
-			}
-
-			data, err := col.items.Serialize(ctx, item, user, id)
-			if err != nil {
-				errs.Add(clues.Wrap(err, "serializing item"))
-				return
-			}
-
-			info.Size = int64(len(data))
-
-			col.data <- &Stream{
-				id:      id,
-				message: data,
-				info:    info,
-				modTime: info.Modified,
-			}
-
-			atomic.AddInt64(&success, 1)
-			atomic.AddInt64(&totalBytes, info.Size)
-
-			if colProgress != nil {
-				colProgress <- struct{}{}
-			}
-		}(id)
+	info.Size = int64(len(bs))
+
+	// mail folder paths are built from folder IDs, so the last folder
+	// is the ID of the folder that holds the mail.
+	if folders := col.fullPath.Folders(); col.category == path.EmailCategory && len(folders) > 0 {
+		info.FolderID = folders[len(folders)-1]
 	}
 
-	wg.Wait()
-}
+	stream := &Stream{
+		id:      id,
+		message: bs,
+		info:    info,
+		modTime: info.Modified,
+	}
 
-// get an item while handling retry and backoff.
-func getItemWithRetries(
-	ctx context.Context,
-	userID, itemID string,
-	items itemer,
-	errs *fault.Errors,
-) (serialization.Parsable, *details.ExchangeInfo, error) {
-	item, info, err := items.GetItem(ctx, userID, itemID, errs)
+	// hand the item off to disk while it waits in the channel,
+	// if the operation provided a spill buffer.
+	rc, spilled, err := spill.Ctx(ctx).Spill(ctx, bytes.NewReader(bs), info.Size)
 	if err != nil {
-		return nil, nil, err
+		logger.Ctx(ctx).With("err", err).Infow("spilling item to disk", clues.InErr(err).Slice()...)
+	} else if spilled {
+		stream.message = nil
+		stream.spilled = rc
 	}
 
-	return item, info, nil
+	emit(stream)
+
+	return data.ItemResult{Bytes: info.Size}, nil
+}
+
+// isRetriableItemErr identifies item retrieval errors which may succeed
+// when attempted again.
+func isRetriableItemErr(err error) bool {
+	return graph.IsErrTimeout(err) || graph.IsErrThrottled(err) || graph.IsInternalServerError(err)
 }
 
 // terminatePopulateSequence is a utility function used to close a Collection's data channel
@@ -335,6 +324,9 @@ type Stream struct {
 	// going forward. Using []byte for now but I assume we'll have
 	// some structured type in here (serialization to []byte can be done in `Read`)
 	message []byte
+	// spilled, if populated, replaces message as the source of the item's
+	// data while it waits on disk for upload.
+	spilled io.ReadCloser
 	info    *details.ExchangeInfo // temporary change to bring populate function into directory
 	// TODO(ashmrtn): Can probably eventually be sourced from info as there's a
 	// request to provide modtime in ItemInfo structs.
@@ -349,6 +341,10 @@ func (od *Stream) UUID() string {
 }
 
 func (od *Stream) ToReader() io.ReadCloser {
+	if od.spilled != nil {
+		return od.spilled
+	}
+
 	return io.NopCloser(bytes.NewReader(od.message))
 }
 
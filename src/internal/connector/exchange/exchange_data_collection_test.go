@@ -12,6 +12,7 @@ import (
 
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/backup/details"
@@ -25,15 +26,19 @@ type mockItemer struct {
 	serializeCount int
 	getErr         error
 	serializeErr   error
+	// recoveredErr is recorded by GetItem without failing the item.
+	recoveredErr error
 }
 
 func (mi *mockItemer) GetItem(
-	context.Context,
-	string, string,
-	*fault.Errors,
+	_ context.Context,
+	_, _ string,
+	errs *fault.Errors,
 ) (serialization.Parsable, *details.ExchangeInfo, error) {
 	mi.getCount++
-	return nil, nil, mi.getErr
+	errs.Add(mi.recoveredErr)
+
+	return nil, &details.ExchangeInfo{}, mi.getErr
 }
 
 func (mi *mockItemer) Serialize(
@@ -193,28 +198,29 @@ func (suite *ExchangeDataCollectionSuite) TestNewCollection_state() {
 	}
 }
 
-func (suite *ExchangeDataCollectionSuite) TestGetItemWithRetries() {
+func (suite *ExchangeDataCollectionSuite) TestCollection_streamItems() {
+	fooP, err := path.Builder{}.
+		Append("foo").
+		ToDataLayerExchangePathForCategory("t", "u", path.EmailCategory, false)
+	require.NoError(suite.T(), err)
+
 	table := []struct {
 		name           string
 		items          *mockItemer
-		expectErr      func(*testing.T, error)
+		added, removed []string
+		expectErrs     int
+		expectDeleted  int
+		expectStatus   support.CollectionMetrics
 		expectGetCalls int
 	}{
 		{
-			name:  "happy",
-			items: &mockItemer{},
-			expectErr: func(t *testing.T, err error) {
-				assert.NoError(t, err)
-			},
-			expectGetCalls: 1,
-		},
-		{
-			name:  "an error",
-			items: &mockItemer{getErr: assert.AnError},
-			expectErr: func(t *testing.T, err error) {
-				assert.Error(t, err)
-			},
-			expectGetCalls: 3,
+			name:         "an error",
+			items:        &mockItemer{getErr: assert.AnError},
+			added:        []string{"a"},
+			expectErrs:   1,
+			expectStatus: support.CollectionMetrics{Objects: 1},
+			// attempted once more in the final retry.
+			expectGetCalls: 2,
 		},
 		{
 			name: "deleted in flight",
@@ -223,20 +229,134 @@ func (suite *ExchangeDataCollectionSuite) TestGetItemWithRetries() {
 					Err: *common.EncapsulateError(assert.AnError),
 				},
 			},
-			expectErr: func(t *testing.T, err error) {
-				assert.True(t, graph.IsErrDeletedInFlight(err), "is ErrDeletedInFlight")
-			},
+			added:          []string{"a"},
+			expectStatus:   support.CollectionMetrics{Objects: 1, Successes: 1},
 			expectGetCalls: 1,
 		},
+		{
+			name:          "removed",
+			items:         &mockItemer{},
+			removed:       []string{"r1", "r2"},
+			expectDeleted: 2,
+			expectStatus:  support.CollectionMetrics{Objects: 2, Successes: 2},
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
 			ctx, flush := tester.NewContext()
 			defer flush()
 
-			// itemer is mocked, so only the errors are configured atm.
-			_, _, err := getItemWithRetries(ctx, "userID", "itemID", test.items, fault.New(true))
-			test.expectErr(suite.T(), err)
+			var (
+				t        = suite.T()
+				errs     = fault.New(false)
+				statusCh = make(chan *support.ConnectorOperationStatus, 1)
+			)
+
+			c := NewCollection(
+				"u",
+				fooP, nil, nil,
+				path.EmailCategory,
+				test.items,
+				func(s *support.ConnectorOperationStatus) { statusCh <- s },
+				control.Options{},
+				false)
+
+			for _, id := range test.added {
+				c.added[id] = struct{}{}
+			}
+
+			for _, id := range test.removed {
+				c.removed[id] = struct{}{}
+			}
+
+			var deleted int
+
+			for item := range c.Items(ctx, errs) {
+				if item.Deleted() {
+					deleted++
+				}
+			}
+
+			assert.Equal(t, test.expectDeleted, deleted, "deleted items")
+			assert.Len(t, errs.Errs(), test.expectErrs, "recoverable errors")
+			assert.Equal(t, test.expectGetCalls, test.items.getCount, "get calls")
+
+			// the status is reported after the items channel closes.
+			status := <-statusCh
+			assert.Equal(t, test.expectStatus.Objects, status.ObjectCount, "objects")
+			assert.Equal(t, test.expectStatus.Successes, status.Successful, "successes")
 		})
 	}
 }
+
+func (suite *ExchangeDataCollectionSuite) TestCollection_produceItem_folderID() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	table := []struct {
+		category path.CategoryType
+		expect   string
+	}{
+		{path.EmailCategory, "childID"},
+		{path.ContactsCategory, ""},
+	}
+	for _, test := range table {
+		p, err := path.Builder{}.
+			Append("parentID", "childID").
+			ToDataLayerExchangePathForCategory("t", "u", test.category, false)
+		require.NoError(t, err)
+
+		c := NewCollection(
+			"u",
+			p, nil, nil,
+			test.category,
+			&mockItemer{},
+			nil,
+			control.Options{},
+			false)
+
+		var streams []data.Stream
+
+		_, err = c.produceItem(ctx, "item", func(s data.Stream) { streams = append(streams, s) }, fault.New(true))
+		require.NoError(t, err)
+		require.Len(t, streams, 1)
+
+		info := streams[0].(*Stream).Info()
+		require.NotNil(t, info.Exchange)
+		assert.Equal(t, test.expect, info.Exchange.FolderID, test.category.String())
+	}
+}
+
+func (suite *ExchangeDataCollectionSuite) TestProduceItem_recoveredErrors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	p, err := path.Builder{}.
+		Append("parentID").
+		ToDataLayerExchangePathForCategory("t", "u", path.ContactsCategory, false)
+	require.NoError(t, err)
+
+	c := NewCollection(
+		"u",
+		p, nil, nil,
+		path.ContactsCategory,
+		&mockItemer{recoveredErr: assert.AnError},
+		nil,
+		control.Options{},
+		false)
+
+	var (
+		streams []data.Stream
+		errs    = fault.New(false)
+	)
+
+	_, err = c.produceItem(ctx, "item", func(s data.Stream) { streams = append(streams, s) }, errs)
+	require.NoError(t, err, "the item recovers from the failure")
+	assert.Len(t, streams, 1)
+	assert.NoError(t, errs.Err())
+	assert.ErrorIs(t, errs.Errs()[0], assert.AnError)
+}
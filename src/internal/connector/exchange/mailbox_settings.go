@@ -0,0 +1,131 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/opctx"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// mailboxSettingsFolder is the folder, within the settings category, that
+// holds the user's mailbox settings.
+const mailboxSettingsFolder = "mailbox"
+
+type mailboxSettingsGetter interface {
+	GetMailboxSettings(ctx context.Context, user string) (models.MailboxSettingsable, error)
+	Serialize(ctx context.Context, settings models.MailboxSettingsable) ([]byte, error)
+}
+
+type mailboxSettingsPatcher interface {
+	PatchMailboxSettings(ctx context.Context, user string, settings models.MailboxSettingsable) error
+}
+
+// MailboxSettingsPath produces the path of the item holding the user's
+// mailbox settings.
+func MailboxSettingsPath(tenant, user string) (path.Path, error) {
+	return path.Builder{}.
+		Append(mailboxSettingsFolder, api.MailboxSettingsFileName).
+		ToDataLayerExchangePathForCategory(tenant, user, path.SettingsCategory, true)
+}
+
+// createMailboxSettingsCollection produces a collection holding the user's
+// automatic replies and working hours.  The settings aren't added to the
+// backup details, so they're only restored when a restore opts in to them.
+// Returns a nil collection if the settings can't be accessed.
+func createMailboxSettingsCollection(
+	ctx context.Context,
+	msg mailboxSettingsGetter,
+	tenant, user string,
+	su support.StatusUpdater,
+) (data.BackupCollection, error) {
+	ctx = opctx.Set(ctx, opctx.Metadata{Category: path.SettingsCategory.String()})
+
+	ms, err := msg.GetMailboxSettings(ctx, user)
+	if err != nil {
+		// the settings are secondary to the mailbox contents, and need an
+		// additional permission, so lacking access doesn't fail the backup.
+		if graph.IsErrAccessDenied(err) {
+			logger.Ctx(ctx).With("err", err).Infow("skipping mailbox settings backup", clues.InErr(err).Slice()...)
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	bs, err := msg.Serialize(ctx, ms)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := MailboxSettingsPath(tenant, user)
+	if err != nil {
+		return nil, clues.Wrap(err, "making mailbox settings path").WithClues(ctx)
+	}
+
+	dir, err := p.Dir()
+	if err != nil {
+		return nil, clues.Wrap(err, "making mailbox settings path").WithClues(ctx)
+	}
+
+	return graph.NewMetadataCollection(
+		dir,
+		[]graph.MetadataItem{graph.NewMetadataItem(p.Item(), bs)},
+		su), nil
+}
+
+// restoreMailboxSettings replaces the user's automatic replies and working
+// hours with the backed up settings.
+func restoreMailboxSettings(
+	ctx context.Context,
+	msp mailboxSettingsPatcher,
+	dc data.RestoreCollection,
+	errs *fault.Errors,
+) support.CollectionMetrics {
+	var (
+		metrics support.CollectionMetrics
+		user    = dc.FullPath().ResourceOwner()
+	)
+
+	ctx = opctx.Set(ctx, opctx.Metadata{Category: path.SettingsCategory.String()})
+
+	for item := range dc.Items(ctx, errs) {
+		if errs.Err() != nil {
+			break
+		}
+
+		metrics.Objects++
+
+		buf := &bytes.Buffer{}
+
+		if _, err := buf.ReadFrom(item.ToReader()); err != nil {
+			errs.Add(clues.Wrap(err, "reading mailbox settings").WithClues(ctx))
+			continue
+		}
+
+		ms, err := support.CreateMailboxSettingsFromBytes(buf.Bytes())
+		if err != nil {
+			errs.Add(clues.Wrap(err, "parsing mailbox settings").WithClues(ctx))
+			continue
+		}
+
+		if err := msp.PatchMailboxSettings(ctx, user, ms); err != nil {
+			errs.Add(err)
+			continue
+		}
+
+		metrics.Successes++
+		metrics.TotalBytes += int64(buf.Len())
+	}
+
+	return metrics
+}
@@ -0,0 +1,248 @@
+package exchange
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type mockMailboxSettings struct {
+	settings models.MailboxSettingsable
+	err      error
+
+	patchedUser string
+	patched     models.MailboxSettingsable
+}
+
+func (m *mockMailboxSettings) GetMailboxSettings(context.Context, string) (models.MailboxSettingsable, error) {
+	return m.settings, m.err
+}
+
+func (m *mockMailboxSettings) Serialize(ctx context.Context, ms models.MailboxSettingsable) ([]byte, error) {
+	return api.MailboxSettings{}.Serialize(ctx, ms)
+}
+
+func (m *mockMailboxSettings) PatchMailboxSettings(
+	_ context.Context,
+	user string,
+	ms models.MailboxSettingsable,
+) error {
+	m.patchedUser = user
+	m.patched = ms
+
+	return m.err
+}
+
+func mockSettings() models.MailboxSettingsable {
+	var (
+		status  = models.SCHEDULED_AUTOMATICREPLIESSTATUS
+		message = "out of office"
+		zone    = "Pacific Standard Time"
+		ms      = models.NewMailboxSettings()
+		ars     = models.NewAutomaticRepliesSetting()
+		wh      = models.NewWorkingHours()
+		tz      = models.NewTimeZoneBase()
+	)
+
+	ars.SetStatus(&status)
+	ars.SetInternalReplyMessage(&message)
+	tz.SetName(&zone)
+	wh.SetTimeZone(tz)
+	wh.SetDaysOfWeek([]models.DayOfWeek{models.MONDAY_DAYOFWEEK, models.FRIDAY_DAYOFWEEK})
+	ms.SetAutomaticRepliesSetting(ars)
+	ms.SetWorkingHours(wh)
+
+	return ms
+}
+
+func accessDeniedErr() error {
+	var (
+		code  = "accessDenied"
+		odErr = &odataerrors.ODataError{}
+		merr  = odataerrors.MainError{}
+	)
+
+	merr.SetCode(&code)
+	odErr.SetError(&merr)
+
+	return odErr
+}
+
+type MailboxSettingsUnitSuite struct {
+	tester.Suite
+}
+
+func TestMailboxSettingsUnitSuite(t *testing.T) {
+	suite.Run(t, &MailboxSettingsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *MailboxSettingsUnitSuite) TestCreateMailboxSettingsCollection() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		statuses = 0
+		su       = func(*support.ConnectorOperationStatus) { statuses++ }
+	)
+
+	coll, err := createMailboxSettingsCollection(ctx, &mockMailboxSettings{settings: mockSettings()}, "tid", "uid", su)
+	require.NoError(t, err)
+	require.NotNil(t, coll)
+
+	p, err := MailboxSettingsPath("tid", "uid")
+	require.NoError(t, err)
+
+	assert.Equal(t, path.SettingsCategory, coll.FullPath().Category())
+	assert.Equal(t, p.ToBuilder().Dir().String(), coll.FullPath().ToBuilder().String())
+
+	items := []data.Stream{}
+	for item := range coll.Items(ctx, fault.New(true)) {
+		items = append(items, item)
+	}
+
+	require.Len(t, items, 1)
+	assert.Equal(t, api.MailboxSettingsFileName, items[0].UUID())
+
+	_, ok := items[0].(data.StreamInfo)
+	assert.False(t, ok, "settings are kept out of backup details")
+
+	bs, err := io.ReadAll(items[0].ToReader())
+	require.NoError(t, err)
+
+	ms, err := support.CreateMailboxSettingsFromBytes(bs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "out of office", ptr.Val(ms.GetAutomaticRepliesSetting().GetInternalReplyMessage()))
+	assert.Equal(t, "Pacific Standard Time", ptr.Val(ms.GetWorkingHours().GetTimeZone().GetName()))
+	assert.Equal(t, 1, statuses)
+}
+
+func (suite *MailboxSettingsUnitSuite) TestCreateMailboxSettingsCollection_errors() {
+	table := []struct {
+		name      string
+		err       error
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "access denied is skipped",
+			err:       clues.Stack(accessDeniedErr()),
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "other errors",
+			err:       assert.AnError,
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			coll, err := createMailboxSettingsCollection(
+				ctx,
+				&mockMailboxSettings{err: test.err},
+				"tid", "uid",
+				func(*support.ConnectorOperationStatus) {})
+			test.expectErr(t, err)
+			assert.Nil(t, coll)
+		})
+	}
+}
+
+func (suite *MailboxSettingsUnitSuite) TestRestoreMailboxSettings() {
+	p, err := MailboxSettingsPath("tid", "uid")
+	require.NoError(suite.T(), err)
+
+	dir, err := p.Dir()
+	require.NoError(suite.T(), err)
+
+	bs, err := api.MailboxSettings{}.Serialize(context.Background(), mockSettings())
+	require.NoError(suite.T(), err)
+
+	table := []struct {
+		name            string
+		data            []byte
+		patchErr        error
+		expectPatched   bool
+		expectSuccesses int
+		expectErr       assert.ErrorAssertionFunc
+	}{
+		{
+			name:            "restored",
+			data:            bs,
+			expectPatched:   true,
+			expectSuccesses: 1,
+			expectErr:       assert.NoError,
+		},
+		{
+			name:      "malformed settings",
+			data:      []byte("snarf"),
+			expectErr: assert.Error,
+		},
+		{
+			name:          "patch failure",
+			data:          bs,
+			patchErr:      assert.AnError,
+			expectPatched: true,
+			expectErr:     assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t    = suite.T()
+				msp  = &mockMailboxSettings{err: test.patchErr}
+				mc   = mockconnector.NewMockExchangeCollection(dir, nil, 1)
+				errs = fault.New(false)
+			)
+
+			mc.Data[0] = test.data
+
+			metrics := restoreMailboxSettings(ctx, msp, data.NotFoundRestoreCollection{Collection: mc}, errs)
+			assert.Equal(t, 1, metrics.Objects)
+			assert.Equal(t, test.expectSuccesses, metrics.Successes)
+
+			var errored error
+			if len(errs.Errs()) > 0 {
+				errored = errs.Errs()[0]
+			}
+
+			test.expectErr(t, errored)
+
+			if !test.expectPatched {
+				assert.Nil(t, msp.patched)
+				return
+			}
+
+			require.NotNil(t, msp.patched)
+			assert.Equal(t, "uid", msp.patchedUser)
+			assert.Equal(
+				t,
+				models.SCHEDULED_AUTOMATICREPLIESSTATUS,
+				ptr.Val(msp.patched.GetAutomaticRepliesSetting().GetStatus()))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alcionai/clues"
+	"github.com/dustin/go-humanize"
+
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// largeMailRestoreBytes is the size of restored mail at which the
+// destination mailbox's quota is checked before restoring.
+const largeMailRestoreBytes = 100 * humanize.MiByte
+
+// ErrMailboxQuotaExceeded is returned by the restore preflight when the
+// destination mailbox is full.
+var ErrMailboxQuotaExceeded = clues.New("restored mail would exceed the mailbox quota")
+
+type mailboxQuotaGetter interface {
+	GetQuota(ctx context.Context, user string) (*api.MailboxQuota, error)
+}
+
+// RestorePreflight checks that the destination mailbox can accept the
+// mail in the restored entries.  Small restores aren't checked.  Returns a
+// warning if the mailbox is full, or, if the options fail over-quota
+// restores, ErrMailboxQuotaExceeded.  Quotas that can't be retrieved also
+// produce a warning, so that the user learns the check was skipped.
+func RestorePreflight(
+	ctx context.Context,
+	creds account.M365Config,
+	user string,
+	entries []details.DetailsEntry,
+	opts control.MailRestoreOptions,
+) (string, error) {
+	return restorePreflight(ctx, api.Client{Credentials: creds}.Mailboxes(), user, mailBytes(entries), opts)
+}
+
+func restorePreflight(
+	ctx context.Context,
+	qg mailboxQuotaGetter,
+	user string,
+	restoreBytes int64,
+	opts control.MailRestoreOptions,
+) (string, error) {
+	if opts.SkipQuotaCheck || restoreBytes < largeMailRestoreBytes {
+		return "", nil
+	}
+
+	ctx = clues.Add(ctx, "restore_bytes", restoreBytes)
+
+	mq, err := qg.GetQuota(ctx, user)
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Warnw("skipping mailbox quota check", clues.InErr(err).Slice()...)
+
+		return fmt.Sprintf(
+			"the destination mailbox's quota is unknown; restoring %s of mail without checking it",
+			humanize.Bytes(uint64(restoreBytes))), nil
+	}
+
+	if !mq.Full {
+		return "", nil
+	}
+
+	if opts.FailOverQuota {
+		return "", clues.Stack(ErrMailboxQuotaExceeded).WithClues(ctx)
+	}
+
+	return fmt.Sprintf(
+		"the destination mailbox is full, and may not accept the %s of restored mail",
+		humanize.Bytes(uint64(restoreBytes))), nil
+}
+
+// mailBytes sums the size of the mail in the entries.
+func mailBytes(entries []details.DetailsEntry) int64 {
+	var size int64
+
+	for _, ent := range entries {
+		if ent.Exchange != nil && ent.Exchange.ItemType == details.ExchangeMail {
+			size += ent.Exchange.Size
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,132 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alcionai/clues"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type mockQuotaGetter struct {
+	mq    *api.MailboxQuota
+	err   error
+	calls int
+}
+
+func (m *mockQuotaGetter) GetQuota(context.Context, string) (*api.MailboxQuota, error) {
+	m.calls++
+	return m.mq, m.err
+}
+
+type RestorePreflightUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestorePreflightUnitSuite(t *testing.T) {
+	suite.Run(t, &RestorePreflightUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestorePreflightUnitSuite) TestRestorePreflight() {
+	var (
+		large = int64(largeMailRestoreBytes)
+		full  = &api.MailboxQuota{Full: true}
+		roomy = &api.MailboxQuota{}
+	)
+
+	table := []struct {
+		name        string
+		bytes       int64
+		qg          *mockQuotaGetter
+		opts        control.MailRestoreOptions
+		expectCalls int
+		expectWarn  assert.ValueAssertionFunc
+		expectErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name:        "small restore",
+			bytes:       large - 1,
+			qg:          &mockQuotaGetter{mq: full},
+			expectCalls: 0,
+			expectWarn:  assert.Empty,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "skipped",
+			bytes:       large,
+			qg:          &mockQuotaGetter{mq: full},
+			opts:        control.MailRestoreOptions{SkipQuotaCheck: true},
+			expectCalls: 0,
+			expectWarn:  assert.Empty,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "fits",
+			bytes:       large,
+			qg:          &mockQuotaGetter{mq: roomy},
+			expectCalls: 1,
+			expectWarn:  assert.Empty,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "mailbox full",
+			bytes:       large,
+			qg:          &mockQuotaGetter{mq: full},
+			expectCalls: 1,
+			expectWarn:  assert.NotEmpty,
+			expectErr:   assert.NoError,
+		},
+		{
+			name:        "mailbox full, fails",
+			bytes:       large,
+			qg:          &mockQuotaGetter{mq: full},
+			opts:        control.MailRestoreOptions{FailOverQuota: true},
+			expectCalls: 1,
+			expectWarn:  assert.Empty,
+			expectErr:   assert.Error,
+		},
+		{
+			name:        "quota unknown",
+			bytes:       large,
+			qg:          &mockQuotaGetter{err: clues.Stack(api.ErrQuotaUnknown)},
+			opts:        control.MailRestoreOptions{FailOverQuota: true},
+			expectCalls: 1,
+			expectWarn:  assert.NotEmpty,
+			expectErr:   assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			warn, err := restorePreflight(ctx, test.qg, "user", test.bytes, test.opts)
+			test.expectErr(t, err)
+			test.expectWarn(t, warn)
+			assert.Equal(t, test.expectCalls, test.qg.calls)
+
+			if err != nil {
+				assert.ErrorIs(t, err, ErrMailboxQuotaExceeded)
+			}
+		})
+	}
+}
+
+func (suite *RestorePreflightUnitSuite) TestMailBytes() {
+	ents := []details.DetailsEntry{
+		{ItemInfo: details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail, Size: 10}}},
+		{ItemInfo: details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeEvent, Size: 20}}},
+		{ItemInfo: details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail, Size: 30}}},
+		{ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{Size: 40}}},
+	}
+
+	assert.Equal(suite.T(), int64(40), mailBytes(ents))
+}
@@ -50,7 +50,7 @@ func (suite *ExchangeRestoreSuite) SetupSuite() {
 	suite.ac, err = api.NewClient(m365)
 	require.NoError(t, err)
 
-	adpt, err := graph.CreateAdapter(m365.AzureTenantID, m365.AzureClientID, m365.AzureClientSecret)
+	adpt, err := graph.CreateAdapter(m365)
 	require.NoError(t, err)
 
 	suite.gs = graph.NewService(adpt)
@@ -88,11 +88,58 @@ func (suite *ExchangeRestoreSuite) TestRestoreContact() {
 		suite.gs,
 		control.Copy,
 		folderID,
-		userID)
+		userID,
+		fault.New(true))
 	assert.NoError(t, err, support.ConnectorStackErrorTrace(err))
 	assert.NotNil(t, info, "contact item info")
 }
 
+// TestRestoreContact_collisions verifies that the skip and replace
+// policies don't multiply copies of a contact that was already restored.
+func (suite *ExchangeRestoreSuite) TestRestoreContact_collisions() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t          = suite.T()
+		userID     = tester.M365UserID(t)
+		bytes      = mockconnector.GetMockContactBytes("Restore Collisions")
+		folderName = "TestRestoreContactCollisions: " + common.FormatSimpleDateTime(time.Now())
+	)
+
+	folder, err := suite.ac.Contacts().CreateContactFolder(ctx, userID, folderName)
+	require.NoError(t, err)
+
+	folderID := *folder.GetId()
+
+	defer func() {
+		assert.NoError(t, suite.ac.Contacts().DeleteContainer(ctx, userID, folderID))
+	}()
+
+	countContacts := func() int {
+		resp, err := suite.gs.Client().UsersById(userID).ContactFoldersById(folderID).Contacts().Get(ctx, nil)
+		require.NoError(t, err)
+
+		return len(resp.GetValue())
+	}
+
+	table := []struct {
+		policy control.CollisionPolicy
+		expect int
+	}{
+		{control.Copy, 1},
+		{control.Skip, 1},
+		{control.Replace, 1},
+		{control.Copy, 2},
+	}
+	for _, test := range table {
+		info, err := RestoreExchangeContact(ctx, bytes, suite.gs, test.policy, folderID, userID, fault.New(true))
+		require.NoError(t, err, test.policy.String(), support.ConnectorStackErrorTrace(err))
+		assert.NotNil(t, info, test.policy.String())
+		assert.Equal(t, test.expect, countContacts(), test.policy.String())
+	}
+}
+
 // TestRestoreEvent verifies that event object is able to created
 // and sent into the test account of the Corso user in the newly created Corso Calendar
 func (suite *ExchangeRestoreSuite) TestRestoreEvent() {
@@ -359,3 +406,52 @@ func (suite *ExchangeRestoreSuite) TestRestoreExchangeObject() {
 		})
 	}
 }
+
+// TestRestoreMailMessage_collisions verifies that the skip and replace
+// policies don't multiply copies of a message that was already restored.
+func (suite *ExchangeRestoreSuite) TestRestoreMailMessage_collisions() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t          = suite.T()
+		userID     = tester.M365UserID(t)
+		bytes      = mockconnector.GetMockMessageBytes("Restore Collisions")
+		folderName = "TestRestoreMailCollisions: " + common.FormatSimpleDateTime(time.Now())
+	)
+
+	service, err := createService(suite.credentials)
+	require.NoError(t, err)
+
+	folder, err := suite.ac.Mail().CreateMailFolder(ctx, userID, folderName)
+	require.NoError(t, err)
+
+	folderID := *folder.GetId()
+
+	defer func() {
+		assert.NoError(t, suite.ac.Mail().DeleteContainer(ctx, userID, folderID))
+	}()
+
+	countMessages := func() int {
+		resp, err := service.Client().UsersById(userID).MailFoldersById(folderID).Messages().Get(ctx, nil)
+		require.NoError(t, err)
+
+		return len(resp.GetValue())
+	}
+
+	table := []struct {
+		policy control.CollisionPolicy
+		expect int
+	}{
+		{control.Copy, 1},
+		{control.Skip, 1},
+		{control.Replace, 1},
+		{control.Copy, 2},
+	}
+	for _, test := range table {
+		info, err := RestoreMailMessage(ctx, bytes, service, test.policy, folderID, userID, fault.New(true))
+		require.NoError(t, err, test.policy.String(), support.ConnectorStackErrorTrace(err))
+		assert.NotNil(t, info, test.policy.String())
+		assert.Equal(t, test.expect, countMessages(), test.policy.String())
+	}
+}
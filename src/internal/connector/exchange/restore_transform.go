@@ -0,0 +1,88 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	kioser "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/transform"
+)
+
+// transformItem applies the context's transform hook to the serialized
+// item, producing the serialized item to restore.  Contacts are restored
+// unchanged.
+func transformItem(ctx context.Context, bits []byte, category path.CategoryType) ([]byte, error) {
+	var (
+		hook          = transform.Ctx(ctx)
+		noAttachments = false
+		item          serialization.Parsable
+	)
+
+	switch category {
+	case path.EmailCategory:
+		msg, err := support.CreateMessageFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating mail from bytes").WithClues(ctx)
+		}
+
+		subject := hook.RestoreName(ptr.Val(msg.GetSubject()))
+		msg.SetSubject(&subject)
+
+		if !hook.KeepAttachments() {
+			msg.SetAttachments([]models.Attachmentable{})
+			msg.SetHasAttachments(&noAttachments)
+		}
+
+		item = msg
+
+	case path.EventsCategory:
+		evt, err := support.CreateEventFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating event from bytes").WithClues(ctx)
+		}
+
+		subject := hook.RestoreName(ptr.Val(evt.GetSubject()))
+		evt.SetSubject(&subject)
+
+		if !hook.KeepAttachments() {
+			evt.SetAttachments([]models.Attachmentable{})
+			evt.SetHasAttachments(&noAttachments)
+		}
+
+		item = evt
+
+	case path.TasksCategory:
+		task, err := support.CreateTaskFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating task from bytes").WithClues(ctx)
+		}
+
+		title := hook.RestoreName(ptr.Val(task.GetTitle()))
+		task.SetTitle(&title)
+
+		item = task
+
+	default:
+		return bits, nil
+	}
+
+	writer := kioser.NewJsonSerializationWriter()
+	defer writer.Close()
+
+	if err := writer.WriteObjectValue("", item); err != nil {
+		return nil, clues.Wrap(err, "serializing transformed item").WithClues(ctx)
+	}
+
+	bs, err := writer.GetSerializedContent()
+	if err != nil {
+		return nil, clues.Wrap(err, "serializing transformed item").WithClues(ctx)
+	}
+
+	return bs, nil
+}
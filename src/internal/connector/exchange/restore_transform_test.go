@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/transform"
+)
+
+type RestoreTransformUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreTransformUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreTransformUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestoreTransformUnitSuite) TestTransformItem_mail() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	ctx = transform.Set(ctx, &transform.Rules{NamePrefix: "[old] ", DropAttachments: true})
+
+	bs, err := transformItem(ctx, mockconnector.GetMockMessageWithDirectAttachment("subject"), path.EmailCategory)
+	require.NoError(t, err)
+
+	msg, err := support.CreateMessageFromBytes(bs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[old] subject", ptr.Val(msg.GetSubject()))
+	assert.Empty(t, msg.GetAttachments())
+	assert.False(t, ptr.Val(msg.GetHasAttachments()))
+}
+
+func (suite *RestoreTransformUnitSuite) TestTransformItem_event() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	ctx = transform.Set(ctx, &transform.Rules{NamePrefix: "[old] "})
+
+	bs, err := transformItem(ctx, mockconnector.GetMockEventWithAttachment("subject"), path.EventsCategory)
+	require.NoError(t, err)
+
+	evt, err := support.CreateEventFromBytes(bs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[old] subject", ptr.Val(evt.GetSubject()))
+	assert.NotEmpty(t, evt.GetAttachments(), "attachments are kept")
+}
+
+func (suite *RestoreTransformUnitSuite) TestTransformItem_contact() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	ctx = transform.Set(ctx, &transform.Rules{NamePrefix: "[old] "})
+
+	contact := mockconnector.GetMockContactBytes("name")
+
+	bs, err := transformItem(ctx, contact, path.ContactsCategory)
+	require.NoError(t, err)
+	assert.Equal(t, contact, bs, "contacts are unchanged")
+}
@@ -0,0 +1,450 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/transform"
+)
+
+// itemRestorer creates the restore destinations and the backed up items
+// within a user's mailbox.  The destination is the ID of the container
+// that receives the item.
+type itemRestorer interface {
+	// CreateDestination builds the destination into the container at the
+	// directory, and produces the ID of the container that receives the
+	// directory's items.
+	CreateDestination(
+		ctx context.Context,
+		directory path.Path,
+		destination string,
+		caches map[path.CategoryType]graph.ContainerResolver,
+		errs *fault.Errors,
+	) (string, error)
+	RestoreItem(
+		ctx context.Context,
+		bits []byte,
+		category path.CategoryType,
+		policy control.CollisionPolicy,
+		destination, user string,
+		errs *fault.Errors,
+	) (*details.ExchangeInfo, error)
+}
+
+// newItemRestorer produces the restorer used by an Exchange restore.  Items
+// are created through graph, unless the options enable the ews fallback.
+func newItemRestorer(
+	creds account.M365Config,
+	gs graph.Servicer,
+	opts control.EWSOptions,
+) (itemRestorer, error) {
+	gr := graphRestorer{creds: creds, service: gs}
+
+	if !opts.Fallback {
+		return gr, nil
+	}
+
+	ews, err := api.NewEWS(creds, opts.Endpoint)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating ews client")
+	}
+
+	return &ewsFallbackRestorer{
+		primary:   gr,
+		ews:       ews,
+		mailbox:   graphMailboxAddress(gs),
+		addresses: map[string]string{},
+		folders:   map[string]string{},
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// graph
+// ---------------------------------------------------------------------------
+
+var _ itemRestorer = graphRestorer{}
+
+// graphRestorer creates containers and items through graph.
+type graphRestorer struct {
+	creds   account.M365Config
+	service graph.Servicer
+}
+
+func (gr graphRestorer) CreateDestination(
+	ctx context.Context,
+	directory path.Path,
+	destination string,
+	caches map[path.CategoryType]graph.ContainerResolver,
+	errs *fault.Errors,
+) (string, error) {
+	return CreateContainerDestination(ctx, gr.creds, directory, destination, caches, errs)
+}
+
+func (gr graphRestorer) RestoreItem(
+	ctx context.Context,
+	bits []byte,
+	category path.CategoryType,
+	policy control.CollisionPolicy,
+	destination, user string,
+	errs *fault.Errors,
+) (*details.ExchangeInfo, error) {
+	return RestoreExchangeObject(ctx, bits, category, policy, gr.service, destination, user, errs)
+}
+
+// ---------------------------------------------------------------------------
+// ews fallback
+// ---------------------------------------------------------------------------
+
+type ewsItemCreator interface {
+	EnsureFolder(ctx context.Context, mailbox, parentID, name, folderClass string) (string, error)
+	CreateMessage(ctx context.Context, mailbox, folderID string, msg models.Messageable) error
+	CreateContact(ctx context.Context, mailbox, folderID string, contact models.Contactable) error
+	CreateEvent(ctx context.Context, mailbox, calendarID string, event models.Eventable) error
+}
+
+// mailboxAddresser produces the smtp address of the user's mailbox, which
+// ews uses to route and authorize requests.
+type mailboxAddresser func(ctx context.Context, user string) (string, error)
+
+var _ itemRestorer = &ewsFallbackRestorer{}
+
+// ewsFallbackRestorer creates containers and items through the primary
+// restorer, and switches the user's remaining containers and items over
+// to ews once graph reports that the user's mailbox is hosted where graph
+// can't write.  Only mail, contacts, and events are supported by ews.  Ews
+// always restores copies: the skip and replace collision policies aren't
+// applied.
+type ewsFallbackRestorer struct {
+	primary itemRestorer
+	ews     ewsItemCreator
+	mailbox mailboxAddresser
+
+	mu sync.Mutex
+	// smtp addresses of the users whose items are restored through ews.
+	addresses map[string]string
+	// ews IDs of the folders created through ews, keyed by mailbox,
+	// category, and folder path.
+	folders map[string]string
+}
+
+// ewsContainers holds the distinguished folder that roots each category's
+// restore destination, and the class of the folders created beneath it.
+var ewsContainers = map[path.CategoryType]struct {
+	root, class string
+}{
+	path.EmailCategory:    {root: api.EWSMailRoot, class: "IPF.Note"},
+	path.ContactsCategory: {root: api.EWSContactsRoot, class: "IPF.Contact"},
+	path.EventsCategory:   {root: api.EWSCalendarRoot, class: "IPF.Appointment"},
+}
+
+func (r *ewsFallbackRestorer) CreateDestination(
+	ctx context.Context,
+	directory path.Path,
+	destination string,
+	caches map[path.CategoryType]graph.ContainerResolver,
+	errs *fault.Errors,
+) (string, error) {
+	var (
+		user     = directory.ResourceOwner()
+		category = directory.Category()
+	)
+
+	if addr, ok := r.address(user); ok && ewsSupports(category) {
+		return r.createWithEWS(ctx, directory, destination, addr)
+	}
+
+	id, err := r.primary.CreateDestination(ctx, directory, destination, caches, errs)
+	if err == nil || !graph.IsErrHybridMailbox(err) || !ewsSupports(category) {
+		return id, err
+	}
+
+	addr, err := r.switchToEWS(ctx, user, err)
+	if err != nil {
+		return "", err
+	}
+
+	return r.createWithEWS(ctx, directory, destination, addr)
+}
+
+func (r *ewsFallbackRestorer) RestoreItem(
+	ctx context.Context,
+	bits []byte,
+	category path.CategoryType,
+	policy control.CollisionPolicy,
+	destination, user string,
+	errs *fault.Errors,
+) (*details.ExchangeInfo, error) {
+	if addr, ok := r.address(user); ok && ewsSupports(category) {
+		return r.restoreWithEWS(ctx, bits, category, destination, addr)
+	}
+
+	info, err := r.primary.RestoreItem(ctx, bits, category, policy, destination, user, errs)
+	if err == nil || !graph.IsErrHybridMailbox(err) || !ewsSupports(category) {
+		return info, err
+	}
+
+	addr, err := r.switchToEWS(ctx, user, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.restoreWithEWS(ctx, bits, category, destination, addr)
+}
+
+func (r *ewsFallbackRestorer) address(user string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addr, ok := r.addresses[user]
+
+	return addr, ok
+}
+
+// switchToEWS resolves the user's mailbox address, and records that the
+// user's remaining containers and items get restored through ews.
+func (r *ewsFallbackRestorer) switchToEWS(ctx context.Context, user string, graphErr error) (string, error) {
+	addr, err := r.mailbox(ctx, user)
+	if err != nil {
+		return "", clues.Wrap(err, "resolving mailbox address for ews").WithClues(ctx)
+	}
+
+	logger.Ctx(ctx).
+		With("err", graphErr).
+		Infow("graph can't write into the mailbox; restoring through ews", clues.InErr(graphErr).Slice()...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addresses[user] = addr
+
+	return addr, nil
+}
+
+// createWithEWS builds the destination through ews, mirroring the graph
+// restore: mail folders are nested beneath the destination, while contacts
+// and events are restored into a single destination folder.
+func (r *ewsFallbackRestorer) createWithEWS(
+	ctx context.Context,
+	directory path.Path,
+	destination, mailbox string,
+) (string, error) {
+	var (
+		category = directory.Category()
+		ec       = ewsContainers[category]
+		folders  = control.ContainerElements(destination)
+		parentID = ec.root
+		pb       = path.Builder{}.Append(mailbox, category.String())
+	)
+
+	if category == path.EmailCategory {
+		folders = append(folders, transform.Ctx(ctx).RestoreFolders(directory.Folders())...)
+	}
+
+	for _, folder := range folders {
+		pb = pb.Append(folder)
+
+		r.mu.Lock()
+		cached, ok := r.folders[pb.String()]
+		r.mu.Unlock()
+
+		if ok {
+			parentID = cached
+			continue
+		}
+
+		id, err := r.ews.EnsureFolder(ctx, mailbox, parentID, folder, ec.class)
+		if err != nil {
+			return "", clues.Wrap(err, "creating destination through ews").WithClues(ctx)
+		}
+
+		r.mu.Lock()
+		r.folders[pb.String()] = id
+		r.mu.Unlock()
+
+		parentID = id
+	}
+
+	return parentID, nil
+}
+
+func ewsSupports(category path.CategoryType) bool {
+	switch category {
+	case path.EmailCategory, path.ContactsCategory, path.EventsCategory:
+		return true
+	}
+
+	return false
+}
+
+func (r *ewsFallbackRestorer) restoreWithEWS(
+	ctx context.Context,
+	bits []byte,
+	category path.CategoryType,
+	destination, mailbox string,
+) (*details.ExchangeInfo, error) {
+	var info *details.ExchangeInfo
+
+	switch category {
+	case path.EmailCategory:
+		msg, err := support.CreateMessageFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating mail from bytes").WithClues(ctx)
+		}
+
+		if err := r.ews.CreateMessage(ctx, mailbox, destination, msg); err != nil {
+			return nil, clues.Wrap(err, "restoring mail through ews")
+		}
+
+		info = api.MailInfo(msg)
+
+	case path.ContactsCategory:
+		contact, err := support.CreateContactFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating contact from bytes").WithClues(ctx)
+		}
+
+		// ews item creation doesn't carry the photo.
+		api.PopContactPhoto(contact)
+
+		if err := r.ews.CreateContact(ctx, mailbox, destination, contact); err != nil {
+			return nil, clues.Wrap(err, "restoring contact through ews")
+		}
+
+		info = api.ContactInfo(contact)
+
+	case path.EventsCategory:
+		event, err := support.CreateEventFromBytes(bits)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating event from bytes").WithClues(ctx)
+		}
+
+		if err := r.ews.CreateEvent(ctx, mailbox, destination, event); err != nil {
+			return nil, clues.Wrap(err, "restoring event through ews")
+		}
+
+		info = api.EventInfo(event)
+
+	default:
+		return nil, clues.New(category.String() + " not supported for ews restore").WithClues(ctx)
+	}
+
+	info.Size = int64(len(bits))
+
+	return info, nil
+}
+
+// graphMailboxAddress looks up the user's primary smtp address, falling
+// back to the user principal name.  Directory lookups still succeed for
+// mailboxes that graph can't write into.
+func graphMailboxAddress(gs graph.Servicer) mailboxAddresser {
+	return func(ctx context.Context, user string) (string, error) {
+		options := &users.UserItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.UserItemRequestBuilderGetQueryParameters{
+				Select: []string{"mail", "userPrincipalName"},
+			},
+		}
+
+		u, err := gs.Client().UsersById(user).Get(ctx, options)
+		if err != nil {
+			return "", clues.Wrap(err, "getting user").WithClues(ctx).With(graph.ErrData(err)...)
+		}
+
+		if addr := ptr.Val(u.GetMail()); len(addr) > 0 {
+			return addr, nil
+		}
+
+		return ptr.Val(u.GetUserPrincipalName()), nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// mail pacing
+// ---------------------------------------------------------------------------
+
+var _ itemRestorer = &pacedRestorer{}
+
+// pacedRestorer spaces out the messages written into each mailbox, so that
+// a burst of new mail doesn't trip exchange's transport protections, which
+// can quarantine the mailbox's incoming mail.  Other categories aren't
+// paced.
+type pacedRestorer struct {
+	itemRestorer
+	interval time.Duration
+	// the time at which the next message can be written into each mailbox.
+	next map[string]time.Time
+}
+
+// paceMail produces a restorer that writes at most messagesPerMinute
+// messages into each mailbox.  The restorer is returned unchanged if the
+// rate is unbounded.
+func paceMail(ir itemRestorer, messagesPerMinute int) itemRestorer {
+	if messagesPerMinute <= 0 {
+		return ir
+	}
+
+	return &pacedRestorer{
+		itemRestorer: ir,
+		interval:     time.Minute / time.Duration(messagesPerMinute),
+		next:         map[string]time.Time{},
+	}
+}
+
+func (r *pacedRestorer) RestoreItem(
+	ctx context.Context,
+	bits []byte,
+	category path.CategoryType,
+	policy control.CollisionPolicy,
+	destination, user string,
+	errs *fault.Errors,
+) (*details.ExchangeInfo, error) {
+	if category == path.EmailCategory {
+		if err := r.wait(ctx, user); err != nil {
+			return nil, clues.Wrap(err, "pacing mail restore").WithClues(ctx)
+		}
+	}
+
+	return r.itemRestorer.RestoreItem(ctx, bits, category, policy, destination, user, errs)
+}
+
+// wait blocks until the next message can be written into the mailbox.
+func (r *pacedRestorer) wait(ctx context.Context, user string) error {
+	var (
+		now  = time.Now()
+		next = r.next[user]
+	)
+
+	if next.Before(now) {
+		next = now
+	}
+
+	r.next[user] = next.Add(r.interval)
+
+	if !next.After(now) {
+		return nil
+	}
+
+	t := time.NewTimer(next.Sub(now))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,674 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type mockItemRestorer struct {
+	err          error
+	calls        int
+	destinations int
+}
+
+func (m *mockItemRestorer) CreateDestination(
+	context.Context,
+	path.Path,
+	string,
+	map[path.CategoryType]graph.ContainerResolver,
+	*fault.Errors,
+) (string, error) {
+	m.destinations++
+
+	if m.err != nil {
+		return "", m.err
+	}
+
+	return "graph-folder", nil
+}
+
+func (m *mockItemRestorer) RestoreItem(
+	context.Context,
+	[]byte,
+	path.CategoryType,
+	control.CollisionPolicy,
+	string, string,
+	*fault.Errors,
+) (*details.ExchangeInfo, error) {
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &details.ExchangeInfo{}, nil
+}
+
+type mockEWSCreator struct {
+	mailboxes []string
+	folders   []string
+	subjects  []string
+	// parent/name/class of each ensured folder.
+	ensured []string
+}
+
+func (m *mockEWSCreator) EnsureFolder(_ context.Context, _, parentID, name, folderClass string) (string, error) {
+	m.ensured = append(m.ensured, parentID+"/"+name+"/"+folderClass)
+	return "ews-" + name, nil
+}
+
+func (m *mockEWSCreator) CreateMessage(_ context.Context, mailbox, folderID string, msg models.Messageable) error {
+	m.mailboxes = append(m.mailboxes, mailbox)
+	m.folders = append(m.folders, folderID)
+	m.subjects = append(m.subjects, *msg.GetSubject())
+
+	return nil
+}
+
+func (m *mockEWSCreator) CreateContact(_ context.Context, mailbox, folderID string, _ models.Contactable) error {
+	m.mailboxes = append(m.mailboxes, mailbox)
+	m.folders = append(m.folders, folderID)
+
+	return nil
+}
+
+func (m *mockEWSCreator) CreateEvent(_ context.Context, mailbox, calendarID string, _ models.Eventable) error {
+	m.mailboxes = append(m.mailboxes, mailbox)
+	m.folders = append(m.folders, calendarID)
+
+	return nil
+}
+
+func hybridMailboxErr() error {
+	var (
+		code  = "MailboxNotEnabledForRESTAPI"
+		odErr = &odataerrors.ODataError{}
+		merr  = odataerrors.MainError{}
+	)
+
+	merr.SetCode(&code)
+	odErr.SetError(&merr)
+
+	return odErr
+}
+
+type RestorerUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestorerUnitSuite(t *testing.T) {
+	suite.Run(t, &RestorerUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestorerUnitSuite) TestNewItemRestorer() {
+	t := suite.T()
+
+	ir, err := newItemRestorer(account.M365Config{}, nil, control.EWSOptions{})
+	require.NoError(t, err)
+	assert.IsType(t, graphRestorer{}, ir)
+}
+
+func (suite *RestorerUnitSuite) TestEWSFallbackRestorer() {
+	var (
+		mail  = mockconnector.GetMockMessageBytes("hybrid")
+		event = mockconnector.GetMockEventWithSubjectBytes("hybrid")
+	)
+
+	table := []struct {
+		name          string
+		primaryErr    error
+		category      path.CategoryType
+		bits          []byte
+		expectErr     assert.ErrorAssertionFunc
+		expectPrimary int
+		expectEWS     int
+	}{
+		{
+			name:          "graph succeeds",
+			category:      path.EmailCategory,
+			bits:          mail,
+			expectErr:     assert.NoError,
+			expectPrimary: 2,
+		},
+		{
+			name:          "hybrid mailbox switches to ews",
+			primaryErr:    hybridMailboxErr(),
+			category:      path.EmailCategory,
+			bits:          mail,
+			expectErr:     assert.NoError,
+			expectPrimary: 1,
+			expectEWS:     2,
+		},
+		{
+			name:          "hybrid mailbox events",
+			primaryErr:    hybridMailboxErr(),
+			category:      path.EventsCategory,
+			bits:          event,
+			expectErr:     assert.NoError,
+			expectPrimary: 1,
+			expectEWS:     2,
+		},
+		{
+			name:          "other errors aren't retried",
+			primaryErr:    assert.AnError,
+			category:      path.EmailCategory,
+			bits:          mail,
+			expectErr:     assert.Error,
+			expectPrimary: 2,
+		},
+		{
+			name:          "tasks aren't supported by ews",
+			primaryErr:    hybridMailboxErr(),
+			category:      path.TasksCategory,
+			bits:          mail,
+			expectErr:     assert.Error,
+			expectPrimary: 2,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t       = suite.T()
+				primary = &mockItemRestorer{err: test.primaryErr}
+				ews     = &mockEWSCreator{}
+				lookups int
+				r       = &ewsFallbackRestorer{
+					primary: primary,
+					ews:     ews,
+					mailbox: func(context.Context, string) (string, error) {
+						lookups++
+						return "user@contoso.com", nil
+					},
+					addresses: map[string]string{},
+					folders:   map[string]string{},
+				}
+			)
+
+			// two items for the same user: the second skips graph once the
+			// mailbox is known to be hybrid.
+			for i := 0; i < 2; i++ {
+				info, err := r.RestoreItem(ctx, test.bits, test.category, control.Copy, "folder", "uid", fault.New(true))
+				test.expectErr(t, err)
+
+				if err == nil {
+					assert.NotNil(t, info)
+				}
+			}
+
+			assert.Equal(t, test.expectPrimary, primary.calls, "graph restores")
+			assert.Len(t, ews.mailboxes, test.expectEWS, "ews restores")
+
+			if test.expectEWS > 0 {
+				assert.Equal(t, 1, lookups, "mailbox address lookups")
+				assert.Equal(t, []string{"user@contoso.com", "user@contoso.com"}, ews.mailboxes)
+				assert.Equal(t, []string{"folder", "folder"}, ews.folders)
+			}
+		})
+	}
+}
+
+func (suite *RestorerUnitSuite) TestEWSFallbackRestorer_createDestination() {
+	table := []struct {
+		name          string
+		primaryErr    error
+		category      path.CategoryType
+		folders       []string
+		expectErr     assert.ErrorAssertionFunc
+		expectID      string
+		expectEnsured []string
+	}{
+		{
+			name:      "graph succeeds",
+			category:  path.EmailCategory,
+			folders:   []string{"Inbox"},
+			expectErr: assert.NoError,
+			expectID:  "graph-folder",
+		},
+		{
+			name:       "hybrid mailbox mail folders",
+			primaryErr: hybridMailboxErr(),
+			category:   path.EmailCategory,
+			folders:    []string{"Inbox", "sub"},
+			expectErr:  assert.NoError,
+			expectID:   "ews-sub",
+			expectEnsured: []string{
+				"msgfolderroot/dest/IPF.Note",
+				"ews-dest/Inbox/IPF.Note",
+				"ews-Inbox/sub/IPF.Note",
+			},
+		},
+		{
+			name:          "hybrid mailbox contacts are flat",
+			primaryErr:    hybridMailboxErr(),
+			category:      path.ContactsCategory,
+			folders:       []string{"Contacts", "sub"},
+			expectErr:     assert.NoError,
+			expectID:      "ews-dest",
+			expectEnsured: []string{"contacts/dest/IPF.Contact"},
+		},
+		{
+			name:          "hybrid mailbox calendars",
+			primaryErr:    hybridMailboxErr(),
+			category:      path.EventsCategory,
+			folders:       []string{"Calendar"},
+			expectErr:     assert.NoError,
+			expectID:      "ews-dest",
+			expectEnsured: []string{"calendar/dest/IPF.Appointment"},
+		},
+		{
+			name:       "tasks aren't supported by ews",
+			primaryErr: hybridMailboxErr(),
+			category:   path.TasksCategory,
+			folders:    []string{"Tasks"},
+			expectErr:  assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t       = suite.T()
+				primary = &mockItemRestorer{err: test.primaryErr}
+				ews     = &mockEWSCreator{}
+				r       = &ewsFallbackRestorer{
+					primary: primary,
+					ews:     ews,
+					mailbox: func(context.Context, string) (string, error) {
+						return "user@contoso.com", nil
+					},
+					addresses: map[string]string{},
+					folders:   map[string]string{},
+				}
+			)
+
+			dir, err := path.Builder{}.Append(test.folders...).
+				ToDataLayerExchangePathForCategory("tid", "uid", test.category, false)
+			require.NoError(t, err)
+
+			// the second call reuses the folders created by the first,
+			// without asking graph again.
+			for i := 0; i < 2; i++ {
+				id, err := r.CreateDestination(ctx, dir, "dest", nil, fault.New(true))
+				test.expectErr(t, err)
+				assert.Equal(t, test.expectID, id)
+			}
+
+			assert.Equal(t, test.expectEnsured, ews.ensured)
+
+			if len(test.expectEnsured) > 0 {
+				assert.Equal(t, 1, primary.destinations, "graph destinations")
+
+				// items follow their container over to ews.
+				_, err := r.RestoreItem(
+					ctx,
+					mockconnector.GetMockEventWithSubjectBytes("hybrid"),
+					path.EventsCategory,
+					control.Copy,
+					test.expectID,
+					"uid",
+					fault.New(true))
+				require.NoError(t, err)
+				assert.Zero(t, primary.calls, "graph restores")
+				assert.Equal(t, []string{test.expectID}, ews.folders)
+			}
+		})
+	}
+}
+
+// restoreResolver resolves the folders that Populate loads, along with
+// the folders added to it afterwards.
+type restoreResolver struct {
+	graph.ContainerResolver
+	existing  map[string]string
+	paths     map[string]string
+	populated int
+}
+
+func (r *restoreResolver) Populate(context.Context, *fault.Errors, string, ...string) error {
+	r.populated++
+
+	for p, id := range r.existing {
+		r.paths[p] = id
+	}
+
+	return nil
+}
+
+func (r *restoreResolver) PathInCache(p string) (string, bool) {
+	id, ok := r.paths[p]
+	return id, ok
+}
+
+func (r *restoreResolver) AddToCache(_ context.Context, c graph.Container, _ bool) error {
+	parent := &path.Builder{}
+
+	for p, id := range r.paths {
+		if id == ptr.Val(c.GetParentFolderId()) {
+			parent = path.Builder{}.Append(strings.Split(p, "/")...)
+		}
+	}
+
+	r.paths[parent.Append(ptr.Val(c.GetDisplayName())).String()] = ptr.Val(c.GetId())
+
+	return nil
+}
+
+type mockMailFolderCreator struct {
+	created []string
+}
+
+func (m *mockMailFolderCreator) CreateMailFolderWithParent(
+	_ context.Context,
+	_, folder, parentID string,
+) (models.MailFolderable, error) {
+	m.created = append(m.created, folder)
+
+	id := "new-" + folder
+	mf := models.NewMailFolder()
+	mf.SetId(&id)
+	mf.SetDisplayName(&folder)
+	mf.SetParentFolderId(&parentID)
+
+	return mf, nil
+}
+
+func (suite *RestorerUnitSuite) TestEstablishMailRestoreLocation() {
+	table := []struct {
+		name          string
+		existing      map[string]string
+		expectID      string
+		expectCreated []string
+	}{
+		{
+			name:          "new destination",
+			existing:      map[string]string{},
+			expectID:      "new-sub",
+			expectCreated: []string{"dest", "Inbox", "sub"},
+		},
+		{
+			name:          "existing destination",
+			existing:      map[string]string{"dest": "dest-id"},
+			expectID:      "new-sub",
+			expectCreated: []string{"Inbox", "sub"},
+		},
+		{
+			name: "existing folders",
+			existing: map[string]string{
+				"dest":           "dest-id",
+				"dest/Inbox":     "inbox-id",
+				"dest/Inbox/sub": "sub-id",
+			},
+			expectID: "sub-id",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t   = suite.T()
+				mc  = &mockMailFolderCreator{}
+				res = &restoreResolver{existing: test.existing, paths: map[string]string{}}
+			)
+
+			id, err := establishMailRestoreLocation(
+				ctx,
+				mc,
+				[]string{"dest", "Inbox", "sub"},
+				res,
+				"uid",
+				true,
+				fault.New(true))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectID, id)
+			assert.Equal(t, test.expectCreated, mc.created)
+			assert.Equal(t, 1, res.populated)
+		})
+	}
+}
+
+type mockContactFolderCreator struct {
+	existing map[string]string
+	created  []string
+}
+
+func (m *mockContactFolderCreator) GetContainerByName(
+	_ context.Context,
+	_, name string,
+) (models.ContactFolderable, error) {
+	id, ok := m.existing[name]
+	if !ok {
+		return nil, nil
+	}
+
+	cf := models.NewContactFolder()
+	cf.SetId(&id)
+	cf.SetDisplayName(&name)
+
+	return cf, nil
+}
+
+func (m *mockContactFolderCreator) CreateContactFolder(
+	_ context.Context,
+	_, name string,
+) (models.ContactFolderable, error) {
+	m.created = append(m.created, name)
+
+	id := "new-" + name
+	cf := models.NewContactFolder()
+	cf.SetId(&id)
+	cf.SetDisplayName(&name)
+
+	return cf, nil
+}
+
+func (suite *RestorerUnitSuite) TestEstablishContactsRestoreLocation() {
+	table := []struct {
+		name          string
+		existing      map[string]string
+		expectID      string
+		expectCreated []string
+	}{
+		{
+			name:          "new destination",
+			existing:      map[string]string{},
+			expectID:      "new-dest",
+			expectCreated: []string{"dest"},
+		},
+		{
+			name:     "existing destination",
+			existing: map[string]string{"dest": "dest-id"},
+			expectID: "dest-id",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t   = suite.T()
+				cc  = &mockContactFolderCreator{existing: test.existing}
+				res = &restoreResolver{existing: map[string]string{}, paths: map[string]string{}}
+			)
+
+			id, err := establishContactsRestoreLocation(
+				ctx,
+				cc,
+				[]string{"dest", "Contacts"},
+				res,
+				"uid",
+				true,
+				fault.New(true))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectID, id)
+			assert.Equal(t, test.expectCreated, cc.created)
+
+			// later collections find the destination in the cache.
+			id, err = establishContactsRestoreLocation(
+				ctx,
+				cc,
+				[]string{"dest", "Contacts"},
+				res,
+				"uid",
+				false,
+				fault.New(true))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectID, id)
+			assert.Equal(t, test.expectCreated, cc.created)
+		})
+	}
+}
+
+func testContact(name string, addrs ...string) models.Contactable {
+	c := models.NewContact()
+	c.SetDisplayName(&name)
+
+	eas := make([]models.EmailAddressable, 0, len(addrs))
+
+	for _, a := range addrs {
+		addr := a
+		ea := models.NewEmailAddress()
+		ea.SetAddress(&addr)
+		eas = append(eas, ea)
+	}
+
+	c.SetEmailAddresses(eas)
+
+	return c
+}
+
+func (suite *RestorerUnitSuite) TestPaceMail() {
+	var (
+		t    = suite.T()
+		mock = &mockItemRestorer{}
+	)
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	assert.Same(t, mock, paceMail(mock, 0), "unbounded rates aren't paced")
+
+	// 600 messages per minute spaces messages 100ms apart.
+	ir := paceMail(mock, 600)
+	start := time.Now()
+
+	for _, user := range []string{"u1", "u1", "u1", "u2"} {
+		_, err := ir.RestoreItem(ctx, nil, path.EmailCategory, control.Copy, "dest", user, fault.New(true))
+		require.NoError(t, err)
+	}
+
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond, "a mailbox's messages are paced")
+	assert.Less(t, elapsed, 350*time.Millisecond, "mailboxes are paced separately")
+
+	start = time.Now()
+
+	_, err := ir.RestoreItem(ctx, nil, path.EventsCategory, control.Copy, "dest", "u1", fault.New(true))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "only mail is paced")
+	assert.Equal(t, 5, mock.calls)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = ir.RestoreItem(cctx, nil, path.EmailCategory, control.Copy, "dest", "u1", fault.New(true))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func (suite *RestorerUnitSuite) TestContactsCollide() {
+	table := []struct {
+		name   string
+		a, b   models.Contactable
+		expect assert.BoolAssertionFunc
+	}{
+		{
+			name:   "same name and addresses",
+			a:      testContact("Ada", "ada@example.com", "a@example.com"),
+			b:      testContact("ada", "A@example.com", "ADA@example.com"),
+			expect: assert.True,
+		},
+		{
+			name:   "same name without addresses",
+			a:      testContact("Ada"),
+			b:      testContact("Ada"),
+			expect: assert.True,
+		},
+		{
+			name:   "different name",
+			a:      testContact("Ada", "ada@example.com"),
+			b:      testContact("Grace", "ada@example.com"),
+			expect: assert.False,
+		},
+		{
+			name:   "extra address",
+			a:      testContact("Ada", "ada@example.com"),
+			b:      testContact("Ada", "ada@example.com", "a@example.com"),
+			expect: assert.False,
+		},
+		{
+			name:   "different address",
+			a:      testContact("Ada", "ada@example.com"),
+			b:      testContact("Ada", "grace@example.com"),
+			expect: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), contactsCollide(test.a, test.b))
+		})
+	}
+}
+
+func (suite *RestorerUnitSuite) TestContactCollisionFilter() {
+	table := []struct {
+		name    string
+		contact models.Contactable
+		expect  string
+	}{
+		{
+			name:    "no name",
+			contact: testContact("", "ada@example.com"),
+			expect:  "",
+		},
+		{
+			name:    "name only",
+			contact: testContact("Ada O'Neil"),
+			expect:  "displayName eq 'Ada O''Neil'",
+		},
+		{
+			name:    "addresses",
+			contact: testContact("Ada", "b@example.com", "A@example.com"),
+			expect:  "emailAddresses/any(a:a/address eq 'a@example.com')",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, contactCollisionFilter(test.contact))
+		})
+	}
+}
@@ -17,11 +17,7 @@ import (
 var ErrFolderNotFound = errors.New("folder not found")
 
 func createService(credentials account.M365Config) (*graph.Service, error) {
-	adapter, err := graph.CreateAdapter(
-		credentials.AzureTenantID,
-		credentials.AzureClientID,
-		credentials.AzureClientSecret,
-	)
+	adapter, err := graph.CreateAdapter(credentials)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating microsoft graph service for exchange")
 	}
@@ -76,6 +72,14 @@ func PopulateExchangeContainerResolver(
 		}
 		cacheRoot = DefaultCalendar
 
+	case path.TasksCategory:
+		act := ac.Tasks()
+		res = &taskFolderCache{
+			userID: qp.ResourceOwner,
+			getter: act,
+			enumer: act,
+		}
+
 	default:
 		return nil, clues.New("no container resolver registered for category").WithClues(ctx)
 	}
@@ -149,6 +153,8 @@ func includeContainer(
 		ok = scope.Matches(selectors.ExchangeContactFolder, directory)
 	case path.EventsCategory:
 		ok = scope.Matches(selectors.ExchangeEventCalendar, directory)
+	case path.TasksCategory:
+		ok = scope.Matches(selectors.ExchangeTaskFolder, directory)
 	default:
 		return nil, nil, false
 	}
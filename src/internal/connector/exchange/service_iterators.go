@@ -4,12 +4,15 @@ import (
 	"context"
 
 	"github.com/alcionai/clues"
+	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/corso/src/internal/connector/exchange/api"
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/observe"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
@@ -24,6 +27,24 @@ type addedAndRemovedItemIDsGetter interface {
 	) ([]string, []string, api.DeltaUpdate, error)
 }
 
+// seriesFingerprintGetter is implemented by getters whose items include
+// recurring series, which delta queries don't completely track.
+type seriesFingerprintGetter interface {
+	GetSeriesFingerprints(
+		ctx context.Context,
+		user, containerID string,
+	) (map[string]string, error)
+}
+
+// containerStatsGetter is implemented by getters whose containers report
+// their contents ahead of item enumeration.
+type containerStatsGetter interface {
+	GetContainerStats(
+		ctx context.Context,
+		user, containerID string,
+	) (api.ContainerStats, error)
+}
+
 // filterContainersAndFillCollections is a utility function
 // that places the M365 object ids belonging to specific directories
 // into a BackupCollection. Messages outside of those directories are omitted.
@@ -45,6 +66,8 @@ func filterContainersAndFillCollections(
 		// folder ID -> delta url or folder path lookups
 		deltaURLs = map[string]string{}
 		currPaths = map[string]string{}
+		// folder ID -> series master ID -> series fingerprint
+		seriesFPs = map[string]map[string]string{}
 		// copy of previousPaths.  any folder found in the resolver get
 		// deleted from this map, leaving only the deleted folders behind
 		tombstones = makeTombstones(dps)
@@ -68,6 +91,13 @@ func filterContainersAndFillCollections(
 		return err
 	}
 
+	var (
+		stats            = preflightContainers(ctx, qp, getter, resolver, scope)
+		enumerated, done = enumerationProgress(ctx, qp, stats)
+	)
+
+	defer done()
+
 	et := errs.Tracker()
 
 	for _, c := range resolver.Items() {
@@ -100,6 +130,8 @@ func filterContainersAndFillCollections(
 		}
 
 		added, removed, newDelta, err := getter.GetAddedAndRemovedItemIDs(ctx, qp.ResourceOwner, cID, prevDelta)
+		enumerated(cID)
+
 		if err != nil {
 			if !graph.IsErrDeletedInFlight(err) {
 				et.Add(err)
@@ -114,21 +146,70 @@ func filterContainersAndFillCollections(
 			newDelta = api.DeltaUpdate{Reset: true}
 		}
 
-		if len(newDelta.URL) > 0 {
+		var (
+			reSeries     []string
+			seriesFailed bool
+		)
+
+		if sfg, ok := getter.(seriesFingerprintGetter); ok && len(newDelta.URL) > 0 {
+			fps, err := sfg.GetSeriesFingerprints(ctx, qp.ResourceOwner, cID)
+
+			switch {
+			case err != nil:
+				// the container's items are still backed up.  Every series
+				// fingerprinted in the previous backup gets re-added, since
+				// its changes can't be ruled out, and its fingerprints are
+				// carried forward for the next backup to compare against.
+				et.Add(clues.Wrap(err, "fingerprinting recurring series").WithClues(ctx))
+
+				seriesFailed = true
+
+				if len(dp.series) > 0 {
+					seriesFPs[cID] = dp.series
+				}
+
+				if len(prevDelta) > 0 && !newDelta.Reset {
+					reSeries = maps.Keys(dp.series)
+				}
+
+			default:
+				seriesFPs[cID] = fps
+
+				// a reset delta already re-adds every item in the container.
+				if len(prevDelta) > 0 && !newDelta.Reset {
+					reSeries = changedSeries(dp.series, fps)
+					logger.Ctx(ctx).Debugw("changed recurring series", "count", len(reSeries))
+				}
+			}
+		}
+
+		// only advance the delta once everything it depends on was fetched,
+		// otherwise the next backup would skip the changes it covers.
+		switch {
+		case seriesFailed && !newDelta.Reset && len(prevDelta) > 0:
+			deltaURLs[cID] = prevDelta
+		case !seriesFailed && len(newDelta.URL) > 0:
 			deltaURLs[cID] = newDelta.URL
 		}
 
-		if qp.Category != path.EventsCategory {
+		// only categories stored by container ID need a separate location.
+		if qp.Category != path.EventsCategory && qp.Category != path.TasksCategory {
 			locPath = nil
 		}
 
+		// to do tasks can only be fetched through the list that holds them.
+		items := ibt
+		if t, ok := ibt.(api.Tasks); ok {
+			items = t.InList(cID)
+		}
+
 		edc := NewCollection(
 			qp.ResourceOwner,
 			currPath,
 			prevPath,
 			locPath,
 			scope.Category().PathType(),
-			ibt,
+			items,
 			statusUpdater,
 			ctrlOpts,
 			newDelta.Reset)
@@ -139,6 +220,10 @@ func filterContainersAndFillCollections(
 			edc.added[add] = struct{}{}
 		}
 
+		for _, add := range reSeries {
+			edc.added[add] = struct{}{}
+		}
+
 		// Remove any deleted IDs from the set of added IDs because items that are
 		// deleted and then restored will have a different ID than they did
 		// originally.
@@ -205,6 +290,10 @@ func filterContainersAndFillCollections(
 		entries = append(entries, graph.NewMetadataEntry(graph.DeltaURLsFileName, deltaURLs))
 	}
 
+	if len(seriesFPs) > 0 {
+		entries = append(entries, graph.NewMetadataEntry(graph.SeriesFingerprintsFileName, seriesFPs))
+	}
+
 	col, err := graph.MakeMetadataCollection(
 		qp.Credentials.AzureTenantID,
 		qp.ResourceOwner,
@@ -221,6 +310,110 @@ func filterContainersAndFillCollections(
 	return et.Err()
 }
 
+// preflightContainers retrieves the item counts and sizes of the containers
+// within the scope, ahead of enumerating their items.  The preflight is best
+// effort: containers whose stats can't be retrieved are left out, and
+// getters that don't report stats produce no stats at all.
+func preflightContainers(
+	ctx context.Context,
+	qp graph.QueryParams,
+	getter addedAndRemovedItemIDsGetter,
+	resolver graph.ContainerResolver,
+	scope selectors.ExchangeScope,
+) map[string]api.ContainerStats {
+	stats := map[string]api.ContainerStats{}
+
+	csg, ok := getter.(containerStatsGetter)
+	if !ok {
+		return stats
+	}
+
+	var total api.ContainerStats
+
+	for _, c := range resolver.Items() {
+		if _, _, ok := includeContainer(qp, c, scope); !ok {
+			continue
+		}
+
+		cID := *c.GetId()
+
+		cs, err := csg.GetContainerStats(ctx, qp.ResourceOwner, cID)
+		if err != nil {
+			logger.Ctx(ctx).
+				With("err", err, "container_id", cID).
+				Infow("retrieving container stats", clues.InErr(err).Slice()...)
+
+			continue
+		}
+
+		stats[cID] = cs
+		total.ItemCount += cs.ItemCount
+		total.Size += cs.Size
+	}
+
+	logger.Ctx(ctx).Infow(
+		"container preflight",
+		"num_containers", len(stats),
+		"item_count", total.ItemCount,
+		"size", humanize.Bytes(uint64(total.Size)))
+
+	return stats
+}
+
+// enumerationProgress displays the progress of item enumeration across
+// the containers, as measured by the containers' item counts.  The returned
+// func advances the progress by the item count of a container that finished
+// enumerating.  The caller is expected to call the returned closer.
+func enumerationProgress(
+	ctx context.Context,
+	qp graph.QueryParams,
+	stats map[string]api.ContainerStats,
+) (func(containerID string), func()) {
+	var total int64
+
+	for _, cs := range stats {
+		total += cs.ItemCount
+	}
+
+	if total == 0 {
+		return func(string) {}, func() {}
+	}
+
+	progress, closer := observe.ProgressWithTotal(
+		ctx,
+		observe.Bullet+" Enumerating "+qp.Category.String(),
+		observe.PII(qp.ResourceOwner),
+		total)
+
+	go closer()
+
+	advance := func(containerID string) {
+		if n := stats[containerID].ItemCount; n > 0 {
+			progress <- n
+		}
+	}
+
+	return advance, func() { close(progress) }
+}
+
+// changedSeries produces the IDs of all series whose fingerprint differs
+// from the previous backup.  If no fingerprints were previously recorded,
+// such as in backups made before fingerprints were tracked, every current
+// series is considered changed.
+func changedSeries(prev, curr map[string]string) []string {
+	changed := []string{}
+
+	for id, fp := range curr {
+		if prevFP, ok := prev[id]; ok && prevFP == fp {
+			continue
+		}
+
+		changed = append(changed, id)
+	}
+
+	return changed
+}
+
 // produces a set of id:path pairs from the deltapaths map.
 // Each entry in the set will, if not removed, produce a collection
 // that will delete the tombstone by path.
@@ -251,6 +444,8 @@ func itemerByType(ac api.Client, category path.CategoryType) (itemer, error) {
 		return ac.Events(), nil
 	case path.ContactsCategory:
 		return ac.Contacts(), nil
+	case path.TasksCategory:
+		return ac.Tasks(), nil
 	default:
 		return nil, clues.New("category not registered in getFetchIDFunc")
 	}
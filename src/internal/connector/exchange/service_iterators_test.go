@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -55,6 +56,40 @@ func (mg mockGetter) GetAddedAndRemovedItemIDs(
 	return results.added, results.removed, results.newDelta, results.err
 }
 
+var _ seriesFingerprintGetter = &mockSeriesGetter{}
+
+type mockSeriesGetter struct {
+	mockGetter
+	fingerprints map[string]map[string]string
+	err          error
+}
+
+func (mg mockSeriesGetter) GetSeriesFingerprints(
+	ctx context.Context,
+	userID, cID string,
+) (map[string]string, error) {
+	return mg.fingerprints[cID], mg.err
+}
+
+var _ containerStatsGetter = &mockStatsGetter{}
+
+type mockStatsGetter struct {
+	mockGetter
+	stats map[string]api.ContainerStats
+}
+
+func (mg mockStatsGetter) GetContainerStats(
+	ctx context.Context,
+	userID, cID string,
+) (api.ContainerStats, error) {
+	cs, ok := mg.stats[cID]
+	if !ok {
+		return api.ContainerStats{}, errors.New("mock stats not found for " + cID)
+	}
+
+	return cs, nil
+}
+
 var _ graph.ContainerResolver = &mockResolver{}
 
 type (
@@ -846,3 +881,352 @@ func (suite *ServiceIteratorsSuite) TestFilterContainersAndFillCollections_incre
 		})
 	}
 }
+
+func (suite *ServiceIteratorsSuite) TestChangedSeries() {
+	table := []struct {
+		name   string
+		prev   map[string]string
+		curr   map[string]string
+		expect []string
+	}{
+		{
+			name:   "unchanged",
+			prev:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			curr:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			expect: []string{},
+		},
+		{
+			name:   "changed fingerprint",
+			prev:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			curr:   map[string]string{"s1": "fp1", "s2": "fp2-edited"},
+			expect: []string{"s2"},
+		},
+		{
+			name:   "new series",
+			prev:   map[string]string{"s1": "fp1"},
+			curr:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			expect: []string{"s2"},
+		},
+		{
+			name:   "removed series",
+			prev:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			curr:   map[string]string{"s1": "fp1"},
+			expect: []string{},
+		},
+		{
+			name:   "no previous fingerprints",
+			curr:   map[string]string{"s1": "fp1", "s2": "fp2"},
+			expect: []string{"s1", "s2"},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.ElementsMatch(suite.T(), test.expect, changedSeries(test.prev, test.curr))
+		})
+	}
+}
+
+func (suite *ServiceIteratorsSuite) TestFilterContainersAndFillCollections_changedSeries() {
+	var (
+		userID   = "user_id"
+		tenantID = suite.creds.AzureTenantID
+		cat      = path.EventsCategory
+		qp       = graph.QueryParams{
+			Category:      cat,
+			ResourceOwner: userID,
+			Credentials:   suite.creds,
+		}
+		statusUpdater = func(*support.ConnectorOperationStatus) {}
+		allScope      = selectors.NewExchangeBackup(nil).EventCalendars(selectors.Any())[0]
+		container1    = mockContainer{
+			id:          strPtr("1"),
+			displayName: strPtr("calendar"),
+			p:           path.Builder{}.Append("1"),
+			l:           path.Builder{}.Append("calendar"),
+		}
+		fingerprints = map[string]map[string]string{
+			"1": {"series1": "fp1", "series2": "fp2-edited"},
+		}
+	)
+
+	prevPath, err := path.Builder{}.
+		Append("1").
+		ToDataLayerExchangePathForCategory(tenantID, userID, cat, false)
+	require.NoError(suite.T(), err)
+
+	table := []struct {
+		name        string
+		newDelta    api.DeltaUpdate
+		dps         DeltaPaths
+		fpErr       error
+		expectAdded []string
+	}{
+		{
+			name:     "incremental re-adds changed series",
+			newDelta: api.DeltaUpdate{URL: "new_delta_url"},
+			dps: DeltaPaths{
+				"1": DeltaPath{
+					delta:  "old_delta_url",
+					path:   prevPath.String(),
+					series: map[string]string{"series1": "fp1", "series2": "fp2"},
+				},
+			},
+			expectAdded: []string{"added", "series2"},
+		},
+		{
+			name:     "reset delta ignores series",
+			newDelta: api.DeltaUpdate{URL: "new_delta_url", Reset: true},
+			dps: DeltaPaths{
+				"1": DeltaPath{
+					delta:  "old_delta_url",
+					path:   prevPath.String(),
+					series: map[string]string{"series1": "fp1", "series2": "fp2"},
+				},
+			},
+			expectAdded: []string{"added"},
+		},
+		{
+			name:     "fingerprint failure re-adds previous series",
+			newDelta: api.DeltaUpdate{URL: "new_delta_url"},
+			dps: DeltaPaths{
+				"1": DeltaPath{
+					delta:  "old_delta_url",
+					path:   prevPath.String(),
+					series: map[string]string{"series1": "fp1", "series2": "fp2"},
+				},
+			},
+			fpErr:       assert.AnError,
+			expectAdded: []string{"added", "series1", "series2"},
+		},
+		{
+			name:        "full backup ignores series",
+			newDelta:    api.DeltaUpdate{URL: "new_delta_url"},
+			dps:         DeltaPaths{},
+			expectAdded: []string{"added"},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				collections = map[string]data.BackupCollection{}
+				getter      = mockSeriesGetter{
+					mockGetter: mockGetter{
+						"1": {added: []string{"added"}, newDelta: test.newDelta},
+					},
+					fingerprints: fingerprints,
+					err:          test.fpErr,
+				}
+				errs = fault.New(false)
+			)
+
+			err := filterContainersAndFillCollections(
+				ctx,
+				qp,
+				getter,
+				collections,
+				statusUpdater,
+				newMockResolver(container1),
+				allScope,
+				test.dps,
+				control.Options{},
+				errs)
+			require.NoError(t, err)
+
+			if test.fpErr != nil {
+				assert.Len(t, errs.Errs(), 1, "fingerprint failure is recoverable")
+			} else {
+				assert.Empty(t, errs.Errs())
+			}
+
+			exColl, ok := collections["1"].(*Collection)
+			require.True(t, ok, "collection is an *exchange.Collection")
+
+			added := []string{}
+			for id := range exColl.added {
+				added = append(added, id)
+			}
+
+			assert.ElementsMatch(t, test.expectAdded, added)
+		})
+	}
+}
+
+func (suite *ServiceIteratorsSuite) TestFilterContainersAndFillCollections_seriesFailureKeepsDelta() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		qp = graph.QueryParams{
+			Category:      path.EventsCategory,
+			ResourceOwner: "user_id",
+			Credentials:   suite.creds,
+		}
+		statusUpdater = func(*support.ConnectorOperationStatus) {}
+		allScope      = selectors.NewExchangeBackup(nil).EventCalendars(selectors.Any())[0]
+		container1    = mockContainer{
+			id:          strPtr("1"),
+			displayName: strPtr("calendar"),
+			p:           path.Builder{}.Append("1"),
+			l:           path.Builder{}.Append("calendar"),
+		}
+		getter = mockSeriesGetter{
+			mockGetter: mockGetter{
+				"1": {added: []string{"added"}, newDelta: api.DeltaUpdate{URL: "new_delta_url"}},
+			},
+			err: assert.AnError,
+		}
+		collections = map[string]data.BackupCollection{}
+		errs        = fault.New(false)
+	)
+
+	err := filterContainersAndFillCollections(
+		ctx,
+		qp,
+		getter,
+		collections,
+		statusUpdater,
+		newMockResolver(container1),
+		allScope,
+		DeltaPaths{},
+		control.Options{},
+		errs)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs.Errs(), "series fingerprint failure is recorded")
+
+	mc, ok := collections["metadata"]
+	require.True(t, ok, "metadata collection")
+
+	deltas := map[string]string{}
+
+	for item := range mc.Items(ctx, fault.New(true)) {
+		if item.UUID() == graph.DeltaURLsFileName {
+			require.NoError(t, json.NewDecoder(item.ToReader()).Decode(&deltas))
+		}
+	}
+
+	// the next backup must re-enumerate the changes the new delta covers.
+	assert.NotContains(t, deltas, "1", "delta was not advanced")
+}
+
+func (suite *ServiceIteratorsSuite) TestPreflightContainers() {
+	var (
+		qp = graph.QueryParams{
+			Category:      path.EmailCategory,
+			ResourceOwner: "user_id",
+			Credentials:   suite.creds,
+		}
+		allScope   = selectors.NewExchangeBackup(nil).MailFolders(selectors.Any())[0]
+		container1 = mockContainer{
+			id:          strPtr("1"),
+			displayName: strPtr("display_name_1"),
+			p:           path.Builder{}.Append("display_name_1"),
+		}
+		container2 = mockContainer{
+			id:          strPtr("2"),
+			displayName: strPtr("display_name_2"),
+			p:           path.Builder{}.Append("display_name_2"),
+		}
+		resolver = newMockResolver(container1, container2)
+	)
+
+	table := []struct {
+		name   string
+		getter addedAndRemovedItemIDsGetter
+		expect map[string]api.ContainerStats
+	}{
+		{
+			name:   "no stats support",
+			getter: mockGetter{},
+			expect: map[string]api.ContainerStats{},
+		},
+		{
+			name: "all containers",
+			getter: mockStatsGetter{
+				stats: map[string]api.ContainerStats{
+					"1": {ItemCount: 10, Size: 1024},
+					"2": {ItemCount: 5},
+				},
+			},
+			expect: map[string]api.ContainerStats{
+				"1": {ItemCount: 10, Size: 1024},
+				"2": {ItemCount: 5},
+			},
+		},
+		{
+			name: "failures are skipped",
+			getter: mockStatsGetter{
+				stats: map[string]api.ContainerStats{
+					"2": {ItemCount: 5},
+				},
+			},
+			expect: map[string]api.ContainerStats{
+				"2": {ItemCount: 5},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			stats := preflightContainers(ctx, qp, test.getter, resolver, allScope)
+			assert.Equal(suite.T(), test.expect, stats)
+		})
+	}
+}
+
+func (suite *ServiceIteratorsSuite) TestFilterContainersAndFillCollections_withStats() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		qp = graph.QueryParams{
+			Category:      path.EmailCategory,
+			ResourceOwner: "user_id",
+			Credentials:   suite.creds,
+		}
+		statusUpdater = func(*support.ConnectorOperationStatus) {}
+		allScope      = selectors.NewExchangeBackup(nil).MailFolders(selectors.Any())[0]
+		container1    = mockContainer{
+			id:          strPtr("1"),
+			displayName: strPtr("display_name_1"),
+			p:           path.Builder{}.Append("display_name_1"),
+		}
+		getter = mockStatsGetter{
+			mockGetter: mockGetter{
+				"1": {added: []string{"a1", "a2"}, newDelta: api.DeltaUpdate{URL: "delta_url"}},
+			},
+			stats: map[string]api.ContainerStats{
+				"1": {ItemCount: 2, Size: 2048},
+			},
+		}
+		collections = map[string]data.BackupCollection{}
+	)
+
+	err := filterContainersAndFillCollections(
+		ctx,
+		qp,
+		getter,
+		collections,
+		statusUpdater,
+		newMockResolver(container1),
+		allScope,
+		DeltaPaths{},
+		control.Options{FailFast: true},
+		fault.New(true))
+	require.NoError(t, err)
+
+	// one data collection, one metadata collection
+	require.Len(t, collections, 2)
+
+	col, ok := collections["1"].(*Collection)
+	require.True(t, ok)
+	assert.Len(t, col.added, 2)
+}
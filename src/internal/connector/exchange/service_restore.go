@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/trace"
+	"sort"
+	"strings"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
 	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/internal/common"
@@ -19,12 +22,14 @@ import (
 	"github.com/alcionai/corso/src/internal/data"
 	D "github.com/alcionai/corso/src/internal/diagnostics"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/transform"
 )
 
 // RestoreExchangeObject directs restore pipeline towards restore function
@@ -39,17 +44,23 @@ func RestoreExchangeObject(
 	destination, user string,
 	errs *fault.Errors,
 ) (*details.ExchangeInfo, error) {
-	if policy != control.Copy {
+	switch policy {
+	case control.Copy, control.Skip, control.Replace:
+	default:
 		return nil, clues.Wrap(clues.New(policy.String()), "policy not supported for Exchange restore").WithClues(ctx)
 	}
 
+	// Events carry no identifier that survives restores, so they are
+	// always restored as copies.
 	switch category {
 	case path.EmailCategory:
-		return RestoreMailMessage(ctx, bits, service, control.Copy, destination, user, errs)
+		return RestoreMailMessage(ctx, bits, service, policy, destination, user, errs)
 	case path.ContactsCategory:
-		return RestoreExchangeContact(ctx, bits, service, control.Copy, destination, user)
+		return RestoreExchangeContact(ctx, bits, service, policy, destination, user, errs)
 	case path.EventsCategory:
 		return RestoreExchangeEvent(ctx, bits, service, control.Copy, destination, user, errs)
+	case path.TasksCategory:
+		return RestoreExchangeTask(ctx, bits, service, destination, user)
 	default:
 		return nil, clues.Wrap(clues.New(category.String()), "not supported for Exchange restore")
 	}
@@ -60,6 +71,13 @@ func RestoreExchangeObject(
 // @destination M365 ID representing a M365 Contact_Folder
 // Returns an error if the input bits do not parse into a models.Contactable object
 // or if an error is encountered sending data to the M365 account.
+// The skip and replace policies look for a contact in the destination with the
+// same name and email addresses, so that repeated restores don't multiply copies
+// of the contact.  Skip leaves an existing contact as-is, while replace merges
+// the backed up properties into the existing contact.
+// A photo that fails to upload is recorded in errs without failing the
+// contact, since the contact already exists, and retrying it would create
+// a duplicate.
 // Post details: https://docs.microsoft.com/en-us/graph/api/user-post-contacts?view=graph-rest-1.0&tabs=go
 func RestoreExchangeContact(
 	ctx context.Context,
@@ -67,6 +85,7 @@ func RestoreExchangeContact(
 	service graph.Servicer,
 	cp control.CollisionPolicy,
 	destination, user string,
+	errs *fault.Errors,
 ) (*details.ExchangeInfo, error) {
 	contact, err := support.CreateContactFromBytes(bits)
 	if err != nil {
@@ -75,21 +94,182 @@ func RestoreExchangeContact(
 
 	ctx = clues.Add(ctx, "item_id", ptr.Val(contact.GetId()))
 
-	response, err := service.Client().UsersById(user).ContactFoldersById(destination).Contacts().Post(ctx, contact, nil)
+	info := api.ContactInfo(contact)
+	info.Size = int64(len(bits))
+
+	var existingID string
+
+	if cp == control.Skip || cp == control.Replace {
+		existingID, err = existingContact(ctx, service, user, destination, contact)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(existingID) > 0 && cp == control.Skip {
+			logger.Ctx(ctx).Debug("skipping restore of contact already in destination")
+			return info, nil
+		}
+	}
+
+	// graph rejects the photo as a contact property; it gets
+	// uploaded separately once the contact exists.
+	photo := api.PopContactPhoto(contact)
+
+	contactID, err := createOrMergeContact(ctx, service, user, destination, existingID, contact)
 	if err != nil {
-		return nil, clues.Wrap(err, "uploading Contact").WithClues(ctx).With(graph.ErrData(err)...)
+		return nil, err
 	}
 
-	if response == nil {
-		return nil, clues.New("nil response from post").WithClues(ctx)
+	if len(photo) > 0 {
+		err = service.Client().
+			UsersById(user).
+			ContactsById(contactID).
+			Photo().
+			Content().
+			Put(ctx, photo, nil)
+		if err != nil {
+			errs.Add(clues.Wrap(err, "uploading contact photo").WithClues(ctx).With(graph.ErrData(err)...))
+			return info, nil
+		}
 	}
 
-	info := api.ContactInfo(contact)
-	info.Size = int64(len(bits))
+	info.PhotoSize = int64(len(photo))
 
 	return info, nil
 }
 
+// createOrMergeContact posts the contact into the destination folder, or,
+// when an existingID is provided, patches the backed up properties onto the
+// existing contact.  Returns the ID of the created or merged contact.
+func createOrMergeContact(
+	ctx context.Context,
+	service graph.Servicer,
+	user, destination, existingID string,
+	contact models.Contactable,
+) (string, error) {
+	if len(existingID) == 0 {
+		resp, err := service.Client().UsersById(user).ContactFoldersById(destination).Contacts().Post(ctx, contact, nil)
+		if err != nil {
+			return "", clues.Wrap(err, "uploading Contact").WithClues(ctx).With(graph.ErrData(err)...)
+		}
+
+		if resp == nil {
+			return "", clues.New("nil response from post").WithClues(ctx)
+		}
+
+		return ptr.Val(resp.GetId()), nil
+	}
+
+	// the backed up identifiers belong to the original contact.
+	contact.SetId(nil)
+	contact.SetChangeKey(nil)
+	contact.SetParentFolderId(nil)
+
+	_, err := service.Client().
+		UsersById(user).
+		ContactFoldersById(destination).
+		ContactsById(existingID).
+		Patch(ctx, contact, nil)
+	if err != nil {
+		return "", clues.Wrap(err, "merging contact").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return existingID, nil
+}
+
+// existingContact returns the ID of the contact within the destination folder
+// that shares the name and email addresses of the original contact, or an empty
+// string if the folder holds no such contact.  Contacts without a name or email
+// address are never matched.
+func existingContact(
+	ctx context.Context,
+	service graph.Servicer,
+	user, destination string,
+	original models.Contactable,
+) (string, error) {
+	filter := contactCollisionFilter(original)
+	if len(filter) == 0 {
+		return "", nil
+	}
+
+	options := &users.ItemContactFoldersItemContactsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemContactFoldersItemContactsRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Select: []string{"id", "displayName", "emailAddresses"},
+		},
+	}
+
+	resp, err := service.Client().UsersById(user).ContactFoldersById(destination).Contacts().Get(ctx, options)
+	if err != nil {
+		return "", clues.Wrap(err, "looking up existing contact").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	for _, c := range resp.GetValue() {
+		if contactsCollide(original, c) {
+			return ptr.Val(c.GetId()), nil
+		}
+	}
+
+	return "", nil
+}
+
+// contactCollisionFilter produces the odata filter that narrows the contacts
+// in a folder down to those which may collide with the contact.  Returns an
+// empty string if the contact can't collide.
+func contactCollisionFilter(c models.Contactable) string {
+	name := ptr.Val(c.GetDisplayName())
+	if len(name) == 0 {
+		return ""
+	}
+
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+
+	addrs := contactAddresses(c)
+	if len(addrs) == 0 {
+		return "displayName eq " + quote(name)
+	}
+
+	return "emailAddresses/any(a:a/address eq " + quote(addrs[0]) + ")"
+}
+
+// contactsCollide is true if both contacts have the same name and the same
+// set of email addresses, ignoring case.
+func contactsCollide(a, b models.Contactable) bool {
+	if !strings.EqualFold(ptr.Val(a.GetDisplayName()), ptr.Val(b.GetDisplayName())) {
+		return false
+	}
+
+	aa, ba := contactAddresses(a), contactAddresses(b)
+	if len(aa) != len(ba) {
+		return false
+	}
+
+	for i := range aa {
+		if aa[i] != ba[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// contactAddresses produces the sorted, lower case, email addresses of the contact.
+func contactAddresses(c models.Contactable) []string {
+	addrs := []string{}
+
+	for _, ea := range c.GetEmailAddresses() {
+		if addr := ptr.Val(ea.GetAddress()); len(addr) > 0 {
+			addrs = append(addrs, strings.ToLower(addr))
+		}
+	}
+
+	sort.Strings(addrs)
+
+	return addrs
+}
+
 // RestoreExchangeEvent restores a contact to the @bits byte
 // representation of M365 event object.
 // @param destination is the M365 ID representing Calendar that will receive the event.
@@ -155,12 +335,49 @@ func RestoreExchangeEvent(
 	return info, et.Err()
 }
 
+// RestoreExchangeTask restores a task to the @bits byte
+// representation of a To Do task object.  Tasks, like
+// contacts and events, are always restored as copies.
+// @param destination is the M365 ID of the task list that will receive the task.
+// Post details: https://learn.microsoft.com/en-us/graph/api/todotasklist-post-tasks?view=graph-rest-1.0
+func RestoreExchangeTask(
+	ctx context.Context,
+	bits []byte,
+	service graph.Servicer,
+	destination, user string,
+) (*details.ExchangeInfo, error) {
+	task, err := support.CreateTaskFromBytes(bits)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating task from bytes").WithClues(ctx)
+	}
+
+	ctx = clues.Add(ctx, "item_id", ptr.Val(task.GetId()))
+
+	response, err := api.PostTask(ctx, service, user, destination, support.ToTodoTask(task))
+	if err != nil {
+		return nil, clues.Stack(err)
+	}
+
+	if response == nil {
+		return nil, clues.New("nil response from post").WithClues(ctx)
+	}
+
+	info := api.TaskInfo(task)
+	info.Size = int64(len(bits))
+
+	return info, nil
+}
+
 // RestoreMailMessage utility function to place an exchange.Mail
 // message into the user's M365 Exchange account.
 // @param bits - byte array representation of exchange.Message from Corso backstore
 // @param service - connector to M365 graph
 // @param cp - collision policy that directs restore workflow
 // @param destination - M365 Folder ID. Verified and sent by higher function. `copy` policy can use directly
+// The skip and replace policies look for a message in the destination with the
+// same internetMessageId, so that repeated restores don't multiply copies of the
+// message.  Skip leaves an existing message as-is, while replace deletes it
+// before restoring the message again.
 func RestoreMailMessage(
 	ctx context.Context,
 	bits []byte,
@@ -177,6 +394,29 @@ func RestoreMailMessage(
 
 	ctx = clues.Add(ctx, "item_id", ptr.Val(originalMessage.GetId()))
 
+	if cp == control.Skip || cp == control.Replace {
+		existingID, err := existingMessage(ctx, service, user, destination, originalMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(existingID) > 0 {
+			if cp == control.Skip {
+				logger.Ctx(ctx).Debug("skipping restore of message already in destination")
+
+				info := api.MailInfo(originalMessage)
+				info.Size = int64(len(bits))
+
+				return info, nil
+			}
+
+			err := service.Client().UsersById(user).MessagesById(existingID).Delete(ctx, nil)
+			if err != nil {
+				return nil, clues.Wrap(err, "deleting replaced message").WithClues(ctx).With(graph.ErrData(err)...)
+			}
+		}
+	}
+
 	var (
 		clone       = support.ToMessage(originalMessage)
 		valueID     = MailRestorePropertyTag
@@ -225,6 +465,42 @@ func RestoreMailMessage(
 	return info, nil
 }
 
+// existingMessage returns the ID of the message within the destination folder
+// that shares the internetMessageId of the original message, or an empty string
+// if the folder holds no such message.
+func existingMessage(
+	ctx context.Context,
+	service graph.Servicer,
+	user, destination string,
+	original models.Messageable,
+) (string, error) {
+	imid := ptr.Val(original.GetInternetMessageId())
+	if len(imid) == 0 {
+		return "", nil
+	}
+
+	filter := fmt.Sprintf("internetMessageId eq '%s'", strings.ReplaceAll(imid, "'", "''"))
+	options := &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Select: []string{"id", "internetMessageId"},
+		},
+	}
+
+	resp, err := service.Client().UsersById(user).MailFoldersById(destination).Messages().Get(ctx, options)
+	if err != nil {
+		return "", clues.Wrap(err, "looking up existing message").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	for _, m := range resp.GetValue() {
+		if ptr.Val(m.GetInternetMessageId()) == imid {
+			return ptr.Val(m.GetId()), nil
+		}
+	}
+
+	return "", nil
+}
+
 // attachmentBytes is a helper to retrieve the attachment content from a models.Attachmentable
 // TODO: Revisit how we retrieve/persist attachment content during backup so this is not needed
 func attachmentBytes(attachment models.Attachmentable) []byte {
@@ -300,6 +576,7 @@ func RestoreExchangeDataCollections(
 	creds account.M365Config,
 	gs graph.Servicer,
 	dest control.RestoreDestination,
+	opts control.Options,
 	dcs []data.RestoreCollection,
 	deets *details.Builder,
 	errs *fault.Errors,
@@ -308,30 +585,47 @@ func RestoreExchangeDataCollections(
 		directoryCaches = make(map[string]map[path.CategoryType]graph.ContainerResolver)
 		metrics         support.CollectionMetrics
 		userID          string
-		// TODO policy to be updated from external source after completion of refactoring
-		policy = control.Copy
-		et     = errs.Tracker()
+		policy          = opts.Collision
+		et              = errs.Tracker()
 	)
 
+	if policy == control.Unknown {
+		policy = control.Copy
+	}
+
 	if len(dcs) > 0 {
 		userID = dcs[0].FullPath().ResourceOwner()
-		ctx = clues.Add(ctx, "resource_owner", userID) // TODO: pii
+		ctx = opctx.Set(ctx, opctx.Metadata{ResourceOwner: userID})
+	}
+
+	ir, err := newItemRestorer(creds, gs, opts.EWS)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating item restorer").WithClues(ctx)
 	}
 
+	ir = paceMail(ir, opts.MailRestore.MessagesPerMinute)
+
 	for _, dc := range dcs {
 		if et.Err() != nil {
 			break
 		}
 
+		if dc.FullPath().Category() == path.SettingsCategory {
+			if opts.RestoreMailboxSettings {
+				metrics.Combine(restoreMailboxSettings(ctx, api.Client{Credentials: creds}.MailboxSettings(), dc, errs))
+			}
+
+			continue
+		}
+
 		userCaches := directoryCaches[userID]
 		if userCaches == nil {
 			directoryCaches[userID] = make(map[path.CategoryType]graph.ContainerResolver)
 			userCaches = directoryCaches[userID]
 		}
 
-		containerID, err := CreateContainerDestination(
+		containerID, err := ir.CreateDestination(
 			ctx,
-			creds,
 			dc.FullPath(),
 			dest.ContainerName,
 			userCaches,
@@ -341,7 +635,7 @@ func RestoreExchangeDataCollections(
 			continue
 		}
 
-		temp, canceled := restoreCollection(ctx, gs, dc, containerID, policy, deets, errs)
+		temp, canceled := restoreCollection(ctx, ir, dc, containerID, policy, deets, errs)
 
 		metrics.Combine(temp)
 
@@ -364,7 +658,7 @@ func RestoreExchangeDataCollections(
 // restoreCollection handles restoration of an individual collection.
 func restoreCollection(
 	ctx context.Context,
-	gs graph.Servicer,
+	ir itemRestorer,
 	dc data.RestoreCollection,
 	folderID string,
 	policy control.CollisionPolicy,
@@ -383,11 +677,11 @@ func restoreCollection(
 		user      = directory.ResourceOwner()
 	)
 
-	ctx = clues.Add(
-		ctx,
-		"full_path", directory,
-		"service", service,
-		"category", category)
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		Service:  service.String(),
+		Category: category.String(),
+	})
+	ctx = clues.Add(ctx, "full_path", directory)
 
 	colProgress, closer := observe.CollectionProgress(
 		ctx,
@@ -422,12 +716,19 @@ func restoreCollection(
 
 			byteArray := buf.Bytes()
 
-			info, err := RestoreExchangeObject(
+			if transform.Enabled(ictx) {
+				byteArray, err = transformItem(ictx, byteArray, category)
+				if err != nil {
+					errs.Add(err)
+					continue
+				}
+			}
+
+			info, err := ir.RestoreItem(
 				ictx,
 				byteArray,
 				category,
 				policy,
-				gs,
 				folderID,
 				user,
 				errs)
@@ -483,6 +784,8 @@ func CreateContainerDestination(
 		user           = directory.ResourceOwner()
 		category       = directory.Category()
 		directoryCache = caches[category]
+		restoreFolders = transform.Ctx(ctx).RestoreFolders(directory.Folders())
+		destFolders    = control.ContainerElements(destination)
 	)
 
 	// TODO(rkeepers): pass the api client into this func, rather than generating one.
@@ -493,7 +796,7 @@ func CreateContainerDestination(
 
 	switch category {
 	case path.EmailCategory:
-		folders := append([]string{destination}, directory.Folders()...)
+		folders := append(destFolders, restoreFolders...)
 
 		if directoryCache == nil {
 			acm := ac.Mail()
@@ -510,7 +813,7 @@ func CreateContainerDestination(
 
 		return establishMailRestoreLocation(
 			ctx,
-			ac,
+			ac.Mail(),
 			folders,
 			directoryCache,
 			user,
@@ -518,7 +821,7 @@ func CreateContainerDestination(
 			errs)
 
 	case path.ContactsCategory:
-		folders := append([]string{destination}, directory.Folders()...)
+		folders := append(destFolders, restoreFolders...)
 
 		if directoryCache == nil {
 			acc := ac.Contacts()
@@ -534,7 +837,7 @@ func CreateContainerDestination(
 
 		return establishContactsRestoreLocation(
 			ctx,
-			ac,
+			ac.Contacts(),
 			folders,
 			directoryCache,
 			user,
@@ -542,6 +845,14 @@ func CreateContainerDestination(
 			errs)
 
 	case path.EventsCategory:
+		// Calendars can't be nested, so the restore can't be created
+		// beneath other containers.
+		if len(destFolders) > 1 {
+			return "", clues.New("calendars can't be restored beneath other containers").
+				With("destination", destination).
+				WithClues(ctx)
+		}
+
 		dest := destination
 
 		if directoryCache == nil {
@@ -561,7 +872,7 @@ func CreateContainerDestination(
 			dest = did
 		}
 
-		folders := append([]string{dest}, directory.Folders()...)
+		folders := append([]string{dest}, restoreFolders...)
 
 		return establishEventsRestoreLocation(
 			ctx,
@@ -572,19 +883,63 @@ func CreateContainerDestination(
 			newCache,
 			errs)
 
+	case path.TasksCategory:
+		// Task lists can't be nested, so the restore can't be created
+		// beneath other containers.
+		if len(destFolders) > 1 {
+			return "", clues.New("task lists can't be restored beneath other containers").
+				With("destination", destination).
+				WithClues(ctx)
+		}
+
+		dest := destination
+
+		if directoryCache == nil {
+			act := ac.Tasks()
+			tfc := &taskFolderCache{
+				userID: user,
+				getter: act,
+				enumer: act,
+			}
+			caches[category] = tfc
+			newCache = true
+			directoryCache = tfc
+		} else if did := directoryCache.DestinationNameToID(dest); len(did) > 0 {
+			// task folders, like calendars, are cached by ID.
+			dest = did
+		}
+
+		folders := append([]string{dest}, restoreFolders...)
+
+		return establishTasksRestoreLocation(
+			ctx,
+			ac,
+			folders,
+			directoryCache,
+			user,
+			newCache,
+			errs)
+
 	default:
 		return "", clues.Wrap(fmt.Errorf("%T", category), "not support for exchange cache").WithClues(ctx)
 	}
 }
 
+// mailFolderCreator creates mail folders within their parent folder.
+type mailFolderCreator interface {
+	CreateMailFolderWithParent(ctx context.Context, user, folder, parentID string) (models.MailFolderable, error)
+}
+
 // establishMailRestoreLocation creates Mail folders in sequence
-// [root leaf1 leaf2] in a similar to a linked list.
+// [root leaf1 leaf2] in a similar to a linked list.  Folders that already
+// exist are reused, so that restores into an existing destination apply
+// the collision policy to the items already within it.
 // @param folders is the desired path from the root to the container
 // that the items will be restored into
 // @param isNewCache identifies if the cache is created and not populated
 func establishMailRestoreLocation(
 	ctx context.Context,
-	ac api.Client,
+	mc mailFolderCreator,
 	folders []string,
 	mfc graph.ContainerResolver,
 	user string,
@@ -598,6 +953,14 @@ func establishMailRestoreLocation(
 
 	ctx = clues.Add(ctx, "is_new_cache", isNewCache)
 
+	// the cache must hold the mailbox's folders before looking up the
+	// destination, or an existing destination would be created again.
+	if isNewCache {
+		if err := mfc.Populate(ctx, errs, rootFolderAlias); err != nil {
+			return "", errors.Wrap(err, "populating folder cache")
+		}
+	}
+
 	for _, folder := range folders {
 		pb = *pb.Append(folder)
 
@@ -607,7 +970,7 @@ func establishMailRestoreLocation(
 			continue
 		}
 
-		temp, err := ac.Mail().CreateMailFolderWithParent(ctx, user, folder, folderID)
+		temp, err := mc.CreateMailFolderWithParent(ctx, user, folder, folderID)
 		if err != nil {
 			// Should only error if cache malfunctions or incorrect parameters
 			return "", errors.Wrap(err, support.ConnectorStackErrorTrace(err))
@@ -615,17 +978,6 @@ func establishMailRestoreLocation(
 
 		folderID = *temp.GetId()
 
-		// Only populate the cache if we actually had to create it. Since we set
-		// newCache to false in this we'll only try to populate it once per function
-		// call even if we make a new cache.
-		if isNewCache {
-			if err := mfc.Populate(ctx, errs, rootFolderAlias); err != nil {
-				return "", errors.Wrap(err, "populating folder cache")
-			}
-
-			isNewCache = false
-		}
-
 		// NOOP if the folder is already in the cache.
 		if err = mfc.AddToCache(ctx, temp, false); err != nil {
 			return "", errors.Wrap(err, "adding folder to cache")
@@ -635,15 +987,23 @@ func establishMailRestoreLocation(
 	return folderID, nil
 }
 
+// contactFolderCreator finds and creates top-level contact folders.
+type contactFolderCreator interface {
+	GetContainerByName(ctx context.Context, user, name string) (models.ContactFolderable, error)
+	CreateContactFolder(ctx context.Context, user, name string) (models.ContactFolderable, error)
+}
+
 // establishContactsRestoreLocation creates Contact Folders in sequence
 // and updates the container resolver appropriately. Contact Folders are
 // displayed in a flat representation. Therefore, only the root can be populated and all content
-// must be restored into the root location.
+// must be restored into the root location.  An existing folder with the
+// root's name is reused, so that restores into an existing destination
+// apply the collision policy to the contacts already within it.
 // @param folders is the list of intended folders from root to leaf (e.g. [root ...])
 // @param isNewCache bool representation of whether Populate function needs to be run
 func establishContactsRestoreLocation(
 	ctx context.Context,
-	ac api.Client,
+	cc contactFolderCreator,
 	folders []string,
 	cfc graph.ContainerResolver,
 	user string,
@@ -657,12 +1017,19 @@ func establishContactsRestoreLocation(
 
 	ctx = clues.Add(ctx, "is_new_cache", isNewCache)
 
-	temp, err := ac.Contacts().CreateContactFolder(ctx, user, folders[0])
+	temp, err := cc.GetContainerByName(ctx, user, folders[0])
 	if err != nil {
 		return "", errors.Wrap(err, support.ConnectorStackErrorTrace(err))
 	}
 
-	folderID := *temp.GetId()
+	if temp == nil {
+		temp, err = cc.CreateContactFolder(ctx, user, folders[0])
+		if err != nil {
+			return "", errors.Wrap(err, support.ConnectorStackErrorTrace(err))
+		}
+	}
+
+	folderID := ptr.Val(temp.GetId())
 
 	if isNewCache {
 		if err := cfc.Populate(ctx, errs, folderID, folders[0]); err != nil {
@@ -714,3 +1081,43 @@ func establishEventsRestoreLocation(
 
 	return folderID, nil
 }
+
+// establishTasksRestoreLocation creates the task list that receives the
+// restored tasks.  Task lists have a flat hierarchy, so all tasks are
+// restored into the single destination list.
+func establishTasksRestoreLocation(
+	ctx context.Context,
+	ac api.Client,
+	folders []string,
+	tfc graph.ContainerResolver, // taskFolderCache
+	user string,
+	isNewCache bool,
+	errs *fault.Errors,
+) (string, error) {
+	cached, ok := tfc.PathInCache(folders[0])
+	if ok {
+		return cached, nil
+	}
+
+	ctx = clues.Add(ctx, "is_new_cache", isNewCache)
+
+	temp, err := ac.Tasks().CreateTaskList(ctx, user, folders[0])
+	if err != nil {
+		return "", errors.Wrap(err, support.ConnectorStackErrorTrace(err))
+	}
+
+	folderID := ptr.Val(temp.GetId())
+
+	if isNewCache {
+		if err = tfc.Populate(ctx, errs, folderID, folders[0]); err != nil {
+			return "", errors.Wrap(err, "populating task list cache")
+		}
+
+		displayable := api.TaskListDisplayable{TodoTaskListable: temp}
+		if err = tfc.AddToCache(ctx, displayable, true); err != nil {
+			return "", errors.Wrap(err, "adding new task list to cache")
+		}
+	}
+
+	return folderID, nil
+}
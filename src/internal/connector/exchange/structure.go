@@ -0,0 +1,257 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+// structureFolder is the folder, within the settings category, that holds
+// the folder hierarchies recorded by structure-only backups.
+const structureFolder = "structure"
+
+// FolderStructure describes a single folder recorded by a structure-only
+// backup.
+type FolderStructure struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentID,omitempty"`
+	// Location is the escaped path of display names leading to the folder.
+	Location  string `json:"location"`
+	ItemCount int64  `json:"itemCount"`
+	// Size is the total size of the folder's items in bytes, or 0 if unknown.
+	Size int64 `json:"size,omitempty"`
+}
+
+// StructurePath produces the path of the item holding the folder hierarchy
+// of the category, as recorded by a structure-only backup.
+func StructurePath(tenant, user string, category path.CategoryType) (path.Path, error) {
+	return path.Builder{}.
+		Append(structureFolder, category.String()).
+		ToDataLayerExchangePathForCategory(tenant, user, path.SettingsCategory, true)
+}
+
+// createStructureCollection produces a collection holding the folder
+// hierarchy of each category within the scopes, along with the count and
+// size of the items in each folder.  No item content is retrieved.
+func createStructureCollection(
+	ctx context.Context,
+	creds account.M365Config,
+	user string,
+	scopes []selectors.ExchangeScope,
+	su support.StatusUpdater,
+	errs *fault.Errors,
+) (data.BackupCollection, error) {
+	var (
+		ac      = api.Client{Credentials: creds}
+		folders = map[path.CategoryType]map[string]FolderStructure{}
+		et      = errs.Tracker()
+	)
+
+	for _, scope := range scopes {
+		if et.Err() != nil {
+			break
+		}
+
+		category := scope.Category().PathType()
+
+		fs, err := scopeStructure(
+			opctx.Set(ctx, opctx.Metadata{Category: category.String()}),
+			ac,
+			user,
+			scope,
+			errs)
+		if err != nil {
+			et.Add(err)
+			continue
+		}
+
+		if folders[category] == nil {
+			folders[category] = map[string]FolderStructure{}
+		}
+
+		// scopes may overlap, so folders are deduplicated by ID.
+		for _, f := range fs {
+			folders[category][f.ID] = f
+		}
+	}
+
+	if et.Err() != nil {
+		return nil, et.Err()
+	}
+
+	items := make([]graph.MetadataItem, 0, len(folders))
+
+	for category, fs := range folders {
+		sorted := make([]FolderStructure, 0, len(fs))
+		for _, f := range fs {
+			sorted = append(sorted, f)
+		}
+
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Location < sorted[j].Location
+		})
+
+		bs, err := json.Marshal(sorted)
+		if err != nil {
+			return nil, clues.Wrap(err, "serializing folder structure").WithClues(ctx)
+		}
+
+		items = append(items, graph.NewMetadataItem(category.String(), bs))
+	}
+
+	dir, err := path.Builder{}.
+		Append(structureFolder).
+		ToDataLayerExchangePathForCategory(creds.AzureTenantID, user, path.SettingsCategory, false)
+	if err != nil {
+		return nil, clues.Wrap(err, "making structure path").WithClues(ctx)
+	}
+
+	return graph.NewMetadataCollection(dir, items, su), nil
+}
+
+// scopeStructure describes the folders within a single scope.
+func scopeStructure(
+	ctx context.Context,
+	ac api.Client,
+	user string,
+	scope selectors.ExchangeScope,
+	errs *fault.Errors,
+) ([]FolderStructure, error) {
+	category := scope.Category().PathType()
+
+	getter, err := getterByType(ac, category)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	qp := graph.QueryParams{
+		Category:      category,
+		ResourceOwner: user,
+		Credentials:   ac.Credentials,
+	}
+
+	complete, closer := observe.MessageWithCompletion(ctx, observe.Bulletf(
+		"%s structure - %s",
+		observe.Safe(category.String()),
+		observe.PII(user)))
+	defer closer()
+	defer close(complete)
+
+	resolver, err := PopulateExchangeContainerResolver(ctx, qp, errs)
+	if err != nil {
+		return nil, clues.Wrap(err, "populating container cache")
+	}
+
+	folders := structureFolders(ctx, qp, getter, resolver, scope, errs)
+
+	complete <- struct{}{}
+
+	return folders, nil
+}
+
+// structureFolders describes each container within the scope.  Counts and
+// sizes come from the containers' own stats when the getter reports them.
+// Otherwise the container's item IDs are enumerated to count its items,
+// and the size is left unknown.
+func structureFolders(
+	ctx context.Context,
+	qp graph.QueryParams,
+	getter addedAndRemovedItemIDsGetter,
+	resolver graph.ContainerResolver,
+	scope selectors.ExchangeScope,
+	errs *fault.Errors,
+) []FolderStructure {
+	var (
+		stats   = preflightContainers(ctx, qp, getter, resolver, scope)
+		folders = []FolderStructure{}
+		et      = errs.Tracker()
+	)
+
+	for _, c := range resolver.Items() {
+		if et.Err() != nil {
+			break
+		}
+
+		currPath, locPath, ok := includeContainer(qp, c, scope)
+		if !ok {
+			continue
+		}
+
+		var (
+			cID = *c.GetId()
+			loc = currPath.Folder(true)
+		)
+
+		if locPath != nil {
+			loc = locPath.Folder(true)
+		}
+
+		f := FolderStructure{
+			ID:       cID,
+			ParentID: ptr.Val(c.GetParentFolderId()),
+			Location: loc,
+		}
+
+		if cs, ok := stats[cID]; ok {
+			f.ItemCount = cs.ItemCount
+			f.Size = cs.Size
+		} else {
+			added, _, _, err := getter.GetAddedAndRemovedItemIDs(ctx, qp.ResourceOwner, cID, "")
+			if err != nil {
+				// containers deleted during the backup are left out.
+				if !graph.IsErrDeletedInFlight(err) {
+					et.Add(err)
+				}
+
+				continue
+			}
+
+			f.ItemCount = int64(len(added))
+		}
+
+		folders = append(folders, f)
+	}
+
+	logger.Ctx(ctx).Infow("recorded folder structure", "num_folders", len(folders))
+
+	return folders
+}
+
+// StructureTree produces the folder tree recorded by a structure-only
+// backup, from the folders of each category.
+func StructureTree(folders map[path.CategoryType][]FolderStructure) (*details.TreeNode, error) {
+	counts := []details.FolderCount{}
+
+	for category, fs := range folders {
+		for _, f := range fs {
+			pb, err := path.Builder{}.SplitUnescapeAppend(f.Location)
+			if err != nil {
+				return nil, clues.Wrap(err, "parsing folder location").With("folder_id", f.ID)
+			}
+
+			counts = append(counts, details.FolderCount{
+				Elements: append([]string{category.String()}, pb.Elements()...),
+				Items:    int(f.ItemCount),
+				Size:     f.Size,
+			})
+		}
+	}
+
+	return details.TreeFromFolders(counts), nil
+}
@@ -0,0 +1,145 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type StructureUnitSuite struct {
+	tester.Suite
+	creds account.M365Config
+}
+
+func TestStructureUnitSuite(t *testing.T) {
+	suite.Run(t, &StructureUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StructureUnitSuite) SetupSuite() {
+	a := tester.NewMockM365Account(suite.T())
+	m365, err := a.M365Config()
+	require.NoError(suite.T(), err)
+	suite.creds = m365
+}
+
+func (suite *StructureUnitSuite) TestStructureFolders() {
+	var (
+		qp = graph.QueryParams{
+			Category:      path.EmailCategory,
+			ResourceOwner: "user_id",
+			Credentials:   suite.creds,
+		}
+		allScope = selectors.NewExchangeBackup(nil).MailFolders(selectors.Any())[0]
+		inbox    = mockContainer{
+			id:          strPtr("1"),
+			displayName: strPtr("Inbox"),
+			p:           path.Builder{}.Append("Inbox"),
+		}
+		child = mockContainer{
+			id:          strPtr("2"),
+			displayName: strPtr("a/b"),
+			parentID:    strPtr("1"),
+			p:           path.Builder{}.Append("Inbox", "a/b"),
+		}
+		resolver = newMockResolver(inbox, child)
+	)
+
+	table := []struct {
+		name   string
+		getter addedAndRemovedItemIDsGetter
+		expect []FolderStructure
+	}{
+		{
+			name: "stats",
+			getter: mockStatsGetter{
+				stats: map[string]api.ContainerStats{
+					"1": {ItemCount: 10, Size: 1024},
+					"2": {ItemCount: 5, Size: 512},
+				},
+			},
+			expect: []FolderStructure{
+				{ID: "1", Location: "Inbox", ItemCount: 10, Size: 1024},
+				{ID: "2", ParentID: "1", Location: `Inbox/a\/b`, ItemCount: 5, Size: 512},
+			},
+		},
+		{
+			name: "items are counted without stats",
+			getter: mockStatsGetter{
+				mockGetter: mockGetter{
+					"2": {added: []string{"a", "b", "c"}, removed: []string{"d"}},
+				},
+				stats: map[string]api.ContainerStats{
+					"1": {ItemCount: 10, Size: 1024},
+				},
+			},
+			expect: []FolderStructure{
+				{ID: "1", Location: "Inbox", ItemCount: 10, Size: 1024},
+				{ID: "2", ParentID: "1", Location: `Inbox/a\/b`, ItemCount: 3},
+			},
+		},
+		{
+			name: "containers deleted in flight are skipped",
+			getter: mockGetter{
+				"1": {added: []string{"a"}},
+				"2": {err: graph.ErrDeletedInFlight{Err: *common.EncapsulateError(assert.AnError)}},
+			},
+			expect: []FolderStructure{
+				{ID: "1", Location: "Inbox", ItemCount: 1},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+			errs := fault.New(true)
+
+			folders := structureFolders(ctx, qp, test.getter, resolver, allScope, errs)
+			assert.NoError(t, errs.Err())
+			assert.Equal(t, test.expect, folders)
+		})
+	}
+}
+
+func (suite *StructureUnitSuite) TestStructureTree() {
+	t := suite.T()
+
+	tree, err := StructureTree(map[path.CategoryType][]FolderStructure{
+		path.EmailCategory: {
+			{ID: "1", Location: "Inbox", ItemCount: 10, Size: 1024},
+			{ID: "2", ParentID: "1", Location: `Inbox/a\/b`, ItemCount: 5, Size: 512},
+		},
+		path.ContactsCategory: {
+			{ID: "3", Location: "Contacts"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 15, tree.Items)
+	assert.Equal(t, int64(1536), tree.Size)
+
+	inbox := tree.Find("email", "Inbox")
+	require.NotNil(t, inbox)
+	assert.Equal(t, 15, inbox.Items)
+
+	nested := tree.Find("email", "Inbox", "a/b")
+	require.NotNil(t, nested)
+	assert.Equal(t, 5, nested.Items)
+
+	contacts := tree.Find("contacts", "Contacts")
+	require.NotNil(t, contacts)
+	assert.Equal(t, 0, contacts.Items)
+}
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+var _ graph.ContainerResolver = &taskFolderCache{}
+
+// taskFolderCache resolves the user's To Do task lists.  Like
+// calendars, task lists have a flat hierarchy, and are stored by ID.
+type taskFolderCache struct {
+	*containerResolver
+	enumer       containersEnumerator
+	getter       containerGetter
+	userID       string
+	newAdditions map[string]string
+}
+
+// init ensures that the structure's fields are initialized.
+// Fields Initialized when cache == nil:
+// [tfc.cache]
+func (tfc *taskFolderCache) init() {
+	if tfc.containerResolver == nil {
+		tfc.containerResolver = newContainerResolver()
+	}
+}
+
+// Populate utility function for populating taskFolderCache.
+// The default Tasks list is included in the enumeration, so
+// no root list needs to be fetched separately.
+// @param baseID: ignored. Present to conform to interface
+func (tfc *taskFolderCache) Populate(
+	ctx context.Context,
+	errs *fault.Errors,
+	baseID string,
+	baseContainerPath ...string,
+) error {
+	tfc.init()
+
+	err := tfc.enumer.EnumerateContainers(
+		ctx,
+		tfc.userID,
+		"",
+		tfc.addFolder,
+		errs)
+	if err != nil {
+		return errors.Wrap(err, "enumerating containers")
+	}
+
+	if err := tfc.populatePaths(ctx, true); err != nil {
+		return errors.Wrap(err, "establishing task list paths")
+	}
+
+	return nil
+}
+
+// AddToCache adds container to map in field 'cache'
+// @returns error iff the required values are not accessible.
+func (tfc *taskFolderCache) AddToCache(ctx context.Context, f graph.Container, useIDInPath bool) error {
+	if err := checkIDAndName(f); err != nil {
+		return clues.Wrap(err, "validating container").WithClues(ctx)
+	}
+
+	tfc.init()
+
+	temp := graph.NewCacheFolder(
+		f,
+		path.Builder{}.Append(*f.GetId()), // storage path
+		path.Builder{}.Append(*f.GetDisplayName())) // display location
+
+	if len(tfc.newAdditions) == 0 {
+		tfc.newAdditions = map[string]string{}
+	}
+
+	tfc.newAdditions[*f.GetDisplayName()] = *f.GetId()
+
+	if err := tfc.addFolder(temp); err != nil {
+		delete(tfc.newAdditions, *f.GetDisplayName())
+		return clues.Wrap(err, "adding container").WithClues(ctx)
+	}
+
+	// Populate the path for this entry so calls to PathInCache succeed no matter
+	// when they're made.
+	_, _, err := tfc.IDToPath(ctx, *f.GetId(), true)
+	if err != nil {
+		delete(tfc.newAdditions, *f.GetDisplayName())
+		return errors.Wrap(err, "setting path to container id")
+	}
+
+	return nil
+}
+
+// DestinationNameToID returns the ID of the task list created for the
+// restore destination.  Task lists are cached by ID, not name, so the
+// destination's name can't be used for resolver lookups.
+func (tfc *taskFolderCache) DestinationNameToID(dest string) string {
+	return tfc.newAdditions[dest]
+}
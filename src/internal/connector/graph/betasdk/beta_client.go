@@ -9,6 +9,7 @@ import (
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 
 	i1a3c1a5501c5e41b7fd169f2d4c768dce9b096ac28fb5431bf02afcc57295411 "github.com/alcionai/corso/src/internal/connector/graph/betasdk/sites"
+	i4dbb4cb345b610c61555b303c6edae43565d2b003017c4b76e7d305209bd7310 "github.com/alcionai/corso/src/internal/connector/graph/betasdk/users"
 )
 
 // BetaClient the main entry point of the SDK, exposes the configuration and the fluent API.
@@ -19,8 +20,9 @@ import (
 // is disabled within the nested directories. Generic Kiota adapters do not support.
 //
 // Supported betasdk models are located within the models subdirectory
-// Supported Call source are located within the sites subdirectory
+// Supported Call source are located within the sites and users subdirectories
 // Specifics on `betaClient.SitesById(siteID).Pages` are located: sites/site_item_request_builder.go
+// Specifics on `betaClient.UsersById(userID).MailboxSettings()` are located: users/user_item_request_builder.go
 //
 // Changes to Sites Directory:
 // Access files send requests with an adapter's with ASync() support.
@@ -32,6 +34,7 @@ import (
 //
 // Required model files are identified as `modelFiles` in kiota-lock.json. Directory -> betasdk/models
 // Required access files are identified as `sitesFiles` in kiota-lock.json. Directory -> betasdk/sites
+// Required access files are identified as `usersFiles` in kiota-lock.json. Directory -> betasdk/users
 //
 // BetaClient minimal msgraph-beta-sdk-go for connecting to msgraph-beta-sdk-go
 // for retrieving `SharePoint.Pages`. Code is generated from kiota.dev.
@@ -92,6 +95,19 @@ func (m *BetaClient) SitesById(id string) *i1a3c1a5501c5e41b7fd169f2d4c768dce9b0
 	return i1a3c1a5501c5e41b7fd169f2d4c768dce9b096ac28fb5431bf02afcc57295411.NewSiteItemRequestBuilderInternal(urlTplParams, m.requestAdapter)
 }
 
+// UsersById provides operations to manage the collection of user entities.
+// Only the mailbox settings, found under `/users/{id}/mailboxSettings`, are supported.
+func (m *BetaClient) UsersById(id string) *i4dbb4cb345b610c61555b303c6edae43565d2b003017c4b76e7d305209bd7310.UserItemRequestBuilder {
+	urlTplParams := make(map[string]string)
+	for idx, item := range m.pathParameters {
+		urlTplParams[idx] = item
+	}
+	if id != "" {
+		urlTplParams["user%2Did"] = id
+	}
+	return i4dbb4cb345b610c61555b303c6edae43565d2b003017c4b76e7d305209bd7310.NewUserItemRequestBuilderInternal(urlTplParams, m.requestAdapter)
+}
+
 // Adapter() helper method to export Adapter for iterating
 func (m *BetaClient) Adapter() *msgraphsdk.GraphRequestAdapter {
 	return m.requestAdapter
@@ -37,11 +37,7 @@ func (suite *BetaClientSuite) SetupSuite() {
 
 func (suite *BetaClientSuite) TestCreateBetaClient() {
 	t := suite.T()
-	adpt, err := graph.CreateAdapter(
-		suite.credentials.AzureTenantID,
-		suite.credentials.AzureClientID,
-		suite.credentials.AzureClientSecret,
-	)
+	adpt, err := graph.CreateAdapter(suite.credentials)
 
 	require.NoError(t, err)
 
@@ -57,11 +53,7 @@ func (suite *BetaClientSuite) TestBasicClientGetFunctionality() {
 	ctx, flush := tester.NewContext()
 	defer flush()
 	t := suite.T()
-	adpt, err := graph.CreateAdapter(
-		suite.credentials.AzureTenantID,
-		suite.credentials.AzureClientID,
-		suite.credentials.AzureClientSecret,
-	)
+	adpt, err := graph.CreateAdapter(suite.credentials)
 
 	require.NoError(t, err)
 	client := NewBetaClient(adpt)
@@ -0,0 +1,156 @@
+package users
+
+import (
+	"context"
+
+	i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f "github.com/microsoft/kiota-abstractions-go"
+	i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87 "github.com/microsoftgraph/msgraph-sdk-go/models"
+	i7ad325c11fbf3db4d761c429267362d8b24daa1eda0081f914ebc3cdc85181a0 "github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// ItemMailboxSettingsRequestBuilder provides operations to manage the mailboxSettings property of the microsoft.graph.user entity.
+type ItemMailboxSettingsRequestBuilder struct {
+	// Path parameters for the request
+	pathParameters map[string]string
+	// The request adapter to use to execute the requests.
+	requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter
+	// Url template to use to build the URL for the current request builder
+	urlTemplate string
+}
+
+// ItemMailboxSettingsRequestBuilderGetQueryParameters settings for the primary mailbox of the signed-in user.
+//
+//nolint:lll
+type ItemMailboxSettingsRequestBuilderGetQueryParameters struct {
+	// Select properties to be returned
+	Select []string `uriparametername:"%24select"`
+}
+
+// ItemMailboxSettingsRequestBuilderGetRequestConfiguration configuration for the request such as headers, query parameters, and middleware options.
+//
+//nolint:wsl,revive,lll
+type ItemMailboxSettingsRequestBuilderGetRequestConfiguration struct {
+	// Request headers
+	Headers *i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestHeaders
+	// Request options
+	Options []i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestOption
+	// Request query parameters
+	QueryParameters *ItemMailboxSettingsRequestBuilderGetQueryParameters
+}
+
+// ItemMailboxSettingsRequestBuilderPatchRequestConfiguration configuration for the request such as headers, query parameters, and middleware options.
+//
+//nolint:wsl,revive,lll
+type ItemMailboxSettingsRequestBuilderPatchRequestConfiguration struct {
+	// Request headers
+	Headers *i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestHeaders
+	// Request options
+	Options []i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestOption
+}
+
+// NewItemMailboxSettingsRequestBuilderInternal instantiates a new ItemMailboxSettingsRequestBuilder and sets the default values.
+//
+//nolint:wsl,revive,lll
+func NewItemMailboxSettingsRequestBuilderInternal(pathParameters map[string]string, requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter) *ItemMailboxSettingsRequestBuilder {
+	m := &ItemMailboxSettingsRequestBuilder{}
+	m.urlTemplate = "{+baseurl}/users/{user%2Did}/mailboxSettings{?%24select}"
+	urlTplParams := make(map[string]string)
+	for idx, item := range pathParameters {
+		urlTplParams[idx] = item
+	}
+	m.pathParameters = urlTplParams
+	m.requestAdapter = requestAdapter
+	return m
+}
+
+// NewItemMailboxSettingsRequestBuilder instantiates a new ItemMailboxSettingsRequestBuilder and sets the default values.
+//
+//nolint:wsl,revive,lll
+func NewItemMailboxSettingsRequestBuilder(rawUrl string, requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter) *ItemMailboxSettingsRequestBuilder {
+	urlParams := make(map[string]string)
+	urlParams["request-raw-url"] = rawUrl
+	return NewItemMailboxSettingsRequestBuilderInternal(urlParams, requestAdapter)
+}
+
+// CreateGetRequestInformation settings for the primary mailbox of the signed-in user.
+//
+//nolint:wsl,revive,lll
+func (m *ItemMailboxSettingsRequestBuilder) CreateGetRequestInformation(ctx context.Context, requestConfiguration *ItemMailboxSettingsRequestBuilderGetRequestConfiguration) (*i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestInformation, error) {
+	requestInfo := i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.NewRequestInformation()
+	requestInfo.UrlTemplate = m.urlTemplate
+	requestInfo.PathParameters = m.pathParameters
+	requestInfo.Method = i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.GET
+	requestInfo.Headers.Add("Accept", "application/json")
+	if requestConfiguration != nil {
+		if requestConfiguration.QueryParameters != nil {
+			requestInfo.AddQueryParameters(*(requestConfiguration.QueryParameters))
+		}
+		requestInfo.Headers.AddAll(requestConfiguration.Headers)
+		requestInfo.AddRequestOptions(requestConfiguration.Options)
+	}
+	return requestInfo, nil
+}
+
+// CreatePatchRequestInformation update property mailboxSettings value.
+//
+//nolint:wsl,revive,lll
+func (m *ItemMailboxSettingsRequestBuilder) CreatePatchRequestInformation(ctx context.Context, body i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable, requestConfiguration *ItemMailboxSettingsRequestBuilderPatchRequestConfiguration) (*i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestInformation, error) {
+	requestInfo := i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.NewRequestInformation()
+	requestInfo.UrlTemplate = m.urlTemplate
+	requestInfo.PathParameters = m.pathParameters
+	requestInfo.Method = i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.PATCH
+	requestInfo.Headers.Add("Accept", "application/json")
+	err := requestInfo.SetContentFromParsable(ctx, m.requestAdapter, "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	if requestConfiguration != nil {
+		requestInfo.Headers.AddAll(requestConfiguration.Headers)
+		requestInfo.AddRequestOptions(requestConfiguration.Options)
+	}
+	return requestInfo, nil
+}
+
+// Get settings for the primary mailbox of the signed-in user.
+//
+//nolint:wsl,revive,lll
+func (m *ItemMailboxSettingsRequestBuilder) Get(ctx context.Context, requestConfiguration *ItemMailboxSettingsRequestBuilderGetRequestConfiguration) (i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable, error) {
+	requestInfo, err := m.CreateGetRequestInformation(ctx, requestConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	errorMapping := i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.ErrorMappings{
+		"4XX": i7ad325c11fbf3db4d761c429267362d8b24daa1eda0081f914ebc3cdc85181a0.CreateODataErrorFromDiscriminatorValue,
+		"5XX": i7ad325c11fbf3db4d761c429267362d8b24daa1eda0081f914ebc3cdc85181a0.CreateODataErrorFromDiscriminatorValue,
+	}
+	res, err := m.requestAdapter.Send(ctx, requestInfo, i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.CreateMailboxSettingsFromDiscriminatorValue, errorMapping)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res.(i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable), nil
+}
+
+// Patch update property mailboxSettings value.
+//
+//nolint:wsl,revive,lll
+func (m *ItemMailboxSettingsRequestBuilder) Patch(ctx context.Context, body i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable, requestConfiguration *ItemMailboxSettingsRequestBuilderPatchRequestConfiguration) (i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable, error) {
+	requestInfo, err := m.CreatePatchRequestInformation(ctx, body, requestConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	errorMapping := i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.ErrorMappings{
+		"4XX": i7ad325c11fbf3db4d761c429267362d8b24daa1eda0081f914ebc3cdc85181a0.CreateODataErrorFromDiscriminatorValue,
+		"5XX": i7ad325c11fbf3db4d761c429267362d8b24daa1eda0081f914ebc3cdc85181a0.CreateODataErrorFromDiscriminatorValue,
+	}
+	res, err := m.requestAdapter.Send(ctx, requestInfo, i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.CreateMailboxSettingsFromDiscriminatorValue, errorMapping)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res.(i4a838ef194e4c99e9f2c63ba10dab9cb120a89367c1d4ab0daa63bb424e20d87.MailboxSettingsable), nil
+}
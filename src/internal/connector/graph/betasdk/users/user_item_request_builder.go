@@ -0,0 +1,44 @@
+package users
+
+import (
+	i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f "github.com/microsoft/kiota-abstractions-go"
+)
+
+// UserItemRequestBuilder provides operations to manage the collection of user entities.
+type UserItemRequestBuilder struct {
+	// Path parameters for the request
+	pathParameters map[string]string
+	// The request adapter to use to execute the requests.
+	requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter
+	// Url template to use to build the URL for the current request builder
+	urlTemplate string
+}
+
+// NewUserItemRequestBuilderInternal instantiates a new UserItemRequestBuilder and sets the default values.
+//
+//nolint:wsl,revive,lll
+func NewUserItemRequestBuilderInternal(pathParameters map[string]string, requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter) *UserItemRequestBuilder {
+	m := &UserItemRequestBuilder{}
+	m.urlTemplate = "{+baseurl}/users/{user%2Did}"
+	urlTplParams := make(map[string]string)
+	for idx, item := range pathParameters {
+		urlTplParams[idx] = item
+	}
+	m.pathParameters = urlTplParams
+	m.requestAdapter = requestAdapter
+	return m
+}
+
+// NewUserItemRequestBuilder instantiates a new UserItemRequestBuilder and sets the default values.
+//
+//nolint:wsl,revive,lll
+func NewUserItemRequestBuilder(rawUrl string, requestAdapter i2ae4187f7daee263371cb1c977df639813ab50ffa529013b7437480d1ec0158f.RequestAdapter) *UserItemRequestBuilder {
+	urlParams := make(map[string]string)
+	urlParams["request-raw-url"] = rawUrl
+	return NewUserItemRequestBuilderInternal(urlParams, requestAdapter)
+}
+
+// MailboxSettings provides operations to manage the mailboxSettings property of the microsoft.graph.user entity.
+func (m *UserItemRequestBuilder) MailboxSettings() *ItemMailboxSettingsRequestBuilder {
+	return NewItemMailboxSettingsRequestBuilderInternal(m.pathParameters, m.requestAdapter)
+}
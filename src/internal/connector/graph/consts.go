@@ -24,4 +24,16 @@ const (
 	// PreviousPathFileName is the name of the file containing previous path(s) for a
 	// given endpoint.
 	PreviousPathFileName = "previouspath"
+
+	// SeriesFingerprintsFileName is the name of the file containing the
+	// fingerprints of recurring event series, grouped by calendar.
+	SeriesFingerprintsFileName = "seriesfingerprints"
+
+	// SiteAccessFileName is the name of the file containing the groups,
+	// memberships, and sharing links that grant access to a site.
+	SiteAccessFileName = "siteaccess"
+
+	// ItemContentFileName is the name of the file containing the content
+	// hashes of drive files, grouped by drive.
+	ItemContentFileName = "itemcontent"
 )
@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/cache"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+const loginAuthority = "https://login.microsoftonline.com/"
+
+var errNoSignedInAccount = errors.New("no signed in account")
+
+// signInPrompt receives the instructions for completing a device code
+// sign-in.  The user needs to see them, so they bypass the logger.
+var signInPrompt io.Writer = os.Stderr
+
+// delegatedCreds holds one credential per tenant, client, and token cache,
+// so that every adapter shares a single sign-in.
+var (
+	delegatedCredsMu sync.Mutex
+	delegatedCreds   = map[string]*delegatedCredential{}
+)
+
+// delegatedClient acquires tokens on behalf of a signed-in user.
+type delegatedClient interface {
+	// silent produces a token without user interaction, from the tokens
+	// cached by an earlier sign-in.
+	silent(ctx context.Context, scopes []string) (public.AuthResult, error)
+	// signIn produces a token after the user signs in.
+	signIn(ctx context.Context, scopes []string) (public.AuthResult, error)
+}
+
+// delegatedCredential authenticates with the permissions delegated to corso
+// by a signed-in user, for tenants that can't grant application permissions.
+// The user signs in the first time a token is needed; later tokens are
+// refreshed silently.
+type delegatedCredential struct {
+	mu     sync.Mutex
+	client delegatedClient
+}
+
+// GetToken fulfills the azcore.TokenCredential interface.
+func (dc *delegatedCredential) GetToken(
+	ctx context.Context,
+	opts policy.TokenRequestOptions,
+) (azcore.AccessToken, error) {
+	// only one sign-in may be in flight at a time.  Callers waiting on it
+	// receive their tokens silently once it completes.
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	ar, err := dc.client.silent(ctx, opts.Scopes)
+	if err != nil {
+		logger.Ctx(ctx).Debugw("acquiring delegated token silently", "error", err)
+
+		ar, err = dc.client.signIn(ctx, opts.Scopes)
+	}
+
+	if err != nil {
+		return azcore.AccessToken{}, clues.Wrap(err, "signing in to m365")
+	}
+
+	return azcore.AccessToken{Token: ar.AccessToken, ExpiresOn: ar.ExpiresOn}, nil
+}
+
+// newDelegatedCredential produces the delegated credential for the config,
+// creating it on first use.
+func newDelegatedCredential(creds account.M365Config) (*delegatedCredential, error) {
+	key := creds.AzureTenantID + "/" + creds.AzureClientID + "/" + creds.TokenCachePath
+
+	delegatedCredsMu.Lock()
+	defer delegatedCredsMu.Unlock()
+
+	if dc, ok := delegatedCreds[key]; ok {
+		return dc, nil
+	}
+
+	opts := []public.Option{public.WithAuthority(loginAuthority + creds.AzureTenantID)}
+
+	var tc *fileTokenCache
+
+	if len(creds.TokenCachePath) > 0 {
+		tc = &fileTokenCache{path: creds.TokenCachePath}
+		opts = append(opts, public.WithCache(tc))
+	}
+
+	if cp := CertPinsFromEnv(); cp.Enabled() {
+		opts = append(opts, public.WithHTTPClient(&http.Client{Transport: cp.transport()}))
+	}
+
+	pc, err := public.New(creds.AzureClientID, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating m365 delegated identity")
+	}
+
+	dc := &delegatedCredential{client: msalClient{pc: pc, mode: creds.AuthMode, cache: tc}}
+	delegatedCreds[key] = dc
+
+	return dc, nil
+}
+
+// msalClient acquires delegated tokens through the msal public client.
+type msalClient struct {
+	pc    public.Client
+	mode  account.M365AuthMode
+	cache *fileTokenCache
+}
+
+func (mc msalClient) silent(ctx context.Context, scopes []string) (public.AuthResult, error) {
+	defer mc.cache.logErrs(ctx)
+
+	accts := mc.pc.Accounts()
+	if len(accts) == 0 {
+		return public.AuthResult{}, errNoSignedInAccount
+	}
+
+	return mc.pc.AcquireTokenSilent(ctx, scopes, public.WithSilentAccount(accts[0]))
+}
+
+func (mc msalClient) signIn(ctx context.Context, scopes []string) (public.AuthResult, error) {
+	defer mc.cache.logErrs(ctx)
+
+	if m, _ := account.ParseM365AuthMode(string(mc.mode)); m == account.AuthInteractive {
+		return mc.pc.AcquireTokenInteractive(ctx, scopes)
+	}
+
+	code, err := mc.pc.AcquireTokenByDeviceCode(ctx, scopes)
+	if err != nil {
+		return public.AuthResult{}, errors.Wrap(err, "requesting device code")
+	}
+
+	fmt.Fprintln(signInPrompt, code.Result.Message)
+
+	return code.AuthenticationResult(ctx)
+}
+
+// fileTokenCache persists the msal token cache to a file readable only by
+// the current user.  The cache holds refresh tokens, and must be protected
+// like any other secret.
+type fileTokenCache struct {
+	path string
+
+	mu sync.Mutex
+	// errs holds the failures to read or write the cache.  The msal cache
+	// interface can't return them, so they're held until they can be
+	// logged with the context of the token request.
+	errs []error
+}
+
+// Replace fulfills the cache.ExportReplace interface.
+func (c *fileTokenCache) Replace(u cache.Unmarshaler, _ string) {
+	bs, err := os.ReadFile(c.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.record(clues.Wrap(err, "reading token cache"))
+		}
+
+		return
+	}
+
+	if err := u.Unmarshal(bs); err != nil {
+		c.record(clues.Wrap(err, "parsing token cache"))
+	}
+}
+
+// Export fulfills the cache.ExportReplace interface.
+func (c *fileTokenCache) Export(m cache.Marshaler, _ string) {
+	if err := c.write(m); err != nil {
+		c.record(clues.Stack(err))
+	}
+}
+
+func (c *fileTokenCache) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errs = append(c.errs, err)
+}
+
+// logErrs logs, and clears, the failures recorded since the last call.
+// The cache is optional, so a nil cache logs nothing.
+func (c *fileTokenCache) logErrs(ctx context.Context) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	errs := c.errs
+	c.errs = nil
+	c.mu.Unlock()
+
+	for _, err := range errs {
+		logger.Ctx(ctx).
+			With("err", err, "token_cache_path", c.path).
+			Infow("accessing token cache", clues.InErr(err).Slice()...)
+	}
+}
+
+func (c *fileTokenCache) write(m cache.Marshaler) error {
+	bs, err := m.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "serializing token cache")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return errors.Wrap(err, "creating token cache directory")
+	}
+
+	// write to a temp file and swap it in, so that a failed write can't
+	// corrupt the cache of an earlier sign-in.
+	tmp := c.path + ".tmp"
+
+	if err := os.WriteFile(tmp, bs, 0o600); err != nil {
+		return errors.Wrap(err, "writing token cache")
+	}
+
+	return errors.Wrap(os.Rename(tmp, c.path), "replacing token cache")
+}
@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/credentials"
+)
+
+type DelegatedUnitSuite struct {
+	tester.Suite
+}
+
+func TestDelegatedUnitSuite(t *testing.T) {
+	suite.Run(t, &DelegatedUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// mockDelegatedClient hands out a token once the user has signed in.
+type mockDelegatedClient struct {
+	mu        sync.Mutex
+	signedIn  bool
+	signIns   int
+	signInErr error
+}
+
+func (c *mockDelegatedClient) silent(context.Context, []string) (public.AuthResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.signedIn {
+		return public.AuthResult{}, errNoSignedInAccount
+	}
+
+	return public.AuthResult{AccessToken: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func (c *mockDelegatedClient) signIn(context.Context, []string) (public.AuthResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.signIns++
+
+	if c.signInErr != nil {
+		return public.AuthResult{}, c.signInErr
+	}
+
+	c.signedIn = true
+
+	return public.AuthResult{AccessToken: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func (suite *DelegatedUnitSuite) TestGetToken() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t      = suite.T()
+		client = &mockDelegatedClient{}
+		dc     = &delegatedCredential{client: client}
+		wg     sync.WaitGroup
+		opts   = policy.TokenRequestOptions{Scopes: []string{"https://graph.microsoft.com/.default"}}
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			tok, err := dc.GetToken(ctx, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, "token", tok.Token)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, client.signIns, "the user signs in once")
+}
+
+func (suite *DelegatedUnitSuite) TestGetToken_signInFailure() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	dc := &delegatedCredential{client: &mockDelegatedClient{signInErr: assert.AnError}}
+
+	_, err := dc.GetToken(ctx, policy.TokenRequestOptions{})
+	assert.ErrorIs(suite.T(), err, assert.AnError)
+}
+
+func (suite *DelegatedUnitSuite) TestNewCredential_delegated() {
+	t := suite.T()
+
+	creds := account.M365Config{
+		M365:           credentials.M365{AzureClientID: "cid"},
+		AzureTenantID:  "tid",
+		AuthMode:       account.AuthDeviceCode,
+		TokenCachePath: filepath.Join(t.TempDir(), "token_cache.json"),
+	}
+
+	cred, err := NewCredential(creds)
+	require.NoError(t, err)
+	assert.IsType(t, &delegatedCredential{}, cred)
+
+	again, err := NewCredential(creds)
+	require.NoError(t, err)
+	assert.Same(t, cred, again, "adapters share a single sign-in")
+}
+
+// mockSerializer stands in for the msal cache.
+type mockSerializer struct {
+	data []byte
+}
+
+func (s *mockSerializer) Marshal() ([]byte, error) {
+	return s.data, nil
+}
+
+func (s *mockSerializer) Unmarshal(bs []byte) error {
+	s.data = bs
+	return nil
+}
+
+func (suite *DelegatedUnitSuite) TestFileTokenCache() {
+	var (
+		t    = suite.T()
+		path = filepath.Join(t.TempDir(), "nested", "token_cache.json")
+		c    = &fileTokenCache{path: path}
+		in   = &mockSerializer{data: []byte(`{"refresh":"token"}`)}
+		out  = &mockSerializer{}
+	)
+
+	// a missing cache leaves the msal cache empty.
+	c.Replace(out, "")
+	assert.Empty(t, out.data)
+
+	c.Export(in, "")
+	c.Replace(out, "")
+	assert.Equal(t, in.data, out.data)
+
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm(), "cache is private to the user")
+	}
+}
+
+func (suite *DelegatedUnitSuite) TestFileTokenCache_recordsErrors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t = suite.T()
+		// a directory can't be read or written as the cache file.
+		c   = &fileTokenCache{path: t.TempDir()}
+		out = &mockSerializer{}
+	)
+
+	c.Replace(out, "")
+	c.Export(&mockSerializer{data: []byte("{}")}, "")
+	assert.Len(t, c.errs, 2)
+
+	c.logErrs(ctx)
+	assert.Empty(t, c.errs, "logged errors are cleared")
+
+	// caches are optional.
+	var nilCache *fileTokenCache
+	nilCache.logErrs(ctx)
+}
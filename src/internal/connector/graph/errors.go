@@ -19,6 +19,7 @@ import (
 // ---------------------------------------------------------------------------
 
 const (
+	errCodeAccessDenied                = "accessDenied"
 	errCodeActivityLimitReached        = "activityLimitReached"
 	errCodeItemNotFound                = "ErrorItemNotFound"
 	errCodeEmailFolderNotFound         = "ErrorSyncFolderNotFound"
@@ -28,6 +29,7 @@ const (
 	errCodeResourceNotFound            = "ResourceNotFound"
 	errCodeRequestResourceNotFound     = "Request_ResourceNotFound"
 	errCodeMailboxNotEnabledForRESTAPI = "MailboxNotEnabledForRESTAPI"
+	errCodeImageNotFound               = "ImageNotFound"
 )
 
 var (
@@ -80,6 +82,17 @@ func IsErrInvalidDelta(err error) bool {
 	return false
 }
 
+// errSimulatedDeltaExpiry is the cause of every simulated delta expiry.
+var errSimulatedDeltaExpiry = errors.New("delta token expired by simulation")
+
+// SimulatedDeltaExpiry produces the invalid delta error that graph returns
+// when a delta token has expired.  Pagers configured to expire deltas on
+// demand return it in place of their first page, so that the incremental
+// fallbacks can be exercised without waiting on graph to expire a token.
+func SimulatedDeltaExpiry() error {
+	return ErrInvalidDelta{Err: *common.EncapsulateError(errSimulatedDeltaExpiry)}
+}
+
 func IsErrExchangeMailFolderNotFound(err error) bool {
 	return hasErrorCode(err, errCodeResourceNotFound, errCodeMailboxNotEnabledForRESTAPI)
 }
@@ -88,6 +101,22 @@ func IsErrUserNotFound(err error) bool {
 	return hasErrorCode(err, errCodeRequestResourceNotFound)
 }
 
+// Hybrid tenants can keep mailboxes on-premises, where graph can't
+// create items in them.
+func IsErrHybridMailbox(err error) bool {
+	return hasErrorCode(err, errCodeMailboxNotEnabledForRESTAPI)
+}
+
+// Contacts and users without a photo report the photo as missing,
+// rather than returning an empty photo.
+func IsErrPhotoNotFound(err error) bool {
+	return hasErrorCode(err, errCodeItemNotFound, errCodeImageNotFound)
+}
+
+func IsErrAccessDenied(err error) bool {
+	return hasErrorCode(err, errCodeAccessDenied)
+}
+
 // Timeout errors are identified for tracking the need to retry calls.
 // Other delay errors, like throttling, are already handled by the
 // graph client's built-in retries.
@@ -163,6 +192,53 @@ func IsInternalServerError(err error) bool {
 	return errors.As(err, &e)
 }
 
+// ErrClass names the kind of failure behind the error.  Error messages
+// can hold mailbox addresses, file names, and paths, so the class never
+// includes the message; only well-known kinds, or graph's error code, are
+// reported.  Errors of any other kind are classed as "other".
+func ErrClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case IsErrThrottled(err):
+		return "throttled"
+	case IsErrTimeout(err):
+		return "timeout"
+	case IsErrUnauthorized(err):
+		return "unauthorized"
+	case IsErrAccessDenied(err):
+		return "access_denied"
+	case IsErrInvalidDelta(err):
+		return "invalid_delta"
+	case IsErrDeletedInFlight(err):
+		return "deleted_in_flight"
+	case IsInternalServerError(err),
+		errors.Is(err, Err503ServiceUnavailable),
+		errors.Is(err, Err504GatewayTimeout):
+		return "server_error"
+	}
+
+	var oDataError *odataerrors.ODataError
+	if errors.As(err, &oDataError) && oDataError.GetError().GetCode() != nil {
+		return "graph:" + *oDataError.GetError().GetCode()
+	}
+
+	return "other"
+}
+
+// ErrorCode produces graph's error code (ex: "itemNotFound") for the
+// error, or an empty string if the error didn't come from graph.
+func ErrorCode(err error) string {
+	var oDataError odataerrors.ODataErrorable
+	if !errors.As(err, &oDataError) || oDataError.GetError() == nil || oDataError.GetError().GetCode() == nil {
+		return ""
+	}
+
+	return *oDataError.GetError().GetCode()
+}
+
 // ---------------------------------------------------------------------------
 // error parsers
 // ---------------------------------------------------------------------------
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
@@ -94,6 +95,11 @@ func (suite *GraphErrorsUnitSuite) TestIsErrInvalidDelta() {
 			err:    ErrInvalidDelta{Err: *common.EncapsulateError(assert.AnError)},
 			expect: assert.True,
 		},
+		{
+			name:   "simulated expiry",
+			err:    SimulatedDeltaExpiry(),
+			expect: assert.True,
+		},
 		{
 			name:   "non-matching oDataErr",
 			err:    odErr("fnords"),
@@ -112,6 +118,40 @@ func (suite *GraphErrorsUnitSuite) TestIsErrInvalidDelta() {
 	}
 }
 
+func (suite *GraphErrorsUnitSuite) TestIsErrHybridMailbox() {
+	table := []struct {
+		name   string
+		err    error
+		expect assert.BoolAssertionFunc
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: assert.False,
+		},
+		{
+			name:   "non-matching",
+			err:    assert.AnError,
+			expect: assert.False,
+		},
+		{
+			name:   "non-matching oDataErr",
+			err:    odErr(errCodeAccessDenied),
+			expect: assert.False,
+		},
+		{
+			name:   "not-enabled-for-rest oDataErr",
+			err:    odErr(errCodeMailboxNotEnabledForRESTAPI),
+			expect: assert.True,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), IsErrHybridMailbox(test.err))
+		})
+	}
+}
+
 func (suite *GraphErrorsUnitSuite) TestIsErrTimeout() {
 	table := []struct {
 		name   string
@@ -247,3 +287,81 @@ func (suite *GraphErrorsUnitSuite) TestIsInternalServerError() {
 		})
 	}
 }
+
+func (suite *GraphErrorsUnitSuite) TestErrClass() {
+	table := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: "",
+		},
+		{
+			name:   "unknown kind",
+			err:    errors.New("getting item user@example.com/Documents/secret.docx"),
+			expect: "other",
+		},
+		{
+			name:   "throttled",
+			err:    errors.Wrap(Err429TooManyRequests, "user@example.com"),
+			expect: "throttled",
+		},
+		{
+			name:   "deleted in flight",
+			err:    errors.Wrap(odErr(errCodeItemNotFound), "user@example.com"),
+			expect: "deleted_in_flight",
+		},
+		{
+			name:   "graph code",
+			err:    errors.Wrap(odErr("fnords"), "user@example.com"),
+			expect: "graph:fnords",
+		},
+		{
+			name:   "canceled",
+			err:    errors.Wrap(context.Canceled, "user@example.com"),
+			expect: "canceled",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, ErrClass(test.err))
+		})
+	}
+}
+
+func (suite *GraphErrorsUnitSuite) TestErrorCode() {
+	table := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{
+			name:   "nil",
+			err:    nil,
+			expect: "",
+		},
+		{
+			name:   "not graph",
+			err:    assert.AnError,
+			expect: "",
+		},
+		{
+			name:   "no code",
+			err:    &odataerrors.ODataError{},
+			expect: "",
+		},
+		{
+			name:   "wrapped graph code",
+			err:    errors.Wrap(odErr(errCodeItemNotFound), "user@example.com"),
+			expect: errCodeItemNotFound,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, ErrorCode(test.err))
+		})
+	}
+}
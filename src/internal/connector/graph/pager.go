@@ -0,0 +1,212 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alcionai/clues"
+	backoff "github.com/cenkalti/backoff/v4"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph/api"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// ---------------------------------------------------------------------------
+// generic pager
+// ---------------------------------------------------------------------------
+
+// Pager is the minimal interface for walking a paged graph api query.
+// Implementations wrap a single request builder and are responsible for
+// producing the page values as type T.  Pagination, retries, and error
+// handling are provided by EnumeratePages and GetAllValues.
+type Pager[T any] interface {
+	GetPage(context.Context) (api.PageLinker, error)
+	SetNext(nextLink string)
+	ValuesIn(api.PageLinker) ([]T, error)
+}
+
+// PageValues extracts the values from a page that complies with the
+// { GetValue() []T } interface.  Most Pager.ValuesIn implementations
+// can defer to this func.
+func PageValues[T any](l api.PageLinker) ([]T, error) {
+	page, ok := l.(interface{ GetValue() []T })
+	if !ok {
+		return nil, clues.Wrap(fmt.Errorf("%T", l), "does not comply with the GetValue() interface")
+	}
+
+	return page.GetValue(), nil
+}
+
+// PagerOptions configures the retry and throttling behavior of
+// EnumeratePages and GetAllValues.
+type PagerOptions struct {
+	// MaxRetries is the number of times a single page request gets
+	// re-attempted after a retriable failure.  Zero disables retries.
+	MaxRetries int
+	// NewBackOff produces the delay strategy used between retries.
+	// Defaults to an exponential backoff.
+	NewBackOff func() backoff.BackOff
+	// IsRetriable identifies page errors that should be retried.
+	// Defaults to timeout, throttling, and internal server errors.
+	IsRetriable func(error) bool
+	// OnThrottle, if populated, gets called each time a page request
+	// is throttled, prior to waiting out the backoff delay.
+	OnThrottle func(ctx context.Context, attempt int, wait time.Duration)
+}
+
+// DefaultPagerOptions produces the standard pager configuration.
+func DefaultPagerOptions() PagerOptions {
+	return PagerOptions{
+		MaxRetries:  numberOfRetries,
+		NewBackOff:  newPagerBackOff,
+		IsRetriable: isRetriablePageErr,
+	}
+}
+
+func newPagerBackOff() backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = defaultDelay
+	bo.MaxElapsedTime = absoluteMaxDelaySeconds * time.Second
+
+	return bo
+}
+
+func isRetriablePageErr(err error) bool {
+	return IsErrTimeout(err) || IsErrThrottled(err) || IsInternalServerError(err)
+}
+
+// getPage retrieves the next page from the pager, retrying the
+// request according to the provided options.
+func getPage[T any](
+	ctx context.Context,
+	pager Pager[T],
+	opts PagerOptions,
+) (api.PageLinker, error) {
+	var (
+		isRetriable = opts.IsRetriable
+		bo          backoff.BackOff
+	)
+
+	if isRetriable == nil {
+		isRetriable = isRetriablePageErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		page, err := pager.GetPage(ctx)
+		if err == nil {
+			return page, nil
+		}
+
+		if attempt >= opts.MaxRetries || !isRetriable(err) {
+			return nil, clues.Stack(err).
+				WithClues(ctx).
+				With("retry_count", attempt).
+				With(ErrData(err)...)
+		}
+
+		if bo == nil {
+			if opts.NewBackOff != nil {
+				bo = opts.NewBackOff()
+			} else {
+				bo = newPagerBackOff()
+			}
+		}
+
+		wait := bo.NextBackOff()
+		if wait == backoff.Stop {
+			return nil, clues.Wrap(err, "page retry backoff exhausted").
+				WithClues(ctx).
+				With("retry_count", attempt).
+				With(ErrData(err)...)
+		}
+
+		if IsErrThrottled(err) && opts.OnThrottle != nil {
+			opts.OnThrottle(ctx, attempt+1, wait)
+		}
+
+		logger.Ctx(ctx).Debugw("retrying page request", "retry_count", attempt+1, "wait", wait, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, clues.Stack(ctx.Err()).WithClues(ctx)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// EnumeratePages walks every page produced by the pager, calling fn on
+// each value.  Errors returned by fn are considered recoverable, and get
+// aggregated into errs.  Failure to retrieve a page is non-recoverable.
+func EnumeratePages[T any](
+	ctx context.Context,
+	pager Pager[T],
+	opts PagerOptions,
+	fn func(T) error,
+	errs *fault.Errors,
+) error {
+	et := errs.Tracker()
+
+	for {
+		if et.Err() != nil {
+			return et.Err()
+		}
+
+		page, err := getPage(ctx, pager, opts)
+		if err != nil {
+			return err
+		}
+
+		vals, err := pager.ValuesIn(page)
+		if err != nil {
+			return clues.Wrap(err, "extracting values from page").WithClues(ctx)
+		}
+
+		for _, v := range vals {
+			if et.Err() != nil {
+				return et.Err()
+			}
+
+			et.Add(fn(v))
+		}
+
+		link, ok := ptr.ValOK(page.GetOdataNextLink())
+		if !ok || len(link) == 0 {
+			break
+		}
+
+		pager.SetNext(link)
+	}
+
+	return et.Err()
+}
+
+// GetAllValues walks every page produced by the pager, and returns the
+// aggregate of all page values.
+func GetAllValues[T any](
+	ctx context.Context,
+	pager Pager[T],
+	opts PagerOptions,
+) ([]T, error) {
+	var (
+		results = []T{}
+		errs    = fault.New(true)
+	)
+
+	err := EnumeratePages(
+		ctx,
+		pager,
+		opts,
+		func(v T) error {
+			results = append(results, v)
+			return nil
+		},
+		errs)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
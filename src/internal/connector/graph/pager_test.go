@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/graph/api"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type mockPage struct {
+	values []string
+	next   *string
+}
+
+func (p mockPage) GetOdataNextLink() *string { return p.next }
+func (p mockPage) GetValue() []string        { return p.values }
+
+type mockPageResult struct {
+	page mockPage
+	err  error
+}
+
+type mockPager struct {
+	results []mockPageResult
+	idx     int
+	nexts   []string
+}
+
+func (p *mockPager) GetPage(context.Context) (api.PageLinker, error) {
+	if p.idx >= len(p.results) {
+		return nil, assert.AnError
+	}
+
+	r := p.results[p.idx]
+	p.idx++
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return r.page, nil
+}
+
+func (p *mockPager) SetNext(link string) {
+	p.nexts = append(p.nexts, link)
+}
+
+func (p *mockPager) ValuesIn(pl api.PageLinker) ([]string, error) {
+	return PageValues[string](pl)
+}
+
+func noDelayOpts(retries int) PagerOptions {
+	opts := DefaultPagerOptions()
+	opts.MaxRetries = retries
+	opts.NewBackOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+
+	return opts
+}
+
+type PagerUnitSuite struct {
+	tester.Suite
+}
+
+func TestPagerUnitSuite(t *testing.T) {
+	suite.Run(t, &PagerUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *PagerUnitSuite) TestGetAllValues() {
+	next := "next"
+	empty := ""
+
+	table := []struct {
+		name      string
+		results   []mockPageResult
+		retries   int
+		expect    []string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "single page",
+			results: []mockPageResult{
+				{page: mockPage{values: []string{"a", "b"}}},
+			},
+			expect:    []string{"a", "b"},
+			expectErr: assert.NoError,
+		},
+		{
+			name: "multiple pages",
+			results: []mockPageResult{
+				{page: mockPage{values: []string{"a"}, next: &next}},
+				{page: mockPage{values: []string{"b"}, next: &empty}},
+			},
+			expect:    []string{"a", "b"},
+			expectErr: assert.NoError,
+		},
+		{
+			name: "retried throttling",
+			results: []mockPageResult{
+				{page: mockPage{values: []string{"a"}, next: &next}},
+				{err: Err429TooManyRequests},
+				{page: mockPage{values: []string{"b"}}},
+			},
+			retries:   1,
+			expect:    []string{"a", "b"},
+			expectErr: assert.NoError,
+		},
+		{
+			name: "too many retries",
+			results: []mockPageResult{
+				{err: Err429TooManyRequests},
+				{err: Err429TooManyRequests},
+			},
+			retries:   1,
+			expectErr: assert.Error,
+		},
+		{
+			name: "non-retriable error",
+			results: []mockPageResult{
+				{err: assert.AnError},
+				{page: mockPage{values: []string{"a"}}},
+			},
+			retries:   3,
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			pager := &mockPager{results: test.results}
+
+			result, err := GetAllValues[string](ctx, pager, noDelayOpts(test.retries))
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
+
+func (suite *PagerUnitSuite) TestEnumeratePages_recoverableErrors() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		next  = "next"
+		seen  = []string{}
+		errs  = fault.New(false)
+		pager = &mockPager{
+			results: []mockPageResult{
+				{page: mockPage{values: []string{"a", "fail"}, next: &next}},
+				{page: mockPage{values: []string{"b"}}},
+			},
+		}
+	)
+
+	err := EnumeratePages[string](
+		ctx,
+		pager,
+		noDelayOpts(0),
+		func(s string) error {
+			if s == "fail" {
+				return errors.New(s)
+			}
+
+			seen = append(seen, s)
+
+			return nil
+		},
+		errs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, seen)
+	assert.Len(t, errs.Errs(), 1)
+	assert.Equal(t, []string{next}, pager.nexts)
+}
+
+func (suite *PagerUnitSuite) TestEnumeratePages_onThrottle() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		throttled int
+		opts      = noDelayOpts(2)
+		pager     = &mockPager{
+			results: []mockPageResult{
+				{err: Err429TooManyRequests},
+				{page: mockPage{values: []string{"a"}}},
+			},
+		}
+	)
+
+	opts.OnThrottle = func(context.Context, int, time.Duration) {
+		throttled++
+	}
+
+	result, err := GetAllValues[string](ctx, pager, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, result)
+	assert.Equal(t, 1, throttled)
+}
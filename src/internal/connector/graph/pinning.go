@@ -0,0 +1,251 @@
+package graph
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/alcionai/clues"
+	khttp "github.com/microsoft/kiota-http-go"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/pkg/account"
+)
+
+const (
+	// pinnedKeysEnvKey holds a comma separated list of the base64 encoded
+	// sha256 hashes of trusted certificate public keys (ex: "sha256/AbC...=").
+	pinnedKeysEnvKey = "CORSO_PINNED_PUBLIC_KEYS"
+	// expectedIssuersEnvKey holds a comma separated list of the issuers
+	// trusted to sign the certificates of m365 endpoints.
+	expectedIssuersEnvKey = "CORSO_EXPECTED_CERT_ISSUERS"
+
+	pinPrefix = "sha256/"
+)
+
+var errCertNotPinned = errors.New("certificate does not match the pinned configuration")
+
+// CertPins restricts the certificates accepted from the Graph, login, and
+// SharePoint endpoints beyond the standard chain verification, for
+// deployments where TLS interception by a trusted, but unwanted, root
+// certificate is a concern.  Pins apply to every host the clients connect
+// to, including the hosts serving file downloads, so they should cover
+// the whole certificate hierarchy used by the environment's endpoints.
+type CertPins struct {
+	// PublicKeys are the base64 encoded sha256 hashes of trusted subject
+	// public keys.  A connection is accepted if any certificate in its
+	// verified chain holds a pinned key.  Pinning an intermediate or root
+	// key survives the routine rotation of the endpoints' leaf certificates.
+	PublicKeys []string
+	// Issuers are the common names or organizations of the issuers trusted
+	// to sign the endpoints' certificates (ex: "Microsoft Azure TLS Issuing CA 01").
+	Issuers []string
+}
+
+// CertPinsFromEnv produces the pins configured in the environment.
+func CertPinsFromEnv() CertPins {
+	return CertPins{
+		PublicKeys: splitEnv(pinnedKeysEnvKey),
+		Issuers:    splitEnv(expectedIssuersEnvKey),
+	}
+}
+
+func splitEnv(key string) []string {
+	vs := []string{}
+
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			vs = append(vs, v)
+		}
+	}
+
+	return vs
+}
+
+// Enabled is true if the pins restrict any certificates.
+func (cp CertPins) Enabled() bool {
+	return len(cp.PublicKeys) > 0 || len(cp.Issuers) > 0
+}
+
+// PublicKeyPin produces the pin of the certificate's subject public key.
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verify checks the certificate chains of an established connection
+// against the pins.  Only runs after the standard verification succeeds.
+func (cp CertPins) verify(cs tls.ConnectionState) error {
+	if len(cs.VerifiedChains) == 0 {
+		return clues.Stack(errCertNotPinned, errors.New("no verified certificate chain"))
+	}
+
+	leaf := cs.VerifiedChains[0][0]
+
+	if len(cp.Issuers) > 0 && !cp.trustsIssuer(leaf) {
+		return clues.Stack(errCertNotPinned, errors.New("unexpected issuer")).
+			With("server_name", cs.ServerName, "issuer", leaf.Issuer.String())
+	}
+
+	if len(cp.PublicKeys) > 0 && !cp.holdsPinnedKey(cs.VerifiedChains) {
+		return clues.Stack(errCertNotPinned, errors.New("no pinned public key")).
+			With("server_name", cs.ServerName)
+	}
+
+	return nil
+}
+
+func (cp CertPins) trustsIssuer(leaf *x509.Certificate) bool {
+	names := append([]string{leaf.Issuer.CommonName}, leaf.Issuer.Organization...)
+
+	for _, want := range cp.Issuers {
+		for _, n := range names {
+			if strings.EqualFold(want, n) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (cp CertPins) holdsPinnedKey(chains [][]*x509.Certificate) bool {
+	pins := map[string]struct{}{}
+
+	for _, k := range cp.PublicKeys {
+		if !strings.HasPrefix(k, pinPrefix) {
+			k = pinPrefix + k
+		}
+
+		pins[k] = struct{}{}
+	}
+
+	for _, chain := range chains {
+		for _, cert := range chain {
+			if _, ok := pins[PublicKeyPin(cert)]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// transport produces an http transport which rejects connections whose
+// certificates don't satisfy the pins.
+func (cp CertPins) transport() *http.Transport {
+	t := khttp.GetDefaultTransport().(*http.Transport)
+	t.TLSClientConfig = &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		VerifyConnection: cp.verify,
+	}
+
+	return t
+}
+
+// Transport produces the http transport used for m365 requests made outside
+// of the graph clients, with the certificate pins of the environment applied.
+func Transport() http.RoundTripper {
+	cp := CertPinsFromEnv()
+	if !cp.Enabled() {
+		return khttp.GetDefaultTransport()
+	}
+
+	return cp.transport()
+}
+
+// PinCertificates overrides the certificate pins of the environment.
+func PinCertificates(cp CertPins) option {
+	return func(c *clientConfig) {
+		c.pins = cp
+	}
+}
+
+// NewCredential produces the credential used to log into M365: the client
+// secret credential of the application, or, if the config uses delegated
+// auth, the credential of the signed-in user.  Token requests to the login
+// endpoint are subject to the certificate pins of the environment.
+func NewCredential(creds account.M365Config) (azcore.TokenCredential, error) {
+	if creds.Delegated() {
+		dc, err := newDelegatedCredential(creds)
+		if err != nil {
+			return nil, err
+		}
+
+		return dc, nil
+	}
+
+	opts := &azidentity.ClientSecretCredentialOptions{}
+
+	if cp := CertPinsFromEnv(); cp.Enabled() {
+		opts.ClientOptions = azcore.ClientOptions{
+			Transport: &http.Client{Transport: cp.transport()},
+		}
+	}
+
+	cred, err := azidentity.NewClientSecretCredential(
+		creds.AzureTenantID,
+		creds.AzureClientID,
+		creds.AzureClientSecret,
+		opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating m365 client identity")
+	}
+
+	return cred, nil
+}
+
+// ErrSharePointRESTAuth is returned when the credentials can't call
+// SharePoint's REST API, which rejects app-only tokens issued for a client
+// secret.
+var ErrSharePointRESTAuth = errors.New("the sharepoint rest api requires delegated auth or a client certificate")
+
+// NewSharePointCredential produces the credential used with SharePoint's
+// REST API: the credential of the signed-in user, or the certificate
+// credential of the application.  App-only auth without a certificate
+// produces ErrSharePointRESTAuth.
+func NewSharePointCredential(creds account.M365Config) (azcore.TokenCredential, error) {
+	if creds.Delegated() {
+		return NewCredential(creds)
+	}
+
+	if len(creds.AzureClientCertificatePath) == 0 {
+		return nil, clues.Stack(ErrSharePointRESTAuth)
+	}
+
+	bs, err := os.ReadFile(creds.AzureClientCertificatePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading m365 client certificate")
+	}
+
+	certs, key, err := azidentity.ParseCertificates(bs, []byte(creds.AzureClientCertificatePassword))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing m365 client certificate")
+	}
+
+	opts := &azidentity.ClientCertificateCredentialOptions{}
+
+	if cp := CertPinsFromEnv(); cp.Enabled() {
+		opts.ClientOptions = azcore.ClientOptions{
+			Transport: &http.Client{Transport: cp.transport()},
+		}
+	}
+
+	cred, err := azidentity.NewClientCertificateCredential(
+		creds.AzureTenantID,
+		creds.AzureClientID,
+		certs,
+		key,
+		opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating m365 client certificate identity")
+	}
+
+	return cred, nil
+}
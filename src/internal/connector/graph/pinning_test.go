@@ -0,0 +1,244 @@
+package graph
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/credentials"
+)
+
+type PinningUnitSuite struct {
+	tester.Suite
+}
+
+func TestPinningUnitSuite(t *testing.T) {
+	suite.Run(t, &PinningUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// newTestCert produces a certificate for the subject, signed by the parent,
+// or self-signed if parent is nil.
+func newTestCert(
+	t *testing.T,
+	subject pkix.Name,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func (suite *PinningUnitSuite) TestCertPinsFromEnv() {
+	t := suite.T()
+
+	t.Setenv(pinnedKeysEnvKey, "sha256/abc=, def= ,")
+	t.Setenv(expectedIssuersEnvKey, "Issuing CA")
+
+	cp := CertPinsFromEnv()
+	assert.Equal(t, []string{"sha256/abc=", "def="}, cp.PublicKeys)
+	assert.Equal(t, []string{"Issuing CA"}, cp.Issuers)
+	assert.True(t, cp.Enabled())
+
+	t.Setenv(pinnedKeysEnvKey, "")
+	t.Setenv(expectedIssuersEnvKey, "")
+
+	assert.False(t, CertPinsFromEnv().Enabled())
+}
+
+func (suite *PinningUnitSuite) TestCertPins_verify() {
+	t := suite.T()
+
+	ca, caKey := newTestCert(t, pkix.Name{CommonName: "Issuing CA", Organization: []string{"Contoso"}}, nil, nil)
+	leaf, _ := newTestCert(t, pkix.Name{CommonName: "graph.example.com"}, ca, caKey)
+	other, _ := newTestCert(t, pkix.Name{CommonName: "Other CA"}, nil, nil)
+
+	cs := tls.ConnectionState{
+		ServerName:     "graph.example.com",
+		VerifiedChains: [][]*x509.Certificate{{leaf, ca}},
+	}
+
+	table := []struct {
+		name   string
+		pins   CertPins
+		cs     tls.ConnectionState
+		expect assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "pinned intermediate key",
+			pins:   CertPins{PublicKeys: []string{PublicKeyPin(ca)}},
+			cs:     cs,
+			expect: assert.NoError,
+		},
+		{
+			name:   "pinned key without prefix",
+			pins:   CertPins{PublicKeys: []string{strings.TrimPrefix(PublicKeyPin(leaf), pinPrefix)}},
+			cs:     cs,
+			expect: assert.NoError,
+		},
+		{
+			name:   "unpinned key",
+			pins:   CertPins{PublicKeys: []string{PublicKeyPin(other)}},
+			cs:     cs,
+			expect: assert.Error,
+		},
+		{
+			name:   "expected issuer name",
+			pins:   CertPins{Issuers: []string{"issuing ca"}},
+			cs:     cs,
+			expect: assert.NoError,
+		},
+		{
+			name:   "expected issuer organization",
+			pins:   CertPins{Issuers: []string{"Contoso"}},
+			cs:     cs,
+			expect: assert.NoError,
+		},
+		{
+			name:   "unexpected issuer",
+			pins:   CertPins{Issuers: []string{"Other CA"}},
+			cs:     cs,
+			expect: assert.Error,
+		},
+		{
+			name: "expected issuer, unpinned key",
+			pins: CertPins{
+				PublicKeys: []string{PublicKeyPin(other)},
+				Issuers:    []string{"Issuing CA"},
+			},
+			cs:     cs,
+			expect: assert.Error,
+		},
+		{
+			name:   "unverified connection",
+			pins:   CertPins{Issuers: []string{"Issuing CA"}},
+			cs:     tls.ConnectionState{ServerName: "graph.example.com"},
+			expect: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			err := test.pins.verify(test.cs)
+			test.expect(suite.T(), err)
+
+			if err != nil {
+				assert.ErrorIs(suite.T(), err, errCertNotPinned)
+			}
+		})
+	}
+}
+
+func (suite *PinningUnitSuite) TestCertPins_transport() {
+	t := suite.T()
+	tr := CertPins{Issuers: []string{"Issuing CA"}}.transport()
+
+	require.NotNil(t, tr.TLSClientConfig)
+	assert.NotNil(t, tr.TLSClientConfig.VerifyConnection)
+	assert.False(t, tr.TLSClientConfig.InsecureSkipVerify)
+}
+
+func (suite *PinningUnitSuite) TestNewSharePointCredential() {
+	t := suite.T()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "corso"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	pk, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pk})...)
+	require.NoError(t, os.WriteFile(certPath, pemBytes, 0o600))
+
+	app := account.M365Config{
+		M365:          credentials.M365{AzureClientID: "cid", AzureClientSecret: "secret"},
+		AzureTenantID: "tid",
+	}
+
+	table := []struct {
+		name      string
+		certPath  string
+		expectErr assert.ErrorAssertionFunc
+		expectIs  error
+	}{
+		{
+			name:      "client secret only",
+			expectErr: assert.Error,
+			expectIs:  ErrSharePointRESTAuth,
+		},
+		{
+			name:      "missing certificate",
+			certPath:  filepath.Join(t.TempDir(), "missing.pem"),
+			expectErr: assert.Error,
+		},
+		{
+			name:      "certificate",
+			certPath:  certPath,
+			expectErr: assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			creds := app
+			creds.AzureClientCertificatePath = test.certPath
+
+			_, err := NewSharePointCredential(creds)
+			test.expectErr(t, err)
+
+			if test.expectIs != nil {
+				assert.ErrorIs(t, err, test.expectIs)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/alcionai/clues"
 	backoff "github.com/cenkalti/backoff/v4"
 	"github.com/microsoft/kiota-abstractions-go/serialization"
@@ -17,6 +16,7 @@ import (
 	msgraphgocore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/pkg/errors"
 
+	"github.com/alcionai/corso/src/internal/stall"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
@@ -38,6 +38,20 @@ func AllMetadataFileNames() []string {
 	return []string{DeltaURLsFileName, PreviousPathFileName}
 }
 
+// OptionalMetadataFileNames produces the filenames of category-specific
+// metadata that prior backups aren't guaranteed to contain, since they were
+// added in later versions.
+func OptionalMetadataFileNames(service path.ServiceType, cat path.CategoryType) []string {
+	switch {
+	case service == path.ExchangeService && cat == path.EventsCategory:
+		return []string{SeriesFingerprintsFileName}
+	case service == path.OneDriveService, service == path.SharePointService && cat == path.LibrariesCategory:
+		return []string{ItemContentFileName}
+	}
+
+	return nil
+}
+
 type QueryParams struct {
 	Category      path.CategoryType
 	ResourceOwner string
@@ -97,6 +111,8 @@ type clientConfig struct {
 	// The minimum delay in seconds between retries
 	minDelay           time.Duration
 	overrideRetryCount bool
+	// pins restrict the certificates accepted from the endpoints
+	pins CertPins
 }
 
 type option func(*clientConfig)
@@ -158,11 +174,12 @@ func MinimumBackoff(dur time.Duration) option {
 // CreateAdapter uses provided credentials to log into M365 using Kiota Azure Library
 // with Azure identity package. An adapter object is a necessary to component
 // to create  *msgraphsdk.GraphServiceClient
-func CreateAdapter(tenant, client, secret string, opts ...option) (*msgraphsdk.GraphRequestAdapter, error) {
-	// Client Provider: Uses Secret for access to tenant-level data
-	cred, err := azidentity.NewClientSecretCredential(tenant, client, secret, nil)
+func CreateAdapter(creds account.M365Config, opts ...option) (*msgraphsdk.GraphRequestAdapter, error) {
+	// Client Provider: Uses Secret for access to tenant-level data, or the
+	// permissions delegated by the signed-in user.
+	cred, err := NewCredential(creds)
 	if err != nil {
-		return nil, errors.Wrap(err, "creating m365 client identity")
+		return nil, err
 	}
 
 	auth, err := ka.NewAzureIdentityAuthenticationProviderWithScopes(
@@ -189,12 +206,16 @@ func CreateAdapter(tenant, client, secret string, opts ...option) (*msgraphsdk.G
 // can utilize it on a per-download basis.
 func HTTPClient(opts ...option) *http.Client {
 	clientOptions := msgraphsdk.GetDefaultClientOptions()
-	clientconfig := (&clientConfig{}).populate(opts...)
+	clientconfig := (&clientConfig{pins: CertPinsFromEnv()}).populate(opts...)
 	noOfRetries, minRetryDelay := clientconfig.applyMiddlewareConfig()
 	middlewares := GetKiotaMiddlewares(&clientOptions, noOfRetries, minRetryDelay)
 	httpClient := msgraphgocore.GetDefaultClient(&clientOptions, middlewares...)
 	httpClient.Timeout = time.Minute * 3
 
+	if clientconfig.pins.Enabled() {
+		httpClient.Transport = khttp.NewCustomTransportWithParentTransport(clientconfig.pins.transport(), middlewares...)
+	}
+
 	clientconfig.apply(httpClient)
 
 	return httpClient
@@ -214,6 +235,8 @@ func GetMiddlewares(maxRetry int, delay time.Duration) []khttp.Middleware {
 		khttp.NewCompressionHandler(),
 		khttp.NewParametersNameDecodingHandler(),
 		khttp.NewUserAgentHandler(),
+		&ThrottleHandler{},
+		&BandwidthHandler{},
 		&LoggingMiddleware{},
 	}
 }
@@ -259,13 +282,15 @@ func (handler *LoggingMiddleware) Intercept(
 	middlewareIndex int,
 	req *http.Request,
 ) (*http.Response, error) {
-	var (
-		ctx       = req.Context()
-		resp, err = pipeline.Next(req, middlewareIndex)
-	)
+	ctx := req.Context()
+
+	// in-flight calls get reported if their collection stalls.
+	callDone := stall.TrackCall(ctx, req.Method, req.URL.String())
+	resp, err := pipeline.Next(req, middlewareIndex)
+	callDone()
 
 	if strings.Contains(req.URL.String(), "users//") {
-		logger.Ctx(ctx).Errorw("malformed request url: missing resource", "url", req.URL)
+		logger.Ctx(ctx).Errorw("malformed request url: missing resource", "url", logger.PII(req.URL.String()))
 	}
 
 	if resp == nil {
@@ -313,7 +338,7 @@ func (handler *LoggingMiddleware) Intercept(
 	} else {
 		// special case for supportability: log all throttling cases.
 		if resp.StatusCode == http.StatusTooManyRequests {
-			logger.Ctx(ctx).Infow("graph api throttling", "method", req.Method, "url", req.URL)
+			logger.Ctx(ctx).Infow("graph api throttling", "method", req.Method, "url", logger.PII(req.URL.String()))
 		} else if resp.StatusCode == http.StatusBadRequest {
 			respDump, _ := httputil.DumpResponse(resp, true)
 			logger.Ctx(ctx).Infow(
@@ -324,7 +349,11 @@ func (handler *LoggingMiddleware) Intercept(
 				"response", string(respDump),
 			)
 		} else if resp.StatusCode/100 != 2 {
-			logger.Ctx(ctx).Infow("graph api error", "status", resp.Status, "method", req.Method, "url", req.URL)
+			logger.Ctx(ctx).Infow(
+				"graph api error",
+				"status", resp.Status,
+				"method", req.Method,
+				"url", logger.PII(req.URL.String()))
 		}
 	}
 
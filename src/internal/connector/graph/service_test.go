@@ -34,10 +34,7 @@ func (suite *GraphUnitSuite) SetupSuite() {
 
 func (suite *GraphUnitSuite) TestCreateAdapter() {
 	t := suite.T()
-	adpt, err := CreateAdapter(
-		suite.credentials.AzureTenantID,
-		suite.credentials.AzureClientID,
-		suite.credentials.AzureClientSecret)
+	adpt, err := CreateAdapter(suite.credentials)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, adpt)
@@ -77,10 +74,7 @@ func (suite *GraphUnitSuite) TestHTTPClient() {
 
 func (suite *GraphUnitSuite) TestSerializationEndPoint() {
 	t := suite.T()
-	adpt, err := CreateAdapter(
-		suite.credentials.AzureTenantID,
-		suite.credentials.AzureClientID,
-		suite.credentials.AzureClientSecret)
+	adpt, err := CreateAdapter(suite.credentials)
 	require.NoError(t, err)
 
 	serv := NewService(adpt)
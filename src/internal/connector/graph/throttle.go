@@ -0,0 +1,271 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+	khttp "github.com/microsoft/kiota-http-go"
+
+	"github.com/alcionai/corso/src/internal/bandwidth"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// Workloads which graph throttles independently of each other.
+const (
+	workloadExchange   = "exchange"
+	workloadOneDrive   = "onedrive"
+	workloadSharePoint = "sharepoint"
+	workloadDrive      = "drive"
+	workloadGraph      = "graph"
+)
+
+// ---------------------------------------------------------------------------
+// Throttle Domains
+// ---------------------------------------------------------------------------
+
+// throttleDomains tracks the requests that graph throttled, grouped into
+// domains by workload and resource (ex: a SharePoint site, or a user's
+// OneDrive).  Graph throttles each workload and resource separately, so a
+// throttled domain only pauses the requests within that domain.
+type throttleDomains struct {
+	mu sync.Mutex
+	// the time at which each throttled domain can resume.
+	until map[string]time.Time
+}
+
+func newThrottleDomains() *throttleDomains {
+	return &throttleDomains{until: map[string]time.Time{}}
+}
+
+// throttles is shared by every client, since graph's budgets are shared
+// by every request made to the same workload and resource.
+var throttles = newThrottleDomains()
+
+// pause holds the domain's requests for the duration, unless the domain
+// is already paused for longer.
+func (td *throttleDomains) pause(domain string, wait time.Duration) {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	until := time.Now().Add(wait)
+	if until.After(td.until[domain]) {
+		td.until[domain] = until
+	}
+}
+
+// resumeAt produces the time at which the domain can resume.  Returns the
+// zero time if the domain isn't paused.
+func (td *throttleDomains) resumeAt(domain string) time.Time {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+
+	until, ok := td.until[domain]
+	if ok && !time.Now().Before(until) {
+		delete(td.until, domain)
+		return time.Time{}
+	}
+
+	return until
+}
+
+// wait blocks until the domain is no longer paused, or the context is
+// cancelled.
+func (td *throttleDomains) wait(ctx context.Context, domain string) error {
+	for {
+		until := td.resumeAt(domain)
+		if until.IsZero() {
+			return nil
+		}
+
+		t := time.NewTimer(time.Until(until))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// throttleDomain groups the request url by the workload and resource that
+// graph throttles it under.  Drive items are downloaded from SharePoint
+// hosts, which are grouped by their site or personal (OneDrive) site.
+func throttleDomain(u *url.URL) string {
+	if !strings.EqualFold(u.Hostname(), "graph.microsoft.com") {
+		return hostThrottleDomain(u)
+	}
+
+	elems := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// drop the api version
+	if len(elems) > 0 && (elems[0] == "v1.0" || elems[0] == "beta") {
+		elems = elems[1:]
+	}
+
+	if len(elems) < 2 {
+		return workloadGraph
+	}
+
+	resource := strings.ToLower(elems[1])
+
+	switch strings.ToLower(elems[0]) {
+	case "sites":
+		return workloadSharePoint + ":" + resource
+	case "drives":
+		return workloadDrive + ":" + resource
+	case "users":
+		if len(elems) > 2 && strings.HasPrefix(strings.ToLower(elems[2]), "drive") {
+			return workloadOneDrive + ":" + resource
+		}
+
+		return workloadExchange + ":" + resource
+	}
+
+	return workloadGraph + ":" + strings.ToLower(elems[0])
+}
+
+// hostThrottleDomain groups requests made directly to SharePoint hosts by
+// their site.  ex: contoso-my.sharepoint.com/personal/user_contoso_com
+func hostThrottleDomain(u *url.URL) string {
+	var (
+		host     = strings.ToLower(u.Hostname())
+		workload = workloadSharePoint
+		elems    = strings.Split(strings.Trim(u.Path, "/"), "/")
+		site     = host
+	)
+
+	if strings.HasSuffix(host, "-my.sharepoint.com") {
+		workload = workloadOneDrive
+	}
+
+	if len(elems) >= 2 && (elems[0] == "sites" || elems[0] == "personal") {
+		site += "/" + strings.ToLower(elems[1])
+	}
+
+	return workload + ":" + site
+}
+
+// throttleWait produces how long to pause the domain of a throttled
+// response.  Returns false if the response wasn't throttled.
+func throttleWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests &&
+		resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(resp.Header.Get(retryAfterHeader), time.Now())
+	if !ok {
+		// unavailable services without a retry-after aren't throttling.
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return 0, false
+		}
+
+		wait = defaultDelay
+	}
+
+	if maxWait := absoluteMaxDelaySeconds * time.Second; wait > maxWait {
+		wait = maxWait
+	}
+
+	return wait, wait > 0
+}
+
+// parseRetryAfter parses the retry-after header, which holds either a
+// count of seconds, or an http date.
+func parseRetryAfter(ra string, now time.Time) (time.Duration, bool) {
+	if len(ra) == 0 {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseFloat(ra, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+
+	if t, err := http.ParseTime(ra); err == nil {
+		return t.Sub(now), true
+	}
+
+	return 0, false
+}
+
+// ---------------------------------------------------------------------------
+// Client Middleware
+// ---------------------------------------------------------------------------
+
+// ThrottleHandler holds requests to the workloads and resources that graph
+// is throttling, until graph's retry-after passes.  Requests to other
+// workloads and resources proceed, so that a throttled SharePoint site
+// doesn't slow the backup of a OneDrive in the same operation.
+type ThrottleHandler struct {
+	domains *throttleDomains
+}
+
+func (handler *ThrottleHandler) Intercept(
+	pipeline khttp.Pipeline,
+	middlewareIndex int,
+	req *http.Request,
+) (*http.Response, error) {
+	var (
+		ctx     = req.Context()
+		domain  = throttleDomain(req.URL)
+		domains = handler.domains
+	)
+
+	if domains == nil {
+		domains = throttles
+	}
+
+	if err := domains.wait(ctx, domain); err != nil {
+		return nil, clues.Wrap(err, "waiting for throttled requests").WithClues(ctx)
+	}
+
+	resp, err := pipeline.Next(req, middlewareIndex)
+	if resp == nil {
+		return resp, err
+	}
+
+	if wait, ok := throttleWait(resp); ok {
+		domains.pause(domain, wait)
+		logger.Ctx(ctx).Infow("pausing throttled requests", "throttle_domain", logger.PII(domain), "wait", wait)
+	}
+
+	return resp, err
+}
+
+// BandwidthHandler draws the bodies of requests and responses from the
+// bandwidth budget of the operation that made the request.  Requests made
+// outside of a budgeted operation are unbounded.
+type BandwidthHandler struct{}
+
+func (handler *BandwidthHandler) Intercept(
+	pipeline khttp.Pipeline,
+	middlewareIndex int,
+	req *http.Request,
+) (*http.Response, error) {
+	var (
+		ctx = req.Context()
+		l   = bandwidth.Ctx(ctx)
+	)
+
+	if l == nil {
+		return pipeline.Next(req, middlewareIndex)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = l.ReadCloser(ctx, req.Body)
+	}
+
+	resp, err := pipeline.Next(req, middlewareIndex)
+	if resp != nil && resp.Body != nil {
+		resp.Body = l.ReadCloser(ctx, resp.Body)
+	}
+
+	return resp, err
+}
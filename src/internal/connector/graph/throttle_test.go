@@ -0,0 +1,242 @@
+package graph
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/bandwidth"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type ThrottleUnitSuite struct {
+	tester.Suite
+}
+
+func TestThrottleUnitSuite(t *testing.T) {
+	suite.Run(t, &ThrottleUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ThrottleUnitSuite) TestThrottleDomain() {
+	table := []struct {
+		name   string
+		url    string
+		expect string
+	}{
+		{
+			name:   "sharepoint site",
+			url:    "https://graph.microsoft.com/v1.0/sites/contoso.sharepoint.com,sid,wid/lists",
+			expect: "sharepoint:contoso.sharepoint.com,sid,wid",
+		},
+		{
+			name:   "onedrive",
+			url:    "https://graph.microsoft.com/v1.0/users/UID/drive/root/children",
+			expect: "onedrive:uid",
+		},
+		{
+			name:   "onedrive drives",
+			url:    "https://graph.microsoft.com/beta/users/uid/drives",
+			expect: "onedrive:uid",
+		},
+		{
+			name:   "exchange",
+			url:    "https://graph.microsoft.com/v1.0/users/uid/mailFolders/inbox/messages",
+			expect: "exchange:uid",
+		},
+		{
+			name:   "drive",
+			url:    "https://graph.microsoft.com/v1.0/drives/did/items/iid",
+			expect: "drive:did",
+		},
+		{
+			name:   "other resource",
+			url:    "https://graph.microsoft.com/v1.0/groups/gid",
+			expect: "graph:groups",
+		},
+		{
+			name:   "no resource",
+			url:    "https://graph.microsoft.com/v1.0/users",
+			expect: "graph",
+		},
+		{
+			name:   "onedrive download",
+			url:    "https://contoso-my.sharepoint.com/personal/user_contoso_com/_layouts/15/download.aspx",
+			expect: "onedrive:contoso-my.sharepoint.com/user_contoso_com",
+		},
+		{
+			name:   "sharepoint download",
+			url:    "https://contoso.sharepoint.com/sites/Marketing/_layouts/15/download.aspx",
+			expect: "sharepoint:contoso.sharepoint.com/marketing",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			u, err := url.Parse(test.url)
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), test.expect, throttleDomain(u))
+		})
+	}
+}
+
+func (suite *ThrottleUnitSuite) TestThrottleWait() {
+	now := time.Now()
+
+	table := []struct {
+		name       string
+		status     int
+		retryAfter string
+		expect     time.Duration
+		expectOK   assert.BoolAssertionFunc
+	}{
+		{"ok", http.StatusOK, "10", 0, assert.False},
+		{"too many requests", http.StatusTooManyRequests, "10", 10 * time.Second, assert.True},
+		{"too many requests, no header", http.StatusTooManyRequests, "", defaultDelay, assert.True},
+		{"unavailable", http.StatusServiceUnavailable, "2", 2 * time.Second, assert.True},
+		{"unavailable, no header", http.StatusServiceUnavailable, "", 0, assert.False},
+		{"capped", http.StatusTooManyRequests, "3600", absoluteMaxDelaySeconds * time.Second, assert.True},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			resp := &http.Response{StatusCode: test.status, Header: http.Header{}}
+
+			if len(test.retryAfter) > 0 {
+				resp.Header.Set(retryAfterHeader, test.retryAfter)
+			}
+
+			wait, ok := throttleWait(resp)
+			test.expectOK(t, ok)
+			assert.Equal(t, test.expect, wait)
+		})
+	}
+
+	wait, ok := parseRetryAfter(now.Add(time.Minute).UTC().Format(http.TimeFormat), now)
+	assert.True(suite.T(), ok, "http date")
+	assert.InDelta(suite.T(), time.Minute, wait, float64(time.Second))
+}
+
+type mockPipeline struct {
+	resps map[string]*http.Response
+	sent  map[string]time.Time
+}
+
+func (mp *mockPipeline) Next(req *http.Request, _ int) (*http.Response, error) {
+	mp.sent[req.URL.Path] = time.Now()
+
+	if resp, ok := mp.resps[req.URL.Path]; ok {
+		return resp, nil
+	}
+
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func (suite *ThrottleUnitSuite) TestThrottleHandler() {
+	var (
+		t   = suite.T()
+		ctx = context.Background()
+		th  = &ThrottleHandler{domains: newThrottleDomains()}
+		mp  = &mockPipeline{
+			resps: map[string]*http.Response{
+				"/v1.0/sites/sid/drives": {
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{retryAfterHeader: []string{"0.5"}},
+				},
+			},
+			sent: map[string]time.Time{},
+		}
+		throttled = "https://graph.microsoft.com/v1.0/sites/sid/drives"
+		sameSite  = "https://graph.microsoft.com/v1.0/sites/sid/lists"
+		oneDrive  = "https://graph.microsoft.com/v1.0/users/uid/drive"
+	)
+
+	send := func(u string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		_, err = th.Intercept(mp, 0, req)
+		require.NoError(t, err)
+	}
+
+	send(throttled)
+
+	start := time.Now()
+
+	send(oneDrive)
+	assert.Less(t, time.Since(start), 250*time.Millisecond, "other workloads aren't paused")
+
+	send(sameSite)
+	assert.GreaterOrEqual(t, mp.sent["/v1.0/sites/sid/lists"].Sub(start), 400*time.Millisecond, "site is paused")
+
+	// cancelled requests stop waiting on the paused domain.
+	send(throttled)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	req, err := http.NewRequestWithContext(cctx, http.MethodGet, sameSite, nil)
+	require.NoError(t, err)
+
+	_, err = th.Intercept(mp, 0, req)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func (suite *ThrottleUnitSuite) TestBandwidthHandler() {
+	var (
+		bh = &BandwidthHandler{}
+		u  = "https://graph.microsoft.com/v1.0/users/uid/drive/items/iid/content"
+		l  = bandwidth.New(control.BandwidthOptions{RestoreBytesPerSecond: 1 << 20}, bandwidth.Restore)
+	)
+
+	table := []struct {
+		name        string
+		ctx         context.Context
+		expectBound bool
+	}{
+		{"no budget", context.Background(), false},
+		{"budget", bandwidth.Set(context.Background(), l), true},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			var (
+				t        = suite.T()
+				body     = io.NopCloser(strings.NewReader("request"))
+				respBody = io.NopCloser(strings.NewReader("response"))
+				mp       = &mockPipeline{
+					resps: map[string]*http.Response{
+						"/v1.0/users/uid/drive/items/iid/content": {StatusCode: http.StatusOK, Body: respBody},
+					},
+					sent: map[string]time.Time{},
+				}
+			)
+
+			req, err := http.NewRequestWithContext(test.ctx, http.MethodPut, u, body)
+			require.NoError(t, err)
+
+			resp, err := bh.Intercept(mp, 0, req)
+			require.NoError(t, err)
+
+			if !test.expectBound {
+				assert.Equal(t, body, req.Body)
+				assert.Equal(t, respBody, resp.Body)
+
+				return
+			}
+
+			assert.NotEqual(t, body, req.Body)
+			assert.NotEqual(t, respBody, resp.Body)
+
+			bs, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "response", string(bs))
+		})
+	}
+}
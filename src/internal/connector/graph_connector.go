@@ -17,6 +17,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
 
+	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/discovery"
 	"github.com/alcionai/corso/src/internal/connector/discovery/api"
 	"github.com/alcionai/corso/src/internal/connector/graph"
@@ -42,7 +43,8 @@ type GraphConnector struct {
 
 	tenant      string
 	Users       map[string]string // key<email> value<id>
-	Sites       map[string]string // key<???> value<???>
+	Sites       map[string]string // key<webURL> value<id>
+	SiteNames   map[string]string // key<id> value<displayName>
 	credentials account.M365Config
 
 	// wg is used to track completion of GC tasks
@@ -69,6 +71,37 @@ func NewGraphConnector(
 	acct account.Account,
 	r resource,
 	errs *fault.Errors,
+) (*GraphConnector, error) {
+	gc, err := newGraphConnector(ctx, itemClient, acct)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO(ashmrtn): When selectors only encapsulate a single resource owner that
+	// is not a wildcard don't populate users or sites when making the connector.
+	// For now this keeps things functioning if callers do pass in a selector like
+	// "*" instead of.
+	if r == AllResources || r == Users {
+		if err = gc.setTenantUsers(ctx, errs); err != nil {
+			return nil, errors.Wrap(err, "retrieving tenant user list")
+		}
+	}
+
+	if r == AllResources || r == Sites {
+		if err = gc.setTenantSites(ctx, errs); err != nil {
+			return nil, errors.Wrap(err, "retrieveing tenant site list")
+		}
+	}
+
+	return gc, nil
+}
+
+// newGraphConnector constructs a connector without discovering any of
+// the tenant's resource owners.
+func newGraphConnector(
+	ctx context.Context,
+	itemClient *http.Client,
+	acct account.Account,
 ) (*GraphConnector, error) {
 	m365, err := acct.M365Config()
 	if err != nil {
@@ -93,32 +126,12 @@ func NewGraphConnector(
 		return nil, clues.Wrap(err, "creating api client").WithClues(ctx)
 	}
 
-	// TODO(ashmrtn): When selectors only encapsulate a single resource owner that
-	// is not a wildcard don't populate users or sites when making the connector.
-	// For now this keeps things functioning if callers do pass in a selector like
-	// "*" instead of.
-	if r == AllResources || r == Users {
-		if err = gc.setTenantUsers(ctx, errs); err != nil {
-			return nil, errors.Wrap(err, "retrieving tenant user list")
-		}
-	}
-
-	if r == AllResources || r == Sites {
-		if err = gc.setTenantSites(ctx, errs); err != nil {
-			return nil, errors.Wrap(err, "retrieveing tenant site list")
-		}
-	}
-
 	return &gc, nil
 }
 
 // createService constructor for graphService component
 func (gc *GraphConnector) createService() (*graph.Service, error) {
-	adapter, err := graph.CreateAdapter(
-		gc.credentials.AzureTenantID,
-		gc.credentials.AzureClientID,
-		gc.credentials.AzureClientSecret,
-	)
+	adapter, err := graph.CreateAdapter(gc.credentials)
 	if err != nil {
 		return &graph.Service{}, err
 	}
@@ -156,19 +169,33 @@ func (gc *GraphConnector) setTenantSites(ctx context.Context, errs *fault.Errors
 	ctx, end := D.Span(ctx, "gc:setTenantSites")
 	defer end()
 
+	names := map[string]string{}
+
+	// display names are recorded alongside the sites, so that sites can
+	// also be selected by name.
+	identify := func(item any) (string, string, error) {
+		url, id, err := identifySite(item)
+		if err == nil {
+			names[id] = ptr.Val(item.(models.Siteable).GetDisplayName())
+		}
+
+		return url, id, err
+	}
+
 	sites, err := getResources(
 		ctx,
 		gc.Service,
 		gc.tenant,
 		sharepoint.GetAllSitesForTenant,
 		models.CreateSiteCollectionResponseFromDiscriminatorValue,
-		identifySite,
+		identify,
 		errs)
 	if err != nil {
 		return err
 	}
 
 	gc.Sites = sites
+	gc.SiteNames = names
 
 	return nil
 }
@@ -254,6 +281,50 @@ func (gc *GraphConnector) UnionSiteIDsAndWebURLs(
 	return idsl, nil
 }
 
+// ResolveSiteID produces the ID of the single site in the tenant identified
+// by the value, which may be either a site ID or a webURL.  WebURLs are
+// matched in the same manner as UnionSiteIDsAndWebURLs, and must not match
+// more than one site.
+func (gc *GraphConnector) ResolveSiteID(
+	ctx context.Context,
+	idOrURL string,
+	errs *fault.Errors,
+) (string, error) {
+	if len(gc.Sites) == 0 {
+		if err := gc.setTenantSites(ctx, errs); err != nil {
+			return "", err
+		}
+	}
+
+	ctx = clues.Add(ctx, "site", idOrURL)
+
+	for _, id := range gc.Sites {
+		if id == idOrURL {
+			return id, nil
+		}
+	}
+
+	var (
+		match = filters.PathSuffix([]string{idOrURL})
+		found []string
+	)
+
+	for url, id := range gc.Sites {
+		if match.Compare(url) {
+			found = append(found, id)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", clues.New("no site matches").WithClues(ctx)
+	case 1:
+		return found[0], nil
+	default:
+		return "", clues.New("multiple sites match").WithClues(ctx).With("match_count", len(found))
+	}
+}
+
 // AwaitStatus waits for all gc tasks to complete and then returns status
 func (gc *GraphConnector) AwaitStatus() *support.ConnectorOperationStatus {
 	defer func() {
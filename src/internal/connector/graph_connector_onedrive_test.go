@@ -487,8 +487,9 @@ func (suite *GraphConnectorOneDriveIntegrationSuite) TestRestoreAndBackup_Multip
 					suite.connector.tenant,
 					[]string{suite.user},
 					control.Options{
-						RestorePermissions: true,
-						ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+						Permissions: control.PermissionOptions{
+							OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+						},
 					},
 				)
 			})
@@ -712,8 +713,9 @@ func (suite *GraphConnectorOneDriveIntegrationSuite) TestPermissionsRestoreAndBa
 					suite.connector.tenant,
 					[]string{suite.user},
 					control.Options{
-						RestorePermissions: true,
-						ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+						Permissions: control.PermissionOptions{
+							OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+						},
 					},
 				)
 			})
@@ -786,8 +788,9 @@ func (suite *GraphConnectorOneDriveIntegrationSuite) TestPermissionsBackupAndNoR
 					suite.connector.tenant,
 					[]string{suite.user},
 					control.Options{
-						RestorePermissions: true,
-						ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+						Permissions: control.PermissionOptions{
+							OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+						},
 					},
 				)
 			})
@@ -912,8 +915,9 @@ func (suite *GraphConnectorOneDriveIntegrationSuite) TestPermissionsRestoreAndNo
 		suite.connector.tenant,
 		[]string{suite.user},
 		control.Options{
-			RestorePermissions: true,
-			ToggleFeatures:     control.Toggles{EnablePermissionsBackup: false},
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Restore: true},
+			},
 		},
 	)
 }
@@ -134,6 +134,44 @@ func (suite *GraphConnectorUnitSuite) TestUnionSiteIDsAndWebURLs() {
 	}
 }
 
+func (suite *GraphConnectorUnitSuite) TestResolveSiteID() {
+	gc := &GraphConnector{
+		// must be populated, else the func will try to make a graph call
+		// to retrieve site data.
+		Sites: map[string]string{
+			"www.foo.com/sites/bar":  "site-id-1",
+			"www.foo.com/sites/baz":  "site-id-2",
+			"www.foo.com/teams/bar":  "site-id-3",
+			"www.fnords.com/smarf/a": "site-id-4",
+		},
+	}
+
+	table := []struct {
+		name      string
+		idOrURL   string
+		expect    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"id", "site-id-2", "site-id-2", assert.NoError},
+		{"url", "www.foo.com/sites/bar", "site-id-1", assert.NoError},
+		{"url suffix", "smarf/a", "site-id-4", assert.NoError},
+		{"ambiguous url suffix", "bar", "", assert.Error},
+		{"no match", "www.foo.com/sites/qux", "", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			result, err := gc.ResolveSiteID(ctx, test.idOrURL, fault.New(true))
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Integration tests
 // ---------------------------------------------------------------------------
@@ -243,8 +281,9 @@ func (suite *GraphConnectorIntegrationSuite) TestRestoreFailsBadService() {
 		sel,
 		dest,
 		control.Options{
-			RestorePermissions: true,
-			ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+			},
 		},
 		nil,
 		fault.New(true))
@@ -322,8 +361,9 @@ func (suite *GraphConnectorIntegrationSuite) TestEmptyCollections() {
 				test.sel,
 				dest,
 				control.Options{
-					RestorePermissions: true,
-					ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+					Permissions: control.PermissionOptions{
+						OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+					},
 				},
 				test.col,
 				fault.New(true))
@@ -500,7 +540,7 @@ func runBackupAndCompare(
 		expectedData,
 		dcs,
 		config.dest,
-		config.opts.RestorePermissions)
+		config.opts.Permissions.OneDrive.Restore)
 
 	status := backupGC.AwaitStatus()
 
@@ -843,8 +883,9 @@ func (suite *GraphConnectorIntegrationSuite) TestRestoreAndBackup() {
 				suite.connector.tenant,
 				[]string{suite.user},
 				control.Options{
-					RestorePermissions: true,
-					ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+					Permissions: control.PermissionOptions{
+						OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+					},
 				},
 			)
 		})
@@ -965,8 +1006,9 @@ func (suite *GraphConnectorIntegrationSuite) TestMultiFolderBackupDifferentNames
 					restoreSel,
 					dest,
 					control.Options{
-						RestorePermissions: true,
-						ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+						Permissions: control.PermissionOptions{
+							OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+						},
 					},
 					collections,
 					fault.New(true))
@@ -995,8 +1037,9 @@ func (suite *GraphConnectorIntegrationSuite) TestMultiFolderBackupDifferentNames
 				backupSel,
 				nil,
 				control.Options{
-					RestorePermissions: true,
-					ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+					Permissions: control.PermissionOptions{
+						OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+					},
 				},
 				fault.New(true))
 			require.NoError(t, err)
@@ -1055,8 +1098,9 @@ func (suite *GraphConnectorIntegrationSuite) TestRestoreAndBackup_largeMailAttac
 		suite.connector.tenant,
 		[]string{suite.user},
 		control.Options{
-			RestorePermissions: true,
-			ToggleFeatures:     control.Toggles{EnablePermissionsBackup: true},
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Backup: true, Restore: true},
+			},
 		},
 	)
 }
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/shares"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/account"
+)
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+// Client is used to fulfill drive queries that are backed by GraphAPI.
+// Like the exchange api client, the boundary separates the granular
+// implementation of the graphAPI and kiota away from the onedrive and
+// sharepoint packages' broader intents.
+type Client struct {
+	Credentials account.M365Config
+
+	// The stable service is re-usable for any non-paged request.
+	stable graph.Servicer
+}
+
+// NewClient produces a new drive api client.
+func NewClient(creds account.M365Config) (Client, error) {
+	a, err := graph.CreateAdapter(creds)
+	if err != nil {
+		return Client{}, clues.Wrap(err, "generating graph adapter")
+	}
+
+	return Client{Credentials: creds, stable: graph.NewService(a)}, nil
+}
+
+// NewClientFromService produces a drive api client that makes its
+// requests through the service.  Used by connectors that already
+// hold a service.
+func NewClientFromService(gs graph.Servicer) Client {
+	return Client{stable: gs}
+}
+
+func (c Client) Drives() Drives {
+	return Drives{c}
+}
+
+// Drives is an interface-compliant provider of the client.
+type Drives struct {
+	Client
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// GetDrive retrieves the drive.
+func (c Drives) GetDrive(ctx context.Context, driveID string) (models.Driveable, error) {
+	// sharePointIds is only returned when selected.
+	options := &drives.DriveItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &drives.DriveItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "name", "driveType", "owner", "webUrl", "sharePointIds"},
+		},
+	}
+
+	d, err := c.stable.Client().DrivesById(driveID).Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting drive").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return d, nil
+}
+
+// GetItem retrieves the drive item.  The item's download url is only
+// valid for a short time after retrieval.
+func (c Drives) GetItem(ctx context.Context, driveID, itemID string) (models.DriveItemable, error) {
+	di, err := c.stable.Client().DrivesById(driveID).ItemsById(itemID).Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting drive item").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return di, nil
+}
+
+// GetRootFolder retrieves the root folder of the drive.
+func (c Drives) GetRootFolder(ctx context.Context, driveID string) (models.DriveItemable, error) {
+	root, err := c.stable.Client().DrivesById(driveID).Root().Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting drive root").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return root, nil
+}
+
+// DeleteItem deletes the drive item.  Deleting a folder deletes
+// everything within it.
+func (c Drives) DeleteItem(ctx context.Context, driveID, itemID string) error {
+	err := c.stable.Client().DrivesById(driveID).ItemsById(itemID).Delete(ctx, nil)
+	if err != nil {
+		return clues.Wrap(err, "deleting drive item").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return nil
+}
+
+// GetSharedItem retrieves the drive item identified by a sharing link, or
+// by the item's web url.
+// Reference: https://learn.microsoft.com/en-us/graph/api/shares-get
+func (c Drives) GetSharedItem(ctx context.Context, itemURL string) (models.DriveItemable, error) {
+	di, err := shares.NewItemDriveItemRequestBuilderInternal(
+		map[string]string{"sharedDriveItem%2Did": encodeSharingURL(itemURL)},
+		c.stable.Adapter(),
+	).Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting shared drive item").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return di, nil
+}
+
+// encodeSharingURL transforms the url into a sharing token, which graph
+// accepts in place of a share ID.
+func encodeSharingURL(u string) string {
+	return "u!" + base64.RawURLEncoding.EncodeToString([]byte(u))
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ClientUnitSuite struct {
+	tester.Suite
+}
+
+func TestClientUnitSuite(t *testing.T) {
+	suite.Run(t, &ClientUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ClientUnitSuite) TestEncodeSharingURL() {
+	assert.Equal(
+		suite.T(),
+		"u!aHR0cHM6Ly9vbmVkcml2ZS5saXZlLmNvbS9yZWRpcj9yZXNpZD0xMjMxMjQ0MTkzOTEyITEyJmF1dGhLZXk9MTIwMTkxOSExMjkyMSEx",
+		encodeSharingURL("https://onedrive.live.com/redir?resid=1231244193912!12&authKey=1201919!12921!1"))
+}
+
+func (suite *ClientUnitSuite) TestGetDrive() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/drives/drive-id", r.URL.Path)
+		assert.Contains(t, r.URL.Query().Get("$select"), "sharePointIds")
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"drive-id","name":"Documents","sharePointIds":{"siteId":"site-id"}}`))
+	}))
+	defer srv.Close()
+
+	adpt, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		&authentication.AnonymousAuthenticationProvider{},
+		nil, nil,
+		srv.Client())
+	require.NoError(t, err)
+
+	adpt.SetBaseUrl(srv.URL)
+
+	d, err := NewClientFromService(graph.NewService(adpt)).Drives().GetDrive(ctx, "drive-id")
+	require.NoError(t, err)
+	require.NotNil(t, d.GetSharePointIds())
+	assert.Equal(t, "site-id", ptr.Val(d.GetSharePointIds().GetSiteId()))
+}
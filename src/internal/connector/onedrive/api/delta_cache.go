@@ -0,0 +1,170 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+// defaultDeltaCacheSubdir is the directory, within the user's cache
+// directory, that holds the delta cache when no other directory is given.
+var defaultDeltaCacheSubdir = filepath.Join("corso", "delta-cache")
+
+// MaxDeltaCacheAge bounds how long a cached page stays fresh, so that the
+// cache never serves a drive's contents long after they changed.  Longer
+// max ages are reduced to it.
+const MaxDeltaCacheAge = 24 * time.Hour
+
+// DeltaCache keeps the json body of each drive delta page on disk, keyed
+// by the url that requested it.  Delta and next links embed the tokens
+// that produced them, so a retried enumeration that requests the same
+// links within the freshness window reads the pages it already retrieved
+// instead of asking graph for them again.  Pages are cached without their
+// items' download urls, which expire within the hour; items read from the
+// cache are retrieved again before they're downloaded.
+type DeltaCache struct {
+	dir    string
+	maxAge time.Duration
+	now    func() time.Time
+
+	// pruneOnce removes the stale pages left by earlier runs before the
+	// first page is cached.
+	pruneOnce sync.Once
+}
+
+// NewDeltaCache produces a delta cache from the options.  Returns nil if
+// caching is disabled.
+func NewDeltaCache(opts control.DeltaCacheOptions) *DeltaCache {
+	if !opts.Enabled() {
+		return nil
+	}
+
+	dir := opts.Dir
+	if len(dir) == 0 {
+		dir = defaultDeltaCacheDir()
+	}
+
+	maxAge := opts.MaxAge
+	if maxAge > MaxDeltaCacheAge {
+		maxAge = MaxDeltaCacheAge
+	}
+
+	return &DeltaCache{
+		dir:    dir,
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// defaultDeltaCacheDir places the cache in the user's own cache directory,
+// rather than the shared temp directory, since pages hold the names of
+// the drive's items.
+func defaultDeltaCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+
+	return filepath.Join(base, defaultDeltaCacheSubdir)
+}
+
+// Get returns the cached page requested by the link.  Pages older than the
+// freshness window are removed, and reported as missing.
+func (dc *DeltaCache) Get(link string) ([]byte, bool) {
+	if dc == nil {
+		return nil, false
+	}
+
+	fp := dc.filePath(link)
+
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return nil, false
+	}
+
+	if dc.now().Sub(fi.ModTime()) > dc.maxAge {
+		_ = os.Remove(fp)
+		return nil, false
+	}
+
+	bs, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, false
+	}
+
+	return bs, true
+}
+
+// Put caches the page requested by the link.  The page is written to a
+// temp file first, so that an interrupted run never leaves a partial page
+// behind.
+func (dc *DeltaCache) Put(link string, page []byte) error {
+	if dc == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(dc.dir, 0o700); err != nil {
+		return clues.Wrap(err, "creating delta cache dir")
+	}
+
+	dc.pruneOnce.Do(dc.prune)
+
+	f, err := os.CreateTemp(dc.dir, "page-*")
+	if err != nil {
+		return clues.Wrap(err, "creating delta cache file")
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(page); err != nil {
+		f.Close()
+		return clues.Wrap(err, "writing delta cache file")
+	}
+
+	if err := f.Close(); err != nil {
+		return clues.Wrap(err, "closing delta cache file")
+	}
+
+	if err := os.Rename(f.Name(), dc.filePath(link)); err != nil {
+		return clues.Wrap(err, "storing delta cache file")
+	}
+
+	return nil
+}
+
+// prune removes the pages, and any temp files abandoned by interrupted
+// runs, that are older than the freshness window.  Pages are otherwise
+// only removed when a later run requests the same link.
+func (dc *DeltaCache) prune() {
+	entries, err := os.ReadDir(dc.dir)
+	if err != nil {
+		return
+	}
+
+	for _, ent := range entries {
+		if ent.IsDir() {
+			continue
+		}
+
+		fi, err := ent.Info()
+		if err != nil || dc.now().Sub(fi.ModTime()) <= dc.maxAge {
+			continue
+		}
+
+		_ = os.Remove(filepath.Join(dc.dir, ent.Name()))
+	}
+}
+
+// filePath hashes the link, since links are too long, and hold too many
+// reserved characters, to be used as file names.
+func (dc *DeltaCache) filePath(link string) string {
+	sum := sha256.Sum256([]byte(link))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:]))
+}
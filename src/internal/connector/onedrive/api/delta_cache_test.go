@@ -0,0 +1,113 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type DeltaCacheUnitSuite struct {
+	tester.Suite
+}
+
+func TestDeltaCacheUnitSuite(t *testing.T) {
+	suite.Run(t, &DeltaCacheUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *DeltaCacheUnitSuite) TestNewDeltaCache() {
+	t := suite.T()
+
+	assert.Nil(t, NewDeltaCache(control.DeltaCacheOptions{Dir: t.TempDir()}), "disabled without a max age")
+
+	dc := NewDeltaCache(control.DeltaCacheOptions{MaxAge: time.Hour})
+	require.NotNil(t, dc)
+	assert.Equal(t, defaultDeltaCacheDir(), dc.dir)
+	assert.Contains(t, dc.dir, defaultDeltaCacheSubdir)
+	assert.Equal(t, time.Hour, dc.maxAge)
+
+	dc = NewDeltaCache(control.DeltaCacheOptions{MaxAge: 7 * 24 * time.Hour})
+	require.NotNil(t, dc)
+	assert.Equal(t, MaxDeltaCacheAge, dc.maxAge, "max age is capped")
+}
+
+func (suite *DeltaCacheUnitSuite) TestPrune() {
+	var (
+		t   = suite.T()
+		dir = t.TempDir()
+		dc  = NewDeltaCache(control.DeltaCacheOptions{Dir: dir, MaxAge: time.Hour})
+		old = time.Now().Add(-2 * time.Hour)
+	)
+
+	require.NotNil(t, dc)
+
+	for _, name := range []string{"stale", "page-abandoned"} {
+		fp := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(fp, []byte("page"), 0o600))
+		require.NoError(t, os.Chtimes(fp, old, old))
+	}
+
+	require.NoError(t, dc.Put("link", []byte(rawDeltaPageJSON)))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "stale pages and abandoned temp files are pruned")
+	assert.Equal(t, filepath.Base(dc.filePath("link")), entries[0].Name())
+}
+
+func (suite *DeltaCacheUnitSuite) TestGetPut() {
+	var (
+		t    = suite.T()
+		dir  = filepath.Join(t.TempDir(), "delta")
+		dc   = NewDeltaCache(control.DeltaCacheOptions{Dir: dir, MaxAge: time.Hour})
+		link = "https://graph.microsoft.com/v1.0/drives/drive/root/delta?token=abc"
+		now  = time.Now()
+	)
+
+	require.NotNil(t, dc)
+
+	dc.now = func() time.Time { return now }
+
+	_, ok := dc.Get(link)
+	assert.False(t, ok, "nothing cached yet")
+
+	require.NoError(t, dc.Put(link, []byte(rawDeltaPageJSON)))
+
+	bs, ok := dc.Get(link)
+	require.True(t, ok)
+	assert.Equal(t, rawDeltaPageJSON, string(bs))
+
+	_, ok = dc.Get(link + "&next")
+	assert.False(t, ok, "other links are cached separately")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files are left behind")
+
+	now = now.Add(2 * time.Hour)
+
+	_, ok = dc.Get(link)
+	assert.False(t, ok, "stale pages are missing")
+
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "stale pages are removed")
+}
+
+func (suite *DeltaCacheUnitSuite) TestNil() {
+	t := suite.T()
+
+	var dc *DeltaCache
+
+	assert.NoError(t, dc.Put("link", []byte("page")))
+
+	_, ok := dc.Get("link")
+	assert.False(t, ok)
+}
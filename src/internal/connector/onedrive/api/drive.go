@@ -7,26 +7,13 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	mssites "github.com/microsoftgraph/msgraph-sdk-go/sites"
 	msusers "github.com/microsoftgraph/msgraph-sdk-go/users"
-	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/internal/connector/graph/api"
 )
 
-func getValues[T any](l api.PageLinker) ([]T, error) {
-	page, ok := l.(interface{ GetValue() []T })
-	if !ok {
-		return nil, errors.Errorf(
-			"response of type [%T] does not comply with GetValue() interface",
-			l,
-		)
-	}
-
-	return page.GetValue(), nil
-}
-
 // max we can do is 999
-const pageSize = int32(999)
+const maxPageSize = int32(999)
 
 type driveItemPager struct {
 	gs      graph.Servicer
@@ -35,12 +22,19 @@ type driveItemPager struct {
 	options *msdrives.ItemRootDeltaRequestBuilderGetRequestConfiguration
 }
 
+// NewItemPager produces a pager for the delta of items in the drive.
+// Page sizes less than 1, or greater than the max, use the max page size.
 func NewItemPager(
 	gs graph.Servicer,
 	driveID, link string,
 	fields []string,
+	pageSize int,
 ) *driveItemPager {
-	pageCount := pageSize
+	pageCount := maxPageSize
+	if pageSize > 0 && pageSize < int(maxPageSize) {
+		pageCount = int32(pageSize)
+	}
+
 	requestConfig := &msdrives.ItemRootDeltaRequestBuilderGetRequestConfiguration{
 		QueryParameters: &msdrives.ItemRootDeltaRequestBuilderGetQueryParameters{
 			Top:    &pageCount,
@@ -82,7 +76,7 @@ func (p *driveItemPager) Reset() {
 }
 
 func (p *driveItemPager) ValuesIn(l api.DeltaPageLinker) ([]models.DriveItemable, error) {
-	return getValues[models.DriveItemable](l)
+	return graph.PageValues[models.DriveItemable](l)
 }
 
 type userDrivePager struct {
@@ -127,7 +121,7 @@ func (p *userDrivePager) SetNext(link string) {
 }
 
 func (p *userDrivePager) ValuesIn(l api.PageLinker) ([]models.Driveable, error) {
-	return getValues[models.Driveable](l)
+	return graph.PageValues[models.Driveable](l)
 }
 
 type siteDrivePager struct {
@@ -172,5 +166,5 @@ func (p *siteDrivePager) SetNext(link string) {
 }
 
 func (p *siteDrivePager) ValuesIn(l api.PageLinker) ([]models.Driveable, error) {
-	return getValues[models.Driveable](l)
+	return graph.PageValues[models.Driveable](l)
 }
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type DriveRawUnitSuite struct {
+	tester.Suite
+}
+
+func TestDriveRawUnitSuite(t *testing.T) {
+	suite.Run(t, &DriveRawUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+const rawDeltaPageJSON = `{
+	"@odata.context": "https://graph.microsoft.com/v1.0/$metadata#Collection(driveItem)",
+	"@odata.nextLink": "https://graph.microsoft.com/v1.0/drives/drive/root/delta?token=next",
+	"value": [
+		{
+			"@microsoft.graph.downloadUrl": "https://download/file",
+			"createdDateTime": "2023-01-10T12:34:56Z",
+			"id": "file",
+			"lastModifiedDateTime": "2023-01-11T01:02:03.456Z",
+			"name": "file.txt",
+			"size": 42,
+			"createdBy": {"user": {"id": "user", "displayName": "User", "email": "user@contoso.com"}},
+			"parentReference": {"driveId": "drive", "driveType": "business", "id": "folder", "path": "/drive/root:/folder"},
+			"file": {"mimeType": "text/plain", "hashes": {"quickXorHash": "abc"}},
+			"sharepointIds": {"siteId": "site", "siteUrl": "https://contoso.sharepoint.com"},
+			"shared": {"scope": "users"},
+			"sensitivityLabel": {"labelId": "label", "displayName": "Confidential"}
+		},
+		{
+			"id": "folder",
+			"name": "folder",
+			"folder": {"childCount": 1},
+			"parentReference": {"driveId": "drive", "id": "root", "path": "/drive/root:"}
+		},
+		{
+			"id": "root",
+			"name": "root",
+			"root": {},
+			"folder": {"childCount": 1}
+		},
+		{
+			"id": "gone",
+			"deleted": {"state": "deleted"},
+			"parentReference": {"driveId": "drive", "id": "folder"}
+		},
+		{
+			"id": "notebook",
+			"name": "notebook",
+			"package": {"type": "oneNote"},
+			"parentReference": {"driveId": "drive", "id": "root", "path": "/drive/root:"}
+		}
+	]
+}`
+
+func (suite *DriveRawUnitSuite) TestDecodeDeltaPage() {
+	t := suite.T()
+
+	page, err := decodeDeltaPage([]byte(rawDeltaPageJSON))
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"https://graph.microsoft.com/v1.0/drives/drive/root/delta?token=next",
+		ptr.Val(page.GetOdataNextLink()))
+	assert.Nil(t, page.GetOdataDeltaLink())
+
+	items := page.GetValue()
+	require.Len(t, items, 5)
+
+	file := items[0]
+	assert.Equal(t, "file", ptr.Val(file.GetId()))
+	assert.Equal(t, "file.txt", ptr.Val(file.GetName()))
+	assert.Equal(t, int64(42), ptr.Val(file.GetSize()))
+	assert.Equal(t, time.Date(2023, 1, 10, 12, 34, 56, 0, time.UTC), ptr.Val(file.GetCreatedDateTime()).UTC())
+	assert.Equal(
+		t,
+		time.Date(2023, 1, 11, 1, 2, 3, 456000000, time.UTC),
+		ptr.Val(file.GetLastModifiedDateTime()).UTC())
+	assert.Equal(t, "https://download/file", ptr.Val(file.GetAdditionalData()[downloadURLKey].(*string)))
+	assert.Equal(t, "user@contoso.com", ptr.Val(file.GetCreatedBy().GetUser().GetAdditionalData()["email"].(*string)))
+	assert.Equal(t, "text/plain", ptr.Val(file.GetFile().GetMimeType()))
+	assert.Equal(t, "abc", ptr.Val(file.GetFile().GetHashes().GetQuickXorHash()))
+	assert.Equal(t, "folder", ptr.Val(file.GetParentReference().GetId()))
+	assert.Equal(t, "/drive/root:/folder", ptr.Val(file.GetParentReference().GetPath()))
+	assert.Equal(t, "drive", ptr.Val(file.GetParentReference().GetDriveId()))
+	assert.Equal(t, "site", ptr.Val(file.GetSharepointIds().GetSiteId()))
+	assert.Equal(t, "Confidential", ptr.Val(file.GetAdditionalData()[sensitivityLabelKey].(*string)))
+	assert.Equal(t, "users", ptr.Val(file.GetShared().GetScope()))
+	assert.Nil(t, file.GetFolder())
+	assert.Nil(t, file.GetRoot())
+	assert.Nil(t, file.GetDeleted())
+
+	folder := items[1]
+	require.NotNil(t, folder.GetFolder())
+	assert.Equal(t, int32(1), ptr.Val(folder.GetFolder().GetChildCount()))
+	assert.Nil(t, folder.GetFile())
+	assert.Nil(t, folder.GetShared())
+	assert.Empty(t, folder.GetAdditionalData())
+
+	root := items[2]
+	assert.NotNil(t, root.GetRoot())
+	assert.Nil(t, root.GetParentReference())
+
+	gone := items[3]
+	require.NotNil(t, gone.GetDeleted())
+	assert.Equal(t, "deleted", ptr.Val(gone.GetDeleted().GetState()))
+	assert.Nil(t, gone.GetParentReference().GetPath())
+
+	notebook := items[4]
+	require.NotNil(t, notebook.GetPackage())
+	assert.Equal(t, "oneNote", ptr.Val(notebook.GetPackage().GetType()))
+}
+
+func (suite *DriveRawUnitSuite) TestCachePage() {
+	var (
+		t    = suite.T()
+		dc   = NewDeltaCache(control.DeltaCacheOptions{Dir: t.TempDir(), MaxAge: time.Hour})
+		link = "https://graph.microsoft.com/v1.0/drives/drive/root/delta"
+	)
+
+	require.NoError(t, cachePage(dc, link, []byte(rawDeltaPageJSON)))
+
+	bs, ok := dc.Get(link)
+	require.True(t, ok)
+	assert.NotContains(t, string(bs), "https://download/file", "download urls aren't cached")
+
+	page, err := decodeDeltaPage(bs)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"https://graph.microsoft.com/v1.0/drives/drive/root/delta?token=next",
+		ptr.Val(page.GetOdataNextLink()))
+	require.Len(t, page.GetValue(), 5)
+
+	file := page.GetValue()[0]
+	assert.Equal(t, "file.txt", ptr.Val(file.GetName()))
+	assert.NotContains(t, file.GetAdditionalData(), downloadURLKey)
+	assert.Equal(t, "Confidential", ptr.Val(file.GetAdditionalData()[sensitivityLabelKey].(*string)))
+}
+
+func (suite *DriveRawUnitSuite) TestDecodeDeltaPage_DeltaLink() {
+	t := suite.T()
+
+	page, err := decodeDeltaPage([]byte(`{"@odata.deltaLink": "https://delta", "value": []}`))
+	require.NoError(t, err)
+
+	assert.Nil(t, page.GetOdataNextLink())
+	assert.Equal(t, "https://delta", ptr.Val(page.GetOdataDeltaLink()))
+	assert.Empty(t, page.GetValue())
+}
+
+func (suite *DriveRawUnitSuite) TestDecodeDeltaPage_Malformed() {
+	_, err := decodeDeltaPage([]byte(`{"value": [`))
+	assert.Error(suite.T(), err)
+}
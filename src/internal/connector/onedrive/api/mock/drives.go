@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// Drives serves drives and drive items from memory, in place of
+// the drive api client.  Requests for unknown drives or items fail.
+type Drives struct {
+	// Drives are keyed by drive ID.
+	Drives map[string]models.Driveable
+	// Items are keyed by item ID.
+	Items map[string]models.DriveItemable
+	// Roots are the root folders of the drives, keyed by drive ID.
+	Roots map[string]models.DriveItemable
+	// Err, if populated, fails every request.
+	Err error
+
+	// Deleted records the ID of each deleted item.
+	Deleted []string
+}
+
+func (d *Drives) GetDrive(_ context.Context, driveID string) (models.Driveable, error) {
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	drive, ok := d.Drives[driveID]
+	if !ok {
+		return nil, clues.New("drive not found").With("drive_id", driveID)
+	}
+
+	return drive, nil
+}
+
+func (d *Drives) GetItem(_ context.Context, _, itemID string) (models.DriveItemable, error) {
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	item, ok := d.Items[itemID]
+	if !ok {
+		return nil, clues.New("drive item not found").With("item_id", itemID)
+	}
+
+	return item, nil
+}
+
+func (d *Drives) GetRootFolder(_ context.Context, driveID string) (models.DriveItemable, error) {
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	root, ok := d.Roots[driveID]
+	if !ok {
+		return nil, clues.New("drive not found").With("drive_id", driveID)
+	}
+
+	return root, nil
+}
+
+func (d *Drives) DeleteItem(_ context.Context, _, itemID string) error {
+	if d.Err != nil {
+		return d.Err
+	}
+
+	delete(d.Items, itemID)
+	d.Deleted = append(d.Deleted, itemID)
+
+	return nil
+}
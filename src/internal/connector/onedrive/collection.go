@@ -6,18 +6,21 @@ import (
 	"io"
 	"net/http"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/pkg/errors"
-	"github.com/spatialcurrent/go-lazy/pkg/lazy"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/spill"
+	"github.com/alcionai/corso/src/internal/stall"
+	"github.com/alcionai/corso/src/internal/stats"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
@@ -36,6 +39,10 @@ const (
 	MetaFileSuffix    = ".meta"
 	DirMetaFileSuffix = ".dirmeta"
 	DataFileSuffix    = ".data"
+	// FieldsFileSuffix names the column values of a SharePoint library
+	// file.  SharePoint doesn't allow colons in file names, so the fields
+	// can't collide with another file.
+	FieldsFileSuffix = ":fields"
 )
 
 var (
@@ -43,6 +50,7 @@ var (
 	_ data.Stream           = &Item{}
 	_ data.StreamInfo       = &Item{}
 	_ data.StreamModTime    = &Item{}
+	_ data.StreamSize       = &Item{}
 )
 
 // Collection represents a set of OneDrive objects retrieved from M365
@@ -57,10 +65,14 @@ type Collection struct {
 	folderPath path.Path
 	// M365 IDs of file items within this collection
 	driveItems map[string]models.DriveItemable
+	// M365 IDs of the files whose content matches the base backup.  Only
+	// their metadata gets produced.
+	unchangedContent map[string]struct{}
 	// M365 ID of the drive this collection was created from
 	driveID        string
 	source         driveSource
 	service        graph.Servicer
+	itemGetter     itemGetter
 	statusUpdater  support.StatusUpdater
 	itemReader     itemReaderFunc
 	itemMetaReader itemMetaReaderFunc
@@ -78,8 +90,14 @@ type Collection struct {
 	doNotMergeItems bool
 }
 
+// itemGetter retrieves a fresh copy of a drive item.
+type itemGetter interface {
+	GetItem(ctx context.Context, driveID, itemID string) (models.DriveItemable, error)
+}
+
 // itemReadFunc returns a reader for the specified item
 type itemReaderFunc func(
+	ctx context.Context,
 	hc *http.Client,
 	item models.DriveItemable,
 ) (itemInfo details.ItemInfo, itemData io.ReadCloser, err error)
@@ -107,24 +125,27 @@ func NewCollection(
 	doNotMergeItems bool,
 ) *Collection {
 	c := &Collection{
-		itemClient:      itemClient,
-		folderPath:      folderPath,
-		prevPath:        prevPath,
-		driveItems:      map[string]models.DriveItemable{},
-		driveID:         driveID,
-		source:          source,
-		service:         service,
-		data:            make(chan data.Stream, collectionChannelBufferSize),
-		statusUpdater:   statusUpdater,
-		ctrl:            ctrlOpts,
-		state:           data.StateOf(prevPath, folderPath),
-		doNotMergeItems: doNotMergeItems,
+		itemClient:       itemClient,
+		folderPath:       folderPath,
+		prevPath:         prevPath,
+		driveItems:       map[string]models.DriveItemable{},
+		unchangedContent: map[string]struct{}{},
+		driveID:          driveID,
+		source:           source,
+		service:          service,
+		itemGetter:       api.NewClientFromService(service).Drives(),
+		data:             make(chan data.Stream, collectionChannelBufferSize),
+		statusUpdater:    statusUpdater,
+		ctrl:             ctrlOpts,
+		state:            data.StateOf(prevPath, folderPath),
+		doNotMergeItems:  doNotMergeItems,
 	}
 
 	// Allows tests to set a mock populator
 	switch source {
 	case SharePointSource:
 		c.itemReader = sharePointItemReader
+		c.itemMetaReader = newSharePointFieldsReader().read
 	default:
 		c.itemReader = oneDriveItemReader
 		c.itemMetaReader = oneDriveItemMetaReader
@@ -139,10 +160,21 @@ func NewCollection(
 func (oc *Collection) Add(item models.DriveItemable) bool {
 	_, found := oc.driveItems[*item.GetId()]
 	oc.driveItems[*item.GetId()] = item
+	delete(oc.unchangedContent, *item.GetId())
 
 	return !found // !found = new
 }
 
+// MarkContentUnchanged identifies a file added to the collection whose
+// content matches the base backup.  If the collection merges the items of
+// its previous path, the file's content gets sourced from the base backup
+// instead of getting downloaded.
+func (oc *Collection) MarkContentUnchanged(itemID string) {
+	if _, ok := oc.driveItems[itemID]; ok {
+		oc.unchangedContent[itemID] = struct{}{}
+	}
+}
+
 // Remove removes a item from the collection
 func (oc *Collection) Remove(item models.DriveItemable) bool {
 	_, found := oc.driveItems[*item.GetId()]
@@ -151,6 +183,7 @@ func (oc *Collection) Remove(item models.DriveItemable) bool {
 	}
 
 	delete(oc.driveItems, *item.GetId())
+	delete(oc.unchangedContent, *item.GetId())
 
 	return true
 }
@@ -164,9 +197,9 @@ func (oc *Collection) IsEmpty() bool {
 // Items() returns the channel containing M365 Exchange objects
 func (oc *Collection) Items(
 	ctx context.Context,
-	errs *fault.Errors, // TODO: currently unused while onedrive isn't up to date with clues/fault
+	errs *fault.Errors,
 ) <-chan data.Stream {
-	go oc.populateItems(ctx)
+	go oc.populateItems(ctx, errs)
 	return oc.data
 }
 
@@ -183,6 +216,12 @@ func (oc *Collection) SetFullPath(curPath path.Path) {
 	oc.state = data.StateOf(oc.prevPath, curPath)
 }
 
+// SetPreviousPath marks the collection as moved from prevPath.
+func (oc *Collection) SetPreviousPath(prevPath path.Path) {
+	oc.prevPath = prevPath
+	oc.state = data.StateOf(prevPath, oc.folderPath)
+}
+
 func (oc Collection) State() data.CollectionState {
 	return oc.state
 }
@@ -203,15 +242,27 @@ type UserPermission struct {
 // ItemMeta contains metadata about the Item. It gets stored in a
 // separate file in kopia
 type Metadata struct {
-	FileName    string           `json:"filename,omitempty"`
+	FileName string `json:"filename,omitempty"`
+	// ItemID identifies the item within its drive, so that restores can
+	// find the original item even after it was renamed or moved.
+	ItemID      string           `json:"itemID,omitempty"`
 	Permissions []UserPermission `json:"permissions,omitempty"`
+	// PermissionsCaptured is set when the backup retrieved the item's
+	// permissions.  Otherwise an empty set of permissions is unknown,
+	// rather than unshared.
+	PermissionsCaptured bool `json:"permissionsCaptured,omitempty"`
 }
 
+// ListItemFields holds the column values of a SharePoint library file,
+// keyed by the internal name of each column.
+type ListItemFields map[string]any
+
 // Item represents a single item retrieved from OneDrive
 type Item struct {
 	id   string
 	data io.ReadCloser
 	info details.ItemInfo
+	size int64
 
 	// true if the item was marked by graph as deleted.
 	deleted bool
@@ -238,18 +289,37 @@ func (od *Item) ModTime() time.Time {
 	return od.info.Modified()
 }
 
+func (od *Item) Size() int64 {
+	return od.size
+}
+
+// fieldsItem holds the column values of a SharePoint library file.  It
+// doesn't carry any item info, which keeps it out of the backup details.
+type fieldsItem struct {
+	id   string
+	data io.ReadCloser
+}
+
+func (fi *fieldsItem) UUID() string {
+	return fi.id
+}
+
+func (fi *fieldsItem) ToReader() io.ReadCloser {
+	return fi.data
+}
+
+func (fi fieldsItem) Deleted() bool {
+	return false
+}
+
 // populateItems iterates through items added to the collection
 // and uses the collection `itemReader` to read the item
-func (oc *Collection) populateItems(ctx context.Context) {
+func (oc *Collection) populateItems(ctx context.Context, errs *fault.Errors) {
 	var (
-		errs       error
-		byteCount  int64
-		itemsRead  int64
-		dirsRead   int64
-		itemsFound int64
-		dirsFound  int64
-		wg         sync.WaitGroup
-		m          sync.Mutex
+		// keyed by item, so that items recovered by the
+		// pipeline's final retry don't report their failures.
+		itemErrs = map[string]error{}
+		m        sync.Mutex
 	)
 
 	// Retrieve the OneDrive folder path to set later in
@@ -268,184 +338,370 @@ func (oc *Collection) populateItems(ctx context.Context) {
 	defer colCloser()
 	defer close(folderProgress)
 
-	semaphoreCh := make(chan struct{}, urlPrefetchChannelBufferSize)
-	defer close(semaphoreCh)
-
 	errUpdater := func(id string, err error) {
 		m.Lock()
-		errs = support.WrapAndAppend(id, err, errs)
+		itemErrs[id] = err
 		m.Unlock()
 	}
 
-	for _, item := range oc.driveItems {
-		if oc.ctrl.FailFast && errs != nil {
-			break
+	// lazy downloads fail after the pipeline has handed the item off, so
+	// the pipeline can't record their failures.
+	lazyErrUpdater := func(id string, err error) {
+		errUpdater(id, err)
+		errs.AddItem(data.ItemFailure(id, parentPathString, graph.ErrorCode(err), err, 0, false, false))
+	}
+
+	produce := func(ctx context.Context, id string, emit func(data.Stream)) (data.ItemResult, error) {
+		item := oc.driveItems[id]
+
+		// download urls expire, so the final retry starts
+		// over with a fresh copy of the item.
+		if data.IsFinalRetry(ctx) {
+			di, err := oc.itemGetter.GetItem(ctx, oc.driveID, id)
+			if err != nil {
+				err = clues.Wrap(err, "refreshing item").WithClues(ctx)
+				errUpdater(id, err)
+
+				return data.ItemResult{Uncounted: item.GetFile() == nil}, err
+			}
+
+			item = di
 		}
 
-		semaphoreCh <- struct{}{}
+		result, err := oc.produceItem(ctx, item, parentPathString, errUpdater, lazyErrUpdater, emit)
+		if err == nil && data.IsFinalRetry(ctx) {
+			m.Lock()
+			delete(itemErrs, id)
+			m.Unlock()
+		}
 
-		wg.Add(1)
+		return result, err
+	}
 
-		go func(item models.DriveItemable) {
-			defer wg.Done()
-			defer func() { <-semaphoreCh }()
+	results := data.RunPipeline(
+		ctx,
+		oc.data,
+		maps.Keys(oc.driveItems),
+		nil,
+		produce,
+		data.PipelineOptions{
+			Concurrency:  urlPrefetchChannelBufferSize,
+			ErrorCode:    graph.ErrorCode,
+			FinalRetries: data.DefaultFinalRetries,
+			Progress:     folderProgress,
+			Folder:       parentPathString,
+		}.Tuned(oc.ctrl.Tuning),
+		errs)
+
+	var statusErr error
+
+	// lazy readers may still report download failures.
+	m.Lock()
+	for id, err := range itemErrs {
+		statusErr = support.WrapAndAppend(id, err, statusErr)
+	}
+	m.Unlock()
 
-			// Read the item
-			var (
-				itemID       = *item.GetId()
-				itemName     = *item.GetName()
-				itemSize     = *item.GetSize()
-				itemInfo     details.ItemInfo
-				itemMeta     io.ReadCloser
-				itemMetaSize int
-				metaSuffix   string
-				err          error
-			)
+	oc.reportAsCompleted(ctx, results.Objects, results.Successes, results.Bytes, statusErr)
+}
 
-			isFile := item.GetFile() != nil
+// produceItem hands the item's data and metadata to emit.  Folders
+// only produce metadata.
+func (oc *Collection) produceItem(
+	ctx context.Context,
+	item models.DriveItemable,
+	parentPathString string,
+	errUpdater, lazyErrUpdater func(string, error),
+	emit func(data.Stream),
+) (data.ItemResult, error) {
+	var (
+		itemID       = *item.GetId()
+		itemName     = *item.GetName()
+		itemSize     = *item.GetSize()
+		isFile       = item.GetFile() != nil
+		result       = data.ItemResult{Bytes: itemSize, Uncounted: !isFile, Lazy: isFile}
+		itemInfo     details.ItemInfo
+		itemMeta     io.ReadCloser
+		itemMetaSize int
+		metaSuffix   = DirMetaFileSuffix
+		err          error
+	)
 
-			if isFile {
-				atomic.AddInt64(&itemsFound, 1)
+	switch {
+	case oc.source == SharePointSource:
+		metaSuffix = FieldsFileSuffix
+	case isFile:
+		metaSuffix = MetaFileSuffix
+	}
 
-				metaSuffix = MetaFileSuffix
-			} else {
-				atomic.AddInt64(&dirsFound, 1)
+	// SharePoint only produces the column values of files.
+	if oc.source == OneDriveSource || isFile {
+		// Fetch metadata for the file
+		itemMeta, itemMetaSize, err = oc.itemMetaReader(
+			ctx,
+			oc.service,
+			oc.driveID,
+			item,
+			oc.ctrl.EffectivePermissions().OneDrive.Backup)
+
+		switch {
+		case err != nil && oc.source == SharePointSource:
+			// column values are secondary to the file itself, so failing
+			// to get them (ex: a list that doesn't support the fields api)
+			// only leaves them out of the backup.
+			logger.Ctx(ctx).
+				With("err", err).
+				Errorw("skipping sharepoint column values", clues.InErr(err).Slice()...)
+
+			itemMeta = nil
+		case err != nil:
+			err = clues.Wrap(err, "getting item metadata")
+			errUpdater(itemID, err)
+
+			return result, err
+		}
+	}
 
-				metaSuffix = DirMetaFileSuffix
-			}
+	switch oc.source {
+	case SharePointSource:
+		itemInfo.SharePoint = sharePointItemInfo(item, itemSize)
+		itemInfo.SharePoint.ParentPath = parentPathString
+	default:
+		itemInfo.OneDrive = oneDriveItemInfo(item, itemSize)
+		itemInfo.OneDrive.ParentPath = parentPathString
+	}
 
-			if oc.source == OneDriveSource {
-				// Fetch metadata for the file
-				itemMeta, itemMetaSize, err = oc.itemMetaReader(
-					ctx,
-					oc.service,
-					oc.driveID,
-					item,
-					oc.ctrl.ToggleFeatures.EnablePermissionsBackup)
-
-				if err != nil {
-					errUpdater(itemID, clues.Wrap(err, "getting item metadata"))
-					return
+	// kopia merges the previous content of unchanged files from the base
+	// backup, since their data isn't produced.
+	unchanged := isFile && oc.sourcesContentFromBase(itemID)
+	if unchanged {
+		logger.Ctx(ctx).Debugw("sourcing unchanged content from base backup", "item_id", itemID)
+
+		result.Bytes = 0
+		result.Lazy = false
+	}
+
+	if isFile && !unchanged {
+		dataSuffix := ""
+		if oc.source == OneDriveSource {
+			dataSuffix = DataFileSuffix
+		}
+
+		// Construct a new lazy readCloser to feed to the collection consumer.
+		// This ensures that downloads won't be attempted unless that consumer
+		// attempts to read bytes.  Assumption is that kopia will check things
+		// like file modtimes before attempting to read.
+		itemReader := data.LazyReader(func() (io.ReadCloser, error) {
+			downloadStart := time.Now()
+
+			// the collection is done by the time the consumer reads the
+			// item, so the download gets tracked on its own.
+			rctx, rt := stall.Ctx(ctx).TrackRead(ctx, parentPathString)
+
+			_, itemData, err := oc.itemReader(rctx, oc.itemClient, item)
+
+			if err != nil && (graph.IsErrUnauthorized(err) || errors.Is(err, errNoDownloadURL)) {
+				// assume unauthorized requests are a sign of an expired
+				// jwt token, and that we've overrun the available window
+				// to download the actual file.  Items enumerated from the
+				// delta cache have no download url at all.  Either way,
+				// getting the item again refreshes its download url, and
+				// the download is retried once.
+				di, diErr := oc.itemGetter.GetItem(rctx, oc.driveID, itemID)
+				if diErr != nil {
+					err = errors.Wrap(diErr, "retrieving expired item")
+				} else {
+					item = di
+					_, itemData, err = oc.itemReader(rctx, oc.itemClient, item)
 				}
 			}
 
-			switch oc.source {
-			case SharePointSource:
-				itemInfo.SharePoint = sharePointItemInfo(item, itemSize)
-				itemInfo.SharePoint.ParentPath = parentPathString
-			default:
-				itemInfo.OneDrive = oneDriveItemInfo(item, itemSize)
-				itemInfo.OneDrive.ParentPath = parentPathString
+			// check for errors following retries
+			if err != nil {
+				rt.Done()
+				lazyErrUpdater(itemID, err)
+				return nil, err
 			}
 
-			if isFile {
-				dataSuffix := ""
-				if oc.source == OneDriveSource {
-					dataSuffix = DataFileSuffix
-				}
+			// display/log the item download
+			progReader, closer := observe.ItemProgress(
+				ctx,
+				itemData,
+				observe.ItemBackupMsg,
+				observe.PII(itemName+dataSuffix),
+				itemSize,
+			)
+			go closer()
 
-				// Construct a new lazy readCloser to feed to the collection consumer.
-				// This ensures that downloads won't be attempted unless that consumer
-				// attempts to read bytes.  Assumption is that kopia will check things
-				// like file modtimes before attempting to read.
-				itemReader := lazy.NewLazyReadCloser(func() (io.ReadCloser, error) {
-					// Read the item
-					var (
-						itemData io.ReadCloser
-						err      error
-					)
-
-					_, itemData, err = oc.itemReader(oc.itemClient, item)
-
-					if err != nil && graph.IsErrUnauthorized(err) {
-						// assume unauthorized requests are a sign of an expired
-						// jwt token, and that we've overrun the available window
-						// to download the actual file.  Re-downloading the item
-						// will refresh that download url.
-						di, diErr := getDriveItem(ctx, oc.service, oc.driveID, itemID)
-						if diErr != nil {
-							err = errors.Wrap(diErr, "retrieving expired item")
-						}
-						item = di
-					}
-
-					// check for errors following retries
-					if err != nil {
-						errUpdater(itemID, err)
-						return nil, err
-					}
-
-					// display/log the item download
-					progReader, closer := observe.ItemProgress(
-						ctx,
-						itemData,
-						observe.ItemBackupMsg,
-						observe.PII(itemName+dataSuffix),
-						itemSize,
-					)
-					go closer()
-
-					return progReader, nil
-				})
-
-				oc.data <- &Item{
-					id:   itemName + dataSuffix,
-					data: itemReader,
-					info: itemInfo,
-				}
+			timing := stats.ItemTiming{
+				Folder: parentPathString,
+				Item:   itemName,
+				ItemID: itemID,
+				Bytes:  itemSize,
 			}
 
-			if oc.source == OneDriveSource {
-				metaReader := lazy.NewLazyReadCloser(func() (io.ReadCloser, error) {
-					progReader, closer := observe.ItemProgress(
-						ctx, itemMeta, observe.ItemBackupMsg,
-						observe.PII(itemName+metaSuffix), int64(itemMetaSize))
-					go closer()
-					return progReader, nil
-				})
-
-				// TODO(meain): Remove this once we change to always
-				// backing up permissions. Until then we cannot rely
-				// on whether the previous data is what we need as the
-				// user might have not backup up permissions in the
-				// previous run.
-				metaItemInfo := details.ItemInfo{}
-				metaItemInfo.OneDrive = &details.OneDriveInfo{
-					Created:    itemInfo.OneDrive.Created,
-					ItemName:   itemInfo.OneDrive.ItemName,
-					DriveName:  itemInfo.OneDrive.DriveName,
-					ItemType:   itemInfo.OneDrive.ItemType,
-					IsMeta:     true,
-					Modified:   time.Now(), // set to current time to always refresh
-					Owner:      itemInfo.OneDrive.Owner,
-					ParentPath: itemInfo.OneDrive.ParentPath,
-					Size:       itemInfo.OneDrive.Size,
-				}
+			return timedReader(ctx, rt.Wrap(progReader), time.Since(downloadStart), timing), nil
+		})
 
-				oc.data <- &Item{
-					id:   itemName + metaSuffix,
-					data: metaReader,
-					info: metaItemInfo,
-				}
+		itemData := itemReader
+
+		// New collections have no prior snapshot entries for kopia to
+		// compare against, so every item will get downloaded.  When the
+		// operation provides a spill buffer, download those items ahead
+		// of the upload and hold them on disk instead of in memory.
+		if buf := spill.Ctx(ctx); buf != nil && oc.state == data.NewState {
+			rc, spilled, err := buf.Spill(ctx, itemReader, itemSize)
+			if err != nil {
+				itemReader.Close()
+
+				err = clues.Wrap(err, "spilling item to disk")
+				errUpdater(itemID, err)
+
+				return result, err
 			}
 
-			// Item read successfully, add to collection
-			if isFile {
-				atomic.AddInt64(&itemsRead, 1)
-			} else {
-				atomic.AddInt64(&dirsRead, 1)
+			if spilled {
+				itemReader.Close()
+				itemData = rc
 			}
+		}
+
+		emit(&Item{
+			id:   itemName + dataSuffix,
+			data: itemData,
+			info: itemInfo,
+			size: itemSize,
+		})
+	}
+
+	if oc.source == SharePointSource && isFile && itemMeta != nil {
+		emit(&fieldsItem{
+			id: itemName + metaSuffix,
+			data: data.LazyReader(func() (io.ReadCloser, error) {
+				progReader, closer := observe.ItemProgress(
+					ctx, itemMeta, observe.ItemBackupMsg,
+					observe.PII(itemName+metaSuffix), int64(itemMetaSize))
+				go closer()
+				return progReader, nil
+			}),
+		})
+	}
+
+	if oc.source == OneDriveSource {
+		metaReader := data.LazyReader(func() (io.ReadCloser, error) {
+			progReader, closer := observe.ItemProgress(
+				ctx, itemMeta, observe.ItemBackupMsg,
+				observe.PII(itemName+metaSuffix), int64(itemMetaSize))
+			go closer()
+			return progReader, nil
+		})
+
+		// TODO(meain): Remove this once we change to always
+		// backing up permissions. Until then we cannot rely
+		// on whether the previous data is what we need as the
+		// user might have not backup up permissions in the
+		// previous run.
+		metaItemInfo := details.ItemInfo{}
+		metaItemInfo.OneDrive = &details.OneDriveInfo{
+			Created:    itemInfo.OneDrive.Created,
+			ItemName:   itemInfo.OneDrive.ItemName,
+			DriveName:  itemInfo.OneDrive.DriveName,
+			ItemType:   itemInfo.OneDrive.ItemType,
+			IsMeta:     true,
+			Modified:   time.Now(), // set to current time to always refresh
+			Owner:      itemInfo.OneDrive.Owner,
+			ParentPath: itemInfo.OneDrive.ParentPath,
+			Size:       itemInfo.OneDrive.Size,
+		}
+
+		emit(&Item{
+			id:   itemName + metaSuffix,
+			data: metaReader,
+			info: metaItemInfo,
+		})
+	}
 
-			// byteCount iteration
-			atomic.AddInt64(&byteCount, itemSize)
+	return result, nil
+}
+
+// sourcesContentFromBase is true if the file's content matches the base
+// backup, and the collection merges the items of its previous path, which
+// hold that content.
+func (oc *Collection) sourcesContentFromBase(itemID string) bool {
+	if oc.prevPath == nil || oc.doNotMergeItems || oc.ctrl.ToggleFeatures.DisableHashSkip {
+		return false
+	}
+
+	_, ok := oc.unchangedContent[itemID]
+
+	return ok
+}
+
+// contentFromBase returns the IDs of the files whose content gets sourced
+// from the base backup.
+func (oc *Collection) contentFromBase() []string {
+	ids := []string{}
+
+	for id := range oc.unchangedContent {
+		if oc.sourcesContentFromBase(id) {
+			ids = append(ids, id)
+		}
+	}
 
-			folderProgress <- struct{}{}
-		}(item)
+	return ids
+}
+
+// timedReader records the time taken to download the item, and adds it to
+// the folder's throughput, when the download is closed.  Lazy items aren't
+// timed by the collection pipeline.  Only the time spent requesting the
+// item, and reading its data, is counted, so that the time kopia spends
+// uploading the data between reads isn't attributed to the download.
+func timedReader(
+	ctx context.Context,
+	rc io.ReadCloser,
+	requested time.Duration,
+	timing stats.ItemTiming,
+) io.ReadCloser {
+	rec := stats.RecorderCtx(ctx)
+	if rec == nil {
+		return rc
 	}
 
-	wg.Wait()
+	timing.Duration = requested
+
+	return &timedReadCloser{ReadCloser: rc, rec: rec, timing: timing}
+}
+
+type timedReadCloser struct {
+	io.ReadCloser
+	rec    *stats.PerformanceRecorder
+	timing stats.ItemTiming
+	read   int64
+	once   sync.Once
+}
+
+func (tr *timedReadCloser) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := tr.ReadCloser.Read(p)
+
+	tr.timing.Duration += time.Since(start)
+	tr.read += int64(n)
+
+	return n, err
+}
 
-	oc.reportAsCompleted(ctx, int(itemsFound), int(itemsRead), byteCount, errs)
+func (tr *timedReadCloser) Close() error {
+	tr.once.Do(func() {
+		tr.rec.RecordItem(tr.timing)
+		tr.rec.RecordFolder(stats.FolderThroughput{
+			Folder:   tr.timing.Folder,
+			Items:    1,
+			Bytes:    tr.read,
+			Duration: tr.timing.Duration,
+		})
+	})
+
+	return tr.ReadCloser.Close()
 }
 
 func (oc *Collection) reportAsCompleted(ctx context.Context, itemsFound, itemsRead int, byteCount int64, errs error) {
@@ -11,15 +11,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alcionai/clues"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	odmock "github.com/alcionai/corso/src/internal/connector/onedrive/api/mock"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/stats"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
@@ -94,7 +98,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 			numInstances: 1,
 			source:       OneDriveSource,
 			itemDeets:    nst{testItemName, 42, now},
-			itemReader: func(*http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+			itemReader: func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
 				return details.ItemInfo{OneDrive: &details.OneDriveInfo{ItemName: testItemName, Modified: now}},
 					io.NopCloser(bytes.NewReader(testItemData)),
 					nil
@@ -109,7 +113,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 			numInstances: 3,
 			source:       OneDriveSource,
 			itemDeets:    nst{testItemName, 42, now},
-			itemReader: func(*http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+			itemReader: func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
 				return details.ItemInfo{OneDrive: &details.OneDriveInfo{ItemName: testItemName, Modified: now}},
 					io.NopCloser(bytes.NewReader(testItemData)),
 					nil
@@ -124,7 +128,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 			numInstances: 1,
 			source:       SharePointSource,
 			itemDeets:    nst{testItemName, 42, now},
-			itemReader: func(*http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+			itemReader: func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
 				return details.ItemInfo{SharePoint: &details.SharePointInfo{ItemName: testItemName, Modified: now}},
 					io.NopCloser(bytes.NewReader(testItemData)),
 					nil
@@ -139,7 +143,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 			numInstances: 3,
 			source:       SharePointSource,
 			itemDeets:    nst{testItemName, 42, now},
-			itemReader: func(*http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+			itemReader: func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
 				return details.ItemInfo{SharePoint: &details.SharePointInfo{ItemName: testItemName, Modified: now}},
 					io.NopCloser(bytes.NewReader(testItemData)),
 					nil
@@ -175,7 +179,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 				suite,
 				suite.testStatusUpdater(&wg, &collStatus),
 				test.source,
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}},
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}},
 				true)
 			require.NotNil(t, coll)
 			assert.Equal(t, folderPath, coll.FullPath())
@@ -217,11 +221,7 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 
 			wg.Wait()
 
-			if test.source == OneDriveSource {
-				require.Len(t, readItems, 2) // .data and .meta
-			} else {
-				require.Len(t, readItems, 1)
-			}
+			require.Len(t, readItems, 2) // data, and .meta or :fields
 
 			// Expect only 1 item
 			require.Equal(t, 1, collStatus.ObjectCount)
@@ -264,6 +264,12 @@ func (suite *CollectionUnitTestSuite) TestCollection() {
 				}
 
 				assert.Equal(t, tm, readMetaData)
+			} else {
+				readItemFields := readItems[1]
+
+				assert.Equal(t, testItemName+FieldsFileSuffix, readItemFields.UUID())
+				_, ok := readItemFields.(data.StreamInfo)
+				assert.False(t, ok, "column values are kept out of the backup details")
 			}
 		})
 	}
@@ -314,7 +320,7 @@ func (suite *CollectionUnitTestSuite) TestCollectionReadError() {
 				suite,
 				suite.testStatusUpdater(&wg, &collStatus),
 				test.source,
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}},
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}},
 				true)
 
 			mockItem := models.NewDriveItem()
@@ -326,7 +332,11 @@ func (suite *CollectionUnitTestSuite) TestCollectionReadError() {
 			mockItem.SetLastModifiedDateTime(&now)
 			coll.Add(mockItem)
 
-			coll.itemReader = func(*http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+			coll.itemReader = func(
+				context.Context,
+				*http.Client,
+				models.DriveItemable,
+			) (details.ItemInfo, io.ReadCloser, error) {
 				return details.ItemInfo{}, nil, assert.AnError
 			}
 
@@ -339,7 +349,9 @@ func (suite *CollectionUnitTestSuite) TestCollectionReadError() {
 				return io.NopCloser(strings.NewReader(`{}`)), 2, nil
 			}
 
-			collItem, ok := <-coll.Items(ctx, fault.New(true))
+			errs := fault.New(true)
+
+			collItem, ok := <-coll.Items(ctx, errs)
 			assert.True(t, ok)
 
 			_, err = io.ReadAll(collItem.ToReader())
@@ -350,10 +362,86 @@ func (suite *CollectionUnitTestSuite) TestCollectionReadError() {
 			// Expect no items
 			require.Equal(t, 1, collStatus.ObjectCount, "only one object should be counted")
 			require.Equal(t, 1, collStatus.Successful, "TODO: should be 0, but allowing 1 to reduce async management")
+
+			// the lazy download's failure is still recorded against the item.
+			items := errs.Data().Items
+			require.Len(t, items, 1)
+			assert.Equal(t, testItemID, items[0].ID)
 		})
 	}
 }
 
+func (suite *CollectionUnitTestSuite) TestCollectionFieldsReadError() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t                = suite.T()
+		name             = "name"
+		size       int64 = 42
+		now              = time.Now()
+		testItemID       = "fakeItemID"
+		collStatus       = support.ConnectorOperationStatus{}
+		wg               = sync.WaitGroup{}
+	)
+
+	wg.Add(1)
+
+	folderPath, err := GetCanonicalPath("drive/driveID1/root:/folderPath", "a-tenant", "a-site", SharePointSource)
+	require.NoError(t, err)
+
+	coll := NewCollection(
+		graph.HTTPClient(graph.NoTimeout()),
+		folderPath,
+		nil,
+		"fakeDriveID",
+		suite,
+		suite.testStatusUpdater(&wg, &collStatus),
+		SharePointSource,
+		control.Options{},
+		true)
+
+	mockItem := models.NewDriveItem()
+	mockItem.SetId(&testItemID)
+	mockItem.SetFile(models.NewFile())
+	mockItem.SetName(&name)
+	mockItem.SetSize(&size)
+	mockItem.SetCreatedDateTime(&now)
+	mockItem.SetLastModifiedDateTime(&now)
+	coll.Add(mockItem)
+
+	coll.itemReader = func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+		return details.ItemInfo{}, io.NopCloser(strings.NewReader("content")), nil
+	}
+
+	coll.itemMetaReader = func(_ context.Context,
+		_ graph.Servicer,
+		_ string,
+		_ models.DriveItemable,
+		_ bool,
+	) (io.ReadCloser, int, error) {
+		return nil, 0, assert.AnError
+	}
+
+	errs := fault.New(false)
+	items := []data.Stream{}
+
+	for item := range coll.Items(ctx, errs) {
+		items = append(items, item)
+	}
+
+	wg.Wait()
+
+	// the file is backed up without its column values.
+	require.Len(t, items, 1)
+	assert.Equal(t, name, items[0].UUID())
+
+	bs, err := io.ReadAll(items[0].ToReader())
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(bs))
+	assert.NoError(t, errs.Err())
+}
+
 // TODO(meain): Remove this test once we start always backing up permissions
 func (suite *CollectionUnitTestSuite) TestCollectionPermissionBackupLatestModTime() {
 	table := []struct {
@@ -393,7 +481,7 @@ func (suite *CollectionUnitTestSuite) TestCollectionPermissionBackupLatestModTim
 				suite,
 				suite.testStatusUpdater(&wg, &collStatus),
 				test.source,
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}},
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}},
 				true)
 
 			mtime := time.Now().AddDate(0, -1, 0)
@@ -407,6 +495,7 @@ func (suite *CollectionUnitTestSuite) TestCollectionPermissionBackupLatestModTim
 			coll.Add(mockItem)
 
 			coll.itemReader = func(
+				context.Context,
 				*http.Client,
 				models.DriveItemable,
 			) (details.ItemInfo, io.ReadCloser, error) {
@@ -448,3 +537,357 @@ func (suite *CollectionUnitTestSuite) TestCollectionPermissionBackupLatestModTim
 		})
 	}
 }
+
+func (suite *CollectionUnitTestSuite) TestCollectionFinalRetryRefreshesItem() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t            = suite.T()
+		testItemID   = "fakeItemID"
+		staleName    = "stale"
+		freshName    = "fresh"
+		testItemSize = int64(10)
+		now          = time.Now()
+		metaCalls    = 0
+
+		collStatus = support.ConnectorOperationStatus{}
+		wg         = sync.WaitGroup{}
+	)
+
+	wg.Add(1)
+
+	folderPath, err := GetCanonicalPath("drive/driveID1/root:/folderPath", "a-tenant", "a-user", OneDriveSource)
+	require.NoError(t, err)
+
+	coll := NewCollection(
+		graph.HTTPClient(graph.NoTimeout()),
+		folderPath,
+		nil,
+		"drive-id",
+		suite,
+		suite.testStatusUpdater(&wg, &collStatus),
+		OneDriveSource,
+		control.Options{},
+		true)
+
+	newItem := func(name string) models.DriveItemable {
+		item := models.NewDriveItem()
+		item.SetFile(models.NewFile())
+		item.SetId(&testItemID)
+		item.SetName(&name)
+		item.SetSize(&testItemSize)
+		item.SetCreatedDateTime(&now)
+		item.SetLastModifiedDateTime(&now)
+
+		return item
+	}
+
+	coll.Add(newItem(staleName))
+
+	coll.itemGetter = &odmock.Drives{Items: map[string]models.DriveItemable{testItemID: newItem(freshName)}}
+
+	coll.itemReader = func(context.Context, *http.Client, models.DriveItemable) (details.ItemInfo, io.ReadCloser, error) {
+		return details.ItemInfo{}, io.NopCloser(strings.NewReader("Fake Data!")), nil
+	}
+
+	// the first attempt fails, the final retry succeeds.
+	coll.itemMetaReader = func(_ context.Context,
+		_ graph.Servicer,
+		_ string,
+		_ models.DriveItemable,
+		_ bool,
+	) (io.ReadCloser, int, error) {
+		metaCalls++
+		if metaCalls == 1 {
+			return nil, 0, assert.AnError
+		}
+
+		return io.NopCloser(strings.NewReader(`{}`)), 2, nil
+	}
+
+	ids := []string{}
+	for item := range coll.Items(ctx, fault.New(false)) {
+		ids = append(ids, item.UUID())
+	}
+
+	wg.Wait()
+
+	assert.ElementsMatch(t, []string{freshName + DataFileSuffix, freshName + MetaFileSuffix}, ids)
+	assert.Equal(t, 2, metaCalls)
+	assert.Equal(t, 1, collStatus.Successful)
+	assert.NoError(t, collStatus.Err)
+}
+
+func (suite *CollectionUnitTestSuite) TestCollectionRefreshesDownloadURL() {
+	table := []struct {
+		name   string
+		failed error
+	}{
+		{
+			name:   "expired download url",
+			failed: graph.Err401Unauthorized,
+		},
+		{
+			name:   "cached item without a download url",
+			failed: errNoDownloadURL,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t          = suite.T()
+				testItemID = "fakeItemID"
+				size       = int64(10)
+				now        = time.Now()
+				reads      = []string{}
+				collStatus = support.ConnectorOperationStatus{}
+				wg         = sync.WaitGroup{}
+			)
+
+			wg.Add(1)
+
+			folderPath, err := GetCanonicalPath("drive/driveID1/root:/folderPath", "a-tenant", "a-user", OneDriveSource)
+			require.NoError(t, err)
+
+			coll := NewCollection(
+				graph.HTTPClient(graph.NoTimeout()),
+				folderPath,
+				nil,
+				"drive-id",
+				suite,
+				suite.testStatusUpdater(&wg, &collStatus),
+				OneDriveSource,
+				control.Options{},
+				true)
+
+			newItem := func(name string) models.DriveItemable {
+				item := models.NewDriveItem()
+				item.SetFile(models.NewFile())
+				item.SetId(&testItemID)
+				item.SetName(&name)
+				item.SetSize(&size)
+				item.SetCreatedDateTime(&now)
+				item.SetLastModifiedDateTime(&now)
+
+				return item
+			}
+
+			coll.Add(newItem("stale"))
+
+			coll.itemGetter = &odmock.Drives{Items: map[string]models.DriveItemable{testItemID: newItem("fresh")}}
+
+			coll.itemReader = func(
+				_ context.Context,
+				_ *http.Client,
+				item models.DriveItemable,
+			) (details.ItemInfo, io.ReadCloser, error) {
+				reads = append(reads, ptr.Val(item.GetName()))
+
+				if ptr.Val(item.GetName()) == "stale" {
+					return details.ItemInfo{}, nil, clues.Stack(test.failed)
+				}
+
+				return details.ItemInfo{}, io.NopCloser(strings.NewReader("Fake Data!")), nil
+			}
+
+			coll.itemMetaReader = func(_ context.Context,
+				_ graph.Servicer,
+				_ string,
+				_ models.DriveItemable,
+				_ bool,
+			) (io.ReadCloser, int, error) {
+				return io.NopCloser(strings.NewReader(`{}`)), 2, nil
+			}
+
+			for item := range coll.Items(ctx, fault.New(true)) {
+				if !strings.HasSuffix(item.UUID(), DataFileSuffix) {
+					continue
+				}
+
+				bs, err := io.ReadAll(item.ToReader())
+				require.NoError(t, err)
+				assert.Equal(t, "Fake Data!", string(bs))
+			}
+
+			wg.Wait()
+
+			assert.Equal(t, []string{"stale", "fresh"}, reads, "download is retried with the refreshed item")
+			assert.NoError(t, collStatus.Err)
+		})
+	}
+}
+
+func (suite *CollectionUnitTestSuite) TestCollectionUnchangedContent() {
+	folderPath, err := GetCanonicalPath("drive/driveID1/root:/folderPath", "a-tenant", "a-user", OneDriveSource)
+	require.NoError(suite.T(), err)
+
+	table := []struct {
+		name            string
+		prevPath        path.Path
+		doNotMergeItems bool
+		toggles         control.Toggles
+		expectData      bool
+	}{
+		{
+			name:     "merged from base",
+			prevPath: folderPath,
+		},
+		{
+			name:       "new collection",
+			expectData: true,
+		},
+		{
+			name:            "items not merged",
+			prevPath:        folderPath,
+			doNotMergeItems: true,
+			expectData:      true,
+		},
+		{
+			name:       "disabled",
+			prevPath:   folderPath,
+			toggles:    control.Toggles{DisableHashSkip: true},
+			expectData: true,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t            = suite.T()
+				testItemID   = "fakeItemID"
+				testItemName = "item"
+				testItemSize = int64(10)
+				now          = time.Now()
+				reads        = 0
+
+				collStatus = support.ConnectorOperationStatus{}
+				wg         = sync.WaitGroup{}
+			)
+
+			wg.Add(1)
+
+			coll := NewCollection(
+				graph.HTTPClient(graph.NoTimeout()),
+				folderPath,
+				test.prevPath,
+				"drive-id",
+				suite,
+				suite.testStatusUpdater(&wg, &collStatus),
+				OneDriveSource,
+				control.Options{ToggleFeatures: test.toggles},
+				test.doNotMergeItems)
+
+			item := models.NewDriveItem()
+			item.SetFile(models.NewFile())
+			item.SetId(&testItemID)
+			item.SetName(&testItemName)
+			item.SetSize(&testItemSize)
+			item.SetCreatedDateTime(&now)
+			item.SetLastModifiedDateTime(&now)
+			coll.Add(item)
+			coll.MarkContentUnchanged(testItemID)
+
+			coll.itemReader = func(
+				context.Context,
+				*http.Client,
+				models.DriveItemable,
+			) (details.ItemInfo, io.ReadCloser, error) {
+				reads++
+				return details.ItemInfo{}, io.NopCloser(strings.NewReader("Fake Data!")), nil
+			}
+
+			coll.itemMetaReader = func(_ context.Context,
+				_ graph.Servicer,
+				_ string,
+				_ models.DriveItemable,
+				_ bool,
+			) (io.ReadCloser, int, error) {
+				return io.NopCloser(strings.NewReader(`{}`)), 2, nil
+			}
+
+			ids := []string{}
+			for item := range coll.Items(ctx, fault.New(false)) {
+				ids = append(ids, item.UUID())
+
+				_, err := io.ReadAll(item.ToReader())
+				require.NoError(t, err)
+			}
+
+			wg.Wait()
+
+			expect := []string{testItemName + MetaFileSuffix}
+			expectReads := 0
+
+			if test.expectData {
+				expect = append(expect, testItemName+DataFileSuffix)
+				expectReads = 1
+			}
+
+			assert.ElementsMatch(t, expect, ids)
+			assert.Equal(t, expectReads, reads, "content downloads")
+			assert.Equal(t, 1, collStatus.Successful)
+		})
+	}
+}
+
+// slowReader delays each read, standing in for a slow download.
+type slowReader struct {
+	io.Reader
+	delay time.Duration
+}
+
+func (sr slowReader) Read(p []byte) (int, error) {
+	time.Sleep(sr.delay)
+	return sr.Reader.Read(p)
+}
+
+func (suite *CollectionUnitTestSuite) TestTimedReader() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		rec = stats.NewPerformanceRecorder()
+		rc  = io.NopCloser(slowReader{strings.NewReader("data"), 10 * time.Millisecond})
+	)
+
+	tr := timedReader(
+		stats.SetRecorder(ctx, rec),
+		rc,
+		time.Second,
+		stats.ItemTiming{Folder: "folder", Item: "item", Bytes: 4})
+
+	// time spent between reads, such as by kopia uploading the data,
+	// isn't counted.
+	buf := make([]byte, 2)
+
+	for {
+		_, err := tr.Read(buf)
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	require.NoError(t, tr.Close())
+	require.NoError(t, tr.Close())
+
+	perf := rec.Performance()
+
+	require.Len(t, perf.SlowestItems, 1, "closed items are recorded once")
+	assert.GreaterOrEqual(t, perf.SlowestItems[0].Duration, time.Second+30*time.Millisecond)
+	assert.Less(t, perf.SlowestItems[0].Duration, time.Second+150*time.Millisecond)
+
+	require.Len(t, perf.Folders, 1)
+	assert.Equal(t, 1, perf.Folders[0].Items)
+	assert.Equal(t, int64(4), perf.Folders[0].Bytes)
+	assert.Equal(t, perf.SlowestItems[0].Duration, perf.Folders[0].Duration)
+}
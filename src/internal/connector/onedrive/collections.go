@@ -15,12 +15,14 @@ import (
 
 	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/observe"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
 )
 
 type driveSource int
@@ -52,6 +54,18 @@ type folderMatcher interface {
 	Matches(string) bool
 }
 
+// driveFolderMatcher is an optional extension of the folderMatcher for
+// matchers that identify folders by their drive, in addition to their
+// path within the drive.
+type driveFolderMatcher interface {
+	folderMatcher
+	// ResolveDrives is called with all of the resource owner's drives
+	// before any drive gets enumerated.
+	ResolveDrives(ctx context.Context, drives []models.Driveable) error
+	IncludesDrive(driveID string) bool
+	MatchesInDrive(driveID, folder string) bool
+}
+
 // Collections is used to retrieve drive data for a
 // resource owner, which can be either a user or a sharepoint site.
 type Collections struct {
@@ -82,12 +96,45 @@ type Collections struct {
 	itemPagerFunc func(
 		servicer graph.Servicer,
 		driveID, link string,
+		pageSize int,
 	) itemPager
 
+	// ItemFilter, if set, excludes any file for which it returns false.
+	ItemFilter func(models.DriveItemable) bool
+
+	// ItemExclusion, if set, excludes any file for which it returns true.
+	// Unlike the ItemFilter, exclusions keep the drive's delta token: they
+	// only name transient files, like sync artifacts, whose absence from
+	// later backups doesn't matter.
+	ItemExclusion func(models.DriveItemable) bool
+
+	// ItemObserver, if set, is called with every item found by the drive
+	// enumeration, before any filters apply, so that callers can inventory
+	// the drives without enumerating them again.
+	ItemObserver func(ctx context.Context, driveID string, item models.DriveItemable)
+
+	// Metadata holds additional entries to store in the metadata
+	// collection, alongside the delta tokens and folder paths.  Entries are
+	// serialized once the drives are enumerated.
+	Metadata []graph.MetadataCollectionEntry
+
+	// prevContent holds the content of the files in the base backup, by
+	// drive ID and item ID.
+	prevContent map[string]map[string]itemContent
+	// content holds the content of the files found in the current drive
+	// enumeration, by drive ID and item ID.
+	content map[string]map[string]itemContent
+
 	// Track stats from drive enumeration. Represents the items backed up.
 	NumItems      int
 	NumFiles      int
 	NumContainers int
+	// NumSyncArtifacts counts the enumerated office lock files, temp files,
+	// and sync-conflict copies, whether or not they were excluded.
+	NumSyncArtifacts int
+	// NumUnchangedContent counts the changed files whose content matches
+	// the base backup.
+	NumUnchangedContent int
 }
 
 func NewCollections(
@@ -100,6 +147,20 @@ func NewCollections(
 	statusUpdater support.StatusUpdater,
 	ctrlOpts control.Options,
 ) *Collections {
+	ipf := defaultItemPager
+	if ctrlOpts.ToggleFeatures.RawDriveEnumeration {
+		ipf = rawItemPager
+	}
+
+	// cached pages are decoded the same way as raw pages.
+	if dc := api.NewDeltaCache(ctrlOpts.DeltaCache); dc != nil {
+		ipf = cachedItemPager(dc)
+	}
+
+	if ctrlOpts.ToggleFeatures.SimulateExpiredDeltas {
+		ipf = expiringItemPager(ipf)
+	}
+
 	return &Collections{
 		itemClient:     itemClient,
 		tenant:         tenant,
@@ -107,8 +168,9 @@ func NewCollections(
 		source:         source,
 		matcher:        matcher,
 		CollectionMap:  map[string]data.BackupCollection{},
+		content:        map[string]map[string]itemContent{},
 		drivePagerFunc: PagerForSource,
-		itemPagerFunc:  defaultItemPager,
+		itemPagerFunc:  ipf,
 		service:        service,
 		statusUpdater:  statusUpdater,
 		ctrl:           ctrlOpts,
@@ -118,7 +180,7 @@ func NewCollections(
 func deserializeMetadata(
 	ctx context.Context,
 	cols []data.RestoreCollection,
-) (map[string]string, map[string]map[string]string, error) {
+) (map[string]string, map[string]map[string]string, map[string]map[string]itemContent, error) {
 	logger.Ctx(ctx).Infow(
 		"deserialzing previous backup metadata",
 		"num_collections",
@@ -127,6 +189,7 @@ func deserializeMetadata(
 
 	prevDeltas := map[string]string{}
 	prevFolders := map[string]map[string]string{}
+	prevContent := map[string]map[string]itemContent{}
 
 	for _, col := range cols {
 		items := col.Items(ctx, nil) // TODO: fault.Errors instead of nil
@@ -134,7 +197,7 @@ func deserializeMetadata(
 		for breakLoop := false; !breakLoop; {
 			select {
 			case <-ctx.Done():
-				return nil, nil, errors.Wrap(ctx.Err(), "deserialzing previous backup metadata")
+				return nil, nil, nil, errors.Wrap(ctx.Err(), "deserialzing previous backup metadata")
 
 			case item, ok := <-items:
 				if !ok {
@@ -152,6 +215,9 @@ func deserializeMetadata(
 				case graph.DeltaURLsFileName:
 					err = deserializeMap(item.ToReader(), prevDeltas)
 
+				case graph.ItemContentFileName:
+					err = deserializeMap(item.ToReader(), prevContent)
+
 				default:
 					logger.Ctx(ctx).Infow(
 						"skipping unknown metadata file",
@@ -174,7 +240,7 @@ func deserializeMetadata(
 				// we end up in a situation where we're sourcing items from the wrong
 				// base in kopia wrapper.
 				if errors.Is(err, errExistingMapping) {
-					return nil, nil, errors.Wrapf(
+					return nil, nil, nil, errors.Wrapf(
 						err,
 						"deserializing metadata file %s",
 						item.UUID(),
@@ -212,9 +278,16 @@ func deserializeMetadata(
 				delete(prevFolders, k)
 			}
 		}
+
+		// Content is only compared against delta results.
+		for k := range prevContent {
+			if _, ok := prevDeltas[k]; !ok {
+				delete(prevContent, k)
+			}
+		}
 	}
 
-	return prevDeltas, prevFolders, nil
+	return prevDeltas, prevFolders, prevContent, nil
 }
 
 var errExistingMapping = errors.New("mapping already exists for same drive ID")
@@ -257,11 +330,13 @@ func (c *Collections) Get(
 	ctx context.Context,
 	prevMetadata []data.RestoreCollection,
 ) ([]data.BackupCollection, map[string]struct{}, error) {
-	prevDeltas, oldPathsByDriveID, err := deserializeMetadata(ctx, prevMetadata)
+	prevDeltas, oldPathsByDriveID, prevContent, err := deserializeMetadata(ctx, prevMetadata)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	c.prevContent = prevContent
+
 	// Enumerate drives for the specified resourceOwner
 	pager, err := c.drivePagerFunc(c.source, c.service, c.resourceOwner, nil)
 	if err != nil {
@@ -275,11 +350,27 @@ func (c *Collections) Get(
 		return nil, nil, err
 	}
 
+	dfm, isDriveMatcher := c.matcher.(driveFolderMatcher)
+	if isDriveMatcher {
+		if err := dfm.ResolveDrives(ctx, drives); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	includes := func(string) bool { return true }
+	if isDriveMatcher {
+		includes = dfm.IncludesDrive
+	}
+
+	transitions := detectTransitions(drives, oldPathsByDriveID, includes)
+
 	var (
 		// Drive ID -> delta URL for drive
 		deltaURLs = map[string]string{}
 		// Drive ID -> folder ID -> folder path
 		folderPaths = map[string]map[string]string{}
+		// Drive ID -> file ID -> file content
+		content = map[string]map[string]itemContent{}
 		// Items that should be excluded when sourcing data from the base backup.
 		// TODO(ashmrtn): This list contains the M365 IDs of deleted items so while
 		// it's technically safe to pass all the way through to kopia (files are
@@ -293,9 +384,20 @@ func (c *Collections) Get(
 		driveID := *d.GetId()
 		driveName := *d.GetName()
 
+		if isDriveMatcher && !dfm.IncludesDrive(driveID) {
+			continue
+		}
+
 		prevDelta := prevDeltas[driveID]
 		oldPaths := oldPathsByDriveID[driveID]
 
+		// The base of a filtered backup may hold items the filter excludes,
+		// so filtered backups always enumerate the whole drive and never
+		// merge items from the base.
+		if c.ItemFilter != nil {
+			prevDelta = ""
+		}
+
 		numOldDelta := 0
 		if len(prevDelta) > 0 {
 			numOldDelta++
@@ -314,6 +416,7 @@ func (c *Collections) Get(
 				c.service,
 				driveID,
 				"",
+				c.ctrl.Tuning.DeltaPageSize,
 			),
 			driveID,
 			driveName,
@@ -333,7 +436,10 @@ func (c *Collections) Get(
 		// remove entries for which there is no corresponding delta token/folder. If
 		// we leave empty delta tokens then we may end up setting the State field
 		// for collections when not actually getting delta results.
-		if len(delta.URL) > 0 {
+		//
+		// Filtered backups don't persist their delta token, since a later
+		// backup would otherwise never see the items the filter excluded.
+		if len(delta.URL) > 0 && c.ItemFilter == nil {
 			deltaURLs[driveID] = delta.URL
 			numDeltas++
 		}
@@ -352,8 +458,30 @@ func (c *Collections) Get(
 			"num_deltas_entries",
 			numDeltas)
 
+		// Files whose content is unchanged only replace their metadata.  Their
+		// content gets merged from the base backup, so it can't be excluded.
+		for _, col := range c.CollectionMap {
+			if oc, ok := col.(*Collection); ok && oc.driveID == driveID {
+				for _, id := range oc.contentFromBase() {
+					delete(excluded, id+DataFileSuffix)
+				}
+			}
+		}
+
+		content[driveID] = map[string]itemContent{}
+		maps.Copy(content[driveID], c.content[driveID])
+
 		if !delta.Reset {
+			// Files missing from the delta are unchanged, while changed and
+			// deleted files were all excluded.
+			for id, ic := range prevContent[driveID] {
+				if _, ok := excluded[id+DataFileSuffix]; !ok {
+					content[driveID][id] = ic
+				}
+			}
+
 			maps.Copy(excludedItems, excluded)
+
 			continue
 		}
 
@@ -400,8 +528,27 @@ func (c *Collections) Get(
 		}
 	}
 
+	for i := range transitions {
+		dt := &transitions[i]
+
+		if err := c.handleTransition(ctx, dt, oldPathsByDriveID[dt.PreviousDriveID]); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	observe.Message(ctx, observe.Safe(fmt.Sprintf("Discovered %d items to backup", c.NumItems)))
 
+	if c.NumUnchangedContent > 0 {
+		logger.Ctx(ctx).Infow("sourcing unchanged file content from base backups", "num_files", c.NumUnchangedContent)
+	}
+
+	if c.NumSyncArtifacts > 0 && c.ItemExclusion == nil {
+		logger.Ctx(ctx).Infow("backing up sync artifacts", "num_sync_artifacts", c.NumSyncArtifacts)
+		observe.Message(ctx, observe.Safe(fmt.Sprintf(
+			"Found %d office lock, temp, or sync-conflict files, which can be excluded from backups",
+			c.NumSyncArtifacts)))
+	}
+
 	// Add an extra for the metadata collection.
 	collections := make([]data.BackupCollection, 0, len(c.CollectionMap)+1)
 	for _, coll := range c.CollectionMap {
@@ -414,10 +561,11 @@ func (c *Collections) Get(
 		c.resourceOwner,
 		service,
 		category,
-		[]graph.MetadataCollectionEntry{
+		append([]graph.MetadataCollectionEntry{
 			graph.NewMetadataEntry(graph.PreviousPathFileName, folderPaths),
 			graph.NewMetadataEntry(graph.DeltaURLsFileName, deltaURLs),
-		},
+			graph.NewMetadataEntry(graph.ItemContentFileName, content),
+		}, c.Metadata...),
 		c.statusUpdater,
 	)
 
@@ -508,6 +656,10 @@ func (c *Collections) UpdateCollections(
 			ok                 bool
 		)
 
+		if c.ItemObserver != nil {
+			c.ItemObserver(ctx, driveID, item)
+		}
+
 		if item.GetRoot() != nil {
 			rootPath, err := GetCanonicalPath(
 				fmt.Sprintf(rootDrivePattern, driveID),
@@ -532,7 +684,7 @@ func (c *Collections) UpdateCollections(
 			(item.GetDeleted() == nil && item.GetParentReference().GetPath() == nil) {
 			err := clues.New("no parent reference").With("item_id", *item.GetId())
 			if item.GetName() != nil {
-				err = err.With("item_name", *item.GetName())
+				err = err.With("item_name", logger.PII(*item.GetName()))
 			}
 
 			return err
@@ -583,7 +735,7 @@ func (c *Collections) UpdateCollections(
 		// Skip items that don't match the folder selectors we were given.
 		if shouldSkipDrive(ctx, itemPath, c.matcher, driveName) &&
 			shouldSkipDrive(ctx, collectionPath, c.matcher, driveName) {
-			logger.Ctx(ctx).Infof("Skipping path %s", collectionPath.String())
+			logger.Ctx(ctx).Infow("skipping path", "path", logger.PII(collectionPath.String()))
 			continue
 		}
 
@@ -684,6 +836,22 @@ func (c *Collections) UpdateCollections(
 				c.NumFiles++
 				c.NumItems++
 
+				delete(c.content[driveID], *item.GetId())
+
+				continue
+			}
+
+			if c.ItemFilter != nil && !c.ItemFilter(item) {
+				delete(c.content[driveID], *item.GetId())
+				continue
+			}
+
+			if selectors.IsSyncArtifact(ptr.Val(item.GetName())) {
+				c.NumSyncArtifacts++
+			}
+
+			if c.ItemExclusion != nil && c.ItemExclusion(item) {
+				delete(c.content[driveID], *item.GetId())
 				continue
 			}
 
@@ -760,6 +928,8 @@ func (c *Collections) UpdateCollections(
 				c.NumFiles++
 			}
 
+			c.compareContent(driveID, item, collection, invalidPrevDelta)
+
 		default:
 			return errors.Errorf("item type not supported. item name : %s", *item.GetName())
 		}
@@ -768,6 +938,43 @@ func (c *Collections) UpdateCollections(
 	return nil
 }
 
+// compareContent records the content of the file, and marks the file as
+// unchanged within the collection if its content matches the base backup.
+func (c *Collections) compareContent(
+	driveID string,
+	item models.DriveItemable,
+	col *Collection,
+	invalidPrevDelta bool,
+) {
+	itemID := ptr.Val(item.GetId())
+
+	ic, ok := newItemContent(item)
+	if !ok {
+		delete(c.content[driveID], itemID)
+		return
+	}
+
+	if c.content == nil {
+		c.content = map[string]map[string]itemContent{}
+	}
+
+	if c.content[driveID] == nil {
+		c.content[driveID] = map[string]itemContent{}
+	}
+
+	c.content[driveID][itemID] = ic
+
+	// base backups are only merged when the previous delta is valid.
+	if invalidPrevDelta || c.ctrl.ToggleFeatures.DisableHashSkip {
+		return
+	}
+
+	if prev, ok := c.prevContent[driveID][itemID]; ok && prev == ic {
+		col.MarkContentUnchanged(itemID)
+		c.NumUnchangedContent++
+	}
+}
+
 func shouldSkipDrive(ctx context.Context, drivePath path.Path, m folderMatcher, driveName string) bool {
 	if drivePath == nil {
 		return false
@@ -803,12 +1010,18 @@ func GetCanonicalPath(p, tenant, resourceOwner string, source driveSource) (path
 
 func includePath(ctx context.Context, m folderMatcher, folderPath path.Path) bool {
 	// Check if the folder is allowed by the scope.
-	folderPathString, err := path.GetDriveFolderPath(folderPath)
+	drivePath, err := path.ToOneDrivePath(folderPath)
 	if err != nil {
 		logger.Ctx(ctx).Error(err)
 		return true
 	}
 
+	folderPathString := path.Builder{}.Append(drivePath.Folders...).String()
+
+	if dfm, ok := m.(driveFolderMatcher); ok {
+		return dfm.MatchesInDrive(drivePath.DriveID, folderPathString)
+	}
+
 	// Hack for the edge case where we're looking at the root folder and can
 	// select any folder. Right now the root folder has an empty folder path.
 	if len(folderPathString) == 0 && m.IsAny() {
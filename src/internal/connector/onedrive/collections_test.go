@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	kjson "github.com/microsoft/kiota-serialization-json-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/exp/maps"
 
+	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	gapi "github.com/alcionai/corso/src/internal/connector/graph/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
@@ -727,7 +729,7 @@ func (suite *OneDriveCollectionsSuite) TestUpdateCollections() {
 				testFolderMatcher{tt.scope},
 				&MockGraphService{},
 				nil,
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}})
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}})
 
 			itemCollection := map[string]string{}
 			err := c.UpdateCollections(
@@ -1101,7 +1103,7 @@ func (suite *OneDriveCollectionsSuite) TestDeserializeMetadata() {
 				cols = append(cols, data.NotFoundRestoreCollection{Collection: mc})
 			}
 
-			deltas, paths, err := deserializeMetadata(ctx, cols)
+			deltas, paths, _, err := deserializeMetadata(ctx, cols)
 			test.errCheck(t, err)
 
 			assert.Equal(t, test.expectedDeltas, deltas)
@@ -1110,6 +1112,193 @@ func (suite *OneDriveCollectionsSuite) TestDeserializeMetadata() {
 	}
 }
 
+func (suite *OneDriveCollectionsSuite) TestDeserializeMetadata_content() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		content = map[string]map[string]itemContent{
+			"drive1": {"item": {Hash: "quickXor:abc", Name: "name", ParentID: "root"}},
+			"drive2": {"item": {Hash: "quickXor:def", Name: "name", ParentID: "root"}},
+		}
+	)
+
+	mc, err := graph.MakeMetadataCollection(
+		"tenant",
+		"user",
+		path.OneDriveService,
+		path.FilesCategory,
+		[]graph.MetadataCollectionEntry{
+			graph.NewMetadataEntry(graph.DeltaURLsFileName, map[string]string{"drive1": "delta"}),
+			graph.NewMetadataEntry(
+				graph.PreviousPathFileName,
+				map[string]map[string]string{"drive1": {}}),
+			graph.NewMetadataEntry(graph.ItemContentFileName, content),
+		},
+		func(*support.ConnectorOperationStatus) {})
+	require.NoError(t, err)
+
+	_, _, prevContent, err := deserializeMetadata(ctx, []data.RestoreCollection{
+		data.NotFoundRestoreCollection{Collection: mc},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]map[string]itemContent{"drive1": content["drive1"]},
+		prevContent,
+		"content without a delta token is dropped")
+}
+
+func (suite *OneDriveCollectionsSuite) TestUpdateCollections_unchangedContent() {
+	rootPath := fmt.Sprintf(rootDrivePattern, "driveID1")
+
+	hashed := func(id, name, parentID, hash string) models.DriveItemable {
+		item := driveItem(id, name, rootPath, parentID, true, false, false)
+
+		hs := models.NewHashes()
+		hs.SetQuickXorHash(&hash)
+		item.GetFile().SetHashes(hs)
+
+		return item
+	}
+
+	prev := map[string]itemContent{
+		"same":    {Hash: "quickXor:abc", Name: "same", ParentID: "root"},
+		"edited":  {Hash: "quickXor:abc", Name: "edited", ParentID: "root"},
+		"renamed": {Hash: "quickXor:abc", Name: "renamed", ParentID: "root"},
+	}
+
+	items := []models.DriveItemable{
+		driveRootItem("root"),
+		hashed("same", "same", "root", "abc"),
+		hashed("edited", "edited", "root", "def"),
+		hashed("renamed", "new-name", "root", "abc"),
+		driveItem("unhashed", "unhashed", rootPath, "root", true, false, false),
+	}
+
+	table := []struct {
+		name             string
+		invalidPrevDelta bool
+		toggles          control.Toggles
+		expectUnchanged  map[string]struct{}
+	}{
+		{
+			name:            "compared",
+			expectUnchanged: map[string]struct{}{"same": {}},
+		},
+		{
+			name:             "invalid previous delta",
+			invalidPrevDelta: true,
+			expectUnchanged:  map[string]struct{}{},
+		},
+		{
+			name:            "disabled",
+			toggles:         control.Toggles{DisableHashSkip: true},
+			expectUnchanged: map[string]struct{}{},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			c := NewCollections(
+				graph.HTTPClient(graph.NoTimeout()),
+				"tenant",
+				"user",
+				OneDriveSource,
+				testFolderMatcher{(&selectors.OneDriveBackup{}).Folders(selectors.Any())[0]},
+				&MockGraphService{},
+				nil,
+				control.Options{ToggleFeatures: test.toggles})
+			c.prevContent = map[string]map[string]itemContent{"driveID1": prev}
+
+			err := c.UpdateCollections(
+				ctx,
+				"driveID1",
+				"General",
+				items,
+				map[string]string{},
+				map[string]string{},
+				map[string]struct{}{},
+				map[string]string{},
+				test.invalidPrevDelta)
+			require.NoError(t, err)
+
+			require.Contains(t, c.CollectionMap, "root")
+			col := c.CollectionMap["root"].(*Collection)
+
+			assert.Equal(t, test.expectUnchanged, col.unchangedContent)
+			assert.Equal(t, len(test.expectUnchanged), c.NumUnchangedContent)
+			assert.Equal(
+				t,
+				map[string]itemContent{
+					"same":    {Hash: "quickXor:abc", Name: "same", ParentID: "root"},
+					"edited":  {Hash: "quickXor:def", Name: "edited", ParentID: "root"},
+					"renamed": {Hash: "quickXor:abc", Name: "new-name", ParentID: "root"},
+				},
+				c.content["driveID1"],
+				"unhashed files aren't recorded")
+		})
+	}
+}
+
+func (suite *OneDriveCollectionsSuite) TestUpdateCollections_itemObserver() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		rootPath = fmt.Sprintf(rootDrivePattern, "driveID1")
+		observed = []string{}
+		items    = []models.DriveItemable{
+			driveRootItem("root"),
+			driveItem("kept", "kept", rootPath, "root", true, false, false),
+			driveItem("filtered", "filtered", rootPath, "root", true, false, false),
+		}
+	)
+
+	c := NewCollections(
+		graph.HTTPClient(graph.NoTimeout()),
+		"tenant",
+		"user",
+		OneDriveSource,
+		testFolderMatcher{(&selectors.OneDriveBackup{}).Folders(selectors.Any())[0]},
+		&MockGraphService{},
+		nil,
+		control.Options{})
+
+	c.ItemFilter = func(item models.DriveItemable) bool {
+		return ptr.Val(item.GetId()) != "filtered"
+	}
+
+	c.ItemObserver = func(_ context.Context, driveID string, item models.DriveItemable) {
+		observed = append(observed, driveID+"/"+ptr.Val(item.GetId()))
+	}
+
+	err := c.UpdateCollections(
+		ctx,
+		"driveID1",
+		"General",
+		items,
+		map[string]string{},
+		map[string]string{},
+		map[string]struct{}{},
+		map[string]string{},
+		false)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]string{"driveID1/root", "driveID1/kept", "driveID1/filtered"},
+		observed,
+		"observers see every enumerated item, including filtered ones")
+}
+
 type mockDeltaPageLinker struct {
 	link  *string
 	delta *string
@@ -1717,6 +1906,46 @@ func (suite *OneDriveCollectionsSuite) TestGet() {
 			expectedDelList: map[string]struct{}{},
 			doNotMergeItems: true,
 		},
+		{
+			name:   "OneDrive_ReprovisionedDrive",
+			drives: []models.Driveable{drive2},
+			items: map[string][]deltaPagerResult{
+				driveID2: {
+					{
+						items: []models.DriveItemable{
+							driveRootItem("root2"),
+							driveItem("folder2", "folder", driveBasePath2, "root2", false, true, false),
+							driveItem("file", "file", driveBasePath2+"/folder", "folder2", true, false, false),
+						},
+						deltaLink: &delta,
+					},
+				},
+			},
+			errCheck: assert.NoError,
+			prevFolderPaths: map[string]map[string]string{
+				driveID1: {
+					"root":   rootFolderPath1,
+					"folder": folderPath1,
+					"gone":   expectedPath1("/gone"),
+				},
+			},
+			expectedCollections: map[string]map[data.CollectionState][]string{
+				folderPath2:            {data.MovedState: {"folder2", "file"}},
+				rootFolderPath1:        {data.DeletedState: {}},
+				expectedPath1("/gone"): {data.DeletedState: {}},
+			},
+			expectedDeltaURLs: map[string]string{
+				driveID2: delta,
+			},
+			expectedFolderPaths: map[string]map[string]string{
+				driveID2: {
+					"root2":   rootFolderPath2,
+					"folder2": folderPath2,
+				},
+			},
+			expectedDelList: map[string]struct{}{},
+			doNotMergeItems: true,
+		},
 	}
 	for _, test := range table {
 		suite.Run(test.name, func() {
@@ -1743,6 +1972,7 @@ func (suite *OneDriveCollectionsSuite) TestGet() {
 			itemPagerFunc := func(
 				servicer graph.Servicer,
 				driveID, link string,
+				pageSize int,
 			) itemPager {
 				return &mockItemPager{
 					toReturn: test.items[driveID],
@@ -1757,7 +1987,7 @@ func (suite *OneDriveCollectionsSuite) TestGet() {
 				testFolderMatcher{anyFolder},
 				&MockGraphService{},
 				func(*support.ConnectorOperationStatus) {},
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}},
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}},
 			)
 			c.drivePagerFunc = drivePagerFunc
 			c.itemPagerFunc = itemPagerFunc
@@ -1803,7 +2033,7 @@ func (suite *OneDriveCollectionsSuite) TestGet() {
 				}
 
 				if folderPath == metadataPath.String() {
-					deltas, paths, err := deserializeMetadata(ctx, []data.RestoreCollection{
+					deltas, paths, _, err := deserializeMetadata(ctx, []data.RestoreCollection{
 						data.NotFoundRestoreCollection{Collection: baseCol},
 					})
 					if !assert.NoError(t, err, "deserializing metadata") {
@@ -1857,6 +2087,152 @@ func (suite *OneDriveCollectionsSuite) TestGet() {
 	}
 }
 
+func (suite *OneDriveCollectionsSuite) TestGet_unchangedContentIsNotExcluded() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t         = suite.T()
+		tenant    = "a-tenant"
+		user      = "a-user"
+		delta     = "delta1"
+		hash      = "abc"
+		driveID   = uuid.NewString()
+		drive     = models.NewDrive()
+		basePath  = fmt.Sprintf(rootDrivePattern, driveID)
+		rootPath  = getExpectedPathGenerator(t, tenant, user, basePath)("")
+		anyFolder = (&selectors.OneDriveBackup{}).Folders(selectors.Any())[0]
+	)
+
+	drive.SetId(&driveID)
+	drive.SetName(&driveID)
+
+	file := driveItem("file", "file", basePath, "root", true, false, false)
+	hs := models.NewHashes()
+	hs.SetQuickXorHash(&hash)
+	file.GetFile().SetHashes(hs)
+
+	c := NewCollections(
+		graph.HTTPClient(graph.NoTimeout()),
+		tenant,
+		user,
+		OneDriveSource,
+		testFolderMatcher{anyFolder},
+		&MockGraphService{},
+		func(*support.ConnectorOperationStatus) {},
+		control.Options{})
+	c.drivePagerFunc = func(driveSource, graph.Servicer, string, []string) (drivePager, error) {
+		return &mockDrivePager{toReturn: []pagerResult{{drives: []models.Driveable{drive}}}}, nil
+	}
+	c.itemPagerFunc = func(graph.Servicer, string, string, int) itemPager {
+		return &mockItemPager{
+			toReturn: []deltaPagerResult{{
+				items:     []models.DriveItemable{driveRootItem("root"), file},
+				deltaLink: &delta,
+			}},
+		}
+	}
+
+	mc, err := graph.MakeMetadataCollection(
+		tenant,
+		user,
+		path.OneDriveService,
+		path.FilesCategory,
+		[]graph.MetadataCollectionEntry{
+			graph.NewMetadataEntry(graph.DeltaURLsFileName, map[string]string{driveID: "prev-delta"}),
+			graph.NewMetadataEntry(
+				graph.PreviousPathFileName,
+				map[string]map[string]string{driveID: {"root": rootPath}}),
+			graph.NewMetadataEntry(
+				graph.ItemContentFileName,
+				map[string]map[string]itemContent{
+					driveID: {"file": {Hash: "quickXor:" + hash, Name: "file", ParentID: "root"}},
+				}),
+		},
+		func(*support.ConnectorOperationStatus) {},
+	)
+	require.NoError(t, err, "creating metadata collection")
+
+	_, delList, err := c.Get(ctx, []data.RestoreCollection{data.NotFoundRestoreCollection{Collection: mc}})
+	require.NoError(t, err)
+
+	// the file's content is merged from the base backup, so only its
+	// metadata gets replaced.
+	assert.Equal(t, map[string]struct{}{"file" + MetaFileSuffix: {}}, delList)
+}
+
+func (suite *OneDriveCollectionsSuite) TestGet_itemFilterDoesNotMergeBase() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t         = suite.T()
+		tenant    = "a-tenant"
+		user      = "a-user"
+		delta     = "delta1"
+		driveID   = uuid.NewString()
+		drive     = models.NewDrive()
+		basePath  = fmt.Sprintf(rootDrivePattern, driveID)
+		rootPath  = getExpectedPathGenerator(t, tenant, user, basePath)("")
+		anyFolder = (&selectors.OneDriveBackup{}).Folders(selectors.Any())[0]
+	)
+
+	drive.SetId(&driveID)
+	drive.SetName(&driveID)
+
+	c := NewCollections(
+		graph.HTTPClient(graph.NoTimeout()),
+		tenant,
+		user,
+		OneDriveSource,
+		testFolderMatcher{anyFolder},
+		&MockGraphService{},
+		func(*support.ConnectorOperationStatus) {},
+		control.Options{})
+	c.ItemFilter = func(models.DriveItemable) bool { return true }
+	c.drivePagerFunc = func(driveSource, graph.Servicer, string, []string) (drivePager, error) {
+		return &mockDrivePager{toReturn: []pagerResult{{drives: []models.Driveable{drive}}}}, nil
+	}
+	c.itemPagerFunc = func(graph.Servicer, string, string, int) itemPager {
+		return &mockItemPager{
+			toReturn: []deltaPagerResult{{
+				items: []models.DriveItemable{
+					driveRootItem("root"),
+					driveItem("file", "file", basePath, "root", true, false, false),
+				},
+				deltaLink: &delta,
+			}},
+		}
+	}
+
+	mc, err := graph.MakeMetadataCollection(
+		tenant,
+		user,
+		path.OneDriveService,
+		path.FilesCategory,
+		[]graph.MetadataCollectionEntry{
+			graph.NewMetadataEntry(graph.DeltaURLsFileName, map[string]string{driveID: "prev-delta"}),
+			graph.NewMetadataEntry(
+				graph.PreviousPathFileName,
+				map[string]map[string]string{driveID: {"root": rootPath}}),
+		},
+		func(*support.ConnectorOperationStatus) {},
+	)
+	require.NoError(t, err, "creating metadata collection")
+
+	cols, delList, err := c.Get(ctx, []data.RestoreCollection{data.NotFoundRestoreCollection{Collection: mc}})
+	require.NoError(t, err)
+	assert.Empty(t, delList)
+
+	for _, col := range cols {
+		if col.FullPath().Service() == path.OneDriveMetadataService {
+			continue
+		}
+
+		assert.True(t, col.DoNotMergeItems(), "filtered collections don't merge the base: "+col.FullPath().String())
+	}
+}
+
 func driveItem(
 	id string,
 	name string,
@@ -2036,3 +2412,196 @@ func (suite *OneDriveCollectionsSuite) TestCollectItems() {
 		})
 	}
 }
+
+func (suite *OneDriveCollectionsSuite) TestCreatedByFilter() {
+	createdBy := func(email string) models.DriveItemable {
+		item := driveItem("id", "name", "/drive/root:", "root", true, false, false)
+
+		u := models.NewIdentity()
+		u.SetAdditionalData(map[string]any{"email": &email})
+
+		is := models.NewIdentitySet()
+		is.SetUser(u)
+		item.SetCreatedBy(is)
+
+		return item
+	}
+
+	noCreator := driveItem("id", "name", "/drive/root:", "root", true, false, false)
+
+	sel := selectors.NewOneDriveBackup([]string{"user"})
+	sel.Include(sel.AllData())
+
+	t := suite.T()
+
+	assert.Nil(t, itemFilter(sel.FilterScopes()), "no created-by filters")
+
+	sel.Filter(sel.CreatedBy("alice@"))
+
+	filter := itemFilter(sel.FilterScopes())
+	require.NotNil(t, filter)
+
+	assert.True(t, filter(createdBy("alice@contoso.com")))
+	assert.False(t, filter(createdBy("bob@contoso.com")))
+	assert.False(t, filter(noCreator))
+}
+
+func (suite *OneDriveCollectionsSuite) TestItemExclusion() {
+	named := func(name string) models.DriveItemable {
+		return driveItem("id", name, "/drive/root:", "root", true, false, false)
+	}
+
+	var (
+		t       = suite.T()
+		sel     = selectors.NewOneDriveBackup([]string{"user"})
+		lock    = named("~$report.docx")
+		report  = named("report.docx")
+		copied  = named("report-DESKTOP-1A2B3C4.docx")
+		merge   = named("merge-conflict-notes.md")
+		scratch = named("scratch.tmp")
+	)
+
+	sel.Include(sel.AllData())
+	assert.Nil(t, itemExclusion(sel.ExcludeScopes()), "no name exclusions")
+
+	sel.Exclude(sel.SyncArtifacts())
+
+	exclude := itemExclusion(sel.ExcludeScopes())
+	require.NotNil(t, exclude)
+
+	assert.True(t, exclude(lock))
+	assert.True(t, exclude(copied))
+	assert.True(t, exclude(scratch))
+	assert.False(t, exclude(report))
+	assert.False(t, exclude(merge))
+}
+
+func (suite *OneDriveCollectionsSuite) TestScopedItemFilter() {
+	var (
+		t     = suite.T()
+		all   = selectors.NewOneDriveBackup([]string{"user"})
+		sel   = selectors.NewOneDriveBackup([]string{"user"})
+		item  = driveItem("item", "name", "/drive/root:", "root", true, false, false)
+		other = driveItem("other", "name", "/drive/root:", "root", true, false, false)
+	)
+
+	all.Include(all.AllData())
+	assert.Nil(t, scopedItemFilter(all.Scopes()[0], nil), "all items")
+
+	sel.Include(sel.Items([]string{"folder"}, []string{"item"}))
+
+	filter := scopedItemFilter(sel.Scopes()[0], nil)
+	require.NotNil(t, filter)
+	assert.True(t, filter(item))
+	assert.False(t, filter(other))
+
+	filter = scopedItemFilter(sel.Scopes()[0], func(models.DriveItemable) bool { return false })
+	require.NotNil(t, filter)
+	assert.False(t, filter(item), "scoped items still pass through the filter")
+}
+
+func (suite *OneDriveCollectionsSuite) TestContentFilters() {
+	labeled := func(mimeType, label string) models.DriveItemable {
+		item := driveItem("id", "name", "/drive/root:", "root", true, false, false)
+
+		f := models.NewFile()
+		f.SetMimeType(&mimeType)
+		item.SetFile(f)
+
+		if len(label) > 0 {
+			node, err := kjson.NewJsonParseNode([]byte(`{"displayName":"` + label + `"}`))
+			require.NoError(suite.T(), err)
+
+			v, err := node.GetRawValue()
+			require.NoError(suite.T(), err)
+
+			item.SetAdditionalData(map[string]any{sensitivityLabelKey: v})
+		}
+
+		return item
+	}
+
+	t := suite.T()
+
+	require.Contains(t, deltaItemFields, sensitivityLabelKey, "delta enumeration selects the label")
+
+	// the label as graph returns it in a delta page.
+	node, err := kjson.NewJsonParseNode([]byte(`{
+		"@odata.deltaLink": "https://delta",
+		"value": [{
+			"id": "file",
+			"name": "file.docx",
+			"file": {"mimeType": "application/msword"},
+			"sensitivityLabel": {"labelId": "label-id", "displayName": "Confidential"}
+		}]
+	}`))
+	require.NoError(t, err)
+
+	values, err := node.GetChildNode("value")
+	require.NoError(t, err)
+
+	parsed, err := values.GetCollectionOfObjectValues(models.CreateDriveItemFromDiscriminatorValue)
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+	assert.Equal(t, "Confidential", SensitivityLabel(parsed[0].(models.DriveItemable)))
+
+	// the raw delta pager reduces the label to its name.
+	rawLabel := "Confidential"
+	raw := driveItem("id", "name", "/drive/root:", "root", true, false, false)
+	raw.SetAdditionalData(map[string]any{sensitivityLabelKey: &rawLabel})
+	assert.Equal(t, "Confidential", SensitivityLabel(raw))
+
+	pdf := labeled("application/pdf", "Confidential")
+	assert.Equal(t, "application/pdf", ContentType(pdf))
+	assert.Equal(t, "Confidential", SensitivityLabel(pdf))
+
+	unlabeled := labeled("text/plain", "")
+	assert.Empty(t, SensitivityLabel(unlabeled))
+
+	table := []struct {
+		name   string
+		scopes func(*selectors.OneDriveBackup) []selectors.OneDriveScope
+		pdf    assert.BoolAssertionFunc
+		text   assert.BoolAssertionFunc
+	}{
+		{
+			name: "content type",
+			scopes: func(sel *selectors.OneDriveBackup) []selectors.OneDriveScope {
+				return sel.ContentType("pdf")
+			},
+			pdf:  assert.True,
+			text: assert.False,
+		},
+		{
+			name: "sensitivity label",
+			scopes: func(sel *selectors.OneDriveBackup) []selectors.OneDriveScope {
+				return sel.SensitivityLabel("confidential")
+			},
+			pdf:  assert.True,
+			text: assert.False,
+		},
+		{
+			name: "other sensitivity label",
+			scopes: func(sel *selectors.OneDriveBackup) []selectors.OneDriveScope {
+				return sel.SensitivityLabel("Highly Confidential")
+			},
+			pdf:  assert.False,
+			text: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			sel := selectors.NewOneDriveBackup([]string{"user"})
+			sel.Include(sel.AllData())
+			sel.Filter(test.scopes(sel))
+
+			filter := itemFilter(sel.FilterScopes())
+			require.NotNil(t, filter)
+
+			test.pdf(t, filter(pdf))
+			test.text(t, filter(unlabeled))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package onedrive
+
+import (
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+)
+
+// itemContent identifies the content of a file as it was backed up, along
+// with where it was backed up.  Files that reappear in a later drive delta
+// with the same content, name, and parent only changed their metadata (ex:
+// their permissions), so their content can be sourced from the base backup
+// instead of getting downloaded again.
+type itemContent struct {
+	Hash     string `json:"hash"`
+	Name     string `json:"name"`
+	ParentID string `json:"parentId"`
+}
+
+// contentHash produces the hash that graph reports for the content of the
+// file, prefixed by the type of hash.  OneDrive for Business and SharePoint
+// only report quickXorHash, while personal drives may only report sha1 or
+// sha256 hashes.  Returns an empty string if graph reports no hash.
+func contentHash(item models.DriveItemable) string {
+	if item.GetFile() == nil || item.GetFile().GetHashes() == nil {
+		return ""
+	}
+
+	hs := item.GetFile().GetHashes()
+
+	switch {
+	case len(ptr.Val(hs.GetQuickXorHash())) > 0:
+		return "quickXor:" + ptr.Val(hs.GetQuickXorHash())
+	case len(ptr.Val(hs.GetSha256Hash())) > 0:
+		return "sha256:" + ptr.Val(hs.GetSha256Hash())
+	case len(ptr.Val(hs.GetSha1Hash())) > 0:
+		return "sha1:" + ptr.Val(hs.GetSha1Hash())
+	}
+
+	return ""
+}
+
+// newItemContent records the content of the file.  Returns false if graph
+// reported no hash for the file, since its content can't be compared.
+func newItemContent(item models.DriveItemable) (itemContent, bool) {
+	hash := contentHash(item)
+	if len(hash) == 0 || item.GetParentReference() == nil {
+		return itemContent{}, false
+	}
+
+	return itemContent{
+		Hash:     hash,
+		Name:     ptr.Val(item.GetName()),
+		ParentID: ptr.Val(item.GetParentReference().GetId()),
+	}, true
+}
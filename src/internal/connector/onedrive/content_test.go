@@ -0,0 +1,89 @@
+package onedrive
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ContentUnitSuite struct {
+	tester.Suite
+}
+
+func TestContentUnitSuite(t *testing.T) {
+	suite.Run(t, &ContentUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ContentUnitSuite) TestContentHash() {
+	str := func(s string) *string { return &s }
+
+	table := []struct {
+		name   string
+		hashes func() models.Hashesable
+		expect string
+	}{
+		{
+			name:   "no hashes",
+			hashes: func() models.Hashesable { return nil },
+		},
+		{
+			name:   "empty hashes",
+			hashes: func() models.Hashesable { return models.NewHashes() },
+		},
+		{
+			name: "quickXor preferred",
+			hashes: func() models.Hashesable {
+				hs := models.NewHashes()
+				hs.SetQuickXorHash(str("qx"))
+				hs.SetSha1Hash(str("s1"))
+
+				return hs
+			},
+			expect: "quickXor:qx",
+		},
+		{
+			name: "sha256 over sha1",
+			hashes: func() models.Hashesable {
+				hs := models.NewHashes()
+				hs.SetSha256Hash(str("s256"))
+				hs.SetSha1Hash(str("s1"))
+
+				return hs
+			},
+			expect: "sha256:s256",
+		},
+		{
+			name: "sha1",
+			hashes: func() models.Hashesable {
+				hs := models.NewHashes()
+				hs.SetSha1Hash(str("s1"))
+
+				return hs
+			},
+			expect: "sha1:s1",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			item := driveItem("id", "name", "/drive/root:", "root", true, false, false)
+			item.GetFile().SetHashes(test.hashes())
+
+			assert.Equal(t, test.expect, contentHash(item))
+
+			ic, ok := newItemContent(item)
+			assert.Equal(t, len(test.expect) > 0, ok)
+
+			if ok {
+				assert.Equal(t, itemContent{Hash: test.expect, Name: "name", ParentID: "root"}, ic)
+			}
+		})
+	}
+
+	assert.Empty(suite.T(), contentHash(driveItem("id", "name", "/drive/root:", "root", false, true, false)), "folder")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"golang.org/x/exp/maps"
 )
 
@@ -30,6 +31,69 @@ func (fm odFolderMatcher) Matches(dir string) bool {
 	return fm.scope.Matches(selectors.OneDriveFolder, dir)
 }
 
+// itemFilter produces an item filter that matches the item's creator,
+// content type, and sensitivity label against the selector's filter scopes.
+// Returns nil if the selector has no such scopes.
+func itemFilter(filters []selectors.OneDriveScope) func(models.DriveItemable) bool {
+	ms := []ItemMatcher{}
+
+	for _, f := range filters {
+		f := f
+
+		var prop ItemProperty
+
+		switch f.FilterCategory() {
+		case selectors.FileFilterCreatedBy:
+			prop = ItemCreatedBy
+		case selectors.FileFilterContentType:
+			prop = ItemContentType
+		case selectors.FileFilterSensitivityLabel:
+			prop = ItemSensitivityLabel
+		default:
+			continue
+		}
+
+		ms = append(ms, ItemMatcher{
+			Property: prop,
+			Matches:  func(v string) bool { return f.Matches(f.FilterCategory(), v) },
+		})
+	}
+
+	return ItemFilter(ms)
+}
+
+// scopedItemFilter restricts the filter to the items selected by the
+// scope, for scopes that select specific items.
+func scopedItemFilter(
+	scope selectors.OneDriveScope,
+	filter func(models.DriveItemable) bool,
+) func(models.DriveItemable) bool {
+	if scope.IsAny(selectors.OneDriveItem) {
+		return filter
+	}
+
+	return ScopedItemFilter(
+		func(id string) bool { return scope.Matches(selectors.OneDriveItem, id) },
+		filter)
+}
+
+// itemExclusion produces an item exclusion that matches the item's name
+// against the selector's name exclusion scopes, such as its SyncArtifacts.
+// Returns nil if the selector has no such scopes.
+func itemExclusion(excludes []selectors.OneDriveScope) func(models.DriveItemable) bool {
+	ms := []func(string) bool{}
+
+	for _, e := range excludes {
+		e := e
+
+		if e.FilterCategory() == selectors.FileFilterItemName {
+			ms = append(ms, func(name string) bool { return e.Matches(selectors.FileFilterItemName, name) })
+		}
+	}
+
+	return ItemNameExclusion(ms)
+}
+
 // OneDriveDataCollections returns a set of DataCollection which represents the OneDrive data
 // for the specified user
 func DataCollections(
@@ -55,9 +119,9 @@ func DataCollections(
 
 	// for each scope that includes oneDrive items, get all
 	for _, scope := range odb.Scopes() {
-		logger.Ctx(ctx).With("user", user).Debug("Creating OneDrive collections")
+		logger.Ctx(ctx).With("user", logger.PII(user)).Debug("Creating OneDrive collections")
 
-		odcs, excludes, err := NewCollections(
+		colls := NewCollections(
 			itemClient,
 			tenant,
 			user,
@@ -65,8 +129,11 @@ func DataCollections(
 			odFolderMatcher{scope},
 			service,
 			su,
-			ctrlOpts,
-		).Get(ctx, metadata)
+			ctrlOpts)
+		colls.ItemFilter = scopedItemFilter(scope, itemFilter(odb.FilterScopes()))
+		colls.ItemExclusion = itemExclusion(odb.ExcludeScopes())
+
+		odcs, excludes, err := colls.Get(ctx, metadata)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	msdrive "github.com/microsoftgraph/msgraph-sdk-go/drive"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -12,7 +11,6 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
 
-	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	gapi "github.com/alcionai/corso/src/internal/connector/graph/api"
 	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
@@ -50,9 +48,7 @@ type DeltaUpdate struct {
 }
 
 type drivePager interface {
-	GetPage(context.Context) (gapi.PageLinker, error)
-	SetNext(nextLink string)
-	ValuesIn(gapi.PageLinker) ([]models.Driveable, error)
+	graph.Pager[models.Driveable]
 }
 
 func PagerForSource(
@@ -76,63 +72,35 @@ func drives(
 	pager drivePager,
 	retry bool,
 ) ([]models.Driveable, error) {
-	var (
-		err             error
-		page            gapi.PageLinker
-		numberOfRetries = getDrivesRetries
-		drives          = []models.Driveable{}
-	)
+	opts := graph.DefaultPagerOptions()
+	opts.MaxRetries = getDrivesRetries
+	// Request can timeout.  Timeouts are sometimes only identifiable
+	// through the odata error details.
+	opts.IsRetriable = func(err error) bool {
+		return graph.IsErrTimeout(err) ||
+			strings.Contains(detailedDriveError(err), contextDeadlineExceeded)
+	}
 
 	if !retry {
-		numberOfRetries = 0
+		opts.MaxRetries = 0
 	}
 
-	// Loop through all pages returned by Graph API.
-	for {
-		// Retry Loop for Drive retrieval. Request can timeout
-		for i := 0; i <= numberOfRetries; i++ {
-			page, err = pager.GetPage(ctx)
-			if err != nil {
-				// Various error handling. May return an error or perform a retry.
-				detailedError := support.ConnectorStackErrorTraceWrap(err, "").Error()
-				if strings.Contains(detailedError, userMysiteURLNotFound) ||
-					strings.Contains(detailedError, userMysiteURLNotFoundMsg) ||
-					strings.Contains(detailedError, userMysiteNotFound) ||
-					strings.Contains(detailedError, userMysiteNotFoundMsg) {
-					logger.Ctx(ctx).Infof("resource owner does not have a drive")
-					return make([]models.Driveable, 0), nil // no license or drives.
-				}
-
-				if strings.Contains(detailedError, contextDeadlineExceeded) && i < numberOfRetries {
-					time.Sleep(time.Duration(3*(i+1)) * time.Second)
-					continue
-				}
-
-				return nil, errors.Wrapf(
-					err,
-					"failed to retrieve drives. details: %s",
-					detailedError,
-				)
-			}
-
-			// No error encountered, break the retry loop so we can extract results
-			// and see if there's another page to fetch.
-			break
-		}
-
-		tmp, err := pager.ValuesIn(page)
-		if err != nil {
-			return nil, errors.Wrap(err, "extracting drives from response")
-		}
-
-		drives = append(drives, tmp...)
-
-		nextLink := ptr.Val(page.GetOdataNextLink())
-		if len(nextLink) == 0 {
-			break
+	drives, err := graph.GetAllValues[models.Driveable](ctx, pager, opts)
+	if err != nil {
+		detailedError := detailedDriveError(err)
+		if strings.Contains(detailedError, userMysiteURLNotFound) ||
+			strings.Contains(detailedError, userMysiteURLNotFoundMsg) ||
+			strings.Contains(detailedError, userMysiteNotFound) ||
+			strings.Contains(detailedError, userMysiteNotFoundMsg) {
+			logger.Ctx(ctx).Infof("resource owner does not have a drive")
+			return make([]models.Driveable, 0), nil // no license or drives.
 		}
 
-		pager.SetNext(nextLink)
+		return nil, errors.Wrapf(
+			err,
+			"failed to retrieve drives. details: %s",
+			detailedError,
+		)
 	}
 
 	logger.Ctx(ctx).Debugf("Found %d drives", len(drives))
@@ -140,6 +108,19 @@ func drives(
 	return drives, nil
 }
 
+// detailedDriveError produces the error message along with any odata
+// error details found within the error chain.
+func detailedDriveError(err error) string {
+	detailedError := support.ConnectorStackErrorTraceWrap(err, "").Error()
+
+	var oDataError *odataerrors.ODataError
+	if errors.As(err, &oDataError) {
+		detailedError += " " + support.ConnectorStackErrorTrace(oDataError)
+	}
+
+	return detailedError
+}
+
 // itemCollector functions collect the items found in a drive
 type itemCollector func(
 	ctx context.Context,
@@ -159,31 +140,91 @@ type itemPager interface {
 	ValuesIn(gapi.DeltaPageLinker) ([]models.DriveItemable, error)
 }
 
+// deltaItemFields are the drive item properties selected when enumerating
+// the drive delta.
+var deltaItemFields = []string{
+	"content.downloadUrl",
+	"createdBy",
+	"createdDateTime",
+	"file",
+	"folder",
+	"id",
+	"lastModifiedDateTime",
+	"name",
+	"package",
+	"parentReference",
+	"root",
+	"sensitivityLabel",
+	"sharepointIds",
+	"shared",
+	"size",
+	"deleted",
+}
+
 func defaultItemPager(
 	servicer graph.Servicer,
 	driveID, link string,
+	pageSize int,
 ) itemPager {
-	return api.NewItemPager(
-		servicer,
-		driveID,
-		link,
-		[]string{
-			"content.downloadUrl",
-			"createdBy",
-			"createdDateTime",
-			"file",
-			"folder",
-			"id",
-			"lastModifiedDateTime",
-			"name",
-			"package",
-			"parentReference",
-			"root",
-			"sharepointIds",
-			"size",
-			"deleted",
-		},
-	)
+	return api.NewItemPager(servicer, driveID, link, deltaItemFields, pageSize)
+}
+
+// rawItemPager enumerates the drive delta without parsing the pages
+// through the graph sdk.  Cheaper for drives with millions of items.
+func rawItemPager(
+	servicer graph.Servicer,
+	driveID, link string,
+	pageSize int,
+) itemPager {
+	return api.NewRawItemPager(servicer, driveID, link, deltaItemFields, pageSize)
+}
+
+// cachedItemPager produces raw item pagers that reuse the delta pages held
+// in the cache, so that a retried backup doesn't enumerate the drive again.
+func cachedItemPager(
+	cache *api.DeltaCache,
+) func(graph.Servicer, string, string, int) itemPager {
+	return func(servicer graph.Servicer, driveID, link string, pageSize int) itemPager {
+		return api.NewCachedItemPager(servicer, driveID, link, deltaItemFields, pageSize, cache)
+	}
+}
+
+// expiringItemPager wraps the pagers produced by pf so that every previous
+// delta token they're pointed at is treated as expired.
+func expiringItemPager(
+	pf func(graph.Servicer, string, string, int) itemPager,
+) func(graph.Servicer, string, string, int) itemPager {
+	return func(servicer graph.Servicer, driveID, link string, pageSize int) itemPager {
+		return &expiringDeltaPager{itemPager: pf(servicer, driveID, link, pageSize)}
+	}
+}
+
+// expiringDeltaPager fails its first page as if the delta token expired,
+// if it was pointed at a previous delta token before getting that page.
+// Enumeration proceeds as normal after the pager is reset.
+type expiringDeltaPager struct {
+	itemPager
+	started bool
+	resumed bool
+}
+
+func (p *expiringDeltaPager) SetNext(nextLink string) {
+	if !p.started {
+		p.resumed = true
+	}
+
+	p.itemPager.SetNext(nextLink)
+}
+
+func (p *expiringDeltaPager) GetPage(ctx context.Context) (gapi.DeltaPageLinker, error) {
+	p.started = true
+
+	if p.resumed {
+		p.resumed = false
+		return nil, graph.SimulatedDeltaExpiry()
+	}
+
+	return p.itemPager.GetPage(ctx)
 }
 
 // collectItems will enumerate all items in the specified drive and hand them to the
@@ -390,6 +431,7 @@ func GetAllFolders(
 				gs,
 				*d.GetId(),
 				"",
+				0,
 			),
 			*d.GetId(),
 			*d.GetName(),
@@ -453,7 +495,7 @@ func DeleteItem(
 	driveID string,
 	itemID string,
 ) error {
-	err := gs.Client().DrivesById(driveID).ItemsById(itemID).Delete(ctx, nil)
+	err := api.NewClientFromService(gs).Drives().DeleteItem(ctx, driveID, itemID)
 	if err != nil {
 		return errors.Wrapf(err, "deleting item with ID %s", itemID)
 	}
@@ -320,6 +320,75 @@ func (suite *OneDriveUnitSuite) TestDrives() {
 	}
 }
 
+func (suite *OneDriveUnitSuite) TestCollectItems_expiredDeltas() {
+	delta := "delta-link"
+
+	table := []struct {
+		name               string
+		expire             bool
+		prevDelta          string
+		expectInvalidDelta bool
+	}{
+		{
+			name:               "previous delta is expired",
+			expire:             true,
+			prevDelta:          "prev-delta",
+			expectInvalidDelta: true,
+		},
+		{
+			name:               "previous delta is not expired",
+			prevDelta:          "prev-delta",
+			expectInvalidDelta: false,
+		},
+		{
+			name:               "no previous delta",
+			expire:             true,
+			expectInvalidDelta: true,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			var (
+				pages = []deltaPagerResult{{deltaLink: &delta}}
+				pf    = func(graph.Servicer, string, string, int) itemPager {
+					return &mockItemPager{toReturn: pages}
+				}
+				collects []bool
+			)
+
+			if test.expire {
+				pf = expiringItemPager(pf)
+			}
+
+			collector := func(
+				_ context.Context,
+				_, _ string,
+				_ []models.DriveItemable,
+				_ map[string]string,
+				_ map[string]string,
+				_ map[string]struct{},
+				_ map[string]string,
+				invalidPrevDelta bool,
+			) error {
+				collects = append(collects, invalidPrevDelta)
+				return nil
+			}
+
+			du, _, _, err := collectItems(ctx, pf(nil, "drive", "", 0), "drive", "drive-name", collector, nil, test.prevDelta)
+			require.NoError(t, err)
+
+			assert.Equal(t, delta, du.URL)
+			assert.Equal(t, test.expectInvalidDelta, du.Reset, "delta reset")
+			assert.Equal(t, []bool{test.expectInvalidDelta}, collects, "items are collected once")
+		})
+	}
+}
+
 // Integration tests
 
 type OneDriveSuite struct {
@@ -471,7 +540,7 @@ func (suite *OneDriveSuite) TestOneDriveNewCollections() {
 				testFolderMatcher{scope},
 				service,
 				service.updateStatus,
-				control.Options{ToggleFeatures: control.Toggles{EnablePermissionsBackup: true}},
+				control.Options{Permissions: control.PermissionOptions{OneDrive: control.PermissionToggles{Backup: true}}},
 			).Get(ctx, nil)
 			assert.NoError(t, err)
 			// Don't expect excludes as this isn't an incremental backup.
@@ -4,18 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/alcionai/clues"
+	kjson "github.com/microsoft/kiota-serialization-json-go"
 	msdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	mssites "github.com/microsoftgraph/msgraph-sdk-go/sites"
 	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/connector/uploadsession"
 	"github.com/alcionai/corso/src/internal/version"
@@ -27,26 +31,21 @@ const (
 	// downloadUrlKey is used to find the download URL in a
 	// DriveItem response
 	downloadURLKey = "@microsoft.graph.downloadUrl"
+	// sensitivityLabelKey is used to find the sensitivity label
+	// in a DriveItem response
+	sensitivityLabelKey = "sensitivityLabel"
 )
 
-// generic drive item getter
-func getDriveItem(
-	ctx context.Context,
-	srv graph.Servicer,
-	driveID, itemID string,
-) (models.DriveItemable, error) {
-	return srv.Client().DrivesById(driveID).ItemsById(itemID).Get(ctx, nil)
-}
-
 // sharePointItemReader will return a io.ReadCloser for the specified item
 // It crafts this by querying M365 for a download URL for the item
 // and using a http client to initialize a reader
 // TODO: Add metadata fetching to SharePoint
 func sharePointItemReader(
+	ctx context.Context,
 	hc *http.Client,
 	item models.DriveItemable,
 ) (details.ItemInfo, io.ReadCloser, error) {
-	resp, err := downloadItem(hc, item)
+	resp, err := downloadItem(ctx, hc, item)
 	if err != nil {
 		return details.ItemInfo{}, nil, errors.Wrap(err, "downloading item")
 	}
@@ -67,6 +66,7 @@ func oneDriveItemMetaReader(
 ) (io.ReadCloser, int, error) {
 	meta := Metadata{
 		FileName: *item.GetName(),
+		ItemID:   ptr.Val(item.GetId()),
 	}
 
 	perms, err := oneDriveItemPermissionInfo(ctx, service, driveID, item, fetchPermissions)
@@ -77,6 +77,7 @@ func oneDriveItemMetaReader(
 		err = clues.Wrap(err, "fetching item permissions")
 	} else {
 		meta.Permissions = perms
+		meta.PermissionsCaptured = fetchPermissions
 	}
 
 	metaJSON, serializeErr := json.Marshal(meta)
@@ -99,10 +100,412 @@ func oneDriveItemMetaReader(
 	return r, len(metaJSON), err
 }
 
+// userInformationList is the hidden list of every site which holds the
+// people known to the site, keyed by their id within the site.
+const userInformationList = "User Information List"
+
+// sharePointFieldsReader reads the column values of library files.  The
+// person or group columns of each drive, and the login names of each
+// site's people, are cached for the life of the reader.
+type sharePointFieldsReader struct {
+	mu sync.Mutex
+	// personColumns holds the names of each drive's person or group
+	// columns, keyed by drive id.
+	personColumns map[string]map[string]struct{}
+	// logins holds the login names of each site's people, keyed by the
+	// site id and the person's id within the site.
+	logins map[string]string
+}
+
+func newSharePointFieldsReader() *sharePointFieldsReader {
+	return &sharePointFieldsReader{
+		personColumns: map[string]map[string]struct{}{},
+		logins:        map[string]string{},
+	}
+}
+
+// read returns a reader for the column values of the library file's list
+// item.
+func (r *sharePointFieldsReader) read(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID string,
+	item models.DriveItemable,
+	_ bool,
+) (io.ReadCloser, int, error) {
+	fields, err := r.fields(ctx, service, driveID, item)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, 0, clues.Wrap(err, "serializing item fields")
+	}
+
+	return io.NopCloser(bytes.NewReader(fieldsJSON)), len(fieldsJSON), nil
+}
+
+func (r *sharePointFieldsReader) fields(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID string,
+	di models.DriveItemable,
+) (ListItemFields, error) {
+	ctx = clues.Add(ctx, "item_id", ptr.Val(di.GetId()))
+
+	li, err := service.
+		Client().
+		DrivesById(driveID).
+		ItemsById(ptr.Val(di.GetId())).
+		ListItem().
+		Get(ctx, &msdrives.ItemItemsItemListItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &msdrives.ItemItemsItemListItemRequestBuilderGetQueryParameters{
+				Select: []string{"id", "sharepointIds"},
+				Expand: []string{"fields"},
+			},
+		})
+	if err != nil {
+		return nil, clues.Wrap(err, "fetching item fields").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	if li.GetFields() == nil {
+		return ListItemFields{}, nil
+	}
+
+	raw := li.GetFields().GetAdditionalData()
+	lif := filterListItemFields(raw)
+
+	lookups := lookupFields(raw)
+	if len(lookups) == 0 {
+		return lif, nil
+	}
+
+	var siteID string
+	if li.GetSharepointIds() != nil {
+		siteID = ptr.Val(li.GetSharepointIds().GetSiteId())
+	}
+
+	people, err := r.personFields(ctx, service, driveID, siteID, lookups)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range people {
+		lif[k] = v
+	}
+
+	return lif, nil
+}
+
+// personFields resolves the ids of the people held by the item's person or
+// group columns into their login names, which carry over to other sites.
+// Other lookup columns refer to the items of another list, whose ids don't
+// carry over, so they're left out of the backup.
+func (r *sharePointFieldsReader) personFields(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID, siteID string,
+	lookups map[string][]string,
+) (ListItemFields, error) {
+	cols, err := r.personColumnsOf(ctx, service, driveID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lif     = ListItemFields{}
+		skipped = []string{}
+	)
+
+	for col, ids := range lookups {
+		if _, ok := cols[col]; !ok {
+			skipped = append(skipped, col)
+			continue
+		}
+
+		logins := make([]string, 0, len(ids))
+
+		for _, id := range ids {
+			login, err := r.login(ctx, service, siteID, id)
+			if err != nil {
+				return nil, clues.Stack(err).With("column", col)
+			}
+
+			logins = append(logins, login)
+		}
+
+		lif[col] = sapi.PeopleValue(logins)
+	}
+
+	if len(skipped) > 0 {
+		logger.Ctx(ctx).Infow("skipping lookup columns, which refer to other lists", "columns", skipped)
+	}
+
+	return lif, nil
+}
+
+// personColumnsOf produces the names of the drive's person or group columns.
+func (r *sharePointFieldsReader) personColumnsOf(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID string,
+) (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cols, ok := r.personColumns[driveID]; ok {
+		return cols, nil
+	}
+
+	resp, err := service.Client().DrivesById(driveID).List().Columns().Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "fetching library columns").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	cols := map[string]struct{}{}
+
+	for _, cd := range resp.GetValue() {
+		if cd.GetPersonOrGroup() != nil {
+			cols[ptr.Val(cd.GetName())] = struct{}{}
+		}
+	}
+
+	r.personColumns[driveID] = cols
+
+	return cols, nil
+}
+
+// login produces the login name of the person with the id within the site.
+func (r *sharePointFieldsReader) login(
+	ctx context.Context,
+	service graph.Servicer,
+	siteID, id string,
+) (string, error) {
+	key := siteID + "/" + id
+
+	r.mu.Lock()
+	login, ok := r.logins[key]
+	r.mu.Unlock()
+
+	if ok {
+		return login, nil
+	}
+
+	li, err := service.
+		Client().
+		SitesById(siteID).
+		ListsById(userInformationList).
+		ItemsById(id).
+		Get(ctx, &mssites.ItemListsItemItemsListItemItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &mssites.ItemListsItemItemsListItemItemRequestBuilderGetQueryParameters{
+				Expand: []string{"fields"},
+			},
+		})
+	if err != nil {
+		return "", clues.Wrap(err, "fetching site user").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	if li.GetFields() != nil {
+		// Name holds the claims login of the person, which sharepoint
+		// accepts for any person column.
+		for _, k := range []string{"Name", "UserName", "EMail"} {
+			if v, ok := li.GetFields().GetAdditionalData()[k].(*string); ok && len(ptr.Val(v)) > 0 {
+				login = *v
+				break
+			}
+		}
+	}
+
+	if len(login) == 0 {
+		return "", clues.New("site user has no login name").WithClues(ctx)
+	}
+
+	r.mu.Lock()
+	r.logins[key] = login
+	r.mu.Unlock()
+
+	return login, nil
+}
+
+// lookupFields produces the ids referred to by each of the item's lookup
+// columns, keyed by column name.  Graph reports a single lookup as the
+// "<column>LookupId" field, and multiple lookups as a list of objects
+// holding a LookupId in the column's own field.
+func lookupFields(fields map[string]any) map[string][]string {
+	lookups := map[string][]string{}
+
+	for k, v := range fields {
+		if strings.HasSuffix(k, "LookupId") {
+			if ids := lookupIDs(v); len(ids) > 0 {
+				lookups[strings.TrimSuffix(k, "LookupId")] = ids
+			}
+
+			continue
+		}
+
+		nodes, ok := v.([]*kjson.JsonParseNode)
+		if !ok {
+			continue
+		}
+
+		ids := []string{}
+
+		for _, n := range nodes {
+			rv, _ := n.GetRawValue()
+
+			obj, ok := rv.(map[string]*kjson.JsonParseNode)
+			if !ok || obj["LookupId"] == nil {
+				continue
+			}
+
+			idv, _ := obj["LookupId"].GetRawValue()
+			ids = append(ids, lookupIDs(idv)...)
+		}
+
+		if len(ids) > 0 {
+			lookups[k] = ids
+		}
+	}
+
+	return lookups
+}
+
+func lookupIDs(v any) []string {
+	switch tv := v.(type) {
+	case *string:
+		if len(ptr.Val(tv)) > 0 {
+			return []string{*tv}
+		}
+	case *int64:
+		if tv != nil {
+			return []string{strconv.FormatInt(*tv, 10)}
+		}
+	case *int32:
+		if tv != nil {
+			return []string{strconv.FormatInt(int64(*tv), 10)}
+		}
+	case *float64:
+		if tv != nil {
+			return []string{strconv.FormatFloat(*tv, 'f', -1, 64)}
+		}
+	case []*kjson.JsonParseNode:
+		ids := []string{}
+
+		for _, n := range tv {
+			rv, _ := n.GetRawValue()
+			ids = append(ids, lookupIDs(rv)...)
+		}
+
+		return ids
+	}
+
+	return nil
+}
+
+// systemFields are the columns maintained by SharePoint, which can't be
+// set on restore.
+var systemFields = map[string]struct{}{
+	"id":                 {},
+	"Attachments":        {},
+	"ContentType":        {},
+	"Created":            {},
+	"DocIcon":            {},
+	"Edit":               {},
+	"FileLeafRef":        {},
+	"FileSizeDisplay":    {},
+	"FolderChildCount":   {},
+	"ItemChildCount":     {},
+	"LinkFilename":       {},
+	"LinkFilenameNoMenu": {},
+	"Modified":           {},
+}
+
+// filterListItemFields keeps the user-defined columns holding plain values,
+// including columns holding multiple plain values, such as multiple choice
+// columns.  Lookup and person columns hold ids which don't carry over to
+// other sites, and are produced separately by personFields.
+func filterListItemFields(fields map[string]any) ListItemFields {
+	lif := ListItemFields{}
+
+	for k, v := range fields {
+		if _, ok := systemFields[k]; ok ||
+			strings.HasPrefix(k, "_") ||
+			strings.HasPrefix(k, "@") ||
+			strings.HasPrefix(k, "MediaService") ||
+			strings.HasSuffix(k, "LookupId") {
+			continue
+		}
+
+		if pv, ok := plainValue(v); ok {
+			lif[k] = pv
+			continue
+		}
+
+		nodes, ok := v.([]*kjson.JsonParseNode)
+		if !ok || len(nodes) == 0 {
+			continue
+		}
+
+		// multiple values are only kept if every one is a plain value;
+		// lists of objects are multiple lookups.
+		vs := make([]any, 0, len(nodes))
+
+		for _, n := range nodes {
+			rv, _ := n.GetRawValue()
+
+			pv, ok := plainValue(rv)
+			if !ok {
+				vs = nil
+				break
+			}
+
+			vs = append(vs, pv)
+		}
+
+		if len(vs) > 0 {
+			lif[k] = vs
+		}
+	}
+
+	return lif
+}
+
+// plainValue dereferences a string, bool, or numeric field value.
+func plainValue(v any) (any, bool) {
+	switch tv := v.(type) {
+	case *string:
+		if tv != nil {
+			return *tv, true
+		}
+	case *bool:
+		if tv != nil {
+			return *tv, true
+		}
+	case *float64:
+		if tv != nil {
+			return *tv, true
+		}
+	case *int32:
+		if tv != nil {
+			return float64(*tv), true
+		}
+	case *int64:
+		if tv != nil {
+			return float64(*tv), true
+		}
+	case string, bool, float64:
+		return tv, true
+	}
+
+	return nil, false
+}
+
 // oneDriveItemReader will return a io.ReadCloser for the specified item
 // It crafts this by querying M365 for a download URL for the item
 // and using a http client to initialize a reader
 func oneDriveItemReader(
+	ctx context.Context,
 	hc *http.Client,
 	item models.DriveItemable,
 ) (details.ItemInfo, io.ReadCloser, error) {
@@ -112,7 +515,7 @@ func oneDriveItemReader(
 	)
 
 	if isFile {
-		resp, err := downloadItem(hc, item)
+		resp, err := downloadItem(ctx, hc, item)
 		if err != nil {
 			return details.ItemInfo{}, nil, errors.Wrap(err, "downloading item")
 		}
@@ -127,13 +530,20 @@ func oneDriveItemReader(
 	return dii, rc, nil
 }
 
-func downloadItem(hc *http.Client, item models.DriveItemable) (*http.Response, error) {
+// errNoDownloadURL is produced when downloading an item that doesn't
+// carry a download url, such as items enumerated from the delta cache.
+var errNoDownloadURL = errors.New("item has no download url")
+
+// downloadItem requests the content of the item from its download url.
+// The request carries the ctx, so that it draws on the operation's
+// bandwidth budget, and ends with the operation.
+func downloadItem(ctx context.Context, hc *http.Client, item models.DriveItemable) (*http.Response, error) {
 	url, ok := item.GetAdditionalData()[downloadURLKey].(*string)
 	if !ok {
-		return nil, fmt.Errorf("extracting file url: file %s", *item.GetId())
+		return nil, clues.Stack(errNoDownloadURL).With("item_id", ptr.Val(item.GetId()))
 	}
 
-	req, err := http.NewRequest(http.MethodGet, *url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *url, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "new request")
 	}
@@ -177,16 +587,10 @@ func downloadItem(hc *http.Client, item models.DriveItemable) (*http.Response, e
 // doesn't have its size value updated as a side effect of creation,
 // and kiota drops any SetSize update.
 func oneDriveItemInfo(di models.DriveItemable, itemSize int64) *details.OneDriveInfo {
-	var email, parent string
-
-	if di.GetCreatedBy() != nil && di.GetCreatedBy().GetUser() != nil {
-		// User is sometimes not available when created via some
-		// external applications (like backup/restore solutions)
-		ed, ok := di.GetCreatedBy().GetUser().GetAdditionalData()["email"]
-		if ok {
-			email = *ed.(*string)
-		}
-	}
+	var (
+		email  = CreatedByEmail(di)
+		parent string
+	)
 
 	if di.GetParentReference() != nil && di.GetParentReference().GetName() != nil {
 		// EndPoint is not always populated from external apps
@@ -194,16 +598,86 @@ func oneDriveItemInfo(di models.DriveItemable, itemSize int64) *details.OneDrive
 	}
 
 	return &details.OneDriveInfo{
-		ItemType:  details.OneDriveItem,
-		ItemName:  ptr.Val(di.GetName()),
-		Created:   ptr.Val(di.GetCreatedDateTime()),
-		Modified:  ptr.Val(di.GetLastModifiedDateTime()),
-		DriveName: parent,
-		Size:      itemSize,
-		Owner:     email,
+		ItemType:         details.OneDriveItem,
+		ItemName:         ptr.Val(di.GetName()),
+		Created:          ptr.Val(di.GetCreatedDateTime()),
+		Modified:         ptr.Val(di.GetLastModifiedDateTime()),
+		DriveName:        parent,
+		Size:             itemSize,
+		Owner:            email,
+		ContentType:      ContentType(di),
+		SensitivityLabel: SensitivityLabel(di),
+		ItemID:           ptr.Val(di.GetId()),
+		ParentID:         parentItemID(di),
 	}
 }
 
+// parentItemID returns the ID of the folder containing the item.
+func parentItemID(di models.DriveItemable) string {
+	if di.GetParentReference() == nil {
+		return ""
+	}
+
+	return ptr.Val(di.GetParentReference().GetId())
+}
+
+// CreatedByEmail returns the email of the user who created the item.
+func CreatedByEmail(di models.DriveItemable) string {
+	if di.GetCreatedBy() == nil || di.GetCreatedBy().GetUser() == nil {
+		// User is sometimes not available when created via some
+		// external applications (like backup/restore solutions)
+		return ""
+	}
+
+	ed, ok := di.GetCreatedBy().GetUser().GetAdditionalData()["email"].(*string)
+	if !ok {
+		return ""
+	}
+
+	return ptr.Val(ed)
+}
+
+// ContentType returns the MIME type of the item's file content.
+func ContentType(di models.DriveItemable) string {
+	if di.GetFile() == nil {
+		return ""
+	}
+
+	return ptr.Val(di.GetFile().GetMimeType())
+}
+
+// SensitivityLabel returns the name of the sensitivity label assigned to
+// the item.  The label isn't part of the typed drive item model, so it's
+// read from the item's additional data when graph provides it, preferring
+// the label's display name over its id.  The raw delta pager stores the
+// label's name directly.
+func SensitivityLabel(di models.DriveItemable) string {
+	var label map[string]*kjson.JsonParseNode
+
+	switch v := di.GetAdditionalData()[sensitivityLabelKey].(type) {
+	case *string:
+		return ptr.Val(v)
+	case map[string]*kjson.JsonParseNode:
+		label = v
+	default:
+		return ""
+	}
+
+	for _, k := range []string{"displayName", "labelId", "id"} {
+		node, ok := label[k]
+		if !ok || node == nil {
+			continue
+		}
+
+		v, err := node.GetStringValue()
+		if err == nil && len(ptr.Val(v)) > 0 {
+			return ptr.Val(v)
+		}
+	}
+
+	return ""
+}
+
 // oneDriveItemPermissionInfo will fetch the permission information for a drive
 // item.
 func oneDriveItemPermissionInfo(
@@ -307,14 +781,19 @@ func sharePointItemInfo(di models.DriveItemable, itemSize int64) *details.ShareP
 	}
 
 	return &details.SharePointInfo{
-		ItemType:  details.OneDriveItem,
-		ItemName:  ptr.Val(di.GetName()),
-		Created:   ptr.Val(di.GetCreatedDateTime()),
-		Modified:  ptr.Val(di.GetLastModifiedDateTime()),
-		DriveName: parent,
-		Size:      itemSize,
-		Owner:     id,
-		WebURL:    url,
+		ItemType:         details.OneDriveItem,
+		ItemName:         ptr.Val(di.GetName()),
+		Created:          ptr.Val(di.GetCreatedDateTime()),
+		CreatedBy:        CreatedByEmail(di),
+		Modified:         ptr.Val(di.GetLastModifiedDateTime()),
+		DriveName:        parent,
+		Size:             itemSize,
+		Owner:            id,
+		WebURL:           url,
+		ContentType:      ContentType(di),
+		SensitivityLabel: SensitivityLabel(di),
+		ItemID:           ptr.Val(di.GetId()),
+		ParentID:         parentItemID(di),
 	}
 }
 
@@ -341,9 +820,9 @@ func driveItemWriter(
 
 	url := *r.GetUploadUrl()
 
-	logger.Ctx(ctx).Debugf("Created an upload session for item %s. URL: %s", itemID, url)
+	logger.Ctx(ctx).Debugw("created an upload session", "item_id", itemID, "upload_url", logger.PII(url))
 
-	return uploadsession.NewWriter(itemID, url, itemSize), nil
+	return uploadsession.NewWriter(ctx, itemID, url, itemSize), nil
 }
 
 // constructWebURL helper function for recreating the webURL
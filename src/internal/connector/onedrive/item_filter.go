@@ -0,0 +1,92 @@
+package onedrive
+
+import (
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+)
+
+// ItemProperty identifies the drive item property compared by an
+// ItemMatcher.
+type ItemProperty int
+
+const (
+	ItemCreatedBy ItemProperty = iota
+	ItemContentType
+	ItemSensitivityLabel
+)
+
+// ItemMatcher compares a property of a drive item against a selector
+// scope.  It lets onedrive and sharepoint selectors, whose scopes and
+// categories differ, share the same item filters.
+type ItemMatcher struct {
+	Property ItemProperty
+	Matches  func(string) bool
+}
+
+func (im ItemMatcher) value(item models.DriveItemable) string {
+	switch im.Property {
+	case ItemCreatedBy:
+		return CreatedByEmail(item)
+	case ItemContentType:
+		return ContentType(item)
+	case ItemSensitivityLabel:
+		return SensitivityLabel(item)
+	}
+
+	return ""
+}
+
+// ItemFilter produces an item filter that passes the items matched by
+// every matcher.  Returns nil if there are no matchers.
+func ItemFilter(matchers []ItemMatcher) func(models.DriveItemable) bool {
+	if len(matchers) == 0 {
+		return nil
+	}
+
+	return func(item models.DriveItemable) bool {
+		for _, m := range matchers {
+			if !m.Matches(m.value(item)) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// ScopedItemFilter restricts the filter to the items whose IDs are
+// selected, for scopes that select specific items.
+func ScopedItemFilter(
+	selected func(itemID string) bool,
+	filter func(models.DriveItemable) bool,
+) func(models.DriveItemable) bool {
+	return func(item models.DriveItemable) bool {
+		if !selected(ptr.Val(item.GetId())) {
+			return false
+		}
+
+		return filter == nil || filter(item)
+	}
+}
+
+// ItemNameExclusion produces an item exclusion that excludes the items
+// whose names are matched by any of the matchers.  Returns nil if there
+// are no matchers.
+func ItemNameExclusion(matchers []func(name string) bool) func(models.DriveItemable) bool {
+	if len(matchers) == 0 {
+		return nil
+	}
+
+	return func(item models.DriveItemable) bool {
+		name := ptr.Val(item.GetName())
+
+		for _, m := range matchers {
+			if m(name) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
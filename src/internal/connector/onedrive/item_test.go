@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	kjson "github.com/microsoft/kiota-serialization-json-go"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/stretchr/testify/assert"
@@ -55,7 +58,7 @@ func (suite *ItemIntegrationSuite) SetupSuite() {
 	m365, err := a.M365Config()
 	require.NoError(t, err)
 
-	adapter, err := graph.CreateAdapter(m365.AzureTenantID, m365.AzureClientID, m365.AzureClientSecret)
+	adapter, err := graph.CreateAdapter(m365)
 	require.NoError(t, err)
 
 	suite.client = msgraphsdk.NewGraphServiceClient(adapter)
@@ -122,6 +125,7 @@ func (suite *ItemIntegrationSuite) TestItemReader_oneDrive() {
 			suite,
 			suite.userDriveID,
 			"",
+			0,
 		),
 		suite.userDriveID,
 		"General",
@@ -141,7 +145,7 @@ func (suite *ItemIntegrationSuite) TestItemReader_oneDrive() {
 	)
 
 	// Read data for the file
-	itemInfo, itemData, err := oneDriveItemReader(graph.HTTPClient(graph.NoTimeout()), driveItem)
+	itemInfo, itemData, err := oneDriveItemReader(ctx, graph.HTTPClient(graph.NoTimeout()), driveItem)
 
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), itemInfo.OneDrive)
@@ -333,3 +337,99 @@ func TestOneDrivePermissionsFilter(t *testing.T) {
 		assert.ElementsMatch(t, tc.parsedPermissions, actual)
 	}
 }
+
+// graphFields produces list item fields in the form that graph's
+// deserializer hands them out.
+func graphFields(t *testing.T, js string) map[string]any {
+	pn, err := kjson.NewJsonParseNode([]byte(js))
+	require.NoError(t, err)
+
+	raw, err := pn.GetRawValue()
+	require.NoError(t, err)
+
+	fields := map[string]any{}
+
+	for k, n := range raw.(map[string]*kjson.JsonParseNode) {
+		v, err := n.GetRawValue()
+		require.NoError(t, err)
+
+		fields[k] = v
+	}
+
+	return fields
+}
+
+func TestFilterListItemFields(t *testing.T) {
+	fields := graphFields(t, `{
+		"Status": "Approved",
+		"Reviewed": true,
+		"Score": 4.5,
+		"Count": 12,
+		"Missing": null,
+		"@odata.etag": "\"1\"",
+		"Modified": "2023-01-01T00:00:00Z",
+		"_UIVersion": "512",
+		"AuthorLookupId": "7",
+		"Tags": ["red", "blue"],
+		"Reviewers": [{"LookupId": 6, "LookupValue": "Adele", "Email": "adele@example.com"}]
+	}`)
+
+	expect := ListItemFields{
+		"Status":   "Approved",
+		"Reviewed": true,
+		"Score":    4.5,
+		"Count":    float64(12),
+		"Tags":     []any{"red", "blue"},
+	}
+
+	assert.Equal(t, expect, filterListItemFields(fields))
+}
+
+func TestLookupFields(t *testing.T) {
+	fields := graphFields(t, `{
+		"Status": "Approved",
+		"OwnerLookupId": "7",
+		"Reviewers": [
+			{"LookupId": 6, "LookupValue": "Adele", "Email": "adele@example.com"},
+			{"LookupId": 9, "LookupValue": "Megan", "Email": "megan@example.com"}
+		],
+		"Tags": ["red", "blue"]
+	}`)
+
+	expect := map[string][]string{
+		"Owner":     {"7"},
+		"Reviewers": {"6", "9"},
+	}
+
+	assert.Equal(t, expect, lookupFields(fields))
+}
+
+func TestDownloadItem_context(t *testing.T) {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("content"))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	var (
+		id   = "id"
+		url  = srv.URL
+		item = models.NewDriveItem()
+	)
+
+	item.SetId(&id)
+	item.SetAdditionalData(map[string]any{downloadURLKey: &url})
+
+	resp, err := downloadItem(ctx, srv.Client(), item)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = downloadItem(cctx, srv.Client(), item)
+	assert.ErrorIs(t, err, context.Canceled, "downloads end with the operation's context")
+}
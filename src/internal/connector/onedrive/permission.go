@@ -191,31 +191,47 @@ func restorePermissions(
 	}
 
 	for _, p := range permAdded {
-		pbody := msdrive.NewItemsItemInvitePostRequestBody()
-		pbody.SetRoles(p.Roles)
-
-		if p.Expiration != nil {
-			expiry := p.Expiration.String()
-			pbody.SetExpirationDateTime(&expiry)
+		id, err := grantPermission(ctx, service, driveID, itemID, p)
+		if err != nil {
+			return err
 		}
 
-		si := false
-		pbody.SetSendInvitation(&si)
+		permissionIDMappings[p.ID] = id
+	}
+
+	return nil
+}
+
+// grantPermission invites the user to the item with the permission's roles,
+// without notifying them.  Returns the ID of the new permission.
+func grantPermission(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID, itemID string,
+	p UserPermission,
+) (string, error) {
+	pbody := msdrive.NewItemsItemInvitePostRequestBody()
+	pbody.SetRoles(p.Roles)
+
+	if p.Expiration != nil {
+		expiry := p.Expiration.String()
+		pbody.SetExpirationDateTime(&expiry)
+	}
 
-		rs := true
-		pbody.SetRequireSignIn(&rs)
+	si := false
+	pbody.SetSendInvitation(&si)
 
-		rec := models.NewDriveRecipient()
-		rec.SetEmail(&p.Email)
-		pbody.SetRecipients([]models.DriveRecipientable{rec})
+	rs := true
+	pbody.SetRequireSignIn(&rs)
 
-		np, err := service.Client().DrivesById(driveID).ItemsById(itemID).Invite().Post(ctx, pbody, nil)
-		if err != nil {
-			return clues.Wrap(err, "setting permissions").WithClues(ctx).With(graph.ErrData(err)...)
-		}
+	rec := models.NewDriveRecipient()
+	rec.SetEmail(&p.Email)
+	pbody.SetRecipients([]models.DriveRecipientable{rec})
 
-		permissionIDMappings[p.ID] = *np.GetValue()[0].GetId()
+	np, err := service.Client().DrivesById(driveID).ItemsById(itemID).Invite().Post(ctx, pbody, nil)
+	if err != nil {
+		return "", clues.Wrap(err, "setting permissions").WithClues(ctx).With(graph.ErrData(err)...)
 	}
 
-	return nil
+	return *np.GetValue()[0].GetId(), nil
 }
@@ -9,20 +9,24 @@ import (
 	"strings"
 
 	"github.com/alcionai/clues"
+	msdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
 	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	D "github.com/alcionai/corso/src/internal/diagnostics"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
 	"github.com/alcionai/corso/src/internal/version"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/transform"
 )
 
 // copyBufferSize is used for chunked upload
@@ -56,6 +60,10 @@ func RestoreCollections(
 		"backup_version", backupVersion,
 		"destination", dest.ContainerName)
 
+	if opts.EffectivePermissions().OneDrive.RestoreOnly {
+		return restorePermissionsInPlace(ctx, backupVersion, service, dest, dcs, errs)
+	}
+
 	// Reorder collections so that the parents directories are created
 	// before the child directories
 	sort.Slice(dcs, func(i, j int) bool {
@@ -65,6 +73,7 @@ func RestoreCollections(
 	var (
 		et                = errs.Tracker()
 		parentPermissions = map[string][]UserPermission{}
+		restoreDrives     = NewRestoreDrives(service, "", dest.DriveName)
 	)
 
 	// Iterate through the data collections and restore the contents of each
@@ -76,10 +85,11 @@ func RestoreCollections(
 		var (
 			err  error
 			ictx = clues.Add(
-				ctx,
-				"resource_owner", dc.FullPath().ResourceOwner(), // TODO: pii
-				"category", dc.FullPath().Category(),
-				"path", dc.FullPath()) // TODO: pii
+				opctx.Set(ctx, opctx.Metadata{
+					ResourceOwner: dc.FullPath().ResourceOwner(),
+					Category:      dc.FullPath().Category().String(),
+				}),
+				"path", logger.PII(dc.FullPath().String()))
 		)
 
 		metrics, folderPerms, permissionIDMappings, err = RestoreCollection(
@@ -90,9 +100,11 @@ func RestoreCollections(
 			parentPermissions,
 			OneDriveSource,
 			dest.ContainerName,
+			restoreDrives,
+			nil,
 			deets,
 			permissionIDMappings,
-			opts.RestorePermissions,
+			opts.EffectivePermissions().OneDrive.Restore,
 			errs)
 		if err != nil {
 			et.Add(err)
@@ -132,6 +144,8 @@ func RestoreCollection(
 	parentPermissions map[string][]UserPermission,
 	source driveSource,
 	restoreContainerName string,
+	restoreDrives *RestoreDrives,
+	fieldsWriter ListItemFieldsWriter,
 	deets *details.Builder,
 	permissionIDMappings map[string]string,
 	restorePerms bool,
@@ -153,12 +167,19 @@ func RestoreCollection(
 		return metrics, folderPerms, permissionIDMappings, clues.Wrap(err, "creating drive path").WithClues(ctx)
 	}
 
+	// Items are restored into the original drive unless the destination
+	// names a separate library to hold them.
+	drivePath.DriveID, err = restoreDrives.driveFor(ctx, drivePath.DriveID)
+	if err != nil {
+		return metrics, folderPerms, permissionIDMappings, clues.Wrap(err, "resolving restore drive")
+	}
+
 	// Assemble folder hierarchy we're going to restore into (we recreate the folder hierarchy
 	// from the backup under this the restore folder instead of root)
 	// i.e. Restore into `<drive>/root:/<restoreContainerName>/<original folder path>`
 
-	restoreFolderElements := []string{restoreContainerName}
-	restoreFolderElements = append(restoreFolderElements, drivePath.Folders...)
+	restoreFolderElements := control.ContainerElements(restoreContainerName)
+	restoreFolderElements = append(restoreFolderElements, transform.Ctx(ctx).RestoreFolders(drivePath.Folders)...)
 
 	ctx = clues.Add(
 		ctx,
@@ -301,11 +322,18 @@ func RestoreCollection(
 
 				}
 			} else {
+				// column values are restored along with their file.
+				if strings.HasSuffix(itemData.UUID(), FieldsFileSuffix) {
+					continue
+				}
+
 				metrics.Objects++
 				metrics.TotalBytes += int64(len(copyBuffer))
 
+				var itemID string
+
 				// No permissions stored at the moment for SharePoint
-				_, itemInfo, err = restoreData(
+				itemID, itemInfo, err = restoreData(
 					ctx,
 					service,
 					itemData.UUID(),
@@ -327,6 +355,30 @@ func RestoreCollection(
 					true,
 					itemInfo)
 				metrics.Successes++
+
+				if source == SharePointSource && fieldsWriter != nil {
+					err = restoreListItemFields(
+						ctx,
+						service,
+						fieldsWriter,
+						dc,
+						drivePath.DriveID,
+						itemID,
+						itemData.UUID())
+					if err != nil {
+						// the file is already restored, so column values that
+						// can't be written only leave the file without them.
+						logger.Ctx(ctx).
+							With("err", err).
+							Errorw("restoring sharepoint column values", clues.InErr(err).Slice()...)
+						errs.AddItem(fault.Item{
+							ID:      itemData.UUID(),
+							Folder:  directory.Folder(false),
+							Cause:   err.Error(),
+							Skipped: true,
+						})
+					}
+				}
 			}
 		}
 	}
@@ -334,6 +386,73 @@ func RestoreCollection(
 	return metrics, folderPerms, permissionIDMappings, et.Err()
 }
 
+// ListItemFieldsWriter sets the column values of the list item behind a
+// SharePoint library file.
+type ListItemFieldsWriter interface {
+	UpdateListItemFields(ctx context.Context, siteURL, listID, itemID string, fields map[string]any) error
+}
+
+// restoreListItemFields re-applies the column values of a restored
+// SharePoint library file.  Backups made before column values were
+// captured don't hold any, and their files are left as-is.
+func restoreListItemFields(
+	ctx context.Context,
+	service graph.Servicer,
+	fw ListItemFieldsWriter,
+	fetcher fileFetcher,
+	driveID, itemID, name string,
+) error {
+	fieldsFile, err := fetcher.Fetch(ctx, name+FieldsFileSuffix)
+	if err != nil {
+		if errors.Is(err, data.ErrNotFound) {
+			return nil
+		}
+
+		return clues.Wrap(err, "getting item fields").WithClues(ctx)
+	}
+
+	rc := fieldsFile.ToReader()
+	defer rc.Close()
+
+	var fields ListItemFields
+
+	if err := json.NewDecoder(rc).Decode(&fields); err != nil {
+		return clues.Wrap(err, "deserializing item fields").WithClues(ctx)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	di, err := service.Client().DrivesById(driveID).ItemsById(itemID).Get(
+		ctx,
+		&msdrives.ItemItemsDriveItemItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &msdrives.ItemItemsDriveItemItemRequestBuilderGetQueryParameters{
+				Select: []string{"id", "sharepointIds"},
+			},
+		})
+	if err != nil {
+		return clues.Wrap(err, "getting restored item list ids").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	ids := di.GetSharepointIds()
+	if ids == nil {
+		return clues.New("restored item has no list item").WithClues(ctx)
+	}
+
+	err = fw.UpdateListItemFields(
+		ctx,
+		ptr.Val(ids.GetSiteUrl()),
+		ptr.Val(ids.GetListId()),
+		ptr.Val(ids.GetListItemId()),
+		fields)
+	if err != nil {
+		return clues.Wrap(err, "restoring item fields")
+	}
+
+	return nil
+}
+
 type fileFetcher interface {
 	Fetch(ctx context.Context, name string) (data.Stream, error)
 }
@@ -473,9 +592,9 @@ func CreateRestoreFolders(
 	driveID string,
 	restoreFolders []string,
 ) (string, error) {
-	driveRoot, err := service.Client().DrivesById(driveID).Root().Get(ctx, nil)
+	driveRoot, err := api.NewClientFromService(service).Drives().GetRootFolder(ctx, driveID)
 	if err != nil {
-		return "", clues.Wrap(err, "getting drive root").WithClues(ctx).With(graph.ErrData(err)...)
+		return "", err
 	}
 
 	parentFolderID := ptr.Val(driveRoot.GetId())
@@ -520,7 +639,7 @@ func restoreData(
 	ctx, end := D.Span(ctx, "gc:oneDrive:restoreItem", D.Label("item_uuid", itemData.UUID()))
 	defer end()
 
-	ctx = clues.Add(ctx, "item_name", itemData.UUID())
+	ctx = clues.Add(ctx, "item_name", logger.PII(itemData.UUID()))
 
 	itemName := itemData.UUID()
 	trace.Log(ctx, "gc:oneDrive:restoreItem", itemName)
@@ -532,6 +651,8 @@ func restoreData(
 	}
 
 	// Create Item
+	name = transform.Ctx(ctx).RestoreName(name)
+
 	newItem, err := createItem(ctx, service, driveID, parentFolderID, newItem(name, false))
 	if err != nil {
 		return "", details.ItemInfo{}, clues.Wrap(err, "creating item")
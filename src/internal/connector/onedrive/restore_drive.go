@@ -0,0 +1,131 @@
+package onedrive
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
+	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// driveGetter retrieves drives.
+type driveGetter interface {
+	GetDrive(ctx context.Context, driveID string) (models.Driveable, error)
+}
+
+// documentLibraries lists and creates the document libraries within sites.
+type documentLibraries interface {
+	GetDrives(ctx context.Context, siteID string) ([]models.Driveable, error)
+	PostDocumentLibrary(ctx context.Context, siteID, name string) (models.Driveable, error)
+}
+
+// RestoreDrives redirects restored drive items out of the drive they were
+// backed up from, and into a document library with the given name within
+// the same site.  The library is created on demand if it does not exist.
+// When given a site, the library is found in (or created in) that site
+// instead, and takes the name of the source drive unless another name is
+// provided.  A nil RestoreDrives, or one without a name or site, restores
+// items into their original drive.
+type RestoreDrives struct {
+	driveAPI driveGetter
+	siteAPI  documentLibraries
+	siteID   string
+	name     string
+
+	mu sync.Mutex
+	// source drive ID -> restore drive ID
+	drives map[string]string
+}
+
+func NewRestoreDrives(service graph.Servicer, siteID, name string) *RestoreDrives {
+	return &RestoreDrives{
+		driveAPI: api.NewClientFromService(service).Drives(),
+		siteAPI:  sapi.NewClientFromService(service).Sites(),
+		siteID:   siteID,
+		name:     name,
+		drives:   map[string]string{},
+	}
+}
+
+// driveFor returns the ID of the drive into which items from the source
+// drive get restored.
+func (rd *RestoreDrives) driveFor(ctx context.Context, sourceDriveID string) (string, error) {
+	if rd == nil || (len(rd.name) == 0 && len(rd.siteID) == 0) {
+		return sourceDriveID, nil
+	}
+
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if id, ok := rd.drives[sourceDriveID]; ok {
+		return id, nil
+	}
+
+	ctx = clues.Add(ctx, "source_drive_id", sourceDriveID, "restore_drive_name", rd.name)
+
+	source, err := rd.driveAPI.GetDrive(ctx, sourceDriveID)
+	if err != nil {
+		return "", clues.Wrap(err, "getting source drive")
+	}
+
+	var (
+		siteID = rd.siteID
+		name   = rd.name
+	)
+
+	if len(siteID) == 0 {
+		if source.GetSharePointIds() == nil || len(ptr.Val(source.GetSharePointIds().GetSiteId())) == 0 {
+			return "", clues.New("source drive is not hosted in a site").WithClues(ctx)
+		}
+
+		siteID = ptr.Val(source.GetSharePointIds().GetSiteId())
+	}
+
+	// restoring into another site keeps the name of the source library.
+	if len(name) == 0 {
+		name = ptr.Val(source.GetName())
+	}
+
+	ctx = clues.Add(ctx, "site_id", siteID, "restore_drive_name", name)
+
+	id, err := rd.siteDrive(ctx, siteID, name)
+	if err != nil {
+		return "", err
+	}
+
+	rd.drives[sourceDriveID] = id
+
+	return id, nil
+}
+
+// siteDrive returns the ID of the site's drive with the given name, creating
+// a new document library to host the drive if none exists.  Items restored
+// into an existing library are kept apart from its contents by the restore
+// folder.
+func (rd *RestoreDrives) siteDrive(ctx context.Context, siteID, name string) (string, error) {
+	ds, err := rd.siteAPI.GetDrives(ctx, siteID)
+	if err != nil {
+		return "", clues.Wrap(err, "listing site drives")
+	}
+
+	for _, d := range ds {
+		if ptr.Val(d.GetName()) == name {
+			return ptr.Val(d.GetId()), nil
+		}
+	}
+
+	logger.Ctx(ctx).Info("creating restore document library")
+
+	d, err := rd.siteAPI.PostDocumentLibrary(ctx, siteID, name)
+	if err != nil {
+		return "", clues.Wrap(err, "creating restore document library")
+	}
+
+	return ptr.Val(d.GetId()), nil
+}
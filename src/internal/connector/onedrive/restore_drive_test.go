@@ -0,0 +1,180 @@
+package onedrive
+
+import (
+	"testing"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	odmock "github.com/alcionai/corso/src/internal/connector/onedrive/api/mock"
+	spmock "github.com/alcionai/corso/src/internal/connector/sharepoint/api/mock"
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type RestoreDrivesUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreDrivesUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreDrivesUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestoreDrivesUnitSuite) TestDriveFor() {
+	table := []struct {
+		name   string
+		rd     *RestoreDrives
+		expect string
+	}{
+		{"nil resolver", nil, "source"},
+		{"no library name", NewRestoreDrives(nil, "", ""), "source"},
+		{
+			name: "cached library",
+			rd: &RestoreDrives{
+				name:   "Restored Files",
+				drives: map[string]string{"source": "library"},
+			},
+			expect: "library",
+		},
+		{
+			name: "cached site library",
+			rd: &RestoreDrives{
+				siteID: "dest-site",
+				drives: map[string]string{"source": "site-library"},
+			},
+			expect: "site-library",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			id, err := test.rd.driveFor(ctx, "source")
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, id)
+		})
+	}
+}
+
+func siteDrive(id, name, siteID string) models.Driveable {
+	d := models.NewDrive()
+	d.SetId(&id)
+	d.SetName(&name)
+
+	if len(siteID) > 0 {
+		ids := models.NewSharepointIds()
+		ids.SetSiteId(&siteID)
+		d.SetSharePointIds(ids)
+	}
+
+	return d
+}
+
+func (suite *RestoreDrivesUnitSuite) TestDriveFor_lookup() {
+	table := []struct {
+		name          string
+		siteID        string
+		libName       string
+		drives        *odmock.Drives
+		sites         *spmock.Sites
+		expect        string
+		expectCreated []string
+		expectErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "existing library",
+			libName: "Restored Files",
+			drives: &odmock.Drives{Drives: map[string]models.Driveable{
+				"source": siteDrive("source", "Documents", "site"),
+			}},
+			sites: &spmock.Sites{Drives: map[string][]models.Driveable{
+				"site": {siteDrive("source", "Documents", ""), siteDrive("library", "Restored Files", "")},
+			}},
+			expect:    "library",
+			expectErr: assert.NoError,
+		},
+		{
+			name:    "created library",
+			libName: "Restored Files",
+			drives: &odmock.Drives{Drives: map[string]models.Driveable{
+				"source": siteDrive("source", "Documents", "site"),
+			}},
+			sites:         &spmock.Sites{},
+			expect:        "Restored Files",
+			expectCreated: []string{"Restored Files"},
+			expectErr:     assert.NoError,
+		},
+		{
+			name:   "other site keeps the source name",
+			siteID: "dest-site",
+			drives: &odmock.Drives{Drives: map[string]models.Driveable{
+				"source": siteDrive("source", "Documents", "site"),
+			}},
+			sites:         &spmock.Sites{},
+			expect:        "Documents",
+			expectCreated: []string{"Documents"},
+			expectErr:     assert.NoError,
+		},
+		{
+			name:    "source drive not in a site",
+			libName: "Restored Files",
+			drives: &odmock.Drives{Drives: map[string]models.Driveable{
+				"source": siteDrive("source", "OneDrive", ""),
+			}},
+			sites:     &spmock.Sites{},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "missing source drive",
+			libName:   "Restored Files",
+			drives:    &odmock.Drives{},
+			sites:     &spmock.Sites{},
+			expectErr: assert.Error,
+		},
+		{
+			name:    "site error",
+			libName: "Restored Files",
+			drives: &odmock.Drives{Drives: map[string]models.Driveable{
+				"source": siteDrive("source", "Documents", "site"),
+			}},
+			sites:     &spmock.Sites{Err: clues.New("access denied")},
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+			rd := &RestoreDrives{
+				driveAPI: test.drives,
+				siteAPI:  test.sites,
+				siteID:   test.siteID,
+				name:     test.libName,
+				drives:   map[string]string{},
+			}
+
+			id, err := rd.driveFor(ctx, "source")
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, id)
+			assert.Equal(t, test.expectCreated, test.sites.Libraries)
+
+			if err != nil {
+				return
+			}
+
+			// the drive is cached after the first lookup.
+			test.drives.Err = clues.New("no more lookups")
+
+			id, err = rd.driveFor(ctx, "source")
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, id)
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alcionai/clues"
+	msdrive "github.com/microsoftgraph/msgraph-sdk-go/drive"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+var (
+	errTargetNotFound     = clues.New("item no longer exists")
+	errPermissionsUnknown = clues.New("backup didn't capture the item's permissions")
+)
+
+// restorePermissionsInPlace reapplies the backed up permissions of each file
+// and folder to the original item, without restoring any content, so that
+// accidental mass changes to sharing can be undone.  Items are found by
+// their ID, if the backup recorded it, or else by their path within the
+// original drive.  Items that no longer exist are skipped.  Refuses to run
+// on backups that didn't capture the permissions of the items.  Nothing is
+// restored, so no details are produced.
+func restorePermissionsInPlace(
+	ctx context.Context,
+	backupVersion int,
+	service graph.Servicer,
+	dest control.RestoreDestination,
+	dcs []data.RestoreCollection,
+	errs *fault.Errors,
+) (*support.ConnectorOperationStatus, error) {
+	if backupVersion < version.OneDrive1DataAndMetaFiles {
+		return nil, clues.New("backup predates permissions, which can't be restored").
+			With("backup_version", backupVersion)
+	}
+
+	var (
+		metrics support.CollectionMetrics
+		et      = errs.Tracker()
+	)
+
+	// Reorder collections so that folders are reconciled before their
+	// children, whose inherited permissions follow those of the folder.
+	sort.Slice(dcs, func(i, j int) bool {
+		return dcs[i].FullPath().String() < dcs[j].FullPath().String()
+	})
+
+	for _, dc := range dcs {
+		if et.Err() != nil {
+			break
+		}
+
+		m, err := restoreCollectionPermissions(ctx, backupVersion, service, dc, errs)
+		if errors.Is(err, errPermissionsUnknown) {
+			return nil, err
+		}
+
+		if err != nil {
+			et.Add(err)
+		}
+
+		metrics.Combine(m)
+	}
+
+	status := support.CreateStatus(
+		ctx,
+		support.Restore,
+		len(dcs),
+		metrics,
+		et.Err(),
+		dest.ContainerName)
+
+	return status, et.Err()
+}
+
+// restoreCollectionPermissions reapplies the backed up permissions of the
+// collection's folder and files.
+func restoreCollectionPermissions(
+	ctx context.Context,
+	backupVersion int,
+	service graph.Servicer,
+	dc data.RestoreCollection,
+	errs *fault.Errors,
+) (support.CollectionMetrics, error) {
+	var (
+		metrics = support.CollectionMetrics{}
+		et      = errs.Tracker()
+	)
+
+	drivePath, err := path.ToOneDrivePath(dc.FullPath())
+	if err != nil {
+		return metrics, clues.Wrap(err, "creating drive path").WithClues(ctx)
+	}
+
+	ctx = clues.Add(
+		ctx,
+		"directory", logger.PII(dc.FullPath().Folder(false)),
+		"drive_id", drivePath.DriveID)
+
+	// Later versions hold the folder's metadata within its own collection.
+	// The root folder doesn't have any.
+	if backupVersion >= version.OneDrive4DirIncludesPermissions && len(drivePath.Folders) > 0 {
+		folders := drivePath.Folders
+
+		meta, err := fetchAndReadMetadata(ctx, dc, folders[len(folders)-1]+DirMetaFileSuffix)
+		if err != nil {
+			et.Add(clues.Wrap(err, "collection permissions"))
+		} else {
+			err = reapplyItemPermissions(ctx, service, drivePath.DriveID, meta, folders)
+			if errors.Is(err, errPermissionsUnknown) {
+				return metrics, err
+			}
+
+			if err != nil && !errors.Is(err, errTargetNotFound) {
+				et.Add(err)
+			}
+		}
+	}
+
+	items := dc.Items(ctx, errs)
+
+	for {
+		if et.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return metrics, clues.Stack(ctx.Err()).WithClues(ctx)
+
+		case itemData, ok := <-items:
+			if !ok {
+				return metrics, et.Err()
+			}
+
+			name := itemData.UUID()
+
+			switch {
+			case strings.HasSuffix(name, DataFileSuffix):
+				metrics.Objects++
+
+				trimmedName := strings.TrimSuffix(name, DataFileSuffix)
+
+				meta, err := fetchAndReadMetadata(ctx, dc, trimmedName+MetaFileSuffix)
+				if err != nil {
+					et.Add(clues.Wrap(err, "restoring file permissions"))
+					continue
+				}
+
+				if backupVersion < version.OneDriveXNameInMeta || len(meta.FileName) == 0 {
+					meta.FileName = trimmedName
+				}
+
+				elems := append(append([]string{}, drivePath.Folders...), meta.FileName)
+
+				err = reapplyItemPermissions(ctx, service, drivePath.DriveID, meta, elems)
+				if errors.Is(err, errPermissionsUnknown) {
+					return metrics, err
+				}
+
+				if errors.Is(err, errTargetNotFound) {
+					continue
+				}
+
+				if err != nil {
+					et.Add(err)
+					continue
+				}
+
+				metrics.Successes++
+
+			case strings.HasSuffix(name, DirMetaFileSuffix):
+				// Earlier versions hold the metadata of child folders within
+				// the collection of their parent.
+				if backupVersion >= version.OneDrive4DirIncludesPermissions {
+					continue
+				}
+
+				r := itemData.ToReader()
+				meta, err := getMetadata(r)
+				r.Close()
+
+				if err != nil {
+					et.Add(clues.Wrap(err, "getting directory metadata").WithClues(ctx))
+					continue
+				}
+
+				folder := strings.TrimSuffix(name, DirMetaFileSuffix)
+				elems := append(append([]string{}, drivePath.Folders...), folder)
+
+				err = reapplyItemPermissions(ctx, service, drivePath.DriveID, meta, elems)
+				if errors.Is(err, errPermissionsUnknown) {
+					return metrics, err
+				}
+
+				if err != nil && !errors.Is(err, errTargetNotFound) {
+					et.Add(err)
+				}
+			}
+		}
+	}
+
+	return metrics, et.Err()
+}
+
+// reapplyItemPermissions finds the original item described by the metadata
+// and brings its permissions in line with the backed up permissions.
+// elems is the item's path within the drive, beneath the root folder.
+// Returns errTargetNotFound if the item no longer exists.
+func reapplyItemPermissions(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID string,
+	meta Metadata,
+	elems []string,
+) error {
+	ctx = clues.Add(ctx, "item_path", logger.PII(strings.Join(elems, "/")), "item_id", meta.ItemID)
+
+	// without the backed up permissions, every live permission would look
+	// like one to revoke.
+	if !meta.PermissionsCaptured {
+		return clues.Stack(errPermissionsUnknown).WithClues(ctx)
+	}
+
+	item, err := findOriginalItem(ctx, service, driveID, meta.ItemID, elems)
+	if errors.Is(err, errTargetNotFound) {
+		logger.Ctx(ctx).Info("skipping permissions of an item that no longer exists")
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	itemID := ptr.Val(item.GetId())
+
+	resp, err := service.Client().DrivesById(driveID).ItemsById(itemID).Permissions().Get(ctx, nil)
+	if err != nil {
+		return clues.Wrap(err, "fetching item permissions").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	grant, revoke := diffPermissions(livePermissions(resp.GetValue()), meta.Permissions)
+
+	for _, id := range revoke {
+		err := service.Client().
+			DrivesById(driveID).
+			ItemsById(itemID).
+			PermissionsById(id).
+			Delete(ctx, nil)
+		if err != nil {
+			return clues.Wrap(err, "removing permissions").WithClues(ctx).With(graph.ErrData(err)...)
+		}
+	}
+
+	for _, p := range grant {
+		if _, err := grantPermission(ctx, service, driveID, itemID, p); err != nil {
+			return err
+		}
+	}
+
+	logger.Ctx(ctx).Debugw("reapplied item permissions", "granted", len(grant), "revoked", len(revoke))
+
+	return nil
+}
+
+// findOriginalItem looks up the item by its ID, falling back to its path
+// within the drive if the ID is unknown or no longer exists.
+func findOriginalItem(
+	ctx context.Context,
+	service graph.Servicer,
+	driveID, itemID string,
+	elems []string,
+) (models.DriveItemable, error) {
+	if len(itemID) > 0 {
+		item, err := service.Client().DrivesById(driveID).ItemsById(itemID).Get(ctx, nil)
+		if err == nil {
+			return item, nil
+		}
+
+		if !graph.IsErrDeletedInFlight(err) {
+			return nil, clues.Wrap(err, "getting item by id").WithClues(ctx).With(graph.ErrData(err)...)
+		}
+	}
+
+	rawURL := fmt.Sprintf(itemByPathRawURLFmt, driveID, "root", strings.Join(elems, "/"))
+
+	item, err := msdrive.NewItemsDriveItemItemRequestBuilder(rawURL, service.Adapter()).Get(ctx, nil)
+	if err != nil {
+		if graph.IsErrDeletedInFlight(err) {
+			return nil, clues.Stack(errTargetNotFound).WithClues(ctx)
+		}
+
+		return nil, clues.Wrap(err, "getting item by path").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return item, nil
+}
+
+// livePermission is a user permission currently granted on an item.
+type livePermission struct {
+	ID        string
+	Email     string
+	Roles     []string
+	Inherited bool
+}
+
+// livePermissions reduces the item's permissions to those granted to users.
+// Link shares and owners are left out, the same as in backups.
+func livePermissions(perms []models.Permissionable) []livePermission {
+	lps := []livePermission{}
+
+	for _, p := range perms {
+		if p.GetGrantedToV2() == nil || p.GetGrantedToV2().GetUser() == nil {
+			continue
+		}
+
+		email, _ := p.GetGrantedToV2().GetUser().GetAdditionalData()["email"].(*string)
+		if len(ptr.Val(email)) == 0 {
+			continue
+		}
+
+		roles := []string{}
+
+		for _, r := range p.GetRoles() {
+			if r != "owner" {
+				roles = append(roles, r)
+			}
+		}
+
+		if len(roles) == 0 {
+			continue
+		}
+
+		lps = append(lps, livePermission{
+			ID:        ptr.Val(p.GetId()),
+			Email:     ptr.Val(email),
+			Roles:     roles,
+			Inherited: p.GetInheritedFrom() != nil,
+		})
+	}
+
+	return lps
+}
+
+// diffPermissions compares the item's live permissions with its backed up
+// permissions by user and roles, since permission IDs change whenever a
+// permission is granted again.  Produces the backed up permissions that
+// need to be granted, and the IDs of the live permissions that need to be
+// revoked.  Inherited permissions are never revoked, since they're
+// reconciled on the ancestor that grants them.
+func diffPermissions(live []livePermission, backedUp []UserPermission) ([]UserPermission, []string) {
+	var (
+		grant   = []UserPermission{}
+		revoke  = []string{}
+		liveSet = map[string]struct{}{}
+		wanted  = map[string]struct{}{}
+	)
+
+	for _, p := range live {
+		liveSet[permissionKey(p.Email, p.Roles)] = struct{}{}
+	}
+
+	for _, p := range backedUp {
+		k := permissionKey(p.Email, p.Roles)
+		wanted[k] = struct{}{}
+
+		if _, ok := liveSet[k]; !ok {
+			grant = append(grant, p)
+		}
+	}
+
+	for _, p := range live {
+		if p.Inherited {
+			continue
+		}
+
+		if _, ok := wanted[permissionKey(p.Email, p.Roles)]; !ok {
+			revoke = append(revoke, p.ID)
+		}
+	}
+
+	return grant, revoke
+}
+
+func permissionKey(email string, roles []string) string {
+	rs := append([]string{}, roles...)
+	sort.Strings(rs)
+
+	return strings.ToLower(email) + "|" + strings.Join(rs, ",")
+}
@@ -0,0 +1,136 @@
+package onedrive
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type RestorePermissionsUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestorePermissionsUnitSuite(t *testing.T) {
+	suite.Run(t, &RestorePermissionsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestorePermissionsUnitSuite) TestLivePermissions() {
+	t := suite.T()
+
+	perm := func(id, email string, inherited bool, roles ...string) models.Permissionable {
+		p := models.NewPermission()
+		p.SetId(&id)
+		p.SetRoles(roles)
+
+		if len(email) > 0 {
+			u := models.NewIdentity()
+			u.SetAdditionalData(map[string]any{"email": &email})
+
+			is := models.NewSharePointIdentitySet()
+			is.SetUser(u)
+			p.SetGrantedToV2(is)
+		}
+
+		if inherited {
+			p.SetInheritedFrom(models.NewItemReference())
+		}
+
+		return p
+	}
+
+	lps := livePermissions([]models.Permissionable{
+		perm("link", "", false, "read"),
+		perm("owner", "bob@example.com", false, "owner"),
+		perm("direct", "alice@example.com", false, "write", "owner"),
+		perm("parent", "carol@example.com", true, "read"),
+	})
+
+	require.Len(t, lps, 2)
+	assert.Equal(t, livePermission{ID: "direct", Email: "alice@example.com", Roles: []string{"write"}}, lps[0])
+	assert.Equal(
+		t,
+		livePermission{ID: "parent", Email: "carol@example.com", Roles: []string{"read"}, Inherited: true},
+		lps[1])
+}
+
+func (suite *RestorePermissionsUnitSuite) TestDiffPermissions() {
+	table := []struct {
+		name         string
+		live         []livePermission
+		backedUp     []UserPermission
+		expectGrant  []string
+		expectRevoke []string
+	}{
+		{
+			name:         "unchanged",
+			live:         []livePermission{{ID: "1", Email: "alice@example.com", Roles: []string{"read", "write"}}},
+			backedUp:     []UserPermission{{ID: "old", Email: "Alice@example.com", Roles: []string{"write", "read"}}},
+			expectGrant:  []string{},
+			expectRevoke: []string{},
+		},
+		{
+			name:         "removed since backup",
+			backedUp:     []UserPermission{{ID: "old", Email: "alice@example.com", Roles: []string{"read"}}},
+			expectGrant:  []string{"alice@example.com"},
+			expectRevoke: []string{},
+		},
+		{
+			name:         "granted since backup",
+			live:         []livePermission{{ID: "1", Email: "mallory@example.com", Roles: []string{"write"}}},
+			expectGrant:  []string{},
+			expectRevoke: []string{"1"},
+		},
+		{
+			name:         "roles changed since backup",
+			live:         []livePermission{{ID: "1", Email: "alice@example.com", Roles: []string{"write"}}},
+			backedUp:     []UserPermission{{ID: "old", Email: "alice@example.com", Roles: []string{"read"}}},
+			expectGrant:  []string{"alice@example.com"},
+			expectRevoke: []string{"1"},
+		},
+		{
+			name:         "inherited permissions are left alone",
+			live:         []livePermission{{ID: "1", Email: "carol@example.com", Roles: []string{"read"}, Inherited: true}},
+			backedUp:     []UserPermission{{ID: "old", Email: "carol@example.com", Roles: []string{"read"}}},
+			expectGrant:  []string{},
+			expectRevoke: []string{},
+		},
+		{
+			name:         "inherited permissions are never revoked",
+			live:         []livePermission{{ID: "1", Email: "carol@example.com", Roles: []string{"read"}, Inherited: true}},
+			expectGrant:  []string{},
+			expectRevoke: []string{},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			grant, revoke := diffPermissions(test.live, test.backedUp)
+
+			emails := []string{}
+			for _, p := range grant {
+				emails = append(emails, p.Email)
+			}
+
+			assert.Equal(t, test.expectGrant, emails)
+			assert.Equal(t, test.expectRevoke, revoke)
+		})
+	}
+}
+
+func (suite *RestorePermissionsUnitSuite) TestReapplyItemPermissions_unknownPermissions() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	// backups that didn't capture permissions hold an empty set, which
+	// would otherwise revoke every live permission.
+	meta := Metadata{FileName: "file", ItemID: "id"}
+
+	err := reapplyItemPermissions(ctx, nil, "drive", meta, []string{"file"})
+	assert.ErrorIs(suite.T(), err, errPermissionsUnknown)
+}
@@ -0,0 +1,193 @@
+package onedrive
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// detectTransitions compares the drives found in the previous backup's
+// metadata against the resource owner's current drives.  A previous drive
+// which no longer exists produces a transition.  Drive IDs aren't stable
+// across re-provisioning, so when exactly one previous drive disappeared
+// and exactly one current drive has no previous metadata, the current drive
+// is considered its replacement.
+func detectTransitions(
+	drives []models.Driveable,
+	prevPathsByDriveID map[string]map[string]string,
+	includes func(driveID string) bool,
+) []backup.DriveTransition {
+	var (
+		current  = map[string]struct{}{}
+		fresh    []models.Driveable
+		previous []string
+	)
+
+	for _, d := range drives {
+		id := ptr.Val(d.GetId())
+		current[id] = struct{}{}
+
+		if _, ok := prevPathsByDriveID[id]; !ok && includes(id) {
+			fresh = append(fresh, d)
+		}
+	}
+
+	for id := range prevPathsByDriveID {
+		if _, ok := current[id]; !ok {
+			previous = append(previous, id)
+		}
+	}
+
+	if len(previous) == 0 {
+		return nil
+	}
+
+	sort.Strings(previous)
+
+	ts := make([]backup.DriveTransition, 0, len(previous))
+
+	for _, id := range previous {
+		ts = append(ts, backup.DriveTransition{PreviousDriveID: id})
+	}
+
+	if len(previous) == 1 && len(fresh) == 1 {
+		ts[0].DriveID = ptr.Val(fresh[0].GetId())
+		ts[0].DriveName = ptr.Val(fresh[0].GetName())
+	}
+
+	return ts
+}
+
+// handleTransition carries the previous drive's folders over to the
+// replacement drive.  Collections of the replacement drive found at the
+// same location as one of the previous drive's folders are marked as moved
+// from that folder.  All other folders of the previous drive are deleted so
+// that its contents aren't merged forward from the base backup.
+func (c *Collections) handleTransition(
+	ctx context.Context,
+	dt *backup.DriveTransition,
+	prevPaths map[string]string,
+) error {
+	ctx = clues.Add(
+		ctx,
+		"previous_drive_id", dt.PreviousDriveID,
+		"drive_id", dt.DriveID,
+		"drive_name", dt.DriveName)
+
+	// location within the drive -> replacement drive collection
+	replacements := map[string]*Collection{}
+
+	if len(dt.DriveID) > 0 {
+		for _, bc := range c.CollectionMap {
+			col, ok := bc.(*Collection)
+			if !ok || col.driveID != dt.DriveID || col.FullPath() == nil || col.PreviousPath() != nil {
+				continue
+			}
+
+			replacements[driveLocation(col.FullPath())] = col
+		}
+	}
+
+	for id, p := range prevPaths {
+		prevPath, err := path.FromDataLayerPath(p, false)
+		if err != nil {
+			return clues.Wrap(err, "invalid previous path").WithClues(ctx).With("previous_path", p)
+		}
+
+		if col, ok := replacements[driveLocation(prevPath)]; ok {
+			col.SetPreviousPath(prevPath)
+			dt.MigratedPaths++
+
+			continue
+		}
+
+		c.CollectionMap[id] = NewCollection(
+			c.itemClient,
+			nil,
+			prevPath,
+			dt.PreviousDriveID,
+			c.service,
+			c.statusUpdater,
+			c.source,
+			c.ctrl,
+			true,
+		)
+	}
+
+	logger.Ctx(ctx).Infow(
+		"drive transition",
+		"num_previous_paths", len(prevPaths),
+		"num_migrated_paths", dt.MigratedPaths)
+
+	recordTransition(ctx, *dt)
+
+	return nil
+}
+
+// driveLocation produces the location of the folder within its drive,
+// dropping the drive ID.
+func driveLocation(p path.Path) string {
+	folders := p.Folders()
+
+	// drives/<driveID>/root:/...
+	if len(folders) < 2 {
+		return p.String()
+	}
+
+	return path.Builder{}.Append(folders[2:]...).String()
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+// Transitions collects the drive transitions found while producing backup
+// collections.  It's safe for concurrent use.
+type Transitions struct {
+	mu  sync.Mutex
+	all []backup.DriveTransition
+}
+
+// All returns the transitions recorded so far.
+func (ts *Transitions) All() []backup.DriveTransition {
+	if ts == nil {
+		return nil
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return append([]backup.DriveTransition{}, ts.all...)
+}
+
+type transitionsKey struct{}
+
+// SetTransitions embeds the transitions within the context, so that drive
+// transitions found during the backup get recorded.
+func SetTransitions(ctx context.Context, ts *Transitions) context.Context {
+	if ts == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, transitionsKey{}, ts)
+}
+
+func recordTransition(ctx context.Context, dt backup.DriveTransition) {
+	ts, _ := ctx.Value(transitionsKey{}).(*Transitions)
+	if ts == nil {
+		return
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.all = append(ts.all, dt)
+}
@@ -0,0 +1,112 @@
+package onedrive
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+)
+
+type TransitionsUnitSuite struct {
+	tester.Suite
+}
+
+func TestTransitionsUnitSuite(t *testing.T) {
+	suite.Run(t, &TransitionsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func namedDrive(id, name string) models.Driveable {
+	d := models.NewDrive()
+	d.SetId(&id)
+	d.SetName(&name)
+
+	return d
+}
+
+func (suite *TransitionsUnitSuite) TestDetectTransitions() {
+	var (
+		all     = func(string) bool { return true }
+		prevOne = map[string]map[string]string{"old": {}}
+		prevTwo = map[string]map[string]string{"old": {}, "older": {}}
+	)
+
+	table := []struct {
+		name      string
+		drives    []models.Driveable
+		prevPaths map[string]map[string]string
+		includes  func(string) bool
+		expect    []backup.DriveTransition
+	}{
+		{
+			name:      "first backup",
+			drives:    []models.Driveable{namedDrive("new", "OneDrive")},
+			prevPaths: map[string]map[string]string{},
+			includes:  all,
+		},
+		{
+			name:      "same drive",
+			drives:    []models.Driveable{namedDrive("old", "OneDrive")},
+			prevPaths: prevOne,
+			includes:  all,
+		},
+		{
+			name:      "replaced drive",
+			drives:    []models.Driveable{namedDrive("new", "OneDrive")},
+			prevPaths: prevOne,
+			includes:  all,
+			expect: []backup.DriveTransition{
+				{PreviousDriveID: "old", DriveID: "new", DriveName: "OneDrive"},
+			},
+		},
+		{
+			name:      "removed drive",
+			drives:    []models.Driveable{},
+			prevPaths: prevOne,
+			includes:  all,
+			expect:    []backup.DriveTransition{{PreviousDriveID: "old"}},
+		},
+		{
+			name:      "replacement excluded",
+			drives:    []models.Driveable{namedDrive("new", "OneDrive")},
+			prevPaths: prevOne,
+			includes:  func(string) bool { return false },
+			expect:    []backup.DriveTransition{{PreviousDriveID: "old"}},
+		},
+		{
+			name:      "ambiguous replacement",
+			drives:    []models.Driveable{namedDrive("new", "OneDrive")},
+			prevPaths: prevTwo,
+			includes:  all,
+			expect: []backup.DriveTransition{
+				{PreviousDriveID: "old"},
+				{PreviousDriveID: "older"},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := detectTransitions(test.drives, test.prevPaths, test.includes)
+			assert.Equal(suite.T(), test.expect, result)
+		})
+	}
+}
+
+func (suite *TransitionsUnitSuite) TestRecordTransition() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	dt := backup.DriveTransition{PreviousDriveID: "old", DriveID: "new"}
+
+	// recording without transitions in the context is a no-op.
+	recordTransition(ctx, dt)
+
+	ts := &Transitions{}
+	recordTransition(SetTransitions(ctx, ts), dt)
+
+	assert.Equal(t, []backup.DriveTransition{dt}, ts.All())
+}
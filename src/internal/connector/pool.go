@@ -0,0 +1,165 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// DefaultOwnerCacheTTL is the duration for which a pool re-uses the
+// resource owners discovered within a tenant.
+const DefaultOwnerCacheTTL = 15 * time.Minute
+
+var sharedPool = NewPool(DefaultOwnerCacheTTL)
+
+// SharedPool returns the pool shared by all operations in the process.
+func SharedPool() *Pool {
+	return sharedPool
+}
+
+// Pool produces GraphConnectors for processes that run many operations.
+// Discovering a tenant's users and sites requires enumerating every owner
+// in the tenant, so the pool caches the discovered owners, per account and
+// resource type, and refreshes them once they are older than the ttl.
+// Each connector produced by the pool is new, and tracks the status of
+// its own operation.
+type Pool struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	owners map[poolKey]*ownerCache
+}
+
+type poolKey struct {
+	tenant   string
+	clientID string
+	resource resource
+}
+
+// ownerCache holds the owners of a single resource type.  The mutex
+// is held during refreshes, so that concurrent requests for the same
+// owners only enumerate the tenant once.
+type ownerCache struct {
+	mu        sync.Mutex
+	owners    map[string]string
+	refreshed time.Time
+}
+
+// NewPool constructs a pool that refreshes its cached owners after the
+// ttl.  A non-positive ttl refreshes the owners on every request.
+func NewPool(ttl time.Duration) *Pool {
+	return &Pool{
+		ttl:    ttl,
+		now:    time.Now,
+		owners: map[poolKey]*ownerCache{},
+	}
+}
+
+// GraphConnector produces a connector for the account whose resource
+// owners are populated from the pool's cache, discovering them only if
+// the cache is empty or expired.
+func (p *Pool) GraphConnector(
+	ctx context.Context,
+	itemClient *http.Client,
+	acct account.Account,
+	r resource,
+	errs *fault.Errors,
+) (*GraphConnector, error) {
+	gc, err := newGraphConnector(ctx, itemClient, acct)
+	if err != nil {
+		return nil, err
+	}
+
+	if r == AllResources || r == Users {
+		gc.Users, err = p.cachedOwners(ctx, gc, Users, gc.setTenantUsers, errs)
+		if err != nil {
+			return nil, errors.Wrap(err, "retrieving tenant user list")
+		}
+	}
+
+	if r == AllResources || r == Sites {
+		gc.Sites, err = p.cachedOwners(ctx, gc, Sites, gc.setTenantSites, errs)
+		if err != nil {
+			return nil, errors.Wrap(err, "retrieveing tenant site list")
+		}
+	}
+
+	return gc, nil
+}
+
+// Invalidate drops every cached owner for the account, forcing the next
+// request to re-discover them.
+func (p *Pool) Invalidate(acct account.Account) {
+	m365, err := acct.M365Config()
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k := range p.owners {
+		if k.tenant == m365.AzureTenantID && k.clientID == m365.AzureClientID {
+			delete(p.owners, k)
+		}
+	}
+}
+
+// cachedOwners returns a copy of the cached owners of the resource type,
+// calling discover to refresh them if needed.  discover must populate the
+// connector's owner map for the resource.
+func (p *Pool) cachedOwners(
+	ctx context.Context,
+	gc *GraphConnector,
+	r resource,
+	discover func(context.Context, *fault.Errors) error,
+	errs *fault.Errors,
+) (map[string]string, error) {
+	key := poolKey{
+		tenant:   gc.credentials.AzureTenantID,
+		clientID: gc.credentials.AzureClientID,
+		resource: r,
+	}
+
+	p.mu.Lock()
+
+	oc, ok := p.owners[key]
+	if !ok {
+		oc = &ownerCache{}
+		p.owners[key] = oc
+	}
+
+	p.mu.Unlock()
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.owners != nil && p.now().Sub(oc.refreshed) < p.ttl {
+		return maps.Clone(oc.owners), nil
+	}
+
+	logger.Ctx(ctx).Debugw("refreshing cached resource owners", "resource", r)
+
+	if err := discover(ctx, errs); err != nil {
+		return nil, err
+	}
+
+	owners := gc.Users
+	if r == Sites {
+		owners = gc.Sites
+	}
+
+	oc.owners = maps.Clone(owners)
+	oc.refreshed = p.now()
+
+	return owners, nil
+}
@@ -0,0 +1,162 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/credentials"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type PoolUnitSuite struct {
+	tester.Suite
+}
+
+func TestPoolUnitSuite(t *testing.T) {
+	suite.Run(t, &PoolUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func poolTestConnector(tenant string) *GraphConnector {
+	return &GraphConnector{
+		credentials: account.M365Config{
+			M365:          credentials.M365{AzureClientID: "cid"},
+			AzureTenantID: tenant,
+		},
+	}
+}
+
+func (suite *PoolUnitSuite) TestCachedOwners() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		now   = time.Now()
+		p     = NewPool(time.Minute)
+		calls int
+	)
+
+	p.now = func() time.Time { return now }
+
+	discoverFor := func(gc *GraphConnector) func(context.Context, *fault.Errors) error {
+		return func(context.Context, *fault.Errors) error {
+			calls++
+			gc.Users = map[string]string{"user@fnords.com": "uid"}
+
+			return nil
+		}
+	}
+
+	gc := poolTestConnector("tid")
+	owners, err := p.cachedOwners(ctx, gc, Users, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user@fnords.com": "uid"}, owners)
+	assert.Equal(t, 1, calls)
+
+	// cached owners get re-used
+	gc = poolTestConnector("tid")
+	owners, err = p.cachedOwners(ctx, gc, Users, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user@fnords.com": "uid"}, owners)
+	assert.Equal(t, 1, calls)
+
+	// callers receive a copy of the cache
+	owners["smarf@fnords.com"] = "uid2"
+
+	gc = poolTestConnector("tid")
+	owners, err = p.cachedOwners(ctx, gc, Users, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Len(t, owners, 1)
+	assert.Equal(t, 1, calls)
+
+	// other tenants and resources are cached separately
+	gc = poolTestConnector("tid2")
+	_, err = p.cachedOwners(ctx, gc, Users, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	gc = poolTestConnector("tid")
+	_, err = p.cachedOwners(ctx, gc, Sites, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// expired owners get refreshed
+	now = now.Add(time.Minute)
+
+	gc = poolTestConnector("tid")
+	_, err = p.cachedOwners(ctx, gc, Users, discoverFor(gc), fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func (suite *PoolUnitSuite) TestCachedOwners_discoveryFails() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		p     = NewPool(time.Minute)
+		gc    = poolTestConnector("tid")
+		calls int
+	)
+
+	fail := func(context.Context, *fault.Errors) error {
+		calls++
+		return errors.New("fnords")
+	}
+
+	_, err := p.cachedOwners(ctx, gc, Users, fail, fault.New(true))
+	assert.Error(t, err)
+
+	_, err = p.cachedOwners(ctx, gc, Users, fail, fault.New(true))
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls, "failures are not cached")
+}
+
+func (suite *PoolUnitSuite) TestInvalidate() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		p     = NewPool(time.Hour)
+		calls int
+	)
+
+	acct, err := account.NewAccount(
+		account.ProviderM365,
+		account.M365Config{
+			M365:          credentials.M365{AzureClientID: "cid", AzureClientSecret: "secret"},
+			AzureTenantID: "tid",
+		})
+	require.NoError(t, err)
+
+	discover := func(context.Context, *fault.Errors) error {
+		calls++
+		return nil
+	}
+
+	gc := poolTestConnector("tid")
+	gc.Sites = map[string]string{}
+
+	for i := 0; i < 2; i++ {
+		_, err = p.cachedOwners(ctx, gc, Sites, discover, fault.New(true))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	p.Invalidate(acct)
+
+	_, err = p.cachedOwners(ctx, gc, Sites, discover, fault.New(true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,416 @@
+package connector
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/clues"
+	msdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	mssites "github.com/microsoftgraph/msgraph-sdk-go/sites"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	discover "github.com/alcionai/corso/src/internal/connector/discovery/api"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	gapi "github.com/alcionai/corso/src/internal/connector/graph/api"
+	"github.com/alcionai/corso/src/internal/connector/onedrive"
+	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// quarantineContainer is the minimal info needed to identify, and
+// delete, a root-level restore container.
+type quarantineContainer struct {
+	id   string
+	name string
+	// driveID is the drive holding drive folders.
+	driveID string
+}
+
+// quarantineSource lists the root-level containers of a single
+// service, and deletes them.
+type quarantineSource struct {
+	name   string
+	list   func(ctx context.Context) ([]quarantineContainer, error)
+	delete func(ctx context.Context, c quarantineContainer) error
+}
+
+// PurgeExpiredQuarantines deletes every quarantine restore container owned
+// by the user whose expiry tag is before the boundary time.  Containers are
+// checked across mail, contacts, calendars, and the user's OneDrive.
+// Returns the names of all deleted containers.
+func (gc *GraphConnector) PurgeExpiredQuarantines(
+	ctx context.Context,
+	userID string,
+	boundary time.Time,
+	errs *fault.Errors,
+) ([]string, error) {
+	ctx = clues.Add(ctx, "user_id", logger.PII(userID), "boundary", boundary)
+	return purgeExpired(ctx, gc.quarantineSources(userID), boundary, errs)
+}
+
+// PurgeExpiredSiteQuarantines deletes every quarantine restore container in
+// the site whose expiry tag is before the boundary time.  Containers are
+// checked across the root folders of the site's document libraries, and
+// the site's lists and pages, which restores name after the container.
+// Returns the names of all deleted containers.
+func (gc *GraphConnector) PurgeExpiredSiteQuarantines(
+	ctx context.Context,
+	siteID string,
+	boundary time.Time,
+	errs *fault.Errors,
+) ([]string, error) {
+	ctx = clues.Add(ctx, "site_id", logger.PII(siteID), "boundary", boundary)
+	return purgeExpired(ctx, gc.siteQuarantineSources(siteID), boundary, errs)
+}
+
+func purgeExpired(
+	ctx context.Context,
+	srcs []quarantineSource,
+	boundary time.Time,
+	errs *fault.Errors,
+) ([]string, error) {
+	var (
+		purged = []string{}
+		et     = errs.Tracker()
+	)
+
+	for _, src := range srcs {
+		if et.Err() != nil {
+			break
+		}
+
+		sctx := clues.Add(ctx, "quarantine_source", src.name)
+
+		cs, err := src.list(sctx)
+		if err != nil {
+			et.Add(clues.Wrap(err, "listing containers").WithClues(sctx))
+			continue
+		}
+
+		for _, c := range cs {
+			if !control.QuarantineExpired(c.name, boundary) {
+				continue
+			}
+
+			cctx := clues.Add(sctx, "container_id", c.id, "container_name", logger.PII(c.name))
+
+			if err := src.delete(cctx, c); err != nil {
+				et.Add(clues.Wrap(err, "deleting quarantine container").WithClues(cctx).With(graph.ErrData(err)...))
+				continue
+			}
+
+			logger.Ctx(cctx).Info("deleted expired quarantine container")
+
+			purged = append(purged, c.name)
+		}
+	}
+
+	return purged, et.Err()
+}
+
+func (gc *GraphConnector) quarantineSources(userID string) []quarantineSource {
+	var (
+		gs   = gc.Service
+		user = gs.Client().UsersById(userID)
+	)
+
+	return []quarantineSource{
+		{
+			name: "mail",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				pager := &mailFolderPager{gs: gs, builder: user.MailFolders()}
+
+				fs, err := graph.GetAllValues[models.MailFolderable](ctx, pager, graph.DefaultPagerOptions())
+				if err != nil {
+					return nil, err
+				}
+
+				return toQuarantineContainers(fs, func(f models.MailFolderable) *string { return f.GetDisplayName() }), nil
+			},
+			delete: func(ctx context.Context, c quarantineContainer) error {
+				return user.MailFoldersById(c.id).Delete(ctx, nil)
+			},
+		},
+		{
+			name: "contacts",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				pager := &contactFolderPager{gs: gs, builder: user.ContactFolders()}
+
+				fs, err := graph.GetAllValues[models.ContactFolderable](ctx, pager, graph.DefaultPagerOptions())
+				if err != nil {
+					return nil, err
+				}
+
+				return toQuarantineContainers(fs, func(f models.ContactFolderable) *string { return f.GetDisplayName() }), nil
+			},
+			delete: func(ctx context.Context, c quarantineContainer) error {
+				return user.ContactFoldersById(c.id).Delete(ctx, nil)
+			},
+		},
+		{
+			name: "calendars",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				pager := &calendarPager{gs: gs, builder: user.Calendars()}
+
+				fs, err := graph.GetAllValues[models.Calendarable](ctx, pager, graph.DefaultPagerOptions())
+				if err != nil {
+					return nil, err
+				}
+
+				return toQuarantineContainers(fs, func(f models.Calendarable) *string { return f.GetName() }), nil
+			},
+			delete: func(ctx context.Context, c quarantineContainer) error {
+				return user.CalendarsById(c.id).Delete(ctx, nil)
+			},
+		},
+		{
+			name: "onedrive",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				drive, err := user.Drive().Get(ctx, nil)
+				if err != nil {
+					if graph.IsErrUserNotFound(err) {
+						return nil, nil
+					}
+
+					return nil, clues.Wrap(err, "getting user drive").WithClues(ctx).With(graph.ErrData(err)...)
+				}
+
+				return driveRootFolders(ctx, gs, ptr.Val(drive.GetId()))
+			},
+			delete: deleteDriveFolder(gs),
+		},
+	}
+}
+
+func (gc *GraphConnector) siteQuarantineSources(siteID string) []quarantineSource {
+	var (
+		gs   = gc.Service
+		site = gs.Client().SitesById(siteID)
+	)
+
+	return []quarantineSource{
+		{
+			name: "libraries",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				drives, err := sapi.NewClientFromService(gs).Sites().GetDrives(ctx, siteID)
+				if err != nil {
+					return nil, err
+				}
+
+				cs := []quarantineContainer{}
+
+				for _, d := range drives {
+					fs, err := driveRootFolders(ctx, gs, ptr.Val(d.GetId()))
+					if err != nil {
+						return nil, err
+					}
+
+					cs = append(cs, fs...)
+				}
+
+				return cs, nil
+			},
+			delete: deleteDriveFolder(gs),
+		},
+		{
+			name: "lists",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				pager := &siteListPager{gs: gs, builder: site.Lists()}
+
+				ls, err := graph.GetAllValues[models.Listable](ctx, pager, graph.DefaultPagerOptions())
+				if err != nil {
+					return nil, err
+				}
+
+				return toQuarantineContainers(ls, func(l models.Listable) *string { return l.GetDisplayName() }), nil
+			},
+			delete: func(ctx context.Context, c quarantineContainer) error {
+				return site.ListsById(c.id).Delete(ctx, nil)
+			},
+		},
+		{
+			name: "pages",
+			list: func(ctx context.Context) ([]quarantineContainer, error) {
+				bs, err := gc.betaService()
+				if err != nil {
+					return nil, err
+				}
+
+				ps, err := sapi.FetchPages(ctx, bs, siteID)
+				if err != nil {
+					return nil, err
+				}
+
+				cs := make([]quarantineContainer, 0, len(ps))
+
+				for _, p := range ps {
+					cs = append(cs, quarantineContainer{id: p.ID, name: p.Name})
+				}
+
+				return cs, nil
+			},
+			delete: func(ctx context.Context, c quarantineContainer) error {
+				bs, err := gc.betaService()
+				if err != nil {
+					return err
+				}
+
+				return sapi.DeleteSitePage(ctx, bs, siteID, c.id)
+			},
+		},
+	}
+}
+
+func (gc *GraphConnector) betaService() (*discover.BetaService, error) {
+	adpt, err := graph.CreateAdapter(gc.credentials)
+	if err != nil {
+		return nil, clues.Wrap(err, "constructing graph client")
+	}
+
+	return discover.NewBetaService(adpt), nil
+}
+
+// driveRootFolders lists the folders at the root of the drive.
+func driveRootFolders(
+	ctx context.Context,
+	gs graph.Servicer,
+	driveID string,
+) ([]quarantineContainer, error) {
+	pager := &driveRootPager{gs: gs, builder: gs.Client().DrivesById(driveID).Root().Children()}
+
+	fs, err := graph.GetAllValues[models.DriveItemable](ctx, pager, graph.DefaultPagerOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	folders := []models.DriveItemable{}
+
+	for _, f := range fs {
+		if f.GetFolder() != nil {
+			folders = append(folders, f)
+		}
+	}
+
+	cs := toQuarantineContainers(folders, func(f models.DriveItemable) *string { return f.GetName() })
+
+	for i := range cs {
+		cs[i].driveID = driveID
+	}
+
+	return cs, nil
+}
+
+func deleteDriveFolder(gs graph.Servicer) func(context.Context, quarantineContainer) error {
+	return func(ctx context.Context, c quarantineContainer) error {
+		return onedrive.DeleteItem(ctx, gs, c.driveID, c.id)
+	}
+}
+
+func toQuarantineContainers[T interface{ GetId() *string }](
+	vs []T,
+	nameOf func(T) *string,
+) []quarantineContainer {
+	cs := make([]quarantineContainer, 0, len(vs))
+
+	for _, v := range vs {
+		cs = append(cs, quarantineContainer{
+			id:   ptr.Val(v.GetId()),
+			name: ptr.Val(nameOf(v)),
+		})
+	}
+
+	return cs
+}
+
+// ---------------------------------------------------------------------------
+// root container pagers
+// ---------------------------------------------------------------------------
+
+type mailFolderPager struct {
+	gs      graph.Servicer
+	builder *users.ItemMailFoldersRequestBuilder
+}
+
+func (p *mailFolderPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *mailFolderPager) SetNext(nextLink string) {
+	p.builder = users.NewItemMailFoldersRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *mailFolderPager) ValuesIn(pl gapi.PageLinker) ([]models.MailFolderable, error) {
+	return graph.PageValues[models.MailFolderable](pl)
+}
+
+type contactFolderPager struct {
+	gs      graph.Servicer
+	builder *users.ItemContactFoldersRequestBuilder
+}
+
+func (p *contactFolderPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *contactFolderPager) SetNext(nextLink string) {
+	p.builder = users.NewItemContactFoldersRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *contactFolderPager) ValuesIn(pl gapi.PageLinker) ([]models.ContactFolderable, error) {
+	return graph.PageValues[models.ContactFolderable](pl)
+}
+
+type calendarPager struct {
+	gs      graph.Servicer
+	builder *users.ItemCalendarsRequestBuilder
+}
+
+func (p *calendarPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *calendarPager) SetNext(nextLink string) {
+	p.builder = users.NewItemCalendarsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *calendarPager) ValuesIn(pl gapi.PageLinker) ([]models.Calendarable, error) {
+	return graph.PageValues[models.Calendarable](pl)
+}
+
+type siteListPager struct {
+	gs      graph.Servicer
+	builder *mssites.ItemListsRequestBuilder
+}
+
+func (p *siteListPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *siteListPager) SetNext(nextLink string) {
+	p.builder = mssites.NewItemListsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *siteListPager) ValuesIn(pl gapi.PageLinker) ([]models.Listable, error) {
+	return graph.PageValues[models.Listable](pl)
+}
+
+type driveRootPager struct {
+	gs      graph.Servicer
+	builder *msdrives.ItemRootChildrenRequestBuilder
+}
+
+func (p *driveRootPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *driveRootPager) SetNext(nextLink string) {
+	p.builder = msdrives.NewItemRootChildrenRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *driveRootPager) ValuesIn(pl gapi.PageLinker) ([]models.DriveItemable, error) {
+	return graph.PageValues[models.DriveItemable](pl)
+}
@@ -0,0 +1,73 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type QuarantineUnitSuite struct {
+	tester.Suite
+}
+
+func TestQuarantineUnitSuite(t *testing.T) {
+	suite.Run(t, &QuarantineUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *QuarantineUnitSuite) TestPurgeExpired() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		expired = control.QuarantineRestoreDestination(common.SimpleDateTimeOneDrive, -time.Hour).ContainerName
+		current = control.QuarantineRestoreDestination(common.SimpleDateTimeOneDrive, time.Hour).ContainerName
+		deleted = []quarantineContainer{}
+	)
+
+	src := func(name string, cs ...quarantineContainer) quarantineSource {
+		return quarantineSource{
+			name: name,
+			list: func(context.Context) ([]quarantineContainer, error) {
+				return cs, nil
+			},
+			delete: func(_ context.Context, c quarantineContainer) error {
+				deleted = append(deleted, c)
+				return nil
+			},
+		}
+	}
+
+	srcs := []quarantineSource{
+		src(
+			"libraries",
+			quarantineContainer{id: "f1", name: expired, driveID: "d1"},
+			quarantineContainer{id: "f2", name: current, driveID: "d1"},
+			quarantineContainer{id: "f3", name: "Documents", driveID: "d2"}),
+		// lists and pages are named after the restore container.
+		src(
+			"lists",
+			quarantineContainer{id: "l1", name: expired + "_Tasks"},
+			quarantineContainer{id: "l2", name: current + "_Tasks"}),
+	}
+
+	purged, err := purgeExpired(ctx, srcs, time.Now(), fault.New(true))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{expired, expired + "_Tasks"}, purged)
+	assert.ElementsMatch(
+		t,
+		[]quarantineContainer{
+			{id: "f1", name: expired, driveID: "d1"},
+			{id: "l1", name: expired + "_Tasks"},
+		},
+		deleted)
+}
@@ -0,0 +1,460 @@
+package sharepoint
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/clues"
+	kjson "github.com/microsoft/kiota-serialization-json-go"
+	msgroups "github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	gapi "github.com/alcionai/corso/src/internal/connector/graph/api"
+	odapi "github.com/alcionai/corso/src/internal/connector/onedrive/api"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// Kinds of principals that can be granted access to a site.
+const (
+	principalUser      = "user"
+	principalGroup     = "group"
+	principalSiteUser  = "siteUser"
+	principalSiteGroup = "siteGroup"
+)
+
+// SiteAccess is an inventory of the principals and sharing links that grant
+// access to a site.  It gets stored alongside the site's library metadata so
+// that access can be reviewed, or re-established, after an incident.
+type SiteAccess struct {
+	// Grants holds the permissions at the root of each library.  This
+	// includes the site's associated owner, member, and visitor groups.
+	Grants []AccessGrant `json:"grants"`
+	// Groups holds the membership of the m365 groups that own the site's
+	// libraries.
+	Groups []GroupMembership `json:"groups"`
+	// Links holds the sharing links created for items within the libraries.
+	Links []SharingLink `json:"links"`
+}
+
+// Principal identifies a user or group.
+type Principal struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	LoginName string `json:"loginName,omitempty"`
+	Kind      string `json:"kind"`
+}
+
+// AccessGrant describes the roles a principal holds in a library.
+type AccessGrant struct {
+	DriveID   string    `json:"driveId"`
+	DriveName string    `json:"driveName"`
+	Principal Principal `json:"principal"`
+	Roles     []string  `json:"roles"`
+}
+
+// GroupMembership lists the owners and members of an m365 group.
+type GroupMembership struct {
+	GroupID string      `json:"groupId"`
+	Name    string      `json:"name,omitempty"`
+	Owners  []Principal `json:"owners"`
+	Members []Principal `json:"members"`
+}
+
+// SharingLink describes a sharing link on a library item.  The link url is
+// intentionally omitted, since it grants access on its own.
+type SharingLink struct {
+	DriveID          string      `json:"driveId"`
+	ItemID           string      `json:"itemId"`
+	ItemName         string      `json:"itemName"`
+	ParentPath       string      `json:"parentPath,omitempty"`
+	Type             string      `json:"type"`
+	Scope            string      `json:"scope"`
+	Roles            []string    `json:"roles"`
+	GrantedTo        []Principal `json:"grantedTo,omitempty"`
+	Expiration       *time.Time  `json:"expiration,omitempty"`
+	HasPassword      bool        `json:"hasPassword,omitempty"`
+	PreventsDownload bool        `json:"preventsDownload,omitempty"`
+}
+
+// siteAccessCollector produces the access inventory of a site.  Library
+// grants and group memberships are retrieved up front, while sharing links
+// are recorded from the items found by the library backup's own drive
+// enumeration, so that libraries aren't walked a second time.  Failures
+// are recorded in errs, and leave gaps in the inventory instead of failing
+// the backup.
+type siteAccessCollector struct {
+	serv   graph.Servicer
+	errs   *fault.Errors
+	access SiteAccess
+	// linked holds the drive and item ids of the shared items whose links
+	// were recorded, since a reset delta can produce an item twice.
+	linked map[string]struct{}
+}
+
+func newSiteAccessCollector(serv graph.Servicer, errs *fault.Errors) *siteAccessCollector {
+	return &siteAccessCollector{
+		serv: serv,
+		errs: errs,
+		access: SiteAccess{
+			Grants: []AccessGrant{},
+			Groups: []GroupMembership{},
+			Links:  []SharingLink{},
+		},
+		linked: map[string]struct{}{},
+	}
+}
+
+// collectGrants records the root permissions, and the owner group
+// memberships, of every library in the site.
+func (sac *siteAccessCollector) collectGrants(ctx context.Context, siteID string) {
+	var (
+		seenGroups = map[string]struct{}{}
+		pager      = odapi.NewSiteDrivePager(sac.serv, siteID, []string{"id", "name", "owner"})
+	)
+
+	drives, err := graph.GetAllValues[models.Driveable](ctx, pager, graph.DefaultPagerOptions())
+	if err != nil {
+		sac.errs.Add(clues.Wrap(err, "getting site libraries").WithClues(ctx).With(graph.ErrData(err)...))
+		return
+	}
+
+	for _, d := range drives {
+		if sac.errs.Err() != nil {
+			return
+		}
+
+		var (
+			driveID   = ptr.Val(d.GetId())
+			driveName = ptr.Val(d.GetName())
+			ictx      = clues.Add(ctx, "drive_id", driveID, "drive_name", logger.PII(driveName))
+		)
+
+		perms, err := getItemPermissions(ictx, sac.serv, driveID, "root")
+		if err != nil {
+			sac.errs.Add(clues.Wrap(err, "getting library permissions"))
+		} else {
+			sac.access.Grants = append(sac.access.Grants, accessGrants(driveID, driveName, perms)...)
+		}
+
+		gid, gname := ownerGroup(d)
+
+		if _, ok := seenGroups[gid]; len(gid) > 0 && !ok {
+			seenGroups[gid] = struct{}{}
+
+			gm, err := getGroupMembership(ictx, sac.serv, gid)
+			if err != nil {
+				sac.errs.Add(clues.Wrap(err, "getting library owner group membership"))
+			} else {
+				gm.Name = gname
+				sac.access.Groups = append(sac.access.Groups, gm)
+			}
+		}
+	}
+}
+
+// observe records the sharing links of each shared item found by the
+// drive enumeration.  Only shared items have their permissions retrieved.
+func (sac *siteAccessCollector) observe(ctx context.Context, driveID string, item models.DriveItemable) {
+	if item.GetShared() == nil || item.GetRoot() != nil || item.GetDeleted() != nil {
+		return
+	}
+
+	itemID := ptr.Val(item.GetId())
+	key := driveID + "/" + itemID
+
+	if _, ok := sac.linked[key]; ok {
+		return
+	}
+
+	sac.linked[key] = struct{}{}
+
+	perms, err := getItemPermissions(ctx, sac.serv, driveID, itemID)
+	if err != nil {
+		sac.errs.Add(clues.Wrap(err, "getting shared item permissions").With("drive_id", driveID))
+		return
+	}
+
+	sac.access.Links = append(sac.access.Links, sharingLinks(driveID, item, perms)...)
+}
+
+func getItemPermissions(
+	ctx context.Context,
+	serv graph.Servicer,
+	driveID, itemID string,
+) ([]models.Permissionable, error) {
+	resp, err := serv.Client().
+		DrivesById(driveID).
+		ItemsById(itemID).
+		Permissions().
+		Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, support.ConnectorStackErrorTrace(err)).
+			WithClues(ctx).
+			With("item_id", itemID).
+			With(graph.ErrData(err)...)
+	}
+
+	return resp.GetValue(), nil
+}
+
+func getGroupMembership(
+	ctx context.Context,
+	serv graph.Servicer,
+	groupID string,
+) (GroupMembership, error) {
+	gm := GroupMembership{GroupID: groupID}
+
+	owners, err := graph.GetAllValues[models.DirectoryObjectable](
+		ctx,
+		&groupOwnersPager{
+			gs:      serv,
+			builder: serv.Client().GroupsById(groupID).Owners(),
+		},
+		graph.DefaultPagerOptions())
+	if err != nil {
+		return GroupMembership{}, clues.Wrap(err, "getting group owners").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	members, err := graph.GetAllValues[models.DirectoryObjectable](
+		ctx,
+		&groupMembersPager{
+			gs:      serv,
+			builder: serv.Client().GroupsById(groupID).Members(),
+		},
+		graph.DefaultPagerOptions())
+	if err != nil {
+		return GroupMembership{}, clues.Wrap(err, "getting group members").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	gm.Owners = directoryPrincipals(owners)
+	gm.Members = directoryPrincipals(members)
+
+	return gm, nil
+}
+
+// ---------------------------------------------------------------------------
+// transformers
+// ---------------------------------------------------------------------------
+
+// accessGrants produces the grants held by each principal in the library's
+// root permissions.  Sharing links are excluded, since those are
+// inventoried separately.
+func accessGrants(driveID, driveName string, perms []models.Permissionable) []AccessGrant {
+	grants := []AccessGrant{}
+
+	for _, p := range perms {
+		if p.GetLink() != nil || p.GetGrantedToV2() == nil {
+			continue
+		}
+
+		principal, ok := toPrincipal(p.GetGrantedToV2())
+		if !ok {
+			continue
+		}
+
+		grants = append(grants, AccessGrant{
+			DriveID:   driveID,
+			DriveName: driveName,
+			Principal: principal,
+			Roles:     p.GetRoles(),
+		})
+	}
+
+	return grants
+}
+
+// sharingLinks produces the sharing links found in the item's permissions.
+// Links inherited from a parent item are skipped, since they get recorded
+// on the item where they were created.
+func sharingLinks(driveID string, item models.DriveItemable, perms []models.Permissionable) []SharingLink {
+	links := []SharingLink{}
+
+	var parentPath string
+	if pr := item.GetParentReference(); pr != nil {
+		parentPath = ptr.Val(pr.GetPath())
+	}
+
+	for _, p := range perms {
+		l := p.GetLink()
+		if l == nil || p.GetInheritedFrom() != nil {
+			continue
+		}
+
+		sl := SharingLink{
+			DriveID:          driveID,
+			ItemID:           ptr.Val(item.GetId()),
+			ItemName:         ptr.Val(item.GetName()),
+			ParentPath:       parentPath,
+			Type:             ptr.Val(l.GetType()),
+			Scope:            ptr.Val(l.GetScope()),
+			Roles:            p.GetRoles(),
+			Expiration:       p.GetExpirationDateTime(),
+			HasPassword:      ptr.Val(p.GetHasPassword()),
+			PreventsDownload: ptr.Val(l.GetPreventsDownload()),
+		}
+
+		for _, gt := range p.GetGrantedToIdentitiesV2() {
+			if principal, ok := toPrincipal(gt); ok {
+				sl.GrantedTo = append(sl.GrantedTo, principal)
+			}
+		}
+
+		links = append(links, sl)
+	}
+
+	return links
+}
+
+// toPrincipal picks the most specific identity within the set.  Returns
+// false if the set holds no identity.
+func toPrincipal(is models.SharePointIdentitySetable) (Principal, bool) {
+	if sg := is.GetSiteGroup(); sg != nil {
+		return Principal{
+			ID:        ptr.Val(sg.GetId()),
+			Name:      ptr.Val(sg.GetDisplayName()),
+			LoginName: ptr.Val(sg.GetLoginName()),
+			Kind:      principalSiteGroup,
+		}, true
+	}
+
+	if g := is.GetGroup(); g != nil {
+		return Principal{
+			ID:    ptr.Val(g.GetId()),
+			Name:  ptr.Val(g.GetDisplayName()),
+			Email: identityEmail(g),
+			Kind:  principalGroup,
+		}, true
+	}
+
+	if u := is.GetUser(); u != nil {
+		return Principal{
+			ID:    ptr.Val(u.GetId()),
+			Name:  ptr.Val(u.GetDisplayName()),
+			Email: identityEmail(u),
+			Kind:  principalUser,
+		}, true
+	}
+
+	if su := is.GetSiteUser(); su != nil {
+		return Principal{
+			ID:        ptr.Val(su.GetId()),
+			Name:      ptr.Val(su.GetDisplayName()),
+			LoginName: ptr.Val(su.GetLoginName()),
+			Kind:      principalSiteUser,
+		}, true
+	}
+
+	return Principal{}, false
+}
+
+// ownerGroup returns the id and name of the m365 group that owns the drive,
+// if any.  The sdk's identitySet doesn't model group owners, so the group
+// gets parsed out of the owner's additional data.
+func ownerGroup(d models.Driveable) (string, string) {
+	owner := d.GetOwner()
+	if owner == nil {
+		return "", ""
+	}
+
+	group, ok := owner.GetAdditionalData()["group"].(map[string]*kjson.JsonParseNode)
+	if !ok {
+		return "", ""
+	}
+
+	return parseNodeString(group["id"]), parseNodeString(group["displayName"])
+}
+
+func parseNodeString(n *kjson.JsonParseNode) string {
+	if n == nil {
+		return ""
+	}
+
+	v, err := n.GetStringValue()
+	if err != nil {
+		return ""
+	}
+
+	return ptr.Val(v)
+}
+
+// identityEmail returns the email graph includes in the identity's
+// additional data, if any.
+func identityEmail(id models.Identityable) string {
+	if email, ok := id.GetAdditionalData()["email"].(*string); ok {
+		return ptr.Val(email)
+	}
+
+	return ""
+}
+
+func directoryPrincipals(objs []models.DirectoryObjectable) []Principal {
+	ps := make([]Principal, 0, len(objs))
+
+	for _, obj := range objs {
+		p := Principal{ID: ptr.Val(obj.GetId())}
+
+		switch o := obj.(type) {
+		case models.Userable:
+			p.Kind = principalUser
+			p.Name = ptr.Val(o.GetDisplayName())
+			p.Email = ptr.Val(o.GetMail())
+		case models.Groupable:
+			p.Kind = principalGroup
+			p.Name = ptr.Val(o.GetDisplayName())
+			p.Email = ptr.Val(o.GetMail())
+		default:
+			p.Kind = ptr.Val(obj.GetOdataType())
+		}
+
+		ps = append(ps, p)
+	}
+
+	return ps
+}
+
+// ---------------------------------------------------------------------------
+// pagers
+// ---------------------------------------------------------------------------
+
+var _ graph.Pager[models.DirectoryObjectable] = &groupOwnersPager{}
+
+type groupOwnersPager struct {
+	gs      graph.Servicer
+	builder *msgroups.ItemOwnersRequestBuilder
+}
+
+func (p *groupOwnersPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *groupOwnersPager) SetNext(nextLink string) {
+	p.builder = msgroups.NewItemOwnersRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *groupOwnersPager) ValuesIn(pl gapi.PageLinker) ([]models.DirectoryObjectable, error) {
+	return graph.PageValues[models.DirectoryObjectable](pl)
+}
+
+var _ graph.Pager[models.DirectoryObjectable] = &groupMembersPager{}
+
+type groupMembersPager struct {
+	gs      graph.Servicer
+	builder *msgroups.ItemMembersRequestBuilder
+}
+
+func (p *groupMembersPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, nil)
+}
+
+func (p *groupMembersPager) SetNext(nextLink string) {
+	p.builder = msgroups.NewItemMembersRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *groupMembersPager) ValuesIn(pl gapi.PageLinker) ([]models.DirectoryObjectable, error) {
+	return graph.PageValues[models.DirectoryObjectable](pl)
+}
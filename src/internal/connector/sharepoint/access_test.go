@@ -0,0 +1,252 @@
+package sharepoint
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type SiteAccessUnitSuite struct {
+	tester.Suite
+}
+
+func TestSiteAccessUnitSuite(t *testing.T) {
+	suite.Run(t, &SiteAccessUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func siteGroupPermission(id, name string, roles ...string) models.Permissionable {
+	sg := models.NewSharePointIdentity()
+	sg.SetId(strPtr(id))
+	sg.SetDisplayName(strPtr(name))
+
+	is := models.NewSharePointIdentitySet()
+	is.SetSiteGroup(sg)
+
+	p := models.NewPermission()
+	p.SetId(strPtr("perm-" + id))
+	p.SetGrantedToV2(is)
+	p.SetRoles(roles)
+
+	return p
+}
+
+func userIdentitySet(id, email string) models.SharePointIdentitySetable {
+	u := models.NewIdentity()
+	u.SetId(strPtr(id))
+	u.SetAdditionalData(map[string]any{"email": strPtr(email)})
+
+	is := models.NewSharePointIdentitySet()
+	is.SetUser(u)
+
+	return is
+}
+
+func linkPermission(
+	linkType string,
+	scope string,
+	inherited bool,
+	grantees ...models.SharePointIdentitySetable,
+) models.Permissionable {
+	l := models.NewSharingLink()
+	l.SetType(strPtr(linkType))
+	l.SetScope(strPtr(scope))
+	l.SetWebUrl(strPtr("https://contoso.sharepoint.com/:w:/s/secret"))
+
+	p := models.NewPermission()
+	p.SetId(strPtr("link-" + linkType))
+	p.SetLink(l)
+	p.SetRoles([]string{linkType})
+	p.SetGrantedToIdentitiesV2(grantees)
+
+	if inherited {
+		p.SetInheritedFrom(models.NewItemReference())
+	}
+
+	return p
+}
+
+func (suite *SiteAccessUnitSuite) TestAccessGrants() {
+	t := suite.T()
+
+	perms := []models.Permissionable{
+		siteGroupPermission("3", "Eng Owners", "owner"),
+		siteGroupPermission("4", "Eng Members", "write"),
+		siteGroupPermission("5", "Eng Visitors", "read"),
+		linkPermission("view", "organization", false),
+		// no identity
+		models.NewPermission(),
+	}
+
+	grants := accessGrants("drive", "Documents", perms)
+	require.Len(t, grants, 3)
+
+	assert.Equal(t, "drive", grants[0].DriveID)
+	assert.Equal(t, "Documents", grants[0].DriveName)
+	assert.Equal(t, Principal{ID: "3", Name: "Eng Owners", Kind: principalSiteGroup}, grants[0].Principal)
+	assert.Equal(t, []string{"owner"}, grants[0].Roles)
+	assert.Equal(t, "Eng Members", grants[1].Principal.Name)
+	assert.Equal(t, []string{"read"}, grants[2].Roles)
+}
+
+func (suite *SiteAccessUnitSuite) TestSharingLinks() {
+	t := suite.T()
+
+	pr := models.NewItemReference()
+	pr.SetPath(strPtr("/drive/root:/Specs"))
+
+	item := models.NewDriveItem()
+	item.SetId(strPtr("item"))
+	item.SetName(strPtr("plan.docx"))
+	item.SetParentReference(pr)
+
+	perms := []models.Permissionable{
+		siteGroupPermission("3", "Eng Owners", "owner"),
+		linkPermission("edit", "users", false, userIdentitySet("u1", "a@contoso.com")),
+		linkPermission("view", "anonymous", true),
+	}
+
+	links := sharingLinks("drive", item, perms)
+	require.Len(t, links, 1)
+
+	l := links[0]
+	assert.Equal(t, "drive", l.DriveID)
+	assert.Equal(t, "item", l.ItemID)
+	assert.Equal(t, "plan.docx", l.ItemName)
+	assert.Equal(t, "/drive/root:/Specs", l.ParentPath)
+	assert.Equal(t, "edit", l.Type)
+	assert.Equal(t, "users", l.Scope)
+	assert.Equal(
+		t,
+		[]Principal{{ID: "u1", Email: "a@contoso.com", Kind: principalUser}},
+		l.GrantedTo)
+}
+
+func (suite *SiteAccessUnitSuite) TestToPrincipal() {
+	group := models.NewIdentity()
+	group.SetId(strPtr("g"))
+	group.SetDisplayName(strPtr("Eng"))
+
+	siteUser := models.NewSharePointIdentity()
+	siteUser.SetId(strPtr("7"))
+	siteUser.SetLoginName(strPtr("i:0#.f|membership|a@contoso.com"))
+
+	table := []struct {
+		name     string
+		is       func() models.SharePointIdentitySetable
+		expect   Principal
+		expectOK assert.BoolAssertionFunc
+	}{
+		{
+			name: "group",
+			is: func() models.SharePointIdentitySetable {
+				is := models.NewSharePointIdentitySet()
+				is.SetGroup(group)
+
+				return is
+			},
+			expect:   Principal{ID: "g", Name: "Eng", Kind: principalGroup},
+			expectOK: assert.True,
+		},
+		{
+			name: "user",
+			is: func() models.SharePointIdentitySetable {
+				return userIdentitySet("u", "a@contoso.com")
+			},
+			expect:   Principal{ID: "u", Email: "a@contoso.com", Kind: principalUser},
+			expectOK: assert.True,
+		},
+		{
+			name: "site user",
+			is: func() models.SharePointIdentitySetable {
+				is := models.NewSharePointIdentitySet()
+				is.SetSiteUser(siteUser)
+
+				return is
+			},
+			expect: Principal{
+				ID:        "7",
+				LoginName: "i:0#.f|membership|a@contoso.com",
+				Kind:      principalSiteUser,
+			},
+			expectOK: assert.True,
+		},
+		{
+			name: "empty",
+			is: func() models.SharePointIdentitySetable {
+				return models.NewSharePointIdentitySet()
+			},
+			expectOK: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			p, ok := toPrincipal(test.is())
+			test.expectOK(t, ok)
+			assert.Equal(t, test.expect, p)
+		})
+	}
+}
+
+func (suite *SiteAccessUnitSuite) TestDirectoryPrincipals() {
+	t := suite.T()
+
+	u := models.NewUser()
+	u.SetId(strPtr("u"))
+	u.SetDisplayName(strPtr("Adele"))
+	u.SetMail(strPtr("adele@contoso.com"))
+
+	g := models.NewGroup()
+	g.SetId(strPtr("g"))
+	g.SetDisplayName(strPtr("Eng"))
+
+	ps := directoryPrincipals([]models.DirectoryObjectable{u, g})
+	assert.Equal(
+		t,
+		[]Principal{
+			{ID: "u", Name: "Adele", Email: "adele@contoso.com", Kind: principalUser},
+			{ID: "g", Name: "Eng", Kind: principalGroup},
+		},
+		ps)
+}
+
+func (suite *SiteAccessUnitSuite) TestObserve_unshared() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	unshared := models.NewDriveItem()
+	unshared.SetId(strPtr("unshared"))
+
+	root := models.NewDriveItem()
+	root.SetId(strPtr("root"))
+	root.SetRoot(models.NewRoot())
+	root.SetShared(models.NewShared())
+
+	deleted := models.NewDriveItem()
+	deleted.SetId(strPtr("deleted"))
+	deleted.SetDeleted(models.NewDeleted())
+	deleted.SetShared(models.NewShared())
+
+	// a nil servicer panics if any permissions are requested.
+	sac := newSiteAccessCollector(nil, fault.New(true))
+
+	for _, item := range []models.DriveItemable{unshared, root, deleted} {
+		sac.observe(ctx, "drive", item)
+	}
+
+	assert.Empty(t, sac.access.Links)
+	assert.Empty(t, sac.linked, "only shared items have their permissions retrieved")
+}
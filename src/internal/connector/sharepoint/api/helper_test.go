@@ -11,11 +11,7 @@ import (
 )
 
 func createTestBetaService(t *testing.T, credentials account.M365Config) *discover.BetaService {
-	adapter, err := graph.CreateAdapter(
-		credentials.AzureTenantID,
-		credentials.AzureClientID,
-		credentials.AzureClientSecret,
-	)
+	adapter, err := graph.CreateAdapter(credentials)
 	require.NoError(t, err)
 
 	return discover.NewBetaService(adapter)
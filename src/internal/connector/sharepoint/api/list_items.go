@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+)
+
+// peopleKey identifies the login names of a person or group column's value.
+const peopleKey = "people"
+
+// PeopleValue produces the value of a person or group column from the login
+// names of its people.  SharePoint identifies people by ids local to each
+// site, so backups keep their login names instead.
+func PeopleValue(logins []string) map[string]any {
+	return map[string]any{peopleKey: logins}
+}
+
+// formValue is a column value as accepted by ValidateUpdateListItem.
+type formValue struct {
+	FieldName  string `json:"FieldName"`
+	FieldValue string `json:"FieldValue"`
+}
+
+// regionalSettings are the locale conventions of a site, which
+// ValidateUpdateListItem uses to parse number and date values.
+type regionalSettings struct {
+	DecimalSeparator string `json:"DecimalSeparator"`
+	DateSeparator    string `json:"DateSeparator"`
+	// DateFormat orders the parts of a date: 0 is month, day, year; 1 is
+	// day, month, year; and 2 is year, month, day.
+	DateFormat int  `json:"DateFormat"`
+	Time24     bool `json:"Time24"`
+	TimeZone   struct {
+		Information struct {
+			// Bias is the number of minutes added to the site's local
+			// time to produce UTC.  Daylight saving isn't accounted for.
+			Bias int `json:"Bias"`
+		} `json:"Information"`
+	} `json:"TimeZone"`
+}
+
+// defaultRegionalSettings are the conventions of an en-US site in UTC.
+var defaultRegionalSettings = regionalSettings{DecimalSeparator: ".", DateSeparator: "/"}
+
+// regionalCache holds the regional settings of each site, keyed by the
+// site's url.
+type regionalCache struct {
+	mu    sync.Mutex
+	sites map[string]regionalSettings
+}
+
+// UpdateListItemFields sets the column values of the list item.  Values are
+// validated by SharePoint the same way as edits made in the browser, and
+// the item is updated without creating a new version.
+func (vs ViewService) UpdateListItemFields(
+	ctx context.Context,
+	siteURL, listID, itemID string,
+	fields map[string]any,
+) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	rs, err := vs.regionalSettings(ctx, siteURL)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"formValues":         formValues(fields, rs),
+		"bNewDocumentUpdate": true,
+	}
+
+	var resp struct {
+		Value []struct {
+			FieldName    string `json:"FieldName"`
+			ErrorMessage string `json:"ErrorMessage"`
+			HasException bool   `json:"HasException"`
+		} `json:"value"`
+	}
+
+	u := fmt.Sprintf("%s/items(%s)/ValidateUpdateListItem", listURL(siteURL, listID), itemID)
+
+	if err := vs.do(ctx, http.MethodPost, u, body, nil, &resp); err != nil {
+		return clues.Wrap(err, "updating list item fields")
+	}
+
+	// invalid values are reported per field, instead of failing the request.
+	failed := map[string]string{}
+
+	for _, v := range resp.Value {
+		if v.HasException {
+			failed[v.FieldName] = v.ErrorMessage
+		}
+	}
+
+	if len(failed) > 0 {
+		return clues.New("list item fields rejected").WithClues(ctx).With("rejected_fields", failed)
+	}
+
+	return nil
+}
+
+// regionalSettings retrieves the site's regional settings, which are
+// cached for the life of the service.
+func (vs ViewService) regionalSettings(ctx context.Context, siteURL string) (regionalSettings, error) {
+	if vs.regional != nil {
+		vs.regional.mu.Lock()
+		defer vs.regional.mu.Unlock()
+
+		if rs, ok := vs.regional.sites[siteURL]; ok {
+			return rs, nil
+		}
+	}
+
+	rs := defaultRegionalSettings
+	u := strings.TrimSuffix(siteURL, "/") + "/_api/web/RegionalSettings?$expand=TimeZone"
+
+	if err := vs.do(ctx, http.MethodGet, u, nil, nil, &rs); err != nil {
+		return rs, clues.Wrap(err, "getting site regional settings")
+	}
+
+	if vs.regional != nil {
+		vs.regional.sites[siteURL] = rs
+	}
+
+	return rs, nil
+}
+
+// formValues serializes the column values in the string form expected by
+// ValidateUpdateListItem, which parses numbers and dates by the site's
+// regional settings.  Graph reports dates as RFC3339 strings in UTC, so
+// any string in that form is treated as a date.
+func formValues(fields map[string]any, rs regionalSettings) []formValue {
+	fvs := make([]formValue, 0, len(fields))
+
+	for k, v := range fields {
+		var s string
+
+		switch tv := v.(type) {
+		case string:
+			s = tv
+
+			if t, err := time.Parse(time.RFC3339, tv); err == nil {
+				s = rs.formatTime(t)
+			}
+		case bool:
+			s = "0"
+			if tv {
+				s = "1"
+			}
+		case float64:
+			s = rs.formatNumber(tv)
+		case []any:
+			// multiple choice values
+			vs := make([]string, 0, len(tv))
+			for _, e := range tv {
+				vs = append(vs, fmt.Sprint(e))
+			}
+
+			s = strings.Join(vs, ";#")
+		case map[string]any:
+			s = peopleFormValue(tv)
+		default:
+			s = fmt.Sprint(tv)
+		}
+
+		fvs = append(fvs, formValue{FieldName: k, FieldValue: s})
+	}
+
+	sort.Slice(fvs, func(i, j int) bool {
+		return fvs[i].FieldName < fvs[j].FieldName
+	})
+
+	return fvs
+}
+
+// peopleFormValue produces the form value of a person or group column,
+// which lists the login name of each person as a key.
+func peopleFormValue(v map[string]any) string {
+	keys := []map[string]string{}
+
+	switch logins := v[peopleKey].(type) {
+	case []string:
+		for _, l := range logins {
+			keys = append(keys, map[string]string{"Key": l})
+		}
+	case []any:
+		for _, l := range logins {
+			keys = append(keys, map[string]string{"Key": fmt.Sprint(l)})
+		}
+	}
+
+	bs, _ := json.Marshal(keys)
+
+	return string(bs)
+}
+
+// formatNumber produces the site's local form of the number.
+func (rs regionalSettings) formatNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if len(rs.DecimalSeparator) > 0 {
+		s = strings.Replace(s, ".", rs.DecimalSeparator, 1)
+	}
+
+	return s
+}
+
+// formatTime produces the site's local date and time form of t.
+func (rs regionalSettings) formatTime(t time.Time) string {
+	t = t.UTC().Add(-time.Duration(rs.TimeZone.Information.Bias) * time.Minute)
+
+	sep := rs.DateSeparator
+	if len(sep) == 0 {
+		sep = "/"
+	}
+
+	var layout string
+
+	switch rs.DateFormat {
+	case 1:
+		layout = "2" + sep + "1" + sep + "2006"
+	case 2:
+		layout = "2006" + sep + "1" + sep + "2"
+	default:
+		layout = "1" + sep + "2" + sep + "2006"
+	}
+
+	if rs.Time24 {
+		return t.Format(layout + " 15:04")
+	}
+
+	return t.Format(layout + " 3:04 PM")
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ListItemsUnitSuite struct {
+	tester.Suite
+}
+
+func TestListItemsUnitSuite(t *testing.T) {
+	suite.Run(t, &ListItemsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ListItemsUnitSuite) TestFormValues() {
+	fields := map[string]any{
+		"Status":   "Approved",
+		"Reviewed": true,
+		"Archived": false,
+		"Score":    4.5,
+		"Count":    float64(12),
+		"Due":      "2023-03-04T17:30:00Z",
+		"Tags":     []any{"red", "blue"},
+		"Owner":    map[string]any{"people": []any{"i:0#.f|membership|adele@example.com"}},
+	}
+
+	expect := []formValue{
+		{FieldName: "Archived", FieldValue: "0"},
+		{FieldName: "Count", FieldValue: "12"},
+		{FieldName: "Due", FieldValue: "3/4/2023 5:30 PM"},
+		{FieldName: "Owner", FieldValue: `[{"Key":"i:0#.f|membership|adele@example.com"}]`},
+		{FieldName: "Reviewed", FieldValue: "1"},
+		{FieldName: "Score", FieldValue: "4.5"},
+		{FieldName: "Status", FieldValue: "Approved"},
+		{FieldName: "Tags", FieldValue: "red;#blue"},
+	}
+
+	assert.Equal(suite.T(), expect, formValues(fields, defaultRegionalSettings))
+}
+
+func (suite *ListItemsUnitSuite) TestFormValues_regional() {
+	rs := regionalSettings{
+		DecimalSeparator: ",",
+		DateSeparator:    ".",
+		DateFormat:       1,
+		Time24:           true,
+	}
+	// UTC+1
+	rs.TimeZone.Information.Bias = -60
+
+	fields := map[string]any{
+		"Score": 4.5,
+		"Due":   "2023-03-04T17:30:00Z",
+	}
+
+	expect := []formValue{
+		{FieldName: "Due", FieldValue: "4.3.2023 18:30"},
+		{FieldName: "Score", FieldValue: "4,5"},
+	}
+
+	assert.Equal(suite.T(), expect, formValues(fields, rs))
+}
+
+func (suite *ListItemsUnitSuite) TestPeopleValue() {
+	v := PeopleValue([]string{"i:0#.f|membership|adele@example.com", "i:0#.f|membership|megan@example.com"})
+
+	assert.Equal(
+		suite.T(),
+		`[{"Key":"i:0#.f|membership|adele@example.com"},{"Key":"i:0#.f|membership|megan@example.com"}]`,
+		peopleFormValue(v))
+}
@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// Sites serves sites and their drives from memory, in place of the
+// site api client.  Requests for unknown sites fail.
+type Sites struct {
+	// Sites are keyed by site ID.
+	Sites map[string]models.Siteable
+	// Drives holds the drives of each site, keyed by site ID.  The
+	// first drive is the site's default drive.
+	Drives map[string][]models.Driveable
+	// Err, if populated, fails every request.
+	Err error
+
+	// Libraries records the name of each created document library.
+	Libraries []string
+	// DeletedLists records the ID of each deleted list.
+	DeletedLists []string
+}
+
+func (s *Sites) GetSite(_ context.Context, siteID string) (models.Siteable, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	site, ok := s.Sites[siteID]
+	if !ok {
+		return nil, clues.New("site not found").With("site_id", siteID)
+	}
+
+	return site, nil
+}
+
+func (s *Sites) GetDefaultDrive(_ context.Context, siteID string) (models.Driveable, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	ds := s.Drives[siteID]
+	if len(ds) == 0 {
+		return nil, clues.New("site drive not found").With("site_id", siteID)
+	}
+
+	return ds[0], nil
+}
+
+func (s *Sites) GetDrives(_ context.Context, siteID string) ([]models.Driveable, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	return s.Drives[siteID], nil
+}
+
+// PostDocumentLibrary adds a drive to the site, whose ID and name
+// are both the library's name.
+func (s *Sites) PostDocumentLibrary(_ context.Context, siteID, name string) (models.Driveable, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	if s.Drives == nil {
+		s.Drives = map[string][]models.Driveable{}
+	}
+
+	d := models.NewDrive()
+	d.SetId(&name)
+	d.SetName(&name)
+
+	s.Drives[siteID] = append(s.Drives[siteID], d)
+	s.Libraries = append(s.Libraries, name)
+
+	return d, nil
+}
+
+func (s *Sites) DeleteList(_ context.Context, _, listID string) error {
+	if s.Err != nil {
+		return s.Err
+	}
+
+	s.DeletedLists = append(s.DeletedLists, listID)
+
+	return nil
+}
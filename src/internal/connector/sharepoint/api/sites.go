@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	msmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	mssites "github.com/microsoftgraph/msgraph-sdk-go/sites"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	odapi "github.com/alcionai/corso/src/internal/connector/onedrive/api"
+	"github.com/alcionai/corso/src/pkg/account"
+)
+
+// documentLibraryTemplate is the list template that hosts a drive.
+const documentLibraryTemplate = "documentLibrary"
+
+// ---------------------------------------------------------------------------
+// controller
+// ---------------------------------------------------------------------------
+
+// Client is used to fulfill site queries that are backed by GraphAPI.
+// Like the exchange api client, the boundary separates the granular
+// implementation of the graphAPI and kiota away from the sharepoint
+// package's broader intents.
+type Client struct {
+	Credentials account.M365Config
+
+	// The stable service is re-usable for any non-paged request.
+	stable graph.Servicer
+}
+
+// NewClient produces a new site api client.
+func NewClient(creds account.M365Config) (Client, error) {
+	a, err := graph.CreateAdapter(creds)
+	if err != nil {
+		return Client{}, clues.Wrap(err, "generating graph adapter")
+	}
+
+	return Client{Credentials: creds, stable: graph.NewService(a)}, nil
+}
+
+// NewClientFromService produces a site api client that makes its
+// requests through the service.  Used by connectors that already
+// hold a service.
+func NewClientFromService(gs graph.Servicer) Client {
+	return Client{stable: gs}
+}
+
+func (c Client) Sites() Sites {
+	return Sites{c}
+}
+
+// Sites is an interface-compliant provider of the client.
+type Sites struct {
+	Client
+}
+
+// ---------------------------------------------------------------------------
+// methods
+// ---------------------------------------------------------------------------
+
+// GetSite retrieves the site's ID, name, and WebURL.
+func (c Sites) GetSite(ctx context.Context, siteID string) (msmodels.Siteable, error) {
+	options := &mssites.SiteItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &mssites.SiteItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "name", "webUrl"},
+		},
+	}
+
+	site, err := c.stable.Client().SitesById(siteID).Get(ctx, options)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting site").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return site, nil
+}
+
+// GetDefaultDrive retrieves the drive of the site's default document
+// library.
+func (c Sites) GetDefaultDrive(ctx context.Context, siteID string) (msmodels.Driveable, error) {
+	d, err := c.stable.Client().SitesById(siteID).Drive().Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting site drive").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return d, nil
+}
+
+// GetDrives retrieves the ID and name of each of the site's drives.
+func (c Sites) GetDrives(ctx context.Context, siteID string) ([]msmodels.Driveable, error) {
+	pager := odapi.NewSiteDrivePager(c.stable, siteID, []string{"id", "name"})
+
+	ds, err := graph.GetAllValues[msmodels.Driveable](ctx, pager, graph.DefaultPagerOptions())
+	if err != nil {
+		return nil, clues.Wrap(err, "listing site drives").WithClues(ctx)
+	}
+
+	return ds, nil
+}
+
+// PostDocumentLibrary creates a document library with the given name
+// within the site, and returns the library's drive.
+func (c Sites) PostDocumentLibrary(ctx context.Context, siteID, name string) (msmodels.Driveable, error) {
+	var (
+		template = documentLibraryTemplate
+		info     = msmodels.NewListInfo()
+		lib      = msmodels.NewList()
+	)
+
+	info.SetTemplate(&template)
+	lib.SetDisplayName(&name)
+	lib.SetList(info)
+
+	created, err := c.stable.Client().SitesById(siteID).Lists().Post(ctx, lib, nil)
+	if err != nil {
+		if graph.IsErrAccessDenied(err) {
+			return nil, clues.Wrap(err, "insufficient permissions to create document library").
+				WithClues(ctx).
+				With(graph.ErrData(err)...)
+		}
+
+		return nil, clues.Wrap(err, "creating document library").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	d, err := c.stable.Client().
+		SitesById(siteID).
+		ListsById(ptr.Val(created.GetId())).
+		Drive().
+		Get(ctx, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting document library drive").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return d, nil
+}
+
+// DeleteList removes the list from the site.
+func (c Sites) DeleteList(ctx context.Context, siteID, listID string) error {
+	err := c.stable.Client().SitesById(siteID).ListsById(listID).Delete(ctx, nil)
+	if err != nil {
+		return clues.Wrap(err, "deleting list").WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/account"
+)
+
+// ListView describes a view of a SharePoint list: the columns it shows,
+// its filters and sort order (as a CAML query), and its formatting.
+type ListView struct {
+	Title string `json:"title"`
+	// Fields are the internal names of the columns shown by the view.
+	Fields []string `json:"fields,omitempty"`
+	// Query is the CAML <Where> and <OrderBy> definition of the view.
+	Query    string `json:"query,omitempty"`
+	RowLimit int    `json:"rowLimit,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+	// CustomFormatter is the view's formatting JSON.
+	CustomFormatter string `json:"customFormatter,omitempty"`
+}
+
+// ViewService reads and writes list views, and writes the column values of
+// list items.  Graph does not expose list views, so the service calls the
+// SharePoint REST API of the site.
+type ViewService struct {
+	cred     azcore.TokenCredential
+	client   *http.Client
+	regional *regionalCache
+}
+
+// NewViewService creates a ViewService which authenticates as the signed-in
+// user, or with the application's certificate.  App-only auth without a
+// certificate produces graph.ErrSharePointRESTAuth.  Requests go through
+// the same retry and throttling middleware as graph requests.
+func NewViewService(creds account.M365Config) (*ViewService, error) {
+	cred, err := graph.NewSharePointCredential(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ViewService{
+		cred:     cred,
+		client:   graph.HTTPClient(),
+		regional: &regionalCache{sites: map[string]regionalSettings{}},
+	}, nil
+}
+
+// restView is the SharePoint REST representation of a list view.
+type restView struct {
+	ID              string `json:"Id,omitempty"`
+	Title           string `json:"Title"`
+	ViewQuery       string `json:"ViewQuery"`
+	RowLimit        int    `json:"RowLimit"`
+	DefaultView     bool   `json:"DefaultView"`
+	Hidden          bool   `json:"Hidden"`
+	PersonalView    bool   `json:"PersonalView"`
+	CustomFormatter string `json:"CustomFormatter"`
+	ViewFields      struct {
+		Items []string `json:"Items"`
+	} `json:"ViewFields"`
+}
+
+func (rv restView) toListView() ListView {
+	return ListView{
+		Title:           rv.Title,
+		Fields:          rv.ViewFields.Items,
+		Query:           rv.ViewQuery,
+		RowLimit:        rv.RowLimit,
+		Default:         rv.DefaultView,
+		CustomFormatter: rv.CustomFormatter,
+	}
+}
+
+// GetListViews retrieves the public views of the list.  Hidden and
+// personal views are skipped.
+func (vs ViewService) GetListViews(ctx context.Context, siteURL, listID string) ([]ListView, error) {
+	rvs, err := vs.getRestViews(ctx, siteURL, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ListView, 0, len(rvs))
+
+	for _, rv := range rvs {
+		if rv.Hidden || rv.PersonalView {
+			continue
+		}
+
+		views = append(views, rv.toListView())
+	}
+
+	return views, nil
+}
+
+func (vs ViewService) getRestViews(ctx context.Context, siteURL, listID string) ([]restView, error) {
+	var resp struct {
+		Value []restView `json:"value"`
+	}
+
+	err := vs.do(ctx, http.MethodGet, listURL(siteURL, listID)+"/views?$expand=ViewFields", nil, nil, &resp)
+	if err != nil {
+		return nil, clues.Wrap(err, "getting list views")
+	}
+
+	return resp.Value, nil
+}
+
+// RestoreListViews re-creates the views within the list.  Views sharing a
+// title with one of the list's existing views, such as the default "All
+// Items" view of a new list, replace the existing view's definition.
+func (vs ViewService) RestoreListViews(
+	ctx context.Context,
+	siteURL, listID string,
+	views []ListView,
+) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	existing, err := vs.getRestViews(ctx, siteURL, listID)
+	if err != nil {
+		return err
+	}
+
+	ids := map[string]string{}
+	for _, rv := range existing {
+		ids[rv.Title] = rv.ID
+	}
+
+	for _, v := range views {
+		vctx := clues.Add(ctx, "view_title", v.Title)
+
+		if id, ok := ids[v.Title]; ok {
+			if err := vs.updateView(vctx, siteURL, listID, id, v); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := vs.addView(vctx, siteURL, listID, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (vs ViewService) addView(ctx context.Context, siteURL, listID string, v ListView) error {
+	body := map[string]any{
+		"parameters": map[string]any{
+			"Title":            v.Title,
+			"ViewFields":       v.Fields,
+			"Query":            v.Query,
+			"RowLimit":         v.RowLimit,
+			"PersonalView":     false,
+			"SetAsDefaultView": v.Default,
+		},
+	}
+
+	var created restView
+
+	if err := vs.do(ctx, http.MethodPost, listURL(siteURL, listID)+"/views/add", body, nil, &created); err != nil {
+		return clues.Wrap(err, "adding list view")
+	}
+
+	// formatting can't be provided when the view is created.
+	if len(v.CustomFormatter) == 0 {
+		return nil
+	}
+
+	return vs.mergeView(ctx, siteURL, listID, created.ID, map[string]any{"CustomFormatter": v.CustomFormatter})
+}
+
+func (vs ViewService) updateView(ctx context.Context, siteURL, listID, viewID string, v ListView) error {
+	err := vs.mergeView(ctx, siteURL, listID, viewID, map[string]any{
+		"ViewQuery":       v.Query,
+		"RowLimit":        v.RowLimit,
+		"DefaultView":     v.Default,
+		"CustomFormatter": v.CustomFormatter,
+	})
+	if err != nil {
+		return err
+	}
+
+	fieldsURL := viewURL(siteURL, listID, viewID) + "/ViewFields"
+
+	if err := vs.do(ctx, http.MethodPost, fieldsURL+"/RemoveAllViewFields", nil, nil, nil); err != nil {
+		return clues.Wrap(err, "clearing list view fields")
+	}
+
+	for _, f := range v.Fields {
+		u := fieldsURL + "/AddViewField(" + url.PathEscape(quote(f)) + ")"
+
+		if err := vs.do(ctx, http.MethodPost, u, nil, nil, nil); err != nil {
+			return clues.Wrap(err, "adding list view field").With("field", f)
+		}
+	}
+
+	return nil
+}
+
+func (vs ViewService) mergeView(
+	ctx context.Context,
+	siteURL, listID, viewID string,
+	props map[string]any,
+) error {
+	err := vs.do(
+		ctx,
+		http.MethodPost,
+		viewURL(siteURL, listID, viewID),
+		props,
+		map[string]string{"X-HTTP-Method": "MERGE", "IF-MATCH": "*"},
+		nil)
+	if err != nil {
+		return clues.Wrap(err, "updating list view")
+	}
+
+	return nil
+}
+
+// do sends the request to the SharePoint REST API, serializing the body,
+// if any, and deserializing the response into out, if provided.
+func (vs ViewService) do(
+	ctx context.Context,
+	method, u string,
+	body any,
+	headers map[string]string,
+	out any,
+) error {
+	ctx = clues.Add(ctx, "method", method, "url", u)
+
+	site, err := url.Parse(u)
+	if err != nil {
+		return clues.Wrap(err, "parsing site url").WithClues(ctx)
+	}
+
+	// sharepoint tokens are scoped to the tenant's sharepoint host.
+	tok, err := vs.cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{site.Scheme + "://" + site.Host + "/.default"},
+	})
+	if err != nil {
+		return clues.Wrap(err, "getting sharepoint token").WithClues(ctx)
+	}
+
+	var rdr io.Reader
+
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return clues.Wrap(err, "serializing request").WithClues(ctx)
+		}
+
+		rdr = bytes.NewReader(bs)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, rdr)
+	if err != nil {
+		return clues.Wrap(err, "creating request").WithClues(ctx)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+tok.Token)
+	req.Header.Set("Accept", "application/json;odata=nometadata")
+	req.Header.Set("Content-Type", "application/json;odata=nometadata")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := vs.client.Do(req)
+	if err != nil {
+		return clues.Wrap(err, "sending request").WithClues(ctx)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return clues.New(resp.Status).
+			WithClues(ctx).
+			With("status_code", resp.StatusCode, "response", string(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return clues.Wrap(err, "deserializing response").WithClues(ctx)
+	}
+
+	return nil
+}
+
+func listURL(siteURL, listID string) string {
+	return fmt.Sprintf("%s/_api/web/lists(guid'%s')", strings.TrimSuffix(siteURL, "/"), listID)
+}
+
+func viewURL(siteURL, listID, viewID string) string {
+	return fmt.Sprintf("%s/views(guid'%s')", listURL(siteURL, listID), viewID)
+}
+
+// quote produces an odata string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -9,10 +9,13 @@ import (
 	"github.com/alcionai/clues"
 	absser "github.com/microsoft/kiota-abstractions-go/serialization"
 	kw "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/discovery/api"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	betamodels "github.com/alcionai/corso/src/internal/connector/graph/betasdk/models"
 	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
@@ -64,6 +67,8 @@ type Collection struct {
 	ctrl          control.Options
 	betaService   *api.BetaService
 	statusUpdater support.StatusUpdater
+	// views retrieves list views; if nil, list views are not backed up.
+	views *sapi.ViewService
 }
 
 // NewCollection helper function for creating a Collection
@@ -186,7 +191,6 @@ func (sc *Collection) finishPopulation(
 func (sc *Collection) populate(ctx context.Context, errs *fault.Errors) {
 	var (
 		metrics numMetrics
-		writer  = kw.NewJsonSerializationWriter()
 		err     error
 	)
 
@@ -209,129 +213,165 @@ func (sc *Collection) populate(ctx context.Context, errs *fault.Errors) {
 	// Switch retrieval function based on category
 	switch sc.category {
 	case List:
-		metrics, err = sc.retrieveLists(ctx, writer, colProgress, errs)
+		metrics, err = sc.retrieveLists(ctx, colProgress, errs)
 	case Pages:
-		metrics, err = sc.retrievePages(ctx, writer, colProgress, errs)
+		metrics, err = sc.retrievePages(ctx, colProgress, errs)
 	}
 }
 
+// streamItems hands each of the items to the collection consumer,
+// serializing them as they're produced.
+func (sc *Collection) streamItems(
+	ctx context.Context,
+	ids []string,
+	produce data.ProduceFunc,
+	progress chan<- struct{},
+	errs *fault.Errors,
+) (numMetrics, error) {
+	results := data.RunPipeline(
+		ctx,
+		sc.data,
+		ids,
+		nil,
+		produce,
+		data.PipelineOptions{
+			Concurrency:  fetchChannelSize,
+			ErrorCode:    graph.ErrorCode,
+			FinalRetries: data.DefaultFinalRetries,
+			Progress:     progress,
+			Folder:       sc.fullPath.Folder(false),
+		}.Tuned(sc.ctrl.Tuning),
+		errs)
+
+	metrics := numMetrics{
+		attempts:   results.Objects,
+		success:    results.Successes,
+		totalBytes: results.Bytes,
+	}
+
+	return metrics, results.Err
+}
+
 // retrieveLists utility function for collection that downloads and serializes
 // models.Listable objects based on M365 IDs from the jobs field.
 func (sc *Collection) retrieveLists(
 	ctx context.Context,
-	wtr *kw.JsonSerializationWriter,
 	progress chan<- struct{},
 	errs *fault.Errors,
 ) (numMetrics, error) {
-	var (
-		metrics numMetrics
-		et      = errs.Tracker()
-	)
-
 	lists, err := loadSiteLists(ctx, sc.service, sc.fullPath.ResourceOwner(), sc.jobs, errs)
 	if err != nil {
-		return metrics, err
+		return numMetrics{}, err
 	}
 
-	metrics.attempts += len(lists)
-	// For each models.Listable, object is serialized and the metrics are collected.
-	// The progress is objected via the passed in channel.
+	sc.addListViews(ctx, lists)
+
+	byID := make(map[string]models.Listable, len(lists))
 	for _, lst := range lists {
-		if et.Err() != nil {
-			break
-		}
+		byID[ptr.Val(lst.GetId())] = lst
+	}
 
-		byteArray, err := serializeContent(wtr, lst)
+	produce := func(ctx context.Context, id string, emit func(data.Stream)) (data.ItemResult, error) {
+		lst := byID[id]
+
+		byteArray, err := serializeContent(kw.NewJsonSerializationWriter(), lst)
 		if err != nil {
-			et.Add(clues.Wrap(err, "serializing list").WithClues(ctx))
-			continue
+			return data.ItemResult{}, clues.Wrap(err, "serializing list")
 		}
 
 		size := int64(len(byteArray))
 
-		if size > 0 {
-			t := time.Now()
-			if t1 := lst.GetLastModifiedDateTime(); t1 != nil {
-				t = *t1
-			}
+		emit(&Item{
+			id:      id,
+			data:    io.NopCloser(bytes.NewReader(byteArray)),
+			info:    sharePointListInfo(lst, size),
+			modTime: ptr.OrNow(lst.GetLastModifiedDateTime()),
+		})
 
-			metrics.totalBytes += size
+		return data.ItemResult{Bytes: size}, nil
+	}
 
-			metrics.success++
-			sc.data <- &Item{
-				id:      *lst.GetId(),
-				data:    io.NopCloser(bytes.NewReader(byteArray)),
-				info:    sharePointListInfo(lst, size),
-				modTime: t,
-			}
+	return sc.streamItems(ctx, maps.Keys(byID), produce, progress, errs)
+}
 
-			progress <- struct{}{}
-		}
+// addListViews folds each list's views into the list.  Views supplement
+// the list, so failing to retrieve them is logged instead of failing the
+// list's backup.
+func (sc *Collection) addListViews(ctx context.Context, lists []models.Listable) {
+	if sc.views == nil || len(lists) == 0 {
+		return
 	}
 
-	return metrics, et.Err()
+	siteURL, err := siteWebURL(ctx, sc.service, sc.fullPath.ResourceOwner())
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("getting site url for list views", clues.InErr(err).Slice()...)
+		return
+	}
+
+	for _, lst := range lists {
+		lctx := clues.Add(ctx, "list_id", ptr.Val(lst.GetId()))
+
+		views, err := sc.views.GetListViews(lctx, siteURL, ptr.Val(lst.GetId()))
+		if err == nil {
+			err = setListViews(lst, views)
+		}
+
+		if err != nil {
+			logger.Ctx(lctx).With("err", err).Errorw("backing up list views", clues.InErr(err).Slice()...)
+		}
+	}
 }
 
 func (sc *Collection) retrievePages(
 	ctx context.Context,
-	wtr *kw.JsonSerializationWriter,
 	progress chan<- struct{},
 	errs *fault.Errors,
 ) (numMetrics, error) {
-	var (
-		metrics numMetrics
-		et      = errs.Tracker()
-	)
-
 	betaService := sc.betaService
 	if betaService == nil {
-		return metrics, clues.New("beta service required").WithClues(ctx)
+		return numMetrics{}, clues.New("beta service required").WithClues(ctx)
 	}
 
 	parent, err := sapi.GetSite(ctx, sc.service, sc.fullPath.ResourceOwner())
 	if err != nil {
-		return metrics, err
+		return numMetrics{}, err
 	}
 
 	root := ptr.Val(parent.GetWebUrl())
 
 	pages, err := sapi.GetSitePages(ctx, betaService, sc.fullPath.ResourceOwner(), sc.jobs, errs)
 	if err != nil {
-		return metrics, err
+		return numMetrics{}, err
+	}
+
+	byID := make(map[string]betamodels.SitePageable, len(pages))
+	for _, pg := range pages {
+		byID[ptr.Val(pg.GetId())] = pg
 	}
 
-	metrics.attempts = len(pages)
-	// For each models.Pageable, object is serialize and the metrics are collected and returned.
 	// Pageable objects are not supported in v1.0 of msgraph at this time.
 	// TODO: Verify Parsable interface supported with modified-Pageable
-	for _, pg := range pages {
-		if et.Err() != nil {
-			break
-		}
+	produce := func(ctx context.Context, id string, emit func(data.Stream)) (data.ItemResult, error) {
+		pg := byID[id]
 
-		byteArray, err := serializeContent(wtr, pg)
+		byteArray, err := serializeContent(kw.NewJsonSerializationWriter(), pg)
 		if err != nil {
-			et.Add(clues.Wrap(err, "serializing page").WithClues(ctx))
-			continue
+			return data.ItemResult{}, clues.Wrap(err, "serializing page")
 		}
 
 		size := int64(len(byteArray))
 
-		if size > 0 {
-			metrics.totalBytes += size
-			metrics.success++
-			sc.data <- &Item{
-				id:      *pg.GetId(),
-				data:    io.NopCloser(bytes.NewReader(byteArray)),
-				info:    sharePointPageInfo(pg, root, size),
-				modTime: ptr.OrNow(pg.GetLastModifiedDateTime()),
-			}
-
-			progress <- struct{}{}
-		}
+		emit(&Item{
+			id:      id,
+			data:    io.NopCloser(bytes.NewReader(byteArray)),
+			info:    sharePointPageInfo(pg, root, size),
+			modTime: ptr.OrNow(pg.GetLastModifiedDateTime()),
+		})
+
+		return data.ItemResult{Bytes: size}, nil
 	}
 
-	return metrics, et.Err()
+	return sc.streamItems(ctx, maps.Keys(byID), produce, progress, errs)
 }
 
 func serializeContent(writer *kw.JsonSerializationWriter, obj absser.Parsable) ([]byte, error) {
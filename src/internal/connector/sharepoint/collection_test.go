@@ -193,7 +193,9 @@ func (suite *SharePointCollectionSuite) TestListCollection_Restore() {
 
 	destName := "Corso_Restore_" + common.FormatNow(common.SimpleTimeTesting)
 
-	deets, err := restoreListItem(ctx, service, listData, suite.siteID, destName)
+	names := &listNamer{existing: map[string]string{}}
+
+	deets, err := restoreListItem(ctx, service, &listViewRestorer{}, names, listData, suite.siteID, destName)
 	assert.NoError(t, err)
 	t.Logf("List created: %s\n", deets.SharePoint.ItemName)
 
@@ -3,10 +3,14 @@ package sharepoint
 import (
 	"context"
 	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/pkg/errors"
 
 	"github.com/alcionai/clues"
+	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/discovery/api"
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/internal/connector/onedrive"
@@ -17,6 +21,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/filters"
 	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
@@ -67,8 +72,8 @@ func DataCollections(
 		case path.ListsCategory:
 			spcs, err = collectLists(
 				ctx,
+				creds,
 				serv,
-				creds.AzureTenantID,
 				site,
 				su,
 				ctrlOpts,
@@ -86,8 +91,11 @@ func DataCollections(
 				creds.AzureTenantID,
 				site,
 				scope,
+				b.FilterScopes(),
+				b.ExcludeScopes(),
 				su,
-				ctrlOpts)
+				ctrlOpts,
+				errs)
 			if err != nil {
 				et.Add(err)
 				continue
@@ -117,13 +125,14 @@ func DataCollections(
 
 func collectLists(
 	ctx context.Context,
+	creds account.M365Config,
 	serv graph.Servicer,
-	tenantID, siteID string,
+	siteID string,
 	updater statusUpdater,
 	ctrlOpts control.Options,
 	errs *fault.Errors,
 ) ([]data.BackupCollection, error) {
-	logger.Ctx(ctx).With("site", siteID).Debug("Creating SharePoint List Collections")
+	logger.Ctx(ctx).With("site", logger.PII(siteID)).Debug("Creating SharePoint List Collections")
 
 	var (
 		et   = errs.Tracker()
@@ -135,6 +144,15 @@ func collectLists(
 		return nil, err
 	}
 
+	// views are supplementary, so credentials that can't read them only
+	// leave them out of the backup.
+	views, err := sapi.NewViewService(creds)
+	if errors.Is(err, graph.ErrSharePointRESTAuth) {
+		logger.Ctx(ctx).Warnw("list views not backed up", "reason", err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
 	for _, tuple := range lists {
 		if et.Err() != nil {
 			break
@@ -142,7 +160,7 @@ func collectLists(
 
 		dir, err := path.Builder{}.Append(tuple.name).
 			ToDataLayerSharePointPath(
-				tenantID,
+				creds.AzureTenantID,
 				siteID,
 				path.ListsCategory,
 				false)
@@ -151,6 +169,7 @@ func collectLists(
 		}
 
 		collection := NewCollection(dir, serv, List, updater.UpdateStatus, ctrlOpts)
+		collection.views = views
 		collection.AddJob(tuple.id)
 
 		spcs = append(spcs, collection)
@@ -160,31 +179,50 @@ func collectLists(
 }
 
 // collectLibraries constructs a onedrive Collections struct and Get()s
-// all the drives associated with the site.
+// all the drives associated with the site.  If permissions backup is
+// enabled, the site's access inventory is included in the libraries'
+// metadata collection.
 func collectLibraries(
 	ctx context.Context,
 	itemClient *http.Client,
 	serv graph.Servicer,
 	tenantID, siteID string,
 	scope selectors.SharePointScope,
+	filters, exclusions []selectors.SharePointScope,
 	updater statusUpdater,
 	ctrlOpts control.Options,
+	errs *fault.Errors,
 ) ([]data.BackupCollection, map[string]struct{}, error) {
 	logger.Ctx(ctx).Debug("creating SharePoint Library collections")
 
 	var (
 		collections = []data.BackupCollection{}
+		matcher     = newLibraryMatcher(scope)
 		colls       = onedrive.NewCollections(
 			itemClient,
 			tenantID,
 			siteID,
 			onedrive.SharePointSource,
-			folderMatcher{scope},
+			matcher,
 			serv,
 			updater.UpdateStatus,
 			ctrlOpts)
 	)
 
+	colls.ItemFilter = scopedItemFilter(scope, itemFilter(filters))
+	colls.ItemExclusion = itemExclusion(exclusions)
+
+	if ctrlOpts.EffectivePermissions().SharePoint.Backup {
+		// sharing links are recorded as the libraries are enumerated below.
+		// Libraries are always fully enumerated, since sharepoint backups
+		// don't use delta tokens, so every shared item is observed.
+		sac := newSiteAccessCollector(serv, errs)
+		sac.collectGrants(ctx, siteID)
+
+		colls.ItemObserver = sac.observe
+		colls.Metadata = append(colls.Metadata, graph.NewMetadataEntry(graph.SiteAccessFileName, &sac.access))
+	}
+
 	// TODO(ashmrtn): Pass previous backup metadata when SharePoint supports delta
 	// token-based incrementals.
 	odcs, excludes, err := colls.Get(ctx, nil)
@@ -215,7 +253,7 @@ func collectPages(
 
 	// make the betaClient
 	// Need to receive From DataCollection Call
-	adpt, err := graph.CreateAdapter(creds.AzureTenantID, creds.AzureClientID, creds.AzureClientSecret)
+	adpt, err := graph.CreateAdapter(creds)
 	if err != nil {
 		return nil, clues.Wrap(err, "creating azure client adapter")
 	}
@@ -252,6 +290,69 @@ func collectPages(
 	return spcs, et.Err()
 }
 
+// itemFilter produces a library item filter that matches the item's
+// creator, content type, and sensitivity label against the selector's
+// filter scopes.  Returns nil if the selector has no such scopes.
+func itemFilter(filters []selectors.SharePointScope) func(models.DriveItemable) bool {
+	ms := []onedrive.ItemMatcher{}
+
+	for _, f := range filters {
+		f := f
+
+		var prop onedrive.ItemProperty
+
+		switch f.FilterCategory() {
+		case selectors.SharePointFilterCreatedBy:
+			prop = onedrive.ItemCreatedBy
+		case selectors.SharePointFilterContentType:
+			prop = onedrive.ItemContentType
+		case selectors.SharePointFilterSensitivityLabel:
+			prop = onedrive.ItemSensitivityLabel
+		default:
+			continue
+		}
+
+		ms = append(ms, onedrive.ItemMatcher{
+			Property: prop,
+			Matches:  func(v string) bool { return f.Matches(f.FilterCategory(), v) },
+		})
+	}
+
+	return onedrive.ItemFilter(ms)
+}
+
+// scopedItemFilter restricts the filter to the library items selected by
+// the scope, for scopes that select specific items.
+func scopedItemFilter(
+	scope selectors.SharePointScope,
+	filter func(models.DriveItemable) bool,
+) func(models.DriveItemable) bool {
+	if scope.IsAny(selectors.SharePointLibraryItem) {
+		return filter
+	}
+
+	return onedrive.ScopedItemFilter(
+		func(id string) bool { return scope.Matches(selectors.SharePointLibraryItem, id) },
+		filter)
+}
+
+// itemExclusion produces a library item exclusion that matches the item's
+// name against the selector's name exclusion scopes, such as its
+// SyncArtifacts.  Returns nil if the selector has no such scopes.
+func itemExclusion(excludes []selectors.SharePointScope) func(models.DriveItemable) bool {
+	ms := []func(string) bool{}
+
+	for _, e := range excludes {
+		e := e
+
+		if e.FilterCategory() == selectors.SharePointFilterItemName {
+			ms = append(ms, func(name string) bool { return e.Matches(selectors.SharePointFilterItemName, name) })
+		}
+	}
+
+	return onedrive.ItemNameExclusion(ms)
+}
+
 type folderMatcher struct {
 	scope selectors.SharePointScope
 }
@@ -263,3 +364,139 @@ func (fm folderMatcher) IsAny() bool {
 func (fm folderMatcher) Matches(dir string) bool {
 	return fm.scope.Matches(selectors.SharePointLibrary, dir)
 }
+
+type libraryMatcher interface {
+	IsAny() bool
+	Matches(string) bool
+}
+
+// newLibraryMatcher produces a libraryURLMatcher if the scope identifies
+// folders by their server-relative url, and a folderMatcher otherwise.
+func newLibraryMatcher(scope selectors.SharePointScope) libraryMatcher {
+	urls := scope.LibraryURLs()
+	if len(urls) == 0 {
+		return folderMatcher{scope}
+	}
+
+	return &libraryURLMatcher{urls: urls}
+}
+
+// libraryURLMatcher matches library folders identified by their
+// server-relative urls (ex: /sites/Eng/Shared Documents/Specs).  Each
+// url is resolved to a drive and the folder within that drive once the
+// site's drives are known.
+type libraryURLMatcher struct {
+	urls []string
+	// drive ID -> folder path prefixes within the drive
+	folders map[string][]string
+}
+
+func (m *libraryURLMatcher) IsAny() bool {
+	return false
+}
+
+// Matches is only used when the drive is unknown, in which case the
+// folder path alone can't be matched unambiguously.
+func (m *libraryURLMatcher) Matches(string) bool {
+	return false
+}
+
+// ResolveDrives maps each url to the drive whose web url is its
+// longest prefix.  Urls that don't belong to any of the drives are
+// ignored, since the scope may span multiple sites.
+func (m *libraryURLMatcher) ResolveDrives(ctx context.Context, drives []models.Driveable) error {
+	m.folders = map[string][]string{}
+
+	driveElems := make(map[string][]string, len(drives))
+
+	for _, d := range drives {
+		u, err := url.Parse(ptr.Val(d.GetWebUrl()))
+		if err != nil {
+			return clues.Wrap(err, "parsing drive url").WithClues(ctx).With("drive_id", ptr.Val(d.GetId()))
+		}
+
+		driveElems[ptr.Val(d.GetId())] = urlElements(u.Path)
+	}
+
+	for _, u := range m.urls {
+		elems := urlElements(u)
+		if unescaped, err := url.PathUnescape(u); err == nil {
+			elems = urlElements(unescaped)
+		}
+
+		var (
+			driveID string
+			longest int
+		)
+
+		for id, de := range driveElems {
+			if len(de) > longest && hasElemPrefix(elems, de) {
+				driveID, longest = id, len(de)
+			}
+		}
+
+		if len(driveID) == 0 {
+			logger.Ctx(ctx).Infow("no library found for url", "library_url", logger.PII(u))
+			continue
+		}
+
+		folder := path.Builder{}.Append(elems[longest:]...).String()
+		m.folders[driveID] = append(m.folders[driveID], folder)
+	}
+
+	return nil
+}
+
+func (m *libraryURLMatcher) IncludesDrive(driveID string) bool {
+	_, ok := m.folders[driveID]
+	return ok
+}
+
+// MatchesInDrive returns true if the folder is, or is contained by, one of
+// the folders resolved for the drive.
+func (m *libraryURLMatcher) MatchesInDrive(driveID, folder string) bool {
+	prefixes, ok := m.folders[driveID]
+	if !ok {
+		return false
+	}
+
+	for _, p := range prefixes {
+		if len(p) == 0 {
+			return true
+		}
+	}
+
+	if len(folder) == 0 {
+		return false
+	}
+
+	return filters.PathPrefix(prefixes).Compare(folder)
+}
+
+func urlElements(u string) []string {
+	elems := []string{}
+
+	for _, e := range strings.Split(u, "/") {
+		if len(e) > 0 {
+			elems = append(elems, e)
+		}
+	}
+
+	return elems
+}
+
+// hasElemPrefix returns true if the leading elements of elems
+// case-insensitively equal the prefix.
+func hasElemPrefix(elems, prefix []string) bool {
+	if len(prefix) > len(elems) {
+		return false
+	}
+
+	for i, p := range prefix {
+		if !strings.EqualFold(elems[i], p) {
+			return false
+		}
+	}
+
+	return true
+}
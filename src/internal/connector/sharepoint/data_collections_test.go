@@ -133,6 +133,72 @@ func (suite *SharePointLibrariesSuite) TestUpdateCollections() {
 	}
 }
 
+func (suite *SharePointLibrariesSuite) TestLibraryURLMatcher() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	drive := func(id, webURL string) models.Driveable {
+		d := models.NewDrive()
+		d.SetId(&id)
+		d.SetWebUrl(&webURL)
+
+		return d
+	}
+
+	drives := []models.Driveable{
+		drive("docs", "https://tenant.sharepoint.com/sites/Eng/Shared%20Documents"),
+		drive("specs", "https://tenant.sharepoint.com/sites/Eng/Specs"),
+		drive("other", "https://tenant.sharepoint.com/sites/Eng/Shared%20Documents%20Old"),
+	}
+
+	scope := (&selectors.SharePointBackup{}).LibraryFolderURLs([]string{
+		"/sites/Eng/Shared Documents/Specs",
+		"/sites/eng/specs/",
+		"/sites/Other/Shared%20Documents/Specs",
+	})[0]
+
+	m, ok := newLibraryMatcher(scope).(*libraryURLMatcher)
+	require.True(t, ok, "scope produces a url matcher")
+
+	err := m.ResolveDrives(ctx, drives)
+	require.NoError(t, err)
+
+	assert.False(t, m.IsAny())
+	assert.True(t, m.IncludesDrive("docs"))
+	assert.True(t, m.IncludesDrive("specs"))
+	assert.False(t, m.IncludesDrive("other"))
+
+	table := []struct {
+		name    string
+		driveID string
+		folder  string
+		expect  assert.BoolAssertionFunc
+	}{
+		{"folder", "docs", "Specs", assert.True},
+		{"subfolder", "docs", "Specs/Drafts", assert.True},
+		{"folder name prefix", "docs", "SpecsOld", assert.False},
+		{"drive root", "docs", "", assert.False},
+		{"other folder", "docs", "Plans", assert.False},
+		{"same folder, other drive", "other", "Specs", assert.False},
+		{"whole library", "specs", "", assert.True},
+		{"whole library, folder", "specs", "Anything", assert.True},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), m.MatchesInDrive(test.driveID, test.folder))
+		})
+	}
+}
+
+func (suite *SharePointLibrariesSuite) TestNewLibraryMatcher_folderScope() {
+	scope := (&selectors.SharePointBackup{}).Libraries(selectors.Any())[0]
+
+	_, ok := newLibraryMatcher(scope).(folderMatcher)
+	assert.True(suite.T(), ok, "scope produces a folder matcher")
+}
+
 func driveItem(name, parentPath, parentID string, isFile bool) models.DriveItemable {
 	item := models.NewDriveItem()
 	item.SetName(&name)
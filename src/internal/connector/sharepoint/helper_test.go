@@ -47,11 +47,7 @@ func (ms *MockGraphService) UpdateStatus(*support.ConnectorOperationStatus) {
 // ---------------------------------------------------------------------------
 
 func createTestService(t *testing.T, credentials account.M365Config) *graph.Service {
-	adapter, err := graph.CreateAdapter(
-		credentials.AzureTenantID,
-		credentials.AzureClientID,
-		credentials.AzureClientSecret,
-	)
+	adapter, err := graph.CreateAdapter(credentials)
 	require.NoError(t, err, "creating microsoft graph service for exchange")
 
 	return graph.NewService(adapter)
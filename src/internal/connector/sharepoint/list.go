@@ -2,6 +2,7 @@ package sharepoint
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 
 	"github.com/alcionai/clues"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/alcionai/corso/src/internal/common/ptr"
 	"github.com/alcionai/corso/src/internal/connector/graph"
+	gapi "github.com/alcionai/corso/src/internal/connector/graph/api"
+	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/pkg/fault"
 )
@@ -38,41 +41,56 @@ func preFetchLists(
 	siteID string,
 ) ([]listTuple, error) {
 	var (
-		builder    = gs.Client().SitesById(siteID).Lists()
-		options    = preFetchListOptions()
+		pager = &listPager{
+			gs:      gs,
+			builder: gs.Client().SitesById(siteID).Lists(),
+			options: preFetchListOptions(),
+		}
 		listTuples = make([]listTuple, 0)
 	)
 
-	for {
-		resp, err := builder.Get(ctx, options)
-		if err != nil {
-			return nil, clues.Wrap(err, "getting lists").WithClues(ctx).With(graph.ErrData(err)...)
-		}
-
-		for _, entry := range resp.GetValue() {
-			var (
-				id   = ptr.Val(entry.GetId())
-				name = ptr.Val(entry.GetDisplayName())
-				temp = listTuple{id: id, name: name}
-			)
+	lists, err := graph.GetAllValues[models.Listable](ctx, pager, graph.DefaultPagerOptions())
+	if err != nil {
+		return nil, clues.Wrap(err, "getting lists").WithClues(ctx).With(graph.ErrData(err)...)
+	}
 
-			if len(name) == 0 {
-				temp.name = id
-			}
+	for _, entry := range lists {
+		var (
+			id   = ptr.Val(entry.GetId())
+			name = ptr.Val(entry.GetDisplayName())
+			temp = listTuple{id: id, name: name}
+		)
 
-			listTuples = append(listTuples, temp)
+		if len(name) == 0 {
+			temp.name = id
 		}
 
-		if resp.GetOdataNextLink() == nil {
-			break
-		}
-
-		builder = mssite.NewItemListsRequestBuilder(ptr.Val(resp.GetOdataNextLink()), gs.Adapter())
+		listTuples = append(listTuples, temp)
 	}
 
 	return listTuples, nil
 }
 
+var _ graph.Pager[models.Listable] = &listPager{}
+
+type listPager struct {
+	gs      graph.Servicer
+	builder *mssite.ItemListsRequestBuilder
+	options *mssite.ItemListsRequestBuilderGetRequestConfiguration
+}
+
+func (p *listPager) GetPage(ctx context.Context) (gapi.PageLinker, error) {
+	return p.builder.Get(ctx, p.options)
+}
+
+func (p *listPager) SetNext(nextLink string) {
+	p.builder = mssite.NewItemListsRequestBuilder(nextLink, p.gs.Adapter())
+}
+
+func (p *listPager) ValuesIn(pl gapi.PageLinker) ([]models.Listable, error) {
+	return graph.PageValues[models.Listable](pl)
+}
+
 // list.go contains additional functions to help retrieve SharePoint List data from M365
 // SharePoint lists represent lists on a site. Inherits additional properties from
 // baseItem: https://learn.microsoft.com/en-us/graph/api/resources/baseitem?view=graph-rest-1.0
@@ -392,10 +410,74 @@ func DeleteList(
 	gs graph.Servicer,
 	siteID, listID string,
 ) error {
-	err := gs.Client().SitesById(siteID).ListsById(listID).Delete(ctx, nil)
+	return sapi.NewClientFromService(gs).Sites().DeleteList(ctx, siteID, listID)
+}
+
+// listViewsKey is the additional data property which carries a backed up
+// list's views, serialized as json.  Graph doesn't expose list views, so
+// they're folded into the list during backup and split back out during
+// restore.
+const listViewsKey = "@corso.views"
+
+// setListViews folds the views into the list's additional data.
+func setListViews(lst models.Listable, views []sapi.ListView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	bs, err := json.Marshal(views)
 	if err != nil {
-		return clues.Wrap(err, "deleting list").WithClues(ctx).With(graph.ErrData(err)...)
+		return clues.Wrap(err, "serializing list views")
 	}
 
+	ad := lst.GetAdditionalData()
+	if ad == nil {
+		ad = map[string]any{}
+	}
+
+	ad[listViewsKey] = string(bs)
+
+	lst.SetAdditionalData(ad)
+
 	return nil
 }
+
+// popListViews removes the views from the list's additional data, so that
+// the list can be posted to graph, and returns the views.
+func popListViews(lst models.Listable) ([]sapi.ListView, error) {
+	ad := lst.GetAdditionalData()
+
+	var enc string
+
+	switch v := ad[listViewsKey].(type) {
+	case string:
+		enc = v
+	case *string:
+		enc = ptr.Val(v)
+	}
+
+	delete(ad, listViewsKey)
+
+	if len(enc) == 0 {
+		return nil, nil
+	}
+
+	views := []sapi.ListView{}
+
+	if err := json.Unmarshal([]byte(enc), &views); err != nil {
+		return nil, clues.Wrap(err, "deserializing list views")
+	}
+
+	return views, nil
+}
+
+// siteWebURL retrieves the site's url, which roots the site's SharePoint
+// REST API.
+func siteWebURL(ctx context.Context, gs graph.Servicer, siteID string) (string, error) {
+	site, err := sapi.NewClientFromService(gs).Sites().GetSite(ctx, siteID)
+	if err != nil {
+		return "", err
+	}
+
+	return ptr.Val(site.GetWebUrl()), nil
+}
@@ -3,10 +3,14 @@ package sharepoint
 import (
 	"testing"
 
+	kw "github.com/microsoft/kiota-serialization-json-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	sapi "github.com/alcionai/corso/src/internal/connector/sharepoint/api"
+	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/fault"
@@ -62,3 +66,54 @@ func (suite *SharePointSuite) TestLoadList() {
 	assert.Greater(t, len(lists), 0)
 	t.Logf("Length: %d\n", len(lists))
 }
+
+type ListViewsUnitSuite struct {
+	tester.Suite
+}
+
+func TestListViewsUnitSuite(t *testing.T) {
+	suite.Run(t, &ListViewsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ListViewsUnitSuite) TestListViews_roundTrip() {
+	t := suite.T()
+
+	views := []sapi.ListView{
+		{
+			Title:    "All Items",
+			Fields:   []string{"LinkTitle", "Modified"},
+			Query:    `<OrderBy><FieldRef Name="Modified" Ascending="FALSE" /></OrderBy>`,
+			RowLimit: 30,
+			Default:  true,
+		},
+		{
+			Title:           "Open",
+			Fields:          []string{"LinkTitle", "Status"},
+			Query:           `<Where><Eq><FieldRef Name="Status" /><Value Type="Text">Open</Value></Eq></Where>`,
+			CustomFormatter: `{"rowFormatter":{}}`,
+		},
+	}
+
+	lst := mockconnector.GetMockListDefault("views")
+	require.NoError(t, setListViews(lst, views))
+
+	// views must survive the list's serialization into the backup.
+	writer := kw.NewJsonSerializationWriter()
+	require.NoError(t, writer.WriteObjectValue("", lst))
+
+	bs, err := writer.GetSerializedContent()
+	require.NoError(t, err)
+
+	restored, err := support.CreateListFromBytes(bs)
+	require.NoError(t, err)
+
+	result, err := popListViews(restored)
+	require.NoError(t, err)
+	assert.Equal(t, views, result)
+	assert.NotContains(t, restored.GetAdditionalData(), listViewsKey)
+
+	// lists without views produce no views.
+	result, err = popListViews(restored)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
@@ -16,7 +16,7 @@ import (
 func GetAllSitesForTenant(ctx context.Context, gs graph.Servicer) (absser.Parsable, error) {
 	options := &mssite.SitesRequestBuilderGetRequestConfiguration{
 		QueryParameters: &mssite.SitesRequestBuilderGetQueryParameters{
-			Select: []string{"id", "name", "weburl"},
+			Select: []string{"id", "name", "displayName", "weburl"},
 		},
 	}
 
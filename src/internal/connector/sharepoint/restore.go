@@ -2,9 +2,11 @@ package sharepoint
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"runtime/trace"
+	"sync"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -17,10 +19,12 @@ import (
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	D "github.com/alcionai/corso/src/internal/diagnostics"
+	"github.com/alcionai/corso/src/internal/opctx"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/path"
 )
 
@@ -35,8 +39,35 @@ import (
 // ----> restoreListItems() is called
 // Restored List can be found in the Site's `Site content` page
 // Restored Libraries can be found within the Site's `Pages` page
+// Data is restored into the site it was backed up from, unless the
+// destination overrides the resource owner with the ID of another site.
 //------------------------------------------
 
+// lazyFieldsWriter creates the sharepoint rest client on its first use, so
+// that restores without any column values don't depend on the client.
+type lazyFieldsWriter struct {
+	creds account.M365Config
+	once  sync.Once
+	vs    *api.ViewService
+	err   error
+}
+
+func (w *lazyFieldsWriter) UpdateListItemFields(
+	ctx context.Context,
+	siteURL, listID, itemID string,
+	fields map[string]any,
+) error {
+	w.once.Do(func() {
+		w.vs, w.err = api.NewViewService(w.creds)
+	})
+
+	if w.err != nil {
+		return clues.Wrap(w.err, "creating sharepoint rest client").WithClues(ctx)
+	}
+
+	return w.vs.UpdateListItemFields(ctx, siteURL, listID, itemID, fields)
+}
+
 // RestoreCollections will restore the specified data collections into OneDrive
 func RestoreCollections(
 	ctx context.Context,
@@ -44,6 +75,7 @@ func RestoreCollections(
 	creds account.M365Config,
 	service graph.Servicer,
 	dest control.RestoreDestination,
+	opts control.Options,
 	dcs []data.RestoreCollection,
 	deets *details.Builder,
 	errs *fault.Errors,
@@ -51,19 +83,36 @@ func RestoreCollections(
 	var (
 		err            error
 		restoreMetrics support.CollectionMetrics
+		restoreDrives  = onedrive.NewRestoreDrives(service, dest.ResourceOwnerOverride, dest.DriveName)
+		listNames      = map[string]*listNamer{}
 	)
 
+	// library files carry column values, which are written over the
+	// sharepoint rest api.
+	fieldsWriter := &lazyFieldsWriter{creds: creds}
+
 	// Iterate through the data collections and restore the contents of each
 	for _, dc := range dcs {
 		var (
 			category = dc.FullPath().Category()
+			siteID   = restoreSiteID(dest, dc.FullPath())
 			metrics  support.CollectionMetrics
-			ictx     = clues.Add(ctx,
-				"category", category,
-				"destination", dest.ContainerName, // TODO: pii
-				"resource_owner", dc.FullPath().ResourceOwner()) // TODO: pii
+			ictx     = clues.Add(
+				opctx.Set(ctx, opctx.Metadata{
+					ResourceOwner: dc.FullPath().ResourceOwner(),
+					Category:      category.String(),
+				}),
+				"destination", logger.PII(dest.ContainerName),
+				"restore_site_id", siteID)
 		)
 
+		// lists and pages are named after the destination, rather than
+		// restored into a container, so they can't be nested.
+		if category != path.LibrariesCategory && len(control.ContainerElements(dest.ContainerName)) > 1 {
+			err = clues.New("lists and pages can't be restored beneath other containers").WithClues(ictx)
+			break
+		}
+
 		switch dc.FullPath().Category() {
 		case path.LibrariesCategory:
 			metrics, _, _, err = onedrive.RestoreCollection(
@@ -74,16 +123,27 @@ func RestoreCollections(
 				map[string][]onedrive.UserPermission{}, // Currently permission data is not stored for sharepoint
 				onedrive.SharePointSource,
 				dest.ContainerName,
+				restoreDrives,
+				fieldsWriter,
 				deets,
 				map[string]string{},
 				false,
 				errs)
 		case path.ListsCategory:
+			names, ok := listNames[siteID]
+			if !ok {
+				names = &listNamer{service: service, siteID: siteID, policy: opts.Collision}
+				listNames[siteID] = names
+			}
+
 			metrics, err = RestoreListCollection(
 				ictx,
+				creds,
 				service,
 				dc,
+				siteID,
 				dest.ContainerName,
+				names,
 				deets,
 				errs)
 		case path.PagesCategory:
@@ -91,6 +151,7 @@ func RestoreCollections(
 				ictx,
 				creds,
 				dc,
+				siteID,
 				dest.ContainerName,
 				deets,
 				errs)
@@ -116,6 +177,16 @@ func RestoreCollections(
 	return status, err
 }
 
+// restoreSiteID returns the ID of the site into which the collection is
+// restored.
+func restoreSiteID(dest control.RestoreDestination, fullPath path.Path) string {
+	if len(dest.ResourceOwnerOverride) > 0 {
+		return dest.ResourceOwnerOverride
+	}
+
+	return fullPath.ResourceOwner()
+}
+
 // createRestoreFolders creates the restore folder hieararchy in the specified drive and returns the folder ID
 // of the last folder entry given in the hiearchy
 func createRestoreFolders(
@@ -125,21 +196,25 @@ func createRestoreFolders(
 	restoreFolders []string,
 ) (string, error) {
 	// Get Main Drive for Site, Documents
-	mainDrive, err := service.Client().SitesById(siteID).Drive().Get(ctx, nil)
+	mainDrive, err := api.NewClientFromService(service).Sites().GetDefaultDrive(ctx, siteID)
 	if err != nil {
-		return "", clues.Wrap(err, "getting site drive root").WithClues(ctx).With(graph.ErrData(err)...)
+		return "", err
 	}
 
-	return onedrive.CreateRestoreFolders(ctx, service, *mainDrive.GetId(), restoreFolders)
+	return onedrive.CreateRestoreFolders(ctx, service, ptr.Val(mainDrive.GetId()), restoreFolders)
 }
 
 // restoreListItem utility function restores a List to the siteID.
 // The name is changed to to Corso_Restore_{timeStame}_name
+// Failing to restore the list's views produces both the restored list's
+// info and the error.
 // API Reference: https://learn.microsoft.com/en-us/graph/api/list-create?view=graph-rest-1.0&tabs=http
 // Restored List can be verified within the Site contents.
 func restoreListItem(
 	ctx context.Context,
 	service graph.Servicer,
+	views *listViewRestorer,
+	names *listNamer,
 	itemData data.Stream,
 	siteID, destName string,
 ) (details.ItemInfo, error) {
@@ -167,8 +242,22 @@ func restoreListItem(
 		listName = *oldList.GetDisplayName()
 	}
 
+	listViews, err := popListViews(oldList)
+	if err != nil {
+		return dii, clues.Stack(err).WithClues(ctx)
+	}
+
+	newName, skip, err := names.name(ctx, fmt.Sprintf("%s_%s", destName, listName))
+	if err != nil {
+		return dii, clues.Stack(err).WithClues(ctx)
+	}
+
+	if skip {
+		logger.Ctx(ctx).Infow("skipping restore of existing list", "list_name", newName)
+		return dii, nil
+	}
+
 	var (
-		newName  = fmt.Sprintf("%s_%s", destName, listName)
 		newList  = support.ToListable(oldList, newName)
 		contents = make([]models.ListItemable, 0)
 	)
@@ -206,14 +295,24 @@ func restoreListItem(
 
 	dii.SharePoint = sharePointListInfo(restoredList, int64(len(byteArray)))
 
+	// views reference the list's columns, so they're restored last.  The
+	// list exists by now, so its info is returned alongside the error.
+	if err := views.restore(ctx, ptr.Val(restoredList.GetId()), listViews); err != nil {
+		return dii, clues.Wrap(err, "restoring list views").
+			With("restored_list_id", ptr.Val(restoredList.GetId())).
+			WithClues(ctx)
+	}
+
 	return dii, nil
 }
 
 func RestoreListCollection(
 	ctx context.Context,
+	creds account.M365Config,
 	service graph.Servicer,
 	dc data.RestoreCollection,
-	restoreContainerName string,
+	siteID, restoreContainerName string,
+	names *listNamer,
 	deets *details.Builder,
 	errs *fault.Errors,
 ) (support.CollectionMetrics, error) {
@@ -223,13 +322,23 @@ func RestoreListCollection(
 	var (
 		metrics   = support.CollectionMetrics{}
 		directory = dc.FullPath()
-		siteID    = directory.ResourceOwner()
 		items     = dc.Items(ctx, errs)
 		et        = errs.Tracker()
 	)
 
 	trace.Log(ctx, "gc:sharepoint:restoreListCollection", directory.String())
 
+	// views are supplementary, so credentials that can't write them only
+	// leave them out of the restore.
+	vs, err := api.NewViewService(creds)
+	if errors.Is(err, graph.ErrSharePointRESTAuth) {
+		logger.Ctx(ctx).Warnw("list views not restored", "reason", err.Error())
+	} else if err != nil {
+		return metrics, err
+	}
+
+	views := &listViewRestorer{service: service, views: vs, siteID: siteID}
+
 	for {
 		if et.Err() != nil {
 			break
@@ -248,11 +357,24 @@ func RestoreListCollection(
 			itemInfo, err := restoreListItem(
 				ctx,
 				service,
+				views,
+				names,
 				itemData,
 				siteID,
 				restoreContainerName)
 			if err != nil {
 				et.Add(err)
+
+				// lists whose views failed are still recorded as restored, so
+				// that retries don't create them again.
+				if itemInfo.SharePoint == nil {
+					continue
+				}
+			}
+
+			// skipped lists already exist in the site.
+			if itemInfo.SharePoint == nil {
+				metrics.Successes++
 				continue
 			}
 
@@ -279,6 +401,105 @@ func RestoreListCollection(
 	return metrics, et.Err()
 }
 
+// listViewRestorer re-creates the views of restored lists.  The site's url
+// is looked up once, when the first list with views is restored.  Views
+// are skipped if the restorer has no view service.
+type listViewRestorer struct {
+	service graph.Servicer
+	views   *api.ViewService
+	siteID  string
+	siteURL string
+}
+
+func (lvr *listViewRestorer) restore(ctx context.Context, listID string, views []api.ListView) error {
+	if len(views) == 0 || lvr.views == nil {
+		return nil
+	}
+
+	if len(lvr.siteURL) == 0 {
+		u, err := siteWebURL(ctx, lvr.service, lvr.siteID)
+		if err != nil {
+			return err
+		}
+
+		lvr.siteURL = u
+	}
+
+	return lvr.views.RestoreListViews(ctx, lvr.siteURL, listID, views)
+}
+
+// listNamer resolves collisions between the names of restored lists and
+// the lists that already exist in the site, as directed by the collision
+// policy.  The site's list names are looked up once, when the first list
+// is restored.
+type listNamer struct {
+	service graph.Servicer
+	siteID  string
+	policy  control.CollisionPolicy
+	// list name -> list ID
+	existing map[string]string
+}
+
+// name produces the name under which a list gets restored.  Returns true
+// if the list shouldn't be restored at all.
+func (ln *listNamer) name(ctx context.Context, want string) (string, bool, error) {
+	if ln.existing == nil {
+		lts, err := preFetchLists(ctx, ln.service, ln.siteID)
+		if err != nil {
+			return "", false, clues.Wrap(err, "listing existing site lists")
+		}
+
+		ln.existing = make(map[string]string, len(lts))
+
+		for _, lt := range lts {
+			ln.existing[lt.name] = lt.id
+		}
+	}
+
+	id, exists := ln.existing[want]
+	if !exists {
+		ln.existing[want] = ""
+		return want, false, nil
+	}
+
+	switch ln.policy {
+	case control.Skip:
+		return want, true, nil
+
+	case control.Replace:
+		// lists restored earlier in this run have no ID, and don't get replaced.
+		if len(id) > 0 {
+			if err := DeleteList(ctx, ln.service, ln.siteID, id); err != nil {
+				return "", false, clues.Wrap(err, "replacing existing list")
+			}
+
+			ln.existing[want] = ""
+
+			return want, false, nil
+		}
+	}
+
+	name := uniqueName(want, ln.existing)
+	ln.existing[name] = ""
+
+	return name, false, nil
+}
+
+// uniqueName appends a counter to the name, if needed, to produce a name
+// that isn't already taken.
+func uniqueName(name string, taken map[string]string) string {
+	if _, ok := taken[name]; !ok {
+		return name
+	}
+
+	for i := 1; ; i++ {
+		n := fmt.Sprintf("%s (%d)", name, i)
+		if _, ok := taken[n]; !ok {
+			return n
+		}
+	}
+}
+
 // RestorePageCollection handles restoration of an individual site page collection.
 // returns:
 // - the collection's item and byte count metrics
@@ -287,14 +508,13 @@ func RestorePageCollection(
 	ctx context.Context,
 	creds account.M365Config,
 	dc data.RestoreCollection,
-	restoreContainerName string,
+	siteID, restoreContainerName string,
 	deets *details.Builder,
 	errs *fault.Errors,
 ) (support.CollectionMetrics, error) {
 	var (
 		metrics   = support.CollectionMetrics{}
 		directory = dc.FullPath()
-		siteID    = directory.ResourceOwner()
 	)
 
 	trace.Log(ctx, "gc:sharepoint:restorePageCollection", directory.String())
@@ -302,7 +522,7 @@ func RestorePageCollection(
 
 	defer end()
 
-	adpt, err := graph.CreateAdapter(creds.AzureTenantID, creds.AzureClientID, creds.AzureClientSecret)
+	adpt, err := graph.CreateAdapter(creds)
 	if err != nil {
 		return metrics, clues.Wrap(err, "constructing graph client")
 	}
@@ -0,0 +1,76 @@
+package sharepoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type RestoreUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestoreUnitSuite) TestListNamer() {
+	table := []struct {
+		name       string
+		policy     control.CollisionPolicy
+		want       string
+		expect     string
+		expectSkip bool
+	}{
+		{"no collision", control.Copy, "Corso_Restore_Tasks", "Corso_Restore_Tasks", false},
+		{"copy", control.Copy, "Corso_Restore_Issues", "Corso_Restore_Issues (2)", false},
+		{"default policy", control.Unknown, "Corso_Restore_Issues", "Corso_Restore_Issues (2)", false},
+		{"skip", control.Skip, "Corso_Restore_Issues", "Corso_Restore_Issues", true},
+		// lists restored earlier in the run are copied instead of replaced.
+		{"replace restored", control.Replace, "Corso_Restore_Issues", "Corso_Restore_Issues (2)", false},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				ln = &listNamer{
+					policy: test.policy,
+					existing: map[string]string{
+						"Corso_Restore_Issues":     "",
+						"Corso_Restore_Issues (1)": "",
+					},
+				}
+			)
+
+			name, skip, err := ln.name(ctx, test.want)
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, name)
+			assert.Equal(t, test.expectSkip, skip)
+
+			// the restored name is taken by subsequent lists.
+			assert.Contains(t, ln.existing, name)
+		})
+	}
+}
+
+func (suite *RestoreUnitSuite) TestRestoreSiteID() {
+	t := suite.T()
+
+	p, err := path.Builder{}.Append("list-id").ToDataLayerSharePointPath("t", "source-site", path.ListsCategory, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "source-site", restoreSiteID(control.RestoreDestination{}, p))
+	assert.Equal(
+		t,
+		"dest-site",
+		restoreSiteID(control.RestoreDestination{ResourceOwnerOverride: "dest-site"}, p))
+}
@@ -88,6 +88,30 @@ func CreatePageFromBytes(bytes []byte) (bmodels.SitePageable, error) {
 	return page, nil
 }
 
+// CreateTaskFromBytes transforms given bytes into models.TodoTaskable object
+func CreateTaskFromBytes(bytes []byte) (models.TodoTaskable, error) {
+	parsable, err := CreateFromBytes(bytes, models.CreateTodoTaskFromDiscriminatorValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "deserializing bytes to exchange task")
+	}
+
+	task := parsable.(models.TodoTaskable)
+
+	return task, nil
+}
+
+// CreateMailboxSettingsFromBytes transforms given bytes into models.MailboxSettingsable object
+func CreateMailboxSettingsFromBytes(bytes []byte) (models.MailboxSettingsable, error) {
+	parsable, err := CreateFromBytes(bytes, models.CreateMailboxSettingsFromDiscriminatorValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "deserializing bytes to mailbox settings")
+	}
+
+	settings := parsable.(models.MailboxSettingsable)
+
+	return settings, nil
+}
+
 func HasAttachments(body models.ItemBodyable) bool {
 	if body.GetContent() == nil || body.GetContentType() == nil ||
 		*body.GetContentType() == models.TEXT_BODYTYPE || len(*body.GetContent()) == 0 {
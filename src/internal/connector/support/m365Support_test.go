@@ -288,3 +288,113 @@ func (suite *DataSupportSuite) TestHasAttachments() {
 		})
 	}
 }
+
+func (suite *DataSupportSuite) TestCreateTaskFromBytes() {
+	tests := []struct {
+		name       string
+		checkError assert.ErrorAssertionFunc
+		isNil      assert.ValueAssertionFunc
+		getBytes   func(t *testing.T) []byte
+	}{
+		{
+			empty,
+			assert.Error,
+			assert.Nil,
+			func(t *testing.T) []byte {
+				return make([]byte, 0)
+			},
+		},
+		{
+			invalid,
+			assert.Error,
+			assert.Nil,
+			func(t *testing.T) []byte {
+				return []byte("snarf")
+			},
+		},
+		{
+			"Valid Task",
+			assert.NoError,
+			assert.NotNil,
+			func(t *testing.T) []byte {
+				task := models.NewTodoTask()
+				title := "Tested"
+				task.SetTitle(&title)
+
+				writer := kioser.NewJsonSerializationWriter()
+				err := task.Serialize(writer)
+				require.NoError(t, err)
+
+				byteArray, err := writer.GetSerializedContent()
+				require.NoError(t, err)
+
+				return byteArray
+			},
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			result, err := CreateTaskFromBytes(test.getBytes(t))
+			test.checkError(t, err)
+			test.isNil(t, result)
+		})
+	}
+}
+
+func (suite *DataSupportSuite) TestCreateMailboxSettingsFromBytes() {
+	tests := []struct {
+		name       string
+		checkError assert.ErrorAssertionFunc
+		isNil      assert.ValueAssertionFunc
+		getBytes   func(t *testing.T) []byte
+	}{
+		{
+			empty,
+			assert.Error,
+			assert.Nil,
+			func(t *testing.T) []byte {
+				return make([]byte, 0)
+			},
+		},
+		{
+			invalid,
+			assert.Error,
+			assert.Nil,
+			func(t *testing.T) []byte {
+				return []byte("snarf")
+			},
+		},
+		{
+			"Valid Settings",
+			assert.NoError,
+			assert.NotNil,
+			func(t *testing.T) []byte {
+				settings := models.NewMailboxSettings()
+				zone := "UTC"
+				settings.SetTimeZone(&zone)
+
+				writer := kioser.NewJsonSerializationWriter()
+				err := settings.Serialize(writer)
+				require.NoError(t, err)
+
+				byteArray, err := writer.GetSerializedContent()
+				require.NoError(t, err)
+
+				return byteArray
+			},
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			result, err := CreateMailboxSettingsFromBytes(test.getBytes(t))
+			test.checkError(t, err)
+			test.isNil(t, result)
+		})
+	}
+}
@@ -88,6 +88,26 @@ func ToEventSimplified(orig models.Eventable) models.Eventable {
 	return orig
 }
 
+// ToTodoTask transforms a task into its restore format.  Only the
+// writable fields are copied; ids, ownership, and the timestamps
+// maintained by the service are left for the service to set.
+func ToTodoTask(orig models.TodoTaskable) models.TodoTaskable {
+	task := models.NewTodoTask()
+	task.SetTitle(orig.GetTitle())
+	task.SetBody(orig.GetBody())
+	task.SetCategories(orig.GetCategories())
+	task.SetCompletedDateTime(orig.GetCompletedDateTime())
+	task.SetDueDateTime(orig.GetDueDateTime())
+	task.SetStartDateTime(orig.GetStartDateTime())
+	task.SetReminderDateTime(orig.GetReminderDateTime())
+	task.SetIsReminderOn(orig.GetIsReminderOn())
+	task.SetImportance(orig.GetImportance())
+	task.SetRecurrence(orig.GetRecurrence())
+	task.SetStatus(orig.GetStatus())
+
+	return task
+}
+
 type getContenter interface {
 	GetContent() *string
 	GetContentType() *models.BodyType
@@ -8,6 +8,7 @@ import (
 	"github.com/alcionai/clues"
 	"gopkg.in/resty.v1"
 
+	"github.com/alcionai/corso/src/internal/bandwidth"
 	"github.com/alcionai/corso/src/pkg/logger"
 )
 
@@ -21,6 +22,9 @@ const (
 // Writer implements an io.Writer for a M365
 // UploadSession URL
 type writer struct {
+	// ctx carries the bandwidth budget of the operation that writes, and
+	// ends its uploads with the operation.
+	ctx context.Context
 	// Identifier
 	id string
 	// Upload URL for this item
@@ -32,22 +36,31 @@ type writer struct {
 	client            *resty.Client
 }
 
-func NewWriter(id, url string, size int64) *writer {
-	return &writer{id: id, url: url, contentLength: size, client: resty.New()}
+func NewWriter(ctx context.Context, id, url string, size int64) *writer {
+	return &writer{ctx: ctx, id: id, url: url, contentLength: size, client: resty.New()}
 }
 
 // Write will upload the provided data to M365. It sets the `Content-Length` and `Content-Range` headers based on
 // https://docs.microsoft.com/en-us/graph/api/driveitem-createuploadsession
 func (iw *writer) Write(p []byte) (int, error) {
 	rangeLength := len(p)
-	logger.Ctx(context.Background()).Debugf("WRITE for %s. Size:%d, Offset: %d, TotalSize: %d",
+	logger.Ctx(iw.ctx).Debugf("WRITE for %s. Size:%d, Offset: %d, TotalSize: %d",
 		iw.id, rangeLength, iw.lastWrittenOffset, iw.contentLength)
 
 	endOffset := iw.lastWrittenOffset + int64(rangeLength)
 
 	// PUT the request - set headers `Content-Range`to describe total size and `Content-Length` to describe size of
 	// data in the current request
+	// upload urls are pre-authenticated, and aren't sent through the graph
+	// middleware, so the chunk draws on the bandwidth budget here.  The
+	// whole chunk is drawn before it's sent, so that its Content-Length is
+	// preserved.
+	if err := bandwidth.Ctx(iw.ctx).WaitN(iw.ctx, rangeLength); err != nil {
+		return 0, clues.Wrap(err, "waiting on upload bandwidth").With("upload_id", iw.id)
+	}
+
 	_, err := iw.client.R().
+		SetContext(iw.ctx).
 		SetHeaders(map[string]string{
 			contentRangeHeaderKey: fmt.Sprintf(contentRangeHeaderValueFmt,
 				iw.lastWrittenOffset,
@@ -2,6 +2,7 @@ package uploadsession
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +27,9 @@ func TestUploadSessionSuite(t *testing.T) {
 }
 
 func (suite *UploadSessionSuite) TestWriter() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
 	t := suite.T()
 
 	// Initialize a 100KB mockDataProvider
@@ -64,7 +68,7 @@ func (suite *UploadSessionSuite) TestWriter() {
 	}))
 	defer ts.Close()
 
-	writer := NewWriter("item", ts.URL, writeSize)
+	writer := NewWriter(ctx, "item", ts.URL, writeSize)
 
 	// Using a 32 KB buffer for the copy allows us to validate the
 	// multi-part upload. `io.CopyBuffer` will only write 32 KB at
@@ -76,6 +80,22 @@ func (suite *UploadSessionSuite) TestWriter() {
 	require.Equal(suite.T(), writeSize, size)
 }
 
+func (suite *UploadSessionSuite) TestWriter_context() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err := NewWriter(cctx, "item", ts.URL, 10).Write([]byte("data"))
+	assert.ErrorIs(t, err, context.Canceled, "uploads end with the operation's context")
+}
+
 func mockDataReader(size int64) (io.Reader, int64) {
 	data := bytes.Repeat([]byte("D"), int(size))
 	return &mockReader{r: bytes.NewReader(data)}, size
@@ -0,0 +1,504 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/spatialcurrent/go-lazy/pkg/lazy"
+
+	"github.com/alcionai/corso/src/internal/fairshare"
+	"github.com/alcionai/corso/src/internal/memlimit"
+	"github.com/alcionai/corso/src/internal/stall"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// ------------------------------------------------------------------------------------------------
+// collection pipeline
+// ------------------------------------------------------------------------------------------------
+
+// ProduceFunc retrieves and serializes the item with the given ID, handing
+// the item's streams to emit.  Most items emit a single stream, but an item
+// may emit more than one (ex: a file and its metadata), or none at all.
+// Streams should only be emitted once the item can no longer fail, since
+// failed items may be produced again.
+//
+// The result is returned even when the item fails, so that the item still
+// gets counted, or not, as the result describes.
+type ProduceFunc func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error)
+
+// ItemResult describes an item handled by a ProduceFunc.
+type ItemResult struct {
+	// Bytes is the size of the item's data.
+	Bytes int64
+	// Uncounted items, such as folders, are produced like any other
+	// item, but are excluded from the pipeline's object counts.
+	Uncounted bool
+	// Lazy items retrieve their data when their streams are read, rather
+	// than when they're produced.  Their retrieval time, and their share of
+	// the folder's throughput, are recorded by the producer instead of the
+	// pipeline.
+	Lazy bool
+}
+
+// PipelineOptions configures the concurrency, retry, and progress
+// behavior of a pipeline.
+type PipelineOptions struct {
+	// Concurrency is the maximum number of items produced at once.
+	// Defaults to 1.
+	Concurrency int
+	// MaxRetries is the number of times a failed item gets produced
+	// again.  Zero disables retries.
+	MaxRetries int
+	// NewBackOff produces the delay strategy used between retries.
+	// Defaults to an exponential backoff.
+	NewBackOff func() backoff.BackOff
+	// IsRetriable identifies item errors that should be retried.
+	// Defaults to retrying all errors.
+	IsRetriable func(error) bool
+	// IsSkippable identifies item errors that can't be corrected by
+	// a retry, and which are counted as a success instead of being
+	// reported (ex: items deleted after they were enumerated).
+	IsSkippable func(error) bool
+	// ErrorCode extracts the service's error code (ex: graph's
+	// "itemNotFound") from item errors, to record with their failures.
+	// Codes are omitted if unset.
+	ErrorCode func(error) string
+	// FinalRetries is the most items that, after failing all of their
+	// retries, get produced once more after every other item is handled.
+	// Failures early in a run are often transient, so that final pass
+	// recovers items that would otherwise fail the run.  Items beyond the
+	// limit are reported as failures.  Zero disables the final pass, which
+	// never runs in failFast mode.
+	FinalRetries int
+	// Progress, if populated, receives a signal each time an item
+	// is produced.
+	Progress chan<- struct{}
+	// Folder names the collection in the performance stats recorded by
+	// the pipeline.
+	Folder string
+	// Class names the share of the ctx's fair scheduler, if there is one,
+	// that the pipeline's items draw on (ex: the collection's category).
+	// Items without a class aren't scheduled.
+	Class string
+}
+
+// DefaultFinalRetries bounds the final retry pass of service collections.
+const DefaultFinalRetries = 100
+
+// Tuned overrides the options with any values set in the tuning options.
+func (po PipelineOptions) Tuned(to control.TuningOptions) PipelineOptions {
+	if to.ItemConcurrency > 0 {
+		po.Concurrency = to.ItemConcurrency
+	}
+
+	if to.ItemRetries > 0 {
+		po.MaxRetries = to.ItemRetries
+	}
+
+	if to.RetryDelay > 0 {
+		delay := to.RetryDelay
+
+		po.NewBackOff = func() backoff.BackOff {
+			bo := backoff.NewExponentialBackOff()
+			bo.InitialInterval = delay
+
+			return bo
+		}
+	}
+
+	return po
+}
+
+// PipelineResults aggregates the counts of the items handled by a pipeline.
+type PipelineResults struct {
+	// Objects is the count of all counted items, including those that failed.
+	Objects int
+	// Successes is the count of counted items that were produced or skipped.
+	Successes int
+	// Bytes is the total size of all produced items.
+	Bytes int64
+	// Err is the item failure that halted the pipeline, if any.  Always
+	// nil when errs is set to bestEffort handling.
+	Err error
+}
+
+// RunPipeline produces each of the added items, along with a tombstone for
+// each of the removed items, into the out channel.  The pipeline handles the
+// concerns shared by every service's collections: items are produced
+// concurrently and throttled by the ctx's memory limiter, failures are
+// retried, progress is reported, and item errors are added to errs as
+// recoverable errors.  New items stop getting produced once one of them
+// fails in failFast mode.  Services only need to provide the item IDs and a ProduceFunc.
+//
+// Items that still fail after their retries get a final attempt, as bounded
+// by opts.FinalRetries, once all other items are handled.  The final pass
+// produces items with half the concurrency, and producers can use
+// IsFinalRetry to refresh any state (ex: download urls) that may have
+// caused the failure.
+//
+// The time taken to produce each item, and the collection as a whole, is
+// recorded in the ctx's performance recorder, if there is one.  The ctx's
+// stall watchdog, if there is one, tracks the collection's progress, and
+// the collection fails with stall.ErrStalled if the watchdog aborts it.
+// The ctx's fair scheduler, if there is one, shares item retrieval between
+// the pipelines of different classes.
+//
+// Blocks until all items are handled.  The out channel is left open.
+func RunPipeline(
+	ctx context.Context,
+	out chan<- Stream,
+	added, removed []string,
+	produce ProduceFunc,
+	opts PipelineOptions,
+	errs *fault.Errors,
+) PipelineResults {
+	var (
+		objects, successes int64
+		totalBytes         int64
+		// lazy items are timed when they're read, after the pipeline ends.
+		lazyItems, lazyBytes int64
+		et                   = errs.Tracker()
+		rec                  = stats.RecorderCtx(ctx)
+		start                = time.Now()
+	)
+
+	ctx, tracker := stall.Ctx(ctx).Track(ctx, opts.Folder)
+	defer tracker.Done()
+
+	success := func() {
+		atomic.AddInt64(&successes, 1)
+
+		if opts.Progress != nil {
+			opts.Progress <- struct{}{}
+		}
+	}
+
+	// removed items need nothing from the service, so their tombstones
+	// get handed off without going through the producer.
+	for _, id := range removed {
+		out <- Tombstone(id)
+
+		atomic.AddInt64(&objects, 1)
+		success()
+	}
+
+	var (
+		// items waiting on the consumer aren't stalled.
+		emit = func(s Stream) {
+			defer tracker.Wait()()
+			out <- s
+		}
+		deferred = []string{}
+		// the retries made in the first pass, by deferred item.
+		deferredRetries = map[string]int{}
+		dm              sync.Mutex
+	)
+
+	// produceItem handles a single item.  Failures in the first pass are
+	// deferred to the final pass while the final retry limit allows.
+	produceItem := func(ctx context.Context, id string, opts PipelineOptions, final bool) {
+		defer memlimit.Ctx(ctx).Throttle(ctx)()
+		defer tracker.Begin()()
+
+		var (
+			ictx     = clues.Add(ctx, "item_id", id)
+			produced = time.Now()
+		)
+
+		result, retries, err := produceWithRetries(ictx, id, produce, emit, opts)
+
+		if final {
+			dm.Lock()
+			retries += deferredRetries[id]
+			dm.Unlock()
+		}
+
+		// deferred items were already counted in the first pass.
+		if !final && !result.Uncounted {
+			atomic.AddInt64(&objects, 1)
+		}
+
+		if err != nil {
+			// Skippable errors are recorded as a "success", since there's nothing
+			// else we can do, and not reporting it will make the status
+			// investigation upset.
+			if opts.IsSkippable != nil && opts.IsSkippable(err) {
+				atomic.AddInt64(&successes, 1)
+				logger.Ctx(ictx).With("err", err).Infow("skipping item", clues.InErr(err).Slice()...)
+
+				errs.AddItem(ItemFailure(id, opts.Folder, opts.errorCode(err), err, retries, final, true))
+
+				return
+			}
+
+			if !final && !errs.FailFast() {
+				dm.Lock()
+				defer dm.Unlock()
+
+				if len(deferred) < opts.FinalRetries {
+					logger.Ctx(ictx).With("err", err).Debugw("deferring item to the final retry")
+					deferred = append(deferred, id)
+					deferredRetries[id] = retries
+
+					return
+				}
+			}
+
+			et.Add(clues.Stack(err).WithClues(ictx))
+			errs.AddItem(ItemFailure(id, opts.Folder, opts.errorCode(err), err, retries, final, false))
+
+			return
+		}
+
+		atomic.AddInt64(&totalBytes, result.Bytes)
+
+		if result.Lazy {
+			atomic.AddInt64(&lazyBytes, result.Bytes)
+
+			if !result.Uncounted {
+				atomic.AddInt64(&lazyItems, 1)
+			}
+		}
+
+		if !result.Uncounted && !result.Lazy {
+			rec.RecordItem(stats.ItemTiming{
+				Folder:   opts.Folder,
+				Item:     id,
+				ItemID:   id,
+				Duration: time.Since(produced),
+				Bytes:    result.Bytes,
+				Retries:  retries,
+			})
+		}
+
+		if !result.Uncounted {
+			success()
+		} else if opts.Progress != nil {
+			opts.Progress <- struct{}{}
+		}
+	}
+
+	runPass(ctx, added, opts.Concurrency, et, func(id string) {
+		produceItem(ctx, id, opts, false)
+	})
+
+	if len(deferred) > 0 {
+		logger.Ctx(ctx).Infow("retrying failed items", "count", len(deferred))
+
+		// a single, slower attempt at each item keeps the final pass bounded.
+		var (
+			fctx  = context.WithValue(ctx, finalRetryKey{}, true)
+			fopts = opts
+		)
+
+		fopts.MaxRetries = 0
+
+		runPass(fctx, deferred, opts.Concurrency/2, et, func(id string) {
+			produceItem(fctx, id, fopts, true)
+		})
+	}
+
+	if tracker.Aborted() {
+		et.Add(clues.Stack(stall.ErrStalled).WithClues(ctx))
+	}
+
+	if timed := successes - lazyItems; timed > 0 {
+		rec.RecordFolder(stats.FolderThroughput{
+			Folder:   opts.Folder,
+			Items:    int(timed),
+			Bytes:    totalBytes - lazyBytes,
+			Duration: time.Since(start),
+		})
+	}
+
+	return PipelineResults{
+		Objects:   int(objects),
+		Successes: int(successes),
+		Bytes:     totalBytes,
+		Err:       et.Err(),
+	}
+}
+
+func (po PipelineOptions) errorCode(err error) string {
+	if po.ErrorCode == nil {
+		return ""
+	}
+
+	return po.ErrorCode(err)
+}
+
+// ItemFailure records the failure of the item, along with the error code
+// reported by the service, if any.
+func ItemFailure(id, folder, code string, err error, retries int, final, skipped bool) fault.Item {
+	return fault.Item{
+		ID:         id,
+		Folder:     folder,
+		Cause:      err.Error(),
+		Code:       code,
+		Retries:    retries,
+		FinalRetry: final,
+		Skipped:    skipped,
+	}
+}
+
+// runPass calls produceItem for each of the ids, with at most concurrency
+// items in flight.  New items stop getting produced once the tracker
+// records a failure, or the ctx is canceled.  Blocks until all items are
+// handled.
+func runPass(
+	ctx context.Context,
+	ids []string,
+	concurrency int,
+	et interface{ Err() error },
+	produceItem func(id string),
+) {
+	var wg sync.WaitGroup
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	semaphoreCh := make(chan struct{}, concurrency)
+	defer close(semaphoreCh)
+
+	for _, id := range ids {
+		semaphoreCh <- struct{}{}
+
+		// checked after acquiring the semaphore, so that a failure
+		// in a running item is always seen before starting the next.
+		if et.Err() != nil || ctx.Err() != nil {
+			<-semaphoreCh
+			break
+		}
+
+		wg.Add(1)
+
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-semaphoreCh }()
+
+			produceItem(id)
+		}(id)
+	}
+
+	wg.Wait()
+}
+
+type finalRetryKey struct{}
+
+// IsFinalRetry returns true if the item is being produced in the final
+// retry pass of a pipeline.
+func IsFinalRetry(ctx context.Context) bool {
+	final, _ := ctx.Value(finalRetryKey{}).(bool)
+	return final
+}
+
+// produceWithRetries produces the item, retrying failures as configured
+// by the options.  Returns the number of retries that were made.
+func produceWithRetries(
+	ctx context.Context,
+	id string,
+	produce ProduceFunc,
+	emit func(Stream),
+	opts PipelineOptions,
+) (ItemResult, int, error) {
+	var bo backoff.BackOff
+
+	for attempt := 0; ; attempt++ {
+		// the slot is only held while the item is retrieved, since emitting
+		// waits on the consumer, which may be reading another collection.
+		release := fairshare.Ctx(ctx).Acquire(ctx, opts.Class)
+
+		result, err := produce(ctx, id, func(s Stream) {
+			release()
+			emit(s)
+		})
+
+		release()
+
+		if err == nil ||
+			attempt >= opts.MaxRetries ||
+			(opts.IsSkippable != nil && opts.IsSkippable(err)) ||
+			(opts.IsRetriable != nil && !opts.IsRetriable(err)) {
+			return result, attempt, err
+		}
+
+		if bo == nil {
+			bo = backoff.NewExponentialBackOff()
+			if opts.NewBackOff != nil {
+				bo = opts.NewBackOff()
+			}
+		}
+
+		wait := bo.NextBackOff()
+		if wait == backoff.Stop {
+			return result, attempt, err
+		}
+
+		logger.Ctx(ctx).Debugw("retrying item", "attempt", attempt+1, "wait", wait, "err", err)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, attempt, clues.Stack(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// streams
+// ------------------------------------------------------------------------------------------------
+
+var (
+	_ Stream        = &tombstone{}
+	_ StreamModTime = &tombstone{}
+)
+
+// tombstone marks an item as deleted from the collection.
+type tombstone struct {
+	id      string
+	modTime time.Time
+}
+
+// Tombstone produces a stream which marks the item as deleted.
+func Tombstone(id string) Stream {
+	return &tombstone{
+		id:      id,
+		modTime: time.Now().UTC(), // removed items have no modTime entry.
+	}
+}
+
+func (t *tombstone) UUID() string {
+	return t.id
+}
+
+func (t *tombstone) ToReader() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(nil))
+}
+
+func (t *tombstone) Deleted() bool {
+	return true
+}
+
+func (t *tombstone) ModTime() time.Time {
+	return t.modTime
+}
+
+// LazyReader produces a reader which doesn't call open until the first
+// read.  Consumers like kopia skip items whose modTimes are unchanged, so
+// lazy readers ensure those items never get downloaded.
+func LazyReader(open func() (io.ReadCloser, error)) io.ReadCloser {
+	return lazy.NewLazyReadCloser(open)
+}
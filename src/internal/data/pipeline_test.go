@@ -0,0 +1,555 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/fairshare"
+	"github.com/alcionai/corso/src/internal/stall"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type PipelineUnitSuite struct {
+	tester.Suite
+}
+
+func TestPipelineUnitSuite(t *testing.T) {
+	suite.Run(t, &PipelineUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+type pipelineStream struct {
+	id string
+}
+
+func (s pipelineStream) UUID() string            { return s.id }
+func (s pipelineStream) ToReader() io.ReadCloser { return io.NopCloser(nil) }
+func (s pipelineStream) Deleted() bool           { return false }
+
+var (
+	errPipelineFail = errors.New("fail")
+	errPipelineSkip = errors.New("skip")
+)
+
+// collect runs the pipeline, producing its results and the sorted
+// IDs of all streams that were handed off.
+func collect(
+	ctx context.Context,
+	added, removed []string,
+	produce ProduceFunc,
+	opts PipelineOptions,
+	errs *fault.Errors,
+) (PipelineResults, []string, []string) {
+	var (
+		out        = make(chan Stream)
+		ids        = []string{}
+		tombstones = []string{}
+		done       = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		for s := range out {
+			if s.Deleted() {
+				tombstones = append(tombstones, s.UUID())
+				continue
+			}
+
+			ids = append(ids, s.UUID())
+		}
+	}()
+
+	results := RunPipeline(ctx, out, added, removed, produce, opts, errs)
+	close(out)
+	<-done
+
+	sort.Strings(ids)
+	sort.Strings(tombstones)
+
+	return results, ids, tombstones
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline() {
+	var (
+		noBackOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+		isSkip    = func(err error) bool { return errors.Is(err, errPipelineSkip) }
+	)
+
+	table := []struct {
+		name          string
+		added         []string
+		removed       []string
+		produceErrs   map[string][]error
+		uncounted     map[string]bool
+		failFast      bool
+		maxRetries    int
+		finalRetries  int
+		expectIDs     []string
+		expectResults PipelineResults
+		expectErrs    int
+	}{
+		{
+			name:          "added and removed",
+			added:         []string{"a", "b"},
+			removed:       []string{"c"},
+			expectIDs:     []string{"a", "b"},
+			expectResults: PipelineResults{Objects: 3, Successes: 3, Bytes: 2},
+		},
+		{
+			name:          "uncounted",
+			added:         []string{"a", "dir"},
+			uncounted:     map[string]bool{"dir": true},
+			expectIDs:     []string{"a", "dir"},
+			expectResults: PipelineResults{Objects: 1, Successes: 1, Bytes: 2},
+		},
+		{
+			name:          "failure",
+			added:         []string{"a", "b"},
+			produceErrs:   map[string][]error{"b": {errPipelineFail}},
+			expectIDs:     []string{"a"},
+			expectResults: PipelineResults{Objects: 2, Successes: 1, Bytes: 1},
+			expectErrs:    1,
+		},
+		{
+			name:          "retried failure",
+			added:         []string{"a"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail, errPipelineFail}},
+			maxRetries:    2,
+			expectIDs:     []string{"a"},
+			expectResults: PipelineResults{Objects: 1, Successes: 1, Bytes: 1},
+		},
+		{
+			name:          "retries exhausted",
+			added:         []string{"a"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail, errPipelineFail}},
+			maxRetries:    1,
+			expectIDs:     []string{},
+			expectResults: PipelineResults{Objects: 1},
+			expectErrs:    1,
+		},
+		{
+			name:          "final retry",
+			added:         []string{"a", "b"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail, errPipelineFail}},
+			maxRetries:    1,
+			finalRetries:  1,
+			expectIDs:     []string{"a", "b"},
+			expectResults: PipelineResults{Objects: 2, Successes: 2, Bytes: 2},
+		},
+		{
+			name:          "final retry failure",
+			added:         []string{"a"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail, errPipelineFail}},
+			finalRetries:  1,
+			expectIDs:     []string{},
+			expectResults: PipelineResults{Objects: 1},
+			expectErrs:    1,
+		},
+		{
+			name:  "final retry limit",
+			added: []string{"a", "b"},
+			produceErrs: map[string][]error{
+				"a": {errPipelineFail},
+				"b": {errPipelineFail},
+			},
+			finalRetries:  1,
+			expectResults: PipelineResults{Objects: 2, Successes: 1, Bytes: 1},
+			expectErrs:    1,
+		},
+		{
+			name:          "skipped",
+			added:         []string{"a"},
+			produceErrs:   map[string][]error{"a": {errPipelineSkip}},
+			maxRetries:    3,
+			expectIDs:     []string{},
+			expectResults: PipelineResults{Objects: 1, Successes: 1},
+		},
+		{
+			name:          "fail fast",
+			added:         []string{"a", "b", "c"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail}},
+			failFast:      true,
+			expectIDs:     []string{},
+			expectResults: PipelineResults{Objects: 1, Err: errPipelineFail},
+			expectErrs:    1,
+		},
+		{
+			name:          "fail fast skips the final retry",
+			added:         []string{"a"},
+			produceErrs:   map[string][]error{"a": {errPipelineFail}},
+			failFast:      true,
+			finalRetries:  1,
+			expectIDs:     []string{},
+			expectResults: PipelineResults{Objects: 1, Err: errPipelineFail},
+			expectErrs:    1,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t     = suite.T()
+				errs  = fault.New(test.failFast)
+				mu    sync.Mutex
+				calls = map[string]int{}
+			)
+
+			produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+				mu.Lock()
+				call := calls[id]
+				calls[id]++
+				mu.Unlock()
+
+				result := ItemResult{Bytes: 1, Uncounted: test.uncounted[id]}
+
+				if call < len(test.produceErrs[id]) {
+					return result, test.produceErrs[id][call]
+				}
+
+				emit(pipelineStream{id: id})
+
+				return result, nil
+			}
+
+			opts := PipelineOptions{
+				MaxRetries:   test.maxRetries,
+				FinalRetries: test.finalRetries,
+				NewBackOff:   noBackOff,
+				IsSkippable:  isSkip,
+			}
+
+			results, ids, tombstones := collect(ctx, test.added, test.removed, produce, opts, errs)
+
+			// which item the final retry limit admits depends on ordering.
+			if test.expectIDs != nil {
+				assert.Equal(t, test.expectIDs, ids)
+			} else {
+				assert.Len(t, ids, test.expectResults.Successes)
+			}
+
+			assert.ElementsMatch(t, test.removed, tombstones)
+			assert.Equal(t, test.expectResults.Objects, results.Objects, "objects")
+			assert.Equal(t, test.expectResults.Successes, results.Successes, "successes")
+			assert.Equal(t, test.expectResults.Bytes, results.Bytes, "bytes")
+			assert.ErrorIs(t, results.Err, test.expectResults.Err)
+			assert.Len(t, errs.Errs(), test.expectErrs)
+		})
+	}
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_progress() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		progress = make(chan struct{}, 10)
+	)
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		emit(pipelineStream{id: id})
+		return ItemResult{Uncounted: id == "dir"}, nil
+	}
+
+	opts := PipelineOptions{
+		Concurrency: 3,
+		Progress:    progress,
+	}
+
+	_, _, _ = collect(ctx, []string{"a", "b", "dir"}, []string{"c"}, produce, opts, fault.New(true))
+	close(progress)
+
+	assert.Len(t, progress, 4)
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_isFinalRetry() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		final []bool
+	)
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		final = append(final, IsFinalRetry(ctx))
+
+		if len(final) == 1 {
+			return ItemResult{}, errPipelineFail
+		}
+
+		emit(pipelineStream{id: id})
+
+		return ItemResult{}, nil
+	}
+
+	opts := PipelineOptions{FinalRetries: 1}
+
+	results, _, _ := collect(ctx, []string{"a"}, nil, produce, opts, fault.New(false))
+
+	assert.Equal(t, []bool{false, true}, final)
+	assert.Equal(t, 1, results.Successes)
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_itemFailures() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		errs  = fault.New(false)
+		code  = "itemNotFound"
+		odErr = errors.New("item not found")
+	)
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		switch id {
+		case "fail":
+			return ItemResult{}, odErr
+		case "skip":
+			return ItemResult{}, errPipelineSkip
+		}
+
+		emit(pipelineStream{id: id})
+
+		return ItemResult{}, nil
+	}
+
+	opts := PipelineOptions{
+		MaxRetries:   1,
+		NewBackOff:   func() backoff.BackOff { return &backoff.ZeroBackOff{} },
+		FinalRetries: 1,
+		Folder:       "folder",
+		IsRetriable:  func(err error) bool { return !errors.Is(err, errPipelineSkip) },
+		IsSkippable:  func(err error) bool { return errors.Is(err, errPipelineSkip) },
+		ErrorCode: func(err error) string {
+			if errors.Is(err, odErr) {
+				return code
+			}
+
+			return ""
+		},
+	}
+
+	collect(ctx, []string{"fail", "ok", "skip"}, nil, produce, opts, errs)
+
+	items := errs.Items()
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	assert.Equal(
+		t,
+		[]fault.Item{
+			{
+				ID:         "fail",
+				Folder:     "folder",
+				Cause:      odErr.Error(),
+				Code:       code,
+				Retries:    1,
+				FinalRetry: true,
+			},
+			{
+				ID:      "skip",
+				Folder:  "folder",
+				Cause:   errPipelineSkip.Error(),
+				Skipped: true,
+			},
+		},
+		items)
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_performance() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		rec      = stats.NewPerformanceRecorder()
+		attempts = map[string]int{}
+		am       sync.Mutex
+	)
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		am.Lock()
+		attempts[id]++
+		n := attempts[id]
+		am.Unlock()
+
+		if id == "retried" && n == 1 {
+			return ItemResult{}, errPipelineFail
+		}
+
+		if id == "slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		emit(pipelineStream{id: id})
+
+		return ItemResult{Bytes: 10, Uncounted: id == "dir", Lazy: id == "lazy"}, nil
+	}
+
+	opts := PipelineOptions{
+		Concurrency: 2,
+		MaxRetries:  1,
+		NewBackOff:  func() backoff.BackOff { return &backoff.ZeroBackOff{} },
+		Folder:      "Inbox",
+	}
+
+	_, _, _ = collect(
+		stats.SetRecorder(ctx, rec),
+		[]string{"slow", "retried", "dir", "lazy"},
+		nil,
+		produce,
+		opts,
+		fault.New(true))
+
+	perf := rec.Performance()
+
+	// folders and lazy items aren't timed by the pipeline.
+	if assert.Len(t, perf.SlowestItems, 2) {
+		assert.Equal(t, "slow", perf.SlowestItems[0].Item)
+		assert.Equal(t, "Inbox", perf.SlowestItems[0].Folder)
+		assert.Equal(t, int64(10), perf.SlowestItems[0].Bytes)
+		assert.Equal(t, "retried", perf.SlowestItems[1].Item)
+		assert.Equal(t, 1, perf.SlowestItems[1].Retries)
+	}
+
+	// lazy items add to the folder's throughput when they're read.
+	if assert.Len(t, perf.Folders, 1) {
+		assert.Equal(t, "Inbox", perf.Folders[0].Folder)
+		assert.Equal(t, 2, perf.Folders[0].Items)
+		assert.Equal(t, int64(30), perf.Folders[0].Bytes)
+		assert.LessOrEqual(t, perf.SlowestItems[0].Duration, perf.Folders[0].Duration)
+	}
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_abortStalled() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	wd, err := stall.New(
+		mock.NewBus(),
+		control.HeartbeatOptions{StallAfter: 40 * time.Millisecond, AbortStalled: true})
+	require.NoError(t, err)
+
+	wd.Start(ctx)
+	defer wd.Stop()
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		if id == "stuck" {
+			<-ctx.Done()
+			return ItemResult{}, ctx.Err()
+		}
+
+		emit(pipelineStream{id: id})
+
+		return ItemResult{}, nil
+	}
+
+	errs := fault.New(false)
+
+	results, ids, _ := collect(
+		stall.Set(ctx, wd),
+		[]string{"a", "stuck"},
+		nil,
+		produce,
+		PipelineOptions{Concurrency: 2, Folder: "Inbox"},
+		errs)
+
+	assert.Equal(t, []string{"a"}, ids)
+	assert.Equal(t, 1, results.Successes)
+
+	var stalled bool
+
+	for _, err := range errs.Errs() {
+		stalled = stalled || errors.Is(err, stall.ErrStalled)
+	}
+
+	assert.True(t, stalled, "stall recorded")
+}
+
+func (suite *PipelineUnitSuite) TestRunPipeline_fairShare() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		errs = fault.New(true)
+		// a single slot is shared by both pipelines.
+		fctx = fairshare.Set(ctx, fairshare.New(1, nil))
+		// nothing reads the mail until the events are done.
+		mail = make(chan Stream)
+	)
+
+	produce := func(ctx context.Context, id string, emit func(Stream)) (ItemResult, error) {
+		emit(pipelineStream{id: id})
+		return ItemResult{}, nil
+	}
+
+	go RunPipeline(
+		fctx,
+		mail,
+		[]string{"m1", "m2"},
+		nil,
+		produce,
+		PipelineOptions{Concurrency: 2, Class: "email"},
+		errs)
+
+	// mail waiting on its consumer doesn't hold the slot, so the events
+	// still get produced.
+	results, ids, _ := collect(
+		fctx,
+		[]string{"e1", "e2"},
+		nil,
+		produce,
+		PipelineOptions{Concurrency: 2, Class: "events"},
+		errs)
+
+	assert.Equal(t, []string{"e1", "e2"}, ids)
+	assert.Equal(t, 2, results.Successes)
+
+	for range []string{"m1", "m2"} {
+		<-mail
+	}
+}
+
+func (suite *PipelineUnitSuite) TestPipelineOptions_Tuned() {
+	t := suite.T()
+	base := PipelineOptions{Concurrency: 4, MaxRetries: 3}
+
+	untuned := base.Tuned(control.TuningOptions{})
+	assert.Equal(t, 4, untuned.Concurrency)
+	assert.Equal(t, 3, untuned.MaxRetries)
+	assert.Nil(t, untuned.NewBackOff)
+
+	tuned := base.Tuned(control.TuningOptions{
+		ItemConcurrency: 1,
+		ItemRetries:     8,
+		RetryDelay:      10 * time.Second,
+	})
+	assert.Equal(t, 1, tuned.Concurrency)
+	assert.Equal(t, 8, tuned.MaxRetries)
+	assert.NotNil(t, tuned.NewBackOff)
+
+	bo, ok := tuned.NewBackOff().(*backoff.ExponentialBackOff)
+	if assert.True(t, ok, "exponential backoff") {
+		assert.Equal(t, 10*time.Second, bo.InitialInterval)
+	}
+}
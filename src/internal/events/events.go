@@ -3,8 +3,10 @@ package events
 import (
 	"context"
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/alcionai/clues"
@@ -23,28 +25,56 @@ const (
 	tenantID     = "m365_tenant_hash"
 
 	// Event Keys
-	CorsoStart   = "Corso Start"
-	RepoInit     = "Repo Init"
-	RepoConnect  = "Repo Connect"
-	BackupStart  = "Backup Start"
-	BackupEnd    = "Backup End"
-	RestoreStart = "Restore Start"
-	RestoreEnd   = "Restore End"
+	CorsoStart             = "Corso Start"
+	RepoInit               = "Repo Init"
+	RepoConnect            = "Repo Connect"
+	BackupStart            = "Backup Start"
+	BackupEnd              = "Backup End"
+	RestoreStart           = "Restore Start"
+	RestoreEnd             = "Restore End"
+	RestoreApprovalRequest = "Restore Approval Request"
+	ExportStart            = "Export Start"
+	ExportEnd              = "Export End"
+	QuotaWarning           = "Quota Warning"
+	BackupAlert            = "Backup Alert"
+	BackupAnomaly          = "Backup Anomaly"
+	BackupHeartbeat        = "Backup Heartbeat"
+	BackupStall            = "Backup Stall"
 
 	// Event Data Keys
+	ActiveItems      = "active_items"
+	Alert            = "alert"
+	AlertThreshold   = "alert_threshold"
+	AlertValue       = "alert_value"
+	Anomaly          = "anomaly"
+	AnomalyExtension = "anomaly_extension"
+	AnomalyItems     = "anomaly_items"
+	AnomalyTotal     = "anomaly_total"
+	ApprovalBytes    = "approval_bytes"
+	ApprovalItems    = "approval_items"
 	BackupCreateTime = "backup_creation_time"
 	BackupID         = "backup_id"
+	Collections      = "collections"
 	DataRetrieved    = "data_retrieved"
 	DataStored       = "data_stored"
 	Duration         = "duration"
 	EndTime          = "end_time"
+	ExportID         = "export_id"
+	Folder           = "folder"
+	IdleTime         = "idle_time"
+	ItemsChanged     = "items_changed"
 	ItemsRead        = "items_read"
 	ItemsWritten     = "items_written"
+	QuotaLimit       = "quota_limit"
+	QuotaLimitBytes  = "quota_limit_bytes"
+	RepoSize         = "repo_size"
 	Resources        = "resources"
 	RestoreID        = "restore_id"
 	Service          = "service"
 	StartTime        = "start_time"
 	Status           = "status"
+	Summary          = "summary"
+	TopErrors        = "top_errors"
 )
 
 type Eventer interface {
@@ -52,9 +82,17 @@ type Eventer interface {
 	Close() error
 }
 
+// ErrLocalOnly is returned when a local-only bus is asked to send
+// events to an external service.
+var ErrLocalOnly = clues.New("events are restricted to the local sink")
+
 // Bus handles all event communication into the events package.
 type Bus struct {
 	client analytics.Client
+	// local receives all events when the bus is local-only.
+	local *localSink
+	// localOnly buses never construct an external client.
+	localOnly bool
 
 	repoID  string // one-way hash that uniquely identifies the repo.
 	tenant  string // one-way hash that uniquely identifies the tenant.
@@ -71,6 +109,41 @@ func NewBus(ctx context.Context, s storage.Storage, tenID string, opts control.O
 		return Bus{}, nil
 	}
 
+	b := Bus{
+		localOnly: opts.Events.LocalOnly,
+		tenant:    tenantHash(tenID),
+		version:   version.Version,
+	}
+
+	if b.localOnly {
+		sink, err := newLocalSink(opts.Events.LocalFile)
+		if err != nil {
+			return Bus{}, clues.Wrap(err, "configuring local event sink").WithClues(ctx)
+		}
+
+		b.local = sink
+
+		return b, nil
+	}
+
+	client, err := b.remoteClient(ctx)
+	if err != nil {
+		return Bus{}, clues.Wrap(err, "configuring event bus").WithClues(ctx)
+	}
+
+	b.client = client
+
+	return b, nil
+}
+
+// remoteClient constructs the client that transmits events to the
+// external analytics service.  Returns a nil client if the service is
+// not configured.  Local-only buses refuse to construct the client.
+func (b Bus) remoteClient(ctx context.Context) (analytics.Client, error) {
+	if b.localOnly {
+		return nil, clues.Stack(ErrLocalOnly).WithClues(ctx)
+	}
+
 	envWK := os.Getenv("RUDDERSTACK_CORSO_WRITE_KEY")
 	if len(envWK) > 0 {
 		RudderStackWriteKey = envWK
@@ -81,30 +154,28 @@ func NewBus(ctx context.Context, s storage.Storage, tenID string, opts control.O
 		RudderStackDataPlaneURL = envDPU
 	}
 
-	var client analytics.Client
-
-	if len(RudderStackWriteKey) > 0 && len(RudderStackDataPlaneURL) > 0 {
-		var err error
-		client, err = analytics.NewWithConfig(
-			RudderStackWriteKey,
-			RudderStackDataPlaneURL,
-			analytics.Config{
-				Logger: logger.WrapCtx(ctx, logger.ForceDebugLogLevel()),
-			})
-
-		if err != nil {
-			return Bus{}, clues.Wrap(err, "configuring event bus").WithClues(ctx)
-		}
+	if len(RudderStackWriteKey) == 0 || len(RudderStackDataPlaneURL) == 0 {
+		return nil, nil
 	}
 
-	return Bus{
-		client:  client,
-		tenant:  tenantHash(tenID),
-		version: version.Version,
-	}, nil
+	return analytics.NewWithConfig(
+		RudderStackWriteKey,
+		RudderStackDataPlaneURL,
+		analytics.Config{
+			Logger: logger.WrapCtx(ctx, logger.ForceDebugLogLevel()),
+		})
+}
+
+// LocalOnly returns true if the bus restricts events to the local sink.
+func (b Bus) LocalOnly() bool {
+	return b.localOnly
 }
 
 func (b Bus) Close() error {
+	if b.local != nil {
+		return b.local.close()
+	}
+
 	if b.client == nil {
 		return nil
 	}
@@ -113,18 +184,16 @@ func (b Bus) Close() error {
 }
 
 func (b Bus) Event(ctx context.Context, key string, data map[string]any) {
-	if b.client == nil {
+	if b.localOnly {
+		if b.local != nil {
+			b.local.write(ctx, b.track(key, data))
+		}
+
 		return
 	}
 
-	props := analytics.
-		NewProperties().
-		Set(repoID, b.repoID).
-		Set(tenantID, b.tenant).
-		Set(corsoVersion, b.version)
-
-	for k, v := range data {
-		props.Set(k, v)
+	if b.client == nil {
+		return
 	}
 
 	// need to setup identity when initializing a new repo
@@ -140,14 +209,29 @@ func (b Bus) Event(ctx context.Context, key string, data map[string]any) {
 		}
 	}
 
-	err := b.client.Enqueue(analytics.Track{
+	err := b.client.Enqueue(b.track(key, data))
+	if err != nil {
+		logger.Ctx(ctx).Debugw("analytics event failure", "err", err)
+	}
+}
+
+// track builds the tracked event, including the bus' identifying properties.
+func (b Bus) track(key string, data map[string]any) analytics.Track {
+	props := analytics.
+		NewProperties().
+		Set(repoID, b.repoID).
+		Set(tenantID, b.tenant).
+		Set(corsoVersion, b.version)
+
+	for k, v := range data {
+		props.Set(k, v)
+	}
+
+	return analytics.Track{
 		Event:      key,
 		UserId:     b.repoID,
 		Timestamp:  time.Now().UTC(),
 		Properties: props,
-	})
-	if err != nil {
-		logger.Ctx(ctx).Debugw("analytics event failure", "err", err)
 	}
 }
 
@@ -159,3 +243,62 @@ func tenantHash(tenID string) string {
 	sum := md5.Sum([]byte(tenID))
 	return fmt.Sprintf("%x", sum)
 }
+
+// ---------------------------------------------------------------------------
+// local sink
+// ---------------------------------------------------------------------------
+
+// localSink records events without transmitting them.  Events are
+// appended to the file as json lines, or written to the log if the
+// sink has no file.
+type localSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newLocalSink(fp string) (*localSink, error) {
+	if len(fp) == 0 {
+		return &localSink{}, nil
+	}
+
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, clues.Wrap(err, "opening events file").With("events_file", fp)
+	}
+
+	return &localSink{f: f}, nil
+}
+
+func (ls *localSink) write(ctx context.Context, t analytics.Track) {
+	if ls.f == nil {
+		logger.Ctx(ctx).Infow("usage event", "event", t.Event, "properties", t.Properties)
+		return
+	}
+
+	bs, err := json.Marshal(t)
+	if err != nil {
+		logger.Ctx(ctx).Debugw("encoding local event", "err", err)
+		return
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if _, err := ls.f.Write(append(bs, '\n')); err != nil {
+		logger.Ctx(ctx).Debugw("writing local event", "err", err)
+	}
+}
+
+func (ls *localSink) close() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.f == nil {
+		return nil
+	}
+
+	err := ls.f.Close()
+	ls.f = nil
+
+	return err
+}
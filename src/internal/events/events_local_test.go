@@ -0,0 +1,119 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/storage"
+)
+
+type LocalEventsUnitSuite struct {
+	tester.Suite
+}
+
+func TestLocalEventsUnitSuite(t *testing.T) {
+	suite.Run(t, &LocalEventsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *LocalEventsUnitSuite) TestNewBus_localOnlyFile() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	t.Setenv("RUDDERSTACK_CORSO_WRITE_KEY", "wk")
+	t.Setenv("RUDDERSTACK_CORSO_DATA_PLANE_URL", "https://localhost:1")
+
+	fp := filepath.Join(t.TempDir(), "events.jsonl")
+	opts := control.Options{
+		Events: control.EventsOptions{LocalOnly: true, LocalFile: fp},
+	}
+
+	b, err := NewBus(ctx, storage.Storage{}, "tid", opts)
+	require.NoError(t, err)
+
+	assert.True(t, b.LocalOnly())
+	assert.Nil(t, b.client, "local-only buses never construct an external client")
+
+	b.SetRepoID("rid")
+	b.Event(ctx, BackupStart, map[string]any{BackupID: "bid"})
+	b.Event(ctx, BackupEnd, map[string]any{BackupID: "bid"})
+	require.NoError(t, b.Close())
+
+	f, err := os.Open(fp)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var (
+		scanner = bufio.NewScanner(f)
+		keys    = []string{}
+	)
+
+	for scanner.Scan() {
+		ev := map[string]any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+
+		keys = append(keys, ev["event"].(string))
+		props := ev["properties"].(map[string]any)
+		assert.Equal(t, "bid", props[BackupID])
+		assert.Equal(t, "rid", props[repoID])
+		assert.Equal(t, tenantHash("tid"), props[tenantID])
+	}
+
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{BackupStart, BackupEnd}, keys)
+}
+
+func (suite *LocalEventsUnitSuite) TestNewBus_localOnlyLog() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	opts := control.Options{Events: control.EventsOptions{LocalOnly: true}}
+
+	b, err := NewBus(ctx, storage.Storage{}, "tid", opts)
+	require.NoError(t, err)
+	require.NotNil(t, b.local)
+	assert.Nil(t, b.local.f)
+
+	b.Event(ctx, BackupStart, nil)
+	assert.NoError(t, b.Close())
+}
+
+func (suite *LocalEventsUnitSuite) TestNewBus_localOnlyBadFile() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	opts := control.Options{
+		Events: control.EventsOptions{
+			LocalOnly: true,
+			LocalFile: filepath.Join(t.TempDir(), "missing", "events.jsonl"),
+		},
+	}
+
+	_, err := NewBus(ctx, storage.Storage{}, "tid", opts)
+	assert.Error(t, err)
+}
+
+func (suite *LocalEventsUnitSuite) TestRemoteClient_refusedWhenLocalOnly() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	t.Setenv("RUDDERSTACK_CORSO_WRITE_KEY", "wk")
+	t.Setenv("RUDDERSTACK_CORSO_DATA_PLANE_URL", "https://localhost:1")
+
+	client, err := Bus{localOnly: true}.remoteClient(ctx)
+	assert.ErrorIs(t, err, ErrLocalOnly)
+	assert.Nil(t, client)
+}
@@ -0,0 +1,237 @@
+// Package fairshare schedules item retrieval across the categories of a
+// single resource owner.  Without it, each category's collections retrieve
+// items as fast as they're consumed, so that a huge mail folder can hold
+// every request the mailbox allows while contacts and events wait behind
+// it.  The scheduler bounds the items retrieved at once, and hands each
+// free slot to the waiting category that has been served the least,
+// relative to its weight.  Small categories thereby finish early, and get
+// captured by the backup's checkpoints instead of being lost if the backup
+// fails partway through the large ones.
+package fairshare
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSlots matches the most requests Outlook accepts at once for
+// a single mailbox.
+const DefaultSlots = 4
+
+// Scheduler grants slots to the classes (ex: categories) of items in
+// proportion to their weights.
+type Scheduler struct {
+	slots   int
+	weights map[string]int
+
+	mu       sync.Mutex
+	inFlight int
+	// vtime is the service each class has received, scaled by its weight.
+	vtime map[string]float64
+	// now is the vtime of the most recently granted class, which newly
+	// active classes start from, so that idle classes don't bank service.
+	now     float64
+	waiting map[string][]*waiter
+}
+
+type waiter struct {
+	granted chan struct{}
+}
+
+// New creates a scheduler that retrieves at most slots items at once.
+// Classes without a positive weight have a weight of 1.
+func New(slots int, weights map[string]int) *Scheduler {
+	if slots < 1 {
+		slots = 1
+	}
+
+	ws := map[string]int{}
+
+	for k, v := range weights {
+		if v > 0 {
+			ws[k] = v
+		}
+	}
+
+	return &Scheduler{
+		slots:   slots,
+		weights: ws,
+		vtime:   map[string]float64{},
+		waiting: map[string][]*waiter{},
+	}
+}
+
+// Acquire blocks until the class is granted a slot, or the ctx is done.
+// The returned func releases the slot, and can be called more than once.
+// A nil scheduler, or an empty class, never blocks.
+func (s *Scheduler) Acquire(ctx context.Context, class string) func() {
+	if s == nil || len(class) == 0 {
+		return func() {}
+	}
+
+	s.mu.Lock()
+
+	if s.inFlight < s.slots && s.queued() == 0 {
+		s.grant(class)
+		s.mu.Unlock()
+
+		return s.releaser()
+	}
+
+	if len(s.waiting[class]) == 0 && s.vtime[class] < s.now {
+		s.vtime[class] = s.now
+	}
+
+	w := &waiter{granted: make(chan struct{}, 1)}
+	s.waiting[class] = append(s.waiting[class], w)
+
+	s.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return s.releaser()
+
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.dequeue(class, w) {
+			return func() {}
+		}
+
+		// the slot was granted while the ctx was ending.
+		s.inFlight--
+		s.dispatch()
+
+		return func() {}
+	}
+}
+
+// releaser produces the func that returns a granted slot.
+func (s *Scheduler) releaser() func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.inFlight--
+			s.dispatch()
+		})
+	}
+}
+
+// grant charges the class for a slot.  Callers must hold the lock.
+func (s *Scheduler) grant(class string) {
+	s.inFlight++
+
+	if s.vtime[class] < s.now {
+		s.vtime[class] = s.now
+	}
+
+	s.now = s.vtime[class]
+	s.vtime[class] += 1 / float64(s.weight(class))
+}
+
+// dispatch hands free slots to the waiting classes that have received the
+// least service.  Callers must hold the lock.
+func (s *Scheduler) dispatch() {
+	for s.inFlight < s.slots {
+		class, ok := s.next()
+		if !ok {
+			return
+		}
+
+		w := s.waiting[class][0]
+		s.dequeue(class, w)
+		s.grant(class)
+
+		w.granted <- struct{}{}
+	}
+}
+
+// next picks the waiting class with the least service, breaking ties by
+// name so that scheduling is deterministic.  Callers must hold the lock.
+func (s *Scheduler) next() (string, bool) {
+	var (
+		best  string
+		found bool
+	)
+
+	for class, ws := range s.waiting {
+		if len(ws) == 0 {
+			continue
+		}
+
+		if !found ||
+			s.vtime[class] < s.vtime[best] ||
+			(s.vtime[class] == s.vtime[best] && class < best) {
+			best = class
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// dequeue removes the waiter from its class' queue.  Returns false if the
+// waiter was no longer queued.  Callers must hold the lock.
+func (s *Scheduler) dequeue(class string, w *waiter) bool {
+	ws := s.waiting[class]
+
+	for i := range ws {
+		if ws[i] == w {
+			s.waiting[class] = append(ws[:i:i], ws[i+1:]...)
+
+			if len(s.waiting[class]) == 0 {
+				delete(s.waiting, class)
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// queued counts the waiters of every class.  Callers must hold the lock.
+func (s *Scheduler) queued() int {
+	n := 0
+
+	for _, ws := range s.waiting {
+		n += len(ws)
+	}
+
+	return n
+}
+
+func (s *Scheduler) weight(class string) int {
+	if w, ok := s.weights[class]; ok {
+		return w
+	}
+
+	return 1
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type schedulerKey struct{}
+
+// Set embeds the scheduler within the context.
+func Set(ctx context.Context, s *Scheduler) context.Context {
+	if s == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, schedulerKey{}, s)
+}
+
+// Ctx retrieves the scheduler embedded in the context.  Returns nil if no
+// scheduler was set, which is safe to Acquire.
+func Ctx(ctx context.Context) *Scheduler {
+	s, _ := ctx.Value(schedulerKey{}).(*Scheduler)
+	return s
+}
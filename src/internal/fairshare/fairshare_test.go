@@ -0,0 +1,175 @@
+package fairshare
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type FairShareUnitSuite struct {
+	tester.Suite
+}
+
+func TestFairShareUnitSuite(t *testing.T) {
+	suite.Run(t, &FairShareUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// queue starts a waiter for each class, in order, and waits until each is
+// queued.  Each waiter records its class once granted, then releases.
+func queue(t *testing.T, s *Scheduler, classes ...string) (*[]string, *sync.WaitGroup) {
+	var (
+		order = []string{}
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+	)
+
+	for _, c := range classes {
+		c := c
+		queued := func() int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			return s.queued()
+		}
+		before := queued()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			release := s.Acquire(context.Background(), c)
+
+			mu.Lock()
+			order = append(order, c)
+			mu.Unlock()
+
+			release()
+		}()
+
+		assert.Eventually(t, func() bool { return queued() > before }, time.Second, time.Millisecond)
+	}
+
+	return &order, &wg
+}
+
+func (suite *FairShareUnitSuite) TestNilScheduler() {
+	var s *Scheduler
+
+	s.Acquire(context.Background(), "email")()
+	New(1, nil).Acquire(context.Background(), "")()
+	assert.Nil(suite.T(), Ctx(context.Background()))
+}
+
+func (suite *FairShareUnitSuite) TestAcquire_bounded() {
+	t := suite.T()
+	s := New(2, nil)
+
+	r1 := s.Acquire(context.Background(), "email")
+	r2 := s.Acquire(context.Background(), "email")
+
+	granted := make(chan struct{})
+
+	go func() {
+		s.Acquire(context.Background(), "events")()
+		close(granted)
+	}()
+
+	select {
+	case <-granted:
+		assert.Fail(t, "granted more slots than the scheduler holds")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r1()
+	r1() // releasing twice returns a single slot
+
+	select {
+	case <-granted:
+	case <-time.After(time.Second):
+		assert.Fail(t, "slot was never granted")
+	}
+
+	r2()
+
+	assert.Zero(t, s.inFlight)
+}
+
+func (suite *FairShareUnitSuite) TestAcquire_fair() {
+	t := suite.T()
+	s := New(1, nil)
+
+	release := s.Acquire(context.Background(), "email")
+	order, wg := queue(t, s, "email", "email", "email", "events", "contacts")
+
+	release()
+	wg.Wait()
+
+	// the small categories get served before the remaining mail, even
+	// though they asked last.
+	assert.Equal(t, []string{"contacts", "events", "email", "email", "email"}, *order)
+}
+
+func (suite *FairShareUnitSuite) TestAcquire_weighted() {
+	t := suite.T()
+	s := New(1, map[string]int{"email": 2, "events": 0})
+
+	release := s.Acquire(context.Background(), "hold")
+	order, wg := queue(t, s, "email", "email", "email", "email", "events", "events", "events", "events")
+
+	release()
+	wg.Wait()
+
+	assert.Equal(
+		t,
+		[]string{"email", "events", "email", "email", "events", "email", "events", "events"},
+		*order)
+}
+
+func (suite *FairShareUnitSuite) TestAcquire_canceled() {
+	t := suite.T()
+	s := New(1, nil)
+
+	release := s.Acquire(context.Background(), "email")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		s.Acquire(ctx, "events")()
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.queued() == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	s.mu.Lock()
+	assert.Zero(t, s.queued())
+	s.mu.Unlock()
+
+	release()
+	s.Acquire(context.Background(), "events")()
+
+	assert.Zero(t, s.inFlight)
+}
+
+func (suite *FairShareUnitSuite) TestCtx() {
+	s := New(1, nil)
+	ctx := Set(context.Background(), s)
+
+	assert.Equal(suite.T(), s, Ctx(ctx))
+	assert.Equal(suite.T(), context.Background(), Set(context.Background(), nil))
+}
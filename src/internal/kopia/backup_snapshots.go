@@ -0,0 +1,217 @@
+package kopia
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/kopia/kopia/snapshot"
+
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// maxDetailsCandidates bounds the untagged snapshots considered as the
+// details of a backup.
+const maxDetailsCandidates = 3
+
+// BackupSnapshot describes the snapshots persisted by a backup, as found
+// in the repository's snapshot manifests.
+type BackupSnapshot struct {
+	BackupID   string
+	SnapshotID string
+	// DetailsID is the ID of the snapshot holding the backup's details.
+	// Empty if no details snapshot is tagged with the backup's ID.
+	DetailsID string
+	// DetailsCandidates are the IDs of untagged snapshots made after the
+	// backup snapshot, in the order they were made.  Details written
+	// before details snapshots were tagged can only be found among them.
+	DetailsCandidates []string
+	Reasons           []Reason
+	StartTime         time.Time
+	EndTime           time.Time
+}
+
+// FindBackupSnapshots produces every complete backup snapshot in the
+// repository, along with the snapshots that hold, or may hold, their
+// details.
+func (w Wrapper) FindBackupSnapshots(ctx context.Context) ([]BackupSnapshot, error) {
+	if w.c == nil {
+		return nil, clues.Stack(errNotConnected).WithClues(ctx)
+	}
+
+	ids, err := snapshot.ListSnapshotManifests(ctx, w.c, nil, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "finding snapshots").WithClues(ctx)
+	}
+
+	mans, err := w.c.LoadSnapshots(ctx, ids)
+	if err != nil {
+		return nil, clues.Wrap(err, "loading snapshots").WithClues(ctx)
+	}
+
+	return groupBackupSnapshots(mans), nil
+}
+
+// groupBackupSnapshots pairs each complete backup snapshot with the
+// snapshots holding its details.
+func groupBackupSnapshots(mans []*snapshot.Manifest) []BackupSnapshot {
+	var (
+		backupIDKey, _  = makeTagKV(TagBackupID)
+		backupCatKey, _ = makeTagKV(TagBackupCategory)
+		detailsKey, _   = makeTagKV(TagDetailsCategory)
+		backups         = map[string]*snapshot.Manifest{}
+		tagged          = map[string]*snapshot.Manifest{}
+		untagged        = []*snapshot.Manifest{}
+	)
+
+	for _, m := range mans {
+		if len(m.IncompleteReason) > 0 {
+			continue
+		}
+
+		if len(m.Tags) == 0 {
+			untagged = append(untagged, m)
+			continue
+		}
+
+		bID := m.Tags[backupIDKey]
+		if len(bID) == 0 {
+			continue
+		}
+
+		if _, ok := m.Tags[detailsKey]; ok {
+			if prev, ok := tagged[bID]; !ok || m.StartTime.After(prev.StartTime) {
+				tagged[bID] = m
+			}
+
+			continue
+		}
+
+		if _, ok := m.Tags[backupCatKey]; !ok {
+			continue
+		}
+
+		if prev, ok := backups[bID]; !ok || m.StartTime.After(prev.StartTime) {
+			backups[bID] = m
+		}
+	}
+
+	sort.Slice(untagged, func(i, j int) bool {
+		return untagged[i].StartTime.Before(untagged[j].StartTime)
+	})
+
+	res := make([]BackupSnapshot, 0, len(backups))
+
+	for bID, m := range backups {
+		bs := BackupSnapshot{
+			BackupID:   bID,
+			SnapshotID: string(m.ID),
+			Reasons:    reasonsFromTags(m.Tags),
+			StartTime:  m.StartTime.ToTime(),
+			EndTime:    m.EndTime.ToTime(),
+		}
+
+		if d, ok := tagged[bID]; ok {
+			bs.DetailsID = string(d.ID)
+		}
+
+		for _, u := range untagged {
+			if len(bs.DetailsCandidates) >= maxDetailsCandidates {
+				break
+			}
+
+			if !u.StartTime.Before(m.EndTime) {
+				bs.DetailsCandidates = append(bs.DetailsCandidates, string(u.ID))
+			}
+		}
+
+		res = append(res, bs)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartTime.Before(res[j].StartTime)
+	})
+
+	return res
+}
+
+// backupServiceCats are the service and category pairs whose tags may
+// appear on backup snapshots.
+var backupServiceCats = map[path.ServiceType][]path.CategoryType{
+	path.ExchangeService: {
+		path.EmailCategory,
+		path.ContactsCategory,
+		path.EventsCategory,
+		path.TasksCategory,
+		path.SettingsCategory,
+	},
+	path.OneDriveService:   {path.FilesCategory},
+	path.SharePointService: {path.LibrariesCategory, path.ListsCategory, path.PagesCategory},
+}
+
+// reasonsFromTags reconstructs the reasons a backup snapshot was made
+// from its tags.  Reason tags hold the resource owner and the service
+// category as keys, so every tag that isn't a known tag or a service
+// category is an owner.
+func reasonsFromTags(tags map[string]string) []Reason {
+	type serviceCat struct {
+		service  path.ServiceType
+		category path.CategoryType
+	}
+
+	var (
+		known = map[string]serviceCat{}
+		scs   = []serviceCat{}
+		owns  = []string{}
+	)
+
+	for s, cats := range backupServiceCats {
+		for _, c := range cats {
+			known[serviceCatString(s, c)] = serviceCat{s, c}
+		}
+	}
+
+	for k := range tags {
+		if !strings.HasPrefix(k, userTagPrefix) {
+			continue
+		}
+
+		k = strings.TrimPrefix(k, userTagPrefix)
+
+		switch k {
+		case TagBackupID, TagBackupCategory, TagDetailsCategory:
+			continue
+		}
+
+		if sc, ok := known[k]; ok {
+			scs = append(scs, sc)
+			continue
+		}
+
+		owns = append(owns, k)
+	}
+
+	res := make([]Reason, 0, len(owns)*len(scs))
+
+	for _, o := range owns {
+		for _, sc := range scs {
+			res = append(res, Reason{
+				ResourceOwner: o,
+				Service:       sc.service,
+				Category:      sc.category,
+			})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ResourceOwner != res[j].ResourceOwner {
+			return res[i].ResourceOwner < res[j].ResourceOwner
+		}
+
+		return res[i].Category < res[j].Category
+	})
+
+	return res
+}
@@ -0,0 +1,90 @@
+package kopia
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+func newDetailsManifest(id manifest.ID, start time.Time, backupID string) *snapshot.Manifest {
+	m := &snapshot.Manifest{
+		ID:        id,
+		StartTime: fs.UTCTimestamp(start.UnixNano()),
+		EndTime:   fs.UTCTimestamp(start.Add(time.Second).UnixNano()),
+	}
+
+	if len(backupID) > 0 {
+		m.Tags = normalizeTagKVs(map[string]string{
+			TagBackupID:        backupID,
+			TagDetailsCategory: "",
+		})
+	}
+
+	return m
+}
+
+type BackupSnapshotsUnitSuite struct {
+	tester.Suite
+}
+
+func TestBackupSnapshotsUnitSuite(t *testing.T) {
+	suite.Run(t, &BackupSnapshotsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *BackupSnapshotsUnitSuite) TestGroupBackupSnapshots() {
+	var (
+		t   = suite.T()
+		now = time.Now().Add(-time.Hour)
+
+		bup1 = newPrunableManifest(testID1, now, testCompleteMan, "bup1", testMail, testEvents, testUser1)
+		bup2 = newPrunableManifest(testID2, now.Add(time.Minute), testCompleteMan, "bup2", testMail, testUser2)
+		// checkpoints aren't backups
+		checkpoint = newPrunableManifest(testID3, now.Add(2*time.Minute), testIncompleteMan, "bup3", testMail, testUser3)
+	)
+
+	bup1.EndTime = fs.UTCTimestamp(now.Add(30 * time.Second).UnixNano())
+	bup2.EndTime = fs.UTCTimestamp(now.Add(90 * time.Second).UnixNano())
+
+	mans := []*snapshot.Manifest{
+		bup1,
+		bup2,
+		checkpoint,
+		newDetailsManifest("deets-tagged", now.Add(40*time.Second), "bup1"),
+		newDetailsManifest("deets-old", now.Add(-time.Minute), ""),
+		newDetailsManifest("deets-1", now.Add(100*time.Second), ""),
+		newDetailsManifest("deets-2", now.Add(35*time.Second), ""),
+	}
+
+	res := groupBackupSnapshots(mans)
+	require.Len(t, res, 2)
+
+	assert.Equal(t, "bup1", res[0].BackupID)
+	assert.Equal(t, string(testID1), res[0].SnapshotID)
+	assert.Equal(t, "deets-tagged", res[0].DetailsID)
+	assert.Equal(t, []string{"deets-2", "deets-1"}, res[0].DetailsCandidates)
+	assert.Equal(t, bup1.EndTime.ToTime(), res[0].EndTime)
+	assert.ElementsMatch(
+		t,
+		[]Reason{
+			{ResourceOwner: testUser1, Service: path.ExchangeService, Category: path.EmailCategory},
+			{ResourceOwner: testUser1, Service: path.ExchangeService, Category: path.EventsCategory},
+		},
+		res[0].Reasons)
+
+	assert.Equal(t, "bup2", res[1].BackupID)
+	assert.Empty(t, res[1].DetailsID)
+	assert.Equal(t, []string{"deets-1"}, res[1].DetailsCandidates, "only details made after the backup")
+	assert.Equal(
+		t,
+		[]Reason{{ResourceOwner: testUser2, Service: path.ExchangeService, Category: path.EmailCategory}},
+		res[1].Reasons)
+}
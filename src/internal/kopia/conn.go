@@ -18,12 +18,14 @@ import (
 	"github.com/kopia/kopia/snapshot/snapshotfs"
 	"github.com/pkg/errors"
 
+	"github.com/alcionai/corso/src/pkg/logger"
 	"github.com/alcionai/corso/src/pkg/storage"
 )
 
 const (
 	defaultKopiaConfigDir  = "/tmp/"
 	defaultKopiaConfigFile = "repository.config"
+	defaultReadCacheSubdir = "cache"
 	defaultCompressor      = "s2-default"
 	// Interval of 0 disables scheduling.
 	defaultSchedulingInterval = time.Second * 0
@@ -61,6 +63,16 @@ type conn struct {
 	repo.Repository
 	mu       sync.Mutex
 	refCount int
+	// local disk cache of content read from storage.  Disabled unless
+	// readCacheMaxBytes is positive.
+	readCacheDir      string
+	readCacheMaxBytes int64
+	// local disk cache of repository metadata.  Disabled unless
+	// metadataCacheMaxBytes is positive.
+	metadataCacheMaxBytes int64
+	// cacheDir is the directory of the local cache used by the connection.
+	// Empty if the connection caches nothing on disk.
+	cacheDir string
 }
 
 func NewConn(s storage.Storage) *conn {
@@ -69,7 +81,33 @@ func NewConn(s storage.Storage) *conn {
 	}
 }
 
+// SetReadCache configures a bounded, on-disk cache of the content read from
+// storage.  Once the cache holds maxBytes, the least recently used content is
+// evicted.  The cache outlives the connection, so repeated reads of the same
+// backups, such as a verify followed by a restore, are served locally instead
+// of from the object store.  If dir is empty, the cache is kept beneath the
+// kopia config directory.  Must be called before Connect.
+func (w *conn) SetReadCache(dir string, maxBytes int64) {
+	w.readCacheDir = dir
+	w.readCacheMaxBytes = maxBytes
+}
+
+// SetMetadataCache configures a bounded, on-disk cache of the repository's
+// metadata, such as its manifests, kept in the read cache's directory.  The
+// listing of index blobs is also reused for a short while, so that commands
+// run in quick succession don't list storage each time.  Must be called
+// before Connect.
+func (w *conn) SetMetadataCache(maxBytes int64) {
+	w.metadataCacheMaxBytes = maxBytes
+}
+
 func (w *conn) Initialize(ctx context.Context) error {
+	if w.storage.Provider == storage.ProviderS3 {
+		if err := writeS3StorageConfig(ctx, w.storage); err != nil {
+			return errors.Wrap(err, "configuring storage classes")
+		}
+	}
+
 	bst, err := blobStoreByProvider(ctx, w.storage)
 	if err != nil {
 		return errors.Wrap(err, "initializing storage")
@@ -137,6 +175,16 @@ func (w *conn) commonConnect(
 		configDir = defaultKopiaConfigDir
 	}
 
+	if w.readCacheMaxBytes > 0 || w.metadataCacheMaxBytes > 0 {
+		opts = &repo.ConnectOptions{CachingOptions: w.cachingOptions(configDir)}
+	}
+
+	// kopia ignores every caching option unless content caching has a
+	// size bound, in which case nothing gets cached on disk.
+	if opts != nil && opts.CachingOptions.MaxCacheSizeBytes > 0 {
+		w.cacheDir = opts.CachingOptions.CacheDirectory
+	}
+
 	cfgFile := filepath.Join(configDir, defaultKopiaConfigFile)
 
 	// todo - issue #75: nil here should be storage.ConnectOptions()
@@ -151,7 +199,17 @@ func (w *conn) commonConnect(
 	}
 
 	if err := w.open(ctx, cfgFile, password); err != nil {
-		return clues.Stack(err).WithClues(ctx)
+		// a corrupted cached index fails opening the repository, while a
+		// missing one is retrieved from storage.  The cache is only checked
+		// after a failure, so that healthy connections don't read every
+		// cached index.
+		if !w.removeCorruptIndexes(ctx) {
+			return clues.Stack(err).WithClues(ctx)
+		}
+
+		if err := w.open(ctx, cfgFile, password); err != nil {
+			return clues.Stack(err).WithClues(ctx)
+		}
 	}
 
 	if err := w.setDefaultConfigValues(ctx); err != nil {
@@ -161,6 +219,53 @@ func (w *conn) commonConnect(
 	return nil
 }
 
+// removeCorruptIndexes removes the cached indexes that fail their check.
+// Failing to check the cache is only logged, since the caller already has
+// the error that matters.  Returns true if any index was removed.
+func (w *conn) removeCorruptIndexes(ctx context.Context) bool {
+	if len(w.cacheDir) == 0 {
+		return false
+	}
+
+	_, removed, err := checkIndexCache(ctx, w.cacheDir, false)
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("checking cached indexes", clues.InErr(err).Slice()...)
+	}
+
+	if removed > 0 {
+		logger.Ctx(ctx).Infow("removed corrupted cached indexes", "num_removed", removed)
+	}
+
+	return removed > 0
+}
+
+// cachingOptions produces the kopia caching options for the read cache.
+// Metadata shares the content cache's size bound, unless the metadata cache
+// sets its own.  Kopia only caches when content caching has a size bound,
+// so a metadata cache without a read cache gets a matching content bound.
+func (w *conn) cachingOptions(configDir string) content.CachingOptions {
+	dir := w.readCacheDir
+	if len(dir) == 0 {
+		dir = filepath.Join(configDir, defaultReadCacheSubdir)
+	}
+
+	opts := content.CachingOptions{
+		CacheDirectory:    dir,
+		MaxCacheSizeBytes: w.readCacheMaxBytes,
+	}
+
+	if w.metadataCacheMaxBytes > 0 {
+		if opts.MaxCacheSizeBytes == 0 {
+			opts.MaxCacheSizeBytes = w.metadataCacheMaxBytes
+		}
+
+		opts.MaxMetadataCacheSizeBytes = w.metadataCacheMaxBytes
+		opts.MaxListCacheDuration = content.DurationSeconds(listCacheDuration.Seconds())
+	}
+
+	return opts
+}
+
 func blobStoreByProvider(ctx context.Context, s storage.Storage) (blob.Storage, error) {
 	switch s.Provider {
 	case storage.ProviderS3:
@@ -209,6 +314,7 @@ func (w *conn) open(ctx context.Context, configPath, password string) error {
 	// TODO(ashmrtnz): issue #75: nil here should be storage.ConnectionOptions().
 	rep, err := repo.Open(ctx, configPath, password, nil)
 	if err != nil {
+		w.refCount--
 		return clues.Wrap(err, "opening repository connection").WithClues(ctx)
 	}
 
@@ -408,3 +514,24 @@ func (w *conn) LoadSnapshots(
 func (w *conn) SnapshotRoot(man *snapshot.Manifest) (fs.Entry, error) {
 	return snapshotfs.SnapshotRoot(w.Repository, man)
 }
+
+// StorageSize returns the total size, in bytes, of every blob held in the
+// repository's storage.
+func (w *conn) StorageSize(ctx context.Context) (int64, error) {
+	dr, ok := w.Repository.(repo.DirectRepository)
+	if !ok {
+		return 0, clues.New("repository does not support direct storage access").WithClues(ctx)
+	}
+
+	var size int64
+
+	err := dr.BlobReader().ListBlobs(ctx, "", func(bm blob.Metadata) error {
+		size += bm.Length
+		return nil
+	})
+	if err != nil {
+		return 0, clues.Wrap(err, "listing repository blobs").WithClues(ctx)
+	}
+
+	return size, nil
+}
@@ -3,9 +3,12 @@ package kopia
 import (
 	"context"
 	"math"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/snapshot"
 	"github.com/kopia/kopia/snapshot/policy"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +54,79 @@ func (suite *WrapperUnitSuite) TestCloseWithoutOpenDoesNotCrash() {
 	})
 }
 
+func (suite *WrapperUnitSuite) TestCachingOptions() {
+	table := []struct {
+		name      string
+		dir       string
+		expectDir string
+	}{
+		{
+			name:      "default directory",
+			expectDir: filepath.Join("cfg", defaultReadCacheSubdir),
+		},
+		{
+			name:      "configured directory",
+			dir:       "elsewhere",
+			expectDir: "elsewhere",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			k := conn{}
+			k.SetReadCache(test.dir, 1024)
+
+			opts := k.cachingOptions("cfg")
+			assert.Equal(t, test.expectDir, opts.CacheDirectory)
+			assert.Equal(t, int64(1024), opts.MaxCacheSizeBytes)
+		})
+	}
+}
+
+func (suite *WrapperUnitSuite) TestCachingOptions_metadata() {
+	t := suite.T()
+
+	k := conn{}
+
+	opts := k.cachingOptions("cfg")
+	assert.Zero(t, opts.MaxMetadataCacheSizeBytes)
+	assert.Zero(t, opts.MaxListCacheDuration)
+
+	k.SetMetadataCache(2048)
+
+	opts = k.cachingOptions("cfg")
+	assert.Equal(t, filepath.Join("cfg", defaultReadCacheSubdir), opts.CacheDirectory)
+	assert.Equal(t, int64(2048), opts.MaxMetadataCacheSizeBytes)
+	assert.Equal(t, int64(2048), opts.MaxCacheSizeBytes, "kopia drops caching without a content bound")
+	assert.Equal(t, content.DurationSeconds(listCacheDuration.Seconds()), opts.MaxListCacheDuration)
+
+	k.SetReadCache("", 4096)
+
+	opts = k.cachingOptions("cfg")
+	assert.Equal(t, int64(2048), opts.MaxMetadataCacheSizeBytes)
+	assert.Equal(t, int64(4096), opts.MaxCacheSizeBytes)
+}
+
+func (suite *WrapperUnitSuite) TestRemoveCorruptIndexes() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	k := conn{}
+	assert.False(t, k.removeCorruptIndexes(ctx), "no cache")
+
+	k.cacheDir = t.TempDir()
+	dir := filepath.Join(k.cacheDir, indexCacheSubdir)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	assert.False(t, k.removeCorruptIndexes(ctx), "empty cache")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "x"+indexCacheSuffix), []byte("garbage"), 0o600))
+	assert.True(t, k.removeCorruptIndexes(ctx), "corrupt index")
+	assert.NoFileExists(t, filepath.Join(dir, "x"+indexCacheSuffix))
+}
+
 // ---------------
 // integration tests that use kopia
 // ---------------
@@ -122,6 +198,24 @@ func (suite *WrapperIntegrationSuite) TestCloseTwiceDoesNotCrash() {
 	assert.NoError(t, k.Close(ctx))
 }
 
+func (suite *WrapperIntegrationSuite) TestStorageSize() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	k, err := openKopiaRepo(t, ctx)
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, k.Close(ctx))
+	}()
+
+	size, err := k.StorageSize(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, size, "initialized repos contain format blobs")
+}
+
 func (suite *WrapperIntegrationSuite) TestCloseAfterWrap() {
 	ctx, flush := tester.NewContext()
 	defer flush()
@@ -22,6 +22,7 @@ type kopiaDataCollection struct {
 	streams      []data.Stream
 	snapshotRoot fs.Entry
 	counter      ByteCounter
+	owners       *ownerKeys
 }
 
 func (kdc *kopiaDataCollection) Items(
@@ -61,7 +62,7 @@ func (kdc kopiaDataCollection) Fetch(
 	// TODO(ashmrtn): We could possibly hold a reference to the folder this
 	// collection corresponds to, but that requires larger changes for the
 	// creation of these collections.
-	return getItemStream(ctx, p, kdc.snapshotRoot, kdc.counter)
+	return getItemStream(ctx, p, kdc.snapshotRoot, kdc.counter, kdc.owners)
 }
 
 type kopiaDataStream struct {
@@ -0,0 +1,157 @@
+package kopia
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content/index"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+const (
+	// kopia keeps each index blob it retrieves within this subdirectory of
+	// the cache directory, in a file named for the blob.
+	indexCacheSubdir = "indexes"
+	indexCacheSuffix = ".sndx"
+	// listCacheDuration is how long the listing of the repository's index
+	// blobs is reused before storage is listed again.
+	listCacheDuration = 30 * time.Second
+)
+
+// checkIndexCache verifies the index blobs cached in the cache directory,
+// and removes the ones that fail, so that kopia retrieves them from storage
+// again instead of failing to open the repository.  Shallow checks only
+// parse the header of each index, while deep checks also read every entry.
+// Returns the number of indexes that were checked, and that were removed.
+func checkIndexCache(ctx context.Context, cacheDir string, deep bool) (int, int, error) {
+	dir := filepath.Join(cacheDir, indexCacheSubdir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, 0, nil
+		}
+
+		return 0, 0, clues.Wrap(err, "listing cached indexes").WithClues(ctx)
+	}
+
+	var checked, removed int
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), indexCacheSuffix) {
+			continue
+		}
+
+		checked++
+
+		fp := filepath.Join(dir, e.Name())
+
+		if err := checkIndexFile(fp, deep); err == nil {
+			continue
+		}
+
+		logger.Ctx(ctx).Infow("removing corrupted cached index", "index_file", e.Name())
+
+		if err := os.Remove(fp); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return checked, removed, clues.Wrap(err, "removing corrupted cached index").WithClues(ctx)
+		}
+
+		removed++
+	}
+
+	return checked, removed, nil
+}
+
+// checkIndexFile returns an error if the cached index can't be opened, or,
+// for deep checks, if any of its entries can't be read.
+func checkIndexFile(fp string, deep bool) error {
+	bs, err := os.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+
+	// the per-content overhead only alters the reported length of
+	// contents in older indexes, which the check doesn't look at.
+	ndx, err := index.Open(bs, nil, func() int { return 0 })
+	if err != nil {
+		return err
+	}
+	defer ndx.Close()
+
+	if !deep {
+		return nil
+	}
+
+	return ndx.Iterate(index.AllIDs, func(index.Info) error { return nil })
+}
+
+// WarmStats describes the repository metadata held in the local cache
+// after warming it.
+type WarmStats struct {
+	// Indexes is the number of index blobs in use by the repository.
+	Indexes int
+	// RemovedIndexes is the number of cached indexes that failed their
+	// integrity check.  They're retrieved from storage again on the next
+	// connection.
+	RemovedIndexes int
+	// Manifests is the number of snapshot and backup manifests loaded.
+	Manifests int
+}
+
+// Warm brings the repository's indexes and manifests into the local cache,
+// after reading every entry of the cached indexes to verify them.  Later
+// connections then load them from disk instead of from storage.  Fails if
+// the connection has no cache directory.
+func (w *Wrapper) Warm(ctx context.Context) (WarmStats, error) {
+	var stats WarmStats
+
+	if w.c == nil || w.c.Repository == nil {
+		return stats, clues.Stack(errNotConnected).WithClues(ctx)
+	}
+
+	if len(w.c.cacheDir) == 0 {
+		return stats, clues.New("repository connection has no local cache").WithClues(ctx)
+	}
+
+	_, removed, err := checkIndexCache(ctx, w.c.cacheDir, true)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.RemovedIndexes = removed
+
+	dr, ok := w.c.Repository.(repo.DirectRepository)
+	if !ok {
+		return stats, clues.New("repository doesn't support index access").WithClues(ctx)
+	}
+
+	// refreshing retrieves any index blobs missing from the cache.
+	if err := dr.Refresh(ctx); err != nil {
+		return stats, clues.Wrap(err, "refreshing indexes").WithClues(ctx)
+	}
+
+	ibs, err := dr.IndexBlobs(ctx, false)
+	if err != nil {
+		return stats, clues.Wrap(err, "listing indexes").WithClues(ctx)
+	}
+
+	stats.Indexes = len(ibs)
+
+	// finding manifests loads all of them, which caches the metadata
+	// blobs that hold them.
+	mans, err := dr.FindManifests(ctx, nil)
+	if err != nil {
+		return stats, clues.Wrap(err, "loading manifests").WithClues(ctx)
+	}
+
+	stats.Manifests = len(mans)
+
+	return stats, nil
+}
@@ -0,0 +1,102 @@
+package kopia
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kopia/kopia/repo/content/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type IndexCacheUnitSuite struct {
+	tester.Suite
+}
+
+func TestIndexCacheUnitSuite(t *testing.T) {
+	suite.Run(t, &IndexCacheUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// validIndex produces the bytes of an index holding two contents.
+func validIndex(t *testing.T) []byte {
+	b := index.Builder{}
+
+	for _, id := range []string{"0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"} {
+		cid, err := index.ParseID(id)
+		require.NoError(t, err)
+
+		b.Add(&index.InfoStruct{
+			ContentID:      cid,
+			PackBlobID:     "ppack",
+			OriginalLength: 10,
+			PackedLength:   10,
+		})
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, b.Build(&buf, index.Version2))
+
+	return buf.Bytes()
+}
+
+func (suite *IndexCacheUnitSuite) TestCheckIndexCache() {
+	for _, deep := range []bool{false, true} {
+		suite.Run(map[bool]string{false: "shallow", true: "deep"}[deep], func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t        = suite.T()
+				cacheDir = t.TempDir()
+				dir      = filepath.Join(cacheDir, indexCacheSubdir)
+			)
+
+			require.NoError(t, os.MkdirAll(dir, 0o700))
+
+			files := map[string][]byte{
+				"xn0_valid" + indexCacheSuffix:   validIndex(t),
+				"xn0_corrupt" + indexCacheSuffix: []byte("not an index"),
+				"unrelated.tmp":                  []byte("not an index"),
+			}
+
+			for name, bs := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), bs, 0o600))
+			}
+
+			checked, removed, err := checkIndexCache(ctx, cacheDir, deep)
+			require.NoError(t, err)
+			assert.Equal(t, 2, checked, "only cached indexes are checked")
+			assert.Equal(t, 1, removed)
+
+			assert.FileExists(t, filepath.Join(dir, "xn0_valid"+indexCacheSuffix))
+			assert.FileExists(t, filepath.Join(dir, "unrelated.tmp"))
+			assert.NoFileExists(t, filepath.Join(dir, "xn0_corrupt"+indexCacheSuffix))
+		})
+	}
+}
+
+func (suite *IndexCacheUnitSuite) TestCheckIndexCache_noCache() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	checked, removed, err := checkIndexCache(ctx, t.TempDir(), true)
+	require.NoError(t, err)
+	assert.Zero(t, checked)
+	assert.Zero(t, removed)
+}
+
+func (suite *IndexCacheUnitSuite) TestWarm_noCache() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	_, err := (&Wrapper{}).Warm(ctx)
+	assert.ErrorIs(suite.T(), err, errNotConnected)
+}
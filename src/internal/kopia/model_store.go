@@ -55,6 +55,12 @@ func (ms *ModelStore) Close(ctx context.Context) error {
 	return errors.Wrap(err, "closing ModelStore")
 }
 
+// Refresh reloads the repository's indexes, so that models written by other
+// connections to the repository become visible.
+func (ms *ModelStore) Refresh(ctx context.Context) error {
+	return errors.Wrap(ms.c.Refresh(ctx), "refreshing repository")
+}
+
 // tagsForModel creates a copy of tags and adds a tag for the model schema to it.
 // Returns an error if another tag has the same key as the model schema or if a
 // bad model type is given.
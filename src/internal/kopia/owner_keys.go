@@ -0,0 +1,540 @@
+package kopia
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/format"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// Kopia encrypts all repository content with keys derived from the
+// repository's master key.  Repositories initialized with owner encryption
+// additionally encrypt each item's data with a key belonging to the item's
+// resource owner.  Owner keys are derived, through kopia's key derivation,
+// from the master key salted with a random value that is stored in its own
+// storage blob.  Deleting an owner's salt blobs makes the owner's keys
+// underivable, which erases the contents of the owner's items from every
+// backup without rewriting any of them.  Backup details and other metadata
+// stay encrypted by kopia alone, so erasure leaves them readable.
+
+const (
+	// ownerEncryptedVersion marks item data encrypted with an owner key.
+	ownerEncryptedVersion uint32 = 2
+
+	ownerKeyBlobPrefix    blob.ID = "corso-owner-key-"
+	ownerErasedBlobPrefix blob.ID = "corso-owner-erased-"
+
+	ownerKeySize         = 32
+	ownerSaltSize        = 32
+	ownerDigestSize      = 16
+	ownerKeyIDSize       = 8
+	ownerStreamSaltSize  = 32
+	ownerSegmentSize     = 64 * 1024
+	ownerSegmentOverhead = 16
+	ownerKeyRefSize      = 2*ownerDigestSize + 1 + 2*ownerKeyIDSize
+	ownerStreamHeadLen   = ownerKeyRefSize + ownerStreamSaltSize
+)
+
+var (
+	ownerKeysPurpose   = []byte("corso-owner-keys")
+	ownerStreamPurpose = []byte("corso-owner-stream")
+)
+
+var (
+	ErrOwnerKeyNotFound           = errors.New("resource owner encryption key not found")
+	errOwnerEncryptionDisabled    = errors.New("repository does not use owner encryption")
+	errOwnerEncryptionUnsupported = errors.New("repository does not support owner encryption")
+)
+
+// encryptsByOwner reports whether the item is encrypted with its owner's key.
+// Metadata, such as delta tokens and backup details, is only encrypted by
+// kopia, so the names, paths, and other details of an erased owner's items
+// remain readable.
+func encryptsByOwner(p path.Path) bool {
+	switch p.Service() {
+	case path.ExchangeMetadataService, path.OneDriveMetadataService, path.SharePointMetadataService:
+		return false
+	}
+
+	return true
+}
+
+// ownerKeys derives, creates, and erases resource owner keys.
+type ownerKeys struct {
+	dr      repo.DirectRepository
+	rootKey []byte
+
+	mu sync.Mutex
+	// blob IDs of the keys which encrypt each owner's new data.
+	current map[string]blob.ID
+	// derived keys, by blob ID.
+	derived map[blob.ID][]byte
+}
+
+func newOwnerKeys(r repo.Repository) (*ownerKeys, error) {
+	dr, ok := r.(repo.DirectRepository)
+	if !ok {
+		return nil, clues.Stack(errOwnerEncryptionUnsupported)
+	}
+
+	return &ownerKeys{
+		dr:      dr,
+		rootKey: dr.DeriveKey(ownerKeysPurpose, ownerKeySize),
+		current: map[string]blob.ID{},
+		derived: map[blob.ID][]byte{},
+	}, nil
+}
+
+// ownerPrefix produces the blob ID prefix of the owner's keys.  Owners are
+// identified by an hmac of their ID so that the storage doesn't reveal which
+// owners have data in the repository.
+func (oks *ownerKeys) ownerPrefix(owner string) blob.ID {
+	return ownerKeyBlobPrefix + oks.ownerDigest(owner) + "-"
+}
+
+// erasedID produces the blob ID of the record of the owner's erasure.
+func (oks *ownerKeys) erasedID(owner string) blob.ID {
+	return ownerErasedBlobPrefix + oks.ownerDigest(owner)
+}
+
+func (oks *ownerKeys) ownerDigest(owner string) blob.ID {
+	h := hmac.New(sha256.New, oks.rootKey)
+	h.Write([]byte(owner))
+
+	return blob.ID(hex.EncodeToString(h.Sum(nil)[:ownerDigestSize]))
+}
+
+func (oks *ownerKeys) listKeys(ctx context.Context, owner string) ([]blob.ID, error) {
+	ids := []blob.ID{}
+
+	err := oks.dr.BlobReader().ListBlobs(ctx, oks.ownerPrefix(owner), func(bm blob.Metadata) error {
+		ids = append(ids, bm.BlobID)
+		return nil
+	})
+	if err != nil {
+		return nil, clues.Wrap(err, "listing owner keys").WithClues(ctx)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// writeKey returns the key which encrypts new data for the owner, creating
+// a key if the owner doesn't have one.
+func (oks *ownerKeys) writeKey(ctx context.Context, owner string) (blob.ID, []byte, error) {
+	oks.mu.Lock()
+	defer oks.mu.Unlock()
+
+	if id, found := oks.current[owner]; found {
+		return id, oks.derived[id], nil
+	}
+
+	ids, err := oks.listKeys(ctx, owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var id blob.ID
+
+	// concurrent backups may each create a key for the owner.  Any of them
+	// works, since the key used is recorded alongside the data.
+	if len(ids) > 0 {
+		id = ids[0]
+	} else if id, err = oks.createKey(ctx, owner); err != nil {
+		return "", nil, err
+	}
+
+	key, err := oks.derive(ctx, id, owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	oks.current[owner] = id
+
+	return id, key, nil
+}
+
+func (oks *ownerKeys) createKey(ctx context.Context, owner string) (blob.ID, error) {
+	salt := make([]byte, ownerSaltSize)
+	kid := make([]byte, ownerKeyIDSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", clues.Wrap(err, "generating owner key salt").WithClues(ctx)
+	}
+
+	if _, err := rand.Read(kid); err != nil {
+		return "", clues.Wrap(err, "generating owner key id").WithClues(ctx)
+	}
+
+	id := oks.ownerPrefix(owner) + blob.ID(hex.EncodeToString(kid))
+
+	err := repo.DirectWriteSession(
+		ctx,
+		oks.dr,
+		repo.WriteSessionOptions{Purpose: "CorsoOwnerKeyCreate"},
+		func(innerCtx context.Context, dw repo.DirectRepositoryWriter) error {
+			return dw.BlobStorage().PutBlob(innerCtx, id, configBytes(salt), blob.PutOptions{})
+		})
+	if err != nil {
+		return "", clues.Wrap(err, "storing owner key").WithClues(ctx)
+	}
+
+	return id, nil
+}
+
+// readKey returns the key with the given blob ID, which decrypts data
+// belonging to the owner.
+func (oks *ownerKeys) readKey(ctx context.Context, owner string, id blob.ID) ([]byte, error) {
+	oks.mu.Lock()
+	defer oks.mu.Unlock()
+
+	// keys are only valid for the owner they were created for.
+	if !strings.HasPrefix(string(id), string(oks.ownerPrefix(owner))) {
+		return nil, clues.New("owner key does not belong to the resource owner").WithClues(ctx)
+	}
+
+	if key, found := oks.derived[id]; found {
+		return key, nil
+	}
+
+	return oks.derive(ctx, id, owner)
+}
+
+// derive produces the owner key from the salt in the blob.  Must be called
+// while holding the lock.
+func (oks *ownerKeys) derive(ctx context.Context, id blob.ID, owner string) ([]byte, error) {
+	var salt blobBuffer
+
+	if err := oks.dr.BlobReader().GetBlob(ctx, id, 0, -1, &salt); err != nil {
+		if errors.Is(err, blob.ErrBlobNotFound) {
+			err = clues.Stack(ErrOwnerKeyNotFound, err)
+		}
+
+		return nil, clues.Wrap(err, "reading owner key").WithClues(ctx)
+	}
+
+	key := format.DeriveKeyFromMasterKey(oks.rootKey, salt.Bytes(), []byte(owner), ownerKeySize)
+	oks.derived[id] = key
+
+	return key, nil
+}
+
+// erase deletes all of the owner's keys, and records when the owner was
+// erased.  Returns the number of keys deleted.
+func (oks *ownerKeys) erase(ctx context.Context, owner string) (int, error) {
+	oks.mu.Lock()
+	defer oks.mu.Unlock()
+
+	ids, err := oks.listKeys(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	erasedAt := make([]byte, 8)
+	binary.BigEndian.PutUint64(erasedAt, uint64(time.Now().UnixNano()))
+
+	err = repo.DirectWriteSession(
+		ctx,
+		oks.dr,
+		repo.WriteSessionOptions{Purpose: "CorsoOwnerKeyErase"},
+		func(innerCtx context.Context, dw repo.DirectRepositoryWriter) error {
+			// recorded first, so that backups never merge data which can't be
+			// read any longer, even if deleting the keys fails part way.
+			err := dw.BlobStorage().PutBlob(innerCtx, oks.erasedID(owner), configBytes(erasedAt), blob.PutOptions{})
+			if err != nil {
+				return clues.Wrap(err, "recording owner erasure")
+			}
+
+			for _, id := range ids {
+				if err := dw.BlobStorage().DeleteBlob(innerCtx, id); err != nil {
+					return clues.Wrap(err, "deleting owner key").With("blob_id", id)
+				}
+			}
+
+			return nil
+		})
+	if err != nil {
+		return 0, clues.Wrap(err, "erasing owner keys").WithClues(ctx)
+	}
+
+	delete(oks.current, owner)
+
+	for _, id := range ids {
+		delete(oks.derived, id)
+	}
+
+	return len(ids), nil
+}
+
+// erasedAt returns the time when the owner's keys were last erased.  Owners
+// whose keys were never erased produce the zero time.
+func (oks *ownerKeys) erasedAt(ctx context.Context, owner string) (time.Time, error) {
+	var buf blobBuffer
+
+	if err := oks.dr.BlobReader().GetBlob(ctx, oks.erasedID(owner), 0, -1, &buf); err != nil {
+		if errors.Is(err, blob.ErrBlobNotFound) {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, clues.Wrap(err, "reading owner erasure").WithClues(ctx)
+	}
+
+	if buf.Len() != 8 {
+		return time.Time{}, clues.New("malformed owner erasure record").WithClues(ctx)
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(buf.Bytes()))), nil
+}
+
+// encryptReader produces a versioned reader of the owner encrypted data.
+func (oks *ownerKeys) encryptReader(
+	ctx context.Context,
+	owner string,
+	r io.ReadCloser,
+) (io.ReadCloser, error) {
+	id, key, err := oks.writeKey(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	er, err := newOwnerEncryptReader(key, strings.TrimPrefix(string(id), string(ownerKeyBlobPrefix)), r)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	return newBackupStreamReader(ownerEncryptedVersion, er), nil
+}
+
+// decrypter produces a func which decrypts the owner's data, for use by a
+// restoreStreamReader.
+func (oks *ownerKeys) decrypter(ctx context.Context, owner string) func(io.Reader) (io.Reader, error) {
+	return func(r io.Reader) (io.Reader, error) {
+		head := make([]byte, ownerStreamHeadLen)
+
+		if _, err := io.ReadFull(r, head); err != nil {
+			return nil, clues.Wrap(err, "reading owner encryption header").WithClues(ctx)
+		}
+
+		id := ownerKeyBlobPrefix + blob.ID(head[:ownerKeyRefSize])
+
+		key, err := oks.readKey(ctx, owner, id)
+		if err != nil {
+			return nil, err
+		}
+
+		dr, err := newOwnerDecryptReader(key, head[ownerKeyRefSize:], r)
+		if err != nil {
+			return nil, clues.Stack(err).WithClues(ctx)
+		}
+
+		return dr, nil
+	}
+}
+
+// ownerPlainSize produces the size of the data encrypted into a stream of
+// the given size, excluding the version.
+func ownerPlainSize(size int64) int64 {
+	size -= ownerStreamHeadLen
+
+	segs := (size + ownerSegmentSize + ownerSegmentOverhead - 1) / (ownerSegmentSize + ownerSegmentOverhead)
+
+	return size - segs*ownerSegmentOverhead
+}
+
+// ---------------------------------------------------------------------------
+// streaming encryption
+// ---------------------------------------------------------------------------
+
+// Data is encrypted in segments with AES-GCM, under a key derived with
+// HKDF from the owner's key and a random salt stored at the head of the
+// stream.  Every owner key encrypts a great many items, so each stream
+// gets its own key rather than a random nonce, which would collide too
+// often at that scale.  Each segment's nonce is the segment's index and a
+// flag marking the final segment, which prevents segments from being
+// reordered or the stream from being truncated.
+
+func ownerAEAD(key, salt []byte) (cipher.AEAD, error) {
+	streamKey := make([]byte, ownerKeySize)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, ownerStreamPurpose), streamKey); err != nil {
+		return nil, errors.Wrap(err, "deriving owner stream key")
+	}
+
+	block, err := aes.NewCipher(streamKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating owner cipher")
+	}
+
+	return cipher.NewGCM(block)
+}
+
+type ownerSegmenter struct {
+	aead  cipher.AEAD
+	index uint32
+}
+
+func (s *ownerSegmenter) nonce(last bool) []byte {
+	n := make([]byte, s.aead.NonceSize())
+	binary.BigEndian.PutUint32(n[len(n)-5:], s.index)
+
+	if last {
+		n[len(n)-1] = 1
+	}
+
+	return n
+}
+
+// nextSegment reads up to size bytes into buf, reporting whether the
+// segment is the last in the stream.
+func nextSegment(r *bufio.Reader, buf []byte) ([]byte, bool, error) {
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return buf[:n], true, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := r.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return buf, true, nil
+		}
+
+		return nil, false, err
+	}
+
+	return buf, false, nil
+}
+
+type ownerEncryptReader struct {
+	ownerSegmenter
+	src    *bufio.Reader
+	closer io.Closer
+	plain  []byte
+	out    []byte
+	done   bool
+}
+
+func newOwnerEncryptReader(key []byte, keyRef string, r io.ReadCloser) (*ownerEncryptReader, error) {
+	salt := make([]byte, ownerStreamSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "generating stream salt")
+	}
+
+	aead, err := ownerAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ownerEncryptReader{
+		ownerSegmenter: ownerSegmenter{aead: aead},
+		src:            bufio.NewReader(r),
+		closer:         r,
+		plain:          make([]byte, ownerSegmentSize),
+		out:            append([]byte(keyRef), salt...),
+	}, nil
+}
+
+func (er *ownerEncryptReader) Read(p []byte) (int, error) {
+	if len(er.out) == 0 {
+		if er.done {
+			return 0, io.EOF
+		}
+
+		seg, last, err := nextSegment(er.src, er.plain)
+		if err != nil {
+			return 0, err
+		}
+
+		er.out = er.aead.Seal(er.out[:0], er.nonce(last), seg, nil)
+		er.index++
+		er.done = last
+	}
+
+	n := copy(p, er.out)
+	er.out = er.out[n:]
+
+	return n, nil
+}
+
+func (er *ownerEncryptReader) Close() error {
+	return er.closer.Close()
+}
+
+type ownerDecryptReader struct {
+	ownerSegmenter
+	src    *bufio.Reader
+	sealed []byte
+	out    []byte
+	done   bool
+}
+
+func newOwnerDecryptReader(key, salt []byte, r io.Reader) (*ownerDecryptReader, error) {
+	aead, err := ownerAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ownerDecryptReader{
+		ownerSegmenter: ownerSegmenter{aead: aead},
+		src:            bufio.NewReader(r),
+		sealed:         make([]byte, ownerSegmentSize+ownerSegmentOverhead),
+	}, nil
+}
+
+func (dr *ownerDecryptReader) Read(p []byte) (int, error) {
+	if len(dr.out) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+
+		seg, last, err := nextSegment(dr.src, dr.sealed)
+		if err != nil {
+			return 0, err
+		}
+
+		dr.out, err = dr.aead.Open(seg[:0], dr.nonce(last), seg, nil)
+		if err != nil {
+			return 0, errors.Wrap(err, "decrypting owner encrypted data")
+		}
+
+		dr.index++
+		dr.done = last
+	}
+
+	n := copy(p, dr.out)
+	dr.out = dr.out[n:]
+
+	return n, nil
+}
+
+// blobBuffer implements kopia's blob.OutputBuffer.
+type blobBuffer struct {
+	bytes.Buffer
+}
+
+func (b *blobBuffer) Length() int {
+	return b.Len()
+}
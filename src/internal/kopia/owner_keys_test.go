@@ -0,0 +1,353 @@
+package kopia
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// ---------------
+// unit tests
+// ---------------
+type OwnerKeysUnitSuite struct {
+	tester.Suite
+}
+
+func TestOwnerKeysUnitSuite(t *testing.T) {
+	suite.Run(t, &OwnerKeysUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func testOwnerKey(t *testing.T) []byte {
+	key := make([]byte, ownerKeySize)
+
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	return key
+}
+
+const testKeyRef = "0123456789abcdef0123456789abcdef-0123456789abcdef"
+
+func encryptForTest(t *testing.T, key, plain []byte) []byte {
+	er, err := newOwnerEncryptReader(key, testKeyRef, io.NopCloser(bytes.NewReader(plain)))
+	require.NoError(t, err)
+
+	// adversarial short reads exercise the segment buffering.
+	enc, _ := readAllInParts(t, 1000, er)
+
+	return enc
+}
+
+func decryptForTest(key, enc []byte) ([]byte, error) {
+	dr, err := newOwnerDecryptReader(
+		key,
+		enc[ownerKeyRefSize:ownerStreamHeadLen],
+		bytes.NewReader(enc[ownerStreamHeadLen:]))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(dr)
+}
+
+func (suite *OwnerKeysUnitSuite) TestRoundTrip() {
+	table := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"small", 42},
+		{"one segment", ownerSegmentSize},
+		{"segment and a byte", ownerSegmentSize + 1},
+		{"many segments", 3*ownerSegmentSize + 7},
+		{"many full segments", 3 * ownerSegmentSize},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			var (
+				t     = suite.T()
+				key   = testOwnerKey(t)
+				plain = make([]byte, test.size)
+			)
+
+			_, err := rand.Read(plain)
+			require.NoError(t, err)
+
+			enc := encryptForTest(t, key, plain)
+			assert.Equal(t, testKeyRef, string(enc[:ownerKeyRefSize]))
+			assert.Equal(t, int64(test.size), ownerPlainSize(int64(len(enc))))
+
+			if test.size > 0 {
+				assert.False(t, bytes.Contains(enc, plain), "ciphertext contains the plaintext")
+			}
+
+			result, err := decryptForTest(key, enc)
+			require.NoError(t, err)
+			assert.Equal(t, plain, result)
+		})
+	}
+}
+
+func (suite *OwnerKeysUnitSuite) TestEncrypt_keyPerStream() {
+	var (
+		t     = suite.T()
+		key   = testOwnerKey(t)
+		plain = bytes.Repeat([]byte("corso"), 100)
+		a     = encryptForTest(t, key, plain)
+		b     = encryptForTest(t, key, plain)
+	)
+
+	assert.NotEqual(
+		t,
+		a[ownerKeyRefSize:ownerStreamHeadLen],
+		b[ownerKeyRefSize:ownerStreamHeadLen],
+		"stream salts")
+	assert.NotEqual(t, a[ownerStreamHeadLen:], b[ownerStreamHeadLen:], "ciphertexts")
+}
+
+func (suite *OwnerKeysUnitSuite) TestDecrypt_rejectsTampering() {
+	var (
+		t     = suite.T()
+		key   = testOwnerKey(t)
+		plain = bytes.Repeat([]byte("corso"), ownerSegmentSize/2)
+		enc   = encryptForTest(t, key, plain)
+		seg   = ownerSegmentSize + ownerSegmentOverhead
+	)
+
+	table := []struct {
+		name string
+		key  []byte
+		data func() []byte
+	}{
+		{
+			name: "wrong key",
+			key:  testOwnerKey(t),
+			data: func() []byte { return enc },
+		},
+		{
+			name: "flipped bit",
+			key:  key,
+			data: func() []byte {
+				d := append([]byte{}, enc...)
+				d[len(d)-1] ^= 1
+
+				return d
+			},
+		},
+		{
+			name: "altered salt",
+			key:  key,
+			data: func() []byte {
+				d := append([]byte{}, enc...)
+				d[ownerKeyRefSize] ^= 1
+
+				return d
+			},
+		},
+		{
+			name: "truncated to a segment boundary",
+			key:  key,
+			data: func() []byte { return enc[:ownerStreamHeadLen+seg] },
+		},
+		{
+			name: "no segments",
+			key:  key,
+			data: func() []byte { return enc[:ownerStreamHeadLen] },
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			_, err := decryptForTest(test.key, test.data())
+			assert.Error(suite.T(), err)
+		})
+	}
+}
+
+func (suite *OwnerKeysUnitSuite) TestRestoreStreamReader_decrypts() {
+	var (
+		t     = suite.T()
+		key   = testOwnerKey(t)
+		plain = []byte("some data for the owner")
+	)
+
+	decrypt := func(r io.Reader) (io.Reader, error) {
+		head := make([]byte, ownerStreamHeadLen)
+		if _, err := io.ReadFull(r, head); err != nil {
+			return nil, err
+		}
+
+		return newOwnerDecryptReader(key, head[ownerKeyRefSize:], r)
+	}
+
+	table := []struct {
+		name    string
+		decrypt func(io.Reader) (io.Reader, error)
+		check   assert.ErrorAssertionFunc
+	}{
+		{"without decrypter", nil, assert.Error},
+		{"with decrypter", decrypt, assert.NoError},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			enc := encryptForTest(t, key, plain)
+
+			rr := &restoreStreamReader{
+				ReadCloser:      newBackupStreamReader(ownerEncryptedVersion, io.NopCloser(bytes.NewReader(enc))),
+				expectedVersion: serializationVersion,
+				decrypt:         test.decrypt,
+			}
+
+			result, err := io.ReadAll(rr)
+			test.check(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, plain, result)
+		})
+	}
+}
+
+// ---------------
+// integration tests
+// ---------------
+func (suite *OwnerKeysUnitSuite) TestDropErasedBases() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	var (
+		now   = time.Now()
+		alice = Reason{ResourceOwner: "alice", Service: path.ExchangeService, Category: path.EmailCategory}
+		bob   = Reason{ResourceOwner: "bob", Service: path.ExchangeService, Category: path.EmailCategory}
+		entry = func(id string, start time.Time, reasons ...Reason) *ManifestEntry {
+			return &ManifestEntry{
+				Manifest: &snapshot.Manifest{ID: manifest.ID(id), StartTime: fs.UTCTimestamp(start.UnixNano())},
+				Reasons:  reasons,
+			}
+		}
+		erasedAt = func(_ context.Context, owner string) (time.Time, error) {
+			if owner == "alice" {
+				return now, nil
+			}
+
+			return time.Time{}, nil
+		}
+	)
+
+	mans, err := dropErasedBases(
+		ctx,
+		[]*ManifestEntry{
+			entry("before", now.Add(-time.Hour), alice),
+			entry("shared", now.Add(-time.Hour), alice, bob),
+			entry("after", now.Add(time.Hour), alice),
+		},
+		erasedAt)
+	require.NoError(t, err)
+	require.Len(t, mans, 2)
+
+	assert.Equal(t, manifest.ID("shared"), mans[0].ID)
+	assert.Equal(t, []Reason{bob}, mans[0].Reasons, "erased owner isn't merged from older bases")
+	assert.Equal(t, manifest.ID("after"), mans[1].ID)
+	assert.Equal(t, []Reason{alice}, mans[1].Reasons, "bases made after the erasure are kept")
+
+	_, err = dropErasedBases(
+		ctx,
+		[]*ManifestEntry{entry("before", now, alice)},
+		func(context.Context, string) (time.Time, error) { return time.Time{}, assert.AnError })
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+type OwnerKeysIntegrationSuite struct {
+	tester.Suite
+}
+
+func TestOwnerKeysIntegrationSuite(t *testing.T) {
+	suite.Run(t, &OwnerKeysIntegrationSuite{
+		Suite: tester.NewIntegrationSuite(
+			t,
+			[][]string{tester.AWSStorageCredEnvs},
+			tester.CorsoKopiaWrapperTests,
+		),
+	})
+}
+
+func (suite *OwnerKeysIntegrationSuite) TestKeyLifecycle() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	k, err := openKopiaRepo(t, ctx)
+	require.NoError(t, err)
+
+	defer k.Close(ctx)
+
+	oks, err := newOwnerKeys(k.Repository)
+	require.NoError(t, err)
+
+	plain := []byte("alice's data")
+
+	r, err := oks.encryptReader(ctx, "alice", io.NopCloser(bytes.NewReader(plain)))
+	require.NoError(t, err)
+
+	enc, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	read := func(ctx context.Context, oks *ownerKeys, owner string) ([]byte, error) {
+		rr := &restoreStreamReader{
+			ReadCloser:      io.NopCloser(bytes.NewReader(enc)),
+			expectedVersion: serializationVersion,
+			decrypt:         oks.decrypter(ctx, owner),
+		}
+
+		return io.ReadAll(rr)
+	}
+
+	// a fresh set of keys, as used by another connection, reads the data.
+	other, err := newOwnerKeys(k.Repository)
+	require.NoError(t, err)
+
+	result, err := read(ctx, other, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, plain, result)
+
+	// other owners can't read the data.
+	_, err = read(ctx, other, "bob")
+	assert.Error(t, err)
+
+	n, err := oks.erase(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	erased, err := newOwnerKeys(k.Repository)
+	require.NoError(t, err)
+
+	_, err = read(ctx, erased, "alice")
+	assert.ErrorIs(t, err, ErrOwnerKeyNotFound)
+
+	at, err := erased.erasedAt(ctx, "alice")
+	require.NoError(t, err)
+	assert.False(t, at.IsZero(), "erasure is recorded")
+
+	at, err = erased.erasedAt(ctx, "bob")
+	require.NoError(t, err)
+	assert.True(t, at.IsZero(), "owner was never erased")
+}
@@ -0,0 +1,189 @@
+package kopia
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// largeItemSize is the size at which items are read ahead of the upload.
+	largeItemSize = 1 << 30
+	// readAheadSegmentSize is the size of each segment read ahead of the
+	// upload.
+	readAheadSegmentSize = 32 << 20
+	// readAheadSegments is the number of segments held at once.  Bounds the
+	// memory used by each large item to readAheadSegments*readAheadSegmentSize.
+	readAheadSegments = 4
+	// readAheadCloseWait bounds how long Close waits on a read of the source
+	// that doesn't return once the source is closed.
+	readAheadCloseWait = 30 * time.Second
+)
+
+var errReadAheadClosed = errors.New("read ahead reader closed")
+
+// segment is a portion of the source read ahead of the consumer.
+type segment struct {
+	buf []byte
+	// err is the error produced while reading the segment, reported
+	// once the segment's data is consumed.
+	err error
+}
+
+// readAheadReader reads its source in segments on a separate goroutine,
+// ahead of the consumer.  Kopia hashes, compresses, and uploads a streamed
+// item in the same goroutine that reads the item, which leaves very large
+// items bound by the sum of their download and upload times.  Reading
+// ahead lets the next segments of the item get downloaded while kopia
+// uploads the current one.
+//
+// Reading doesn't begin until the first call to Read, so items that kopia
+// skips as unchanged are never downloaded.
+type readAheadReader struct {
+	src     io.ReadCloser
+	segSize int
+	// closeWait bounds how long Close waits on the reading goroutine.
+	closeWait time.Duration
+
+	startOnce sync.Once
+	closeOnce sync.Once
+	started   bool
+
+	segs chan segment
+	// free holds the buffers available to the reading goroutine.  A nil
+	// buffer gets allocated on first use.
+	free chan []byte
+	stop chan struct{}
+	done chan struct{}
+
+	cur segment
+	off int
+}
+
+func newReadAheadReader(src io.ReadCloser, segSize, segments int) *readAheadReader {
+	if segments < 2 {
+		segments = 2
+	}
+
+	r := &readAheadReader{
+		src:       src,
+		segSize:   segSize,
+		closeWait: readAheadCloseWait,
+		segs:      make(chan segment, segments),
+		free:      make(chan []byte, segments),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	for i := 0; i < segments; i++ {
+		r.free <- nil
+	}
+
+	return r
+}
+
+func (r *readAheadReader) start() {
+	r.started = true
+	go r.fill()
+}
+
+// fill reads the source into segments until the source is exhausted,
+// fails, or the reader is closed.
+func (r *readAheadReader) fill() {
+	defer close(r.done)
+	defer close(r.segs)
+
+	for {
+		var buf []byte
+
+		select {
+		case buf = <-r.free:
+		case <-r.stop:
+			return
+		}
+
+		if buf == nil {
+			buf = make([]byte, r.segSize)
+		}
+
+		n, err := io.ReadFull(r.src, buf)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+
+		select {
+		case r.segs <- segment{buf: buf[:n], err: err}:
+		case <-r.stop:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	r.startOnce.Do(r.start)
+
+	for r.off >= len(r.cur.buf) {
+		if r.cur.err != nil {
+			return 0, r.cur.err
+		}
+
+		if r.cur.buf != nil {
+			r.free <- r.cur.buf[:cap(r.cur.buf)]
+		}
+
+		var (
+			seg segment
+			ok  bool
+		)
+
+		select {
+		case seg, ok = <-r.segs:
+		case <-r.stop:
+		}
+
+		if !ok {
+			return 0, errReadAheadClosed
+		}
+
+		r.cur, r.off = seg, 0
+	}
+
+	n := copy(p, r.cur.buf[r.off:])
+	r.off += n
+
+	return n, nil
+}
+
+// Close stops reading ahead and closes the source.  Any segments that
+// were read ahead of the consumer are discarded.
+func (r *readAheadReader) Close() error {
+	var err error
+
+	r.closeOnce.Do(func() {
+		// prevent a later Read from starting the goroutine.
+		r.startOnce.Do(func() {})
+
+		close(r.stop)
+
+		// close the source before waiting on the reading goroutine, so that
+		// a read stalled on the network returns instead of holding up the
+		// close.  Sources whose reads ignore the close are waited on for a
+		// bounded time; their goroutine exits once the read returns.
+		err = r.src.Close()
+
+		if r.started {
+			select {
+			case <-r.done:
+			case <-time.After(r.closeWait):
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,193 @@
+package kopia
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ReadAheadUnitSuite struct {
+	tester.Suite
+}
+
+func TestReadAheadUnitSuite(t *testing.T) {
+	suite.Run(t, &ReadAheadUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// trackedReader records whether it was read or closed.
+type trackedReader struct {
+	io.Reader
+	reads  int
+	closed bool
+}
+
+func (tr *trackedReader) Read(p []byte) (int, error) {
+	tr.reads++
+	return tr.Reader.Read(p)
+}
+
+func (tr *trackedReader) Close() error {
+	tr.closed = true
+	return nil
+}
+
+func (suite *ReadAheadUnitSuite) TestRead() {
+	table := []struct {
+		name     string
+		size     int
+		segments int
+	}{
+		{"empty", 0, 2},
+		{"smaller than a segment", 5, 2},
+		{"exact segments", 32, 2},
+		{"partial last segment", 37, 3},
+		{"more segments than buffers", 100, 2},
+		{"minimum buffers", 20, 0},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			content := bytes.Repeat([]byte("abcdefg"), test.size)[:test.size]
+			src := &trackedReader{Reader: iotest.HalfReader(bytes.NewReader(content))}
+
+			r := newReadAheadReader(src, 8, test.segments)
+
+			// exercise reads that don't align with segments.
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			require.NoError(t, err)
+			assert.Equal(t, content, got)
+
+			require.NoError(t, r.Close())
+			assert.True(t, src.closed)
+		})
+	}
+}
+
+func (suite *ReadAheadUnitSuite) TestRead_error() {
+	t := suite.T()
+	src := &trackedReader{
+		Reader: io.MultiReader(bytes.NewReader([]byte("0123456789")), iotest.ErrReader(assert.AnError)),
+	}
+
+	r := newReadAheadReader(src, 4, 2)
+
+	got, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, []byte("0123456789"), got, "data read before the failure is kept")
+
+	require.NoError(t, r.Close())
+}
+
+func (suite *ReadAheadUnitSuite) TestLazy() {
+	t := suite.T()
+	src := &trackedReader{Reader: bytes.NewReader([]byte("content"))}
+
+	r := newReadAheadReader(src, 4, 2)
+	require.NoError(t, r.Close())
+
+	assert.Zero(t, src.reads, "source never read")
+	assert.True(t, src.closed)
+
+	_, err := r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, errReadAheadClosed)
+}
+
+func (suite *ReadAheadUnitSuite) TestClose_midStream() {
+	t := suite.T()
+	src := &trackedReader{Reader: bytes.NewReader(bytes.Repeat([]byte("a"), 1000))}
+
+	r := newReadAheadReader(src, 4, 2)
+
+	_, err := r.Read(make([]byte, 2))
+	require.NoError(t, err)
+
+	// must not hang on the reading goroutine, which is blocked on
+	// handing off segments that will never be read.
+	require.NoError(t, r.Close())
+	assert.True(t, src.closed)
+}
+
+// stalledReader blocks reads, as a stalled network read would, until it's
+// released.  Closing the reader releases it unless ignoreClose is set.
+type stalledReader struct {
+	reading     chan struct{}
+	release     chan struct{}
+	releaseOnce sync.Once
+	ignoreClose bool
+}
+
+func newStalledReader(ignoreClose bool) *stalledReader {
+	return &stalledReader{
+		reading:     make(chan struct{}),
+		release:     make(chan struct{}),
+		ignoreClose: ignoreClose,
+	}
+}
+
+func (sr *stalledReader) Read(p []byte) (int, error) {
+	close(sr.reading)
+	<-sr.release
+
+	return 0, io.ErrClosedPipe
+}
+
+func (sr *stalledReader) unblock() {
+	sr.releaseOnce.Do(func() { close(sr.release) })
+}
+
+func (sr *stalledReader) Close() error {
+	if !sr.ignoreClose {
+		sr.unblock()
+	}
+
+	return nil
+}
+
+func (suite *ReadAheadUnitSuite) TestClose_stalledSource() {
+	table := []struct {
+		name        string
+		ignoreClose bool
+	}{
+		{"source unblocks on close", false},
+		{"source ignores close", true},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			src := newStalledReader(test.ignoreClose)
+
+			defer src.unblock()
+
+			r := newReadAheadReader(src, 4, 2)
+			r.closeWait = 100 * time.Millisecond
+
+			go func() {
+				_, _ = r.Read(make([]byte, 1))
+			}()
+
+			<-src.reading
+
+			closed := make(chan error)
+
+			go func() {
+				closed <- r.Close()
+			}()
+
+			select {
+			case err := <-closed:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				assert.Fail(t, "close hung on the stalled source")
+			}
+		})
+	}
+}
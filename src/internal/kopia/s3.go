@@ -1,11 +1,16 @@
 package kopia
 
 import (
+	"bytes"
 	"context"
+	"io"
 
 	"github.com/alcionai/clues"
 	"github.com/kopia/kopia/repo/blob"
 	"github.com/kopia/kopia/repo/blob/s3"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/format"
+	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/pkg/storage"
 )
@@ -40,3 +45,110 @@ func s3BlobStorage(ctx context.Context, s storage.Storage) (blob.Storage, error)
 
 	return store, nil
 }
+
+// blob ID prefixes of kopia's index blobs: "x" for epoch-managed indexes
+// and "n" for legacy indexes.
+const (
+	indexBlobPrefix       blob.ID = "x"
+	legacyIndexBlobPrefix blob.ID = "n"
+)
+
+// s3StorageConfig maps the configured storage classes onto kopia's blob ID
+// prefixes.  Item data is stored in regular pack blobs, while directory
+// and manifest contents are stored in special pack blobs alongside the
+// indexes.  Returns nil if no storage class is configured.
+func s3StorageConfig(cfg storage.S3Config) *s3.StorageConfig {
+	if len(cfg.DataStorageClass) == 0 && len(cfg.MetadataStorageClass) == 0 {
+		return nil
+	}
+
+	sc := &s3.StorageConfig{}
+
+	if len(cfg.DataStorageClass) > 0 {
+		sc.BlobOptions = append(sc.BlobOptions, s3.PrefixAndStorageClass{
+			Prefix:       content.PackBlobIDPrefixRegular,
+			StorageClass: cfg.DataStorageClass,
+		})
+	}
+
+	if len(cfg.MetadataStorageClass) > 0 {
+		for _, p := range []blob.ID{content.PackBlobIDPrefixSpecial, indexBlobPrefix, legacyIndexBlobPrefix} {
+			sc.BlobOptions = append(sc.BlobOptions, s3.PrefixAndStorageClass{
+				Prefix:       p,
+				StorageClass: cfg.MetadataStorageClass,
+			})
+		}
+	}
+
+	return sc
+}
+
+// writeS3StorageConfig persists the configured storage classes within the
+// bucket, where kopia reads them each time the storage is opened.  Must be
+// called before the repository is initialized so that all blobs land in
+// their configured class.  Does nothing if the repository already exists.
+func writeS3StorageConfig(ctx context.Context, s storage.Storage) error {
+	cfg, err := s.S3Config()
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	sc := s3StorageConfig(cfg)
+	if sc == nil {
+		return nil
+	}
+
+	bst, err := s3BlobStorage(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer bst.Close(ctx)
+
+	// leave existing repositories untouched; initialization will
+	// report that the repo already exists.
+	_, err = bst.GetMetadata(ctx, format.KopiaRepositoryBlobID)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, blob.ErrBlobNotFound) {
+		return clues.Wrap(err, "checking for existing repo").WithClues(ctx)
+	}
+
+	var buf bytes.Buffer
+
+	if err := sc.Save(&buf); err != nil {
+		return clues.Wrap(err, "serializing storage config").WithClues(ctx)
+	}
+
+	err = bst.PutBlob(ctx, s3.ConfigName, configBytes(buf.Bytes()), blob.PutOptions{})
+	if err != nil {
+		return clues.Wrap(err, "writing storage config").WithClues(ctx)
+	}
+
+	return nil
+}
+
+// configBytes implements kopia's blob.Bytes over a byte slice.
+type configBytes []byte
+
+func (b configBytes) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (b configBytes) Length() int {
+	return len(b)
+}
+
+func (b configBytes) Reader() io.ReadSeekCloser {
+	return nopCloser{bytes.NewReader(b)}
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
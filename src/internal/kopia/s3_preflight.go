@@ -0,0 +1,527 @@
+package kopia
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/sse"
+
+	"github.com/alcionai/corso/src/pkg/storage"
+)
+
+// PreflightStatus is the outcome of a single pre-flight check.
+type PreflightStatus string
+
+const (
+	PreflightPassed  PreflightStatus = "passed"
+	PreflightWarning PreflightStatus = "warning"
+	PreflightFailed  PreflightStatus = "failed"
+	// PreflightSkipped checks couldn't be evaluated, usually because the
+	// credentials can't read the bucket's configuration.
+	PreflightSkipped PreflightStatus = "skipped"
+)
+
+// PreflightCheck is a single entry in the pre-flight checklist.
+type PreflightCheck struct {
+	Name   string          `json:"name"`
+	Status PreflightStatus `json:"status"`
+	Detail string          `json:"detail"`
+	// Remediation describes how to resolve a warning or failure.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Preflight is the checklist produced by validating a bucket before a
+// repository is created within it.
+type Preflight []PreflightCheck
+
+// Failed returns true if any check failed.  Warnings don't fail the
+// pre-flight.
+func (p Preflight) Failed() bool {
+	for _, c := range p {
+		if c.Status == PreflightFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Warned returns true if any check produced a warning.
+func (p Preflight) Warned() bool {
+	for _, c := range p {
+		if c.Status == PreflightWarning {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	preflightVersioning  = "Bucket versioning"
+	preflightEncryption  = "Bucket encryption"
+	preflightLifecycle   = "Lifecycle rules"
+	preflightObjectLock  = "Object lock"
+	preflightPermissions = "Permission: "
+)
+
+// s3 error codes returned when the bucket has no such configuration.
+const (
+	errCodeNoLifecycle    = "NoSuchLifecycleConfiguration"
+	errCodeNoEncryption   = "ServerSideEncryptionConfigurationNotFoundError"
+	errCodeNoObjectLock   = "ObjectLockConfigurationNotFoundError"
+	errCodeAccessDenied   = "AccessDenied"
+	errCodeNotImplemented = "NotImplemented"
+)
+
+// PreflightS3 validates the bucket's configuration and the credentials'
+// permissions ahead of creating a repository.  Configurations which
+// conflict with corso's data removal (maintenance, retention, and owner
+// erasure) produce warnings, while missing permissions produce failures.
+// Returns an error only if the bucket can't be reached at all.
+func PreflightS3(ctx context.Context, s storage.Storage) (Preflight, error) {
+	cfg, err := s.S3Config()
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	ctx = clues.Add(ctx, "bucket", cfg.Bucket)
+
+	cli, err := preflightClient(cfg)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating s3 client").WithClues(ctx)
+	}
+
+	ok, err := cli.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, clues.Wrap(err, "checking bucket").WithClues(ctx)
+	}
+
+	if !ok {
+		return nil, clues.New("bucket does not exist").WithClues(ctx)
+	}
+
+	var (
+		p  = Preflight{}
+		lc *lifecycle.Configuration
+	)
+
+	p = append(p, preflightPermissionChecks(ctx, cli, cfg)...)
+
+	lc, err = cli.GetBucketLifecycle(ctx, cfg.Bucket)
+	if err != nil && errCode(err) != errCodeNoLifecycle {
+		p = append(p, skippedCheck(preflightLifecycle, "s3:GetLifecycleConfiguration", err))
+	} else {
+		p = append(p, checkLifecycle(lc, cfg.Prefix))
+	}
+
+	versioning, err := cli.GetBucketVersioning(ctx, cfg.Bucket)
+	if err != nil {
+		p = append(p, skippedCheck(preflightVersioning, "s3:GetBucketVersioning", err))
+	} else {
+		p = append(p, checkVersioning(versioning, lc, cfg.Prefix))
+	}
+
+	enc, err := cli.GetBucketEncryption(ctx, cfg.Bucket)
+	if err != nil && errCode(err) != errCodeNoEncryption {
+		p = append(p, skippedCheck(preflightEncryption, "s3:GetEncryptionConfiguration", err))
+	} else {
+		p = append(p, checkEncryption(enc))
+	}
+
+	lockEnabled, mode, validity, unit, err := cli.GetObjectLockConfig(ctx, cfg.Bucket)
+	if err != nil && errCode(err) != errCodeNoObjectLock {
+		p = append(p, skippedCheck(preflightObjectLock, "s3:GetBucketObjectLockConfiguration", err))
+	} else {
+		p = append(p, checkObjectLock(lockEnabled, mode, validity, unit))
+	}
+
+	return p, nil
+}
+
+// ErasureRetention checks whether the bucket keeps the objects which corso
+// deletes, through versioning or an object lock retention.  Retained
+// objects include the salts of erased owner keys, which keep the owners'
+// data recoverable.  Returns only the checks that warn, or that couldn't be
+// evaluated.
+func ErasureRetention(ctx context.Context, s storage.Storage) (Preflight, error) {
+	cfg, err := s.S3Config()
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	ctx = clues.Add(ctx, "bucket", cfg.Bucket)
+
+	cli, err := preflightClient(cfg)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating s3 client").WithClues(ctx)
+	}
+
+	p := Preflight{}
+
+	versioning, err := cli.GetBucketVersioning(ctx, cfg.Bucket)
+	if err != nil {
+		p = append(p, skippedCheck(preflightVersioning, "s3:GetBucketVersioning", err))
+	} else if c := checkErasureVersioning(versioning); c.Status != PreflightPassed {
+		p = append(p, c)
+	}
+
+	lockEnabled, mode, validity, unit, err := cli.GetObjectLockConfig(ctx, cfg.Bucket)
+	if err != nil && errCode(err) != errCodeNoObjectLock {
+		p = append(p, skippedCheck(preflightObjectLock, "s3:GetBucketObjectLockConfiguration", err))
+	} else if c := checkObjectLock(lockEnabled, mode, validity, unit); c.Status != PreflightPassed {
+		p = append(p, c)
+	}
+
+	return p, nil
+}
+
+// preflightClient creates a client using the same credential chain as
+// kopia's s3 storage.
+func preflightClient(cfg storage.S3Config) (*minio.Client, error) {
+	endpoint := defaultS3Endpoint
+	if len(cfg.Endpoint) > 0 {
+		endpoint = cfg.Endpoint
+	}
+
+	opts := &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		}),
+		Secure: !cfg.DoNotUseTLS,
+	}
+
+	if cfg.DoNotVerifyTLS {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		//nolint:gosec
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		opts.Transport = t
+	}
+
+	return minio.New(endpoint, opts)
+}
+
+// preflightPermissionChecks writes, reads, lists, and deletes a probe
+// object within the repository prefix.
+func preflightPermissionChecks(ctx context.Context, cli *minio.Client, cfg storage.S3Config) []PreflightCheck {
+	var (
+		key     = cfg.Prefix + "corso-preflight-" + uuid.NewString()
+		content = []byte("corso pre-flight check")
+		checks  = make([]PreflightCheck, 0, 4)
+	)
+
+	check := func(name, action string, err error) PreflightCheck {
+		c := PreflightCheck{Name: preflightPermissions + name}
+
+		if err == nil {
+			c.Status = PreflightPassed
+			c.Detail = "allowed"
+
+			return c
+		}
+
+		c.Status = PreflightFailed
+		c.Detail = err.Error()
+		c.Remediation = fmt.Sprintf(
+			"Grant %s on arn:aws:s3:::%s/%s* to the credentials used by corso.",
+			action, cfg.Bucket, cfg.Prefix)
+
+		return c
+	}
+
+	_, putErr := cli.PutObject(
+		ctx,
+		cfg.Bucket,
+		key,
+		bytes.NewReader(content),
+		int64(len(content)),
+		minio.PutObjectOptions{})
+	checks = append(checks, check("put", "s3:PutObject", putErr))
+
+	// nothing to read or delete without a probe object.
+	requiresPut := func(name string) PreflightCheck {
+		return PreflightCheck{
+			Name:   preflightPermissions + name,
+			Status: PreflightSkipped,
+			Detail: "requires the put permission",
+		}
+	}
+
+	if putErr != nil {
+		checks = append(checks, requiresPut("get"))
+	} else {
+		checks = append(checks, check("get", "s3:GetObject", getProbe(ctx, cli, cfg.Bucket, key, content)))
+	}
+
+	checks = append(checks, check("list", "s3:ListBucket", listProbe(ctx, cli, cfg.Bucket, cfg.Prefix)))
+
+	if putErr != nil {
+		checks = append(checks, requiresPut("delete"))
+	} else {
+		err := cli.RemoveObject(ctx, cfg.Bucket, key, minio.RemoveObjectOptions{})
+		checks = append(checks, check("delete", "s3:DeleteObject", err))
+	}
+
+	return checks
+}
+
+func getProbe(ctx context.Context, cli *minio.Client, bucket, key string, expect []byte) error {
+	obj, err := cli.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer obj.Close()
+
+	bs, err := io.ReadAll(obj)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(bs, expect) {
+		return clues.New("read back unexpected content")
+	}
+
+	return nil
+}
+
+// listProbe lists a single object within the prefix.
+func listProbe(ctx context.Context, cli *minio.Client, bucket, prefix string) error {
+	// cancelling stops the listing after the first page.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	obj, ok := <-cli.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, MaxKeys: 1})
+	if !ok {
+		return nil
+	}
+
+	return obj.Err
+}
+
+// checkLifecycle warns about enabled rules which expire current objects
+// or transition them to archival storage within the repository prefix.
+// Either makes repository blobs unreadable, corrupting the repository.
+func checkLifecycle(lc *lifecycle.Configuration, prefix string) PreflightCheck {
+	c := PreflightCheck{Name: preflightLifecycle}
+
+	var conflicts []string
+
+	if lc != nil {
+		for _, r := range lc.Rules {
+			if r.Status != "Enabled" || !rulePrefixOverlaps(r, prefix) {
+				continue
+			}
+
+			if !r.Expiration.IsDaysNull() || !r.Expiration.IsDateNull() {
+				conflicts = append(conflicts, fmt.Sprintf("rule %q expires objects", r.ID))
+			}
+
+			if !r.Transition.IsNull() && archivalStorageClass(r.Transition.StorageClass) {
+				conflicts = append(conflicts, fmt.Sprintf(
+					"rule %q transitions objects to %s",
+					r.ID, r.Transition.StorageClass))
+			}
+		}
+	}
+
+	if len(conflicts) == 0 {
+		c.Status = PreflightPassed
+		c.Detail = "no rules expire or archive repository objects"
+
+		return c
+	}
+
+	c.Status = PreflightWarning
+	c.Detail = strings.Join(conflicts, "; ")
+	c.Remediation = "Corso manages the lifetime of repository objects itself, and must be able to read them " +
+		"immediately.  Exclude the repository prefix from rules that expire objects or transition them to " +
+		"GLACIER or DEEP_ARCHIVE.  Configure the repository's data storage class to reduce storage costs instead."
+
+	return c
+}
+
+// checkVersioning warns when versioning retains the objects which corso
+// deletes, unless a lifecycle rule expires noncurrent versions.
+func checkVersioning(
+	v minio.BucketVersioningConfiguration,
+	lc *lifecycle.Configuration,
+	prefix string,
+) PreflightCheck {
+	c := PreflightCheck{Name: preflightVersioning}
+
+	if !v.Enabled() {
+		c.Status = PreflightPassed
+		c.Detail = "versioning is not enabled"
+
+		return c
+	}
+
+	if lc != nil {
+		for _, r := range lc.Rules {
+			if r.Status != "Enabled" || !rulePrefixOverlaps(r, prefix) {
+				continue
+			}
+
+			if r.NoncurrentVersionExpiration.NoncurrentDays > 0 {
+				c.Status = PreflightPassed
+				c.Detail = fmt.Sprintf(
+					"versioning is enabled, and rule %q expires noncurrent versions after %d days",
+					r.ID, r.NoncurrentVersionExpiration.NoncurrentDays)
+
+				return c
+			}
+		}
+	}
+
+	c.Status = PreflightWarning
+	c.Detail = "versioning is enabled, and noncurrent versions are never expired"
+	c.Remediation = "Objects deleted by repository maintenance, metadata retention, and owner erasure are kept " +
+		"as noncurrent versions, which grow storage costs and keep erased data recoverable.  Add a lifecycle " +
+		"rule that expires noncurrent versions, or suspend versioning."
+
+	return c
+}
+
+// checkErasureVersioning warns when versioning keeps the deleted salts of
+// erased owner keys as noncurrent versions.  Unlike maintenance, erasure
+// isn't satisfied by expiring noncurrent versions later.
+func checkErasureVersioning(v minio.BucketVersioningConfiguration) PreflightCheck {
+	c := PreflightCheck{Name: preflightVersioning}
+
+	if !v.Enabled() {
+		c.Status = PreflightPassed
+		c.Detail = "versioning is not enabled"
+
+		return c
+	}
+
+	c.Status = PreflightWarning
+	c.Detail = "versioning is enabled, so erased owner keys are kept as noncurrent versions"
+	c.Remediation = "Anyone with the repository password can recover the erased data from the noncurrent " +
+		"versions of the corso-owner-key- objects until they're removed.  Suspend versioning and delete the " +
+		"noncurrent versions of those objects to complete the erasure."
+
+	return c
+}
+
+// checkEncryption reports the bucket's default encryption.  Corso encrypts
+// all data before it's uploaded, so server side encryption is optional.
+func checkEncryption(enc *sse.Configuration) PreflightCheck {
+	c := PreflightCheck{Name: preflightEncryption, Status: PreflightPassed}
+
+	if enc == nil || len(enc.Rules) == 0 {
+		c.Detail = "no default encryption; corso encrypts all data client-side"
+		return c
+	}
+
+	apply := enc.Rules[0].Apply
+	c.Detail = "default encryption: " + apply.SSEAlgorithm
+
+	if strings.HasPrefix(apply.SSEAlgorithm, "aws:kms") {
+		c.Status = PreflightWarning
+		c.Remediation = "With SSE-KMS, every read and write calls KMS.  Grant kms:Decrypt and " +
+			"kms:GenerateDataKey on the key to the credentials used by corso, and enable S3 Bucket Keys " +
+			"to limit KMS request costs."
+	}
+
+	return c
+}
+
+// checkObjectLock warns when a default retention prevents corso from
+// deleting objects.
+func checkObjectLock(
+	enabled string,
+	mode *minio.RetentionMode,
+	validity *uint,
+	unit *minio.ValidityUnit,
+) PreflightCheck {
+	c := PreflightCheck{Name: preflightObjectLock}
+
+	if enabled != "Enabled" {
+		c.Status = PreflightPassed
+		c.Detail = "object lock is not enabled"
+
+		return c
+	}
+
+	if mode == nil || validity == nil || unit == nil {
+		c.Status = PreflightPassed
+		c.Detail = "object lock is enabled without a default retention"
+
+		return c
+	}
+
+	c.Status = PreflightWarning
+	c.Detail = fmt.Sprintf("default %s retention of %d %s", *mode, *validity, strings.ToLower(string(*unit)))
+	c.Remediation = "Locked objects can't be removed until their retention expires, so repository maintenance, " +
+		"metadata retention, and owner erasure can't reclaim space or erase data within that period.  " +
+		"Remove the default retention, or shorten it to the shortest period required by your policies."
+
+	return c
+}
+
+// rulePrefixOverlaps returns true if the lifecycle rule applies to any
+// object within the repository prefix.  Corso doesn't tag objects, so
+// rules filtered by tags never apply.
+func rulePrefixOverlaps(r lifecycle.Rule, prefix string) bool {
+	if !r.RuleFilter.Tag.IsEmpty() || len(r.RuleFilter.And.Tags) > 0 {
+		return false
+	}
+
+	rp := r.Prefix
+
+	switch {
+	case len(r.RuleFilter.Prefix) > 0:
+		rp = r.RuleFilter.Prefix
+	case len(r.RuleFilter.And.Prefix) > 0:
+		rp = r.RuleFilter.And.Prefix
+	}
+
+	return strings.HasPrefix(prefix, rp) || strings.HasPrefix(rp, prefix)
+}
+
+// archivalStorageClass returns true for storage classes which require a
+// restore before objects can be read.
+func archivalStorageClass(sc string) bool {
+	switch strings.ToUpper(sc) {
+	case "GLACIER", "DEEP_ARCHIVE":
+		return true
+	}
+
+	return false
+}
+
+// skippedCheck produces the check for a bucket configuration which
+// couldn't be retrieved.
+func skippedCheck(name, action string, err error) PreflightCheck {
+	c := PreflightCheck{
+		Name:   name,
+		Status: PreflightSkipped,
+		Detail: err.Error(),
+	}
+
+	switch errCode(err) {
+	case errCodeAccessDenied:
+		c.Remediation = "Grant " + action + " to the credentials used by corso, or verify the configuration manually."
+	case errCodeNotImplemented:
+		c.Detail = "not supported by the storage provider"
+	}
+
+	return c
+}
+
+func errCode(err error) string {
+	return minio.ToErrorResponse(err).Code
+}
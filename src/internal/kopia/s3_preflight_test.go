@@ -0,0 +1,271 @@
+package kopia
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type S3PreflightUnitSuite struct {
+	tester.Suite
+}
+
+func TestS3PreflightUnitSuite(t *testing.T) {
+	suite.Run(t, &S3PreflightUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func lifecycleRules(rules ...lifecycle.Rule) *lifecycle.Configuration {
+	return &lifecycle.Configuration{Rules: rules}
+}
+
+func (suite *S3PreflightUnitSuite) TestCheckLifecycle() {
+	table := []struct {
+		name   string
+		lc     *lifecycle.Configuration
+		prefix string
+		expect PreflightStatus
+	}{
+		{
+			name:   "no configuration",
+			expect: PreflightPassed,
+		},
+		{
+			name: "expiration",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "expire",
+				Status:     "Enabled",
+				Expiration: lifecycle.Expiration{Days: 30},
+			}),
+			expect: PreflightWarning,
+		},
+		{
+			name: "disabled expiration",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "expire",
+				Status:     "Disabled",
+				Expiration: lifecycle.Expiration{Days: 30},
+			}),
+			expect: PreflightPassed,
+		},
+		{
+			name: "delete marker expiration",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "markers",
+				Status:     "Enabled",
+				Expiration: lifecycle.Expiration{DeleteMarker: true},
+			}),
+			expect: PreflightPassed,
+		},
+		{
+			name: "expiration of another prefix",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "expire",
+				Status:     "Enabled",
+				RuleFilter: lifecycle.Filter{Prefix: "logs/"},
+				Expiration: lifecycle.Expiration{Days: 30},
+			}),
+			prefix: "corso/",
+			expect: PreflightPassed,
+		},
+		{
+			name: "expiration of a tag",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "expire",
+				Status:     "Enabled",
+				RuleFilter: lifecycle.Filter{Tag: lifecycle.Tag{Key: "tmp", Value: "true"}},
+				Expiration: lifecycle.Expiration{Days: 30},
+			}),
+			expect: PreflightPassed,
+		},
+		{
+			name: "archival transition",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "archive",
+				Status:     "Enabled",
+				RuleFilter: lifecycle.Filter{Prefix: "corso/"},
+				Transition: lifecycle.Transition{Days: 90, StorageClass: "DEEP_ARCHIVE"},
+			}),
+			prefix: "corso/",
+			expect: PreflightWarning,
+		},
+		{
+			name: "infrequent access transition",
+			lc: lifecycleRules(lifecycle.Rule{
+				ID:         "tier",
+				Status:     "Enabled",
+				Transition: lifecycle.Transition{Days: 90, StorageClass: "STANDARD_IA"},
+			}),
+			expect: PreflightPassed,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := checkLifecycle(test.lc, test.prefix)
+			assert.Equal(suite.T(), test.expect, result.Status, result.Detail)
+		})
+	}
+}
+
+func (suite *S3PreflightUnitSuite) TestCheckVersioning() {
+	var (
+		enabled  = minio.BucketVersioningConfiguration{Status: "Enabled"}
+		noncurEx = lifecycle.Rule{
+			ID:                          "noncurrent",
+			Status:                      "Enabled",
+			NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{NoncurrentDays: 7},
+		}
+	)
+
+	table := []struct {
+		name   string
+		v      minio.BucketVersioningConfiguration
+		lc     *lifecycle.Configuration
+		expect PreflightStatus
+	}{
+		{
+			name:   "not enabled",
+			expect: PreflightPassed,
+		},
+		{
+			name:   "suspended",
+			v:      minio.BucketVersioningConfiguration{Status: "Suspended"},
+			expect: PreflightPassed,
+		},
+		{
+			name:   "enabled",
+			v:      enabled,
+			expect: PreflightWarning,
+		},
+		{
+			name:   "enabled with noncurrent expiration",
+			v:      enabled,
+			lc:     lifecycleRules(noncurEx),
+			expect: PreflightPassed,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := checkVersioning(test.v, test.lc, "")
+			assert.Equal(suite.T(), test.expect, result.Status, result.Detail)
+		})
+	}
+}
+
+func (suite *S3PreflightUnitSuite) TestCheckErasureVersioning() {
+	table := []struct {
+		name   string
+		v      minio.BucketVersioningConfiguration
+		expect PreflightStatus
+	}{
+		{
+			name:   "not enabled",
+			expect: PreflightPassed,
+		},
+		{
+			name:   "suspended",
+			v:      minio.BucketVersioningConfiguration{Status: "Suspended"},
+			expect: PreflightPassed,
+		},
+		{
+			name:   "enabled",
+			v:      minio.BucketVersioningConfiguration{Status: "Enabled"},
+			expect: PreflightWarning,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := checkErasureVersioning(test.v)
+			assert.Equal(suite.T(), test.expect, result.Status, result.Detail)
+		})
+	}
+}
+
+func (suite *S3PreflightUnitSuite) TestCheckEncryption() {
+	table := []struct {
+		name   string
+		enc    *sse.Configuration
+		expect PreflightStatus
+	}{
+		{
+			name:   "none",
+			expect: PreflightPassed,
+		},
+		{
+			name:   "sse-s3",
+			enc:    sse.NewConfigurationSSES3(),
+			expect: PreflightPassed,
+		},
+		{
+			name:   "sse-kms",
+			enc:    sse.NewConfigurationSSEKMS("key"),
+			expect: PreflightWarning,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := checkEncryption(test.enc)
+			assert.Equal(suite.T(), test.expect, result.Status, result.Detail)
+		})
+	}
+}
+
+func (suite *S3PreflightUnitSuite) TestCheckObjectLock() {
+	var (
+		mode     = minio.Compliance
+		validity = uint(30)
+		unit     = minio.Days
+	)
+
+	table := []struct {
+		name     string
+		enabled  string
+		mode     *minio.RetentionMode
+		validity *uint
+		unit     *minio.ValidityUnit
+		expect   PreflightStatus
+	}{
+		{
+			name:   "not enabled",
+			expect: PreflightPassed,
+		},
+		{
+			name:    "no default retention",
+			enabled: "Enabled",
+			expect:  PreflightPassed,
+		},
+		{
+			name:     "default retention",
+			enabled:  "Enabled",
+			mode:     &mode,
+			validity: &validity,
+			unit:     &unit,
+			expect:   PreflightWarning,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := checkObjectLock(test.enabled, test.mode, test.validity, test.unit)
+			assert.Equal(suite.T(), test.expect, result.Status, result.Detail)
+		})
+	}
+}
+
+func (suite *S3PreflightUnitSuite) TestPreflight_Failed() {
+	t := suite.T()
+
+	assert.False(t, Preflight{{Status: PreflightPassed}, {Status: PreflightWarning}}.Failed())
+	assert.True(t, Preflight{{Status: PreflightPassed}, {Status: PreflightFailed}}.Failed())
+}
+
+func (suite *S3PreflightUnitSuite) TestPreflight_Warned() {
+	t := suite.T()
+
+	assert.False(t, Preflight{{Status: PreflightPassed}, {Status: PreflightSkipped}}.Warned())
+	assert.True(t, Preflight{{Status: PreflightPassed}, {Status: PreflightWarning}}.Warned())
+}
@@ -0,0 +1,98 @@
+package kopia
+
+import (
+	"testing"
+
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/storage"
+	"github.com/alcionai/corso/src/pkg/storage/storagetest"
+)
+
+type S3UnitSuite struct {
+	tester.Suite
+}
+
+func TestS3UnitSuite(t *testing.T) {
+	suite.Run(t, &S3UnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *S3UnitSuite) TestS3StorageConfig() {
+	table := []struct {
+		name   string
+		cfg    storage.S3Config
+		expect *s3.StorageConfig
+	}{
+		{
+			name: "no storage classes",
+			cfg:  storage.S3Config{Bucket: "bkt"},
+		},
+		{
+			name: "data only",
+			cfg: storage.S3Config{
+				Bucket:           "bkt",
+				DataStorageClass: storage.StorageClassGlacierIR,
+			},
+			expect: &s3.StorageConfig{
+				BlobOptions: []s3.PrefixAndStorageClass{
+					{Prefix: "p", StorageClass: storage.StorageClassGlacierIR},
+				},
+			},
+		},
+		{
+			name: "data and metadata",
+			cfg: storage.S3Config{
+				Bucket:               "bkt",
+				DataStorageClass:     storage.StorageClassStandardIA,
+				MetadataStorageClass: storage.StorageClassIntelligentTiering,
+			},
+			expect: &s3.StorageConfig{
+				BlobOptions: []s3.PrefixAndStorageClass{
+					{Prefix: "p", StorageClass: storage.StorageClassStandardIA},
+					{Prefix: "q", StorageClass: storage.StorageClassIntelligentTiering},
+					{Prefix: "x", StorageClass: storage.StorageClassIntelligentTiering},
+					{Prefix: "n", StorageClass: storage.StorageClassIntelligentTiering},
+				},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, s3StorageConfig(test.cfg))
+		})
+	}
+}
+
+type S3IntegrationSuite struct {
+	tester.Suite
+}
+
+func TestS3IntegrationSuite(t *testing.T) {
+	suite.Run(t, &S3IntegrationSuite{
+		Suite: tester.NewIntegrationSuite(
+			t,
+			[][]string{tester.AWSStorageCredEnvs},
+			tester.CorsoKopiaWrapperTests,
+		),
+	})
+}
+
+func (suite *S3IntegrationSuite) TestConformance() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	storagetest.RunConformance(
+		suite.T(),
+		func(t *testing.T) blob.Storage {
+			st, err := s3BlobStorage(ctx, tester.NewPrefixedS3Storage(t))
+			require.NoError(t, err, "creating s3 blob storage")
+
+			return st
+		},
+		storagetest.Options{})
+}
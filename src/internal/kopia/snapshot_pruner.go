@@ -0,0 +1,161 @@
+package kopia
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// PruneRules determines which backup snapshots are eligible for removal
+// by PruneSnapshots.  Every backup snapshot carries the metadata
+// collections (delta tokens, previous paths) used for incremental
+// backups.  Snapshots that can no longer act as an incremental base, and
+// which back no backup, only add clutter to the repository.
+type PruneRules struct {
+	// KeepIncomplete retains incomplete (checkpoint) snapshots even after
+	// a newer complete snapshot supersedes them as an incremental base.
+	KeepIncomplete bool
+	// KeepUnreferenced retains complete snapshots that are not referenced
+	// by any backup model, such as those left behind by failed backups.
+	KeepUnreferenced bool
+	// MinAge protects snapshots younger than the duration from removal,
+	// so that in-flight backups are never affected.
+	MinAge time.Duration
+}
+
+// PruneSnapshots deletes all backup snapshots eligible for removal under
+// the provided rules.  referencedBackupIDs must contain the ID of every
+// backup persisted in the repository.  Returns the IDs of the deleted
+// snapshots.
+func (w Wrapper) PruneSnapshots(
+	ctx context.Context,
+	referencedBackupIDs map[string]struct{},
+	rules PruneRules,
+) ([]string, error) {
+	if w.c == nil {
+		return nil, clues.Stack(errNotConnected).WithClues(ctx)
+	}
+
+	metas, err := w.c.FindManifests(ctx, normalizeTagKVs(map[string]string{TagBackupCategory: ""}))
+	if err != nil {
+		return nil, clues.Wrap(err, "finding backup snapshots").WithClues(ctx)
+	}
+
+	ids := make([]manifest.ID, 0, len(metas))
+	for _, m := range metas {
+		ids = append(ids, m.ID)
+	}
+
+	mans, err := w.c.LoadSnapshots(ctx, ids)
+	if err != nil {
+		return nil, clues.Wrap(err, "loading backup snapshots").WithClues(ctx)
+	}
+
+	prunable := prunableSnapshots(time.Now(), mans, referencedBackupIDs, rules)
+	if len(prunable) == 0 {
+		return nil, nil
+	}
+
+	err = repo.WriteSession(
+		ctx,
+		w.c,
+		repo.WriteSessionOptions{Purpose: "KopiaWrapperSnapshotPrune"},
+		func(innerCtx context.Context, rw repo.RepositoryWriter) error {
+			for _, id := range prunable {
+				if err := rw.DeleteManifest(innerCtx, id); err != nil {
+					return clues.Wrap(err, "deleting snapshot").WithClues(ctx).With("snapshot_id", id)
+				}
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, clues.Wrap(err, "pruning backup snapshots").WithClues(ctx)
+	}
+
+	res := make([]string, 0, len(prunable))
+	for _, id := range prunable {
+		res = append(res, string(id))
+	}
+
+	logger.Ctx(ctx).Infow("pruned backup snapshots", "snapshot_ids", res)
+
+	return res, nil
+}
+
+// prunableSnapshots identifies the snapshots eligible for removal.
+//   - An incomplete snapshot is superseded once a newer complete snapshot
+//     covers all of its reasons, after which it is never used as a base.
+//   - A complete snapshot is unreferenced if no backup model holds its
+//     backup ID.
+func prunableSnapshots(
+	now time.Time,
+	mans []*snapshot.Manifest,
+	referencedBackupIDs map[string]struct{},
+	rules PruneRules,
+) []manifest.ID {
+	var (
+		backupIDKey, _ = makeTagKV(TagBackupID)
+		res            = []manifest.ID{}
+	)
+
+	for _, m := range mans {
+		if now.Sub(m.StartTime.ToTime()) < rules.MinAge {
+			continue
+		}
+
+		if len(m.IncompleteReason) > 0 {
+			if !rules.KeepIncomplete && isSuperseded(m, mans, backupIDKey) {
+				res = append(res, m.ID)
+			}
+
+			continue
+		}
+
+		if rules.KeepUnreferenced {
+			continue
+		}
+
+		if _, ok := referencedBackupIDs[m.Tags[backupIDKey]]; !ok {
+			res = append(res, m.ID)
+		}
+	}
+
+	return res
+}
+
+// isSuperseded is true if some complete snapshot, started after the
+// incomplete snapshot, carries all of the incomplete snapshot's reason tags.
+func isSuperseded(incomplete *snapshot.Manifest, mans []*snapshot.Manifest, backupIDKey string) bool {
+	for _, m := range mans {
+		if len(m.IncompleteReason) > 0 || !m.StartTime.After(incomplete.StartTime) {
+			continue
+		}
+
+		covers := true
+
+		for k := range incomplete.Tags {
+			if k == backupIDKey {
+				continue
+			}
+
+			if _, ok := m.Tags[k]; !ok {
+				covers = false
+				break
+			}
+		}
+
+		if covers {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+package kopia
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+func newPrunableManifest(
+	id manifest.ID,
+	startTime time.Time,
+	incomplete bool,
+	backupID string,
+	tags ...string,
+) *snapshot.Manifest {
+	incompleteStr := ""
+	if incomplete {
+		incompleteStr = "checkpoint"
+	}
+
+	allTags := map[string]string{
+		TagBackupID:       backupID,
+		TagBackupCategory: "",
+	}
+
+	for _, t := range tags {
+		allTags[t] = ""
+	}
+
+	return &snapshot.Manifest{
+		ID:               id,
+		StartTime:        fs.UTCTimestamp(startTime.UnixNano()),
+		IncompleteReason: incompleteStr,
+		Tags:             normalizeTagKVs(allTags),
+	}
+}
+
+type SnapshotPrunerUnitSuite struct {
+	tester.Suite
+}
+
+func TestSnapshotPrunerUnitSuite(t *testing.T) {
+	suite.Run(t, &SnapshotPrunerUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *SnapshotPrunerUnitSuite) TestPrunableSnapshots() {
+	var (
+		now   = time.Now()
+		old1  = now.Add(-72 * time.Hour)
+		old2  = now.Add(-48 * time.Hour)
+		fresh = now.Add(-1 * time.Hour)
+
+		referenced = map[string]struct{}{
+			"bup1": {},
+			"bup2": {},
+		}
+	)
+
+	table := []struct {
+		name   string
+		mans   []*snapshot.Manifest
+		rules  PruneRules
+		expect []manifest.ID
+	}{
+		{
+			name: "all referenced",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testCompleteMan, "bup1", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup2", testMail, testUser1),
+			},
+			expect: []manifest.ID{},
+		},
+		{
+			name: "unreferenced complete",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testCompleteMan, "bup1", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup3", testMail, testUser1),
+			},
+			expect: []manifest.ID{testID2},
+		},
+		{
+			name: "unreferenced complete, kept",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testCompleteMan, "bup3", testMail, testUser1),
+			},
+			rules:  PruneRules{KeepUnreferenced: true},
+			expect: []manifest.ID{},
+		},
+		{
+			name: "superseded incomplete",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testIncompleteMan, "bup3", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup2", testMail, testUser1),
+			},
+			expect: []manifest.ID{testID1},
+		},
+		{
+			name: "superseded incomplete, kept",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testIncompleteMan, "bup3", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup2", testMail, testUser1),
+			},
+			rules:  PruneRules{KeepIncomplete: true},
+			expect: []manifest.ID{},
+		},
+		{
+			name: "incomplete newer than complete",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testCompleteMan, "bup1", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testIncompleteMan, "bup3", testMail, testUser1),
+			},
+			expect: []manifest.ID{},
+		},
+		{
+			name: "incomplete with different reason",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testIncompleteMan, "bup3", testEvents, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup2", testMail, testUser1),
+			},
+			expect: []manifest.ID{},
+		},
+		{
+			name: "too young",
+			mans: []*snapshot.Manifest{
+				newPrunableManifest(testID1, old1, testIncompleteMan, "bup3", testMail, testUser1),
+				newPrunableManifest(testID2, old2, testCompleteMan, "bup2", testMail, testUser1),
+				newPrunableManifest(testID3, fresh, testCompleteMan, "bup4", testMail, testUser1),
+			},
+			rules:  PruneRules{MinAge: 24 * time.Hour},
+			expect: []manifest.ID{testID1},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			result := prunableSnapshots(now, test.mans, referenced, test.rules)
+			assert.ElementsMatch(suite.T(), test.expect, result)
+		})
+	}
+}
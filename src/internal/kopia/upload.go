@@ -87,6 +87,9 @@ type restoreStreamReader struct {
 	io.ReadCloser
 	expectedVersion uint32
 	readVersion     bool
+	// decrypt, if set, allows the reader to accept owner encrypted data.
+	decrypt   func(io.Reader) (io.Reader, error)
+	decrypted io.Reader
 }
 
 func (rw *restoreStreamReader) checkVersion() error {
@@ -103,6 +106,17 @@ func (rw *restoreStreamReader) checkVersion() error {
 
 	version := binary.BigEndian.Uint32(versionBuf)
 
+	if version == ownerEncryptedVersion && rw.decrypt != nil {
+		r, err := rw.decrypt(rw.ReadCloser)
+		if err != nil {
+			return err
+		}
+
+		rw.decrypted = r
+
+		return nil
+	}
+
 	if version != rw.expectedVersion {
 		return errors.Errorf("unexpected data format %v", version)
 	}
@@ -119,6 +133,10 @@ func (rw *restoreStreamReader) Read(p []byte) (n int, err error) {
 		}
 	}
 
+	if rw.decrypted != nil {
+		return rw.decrypted.Read(p)
+	}
+
 	return rw.ReadCloser.Read(p)
 }
 
@@ -136,10 +154,15 @@ type corsoProgress struct {
 	deets   *details.Builder
 	// toMerge represents items that we don't have in-memory item info for. The
 	// item info for these items should be sourced from a base snapshot later on.
-	toMerge    map[string]PrevRefs
+	toMerge map[string]PrevRefs
+	// merger, if set, receives items sourced from a base snapshot as they
+	// finish, so that they needn't be held in toMerge.
+	merger     DetailsMerger
 	mu         sync.RWMutex
 	totalBytes int64
 	errs       *fault.Errors
+	// owners, if set, encrypts item data with resource owner keys.
+	owners *ownerKeys
 }
 
 // Kopia interface function used as a callback when kopia finishes processing a
@@ -178,14 +201,20 @@ func (cp *corsoProgress) FinishedFile(relativePath string, err error) {
 			return
 		}
 
-		cp.mu.Lock()
-		defer cp.mu.Unlock()
-
-		cp.toMerge[d.prevPath.ShortRef()] = PrevRefs{
+		refs := PrevRefs{
 			Repo:     d.repoPath,
 			Location: d.locationPath,
 		}
 
+		if cp.merger != nil && cp.merger.Merge(cp.deets, d.prevPath.ShortRef(), refs) {
+			return
+		}
+
+		cp.mu.Lock()
+		defer cp.mu.Unlock()
+
+		cp.toMerge[d.prevPath.ShortRef()] = refs
+
 		return
 	}
 
@@ -347,6 +376,28 @@ func collectionEntries(
 				continue
 			}
 
+			var rdr io.ReadCloser
+
+			if progress.owners != nil && encryptsByOwner(itemPath) {
+				rdr, err = progress.owners.encryptReader(ctx, itemPath.ResourceOwner(), e.ToReader())
+				if err != nil {
+					err = errors.Wrap(err, "encrypting item with owner key")
+					progress.errs.Add(err)
+
+					logger.Ctx(ctx).With("err", err).Errorw("encrypting item", clues.InErr(err).Slice()...)
+
+					continue
+				}
+			} else {
+				rdr = newBackupStreamReader(serializationVersion, e.ToReader())
+			}
+
+			// kopia uploads each streamed item serially, so very large items
+			// get downloaded ahead of the upload instead of in lockstep with it.
+			if ss, ok := e.(data.StreamSize); ok && ss.Size() >= largeItemSize {
+				rdr = newReadAheadReader(rdr, readAheadSegmentSize, readAheadSegments)
+			}
+
 			// Not all items implement StreamInfo. For example, the metadata files
 			// do not because they don't contain information directly backed up or
 			// used for restore. If progress does not contain information about a
@@ -375,15 +426,12 @@ func collectionEntries(
 				modTime = smt.ModTime()
 			}
 
-			entry := virtualfs.StreamingFileWithModTimeFromReader(
-				encodedName,
-				modTime,
-				newBackupStreamReader(serializationVersion, e.ToReader()))
+			entry := virtualfs.StreamingFileWithModTimeFromReader(encodedName, modTime, rdr)
 
 			if err := cb(ctx, entry); err != nil {
 				// Kopia's uploader swallows errors in most cases, so if we see
 				// something here it's probably a big issue and we should return.
-				return seen, clues.Wrap(err, "executing callback").WithClues(ctx).With("item_path", itemPath)
+				return seen, clues.Wrap(err, "executing callback").WithClues(ctx).With("item_path", logger.PII(itemPath.String()))
 			}
 		}
 	}
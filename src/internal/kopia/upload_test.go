@@ -627,6 +627,80 @@ func (suite *CorsoProgressUnitSuite) TestFinishedFileBaseItemDoesntBuildHierarch
 	assert.Empty(t, cp.deets)
 }
 
+type mockDetailsMerger struct {
+	known  map[string]struct{}
+	merged map[string]PrevRefs
+}
+
+func (m *mockDetailsMerger) Merge(_ *details.Builder, prevShortRef string, refs PrevRefs) bool {
+	if _, ok := m.known[prevShortRef]; !ok {
+		return false
+	}
+
+	m.merged[prevShortRef] = refs
+
+	return true
+}
+
+func (suite *CorsoProgressUnitSuite) TestFinishedFileBaseItemWithMerger() {
+	prevPath := makePath(
+		suite.T(),
+		[]string{testTenant, service, testUser, category, testInboxDir, testFileName2},
+		true,
+	)
+
+	refs := PrevRefs{
+		Repo:     suite.targetFilePath,
+		Location: suite.targetFilePath,
+	}
+
+	table := []struct {
+		name          string
+		known         map[string]struct{}
+		expectMerged  map[string]PrevRefs
+		expectToMerge map[string]PrevRefs
+	}{
+		{
+			name:          "merged while running",
+			known:         map[string]struct{}{prevPath.ShortRef(): {}},
+			expectMerged:  map[string]PrevRefs{prevPath.ShortRef(): refs},
+			expectToMerge: map[string]PrevRefs{},
+		},
+		{
+			name:          "unknown to the merger",
+			known:         map[string]struct{}{},
+			expectMerged:  map[string]PrevRefs{},
+			expectToMerge: map[string]PrevRefs{prevPath.ShortRef(): refs},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			merger := &mockDetailsMerger{known: test.known, merged: map[string]PrevRefs{}}
+			cp := corsoProgress{
+				UploadProgress: &snapshotfs.NullUploadProgress{},
+				deets:          &details.Builder{},
+				pending:        map[string]*itemDetails{},
+				toMerge:        map[string]PrevRefs{},
+				merger:         merger,
+				errs:           fault.New(true),
+			}
+
+			cp.put(suite.targetFileName, &itemDetails{
+				repoPath:     suite.targetFilePath,
+				prevPath:     prevPath,
+				locationPath: suite.targetFilePath,
+			})
+
+			cp.FinishedFile(suite.targetFileName, nil)
+			assert.Equal(t, test.expectMerged, merger.merged)
+			assert.Equal(t, test.expectToMerge, cp.toMerge)
+			assert.Empty(t, cp.pending)
+		})
+	}
+}
+
 func (suite *CorsoProgressUnitSuite) TestFinishedHashingFile() {
 	for _, test := range finishedFileTable {
 		suite.Run(test.name, func() {
@@ -2365,6 +2439,117 @@ func (suite *HierarchyBuilderUnitSuite) TestBuildDirectoryTree_HandleEmptyBase()
 	expectTree(t, ctx, expected, dirTree)
 }
 
+func (suite *HierarchyBuilderUnitSuite) TestBuildDirectoryTree_MergesUnexcludedContent() {
+	tester.LogTimeOfTest(suite.T())
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		storePath = makePath(
+			t,
+			[]string{testTenant, service, testUser, category, testInboxID},
+			false)
+		locPath = makePath(
+			t,
+			[]string{testTenant, service, testUser, category, testInboxDir},
+			false)
+		dataName = testFileName + ".data"
+		metaName = testFileName + ".meta"
+	)
+
+	// baseSnapshot with the following layout:
+	// - a-tenant
+	//   - exchange
+	//     - user1
+	//       - email
+	//         - Inbox
+	//           - file1.data
+	//           - file1.meta
+	getBaseSnapshot := func() fs.Entry {
+		return baseWithChildren(
+			[]string{
+				testTenant,
+				service,
+				testUser,
+				category,
+			},
+			[]fs.Entry{
+				virtualfs.NewStaticDirectory(
+					encodeElements(testInboxID)[0],
+					[]fs.Entry{
+						virtualfs.StreamingFileWithModTimeFromReader(
+							encodeElements(dataName)[0],
+							time.Time{},
+							io.NopCloser(bytes.NewReader(testFileData)),
+						),
+						virtualfs.StreamingFileWithModTimeFromReader(
+							encodeElements(metaName)[0],
+							time.Time{},
+							io.NopCloser(bytes.NewReader(testFileData2)),
+						),
+					},
+				),
+			},
+		)
+	}
+
+	// Only the metadata of the item changed, so only that entry is excluded
+	// from the base and replaced. The content is merged from the base.
+	expected := expectedTreeWithChildren(
+		[]string{
+			testTenant,
+			service,
+			testUser,
+			category,
+		},
+		[]*expectedNode{
+			{
+				name: testInboxID,
+				children: []*expectedNode{
+					{
+						name:     dataName,
+						children: []*expectedNode{},
+					},
+					{
+						name:     metaName,
+						children: []*expectedNode{},
+						data:     testFileData3,
+					},
+				},
+			},
+		},
+	)
+
+	progress := &corsoProgress{
+		pending: map[string]*itemDetails{},
+		errs:    fault.New(true),
+	}
+	mc := mockconnector.NewMockExchangeCollection(storePath, locPath, 1)
+	mc.ColState = data.NotMovedState
+	mc.PrevPath = storePath
+	mc.Names[0] = metaName
+	mc.Data[0] = testFileData3
+
+	msw := &mockSnapshotWalker{
+		snapshotRoot: getBaseSnapshot(),
+	}
+
+	dirTree, err := inflateDirTree(
+		ctx,
+		msw,
+		[]IncrementalBase{
+			mockIncrementalBase("", testTenant, testUser, path.ExchangeService, path.EmailCategory),
+		},
+		[]data.BackupCollection{mc},
+		map[string]struct{}{metaName: {}},
+		progress)
+	require.NoError(t, err)
+
+	expectTree(t, ctx, expected, dirTree)
+}
+
 type mockMultiSnapshotWalker struct {
 	snaps map[string]fs.Entry
 }
@@ -3,6 +3,7 @@ package kopia
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/kopia/kopia/fs"
@@ -35,6 +36,8 @@ const (
 const (
 	TagBackupID       = "backup-id"
 	TagBackupCategory = "is-canon-backup"
+	// TagDetailsCategory marks the snapshots holding backup details.
+	TagDetailsCategory = "is-backup-details"
 )
 
 var (
@@ -47,6 +50,9 @@ type BackupStats struct {
 
 	TotalHashedBytes   int64
 	TotalUploadedBytes int64
+	// FirstUploadAt is the time at which the first bytes were uploaded.
+	// Zero if nothing was uploaded.
+	FirstUploadAt time.Time
 
 	TotalFileCount      int
 	CachedFileCount     int
@@ -69,6 +75,7 @@ func manifestToStats(
 
 		TotalHashedBytes:   progress.totalBytes,
 		TotalUploadedBytes: uploadCount.NumBytes,
+		FirstUploadAt:      uploadCount.FirstCountAt(),
 
 		TotalFileCount:      int(man.Stats.TotalFileCount),
 		CachedFileCount:     int(man.Stats.CachedFiles),
@@ -87,11 +94,13 @@ func NewWrapper(c *conn) (*Wrapper, error) {
 		return nil, errors.Wrap(err, "creating Wrapper")
 	}
 
-	return &Wrapper{c}, nil
+	return &Wrapper{c: c}, nil
 }
 
 type Wrapper struct {
 	c *conn
+	// owners is set when item data is encrypted with resource owner keys.
+	owners *ownerKeys
 }
 
 func (w *Wrapper) Close(ctx context.Context) error {
@@ -121,6 +130,36 @@ type PrevRefs struct {
 	Location path.Path
 }
 
+// DetailsMerger folds the base details entries of items sourced from a
+// base snapshot into the backup details while the backup is still running,
+// instead of after it completes.
+type DetailsMerger interface {
+	// Merge adds the base entry of the item to the details, using the refs of
+	// the item in the new snapshot.  The item is identified by the ShortRef of
+	// its path in the base snapshot.  Returns false if the merger holds no
+	// entry for the item, in which case the item is returned among the refs
+	// to merge once the backup completes.
+	Merge(deets *details.Builder, prevShortRef string, refs PrevRefs) bool
+}
+
+type detailsMergerKey struct{}
+
+// SetDetailsMerger embeds the merger within the context.  Backups run with
+// the context hand each item sourced from a base snapshot to the merger as
+// soon as the item is processed.
+func SetDetailsMerger(ctx context.Context, m DetailsMerger) context.Context {
+	if m == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, detailsMergerKey{}, m)
+}
+
+func detailsMergerFrom(ctx context.Context) DetailsMerger {
+	m, _ := ctx.Value(detailsMergerKey{}).(DetailsMerger)
+	return m
+}
+
 // BackupCollections takes a set of collections and creates a kopia snapshot
 // with the data that they contain. previousSnapshots is used for incremental
 // backups and should represent the base snapshot from which metadata is sourced
@@ -151,7 +190,9 @@ func (w Wrapper) BackupCollections(
 		pending: map[string]*itemDetails{},
 		deets:   &details.Builder{},
 		toMerge: map[string]PrevRefs{},
+		merger:  detailsMergerFrom(ctx),
 		errs:    errs,
+		owners:  w.owners,
 	}
 
 	// When running an incremental backup, we need to pass the prior
@@ -319,6 +360,7 @@ func getItemStream(
 	itemPath path.Path,
 	snapshotRoot fs.Entry,
 	bcounter ByteCounter,
+	owners *ownerKeys,
 ) (data.Stream, error) {
 	if itemPath == nil {
 		return nil, clues.Stack(errNoRestorePath).WithClues(ctx)
@@ -357,13 +399,23 @@ func getItemStream(
 		return nil, clues.Wrap(err, "decoding file name").WithClues(ctx)
 	}
 
-	return &kopiaDataStream{
-		uuid: decodedName,
-		reader: &restoreStreamReader{
+	var (
+		rr = &restoreStreamReader{
 			ReadCloser:      r,
 			expectedVersion: serializationVersion,
-		},
-		size: f.Size() - int64(versionSize),
+		}
+		size = f.Size() - int64(versionSize)
+	)
+
+	if owners != nil && encryptsByOwner(itemPath) {
+		rr.decrypt = owners.decrypter(ctx, itemPath.ResourceOwner())
+		size = ownerPlainSize(size)
+	}
+
+	return &kopiaDataStream{
+		uuid:   decodedName,
+		reader: rr,
+		size:   size,
 	}, nil
 }
 
@@ -408,7 +460,7 @@ func (w Wrapper) RestoreMultipleItems(
 			return nil, et.Err()
 		}
 
-		ds, err := getItemStream(ctx, itemPath, snapshotRoot, bcounter)
+		ds, err := getItemStream(ctx, itemPath, snapshotRoot, bcounter, w.owners)
 		if err != nil {
 			et.Add(err)
 			continue
@@ -426,6 +478,7 @@ func (w Wrapper) RestoreMultipleItems(
 				path:         parentPath,
 				snapshotRoot: snapshotRoot,
 				counter:      bcounter,
+				owners:       w.owners,
 			}
 			c = cols[parentPath.ShortRef()]
 		}
@@ -493,7 +546,110 @@ func (w Wrapper) FetchPrevSnapshotManifests(
 		return nil, clues.Stack(errNotConnected).WithClues(ctx)
 	}
 
-	return fetchPrevSnapshotManifests(ctx, w.c, reasons, tags), nil
+	mans := fetchPrevSnapshotManifests(ctx, w.c, reasons, tags)
+
+	if w.owners == nil {
+		return mans, nil
+	}
+
+	return dropErasedBases(ctx, mans, w.owners.erasedAt)
+}
+
+// dropErasedBases removes the reasons of owners whose keys were erased after
+// the manifest was made, since the owners' data in it can't be read.  Backups
+// of those owners then start over from a full enumeration.  Manifests left
+// without any reasons are dropped.
+func dropErasedBases(
+	ctx context.Context,
+	mans []*ManifestEntry,
+	erasedAt func(context.Context, string) (time.Time, error),
+) ([]*ManifestEntry, error) {
+	var (
+		res    = make([]*ManifestEntry, 0, len(mans))
+		erased = map[string]time.Time{}
+	)
+
+	for _, man := range mans {
+		reasons := make([]Reason, 0, len(man.Reasons))
+
+		for _, r := range man.Reasons {
+			at, ok := erased[r.ResourceOwner]
+			if !ok {
+				var err error
+
+				if at, err = erasedAt(ctx, r.ResourceOwner); err != nil {
+					return nil, err
+				}
+
+				erased[r.ResourceOwner] = at
+			}
+
+			if !at.IsZero() && man.StartTime.ToTime().Before(at) {
+				logger.Ctx(ctx).Infow(
+					"dropping base of erased resource owner",
+					"snapshot_id", man.ID,
+					"service", r.Service.String(),
+					"category", r.Category.String())
+
+				continue
+			}
+
+			reasons = append(reasons, r)
+		}
+
+		if len(reasons) == 0 {
+			continue
+		}
+
+		man.Reasons = reasons
+		res = append(res, man)
+	}
+
+	return res, nil
+}
+
+// RepositorySize returns the total size, in bytes, of the repository's
+// storage.
+func (w Wrapper) RepositorySize(ctx context.Context) (int64, error) {
+	if w.c == nil {
+		return 0, clues.Stack(errNotConnected).WithClues(ctx)
+	}
+
+	return w.c.StorageSize(ctx)
+}
+
+// EncryptByOwner configures the wrapper to encrypt the data of backed up
+// items with keys belonging to the items' resource owners.  Data written
+// with owner keys can only be read by wrappers configured this way.
+func (w *Wrapper) EncryptByOwner() error {
+	if w.c == nil {
+		return clues.Stack(errNotConnected)
+	}
+
+	oks, err := newOwnerKeys(w.c.Repository)
+	if err != nil {
+		return err
+	}
+
+	w.owners = oks
+
+	return nil
+}
+
+// EraseOwner deletes the resource owner's encryption keys, after which
+// the contents of the owner's items in any backup can't be read.  Earlier snapshots
+// stop serving as bases for the owner, so its next backup is a full one.
+// Returns the number of keys deleted.
+func (w Wrapper) EraseOwner(ctx context.Context, owner string) (int, error) {
+	if w.c == nil {
+		return 0, clues.Stack(errNotConnected).WithClues(ctx)
+	}
+
+	if w.owners == nil {
+		return 0, clues.Stack(errOwnerEncryptionDisabled).WithClues(ctx)
+	}
+
+	return w.owners.erase(ctx, owner)
 }
 
 func isErrEntryNotFound(err error) bool {
@@ -192,7 +192,7 @@ func (suite *KopiaIntegrationSuite) SetupTest() {
 	c, err := openKopiaRepo(t, suite.ctx)
 	require.NoError(t, err)
 
-	suite.w = &Wrapper{c}
+	suite.w = &Wrapper{c: c}
 }
 
 func (suite *KopiaIntegrationSuite) TearDownTest() {
@@ -335,7 +335,7 @@ func (suite *KopiaIntegrationSuite) TestRestoreAfterCompressionChange() {
 
 	require.NoError(t, k.Compression(ctx, "s2-default"))
 
-	w := &Wrapper{k}
+	w := &Wrapper{c: k}
 
 	tags := map[string]string{}
 	reason := Reason{
@@ -665,7 +665,7 @@ func (suite *KopiaSimpleRepoIntegrationSuite) SetupTest() {
 	c, err := openKopiaRepo(t, suite.ctx)
 	require.NoError(t, err)
 
-	suite.w = &Wrapper{c}
+	suite.w = &Wrapper{c: c}
 
 	collections := []data.BackupCollection{}
 
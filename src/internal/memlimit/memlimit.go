@@ -0,0 +1,190 @@
+// Package memlimit keeps the memory used by an operation beneath a ceiling.
+// A watchdog samples the process's memory footprint, and while the footprint
+// approaches the ceiling it reduces item retrieval to a single item at a time.
+// Once the footprint passes the ceiling, freed buffers are returned to the
+// operating system.  This keeps backups of
+// very large drives from being OOM killed in small containers.
+package memlimit
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+const (
+	defaultInterval = time.Second
+
+	// the watchdog applies pressure once the footprint passes highWater
+	// percent of the ceiling, and relieves it once the footprint falls
+	// beneath lowWater percent.
+	highWater = 90
+	lowWater  = 75
+
+	bytesPerMB = 1 << 20
+)
+
+// Watchdog tracks the process's memory footprint against a ceiling.
+type Watchdog struct {
+	limit    uint64
+	interval time.Duration
+	// footprint reports the process's memory usage in bytes.
+	footprint func() uint64
+	// free returns freed buffers to the os.
+	free func()
+
+	pressure int32 // atomic
+	// serializes item retrieval while under pressure.
+	single chan struct{}
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	prevGC   int64
+}
+
+// New creates a watchdog that holds the process beneath maxMB megabytes.
+func New(maxMB int) (*Watchdog, error) {
+	if maxMB <= 0 {
+		return nil, clues.New("memory watchdog requires a positive limit")
+	}
+
+	return &Watchdog{
+		limit:     uint64(maxMB) * bytesPerMB,
+		interval:  defaultInterval,
+		footprint: processFootprint,
+		free:      debug.FreeOSMemory,
+		single:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// processFootprint approximates the memory the process holds from the
+// operating system.
+func processFootprint() uint64 {
+	var ms runtime.MemStats
+
+	runtime.ReadMemStats(&ms)
+
+	return ms.Sys - ms.HeapReleased
+}
+
+// Start begins sampling the memory footprint, and sets the runtime's soft
+// memory limit to the watchdog's ceiling.  Stop must be called to release
+// the watchdog.
+func (w *Watchdog) Start(ctx context.Context) {
+	w.prevGC = debug.SetMemoryLimit(int64(w.limit))
+
+	go func() {
+		defer close(w.done)
+
+		t := time.NewTicker(w.interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				w.check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends sampling, relieves any pressure, and restores the runtime's
+// previous soft memory limit.
+func (w *Watchdog) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+
+		atomic.StoreInt32(&w.pressure, 0)
+		debug.SetMemoryLimit(w.prevGC)
+	})
+}
+
+// check samples the footprint and applies or relieves pressure.
+func (w *Watchdog) check(ctx context.Context) {
+	used := w.footprint()
+
+	switch {
+	case used >= w.limit/100*highWater:
+		if atomic.CompareAndSwapInt32(&w.pressure, 0, 1) {
+			logger.Ctx(ctx).Infow(
+				"approaching memory limit, throttling item retrieval",
+				"used_bytes", used,
+				"limit_bytes", w.limit)
+		}
+
+		// flush released buffers back to the os so that the footprint
+		// reflects the memory that is still in use.  Freeing forces a full
+		// collection, so it's saved for when the ceiling is breached.
+		if used >= w.limit {
+			w.free()
+		}
+
+	case used < w.limit/100*lowWater:
+		if atomic.CompareAndSwapInt32(&w.pressure, 1, 0) {
+			logger.Ctx(ctx).Infow(
+				"memory usage recovered, resuming item retrieval",
+				"used_bytes", used,
+				"limit_bytes", w.limit)
+		}
+	}
+}
+
+// UnderPressure returns true while the footprint is near the ceiling.
+// A nil watchdog is never under pressure.
+func (w *Watchdog) UnderPressure() bool {
+	return w != nil && atomic.LoadInt32(&w.pressure) == 1
+}
+
+// Throttle is called before retrieving an item.  While the watchdog is
+// under pressure, Throttle blocks until no other throttled retrieval is in
+// flight, so that items are retrieved one at a time.  The returned func must
+// be called once the item is retrieved.  A nil watchdog never blocks.
+func (w *Watchdog) Throttle(ctx context.Context) func() {
+	if !w.UnderPressure() {
+		return func() {}
+	}
+
+	select {
+	case w.single <- struct{}{}:
+		return func() { <-w.single }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type watchdogKey struct{}
+
+// Set embeds the watchdog within the context.
+func Set(ctx context.Context, w *Watchdog) context.Context {
+	if w == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, watchdogKey{}, w)
+}
+
+// Ctx retrieves the watchdog embedded in the context.  Returns nil if no
+// watchdog was set, which is safe to Throttle.
+func Ctx(ctx context.Context) *Watchdog {
+	w, _ := ctx.Value(watchdogKey{}).(*Watchdog)
+	return w
+}
@@ -0,0 +1,169 @@
+package memlimit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type MemLimitUnitSuite struct {
+	tester.Suite
+}
+
+func TestMemLimitUnitSuite(t *testing.T) {
+	suite.Run(t, &MemLimitUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *MemLimitUnitSuite) TestNew_requiresLimit() {
+	_, err := New(0)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MemLimitUnitSuite) TestCheck_pressure() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, err := New(100)
+	require.NoError(t, err)
+
+	var used uint64
+	w.footprint = func() uint64 { return atomic.LoadUint64(&used) }
+
+	table := []struct {
+		name   string
+		usedMB uint64
+		expect assert.BoolAssertionFunc
+	}{
+		{"well below limit", 10, assert.False},
+		{"above high water", 95, assert.True},
+		{"between water marks", 80, assert.True},
+		{"below low water", 70, assert.False},
+		{"between water marks again", 80, assert.False},
+	}
+	for _, test := range table {
+		atomic.StoreUint64(&used, test.usedMB*bytesPerMB)
+		w.check(ctx)
+		test.expect(t, w.UnderPressure(), test.name)
+	}
+}
+
+func (suite *MemLimitUnitSuite) TestCheck_free() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, err := New(100)
+	require.NoError(t, err)
+
+	var used, freed uint64
+	w.footprint = func() uint64 { return used }
+	w.free = func() { freed++ }
+
+	table := []struct {
+		name   string
+		usedMB uint64
+		expect uint64
+	}{
+		{"below limit", 50, 0},
+		{"above high water", 95, 0},
+		{"above limit", 110, 1},
+		{"still above limit", 105, 2},
+		{"back beneath limit", 92, 2},
+	}
+	for _, test := range table {
+		used = test.usedMB * bytesPerMB
+		w.check(ctx)
+		assert.Equal(t, test.expect, freed, test.name)
+	}
+}
+
+func (suite *MemLimitUnitSuite) TestThrottle() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, err := New(100)
+	require.NoError(t, err)
+
+	// without pressure, throttling never blocks.
+	r1 := w.Throttle(ctx)
+	r2 := w.Throttle(ctx)
+
+	r1()
+	r2()
+
+	atomic.StoreInt32(&w.pressure, 1)
+
+	release := w.Throttle(ctx)
+	acquired := make(chan struct{})
+
+	go func() {
+		defer w.Throttle(ctx)()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		assert.Fail(t, "second retrieval proceeded while under pressure")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		assert.Fail(t, "second retrieval never proceeded")
+	}
+
+	// cancelled contexts never block.
+	release = w.Throttle(ctx)
+	defer release()
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	w.Throttle(cctx)()
+}
+
+func (suite *MemLimitUnitSuite) TestNilWatchdog() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var w *Watchdog
+
+	assert.False(suite.T(), w.UnderPressure())
+	w.Throttle(ctx)()
+	assert.Nil(suite.T(), Ctx(Set(ctx, w)))
+}
+
+func (suite *MemLimitUnitSuite) TestStartStop() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, err := New(100)
+	require.NoError(t, err)
+
+	w.interval = time.Millisecond
+	w.footprint = func() uint64 { return 99 * bytesPerMB }
+
+	w.Start(ctx)
+	assert.Eventually(t, w.UnderPressure, time.Second, time.Millisecond)
+
+	w.Stop()
+	assert.False(t, w.UnderPressure())
+	assert.Equal(t, w, Ctx(Set(ctx, w)))
+}
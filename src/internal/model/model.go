@@ -24,16 +24,25 @@ const (
 	BackupSchema
 	BackupDetailsSchema
 	RepositorySchema
+	RestoreProgressSchema
+	BackupTrendSchema
+	RestorePolicySchema
+	RestoreApprovalSchema
+	RepoQuotaSchema
 )
 
 // common tags for filtering
 const (
-	ServiceTag = "service"
+	ServiceTag        = "service"
+	ResourceOwnerTag  = "resourceOwner"
+	IdempotencyKeyTag = "idempotencyKey"
+	RestoreIDTag      = "restoreID"
+	StagingTag        = "staging"
 )
 
 // Valid returns true if the ModelType value fits within the iota range.
 func (mt Schema) Valid() bool {
-	return mt > 0 && mt < RepositorySchema+1
+	return mt > 0 && mt < RepoQuotaSchema+1
 }
 
 type Model interface {
@@ -29,7 +29,12 @@ func (suite *ModelUnitSuite) TestValid() {
 		{model.BackupSchema, assert.True},
 		{model.BackupDetailsSchema, assert.True},
 		{model.RepositorySchema, assert.True},
-		{model.RepositorySchema + 1, assert.False},
+		{model.RestoreProgressSchema, assert.True},
+		{model.BackupTrendSchema, assert.True},
+		{model.RestorePolicySchema, assert.True},
+		{model.RestoreApprovalSchema, assert.True},
+		{model.RepoQuotaSchema, assert.True},
+		{model.RepoQuotaSchema + 1, assert.False},
 		{model.Schema(-1), assert.False},
 		{model.Schema(100), assert.False},
 	}
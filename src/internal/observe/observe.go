@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"sort"
 	"sync"
 
 	"github.com/dustin/go-humanize"
@@ -12,6 +14,8 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 
 	"github.com/alcionai/corso/src/pkg/logger"
 )
@@ -33,6 +37,11 @@ var (
 	writer   io.Writer
 	progress *mpb.Progress
 	cfg      *config
+
+	// ownersMu guards owners, the display of the current run over many
+	// owners, if any.
+	ownersMu sync.Mutex
+	owners   *OwnersProgress
 )
 
 func init() {
@@ -125,6 +134,18 @@ func SeedWriter(ctx context.Context, w io.Writer, c *config) {
 // Complete blocks until the progress finishes writing out all data.
 // Afterwards, the progress instance is reset.
 func Complete() {
+	// a run over many owners keeps its display going across each owner's
+	// operations, which may run at once.  Waiting would hold each operation
+	// on the bars of the others, so the bars are waited on once the run
+	// finishes.
+	ownersMu.Lock()
+	running := owners != nil
+	ownersMu.Unlock()
+
+	if running {
+		return
+	}
+
 	if progress != nil {
 		progress.Wait()
 	}
@@ -318,6 +339,61 @@ func ProgressWithCount(
 	return ch, wacb
 }
 
+// ProgressWithTotal tracks the display of a bar that tracks the completion
+// of the specified total, along with the estimated time until completion.
+// Each write to the provided channel advances the bar by the written amount.
+// The caller is expected to close the channel.
+func ProgressWithTotal(
+	ctx context.Context,
+	header string,
+	message cleanable,
+	total int64,
+) (chan<- int64, func()) {
+	var (
+		log  = logger.Ctx(ctx)
+		lmsg = fmt.Sprintf("%s %s - %d", header, message.clean(), total)
+		ch   = make(chan int64)
+	)
+
+	log.Info(lmsg)
+
+	if cfg.hidden() {
+		go listenCount(ctx, ch, nop, func(int64) {})
+		return ch, func() { log.Info("done - " + lmsg) }
+	}
+
+	wg.Add(1)
+
+	barOpts := []mpb.BarOption{
+		mpb.PrependDecorators(
+			decor.Name(header, decor.WCSyncSpaceR),
+			decor.Name(message.String()),
+			decor.Counters(0, " %d/%d "),
+		),
+		mpb.AppendDecorators(
+			decor.OnComplete(decor.AverageETA(decor.ET_STYLE_GO), "done"),
+		),
+	}
+
+	if !cfg.keepBarsAfterComplete {
+		barOpts = append(barOpts, mpb.BarRemoveOnComplete())
+	}
+
+	bar := progress.New(total, mpb.NopStyle(), barOpts...)
+
+	go listenCount(
+		ctx,
+		ch,
+		func() { bar.Abort(true) },
+		bar.IncrInt64)
+
+	wacb := waitAndCloseBar(bar, func() {
+		log.Info("done - " + lmsg)
+	})
+
+	return ch, wacb
+}
+
 // ---------------------------------------------------------------------------
 // Progress for Unknown Quantities
 // ---------------------------------------------------------------------------
@@ -427,6 +503,250 @@ func waitAndCloseBar(bar *mpb.Bar, log func()) func() {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Progress for Multiple Owners
+// ---------------------------------------------------------------------------
+
+// OwnerStatus describes how far along a resource owner is within a run that
+// handles many owners.
+type OwnerStatus string
+
+const (
+	OwnerQueued   OwnerStatus = "queued"
+	OwnerRunning  OwnerStatus = "running"
+	OwnerDone     OwnerStatus = "done"
+	OwnerFailed   OwnerStatus = "failed"
+	OwnerCanceled OwnerStatus = "canceled"
+)
+
+// Terminal returns true if the owner won't be processed any further.
+func (s OwnerStatus) Terminal() bool {
+	return s == OwnerDone || s == OwnerFailed || s == OwnerCanceled
+}
+
+// maxRecentOwners is the number of completed owners that remain displayed
+// in a run over many owners.
+const maxRecentOwners = 5
+
+// OwnersProgress displays a line with the status of each running or
+// failed owner in a run, as well as the most recently completed owners,
+// pinned above the progress of the owners being processed.  A footer
+// summarizes the whole run beneath them, so that runs over thousands of
+// owners stay readable.  The caller is expected to call Finish once the
+// run ends.
+type OwnersProgress struct {
+	mu       sync.Mutex
+	header   string
+	owners   []string
+	statuses map[string]OwnerStatus
+	footer   *mpb.Bar
+	log      *zap.SugaredLogger
+
+	// barsMu guards the displayed owners.  It's kept apart from mu, which
+	// the bars' decorators lock while rendering.
+	barsMu sync.Mutex
+	bars   map[string]*mpb.Bar
+	// recent holds the completed owners still on display, oldest first.
+	recent []string
+	// shown counts the owners displayed so far, to order their lines.
+	shown int
+}
+
+// OwnerProgress starts the display of a run over the owners, all of which
+// begin as queued.
+func OwnerProgress(ctx context.Context, header string, ownerIDs []string) *OwnersProgress {
+	op := &OwnersProgress{
+		header:   header,
+		owners:   ownerIDs,
+		statuses: make(map[string]OwnerStatus, len(ownerIDs)),
+		bars:     map[string]*mpb.Bar{},
+		log:      logger.Ctx(ctx),
+	}
+
+	for _, o := range ownerIDs {
+		op.statuses[o] = OwnerQueued
+	}
+
+	op.log.Infow(header, "owner_count", len(ownerIDs))
+
+	if cfg.hidden() {
+		return op
+	}
+
+	ownersMu.Lock()
+	defer ownersMu.Unlock()
+
+	// only one run gets displayed at a time.
+	if owners != nil {
+		return op
+	}
+
+	owners = op
+
+	op.footer = progress.New(
+		-1,
+		mpb.NopStyle(),
+		mpb.BarPriority(math.MaxInt),
+		mpb.PrependDecorators(
+			decor.Name(header, decor.WCSyncSpaceR),
+			decor.Any(func(decor.Statistics) string { return op.Summary() }),
+		))
+
+	return op
+}
+
+// Status returns the owner's current status.
+func (op *OwnersProgress) Status(owner string) OwnerStatus {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	return op.statuses[owner]
+}
+
+// Set updates the owner's status.  Owners in a terminal status can't be
+// updated any further.
+func (op *OwnersProgress) Set(owner string, status OwnerStatus) {
+	op.mu.Lock()
+	updated := op.set(owner, status)
+	op.mu.Unlock()
+
+	// the bars render the status, so they can't be changed while holding
+	// the lock.
+	if updated && op.footer != nil {
+		op.display(owner, status)
+	}
+}
+
+// set updates the status, returning true if it changed.
+func (op *OwnersProgress) set(owner string, status OwnerStatus) bool {
+	prev, ok := op.statuses[owner]
+	if !ok || prev.Terminal() {
+		return false
+	}
+
+	op.statuses[owner] = status
+	op.log.Infow(op.header, "owner", PII(owner).clean(), "status", status)
+
+	return true
+}
+
+// display shows running and failed owners, and the most recently completed
+// owners.  Queued and canceled owners are only counted by the footer.
+func (op *OwnersProgress) display(owner string, status OwnerStatus) {
+	op.barsMu.Lock()
+	defer op.barsMu.Unlock()
+
+	switch status {
+	case OwnerRunning, OwnerFailed:
+		if _, ok := op.bars[owner]; !ok {
+			op.bars[owner] = op.newBar(owner)
+		}
+
+	case OwnerDone:
+		op.recent = append(op.recent, owner)
+
+		if len(op.recent) > maxRecentOwners {
+			oldest := op.recent[0]
+			op.recent = op.recent[1:]
+
+			if bar := op.bars[oldest]; bar != nil {
+				bar.Abort(true)
+				delete(op.bars, oldest)
+			}
+		}
+	}
+}
+
+// newBar produces the line displaying the owner's status.  Owners are
+// listed in the order they were first displayed.
+func (op *OwnersProgress) newBar(owner string) *mpb.Bar {
+	// priorities beneath those of all other bars keep the owners above them.
+	priority := math.MinInt32 + op.shown
+	op.shown++
+
+	return progress.New(
+		-1,
+		mpb.NopStyle(),
+		mpb.BarPriority(priority),
+		mpb.PrependDecorators(
+			decor.Name(owner, decor.WCSyncSpaceR),
+			decor.Any(func(decor.Statistics) string { return string(op.Status(owner)) }),
+		))
+}
+
+// displayed lists the owners currently on display.
+func (op *OwnersProgress) displayed() []string {
+	op.barsMu.Lock()
+	defer op.barsMu.Unlock()
+
+	ds := make([]string, 0, len(op.bars))
+
+	for o := range op.bars {
+		ds = append(ds, o)
+	}
+
+	sort.Strings(ds)
+
+	return ds
+}
+
+// Summary counts the owners in each status.
+func (op *OwnersProgress) Summary() string {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	counts := map[OwnerStatus]int{}
+
+	for _, s := range op.statuses {
+		counts[s]++
+	}
+
+	done := counts[OwnerDone] + counts[OwnerFailed] + counts[OwnerCanceled]
+	sum := fmt.Sprintf("%d/%d owners complete", done, len(op.owners))
+
+	for _, s := range []OwnerStatus{OwnerRunning, OwnerFailed, OwnerCanceled} {
+		if counts[s] > 0 {
+			sum += fmt.Sprintf(" %s %d %s", Bullet, counts[s], s)
+		}
+	}
+
+	return sum
+}
+
+// Finish ends the display of the run.  Owners that weren't processed to
+// completion are marked as canceled.
+func (op *OwnersProgress) Finish() {
+	for _, o := range op.owners {
+		op.Set(o, OwnerCanceled)
+	}
+
+	op.log.Infow("done - "+op.header, "summary", op.Summary())
+
+	if op.footer == nil {
+		return
+	}
+
+	op.barsMu.Lock()
+	bars := maps.Values(op.bars)
+	op.barsMu.Unlock()
+
+	bars = append(bars, op.footer)
+
+	for _, bar := range bars {
+		bar.SetTotal(-1, true)
+	}
+
+	for _, bar := range bars {
+		bar.Wait()
+	}
+
+	ownersMu.Lock()
+	owners = nil
+	ownersMu.Unlock()
+
+	Complete()
+}
+
 // ---------------------------------------------------------------------------
 // other funcs
 // ---------------------------------------------------------------------------
@@ -456,6 +776,26 @@ func listen(ctx context.Context, ch <-chan struct{}, onEnd, onInc func()) {
 	}
 }
 
+// listenCount is the counterpart of listen for channels that report the
+// amount of each increment.
+func listenCount(ctx context.Context, ch <-chan int64, onEnd func(), onIncr func(int64)) {
+	for {
+		select {
+		case <-ctx.Done():
+			onEnd()
+			return
+
+		case n, ok := <-ch:
+			if !ok {
+				onEnd()
+				return
+			}
+
+			onIncr(n)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // PII redaction
 // ---------------------------------------------------------------------------
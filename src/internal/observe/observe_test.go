@@ -332,6 +332,125 @@ func (suite *ObserveProgressUnitSuite) TestrogressWithCountChannelClosed() {
 	require.Contains(suite.T(), recorder.String(), fmt.Sprintf("%d/%d", 0, count))
 }
 
+func (suite *ObserveProgressUnitSuite) TestObserveProgressWithTotal() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	recorder := strings.Builder{}
+	SeedWriter(ctx, &recorder, nil)
+
+	defer func() {
+		// don't cross-contaminate other tests.
+		//nolint:forbidigo
+		SeedWriter(context.Background(), nil, nil)
+	}()
+
+	header := "Header"
+	message := "Test Message"
+	total := int64(30)
+
+	ch, closer := ProgressWithTotal(ctx, header, Safe(message), total)
+
+	for i := 0; i < 3; i++ {
+		ch <- 10
+	}
+
+	closer()
+
+	Complete()
+
+	require.NotEmpty(suite.T(), recorder.String())
+	require.Contains(suite.T(), recorder.String(), message)
+	require.Contains(suite.T(), recorder.String(), fmt.Sprintf("%d/%d", total, total))
+}
+
+func (suite *ObserveProgressUnitSuite) TestOwnerProgress() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	recorder := strings.Builder{}
+	SeedWriter(ctx, &recorder, nil)
+
+	defer func() {
+		// don't cross-contaminate other tests.
+		//nolint:forbidigo
+		SeedWriter(context.Background(), nil, nil)
+	}()
+
+	op := OwnerProgress(ctx, "Backing up owners", []string{"a", "b", "c", "d"})
+	assert.Equal(t, "0/4 owners complete", op.Summary())
+
+	op.Set("a", OwnerRunning)
+	op.Set("a", OwnerDone)
+	op.Set("b", OwnerRunning)
+	op.Set("b", OwnerFailed)
+	op.Set("c", OwnerRunning)
+	assert.Equal(t, "2/4 owners complete ∙ 1 running ∙ 1 failed", op.Summary())
+
+	// operations completing their own display don't wait on the owners.
+	Message(ctx, Safe("operation"))
+	Complete()
+
+	// terminal statuses don't change.
+	op.Set("a", OwnerRunning)
+	assert.Equal(t, OwnerDone, op.Status("a"))
+
+	op.Finish()
+	Complete()
+
+	assert.Equal(t, OwnerDone, op.Status("a"))
+	assert.Equal(t, OwnerFailed, op.Status("b"))
+	assert.Equal(t, OwnerCanceled, op.Status("c"))
+	assert.Equal(t, OwnerCanceled, op.Status("d"))
+	assert.Equal(t, "4/4 owners complete ∙ 1 failed ∙ 2 canceled", op.Summary())
+	assert.Contains(t, recorder.String(), "Backing up owners")
+}
+
+func (suite *ObserveProgressUnitSuite) TestOwnerProgress_displayed() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	recorder := strings.Builder{}
+	SeedWriter(ctx, &recorder, nil)
+
+	defer func() {
+		// don't cross-contaminate other tests.
+		//nolint:forbidigo
+		SeedWriter(context.Background(), nil, nil)
+	}()
+
+	ids := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		ids = append(ids, fmt.Sprintf("owner-%02d", i))
+	}
+
+	op := OwnerProgress(ctx, "Backing up owners", ids)
+	assert.Empty(t, op.displayed(), "queued owners aren't displayed")
+
+	op.Set(ids[0], OwnerRunning)
+	op.Set(ids[0], OwnerFailed)
+
+	for _, id := range ids[1:10] {
+		op.Set(id, OwnerRunning)
+		op.Set(id, OwnerDone)
+	}
+
+	op.Set(ids[10], OwnerRunning)
+	op.Set(ids[11], OwnerRunning)
+
+	expect := append([]string{ids[0]}, ids[10-maxRecentOwners:12]...)
+	assert.Equal(t, expect, op.displayed(), "running, failed, and recent owners")
+
+	op.Finish()
+	Complete()
+
+	assert.Equal(t, "20/20 owners complete ∙ 1 failed ∙ 10 canceled", op.Summary())
+}
+
 func (suite *ObserveProgressUnitSuite) TestListen() {
 	ctx, flush := tester.NewContext()
 	defer flush()
@@ -0,0 +1,115 @@
+// Package opctx stamps the metadata that identifies an operation onto its
+// context, so that every log written, and every error annotated, with the
+// context carries the same correlation fields under the same keys.
+package opctx
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// Kind identifies the type of operation.
+type Kind string
+
+const (
+	Backup  Kind = "backup"
+	Restore Kind = "restore"
+	Export  Kind = "export"
+	Verify  Kind = "verify"
+)
+
+// The keys under which the metadata is added to the context's clues.
+const (
+	OperationIDKey   = "operation_id"
+	KindKey          = "operation"
+	TenantIDKey      = "tenant_id"
+	BackupIDKey      = "backup_id"
+	ServiceKey       = "service"
+	CategoryKey      = "category"
+	ResourceOwnerKey = "resource_owner"
+)
+
+// Metadata identifies an operation, and the data it's working on.
+// Empty fields are unknown, or don't apply to the operation.
+type Metadata struct {
+	OperationID   string
+	Kind          Kind
+	TenantID      string
+	BackupID      string
+	Service       string
+	Category      string
+	ResourceOwner string
+}
+
+// merge overwrites the fields of md with the populated fields of other.
+func (md Metadata) merge(other Metadata) Metadata {
+	set := func(field *string, val string) {
+		if len(val) > 0 {
+			*field = val
+		}
+	}
+
+	if len(other.Kind) > 0 {
+		md.Kind = other.Kind
+	}
+
+	set(&md.OperationID, other.OperationID)
+	set(&md.TenantID, other.TenantID)
+	set(&md.BackupID, other.BackupID)
+	set(&md.Service, other.Service)
+	set(&md.Category, other.Category)
+	set(&md.ResourceOwner, other.ResourceOwner)
+
+	return md
+}
+
+// clues produces the key-value pairs of the populated fields.
+func (md Metadata) clues() []any {
+	kvs := []any{}
+
+	add := func(k, v string) {
+		if len(v) > 0 {
+			kvs = append(kvs, k, v)
+		}
+	}
+
+	add(OperationIDKey, md.OperationID)
+	add(KindKey, string(md.Kind))
+	add(TenantIDKey, md.TenantID) // TODO: pii
+	add(BackupIDKey, md.BackupID)
+	add(ServiceKey, md.Service)
+	add(CategoryKey, md.Category)
+
+	if len(md.ResourceOwner) > 0 {
+		kvs = append(kvs, ResourceOwnerKey, logger.PII(md.ResourceOwner))
+	}
+
+	return kvs
+}
+
+type metadataKey struct{}
+
+// Set merges the populated fields of md into the metadata already held by
+// the context, and adds them to the context's clues.  Operations set their
+// metadata when they start, while connectors narrow it, such as by setting
+// the category of the data being processed.
+func Set(ctx context.Context, md Metadata) context.Context {
+	kvs := md.clues()
+	if len(kvs) == 0 {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, metadataKey{}, Ctx(ctx).merge(md))
+
+	return clues.Add(ctx, kvs...)
+}
+
+// Ctx retrieves the metadata set in the context.  Returns empty metadata if
+// none was set.
+func Ctx(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
@@ -0,0 +1,70 @@
+package opctx
+
+import (
+	"testing"
+
+	"github.com/alcionai/clues"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type OpCtxUnitSuite struct {
+	tester.Suite
+}
+
+func TestOpCtxUnitSuite(t *testing.T) {
+	suite.Run(t, &OpCtxUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *OpCtxUnitSuite) TestSet() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	assert.Equal(t, Metadata{}, Ctx(ctx))
+	assert.Equal(t, ctx, Set(ctx, Metadata{}), "empty metadata leaves the context unchanged")
+
+	ctx = Set(ctx, Metadata{
+		OperationID:   "op",
+		Kind:          Backup,
+		TenantID:      "tenant",
+		BackupID:      "bup",
+		Service:       "exchange",
+		ResourceOwner: "user",
+	})
+
+	cctx := Set(ctx, Metadata{Category: "email"})
+
+	assert.Equal(
+		t,
+		Metadata{
+			OperationID:   "op",
+			Kind:          Backup,
+			TenantID:      "tenant",
+			BackupID:      "bup",
+			Service:       "exchange",
+			Category:      "email",
+			ResourceOwner: "user",
+		},
+		Ctx(cctx),
+		"narrowing keeps the operation's metadata")
+	assert.Empty(t, Ctx(ctx).Category, "the parent context is unchanged")
+
+	vs := clues.In(cctx)
+
+	for k, v := range map[string]any{
+		OperationIDKey: "op",
+		KindKey:        string(Backup),
+		TenantIDKey:    "tenant",
+		BackupIDKey:    "bup",
+		ServiceKey:     "exchange",
+		CategoryKey:    "email",
+	} {
+		assert.Equal(t, v, vs[k], k)
+	}
+
+	assert.Contains(t, vs, ResourceOwnerKey)
+}
@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// backup alerts, as reported in backup results and warning events.
+const (
+	AlertDuration       = "duration"
+	AlertChangedItems   = "changed_items"
+	AlertChangedPercent = "changed_percent"
+)
+
+// durationAlarm emits a warning event as soon as a backup runs longer
+// than the maximum duration.  The backup itself continues running.
+type durationAlarm struct {
+	timer *time.Timer
+	// closed once the alarm's event has been emitted.
+	fired chan struct{}
+}
+
+// startDurationAlarm starts the alarm for the backup.  Returns nil if no
+// maximum duration is configured.
+func startDurationAlarm(
+	ctx context.Context,
+	bus events.Eventer,
+	maxDuration time.Duration,
+) *durationAlarm {
+	if maxDuration <= 0 {
+		return nil
+	}
+
+	da := &durationAlarm{fired: make(chan struct{})}
+
+	da.timer = time.AfterFunc(maxDuration, func() {
+		defer close(da.fired)
+
+		logger.Ctx(ctx).Infow("backup exceeds the maximum duration", "max_duration", maxDuration)
+
+		bus.Event(
+			ctx,
+			events.BackupAlert,
+			map[string]any{
+				events.Alert:          AlertDuration,
+				events.AlertThreshold: maxDuration.String(),
+			})
+	})
+
+	return da
+}
+
+// stop halts the alarm, and returns true if it already fired.
+func (da *durationAlarm) stop() bool {
+	if da == nil {
+		return false
+	}
+
+	if da.timer.Stop() {
+		return false
+	}
+
+	<-da.fired
+
+	return true
+}
+
+// changeAlerts compares the volume of changes in an incremental backup
+// against the alert thresholds, and emits a warning event for each
+// threshold that was reached.  Added, changed, and deleted items all count
+// as changes, and the percentage is relative to all items in the backup
+// along with the deleted ones.  Returns the reached thresholds.
+func changeAlerts(
+	ctx context.Context,
+	bus events.Eventer,
+	ao control.AlertOptions,
+	summary map[string]details.CategorySummary,
+) []string {
+	var changed, total int
+
+	for _, cs := range summary {
+		changed += cs.New + cs.Changed + cs.Deleted
+		total += cs.Items + cs.Deleted
+	}
+
+	var (
+		alerts []string
+		pct    float64
+	)
+
+	if total > 0 {
+		pct = float64(changed) * 100 / float64(total)
+	}
+
+	alert := func(kind string, threshold, value any) {
+		logger.Ctx(ctx).Infow(
+			"backup change volume exceeds the alert threshold",
+			"alert", kind,
+			"threshold", threshold,
+			"value", value)
+
+		bus.Event(
+			ctx,
+			events.BackupAlert,
+			map[string]any{
+				events.Alert:          kind,
+				events.AlertThreshold: threshold,
+				events.AlertValue:     value,
+				events.ItemsChanged:   changed,
+			})
+
+		alerts = append(alerts, kind)
+	}
+
+	if ao.MaxChangedItems > 0 && changed >= ao.MaxChangedItems {
+		alert(AlertChangedItems, ao.MaxChangedItems, changed)
+	}
+
+	if ao.MaxChangedPercent > 0 && pct >= ao.MaxChangedPercent {
+		alert(AlertChangedPercent, ao.MaxChangedPercent, pct)
+	}
+
+	return alerts
+}
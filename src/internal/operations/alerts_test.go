@@ -0,0 +1,126 @@
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/events"
+	evmock "github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type AlertsUnitSuite struct {
+	tester.Suite
+}
+
+func TestAlertsUnitSuite(t *testing.T) {
+	suite.Run(t, &AlertsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *AlertsUnitSuite) TestDurationAlarm() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		mb = evmock.NewBus()
+	)
+
+	da := startDurationAlarm(ctx, mb, time.Millisecond)
+	require.NotNil(t, da)
+
+	<-da.fired
+
+	assert.True(t, da.stop(), "alarm fired")
+	assert.Equal(t, 1, mb.TimesCalled[events.BackupAlert])
+	assert.Equal(t, AlertDuration, mb.CalledWith[events.BackupAlert][0][events.Alert])
+}
+
+func (suite *AlertsUnitSuite) TestDurationAlarm_stoppedInTime() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		mb = evmock.NewBus()
+	)
+
+	da := startDurationAlarm(ctx, mb, time.Hour)
+	require.NotNil(t, da)
+
+	assert.False(t, da.stop(), "alarm fired")
+	assert.Zero(t, mb.TimesCalled[events.BackupAlert])
+}
+
+func (suite *AlertsUnitSuite) TestDurationAlarm_disabled() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	da := startDurationAlarm(ctx, evmock.NewBus(), 0)
+	assert.Nil(suite.T(), da)
+	assert.False(suite.T(), da.stop(), "alarm fired")
+}
+
+func (suite *AlertsUnitSuite) TestChangeAlerts() {
+	// 100 items before the backup: 10 new, 5 changed, and 5 deleted, out
+	// of 105 items in the backup plus the 5 deleted ones.
+	summary := map[string]details.CategorySummary{
+		"email":    {Items: 80, New: 10, Changed: 5, Unchanged: 65, Deleted: 5},
+		"contacts": {Items: 25, Unchanged: 25},
+	}
+
+	table := []struct {
+		name   string
+		opts   control.AlertOptions
+		expect []string
+	}{
+		{
+			name: "no thresholds",
+		},
+		{
+			name: "under the thresholds",
+			opts: control.AlertOptions{MaxChangedItems: 21, MaxChangedPercent: 20},
+		},
+		{
+			name:   "at the item threshold",
+			opts:   control.AlertOptions{MaxChangedItems: 20, MaxChangedPercent: 20},
+			expect: []string{AlertChangedItems},
+		},
+		{
+			name:   "over the percent threshold",
+			opts:   control.AlertOptions{MaxChangedItems: 21, MaxChangedPercent: 18},
+			expect: []string{AlertChangedPercent},
+		},
+		{
+			name:   "both thresholds",
+			opts:   control.AlertOptions{MaxChangedItems: 1, MaxChangedPercent: 1},
+			expect: []string{AlertChangedItems, AlertChangedPercent},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				mb = evmock.NewBus()
+			)
+
+			alerts := changeAlerts(ctx, mb, test.opts, summary)
+			assert.Equal(t, test.expect, alerts)
+			assert.Equal(t, len(test.expect), mb.TimesCalled[events.BackupAlert])
+
+			for i, alert := range test.expect {
+				assert.Equal(t, alert, mb.CalledWith[events.BackupAlert][i][events.Alert])
+				assert.Equal(t, 20, mb.CalledWith[events.BackupAlert][i][events.ItemsChanged])
+			}
+		})
+	}
+}
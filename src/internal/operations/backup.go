@@ -2,28 +2,40 @@ package operations
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/google/uuid"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
 
+	"github.com/alcionai/corso/src/internal/bandwidth"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/common/crash"
 	"github.com/alcionai/corso/src/internal/connector"
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/internal/connector/onedrive"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	D "github.com/alcionai/corso/src/internal/diagnostics"
 	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/internal/fairshare"
 	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/memlimit"
 	"github.com/alcionai/corso/src/internal/model"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
+	"github.com/alcionai/corso/src/internal/spill"
+	"github.com/alcionai/corso/src/internal/stall"
 	"github.com/alcionai/corso/src/internal/stats"
 	"github.com/alcionai/corso/src/internal/streamstore"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/backup"
 	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/catalog"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
@@ -41,6 +53,9 @@ type BackupOperation struct {
 	Selectors     selectors.Selector `json:"selectors"`
 	Version       string             `json:"version"`
 
+	// Catalog, if set, mirrors the backup's record once it's persisted.
+	Catalog *catalog.Catalog `json:"-"`
+
 	account account.Account
 
 	// when true, this allows for incremental backups instead of full data pulls
@@ -53,6 +68,32 @@ type BackupResults struct {
 	stats.ReadWrites
 	stats.StartAndEndTime
 	BackupID model.StableID `json:"backupID"`
+	// BaseBackupIDs identifies the prior backups whose snapshots were
+	// used as bases for this backup.
+	BaseBackupIDs []model.StableID `json:"baseBackupIDs,omitempty"`
+	// Incremental is true if the backup only retrieved the changes made
+	// since its bases.
+	Incremental bool `json:"incremental"`
+	// ProjectedRepoSize is the size of the repository after the backup,
+	// estimated from its size before the backup and the bytes uploaded.
+	// Only populated when a quota is configured.
+	ProjectedRepoSize int64 `json:"projectedRepoSize,omitempty"`
+	// QuotaAlert names the quota limit that the projected repository size
+	// reached, if any.
+	QuotaAlert string `json:"quotaAlert,omitempty"`
+	// Alerts names the alert thresholds that the backup reached, if any.
+	Alerts []string `json:"alerts,omitempty"`
+	// Anomalies lists the suspicious patterns of changes found since the
+	// base backups.  Only populated when anomaly detection is enabled.
+	Anomalies []details.Anomaly `json:"anomalies,omitempty"`
+	// Timeline records when each phase of the backup occurred.
+	Timeline stats.Timeline `json:"timeline,omitempty"`
+	// Performance records the folder throughput, and the slowest items,
+	// retrieved by the backup.
+	Performance stats.Performance `json:"performance,omitempty"`
+	// DriveTransitions records the drives which were replaced since the
+	// base backup.
+	DriveTransitions []backup.DriveTransition `json:"driveTransitions,omitempty"`
 }
 
 // NewBackupOperation constructs and validates a backup operation.
@@ -85,6 +126,10 @@ func (op BackupOperation) validate() error {
 		return errors.New("backup requires a resource owner")
 	}
 
+	if op.Options.StructureOnly && op.Selectors.Service != selectors.ServiceExchange {
+		return errors.New("structure-only backups are only supported for exchange")
+	}
+
 	return op.operation.validate()
 }
 
@@ -96,11 +141,24 @@ type backupStats struct {
 	k                 *kopia.BackupStats
 	gc                *support.ConnectorOperationStatus
 	resourceCount     int
+	baseBackupIDs     []model.StableID
+	incremental       bool
+	projectedRepoSize int64
+	quotaAlert        string
+	alerts            []string
+	timeline          stats.Timeline
+	performance       *stats.PerformanceRecorder
+	driveTransitions  []backup.DriveTransition
 	readErr, writeErr error
 }
 
 type detailsWriter interface {
-	WriteBackupDetails(context.Context, *details.Details, *fault.Errors) (string, error)
+	WriteBackupDetails(context.Context, string, *details.Details, *fault.Errors) (string, error)
+}
+
+type detailsReadWriter interface {
+	detailsReader
+	detailsWriter
 }
 
 // ---------------------------------------------------------------------------
@@ -134,13 +192,15 @@ func (op *BackupOperation) Run(ctx context.Context) (err error) {
 
 	op.Results.BackupID = model.StableID(uuid.NewString())
 
-	ctx = clues.Add(
-		ctx,
-		"tenant_id", op.account.ID(), // TODO: pii
-		"resource_owner", op.ResourceOwner, // TODO: pii
-		"backup_id", op.Results.BackupID,
-		"service", op.Selectors.Service,
-		"incremental", op.incremental)
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		OperationID:   op.ID,
+		Kind:          opctx.Backup,
+		TenantID:      op.account.ID(),
+		BackupID:      string(op.Results.BackupID),
+		Service:       op.Selectors.Service.String(),
+		ResourceOwner: op.ResourceOwner,
+	})
+	ctx = clues.Add(ctx, "incremental", op.incremental)
 
 	op.bus.Event(
 		ctx,
@@ -155,11 +215,17 @@ func (op *BackupOperation) Run(ctx context.Context) (err error) {
 	// Execution
 	// -----
 
+	alarm := startDurationAlarm(ctx, op.bus, op.Options.Alerts.MaxDuration)
+
 	deets, err := op.do(
 		ctx,
 		&opStats,
 		detailsStore,
 		op.Results.BackupID)
+
+	if alarm.stop() {
+		opStats.alerts = append([]string{AlertDuration}, opStats.alerts...)
+	}
 	if err != nil {
 		// No return here!  We continue down to persistResults, even in case of failure.
 		logger.Ctx(ctx).
@@ -170,7 +236,7 @@ func (op *BackupOperation) Run(ctx context.Context) (err error) {
 	}
 
 	// TODO: the consumer (sdk or cli) should run this, not operations.
-	recoverableCount := len(op.Errors.Errs())
+	recoverableCount := op.Errors.Count()
 	for i, err := range op.Errors.Errs() {
 		logger.Ctx(ctx).
 			With("error", err).
@@ -186,6 +252,7 @@ func (op *BackupOperation) Run(ctx context.Context) (err error) {
 	if err != nil {
 		op.Errors.Fail(errors.Wrap(err, "persisting backup results"))
 		opStats.writeErr = op.Errors.Err()
+		op.catalogFailure(ctx)
 
 		return op.Errors.Err()
 	}
@@ -195,10 +262,12 @@ func (op *BackupOperation) Run(ctx context.Context) (err error) {
 		detailsStore,
 		opStats.k.SnapshotID,
 		op.Results.BackupID,
-		deets.Details())
+		deets)
 	if err != nil {
 		op.Errors.Fail(errors.Wrap(err, "persisting backup"))
 		opStats.writeErr = op.Errors.Err()
+		op.Status = Failed
+		op.catalogFailure(ctx)
 
 		return op.Errors.Err()
 	}
@@ -219,9 +288,67 @@ func (op *BackupOperation) do(
 	reasons := selectorToReasons(op.Selectors)
 	logger.Ctx(ctx).With("selectors", op.Selectors).Info("backing up selection")
 
+	// structure-only snapshots hold no items, so they're kept out of the
+	// reasons that later backups use to find their bases.
+	if op.Options.StructureOnly {
+		reasons = nil
+	}
+
 	// should always be 1, since backups are 1:1 with resourceOwners.
 	opStats.resourceCount = 1
 
+	quota, err := checkQuota(ctx, op.kopia, op.store)
+	if err != nil {
+		return nil, errors.Wrap(err, "checking repository quota")
+	}
+
+	if op.Options.Spill.Enabled() {
+		buf, err := spill.New(op.Options.Spill.Dir, op.Options.Spill.MaxBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating disk spill buffer")
+		}
+
+		defer func() {
+			if err := buf.Close(); err != nil {
+				logger.Ctx(ctx).With("err", err).Errorw("removing disk spill buffer", clues.InErr(err).Slice()...)
+			}
+		}()
+
+		ctx = spill.Set(ctx, buf)
+	}
+
+	if op.Options.MaxMemoryMB > 0 {
+		wd, err := memlimit.New(op.Options.MaxMemoryMB)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating memory watchdog")
+		}
+
+		wd.Start(ctx)
+		defer wd.Stop()
+
+		ctx = memlimit.Set(ctx, wd)
+	}
+
+	if op.Options.Heartbeat.Enabled() {
+		wd, err := stall.New(op.bus, op.Options.Heartbeat)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating stall watchdog")
+		}
+
+		wd.Start(ctx)
+		defer wd.Stop()
+
+		ctx = stall.Set(ctx, wd)
+	}
+
+	ctx = bandwidth.Set(ctx, bandwidth.New(op.Options.Bandwidth, bandwidth.Backup))
+
+	// categories of a mailbox share its request limit, so that a large
+	// category doesn't hold back the others.
+	if op.Selectors.Service == selectors.ServiceExchange {
+		ctx = fairshare.Set(ctx, fairshare.New(fairshare.DefaultSlots, op.Options.Tuning.CategoryWeights))
+	}
+
 	mans, mdColls, canUseMetaData, err := produceManifestsAndMetadata(
 		ctx,
 		op.kopia,
@@ -234,20 +361,59 @@ func (op *BackupOperation) do(
 		return nil, errors.Wrap(err, "producing manifests and metadata")
 	}
 
+	opStats.baseBackupIDs = baseBackupIDs(mans)
+	opStats.incremental = op.incremental && canUseMetaData && len(opStats.baseBackupIDs) > 0
+
+	ctx = clues.Add(ctx, "base_backup_ids", opStats.baseBackupIDs)
+
 	gc, err := connectToM365(ctx, op.Selectors, op.account, op.Errors)
 	if err != nil {
 		return nil, errors.Wrap(err, "connectng to m365")
 	}
 
-	cs, excludes, err := produceBackupDataCollections(ctx, gc, op.Selectors, mdColls, op.Options, op.Errors)
+	opStats.timeline.Mark(stats.PhaseDiscoveryStarted)
+
+	opStats.performance = stats.NewPerformanceRecorder()
+	ctx = stats.SetRecorder(ctx, opStats.performance)
+
+	transitions := &onedrive.Transitions{}
+
+	cs, excludes, err := produceBackupDataCollections(
+		onedrive.SetTransitions(ctx, transitions),
+		gc,
+		op.Selectors,
+		mdColls,
+		op.Options,
+		op.Errors)
 	if err != nil {
 		return nil, errors.Wrap(err, "producing backup data collections")
 	}
 
+	opStats.driveTransitions = transitions.All()
+
+	opStats.timeline.Mark(stats.PhaseDiscoveryCompleted)
+
 	ctx = clues.Add(ctx, "coll_count", len(cs))
 
+	// Base details are merged as the items sourced from base snapshots are
+	// uploaded, rather than in a full pass once the upload completes.  Only
+	// one base's details are held in memory at a time.
+	var (
+		bdm     *baseDetailsMerger
+		backCtx = ctx
+	)
+
+	if op.incremental && canUseMetaData && len(mans) > 0 {
+		bdm, err = newBaseDetailsMerger(ctx, op.store, detailsStore, mans, op.Errors)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading base details")
+		}
+
+		backCtx = kopia.SetDetailsMerger(ctx, bdm)
+	}
+
 	writeStats, deets, toMerge, err := consumeBackupDataCollections(
-		ctx,
+		backCtx,
 		op.kopia,
 		op.account.ID(),
 		reasons,
@@ -262,19 +428,35 @@ func (op *BackupOperation) do(
 	}
 
 	opStats.k = writeStats
+	opStats.timeline.Add(stats.PhaseFirstByteUploaded, writeStats.FirstUploadAt)
+
+	if quota.Limits.Enabled() {
+		opStats.projectedRepoSize = recordUpload(ctx, op.store, quota, writeStats.TotalUploadedBytes)
+		opStats.quotaAlert = quotaAlert(ctx, op.bus, quota.Limits, opStats.projectedRepoSize)
+	}
+
+	opStats.timeline.Mark(stats.PhaseDetailsMergeStarted)
+
+	if bdm != nil {
+		err = bdm.finish(ctx, toMerge, excludes, deets)
+	} else {
+		err = mergeDetails(
+			ctx,
+			op.store,
+			detailsStore,
+			mans,
+			toMerge,
+			excludes,
+			deets,
+			op.Errors)
+	}
 
-	err = mergeDetails(
-		ctx,
-		op.store,
-		detailsStore,
-		mans,
-		toMerge,
-		deets,
-		op.Errors)
 	if err != nil {
 		return nil, errors.Wrap(err, "merging details")
 	}
 
+	opStats.timeline.Mark(stats.PhaseDetailsMergeCompleted)
+
 	opStats.gc = gc.AwaitStatus()
 	// TODO(keepers): remove when fault.Errors handles all iterable error aggregation.
 	if opStats.gc.ErrorCount > 0 {
@@ -283,6 +465,10 @@ func (op *BackupOperation) do(
 
 	logger.Ctx(ctx).Debug(gc.PrintableStatus())
 
+	if opStats.incremental {
+		opStats.alerts = changeAlerts(ctx, op.bus, op.Options.Alerts, deets.Summary())
+	}
+
 	return deets, nil
 }
 
@@ -294,7 +480,7 @@ func useIncrementalBackup(sel selectors.Selector, opts control.Options) bool {
 		return false
 	}
 
-	return !opts.ToggleFeatures.DisableIncrementals
+	return !opts.ToggleFeatures.DisableIncrementals && !opts.StructureOnly
 }
 
 // ---------------------------------------------------------------------------
@@ -361,7 +547,7 @@ func selectorToReasons(sel selectors.Selector) []kopia.Reason {
 }
 
 func builderFromReason(ctx context.Context, tenant string, r kopia.Reason) (*path.Builder, error) {
-	ctx = clues.Add(ctx, "category", r.Category.String())
+	ctx = opctx.Set(ctx, opctx.Metadata{Category: r.Category.String()})
 
 	// This is hacky, but we want the path package to format the path the right
 	// way (e.x. proper order for service, category, etc), but we don't care about
@@ -493,22 +679,92 @@ func matchesReason(reasons []kopia.Reason, p path.Path) bool {
 	return false
 }
 
+// mergeDetails adds the base details entries of the items sourced from base
+// snapshots to the details, along with tombstones for excluded items.  Used
+// when the entries weren't merged while the backup was running.
 func mergeDetails(
 	ctx context.Context,
 	ms *store.Wrapper,
 	detailsStore detailsReader,
 	mans []*kopia.ManifestEntry,
 	shortRefsFromPrevBackup map[string]kopia.PrevRefs,
+	excludes map[string]struct{},
 	deets *details.Builder,
 	errs *fault.Errors,
 ) error {
 	// Don't bother loading any of the base details if there's nothing we need to
-	// merge.
-	if len(shortRefsFromPrevBackup) == 0 {
+	// merge, and no deletions to record.
+	if len(shortRefsFromPrevBackup) == 0 && len(excludes) == 0 {
 		return nil
 	}
 
-	var addedEntries int
+	bdm, err := newBaseDetailsMerger(ctx, ms, detailsStore, mans, errs)
+	if err != nil {
+		return err
+	}
+
+	return bdm.finish(ctx, shortRefsFromPrevBackup, excludes, deets)
+}
+
+var _ kopia.DetailsMerger = &baseDetailsMerger{}
+
+// baseEntry is a details entry from a base backup, along with its
+// parsed repoRef.
+type baseEntry struct {
+	entry   details.DetailsEntry
+	repoRef path.Path
+}
+
+// baseBackup is a backup chosen as a base, along with the reasons for
+// which each of its manifests was chosen.  Manifests of the same backup
+// share a baseBackup, so that its details are read once.
+type baseBackup struct {
+	id      model.StableID
+	reasons [][]kopia.Reason
+}
+
+// baseDetailsMerger merges the details entries of the base backups into
+// the backup details, one base at a time, so that only a single base's
+// details are held in memory.  The first base is loaded up front, and its
+// entries get merged as the items they describe are sourced from a base
+// snapshot while the backup is running.  Items sourced from the other
+// bases are left for finish, which streams through the rest of the bases.
+type baseDetailsMerger struct {
+	mu sync.Mutex
+
+	ms           *store.Wrapper
+	detailsStore detailsReader
+	errs         *fault.Errors
+
+	// the bases whose details haven't been loaded yet.
+	pending []baseBackup
+	// unmerged entries of the loaded base, keyed by the ShortRef of their
+	// repoRef.
+	entries map[string][]baseEntry
+	// the count of refs and entries merged so far.
+	mergedRefs    int
+	mergedEntries int
+	// err records the first failure to merge an entry.
+	err error
+}
+
+// newBaseDetailsMerger produces a merger of the details of each complete
+// base backup, and loads the details of the first base.
+func newBaseDetailsMerger(
+	ctx context.Context,
+	ms *store.Wrapper,
+	detailsStore detailsReader,
+	mans []*kopia.ManifestEntry,
+	errs *fault.Errors,
+) (*baseDetailsMerger, error) {
+	var (
+		bdm = &baseDetailsMerger{
+			ms:           ms,
+			detailsStore: detailsStore,
+			errs:         errs,
+		}
+		byID = map[model.StableID]int{}
+	)
 
 	for _, man := range mans {
 		mctx := clues.Add(ctx, "manifest_id", man.ID)
@@ -521,92 +777,204 @@ func mergeDetails(
 
 		bID, ok := man.GetTag(kopia.TagBackupID)
 		if !ok {
-			return clues.New("no backup ID in snapshot manifest").WithClues(mctx)
+			return nil, clues.New("no backup ID in snapshot manifest").WithClues(mctx)
 		}
 
-		mctx = clues.Add(mctx, "manifest_backup_id", bID)
+		id := model.StableID(bID)
 
-		_, baseDeets, err := getBackupAndDetailsFromID(
-			ctx,
-			model.StableID(bID),
-			ms,
-			detailsStore,
-			errs)
+		if i, ok := byID[id]; ok {
+			bdm.pending[i].reasons = append(bdm.pending[i].reasons, man.Reasons)
+			continue
+		}
+
+		byID[id] = len(bdm.pending)
+		bdm.pending = append(bdm.pending, baseBackup{id: id, reasons: [][]kopia.Reason{man.Reasons}})
+	}
+
+	if _, err := bdm.next(ctx); err != nil {
+		return nil, err
+	}
+
+	return bdm, nil
+}
+
+// next replaces the loaded base entries with those of the next pending
+// base.  Returns false once no bases remain.
+func (bdm *baseDetailsMerger) next(ctx context.Context) (bool, error) {
+	bdm.mu.Lock()
+	defer bdm.mu.Unlock()
+
+	bdm.entries = map[string][]baseEntry{}
+
+	if len(bdm.pending) == 0 {
+		return false, nil
+	}
+
+	bb := bdm.pending[0]
+	bdm.pending = bdm.pending[1:]
+
+	ctx = clues.Add(ctx, "manifest_backup_id", bb.id)
+
+	_, baseDeets, err := getBackupAndDetailsFromID(ctx, bb.id, bdm.ms, bdm.detailsStore, bdm.errs)
+	if err != nil {
+		return false, clues.New("fetching base details for backup").WithClues(ctx)
+	}
+
+	for _, entry := range baseDeets.Items() {
+		rr, err := path.FromDataLayerPath(entry.RepoRef, true)
 		if err != nil {
-			return clues.New("fetching base details for backup").WithClues(mctx)
+			return false, clues.New("parsing base item info path").
+				WithClues(ctx).
+				With("repo_ref", logger.PII(entry.RepoRef))
 		}
 
-		for _, entry := range baseDeets.Items() {
-			rr, err := path.FromDataLayerPath(entry.RepoRef, true)
-			if err != nil {
-				return clues.New("parsing base item info path").
-					WithClues(mctx).
-					With("repo_ref", entry.RepoRef) // todo: pii
+		// Although this base has an entry it may not be the most recent. Check
+		// the reasons a snapshot was returned to ensure we only choose the recent
+		// entries.
+		//
+		// TODO(ashmrtn): This logic will need expanded to cover entries from
+		// checkpoints if we start doing kopia-assisted incrementals for those.
+		for _, reasons := range bb.reasons {
+			if matchesReason(reasons, rr) {
+				bdm.entries[rr.ShortRef()] = append(bdm.entries[rr.ShortRef()], baseEntry{*entry, rr})
 			}
+		}
+	}
 
-			// Although this base has an entry it may not be the most recent. Check
-			// the reasons a snapshot was returned to ensure we only choose the recent
-			// entries.
-			//
-			// TODO(ashmrtn): This logic will need expanded to cover entries from
-			// checkpoints if we start doing kopia-assisted incrementals for those.
-			if !matchesReason(man.Reasons, rr) {
-				continue
-			}
+	return true, nil
+}
 
-			prev, ok := shortRefsFromPrevBackup[rr.ShortRef()]
-			if !ok {
-				// This entry was not sourced from a base snapshot or cached from a
-				// previous backup, skip it.
-				continue
-			}
+// Merge adds the base entries of the item to the details, if the item
+// belongs to the loaded base.  Safe for concurrent use.
+func (bdm *baseDetailsMerger) Merge(deets *details.Builder, prevShortRef string, refs kopia.PrevRefs) bool {
+	bdm.mu.Lock()
+
+	bes, ok := bdm.entries[prevShortRef]
+	if !ok {
+		bdm.mu.Unlock()
+		return false
+	}
 
-			newPath := prev.Repo
-			newLoc := prev.Location
+	delete(bdm.entries, prevShortRef)
+	bdm.mergedRefs++
+	bdm.mergedEntries += len(bes)
 
-			// Fixup paths in the item.
-			item := entry.ItemInfo
-			if err := details.UpdateItem(&item, newPath); err != nil {
-				return clues.New("updating item details").WithClues(mctx)
+	bdm.mu.Unlock()
+
+	for _, be := range bes {
+		if err := mergeEntry(deets, be, refs); err != nil {
+			bdm.mu.Lock()
+			if bdm.err == nil {
+				bdm.err = err
 			}
+			bdm.mu.Unlock()
+		}
+	}
+
+	return true
+}
+
+// finish streams through the bases, merging the entries of the refs that
+// weren't merged while the backup was running.  It then checks that every
+// sourced item received an entry, and records the excluded entries that
+// weren't sourced as tombstones.
+func (bdm *baseDetailsMerger) finish(
+	ctx context.Context,
+	shortRefsFromPrevBackup map[string]kopia.PrevRefs,
+	excludes map[string]struct{},
+	deets *details.Builder,
+) error {
+	var (
+		unmerged = maps.Clone(shortRefsFromPrevBackup)
+		excluded []details.DetailsEntry
+	)
 
-			// TODO(ashmrtn): This may need updated if we start using this merge
-			// strategry for items that were cached in kopia.
-			var (
-				itemUpdated = newPath.String() != rr.String()
-				newLocStr   string
-				locBuilder  *path.Builder
-			)
-
-			if newLoc != nil {
-				locBuilder = newLoc.ToBuilder()
-				newLocStr = newLoc.Folder(true)
-				itemUpdated = itemUpdated || newLocStr != entry.LocationRef
+	for loaded := true; loaded; {
+		for ref, refs := range unmerged {
+			if bdm.Merge(deets, ref, refs) {
+				delete(unmerged, ref)
 			}
+		}
 
-			deets.Add(
-				newPath.String(),
-				newPath.ShortRef(),
-				newPath.ToBuilder().Dir().ShortRef(),
-				newLocStr,
-				itemUpdated,
-				item)
+		if bdm.err != nil {
+			return clues.Stack(bdm.err).WithClues(ctx)
+		}
+
+		// base entries for items that were excluded from this backup.
+		// Any that aren't re-added by this backup were deleted.
+		for _, bes := range bdm.entries {
+			for _, be := range bes {
+				if _, ok := excludes[be.repoRef.Item()]; ok {
+					excluded = append(excluded, be.entry)
+				}
+			}
+		}
 
-			folders := details.FolderEntriesForPath(newPath.ToBuilder().Dir(), locBuilder)
-			deets.AddFoldersForItem(folders, item, itemUpdated)
+		var err error
 
-			// Track how many entries we added so that we know if we got them all when
-			// we're done.
-			addedEntries++
+		if loaded, err = bdm.next(ctx); err != nil {
+			return err
 		}
 	}
 
-	if addedEntries != len(shortRefsFromPrevBackup) {
+	// each sourced item expects exactly one base entry.
+	expected := bdm.mergedRefs + len(unmerged)
+
+	if bdm.mergedEntries != expected {
 		return clues.New("incomplete migration of backup details").
 			WithClues(ctx).
-			With("item_count", addedEntries, "expected_item_count", len(shortRefsFromPrevBackup))
+			With("item_count", bdm.mergedEntries, "expected_item_count", expected)
+	}
+
+	deets.AddTombstones(excluded)
+
+	return nil
+}
+
+// mergeEntry adds the base entry to the details, updated with the refs
+// of the item in the new snapshot.
+func mergeEntry(deets *details.Builder, be baseEntry, refs kopia.PrevRefs) error {
+	var (
+		newPath = refs.Repo
+		newLoc  = refs.Location
+		// Fixup paths in the item.
+		item = be.entry.ItemInfo
+	)
+
+	if err := details.UpdateItem(&item, newPath); err != nil {
+		return clues.New("updating item details")
+	}
+
+	// TODO(ashmrtn): This may need updated if we start using this merge
+	// strategry for items that were cached in kopia.
+	var (
+		itemUpdated = newPath.String() != be.repoRef.String()
+		newLocStr   string
+		locBuilder  *path.Builder
+	)
+
+	if newLoc != nil {
+		locBuilder = newLoc.ToBuilder()
+		newLocStr = newLoc.Folder(true)
+		itemUpdated = itemUpdated || newLocStr != be.entry.LocationRef
+	}
+
+	deets.AddWithExtensions(
+		newPath.String(),
+		newPath.ShortRef(),
+		newPath.ToBuilder().Dir().ShortRef(),
+		newLocStr,
+		itemUpdated,
+		item,
+		be.entry.Extensions)
+
+	if itemUpdated {
+		deets.MarkChanged(newPath.String())
 	}
 
+	folders := details.FolderEntriesForPath(newPath.ToBuilder().Dir(), locBuilder)
+	deets.AddFoldersForItem(folders, item, itemUpdated)
+
 	return nil
 }
 
@@ -620,6 +988,14 @@ func (op *BackupOperation) persistResults(
 	op.Results.CompletedAt = time.Now()
 	op.Results.ReadErrors = opStats.readErr
 	op.Results.WriteErrors = opStats.writeErr
+	op.Results.BaseBackupIDs = opStats.baseBackupIDs
+	op.Results.Incremental = opStats.incremental
+	op.Results.ProjectedRepoSize = opStats.projectedRepoSize
+	op.Results.QuotaAlert = opStats.quotaAlert
+	op.Results.Alerts = opStats.alerts
+	op.Results.Timeline = opStats.timeline
+	op.Results.Performance = opStats.performance.Performance()
+	op.Results.DriveTransitions = opStats.driveTransitions
 
 	op.Status = Completed
 
@@ -652,21 +1028,48 @@ func (op *BackupOperation) persistResults(
 	return nil
 }
 
+// catalogFailure mirrors a failed backup to the catalog.  Failed backups
+// don't persist a backup model, so the record is built from the
+// operation's results.
+func (op *BackupOperation) catalogFailure(ctx context.Context) {
+	if op.Catalog == nil {
+		return
+	}
+
+	b := backup.New(
+		"", "", Failed.String(),
+		op.Results.BackupID,
+		op.Selectors,
+		op.Results.ReadWrites,
+		op.Results.StartAndEndTime,
+		op.Errors)
+	b.Incremental = op.Results.Incremental
+
+	if err := op.Catalog.Put(ctx, b); err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("syncing backup catalog", clues.InErr(err).Slice()...)
+	}
+}
+
 // stores the operation details, results, and selectors in the backup manifest.
 func (op *BackupOperation) createBackupModels(
 	ctx context.Context,
-	detailsStore detailsWriter,
+	detailsStore detailsReadWriter,
 	snapID string,
 	backupID model.StableID,
-	backupDetails *details.Details,
+	deets *details.Builder,
 ) error {
 	ctx = clues.Add(ctx, "snapshot_id", snapID)
 
-	if backupDetails == nil {
+	op.Results.Timeline.Mark(stats.PhaseModelPersistStarted)
+
+	if deets == nil {
 		return clues.New("no backup details to record").WithClues(ctx)
 	}
 
-	detailsID, err := detailsStore.WriteBackupDetails(ctx, backupDetails, op.Errors)
+	d := deets.Details()
+	op.Results.Anomalies = op.detectAnomalies(ctx, detailsStore, d)
+
+	detailsID, err := detailsStore.WriteBackupDetails(ctx, string(backupID), d, op.Errors)
 	if err != nil {
 		return clues.Wrap(err, "creating backupDetails model").WithClues(ctx)
 	}
@@ -680,12 +1083,47 @@ func (op *BackupOperation) createBackupModels(
 		op.Results.StartAndEndTime,
 		op.Errors,
 	)
+	b.BaseBackupIDs = op.Results.BaseBackupIDs
+	b.Incremental = op.Results.Incremental
+	b.Timeline = op.Results.Timeline
+	b.Performance = op.Results.Performance
+	b.DriveTransitions = op.Results.DriveTransitions
+	b.Anomalies = op.Results.Anomalies
+	b.StructureOnly = op.Options.StructureOnly
+
+	if op.Options.Staging {
+		b.Staging = true
+		b.Tags[model.StagingTag] = "true"
+	}
 
 	if err = op.store.Put(ctx, model.BackupSchema, b); err != nil {
 		return clues.Wrap(err, "creating backup model").WithClues(ctx)
 	}
 
-	dur := op.Results.CompletedAt.Sub(op.Results.StartedAt)
+	// the catalog only mirrors the repository, and shouldn't fail an
+	// otherwise successful backup.
+	if err := op.Catalog.Put(ctx, b); err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("syncing backup catalog", clues.InErr(err).Slice()...)
+	}
+
+	var (
+		dur     = op.Results.CompletedAt.Sub(op.Results.StartedAt)
+		summary = deets.Summary()
+	)
+
+	// trends are a convenience for reporting, and shouldn't fail
+	// an otherwise successful backup.  Structure-only backups hold no
+	// items, and would skew the trend.
+	if !b.StructureOnly {
+		err = op.store.AddTrendPoint(
+			ctx,
+			op.Selectors.PathService(),
+			op.Selectors.DiscreteOwner,
+			trendPoint(b, dur, summary))
+		if err != nil {
+			logger.Ctx(ctx).With("err", err).Errorw("recording backup trend", clues.InErr(err).Slice()...)
+		}
+	}
 
 	op.bus.Event(
 		ctx,
@@ -699,8 +1137,131 @@ func (op *BackupOperation) createBackupModels(
 			events.Service:    op.Selectors.PathService().String(),
 			events.StartTime:  common.FormatTime(op.Results.StartedAt),
 			events.Status:     op.Status.String(),
+			events.Summary:    summary,
+			events.TopErrors:  topErrors(op.Errors.Groups(), maxTopErrors),
 		},
 	)
 
 	return nil
 }
+
+// detectAnomalies compares the details of an incremental backup against the
+// details of its bases, and emits a warning event for each suspicious
+// pattern of changes.  Anomaly detection is advisory: failing to read the
+// base details is logged, and doesn't fail the backup.
+func (op *BackupOperation) detectAnomalies(
+	ctx context.Context,
+	detailsStore detailsReader,
+	d *details.Details,
+) []details.Anomaly {
+	if !op.Options.Alerts.DetectAnomalies || !op.Results.Incremental {
+		return nil
+	}
+
+	var base details.DetailsModel
+
+	for _, bID := range op.Results.BaseBackupIDs {
+		_, bd, err := getBackupAndDetailsFromID(ctx, bID, op.store, detailsStore, fault.New(true))
+		if err != nil {
+			logger.Ctx(ctx).
+				With("err", err, "base_backup_id", bID).
+				Errorw("reading base details for anomaly detection", clues.InErr(err).Slice()...)
+
+			return nil
+		}
+
+		base.Entries = append(base.Entries, bd.Entries...)
+	}
+
+	anomalies := d.Anomalies(base, details.DefaultAnomalyThresholds)
+
+	for _, a := range anomalies {
+		logger.Ctx(ctx).Infow(
+			"backup changes resemble a suspicious pattern",
+			"anomaly", a.Kind,
+			"items", a.Items,
+			"total", a.Total)
+
+		op.bus.Event(
+			ctx,
+			events.BackupAnomaly,
+			map[string]any{
+				events.Anomaly:          string(a.Kind),
+				events.AnomalyExtension: a.Extension,
+				events.AnomalyItems:     a.Items,
+				events.AnomalyTotal:     a.Total,
+				events.BackupID:         op.Results.BackupID,
+			})
+	}
+
+	return anomalies
+}
+
+// trendPoint produces the backup's stats for recording in the owner's
+// backup trend.
+func trendPoint(
+	b *backup.Backup,
+	dur time.Duration,
+	summary map[string]details.CategorySummary,
+) backup.TrendPoint {
+	p := backup.TrendPoint{
+		BackupID:      b.ID,
+		StartedAt:     b.StartedAt,
+		Duration:      dur,
+		Status:        b.Status,
+		BytesRead:     b.BytesRead,
+		BytesUploaded: b.BytesUploaded,
+	}
+
+	for _, cs := range summary {
+		p.Items += cs.Items
+		p.Changed += cs.New + cs.Changed + cs.Deleted
+	}
+
+	return p
+}
+
+// maxTopErrors is the number of error classes reported at the end of a
+// backup.
+const maxTopErrors = 5
+
+// errorCount counts the recoverable errors which share a class.
+type errorCount struct {
+	Class string `json:"class"`
+	Count int    `json:"count"`
+}
+
+// topErrors produces the n most frequent classes of error, most frequent
+// first.  The errors end up in telemetry, so they're reduced to their
+// graph.ErrClass; error messages can hold names and paths of user data.
+func topErrors(groups []fault.Group, n int) []errorCount {
+	counts := map[string]int{}
+
+	for _, g := range groups {
+		class := "other"
+		if len(g.Samples) > 0 {
+			class = graph.ErrClass(g.Samples[0])
+		}
+
+		counts[class] += g.Count
+	}
+
+	res := make([]errorCount, 0, len(counts))
+	for class, count := range counts {
+		res = append(res, errorCount{Class: class, Count: count})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+
+		return res[i].Class < res[j].Class
+	})
+
+	if len(res) > n {
+		res = res[:n]
+	}
+
+	return res
+}
@@ -346,7 +346,11 @@ func generateContainerOfItems(
 		acct,
 		sel,
 		dest,
-		control.Options{RestorePermissions: true},
+		control.Options{
+			Permissions: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Restore: true},
+			},
+		},
 		dataColls,
 		fault.New(true))
 	require.NoError(t, err)
@@ -1087,9 +1091,11 @@ func (suite *BackupOpIntegrationSuite) TestBackup_Run_oneDrive() {
 
 	sel.Include(sel.AllData())
 
-	bo, _, _, _, closer := prepNewTestBackupOp(t, ctx, mb, sel.Selector, control.Toggles{EnablePermissionsBackup: true})
+	bo, _, _, _, closer := prepNewTestBackupOp(t, ctx, mb, sel.Selector, control.Toggles{})
 	defer closer()
 
+	bo.Options.Permissions.OneDrive.Backup = true
+
 	runAndCheckBackup(t, ctx, &bo, mb)
 }
 
@@ -2,6 +2,7 @@ package operations
 
 import (
 	"context"
+	"fmt"
 	stdpath "path"
 	"testing"
 	"time"
@@ -13,8 +14,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
+	"github.com/alcionai/corso/src/internal/events"
 	evmock "github.com/alcionai/corso/src/internal/events/mock"
 	"github.com/alcionai/corso/src/internal/kopia"
 	"github.com/alcionai/corso/src/internal/model"
@@ -22,6 +25,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/backup"
 	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/catalog"
 	"github.com/alcionai/corso/src/pkg/control"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/path"
@@ -1224,6 +1228,7 @@ func (suite *BackupOpSuite) TestBackupOperation_MergeBackupDetails_AddsItems() {
 				mdr,
 				test.inputMans,
 				test.inputShortRefsFromPrevBackup,
+				nil,
 				&deets,
 				fault.New(true))
 			test.errCheck(t, err)
@@ -1316,6 +1321,11 @@ func (suite *BackupOpSuite) TestBackupOperation_MergeBackupDetails_AddsFolders()
 
 	itemDetails.Exchange.Modified = time.Now()
 
+	// extensions are carried over from the base.
+	ext := map[string]string{"com.example/label": "confidential"}
+	populatedDetails[backup1.DetailsID].Entries[0].Extensions = ext
+	expectedEntries[0].Extensions = ext
+
 	for i := 1; i < len(pathElems); i++ {
 		expectedEntries = append(expectedEntries, *makeFolderEntry(
 			t,
@@ -1338,8 +1348,527 @@ func (suite *BackupOpSuite) TestBackupOperation_MergeBackupDetails_AddsFolders()
 		mdr,
 		inputMans,
 		inputToMerge,
+		nil,
 		&deets,
 		fault.New(true))
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, expectedEntries, deets.Details().Entries)
 }
+
+func (suite *BackupOpSuite) TestBackupOperation_MergeBackupDetails_AddsTombstones() {
+	var (
+		t = suite.T()
+
+		tenant = "a-tenant"
+		ro     = "a-user"
+
+		drivePath = func(elems ...string) path.Path {
+			return makePath(
+				t,
+				append(
+					[]string{tenant, path.OneDriveService.String(), ro, path.FilesCategory.String(), "drives", "drive-id", "root:"},
+					elems...),
+				true)
+		}
+
+		deletedPath  = drivePath("work", "deleted.data")
+		movedPath    = drivePath("work", "moved.data")
+		newMovedPath = drivePath("personal", "moved.data")
+		keptPath     = drivePath("work", "kept.data")
+
+		backup1 = backup.Backup{
+			BaseModel: model.BaseModel{
+				ID: "bid1",
+			},
+			DetailsID: "did1",
+		}
+
+		inputMans = []*kopia.ManifestEntry{
+			{
+				Manifest: makeManifest(t, backup1.ID, ""),
+				Reasons: []kopia.Reason{
+					{
+						ResourceOwner: ro,
+						Service:       path.OneDriveService,
+						Category:      path.FilesCategory,
+					},
+				},
+			},
+		}
+
+		deletedDetails = makeDetailsEntry(t, deletedPath, nil, 42, false)
+
+		populatedDetails = map[string]*details.Details{
+			backup1.DetailsID: {
+				DetailsModel: details.DetailsModel{
+					Entries: []details.DetailsEntry{
+						*deletedDetails,
+						*makeDetailsEntry(t, movedPath, nil, 42, false),
+						*makeDetailsEntry(t, keptPath, nil, 42, false),
+					},
+				},
+			},
+		}
+
+		excludes = map[string]struct{}{
+			"deleted.data": {},
+			"moved.data":   {},
+		}
+	)
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	mdr := mockDetailsReader{entries: populatedDetails}
+	w := &store.Wrapper{Storer: mockBackupStorer{entries: map[model.StableID]backup.Backup{backup1.ID: backup1}}}
+	deets := details.Builder{}
+
+	// the moved item is re-uploaded under its new location.
+	moved := makeDetailsEntry(t, newMovedPath, nil, 42, true)
+	deets.Add(moved.RepoRef, moved.ShortRef, moved.ParentRef, moved.LocationRef, moved.Updated, moved.ItemInfo)
+
+	err := mergeDetails(
+		ctx,
+		w,
+		mdr,
+		inputMans,
+		nil,
+		excludes,
+		&deets,
+		fault.New(true))
+	require.NoError(t, err)
+
+	deletedDetails.Deleted = true
+
+	dm := deets.Details().DetailsModel
+	assert.ElementsMatch(t, []*details.DetailsEntry{deletedDetails}, dm.Tombstones())
+	assert.ElementsMatch(t, []*details.DetailsEntry{moved}, dm.Items())
+}
+
+func (suite *BackupOpSuite) TestBackupOperation_BaseDetailsMerger() {
+	var (
+		tenant = "a-tenant"
+		ro     = "a-user"
+
+		ro2 = "another-user"
+
+		drivePath = func(owner string, elems ...string) path.Path {
+			return makePath(
+				suite.T(),
+				append(
+					[]string{tenant, path.OneDriveService.String(), owner, path.FilesCategory.String(), "drives", "drive-id", "root:"},
+					elems...),
+				true)
+		}
+
+		item1Path   = drivePath(ro, "work", "item1")
+		item2Path   = drivePath(ro, "work", "item2")
+		deletedPath = drivePath(ro, "work", "deleted")
+		unknownPath = drivePath(ro, "work", "unknown")
+		otherPath   = drivePath(ro2, "work", "other")
+		other2Path  = drivePath(ro2, "work", "other2")
+
+		backup1 = backup.Backup{
+			BaseModel: model.BaseModel{
+				ID: "bid1",
+			},
+			DetailsID: "did1",
+		}
+		backup2 = backup.Backup{
+			BaseModel: model.BaseModel{
+				ID: "bid2",
+			},
+			DetailsID: "did2",
+		}
+
+		reason = func(owner string) []kopia.Reason {
+			return []kopia.Reason{
+				{
+					ResourceOwner: owner,
+					Service:       path.OneDriveService,
+					Category:      path.FilesCategory,
+				},
+			}
+		}
+
+		inputMans = []*kopia.ManifestEntry{
+			{Manifest: makeManifest(suite.T(), backup1.ID, ""), Reasons: reason(ro)},
+			{Manifest: makeManifest(suite.T(), backup2.ID, ""), Reasons: reason(ro2)},
+		}
+
+		populatedDetails = map[string]*details.Details{
+			backup1.DetailsID: {
+				DetailsModel: details.DetailsModel{
+					Entries: []details.DetailsEntry{
+						*makeDetailsEntry(suite.T(), item1Path, nil, 42, false),
+						*makeDetailsEntry(suite.T(), item2Path, nil, 42, false),
+						*makeDetailsEntry(suite.T(), deletedPath, nil, 42, false),
+					},
+				},
+			},
+			backup2.DetailsID: {
+				DetailsModel: details.DetailsModel{
+					Entries: []details.DetailsEntry{
+						*makeDetailsEntry(suite.T(), otherPath, nil, 42, false),
+						*makeDetailsEntry(suite.T(), other2Path, nil, 42, false),
+					},
+				},
+			},
+		}
+	)
+
+	table := []struct {
+		name            string
+		streamed        []path.Path
+		toMerge         []path.Path
+		excludes        map[string]struct{}
+		expectMerged    []bool
+		expectErr       assert.ErrorAssertionFunc
+		expectItems     int
+		expectTombstone int
+	}{
+		{
+			name:         "all merged while running",
+			streamed:     []path.Path{item1Path, item2Path},
+			expectMerged: []bool{true, true},
+			expectErr:    assert.NoError,
+			expectItems:  2,
+		},
+		{
+			name:         "leftovers merged on finish",
+			streamed:     []path.Path{item1Path},
+			toMerge:      []path.Path{item2Path},
+			expectMerged: []bool{true},
+			expectErr:    assert.NoError,
+			expectItems:  2,
+		},
+		{
+			name:         "unknown item left for finish",
+			streamed:     []path.Path{item1Path, unknownPath},
+			toMerge:      []path.Path{unknownPath},
+			expectMerged: []bool{true, false},
+			expectErr:    assert.Error,
+		},
+		{
+			name:            "excluded items become tombstones",
+			streamed:        []path.Path{item1Path, item2Path},
+			excludes:        map[string]struct{}{"deleted": {}, "other2": {}},
+			expectMerged:    []bool{true, true},
+			expectErr:       assert.NoError,
+			expectItems:     2,
+			expectTombstone: 2,
+		},
+		{
+			name:         "later bases merged on finish",
+			streamed:     []path.Path{item1Path, otherPath},
+			toMerge:      []path.Path{item2Path, otherPath, other2Path},
+			expectMerged: []bool{true, false},
+			expectErr:    assert.NoError,
+			expectItems:  4,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t   = suite.T()
+				mdr = mockDetailsReader{entries: populatedDetails}
+				w   = &store.Wrapper{Storer: mockBackupStorer{entries: map[model.StableID]backup.Backup{
+					backup1.ID: backup1,
+					backup2.ID: backup2,
+				}}}
+				deets = &details.Builder{}
+			)
+
+			bdm, err := newBaseDetailsMerger(ctx, w, mdr, inputMans, fault.New(true))
+			require.NoError(t, err)
+
+			for i, p := range test.streamed {
+				merged := bdm.Merge(deets, p.ShortRef(), kopia.PrevRefs{Repo: p})
+				assert.Equal(t, test.expectMerged[i], merged, p.Item())
+			}
+
+			toMerge := map[string]kopia.PrevRefs{}
+			for _, p := range test.toMerge {
+				toMerge[p.ShortRef()] = kopia.PrevRefs{Repo: p}
+			}
+
+			// only the first base is held while the backup runs.
+			assert.Len(t, bdm.pending, 1)
+
+			err = bdm.finish(ctx, toMerge, test.excludes, deets)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Empty(t, bdm.pending)
+			assert.Len(t, toMerge, len(test.toMerge), "the refs to merge are left untouched")
+
+			dm := deets.Details().DetailsModel
+			assert.Len(t, dm.Items(), test.expectItems)
+			assert.Len(t, dm.Tombstones(), test.expectTombstone)
+		})
+	}
+}
+
+func (suite *BackupOpSuite) TestTopErrors() {
+	var (
+		throttled = errors.Wrap(graph.Err429TooManyRequests, "getting user@example.com/inbox")
+		canceled  = errors.Wrap(context.Canceled, "getting user@example.com/Documents/a.docx")
+		other     = errors.New("getting user@example.com/Documents/b.docx")
+		other2    = errors.New("getting user@example.com/Documents/c.docx")
+	)
+
+	table := []struct {
+		name   string
+		errs   []error
+		n      int
+		expect []errorCount
+	}{
+		{
+			name:   "no errors",
+			n:      2,
+			expect: []errorCount{},
+		},
+		{
+			name: "most frequent first",
+			errs: []error{other, throttled, throttled, canceled, throttled, other2},
+			n:    5,
+			expect: []errorCount{
+				{Class: "throttled", Count: 3},
+				{Class: "other", Count: 2},
+				{Class: "canceled", Count: 1},
+			},
+		},
+		{
+			name: "limited, ties by class",
+			errs: []error{other, throttled, canceled},
+			n:    2,
+			expect: []errorCount{
+				{Class: "canceled", Count: 1},
+				{Class: "other", Count: 1},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			errs := fault.New(false)
+			for _, err := range test.errs {
+				errs.Add(err)
+			}
+
+			assert.Equal(suite.T(), test.expect, topErrors(errs.Groups(), test.n))
+		})
+	}
+}
+
+func (suite *BackupOpSuite) TestUseIncrementalBackup() {
+	var (
+		exchange = selectors.NewExchangeBackup([]string{"user"}).Selector
+		onedrive = selectors.NewOneDriveBackup([]string{"user"}).Selector
+	)
+
+	table := []struct {
+		name   string
+		sel    selectors.Selector
+		opts   control.Options
+		expect assert.BoolAssertionFunc
+	}{
+		{
+			name:   "exchange",
+			sel:    exchange,
+			expect: assert.True,
+		},
+		{
+			name:   "onedrive",
+			sel:    onedrive,
+			expect: assert.False,
+		},
+		{
+			name:   "incrementals disabled",
+			sel:    exchange,
+			opts:   control.Options{ToggleFeatures: control.Toggles{DisableIncrementals: true}},
+			expect: assert.False,
+		},
+		{
+			name:   "structure only",
+			sel:    exchange,
+			opts:   control.Options{StructureOnly: true},
+			expect: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), useIncrementalBackup(test.sel, test.opts))
+		})
+	}
+}
+
+func (suite *BackupOpSuite) TestTrendPoint() {
+	var (
+		t   = suite.T()
+		now = time.Now()
+		b   = &backup.Backup{
+			BaseModel: model.BaseModel{ID: "bid"},
+			Status:    "Completed",
+		}
+		summary = map[string]details.CategorySummary{
+			"email":  {Items: 10, New: 1, Changed: 2, Unchanged: 7, Deleted: 1},
+			"events": {Items: 5, Changed: 1, Unchanged: 4},
+		}
+	)
+
+	b.StartedAt = now
+	b.BytesRead = 20
+	b.BytesUploaded = 10
+
+	p := trendPoint(b, time.Minute, summary)
+
+	assert.Equal(t, model.StableID("bid"), p.BackupID)
+	assert.Equal(t, now, p.StartedAt)
+	assert.Equal(t, time.Minute, p.Duration)
+	assert.Equal(t, "Completed", p.Status)
+	assert.Equal(t, int64(20), p.BytesRead)
+	assert.Equal(t, int64(10), p.BytesUploaded)
+	assert.Equal(t, 15, p.Items)
+	assert.Equal(t, 5, p.Changed)
+}
+
+func (suite *BackupOpSuite) TestBackupOperation_DetectAnomalies() {
+	var (
+		baseID = model.StableID("base")
+		base   = &details.Details{}
+		// the current backup keeps a quarter of the base's items.
+		current = &details.Details{}
+		mdr     = mockDetailsReader{entries: map[string]*details.Details{"base-deets": base}}
+		w       = &store.Wrapper{Storer: mockBackupStorer{entries: map[model.StableID]backup.Backup{
+			baseID: {BaseModel: model.BaseModel{ID: baseID}, DetailsID: "base-deets"},
+		}}}
+	)
+
+	for i := 0; i < 100; i++ {
+		p := makePath(
+			suite.T(),
+			[]string{
+				"tenant",
+				path.ExchangeService.String(),
+				"user",
+				path.EmailCategory.String(),
+				"inbox",
+				fmt.Sprintf("item%d", i),
+			},
+			true)
+		de := makeDetailsEntry(suite.T(), p, nil, 1, false)
+
+		base.Entries = append(base.Entries, *de)
+
+		if i%4 == 0 {
+			current.Entries = append(current.Entries, *de)
+		}
+	}
+
+	table := []struct {
+		name        string
+		detect      bool
+		incremental bool
+		expect      int
+	}{
+		{
+			name:        "detection disabled",
+			incremental: true,
+		},
+		{
+			name:   "full backup",
+			detect: true,
+		},
+		{
+			name:        "mass deletion",
+			detect:      true,
+			incremental: true,
+			expect:      1,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				mb = evmock.NewBus()
+				op = BackupOperation{
+					operation: operation{
+						bus:   mb,
+						store: w,
+						Options: control.Options{
+							Alerts: control.AlertOptions{DetectAnomalies: test.detect},
+						},
+					},
+					Results: BackupResults{
+						BaseBackupIDs: []model.StableID{baseID},
+						Incremental:   test.incremental,
+					},
+				}
+			)
+
+			anomalies := op.detectAnomalies(ctx, mdr, current)
+			require.Len(t, anomalies, test.expect)
+			assert.Equal(t, test.expect, mb.TimesCalled[events.BackupAnomaly])
+
+			if test.expect == 0 {
+				return
+			}
+
+			assert.Equal(t, details.MassDeletion, anomalies[0].Kind)
+			assert.Equal(t, 75, anomalies[0].Items)
+			assert.Equal(t, 100, anomalies[0].Total)
+			assert.Equal(t, string(details.MassDeletion), mb.CalledWith[events.BackupAnomaly][0][events.Anomaly])
+		})
+	}
+}
+
+type mockCatalogSink struct {
+	records []catalog.Record
+}
+
+func (m *mockCatalogSink) Put(_ context.Context, r catalog.Record) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func (m *mockCatalogSink) Delete(context.Context, string, string) error { return nil }
+func (m *mockCatalogSink) Close() error                                 { return nil }
+
+func (suite *BackupOpSuite) TestBackupOperation_CatalogFailure() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		sink = &mockCatalogSink{}
+		errs = fault.New(false)
+		op   = BackupOperation{
+			operation: operation{Errors: errs},
+			Selectors: selectors.NewExchangeBackup([]string{"uid"}).Selector,
+			Results:   BackupResults{BackupID: "bid", Incremental: true},
+			Catalog:   catalog.NewWithSink(sink, "rid"),
+		}
+	)
+
+	errs.Fail(assert.AnError)
+
+	op.catalogFailure(ctx)
+
+	require.Len(t, sink.records, 1)
+
+	r := sink.records[0]
+	assert.Equal(t, "bid", r.BackupID)
+	assert.Equal(t, "uid", r.Owner)
+	assert.Equal(t, Failed.String(), r.Status)
+	assert.True(t, r.Incremental)
+	assert.Equal(t, 1, r.Errors)
+}
@@ -0,0 +1,518 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/common/crash"
+	"github.com/alcionai/corso/src/internal/data"
+	D "github.com/alcionai/corso/src/internal/diagnostics"
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/streamstore"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/export"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// ExportOperation wraps an operation with export-specific props.
+// Exports read the selected items out of a backup and hand them to
+// a writer, or stream them to a caller's func, without communicating
+// with M365.
+type ExportOperation struct {
+	operation
+
+	BackupID  model.StableID     `json:"backupID"`
+	Results   ExportResults      `json:"results"`
+	Selectors selectors.Selector `json:"selectors"`
+	Version   string             `json:"version"`
+
+	account account.Account
+	writer  export.Writer
+	stream  export.ItemFunc
+}
+
+// ExportResults aggregate the details of the results of the operation.
+type ExportResults struct {
+	stats.ReadWrites
+	stats.StartAndEndTime
+}
+
+// NewExportOperation constructs and validates an export operation.
+func NewExportOperation(
+	ctx context.Context,
+	opts control.Options,
+	kw *kopia.Wrapper,
+	sw *store.Wrapper,
+	acct account.Account,
+	backupID model.StableID,
+	sel selectors.Selector,
+	writer export.Writer,
+	bus events.Eventer,
+) (ExportOperation, error) {
+	op := ExportOperation{
+		operation: newOperation(opts, bus, kw, sw),
+		BackupID:  backupID,
+		Selectors: sel,
+		Version:   "v0",
+		account:   acct,
+		writer:    writer,
+	}
+	if err := op.validate(); err != nil {
+		return ExportOperation{}, err
+	}
+
+	return op, nil
+}
+
+// NewStreamOperation constructs and validates an export operation that
+// hands each item, along with its details entry, to fn instead of
+// writing it to an export destination.
+func NewStreamOperation(
+	ctx context.Context,
+	opts control.Options,
+	kw *kopia.Wrapper,
+	sw *store.Wrapper,
+	acct account.Account,
+	backupID model.StableID,
+	sel selectors.Selector,
+	fn export.ItemFunc,
+	bus events.Eventer,
+) (ExportOperation, error) {
+	op := ExportOperation{
+		operation: newOperation(opts, bus, kw, sw),
+		BackupID:  backupID,
+		Selectors: sel,
+		Version:   "v0",
+		account:   acct,
+		stream:    fn,
+	}
+	if err := op.validate(); err != nil {
+		return ExportOperation{}, err
+	}
+
+	return op, nil
+}
+
+func (op ExportOperation) validate() error {
+	if op.writer == nil && op.stream == nil {
+		return errors.New("missing export writer or item func")
+	}
+
+	return op.operation.validate()
+}
+
+// aggregates stats from the export.Run().
+type exportStats struct {
+	bytesRead         *stats.ByteCounter
+	itemsRead         int
+	itemsWritten      int
+	resourceCount     int
+	readErr, writeErr error
+
+	// a transient value only used to pair up start-end events.
+	exportID string
+}
+
+// Run begins a synchronous export operation.  The writer is closed
+// once all items have been exported.
+func (op *ExportOperation) Run(ctx context.Context) (err error) {
+	defer func() {
+		if crErr := crash.Recovery(ctx, recover()); crErr != nil {
+			err = crErr
+		}
+	}()
+
+	var (
+		opStats = exportStats{
+			bytesRead: &stats.ByteCounter{},
+			exportID:  uuid.NewString(),
+		}
+		start        = time.Now()
+		detailsStore = streamstore.New(op.kopia, op.account.ID(), op.Selectors.PathService())
+	)
+
+	ctx, end := D.Span(ctx, "operations:export:run")
+	defer func() {
+		end()
+		// wait for the progress display to clean up
+		observe.Complete()
+	}()
+
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		OperationID: op.ID,
+		Kind:        opctx.Export,
+		TenantID:    op.account.ID(),
+		BackupID:    string(op.BackupID),
+		Service:     op.Selectors.Service.String(),
+	})
+
+	if err := op.do(ctx, &opStats, detailsStore, start); err != nil {
+		// No return here!  We continue down to persistResults, even in case of failure.
+		logger.Ctx(ctx).
+			With("err", err).
+			Errorw("doing export", clues.InErr(err).Slice()...)
+		op.Errors.Fail(errors.Wrap(err, "doing export"))
+		opStats.readErr = op.Errors.Err()
+	}
+
+	if op.writer != nil {
+		if err := op.writer.Close(); err != nil {
+			op.Errors.Fail(errors.Wrap(err, "closing export writer"))
+			opStats.writeErr = op.Errors.Err()
+		}
+	}
+
+	recoverableCount := op.Errors.Count()
+	for i, err := range op.Errors.Errs() {
+		logger.Ctx(ctx).
+			With("error", err).
+			With(clues.InErr(err).Slice()...).
+			Errorf("doing export: recoverable error %d of %d", i+1, recoverableCount)
+	}
+
+	if err := op.persistResults(ctx, start, &opStats); err != nil {
+		op.Errors.Fail(errors.Wrap(err, "persisting export results"))
+		return op.Errors.Err()
+	}
+
+	logger.Ctx(ctx).Infow("completed export", "results", op.Results)
+
+	return nil
+}
+
+func (op *ExportOperation) do(
+	ctx context.Context,
+	opStats *exportStats,
+	detailsStore detailsReader,
+	start time.Time,
+) error {
+	bup, deets, err := getBackupAndDetailsFromID(
+		ctx,
+		op.BackupID,
+		op.store,
+		detailsStore,
+		op.Errors)
+	if err != nil {
+		return errors.Wrap(err, "getting backup and details")
+	}
+
+	fn := op.stream
+	if fn == nil {
+		serializer, err := export.SerializerFor(op.Selectors.PathService())
+		if err != nil {
+			return err
+		}
+
+		fn = writeTo(serializer, op.writer)
+	} else {
+		fn = streamTo(fn)
+	}
+
+	fds, err := op.Selectors.Reduce(ctx, deets, op.Errors)
+	if err != nil {
+		return errors.Wrap(err, "reducing backup details")
+	}
+
+	entries, paths, err := exportEntries(ctx, fds, op.Errors)
+	if err != nil {
+		return errors.Wrap(err, "formatting paths from details")
+	}
+
+	ctx = opctx.Set(ctx, opctx.Metadata{ResourceOwner: bup.Selector.DiscreteOwner})
+	ctx = clues.Add(ctx, "details_paths", len(paths))
+
+	op.bus.Event(
+		ctx,
+		events.ExportStart,
+		map[string]any{
+			events.StartTime:        start,
+			events.BackupID:         op.BackupID,
+			events.BackupCreateTime: bup.CreationTime,
+			events.ExportID:         opStats.exportID,
+		})
+
+	observe.Message(ctx, observe.Safe(fmt.Sprintf("Discovered %d items in backup %s to export", len(paths), op.BackupID)))
+
+	kopiaComplete, closer := observe.MessageWithCompletion(ctx, observe.Safe("Enumerating items in repository"))
+	defer closer()
+	defer close(kopiaComplete)
+
+	dcs, err := op.kopia.RestoreMultipleItems(ctx, bup.SnapshotID, paths, opStats.bytesRead, op.Errors)
+	if err != nil {
+		return errors.Wrap(err, "retrieving collections from repository")
+	}
+
+	kopiaComplete <- struct{}{}
+
+	// should always be 1, since backups are 1:1 with resourceOwners.
+	opStats.resourceCount = 1
+
+	exportComplete, closer := observe.MessageWithCompletion(ctx, observe.Safe("Exporting data"))
+	defer closer()
+	defer close(exportComplete)
+
+	opStats.itemsRead, opStats.itemsWritten = exportCollections(
+		ctx,
+		dcs,
+		entries,
+		fn,
+		op.Errors)
+
+	exportComplete <- struct{}{}
+
+	return op.Errors.Err()
+}
+
+// exportEntries produces the details entries for each item in the reduced
+// details, keyed by repoRef, along with the item paths.
+func exportEntries(
+	ctx context.Context,
+	deets *details.Details,
+	errs *fault.Errors,
+) (map[string]details.DetailsEntry, []path.Path, error) {
+	var (
+		items   = deets.Items()
+		entries = make(map[string]details.DetailsEntry, len(items))
+		paths   = make([]path.Path, 0, len(items))
+		et      = errs.Tracker()
+	)
+
+	for _, ent := range items {
+		if et.Err() != nil {
+			break
+		}
+
+		p, err := path.FromDataLayerPath(ent.RepoRef, true)
+		if err != nil {
+			et.Add(clues.
+				Wrap(err, "parsing details path after reduction").
+				WithClues(ctx).
+				With("path", ent.RepoRef))
+
+			continue
+		}
+
+		entries[p.String()] = *ent
+		paths = append(paths, p)
+	}
+
+	return entries, paths, et.Err()
+}
+
+// exportCollections hands every item in the collections, along with its
+// details entry, to fn.  Item failures are recoverable.  Returns the count
+// of items read and handled.
+func exportCollections(
+	ctx context.Context,
+	dcs []data.RestoreCollection,
+	entries map[string]details.DetailsEntry,
+	fn export.ItemFunc,
+	errs *fault.Errors,
+) (int, int) {
+	var (
+		read, handled int
+		et            = errs.Tracker()
+	)
+
+	for _, dc := range dcs {
+		if et.Err() != nil {
+			break
+		}
+
+		for item := range dc.Items(ctx, errs) {
+			if et.Err() != nil {
+				break
+			}
+
+			read++
+
+			ictx := clues.Add(ctx, "item_uuid", item.UUID())
+
+			ip, err := dc.FullPath().Append(item.UUID(), true)
+			if err != nil {
+				et.Add(clues.Wrap(err, "building item path").WithClues(ictx))
+				continue
+			}
+
+			ent, ok := entries[ip.String()]
+			if !ok {
+				et.Add(clues.New("no details entry for item").WithClues(ictx))
+				continue
+			}
+
+			if err := handleItem(ictx, item, ent, fn); err != nil {
+				et.Add(err)
+				continue
+			}
+
+			handled++
+		}
+	}
+
+	return read, handled
+}
+
+func handleItem(
+	ctx context.Context,
+	item data.Stream,
+	ent details.DetailsEntry,
+	fn export.ItemFunc,
+) error {
+	rc := item.ToReader()
+	defer rc.Close()
+
+	r := io.Reader(rc)
+
+	if ss, ok := item.(data.StreamSize); ok {
+		r = export.SizedReader(rc, ss.Size())
+	}
+
+	return fn(ctx, ent, r)
+}
+
+// writeTo produces an ItemFunc that serializes each item and writes it to
+// the writer.  Names which collide with a previously exported item are
+// suffixed with a counter.
+func writeTo(serializer export.Serializer, writer export.Writer) export.ItemFunc {
+	names := map[string]int{}
+
+	return func(ctx context.Context, ent details.DetailsEntry, r io.Reader) error {
+		name, err := serializer.Name(ent)
+		if err != nil {
+			return clues.Wrap(err, "naming exported item").WithClues(ctx)
+		}
+
+		sr, err := serializer.Serialize(r)
+		if err != nil {
+			return clues.Wrap(err, "serializing exported item").WithClues(ctx)
+		}
+
+		if err := writer.Write(ctx, uniqueName(names, name), sr); err != nil {
+			return clues.Wrap(err, "writing exported item").WithClues(ctx)
+		}
+
+		return nil
+	}
+}
+
+// streamTo wraps the caller's ItemFunc, attributing its errors.
+func streamTo(fn export.ItemFunc) export.ItemFunc {
+	return func(ctx context.Context, ent details.DetailsEntry, r io.Reader) error {
+		if err := fn(ctx, ent, r); err != nil {
+			return clues.Wrap(err, "streaming item").WithClues(ctx)
+		}
+
+		return nil
+	}
+}
+
+// StreamErr reports the items that the caller's item func failed to
+// handle.  Those failures don't stop the export, but a caller feeding
+// its own pipeline needs to know that the pipeline missed items.
+// Returns nil if every item was handled, or if the operation writes to
+// an export destination instead of streaming.
+func (op ExportOperation) StreamErr() error {
+	if op.stream == nil || op.Errors.Count() == 0 {
+		return nil
+	}
+
+	errs := op.Errors.Errs()
+
+	var err error
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+
+	return clues.Wrap(err, "streaming backup items").With("failed_items", op.Errors.Count())
+}
+
+// uniqueName returns the name unchanged on its first use, and with an
+// incrementing " (n)" suffix, ahead of any extension, on each reuse.
+func uniqueName(names map[string]int, name string) string {
+	n := names[name]
+	names[name] = n + 1
+
+	if n == 0 {
+		return name
+	}
+
+	base, ext := name, ""
+
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.LastIndexByte(name, '.'); dot > slash+1 {
+		base, ext = name[:dot], name[dot:]
+	}
+
+	return uniqueName(names, base+" ("+strconv.Itoa(n)+")"+ext)
+}
+
+// persists details and statistics about the export operation.
+func (op *ExportOperation) persistResults(
+	ctx context.Context,
+	started time.Time,
+	opStats *exportStats,
+) error {
+	op.Results.StartedAt = started
+	op.Results.CompletedAt = time.Now()
+
+	op.Status = Completed
+
+	if opStats.readErr != nil || opStats.writeErr != nil {
+		op.Status = Failed
+
+		return multierror.Append(
+			errors.New("errors prevented the operation from processing"),
+			opStats.readErr,
+			opStats.writeErr)
+	}
+
+	op.Results.BytesRead = opStats.bytesRead.NumBytes
+	op.Results.ItemsRead = opStats.itemsRead
+	op.Results.ItemsWritten = opStats.itemsWritten
+	op.Results.ResourceOwners = opStats.resourceCount
+
+	if opStats.itemsWritten == 0 {
+		op.Status = NoData
+	}
+
+	op.bus.Event(
+		ctx,
+		events.ExportEnd,
+		map[string]any{
+			events.BackupID:      op.BackupID,
+			events.DataRetrieved: op.Results.BytesRead,
+			events.Duration:      op.Results.CompletedAt.Sub(op.Results.StartedAt),
+			events.EndTime:       common.FormatTime(op.Results.CompletedAt),
+			events.ExportID:      opStats.exportID,
+			events.ItemsRead:     op.Results.ItemsRead,
+			events.ItemsWritten:  op.Results.ItemsWritten,
+			events.Resources:     op.Results.ResourceOwners,
+			events.Service:       op.Selectors.Service.String(),
+			events.StartTime:     common.FormatTime(op.Results.StartedAt),
+			events.Status:        op.Status.String(),
+		},
+	)
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package operations
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/data"
+	evmock "github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/export"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+type mockExportWriter struct {
+	written map[string][]byte
+	closed  bool
+}
+
+func (w *mockExportWriter) Write(_ context.Context, name string, r io.Reader) error {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	w.written[name] = bs
+
+	return nil
+}
+
+func (w *mockExportWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// unit
+// ---------------------------------------------------------------------------
+
+type ExportOpSuite struct {
+	tester.Suite
+}
+
+func TestExportOpSuite(t *testing.T) {
+	suite.Run(t, &ExportOpSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ExportOpSuite) TestNewExportOperation_requiresWriter() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	_, err := NewExportOperation(
+		ctx,
+		control.Options{},
+		&kopia.Wrapper{},
+		&store.Wrapper{},
+		account.Account{},
+		"foo",
+		selectors.Selector{DiscreteOwner: "test"},
+		nil,
+		evmock.NewBus())
+	assert.Error(t, err)
+}
+
+func (suite *ExportOpSuite) TestNewStreamOperation() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	newOp := func(fn export.ItemFunc) error {
+		_, err := NewStreamOperation(
+			ctx,
+			control.Options{},
+			&kopia.Wrapper{},
+			&store.Wrapper{},
+			account.Account{},
+			"foo",
+			selectors.Selector{DiscreteOwner: "test"},
+			fn,
+			evmock.NewBus())
+
+		return err
+	}
+
+	assert.Error(t, newOp(nil), "requires an item func")
+	assert.NoError(t, newOp(func(context.Context, details.DetailsEntry, io.Reader) error { return nil }))
+}
+
+func (suite *ExportOpSuite) TestExportOperation_PersistResults() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	now := time.Now()
+
+	table := []struct {
+		name         string
+		expectStatus opStatus
+		expectErr    assert.ErrorAssertionFunc
+		stats        exportStats
+	}{
+		{
+			name:         "completed",
+			expectStatus: Completed,
+			expectErr:    assert.NoError,
+			stats: exportStats{
+				resourceCount: 1,
+				itemsRead:     2,
+				itemsWritten:  2,
+				bytesRead:     &stats.ByteCounter{NumBytes: 42},
+			},
+		},
+		{
+			name:         "failed",
+			expectStatus: Failed,
+			expectErr:    assert.Error,
+			stats: exportStats{
+				readErr:   assert.AnError,
+				bytesRead: &stats.ByteCounter{},
+			},
+		},
+		{
+			name:         "no data",
+			expectStatus: NoData,
+			expectErr:    assert.NoError,
+			stats: exportStats{
+				bytesRead: &stats.ByteCounter{},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			op, err := NewExportOperation(
+				ctx,
+				control.Options{},
+				&kopia.Wrapper{},
+				&store.Wrapper{},
+				account.Account{},
+				"foo",
+				selectors.Selector{DiscreteOwner: "test"},
+				&mockExportWriter{},
+				evmock.NewBus())
+			require.NoError(t, err)
+			test.expectErr(t, op.persistResults(ctx, now, &test.stats))
+
+			assert.Equal(t, test.expectStatus.String(), op.Status.String(), "status")
+			assert.Equal(t, now, op.Results.StartedAt, "started at")
+
+			if test.stats.readErr != nil {
+				return
+			}
+
+			assert.Equal(t, test.stats.itemsRead, op.Results.ItemsRead, "items read")
+			assert.Equal(t, test.stats.itemsWritten, op.Results.ItemsWritten, "items written")
+			assert.Equal(t, test.stats.bytesRead.NumBytes, op.Results.BytesRead, "bytes read")
+			assert.Equal(t, test.stats.resourceCount, op.Results.ResourceOwners, "resource owners")
+		})
+	}
+}
+
+func (suite *ExportOpSuite) TestExportCollections() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	fp, err := path.Builder{}.
+		Append("inboxID").
+		ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, false)
+	require.NoError(t, err)
+
+	var (
+		coll    = mockconnector.NewMockExchangeCollection(fp, nil, 3)
+		entries = map[string]details.DetailsEntry{}
+		writer  = &mockExportWriter{written: map[string][]byte{}}
+		errs    = fault.New(false)
+	)
+
+	// the last item has no details entry, and fails to export.
+	for _, name := range coll.Names[:2] {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		entries[ip.String()] = details.DetailsEntry{
+			RepoRef:     ip.String(),
+			LocationRef: "Inbox",
+		}
+	}
+
+	serializer, err := export.SerializerFor(path.ExchangeService)
+	require.NoError(t, err)
+
+	read, written := exportCollections(
+		ctx,
+		[]data.RestoreCollection{data.NotFoundRestoreCollection{Collection: coll}},
+		entries,
+		writeTo(serializer, writer),
+		errs)
+
+	assert.Equal(t, 3, read, "items read")
+	assert.Equal(t, 2, written, "items written")
+	assert.NoError(t, errs.Err())
+	assert.Len(t, errs.Errs(), 1, "recoverable errors")
+
+	for i, name := range coll.Names[:2] {
+		assert.Equal(t, coll.Data[i], writer.written["email/Inbox/"+name+".json"])
+	}
+}
+
+func (suite *ExportOpSuite) TestExportCollections_stream() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	fp, err := path.Builder{}.
+		Append("inboxID").
+		ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, false)
+	require.NoError(t, err)
+
+	var (
+		coll     = mockconnector.NewMockExchangeCollection(fp, nil, 3)
+		entries  = map[string]details.DetailsEntry{}
+		streamed = map[string][]byte{}
+		errs     = fault.New(false)
+	)
+
+	for _, name := range coll.Names {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		entries[ip.String()] = details.DetailsEntry{RepoRef: ip.String()}
+	}
+
+	fn := func(ctx context.Context, ent details.DetailsEntry, r io.Reader) error {
+		// the caller's failures are recoverable.
+		if len(streamed) == 2 {
+			return assert.AnError
+		}
+
+		bs, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		streamed[ent.RepoRef] = bs
+
+		return nil
+	}
+
+	read, handled := exportCollections(
+		ctx,
+		[]data.RestoreCollection{data.NotFoundRestoreCollection{Collection: coll}},
+		entries,
+		streamTo(fn),
+		errs)
+
+	assert.Equal(t, 3, read, "items read")
+	assert.Equal(t, 2, handled, "items streamed")
+	assert.NoError(t, errs.Err())
+	require.Len(t, errs.Errs(), 1, "recoverable errors")
+	assert.ErrorIs(t, errs.Errs()[0], assert.AnError)
+
+	for i, name := range coll.Names[:2] {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, coll.Data[i], streamed[ip.String()])
+	}
+}
+
+func (suite *ExportOpSuite) TestStreamErr() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	fp, err := path.Builder{}.
+		Append("inboxID").
+		ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, false)
+	require.NoError(t, err)
+
+	var (
+		coll    = mockconnector.NewMockExchangeCollection(fp, nil, 2)
+		entries = map[string]details.DetailsEntry{}
+	)
+
+	for _, name := range coll.Names {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		entries[ip.String()] = details.DetailsEntry{RepoRef: ip.String()}
+	}
+
+	fn := func(context.Context, details.DetailsEntry, io.Reader) error {
+		return assert.AnError
+	}
+
+	op, err := NewStreamOperation(
+		ctx,
+		control.Options{},
+		&kopia.Wrapper{},
+		&store.Wrapper{},
+		account.Account{},
+		"foo",
+		selectors.Selector{DiscreteOwner: "test"},
+		fn,
+		evmock.NewBus())
+	require.NoError(t, err)
+	assert.NoError(t, op.StreamErr(), "nothing streamed yet")
+
+	_, handled := exportCollections(
+		ctx,
+		[]data.RestoreCollection{data.NotFoundRestoreCollection{Collection: coll}},
+		entries,
+		streamTo(fn),
+		op.Errors)
+
+	assert.Zero(t, handled, "items streamed")
+	assert.NoError(t, op.Errors.Err(), "callback failures are recoverable")
+
+	err = op.StreamErr()
+	require.Error(t, err, "every callback failed")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 2, clues.InErr(err)["failed_items"])
+}
+
+func (suite *ExportOpSuite) TestUniqueName() {
+	t := suite.T()
+	names := map[string]int{}
+
+	assert.Equal(t, "a/b.txt", uniqueName(names, "a/b.txt"))
+	assert.Equal(t, "a/b (1).txt", uniqueName(names, "a/b.txt"))
+	assert.Equal(t, "a/b (2).txt", uniqueName(names, "a/b.txt"))
+	assert.Equal(t, "a.d/b", uniqueName(names, "a.d/b"))
+	assert.Equal(t, "a.d/b (1)", uniqueName(names, "a.d/b"))
+	assert.Equal(t, "a/.hidden", uniqueName(names, "a/.hidden"))
+	assert.Equal(t, "a/.hidden (1)", uniqueName(names, "a/.hidden"))
+}
@@ -126,11 +126,57 @@ func produceManifestsAndMetadata(
 		}
 
 		collections = append(collections, colls...)
+
+		collections = append(collections, collectOptionalMetadata(mctx, mr, man, tenantID)...)
 	}
 
 	return ms, collections, true, err
 }
 
+// collectOptionalMetadata retrieves any metadata files that the manifest
+// isn't guaranteed to contain.  Missing files are expected, so they aren't
+// reported as errors.
+func collectOptionalMetadata(
+	ctx context.Context,
+	r restorer,
+	man *kopia.ManifestEntry,
+	tenantID string,
+) []data.RestoreCollection {
+	paths := []path.Path{}
+
+	for _, reason := range man.Reasons {
+		for _, fn := range graph.OptionalMetadataFileNames(reason.Service, reason.Category) {
+			p, err := path.Builder{}.
+				Append(fn).
+				ToServiceCategoryMetadataPath(
+					tenantID,
+					reason.ResourceOwner,
+					reason.Service,
+					reason.Category,
+					true)
+			if err != nil {
+				logger.Ctx(ctx).With("err", err).Errorw("building optional metadata path", clues.InErr(err).Slice()...)
+				continue
+			}
+
+			paths = append(paths, p)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// a separate, non-failing error bus keeps absent files out of
+	// the backup's recoverable errors.
+	dcs, err := r.RestoreMultipleItems(ctx, string(man.ID), paths, nil, fault.New(false))
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Infow("collecting optional metadata", clues.InErr(err).Slice()...)
+	}
+
+	return dcs
+}
+
 // verifyDistinctBases is a validation checker that ensures, for a given slice
 // of manifests, that each manifest's Reason (owner, service, category) is only
 // included once.  If a reason is duplicated by any two manifests, an error is
@@ -222,3 +268,32 @@ func collectMetadata(
 
 	return dcs, nil
 }
+
+// baseBackupIDs returns the IDs of the backups that produced the complete
+// manifests in mans, in manifest order.
+func baseBackupIDs(mans []*kopia.ManifestEntry) []model.StableID {
+	var (
+		ids  []model.StableID
+		seen = map[string]struct{}{}
+	)
+
+	for _, man := range mans {
+		if len(man.IncompleteReason) > 0 {
+			continue
+		}
+
+		bID, ok := man.GetTag(kopia.TagBackupID)
+		if !ok {
+			continue
+		}
+
+		if _, ok := seen[bID]; ok {
+			continue
+		}
+
+		seen[bID] = struct{}{}
+		ids = append(ids, model.StableID(bID))
+	}
+
+	return ids
+}
@@ -397,6 +397,21 @@ func (suite *OperationsManifestsUnitSuite) TestVerifyDistinctBases() {
 	}
 }
 
+func (suite *OperationsManifestsUnitSuite) TestBaseBackupIDs() {
+	t := suite.T()
+
+	mans := []*kopia.ManifestEntry{
+		{Manifest: makeManifest(t, "b1", "")},
+		{Manifest: makeManifest(t, "incomplete", "checkpoint")},
+		{Manifest: makeManifest(t, "b2", "")},
+		{Manifest: makeManifest(t, "b1", "")},
+		{Manifest: &snapshot.Manifest{}},
+	}
+
+	assert.Equal(t, []model.StableID{"b1", "b2"}, baseBackupIDs(mans))
+	assert.Empty(t, baseBackupIDs(nil))
+}
+
 func (suite *OperationsManifestsUnitSuite) TestProduceManifestsAndMetadata() {
 	const (
 		ro  = "resourceowner"
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/alcionai/corso/src/internal/connector"
@@ -53,6 +54,8 @@ const (
 // Specific processes (eg: backups, restores, etc) are expected to wrap operation
 // with process specific details.
 type operation struct {
+	// ID uniquely identifies the run of the operation in its logs and errors.
+	ID        string    `json:"id"`
 	CreatedAt time.Time `json:"createdAt"`
 
 	Errors  *fault.Errors   `json:"errors"`
@@ -71,6 +74,7 @@ func newOperation(
 	sw *store.Wrapper,
 ) operation {
 	return operation{
+		ID:        uuid.NewString(),
 		CreatedAt: time.Now(),
 		Errors:    fault.New(opts.FailFast),
 		Options:   opts,
@@ -115,7 +119,7 @@ func connectToM365(
 		resource = connector.Sites
 	}
 
-	gc, err := connector.NewGraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, resource, errs)
+	gc, err := connector.SharedPool().GraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, resource, errs)
 	if err != nil {
 		return nil, err
 	}
@@ -25,6 +25,8 @@ func (suite *OperationSuite) TestNewOperation() {
 	t := suite.T()
 	op := newOperation(control.Options{}, events.Bus{}, nil, nil)
 	assert.Greater(t, op.CreatedAt, time.Time{})
+	assert.NotEmpty(t, op.ID)
+	assert.NotEqual(t, op.ID, newOperation(control.Options{}, events.Bus{}, nil, nil).ID)
 }
 
 func (suite *OperationSuite) TestOperation_Validate() {
@@ -0,0 +1,137 @@
+package operations
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/dustin/go-humanize"
+
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// ErrQuotaExceeded is returned when a backup is prevented from starting
+// because the repository has reached its hard size limit.
+var ErrQuotaExceeded = clues.New("repository size quota exceeded")
+
+// quota limits, as reported in backup results and warning events.
+const (
+	QuotaSoftLimit = "soft"
+	QuotaHardLimit = "hard"
+)
+
+type repoSizer interface {
+	RepositorySize(ctx context.Context) (int64, error)
+}
+
+// checkQuota retrieves the repository's quota ahead of a backup.  The
+// cached repository size is measured again once it expires, so that
+// backups don't list every object in the repository's storage.  If the
+// repository has reached the hard limit, and the quota is configured to
+// fail at the hard limit, ErrQuotaExceeded is returned.
+func checkQuota(
+	ctx context.Context,
+	rs repoSizer,
+	sw *store.Wrapper,
+) (*control.RepoQuota, error) {
+	q, err := sw.GetRepoQuota(ctx)
+	if err != nil {
+		return nil, clues.Wrap(err, "retrieving repository quota")
+	}
+
+	if !q.Limits.Enabled() {
+		return q, nil
+	}
+
+	if now := time.Now(); q.SizeExpired(now) {
+		size, err := rs.RepositorySize(ctx)
+		if err != nil {
+			return nil, clues.Wrap(err, "measuring repository size")
+		}
+
+		q.Size = size
+		q.MeasuredAt = now
+
+		if err := sw.SaveRepoQuota(ctx, q); err != nil {
+			return nil, clues.Wrap(err, "caching repository size")
+		}
+	}
+
+	qo := q.Limits
+
+	if qo.FailAtHardLimit && qo.HardLimitBytes > 0 && q.Size >= qo.HardLimitBytes {
+		return q, clues.Stack(ErrQuotaExceeded).
+			WithClues(ctx).
+			With("repo_size", q.Size, "hard_limit_bytes", qo.HardLimitBytes)
+	}
+
+	return q, nil
+}
+
+// recordUpload adds the bytes uploaded by a backup to the cached size of
+// the repository, and returns the new size.  The quota is read again first,
+// so that backups running alongside this one aren't overwritten.  Failing
+// to save the size only leaves it stale until its next measurement.
+func recordUpload(
+	ctx context.Context,
+	sw *store.Wrapper,
+	q *control.RepoQuota,
+	uploaded int64,
+) int64 {
+	if latest, err := sw.GetRepoQuota(ctx); err == nil && latest.Limits.Enabled() {
+		q = latest
+	}
+
+	q.Size += uploaded
+
+	if err := sw.SaveRepoQuota(ctx, q); err != nil {
+		logger.Ctx(ctx).
+			With("err", err).
+			Infow("caching repository size", clues.InErr(err).Slice()...)
+	}
+
+	return q.Size
+}
+
+// quotaAlert compares the projected size of the repository against the
+// quota, and returns the most severe limit that was reached, if any.
+// A warning event is emitted for the reached limit.
+func quotaAlert(
+	ctx context.Context,
+	bus events.Eventer,
+	qo control.QuotaOptions,
+	projected int64,
+) string {
+	var (
+		alert      string
+		limitBytes int64
+	)
+
+	switch {
+	case qo.HardLimitBytes > 0 && projected >= qo.HardLimitBytes:
+		alert, limitBytes = QuotaHardLimit, qo.HardLimitBytes
+	case qo.SoftLimitBytes > 0 && projected >= qo.SoftLimitBytes:
+		alert, limitBytes = QuotaSoftLimit, qo.SoftLimitBytes
+	default:
+		return ""
+	}
+
+	logger.Ctx(ctx).Infow(
+		"repository size exceeds the "+alert+" quota limit",
+		"repo_size", humanize.IBytes(uint64(projected)),
+		"quota_limit", humanize.IBytes(uint64(limitBytes)))
+
+	bus.Event(
+		ctx,
+		events.QuotaWarning,
+		map[string]any{
+			events.QuotaLimit:      alert,
+			events.QuotaLimitBytes: limitBytes,
+			events.RepoSize:        projected,
+		})
+
+	return alert
+}
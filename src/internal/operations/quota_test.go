@@ -0,0 +1,214 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/events"
+	evmock "github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/store"
+	storeMock "github.com/alcionai/corso/src/pkg/store/mock"
+)
+
+type mockSizer struct {
+	size  int64
+	err   error
+	calls int
+}
+
+func (ms *mockSizer) RepositorySize(context.Context) (int64, error) {
+	ms.calls++
+	return ms.size, ms.err
+}
+
+type QuotaUnitSuite struct {
+	tester.Suite
+}
+
+func TestQuotaUnitSuite(t *testing.T) {
+	suite.Run(t, &QuotaUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *QuotaUnitSuite) TestCheckQuota() {
+	table := []struct {
+		name       string
+		sizer      *mockSizer
+		opts       control.QuotaOptions
+		expectSize int64
+		expectErr  assert.ErrorAssertionFunc
+		expectCall int
+	}{
+		{
+			name:       "no quota",
+			sizer:      &mockSizer{size: 100},
+			expectErr:  assert.NoError,
+			expectCall: 0,
+		},
+		{
+			name:       "under the hard limit",
+			sizer:      &mockSizer{size: 100},
+			opts:       control.QuotaOptions{HardLimitBytes: 200, FailAtHardLimit: true},
+			expectSize: 100,
+			expectErr:  assert.NoError,
+			expectCall: 1,
+		},
+		{
+			name:       "at the hard limit",
+			sizer:      &mockSizer{size: 200},
+			opts:       control.QuotaOptions{HardLimitBytes: 200, FailAtHardLimit: true},
+			expectSize: 200,
+			expectErr:  assert.Error,
+			expectCall: 1,
+		},
+		{
+			name:       "at the hard limit without failing",
+			sizer:      &mockSizer{size: 200},
+			opts:       control.QuotaOptions{HardLimitBytes: 200},
+			expectSize: 200,
+			expectErr:  assert.NoError,
+			expectCall: 1,
+		},
+		{
+			name:       "over the soft limit",
+			sizer:      &mockSizer{size: 200},
+			opts:       control.QuotaOptions{SoftLimitBytes: 100, FailAtHardLimit: true},
+			expectSize: 200,
+			expectErr:  assert.NoError,
+			expectCall: 1,
+		},
+		{
+			name:       "sizing fails",
+			sizer:      &mockSizer{err: errors.New("fnords")},
+			opts:       control.QuotaOptions{SoftLimitBytes: 100},
+			expectErr:  assert.Error,
+			expectCall: 1,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t   = suite.T()
+				mms = storeMock.NewMock(nil, nil)
+				sw  = &store.Wrapper{Storer: mms}
+			)
+
+			if test.opts.Enabled() {
+				require.NoError(t, sw.SetRepoQuota(ctx, test.opts))
+			}
+
+			q, err := checkQuota(ctx, test.sizer, sw)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expectCall, test.sizer.calls)
+
+			if q != nil {
+				assert.Equal(t, test.expectSize, q.Size)
+			}
+
+			if test.expectCall > 0 && test.sizer.err == nil {
+				require.NotNil(t, mms.RepoQuota())
+				assert.Equal(t, test.expectSize, mms.RepoQuota().Size, "size is cached")
+				assert.False(t, mms.RepoQuota().MeasuredAt.IsZero())
+			}
+		})
+	}
+}
+
+func (suite *QuotaUnitSuite) TestCheckQuota_cachedSize() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		sizer = &mockSizer{size: 100}
+		sw    = &store.Wrapper{Storer: storeMock.NewMock(nil, nil)}
+	)
+
+	require.NoError(t, sw.SetRepoQuota(ctx, control.QuotaOptions{SoftLimitBytes: 1000}))
+
+	_, err := checkQuota(ctx, sizer, sw)
+	require.NoError(t, err)
+
+	q, err := checkQuota(ctx, sizer, sw)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sizer.calls, "the cached size is reused")
+	assert.Equal(t, int64(100), q.Size)
+
+	assert.Equal(t, int64(150), recordUpload(ctx, sw, q, 50))
+
+	q, err = checkQuota(ctx, sizer, sw)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sizer.calls, "the cached size is reused")
+	assert.Equal(t, int64(150), q.Size, "uploads are added to the cached size")
+
+	// expire the cached size
+	q.MeasuredAt = time.Now().Add(-2 * control.RepoSizeTTL)
+	require.NoError(t, sw.SaveRepoQuota(ctx, q))
+
+	q, err = checkQuota(ctx, sizer, sw)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sizer.calls, "expired sizes are measured again")
+	assert.Equal(t, int64(100), q.Size)
+}
+
+func (suite *QuotaUnitSuite) TestCheckQuota_exceeded() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	sw := &store.Wrapper{Storer: storeMock.NewMock(nil, nil)}
+
+	require.NoError(t, sw.SetRepoQuota(ctx, control.QuotaOptions{HardLimitBytes: 1, FailAtHardLimit: true}))
+
+	_, err := checkQuota(ctx, &mockSizer{size: 1}, sw)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func (suite *QuotaUnitSuite) TestQuotaAlert() {
+	opts := control.QuotaOptions{SoftLimitBytes: 100, HardLimitBytes: 200}
+
+	table := []struct {
+		name      string
+		opts      control.QuotaOptions
+		projected int64
+		expect    string
+	}{
+		{"under the limits", opts, 99, ""},
+		{"at the soft limit", opts, 100, QuotaSoftLimit},
+		{"over the hard limit", opts, 300, QuotaHardLimit},
+		{"hard limit only", control.QuotaOptions{HardLimitBytes: 200}, 150, ""},
+		{"soft limit only", control.QuotaOptions{SoftLimitBytes: 100}, 300, QuotaSoftLimit},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				mb = evmock.NewBus()
+			)
+
+			assert.Equal(t, test.expect, quotaAlert(ctx, mb, test.opts, test.projected))
+
+			if len(test.expect) == 0 {
+				assert.Zero(t, mb.TimesCalled[events.QuotaWarning])
+				return
+			}
+
+			assert.Equal(t, 1, mb.TimesCalled[events.QuotaWarning])
+			assert.Equal(t, test.expect, mb.CalledWith[events.QuotaWarning][0][events.QuotaLimit])
+			assert.Equal(t, test.projected, mb.CalledWith[events.QuotaWarning][0][events.RepoSize])
+		})
+	}
+}
@@ -7,12 +7,13 @@ import (
 	"time"
 
 	"github.com/alcionai/clues"
-	"github.com/google/uuid"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
+	"github.com/alcionai/corso/src/internal/bandwidth"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/common/crash"
+	"github.com/alcionai/corso/src/internal/connector/exchange"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/data"
 	D "github.com/alcionai/corso/src/internal/diagnostics"
@@ -20,6 +21,7 @@ import (
 	"github.com/alcionai/corso/src/internal/kopia"
 	"github.com/alcionai/corso/src/internal/model"
 	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
 	"github.com/alcionai/corso/src/internal/stats"
 	"github.com/alcionai/corso/src/internal/streamstore"
 	"github.com/alcionai/corso/src/pkg/account"
@@ -30,6 +32,7 @@ import (
 	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
 	"github.com/alcionai/corso/src/pkg/store"
+	"github.com/alcionai/corso/src/pkg/transform"
 )
 
 // RestoreOperation wraps an operation with restore-specific props.
@@ -41,6 +44,19 @@ type RestoreOperation struct {
 	Selectors   selectors.Selector         `json:"selectors"`
 	Destination control.RestoreDestination `json:"destination"`
 	Version     string                     `json:"version"`
+	// RestoreID identifies the restore's progress checkpoint.  Callers
+	// may set it before running the operation to resume a previous
+	// restore, skipping any items that restore already completed.
+	RestoreID model.StableID `json:"restoreID,omitempty"`
+	// IdempotencyKey, if set by the caller, identifies retries of the same
+	// restore.  Running the operation with the key of a completed restore
+	// restores nothing and reports the results of that restore, while the
+	// key of a failed restore resumes it.  Fails with ErrRestoreInProgress
+	// if a restore with the key is still running.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// Replayed is true if a previous restore with the same idempotency key
+	// already completed, and nothing was restored by this operation.
+	Replayed bool `json:"replayed,omitempty"`
 
 	account account.Account
 }
@@ -50,6 +66,9 @@ type RestoreResults struct {
 	stats.Errs // deprecated in place of fault.Errors in the base operation.
 	stats.ReadWrites
 	stats.StartAndEndTime
+	// QuotaWarning describes why the restore may not fit within the
+	// destination, such as a mailbox quota.  Empty if no issue was found.
+	QuotaWarning string `json:"quotaWarning,omitempty"`
 }
 
 // NewRestoreOperation constructs and validates a restore operation.
@@ -119,7 +138,6 @@ func (op *RestoreOperation) Run(ctx context.Context) (restoreDetails *details.De
 	var (
 		opStats = restoreStats{
 			bytesRead: &stats.ByteCounter{},
-			restoreID: uuid.NewString(),
 		}
 		start        = time.Now()
 		detailsStore = streamstore.New(op.kopia, op.account.ID(), op.Selectors.PathService())
@@ -136,17 +154,60 @@ func (op *RestoreOperation) Run(ctx context.Context) (restoreDetails *details.De
 		observe.Complete()
 	}()
 
-	ctx = clues.Add(
-		ctx,
-		"tenant_id", op.account.ID(), // TODO: pii
-		"backup_id", op.BackupID,
-		"service", op.Selectors.Service)
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		OperationID: op.ID,
+		Kind:        opctx.Restore,
+		TenantID:    op.account.ID(),
+		BackupID:    string(op.BackupID),
+		Service:     op.Selectors.Service.String(),
+	})
+
+	progress, err := op.getProgress(ctx)
+	if err != nil {
+		op.Errors.Fail(errors.Wrap(err, "resuming restore"))
+		op.reportFailure(ctx, start)
+
+		return nil, op.Errors.Err()
+	}
+
+	op.RestoreID = progress.ID
+	op.Destination = progress.Destination
+	opStats.restoreID = string(progress.ID)
+
+	ctx = clues.Add(ctx, "restore_id", op.RestoreID)
+
+	if err := purgeExpiredProgress(ctx, op.store, progress.ID, time.Now()); err != nil {
+		logger.Ctx(ctx).
+			With("err", err).
+			Infow("deleting expired restore progress", clues.InErr(err).Slice()...)
+	}
+
+	if progress.Status == Completed {
+		logger.Ctx(ctx).Info("restore with the idempotency key already completed")
+
+		op.Replayed = true
+		op.Status = Completed
+		op.Results.ReadWrites = progress.Results
+
+		return &details.Details{}, nil
+	}
+
+	if err := permitDestination(ctx, op.store, op.Destination, op.inPlace()); err != nil {
+		op.Errors.Fail(errors.Wrap(err, "checking restore destination"))
+		op.reportFailure(ctx, start)
+
+		return nil, op.Errors.Err()
+	}
+
+	stopRenewal := renewLease(ctx, op.store, progress)
 
 	// -----
 	// Execution
 	// -----
 
-	deets, err := op.do(ctx, &opStats, detailsStore, start)
+	deets, err := op.do(ctx, &opStats, progress, detailsStore, start)
+	stopRenewal()
+
 	if err != nil {
 		// No return here!  We continue down to persistResults, even in case of failure.
 		logger.Ctx(ctx).
@@ -157,7 +218,7 @@ func (op *RestoreOperation) Run(ctx context.Context) (restoreDetails *details.De
 	}
 
 	// TODO: the consumer (sdk or cli) should run this, not operations.
-	recoverableCount := len(op.Errors.Errs())
+	recoverableCount := op.Errors.Count()
 	for i, err := range op.Errors.Errs() {
 		logger.Ctx(ctx).
 			With("error", err).
@@ -169,11 +230,32 @@ func (op *RestoreOperation) Run(ctx context.Context) (restoreDetails *details.De
 	// Persistence
 	// -----
 
-	err = op.persistResults(ctx, start, &opStats)
-	if err != nil {
-		op.Errors.Fail(errors.Wrap(err, "persisting restore results"))
+	resultsErr := op.persistResults(ctx, start, &opStats)
+	finished := resultsErr == nil && op.Errors.Err() == nil && len(op.Errors.Errs()) == 0
+
+	// keyed restores report their results to retries.
+	progress.Results = op.Results.ReadWrites
+
+	if err := persistProgress(ctx, op.store, progress, finished); err != nil {
+		// the restore results take priority over the checkpoint.
+		logger.Ctx(ctx).
+			With("err", err).
+			Errorw("persisting restore progress", clues.InErr(err).Slice()...)
+
+		op.RestoreID = ""
+	} else if !finished && len(progress.ModelStoreID) == 0 {
+		// nothing was restored, so there's nothing to resume.
+		op.RestoreID = ""
+	}
+
+	if resultsErr != nil {
+		op.Errors.Fail(errors.Wrap(resultsErr, "persisting restore results"))
 		opStats.writeErr = op.Errors.Err()
+	}
+
+	op.reportRestore(ctx, deets)
 
+	if resultsErr != nil {
 		return nil, op.Errors.Err()
 	}
 
@@ -182,9 +264,101 @@ func (op *RestoreOperation) Run(ctx context.Context) (restoreDetails *details.De
 	return deets, nil
 }
 
+// getProgress produces the progress of the restore, claiming its
+// idempotency key if one was provided.
+func (op *RestoreOperation) getProgress(ctx context.Context) (*RestoreProgress, error) {
+	if len(op.IdempotencyKey) == 0 {
+		return getRestoreProgress(ctx, op.store, op.RestoreID, op.BackupID, op.Destination)
+	}
+
+	if len(op.RestoreID) > 0 {
+		return nil, clues.New("a restore can't be resumed by both its ID and an idempotency key")
+	}
+
+	return claimRestore(ctx, op.store, op.IdempotencyKey, op.BackupID, op.Destination)
+}
+
+// Validate checks that the restore is able to run, without restoring
+// anything: the backup and its details must exist, the selectors must
+// match at least one item in the backup, and M365 must be reachable.
+// Lets a deferred restore fail when it's queued instead of when it runs.
+func (op *RestoreOperation) Validate(ctx context.Context) error {
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		OperationID: op.ID,
+		Kind:        opctx.Restore,
+		BackupID:    string(op.BackupID),
+		Service:     op.Selectors.Service.String(),
+	})
+
+	var (
+		// validation shouldn't record errors on the operation itself,
+		// which may still run.
+		errs         = fault.New(op.Options.FailFast)
+		detailsStore = streamstore.New(op.kopia, op.account.ID(), op.Selectors.PathService())
+	)
+
+	if err := permitDestination(ctx, op.store, op.Destination, op.inPlace()); err != nil {
+		return errors.Wrap(err, "checking restore destination")
+	}
+
+	_, deets, err := getBackupAndDetailsFromID(ctx, op.BackupID, op.store, detailsStore, errs)
+	if err != nil {
+		return errors.Wrap(err, "getting backup and details")
+	}
+
+	paths, err := formatDetailsForRestoration(ctx, op.Selectors, deets, errs)
+	if err != nil {
+		return errors.Wrap(err, "formatting paths from details")
+	}
+
+	if len(paths) == 0 {
+		return clues.New("no items in the backup match the restore selection").WithClues(ctx)
+	}
+
+	if _, err := connectToM365(ctx, op.Selectors, op.account, errs); err != nil {
+		return errors.Wrap(err, "connecting to M365")
+	}
+
+	return nil
+}
+
+// permitDestination checks the destination against the repository's
+// restore policy, so that restores can't write outside of the containers
+// approved by the repository's administrators.  Restores that write to the
+// original items must be allowed by the policy instead.
+func permitDestination(
+	ctx context.Context,
+	sw *store.Wrapper,
+	dest control.RestoreDestination,
+	inPlace bool,
+) error {
+	policy, err := sw.GetRestorePolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	permits := policy.Permits(dest)
+	if inPlace {
+		permits = policy.PermitsInPlace()
+	}
+
+	if permits != nil {
+		return clues.Stack(permits).WithClues(ctx)
+	}
+
+	return nil
+}
+
+// inPlace returns true if the restore writes to the original items rather
+// than to the restore destination.
+func (op *RestoreOperation) inPlace() bool {
+	return op.Options.EffectivePermissions().OneDrive.RestoreOnly
+}
+
 func (op *RestoreOperation) do(
 	ctx context.Context,
 	opStats *restoreStats,
+	progress *RestoreProgress,
 	detailsStore detailsReader,
 	start time.Time,
 ) (*details.Details, error) {
@@ -203,10 +377,15 @@ func (op *RestoreOperation) do(
 		return nil, errors.Wrap(err, "formatting paths from details")
 	}
 
-	ctx = clues.Add(
-		ctx,
-		"resource_owner", bup.Selector.DiscreteOwner,
-		"details_paths", len(paths))
+	total := len(paths)
+	paths = progress.skip(paths)
+
+	if skipped := total - len(paths); skipped > 0 {
+		logger.Ctx(ctx).Infof("skipping %d items completed by a previous run of the restore", skipped)
+	}
+
+	ctx = opctx.Set(ctx, opctx.Metadata{ResourceOwner: bup.Selector.DiscreteOwner})
+	ctx = clues.Add(ctx, "details_paths", len(paths))
 
 	op.bus.Event(
 		ctx,
@@ -221,6 +400,25 @@ func (op *RestoreOperation) do(
 	observe.Message(ctx, observe.Safe(fmt.Sprintf("Discovered %d items in backup %s to restore", len(paths), op.BackupID)))
 	logger.Ctx(ctx).With("selectors", op.Selectors).Info("restoring selection")
 
+	if len(paths) == 0 {
+		// everything was restored by a previous run.
+		opStats.resourceCount = 1
+		opStats.gc = &support.ConnectorOperationStatus{}
+
+		return &details.Details{}, nil
+	}
+
+	approval := control.RestoreApproval{
+		RestoreID:   progress.ID,
+		BackupID:    op.BackupID,
+		Destination: op.Destination.ContainerName,
+		InPlace:     op.inPlace(),
+	}
+
+	if err := awaitApproval(ctx, op.store, op.bus, op.Options.Approval, approval, paths, deets); err != nil {
+		return nil, errors.Wrap(err, "awaiting restore approval")
+	}
+
 	kopiaComplete, closer := observe.MessageWithCompletion(ctx, observe.Safe("Enumerating items in repository"))
 	defer closer()
 	defer close(kopiaComplete)
@@ -230,6 +428,10 @@ func (op *RestoreOperation) do(
 		return nil, errors.Wrap(err, "retrieving collections from repository")
 	}
 
+	if op.Options.RestoreMailboxSettings && op.Selectors.Service == selectors.ServiceExchange {
+		dcs = append(dcs, op.mailboxSettingsCollections(ctx, bup.SnapshotID, paths[0], opStats)...)
+	}
+
 	kopiaComplete <- struct{}{}
 
 	ctx = clues.Add(ctx, "coll_count", len(dcs))
@@ -243,6 +445,31 @@ func (op *RestoreOperation) do(
 		return nil, errors.Wrap(err, "connecting to M365")
 	}
 
+	warning, err := gc.RestorePreflight(
+		ctx,
+		op.account,
+		op.Selectors,
+		bup.Selector.DiscreteOwner,
+		op.Options,
+		pathEntries(paths, deets))
+	if err != nil {
+		return nil, errors.Wrap(err, "checking restore destination")
+	}
+
+	if len(warning) > 0 {
+		op.Results.QuotaWarning = warning
+		observe.Message(ctx, observe.Safe("Warning: "+warning))
+	}
+
+	// connectors apply the destination's transform to each item.
+	ctx = transform.Set(ctx, op.Destination.Transform)
+
+	// checkpoints the restored items while the restore runs.
+	ctx = details.SetObserver(ctx, progress.observer(ctx, op.store, restoreCheckpointInterval))
+
+	// restores draw on their own bandwidth budget, apart from backups.
+	ctx = bandwidth.Set(ctx, bandwidth.New(op.Options.Bandwidth, bandwidth.Restore))
+
 	restoreComplete, closer := observe.MessageWithCompletion(ctx, observe.Safe("Restoring data"))
 	defer closer()
 	defer close(restoreComplete)
@@ -256,6 +483,11 @@ func (op *RestoreOperation) do(
 		op.Options,
 		dcs,
 		op.Errors)
+
+	// record completed items before handling errors, so that a
+	// partial restore can be resumed.
+	progress.record(restoreDetails)
+
 	if err != nil {
 		return nil, errors.Wrap(err, "restoring collections")
 	}
@@ -273,6 +505,52 @@ func (op *RestoreOperation) do(
 	return restoreDetails, nil
 }
 
+// pathEntries produces the details entries of the paths.
+func pathEntries(paths []path.Path, deets *details.Details) []details.DetailsEntry {
+	refs := make(map[string]struct{}, len(paths))
+
+	for _, p := range paths {
+		refs[p.ShortRef()] = struct{}{}
+	}
+
+	ents := make([]details.DetailsEntry, 0, len(paths))
+
+	for _, ent := range deets.Entries {
+		if _, ok := refs[ent.ShortRef]; ok {
+			ents = append(ents, ent)
+		}
+	}
+
+	return ents
+}
+
+// mailboxSettingsCollections retrieves the mailbox settings of the resource
+// owner that holds the restored item.  The settings aren't in the backup
+// details, so they're retrieved by their path.  Backups made before mailbox
+// settings were supported don't hold any, and produce no collections.
+func (op *RestoreOperation) mailboxSettingsCollections(
+	ctx context.Context,
+	snapshotID string,
+	item path.Path,
+	opStats *restoreStats,
+) []data.RestoreCollection {
+	p, err := exchange.MailboxSettingsPath(item.Tenant(), item.ResourceOwner())
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("making mailbox settings path", clues.InErr(err).Slice()...)
+		return nil
+	}
+
+	// a missing item is expected for older backups, so retrieval
+	// errors stay out of the operation's errors.
+	dcs, err := op.kopia.RestoreMultipleItems(ctx, snapshotID, []path.Path{p}, opStats.bytesRead, fault.New(false))
+	if err != nil || len(dcs) == 0 {
+		logger.Ctx(ctx).Info("backup holds no mailbox settings")
+		return nil
+	}
+
+	return dcs
+}
+
 // persists details and statistics about the restore operation.
 func (op *RestoreOperation) persistResults(
 	ctx context.Context,
@@ -0,0 +1,245 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// ErrApprovalPending is produced when a restore that requires approval
+// wasn't approved before its approval timeout.  The restore can be resumed
+// once it's approved.
+var ErrApprovalPending = errors.New("the restore is awaiting approval")
+
+// approvalPollInterval is how often a waiting restore checks whether it
+// has been approved.
+var approvalPollInterval = 15 * time.Second
+
+const webhookTimeout = 30 * time.Second
+
+// webhookClient posts approval requests.  The client's own timeout bounds
+// every request, even when the caller's context has no deadline.
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// approvalWebhookPayload is the body of each approval request posted to
+// the approval webhook.
+type approvalWebhookPayload struct {
+	Event          string         `json:"event"`
+	RestoreID      model.StableID `json:"restoreID"`
+	BackupID       model.StableID `json:"backupID"`
+	Destination    string         `json:"destination"`
+	InPlace        bool           `json:"inPlace,omitempty"`
+	Items          int            `json:"items"`
+	Bytes          int64          `json:"bytes"`
+	RequestedAt    time.Time      `json:"requestedAt"`
+	RequestedBy    string         `json:"requestedBy"`
+	ApproveCommand string         `json:"approveCommand"`
+}
+
+// awaitApproval holds the restore until it's approved, if restoring the
+// paths exceeds the approval thresholds of the repository's restore
+// policy, or the restore writes to the original items.  The first time a
+// restore needs approval, an approval request is stored, and announced
+// through an event and the policy's approval webhook.  Resumed restores
+// that were already approved proceed without waiting.
+func awaitApproval(
+	ctx context.Context,
+	sw *store.Wrapper,
+	bus events.Eventer,
+	ao control.ApprovalOptions,
+	ra control.RestoreApproval,
+	paths []path.Path,
+	deets *details.Details,
+) error {
+	policy, err := sw.GetRestorePolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	ap := policy.Approval
+
+	if !ap.Enabled() {
+		return nil
+	}
+
+	ra.Items = len(paths)
+	ra.Bytes = pathBytes(paths, deets)
+
+	// restores into production items always need approval.
+	if !ra.InPlace && !ap.Requires(ra.Items, ra.Bytes) {
+		return nil
+	}
+
+	ctx = clues.Add(ctx, "approval_items", ra.Items, "approval_bytes", ra.Bytes)
+
+	prev, err := sw.GetRestoreApproval(ctx, ra.RestoreID)
+	if err != nil {
+		return err
+	}
+
+	if prev != nil && prev.Approved {
+		logger.Ctx(ctx).Infow("restore was approved", "approved_at", prev.ApprovedAt)
+		return nil
+	}
+
+	if prev == nil {
+		ra.ID = model.StableID(uuid.NewString())
+		ra.RequestedBy = common.Identity()
+
+		if err := sw.RequestRestoreApproval(ctx, &ra); err != nil {
+			return err
+		}
+
+		announceApproval(ctx, bus, ap.Webhook, ra)
+	}
+
+	observe.Message(ctx, observe.Safe(fmt.Sprintf(
+		"Restoring %d items requires approval.  Waiting for 'corso restore approve %s'.",
+		ra.Items,
+		ra.RestoreID)))
+
+	timeout := ao.Timeout
+	if timeout <= 0 {
+		timeout = control.DefaultApprovalTimeout
+	}
+
+	return waitForApproval(ctx, sw, ra.RestoreID, timeout)
+}
+
+// waitForApproval polls the restore's approval request until it's
+// approved, or the timeout passes.
+func waitForApproval(
+	ctx context.Context,
+	sw *store.Wrapper,
+	restoreID model.StableID,
+	timeout time.Duration,
+) error {
+	ticker := time.NewTicker(approvalPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return clues.Stack(ctx.Err()).WithClues(ctx)
+
+		case <-deadline.C:
+			return clues.Stack(ErrApprovalPending).WithClues(ctx).With("restore_id", restoreID)
+
+		case <-ticker.C:
+			// approvals are written by other processes.
+			if err := sw.Refresh(ctx); err != nil {
+				return err
+			}
+
+			ra, err := sw.GetRestoreApproval(ctx, restoreID)
+			if err != nil {
+				return err
+			}
+
+			if ra != nil && ra.Approved {
+				logger.Ctx(ctx).Infow("restore was approved", "approved_at", ra.ApprovedAt)
+				return nil
+			}
+		}
+	}
+}
+
+// announceApproval emits the approval request event, and posts the request
+// to the webhook, if one is configured.  Failing to reach the webhook
+// doesn't fail the restore; the request can still be approved.
+func announceApproval(
+	ctx context.Context,
+	bus events.Eventer,
+	webhook string,
+	ra control.RestoreApproval,
+) {
+	bus.Event(
+		ctx,
+		events.RestoreApprovalRequest,
+		map[string]any{
+			events.RestoreID:     ra.RestoreID,
+			events.BackupID:      ra.BackupID,
+			events.ApprovalItems: ra.Items,
+			events.ApprovalBytes: ra.Bytes,
+		})
+
+	if len(webhook) == 0 {
+		return
+	}
+
+	payload := approvalWebhookPayload{
+		Event:          events.RestoreApprovalRequest,
+		RestoreID:      ra.RestoreID,
+		BackupID:       ra.BackupID,
+		Destination:    ra.Destination,
+		InPlace:        ra.InPlace,
+		Items:          ra.Items,
+		Bytes:          ra.Bytes,
+		RequestedAt:    ra.RequestedAt,
+		RequestedBy:    ra.RequestedBy,
+		ApproveCommand: "corso restore approve " + string(ra.RestoreID),
+	}
+
+	if err := postWebhook(ctx, webhook, payload); err != nil {
+		logger.Ctx(ctx).
+			With("err", err).
+			Errorw("posting restore approval request", clues.InErr(err).Slice()...)
+	}
+}
+
+func postWebhook(ctx context.Context, url string, payload any) error {
+	bs, err := json.Marshal(payload)
+	if err != nil {
+		return clues.Wrap(err, "serializing webhook payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bs))
+	if err != nil {
+		return clues.Wrap(err, "building webhook request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return clues.Wrap(err, "sending webhook request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return clues.New("webhook rejected the request").With("status_code", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// pathBytes sums the size of the details entries of the paths.
+func pathBytes(paths []path.Path, deets *details.Details) int64 {
+	var total int64
+
+	for _, ent := range pathEntries(paths, deets) {
+		total += ent.Size()
+	}
+
+	return total
+}
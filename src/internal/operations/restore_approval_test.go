@@ -0,0 +1,221 @@
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/events"
+	evmock "github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+type RestoreApprovalUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreApprovalUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreApprovalUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// approvalPaths produces two mail paths, and details for them which sum
+// to 300 bytes.
+func approvalPaths(t *testing.T) ([]path.Path, *details.Details) {
+	var (
+		ps    = make([]path.Path, 0, 2)
+		deets = &details.Details{}
+	)
+
+	for i, item := range []string{"a", "b"} {
+		p, err := path.Builder{}.
+			Append("Inbox", item).
+			ToDataLayerExchangePathForCategory("tid", "uid", path.EmailCategory, true)
+		require.NoError(t, err)
+
+		ps = append(ps, p)
+		deets.Entries = append(deets.Entries, details.DetailsEntry{
+			RepoRef:  p.String(),
+			ShortRef: p.ShortRef(),
+			ItemInfo: details.ItemInfo{
+				Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail, Size: int64(100 * (i + 1))},
+			},
+		})
+	}
+
+	return ps, deets
+}
+
+// approvalStore produces a store whose restore policy holds the approval
+// policy.
+func approvalStore(t *testing.T, ap control.ApprovalPolicy) *store.Wrapper {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	sw := &store.Wrapper{Storer: newProgressStore()}
+	require.NoError(t, sw.SetRestorePolicy(ctx, control.RestorePolicy{Approval: ap}))
+
+	return sw
+}
+
+func (suite *RestoreApprovalUnitSuite) TestAwaitApproval_notRequired() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	ps, deets := approvalPaths(suite.T())
+
+	table := []struct {
+		name string
+		ap   control.ApprovalPolicy
+	}{
+		{"disabled", control.ApprovalPolicy{}},
+		{"under item threshold", control.ApprovalPolicy{MaxItems: 2}},
+		{"under byte threshold", control.ApprovalPolicy{MaxBytes: 300}},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			var (
+				t  = suite.T()
+				sw = approvalStore(t, test.ap)
+				mb = evmock.NewBus()
+			)
+
+			err := awaitApproval(
+				ctx,
+				sw,
+				mb,
+				control.ApprovalOptions{},
+				control.RestoreApproval{RestoreID: "rid"},
+				ps,
+				deets)
+			require.NoError(t, err)
+
+			ra, err := sw.GetRestoreApproval(ctx, "rid")
+			require.NoError(t, err)
+			assert.Nil(t, ra, "no approval requested")
+			assert.Zero(t, mb.TimesCalled[events.RestoreApprovalRequest])
+		})
+	}
+}
+
+func (suite *RestoreApprovalUnitSuite) TestAwaitApproval() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	defer func(d time.Duration) { approvalPollInterval = d }(approvalPollInterval)
+	approvalPollInterval = time.Millisecond
+
+	var (
+		t         = suite.T()
+		ps, deets = approvalPaths(t)
+		mb        = evmock.NewBus()
+		received  = make(chan approvalWebhookPayload, 1)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p approvalWebhookPayload
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		received <- p
+	}))
+	defer srv.Close()
+
+	var (
+		sw = approvalStore(t, control.ApprovalPolicy{MaxBytes: 200, Webhook: srv.URL})
+		ao = control.ApprovalOptions{Timeout: 10 * time.Millisecond}
+	)
+
+	ra := control.RestoreApproval{RestoreID: "rid", BackupID: "bid", Destination: "Corso_Restore_"}
+
+	err := awaitApproval(ctx, sw, mb, ao, ra, ps, deets)
+	assert.ErrorIs(t, err, ErrApprovalPending)
+	assert.Equal(t, 1, mb.TimesCalled[events.RestoreApprovalRequest])
+
+	p := <-received
+	assert.Equal(t, ra.RestoreID, p.RestoreID)
+	assert.Equal(t, ra.BackupID, p.BackupID)
+	assert.Equal(t, 2, p.Items)
+	assert.Equal(t, int64(300), p.Bytes)
+	assert.Equal(t, "corso restore approve rid", p.ApproveCommand)
+	assert.Equal(t, common.Identity(), p.RequestedBy)
+
+	req, err := sw.GetRestoreApproval(ctx, ra.RestoreID)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.False(t, req.Approved)
+	assert.Equal(t, common.Identity(), req.RequestedBy)
+
+	// resuming an unapproved restore waits again, without a second request.
+	err = awaitApproval(ctx, sw, mb, ao, ra, ps, deets)
+	assert.ErrorIs(t, err, ErrApprovalPending)
+	assert.Equal(t, 1, mb.TimesCalled[events.RestoreApprovalRequest])
+
+	err = sw.ApproveRestore(ctx, ra.RestoreID, common.Identity())
+	assert.ErrorIs(t, err, store.ErrSelfApproval)
+
+	require.NoError(t, sw.ApproveRestore(ctx, ra.RestoreID, "approver@host"))
+
+	err = awaitApproval(ctx, sw, mb, ao, ra, ps, deets)
+	assert.NoError(t, err)
+}
+
+func (suite *RestoreApprovalUnitSuite) TestAwaitApproval_webhookFailure() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t         = suite.T()
+		ps, deets = approvalPaths(t)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var (
+		sw = approvalStore(t, control.ApprovalPolicy{MaxItems: 1, Webhook: srv.URL})
+		ao = control.ApprovalOptions{Timeout: time.Millisecond}
+	)
+
+	err := awaitApproval(ctx, sw, evmock.NewBus(), ao, control.RestoreApproval{RestoreID: "rid"}, ps, deets)
+	assert.ErrorIs(t, err, ErrApprovalPending, "webhook failures don't fail the restore")
+
+	req, err := sw.GetRestoreApproval(ctx, "rid")
+	require.NoError(t, err)
+	assert.NotNil(t, req, "approval was still requested")
+}
+
+func (suite *RestoreApprovalUnitSuite) TestAwaitApproval_inPlace() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t         = suite.T()
+		ps, deets = approvalPaths(t)
+		sw        = approvalStore(t, control.ApprovalPolicy{MaxItems: 100})
+		mb        = evmock.NewBus()
+		ao        = control.ApprovalOptions{Timeout: time.Millisecond}
+		ra        = control.RestoreApproval{RestoreID: "rid", InPlace: true}
+	)
+
+	// in place restores need approval even under the thresholds.
+	err := awaitApproval(ctx, sw, mb, ao, ra, ps, deets)
+	assert.ErrorIs(t, err, ErrApprovalPending)
+	assert.Equal(t, 1, mb.TimesCalled[events.RestoreApprovalRequest])
+
+	req, err := sw.GetRestoreApproval(ctx, ra.RestoreID)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.True(t, req.InPlace)
+}
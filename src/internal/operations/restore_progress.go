@@ -0,0 +1,473 @@
+package operations
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+const (
+	// restoreLease is how long an in-progress restore holds its idempotency
+	// key without renewing it.  Retries of a restore whose lease expired,
+	// such as one whose process crashed, take over the key.
+	restoreLease        = 30 * time.Minute
+	restoreLeaseRenewal = restoreLease / 3
+
+	// restoreCheckpointInterval is how often a running restore stores the
+	// items it completed, so that a crashed restore can be resumed.
+	restoreCheckpointInterval = time.Minute
+	// restoreProgressTTL is how long the progress of a restore is retained
+	// after it was last updated.  Expired restores can't be resumed, and
+	// retries of an expired keyed restore run again.
+	restoreProgressTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrRestoreInProgress    = errors.New("a restore with the same idempotency key is in progress")
+	ErrIdempotencyKeyReused = errors.New("idempotency key belongs to a restore of a different backup")
+)
+
+// RestoreProgress checkpoints the items completed by a restore operation,
+// so that a failed, cancelled, or crashed restore can be resumed without
+// duplicating items in the destination.  The model is transient: it's
+// deleted once the restore completes without errors, unless the restore
+// was given an idempotency key, and it expires after restoreProgressTTL.
+type RestoreProgress struct {
+	model.BaseModel
+
+	BackupID model.StableID `json:"backupID"`
+	// Destination is re-used when resuming, so that the remaining items
+	// land alongside those that were already restored.
+	Destination control.RestoreDestination `json:"destination"`
+	// Completed holds the ShortRefs of every restored item.
+	Completed map[string]struct{} `json:"completed"`
+
+	// IdempotencyKey identifies retries of the same restore.  The progress
+	// of a keyed restore is retained after it completes, so that retries
+	// don't restore its items a second time.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// Status of the keyed restore: in progress, completed, or failed.
+	Status opStatus `json:"status,omitempty"`
+	// LeaseExpiry is the time at which an in-progress keyed restore is
+	// presumed to have died.
+	LeaseExpiry time.Time `json:"leaseExpiry,omitempty"`
+	// Results of the completed keyed restore, reported to retries.
+	Results stats.ReadWrites `json:"results,omitempty"`
+	// UpdatedAt is when the progress was last stored.
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+
+	// guards concurrent lease renewals, checkpoints, and recording.
+	mu sync.Mutex
+}
+
+// getRestoreProgress produces the progress for the restore.  If restoreID
+// is empty, a new, unpersisted progress is created.  Otherwise, the progress
+// of the identified restore is retrieved from the store.
+func getRestoreProgress(
+	ctx context.Context,
+	sw *store.Wrapper,
+	restoreID, backupID model.StableID,
+	dest control.RestoreDestination,
+) (*RestoreProgress, error) {
+	if len(restoreID) == 0 {
+		return &RestoreProgress{
+			BaseModel:   model.BaseModel{ID: model.StableID(uuid.NewString())},
+			BackupID:    backupID,
+			Destination: dest,
+			Completed:   map[string]struct{}{},
+		}, nil
+	}
+
+	rp := &RestoreProgress{}
+
+	if err := sw.Get(ctx, model.RestoreProgressSchema, restoreID, rp); err != nil {
+		return nil, errors.Wrap(err, "getting restore progress")
+	}
+
+	if rp.BackupID != backupID {
+		return nil, clues.New("resumed restore was created from a different backup").
+			With("resume_backup_id", rp.BackupID)
+	}
+
+	if rp.Completed == nil {
+		rp.Completed = map[string]struct{}{}
+	}
+
+	return rp, nil
+}
+
+// skip removes the paths of all completed items.
+func (rp *RestoreProgress) skip(ps []path.Path) []path.Path {
+	if len(rp.Completed) == 0 {
+		return ps
+	}
+
+	remaining := make([]path.Path, 0, len(ps))
+
+	for _, p := range ps {
+		if _, ok := rp.Completed[p.ShortRef()]; !ok {
+			remaining = append(remaining, p)
+		}
+	}
+
+	return remaining
+}
+
+// record marks every item in the restore details as completed.
+func (rp *RestoreProgress) record(deets *details.Details) {
+	if deets == nil {
+		return
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	for _, ent := range deets.Entries {
+		rp.Completed[ent.ShortRef] = struct{}{}
+	}
+}
+
+// observer produces a details builder observer that records each restored
+// item, and checkpoints the progress once the interval has passed since
+// the last checkpoint.
+func (rp *RestoreProgress) observer(
+	ctx context.Context,
+	sw *store.Wrapper,
+	interval time.Duration,
+) func(shortRef string) {
+	ctx = detachedCtx{ctx}
+	last := time.Now()
+
+	return func(shortRef string) {
+		rp.mu.Lock()
+		defer rp.mu.Unlock()
+
+		rp.Completed[shortRef] = struct{}{}
+
+		if time.Since(last) < interval {
+			return
+		}
+
+		last = time.Now()
+
+		logger.Ctx(ctx).Infow("checkpointing restore progress", "completed_items", len(rp.Completed))
+
+		if err := rp.save(ctx, sw); err != nil {
+			logger.Ctx(ctx).
+				With("err", err).
+				Infow("checkpointing restore progress", clues.InErr(err).Slice()...)
+		}
+	}
+}
+
+// save stores the progress.  The caller must hold rp.mu.
+func (rp *RestoreProgress) save(ctx context.Context, sw *store.Wrapper) error {
+	rp.UpdatedAt = time.Now()
+
+	if len(rp.ModelStoreID) > 0 {
+		return errors.Wrap(sw.Update(ctx, model.RestoreProgressSchema, rp), "updating restore progress")
+	}
+
+	return errors.Wrap(sw.Put(ctx, model.RestoreProgressSchema, rp), "storing restore progress")
+}
+
+// persistProgress stores the restore progress so that the restore can be
+// resumed, or removes it if the restore has nothing left to resume.
+// Restores that didn't complete any items aren't stored, since resuming
+// them would restore everything again.
+// Persistence uses a context that is detached from cancellation, since
+// a cancelled restore is one of the primary reasons for resuming.
+func persistProgress(
+	ctx context.Context,
+	sw *store.Wrapper,
+	rp *RestoreProgress,
+	finished bool,
+) error {
+	ctx = detachedCtx{ctx}
+	persisted := len(rp.ModelStoreID) > 0
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if len(rp.IdempotencyKey) > 0 {
+		return persistKeyedProgress(ctx, sw, rp, finished)
+	}
+
+	if finished {
+		if !persisted {
+			return nil
+		}
+
+		return errors.Wrap(
+			sw.Delete(ctx, model.RestoreProgressSchema, rp.ID),
+			"deleting restore progress")
+	}
+
+	if !persisted && len(rp.Completed) == 0 {
+		return nil
+	}
+
+	logger.Ctx(ctx).Infow("checkpointing restore progress", "completed_items", len(rp.Completed))
+
+	return rp.save(ctx, sw)
+}
+
+// persistKeyedProgress stores the outcome of a keyed restore.  Completed
+// restores retain their record, minus the items they completed, so that
+// retries can report the completion instead of restoring anything.
+func persistKeyedProgress(
+	ctx context.Context,
+	sw *store.Wrapper,
+	rp *RestoreProgress,
+	finished bool,
+) error {
+	rp.Status = Failed
+	rp.LeaseExpiry = time.Time{}
+
+	if finished {
+		rp.Status = Completed
+		rp.Completed = map[string]struct{}{}
+	}
+
+	logger.Ctx(ctx).Infow(
+		"storing keyed restore progress",
+		"status", rp.Status.String(),
+		"completed_items", len(rp.Completed))
+
+	return rp.save(ctx, sw)
+}
+
+// claimRestore produces the progress of the restore identified by the
+// idempotency key, claiming the key for the caller.  If no restore has used
+// the key, a new progress is stored.  If a previous restore with the key
+// failed, or its lease expired, its progress is resumed.  The progress of
+// a completed restore is returned as-is; the caller shouldn't restore
+// anything.  Restores still holding the key produce ErrRestoreInProgress.
+func claimRestore(
+	ctx context.Context,
+	sw *store.Wrapper,
+	key string,
+	backupID model.StableID,
+	dest control.RestoreDestination,
+) (*RestoreProgress, error) {
+	ctx = clues.Add(ctx, "idempotency_key", key)
+
+	rp, err := getKeyedProgress(ctx, sw, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if rp == nil {
+		return putKeyedProgress(ctx, sw, key, backupID, dest)
+	}
+
+	if rp.BackupID != backupID {
+		return nil, clues.Stack(ErrIdempotencyKeyReused).WithClues(ctx).With("key_backup_id", rp.BackupID)
+	}
+
+	switch {
+	case rp.Status == Completed:
+		return rp, nil
+
+	case rp.Status == InProgress && time.Now().Before(rp.LeaseExpiry):
+		return nil, clues.Stack(ErrRestoreInProgress).WithClues(ctx).With("restore_id", rp.ID)
+	}
+
+	logger.Ctx(ctx).Infow("resuming keyed restore", "restore_id", rp.ID, "status", rp.Status.String())
+
+	rp.Status = InProgress
+	rp.LeaseExpiry = time.Now().Add(restoreLease)
+
+	if rp.Completed == nil {
+		rp.Completed = map[string]struct{}{}
+	}
+
+	if err := rp.save(ctx, sw); err != nil {
+		return nil, errors.Wrap(err, "claiming restore progress")
+	}
+
+	return rp, nil
+}
+
+// getKeyedProgress retrieves the progress of the restore with the
+// idempotency key, or nil if no restore used the key.  If concurrent claims
+// produced more than one progress, the one with the lowest ID wins.
+func getKeyedProgress(ctx context.Context, sw *store.Wrapper, key string) (*RestoreProgress, error) {
+	bms, err := sw.GetIDsForType(ctx, model.RestoreProgressSchema, map[string]string{model.IdempotencyKeyTag: key})
+	if err != nil {
+		return nil, errors.Wrap(err, "looking up restore progress")
+	}
+
+	if len(bms) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(bms, func(i, j int) bool { return bms[i].ID < bms[j].ID })
+
+	rp := &RestoreProgress{}
+
+	if err := sw.GetWithModelStoreID(ctx, model.RestoreProgressSchema, bms[0].ModelStoreID, rp); err != nil {
+		return nil, errors.Wrap(err, "getting restore progress")
+	}
+
+	return rp, nil
+}
+
+// putKeyedProgress stores a new, in-progress restore under the key.  The
+// model store has no conditional writes, so two concurrent claims of a new
+// key could both succeed.  Re-reading the key after storing the progress
+// settles the race: the claim that lost deletes its progress.
+func putKeyedProgress(
+	ctx context.Context,
+	sw *store.Wrapper,
+	key string,
+	backupID model.StableID,
+	dest control.RestoreDestination,
+) (*RestoreProgress, error) {
+	rp := &RestoreProgress{
+		BaseModel: model.BaseModel{
+			ID:   model.StableID(uuid.NewString()),
+			Tags: map[string]string{model.IdempotencyKeyTag: key},
+		},
+		BackupID:       backupID,
+		Destination:    dest,
+		Completed:      map[string]struct{}{},
+		IdempotencyKey: key,
+		Status:         InProgress,
+		LeaseExpiry:    time.Now().Add(restoreLease),
+	}
+
+	if err := rp.save(ctx, sw); err != nil {
+		return nil, err
+	}
+
+	winner, err := getKeyedProgress(ctx, sw, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if winner != nil && winner.ID != rp.ID {
+		if err := sw.Delete(ctx, model.RestoreProgressSchema, rp.ID); err != nil {
+			logger.Ctx(ctx).
+				With("err", err).
+				Errorw("deleting duplicate restore progress", clues.InErr(err).Slice()...)
+		}
+
+		return nil, clues.Stack(ErrRestoreInProgress).WithClues(ctx).With("restore_id", winner.ID)
+	}
+
+	return rp, nil
+}
+
+// renewLease extends the lease of an in-progress keyed restore until the
+// returned func is called, so that retries don't take over a restore that
+// is still running.
+func renewLease(ctx context.Context, sw *store.Wrapper, rp *RestoreProgress) func() {
+	if len(rp.IdempotencyKey) == 0 {
+		return func() {}
+	}
+
+	var (
+		stop = make(chan struct{})
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(restoreLeaseRenewal)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+
+			case <-ticker.C:
+				rp.mu.Lock()
+				rp.LeaseExpiry = time.Now().Add(restoreLease)
+				err := rp.save(ctx, sw)
+				rp.mu.Unlock()
+
+				if err != nil {
+					logger.Ctx(ctx).
+						With("err", err).
+						Errorw("renewing restore lease", clues.InErr(err).Slice()...)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// purgeExpiredProgress deletes the progress of every restore, other than
+// the running one, that wasn't updated within restoreProgressTTL.  Keyed
+// restores that still hold their lease are retained.
+func purgeExpiredProgress(
+	ctx context.Context,
+	sw *store.Wrapper,
+	running model.StableID,
+	now time.Time,
+) error {
+	bms, err := sw.GetIDsForType(ctx, model.RestoreProgressSchema, nil)
+	if err != nil {
+		return errors.Wrap(err, "looking up restore progress")
+	}
+
+	var purged int
+
+	for _, bm := range bms {
+		if bm.ID == running {
+			continue
+		}
+
+		rp := &RestoreProgress{}
+
+		if err := sw.GetWithModelStoreID(ctx, model.RestoreProgressSchema, bm.ModelStoreID, rp); err != nil {
+			return errors.Wrap(err, "getting restore progress")
+		}
+
+		if now.Sub(rp.UpdatedAt) < restoreProgressTTL ||
+			(rp.Status == InProgress && now.Before(rp.LeaseExpiry)) {
+			continue
+		}
+
+		if err := sw.DeleteWithModelStoreID(ctx, bm.ModelStoreID); err != nil {
+			return errors.Wrap(err, "deleting expired restore progress")
+		}
+
+		purged++
+	}
+
+	if purged > 0 {
+		logger.Ctx(ctx).Infow("deleted expired restore progress", "num_purged", purged)
+	}
+
+	return nil
+}
+
+// detachedCtx retains the values of its parent, but is never cancelled.
+type detachedCtx struct {
+	context.Context
+}
+
+func (detachedCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedCtx) Done() <-chan struct{}       { return nil }
+func (detachedCtx) Err() error                  { return nil }
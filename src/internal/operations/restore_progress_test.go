@@ -0,0 +1,505 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// progressStore is an in-memory model store for restore progress, and the
+// other models consulted by restores.
+type progressStore struct {
+	models map[manifest.ID]storedModel
+}
+
+type storedModel struct {
+	schema model.Schema
+	id     model.StableID
+	tags   map[string]string
+	data   []byte
+}
+
+func newProgressStore() *progressStore {
+	return &progressStore{models: map[manifest.ID]storedModel{}}
+}
+
+func (ps *progressStore) put(s model.Schema, m model.Model) error {
+	bs, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	base := m.Base()
+	base.ModelStoreID = manifest.ID(uuid.NewString())
+	ps.models[base.ModelStoreID] = storedModel{s, base.ID, base.Tags, bs}
+
+	return nil
+}
+
+func (ps *progressStore) find(id model.StableID) (manifest.ID, bool) {
+	for msid, sm := range ps.models {
+		if sm.id == id {
+			return msid, true
+		}
+	}
+
+	return "", false
+}
+
+func (ps *progressStore) Delete(_ context.Context, _ model.Schema, id model.StableID) error {
+	if msid, ok := ps.find(id); ok {
+		delete(ps.models, msid)
+	}
+
+	return nil
+}
+
+func (ps *progressStore) DeleteWithModelStoreID(_ context.Context, id manifest.ID) error {
+	delete(ps.models, id)
+	return nil
+}
+
+func (ps *progressStore) Get(ctx context.Context, s model.Schema, id model.StableID, m model.Model) error {
+	msid, ok := ps.find(id)
+	if !ok {
+		return errors.New("not found")
+	}
+
+	return ps.GetWithModelStoreID(ctx, s, msid, m)
+}
+
+func (ps *progressStore) GetIDsForType(
+	_ context.Context,
+	s model.Schema,
+	tags map[string]string,
+) ([]*model.BaseModel, error) {
+	bms := []*model.BaseModel{}
+
+outer:
+	for msid, sm := range ps.models {
+		if sm.schema != s {
+			continue
+		}
+
+		for k, v := range tags {
+			if sm.tags[k] != v {
+				continue outer
+			}
+		}
+
+		bms = append(bms, &model.BaseModel{ID: sm.id, ModelStoreID: msid, Tags: sm.tags})
+	}
+
+	return bms, nil
+}
+
+func (ps *progressStore) GetWithModelStoreID(_ context.Context, _ model.Schema, id manifest.ID, m model.Model) error {
+	sm, ok := ps.models[id]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	if err := json.Unmarshal(sm.data, m); err != nil {
+		return err
+	}
+
+	base := m.Base()
+	base.ID = sm.id
+	base.ModelStoreID = id
+	base.Tags = sm.tags
+
+	return nil
+}
+
+func (ps *progressStore) Put(_ context.Context, s model.Schema, m model.Model) error {
+	return ps.put(s, m)
+}
+
+func (ps *progressStore) Update(_ context.Context, s model.Schema, m model.Model) error {
+	delete(ps.models, m.Base().ModelStoreID)
+	return ps.put(s, m)
+}
+
+type RestoreProgressUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreProgressUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreProgressUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestoreProgressUnitSuite) TestGetRestoreProgress_new() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		dest = tester.DefaultTestRestoreDestination()
+	)
+
+	rp, err := getRestoreProgress(ctx, &store.Wrapper{}, "", "bid", dest)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, rp.ID)
+	assert.Empty(t, rp.ModelStoreID, "new progress should not be persisted")
+	assert.Equal(t, "bid", string(rp.BackupID))
+	assert.Equal(t, dest, rp.Destination)
+	assert.Empty(t, rp.Completed)
+}
+
+func (suite *RestoreProgressUnitSuite) TestSkipAndRecord() {
+	t := suite.T()
+
+	ps := make([]path.Path, 0, 3)
+
+	for _, item := range []string{"a", "b", "c"} {
+		p, err := path.Builder{}.
+			Append("Inbox", item).
+			ToDataLayerExchangePathForCategory("tid", "uid", path.EmailCategory, true)
+		require.NoError(t, err)
+
+		ps = append(ps, p)
+	}
+
+	rp := &RestoreProgress{Completed: map[string]struct{}{}}
+	assert.Equal(t, ps, rp.skip(ps), "nothing completed")
+
+	rp.record(nil)
+
+	deets := &details.Details{}
+	deets.Entries = append(
+		deets.Entries,
+		details.DetailsEntry{RepoRef: ps[0].String(), ShortRef: ps[0].ShortRef()},
+		details.DetailsEntry{RepoRef: ps[2].String(), ShortRef: ps[2].ShortRef()})
+
+	rp.record(deets)
+	assert.Len(t, rp.Completed, 2)
+	assert.Equal(t, []path.Path{ps[1]}, rp.skip(ps))
+}
+
+func (suite *RestoreProgressUnitSuite) TestDetachedCtx() {
+	t := suite.T()
+
+	type key struct{}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	cancel()
+
+	dctx := detachedCtx{ctx}
+	assert.NoError(t, dctx.Err())
+	assert.Nil(t, dctx.Done())
+	assert.Equal(t, "v", dctx.Value(key{}))
+}
+
+func (suite *RestoreProgressUnitSuite) TestClaimRestore() {
+	dest := tester.DefaultTestRestoreDestination()
+
+	table := []struct {
+		name string
+		// prior restore with the key, if any.
+		prior       *RestoreProgress
+		expectErr   error
+		expectPrior bool
+		expect      opStatus
+	}{
+		{
+			name:   "new key",
+			expect: InProgress,
+		},
+		{
+			name: "completed",
+			prior: &RestoreProgress{
+				BackupID: "bid",
+				Status:   Completed,
+				Results:  stats.ReadWrites{ItemsWritten: 3},
+			},
+			expectPrior: true,
+			expect:      Completed,
+		},
+		{
+			name: "failed",
+			prior: &RestoreProgress{
+				BackupID:  "bid",
+				Status:    Failed,
+				Completed: map[string]struct{}{"a": {}},
+			},
+			expectPrior: true,
+			expect:      InProgress,
+		},
+		{
+			name: "in progress",
+			prior: &RestoreProgress{
+				BackupID:    "bid",
+				Status:      InProgress,
+				LeaseExpiry: time.Now().Add(time.Hour),
+			},
+			expectErr: ErrRestoreInProgress,
+		},
+		{
+			name: "in progress, lease expired",
+			prior: &RestoreProgress{
+				BackupID:    "bid",
+				Status:      InProgress,
+				LeaseExpiry: time.Now().Add(-time.Minute),
+			},
+			expectPrior: true,
+			expect:      InProgress,
+		},
+		{
+			name: "different backup",
+			prior: &RestoreProgress{
+				BackupID: "other",
+				Status:   Completed,
+			},
+			expectErr: ErrIdempotencyKeyReused,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				sw = &store.Wrapper{Storer: newProgressStore()}
+			)
+
+			if test.prior != nil {
+				test.prior.ID = "prior"
+				test.prior.Tags = map[string]string{model.IdempotencyKeyTag: "key"}
+				test.prior.IdempotencyKey = "key"
+				require.NoError(t, sw.Put(ctx, model.RestoreProgressSchema, test.prior))
+			}
+
+			rp, err := claimRestore(ctx, sw, "key", "bid", dest)
+			if test.expectErr != nil {
+				assert.ErrorIs(t, err, test.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expect, rp.Status)
+			assert.Equal(t, "key", rp.IdempotencyKey)
+			assert.NotEmpty(t, rp.ModelStoreID, "claim is persisted")
+
+			if test.expectPrior {
+				assert.Equal(t, test.prior.ID, rp.ID)
+				assert.Len(t, rp.Completed, len(test.prior.Completed))
+				assert.Equal(t, test.prior.Results, rp.Results)
+			}
+
+			if test.expect == InProgress {
+				assert.True(t, rp.LeaseExpiry.After(time.Now()), "lease is held")
+
+				_, err := claimRestore(ctx, sw, "key", "bid", dest)
+				assert.ErrorIs(t, err, ErrRestoreInProgress, "key is claimed")
+			}
+		})
+	}
+}
+
+func (suite *RestoreProgressUnitSuite) TestClaimRestore_concurrentClaims() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		ps   = newProgressStore()
+		sw   = &store.Wrapper{Storer: ps}
+		dest = tester.DefaultTestRestoreDestination()
+		// a concurrent claim that stored its progress first, and sorts
+		// ahead of any generated ID.
+		other = &RestoreProgress{
+			BaseModel: model.BaseModel{
+				ID:   "0",
+				Tags: map[string]string{model.IdempotencyKeyTag: "key"},
+			},
+			BackupID:       "bid",
+			IdempotencyKey: "key",
+			Status:         InProgress,
+		}
+	)
+
+	rp, err := putKeyedProgress(ctx, sw, "key", "bid", dest)
+	require.NoError(t, err)
+
+	require.NoError(t, sw.Put(ctx, model.RestoreProgressSchema, other))
+
+	_, err = putKeyedProgress(ctx, sw, "key", "bid", dest)
+	assert.ErrorIs(t, err, ErrRestoreInProgress)
+
+	bms, err := sw.GetIDsForType(ctx, model.RestoreProgressSchema, map[string]string{model.IdempotencyKeyTag: "key"})
+	require.NoError(t, err)
+	assert.Len(t, bms, 2, "losing claim deletes its progress")
+
+	winner, err := getKeyedProgress(ctx, sw, "key")
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, winner.ID)
+	assert.NotEqual(t, rp.ID, winner.ID)
+}
+
+func (suite *RestoreProgressUnitSuite) TestPersistProgress_keyed() {
+	table := []struct {
+		name            string
+		finished        bool
+		expect          opStatus
+		expectCompleted int
+	}{
+		{"finished", true, Completed, 0},
+		{"unfinished", false, Failed, 1},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				sw = &store.Wrapper{Storer: newProgressStore()}
+			)
+
+			rp, err := claimRestore(ctx, sw, "key", "bid", tester.DefaultTestRestoreDestination())
+			require.NoError(t, err)
+
+			rp.Completed["a"] = struct{}{}
+			rp.Results = stats.ReadWrites{ItemsWritten: 1}
+
+			require.NoError(t, persistProgress(ctx, sw, rp, test.finished))
+
+			got, err := getKeyedProgress(ctx, sw, "key")
+			require.NoError(t, err)
+			require.NotNil(t, got, "keyed progress is retained")
+			assert.Equal(t, test.expect, got.Status)
+			assert.Len(t, got.Completed, test.expectCompleted)
+			assert.Equal(t, rp.Results, got.Results)
+			assert.True(t, got.LeaseExpiry.IsZero(), "lease is released")
+		})
+	}
+}
+
+func (suite *RestoreProgressUnitSuite) TestPersistProgress() {
+	table := []struct {
+		name      string
+		completed int
+		finished  bool
+		expect    int
+	}{
+		{"nothing restored", 0, false, 0},
+		{"partially restored", 1, false, 1},
+		{"finished", 1, true, 0},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t  = suite.T()
+				sw = &store.Wrapper{Storer: newProgressStore()}
+			)
+
+			rp, err := getRestoreProgress(ctx, sw, "", "bid", tester.DefaultTestRestoreDestination())
+			require.NoError(t, err)
+
+			for i := 0; i < test.completed; i++ {
+				rp.Completed[uuid.NewString()] = struct{}{}
+			}
+
+			// a checkpoint stored while the restore ran.
+			if test.finished {
+				require.NoError(t, rp.save(ctx, sw))
+			}
+
+			require.NoError(t, persistProgress(ctx, sw, rp, test.finished))
+
+			bms, err := sw.GetIDsForType(ctx, model.RestoreProgressSchema, nil)
+			require.NoError(t, err)
+			assert.Len(t, bms, test.expect)
+		})
+	}
+}
+
+func (suite *RestoreProgressUnitSuite) TestObserver() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		sw = &store.Wrapper{Storer: newProgressStore()}
+	)
+
+	rp, err := getRestoreProgress(ctx, sw, "", "bid", tester.DefaultTestRestoreDestination())
+	require.NoError(t, err)
+
+	deets := &details.Builder{}
+	deets.Observe(rp.observer(ctx, sw, time.Hour))
+	deets.Add("rr1", "sr1", "", "", false, details.ItemInfo{})
+
+	assert.Contains(t, rp.Completed, "sr1")
+	assert.Empty(t, rp.ModelStoreID, "not checkpointed within the interval")
+
+	deets.Observe(rp.observer(ctx, sw, 0))
+	deets.Add("rr2", "sr2", "", "", false, details.ItemInfo{})
+
+	require.NotEmpty(t, rp.ModelStoreID, "checkpointed")
+
+	got, err := getRestoreProgress(ctx, sw, rp.ID, "bid", rp.Destination)
+	require.NoError(t, err)
+	assert.Len(t, got.Completed, 2)
+	assert.False(t, got.UpdatedAt.IsZero())
+}
+
+func (suite *RestoreProgressUnitSuite) TestPurgeExpiredProgress() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		sw      = &store.Wrapper{Storer: newProgressStore()}
+		now     = time.Now()
+		expired = now.Add(-restoreProgressTTL - time.Hour)
+		models  = map[string]*RestoreProgress{
+			"running": {BaseModel: model.BaseModel{ID: "running"}, UpdatedAt: expired},
+			"recent":  {BaseModel: model.BaseModel{ID: "recent"}, UpdatedAt: now},
+			"expired": {BaseModel: model.BaseModel{ID: "expired"}, UpdatedAt: expired},
+			"leased": {
+				BaseModel:   model.BaseModel{ID: "leased"},
+				UpdatedAt:   expired,
+				Status:      InProgress,
+				LeaseExpiry: now.Add(time.Minute),
+			},
+			"unversioned": {BaseModel: model.BaseModel{ID: "unversioned"}},
+		}
+	)
+
+	for _, rp := range models {
+		require.NoError(t, sw.Put(ctx, model.RestoreProgressSchema, rp))
+	}
+
+	require.NoError(t, purgeExpiredProgress(ctx, sw, "running", now))
+
+	bms, err := sw.GetIDsForType(ctx, model.RestoreProgressSchema, nil)
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, bm := range bms {
+		ids = append(ids, string(bm.ID))
+	}
+
+	assert.ElementsMatch(t, []string{"running", "recent", "leased"}, ids)
+}
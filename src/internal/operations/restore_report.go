@@ -0,0 +1,219 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/connector/exchange/api"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// maxReportItems bounds the number of restored items listed in a restore
+// report, so that large restores still produce a readable report.
+const maxReportItems = 500
+
+// restoreReport summarizes a restore: what was restored, where it was
+// restored to, and any failures.
+type restoreReport struct {
+	RestoreID     model.StableID
+	BackupID      model.StableID
+	Service       string
+	ResourceOwner string
+	Destination   string
+	Status        string
+	StartedAt     time.Time
+	CompletedAt   time.Time
+	ItemsWritten  int
+	BytesRead     int64
+	Warning       string
+	// Items describes each restored item, as listed in the restore details.
+	Items []string
+	// Failures holds the message of each error that occurred.
+	Failures []string
+}
+
+// reportMailer sends restore reports.
+type reportMailer interface {
+	SendMail(ctx context.Context, user string, to []string, subject, body string) error
+}
+
+// newRestoreReport summarizes the results of the restore, along with the
+// items listed in its details.
+func (op *RestoreOperation) newRestoreReport(deets *details.Details) restoreReport {
+	rr := restoreReport{
+		RestoreID:     op.RestoreID,
+		BackupID:      op.BackupID,
+		Service:       op.Selectors.Service.String(),
+		ResourceOwner: op.Selectors.DiscreteOwner,
+		Destination:   op.Destination.ContainerName,
+		Status:        op.Status.String(),
+		StartedAt:     op.Results.StartedAt,
+		CompletedAt:   op.Results.CompletedAt,
+		ItemsWritten:  op.Results.ItemsWritten,
+		BytesRead:     op.Results.BytesRead,
+		Warning:       op.Results.QuotaWarning,
+	}
+
+	if len(op.Destination.DriveName) > 0 {
+		rr.Destination = op.Destination.DriveName + "/" + rr.Destination
+	}
+
+	if deets != nil {
+		for _, ent := range deets.Items() {
+			rr.Items = append(rr.Items, strings.Join(ent.Values(), " | "))
+		}
+	}
+
+	if err := op.Errors.Err(); err != nil {
+		rr.Failures = append(rr.Failures, err.Error())
+	}
+
+	for _, g := range op.Errors.Groups() {
+		for _, err := range g.Samples {
+			rr.Failures = append(rr.Failures, err.Error())
+		}
+
+		if more := g.Count - len(g.Samples); more > 0 {
+			rr.Failures = append(rr.Failures, fmt.Sprintf("%d more like: %s", more, g.Message))
+		}
+	}
+
+	return rr
+}
+
+// subject produces the subject line of the report.
+func (rr restoreReport) subject() string {
+	return fmt.Sprintf("Corso restore %s: %s of %s", rr.Status, rr.Service, rr.ResourceOwner)
+}
+
+// String formats the report as plain text.
+func (rr restoreReport) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintln(&sb, rr.subject())
+	fmt.Fprintln(&sb)
+	fmt.Fprintf(&sb, "Restore ID:     %s\n", rr.RestoreID)
+	fmt.Fprintf(&sb, "Backup ID:      %s\n", rr.BackupID)
+	fmt.Fprintf(&sb, "Resource owner: %s\n", rr.ResourceOwner)
+	fmt.Fprintf(&sb, "Destination:    %s\n", rr.Destination)
+	fmt.Fprintf(&sb, "Started:        %s\n", rr.StartedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Completed:      %s\n", rr.CompletedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Items restored: %d\n", rr.ItemsWritten)
+	fmt.Fprintf(&sb, "Bytes read:     %d\n", rr.BytesRead)
+
+	if len(rr.Warning) > 0 {
+		fmt.Fprintf(&sb, "Warning:        %s\n", rr.Warning)
+	}
+
+	fmt.Fprintln(&sb)
+	fmt.Fprintf(&sb, "Failures (%d):\n", len(rr.Failures))
+
+	for _, f := range rr.Failures {
+		fmt.Fprintf(&sb, "  - %s\n", f)
+	}
+
+	fmt.Fprintln(&sb)
+	fmt.Fprintf(&sb, "Restored items (%d):\n", len(rr.Items))
+
+	for i, item := range rr.Items {
+		if i == maxReportItems {
+			fmt.Fprintf(&sb, "  ... and %d more\n", len(rr.Items)-maxReportItems)
+			break
+		}
+
+		fmt.Fprintf(&sb, "  - %s\n", item)
+	}
+
+	return sb.String()
+}
+
+// reportRestore writes and mails the report of the restore, as configured
+// in the options.  Reports supplement the restore, so failures to produce
+// them are logged, and never fail the restore.
+func (op *RestoreOperation) reportRestore(ctx context.Context, deets *details.Details) {
+	ro := op.Options.RestoreReport
+	if !ro.Enabled() {
+		return
+	}
+
+	var mailer reportMailer
+
+	if ro.Mails() {
+		creds, err := op.account.M365Config()
+		if err != nil {
+			logger.Ctx(ctx).
+				With("err", err).
+				Errorw("getting credentials to mail the restore report", clues.InErr(err).Slice()...)
+
+			return
+		}
+
+		ac, err := api.NewClient(creds)
+		if err != nil {
+			logger.Ctx(ctx).
+				With("err", err).
+				Errorw("connecting to mail the restore report", clues.InErr(err).Slice()...)
+
+			return
+		}
+
+		mailer = ac.Mail()
+	}
+
+	if err := sendRestoreReport(ctx, ro, op.newRestoreReport(deets), mailer); err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("producing restore report", clues.InErr(err).Slice()...)
+	}
+}
+
+// reportFailure reports a restore that failed before it began, such as
+// when its destination isn't permitted.  Restores that fail later, such as
+// when they're rejected by an approver or fail the preflight checks, are
+// reported once their results are persisted.
+func (op *RestoreOperation) reportFailure(ctx context.Context, start time.Time) {
+	op.Status = Failed
+	op.Results.StartedAt = start
+	op.Results.CompletedAt = time.Now()
+
+	op.reportRestore(ctx, nil)
+}
+
+// sendRestoreReport writes the report to the options' file, and mails it
+// from the options' sender if one is set.  Both are attempted even if the
+// other fails.
+func sendRestoreReport(
+	ctx context.Context,
+	ro control.RestoreReportOptions,
+	rr restoreReport,
+	mailer reportMailer,
+) error {
+	var (
+		body = rr.String()
+		errs *multierror.Error
+	)
+
+	if len(ro.File) > 0 {
+		if err := os.WriteFile(ro.File, []byte(body), 0o600); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "writing restore report"))
+		}
+	}
+
+	if ro.Mails() && mailer != nil {
+		ctx = clues.Add(ctx, "report_sender", logger.PII(ro.MailFrom))
+
+		if err := mailer.SendMail(ctx, ro.MailFrom, ro.MailTo, rr.subject(), body); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "mailing restore report"))
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
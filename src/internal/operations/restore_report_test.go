@@ -0,0 +1,225 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type RestoreReportUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestoreReportUnitSuite(t *testing.T) {
+	suite.Run(t, &RestoreReportUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+type mockReportMailer struct {
+	user, subject, body string
+	to                  []string
+	err                 error
+}
+
+func (m *mockReportMailer) SendMail(_ context.Context, user string, to []string, subject, body string) error {
+	m.user, m.to, m.subject, m.body = user, to, subject, body
+	return m.err
+}
+
+func (suite *RestoreReportUnitSuite) TestNewRestoreReport() {
+	t := suite.T()
+
+	sel := selectors.NewExchangeRestore([]string{"user"})
+
+	op := RestoreOperation{
+		operation:   operation{Errors: fault.New(false), Status: Completed},
+		BackupID:    "bup",
+		RestoreID:   "rst",
+		Selectors:   sel.Selector,
+		Destination: control.RestoreDestination{ContainerName: "Corso_Restore"},
+	}
+	op.Results.ItemsWritten = 1
+	op.Errors.Add(assert.AnError)
+
+	deets := &details.Details{}
+	deets.Entries = []details.DetailsEntry{
+		{
+			ShortRef: "folder",
+			ItemInfo: details.ItemInfo{Folder: &details.FolderInfo{DisplayName: "Inbox"}},
+		},
+		{
+			ShortRef: "item",
+			ItemInfo: details.ItemInfo{Exchange: &details.ExchangeInfo{
+				ItemType: details.ExchangeMail,
+				Subject:  "hello",
+			}},
+		},
+	}
+
+	rr := op.newRestoreReport(deets)
+
+	assert.Equal(t, "Completed", rr.Status)
+	assert.Equal(t, "user", rr.ResourceOwner)
+	assert.Equal(t, "Corso_Restore", rr.Destination)
+	assert.Equal(t, []string{assert.AnError.Error()}, rr.Failures)
+	require.Len(t, rr.Items, 1, "folders are left out")
+	assert.Contains(t, rr.Items[0], "hello")
+
+	body := rr.String()
+	assert.Contains(t, body, "Items restored: 1")
+	assert.Contains(t, body, "Failures (1):")
+	assert.Contains(t, body, "Restored items (1):")
+}
+
+func (suite *RestoreReportUnitSuite) TestNewRestoreReport_grouped() {
+	t := suite.T()
+
+	op := RestoreOperation{operation: operation{Errors: fault.New(false)}}
+
+	for i := 0; i < fault.MaxSamples+3; i++ {
+		op.Errors.Add(assert.AnError)
+	}
+
+	rr := op.newRestoreReport(nil)
+
+	require.Len(t, rr.Failures, fault.MaxSamples+1)
+	assert.Equal(t, "3 more like: "+assert.AnError.Error(), rr.Failures[fault.MaxSamples])
+}
+
+func (suite *RestoreReportUnitSuite) TestReportFailure() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t    = suite.T()
+		file = filepath.Join(t.TempDir(), "report.txt")
+		sel  = selectors.NewExchangeRestore([]string{"user"})
+	)
+
+	op := RestoreOperation{
+		operation: operation{
+			Errors:  fault.New(false),
+			Options: control.Options{RestoreReport: control.RestoreReportOptions{File: file}},
+		},
+		BackupID:  "bup",
+		Selectors: sel.Selector,
+	}
+	op.Errors.Fail(assert.AnError)
+
+	op.reportFailure(ctx, time.Now())
+
+	assert.Equal(t, Failed, op.Status)
+
+	body, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Corso restore Failed")
+	assert.Contains(t, string(body), assert.AnError.Error())
+}
+
+func (suite *RestoreReportUnitSuite) TestString_truncated() {
+	t := suite.T()
+
+	rr := restoreReport{}
+	for i := 0; i < maxReportItems+2; i++ {
+		rr.Items = append(rr.Items, fmt.Sprintf("item-%d", i))
+	}
+
+	body := rr.String()
+	assert.Contains(t, body, fmt.Sprintf("item-%d", maxReportItems-1))
+	assert.NotContains(t, body, fmt.Sprintf("item-%d", maxReportItems))
+	assert.Contains(t, body, "... and 2 more")
+}
+
+func (suite *RestoreReportUnitSuite) TestSendRestoreReport() {
+	rr := restoreReport{Status: "Completed", Service: "ExchangeService", ResourceOwner: "user"}
+
+	table := []struct {
+		name      string
+		opts      func(dir string) control.RestoreReportOptions
+		mailErr   error
+		expectErr assert.ErrorAssertionFunc
+		expectFile,
+		expectMail bool
+	}{
+		{
+			name: "file",
+			opts: func(dir string) control.RestoreReportOptions {
+				return control.RestoreReportOptions{File: filepath.Join(dir, "report.txt")}
+			},
+			expectErr:  assert.NoError,
+			expectFile: true,
+		},
+		{
+			name: "mail",
+			opts: func(string) control.RestoreReportOptions {
+				return control.RestoreReportOptions{MailFrom: "svc", MailTo: []string{"a@b.com"}}
+			},
+			expectErr:  assert.NoError,
+			expectMail: true,
+		},
+		{
+			name: "sender without recipients",
+			opts: func(string) control.RestoreReportOptions {
+				return control.RestoreReportOptions{MailFrom: "svc"}
+			},
+			expectErr: assert.NoError,
+		},
+		{
+			name: "mail fails, file still written",
+			opts: func(dir string) control.RestoreReportOptions {
+				return control.RestoreReportOptions{
+					File:     filepath.Join(dir, "report.txt"),
+					MailFrom: "svc",
+					MailTo:   []string{"a@b.com"},
+				}
+			},
+			mailErr:    assert.AnError,
+			expectErr:  assert.Error,
+			expectFile: true,
+			expectMail: true,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			var (
+				t      = suite.T()
+				opts   = test.opts(t.TempDir())
+				mailer = &mockReportMailer{err: test.mailErr}
+			)
+
+			err := sendRestoreReport(ctx, opts, rr, mailer)
+			test.expectErr(t, err)
+
+			if test.expectFile {
+				bs, err := os.ReadFile(opts.File)
+				require.NoError(t, err)
+				assert.Equal(t, rr.String(), string(bs))
+			}
+
+			if !test.expectMail {
+				assert.Empty(t, mailer.user)
+				return
+			}
+
+			assert.Equal(t, "svc", mailer.user)
+			assert.Equal(t, []string{"a@b.com"}, mailer.to)
+			assert.Equal(t, rr.subject(), mailer.subject)
+			assert.Equal(t, rr.String(), mailer.body)
+		})
+	}
+}
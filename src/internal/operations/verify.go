@@ -0,0 +1,526 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/common/crash"
+	"github.com/alcionai/corso/src/internal/connector/support"
+	"github.com/alcionai/corso/src/internal/data"
+	D "github.com/alcionai/corso/src/internal/diagnostics"
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/observe"
+	"github.com/alcionai/corso/src/internal/opctx"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/streamstore"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// VerifyOperation wraps an operation with verification-specific props.
+// Verification reads the items of each backup out of the repository, which
+// confirms that their stored data is present and intact, without
+// communicating with M365.  In scrub mode, each item is also deserialized
+// the way a restore would, to catch corrupted data before a restore needs it.
+type VerifyOperation struct {
+	operation
+
+	BackupIDs []model.StableID     `json:"backupIDs"`
+	Config    control.VerifyConfig `json:"config"`
+	Results   VerifyResults        `json:"results"`
+	Version   string               `json:"version"`
+
+	account account.Account
+	rand    *rand.Rand
+}
+
+// VerifyResults aggregate the details of the results of the operation.
+type VerifyResults struct {
+	stats.ReadWrites
+	stats.StartAndEndTime
+
+	// ItemsChecked is the count of items that were checked, including
+	// those found to be corrupted.
+	ItemsChecked int `json:"itemsChecked"`
+	// Corrupted lists each item that failed verification.
+	Corrupted []CorruptItem `json:"corrupted,omitempty"`
+}
+
+// CorruptItem identifies an item that failed verification, along with the
+// backup that holds it.
+type CorruptItem struct {
+	BackupID model.StableID `json:"backupID"`
+	ShortRef string         `json:"shortRef"`
+	RepoRef  string         `json:"repoRef"`
+	Reason   string         `json:"reason"`
+}
+
+// NewVerifyOperation constructs and validates a verify operation.
+func NewVerifyOperation(
+	ctx context.Context,
+	opts control.Options,
+	kw *kopia.Wrapper,
+	sw *store.Wrapper,
+	acct account.Account,
+	backupIDs []model.StableID,
+	cfg control.VerifyConfig,
+	bus events.Eventer,
+) (VerifyOperation, error) {
+	op := VerifyOperation{
+		operation: newOperation(opts, bus, kw, sw),
+		BackupIDs: backupIDs,
+		Config:    cfg,
+		Version:   "v0",
+		account:   acct,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if err := op.validate(); err != nil {
+		return VerifyOperation{}, err
+	}
+
+	return op, nil
+}
+
+func (op VerifyOperation) validate() error {
+	if len(op.BackupIDs) == 0 {
+		return errors.New("missing backup IDs")
+	}
+
+	if op.Config.SampleSize < 0 {
+		return errors.New("sample size must not be negative")
+	}
+
+	return op.operation.validate()
+}
+
+// Run begins a synchronous verify operation.  Corrupted items are
+// reported in the results, and don't cause the operation to fail.
+func (op *VerifyOperation) Run(ctx context.Context) (err error) {
+	defer func() {
+		if crErr := crash.Recovery(ctx, recover()); crErr != nil {
+			err = crErr
+		}
+	}()
+
+	var (
+		start     = time.Now()
+		bytesRead = &stats.ByteCounter{}
+	)
+
+	ctx, end := D.Span(ctx, "operations:verify:run")
+	defer func() {
+		end()
+		// wait for the progress display to clean up
+		observe.Complete()
+	}()
+
+	ctx = opctx.Set(ctx, opctx.Metadata{
+		OperationID: op.ID,
+		Kind:        opctx.Verify,
+		TenantID:    op.account.ID(),
+	})
+	ctx = clues.Add(
+		ctx,
+		"scrub", op.Config.Scrub,
+		"sample_size", op.Config.SampleSize)
+
+	for _, id := range op.BackupIDs {
+		if op.Errors.Err() != nil {
+			break
+		}
+
+		if err := op.verifyBackup(opctx.Set(ctx, opctx.Metadata{BackupID: string(id)}), id, bytesRead); err != nil {
+			op.Errors.Add(clues.Wrap(err, "verifying backup").With("backup_id", id))
+		}
+	}
+
+	op.Results.StartedAt = start
+	op.Results.CompletedAt = time.Now()
+	op.Results.BytesRead = bytesRead.NumBytes
+
+	op.Status = Completed
+
+	switch {
+	case op.Errors.Err() != nil:
+		op.Status = Failed
+	case op.Results.ItemsChecked == 0:
+		op.Status = NoData
+	}
+
+	logger.Ctx(ctx).Infow(
+		"completed verify",
+		"results", op.Results,
+		"recoverable_errors", op.Errors.Count())
+
+	return op.Errors.Err()
+}
+
+// verifyBackup checks the items, or a sample of the items, in a single
+// backup.
+func (op *VerifyOperation) verifyBackup(
+	ctx context.Context,
+	backupID model.StableID,
+	bytesRead *stats.ByteCounter,
+) error {
+	dID, bup, err := op.store.GetDetailsIDFromBackupID(ctx, backupID)
+	if err != nil {
+		return errors.Wrap(err, "getting backup details ID")
+	}
+
+	deets, err := streamstore.
+		New(op.kopia, op.account.ID(), bup.Selector.PathService()).
+		ReadBackupDetails(ctx, dID, op.Errors)
+	if err != nil {
+		return errors.Wrap(err, "getting backup details data")
+	}
+
+	var (
+		items   = sampleEntries(deets.Items(), op.Config.SampleSize, op.rand)
+		entries = make(map[string]details.DetailsEntry, len(items))
+		paths   = make([]path.Path, 0, len(items))
+	)
+
+	for _, ent := range items {
+		p, err := path.FromDataLayerPath(ent.RepoRef, true)
+		if err != nil {
+			op.corrupt(backupID, *ent, "invalid repoRef: "+err.Error())
+			continue
+		}
+
+		entries[p.String()] = *ent
+		paths = append(paths, p)
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	observe.Message(
+		ctx,
+		observe.Safe(fmt.Sprintf("Checking %d items in backup %s", len(paths), backupID)))
+
+	// missing items are reported as corruption, instead of failing the
+	// backup, so item errors are collected separately.
+	readErrs := fault.New(false)
+
+	dcs, err := op.kopia.RestoreMultipleItems(ctx, bup.SnapshotID, paths, bytesRead, readErrs)
+	if err != nil {
+		return errors.Wrap(err, "retrieving items from repository")
+	}
+
+	checked, corrupted := checkCollections(ctx, backupID, dcs, entries, op.Config.Scrub, readErrs)
+
+	op.Results.ItemsRead += checked - len(corrupted)
+	op.Results.ItemsChecked += checked
+	op.Results.Corrupted = append(op.Results.Corrupted, corrupted...)
+	op.Results.ResourceOwners++
+
+	return nil
+}
+
+func (op *VerifyOperation) corrupt(backupID model.StableID, ent details.DetailsEntry, reason string) {
+	op.Results.ItemsChecked++
+	op.Results.Corrupted = append(op.Results.Corrupted, CorruptItem{
+		BackupID: backupID,
+		ShortRef: ent.ShortRef,
+		RepoRef:  ent.RepoRef,
+		Reason:   reason,
+	})
+}
+
+// sampleEntries returns a random sample of at most n of the entries.
+// All entries are returned if n is less than 1.
+func sampleEntries(ents []*details.DetailsEntry, n int, rng *rand.Rand) []*details.DetailsEntry {
+	if n < 1 || n >= len(ents) {
+		return ents
+	}
+
+	sample := make([]*details.DetailsEntry, len(ents))
+	copy(sample, ents)
+
+	rng.Shuffle(len(sample), func(i, j int) {
+		sample[i], sample[j] = sample[j], sample[i]
+	})
+
+	return sample[:n]
+}
+
+// checkCollections reads every item in the collections, scrubbing each
+// item if requested, and reports the items which fail.  Entries whose
+// items aren't found in the collections are reported as missing.
+// Returns the count of items checked.
+func checkCollections(
+	ctx context.Context,
+	backupID model.StableID,
+	dcs []data.RestoreCollection,
+	entries map[string]details.DetailsEntry,
+	scrub bool,
+	errs *fault.Errors,
+) (int, []CorruptItem) {
+	var (
+		checked   int
+		corrupted []CorruptItem
+		seen      = make(map[string]struct{}, len(entries))
+	)
+
+	report := func(ent details.DetailsEntry, reason string) {
+		corrupted = append(corrupted, CorruptItem{
+			BackupID: backupID,
+			ShortRef: ent.ShortRef,
+			RepoRef:  ent.RepoRef,
+			Reason:   reason,
+		})
+	}
+
+	for _, dc := range dcs {
+		for item := range dc.Items(ctx, errs) {
+			ip, err := dc.FullPath().Append(item.UUID(), true)
+			if err != nil {
+				errs.Add(clues.Wrap(err, "building item path").WithClues(ctx))
+				continue
+			}
+
+			ent, ok := entries[ip.String()]
+			if !ok {
+				continue
+			}
+
+			seen[ip.String()] = struct{}{}
+			checked++
+
+			if err := checkItem(ent, item, scrub); err != nil {
+				report(ent, err.Error())
+			}
+		}
+	}
+
+	for ref, ent := range entries {
+		if _, ok := seen[ref]; !ok {
+			checked++
+
+			report(ent, "not retrievable from storage")
+		}
+	}
+
+	for _, err := range errs.Errs() {
+		logger.Ctx(ctx).
+			With("error", err).
+			With(clues.InErr(err).Slice()...).
+			Info("reading item for verification")
+	}
+
+	return checked, corrupted
+}
+
+// checkItem reads the item's data and, when scrubbing, checks that the data
+// can be deserialized.
+func checkItem(ent details.DetailsEntry, item data.Stream, scrub bool) error {
+	rc := item.ToReader()
+	defer rc.Close()
+
+	if !scrub {
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			return errors.Wrap(err, "unreadable")
+		}
+
+		return nil
+	}
+
+	return scrubItem(ent, rc)
+}
+
+// scrubItem deserializes the item's data according to the kind of item
+// described by its details entry.  Items without a known format only need
+// to be readable.
+func scrubItem(ent details.DetailsEntry, r io.Reader) error {
+	if drive := driveInfo(ent); drive != nil && !drive.IsMeta {
+		return scrubDriveFile(drive.ItemName, drive.Size, r)
+	}
+
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "unreadable")
+	}
+
+	var (
+		kind  string
+		parse func([]byte) error
+	)
+
+	switch {
+	case ent.Exchange != nil:
+		kind, parse = exchangeParser(ent.Exchange.ItemType)
+
+	case ent.SharePoint != nil || ent.OneDrive != nil:
+		// metadata files, lists, and pages.
+		kind, parse = driveParser(ent)
+	}
+
+	if parse == nil {
+		return nil
+	}
+
+	if err := parse(bs); err != nil {
+		return errors.Wrapf(err, "not a valid %s (%d bytes)", kind, len(bs))
+	}
+
+	return nil
+}
+
+func exchangeParser(it details.ItemType) (string, func([]byte) error) {
+	switch it {
+	case details.ExchangeMail:
+		return "mail message", func(bs []byte) error {
+			_, err := support.CreateMessageFromBytes(bs)
+			return err
+		}
+	case details.ExchangeContact:
+		return "contact", func(bs []byte) error {
+			_, err := support.CreateContactFromBytes(bs)
+			return err
+		}
+	case details.ExchangeEvent:
+		return "event", func(bs []byte) error {
+			_, err := support.CreateEventFromBytes(bs)
+			return err
+		}
+	case details.ExchangeTask:
+		return "task", func(bs []byte) error {
+			_, err := support.CreateTaskFromBytes(bs)
+			return err
+		}
+	}
+
+	return "", nil
+}
+
+func driveParser(ent details.DetailsEntry) (string, func([]byte) error) {
+	if drive := driveInfo(ent); drive != nil && drive.IsMeta {
+		return "metadata file", func(bs []byte) error {
+			if !json.Valid(bs) {
+				return errors.New("invalid json")
+			}
+
+			return nil
+		}
+	}
+
+	p, err := path.FromDataLayerPath(ent.RepoRef, true)
+	if err != nil {
+		return "", nil
+	}
+
+	switch p.Category() {
+	case path.ListsCategory:
+		return "list", func(bs []byte) error {
+			_, err := support.CreateListFromBytes(bs)
+			return err
+		}
+	case path.PagesCategory:
+		return "page", func(bs []byte) error {
+			_, err := support.CreatePageFromBytes(bs)
+			return err
+		}
+	}
+
+	return "", nil
+}
+
+// driveInfo returns the drive item info of the entry, if it describes a
+// OneDrive or SharePoint library file.
+func driveInfo(ent details.DetailsEntry) *details.OneDriveInfo {
+	switch {
+	case ent.OneDrive != nil:
+		info := *ent.OneDrive
+		// older backups didn't mark metadata files in their details.
+		info.IsMeta = info.IsMeta || isDriveMeta(ent.RepoRef)
+
+		return &info
+	case ent.SharePoint != nil && ent.SharePoint.ItemType == details.SharePointItem && len(ent.SharePoint.DriveName) > 0:
+		return &details.OneDriveInfo{
+			ItemName: ent.SharePoint.ItemName,
+			Size:     ent.SharePoint.Size,
+			IsMeta:   isDriveMeta(ent.RepoRef),
+		}
+	}
+
+	return nil
+}
+
+func isDriveMeta(repoRef string) bool {
+	return strings.HasSuffix(repoRef, ".meta") || strings.HasSuffix(repoRef, ".dirmeta")
+}
+
+// fileSignatures lists the leading bytes of the file formats which can be
+// identified by their extension.  Each format may have more than one
+// signature.
+var fileSignatures = map[string][]string{
+	".pdf":  {"%PDF-"},
+	".png":  {"\x89PNG\r\n\x1a\n"},
+	".jpg":  {"\xff\xd8\xff"},
+	".jpeg": {"\xff\xd8\xff"},
+	".gif":  {"GIF87a", "GIF89a"},
+	".zip":  {"PK\x03\x04", "PK\x05\x06"},
+	".docx": {"PK\x03\x04"},
+	".xlsx": {"PK\x03\x04"},
+	".pptx": {"PK\x03\x04"},
+	".doc":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".xls":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".ppt":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+	".msg":  {"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+}
+
+// scrubDriveFile checks that the file's size matches the size recorded in
+// the backup details, and that files in well-known formats begin with the
+// header of that format.
+func scrubDriveFile(name string, expectSize int64, r io.Reader) error {
+	header := make([]byte, 8)
+
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.Wrap(err, "unreadable")
+	}
+
+	header = header[:n]
+
+	rest, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return errors.Wrap(err, "unreadable")
+	}
+
+	if size := int64(n) + rest; size != expectSize {
+		return errors.Errorf("file holds %d bytes, but the backup details record %d bytes", size, expectSize)
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+
+	sigs, ok := fileSignatures[ext]
+	if !ok || n == 0 {
+		return nil
+	}
+
+	for _, sig := range sigs {
+		if bytes.HasPrefix(header, []byte(sig)) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("file content doesn't begin with a %s header", ext)
+}
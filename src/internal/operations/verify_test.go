@@ -0,0 +1,224 @@
+package operations
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/connector/mockconnector"
+	"github.com/alcionai/corso/src/internal/data"
+	evmock "github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+type VerifyOpSuite struct {
+	tester.Suite
+}
+
+func TestVerifyOpSuite(t *testing.T) {
+	suite.Run(t, &VerifyOpSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *VerifyOpSuite) TestNewVerifyOperation() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	newOp := func(ids []model.StableID, cfg control.VerifyConfig) error {
+		_, err := NewVerifyOperation(
+			ctx,
+			control.Options{},
+			&kopia.Wrapper{},
+			&store.Wrapper{},
+			account.Account{},
+			ids,
+			cfg,
+			evmock.NewBus())
+
+		return err
+	}
+
+	assert.Error(t, newOp(nil, control.VerifyConfig{}), "requires backup ids")
+	assert.Error(t, newOp([]model.StableID{"foo"}, control.VerifyConfig{SampleSize: -1}), "negative sample")
+	assert.NoError(t, newOp([]model.StableID{"foo"}, control.VerifyConfig{Scrub: true, SampleSize: 10}))
+}
+
+func (suite *VerifyOpSuite) TestSampleEntries() {
+	t := suite.T()
+	rng := rand.New(rand.NewSource(1))
+
+	ents := make([]*details.DetailsEntry, 10)
+	for i := range ents {
+		ents[i] = &details.DetailsEntry{ShortRef: string(rune('a' + i))}
+	}
+
+	assert.Len(t, sampleEntries(ents, 0, rng), 10, "no sample size")
+	assert.Len(t, sampleEntries(ents, 20, rng), 10, "sample larger than the entries")
+
+	sample := sampleEntries(ents, 3, rng)
+	require.Len(t, sample, 3)
+
+	seen := map[string]struct{}{}
+	for _, ent := range sample {
+		seen[ent.ShortRef] = struct{}{}
+	}
+
+	assert.Len(t, seen, 3, "no entry is sampled twice")
+	assert.Equal(t, "a", ents[0].ShortRef, "sampling leaves the entries intact")
+}
+
+func (suite *VerifyOpSuite) TestScrubItem() {
+	var (
+		mail = details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail}}
+		pdf  = func(size int64) details.ItemInfo {
+			return details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				ItemType: details.OneDriveItem,
+				ItemName: "report.PDF",
+				Size:     size,
+			}}
+		}
+		meta = details.ItemInfo{OneDrive: &details.OneDriveInfo{ItemType: details.OneDriveItem, IsMeta: true}}
+	)
+
+	table := []struct {
+		name   string
+		info   details.ItemInfo
+		data   []byte
+		expect assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "mail",
+			info:   mail,
+			data:   mockconnector.GetMockMessageBytes("scrub"),
+			expect: assert.NoError,
+		},
+		{
+			name:   "garbage mail",
+			info:   mail,
+			data:   []byte("\x00\x01garbage"),
+			expect: assert.Error,
+		},
+		{
+			name:   "contact",
+			info:   details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeContact}},
+			data:   mockconnector.GetMockContactBytes("scrub"),
+			expect: assert.NoError,
+		},
+		{
+			name:   "truncated contact",
+			info:   details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeContact}},
+			data:   mockconnector.GetMockContactBytes("scrub")[:20],
+			expect: assert.Error,
+		},
+		{
+			name:   "file",
+			info:   pdf(9),
+			data:   []byte("%PDF-1.7\n"),
+			expect: assert.NoError,
+		},
+		{
+			name:   "file with the wrong header",
+			info:   pdf(9),
+			data:   []byte("garbage!\n"),
+			expect: assert.Error,
+		},
+		{
+			name:   "file with the wrong size",
+			info:   pdf(100),
+			data:   []byte("%PDF-1.7\n"),
+			expect: assert.Error,
+		},
+		{
+			name:   "empty file",
+			info:   pdf(0),
+			expect: assert.NoError,
+		},
+		{
+			name:   "metadata",
+			info:   meta,
+			data:   []byte(`{"filename":"report.pdf"}`),
+			expect: assert.NoError,
+		},
+		{
+			name:   "garbage metadata",
+			info:   meta,
+			data:   []byte(`{"filename":`),
+			expect: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ent := details.DetailsEntry{RepoRef: "ref", ItemInfo: test.info}
+			test.expect(suite.T(), scrubItem(ent, bytes.NewReader(test.data)))
+		})
+	}
+}
+
+func (suite *VerifyOpSuite) TestCheckCollections() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	fp, err := path.Builder{}.
+		Append("inboxID").
+		ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, false)
+	require.NoError(t, err)
+
+	var (
+		coll    = mockconnector.NewMockExchangeCollection(fp, nil, 2)
+		entries = map[string]details.DetailsEntry{}
+		info    = details.ItemInfo{Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail}}
+	)
+
+	// the second item is corrupted.
+	coll.Data[1] = []byte("garbage")
+
+	for _, name := range append(coll.Names, "missing") {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		entries[ip.String()] = details.DetailsEntry{RepoRef: ip.String(), ShortRef: name, ItemInfo: info}
+	}
+
+	dcs := []data.RestoreCollection{data.NotFoundRestoreCollection{Collection: coll}}
+
+	checked, corrupted := checkCollections(ctx, "bid", dcs, entries, false, fault.New(false))
+	assert.Equal(t, 3, checked, "items checked")
+	require.Len(t, corrupted, 1, "only the missing item is caught without scrubbing")
+	assert.Equal(t, "missing", corrupted[0].ShortRef)
+	assert.Equal(t, model.StableID("bid"), corrupted[0].BackupID)
+
+	coll = mockconnector.NewMockExchangeCollection(fp, nil, 2)
+	coll.Data[1] = []byte("garbage")
+
+	// the mock produces new item names, so the entries are rebuilt.
+	entries = map[string]details.DetailsEntry{}
+
+	for _, name := range coll.Names {
+		ip, err := fp.Append(name, true)
+		require.NoError(t, err)
+
+		entries[ip.String()] = details.DetailsEntry{RepoRef: ip.String(), ShortRef: name, ItemInfo: info}
+	}
+
+	dcs = []data.RestoreCollection{data.NotFoundRestoreCollection{Collection: coll}}
+
+	checked, corrupted = checkCollections(ctx, "bid", dcs, entries, true, fault.New(false))
+	assert.Equal(t, 2, checked, "items checked")
+	require.Len(t, corrupted, 1, "scrubbing catches the corrupted item")
+	assert.Equal(t, coll.Names[1], corrupted[0].ShortRef)
+}
@@ -0,0 +1,258 @@
+// Package spill provides a disk-backed buffer for item data that is waiting
+// to be uploaded.  Spilling items to disk keeps memory usage flat when items
+// are retrieved from M365 faster than they can be persisted to the repository.
+package spill
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+)
+
+const (
+	keySize = 32
+	// growth is the capacity claimed each time an item outgrows its
+	// reservation.
+	growth = 1 << 20
+)
+
+// Buffer spills item data into files within a private temporary directory.
+// File contents are encrypted with a key that only lives in memory for the
+// lifetime of the buffer, so spilled data is unreadable once the buffer is
+// discarded.  The total size of all spilled files is bounded by the buffer's
+// byte limit.
+type Buffer struct {
+	dir      string
+	maxBytes int64
+	used     int64 // atomic
+	key      []byte
+}
+
+// New creates a buffer within a new temporary directory under root.  If root
+// is empty, the default directory for temporary files is used.  maxBytes
+// bounds the combined size of the spilled data; items that don't fit within
+// the remaining space are not spilled.
+func New(root string, maxBytes int64) (*Buffer, error) {
+	if maxBytes <= 0 {
+		return nil, clues.New("spill buffer requires a positive size limit")
+	}
+
+	dir, err := os.MkdirTemp(root, "corso-spill-*")
+	if err != nil {
+		return nil, clues.Wrap(err, "creating spill directory")
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		os.RemoveAll(dir)
+		return nil, clues.Wrap(err, "generating spill key")
+	}
+
+	return &Buffer{
+		dir:      dir,
+		maxBytes: maxBytes,
+		key:      key,
+	}, nil
+}
+
+// Dir returns the directory containing the spilled data.
+func (b *Buffer) Dir() string {
+	return b.dir
+}
+
+// Used returns the number of bytes currently spilled to disk.
+func (b *Buffer) Used() int64 {
+	return atomic.LoadInt64(&b.used)
+}
+
+// Close removes the buffer directory along with any spilled data that has
+// not yet been read.
+func (b *Buffer) Close() error {
+	if err := os.RemoveAll(b.dir); err != nil {
+		return clues.Wrap(err, "removing spill directory")
+	}
+
+	return nil
+}
+
+// reserve claims size bytes of the buffer's capacity.  Returns false if the
+// buffer lacks the space.
+func (b *Buffer) reserve(size int64) bool {
+	for {
+		used := atomic.LoadInt64(&b.used)
+		if used+size > b.maxBytes {
+			return false
+		}
+
+		if atomic.CompareAndSwapInt64(&b.used, used, used+size) {
+			return true
+		}
+	}
+}
+
+func (b *Buffer) release(size int64) {
+	atomic.AddInt64(&b.used, -size)
+}
+
+// Spill copies the contents of r, which is expected to hold size bytes, into
+// the buffer.  The returned reader produces the original contents, and frees
+// the spilled data when closed.  If the buffer is nil, or lacks the capacity
+// to hold the item, r is left unread and Spill returns false.  Items that
+// turn out larger than size claim more capacity as they're written, and
+// produce an error, after r was partially read, if the buffer runs out.
+func (b *Buffer) Spill(ctx context.Context, r io.Reader, size int64) (io.ReadCloser, bool, error) {
+	if b == nil || size < 0 || !b.reserve(size) {
+		return nil, false, nil
+	}
+
+	rc, reserved, err := b.spill(r, size)
+	if err != nil {
+		b.release(reserved)
+		return nil, false, clues.Stack(err).WithClues(ctx)
+	}
+
+	return rc, true, nil
+}
+
+// spill writes the item to a spill file.  Returns the capacity reserved
+// for the item, which grows beyond size if the item holds more data than
+// expected.
+func (b *Buffer) spill(r io.Reader, size int64) (io.ReadCloser, int64, error) {
+	f, err := os.CreateTemp(b.dir, "item-*")
+	if err != nil {
+		return nil, size, clues.Wrap(err, "creating spill file")
+	}
+
+	block, err := aes.NewCipher(b.key)
+	if err != nil {
+		return nil, size, discard(f, clues.Wrap(err, "initializing spill cipher"))
+	}
+
+	iv := make([]byte, block.BlockSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, size, discard(f, clues.Wrap(err, "generating spill iv"))
+	}
+
+	w := cipher.StreamWriter{S: cipher.NewCTR(block, iv), W: f}
+
+	written, reserved, err := b.copyReserved(w, r, size)
+	if err != nil {
+		return nil, reserved, discard(f, clues.Wrap(err, "writing spill file"))
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, reserved, discard(f, clues.Wrap(err, "rewinding spill file"))
+	}
+
+	// release the capacity reserved beyond the item's actual size.
+	b.release(reserved - written)
+
+	return &spilled{
+		Reader: cipher.StreamReader{S: cipher.NewCTR(block, iv), R: f},
+		f:      f,
+		buf:    b,
+		size:   written,
+	}, written, nil
+}
+
+// copyReserved copies r into w without exceeding the capacity reserved
+// for it.  Items that outgrow their reservation claim more capacity before
+// more of their data is written, and fail if the buffer lacks the space.
+// Returns the number of bytes written and the capacity reserved.
+func (b *Buffer) copyReserved(w io.Writer, r io.Reader, size int64) (int64, int64, error) {
+	var (
+		br       = bufio.NewReader(r)
+		written  int64
+		reserved = size
+	)
+
+	for {
+		n, err := io.CopyN(w, br, reserved-written)
+		written += n
+
+		if errors.Is(err, io.EOF) {
+			return written, reserved, nil
+		}
+
+		if err != nil {
+			return written, reserved, err
+		}
+
+		// the reservation is full; only claim more capacity if the item
+		// holds more data.
+		if _, err := br.Peek(1); err != nil {
+			if errors.Is(err, io.EOF) {
+				return written, reserved, nil
+			}
+
+			return written, reserved, err
+		}
+
+		if !b.reserve(growth) {
+			return written, reserved, clues.New("item exceeds the spill buffer's capacity").
+				With("expected_size", size, "written_size", written)
+		}
+
+		reserved += growth
+	}
+}
+
+// discard closes and removes the file, returning err.
+func discard(f *os.File, err error) error {
+	f.Close()
+	os.Remove(f.Name())
+
+	return err
+}
+
+var _ io.ReadCloser = &spilled{}
+
+// spilled reads a decrypted item from its spill file.
+type spilled struct {
+	io.Reader
+	f    *os.File
+	buf  *Buffer
+	size int64
+	once sync.Once
+}
+
+// Close removes the spill file and releases its capacity in the buffer.
+func (s *spilled) Close() error {
+	s.once.Do(func() {
+		_ = discard(s.f, nil)
+		s.buf.release(s.size)
+	})
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type spillKey struct{}
+
+// Set embeds the buffer within the context.
+func Set(ctx context.Context, b *Buffer) context.Context {
+	if b == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, spillKey{}, b)
+}
+
+// Ctx retrieves the buffer embedded in the context.  Returns nil if no buffer
+// was set, which is safe to Spill into.
+func Ctx(ctx context.Context) *Buffer {
+	b, _ := ctx.Value(spillKey{}).(*Buffer)
+	return b
+}
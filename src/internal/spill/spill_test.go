@@ -0,0 +1,221 @@
+package spill_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/spill"
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type SpillUnitSuite struct {
+	tester.Suite
+}
+
+func TestSpillUnitSuite(t *testing.T) {
+	suite.Run(t, &SpillUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func spilledFiles(t *testing.T, b *spill.Buffer) []string {
+	fs, err := filepath.Glob(filepath.Join(b.Dir(), "*"))
+	require.NoError(t, err)
+
+	return fs
+}
+
+func (suite *SpillUnitSuite) TestNew_requiresLimit() {
+	_, err := spill.New(suite.T().TempDir(), 0)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SpillUnitSuite) TestSpill_roundTrip() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	content := []byte("some item content that gets spilled to disk")
+
+	b, err := spill.New(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	rc, spilled, err := b.Spill(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.True(t, spilled)
+	assert.Equal(t, int64(len(content)), b.Used())
+
+	fs := spilledFiles(t, b)
+	require.Len(t, fs, 1)
+
+	// data at rest must not match the original content.
+	onDisk, err := os.ReadFile(fs[0])
+	require.NoError(t, err)
+	assert.NotEqual(t, content, onDisk)
+
+	result, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+
+	require.NoError(t, rc.Close())
+	assert.Zero(t, b.Used())
+	assert.Empty(t, spilledFiles(t, b))
+
+	// closing more than once is safe
+	require.NoError(t, rc.Close())
+	assert.Zero(t, b.Used())
+}
+
+func (suite *SpillUnitSuite) TestSpill_capacity() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	content := []byte("0123456789")
+
+	b, err := spill.New(t.TempDir(), 15)
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	rc, spilled, err := b.Spill(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.True(t, spilled)
+
+	r := bytes.NewReader(content)
+
+	_, spilled, err = b.Spill(ctx, r, int64(len(content)))
+	require.NoError(t, err)
+	assert.False(t, spilled)
+	assert.Equal(t, len(content), r.Len(), "reader should be left unread")
+
+	// freeing the first item makes room for another.
+	require.NoError(t, rc.Close())
+
+	rc, spilled, err = b.Spill(ctx, r, int64(len(content)))
+	require.NoError(t, err)
+	assert.True(t, spilled)
+	require.NoError(t, rc.Close())
+}
+
+func (suite *SpillUnitSuite) TestSpill_unexpectedSize() {
+	table := []struct {
+		name      string
+		limit     int64
+		content   []byte
+		size      int64
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "smaller than expected",
+			limit:     100,
+			content:   bytes.Repeat([]byte("a"), 10),
+			size:      20,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "larger than expected, within capacity",
+			limit:     4 << 20,
+			content:   bytes.Repeat([]byte("a"), 20),
+			size:      10,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "larger than capacity",
+			limit:     100,
+			content:   bytes.Repeat([]byte("a"), 200),
+			size:      10,
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			b, err := spill.New(t.TempDir(), test.limit)
+			require.NoError(t, err)
+
+			defer b.Close()
+
+			rc, spilled, err := b.Spill(ctx, bytes.NewReader(test.content), test.size)
+			test.expectErr(t, err)
+
+			if err != nil {
+				assert.False(t, spilled)
+				assert.Zero(t, b.Used(), "capacity released")
+				assert.Empty(t, spilledFiles(t, b))
+
+				return
+			}
+
+			require.True(t, spilled)
+			assert.Equal(t, int64(len(test.content)), b.Used())
+
+			result, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, test.content, result)
+
+			require.NoError(t, rc.Close())
+			assert.Zero(t, b.Used())
+		})
+	}
+}
+
+func (suite *SpillUnitSuite) TestSpill_nilBuffer() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	b := spill.Ctx(ctx)
+	require.Nil(t, b)
+
+	rc, spilled, err := b.Spill(ctx, bytes.NewReader([]byte("a")), 1)
+	require.NoError(t, err)
+	assert.False(t, spilled)
+	assert.Nil(t, rc)
+}
+
+func (suite *SpillUnitSuite) TestClose() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	b, err := spill.New(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	_, spilled, err := b.Spill(ctx, bytes.NewReader([]byte("abc")), 3)
+	require.NoError(t, err)
+	require.True(t, spilled)
+
+	require.NoError(t, b.Close())
+
+	_, err = os.Stat(b.Dir())
+	assert.True(t, os.IsNotExist(err), "spill directory should be removed")
+}
+
+func (suite *SpillUnitSuite) TestSetCtx() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	b, err := spill.New(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	assert.Nil(t, spill.Ctx(ctx))
+	assert.Equal(t, ctx, spill.Set(ctx, nil))
+	assert.Equal(t, b, spill.Ctx(spill.Set(ctx, b)))
+}
@@ -0,0 +1,575 @@
+// Package stall watches the collections of a long-running operation.  A
+// watchdog periodically emits heartbeat events describing each collection's
+// progress, and flags collections whose items stop making progress.  A
+// stalled collection gets its goroutines and in-flight graph calls dumped to
+// the log and, if configured, gets aborted so that the rest of the backup
+// can complete instead of hanging behind it.
+package stall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+// ErrStalled is recorded for each collection that gets aborted after its
+// items stop making progress.
+var ErrStalled = clues.New("collection stalled")
+
+// stall checks run at least this often relative to the stall timeout, so
+// that a stall is caught shortly after it passes the timeout.
+const checksPerStall = 4
+
+// Watchdog tracks the progress of an operation's collections.
+type Watchdog struct {
+	bus        events.Eventer
+	interval   time.Duration
+	stallAfter time.Duration
+	abort      bool
+	tick       time.Duration
+	now        func() time.Time
+	// dump writes the diagnostics that accompany a stall.
+	dump func(ctx context.Context)
+
+	mu          sync.Mutex
+	collections map[*Tracker]struct{}
+	lastBeat    time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a watchdog that reports to the bus according to the options.
+func New(bus events.Eventer, opts control.HeartbeatOptions) (*Watchdog, error) {
+	if !opts.Enabled() {
+		return nil, clues.New("watchdog requires a heartbeat interval or stall timeout")
+	}
+
+	tick := opts.Interval
+
+	if opts.StallAfter > 0 {
+		check := opts.StallAfter / checksPerStall
+		if tick <= 0 || check < tick {
+			tick = check
+		}
+	}
+
+	if tick <= 0 {
+		return nil, clues.New("watchdog intervals are too small")
+	}
+
+	return &Watchdog{
+		bus:         bus,
+		interval:    opts.Interval,
+		stallAfter:  opts.StallAfter,
+		abort:       opts.AbortStalled,
+		tick:        tick,
+		now:         time.Now,
+		dump:        dumpGoroutines,
+		collections: map[*Tracker]struct{}{},
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Start begins watching the tracked collections.  Stop must be called to
+// release the watchdog.
+func (w *Watchdog) Start(ctx context.Context) {
+	w.lastBeat = w.now()
+
+	go func() {
+		defer close(w.done)
+
+		t := time.NewTicker(w.tick)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				w.check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the watch.
+func (w *Watchdog) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+}
+
+// Track begins tracking the progress of a collection.  The returned ctx
+// must be used to produce the collection's items: it gets canceled if the
+// collection is aborted.  Done must be called on the tracker once the
+// collection is complete.  A nil watchdog returns the ctx and a nil
+// tracker, which is safe to use.
+func (w *Watchdog) Track(ctx context.Context, folder string) (context.Context, *Tracker) {
+	if w == nil {
+		return ctx, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	t := &Tracker{
+		w:            w,
+		folder:       folder,
+		cancel:       cancel,
+		lastProgress: w.now(),
+		calls:        map[*call]struct{}{},
+	}
+
+	w.mu.Lock()
+	w.collections[t] = struct{}{}
+	w.mu.Unlock()
+
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// check emits a heartbeat if one is due, and handles any collection that
+// has stalled since the last check.
+func (w *Watchdog) check(ctx context.Context) {
+	now := w.now()
+
+	w.mu.Lock()
+
+	progress := make([]Progress, 0, len(w.collections))
+	stalled := []*Tracker{}
+
+	for t := range w.collections {
+		p := t.progress(now)
+		progress = append(progress, p)
+
+		if w.stallAfter > 0 && t.markStalled(now, w.stallAfter) {
+			stalled = append(stalled, t)
+		}
+	}
+
+	beat := w.interval > 0 && now.Sub(w.lastBeat) >= w.interval
+	if beat {
+		w.lastBeat = now
+	}
+
+	w.mu.Unlock()
+
+	sort.Slice(progress, func(i, j int) bool { return progress[i].Folder < progress[j].Folder })
+
+	if beat {
+		w.heartbeat(ctx, progress)
+	}
+
+	if len(stalled) == 0 {
+		return
+	}
+
+	for _, t := range stalled {
+		w.stalled(ctx, t, now)
+	}
+
+	w.dump(ctx)
+}
+
+// heartbeat reports the progress of all tracked collections.
+func (w *Watchdog) heartbeat(ctx context.Context, progress []Progress) {
+	var (
+		items       int
+		collections = make([]map[string]any, 0, len(progress))
+	)
+
+	for _, p := range progress {
+		items += p.Items
+
+		logger.Ctx(ctx).Infow(
+			"collection heartbeat",
+			"folder", logger.PII(p.Folder),
+			"items", p.Items,
+			"active_items", p.Active,
+			"idle", p.Idle)
+
+		collections = append(collections, map[string]any{
+			events.Folder:      logger.Conceal(p.Folder),
+			events.ItemsRead:   p.Items,
+			events.ActiveItems: p.Active,
+			events.IdleTime:    p.Idle.String(),
+		})
+	}
+
+	w.bus.Event(
+		ctx,
+		events.BackupHeartbeat,
+		map[string]any{
+			events.Collections: collections,
+			events.ItemsRead:   items,
+		})
+}
+
+// stalled reports a stalled collection, and aborts it if configured.
+func (w *Watchdog) stalled(ctx context.Context, t *Tracker, now time.Time) {
+	p := t.progress(now)
+
+	log := logger.Ctx(ctx).With(
+		"folder", logger.PII(p.Folder),
+		"items", p.Items,
+		"active_items", p.Active,
+		"idle", p.Idle,
+		"abort", w.abort)
+
+	log.Warnw("collection stalled")
+
+	for _, c := range t.inFlight(now) {
+		log.Infow(
+			"stalled graph call",
+			"method", c.Method,
+			"url", logger.PII(c.URL),
+			"duration", c.Duration)
+	}
+
+	w.bus.Event(
+		ctx,
+		events.BackupStall,
+		map[string]any{
+			events.Folder:    logger.Conceal(p.Folder),
+			events.ItemsRead: p.Items,
+			events.IdleTime:  p.Idle.String(),
+		})
+
+	if w.abort {
+		t.abort()
+	}
+}
+
+// dumpGoroutines writes the stacks of all goroutines to the log.
+func dumpGoroutines(ctx context.Context) {
+	var buf bytes.Buffer
+
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		logger.Ctx(ctx).With("err", err).Debugw("dumping goroutines")
+		return
+	}
+
+	logger.Ctx(ctx).Infow("goroutine dump", "goroutines", buf.String())
+}
+
+// ---------------------------------------------------------------------------
+// collection tracking
+// ---------------------------------------------------------------------------
+
+// Progress describes a collection at the time of a check.
+type Progress struct {
+	Folder string
+	// Items is the count of items handled so far.
+	Items int
+	// Active is the count of items being retrieved.
+	Active int
+	// Idle is the time since any of the collection's items made progress.
+	Idle time.Duration
+}
+
+// Tracker records the progress of a single collection.  All methods are
+// safe to call on a nil tracker.
+type Tracker struct {
+	w      *Watchdog
+	folder string
+	cancel context.CancelFunc
+
+	mu           sync.Mutex
+	items        int
+	active       int
+	lastProgress time.Time
+	stalled      bool
+	aborted      bool
+	calls        map[*call]struct{}
+}
+
+// Begin records that an item is being retrieved.  The returned func must
+// be called once the item is handled.
+func (t *Tracker) Begin() func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.active++
+	t.touch()
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.active--
+		t.items++
+		t.touch()
+	}
+}
+
+// Wait records that an item is waiting for its stream to be consumed.
+// Waiting items aren't expected to make progress, and so can't stall the
+// collection.  The returned func must be called once the wait is over.
+func (t *Tracker) Wait() func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.active--
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.active++
+		t.touch()
+	}
+}
+
+// Aborted returns true if the watchdog aborted the collection.
+func (t *Tracker) Aborted() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.aborted
+}
+
+// Done stops tracking the collection.  The tracked ctx is left alive,
+// since lazily produced items may use it after the collection is done.
+func (t *Tracker) Done() {
+	if t == nil {
+		return
+	}
+
+	t.w.mu.Lock()
+	delete(t.w.collections, t)
+	t.w.mu.Unlock()
+}
+
+// touch records progress.  Callers must hold the lock.
+func (t *Tracker) touch() {
+	t.lastProgress = t.w.now()
+	t.stalled = false
+}
+
+func (t *Tracker) progress(now time.Time) Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return Progress{
+		Folder: t.folder,
+		Items:  t.items,
+		Active: t.active,
+		Idle:   now.Sub(t.lastProgress),
+	}
+}
+
+// markStalled returns true the first time the collection is seen to have
+// items in flight that made no progress within the stall timeout.
+func (t *Tracker) markStalled(now time.Time, after time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stalled || t.aborted || t.active <= 0 || now.Sub(t.lastProgress) < after {
+		return false
+	}
+
+	t.stalled = true
+
+	return true
+}
+
+func (t *Tracker) abort() {
+	t.mu.Lock()
+	t.aborted = true
+	t.mu.Unlock()
+
+	t.cancel()
+}
+
+// ---------------------------------------------------------------------------
+// lazy reads
+// ---------------------------------------------------------------------------
+
+// TrackRead tracks an item that gets downloaded lazily, after its
+// collection is done, when the consumer reads it.  The returned ctx must be
+// used to download the item: it gets canceled if the read stalls.  The
+// reader produced by the tracker's Wrap stops tracking the read once it gets
+// closed; if the download fails before then, Done must be called instead.
+// A nil watchdog returns the ctx and a nil tracker, which is safe to use.
+func (w *Watchdog) TrackRead(ctx context.Context, folder string) (context.Context, *ReadTracker) {
+	if w == nil {
+		return ctx, nil
+	}
+
+	ctx, t := w.Track(ctx, folder)
+
+	return ctx, &ReadTracker{t: t, end: t.Begin()}
+}
+
+// ReadTracker tracks a single lazily downloaded item.
+type ReadTracker struct {
+	t    *Tracker
+	end  func()
+	once sync.Once
+}
+
+// Wrap produces a reader that records progress on every read, and stops
+// tracking the item once closed.
+func (rt *ReadTracker) Wrap(rc io.ReadCloser) io.ReadCloser {
+	if rt == nil {
+		return rc
+	}
+
+	return &trackedReader{ReadCloser: rc, rt: rt}
+}
+
+// Done stops tracking the item.
+func (rt *ReadTracker) Done() {
+	if rt == nil {
+		return
+	}
+
+	rt.once.Do(func() {
+		rt.end()
+		rt.t.Done()
+	})
+}
+
+type trackedReader struct {
+	io.ReadCloser
+	rt *ReadTracker
+}
+
+func (tr *trackedReader) Read(p []byte) (int, error) {
+	n, err := tr.ReadCloser.Read(p)
+	if n > 0 {
+		tr.rt.t.read()
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) && tr.rt.t.Aborted() {
+		err = clues.Stack(ErrStalled, err)
+	}
+
+	return n, err
+}
+
+func (tr *trackedReader) Close() error {
+	tr.rt.Done()
+	return tr.ReadCloser.Close()
+}
+
+// read records that the item made progress.
+func (t *Tracker) read() {
+	t.mu.Lock()
+	t.touch()
+	t.mu.Unlock()
+}
+
+// ---------------------------------------------------------------------------
+// graph calls
+// ---------------------------------------------------------------------------
+
+type call struct {
+	method  string
+	url     string
+	started time.Time
+}
+
+// Call describes a graph call that was in flight at the time of a check.
+type Call struct {
+	Method   string
+	URL      string
+	Duration time.Duration
+}
+
+// TrackCall records a graph call made on behalf of the collection tracked
+// in the ctx, so that the call can be reported if the collection stalls.
+// The returned func must be called once the call completes.
+func TrackCall(ctx context.Context, method, url string) func() {
+	t := trackerCtx(ctx)
+	if t == nil {
+		return func() {}
+	}
+
+	c := &call{method: method, url: url, started: t.w.now()}
+
+	t.mu.Lock()
+	t.calls[c] = struct{}{}
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.calls, c)
+		t.mu.Unlock()
+	}
+}
+
+// inFlight returns the collection's in-flight calls, oldest first.
+func (t *Tracker) inFlight(now time.Time) []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	calls := make([]Call, 0, len(t.calls))
+
+	for c := range t.calls {
+		calls = append(calls, Call{
+			Method:   c.method,
+			URL:      c.url,
+			Duration: now.Sub(c.started),
+		})
+	}
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Duration > calls[j].Duration })
+
+	return calls
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type (
+	watchdogKey struct{}
+	trackerKey  struct{}
+)
+
+// Set embeds the watchdog within the context.
+func Set(ctx context.Context, w *Watchdog) context.Context {
+	if w == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, watchdogKey{}, w)
+}
+
+// Ctx retrieves the watchdog embedded in the context.  Returns nil if no
+// watchdog was set, which is safe to Track.
+func Ctx(ctx context.Context) *Watchdog {
+	w, _ := ctx.Value(watchdogKey{}).(*Watchdog)
+	return w
+}
+
+func trackerCtx(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
@@ -0,0 +1,286 @@
+package stall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/events"
+	"github.com/alcionai/corso/src/internal/events/mock"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type StallUnitSuite struct {
+	tester.Suite
+}
+
+func TestStallUnitSuite(t *testing.T) {
+	suite.Run(t, &StallUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// newTestWatchdog produces a watchdog on a fake clock, which the test
+// advances by calling the returned func.
+func newTestWatchdog(
+	t *testing.T,
+	opts control.HeartbeatOptions,
+) (*Watchdog, *mock.Bus, *int, func(time.Duration)) {
+	bus := mock.NewBus()
+
+	w, err := New(bus, opts)
+	require.NoError(t, err)
+
+	var (
+		now   = time.Now()
+		dumps int
+	)
+
+	w.now = func() time.Time { return now }
+	w.dump = func(context.Context) { dumps++ }
+	w.lastBeat = now
+
+	return w, bus, &dumps, func(d time.Duration) { now = now.Add(d) }
+}
+
+func (suite *StallUnitSuite) TestNew() {
+	table := []struct {
+		name      string
+		opts      control.HeartbeatOptions
+		expect    time.Duration
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "disabled",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "heartbeat only",
+			opts:      control.HeartbeatOptions{Interval: time.Minute},
+			expect:    time.Minute,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "stall only",
+			opts:      control.HeartbeatOptions{StallAfter: time.Minute},
+			expect:    15 * time.Second,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "stalls checked more often than heartbeats",
+			opts:      control.HeartbeatOptions{Interval: time.Hour, StallAfter: time.Minute},
+			expect:    15 * time.Second,
+			expectErr: assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			w, err := New(mock.NewBus(), test.opts)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expect, w.tick)
+		})
+	}
+}
+
+func (suite *StallUnitSuite) TestHeartbeat() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, bus, _, advance := newTestWatchdog(t, control.HeartbeatOptions{Interval: time.Minute})
+
+	_, inbox := w.Track(ctx, "inbox")
+	_, sent := w.Track(ctx, "sent")
+
+	inbox.Begin()()
+	inbox.Begin()()
+	sent.Begin()()
+
+	advance(30 * time.Second)
+	w.check(ctx)
+	assert.Zero(t, bus.TimesCalled[events.BackupHeartbeat], "heartbeat before the interval")
+
+	advance(30 * time.Second)
+	w.check(ctx)
+	require.Equal(t, 1, bus.TimesCalled[events.BackupHeartbeat])
+
+	data := bus.CalledWith[events.BackupHeartbeat][0]
+	assert.Equal(t, 3, data[events.ItemsRead])
+	assert.Len(t, data[events.Collections], 2)
+
+	sent.Done()
+	advance(time.Minute)
+	w.check(ctx)
+	require.Equal(t, 2, bus.TimesCalled[events.BackupHeartbeat])
+
+	data = bus.CalledWith[events.BackupHeartbeat][1]
+	assert.Equal(t, 2, data[events.ItemsRead])
+	assert.Len(t, data[events.Collections], 1)
+}
+
+func (suite *StallUnitSuite) TestStall() {
+	table := []struct {
+		name  string
+		abort bool
+	}{
+		{name: "report only"},
+		{name: "abort", abort: true},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			w, bus, dumps, advance := newTestWatchdog(
+				t,
+				control.HeartbeatOptions{StallAfter: time.Minute, AbortStalled: test.abort})
+
+			tctx, tr := w.Track(ctx, "inbox")
+
+			// idle collections, with no items in flight, never stall.
+			advance(2 * time.Minute)
+			w.check(ctx)
+			assert.Zero(t, bus.TimesCalled[events.BackupStall], "idle collection")
+
+			done := tr.Begin()
+			callDone := TrackCall(tctx, "GET", "https://graph.microsoft.com/v1.0/users")
+
+			advance(30 * time.Second)
+			w.check(ctx)
+			assert.Zero(t, bus.TimesCalled[events.BackupStall], "before the stall timeout")
+
+			// items waiting on the consumer aren't stalled.
+			resume := tr.Wait()
+
+			advance(2 * time.Minute)
+			w.check(ctx)
+			assert.Zero(t, bus.TimesCalled[events.BackupStall], "waiting on the consumer")
+
+			resume()
+
+			advance(time.Minute)
+			assert.Len(t, tr.inFlight(w.now()), 1)
+			w.check(ctx)
+			assert.Equal(t, 1, bus.TimesCalled[events.BackupStall])
+			assert.Equal(t, 1, *dumps)
+			assert.Equal(t, test.abort, tr.Aborted())
+
+			if test.abort {
+				assert.ErrorIs(t, tctx.Err(), context.Canceled)
+			} else {
+				assert.NoError(t, tctx.Err())
+			}
+
+			// stalls are only reported once.
+			advance(time.Minute)
+			w.check(ctx)
+			assert.Equal(t, 1, bus.TimesCalled[events.BackupStall])
+
+			callDone()
+			done()
+			tr.Done()
+
+			assert.Empty(t, tr.inFlight(w.now()))
+			assert.Empty(t, w.collections)
+		})
+	}
+}
+
+func (suite *StallUnitSuite) TestNilWatchdog() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w := Ctx(ctx)
+	assert.Nil(t, w)
+
+	tctx, tr := w.Track(ctx, "inbox")
+	assert.Equal(t, ctx, tctx)
+	assert.Nil(t, tr)
+
+	tr.Begin()()
+	tr.Wait()()
+
+	rctx, rt := w.TrackRead(ctx, "drive")
+	assert.Equal(t, ctx, rctx)
+	assert.Nil(t, rt)
+	rt.Done()
+	TrackCall(tctx, "GET", "url")()
+	assert.False(t, tr.Aborted())
+	tr.Done()
+}
+
+type stuckReader struct {
+	ctx  context.Context
+	read chan struct{}
+}
+
+func (r *stuckReader) Read(p []byte) (int, error) {
+	select {
+	case r.read <- struct{}{}:
+		return len(p), nil
+	case <-r.ctx.Done():
+		return 0, r.ctx.Err()
+	}
+}
+
+func (r *stuckReader) Close() error { return nil }
+
+func (suite *StallUnitSuite) TestTrackRead() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	w, bus, _, advance := newTestWatchdog(
+		t,
+		control.HeartbeatOptions{StallAfter: time.Minute, AbortStalled: true})
+
+	rctx, rt := w.TrackRead(ctx, "drive")
+	assert.Len(t, w.collections, 1)
+
+	sr := &stuckReader{ctx: rctx, read: make(chan struct{}, 1)}
+	rc := rt.Wrap(sr)
+
+	// reads are progress.
+	advance(50 * time.Second)
+
+	_, err := rc.Read(make([]byte, 1))
+	require.NoError(t, err)
+
+	advance(50 * time.Second)
+	w.check(ctx)
+	assert.Zero(t, bus.TimesCalled[events.BackupStall], "read within the stall timeout")
+
+	advance(time.Minute)
+	w.check(ctx)
+	assert.Equal(t, 1, bus.TimesCalled[events.BackupStall])
+	assert.ErrorIs(t, rctx.Err(), context.Canceled)
+
+	// the first read filled the reader's buffer, so the next one is stuck
+	// until the ctx gets canceled.
+	_, err = rc.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrStalled)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, rc.Close())
+	assert.Empty(t, w.collections)
+
+	// closing again, or calling Done, is safe.
+	rt.Done()
+	require.NoError(t, rc.Close())
+}
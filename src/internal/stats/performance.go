@@ -0,0 +1,187 @@
+package stats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// SlowestItemsLimit is the number of slowest items kept by a
+	// PerformanceRecorder.
+	SlowestItemsLimit = 10
+	// SlowestFoldersLimit is the number of folders with the lowest
+	// throughput reported by a PerformanceRecorder.
+	SlowestFoldersLimit = 25
+)
+
+// ItemTiming records how long an item took to retrieve.
+type ItemTiming struct {
+	Folder string `json:"folder"`
+	// Item is the item's name, or its ID when the name isn't known.
+	Item string `json:"item"`
+	// ItemID is the item's ID within the service.
+	ItemID   string        `json:"itemID,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Bytes    int64         `json:"bytes"`
+	// Retries is the number of times the item was retrieved again after
+	// a failure.
+	Retries int `json:"retries,omitempty"`
+}
+
+// FolderThroughput records the items retrieved from a folder, and how long
+// retrieving them took.  Items that are downloaded as they're uploaded
+// only add the time spent downloading them.
+type FolderThroughput struct {
+	Folder   string        `json:"folder"`
+	Items    int           `json:"items"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration"`
+}
+
+// BytesPerSecond is the folder's throughput.  Produces 0 if the folder
+// has no duration.
+func (ft FolderThroughput) BytesPerSecond() int64 {
+	if ft.Duration <= 0 {
+		return 0
+	}
+
+	return int64(float64(ft.Bytes) / ft.Duration.Seconds())
+}
+
+// hasThroughput is true if the folder retrieved any bytes in measurable
+// time.
+func (ft FolderThroughput) hasThroughput() bool {
+	return ft.Bytes > 0 && ft.Duration > 0
+}
+
+// Performance describes the folders with the lowest throughput, and the
+// items that took the longest to retrieve, so that slow operations can be
+// attributed to them.  Both are ordered from slowest to fastest.
+type Performance struct {
+	Folders      []FolderThroughput `json:"folders,omitempty"`
+	SlowestItems []ItemTiming       `json:"slowestItems,omitempty"`
+}
+
+// IsEmpty returns true if no performance was recorded.
+func (p Performance) IsEmpty() bool {
+	return len(p.Folders) == 0 && len(p.SlowestItems) == 0
+}
+
+// PerformanceRecorder totals the throughput of each folder, and keeps the
+// slowest items recorded in it.  Recorders are safe for concurrent use,
+// and nil recorders ignore everything recorded in them.
+type PerformanceRecorder struct {
+	mu      sync.Mutex
+	folders map[string]FolderThroughput
+	items   []ItemTiming
+}
+
+// NewPerformanceRecorder produces an empty recorder.
+func NewPerformanceRecorder() *PerformanceRecorder {
+	return &PerformanceRecorder{folders: map[string]FolderThroughput{}}
+}
+
+// RecordItem records the time taken to retrieve an item.
+func (r *PerformanceRecorder) RecordItem(it ItemTiming) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = keepSlowest(r.items, it, SlowestItemsLimit, func(a, b ItemTiming) bool {
+		return a.Duration > b.Duration
+	})
+}
+
+// RecordFolder adds the items retrieved from a folder, and the time taken
+// to retrieve them, to the folder's totals.
+func (r *PerformanceRecorder) RecordFolder(ft FolderThroughput) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := r.folders[ft.Folder]
+	total.Folder = ft.Folder
+	total.Items += ft.Items
+	total.Bytes += ft.Bytes
+	total.Duration += ft.Duration
+
+	r.folders[ft.Folder] = total
+}
+
+// Performance produces the folders with the lowest throughput, and the
+// slowest items, recorded so far.  Folders without a measurable throughput
+// are left out.
+func (r *PerformanceRecorder) Performance() Performance {
+	if r == nil {
+		return Performance{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var folders []FolderThroughput
+
+	for _, ft := range r.folders {
+		if !ft.hasThroughput() {
+			continue
+		}
+
+		folders = keepSlowest(folders, ft, SlowestFoldersLimit, func(a, b FolderThroughput) bool {
+			return a.BytesPerSecond() < b.BytesPerSecond()
+		})
+	}
+
+	return Performance{
+		Folders:      folders,
+		SlowestItems: append([]ItemTiming(nil), r.items...),
+	}
+}
+
+// keepSlowest inserts v into the sorted slice, dropping the fastest
+// entries beyond the limit.
+func keepSlowest[T any](ts []T, v T, limit int, slower func(a, b T) bool) []T {
+	i := sort.Search(len(ts), func(i int) bool { return slower(v, ts[i]) })
+	if i >= limit {
+		return ts
+	}
+
+	ts = append(ts, v)
+	copy(ts[i+1:], ts[i:])
+	ts[i] = v
+
+	if len(ts) > limit {
+		ts = ts[:limit]
+	}
+
+	return ts
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type recorderKey struct{}
+
+// SetRecorder embeds the recorder within the context.
+func SetRecorder(ctx context.Context, r *PerformanceRecorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderCtx retrieves the recorder embedded in the context.  Returns nil
+// if no recorder was set, which is safe to record into.
+func RecorderCtx(ctx context.Context) *PerformanceRecorder {
+	r, _ := ctx.Value(recorderKey{}).(*PerformanceRecorder)
+	return r
+}
@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"sort"
 	"sync/atomic"
 	"time"
 )
@@ -29,8 +30,64 @@ type StartAndEndTime struct {
 
 type ByteCounter struct {
 	NumBytes int64
+	// unix nanoseconds of the first count; 0 until something is counted.
+	firstCount int64
 }
 
 func (bc *ByteCounter) Count(i int64) {
+	if atomic.LoadInt64(&bc.firstCount) == 0 {
+		atomic.CompareAndSwapInt64(&bc.firstCount, 0, time.Now().UnixNano())
+	}
+
 	atomic.AddInt64(&bc.NumBytes, i)
 }
+
+// FirstCountAt returns the time at which bytes were first counted, or the
+// zero time if nothing has been counted.
+func (bc *ByteCounter) FirstCountAt() time.Time {
+	ns := atomic.LoadInt64(&bc.firstCount)
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ns)
+}
+
+// operation phases recorded in a Timeline.
+const (
+	PhaseDiscoveryStarted      = "discovery started"
+	PhaseDiscoveryCompleted    = "discovery completed"
+	PhaseFirstByteUploaded     = "first byte uploaded"
+	PhaseDetailsMergeStarted   = "details merge started"
+	PhaseDetailsMergeCompleted = "details merge completed"
+	PhaseModelPersistStarted   = "model persist started"
+)
+
+// PhaseTime records the time at which a phase occurred.
+type PhaseTime struct {
+	Phase string    `json:"phase"`
+	At    time.Time `json:"at"`
+}
+
+// Timeline records the phases of an operation in the order they occurred.
+// Timelines are not safe for concurrent use.
+type Timeline []PhaseTime
+
+// Mark records that the phase occurred now.
+func (tl *Timeline) Mark(phase string) {
+	tl.Add(phase, time.Now())
+}
+
+// Add records that the phase occurred at the given time.  Zero times are
+// ignored, so that phases which never happened can be added unconditionally.
+func (tl *Timeline) Add(phase string, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+
+	*tl = append(*tl, PhaseTime{Phase: phase, At: at})
+
+	sort.SliceStable(*tl, func(i, j int) bool {
+		return (*tl)[i].At.Before((*tl)[j].At)
+	})
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type StatsUnitSuite struct {
+	tester.Suite
+}
+
+func TestStatsUnitSuite(t *testing.T) {
+	suite.Run(t, &StatsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StatsUnitSuite) TestTimeline_Add() {
+	var (
+		t     = suite.T()
+		now   = time.Now()
+		tl    = Timeline{}
+		later = now.Add(time.Minute)
+	)
+
+	tl.Add(PhaseDiscoveryStarted, now)
+	tl.Add(PhaseDetailsMergeStarted, later)
+	// phases added out of order are sorted by time.
+	tl.Add(PhaseFirstByteUploaded, now.Add(time.Second))
+	// phases that never occurred are skipped.
+	tl.Add(PhaseDiscoveryCompleted, time.Time{})
+
+	assert.Equal(
+		t,
+		Timeline{
+			{Phase: PhaseDiscoveryStarted, At: now},
+			{Phase: PhaseFirstByteUploaded, At: now.Add(time.Second)},
+			{Phase: PhaseDetailsMergeStarted, At: later},
+		},
+		tl)
+}
+
+func (suite *StatsUnitSuite) TestByteCounter_FirstCountAt() {
+	t := suite.T()
+	bc := &ByteCounter{}
+
+	assert.True(t, bc.FirstCountAt().IsZero())
+
+	before := time.Now()
+
+	bc.Count(1)
+
+	first := bc.FirstCountAt()
+	assert.False(t, first.Before(before))
+
+	bc.Count(1)
+	assert.Equal(t, first, bc.FirstCountAt())
+	assert.Equal(t, int64(2), bc.NumBytes)
+}
+
+func (suite *StatsUnitSuite) TestPerformanceRecorder() {
+	var (
+		t   = suite.T()
+		rec = NewPerformanceRecorder()
+	)
+
+	for i := 1; i <= SlowestItemsLimit+5; i++ {
+		rec.RecordItem(ItemTiming{Item: fmt.Sprint(i), Duration: time.Duration(i) * time.Second})
+	}
+
+	rec.RecordFolder(FolderThroughput{Folder: "fast", Items: 1, Bytes: 1000, Duration: time.Second})
+	// the longest folder moves the most data, and isn't the slowest.
+	rec.RecordFolder(FolderThroughput{Folder: "long", Items: 1, Bytes: 1000000, Duration: time.Hour})
+	rec.RecordFolder(FolderThroughput{Folder: "slow", Items: 1, Bytes: 60, Duration: time.Minute})
+	rec.RecordFolder(FolderThroughput{Folder: "slow", Items: 1, Bytes: 60, Duration: time.Minute})
+	rec.RecordFolder(FolderThroughput{Folder: "empty", Items: 1, Duration: time.Minute})
+
+	perf := rec.Performance()
+
+	assert.Len(t, perf.SlowestItems, SlowestItemsLimit, "only the slowest items are kept")
+	assert.Equal(t, fmt.Sprint(SlowestItemsLimit+5), perf.SlowestItems[0].Item, "slowest item is first")
+	assert.Equal(t, "6", perf.SlowestItems[SlowestItemsLimit-1].Item)
+
+	if assert.Len(t, perf.Folders, 3, "folders without throughput are left out") {
+		assert.Equal(t, "slow", perf.Folders[0].Folder, "lowest throughput is first")
+		assert.Equal(t, 2, perf.Folders[0].Items, "folder totals are summed")
+		assert.Equal(t, 2*time.Minute, perf.Folders[0].Duration)
+		assert.Equal(t, int64(1), perf.Folders[0].BytesPerSecond())
+		assert.Equal(t, "long", perf.Folders[1].Folder)
+		assert.Equal(t, "fast", perf.Folders[2].Folder)
+	}
+
+	// nil recorders, as produced by contexts without a recorder, are safe to use.
+	nilRec := RecorderCtx(context.Background())
+	nilRec.RecordItem(ItemTiming{})
+	nilRec.RecordFolder(FolderThroughput{})
+	assert.True(t, nilRec.Performance().IsEmpty())
+
+	assert.Same(t, rec, RecorderCtx(SetRecorder(context.Background(), rec)))
+}
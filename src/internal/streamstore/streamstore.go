@@ -5,8 +5,12 @@ package streamstore
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
+	"sync"
 
 	"github.com/alcionai/clues"
 	"github.com/pkg/errors"
@@ -37,15 +41,46 @@ const (
 	// detailsItemName is the name of the stream used to store
 	// backup details
 	detailsItemName = "details"
+	// detailsPartPrefix prefixes the names of the streams holding
+	// each part of the backup details entries
+	detailsPartPrefix = "details-part-"
 	// collectionPurposeDetails is used to indicate
 	// what the collection is being used for
 	collectionPurposeDetails = "details"
 )
 
+const (
+	// detailsPartSize is the number of details entries stored in each part.
+	detailsPartSize = 25000
+	// detailsParallelism bounds the number of parts serialized, or
+	// decoded, at the same time.
+	detailsParallelism = 8
+)
+
+// detailsManifest is the content of the details stream.  The details
+// entries are split into parts, which are serialized and read back in
+// parallel.  Details written before entries were split into parts hold
+// all of their entries in the details stream, and list no parts.
+type detailsManifest struct {
+	details.DetailsModel
+	Parts []detailsPart `json:"parts,omitempty"`
+}
+
+// detailsPart identifies the stream holding a part of the details entries.
+type detailsPart struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	// hex encoded sha256 of the serialized part
+	Checksum string `json:"checksum"`
+}
+
 // WriteBackupDetails persists a `details.Details`
-// object in the stream store
+// object in the stream store.  The details snapshot is tagged with the
+// backup's ID, so that the details can be found if the backup model is
+// lost.
 func (ss *streamStore) WriteBackupDetails(
 	ctx context.Context,
+	backupID string,
 	backupDetails *details.Details,
 	errs *fault.Errors,
 ) (string, error) {
@@ -60,19 +95,27 @@ func (ss *streamStore) WriteBackupDetails(
 		return "", clues.Stack(err).WithClues(ctx)
 	}
 
-	// TODO: We could use an io.Pipe here to avoid a double copy but that
-	// makes error handling a bit complicated
-	dbytes, err := json.Marshal(backupDetails)
+	parts, items, err := serializeDetailsParts(backupDetails.Entries, detailsPartSize)
+	if err != nil {
+		return "", clues.Stack(err).WithClues(ctx)
+	}
+
+	mbytes, err := json.Marshal(detailsManifest{Parts: parts})
 	if err != nil {
-		return "", clues.Wrap(err, "marshalling backup details").WithClues(ctx)
+		return "", clues.Wrap(err, "marshalling backup details manifest").WithClues(ctx)
 	}
 
 	dc := &streamCollection{
 		folderPath: p,
-		item: &streamItem{
-			name: detailsItemName,
-			data: dbytes,
-		},
+		items:      append([]*streamItem{{name: detailsItemName, data: mbytes}}, items...),
+	}
+
+	var tags map[string]string
+	if len(backupID) > 0 {
+		tags = map[string]string{
+			kopia.TagBackupID:        backupID,
+			kopia.TagDetailsCategory: "",
+		}
 	}
 
 	backupStats, _, _, err := ss.kw.BackupCollections(
@@ -80,7 +123,7 @@ func (ss *streamStore) WriteBackupDetails(
 		nil,
 		[]data.BackupCollection{dc},
 		nil,
-		nil,
+		tags,
 		false,
 		errs)
 	if err != nil {
@@ -97,24 +140,73 @@ func (ss *streamStore) ReadBackupDetails(
 	detailsID string,
 	errs *fault.Errors,
 ) (*details.Details, error) {
-	// construct the path for the `details` item
-	detailsPath, err := path.Builder{}.
-		Append(detailsItemName).
-		ToStreamStorePath(
-			ss.tenant,
-			collectionPurposeDetails,
-			ss.service,
-			true,
-		)
+	streams, err := ss.readStreams(ctx, detailsID, []string{detailsItemName}, errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving backup details data")
+	}
+
+	var man detailsManifest
+
+	rc := streams[detailsItemName].ToReader()
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(&man); err != nil {
+		return nil, clues.Wrap(err, "decoding details data").WithClues(ctx)
+	}
+
+	if len(man.Parts) == 0 {
+		return &details.Details{DetailsModel: man.DetailsModel}, nil
+	}
+
+	names := make([]string, 0, len(man.Parts))
+	for _, part := range man.Parts {
+		names = append(names, part.Name)
+	}
+
+	streams, err = ss.readStreams(ctx, detailsID, names, errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving backup details parts")
+	}
+
+	d, err := decodeDetailsParts(man.Parts, streams)
 	if err != nil {
 		return nil, clues.Stack(err).WithClues(ctx)
 	}
 
+	return d, nil
+}
+
+// readStreams retrieves the named streams from the details collection
+// of the snapshot.  Every stream must be found.
+func (ss *streamStore) readStreams(
+	ctx context.Context,
+	snapshotID string,
+	names []string,
+	errs *fault.Errors,
+) (map[string]data.Stream, error) {
+	paths := make([]path.Path, 0, len(names))
+
+	for _, name := range names {
+		p, err := path.Builder{}.
+			Append(name).
+			ToStreamStorePath(
+				ss.tenant,
+				collectionPurposeDetails,
+				ss.service,
+				true,
+			)
+		if err != nil {
+			return nil, clues.Stack(err).WithClues(ctx)
+		}
+
+		paths = append(paths, p)
+	}
+
 	var bc stats.ByteCounter
 
-	dcs, err := ss.kw.RestoreMultipleItems(ctx, detailsID, []path.Path{detailsPath}, &bc, errs)
+	dcs, err := ss.kw.RestoreMultipleItems(ctx, snapshotID, paths, &bc, errs)
 	if err != nil {
-		return nil, errors.Wrap(err, "retrieving backup details data")
+		return nil, err
 	}
 
 	// Expect only 1 data collection
@@ -124,12 +216,10 @@ func (ss *streamStore) ReadBackupDetails(
 			With("collection_count", len(dcs))
 	}
 
-	dc := dcs[0]
-
-	var d details.Details
-
-	found := false
-	items := dc.Items(ctx, errs)
+	var (
+		streams = make(map[string]data.Stream, len(names))
+		items   = dcs[0].Items(ctx, errs)
+	)
 
 	for {
 		select {
@@ -138,21 +228,159 @@ func (ss *streamStore) ReadBackupDetails(
 
 		case itemData, ok := <-items:
 			if !ok {
-				if !found {
-					return nil, clues.New("no backup details found").WithClues(ctx)
+				for _, name := range names {
+					if _, found := streams[name]; !found {
+						return nil, clues.New("no backup details found").WithClues(ctx).With("stream_name", name)
+					}
 				}
 
-				return &d, nil
+				return streams, nil
 			}
 
-			err := json.NewDecoder(itemData.ToReader()).Decode(&d)
+			streams[itemData.UUID()] = itemData
+		}
+	}
+}
+
+// serializeDetailsParts splits the entries into parts of up to partSize
+// entries, and serializes the parts in parallel.
+func serializeDetailsParts(
+	entries []details.DetailsEntry,
+	partSize int,
+) ([]detailsPart, []*streamItem, error) {
+	var (
+		numParts = (len(entries) + partSize - 1) / partSize
+		parts    = make([]detailsPart, numParts)
+		items    = make([]*streamItem, numParts)
+		errs     = make([]error, numParts)
+		sem      = make(chan struct{}, detailsParallelism)
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < numParts; i++ {
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := i * partSize
+			end := start + partSize
+
+			if end > len(entries) {
+				end = len(entries)
+			}
+
+			bs, err := json.Marshal(details.DetailsModel{Entries: entries[start:end]})
 			if err != nil {
-				return nil, clues.Wrap(err, "decoding details data").WithClues(ctx)
+				errs[i] = clues.Wrap(err, "marshalling backup details").With("details_part", i)
+				return
+			}
+
+			sum := sha256.Sum256(bs)
+			name := fmt.Sprintf("%s%06d", detailsPartPrefix, i)
+
+			parts[i] = detailsPart{
+				Name:     name,
+				Entries:  end - start,
+				Checksum: hex.EncodeToString(sum[:]),
 			}
+			items[i] = &streamItem{name: name, data: bs}
+		}(i)
+	}
 
-			found = true
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
 		}
 	}
+
+	return parts, items, nil
+}
+
+// decodeDetailsParts decodes the parts in parallel, verifying each part
+// against its checksum, and joins their entries in order.
+func decodeDetailsParts(parts []detailsPart, streams map[string]data.Stream) (*details.Details, error) {
+	var (
+		models = make([]details.DetailsModel, len(parts))
+		errs   = make([]error, len(parts))
+		sem    = make(chan struct{}, detailsParallelism)
+		wg     sync.WaitGroup
+	)
+
+	for i, part := range parts {
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func(i int, part detailsPart) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dm, err := decodeDetailsPart(part, streams[part.Name])
+			if err != nil {
+				errs[i] = clues.Stack(err).With("details_part", part.Name)
+				return
+			}
+
+			models[i] = dm
+		}(i, part)
+	}
+
+	wg.Wait()
+
+	d := &details.Details{}
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+
+		d.Entries = append(d.Entries, models[i].Entries...)
+	}
+
+	return d, nil
+}
+
+func decodeDetailsPart(part detailsPart, stream data.Stream) (details.DetailsModel, error) {
+	var dm details.DetailsModel
+
+	if stream == nil {
+		return dm, clues.New("missing backup details part")
+	}
+
+	rc := stream.ToReader()
+	defer rc.Close()
+
+	var (
+		h   = sha256.New()
+		tee = io.TeeReader(rc, h)
+	)
+
+	if err := json.NewDecoder(tee).Decode(&dm); err != nil {
+		return dm, clues.Wrap(err, "decoding backup details part")
+	}
+
+	// the decoder may stop short of the end of the part.
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return dm, clues.Wrap(err, "reading backup details part")
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != part.Checksum {
+		return dm, clues.New("backup details part checksum mismatch").
+			With("expected_checksum", part.Checksum, "checksum", sum)
+	}
+
+	if len(dm.Entries) != part.Entries {
+		return dm, clues.New("backup details part entry count mismatch").
+			With("expected_entries", part.Entries, "entries", len(dm.Entries))
+	}
+
+	return dm, nil
 }
 
 // DeleteBackupDetails deletes the specified details object from the kopia repository
@@ -169,12 +397,12 @@ func (ss *streamStore) DeleteBackupDetails(
 }
 
 // streamCollection is a data.BackupCollection used to persist
-// a single data stream
+// a set of data streams
 type streamCollection struct {
 	// folderPath indicates what level in the hierarchy this collection
 	// represents
 	folderPath path.Path
-	item       *streamItem
+	items      []*streamItem
 }
 
 func (dc *streamCollection) FullPath() path.Path {
@@ -193,12 +421,15 @@ func (dc *streamCollection) DoNotMergeItems() bool {
 	return false
 }
 
-// Items() always returns a channel with a single data.Stream
-// representing the object to be persisted
+// Items() returns a channel holding every data.Stream
+// to be persisted
 func (dc *streamCollection) Items(context.Context, *fault.Errors) <-chan data.Stream {
-	items := make(chan data.Stream, 1)
+	items := make(chan data.Stream, len(dc.items))
 	defer close(items)
-	items <- dc.item
+
+	for _, item := range dc.items {
+		items <- item
+	}
 
 	return items
 }
@@ -1,12 +1,16 @@
 package streamstore
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/alcionai/corso/src/internal/data"
 	"github.com/alcionai/corso/src/internal/kopia"
 	"github.com/alcionai/corso/src/internal/tester"
 	"github.com/alcionai/corso/src/pkg/backup/details"
@@ -54,7 +58,7 @@ func (suite *StreamStoreIntegrationSuite) TestDetails() {
 	deets := deetsBuilder.Details()
 	ss := New(kw, "tenant", path.ExchangeService)
 
-	id, err := ss.WriteBackupDetails(ctx, deets, fault.New(true))
+	id, err := ss.WriteBackupDetails(ctx, "backupID", deets, fault.New(true))
 	require.NoError(t, err)
 	require.NotNil(t, id)
 
@@ -71,3 +75,143 @@ func (suite *StreamStoreIntegrationSuite) TestDetails() {
 	assert.NotNil(t, readDeets.Entries[0].Exchange)
 	assert.Equal(t, *deets.Entries[0].Exchange, *readDeets.Entries[0].Exchange)
 }
+
+type StreamStoreUnitSuite struct {
+	tester.Suite
+}
+
+func TestStreamStoreUnitSuite(t *testing.T) {
+	suite.Run(t, &StreamStoreUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func detailsEntries(n int) []details.DetailsEntry {
+	ents := make([]details.DetailsEntry, 0, n)
+
+	for i := 0; i < n; i++ {
+		ents = append(ents, details.DetailsEntry{
+			RepoRef:  fmt.Sprintf("ref-%d", i),
+			ShortRef: fmt.Sprintf("short-%d", i),
+			ItemInfo: details.ItemInfo{
+				Exchange: &details.ExchangeInfo{Subject: fmt.Sprintf("subject %d", i)},
+			},
+		})
+	}
+
+	return ents
+}
+
+func partStreams(items []*streamItem) map[string]data.Stream {
+	streams := map[string]data.Stream{}
+	for _, item := range items {
+		streams[item.name] = item
+	}
+
+	return streams
+}
+
+func (suite *StreamStoreUnitSuite) TestDetailsParts() {
+	table := []struct {
+		name        string
+		entries     int
+		expectParts int
+	}{
+		{
+			name:        "empty",
+			entries:     0,
+			expectParts: 0,
+		},
+		{
+			name:        "partial part",
+			entries:     3,
+			expectParts: 1,
+		},
+		{
+			name:        "full parts",
+			entries:     10,
+			expectParts: 2,
+		},
+		{
+			name:        "trailing partial part",
+			entries:     23,
+			expectParts: 5,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			ents := detailsEntries(test.entries)
+
+			parts, items, err := serializeDetailsParts(ents, 5)
+			require.NoError(t, err)
+			require.Len(t, parts, test.expectParts)
+			require.Len(t, items, test.expectParts)
+
+			d, err := decodeDetailsParts(parts, partStreams(items))
+			require.NoError(t, err)
+			require.Len(t, d.Entries, test.entries)
+
+			for i, ent := range d.Entries {
+				assert.Equal(t, ents[i].RepoRef, ent.RepoRef)
+				assert.Equal(t, ents[i].Exchange.Subject, ent.Exchange.Subject)
+			}
+		})
+	}
+}
+
+func (suite *StreamStoreUnitSuite) TestDecodeDetailsParts_Errors() {
+	table := []struct {
+		name   string
+		modify func(parts []detailsPart, items []*streamItem)
+	}{
+		{
+			name: "corrupted part",
+			modify: func(parts []detailsPart, items []*streamItem) {
+				items[1].data = bytes.Replace(items[1].data, []byte("ref-5"), []byte("ref-X"), 1)
+			},
+		},
+		{
+			name: "truncated part",
+			modify: func(parts []detailsPart, items []*streamItem) {
+				items[0].data = items[0].data[:len(items[0].data)/2]
+			},
+		},
+		{
+			name: "missing part",
+			modify: func(parts []detailsPart, items []*streamItem) {
+				items[1].name = "other"
+			},
+		},
+		{
+			name: "entry count mismatch",
+			modify: func(parts []detailsPart, items []*streamItem) {
+				parts[0].Entries++
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			parts, items, err := serializeDetailsParts(detailsEntries(10), 5)
+			require.NoError(t, err)
+
+			test.modify(parts, items)
+
+			_, err = decodeDetailsParts(parts, partStreams(items))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func (suite *StreamStoreUnitSuite) TestDetailsManifest_Legacy() {
+	t := suite.T()
+
+	legacy, err := json.Marshal(details.DetailsModel{Entries: detailsEntries(3)})
+	require.NoError(t, err)
+
+	var man detailsManifest
+
+	require.NoError(t, json.Unmarshal(legacy, &man))
+	assert.Empty(t, man.Parts)
+	assert.Len(t, man.Entries, 3)
+}
@@ -0,0 +1,425 @@
+// update finds, verifies, and installs corso releases.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// DefaultReleasesURL lists corso's releases through the github api.
+const DefaultReleasesURL = "https://api.github.com/repos/alcionai/corso/releases"
+
+const (
+	checksumsName = "checksums.txt"
+	signatureName = checksumsName + ".sig"
+
+	maxArchiveBytes  = 512 << 20
+	maxMetadataBytes = 4 << 20
+)
+
+// PublicKey is the base64 encoded ed25519 key that signs the checksums of
+// each release.  It's populated at build time.
+var PublicKey = ""
+
+var (
+	ErrNoPublicKey    = errors.New("this build of corso can't verify releases")
+	ErrBadSignature   = errors.New("release checksums don't match their signature")
+	ErrBadChecksum    = errors.New("release archive doesn't match its checksum")
+	ErrNoRelease      = errors.New("no release found")
+	ErrUnknownChannel = errors.New("unknown release channel")
+)
+
+// Channel identifies the releases that corso updates to.
+type Channel string
+
+const (
+	// Stable releases exclude pre-releases.
+	Stable Channel = "stable"
+	// Beta releases include pre-releases.
+	Beta Channel = "beta"
+)
+
+// ParseChannel produces the channel with the given name.  An empty name
+// produces the stable channel.
+func ParseChannel(name string) (Channel, error) {
+	switch Channel(strings.ToLower(name)) {
+	case "", Stable:
+		return Stable, nil
+	case Beta:
+		return Beta, nil
+	}
+
+	return "", clues.Stack(ErrUnknownChannel).With("channel", name)
+}
+
+// Release is a published corso release.
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file published with a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+func (r Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+
+	return Asset{}, false
+}
+
+// Newer is true if the release tag is a later version than the current
+// version.  Versions that aren't semantic versions, such as development
+// builds, are never older than a release.
+func Newer(current, tag string) bool {
+	if !semver.IsValid(current) || !semver.IsValid(tag) {
+		return false
+	}
+
+	return semver.Compare(tag, current) > 0
+}
+
+// ---------------------------------------------------------------------------
+// Updater
+// ---------------------------------------------------------------------------
+
+// Updater looks up releases, and downloads their verified binaries.
+type Updater struct {
+	client      *http.Client
+	releasesURL string
+	key         ed25519.PublicKey
+}
+
+// NewUpdater produces an updater which looks up releases at the url, and
+// verifies them with the build's PublicKey.  An empty url uses the
+// DefaultReleasesURL.
+func NewUpdater(releasesURL string) (*Updater, error) {
+	if len(PublicKey) == 0 {
+		return nil, clues.Stack(ErrNoPublicKey)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, clues.Stack(ErrNoPublicKey, clues.New("malformed public key"))
+	}
+
+	return newUpdater(&http.Client{Timeout: 10 * time.Minute}, releasesURL, key), nil
+}
+
+func newUpdater(client *http.Client, releasesURL string, key ed25519.PublicKey) *Updater {
+	if len(releasesURL) == 0 {
+		releasesURL = DefaultReleasesURL
+	}
+
+	return &Updater{
+		client:      client,
+		releasesURL: releasesURL,
+		key:         key,
+	}
+}
+
+// Latest produces the most recent release in the channel.
+func (u Updater) Latest(ctx context.Context, ch Channel) (Release, error) {
+	ctx = clues.Add(ctx, "release_channel", ch)
+
+	bs, err := u.get(ctx, u.releasesURL, maxMetadataBytes)
+	if err != nil {
+		return Release{}, clues.Wrap(err, "listing releases")
+	}
+
+	var rs []Release
+
+	if err := json.Unmarshal(bs, &rs); err != nil {
+		return Release{}, clues.Wrap(err, "decoding releases").WithClues(ctx)
+	}
+
+	var latest Release
+
+	for _, r := range rs {
+		if r.Draft || !semver.IsValid(r.Tag) || (r.Prerelease && ch != Beta) {
+			continue
+		}
+
+		if len(latest.Tag) == 0 || semver.Compare(r.Tag, latest.Tag) > 0 {
+			latest = r
+		}
+	}
+
+	if len(latest.Tag) == 0 {
+		return Release{}, clues.Stack(ErrNoRelease).WithClues(ctx)
+	}
+
+	return latest, nil
+}
+
+// Download fetches the release's corso binary for the running platform.
+// The release checksums are verified against their signature, and the
+// archive against its checksum, before the binary is extracted.
+func (u Updater) Download(ctx context.Context, r Release) ([]byte, error) {
+	name := ArchiveName(r.Tag, runtime.GOOS, runtime.GOARCH)
+	ctx = clues.Add(ctx, "release", r.Tag, "archive", name)
+
+	sums, err := u.getAsset(ctx, r, checksumsName, maxMetadataBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := u.getAsset(ctx, r, signatureName, maxMetadataBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(u.key, sums, sig) {
+		return nil, clues.Stack(ErrBadSignature).WithClues(ctx)
+	}
+
+	want, err := checksumFor(sums, name)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	archive, err := u.getAsset(ctx, r, name, maxArchiveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if got := sha256.Sum256(archive); hex.EncodeToString(got[:]) != want {
+		return nil, clues.Stack(ErrBadChecksum).WithClues(ctx)
+	}
+
+	bin, err := extractBinary(archive, strings.HasSuffix(name, ".zip"))
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	return bin, nil
+}
+
+func (u Updater) getAsset(ctx context.Context, r Release, name string, limit int64) ([]byte, error) {
+	a, ok := r.asset(name)
+	if !ok {
+		return nil, clues.New("release is missing a file").WithClues(ctx).With("file", name)
+	}
+
+	bs, err := u.get(ctx, a.URL, limit)
+	if err != nil {
+		return nil, clues.Wrap(err, "downloading "+name)
+	}
+
+	return bs, nil
+}
+
+func (u Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, clues.Wrap(err, "creating request").WithClues(ctx)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, clues.Wrap(err, "sending request").WithClues(ctx)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, clues.New(resp.Status).WithClues(ctx).With("status_code", resp.StatusCode)
+	}
+
+	bs, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, clues.Wrap(err, "reading response").WithClues(ctx)
+	}
+
+	if int64(len(bs)) > limit {
+		return nil, clues.New("response exceeds the size limit").WithClues(ctx).With("limit", limit)
+	}
+
+	return bs, nil
+}
+
+// ---------------------------------------------------------------------------
+// archives
+// ---------------------------------------------------------------------------
+
+var (
+	archiveOS = map[string]string{
+		"darwin":  "Darwin",
+		"linux":   "Linux",
+		"windows": "Windows",
+	}
+	archiveArch = map[string]string{
+		"386":   "i386",
+		"amd64": "x86_64",
+	}
+)
+
+// ArchiveName produces the name of the release archive that holds the
+// binary for the platform.
+func ArchiveName(tag, goos, goarch string) string {
+	osName, archName := goos, goarch
+
+	if n, ok := archiveOS[goos]; ok {
+		osName = n
+	}
+
+	if n, ok := archiveArch[goarch]; ok {
+		archName = n
+	}
+
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+
+	return fmt.Sprintf("corso_%s_%s_%s.%s", tag, osName, archName, ext)
+}
+
+// checksumFor finds the hex encoded sha256 of the named file within the
+// release checksums.
+func checksumFor(sums []byte, name string) (string, error) {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", clues.New("no checksum for the release archive").With("archive", name)
+}
+
+// extractBinary reads the corso binary out of the release archive.
+func extractBinary(archive []byte, isZip bool) ([]byte, error) {
+	if isZip {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, clues.Wrap(err, "opening zip archive")
+		}
+
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != "corso.exe" {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil, clues.Wrap(err, "opening binary")
+			}
+
+			defer rc.Close()
+
+			return readBinary(rc)
+		}
+
+		return nil, clues.New("no corso binary in the archive")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, clues.Wrap(err, "opening archive")
+	}
+
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, clues.New("no corso binary in the archive")
+		}
+
+		if err != nil {
+			return nil, clues.Wrap(err, "reading archive")
+		}
+
+		if h.Typeflag == tar.TypeReg && filepath.Base(h.Name) == "corso" {
+			return readBinary(tr)
+		}
+	}
+}
+
+func readBinary(r io.Reader) ([]byte, error) {
+	bs, err := io.ReadAll(io.LimitReader(r, maxArchiveBytes))
+	if err != nil {
+		return nil, clues.Wrap(err, "reading binary")
+	}
+
+	return bs, nil
+}
+
+// ---------------------------------------------------------------------------
+// install
+// ---------------------------------------------------------------------------
+
+// Install swaps the binary at the path for the new binary.  The new binary
+// is written beside the old one before the two are swapped, so a failure
+// leaves the old binary in place.  The running binary is moved aside rather
+// than overwritten, since windows can't overwrite a running executable.
+func Install(exePath string, bin []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return clues.Wrap(err, "finding the current binary")
+	}
+
+	var (
+		newPath = exePath + ".new"
+		oldPath = exePath + ".old"
+	)
+
+	if err := os.WriteFile(newPath, bin, info.Mode().Perm()|0o500); err != nil {
+		return clues.Wrap(err, "writing the new binary")
+	}
+
+	// a previous update may have left its old binary behind.
+	_ = os.Remove(oldPath)
+
+	if err := os.Rename(exePath, oldPath); err != nil {
+		_ = os.Remove(newPath)
+		return clues.Wrap(err, "moving the current binary aside")
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		// restore the old binary, so that corso remains installed.
+		_ = os.Rename(oldPath, exePath)
+		_ = os.Remove(newPath)
+
+		return clues.Wrap(err, "installing the new binary")
+	}
+
+	// windows holds the running binary open; it's removed by the next update.
+	_ = os.Remove(oldPath)
+
+	return nil
+}
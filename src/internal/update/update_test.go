@@ -0,0 +1,301 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type UpdateUnitSuite struct {
+	tester.Suite
+}
+
+func TestUpdateUnitSuite(t *testing.T) {
+	suite.Run(t, &UpdateUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+// releaseServer serves a list of releases, and the files of a release
+// holding the binary for the running platform.
+type releaseServer struct {
+	srv      *httptest.Server
+	releases []Release
+	files    map[string][]byte
+}
+
+func newReleaseServer(t *testing.T, tags map[string]bool) *releaseServer {
+	rs := &releaseServer{files: map[string][]byte{}}
+
+	rs.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/releases" {
+			bs, err := json.Marshal(rs.releases)
+			require.NoError(t, err)
+
+			_, _ = w.Write(bs)
+
+			return
+		}
+
+		bs, ok := rs.files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(bs)
+	}))
+
+	t.Cleanup(rs.srv.Close)
+
+	for tag, pre := range tags {
+		r := Release{Tag: tag, Prerelease: pre}
+
+		for _, name := range []string{
+			checksumsName,
+			signatureName,
+			ArchiveName(tag, runtime.GOOS, runtime.GOARCH),
+		} {
+			r.Assets = append(r.Assets, Asset{Name: name, URL: rs.srv.URL + "/" + tag + "/" + name})
+		}
+
+		rs.releases = append(rs.releases, r)
+	}
+
+	return rs
+}
+
+// publish stores the release's archive, checksums, and checksum signature.
+func (rs *releaseServer) publish(tag string, archive []byte, key ed25519.PrivateKey) {
+	var (
+		name = ArchiveName(tag, runtime.GOOS, runtime.GOARCH)
+		sum  = sha256.Sum256(archive)
+		sums = []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+	)
+
+	rs.files["/"+tag+"/"+name] = archive
+	rs.files["/"+tag+"/"+checksumsName] = sums
+	rs.files["/"+tag+"/"+signatureName] = ed25519.Sign(key, sums)
+}
+
+func tarball(t *testing.T, name string, content []byte) []byte {
+	var (
+		buf bytes.Buffer
+		gz  = gzip.NewWriter(&buf)
+		tw  = tar.NewWriter(gz)
+	)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0o755,
+		Size:     int64(len(content)),
+		Typeflag: tar.TypeReg,
+	}))
+
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func (suite *UpdateUnitSuite) TestParseChannel() {
+	table := []struct {
+		input     string
+		expect    Channel
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"", Stable, assert.NoError},
+		{"stable", Stable, assert.NoError},
+		{"Beta", Beta, assert.NoError},
+		{"nightly", "", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.input, func() {
+			t := suite.T()
+
+			ch, err := ParseChannel(test.input)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, ch)
+		})
+	}
+}
+
+func (suite *UpdateUnitSuite) TestNewer() {
+	table := []struct {
+		name    string
+		current string
+		tag     string
+		expect  bool
+	}{
+		{"newer", "v0.5.0", "v0.6.0", true},
+		{"same", "v0.6.0", "v0.6.0", false},
+		{"older", "v0.6.0", "v0.5.0", false},
+		{"release after prerelease", "v0.6.0-rc1", "v0.6.0", true},
+		{"dev build", "dev-0123456", "v0.6.0", false},
+		{"invalid tag", "v0.5.0", "latest", false},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, Newer(test.current, test.tag))
+		})
+	}
+}
+
+func (suite *UpdateUnitSuite) TestArchiveName() {
+	t := suite.T()
+
+	assert.Equal(t, "corso_v0.6.0_Linux_x86_64.tar.gz", ArchiveName("v0.6.0", "linux", "amd64"))
+	assert.Equal(t, "corso_v0.6.0_Darwin_arm64.tar.gz", ArchiveName("v0.6.0", "darwin", "arm64"))
+	assert.Equal(t, "corso_v0.6.0_Windows_x86_64.zip", ArchiveName("v0.6.0", "windows", "amd64"))
+}
+
+func (suite *UpdateUnitSuite) TestLatest() {
+	table := []struct {
+		name      string
+		ch        Channel
+		tags      map[string]bool
+		expect    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "stable skips prereleases",
+			ch:        Stable,
+			tags:      map[string]bool{"v0.5.0": false, "v0.6.0": false, "v0.7.0-rc1": true},
+			expect:    "v0.6.0",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "beta includes prereleases",
+			ch:        Beta,
+			tags:      map[string]bool{"v0.5.0": false, "v0.6.0": false, "v0.7.0-rc1": true},
+			expect:    "v0.7.0-rc1",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "no stable release",
+			ch:        Stable,
+			tags:      map[string]bool{"v0.7.0-rc1": true},
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			rs := newReleaseServer(t, test.tags)
+			u := newUpdater(rs.srv.Client(), rs.srv.URL+"/releases", nil)
+
+			r, err := u.Latest(ctx, test.ch)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, r.Tag)
+		})
+	}
+}
+
+func (suite *UpdateUnitSuite) TestDownload() {
+	if runtime.GOOS == "windows" {
+		suite.T().Skip("release archive is a zip on windows")
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(suite.T(), err)
+
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(suite.T(), err)
+
+	var (
+		binName = "corso"
+		bin     = []byte("new corso binary")
+	)
+
+	table := []struct {
+		name      string
+		publish   func(rs *releaseServer, tag string)
+		expectErr error
+	}{
+		{
+			name: "verified",
+			publish: func(rs *releaseServer, tag string) {
+				rs.publish(tag, tarball(suite.T(), binName, bin), priv)
+			},
+		},
+		{
+			name: "bad signature",
+			publish: func(rs *releaseServer, tag string) {
+				rs.publish(tag, tarball(suite.T(), binName, bin), otherPriv)
+			},
+			expectErr: ErrBadSignature,
+		},
+		{
+			name: "bad checksum",
+			publish: func(rs *releaseServer, tag string) {
+				rs.publish(tag, tarball(suite.T(), binName, bin), priv)
+
+				name := ArchiveName(tag, runtime.GOOS, runtime.GOARCH)
+				rs.files["/"+tag+"/"+name] = tarball(suite.T(), binName, []byte("tampered"))
+			},
+			expectErr: ErrBadChecksum,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			rs := newReleaseServer(t, map[string]bool{"v0.6.0": false})
+			test.publish(rs, "v0.6.0")
+
+			u := newUpdater(rs.srv.Client(), rs.srv.URL+"/releases", pub)
+
+			r, err := u.Latest(ctx, Stable)
+			require.NoError(t, err)
+
+			result, err := u.Download(ctx, r)
+			if test.expectErr != nil {
+				assert.ErrorIs(t, err, test.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, bin, result)
+		})
+	}
+}
+
+func (suite *UpdateUnitSuite) TestInstall() {
+	t := suite.T()
+
+	exe := filepath.Join(t.TempDir(), "corso")
+	require.NoError(t, os.WriteFile(exe, []byte("old"), 0o755))
+
+	require.NoError(t, Install(exe, []byte("new")))
+
+	bs, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), bs)
+
+	_, err = os.Stat(exe + ".new")
+	assert.True(t, os.IsNotExist(err), "new binary is moved into place")
+}
@@ -1,6 +1,8 @@
 package account
 
 import (
+	"strings"
+
 	"github.com/alcionai/clues"
 	"github.com/pkg/errors"
 
@@ -9,12 +11,56 @@ import (
 
 // config exported name consts
 const (
-	AzureTenantID = "AZURE_TENANT_ID"
+	AzureTenantID   = "AZURE_TENANT_ID"
+	AzureAuthMode   = "AZURE_AUTH_MODE"
+	AzureTokenCache = "AZURE_TOKEN_CACHE"
+)
+
+// M365AuthMode describes how corso authenticates with M365.
+type M365AuthMode string
+
+const (
+	// AuthApplication authenticates as the application itself, with the
+	// client secret and the application permissions granted in the tenant.
+	AuthApplication M365AuthMode = "application"
+	// AuthDeviceCode authenticates as a user, who signs in on any device
+	// using a code, and grants corso their delegated permissions.
+	AuthDeviceCode M365AuthMode = "device-code"
+	// AuthInteractive authenticates as a user, who signs in through a
+	// browser opened on this machine.
+	AuthInteractive M365AuthMode = "interactive"
 )
 
+// ParseM365AuthMode produces the auth mode matching s.  An empty string
+// produces the default, AuthApplication.
+func ParseM365AuthMode(s string) (M365AuthMode, error) {
+	switch m := M365AuthMode(strings.ToLower(s)); m {
+	case "":
+		return AuthApplication, nil
+	case AuthApplication, AuthDeviceCode, AuthInteractive:
+		return m, nil
+	default:
+		return "", clues.New("unknown m365 auth mode").With("auth_mode", s)
+	}
+}
+
+// normalize produces m in the lower case form of the mode consts, since
+// ParseM365AuthMode accepts modes in any case.
+func (m M365AuthMode) normalize() M365AuthMode {
+	return M365AuthMode(strings.ToLower(string(m)))
+}
+
 type M365Config struct {
-	credentials.M365 // requires: ClientID, ClientSecret
+	credentials.M365 // requires: ClientID, ClientSecret (application auth only)
 	AzureTenantID    string
+
+	// AuthMode selects between app-only and delegated authentication.
+	// Empty is equivalent to AuthApplication.
+	AuthMode M365AuthMode
+	// TokenCachePath is the file where delegated auth persists its tokens,
+	// so that users sign in once instead of on every run.  Delegated auth
+	// without a cache path keeps its tokens in memory.
+	TokenCachePath string
 }
 
 // config key consts
@@ -22,6 +68,10 @@ const (
 	keyAzureClientID     = "azure_clientid"
 	keyAzureClientSecret = "azure_clientSecret"
 	keyAzureTenantID     = "azure_tenantid"
+	keyAzureAuthMode     = "azure_authmode"
+	keyAzureTokenCache   = "azure_tokencache"
+	keyAzureCertPath     = "azure_clientCertificatePath"
+	keyAzureCertPassword = "azure_clientCertificatePassword"
 )
 
 // StringConfig transforms a m365Config struct into a plain
@@ -32,6 +82,10 @@ func (c M365Config) StringConfig() (map[string]string, error) {
 		keyAzureClientID:     c.AzureClientID,
 		keyAzureClientSecret: c.AzureClientSecret,
 		keyAzureTenantID:     c.AzureTenantID,
+		keyAzureAuthMode:     string(c.AuthMode.normalize()),
+		keyAzureTokenCache:   c.TokenCachePath,
+		keyAzureCertPath:     c.AzureClientCertificatePath,
+		keyAzureCertPassword: c.AzureClientCertificatePassword,
 	}
 
 	return cfg, c.validate()
@@ -53,16 +107,35 @@ func (a Account) M365Config() (M365Config, error) {
 		c.AzureClientID = a.Config[keyAzureClientID]
 		c.AzureClientSecret = a.Config[keyAzureClientSecret]
 		c.AzureTenantID = a.Config[keyAzureTenantID]
+		c.AuthMode = M365AuthMode(a.Config[keyAzureAuthMode]).normalize()
+		c.TokenCachePath = a.Config[keyAzureTokenCache]
+		c.AzureClientCertificatePath = a.Config[keyAzureCertPath]
+		c.AzureClientCertificatePassword = a.Config[keyAzureCertPassword]
 	}
 
 	return c, c.validate()
 }
 
+// Delegated returns true if corso authenticates as a user, rather than
+// as the application.
+func (c M365Config) Delegated() bool {
+	m := c.AuthMode.normalize()
+	return m == AuthDeviceCode || m == AuthInteractive
+}
+
 func (c M365Config) validate() error {
+	if _, err := ParseM365AuthMode(string(c.AuthMode)); err != nil {
+		return err
+	}
+
 	check := map[string]string{
-		credentials.AzureClientID:     c.AzureClientID,
-		credentials.AzureClientSecret: c.AzureClientSecret,
-		AzureTenantID:                 c.AzureTenantID,
+		credentials.AzureClientID: c.AzureClientID,
+		AzureTenantID:             c.AzureTenantID,
+	}
+
+	// delegated auth is granted by the signed-in user, and has no secret.
+	if !c.Delegated() {
+		check[credentials.AzureClientSecret] = c.AzureClientSecret
 	}
 
 	for k, v := range check {
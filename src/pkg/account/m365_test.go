@@ -120,3 +120,60 @@ func (suite *M365CfgSuite) TestAccount_M365Config_InvalidCases() {
 		})
 	}
 }
+
+func (suite *M365CfgSuite) TestAccount_M365Config_Delegated() {
+	t := suite.T()
+
+	in := makeTestM365Cfg("cid", "", "tid")
+	in.AuthMode = account.AuthDeviceCode
+	in.TokenCachePath = "/tmp/corso_token_cache"
+
+	a, err := account.NewAccount(account.ProviderM365, in)
+	require.NoError(t, err, "delegated auth needs no client secret")
+
+	out, err := a.M365Config()
+	require.NoError(t, err)
+
+	assert.True(t, out.Delegated())
+	assert.Equal(t, in.AuthMode, out.AuthMode)
+	assert.Equal(t, in.TokenCachePath, out.TokenCachePath)
+
+	in.AuthMode = "Device-Code"
+	assert.True(t, in.Delegated(), "mixed case mode")
+
+	a, err = account.NewAccount(account.ProviderM365, in)
+	require.NoError(t, err, "mixed case mode needs no client secret")
+
+	out, err = a.M365Config()
+	require.NoError(t, err)
+
+	assert.True(t, out.Delegated())
+	assert.Equal(t, account.AuthDeviceCode, out.AuthMode)
+
+	in.AuthMode = "password"
+	_, err = account.NewAccount(account.ProviderM365, in)
+	assert.Error(t, err, "unknown auth mode")
+}
+
+func (suite *M365CfgSuite) TestParseM365AuthMode() {
+	table := []struct {
+		input     string
+		expect    account.M365AuthMode
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"", account.AuthApplication, assert.NoError},
+		{"application", account.AuthApplication, assert.NoError},
+		{"Device-Code", account.AuthDeviceCode, assert.NoError},
+		{"interactive", account.AuthInteractive, assert.NoError},
+		{"password", "", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.input, func() {
+			t := suite.T()
+
+			m, err := account.ParseM365AuthMode(test.input)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, m)
+		})
+	}
+}
@@ -3,14 +3,19 @@ package backup
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
+
 	"github.com/alcionai/corso/src/cli/print"
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/connector/support"
 	"github.com/alcionai/corso/src/internal/model"
 	"github.com/alcionai/corso/src/internal/stats"
 	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/selectors"
 )
@@ -36,6 +41,44 @@ type Backup struct {
 	// Version represents the version of the backup format
 	Version int `json:"version"`
 
+	// BaseBackupIDs identifies the prior backups whose snapshots were
+	// used as bases for this backup.
+	BaseBackupIDs []model.StableID `json:"baseBackupIDs,omitempty"`
+
+	// Incremental is true if the backup only retrieved the changes made
+	// since its bases.  Unexpected full backups can be spotted by a false
+	// value.
+	Incremental bool `json:"isIncremental"`
+
+	// StructureOnly is true if the backup only recorded the folder
+	// hierarchy of the resource owner's data, without any items.
+	StructureOnly bool `json:"structureOnly,omitempty"`
+
+	// Staging is true if the backup was created in a staging repository,
+	// rather than in a repository holding production backups.
+	Staging bool `json:"staging,omitempty"`
+
+	// Notes are freeform, user-provided annotations.  Unlike the rest of
+	// the backup, notes can be edited after the backup is created.
+	Notes string `json:"notes,omitempty"`
+
+	// Timeline records when each phase of the backup occurred, so that
+	// slow backups can be attributed to a specific phase.
+	Timeline stats.Timeline `json:"timeline,omitempty"`
+
+	// Performance records the folders and items that took the longest
+	// to retrieve, so that slow backups can be attributed to them.
+	Performance stats.Performance `json:"performance,omitempty"`
+
+	// DriveTransitions records the drives which were replaced since the
+	// base backup, such as when a user's OneDrive is re-provisioned.
+	DriveTransitions []DriveTransition `json:"driveTransitions,omitempty"`
+
+	// Anomalies flags suspicious patterns of changes since the base
+	// backups, such as mass deletions, which can be early signs of
+	// ransomware.
+	Anomalies []details.Anomaly `json:"anomalies,omitempty"`
+
 	// Errors contains all errors aggregated during a backup operation.
 	Errors fault.ErrorsData `json:"errors"`
 
@@ -48,6 +91,20 @@ type Backup struct {
 // interface compliance checks
 var _ print.Printable = &Backup{}
 
+// DriveTransition describes a drive found in the base backup's metadata
+// which no longer belongs to the resource owner.
+type DriveTransition struct {
+	// PreviousDriveID is the ID of the drive in the base backup.
+	PreviousDriveID string `json:"previousDriveID"`
+	// DriveID is the ID of the drive that replaced the previous drive.
+	// Empty if no replacement could be identified.
+	DriveID   string `json:"driveID,omitempty"`
+	DriveName string `json:"driveName,omitempty"`
+	// MigratedPaths counts the folders of the previous drive which were
+	// found at the same location in the replacement drive.
+	MigratedPaths int `json:"migratedPaths"`
+}
+
 func New(
 	snapshotID, detailsID, status string,
 	id model.StableID,
@@ -100,27 +157,48 @@ func PrintAll(ctx context.Context, bs []*Backup) {
 }
 
 type Printable struct {
-	ID            model.StableID `json:"id"`
-	ErrorCount    int            `json:"errorCount"`
-	StartedAt     time.Time      `json:"started at"`
-	Status        string         `json:"status"`
-	Version       string         `json:"version"`
-	BytesRead     int64          `json:"bytesRead"`
-	BytesUploaded int64          `json:"bytesUploaded"`
-	Owner         string         `json:"owner"`
+	ID               model.StableID     `json:"id"`
+	ErrorCount       int                `json:"errorCount"`
+	StartedAt        time.Time          `json:"started at"`
+	Status           string             `json:"status"`
+	Version          string             `json:"version"`
+	BytesRead        int64              `json:"bytesRead"`
+	BytesUploaded    int64              `json:"bytesUploaded"`
+	Owner            string             `json:"owner"`
+	Incremental      bool               `json:"isIncremental"`
+	Staging          bool               `json:"staging,omitempty"`
+	BaseBackupIDs    []model.StableID   `json:"baseBackupIDs,omitempty"`
+	Notes            string             `json:"notes,omitempty"`
+	Timeline         stats.Timeline     `json:"timeline,omitempty"`
+	Performance      *stats.Performance `json:"performance,omitempty"`
+	DriveTransitions []DriveTransition  `json:"driveTransitions,omitempty"`
+	Anomalies        []details.Anomaly  `json:"anomalies,omitempty"`
 }
 
 // MinimumPrintable reduces the Backup to its minimally printable details.
 func (b Backup) MinimumPrintable() any {
+	var perf *stats.Performance
+	if !b.Performance.IsEmpty() {
+		perf = &b.Performance
+	}
+
 	return Printable{
-		ID:            b.ID,
-		ErrorCount:    b.errorCount(),
-		StartedAt:     b.StartedAt,
-		Status:        b.Status,
-		Version:       "0",
-		BytesRead:     b.BytesRead,
-		BytesUploaded: b.BytesUploaded,
-		Owner:         b.Selector.DiscreteOwner,
+		ID:               b.ID,
+		ErrorCount:       b.ErrorCount(),
+		StartedAt:        b.StartedAt,
+		Status:           b.Status,
+		Version:          "0",
+		BytesRead:        b.BytesRead,
+		BytesUploaded:    b.BytesUploaded,
+		Owner:            b.Selector.DiscreteOwner,
+		Incremental:      b.Incremental,
+		Staging:          b.Staging,
+		BaseBackupIDs:    b.BaseBackupIDs,
+		Notes:            b.Notes,
+		Timeline:         b.Timeline,
+		Performance:      perf,
+		DriveTransitions: b.DriveTransitions,
+		Anomalies:        b.Anomalies,
 	}
 }
 
@@ -132,23 +210,44 @@ func (b Backup) Headers() []string {
 		"ID",
 		"Status",
 		"Resource Owner",
+		"Type",
+		"Base Backups",
+		"Notes",
 	}
 }
 
 // Values returns the values matching the Headers list for printing
 // out to a terminal in a columnar display.
 func (b Backup) Values() []string {
-	status := fmt.Sprintf("%s (%d errors)", b.Status, b.errorCount())
+	status := fmt.Sprintf("%s (%d errors)", b.Status, b.ErrorCount())
+
+	kind := "full"
+	if b.Incremental {
+		kind = "incremental"
+	}
+
+	if b.Staging {
+		kind += " (staging)"
+	}
+
+	bases := make([]string, 0, len(b.BaseBackupIDs))
+	for _, id := range b.BaseBackupIDs {
+		bases = append(bases, string(id))
+	}
 
 	return []string{
 		common.FormatTabularDisplayTime(b.StartedAt),
 		string(b.ID),
 		status,
 		b.Selector.DiscreteOwner,
+		kind,
+		strings.Join(bases, ", "),
+		b.Notes,
 	}
 }
 
-func (b Backup) errorCount() int {
+// ErrorCount is the number of errors recorded by the backup.
+func (b Backup) ErrorCount() int {
 	var errCount int
 
 	// current tracking
@@ -157,13 +256,136 @@ func (b Backup) errorCount() int {
 	}
 
 	// future tracking
-	if b.Errors.Err != nil || len(b.Errors.Errs) > 0 {
+	if b.Errors.Err != nil || len(b.Errors.Errs) > 0 || b.Errors.Count > 0 {
 		if b.Errors.Err != nil {
 			errCount++
 		}
 
-		errCount += len(b.Errors.Errs)
+		// backups made before errors were grouped only hold the errors.
+		if b.Errors.Count > 0 {
+			errCount += b.Errors.Count
+		} else {
+			errCount += len(b.Errors.Errs)
+		}
 	}
 
 	return errCount
 }
+
+// PrintTimeline writes the Backup's timeline to StdOut, in the format
+// requested by the caller.
+func (b Backup) PrintTimeline(ctx context.Context) {
+	if len(b.Timeline) == 0 {
+		print.Info(ctx, "No timeline was recorded for this backup")
+		return
+	}
+
+	var (
+		ps   = make([]print.Printable, 0, len(b.Timeline))
+		prev = b.StartedAt
+	)
+
+	for _, pt := range b.Timeline {
+		ps = append(ps, timelinePhase{
+			PhaseTime: pt,
+			elapsed:   pt.At.Sub(b.StartedAt),
+			duration:  pt.At.Sub(prev),
+		})
+
+		prev = pt.At
+	}
+
+	print.All(ctx, ps...)
+}
+
+// timelinePhase is a printable phase within a backup's timeline.
+type timelinePhase struct {
+	stats.PhaseTime
+	// time since the backup started
+	elapsed time.Duration
+	// time since the previous phase
+	duration time.Duration
+}
+
+func (tp timelinePhase) MinimumPrintable() any {
+	return tp.PhaseTime
+}
+
+func (tp timelinePhase) Headers() []string {
+	return []string{"Phase", "At", "Elapsed", "Since Previous"}
+}
+
+func (tp timelinePhase) Values() []string {
+	return []string{
+		tp.Phase,
+		common.FormatTabularDisplayTime(tp.At),
+		tp.elapsed.Round(time.Millisecond).String(),
+		tp.duration.Round(time.Millisecond).String(),
+	}
+}
+
+// PrintPerformance writes the Backup's folders with the lowest throughput,
+// and its slowest items, to StdOut, in the format requested by the caller.
+func (b Backup) PrintPerformance(ctx context.Context) {
+	if b.Performance.IsEmpty() {
+		print.Info(ctx, "No performance stats were recorded for this backup")
+		return
+	}
+
+	folders := make([]print.Printable, 0, len(b.Performance.Folders))
+	for _, ft := range b.Performance.Folders {
+		folders = append(folders, folderThroughput(ft))
+	}
+
+	items := make([]print.Printable, 0, len(b.Performance.SlowestItems))
+	for _, it := range b.Performance.SlowestItems {
+		items = append(items, itemTiming(it))
+	}
+
+	print.Out(ctx, "\nFolders with the lowest throughput:")
+	print.All(ctx, folders...)
+	print.Out(ctx, "\nSlowest items:")
+	print.All(ctx, items...)
+}
+
+// folderThroughput is a printable folder within a backup's performance.
+type folderThroughput stats.FolderThroughput
+
+func (ft folderThroughput) MinimumPrintable() any {
+	return stats.FolderThroughput(ft)
+}
+
+func (ft folderThroughput) Headers() []string {
+	return []string{"Folder", "Items", "Size", "Duration", "Throughput"}
+}
+
+func (ft folderThroughput) Values() []string {
+	return []string{
+		ft.Folder,
+		strconv.Itoa(ft.Items),
+		humanize.Bytes(uint64(ft.Bytes)),
+		ft.Duration.Round(time.Millisecond).String(),
+		humanize.Bytes(uint64(stats.FolderThroughput(ft).BytesPerSecond())) + "/s",
+	}
+}
+
+// itemTiming is a printable item within a backup's performance.
+type itemTiming stats.ItemTiming
+
+func (it itemTiming) MinimumPrintable() any {
+	return stats.ItemTiming(it)
+}
+
+func (it itemTiming) Headers() []string {
+	return []string{"Item", "Folder", "Size", "Duration", "Retries"}
+}
+
+func (it itemTiming) Values() []string {
+	return []string{
+		it.Item,
+		it.Folder,
+		humanize.Bytes(uint64(it.Bytes)),
+		it.Duration.Round(time.Millisecond).String(),
+		strconv.Itoa(it.Retries),
+	}
+}
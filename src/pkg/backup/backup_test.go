@@ -36,11 +36,20 @@ func stubBackup(t time.Time) backup.Backup {
 				model.ServiceTag: sel.PathService().String(),
 			},
 		},
-		CreationTime: t,
-		SnapshotID:   "snapshot",
-		DetailsID:    "details",
-		Status:       "status",
-		Selector:     sel.Selector,
+		CreationTime:  t,
+		SnapshotID:    "snapshot",
+		DetailsID:     "details",
+		Status:        "status",
+		Selector:      sel.Selector,
+		Notes:         "notes",
+		Incremental:   true,
+		BaseBackupIDs: []model.StableID{"base1", "base2"},
+		Timeline: stats.Timeline{
+			{Phase: stats.PhaseDiscoveryStarted, At: t},
+		},
+		Performance: stats.Performance{
+			SlowestItems: []stats.ItemTiming{{Folder: "Inbox", Item: "item", Duration: time.Second}},
+		},
 		Errors: fault.ErrorsData{
 			Errs: []error{errors.New("read"), errors.New("write")},
 		},
@@ -71,6 +80,9 @@ func (suite *BackupSuite) TestBackup_HeadersValues() {
 		"ID",
 		"Status",
 		"Resource Owner",
+		"Type",
+		"Base Backups",
+		"Notes",
 	}
 	hs := b.Headers()
 	assert.Equal(t, expectHs, hs)
@@ -81,10 +93,16 @@ func (suite *BackupSuite) TestBackup_HeadersValues() {
 		"id",
 		"status (2 errors)",
 		"test",
+		"incremental",
+		"base1, base2",
+		"notes",
 	}
 
 	vs := b.Values()
 	assert.Equal(t, expectVs, vs)
+
+	b.Staging = true
+	assert.Equal(t, "incremental (staging)", b.Values()[4])
 }
 
 func (suite *BackupSuite) TestBackup_MinimumPrintable() {
@@ -103,4 +121,41 @@ func (suite *BackupSuite) TestBackup_MinimumPrintable() {
 	assert.Equal(t, b.BytesRead, result.BytesRead, "size")
 	assert.Equal(t, b.BytesUploaded, result.BytesUploaded, "stored size")
 	assert.Equal(t, b.Selector.DiscreteOwner, result.Owner, "owner")
+	assert.Equal(t, b.Incremental, result.Incremental, "incremental")
+	assert.Equal(t, b.BaseBackupIDs, result.BaseBackupIDs, "base backup ids")
+	assert.Equal(t, b.Notes, result.Notes, "notes")
+	assert.Equal(t, b.Timeline, result.Timeline, "timeline")
+	assert.Equal(t, &b.Performance, result.Performance, "performance")
+}
+
+func (suite *BackupSuite) TestBackup_ErrorCount() {
+	table := []struct {
+		name   string
+		errs   fault.ErrorsData
+		expect int
+	}{
+		{
+			name:   "none",
+			expect: 0,
+		},
+		{
+			name:   "ungrouped",
+			errs:   fault.ErrorsData{Err: errors.New("fail"), Errs: []error{errors.New("1")}},
+			expect: 2,
+		},
+		{
+			name: "grouped",
+			errs: fault.ErrorsData{
+				Errs:  []error{errors.New("1")},
+				Count: 40,
+			},
+			expect: 40,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			b := backup.Backup{Errors: test.errs}
+			assert.Equal(suite.T(), test.expect, b.ErrorCount())
+		})
+	}
 }
@@ -0,0 +1,155 @@
+package details
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AnomalyKind names a suspicious pattern of changes between two backups.
+type AnomalyKind string
+
+const (
+	// MassDeletion flags a backup from which a large fraction of its base's
+	// items were deleted.
+	MassDeletion AnomalyKind = "mass_deletion"
+	// ExtensionChange flags a backup in which a large fraction of its
+	// base's files were renamed with a different extension, as happens
+	// when ransomware encrypts files.
+	ExtensionChange AnomalyKind = "extension_change"
+)
+
+// Anomaly is a suspicious pattern of changes found by comparing a backup's
+// details against the details of its base.
+type Anomaly struct {
+	Kind AnomalyKind `json:"kind"`
+	// Items is the count of items that fit the pattern, out of the Total
+	// items in the base that were considered.
+	Items int `json:"items"`
+	Total int `json:"total"`
+	// Extension is the most common extension of the renamed files.  Only
+	// populated for ExtensionChange anomalies.
+	Extension string `json:"extension,omitempty"`
+}
+
+// AnomalyThresholds sets the fractions of a base's items that must fit a
+// pattern for the pattern to be flagged.
+type AnomalyThresholds struct {
+	// MinItems is the least number of items the base must hold before its
+	// changes are considered.  Small bases flag too easily.
+	MinItems int
+	// Deleted is the fraction of items that must be deleted.
+	Deleted float64
+	// ExtensionChanged is the fraction of files whose extension must change.
+	ExtensionChanged float64
+}
+
+// DefaultAnomalyThresholds flags a backup once half of the items in a
+// base of at least 100 items fit a pattern.
+var DefaultAnomalyThresholds = AnomalyThresholds{
+	MinItems:         100,
+	Deleted:          0.5,
+	ExtensionChanged: 0.5,
+}
+
+// Anomalies compares the items of this model against those of the base,
+// and returns the suspicious patterns of changes that reach the thresholds.
+// Items are matched by their repoRef.  Extension changes only consider the
+// files of OneDrive and SharePoint, whose names hold an extension.
+func (dm DetailsModel) Anomalies(base DetailsModel, th AnomalyThresholds) []Anomaly {
+	var (
+		anomalies  = []Anomaly{}
+		baseItems  = base.Items()
+		current    = map[string]*DetailsEntry{}
+		deleted    int
+		files      int
+		renamed    int
+		extensions = map[string]int{}
+	)
+
+	if len(baseItems) == 0 || len(baseItems) < th.MinItems {
+		return anomalies
+	}
+
+	for _, ent := range dm.Items() {
+		current[ent.RepoRef] = ent
+	}
+
+	for _, old := range baseItems {
+		ent, ok := current[old.RepoRef]
+		if !ok {
+			deleted++
+			continue
+		}
+
+		oldExt := fileExtension(*old)
+		if len(oldExt) == 0 {
+			continue
+		}
+
+		files++
+
+		if ext := fileExtension(*ent); !strings.EqualFold(ext, oldExt) {
+			renamed++
+			extensions[ext]++
+		}
+	}
+
+	if th.Deleted > 0 && float64(deleted) >= th.Deleted*float64(len(baseItems)) {
+		anomalies = append(anomalies, Anomaly{
+			Kind:  MassDeletion,
+			Items: deleted,
+			Total: len(baseItems),
+		})
+	}
+
+	if th.ExtensionChanged > 0 && files > 0 && float64(renamed) >= th.ExtensionChanged*float64(files) {
+		anomalies = append(anomalies, Anomaly{
+			Kind:      ExtensionChange,
+			Items:     renamed,
+			Total:     files,
+			Extension: mostCommon(extensions),
+		})
+	}
+
+	return anomalies
+}
+
+// fileExtension returns the extension of drive files, or an empty string
+// for items that aren't files, or whose names have no extension.
+func fileExtension(de DetailsEntry) string {
+	var name string
+
+	switch {
+	case de.OneDrive != nil:
+		name = de.OneDrive.ItemName
+	case de.SharePoint != nil:
+		name = de.SharePoint.ItemName
+	}
+
+	return filepath.Ext(name)
+}
+
+// mostCommon returns the key with the highest count, preferring the
+// lowest key among ties.
+func mostCommon(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var (
+		best  string
+		count int
+	)
+
+	for _, k := range keys {
+		if counts[k] > count {
+			best, count = k, counts[k]
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,143 @@
+package details
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type AnomalyUnitSuite struct {
+	suite.Suite
+}
+
+func TestAnomalyUnitSuite(t *testing.T) {
+	suite.Run(t, new(AnomalyUnitSuite))
+}
+
+func (suite *AnomalyUnitSuite) file(id, name string) DetailsEntry {
+	p, err := path.Builder{}.
+		Append("drive", "root:", id).
+		ToDataLayerOneDrivePath("tenant", "owner", true)
+	require.NoError(suite.T(), err)
+
+	return DetailsEntry{
+		RepoRef:  p.String(),
+		ShortRef: p.ShortRef(),
+		ItemInfo: ItemInfo{
+			OneDrive: &OneDriveInfo{
+				ItemType: OneDriveItem,
+				ItemName: name,
+			},
+		},
+	}
+}
+
+// files produces count files, named with the extension.
+func (suite *AnomalyUnitSuite) files(count int, ext string) []DetailsEntry {
+	res := make([]DetailsEntry, 0, count)
+
+	for i := 0; i < count; i++ {
+		res = append(res, suite.file(fmt.Sprintf("id%d", i), fmt.Sprintf("file%d%s", i, ext)))
+	}
+
+	return res
+}
+
+func (suite *AnomalyUnitSuite) TestDetailsModel_Anomalies() {
+	var (
+		base       = suite.files(10, ".docx")
+		th         = AnomalyThresholds{MinItems: 10, Deleted: 0.5, ExtensionChanged: 0.5}
+		encrypted  = suite.files(10, ".docx.locked")
+		tombstones = suite.files(10, ".docx")
+	)
+
+	for i := range tombstones {
+		tombstones[i].Deleted = true
+	}
+
+	table := []struct {
+		name    string
+		base    []DetailsEntry
+		current []DetailsEntry
+		th      AnomalyThresholds
+		expect  []Anomaly
+	}{
+		{
+			name:    "unchanged",
+			base:    base,
+			current: base,
+			th:      th,
+			expect:  []Anomaly{},
+		},
+		{
+			name:    "some deleted",
+			base:    base,
+			current: base[:6],
+			th:      th,
+			expect:  []Anomaly{},
+		},
+		{
+			name:    "half deleted",
+			base:    base,
+			current: base[:5],
+			th:      th,
+			expect:  []Anomaly{{Kind: MassDeletion, Items: 5, Total: 10}},
+		},
+		{
+			name:    "deleted as tombstones",
+			base:    base,
+			current: tombstones,
+			th:      th,
+			expect:  []Anomaly{{Kind: MassDeletion, Items: 10, Total: 10}},
+		},
+		{
+			name:    "extensions changed",
+			base:    base,
+			current: append(append([]DetailsEntry{}, encrypted[:7]...), base[7:]...),
+			th:      th,
+			expect:  []Anomaly{{Kind: ExtensionChange, Items: 7, Total: 10, Extension: ".locked"}},
+		},
+		{
+			name:    "few extensions changed",
+			base:    base,
+			current: append(append([]DetailsEntry{}, encrypted[:4]...), base[4:]...),
+			th:      th,
+			expect:  []Anomaly{},
+		},
+		{
+			name:    "base too small",
+			base:    base,
+			current: nil,
+			th:      AnomalyThresholds{MinItems: 11, Deleted: 0.5},
+			expect:  []Anomaly{},
+		},
+		{
+			name:    "disabled thresholds",
+			base:    base,
+			current: encrypted[:1],
+			th:      AnomalyThresholds{},
+			expect:  []Anomaly{},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			var (
+				bdm = DetailsModel{Entries: test.base}
+				dm  = DetailsModel{Entries: test.current}
+			)
+
+			assert.Equal(suite.T(), test.expect, dm.Anomalies(bdm, test.th))
+		})
+	}
+}
+
+func (suite *AnomalyUnitSuite) TestMostCommon() {
+	assert.Equal(suite.T(), "", mostCommon(nil))
+	assert.Equal(suite.T(), ".a", mostCommon(map[string]int{".b": 1, ".a": 1}))
+	assert.Equal(suite.T(), ".b", mostCommon(map[string]int{"": 1, ".a": 1, ".b": 2}))
+}
@@ -3,6 +3,7 @@ package details
 import (
 	"context"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,21 +34,33 @@ type DetailsModel struct {
 }
 
 // Print writes the DetailModel Entries to StdOut, in the format
-// requested by the caller.
-func (dm DetailsModel) PrintEntries(ctx context.Context) {
+// requested by the caller.  If columns are provided, tabular output
+// is restricted to those columns.  See ValidateColumns.
+func (dm DetailsModel) PrintEntries(ctx context.Context, columns ...string) {
 	sl := dm.FilterMetaFiles()
 
 	if print.JSONFormat() {
 		printJSON(ctx, sl)
 	} else {
-		printTable(ctx, sl)
+		printTable(ctx, sl, columns)
 	}
 }
 
-func printTable(ctx context.Context, dm DetailsModel) {
+func printTable(ctx context.Context, dm DetailsModel, columns []string) {
 	perType := map[ItemType][]print.Printable{}
 
 	for _, de := range dm.Entries {
+		var p print.Printable = de
+
+		if len(columns) > 0 {
+			ce := de.selectColumns(columns)
+			if len(ce.headers) == 0 {
+				continue
+			}
+
+			p = ce
+		}
+
 		it := de.infoType()
 		ps, ok := perType[it]
 
@@ -55,7 +68,7 @@ func printTable(ctx context.Context, dm DetailsModel) {
 			ps = []print.Printable{}
 		}
 
-		perType[it] = append(ps, print.Printable(de))
+		perType[it] = append(ps, p)
 	}
 
 	for _, ps := range perType {
@@ -73,13 +86,13 @@ func printJSON(ctx context.Context, dm DetailsModel) {
 	print.All(ctx, ents...)
 }
 
-// Paths returns the list of Paths for non-folder and non-meta items extracted
-// from the Entries slice.
+// Paths returns the list of Paths for non-folder, non-meta, and non-deleted
+// items extracted from the Entries slice.
 func (dm DetailsModel) Paths() []string {
 	r := make([]string, 0, len(dm.Entries))
 
 	for _, ent := range dm.Entries {
-		if ent.Folder != nil || ent.isMetaFile() {
+		if ent.Folder != nil || ent.isMetaFile() || ent.Deleted {
 			continue
 		}
 
@@ -91,13 +104,14 @@ func (dm DetailsModel) Paths() []string {
 
 // Items returns a slice of *ItemInfo that does not contain any FolderInfo
 // entries. Required because not all folders in the details are valid resource
-// paths, and we want to slice out metadata.
+// paths, and we want to slice out metadata.  Tombstones are also excluded,
+// since their items no longer exist in the backup.
 func (dm DetailsModel) Items() []*DetailsEntry {
 	res := make([]*DetailsEntry, 0, len(dm.Entries))
 
 	for i := 0; i < len(dm.Entries); i++ {
 		ent := dm.Entries[i]
-		if ent.Folder != nil || ent.isMetaFile() {
+		if ent.Folder != nil || ent.isMetaFile() || ent.Deleted {
 			continue
 		}
 
@@ -107,6 +121,34 @@ func (dm DetailsModel) Items() []*DetailsEntry {
 	return res
 }
 
+// Item returns the item entry whose ShortRef or RepoRef matches the ref.
+// Folders, metadata, and tombstones are never matched.  Returns nil if no
+// item matches.
+func (dm DetailsModel) Item(ref string) *DetailsEntry {
+	for _, ent := range dm.Items() {
+		if ent.ShortRef == ref || ent.RepoRef == ref {
+			return ent
+		}
+	}
+
+	return nil
+}
+
+// Tombstones returns the entries of items that existed in a base backup,
+// but were deleted before this backup was made.
+func (dm DetailsModel) Tombstones() []*DetailsEntry {
+	res := []*DetailsEntry{}
+
+	for i := 0; i < len(dm.Entries); i++ {
+		ent := dm.Entries[i]
+		if ent.Deleted {
+			res = append(res, &ent)
+		}
+	}
+
+	return res
+}
+
 // FilterMetaFiles returns a copy of the Details with all of the
 // .meta files removed from the entries.
 func (dm DetailsModel) FilterMetaFiles() DetailsModel {
@@ -139,16 +181,184 @@ type Builder struct {
 	d            Details
 	mu           sync.Mutex             `json:"-"`
 	knownFolders map[string]folderEntry `json:"-"`
+	// names of the items that replaced an item from a base backup.
+	changed map[string]struct{} `json:"-"`
+	// observer, if set, is called with the short ref of each added item.
+	observer func(shortRef string)
+}
+
+// Observe calls fn with the ShortRef of each item added to the builder,
+// once the item is added.  Lets restores checkpoint the items restored so
+// far while the restore is still running.
+func (b *Builder) Observe(fn func(shortRef string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.observer = fn
+}
+
+type observerKey struct{}
+
+// SetObserver embeds the builder observer within the context.
+func SetObserver(ctx context.Context, fn func(shortRef string)) context.Context {
+	if fn == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, observerKey{}, fn)
+}
+
+// ObserverCtx retrieves the builder observer embedded in the context.
+// Returns nil if no observer was set.
+func ObserverCtx(ctx context.Context) func(shortRef string) {
+	fn, _ := ctx.Value(observerKey{}).(func(shortRef string))
+	return fn
 }
 
 func (b *Builder) Add(
 	repoRef, shortRef, parentRef, locationRef string,
 	updated bool,
 	info ItemInfo,
+) {
+	b.AddWithExtensions(repoRef, shortRef, parentRef, locationRef, updated, info, nil)
+}
+
+// AddWithExtensions adds an entry which carries the extensions, such as
+// an entry merged from a base backup.
+func (b *Builder) AddWithExtensions(
+	repoRef, shortRef, parentRef, locationRef string,
+	updated bool,
+	info ItemInfo,
+	ext map[string]string,
 ) {
+	b.mu.Lock()
+	b.d.add(repoRef, shortRef, parentRef, locationRef, updated, info, ext)
+	observer := b.observer
+	b.mu.Unlock()
+
+	if observer != nil {
+		observer(shortRef)
+	}
+}
+
+// AddTombstones records each of the base entries as deleted, unless an
+// item with the same name was already added to the builder.  Items are
+// compared by name (the final element of the repoRef), since moved items
+// retain their name, but not their repoRef.
+func (b *Builder) AddTombstones(base []DetailsEntry) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.d.add(repoRef, shortRef, parentRef, locationRef, updated, info)
+
+	live := map[string]struct{}{}
+
+	for _, ent := range b.d.Entries {
+		live[itemName(ent.RepoRef)] = struct{}{}
+	}
+
+	for _, ent := range base {
+		if _, ok := live[itemName(ent.RepoRef)]; ok {
+			b.markChanged(itemName(ent.RepoRef))
+			continue
+		}
+
+		b.d.Entries = append(b.d.Entries, DetailsEntry{
+			RepoRef:     ent.RepoRef,
+			ShortRef:    ent.ShortRef,
+			ParentRef:   ent.ParentRef,
+			LocationRef: ent.LocationRef,
+			Deleted:     true,
+			Extensions:  copyExtensions(ent.Extensions),
+			ItemInfo:    ent.ItemInfo,
+		})
+	}
+}
+
+// MarkChanged records that the item, identified by its repoRef, replaced
+// an item from a base backup, such as when the item was moved.
+func (b *Builder) MarkChanged(repoRef string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.markChanged(itemName(repoRef))
+}
+
+func (b *Builder) markChanged(name string) {
+	if b.changed == nil {
+		b.changed = map[string]struct{}{}
+	}
+
+	b.changed[name] = struct{}{}
+}
+
+// CategorySummary counts the items of a single category within a backup.
+// Items and Bytes cover every item in the backup.  Of those items, New
+// items were added in this backup, Changed items replaced an item from a
+// base backup, and Unchanged items were carried over from a base backup.
+// Deleted counts the items removed since the base backup.
+type CategorySummary struct {
+	Items     int   `json:"items"`
+	Bytes     int64 `json:"bytes"`
+	New       int   `json:"new"`
+	Changed   int   `json:"changed"`
+	Unchanged int   `json:"unchanged"`
+	Deleted   int   `json:"deleted"`
+}
+
+// Summary produces a summary of the items added to the builder, keyed by
+// the items' path category (ex: "email", "files").
+func (b *Builder) Summary() map[string]CategorySummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := map[string]CategorySummary{}
+
+	for _, ent := range b.d.Entries {
+		if ent.Folder != nil || ent.isMetaFile() {
+			continue
+		}
+
+		cat := path.UnknownCategory.String()
+
+		if p, err := path.FromDataLayerPath(ent.RepoRef, true); err == nil {
+			cat = p.Category().String()
+		}
+
+		cs := res[cat]
+
+		switch {
+		case ent.Deleted:
+			cs.Deleted++
+			res[cat] = cs
+
+			continue
+
+		case !ent.Updated:
+			cs.Unchanged++
+
+		default:
+			if _, ok := b.changed[itemName(ent.RepoRef)]; ok {
+				cs.Changed++
+			} else {
+				cs.New++
+			}
+		}
+
+		cs.Items++
+		cs.Bytes += ent.Size()
+		res[cat] = cs
+	}
+
+	return res
+}
+
+// itemName returns the final element of the repoRef.
+func itemName(repoRef string) string {
+	p, err := path.FromDataLayerPath(repoRef, true)
+	if err != nil {
+		return repoRef
+	}
+
+	return p.Item()
 }
 
 func (b *Builder) Details() *Details {
@@ -245,7 +455,7 @@ func (b *Builder) AddFoldersForItem(folders []folderEntry, itemInfo ItemInfo, up
 		// Update the folder's size and modified time
 		itemModified := itemInfo.Modified()
 
-		folder.Info.Folder.Size += itemInfo.size()
+		folder.Info.Folder.Size += itemInfo.Size()
 
 		if folder.Info.Folder.Modified.Before(itemModified) {
 			folder.Info.Folder.Modified = itemModified
@@ -276,6 +486,7 @@ func (d *Details) add(
 	repoRef, shortRef, parentRef, locationRef string,
 	updated bool,
 	info ItemInfo,
+	ext map[string]string,
 ) {
 	d.Entries = append(d.Entries, DetailsEntry{
 		RepoRef:     repoRef,
@@ -283,6 +494,7 @@ func (d *Details) add(
 		ParentRef:   parentRef,
 		LocationRef: locationRef,
 		Updated:     updated,
+		Extensions:  copyExtensions(ext),
 		ItemInfo:    info,
 	})
 }
@@ -323,6 +535,16 @@ type DetailsEntry struct {
 	// Always `true` for full backups
 	Updated bool `json:"updated"`
 
+	// Deleted marks a tombstone: the item existed in a base backup, but
+	// was deleted before this backup was made.  Tombstones retain the
+	// item info recorded by the base.
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Extensions hold custom attributes attached by post-processing, such
+	// as classifications or hashes, keyed by namespaced extension keys.
+	// See SetExtension.
+	Extensions map[string]string `json:"extensions,omitempty"`
+
 	ItemInfo
 }
 
@@ -342,48 +564,179 @@ func (de DetailsEntry) MinimumPrintable() any {
 // Headers returns the human-readable names of properties in a DetailsEntry
 // for printing out to a terminal in a columnar display.
 func (de DetailsEntry) Headers() []string {
-	hs := []string{"ID"}
+	return defaultHeaders(de.columns())
+}
+
+// Values returns the values matching the Headers list.
+func (de DetailsEntry) Values() []string {
+	return defaultValues(de.columns())
+}
+
+func (de DetailsEntry) columns() []column {
+	cs := []column{{header: "ID", value: de.ShortRef, def: true}}
 
 	if de.ItemInfo.Folder != nil {
-		hs = append(hs, de.ItemInfo.Folder.Headers()...)
+		cs = append(cs, de.ItemInfo.Folder.columns()...)
 	}
 
 	if de.ItemInfo.Exchange != nil {
-		hs = append(hs, de.ItemInfo.Exchange.Headers()...)
+		cs = append(cs, de.ItemInfo.Exchange.columns()...)
 	}
 
 	if de.ItemInfo.SharePoint != nil {
-		hs = append(hs, de.ItemInfo.SharePoint.Headers()...)
+		cs = append(cs, de.ItemInfo.SharePoint.columns()...)
 	}
 
 	if de.ItemInfo.OneDrive != nil {
-		hs = append(hs, de.ItemInfo.OneDrive.Headers()...)
+		cs = append(cs, de.ItemInfo.OneDrive.columns()...)
+	}
+
+	return append(cs, de.extensionColumns()...)
+}
+
+// selectColumns restricts the entry's tabular output to the named
+// columns, in the order they were named.  Names that don't apply to
+// the entry's type are skipped.
+func (de DetailsEntry) selectColumns(names []string) columnsEntry {
+	var (
+		ce = columnsEntry{DetailsEntry: de}
+		cs = map[string]column{}
+	)
+
+	for _, c := range de.columns() {
+		cs[normalizeColumn(c.header)] = c
+	}
+
+	for _, n := range names {
+		c, ok := cs[normalizeColumn(n)]
+
+		// entries without the extension still fill its column.
+		if !ok && ValidateExtensionKey(n) == nil {
+			c, ok = column{header: n}, true
+		}
+
+		if !ok {
+			continue
+		}
+
+		ce.headers = append(ce.headers, c.header)
+		ce.values = append(ce.values, c.value)
+	}
+
+	return ce
+}
+
+// columnsEntry is a DetailsEntry whose tabular output is restricted
+// to a selection of its columns.
+type columnsEntry struct {
+	DetailsEntry
+	headers []string
+	values  []string
+}
+
+func (ce columnsEntry) Headers() []string {
+	return ce.headers
+}
+
+func (ce columnsEntry) Values() []string {
+	return ce.values
+}
+
+// ---------------------------------------------------------------------------
+// Columns
+// ---------------------------------------------------------------------------
+
+// column is a single property in the columnar display of an entry.
+// Default columns are displayed unless the caller selects other columns.
+type column struct {
+	header string
+	value  string
+	def    bool
+}
+
+func defaultHeaders(cs []column) []string {
+	hs := []string{}
+
+	for _, c := range cs {
+		if c.def {
+			hs = append(hs, c.header)
+		}
 	}
 
 	return hs
 }
 
-// Values returns the values matching the Headers list.
-func (de DetailsEntry) Values() []string {
-	vs := []string{de.ShortRef}
+func defaultValues(cs []column) []string {
+	vs := []string{}
 
-	if de.ItemInfo.Folder != nil {
-		vs = append(vs, de.ItemInfo.Folder.Values()...)
+	for _, c := range cs {
+		if c.def {
+			vs = append(vs, c.value)
+		}
 	}
 
-	if de.ItemInfo.Exchange != nil {
-		vs = append(vs, de.ItemInfo.Exchange.Values()...)
+	return vs
+}
+
+// normalizeColumn allows column names to be matched regardless of
+// their case and spacing.
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+// Columns returns the names of every column that can be selected in
+// the columnar display of the details entries.
+func Columns() []string {
+	infos := []ItemInfo{
+		{Folder: &FolderInfo{}},
+		{Exchange: &ExchangeInfo{ItemType: ExchangeMail}},
+		{Exchange: &ExchangeInfo{ItemType: ExchangeEvent}},
+		{Exchange: &ExchangeInfo{ItemType: ExchangeContact}},
+		{Exchange: &ExchangeInfo{ItemType: ExchangeTask}},
+		{SharePoint: &SharePointInfo{}},
+		{OneDrive: &OneDriveInfo{}},
 	}
 
-	if de.ItemInfo.SharePoint != nil {
-		vs = append(vs, de.ItemInfo.SharePoint.Values()...)
+	var (
+		names = []string{}
+		seen  = map[string]struct{}{}
+	)
+
+	for _, info := range infos {
+		for _, c := range (DetailsEntry{ItemInfo: info}).columns() {
+			if _, ok := seen[c.header]; ok {
+				continue
+			}
+
+			seen[c.header] = struct{}{}
+			names = append(names, c.header)
+		}
 	}
 
-	if de.ItemInfo.OneDrive != nil {
-		vs = append(vs, de.ItemInfo.OneDrive.Values()...)
+	return names
+}
+
+// ValidateColumns returns an error if any of the names does not
+// identify a column produced by Columns.
+func ValidateColumns(names []string) error {
+	known := map[string]struct{}{}
+
+	for _, c := range Columns() {
+		known[normalizeColumn(c)] = struct{}{}
 	}
 
-	return vs
+	for _, n := range names {
+		// extension columns are only known once the entries are read.
+		if ValidateExtensionKey(n) == nil {
+			continue
+		}
+
+		if _, ok := known[normalizeColumn(n)]; !ok {
+			return clues.New("unknown column: " + n + "; must be one of: " + strings.Join(Columns(), ", "))
+		}
+	}
+
+	return nil
 }
 
 type ItemType int
@@ -401,6 +754,10 @@ const (
 	OneDriveItem ItemType = iota + 200
 
 	FolderItem ItemType = iota + 300
+
+	// item types are persisted in backup details, so later additions are
+	// pinned to a value instead of shifting the sequence above.
+	ExchangeTask ItemType = ExchangeMail + 1
 )
 
 func UpdateItem(item *ItemInfo, repoPath path.Path) error {
@@ -453,7 +810,8 @@ func (i ItemInfo) infoType() ItemType {
 	return UnknownType
 }
 
-func (i ItemInfo) size() int64 {
+// Size is the size of the item, in bytes.
+func (i ItemInfo) Size() int64 {
 	switch {
 	case i.Exchange != nil:
 		return i.Exchange.Size
@@ -497,11 +855,19 @@ type FolderInfo struct {
 }
 
 func (i FolderInfo) Headers() []string {
-	return []string{"Display Name"}
+	return defaultHeaders(i.columns())
 }
 
 func (i FolderInfo) Values() []string {
-	return []string{i.DisplayName}
+	return defaultValues(i.columns())
+}
+
+func (i FolderInfo) columns() []column {
+	return []column{
+		{header: "Display Name", value: i.DisplayName, def: true},
+		{header: "Size", value: humanize.Bytes(uint64(i.Size))},
+		{header: "Modified", value: common.FormatTabularDisplayTime(i.Modified)},
+	}
 }
 
 // ExchangeInfo describes an exchange item
@@ -518,54 +884,87 @@ type ExchangeInfo struct {
 	Created     time.Time `json:"created,omitempty"`
 	Modified    time.Time `json:"modified,omitempty"`
 	Size        int64     `json:"size,omitempty"`
+
+	// MessageID is the mail's internet Message-ID header.
+	MessageID string `json:"messageID,omitempty"`
+	// InReplyTo is the Message-ID of the mail that this mail replies to.
+	InReplyTo string `json:"inReplyTo,omitempty"`
+	// ReceivedChain summarizes the mail's Received headers, one entry per
+	// relay hop, most recent first.
+	ReceivedChain []string `json:"receivedChain,omitempty"`
+	// PhotoSize is the size, in bytes, of the contact's photo, if it has one.
+	PhotoSize int64 `json:"photoSize,omitempty"`
+	// TaskDue is the date on which the task is due, if it has one.
+	TaskDue time.Time `json:"taskDue,omitempty"`
+	// TaskStatus is the task's completion status, such as notStarted or completed.
+	TaskStatus string `json:"taskStatus,omitempty"`
+	// FolderID is the ID of the folder that contains the item.  Unlike
+	// the item's location, it remains the same if the folder is renamed.
+	FolderID string `json:"folderID,omitempty"`
 }
 
 // Headers returns the human-readable names of properties in an ExchangeInfo
 // for printing out to a terminal in a columnar display.
 func (i ExchangeInfo) Headers() []string {
-	switch i.ItemType {
-	case ExchangeEvent:
-		return []string{"Organizer", "Subject", "Starts", "Ends", "Recurring"}
-
-	case ExchangeContact:
-		return []string{"Contact Name"}
-
-	case ExchangeMail:
-		return []string{"Sender", "Subject", "Received"}
-	}
-
-	return []string{}
+	return defaultHeaders(i.columns())
 }
 
 // Values returns the values matching the Headers list for printing
 // out to a terminal in a columnar display.
 func (i ExchangeInfo) Values() []string {
+	return defaultValues(i.columns())
+}
+
+func (i ExchangeInfo) columns() []column {
+	var cs []column
+
 	switch i.ItemType {
 	case ExchangeEvent:
-		return []string{
-			i.Organizer,
-			i.Subject,
-			common.FormatTabularDisplayTime(i.EventStart),
-			common.FormatTabularDisplayTime(i.EventEnd),
-			strconv.FormatBool(i.EventRecurs),
+		cs = []column{
+			{header: "Organizer", value: i.Organizer, def: true},
+			{header: "Subject", value: i.Subject, def: true},
+			{header: "Starts", value: common.FormatTabularDisplayTime(i.EventStart), def: true},
+			{header: "Ends", value: common.FormatTabularDisplayTime(i.EventEnd), def: true},
+			{header: "Recurring", value: strconv.FormatBool(i.EventRecurs), def: true},
 		}
 
 	case ExchangeContact:
-		return []string{i.ContactName}
+		cs = []column{
+			{header: "Contact Name", value: i.ContactName, def: true},
+			{header: "PhotoSize", value: humanize.Bytes(uint64(i.PhotoSize))},
+		}
 
 	case ExchangeMail:
-		return []string{
-			i.Sender, i.Subject,
-			common.FormatTabularDisplayTime(i.Received),
+		cs = []column{
+			{header: "Sender", value: i.Sender, def: true},
+			{header: "Subject", value: i.Subject, def: true},
+			{header: "Received", value: common.FormatTabularDisplayTime(i.Received), def: true},
+			{header: "MessageID", value: i.MessageID},
+			{header: "FolderID", value: i.FolderID},
+		}
+
+	case ExchangeTask:
+		cs = []column{
+			{header: "Subject", value: i.Subject, def: true},
+			{header: "Status", value: i.TaskStatus, def: true},
+			{header: "Due", value: common.FormatTabularDisplayTime(i.TaskDue), def: true},
 		}
+
+	default:
+		return []column{}
 	}
 
-	return []string{}
+	return append(
+		cs,
+		column{header: "Size", value: humanize.Bytes(uint64(i.Size))},
+		column{header: "Created", value: common.FormatTabularDisplayTime(i.Created)},
+		column{header: "Modified", value: common.FormatTabularDisplayTime(i.Modified)})
 }
 
 // SharePointInfo describes a sharepoint item
 type SharePointInfo struct {
 	Created    time.Time `json:"created,omitempty"`
+	CreatedBy  string    `json:"createdBy,omitempty"`
 	ItemName   string    `json:"itemName,omitempty"`
 	DriveName  string    `json:"driveName,omitempty"`
 	ItemType   ItemType  `json:"itemType,omitempty"`
@@ -574,25 +973,43 @@ type SharePointInfo struct {
 	ParentPath string    `json:"parentPath,omitempty"`
 	Size       int64     `json:"size,omitempty"`
 	WebURL     string    `json:"webUrl,omitempty"`
+
+	// ContentType is the item's MIME type.
+	ContentType string `json:"contentType,omitempty"`
+	// SensitivityLabel is the name of the sensitivity label assigned
+	// to the item, if any.
+	SensitivityLabel string `json:"sensitivityLabel,omitempty"`
+	// ItemID and ParentID are the drive item IDs of the item and its
+	// parent folder.  They stay stable when the item is moved or renamed.
+	ItemID   string `json:"itemID,omitempty"`
+	ParentID string `json:"parentID,omitempty"`
 }
 
 // Headers returns the human-readable names of properties in a SharePointInfo
 // for printing out to a terminal in a columnar display.
 func (i SharePointInfo) Headers() []string {
-	return []string{"ItemName", "Drive", "ParentPath", "Size", "WebURL", "Created", "Modified"}
+	return defaultHeaders(i.columns())
 }
 
 // Values returns the values matching the Headers list for printing
 // out to a terminal in a columnar display.
 func (i SharePointInfo) Values() []string {
-	return []string{
-		i.ItemName,
-		i.DriveName,
-		i.ParentPath,
-		humanize.Bytes(uint64(i.Size)),
-		i.WebURL,
-		common.FormatTabularDisplayTime(i.Created),
-		common.FormatTabularDisplayTime(i.Modified),
+	return defaultValues(i.columns())
+}
+
+func (i SharePointInfo) columns() []column {
+	return []column{
+		{header: "ItemName", value: i.ItemName, def: true},
+		{header: "Drive", value: i.DriveName, def: true},
+		{header: "ParentPath", value: i.ParentPath, def: true},
+		{header: "Size", value: humanize.Bytes(uint64(i.Size)), def: true},
+		{header: "WebURL", value: i.WebURL, def: true},
+		{header: "Created", value: common.FormatTabularDisplayTime(i.Created), def: true},
+		{header: "Modified", value: common.FormatTabularDisplayTime(i.Modified), def: true},
+		{header: "Owner", value: i.Owner},
+		{header: "CreatedBy", value: i.CreatedBy},
+		{header: "ContentType", value: i.ContentType},
+		{header: "SensitivityLabel", value: i.SensitivityLabel},
 	}
 }
 
@@ -618,24 +1035,41 @@ type OneDriveInfo struct {
 	Owner      string    `json:"owner,omitempty"`
 	ParentPath string    `json:"parentPath"`
 	Size       int64     `json:"size,omitempty"`
+
+	// ContentType is the item's MIME type.
+	ContentType string `json:"contentType,omitempty"`
+	// SensitivityLabel is the name of the sensitivity label assigned
+	// to the item, if any.
+	SensitivityLabel string `json:"sensitivityLabel,omitempty"`
+	// ItemID and ParentID are the drive item IDs of the item and its
+	// parent folder.  They stay stable when the item is moved or renamed.
+	ItemID   string `json:"itemID,omitempty"`
+	ParentID string `json:"parentID,omitempty"`
 }
 
 // Headers returns the human-readable names of properties in a OneDriveInfo
 // for printing out to a terminal in a columnar display.
 func (i OneDriveInfo) Headers() []string {
-	return []string{"ItemName", "ParentPath", "Size", "Owner", "Created", "Modified"}
+	return defaultHeaders(i.columns())
 }
 
 // Values returns the values matching the Headers list for printing
 // out to a terminal in a columnar display.
 func (i OneDriveInfo) Values() []string {
-	return []string{
-		i.ItemName,
-		i.ParentPath,
-		humanize.Bytes(uint64(i.Size)),
-		i.Owner,
-		common.FormatTabularDisplayTime(i.Created),
-		common.FormatTabularDisplayTime(i.Modified),
+	return defaultValues(i.columns())
+}
+
+func (i OneDriveInfo) columns() []column {
+	return []column{
+		{header: "ItemName", value: i.ItemName, def: true},
+		{header: "ParentPath", value: i.ParentPath, def: true},
+		{header: "Size", value: humanize.Bytes(uint64(i.Size)), def: true},
+		{header: "Owner", value: i.Owner, def: true},
+		{header: "Created", value: common.FormatTabularDisplayTime(i.Created), def: true},
+		{header: "Modified", value: common.FormatTabularDisplayTime(i.Modified), def: true},
+		{header: "Drive", value: i.DriveName},
+		{header: "ContentType", value: i.ContentType},
+		{header: "SensitivityLabel", value: i.SensitivityLabel},
 	}
 }
 
@@ -100,6 +100,24 @@ func (suite *DetailsUnitSuite) TestDetailsEntry_HeadersValues() {
 			expectHs: []string{"ID", "Sender", "Subject", "Received"},
 			expectVs: []string{"deadbeef", "sender", "subject", nowStr},
 		},
+		{
+			name: "exchange task info",
+			entry: DetailsEntry{
+				RepoRef:     "reporef",
+				ShortRef:    "deadbeef",
+				LocationRef: "locationref",
+				ItemInfo: ItemInfo{
+					Exchange: &ExchangeInfo{
+						ItemType:   ExchangeTask,
+						Subject:    "subject",
+						TaskStatus: "completed",
+						TaskDue:    now,
+					},
+				},
+			},
+			expectHs: []string{"ID", "Subject", "Status", "Due"},
+			expectVs: []string{"deadbeef", "subject", "completed", nowStr},
+		},
 		{
 			name: "sharepoint info",
 			entry: DetailsEntry{
@@ -162,6 +180,79 @@ func (suite *DetailsUnitSuite) TestDetailsEntry_HeadersValues() {
 	}
 }
 
+func (suite *DetailsUnitSuite) TestDetailsEntry_selectColumns() {
+	entry := DetailsEntry{
+		RepoRef:  "reporef",
+		ShortRef: "deadbeef",
+		ItemInfo: ItemInfo{
+			OneDrive: &OneDriveInfo{
+				ItemName:   "itemName",
+				DriveName:  "driveName",
+				ParentPath: "parentPath",
+				Size:       1000,
+			},
+		},
+	}
+
+	table := []struct {
+		name     string
+		columns  []string
+		expectHs []string
+		expectVs []string
+	}{
+		{
+			name:     "default columns",
+			columns:  []string{"ID", "ItemName"},
+			expectHs: []string{"ID", "ItemName"},
+			expectVs: []string{"deadbeef", "itemName"},
+		},
+		{
+			name:     "non-default column",
+			columns:  []string{"Drive"},
+			expectHs: []string{"Drive"},
+			expectVs: []string{"driveName"},
+		},
+		{
+			name:     "order, case, and spacing",
+			columns:  []string{"size", "parent path", "ITEMNAME"},
+			expectHs: []string{"Size", "ParentPath", "ItemName"},
+			expectVs: []string{"1.0 kB", "parentPath", "itemName"},
+		},
+		{
+			name:     "columns of other types",
+			columns:  []string{"Sender", "ItemName"},
+			expectHs: []string{"ItemName"},
+			expectVs: []string{"itemName"},
+		},
+		{
+			name:    "no matching columns",
+			columns: []string{"Sender"},
+		},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			ce := entry.selectColumns(test.columns)
+			assert.Equal(t, test.expectHs, ce.Headers())
+			assert.Equal(t, test.expectVs, ce.Values())
+			assert.Equal(t, entry, ce.MinimumPrintable())
+		})
+	}
+}
+
+func (suite *DetailsUnitSuite) TestValidateColumns() {
+	t := suite.T()
+
+	cs := Columns()
+	assert.Contains(t, cs, "ID")
+	assert.Contains(t, cs, "MessageID")
+	assert.Contains(t, cs, "WebURL")
+
+	assert.NoError(t, ValidateColumns(nil))
+	assert.NoError(t, ValidateColumns(cs))
+	assert.NoError(t, ValidateColumns([]string{"contactname", "Parent Path"}))
+	assert.Error(t, ValidateColumns([]string{"ID", "fnords"}))
+}
+
 var pathItemsTable = []struct {
 	name               string
 	ents               []DetailsEntry
@@ -287,6 +378,22 @@ var pathItemsTable = []struct {
 		expectRepoRefs:     []string{"abcde", "12345", "foo.meta"},
 		expectLocationRefs: []string{"locationref", "locationref2", "locationref.dirmeta"},
 	},
+	{
+		name: "multiple entries with tombstone",
+		ents: []DetailsEntry{
+			{
+				RepoRef:     "abcde",
+				LocationRef: "locationref",
+			},
+			{
+				RepoRef:     "12345",
+				LocationRef: "locationref2",
+				Deleted:     true,
+			},
+		},
+		expectRepoRefs:     []string{"abcde"},
+		expectLocationRefs: []string{"locationref"},
+	},
 }
 
 func (suite *DetailsUnitSuite) TestDetailsModel_Path() {
@@ -322,6 +429,44 @@ func (suite *DetailsUnitSuite) TestDetailsModel_Items() {
 	}
 }
 
+func (suite *DetailsUnitSuite) TestDetailsModel_Item() {
+	dm := DetailsModel{
+		Entries: []DetailsEntry{
+			{RepoRef: "a/item", ShortRef: "aaaa"},
+			{RepoRef: "b/item", ShortRef: "bbbb", Deleted: true},
+			{
+				RepoRef:  "c/folder",
+				ShortRef: "cccc",
+				ItemInfo: ItemInfo{Folder: &FolderInfo{DisplayName: "folder"}},
+			},
+		},
+	}
+
+	table := []struct {
+		name   string
+		ref    string
+		expect string
+	}{
+		{"by shortRef", "aaaa", "a/item"},
+		{"by repoRef", "a/item", "a/item"},
+		{"tombstone", "bbbb", ""},
+		{"folder", "cccc", ""},
+		{"unknown", "dddd", ""},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ent := dm.Item(test.ref)
+			if len(test.expect) == 0 {
+				assert.Nil(suite.T(), ent)
+				return
+			}
+
+			require.NotNil(suite.T(), ent)
+			assert.Equal(suite.T(), test.expect, ent.RepoRef)
+		})
+	}
+}
+
 func (suite *DetailsUnitSuite) TestDetailsModel_FilterMetaFiles() {
 	t := suite.T()
 
@@ -354,6 +499,91 @@ func (suite *DetailsUnitSuite) TestDetailsModel_FilterMetaFiles() {
 	assert.Len(t, d.Entries, 3)
 }
 
+func (suite *DetailsUnitSuite) TestBuilder_AddTombstones() {
+	t := suite.T()
+
+	itemPath := func(elems ...string) string {
+		p, err := path.Builder{}.
+			Append(elems...).
+			ToDataLayerOneDrivePath("tid", "uid", true)
+		require.NoError(t, err)
+
+		return p.String()
+	}
+
+	var (
+		deleted  = DetailsEntry{RepoRef: itemPath("work", "deleted"), ShortRef: "d"}
+		moved    = DetailsEntry{RepoRef: itemPath("work", "moved"), ShortRef: "m"}
+		newMoved = DetailsEntry{RepoRef: itemPath("personal", "moved"), ShortRef: "nm", Updated: true}
+		b        = &Builder{}
+	)
+
+	b.Add(newMoved.RepoRef, newMoved.ShortRef, "", "", true, newMoved.ItemInfo)
+	b.AddTombstones([]DetailsEntry{deleted, moved})
+
+	dm := b.Details().DetailsModel
+
+	deleted.Deleted = true
+
+	assert.Equal(t, []*DetailsEntry{&deleted}, dm.Tombstones())
+	assert.Equal(t, []*DetailsEntry{&newMoved}, dm.Items())
+}
+
+func (suite *DetailsUnitSuite) TestBuilder_Summary() {
+	t := suite.T()
+
+	itemPath := func(elems ...string) string {
+		p, err := path.Builder{}.
+			Append(elems...).
+			ToDataLayerOneDrivePath("tid", "uid", true)
+		require.NoError(t, err)
+
+		return p.String()
+	}
+
+	var (
+		info = func(size int64) ItemInfo {
+			return ItemInfo{OneDrive: &OneDriveInfo{ItemType: OneDriveItem, Size: size}}
+		}
+		b = &Builder{}
+	)
+
+	b.Add(itemPath("work", "new"), "n", "", "", true, info(1))
+	b.Add(itemPath("work", "edited"), "e", "", "", true, info(2))
+	b.Add(itemPath("work", "same"), "s", "", "", false, info(4))
+	b.Add(itemPath("personal", "moved"), "m", "", "", true, info(8))
+	b.MarkChanged(itemPath("personal", "moved"))
+	b.Add(
+		itemPath("work", "perms.meta"), "pm", "", "", true,
+		ItemInfo{OneDrive: &OneDriveInfo{ItemType: OneDriveItem, IsMeta: true, Size: 16}})
+	b.AddTombstones([]DetailsEntry{
+		{RepoRef: itemPath("work", "edited"), ShortRef: "e"},
+		{RepoRef: itemPath("work", "deleted"), ShortRef: "d", ItemInfo: info(32)},
+	})
+
+	expect := map[string]CategorySummary{
+		path.FilesCategory.String(): {
+			Items:     4,
+			Bytes:     15,
+			New:       1,
+			Changed:   2,
+			Unchanged: 1,
+			Deleted:   1,
+		},
+	}
+
+	assert.Equal(t, expect, b.Summary())
+
+	// folders added for the details don't affect the summary.
+	b.AddFoldersForItem(
+		[]folderEntry{{RepoRef: "rr", ShortRef: "sr", Info: ItemInfo{Folder: &FolderInfo{}}}},
+		info(1),
+		true)
+	b.Details()
+
+	assert.Equal(t, expect, b.Summary())
+}
+
 func (suite *DetailsUnitSuite) TestDetails_AddFolders() {
 	itemTime := time.Date(2022, 10, 21, 10, 0, 0, 0, time.UTC)
 	folderTimeOlderThanItem := time.Date(2022, 9, 21, 10, 0, 0, 0, time.UTC)
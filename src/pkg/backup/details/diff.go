@@ -0,0 +1,86 @@
+package details
+
+import (
+	"sort"
+)
+
+// ChangeKind describes how an item differs between two backups.
+type ChangeKind string
+
+const (
+	ItemAdded   ChangeKind = "added"
+	ItemChanged ChangeKind = "changed"
+	ItemDeleted ChangeKind = "deleted"
+)
+
+// Change is an item that differs between two backups.  The entry is the
+// item as recorded by the newer backup, or by the older backup if the
+// item was deleted.
+type Change struct {
+	Kind  ChangeKind   `json:"kind"`
+	Entry DetailsEntry `json:"entry"`
+}
+
+// Diff produces the changes to the items of the base model that result
+// in the items of this model.  Items are matched by their repoRef, so an
+// item that moved to another folder appears as deleted from the old folder
+// and added to the new one.  Matched items are changed if their modified
+// time, size, or location differ.  Folders, metadata files, and tombstones
+// are ignored.  Changes are sorted by repoRef.
+func (dm DetailsModel) Diff(base DetailsModel) []Change {
+	var (
+		changes = []Change{}
+		prev    = map[string]*DetailsEntry{}
+	)
+
+	for _, ent := range base.Items() {
+		prev[ent.RepoRef] = ent
+	}
+
+	for _, ent := range dm.Items() {
+		old, ok := prev[ent.RepoRef]
+		if !ok {
+			changes = append(changes, Change{Kind: ItemAdded, Entry: *ent})
+			continue
+		}
+
+		delete(prev, ent.RepoRef)
+
+		if ent.differsFrom(*old) {
+			changes = append(changes, Change{Kind: ItemChanged, Entry: *ent})
+		}
+	}
+
+	for _, ent := range prev {
+		changes = append(changes, Change{Kind: ItemDeleted, Entry: *ent})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Entry.RepoRef < changes[j].Entry.RepoRef
+	})
+
+	return changes
+}
+
+// differsFrom reports whether the item was modified, resized, or
+// relocated since the other entry was recorded.
+func (de DetailsEntry) differsFrom(other DetailsEntry) bool {
+	if !de.Modified().Equal(other.Modified()) || de.Size() != other.Size() {
+		return true
+	}
+
+	return de.LocationRef != other.LocationRef || de.parentPath() != other.parentPath()
+}
+
+// parentPath returns the location of drive items, which is recorded in
+// the item info instead of the location ref.
+func (de DetailsEntry) parentPath() string {
+	switch {
+	case de.OneDrive != nil:
+		return de.OneDrive.ParentPath
+	case de.SharePoint != nil:
+		return de.SharePoint.DriveName + "/" + de.SharePoint.ParentPath
+	}
+
+	return ""
+}
@@ -0,0 +1,175 @@
+package details
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type DiffUnitSuite struct {
+	suite.Suite
+}
+
+func TestDiffUnitSuite(t *testing.T) {
+	suite.Run(t, new(DiffUnitSuite))
+}
+
+func (suite *DiffUnitSuite) mail(loc, subject string, size int64, mod time.Time, elems ...string) DetailsEntry {
+	p, err := path.Builder{}.
+		Append(elems...).
+		ToDataLayerExchangePathForCategory("tenant", "owner", path.EmailCategory, true)
+	require.NoError(suite.T(), err)
+
+	return DetailsEntry{
+		RepoRef:     p.String(),
+		ShortRef:    p.ShortRef(),
+		LocationRef: loc,
+		ItemInfo: ItemInfo{
+			Exchange: &ExchangeInfo{
+				ItemType: ExchangeMail,
+				Subject:  subject,
+				Size:     size,
+				Modified: mod,
+			},
+		},
+	}
+}
+
+func (suite *DiffUnitSuite) TestDetailsModel_Diff() {
+	var (
+		t     = suite.T()
+		now   = time.Now()
+		later = now.Add(time.Hour)
+
+		kept      = suite.mail("Inbox", "kept", 1, now, "inbox", "kept")
+		edited    = suite.mail("Inbox", "edited", 1, now, "inbox", "edited")
+		newEdited = suite.mail("Inbox", "edited", 2, later, "inbox", "edited")
+		renamed   = suite.mail("Inbox", "renamed", 1, now, "inbox", "renamed")
+		newName   = suite.mail("Mail", "renamed", 1, now, "inbox", "renamed")
+		removed   = suite.mail("Inbox", "removed", 1, now, "inbox", "removed")
+		added     = suite.mail("Inbox", "added", 1, now, "inbox", "added")
+		tombstone = suite.mail("Inbox", "gone", 1, now, "inbox", "gone")
+	)
+
+	tombstone.Deleted = true
+
+	base := DetailsModel{Entries: []DetailsEntry{kept, edited, renamed, removed}}
+	dm := DetailsModel{Entries: []DetailsEntry{kept, newEdited, newName, added, tombstone}}
+
+	expect := []Change{
+		{Kind: ItemAdded, Entry: added},
+		{Kind: ItemChanged, Entry: newEdited},
+		{Kind: ItemDeleted, Entry: removed},
+		{Kind: ItemChanged, Entry: newName},
+	}
+
+	assert.Equal(t, expect, dm.Diff(base))
+	assert.Empty(t, dm.Diff(dm))
+}
+
+func (suite *DiffUnitSuite) TestNewChangeReport() {
+	var (
+		t   = suite.T()
+		now = time.Now()
+	)
+
+	changes := []Change{
+		{Kind: ItemAdded, Entry: suite.mail("Inbox", "b", 1, now, "inbox", "b")},
+		{Kind: ItemAdded, Entry: suite.mail("Inbox", "a", 1, now, "inbox", "a")},
+		{Kind: ItemDeleted, Entry: suite.mail("Archive", "", 1, now, "archive", "c")},
+		{Kind: ItemChanged, Entry: suite.mail("Inbox/Work", "d", 1, now, "inbox", "work", "d")},
+	}
+
+	cr, err := NewChangeReport("base", "target", changes)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, cr.Added)
+	assert.Equal(t, 1, cr.Changed)
+	assert.Equal(t, 1, cr.Deleted)
+	assert.Equal(
+		t,
+		[]FolderChanges{
+			// items without a subject are named by their ID.
+			{Folder: "email/Archive", Deleted: []string{"c"}},
+			{Folder: "email/Inbox", Added: []string{"a", "b"}},
+			{Folder: "email/Inbox/Work", Changed: []string{"d"}},
+		},
+		cr.Folders)
+}
+
+func (suite *DiffUnitSuite) TestChangeReport_Write() {
+	cr := ChangeReport{
+		BaseBackupID: "base",
+		BackupID:     "target",
+		Added:        1,
+		Deleted:      1,
+		Folders: []FolderChanges{
+			{Folder: "email/Inbox", Added: []string{"<b>bold|move</b>"}, Deleted: []string{"old"}},
+		},
+	}
+
+	table := []struct {
+		name     string
+		format   ReportFormat
+		expect   []string
+		expectNo []string
+	}{
+		{
+			name:   "markdown",
+			format: MarkdownReport,
+			expect: []string{
+				"# Changes from backup base to backup target",
+				"| 1 | 0 | 1 |",
+				"| email/Inbox | 1 | 0 | 1 |",
+				"## email/Inbox",
+				`- Added: &lt;b&gt;bold\|move&lt;/b&gt;`,
+				"- Deleted: old",
+			},
+			expectNo: []string{"<b>"},
+		},
+		{
+			name:   "html",
+			format: HTMLReport,
+			expect: []string{
+				"<h1>Changes from backup base to backup target</h1>",
+				"<tr><td>email/Inbox</td><td>1</td><td>0</td><td>1</td></tr>",
+				"<li>Added: &lt;b&gt;bold|move&lt;/b&gt;</li>",
+				"<li>Deleted: old</li>",
+			},
+			expectNo: []string{"<b>"},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			buf := &bytes.Buffer{}
+
+			require.NoError(t, cr.Write(buf, test.format))
+
+			for _, e := range test.expect {
+				assert.Contains(t, buf.String(), e)
+			}
+
+			for _, e := range test.expectNo {
+				assert.NotContains(t, buf.String(), e)
+			}
+		})
+	}
+
+	assert.Error(suite.T(), cr.Write(&bytes.Buffer{}, "pdf"))
+}
+
+func (suite *DiffUnitSuite) TestChangeReport_WriteEmpty() {
+	t := suite.T()
+	buf := &bytes.Buffer{}
+
+	require.NoError(t, ChangeReport{BaseBackupID: "base", BackupID: "target"}.Write(buf, MarkdownReport))
+	assert.Contains(t, buf.String(), "No items changed between the backups.")
+	assert.NotContains(t, buf.String(), "| Folder |")
+}
@@ -0,0 +1,119 @@
+package details
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alcionai/clues"
+)
+
+// ExtensionSeparator divides the namespace of an extension key from
+// its name.  Ex: com.example.dlp/classification
+const ExtensionSeparator = "/"
+
+var ErrInvalidExtensionKey = clues.New("invalid extension key")
+
+var (
+	// namespaces are lowercase, dot separated segments, such as a
+	// reversed domain name (ex: com.example.dlp).
+	extensionNamespaceRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+(-[a-z0-9]+)*)*$`)
+	extensionNameRE      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// ExtensionKey produces the key of the named extension within the
+// namespace.  Namespaces keep the extensions of separate integrations
+// from colliding.
+func ExtensionKey(namespace, name string) (string, error) {
+	if !extensionNamespaceRE.MatchString(namespace) {
+		return "", clues.Stack(ErrInvalidExtensionKey).With("extension_namespace", namespace)
+	}
+
+	if !extensionNameRE.MatchString(name) {
+		return "", clues.Stack(ErrInvalidExtensionKey).With("extension_name", name)
+	}
+
+	return namespace + ExtensionSeparator + name, nil
+}
+
+// ValidateExtensionKey returns an error if the key is not a namespaced
+// extension key, as produced by ExtensionKey.
+func ValidateExtensionKey(key string) error {
+	ns, name, ok := strings.Cut(key, ExtensionSeparator)
+	if !ok {
+		return clues.Stack(ErrInvalidExtensionKey).With("extension_key", key)
+	}
+
+	_, err := ExtensionKey(ns, name)
+
+	return err
+}
+
+// SetExtension attaches the value to the entry under the namespaced
+// extension key, replacing any previous value.  Extensions are carried
+// over when the entry is merged into later backups, and are included in
+// the entry's json output.  Extensions set on a stored backup's entries
+// are persisted through the repository's UpdateBackupDetails.
+func (de *DetailsEntry) SetExtension(namespace, name, value string) error {
+	key, err := ExtensionKey(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	if de.Extensions == nil {
+		de.Extensions = map[string]string{}
+	}
+
+	de.Extensions[key] = value
+
+	return nil
+}
+
+// Extension produces the value of the namespaced extension, and whether
+// the entry has it.
+func (de DetailsEntry) Extension(namespace, name string) (string, bool) {
+	v, ok := de.Extensions[namespace+ExtensionSeparator+name]
+	return v, ok
+}
+
+// RemoveExtension removes the namespaced extension from the entry.
+func (de *DetailsEntry) RemoveExtension(namespace, name string) {
+	delete(de.Extensions, namespace+ExtensionSeparator+name)
+
+	if len(de.Extensions) == 0 {
+		de.Extensions = nil
+	}
+}
+
+// extensionColumns produces a non-default column for each of the entry's
+// extensions, in key order.
+func (de DetailsEntry) extensionColumns() []column {
+	keys := make([]string, 0, len(de.Extensions))
+	for k := range de.Extensions {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	cs := make([]column, 0, len(keys))
+	for _, k := range keys {
+		cs = append(cs, column{header: k, value: de.Extensions[k]})
+	}
+
+	return cs
+}
+
+// copyExtensions produces a copy of the extensions, so that entries
+// built from another entry don't share its map.
+func copyExtensions(ext map[string]string) map[string]string {
+	if len(ext) == 0 {
+		return nil
+	}
+
+	cp := make(map[string]string, len(ext))
+	for k, v := range ext {
+		cp[k] = v
+	}
+
+	return cp
+}
@@ -0,0 +1,139 @@
+package details
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExtensionsUnitSuite struct {
+	suite.Suite
+}
+
+func TestExtensionsUnitSuite(t *testing.T) {
+	suite.Run(t, new(ExtensionsUnitSuite))
+}
+
+func (suite *ExtensionsUnitSuite) TestExtensionKey() {
+	table := []struct {
+		name      string
+		namespace string
+		key       string
+		expect    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"reversed domain", "com.example.dlp", "classification", "com.example.dlp/classification", assert.NoError},
+		{"single segment", "hashes", "sha-256", "hashes/sha-256", assert.NoError},
+		{"dashed namespace", "acme-corp.tags", "Label_1", "acme-corp.tags/Label_1", assert.NoError},
+		{"empty namespace", "", "classification", "", assert.Error},
+		{"uppercase namespace", "Com.Example", "classification", "", assert.Error},
+		{"separator in namespace", "com/example", "classification", "", assert.Error},
+		{"trailing dot", "com.example.", "classification", "", assert.Error},
+		{"empty name", "com.example", "", "", assert.Error},
+		{"separator in name", "com.example", "a/b", "", assert.Error},
+		{"space in name", "com.example", "a b", "", assert.Error},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			key, err := ExtensionKey(test.namespace, test.key)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, key)
+
+			if err != nil {
+				assert.ErrorIs(t, err, ErrInvalidExtensionKey)
+				return
+			}
+
+			assert.NoError(t, ValidateExtensionKey(key))
+		})
+	}
+
+	assert.Error(suite.T(), ValidateExtensionKey("classification"), "keys require a namespace")
+}
+
+func (suite *ExtensionsUnitSuite) TestSetExtension() {
+	var (
+		t  = suite.T()
+		de = DetailsEntry{ShortRef: "deadbeef"}
+	)
+
+	_, ok := de.Extension("com.example", "label")
+	assert.False(t, ok)
+
+	require.NoError(t, de.SetExtension("com.example", "label", "confidential"))
+	require.NoError(t, de.SetExtension("com.example.hash", "sha256", "abc123"))
+	assert.Error(t, de.SetExtension("", "label", "public"))
+
+	v, ok := de.Extension("com.example", "label")
+	assert.True(t, ok)
+	assert.Equal(t, "confidential", v)
+
+	// extensions survive serialization.
+	bs, err := json.Marshal(de)
+	require.NoError(t, err)
+
+	var rt DetailsEntry
+
+	require.NoError(t, json.Unmarshal(bs, &rt))
+	assert.Equal(t, de.Extensions, rt.Extensions)
+
+	de.RemoveExtension("com.example", "label")
+	de.RemoveExtension("com.example.hash", "sha256")
+	assert.Nil(t, de.Extensions)
+
+	bs, err = json.Marshal(de)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bs), "extensions", "entries without extensions omit them")
+}
+
+func (suite *ExtensionsUnitSuite) TestExtensionColumns() {
+	var (
+		t       = suite.T()
+		labeled = DetailsEntry{
+			ShortRef:   "deadbeef",
+			Extensions: map[string]string{"com.example/label": "confidential"},
+			ItemInfo:   ItemInfo{OneDrive: &OneDriveInfo{ItemName: "itemName"}},
+		}
+		unlabeled = DetailsEntry{
+			ShortRef: "beefdead",
+			ItemInfo: ItemInfo{OneDrive: &OneDriveInfo{ItemName: "other"}},
+		}
+		cols = []string{"ItemName", "com.example/label"}
+	)
+
+	assert.NotContains(t, labeled.Headers(), "com.example/label", "extensions aren't shown by default")
+	assert.NoError(t, ValidateColumns(cols))
+
+	ce := labeled.selectColumns(cols)
+	assert.Equal(t, []string{"ItemName", "com.example/label"}, ce.Headers())
+	assert.Equal(t, []string{"itemName", "confidential"}, ce.Values())
+
+	ce = unlabeled.selectColumns(cols)
+	assert.Equal(t, []string{"ItemName", "com.example/label"}, ce.Headers())
+	assert.Equal(t, []string{"other", ""}, ce.Values(), "entries without the extension keep the table aligned")
+}
+
+func (suite *ExtensionsUnitSuite) TestBuilder_extensions() {
+	var (
+		t   = suite.T()
+		ext = map[string]string{"com.example/label": "confidential"}
+		b   = &Builder{}
+	)
+
+	b.AddWithExtensions("repo/ref", "short", "", "", false, ItemInfo{}, ext)
+	b.AddTombstones([]DetailsEntry{{RepoRef: "repo/gone", ShortRef: "gone", Extensions: ext}})
+
+	ext["com.example/label"] = "public"
+
+	ents := b.Details().Entries
+	require.Len(t, ents, 2)
+
+	for _, ent := range ents {
+		v, ok := ent.Extension("com.example", "label")
+		assert.True(t, ok, ent.ShortRef)
+		assert.Equal(t, "confidential", v, "entries hold their own copy of the extensions")
+	}
+}
@@ -0,0 +1,234 @@
+package details
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/alcionai/clues"
+)
+
+// ReportFormat is the document format in which a change report is written.
+type ReportFormat string
+
+const (
+	MarkdownReport ReportFormat = "markdown"
+	HTMLReport     ReportFormat = "html"
+)
+
+// ReportFormats lists the supported change report formats.
+var ReportFormats = []ReportFormat{MarkdownReport, HTMLReport}
+
+// ChangeReport summarizes the changes between two backups by folder, in
+// a form suited to readers who aren't familiar with the backup details.
+type ChangeReport struct {
+	BaseBackupID string          `json:"baseBackupID"`
+	BackupID     string          `json:"backupID"`
+	Added        int             `json:"added"`
+	Changed      int             `json:"changed"`
+	Deleted      int             `json:"deleted"`
+	Folders      []FolderChanges `json:"folders"`
+}
+
+// FolderChanges names the items that were added, changed, or deleted
+// within a folder.  The folder is named by its location, starting with
+// the category of data.
+type FolderChanges struct {
+	Folder  string   `json:"folder"`
+	Added   []string `json:"added,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// NewChangeReport groups the changes between the base backup and the
+// backup by the folders that hold the changed items.  Folders are sorted
+// by name, as are the items within each folder.
+func NewChangeReport(baseBackupID, backupID string, changes []Change) (ChangeReport, error) {
+	var (
+		cr      = ChangeReport{BaseBackupID: baseBackupID, BackupID: backupID}
+		folders = map[string]*FolderChanges{}
+	)
+
+	for _, c := range changes {
+		elems, err := c.Entry.treeElements()
+		if err != nil {
+			return ChangeReport{}, clues.Stack(err).With("repo_ref", c.Entry.RepoRef)
+		}
+
+		name := strings.Join(elems, "/")
+
+		fc, ok := folders[name]
+		if !ok {
+			fc = &FolderChanges{Folder: name}
+			folders[name] = fc
+		}
+
+		item := c.Entry.displayName()
+
+		switch c.Kind {
+		case ItemAdded:
+			cr.Added++
+			fc.Added = append(fc.Added, item)
+		case ItemChanged:
+			cr.Changed++
+			fc.Changed = append(fc.Changed, item)
+		case ItemDeleted:
+			cr.Deleted++
+			fc.Deleted = append(fc.Deleted, item)
+		}
+	}
+
+	cr.Folders = make([]FolderChanges, 0, len(folders))
+
+	for _, fc := range folders {
+		sort.Strings(fc.Added)
+		sort.Strings(fc.Changed)
+		sort.Strings(fc.Deleted)
+
+		cr.Folders = append(cr.Folders, *fc)
+	}
+
+	sort.Slice(cr.Folders, func(i, j int) bool {
+		return cr.Folders[i].Folder < cr.Folders[j].Folder
+	})
+
+	return cr, nil
+}
+
+// displayName returns the name by which people recognize the item: the
+// subject of mail and events, the name of contacts, and the name of files.
+// Falls back to the item's ID.
+func (de DetailsEntry) displayName() string {
+	var name string
+
+	switch {
+	case de.Exchange != nil:
+		name = de.Exchange.Subject
+		if len(name) == 0 {
+			name = de.Exchange.ContactName
+		}
+	case de.OneDrive != nil:
+		name = de.OneDrive.ItemName
+	case de.SharePoint != nil:
+		name = de.SharePoint.ItemName
+	}
+
+	if len(name) == 0 {
+		name = itemName(de.RepoRef)
+	}
+
+	return name
+}
+
+// Write renders the report into w in the given format.
+func (cr ChangeReport) Write(w io.Writer, format ReportFormat) error {
+	var err error
+
+	switch format {
+	case MarkdownReport:
+		err = markdownReport.Execute(w, cr)
+	case HTMLReport:
+		err = htmlReport.Execute(w, cr)
+	default:
+		return clues.New("unsupported report format").With("report_format", format)
+	}
+
+	if err != nil {
+		return clues.Wrap(err, "writing change report").With("report_format", format)
+	}
+
+	return nil
+}
+
+// --------------------------------------------------------------------------------
+// Templates
+// --------------------------------------------------------------------------------
+
+var markdownReport = texttemplate.Must(texttemplate.New("markdown").
+	Funcs(texttemplate.FuncMap{"md": escapeMarkdown}).
+	Parse(`# Changes from backup {{ .BaseBackupID }} to backup {{ .BackupID }}
+
+| Added | Changed | Deleted |
+|------:|--------:|--------:|
+| {{ .Added }} | {{ .Changed }} | {{ .Deleted }} |
+{{ if not .Folders }}
+No items changed between the backups.
+{{ else }}
+| Folder | Added | Changed | Deleted |
+|--------|------:|--------:|--------:|
+{{- range .Folders }}
+| {{ md .Folder }} | {{ len .Added }} | {{ len .Changed }} | {{ len .Deleted }} |
+{{- end }}
+{{ range .Folders }}
+## {{ md .Folder }}
+{{ range .Added }}
+- Added: {{ md . }}
+{{- end }}
+{{- range .Changed }}
+- Changed: {{ md . }}
+{{- end }}
+{{- range .Deleted }}
+- Deleted: {{ md . }}
+{{- end }}
+{{ end }}{{ end }}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Changes from backup {{ .BaseBackupID }} to backup {{ .BackupID }}</title>
+</head>
+<body>
+<h1>Changes from backup {{ .BaseBackupID }} to backup {{ .BackupID }}</h1>
+<table>
+<tr><th>Added</th><th>Changed</th><th>Deleted</th></tr>
+<tr><td>{{ .Added }}</td><td>{{ .Changed }}</td><td>{{ .Deleted }}</td></tr>
+</table>
+{{- if not .Folders }}
+<p>No items changed between the backups.</p>
+{{- else }}
+<table>
+<tr><th>Folder</th><th>Added</th><th>Changed</th><th>Deleted</th></tr>
+{{- range .Folders }}
+<tr><td>{{ .Folder }}</td><td>{{ len .Added }}</td><td>{{ len .Changed }}</td><td>{{ len .Deleted }}</td></tr>
+{{- end }}
+</table>
+{{- range .Folders }}
+<h2>{{ .Folder }}</h2>
+<ul>
+{{- range .Added }}
+<li>Added: {{ . }}</li>
+{{- end }}
+{{- range .Changed }}
+<li>Changed: {{ . }}</li>
+{{- end }}
+{{- range .Deleted }}
+<li>Deleted: {{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+	"\n", " ",
+)
+
+// escapeMarkdown keeps item and folder names from being rendered as
+// markdown formatting, or from breaking out of a table cell.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,258 @@
+package details
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/alcionai/clues"
+	"github.com/dustin/go-humanize"
+
+	"github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// TreeNode is a folder in the hierarchy of a backup's items.  The item
+// count and size of each node include the items in all of its subfolders.
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Items    int         `json:"items"`
+	Size     int64       `json:"size"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Tree produces the folder hierarchy of the model's items, as named by
+// their locations.  The top level of the tree holds the categories of
+// data (email, contacts, files, etc) within the backup.  Folders, metadata
+// files, and tombstones are excluded from the item counts.
+func (dm DetailsModel) Tree() (*TreeNode, error) {
+	root := &TreeNode{Name: "/"}
+
+	for _, ent := range dm.Items() {
+		elems, err := ent.treeElements()
+		if err != nil {
+			return nil, clues.Stack(err).With("repo_ref", ent.RepoRef)
+		}
+
+		root.add(elems, 1, ent.Size())
+	}
+
+	root.sort()
+
+	return root, nil
+}
+
+// FolderCount is the count and size of the items held directly within a
+// folder, not including the items in its subfolders.
+type FolderCount struct {
+	// Elements is the path of display names leading to the folder,
+	// starting with the folder's category.
+	Elements []string
+	Items    int
+	Size     int64
+}
+
+// TreeFromFolders produces the folder hierarchy described by the folder
+// counts, for backups which record their folders without any items.
+// Folders are included even if they hold no items.
+func TreeFromFolders(folders []FolderCount) *TreeNode {
+	root := &TreeNode{Name: "/"}
+
+	for _, f := range folders {
+		root.add(f.Elements, f.Items, f.Size)
+	}
+
+	root.sort()
+
+	return root
+}
+
+// treeElements produces the path of display names leading to the folder
+// that contains the entry, starting with the entry's category.
+func (de DetailsEntry) treeElements() ([]string, error) {
+	p, err := path.FromDataLayerPath(de.RepoRef, true)
+	if err != nil {
+		return nil, clues.Wrap(err, "parsing repoRef")
+	}
+
+	var (
+		elems = []string{p.Category().String()}
+		loc   = de.LocationRef
+	)
+
+	// drive items record their location in the item info, instead of
+	// in the location ref.
+	if len(loc) == 0 {
+		switch {
+		case de.OneDrive != nil:
+			loc = de.OneDrive.ParentPath
+		case de.SharePoint != nil:
+			loc = de.SharePoint.ParentPath
+
+			// sites hold many document libraries.
+			if len(de.SharePoint.DriveName) > 0 {
+				elems = append(elems, de.SharePoint.DriveName)
+			}
+		}
+	}
+
+	if len(loc) == 0 {
+		return elems, nil
+	}
+
+	pb, err := path.Builder{}.SplitUnescapeAppend(loc)
+	if err != nil {
+		return nil, clues.Wrap(err, "parsing location")
+	}
+
+	return append(elems, pb.Elements()...), nil
+}
+
+// add counts the items within the node, along with each folder along the
+// path beneath the node.
+func (tn *TreeNode) add(elems []string, items int, size int64) {
+	tn.Items += items
+	tn.Size += size
+
+	if len(elems) == 0 {
+		return
+	}
+
+	child := tn.child(elems[0])
+	if child == nil {
+		child = &TreeNode{Name: elems[0]}
+		tn.Children = append(tn.Children, child)
+	}
+
+	child.add(elems[1:], items, size)
+}
+
+func (tn *TreeNode) child(name string) *TreeNode {
+	for _, c := range tn.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (tn *TreeNode) sort() {
+	sort.Slice(tn.Children, func(i, j int) bool {
+		return tn.Children[i].Name < tn.Children[j].Name
+	})
+
+	for _, c := range tn.Children {
+		c.sort()
+	}
+}
+
+// Find returns the node at the end of the path of folder names beneath
+// this node, or nil if no such folder exists.
+func (tn *TreeNode) Find(elems ...string) *TreeNode {
+	node := tn
+
+	for _, e := range elems {
+		node = node.child(e)
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// Prune returns a copy of the tree that only descends depth levels
+// below this node.  A depth less than one retains the entire tree.
+func (tn *TreeNode) Prune(depth int) *TreeNode {
+	node := &TreeNode{Name: tn.Name, Items: tn.Items, Size: tn.Size}
+
+	if depth == 1 {
+		return node
+	}
+
+	for _, c := range tn.Children {
+		node.Children = append(node.Children, c.Prune(depth-1))
+	}
+
+	return node
+}
+
+// --------------------------------------------------------------------------------
+// CLI Output
+// --------------------------------------------------------------------------------
+
+// PrintTree writes the tree to StdOut, in the format requested by the
+// caller.  Tabular output draws each folder beneath its parent.
+func (tn *TreeNode) PrintTree(ctx context.Context) {
+	if print.JSONFormat() {
+		print.Item(ctx, tn)
+		return
+	}
+
+	rows := []print.Printable{treeRow{node: tn, name: tn.Name}}
+	rows = tn.appendRows(rows, "")
+
+	print.All(ctx, rows...)
+}
+
+func (tn *TreeNode) appendRows(rows []print.Printable, indent string) []print.Printable {
+	for i, c := range tn.Children {
+		// ascii keeps the table's column widths accurate.
+		branch, nextIndent := "|-- ", "|   "
+
+		if i == len(tn.Children)-1 {
+			branch, nextIndent = "`-- ", "    "
+		}
+
+		rows = append(rows, treeRow{node: c, name: indent + branch + c.Name})
+		rows = c.appendRows(rows, indent+nextIndent)
+	}
+
+	return rows
+}
+
+// interface compliance checks
+var (
+	_ print.Printable = &TreeNode{}
+	_ print.Printable = treeRow{}
+)
+
+// MinimumPrintable TreeNodes is a passthrough func, because no
+// reduction is needed for the json output.
+func (tn *TreeNode) MinimumPrintable() any {
+	return tn
+}
+
+// Headers returns the human-readable names of properties in a TreeNode
+// for printing out to a terminal in a columnar display.
+func (tn *TreeNode) Headers() []string {
+	return []string{"Folder", "Items", "Size"}
+}
+
+// Values returns the values matching the Headers list for printing
+// out to a terminal in a columnar display.
+func (tn *TreeNode) Values() []string {
+	return []string{tn.Name, strconv.Itoa(tn.Items), humanize.Bytes(uint64(tn.Size))}
+}
+
+// treeRow is a node printed as a row within a drawing of the tree.
+type treeRow struct {
+	node *TreeNode
+	name string
+}
+
+func (tr treeRow) MinimumPrintable() any {
+	return tr.node
+}
+
+func (tr treeRow) Headers() []string {
+	return tr.node.Headers()
+}
+
+func (tr treeRow) Values() []string {
+	vs := tr.node.Values()
+	vs[0] = tr.name
+
+	return vs
+}
@@ -0,0 +1,214 @@
+package details
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type TreeUnitSuite struct {
+	suite.Suite
+}
+
+func TestTreeUnitSuite(t *testing.T) {
+	suite.Run(t, new(TreeUnitSuite))
+}
+
+func (suite *TreeUnitSuite) repoRef(
+	service path.ServiceType,
+	category path.CategoryType,
+	elems ...string,
+) string {
+	p, err := path.Builder{}.
+		Append(elems...).
+		ToDataLayerPath("tenant", "owner", service, category, true)
+	require.NoError(suite.T(), err)
+
+	return p.String()
+}
+
+func (suite *TreeUnitSuite) TestDetailsModel_Tree() {
+	t := suite.T()
+
+	dm := DetailsModel{
+		Entries: []DetailsEntry{
+			{
+				RepoRef:     suite.repoRef(path.ExchangeService, path.EmailCategory, "inboxID", "mail1"),
+				LocationRef: "Inbox",
+				ItemInfo:    ItemInfo{Exchange: &ExchangeInfo{ItemType: ExchangeMail, Size: 10}},
+			},
+			{
+				RepoRef:     suite.repoRef(path.ExchangeService, path.EmailCategory, "inboxID", "importantID", "mail2"),
+				LocationRef: "Inbox/Important",
+				ItemInfo:    ItemInfo{Exchange: &ExchangeInfo{ItemType: ExchangeMail, Size: 20}},
+			},
+			{
+				RepoRef:     suite.repoRef(path.ExchangeService, path.EmailCategory, "archiveID", "mail3"),
+				LocationRef: "Archive",
+				ItemInfo:    ItemInfo{Exchange: &ExchangeInfo{ItemType: ExchangeMail, Size: 30}},
+			},
+			{
+				RepoRef:     suite.repoRef(path.ExchangeService, path.ContactsCategory, "contactsID", "contact"),
+				LocationRef: "Contacts",
+				ItemInfo:    ItemInfo{Exchange: &ExchangeInfo{ItemType: ExchangeContact, Size: 5}},
+			},
+			{
+				// tombstones aren't counted.
+				RepoRef:     suite.repoRef(path.ExchangeService, path.EmailCategory, "inboxID", "deleted"),
+				LocationRef: "Inbox",
+				Deleted:     true,
+				ItemInfo:    ItemInfo{Exchange: &ExchangeInfo{ItemType: ExchangeMail, Size: 100}},
+			},
+			{
+				// folders aren't counted.
+				RepoRef:  "tenant/exchange/owner/email/inboxID",
+				ItemInfo: ItemInfo{Folder: &FolderInfo{DisplayName: "Inbox", Size: 30}},
+			},
+		},
+	}
+
+	tree, err := dm.Tree()
+	require.NoError(t, err)
+
+	expect := &TreeNode{
+		Name:  "/",
+		Items: 4,
+		Size:  65,
+		Children: []*TreeNode{
+			{
+				Name:  "contacts",
+				Items: 1,
+				Size:  5,
+				Children: []*TreeNode{
+					{Name: "Contacts", Items: 1, Size: 5},
+				},
+			},
+			{
+				Name:  "email",
+				Items: 3,
+				Size:  60,
+				Children: []*TreeNode{
+					{Name: "Archive", Items: 1, Size: 30},
+					{
+						Name:  "Inbox",
+						Items: 2,
+						Size:  30,
+						Children: []*TreeNode{
+							{Name: "Important", Items: 1, Size: 20},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expect, tree)
+
+	assert.Equal(t, expect.Children[1].Children[1], tree.Find("email", "Inbox"))
+	assert.Nil(t, tree.Find("email", "Outbox"))
+
+	pruned := tree.Prune(1)
+	assert.Empty(t, pruned.Children)
+	assert.Equal(t, 4, pruned.Items)
+
+	pruned = tree.Prune(2)
+	require.Len(t, pruned.Children, 2)
+	assert.Empty(t, pruned.Children[1].Children)
+	assert.Len(t, tree.Children[1].Children, 2, "pruning leaves the tree intact")
+}
+
+func (suite *TreeUnitSuite) TestDetailsModel_Tree_drives() {
+	t := suite.T()
+
+	dm := DetailsModel{
+		Entries: []DetailsEntry{
+			{
+				RepoRef: suite.repoRef(path.OneDriveService, path.FilesCategory, "drives", "d", "root:", "a", "f1"),
+				ItemInfo: ItemInfo{OneDrive: &OneDriveInfo{
+					ItemType:   OneDriveItem,
+					ParentPath: "a",
+					Size:       1,
+				}},
+			},
+			{
+				// metadata files aren't counted.
+				RepoRef: suite.repoRef(path.OneDriveService, path.FilesCategory, "drives", "d", "root:", "a", "f1.meta"),
+				ItemInfo: ItemInfo{OneDrive: &OneDriveInfo{
+					ItemType:   OneDriveItem,
+					ParentPath: "a",
+					IsMeta:     true,
+				}},
+			},
+			{
+				RepoRef: suite.repoRef(path.OneDriveService, path.FilesCategory, "drives", "d", "root:", "f2"),
+				ItemInfo: ItemInfo{OneDrive: &OneDriveInfo{
+					ItemType: OneDriveItem,
+					Size:     2,
+				}},
+			},
+			{
+				RepoRef: suite.repoRef(path.SharePointService, path.LibrariesCategory, "drives", "d", "root:", "a", "f3"),
+				ItemInfo: ItemInfo{SharePoint: &SharePointInfo{
+					ItemType:   SharePointItem,
+					DriveName:  "Documents",
+					ParentPath: `a/b\/c`,
+					Size:       4,
+				}},
+			},
+		},
+	}
+
+	tree, err := dm.Tree()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, tree.Items)
+	assert.Equal(t, int64(7), tree.Size)
+
+	files := tree.Find("files")
+	require.NotNil(t, files)
+	assert.Equal(t, 2, files.Items, "files include items at the drive root")
+	assert.Equal(t, 1, tree.Find("files", "a").Items)
+
+	lib := tree.Find("libraries", "Documents", "a", "b/c")
+	require.NotNil(t, lib, "library folders are named by the drive, then the unescaped parent path")
+	assert.Equal(t, int64(4), lib.Size)
+}
+
+func (suite *TreeUnitSuite) TestTreeFromFolders() {
+	t := suite.T()
+
+	tree := TreeFromFolders([]FolderCount{
+		{Elements: []string{"email", "Inbox", "Important"}, Items: 2, Size: 20},
+		{Elements: []string{"email", "Inbox"}, Items: 3, Size: 30},
+		{Elements: []string{"email", "Archive"}},
+	})
+
+	expect := &TreeNode{
+		Name:  "/",
+		Items: 5,
+		Size:  50,
+		Children: []*TreeNode{
+			{
+				Name:  "email",
+				Items: 5,
+				Size:  50,
+				Children: []*TreeNode{
+					// empty folders are retained.
+					{Name: "Archive"},
+					{
+						Name:  "Inbox",
+						Items: 5,
+						Size:  50,
+						Children: []*TreeNode{
+							{Name: "Important", Items: 2, Size: 20},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expect, tree)
+}
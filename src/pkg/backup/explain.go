@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+// dataSuffix marks the data of drive files in their repo refs.
+const dataSuffix = ".data"
+
+// codeHints describe the likely root cause behind the error codes that
+// graph commonly reports for individual items.
+var codeHints = map[string]string{
+	"itemNotFound":         "the item was deleted or moved while the backup ran",
+	"ErrorItemNotFound":    "the item was deleted or moved while the backup ran",
+	"accessDenied":         "the application isn't permitted to read the item",
+	"ErrorAccessDenied":    "the application isn't permitted to read the item",
+	"activityLimitReached": "the service throttled the backup",
+	"TooManyRequests":      "the service throttled the backup",
+	"ErrorQuotaExceeded":   "the resource owner's storage quota is exhausted",
+	"generalException":     "the service failed unexpectedly; retrying the backup usually succeeds",
+	"serviceNotAvailable":  "the service was unavailable; retrying the backup usually succeeds",
+}
+
+// ItemExplanation correlates everything that a backup recorded about a
+// single item: its details entry, its failures, and its retrieval.
+type ItemExplanation struct {
+	BackupID string `json:"backupID"`
+	Ref      string `json:"ref"`
+	// Entry is the item's entry in the backup details, if it has one.
+	Entry *details.DetailsEntry `json:"entry,omitempty"`
+	// Failures are the recorded failures of the item.
+	Failures []fault.Item `json:"failures,omitempty"`
+	// SameCause counts the errors in the backup that share the cause of
+	// the item's last failure, including that failure.
+	SameCause int `json:"sameCause,omitempty"`
+	// FolderFailures counts the failures of other items within the
+	// folders of the item's failures.
+	FolderFailures int `json:"folderFailures,omitempty"`
+	// Timing is the item's retrieval time, if it was among the backup's
+	// slowest items.
+	Timing *stats.ItemTiming `json:"timing,omitempty"`
+	// Truncated is true if the backup recorded as many item failures as
+	// it could hold, so the item's failure may have gone unrecorded.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ExplainItem correlates the details entry, failures, and retrieval of the
+// item identified by ref, which may be the item's ID, its short ref, its
+// repo ref, or the last element of its repo ref, with or without its data
+// suffix.  deets may be nil.
+func (b Backup) ExplainItem(ref string, deets *details.Details) ItemExplanation {
+	ie := ItemExplanation{
+		BackupID:  string(b.ID),
+		Ref:       ref,
+		Truncated: len(b.Errors.Items) >= fault.MaxItems,
+	}
+
+	// failures and timings identify items by their ID, which drive entries
+	// record separately from their repo ref.
+	ids := map[string]struct{}{ref: {}}
+
+	if deets != nil {
+		for _, ent := range deets.Entries {
+			if matchesEntry(ref, ent) {
+				ent := ent
+				ie.Entry = &ent
+
+				if id := entryItemID(ent); len(id) > 0 {
+					ids[id] = struct{}{}
+				}
+
+				break
+			}
+		}
+	}
+
+	folders := map[string]struct{}{}
+
+	for _, it := range b.Errors.Items {
+		if _, ok := ids[it.ID]; ok {
+			ie.Failures = append(ie.Failures, it)
+			folders[it.Folder] = struct{}{}
+		}
+	}
+
+	for _, it := range b.Errors.Items {
+		_, inFolder := folders[it.Folder]
+		_, isItem := ids[it.ID]
+
+		if inFolder && !isItem {
+			ie.FolderFailures++
+		}
+	}
+
+	if len(ie.Failures) > 0 {
+		cause := ie.Failures[len(ie.Failures)-1].Cause
+
+		for _, g := range b.Errors.Groups {
+			if g.Message == cause {
+				ie.SameCause = g.Count
+				break
+			}
+		}
+	}
+
+	for _, it := range b.Performance.SlowestItems {
+		_, ok := ids[it.ItemID]
+
+		// backups from before timings recorded IDs only have the item's name.
+		if ok || (len(it.ItemID) == 0 && it.Item == ref) {
+			it := it
+			ie.Timing = &it
+
+			break
+		}
+	}
+
+	return ie
+}
+
+// matchesEntry is true if ref identifies the details entry.
+func matchesEntry(ref string, ent details.DetailsEntry) bool {
+	if ref == ent.ShortRef || ref == ent.RepoRef {
+		return true
+	}
+
+	if id := entryItemID(ent); len(id) > 0 && ref == id {
+		return true
+	}
+
+	elems := strings.Split(ent.RepoRef, "/")
+	if len(elems) == 0 {
+		return false
+	}
+
+	last := elems[len(elems)-1]
+
+	return ref == last || ref == strings.TrimSuffix(last, dataSuffix)
+}
+
+// entryItemID is the ID of the drive item in the entry, if it was recorded.
+// Other entries are identified by the last element of their repo ref.
+func entryItemID(ent details.DetailsEntry) string {
+	switch {
+	case ent.OneDrive != nil:
+		return ent.OneDrive.ItemID
+	case ent.SharePoint != nil:
+		return ent.SharePoint.ItemID
+	}
+
+	return ""
+}
+
+// String narrates the explanation in plain language.
+func (ie ItemExplanation) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Item %s in backup %s:\n", ie.Ref, ie.BackupID)
+
+	if ie.Entry == nil && len(ie.Failures) == 0 && ie.Timing == nil {
+		fmt.Fprintln(&sb, "  The backup recorded nothing about this item.  Items are identified")
+		fmt.Fprintln(&sb, "  by their ID, their short ref, or their repo ref.")
+
+		if ie.Truncated {
+			fmt.Fprintf(
+				&sb,
+				"  The backup only recorded its first %d item failures, so this item may have failed.\n",
+				fault.MaxItems)
+		}
+
+		return sb.String()
+	}
+
+	if ie.Entry != nil {
+		switch {
+		case ie.Entry.Deleted:
+			fmt.Fprintln(&sb, "  - The item was deleted before the backup; its details keep the last backed up version.")
+		case ie.Entry.Updated:
+			fmt.Fprintf(&sb, "  - The item was backed up to %s.\n", ie.Entry.RepoRef)
+		default:
+			fmt.Fprintf(&sb, "  - The item was unchanged, and carried over from a base backup to %s.\n", ie.Entry.RepoRef)
+		}
+	}
+
+	for _, f := range ie.Failures {
+		verb := "failed"
+		if f.Skipped {
+			verb = "was skipped"
+		}
+
+		fmt.Fprintf(&sb, "  - The item %s", verb)
+
+		if len(f.Folder) > 0 {
+			fmt.Fprintf(&sb, " in folder %s", f.Folder)
+		}
+
+		switch {
+		case f.Retries > 0 && f.FinalRetry:
+			fmt.Fprintf(&sb, " after %d retries and a final retry", f.Retries)
+		case f.Retries > 0:
+			fmt.Fprintf(&sb, " after %d retries", f.Retries)
+		case f.FinalRetry:
+			fmt.Fprint(&sb, " after a final retry")
+		}
+
+		fmt.Fprintf(&sb, ": %s\n", f.Cause)
+
+		if len(f.Code) > 0 {
+			fmt.Fprintf(&sb, "    The service reported the error code %s", f.Code)
+
+			if hint, ok := codeHints[f.Code]; ok {
+				fmt.Fprintf(&sb, ", which usually means %s", hint)
+			}
+
+			fmt.Fprintln(&sb, ".")
+		}
+	}
+
+	if ie.Entry != nil && len(ie.Failures) > 0 {
+		fmt.Fprintln(&sb, "  - The failures were recovered from, since the item was still backed up.")
+	}
+
+	if ie.SameCause > 1 {
+		fmt.Fprintf(
+			&sb,
+			"  - %d errors in the backup share this cause, which points to a problem beyond this item.\n",
+			ie.SameCause)
+	}
+
+	if ie.FolderFailures > 0 {
+		fmt.Fprintf(&sb, "  - Other items in the same folder also failed: %d.\n", ie.FolderFailures)
+	}
+
+	if ie.Timing != nil {
+		fmt.Fprintf(&sb, "  - The item was among the slowest to retrieve, taking %s", ie.Timing.Duration)
+
+		if ie.Timing.Retries > 0 {
+			fmt.Fprintf(&sb, " across %d retries", ie.Timing.Retries)
+		}
+
+		fmt.Fprintln(&sb, ".")
+	}
+
+	return sb.String()
+}
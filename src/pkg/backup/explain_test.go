@@ -0,0 +1,166 @@
+package backup_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+)
+
+type ExplainUnitSuite struct {
+	tester.Suite
+}
+
+func TestExplainUnitSuite(t *testing.T) {
+	suite.Run(t, &ExplainUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func explainBackup() backup.Backup {
+	return backup.Backup{
+		BaseModel: model.BaseModel{ID: "bup"},
+		Errors: fault.ErrorsData{
+			Groups: []fault.Group{{Message: "not found", Count: 12}},
+			Items: []fault.Item{
+				{ID: "failed", Folder: "Inbox", Cause: "not found", Code: "ErrorItemNotFound", Retries: 3, FinalRetry: true},
+				{ID: "neighbor", Folder: "Inbox", Cause: "not found"},
+				{ID: "elsewhere", Folder: "Sent", Cause: "not found"},
+				{ID: "recovered", Folder: "Sent", Cause: "throttled", Code: "TooManyRequests"},
+			},
+		},
+		Performance: stats.Performance{
+			SlowestItems: []stats.ItemTiming{{Folder: "Sent", Item: "recovered", Duration: time.Minute, Retries: 2}},
+		},
+	}
+}
+
+func (suite *ExplainUnitSuite) TestExplainItem() {
+	deets := &details.Details{}
+	deets.Entries = []details.DetailsEntry{
+		{RepoRef: "tenant/exchange/user/email/Sent/recovered", ShortRef: "short", Updated: true},
+	}
+
+	table := []struct {
+		name   string
+		ref    string
+		expect backup.ItemExplanation
+		// substrings expected in the narrative
+		narrative []string
+	}{
+		{
+			name: "failed",
+			ref:  "failed",
+			expect: backup.ItemExplanation{
+				BackupID:       "bup",
+				Ref:            "failed",
+				Failures:       []fault.Item{explainBackup().Errors.Items[0]},
+				SameCause:      12,
+				FolderFailures: 1,
+			},
+			narrative: []string{
+				"failed in folder Inbox after 3 retries and a final retry: not found",
+				"error code ErrorItemNotFound, which usually means the item was deleted or moved",
+				"12 errors in the backup share this cause",
+				"Other items in the same folder also failed: 1",
+			},
+		},
+		{
+			name: "recovered, by short ref",
+			ref:  "short",
+			expect: backup.ItemExplanation{
+				BackupID: "bup",
+				Ref:      "short",
+				Entry:    &deets.Entries[0],
+			},
+			narrative: []string{"was backed up to tenant/exchange/user/email/Sent/recovered"},
+		},
+		{
+			name: "recovered, by id",
+			ref:  "recovered",
+			expect: backup.ItemExplanation{
+				BackupID:       "bup",
+				Ref:            "recovered",
+				Entry:          &deets.Entries[0],
+				Failures:       []fault.Item{explainBackup().Errors.Items[3]},
+				FolderFailures: 1,
+				Timing:         &explainBackup().Performance.SlowestItems[0],
+			},
+			narrative: []string{
+				"failures were recovered from",
+				"the service throttled the backup",
+				"taking 1m0s across 2 retries",
+			},
+		},
+		{
+			name:      "unknown",
+			ref:       "unknown",
+			expect:    backup.ItemExplanation{BackupID: "bup", Ref: "unknown"},
+			narrative: []string{"recorded nothing about this item"},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ie := explainBackup().ExplainItem(test.ref, deets)
+			assert.Equal(t, test.expect, ie)
+
+			narrative := ie.String()
+			for _, s := range test.narrative {
+				assert.Contains(t, narrative, s)
+			}
+		})
+	}
+}
+
+func (suite *ExplainUnitSuite) TestExplainItem_truncated() {
+	t := suite.T()
+
+	b := backup.Backup{}
+	for i := 0; i < fault.MaxItems; i++ {
+		b.Errors.Items = append(b.Errors.Items, fault.Item{ID: "other"})
+	}
+
+	ie := b.ExplainItem("item", nil)
+	require.True(t, ie.Truncated)
+	assert.Contains(t, ie.String(), "this item may have failed")
+}
+
+func (suite *ExplainUnitSuite) TestExplainItem_driveItem() {
+	b := backup.Backup{
+		BaseModel: model.BaseModel{ID: "bup"},
+		Errors: fault.ErrorsData{
+			Items: []fault.Item{{ID: "iid", Folder: "Docs", Cause: "download failed"}},
+		},
+		Performance: stats.Performance{
+			SlowestItems: []stats.ItemTiming{{Folder: "Docs", Item: "plan.docx", ItemID: "iid", Duration: time.Minute}},
+		},
+	}
+
+	deets := &details.Details{}
+	deets.Entries = []details.DetailsEntry{{
+		RepoRef:  "tenant/onedrive/user/files/drives/did/root:/Docs/plan.docx.data",
+		ShortRef: "short",
+		Updated:  true,
+		ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{ItemName: "plan.docx", ItemID: "iid"}},
+	}}
+
+	for _, ref := range []string{"iid", "plan.docx", "plan.docx.data", "short"} {
+		suite.Run(ref, func() {
+			t := suite.T()
+
+			ie := b.ExplainItem(ref, deets)
+			assert.Equal(t, &deets.Entries[0], ie.Entry)
+			assert.Equal(t, b.Errors.Items, ie.Failures)
+			assert.Equal(t, &b.Performance.SlowestItems[0], ie.Timing)
+		})
+	}
+}
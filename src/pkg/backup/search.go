@@ -0,0 +1,257 @@
+package backup
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alcionai/corso/src/cli/print"
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+)
+
+// SearchTerms splits the query into the lower-cased terms that an item
+// must contain to match.
+func SearchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// SearchMatch is an item in the details of a backup that matched a search.
+type SearchMatch struct {
+	BackupID  model.StableID       `json:"backupID"`
+	Service   string               `json:"service"`
+	Owner     string               `json:"owner"`
+	StartedAt time.Time            `json:"startedAt"`
+	Entry     details.DetailsEntry `json:"entry"`
+	// Backups is the number of searched backups that hold the item.  Each
+	// match counts one, until it's combined by DedupeMatches.
+	Backups int `json:"backups,omitempty"`
+}
+
+// Type is the kind of the matched item: mail, events, contacts, tasks,
+// files, or site items.
+func (sm SearchMatch) Type() string {
+	ent := sm.Entry
+
+	switch {
+	case ent.Exchange != nil:
+		switch ent.Exchange.ItemType {
+		case details.ExchangeMail:
+			return "mail"
+		case details.ExchangeEvent:
+			return "events"
+		case details.ExchangeContact:
+			return "contacts"
+		case details.ExchangeTask:
+			return "tasks"
+		}
+	case ent.OneDrive != nil:
+		return "files"
+	case ent.SharePoint != nil:
+		return "site items"
+	}
+
+	return "other"
+}
+
+// itemKey identifies the item across backups.  Drive items are identified
+// by their item ID, which stays stable when they're moved or renamed.
+func (sm SearchMatch) itemKey() string {
+	id := sm.Entry.RepoRef
+
+	switch {
+	case sm.Entry.OneDrive != nil && len(sm.Entry.OneDrive.ItemID) > 0:
+		id = sm.Entry.OneDrive.ItemID
+	case sm.Entry.SharePoint != nil && len(sm.Entry.SharePoint.ItemID) > 0:
+		id = sm.Entry.SharePoint.ItemID
+	}
+
+	return sm.Service + "/" + sm.Owner + "/" + id
+}
+
+// DedupeMatches reduces the matches to one per item, since an item that
+// didn't change is held by every incremental backup that followed it.  The
+// match from the most recent backup is kept, and counts the backups that
+// hold the item.  Matches are sorted by owner, then location.
+func DedupeMatches(ms []SearchMatch) []SearchMatch {
+	var (
+		byItem = map[string]SearchMatch{}
+		counts = map[string]int{}
+	)
+
+	for _, m := range ms {
+		k := m.itemKey()
+		counts[k]++
+
+		if prev, ok := byItem[k]; !ok || m.StartedAt.After(prev.StartedAt) {
+			byItem[k] = m
+		}
+	}
+
+	deduped := make([]SearchMatch, 0, len(byItem))
+
+	for k, m := range byItem {
+		m.Backups = counts[k]
+		deduped = append(deduped, m)
+	}
+
+	sort.Slice(deduped, func(i, j int) bool {
+		a, b := deduped[i], deduped[j]
+
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+
+		if la, lb := matchLocation(a.Entry), matchLocation(b.Entry); la != lb {
+			return la < lb
+		}
+
+		return matchName(a.Entry) < matchName(b.Entry)
+	})
+
+	return deduped
+}
+
+// SearchFacets counts the matches by service, owner, and item type.
+type SearchFacets struct {
+	Services map[string]int `json:"services"`
+	Owners   map[string]int `json:"owners"`
+	Types    map[string]int `json:"types"`
+}
+
+// Facets counts the matches within each facet.
+func Facets(ms []SearchMatch) SearchFacets {
+	f := SearchFacets{
+		Services: map[string]int{},
+		Owners:   map[string]int{},
+		Types:    map[string]int{},
+	}
+
+	for _, m := range ms {
+		f.Services[m.Service]++
+		f.Owners[m.Owner]++
+		f.Types[m.Type()]++
+	}
+
+	return f
+}
+
+// Search produces the items in the backup's details that contain every one
+// of the terms within their properties, their repo ref, or their location.
+// Folders, metadata files, and tombstones are never matched.  Terms are
+// expected to be lower-cased, as produced by SearchTerms.
+func (b Backup) Search(terms []string, deets *details.Details) []SearchMatch {
+	if deets == nil || len(terms) == 0 {
+		return nil
+	}
+
+	ms := []SearchMatch{}
+
+	for _, ent := range deets.Items() {
+		if !containsAll(searchText(*ent), terms) {
+			continue
+		}
+
+		ms = append(ms, SearchMatch{
+			BackupID:  b.ID,
+			Service:   b.Selector.PathService().String(),
+			Owner:     b.Selector.DiscreteOwner,
+			StartedAt: b.StartedAt,
+			Entry:     *ent,
+			Backups:   1,
+		})
+	}
+
+	return ms
+}
+
+// searchText is the lower-cased text that terms get matched against.
+func searchText(ent details.DetailsEntry) string {
+	vs := append(ent.Values(), ent.RepoRef, ent.LocationRef)
+	return strings.ToLower(strings.Join(vs, "\n"))
+}
+
+func containsAll(s string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(s, t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// --------------------------------------------------------------------------------
+// CLI Output
+// --------------------------------------------------------------------------------
+
+// interface compliance checks
+var _ print.Printable = &SearchMatch{}
+
+// MinimumPrintable SearchMatches is a passthrough func, because no
+// reduction is needed for the json output.
+func (sm SearchMatch) MinimumPrintable() any {
+	return sm
+}
+
+// Headers returns the human-readable names of properties in a SearchMatch
+// for printing out to a terminal in a columnar display.
+func (sm SearchMatch) Headers() []string {
+	return []string{
+		"Backup ID",
+		"Started At",
+		"Resource Owner",
+		"Type",
+		"ID",
+		"Item",
+		"Location",
+		"Backups",
+	}
+}
+
+// Values returns the values matching the Headers list for printing
+// out to a terminal in a columnar display.
+func (sm SearchMatch) Values() []string {
+	return []string{
+		string(sm.BackupID),
+		common.FormatTabularDisplayTime(sm.StartedAt),
+		sm.Owner,
+		sm.Type(),
+		sm.Entry.ShortRef,
+		matchName(sm.Entry),
+		matchLocation(sm.Entry),
+		strconv.Itoa(sm.Backups),
+	}
+}
+
+// matchName is the display name of the matched item.
+func matchName(ent details.DetailsEntry) string {
+	switch {
+	case ent.Exchange != nil && len(ent.Exchange.Subject) > 0:
+		return ent.Exchange.Subject
+	case ent.Exchange != nil:
+		return ent.Exchange.ContactName
+	case ent.OneDrive != nil:
+		return ent.OneDrive.ItemName
+	case ent.SharePoint != nil:
+		return ent.SharePoint.ItemName
+	}
+
+	return ""
+}
+
+// matchLocation is the display location of the matched item.
+func matchLocation(ent details.DetailsEntry) string {
+	switch {
+	case len(ent.LocationRef) > 0:
+		return ent.LocationRef
+	case ent.OneDrive != nil:
+		return ent.OneDrive.ParentPath
+	case ent.SharePoint != nil:
+		return ent.SharePoint.ParentPath
+	}
+
+	return ""
+}
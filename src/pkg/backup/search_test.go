@@ -0,0 +1,193 @@
+package backup_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type SearchUnitSuite struct {
+	tester.Suite
+}
+
+func TestSearchUnitSuite(t *testing.T) {
+	suite.Run(t, &SearchUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func searchDetails() *details.Details {
+	return &details.Details{
+		DetailsModel: details.DetailsModel{
+			Entries: []details.DetailsEntry{
+				{
+					RepoRef:  "tid/onedrive/bob/files/drives/d/root:/Finance/invoice-2023.pdf",
+					ShortRef: "invoice",
+					ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+						ItemType:   details.OneDriveItem,
+						ItemName:   "Invoice-2023.pdf",
+						ParentPath: "Finance",
+					}},
+				},
+				{
+					RepoRef:  "tid/onedrive/bob/files/drives/d/root:/Finance/invoice-2022.pdf",
+					ShortRef: "old",
+					ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+						ItemType:   details.OneDriveItem,
+						ItemName:   "invoice-2022.pdf",
+						ParentPath: "Finance",
+					}},
+				},
+				{
+					RepoRef:  "tid/onedrive/bob/files/drives/d/root:/Finance/invoice-2023.pdf.meta",
+					ShortRef: "meta",
+					ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+						ItemType: details.OneDriveItem,
+						ItemName: "invoice-2023.pdf.meta",
+						IsMeta:   true,
+					}},
+				},
+				{
+					RepoRef:  "tid/onedrive/bob/files/drives/d/root:/Invoices 2023",
+					ShortRef: "folder",
+					ItemInfo: details.ItemInfo{Folder: &details.FolderInfo{DisplayName: "Invoices 2023"}},
+				},
+				{
+					RepoRef:  "tid/onedrive/bob/files/drives/d/root:/Finance/deleted-invoice-2023.pdf",
+					ShortRef: "tombstone",
+					Deleted:  true,
+					ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+						ItemType: details.OneDriveItem,
+						ItemName: "deleted-invoice-2023.pdf",
+					}},
+				},
+			},
+		},
+	}
+}
+
+func (suite *SearchUnitSuite) TestSearchTerms() {
+	assert.Equal(suite.T(), []string{"invoice", "2023"}, backup.SearchTerms("  Invoice   2023 "))
+}
+
+func (suite *SearchUnitSuite) TestBackup_Search() {
+	b := backup.Backup{
+		BaseModel: model.BaseModel{ID: "bup"},
+		Selector:  selectors.Selector{DiscreteOwner: "bob"},
+	}
+
+	table := []struct {
+		name   string
+		query  string
+		expect []string
+	}{
+		{
+			name:   "all terms",
+			query:  "invoice 2023",
+			expect: []string{"invoice"},
+		},
+		{
+			name:   "case insensitive",
+			query:  "INVOICE",
+			expect: []string{"invoice", "old"},
+		},
+		{
+			name:   "location",
+			query:  "finance 2022",
+			expect: []string{"old"},
+		},
+		{
+			name:  "no match",
+			query: "receipt",
+		},
+		{
+			name:  "no terms",
+			query: " ",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ms := b.Search(backup.SearchTerms(test.query), searchDetails())
+			require.Len(t, ms, len(test.expect))
+
+			for i, m := range ms {
+				assert.Equal(t, test.expect[i], m.Entry.ShortRef)
+				assert.Equal(t, b.ID, m.BackupID)
+				assert.Equal(t, "bob", m.Owner)
+			}
+		})
+	}
+}
+
+func (suite *SearchUnitSuite) TestSearchMatch_Values() {
+	t := suite.T()
+	ms := backup.Backup{BaseModel: model.BaseModel{ID: "bup"}}.
+		Search([]string{"2023"}, searchDetails())
+
+	require.Len(t, ms, 1)
+
+	vs := ms[0].Values()
+	assert.Len(t, vs, len(ms[0].Headers()))
+	assert.Equal(t, "bup", vs[0])
+	assert.Equal(t, "files", vs[3])
+	assert.Equal(t, "Invoice-2023.pdf", vs[5])
+	assert.Equal(t, "Finance", vs[6])
+	assert.Equal(t, "1", vs[7])
+}
+
+func (suite *SearchUnitSuite) TestDedupeMatches() {
+	var (
+		t     = suite.T()
+		now   = time.Now()
+		terms = backup.SearchTerms("invoice")
+		older = backup.Backup{
+			BaseModel:       model.BaseModel{ID: "older"},
+			Selector:        selectors.Selector{DiscreteOwner: "bob", Service: selectors.ServiceOneDrive},
+			StartAndEndTime: stats.StartAndEndTime{StartedAt: now.Add(-time.Hour)},
+		}
+		newer = backup.Backup{
+			BaseModel:       model.BaseModel{ID: "newer"},
+			Selector:        selectors.Selector{DiscreteOwner: "bob", Service: selectors.ServiceOneDrive},
+			StartAndEndTime: stats.StartAndEndTime{StartedAt: now},
+		}
+		other = backup.Backup{
+			BaseModel:       model.BaseModel{ID: "other"},
+			Selector:        selectors.Selector{DiscreteOwner: "alice", Service: selectors.ServiceOneDrive},
+			StartAndEndTime: stats.StartAndEndTime{StartedAt: now},
+		}
+	)
+
+	ms := append(older.Search(terms, searchDetails()), newer.Search(terms, searchDetails())...)
+	ms = append(ms, other.Search(terms, searchDetails())...)
+	require.Len(t, ms, 6)
+
+	deduped := backup.DedupeMatches(ms)
+	require.Len(t, deduped, 4, "one match per owner's item")
+
+	for _, m := range deduped {
+		if m.Owner == "alice" {
+			assert.Equal(t, other.ID, m.BackupID)
+			assert.Equal(t, 1, m.Backups)
+
+			continue
+		}
+
+		assert.Equal(t, newer.ID, m.BackupID, "the most recent backup is kept")
+		assert.Equal(t, 2, m.Backups)
+	}
+
+	f := backup.Facets(deduped)
+	assert.Equal(t, map[string]int{"onedrive": 4}, f.Services)
+	assert.Equal(t, map[string]int{"alice": 2, "bob": 2}, f.Owners)
+	assert.Equal(t, map[string]int{"files": 4}, f.Types)
+}
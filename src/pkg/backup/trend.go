@@ -0,0 +1,267 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// MaxTrendPoints caps the number of backups retained in a Trend.  Once
+// the cap is reached, the oldest points are dropped as new ones get added.
+const MaxTrendPoints = 1000
+
+// Trend is a compact time-series of the stats produced by each backup of a
+// single resource owner within a single service.  Trends are kept separately
+// from the backups themselves, so that they survive backup deletion and can
+// be read without loading every backup in the repository.
+type Trend struct {
+	model.BaseModel
+
+	ResourceOwner string           `json:"resourceOwner"`
+	Service       path.ServiceType `json:"service"`
+
+	// Points are ordered from oldest to newest.
+	Points []TrendPoint `json:"points"`
+}
+
+// NewTrend produces an empty trend for the owner and service, tagged
+// so that it can be looked up by either.
+func NewTrend(service path.ServiceType, owner string) *Trend {
+	return &Trend{
+		BaseModel: model.BaseModel{
+			Tags: TrendTags(service, owner),
+		},
+		ResourceOwner: owner,
+		Service:       service,
+		Points:        []TrendPoint{},
+	}
+}
+
+// TrendTags produces the model store tags identifying the trend of
+// the owner within the service.
+func TrendTags(service path.ServiceType, owner string) map[string]string {
+	return map[string]string{
+		model.ServiceTag:       service.String(),
+		model.ResourceOwnerTag: owner,
+	}
+}
+
+// Add records the point in the trend.  A point that was already recorded
+// for the same backup is replaced.
+func (t *Trend) Add(p TrendPoint) {
+	for i := range t.Points {
+		if t.Points[i].BackupID == p.BackupID {
+			t.Points[i] = p
+			return
+		}
+	}
+
+	t.Points = append(t.Points, p)
+
+	sort.SliceStable(t.Points, func(i, j int) bool {
+		return t.Points[i].StartedAt.Before(t.Points[j].StartedAt)
+	})
+
+	if len(t.Points) > MaxTrendPoints {
+		t.Points = t.Points[len(t.Points)-MaxTrendPoints:]
+	}
+}
+
+// Summarize describes the change across the trend's points.
+func (t Trend) Summarize() TrendSummary {
+	ts := TrendSummary{Backups: len(t.Points)}
+
+	if len(t.Points) == 0 {
+		return ts
+	}
+
+	ts.First = t.Points[0].StartedAt
+	ts.Last = t.Points[len(t.Points)-1].StartedAt
+
+	var (
+		durations = make([]float64, 0, len(t.Points))
+		uploaded  = make([]float64, 0, len(t.Points))
+		rates     = make([]float64, 0, len(t.Points))
+	)
+
+	for _, p := range t.Points {
+		durations = append(durations, float64(p.Duration))
+		uploaded = append(uploaded, float64(p.BytesUploaded))
+		rates = append(rates, p.ChangeRate())
+	}
+
+	ts.AvgDuration = time.Duration(mean(durations))
+	ts.AvgBytesUploaded = int64(mean(uploaded))
+	ts.AvgChangeRate = mean(rates)
+	ts.DurationGrowth = growth(durations)
+	ts.BytesUploadedGrowth = growth(uploaded)
+	ts.ChangeRateGrowth = growth(rates)
+
+	return ts
+}
+
+func mean(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+
+	var sum float64
+
+	for _, v := range vs {
+		sum += v
+	}
+
+	return sum / float64(len(vs))
+}
+
+// growth compares the mean of the newer half of the values against the
+// mean of the older half, producing the relative change between the two.
+// Comparing halves, instead of the first and last values, keeps a single
+// unusual backup from skewing the result.  Produces 0 if there are too
+// few values to compare, or if the older half has no magnitude.
+func growth(vs []float64) float64 {
+	if len(vs) < 2 {
+		return 0
+	}
+
+	half := len(vs) / 2
+
+	older := mean(vs[:half])
+	if older == 0 {
+		return 0
+	}
+
+	return (mean(vs[len(vs)-half:]) - older) / older
+}
+
+// ---------------------------------------------------------------------------
+// Points
+// ---------------------------------------------------------------------------
+
+// TrendPoint holds the stats of a single backup.
+type TrendPoint struct {
+	BackupID      model.StableID `json:"backupID"`
+	StartedAt     time.Time      `json:"startedAt"`
+	Duration      time.Duration  `json:"duration"`
+	Status        string         `json:"status"`
+	BytesRead     int64          `json:"bytesRead"`
+	BytesUploaded int64          `json:"bytesUploaded"`
+	// Items is the count of items in the backup.
+	Items int `json:"items"`
+	// Changed is the count of items which were new, modified, or deleted
+	// since the prior backup.
+	Changed int `json:"changed"`
+}
+
+// ChangeRate is the fraction of the backup's items which changed since
+// the prior backup.
+func (p TrendPoint) ChangeRate() float64 {
+	if p.Items == 0 {
+		return 0
+	}
+
+	return float64(p.Changed) / float64(p.Items)
+}
+
+func (p TrendPoint) MinimumPrintable() any {
+	return p
+}
+
+// Headers returns the human-readable names of properties in a TrendPoint
+// for printing out to a terminal in a columnar display.
+func (p TrendPoint) Headers() []string {
+	return []string{
+		"Started At",
+		"ID",
+		"Status",
+		"Duration",
+		"Bytes Uploaded",
+		"Items",
+		"Changed",
+		"Change Rate",
+	}
+}
+
+// Values returns the values matching the Headers list for printing
+// out to a terminal in a columnar display.
+func (p TrendPoint) Values() []string {
+	return []string{
+		common.FormatTabularDisplayTime(p.StartedAt),
+		string(p.BackupID),
+		p.Status,
+		p.Duration.Round(time.Second).String(),
+		humanize.Bytes(uint64(p.BytesUploaded)),
+		strconv.Itoa(p.Items),
+		strconv.Itoa(p.Changed),
+		formatPercent(p.ChangeRate()),
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Summary
+// ---------------------------------------------------------------------------
+
+// TrendSummary describes the change across a trend's points.  Growth values
+// are relative, such that 0.5 marks a 50% increase.
+type TrendSummary struct {
+	Backups             int           `json:"backups"`
+	First               time.Time     `json:"first"`
+	Last                time.Time     `json:"last"`
+	AvgDuration         time.Duration `json:"avgDuration"`
+	DurationGrowth      float64       `json:"durationGrowth"`
+	AvgBytesUploaded    int64         `json:"avgBytesUploaded"`
+	BytesUploadedGrowth float64       `json:"bytesUploadedGrowth"`
+	AvgChangeRate       float64       `json:"avgChangeRate"`
+	ChangeRateGrowth    float64       `json:"changeRateGrowth"`
+}
+
+func (ts TrendSummary) MinimumPrintable() any {
+	return ts
+}
+
+// Headers returns the human-readable names of properties in a TrendSummary
+// for printing out to a terminal in a columnar display.
+func (ts TrendSummary) Headers() []string {
+	return []string{
+		"Backups",
+		"First",
+		"Last",
+		"Avg Duration",
+		"Duration Trend",
+		"Avg Bytes Uploaded",
+		"Bytes Trend",
+		"Avg Change Rate",
+		"Change Rate Trend",
+	}
+}
+
+// Values returns the values matching the Headers list for printing
+// out to a terminal in a columnar display.
+func (ts TrendSummary) Values() []string {
+	return []string{
+		strconv.Itoa(ts.Backups),
+		common.FormatTabularDisplayTime(ts.First),
+		common.FormatTabularDisplayTime(ts.Last),
+		ts.AvgDuration.Round(time.Second).String(),
+		formatGrowth(ts.DurationGrowth),
+		humanize.Bytes(uint64(ts.AvgBytesUploaded)),
+		formatGrowth(ts.BytesUploadedGrowth),
+		formatPercent(ts.AvgChangeRate),
+		formatGrowth(ts.ChangeRateGrowth),
+	}
+}
+
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.1f%%", f*100)
+}
+
+func formatGrowth(f float64) string {
+	return fmt.Sprintf("%+.1f%%", f*100)
+}
@@ -0,0 +1,149 @@
+package backup_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type TrendUnitSuite struct {
+	tester.Suite
+}
+
+func TestTrendUnitSuite(t *testing.T) {
+	suite.Run(t, &TrendUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func trendPoint(id string, at time.Time, dur time.Duration, uploaded int64, items, changed int) backup.TrendPoint {
+	return backup.TrendPoint{
+		BackupID:      model.StableID(id),
+		StartedAt:     at,
+		Duration:      dur,
+		BytesUploaded: uploaded,
+		Items:         items,
+		Changed:       changed,
+	}
+}
+
+func (suite *TrendUnitSuite) TestNewTrend() {
+	t := suite.T()
+	tr := backup.NewTrend(path.OneDriveService, "owner")
+
+	assert.Equal(t, path.OneDriveService, tr.Service)
+	assert.Equal(t, "owner", tr.ResourceOwner)
+	assert.Empty(t, tr.Points)
+	assert.Equal(t, path.OneDriveService.String(), tr.Tags[model.ServiceTag])
+	assert.Equal(t, "owner", tr.Tags[model.ResourceOwnerTag])
+}
+
+func (suite *TrendUnitSuite) TestTrend_Add() {
+	var (
+		t   = suite.T()
+		now = time.Now()
+		tr  = backup.NewTrend(path.ExchangeService, "owner")
+	)
+
+	tr.Add(trendPoint("b", now, time.Minute, 1, 1, 1))
+	tr.Add(trendPoint("a", now.Add(-time.Hour), time.Minute, 1, 1, 1))
+	tr.Add(trendPoint("c", now.Add(time.Hour), time.Minute, 1, 1, 1))
+
+	// re-adding a backup replaces its point.
+	tr.Add(trendPoint("b", now, time.Hour, 1, 1, 1))
+
+	require.Len(t, tr.Points, 3)
+
+	ids := []model.StableID{}
+	for _, p := range tr.Points {
+		ids = append(ids, p.BackupID)
+	}
+
+	assert.Equal(t, []model.StableID{"a", "b", "c"}, ids)
+	assert.Equal(t, time.Hour, tr.Points[1].Duration)
+}
+
+func (suite *TrendUnitSuite) TestTrend_Add_capped() {
+	var (
+		t   = suite.T()
+		now = time.Now()
+		tr  = backup.NewTrend(path.ExchangeService, "owner")
+	)
+
+	for i := 0; i < backup.MaxTrendPoints+5; i++ {
+		tr.Add(trendPoint(fmt.Sprint(i), now.Add(time.Duration(i)*time.Hour), time.Minute, 1, 1, 1))
+	}
+
+	require.Len(t, tr.Points, backup.MaxTrendPoints)
+	assert.Equal(t, model.StableID("5"), tr.Points[0].BackupID, "oldest points are dropped")
+}
+
+func (suite *TrendUnitSuite) TestTrendPoint_ChangeRate() {
+	t := suite.T()
+
+	assert.Equal(t, 0.25, trendPoint("a", time.Now(), 0, 0, 4, 1).ChangeRate())
+	assert.Zero(t, trendPoint("a", time.Now(), 0, 0, 0, 0).ChangeRate())
+}
+
+func (suite *TrendUnitSuite) TestTrend_Summarize() {
+	now := time.Now()
+
+	table := []struct {
+		name   string
+		points []backup.TrendPoint
+		expect backup.TrendSummary
+	}{
+		{
+			name:   "no points",
+			expect: backup.TrendSummary{},
+		},
+		{
+			name:   "single point",
+			points: []backup.TrendPoint{trendPoint("a", now, time.Minute, 100, 10, 5)},
+			expect: backup.TrendSummary{
+				Backups:          1,
+				First:            now,
+				Last:             now,
+				AvgDuration:      time.Minute,
+				AvgBytesUploaded: 100,
+				AvgChangeRate:    0.5,
+			},
+		},
+		{
+			name: "growing",
+			points: []backup.TrendPoint{
+				trendPoint("a", now, time.Minute, 100, 10, 1),
+				trendPoint("b", now.Add(time.Hour), time.Minute, 100, 10, 1),
+				trendPoint("c", now.Add(2*time.Hour), 2*time.Minute, 300, 10, 1),
+				trendPoint("d", now.Add(3*time.Hour), 4*time.Minute, 500, 10, 1),
+			},
+			expect: backup.TrendSummary{
+				Backups:             4,
+				First:               now,
+				Last:                now.Add(3 * time.Hour),
+				AvgDuration:         2 * time.Minute,
+				DurationGrowth:      2,
+				AvgBytesUploaded:    250,
+				BytesUploadedGrowth: 3,
+				AvgChangeRate:       0.1,
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			tr := backup.NewTrend(path.ExchangeService, "owner")
+			for _, p := range test.points {
+				tr.Add(p)
+			}
+
+			assert.Equal(suite.T(), test.expect, tr.Summarize())
+		})
+	}
+}
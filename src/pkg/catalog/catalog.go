@@ -0,0 +1,166 @@
+// Package catalog mirrors the backup records of a repository to an external
+// system, such as a SQL database or a DynamoDB table.  Enterprises with many
+// repositories can then query the protection status of their whole fleet
+// without connecting to each repository.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+// ErrNotConfigured is returned when backfilling a disabled catalog.
+var ErrNotConfigured = errors.New("no backup catalog is configured")
+
+// Record is the summary of a backup that gets mirrored to the catalog.
+type Record struct {
+	RepoID        string    `json:"repoID" dynamodbav:"repo_id"`
+	BackupID      string    `json:"backupID" dynamodbav:"backup_id"`
+	Service       string    `json:"service" dynamodbav:"service"`
+	Owner         string    `json:"owner" dynamodbav:"owner"`
+	Status        string    `json:"status" dynamodbav:"status"`
+	Incremental   bool      `json:"incremental" dynamodbav:"incremental"`
+	CreatedAt     time.Time `json:"createdAt" dynamodbav:"created_at"`
+	StartedAt     time.Time `json:"startedAt" dynamodbav:"started_at"`
+	CompletedAt   time.Time `json:"completedAt" dynamodbav:"completed_at"`
+	ItemsRead     int       `json:"itemsRead" dynamodbav:"items_read"`
+	ItemsWritten  int       `json:"itemsWritten" dynamodbav:"items_written"`
+	BytesRead     int64     `json:"bytesRead" dynamodbav:"bytes_read"`
+	BytesUploaded int64     `json:"bytesUploaded" dynamodbav:"bytes_uploaded"`
+	Errors        int       `json:"errors" dynamodbav:"errors"`
+}
+
+// NewRecord summarizes the backup for the catalog.
+func NewRecord(repoID string, b *backup.Backup) Record {
+	return Record{
+		RepoID:        repoID,
+		BackupID:      string(b.ID),
+		Service:       b.Selector.PathService().String(),
+		Owner:         b.Selector.DiscreteOwner,
+		Status:        b.Status,
+		Incremental:   b.Incremental,
+		CreatedAt:     b.CreationTime.UTC(),
+		StartedAt:     b.StartedAt.UTC(),
+		CompletedAt:   b.CompletedAt.UTC(),
+		ItemsRead:     b.ItemsRead,
+		ItemsWritten:  b.ItemsWritten,
+		BytesRead:     b.BytesRead,
+		BytesUploaded: b.BytesUploaded,
+		Errors:        b.ErrorCount(),
+	}
+}
+
+// Sink writes records to an external system.  Records are keyed by their
+// repo and backup IDs, and writing a record replaces any existing record
+// with the same keys.
+type Sink interface {
+	Put(ctx context.Context, r Record) error
+	Delete(ctx context.Context, repoID, backupID string) error
+	Close() error
+}
+
+// Catalog mirrors the backup records of a single repository.  A nil
+// catalog is disabled, and is safe to use.
+type Catalog struct {
+	repoID string
+	sink   Sink
+}
+
+// New connects to the catalog configured by the options.  Returns nil if no
+// catalog is configured.
+func New(ctx context.Context, opts control.CatalogOptions, repoID string) (*Catalog, error) {
+	if !opts.Enabled() {
+		return nil, nil
+	}
+
+	ctx = clues.Add(ctx, "catalog_kind", opts.Kind, "catalog_table", opts.Table)
+
+	if len(opts.Table) == 0 {
+		return nil, clues.New("catalog requires a table").WithClues(ctx)
+	}
+
+	var (
+		sink Sink
+		err  error
+	)
+
+	switch opts.Kind {
+	case control.CatalogSQL:
+		sink, err = openSQL(ctx, opts.SQLDriver, opts.SQLDSN, opts.Table)
+	case control.CatalogDynamoDB:
+		sink, err = openDynamoDB(opts.Region, opts.Endpoint, opts.Table)
+	default:
+		return nil, clues.New("unknown catalog kind").WithClues(ctx)
+	}
+
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	return NewWithSink(sink, repoID), nil
+}
+
+// NewWithSink produces a catalog which writes the repository's records to
+// the sink.
+func NewWithSink(sink Sink, repoID string) *Catalog {
+	return &Catalog{repoID: repoID, sink: sink}
+}
+
+// Put mirrors the backup's record.
+func (c *Catalog) Put(ctx context.Context, b *backup.Backup) error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.sink.Put(ctx, NewRecord(c.repoID, b)); err != nil {
+		return clues.Wrap(err, "writing catalog record").WithClues(ctx)
+	}
+
+	return nil
+}
+
+// Backfill mirrors the records of backups made before the catalog was
+// configured, or while it was unreachable.  Existing records are replaced.
+// Returns the number of records written.
+func (c *Catalog) Backfill(ctx context.Context, bs []*backup.Backup) (int, error) {
+	if c == nil {
+		return 0, clues.Stack(ErrNotConfigured).WithClues(ctx)
+	}
+
+	for i, b := range bs {
+		if err := c.Put(clues.Add(ctx, "backup_id", b.ID), b); err != nil {
+			return i, err
+		}
+	}
+
+	return len(bs), nil
+}
+
+// Delete removes the backup's record.
+func (c *Catalog) Delete(ctx context.Context, id model.StableID) error {
+	if c == nil {
+		return nil
+	}
+
+	if err := c.sink.Delete(ctx, c.repoID, string(id)); err != nil {
+		return clues.Wrap(err, "deleting catalog record").WithClues(ctx)
+	}
+
+	return nil
+}
+
+// Close releases the catalog's connection.
+func (c *Catalog) Close() error {
+	if c == nil {
+		return nil
+	}
+
+	return c.sink.Close()
+}
@@ -0,0 +1,457 @@
+package catalog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+type CatalogUnitSuite struct {
+	tester.Suite
+}
+
+func TestCatalogUnitSuite(t *testing.T) {
+	suite.Run(t, &CatalogUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func testBackup() *backup.Backup {
+	sel := selectors.NewExchangeBackup([]string{"uid"})
+
+	errs := fault.New(false)
+	errs.Add(errors.New("item failure"))
+
+	b := backup.New(
+		"snap-id", "details-id", "Completed",
+		model.StableID("bid"),
+		sel.Selector,
+		stats.ReadWrites{ItemsRead: 3, ItemsWritten: 2, BytesRead: 30, BytesUploaded: 20},
+		stats.StartAndEndTime{
+			StartedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			CompletedAt: time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+		errs)
+	b.Incremental = true
+
+	return b
+}
+
+func (suite *CatalogUnitSuite) TestNewRecord() {
+	t := suite.T()
+	b := testBackup()
+	r := NewRecord("rid", b)
+
+	assert.Equal(t, "rid", r.RepoID)
+	assert.Equal(t, "bid", r.BackupID)
+	assert.Equal(t, "exchange", r.Service)
+	assert.Equal(t, "uid", r.Owner)
+	assert.Equal(t, "Completed", r.Status)
+	assert.True(t, r.Incremental)
+	assert.Equal(t, b.StartedAt, r.StartedAt)
+	assert.Equal(t, b.CompletedAt, r.CompletedAt)
+	assert.Equal(t, 3, r.ItemsRead)
+	assert.Equal(t, 2, r.ItemsWritten)
+	assert.Equal(t, int64(30), r.BytesRead)
+	assert.Equal(t, int64(20), r.BytesUploaded)
+	assert.Equal(t, 1, r.Errors)
+}
+
+func (suite *CatalogUnitSuite) TestNew() {
+	table := []struct {
+		name      string
+		opts      control.CatalogOptions
+		expectNil bool
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "disabled",
+			expectNil: true,
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "missing table",
+			opts:      control.CatalogOptions{Kind: control.CatalogDynamoDB},
+			expectNil: true,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "unknown kind",
+			opts:      control.CatalogOptions{Kind: "spreadsheet", Table: "backups"},
+			expectNil: true,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "sql without a driver",
+			opts:      control.CatalogOptions{Kind: control.CatalogSQL, Table: "backups", SQLDSN: "dsn"},
+			expectNil: true,
+			expectErr: assert.Error,
+		},
+		{
+			name: "sql with an unregistered driver",
+			opts: control.CatalogOptions{
+				Kind:      control.CatalogSQL,
+				Table:     "backups",
+				SQLDriver: "not-a-driver",
+				SQLDSN:    "dsn",
+			},
+			expectNil: true,
+			expectErr: assert.Error,
+		},
+		{
+			name: "sql with an invalid table",
+			opts: control.CatalogOptions{
+				Kind:      control.CatalogSQL,
+				Table:     "backups; DROP TABLE users",
+				SQLDriver: fakeDriverName,
+				SQLDSN:    "dsn",
+			},
+			expectNil: true,
+			expectErr: assert.Error,
+		},
+		{
+			name: "sql",
+			opts: control.CatalogOptions{
+				Kind:      control.CatalogSQL,
+				Table:     "corso.backups",
+				SQLDriver: fakeDriverName,
+				SQLDSN:    "dsn",
+			},
+			expectErr: assert.NoError,
+		},
+		{
+			name: "dynamodb",
+			opts: control.CatalogOptions{
+				Kind:     control.CatalogDynamoDB,
+				Table:    "backups",
+				Region:   "us-east-1",
+				Endpoint: "http://localhost:8000",
+			},
+			expectErr: assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			c, err := New(ctx, test.opts, "rid")
+			test.expectErr(t, err)
+			assert.Equal(t, test.expectNil, c == nil)
+			assert.NoError(t, c.Close())
+		})
+	}
+}
+
+func (suite *CatalogUnitSuite) TestNilCatalog() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	var c *Catalog
+
+	assert.NoError(t, c.Put(ctx, testBackup()))
+	assert.NoError(t, c.Delete(ctx, "bid"))
+	assert.NoError(t, c.Close())
+
+	_, err := c.Backfill(ctx, []*backup.Backup{testBackup()})
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+// ---------------------------------------------------------------------------
+// sql
+// ---------------------------------------------------------------------------
+
+const fakeDriverName = "catalog-fake"
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeDriver records the statements executed against it.  Statements
+// containing failQuery return an error.
+type fakeDriver struct {
+	mu        sync.Mutex
+	execs     []fakeExec
+	commits   int
+	rollbacks int
+	failQuery string
+}
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register(fakeDriverName, fake)
+}
+
+func (d *fakeDriver) reset(failQuery string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.execs = nil
+	d.commits = 0
+	d.rollbacks = 0
+	d.failQuery = failQuery
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx(c), nil }
+
+type fakeTx struct{ d *fakeDriver }
+
+func (tx fakeTx) Commit() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+
+	tx.d.commits++
+
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+
+	tx.d.rollbacks++
+
+	return nil
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	if len(s.d.failQuery) > 0 && strings.Contains(s.query, s.d.failQuery) {
+		return nil, errors.New("exec failure")
+	}
+
+	s.d.execs = append(s.d.execs, fakeExec{s.query, args})
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (suite *CatalogUnitSuite) TestSQLSink_Put() {
+	table := []struct {
+		name         string
+		driver       string
+		failQuery    string
+		expectDelete string
+		expectInsert string
+		expectCommit int
+		expectErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:         "positional placeholders",
+			driver:       "mysql",
+			expectDelete: "DELETE FROM backups WHERE repo_id = ? AND backup_id = ?",
+			expectInsert: "VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			expectCommit: 1,
+			expectErr:    assert.NoError,
+		},
+		{
+			name:         "numbered placeholders",
+			driver:       "postgres",
+			expectDelete: "DELETE FROM backups WHERE repo_id = $1 AND backup_id = $2",
+			expectInsert: "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)",
+			expectCommit: 1,
+			expectErr:    assert.NoError,
+		},
+		{
+			name:      "insert failure",
+			driver:    "mysql",
+			failQuery: "INSERT",
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			t := suite.T()
+
+			fake.reset(test.failQuery)
+
+			db, err := sql.Open(fakeDriverName, "dsn")
+			require.NoError(t, err)
+
+			s, err := NewSQLSink(db, test.driver, "backups")
+			require.NoError(t, err)
+
+			defer s.Close()
+
+			r := NewRecord("rid", testBackup())
+
+			err = NewWithSink(s, "rid").Put(ctx, testBackup())
+			test.expectErr(t, err)
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+
+			assert.Equal(t, test.expectCommit, fake.commits)
+
+			if err != nil {
+				assert.Equal(t, 1, fake.rollbacks)
+				return
+			}
+
+			require.Len(t, fake.execs, 2)
+			assert.Equal(t, test.expectDelete, fake.execs[0].query)
+			assert.Equal(t, []driver.Value{"rid", "bid"}, fake.execs[0].args)
+			assert.Contains(t, fake.execs[1].query, "INSERT INTO backups (repo_id, backup_id, service")
+			assert.Contains(t, fake.execs[1].query, test.expectInsert)
+			assert.Len(t, fake.execs[1].args, len(sqlColumns))
+			assert.Equal(t, driver.Value(r.Owner), fake.execs[1].args[3])
+		})
+	}
+}
+
+func (suite *CatalogUnitSuite) TestSQLSink_Delete() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	fake.reset("")
+
+	db, err := sql.Open(fakeDriverName, "dsn")
+	require.NoError(t, err)
+
+	s, err := NewSQLSink(db, "sqlite3", "backups")
+	require.NoError(t, err)
+
+	defer s.Close()
+
+	err = NewWithSink(s, "rid").Delete(ctx, "bid")
+	require.NoError(t, err)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	require.Len(t, fake.execs, 1)
+	assert.Equal(t, "DELETE FROM backups WHERE repo_id = ? AND backup_id = ?", fake.execs[0].query)
+	assert.Equal(t, []driver.Value{"rid", "bid"}, fake.execs[0].args)
+}
+
+// ---------------------------------------------------------------------------
+// dynamodb
+// ---------------------------------------------------------------------------
+
+type mockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	puts    []*dynamodb.PutItemInput
+	deletes []*dynamodb.DeleteItemInput
+	err     error
+}
+
+func (m *mockDynamoDB) PutItemWithContext(
+	_ aws.Context,
+	in *dynamodb.PutItemInput,
+	_ ...request.Option,
+) (*dynamodb.PutItemOutput, error) {
+	m.puts = append(m.puts, in)
+	return &dynamodb.PutItemOutput{}, m.err
+}
+
+func (m *mockDynamoDB) DeleteItemWithContext(
+	_ aws.Context,
+	in *dynamodb.DeleteItemInput,
+	_ ...request.Option,
+) (*dynamodb.DeleteItemOutput, error) {
+	m.deletes = append(m.deletes, in)
+	return &dynamodb.DeleteItemOutput{}, m.err
+}
+
+func (suite *CatalogUnitSuite) TestDynamoDBSink() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	m := &mockDynamoDB{}
+	c := NewWithSink(NewDynamoDBSink(m, "backups"), "rid")
+
+	require.NoError(t, c.Put(ctx, testBackup()))
+	require.Len(t, m.puts, 1)
+
+	item := m.puts[0].Item
+	assert.Equal(t, "backups", aws.StringValue(m.puts[0].TableName))
+	assert.Equal(t, "rid", aws.StringValue(item["repo_id"].S))
+	assert.Equal(t, "bid", aws.StringValue(item["backup_id"].S))
+	assert.Equal(t, "uid", aws.StringValue(item["owner"].S))
+	assert.Equal(t, "2023-01-01T00:00:00Z", aws.StringValue(item["started_at"].S))
+	assert.Equal(t, "3", aws.StringValue(item["items_read"].N))
+	assert.True(t, aws.BoolValue(item["incremental"].BOOL))
+
+	require.NoError(t, c.Delete(ctx, "bid"))
+	require.Len(t, m.deletes, 1)
+
+	key := m.deletes[0].Key
+	assert.Equal(t, "rid", aws.StringValue(key["repo_id"].S))
+	assert.Equal(t, "bid", aws.StringValue(key["backup_id"].S))
+
+	m.err = errors.New("throttled")
+	assert.Error(t, c.Put(ctx, testBackup()))
+	assert.Error(t, c.Delete(ctx, "bid"))
+}
+
+func (suite *CatalogUnitSuite) TestBackfill() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+	m := &mockDynamoDB{}
+	c := NewWithSink(NewDynamoDBSink(m, "backups"), "rid")
+
+	second := testBackup()
+	second.ID = "bid2"
+
+	n, err := c.Backfill(ctx, []*backup.Backup{testBackup(), second})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, m.puts, 2)
+	assert.Equal(t, "bid2", aws.StringValue(m.puts[1].Item["backup_id"].S))
+
+	m.err = errors.New("throttled")
+	n, err = c.Backfill(ctx, []*backup.Backup{testBackup(), second})
+	assert.Error(t, err)
+	assert.Zero(t, n)
+}
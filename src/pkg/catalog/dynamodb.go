@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+var _ Sink = &DynamoDBSink{}
+
+// DynamoDBSink writes records to a table with a string partition key named
+// repo_id, and a string sort key named backup_id.  Times are written as
+// RFC 3339 strings.
+type DynamoDBSink struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// NewDynamoDBSink produces a sink that writes to the table through the client.
+func NewDynamoDBSink(client dynamodbiface.DynamoDBAPI, table string) *DynamoDBSink {
+	return &DynamoDBSink{client: client, table: table}
+}
+
+// openDynamoDB connects to DynamoDB with the credentials found in the aws
+// environment.
+func openDynamoDB(region, endpoint, table string) (*DynamoDBSink, error) {
+	cfg := aws.NewConfig()
+
+	if len(region) > 0 {
+		cfg = cfg.WithRegion(region)
+	}
+
+	if len(endpoint) > 0 {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, clues.Wrap(err, "creating aws session")
+	}
+
+	return NewDynamoDBSink(dynamodb.New(sess), table), nil
+}
+
+// Put replaces the backup's record.
+func (s *DynamoDBSink) Put(ctx context.Context, r Record) error {
+	item, err := dynamodbattribute.MarshalMap(r)
+	if err != nil {
+		return clues.Wrap(err, "marshalling record")
+	}
+
+	_, err = s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return clues.Wrap(err, "putting record")
+	}
+
+	return nil
+}
+
+// Delete removes the backup's record.
+func (s *DynamoDBSink) Delete(ctx context.Context, repoID, backupID string) error {
+	_, err := s.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"repo_id":   {S: aws.String(repoID)},
+			"backup_id": {S: aws.String(backupID)},
+		},
+	})
+	if err != nil {
+		return clues.Wrap(err, "deleting record")
+	}
+
+	return nil
+}
+
+// Close is a no-op: the client holds no connection.
+func (s *DynamoDBSink) Close() error {
+	return nil
+}
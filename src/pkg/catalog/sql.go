@@ -0,0 +1,194 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alcionai/clues"
+	// registers the "mysql" driver.
+	_ "github.com/go-sql-driver/mysql"
+	// registers the "pgx" driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+var _ Sink = &SQLSink{}
+
+// sqlColumns are the columns of the catalog table, in the order of the
+// values produced by sqlValues.
+var sqlColumns = []string{
+	"repo_id",
+	"backup_id",
+	"service",
+	"owner",
+	"status",
+	"incremental",
+	"created_at",
+	"started_at",
+	"completed_at",
+	"items_read",
+	"items_written",
+	"bytes_read",
+	"bytes_uploaded",
+	"errors",
+}
+
+// table names get interpolated into queries, and so are restricted to
+// plain, optionally schema-qualified, identifiers.
+var tableNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLSink writes records to a table with the columns:
+//
+//	repo_id, backup_id, service, owner, status, incremental, created_at,
+//	started_at, completed_at, items_read, items_written, bytes_read,
+//	bytes_uploaded, errors
+//
+// Records are replaced within a transaction, rather than upserted, so that
+// the sink works with any database that database/sql supports.
+type SQLSink struct {
+	db    *sql.DB
+	table string
+	// numbered placeholders ($1) instead of positional placeholders (?).
+	numbered bool
+}
+
+// NewSQLSink produces a sink that writes to the table in the database.
+// The driver names the database/sql driver that opened the db.
+func NewSQLSink(db *sql.DB, driver, table string) (*SQLSink, error) {
+	if !tableNameRE.MatchString(table) {
+		return nil, clues.New("invalid catalog table name").With("catalog_table", table)
+	}
+
+	return &SQLSink{
+		db:       db,
+		table:    table,
+		numbered: numberedPlaceholders(driver),
+	}, nil
+}
+
+// openSQL opens the database and verifies that it can be reached, so that
+// a misconfigured catalog is reported when connecting to the repository,
+// instead of after the first backup.
+func openSQL(ctx context.Context, driver, dsn, table string) (*SQLSink, error) {
+	if len(driver) == 0 || len(dsn) == 0 {
+		return nil, clues.New("sql catalog requires a driver and a data source name")
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, clues.Wrap(err, "opening catalog database").With("sql_driver", driver)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, clues.Wrap(err, "reaching catalog database").With("sql_driver", driver)
+	}
+
+	return NewSQLSink(db, driver, table)
+}
+
+// numberedPlaceholders returns true for the drivers of databases, such as
+// postgres, that don't accept positional placeholders.
+func numberedPlaceholders(driver string) bool {
+	switch strings.ToLower(driver) {
+	case "postgres", "pgx", "cloudsqlpostgres", "cockroach":
+		return true
+	}
+
+	return false
+}
+
+func (s *SQLSink) placeholders(from, n int) string {
+	ps := make([]string, 0, n)
+
+	for i := from; i < from+n; i++ {
+		if s.numbered {
+			ps = append(ps, fmt.Sprintf("$%d", i))
+		} else {
+			ps = append(ps, "?")
+		}
+	}
+
+	return strings.Join(ps, ", ")
+}
+
+func (s *SQLSink) deleteQuery() string {
+	if s.numbered {
+		return fmt.Sprintf("DELETE FROM %s WHERE repo_id = $1 AND backup_id = $2", s.table)
+	}
+
+	return fmt.Sprintf("DELETE FROM %s WHERE repo_id = ? AND backup_id = ?", s.table)
+}
+
+func (s *SQLSink) insertQuery() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		s.table,
+		strings.Join(sqlColumns, ", "),
+		s.placeholders(1, len(sqlColumns)))
+}
+
+func sqlValues(r Record) []any {
+	return []any{
+		r.RepoID,
+		r.BackupID,
+		r.Service,
+		r.Owner,
+		r.Status,
+		r.Incremental,
+		r.CreatedAt,
+		r.StartedAt,
+		r.CompletedAt,
+		r.ItemsRead,
+		r.ItemsWritten,
+		r.BytesRead,
+		r.BytesUploaded,
+		r.Errors,
+	}
+}
+
+// Put replaces the backup's record.
+func (s *SQLSink) Put(ctx context.Context, r Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return clues.Wrap(err, "beginning transaction")
+	}
+
+	if _, err := tx.ExecContext(ctx, s.deleteQuery(), r.RepoID, r.BackupID); err != nil {
+		rollback(tx)
+		return clues.Wrap(err, "removing previous record")
+	}
+
+	if _, err := tx.ExecContext(ctx, s.insertQuery(), sqlValues(r)...); err != nil {
+		rollback(tx)
+		return clues.Wrap(err, "inserting record")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return clues.Wrap(err, "committing transaction")
+	}
+
+	return nil
+}
+
+// Delete removes the backup's record.
+func (s *SQLSink) Delete(ctx context.Context, repoID, backupID string) error {
+	if _, err := s.db.ExecContext(ctx, s.deleteQuery(), repoID, backupID); err != nil {
+		return clues.Wrap(err, "deleting record")
+	}
+
+	return nil
+}
+
+// Close closes the database.
+func (s *SQLSink) Close() error {
+	return s.db.Close()
+}
+
+// rollback abandons a failed transaction.  The transaction's error is the
+// one worth reporting, so rollback errors are dropped.
+func rollback(tx *sql.Tx) {
+	_ = tx.Rollback()
+}
@@ -1,22 +1,65 @@
 package control
 
 import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+
 	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/pkg/transform"
 )
 
-// Options holds the optional configurations for a process
+// Options holds the optional configurations for a process.
 type Options struct {
-	Collision          CollisionPolicy `json:"-"`
-	DisableMetrics     bool            `json:"disableMetrics"`
-	FailFast           bool            `json:"failFast"`
-	RestorePermissions bool            `json:"restorePermissions"`
-	ToggleFeatures     Toggles         `json:"ToggleFeatures"`
+	Alerts         AlertOptions       `json:"alerts"`
+	Approval       ApprovalOptions    `json:"approval"`
+	Bandwidth      BandwidthOptions   `json:"bandwidth"`
+	Catalog        CatalogOptions     `json:"catalog"`
+	Collision      CollisionPolicy    `json:"-"`
+	DeltaCache     DeltaCacheOptions  `json:"deltaCache"`
+	DisableMetrics bool               `json:"disableMetrics"`
+	EWS            EWSOptions         `json:"ews"`
+	Events         EventsOptions      `json:"events"`
+	FailFast       bool               `json:"failFast"`
+	Heartbeat      HeartbeatOptions   `json:"heartbeat"`
+	MailRestore    MailRestoreOptions `json:"mailRestore"`
+	// MaxMemoryMB bounds the memory used by a backup: as the process
+	// approaches the limit, item retrieval is throttled, and once it passes
+	// the limit, freed buffers are returned to the os.  Zero is unbounded.
+	MaxMemoryMB int `json:"maxMemoryMB,omitempty"`
+	// OwnerEncryption only applies when initializing a repository: the
+	// repository encrypts each resource owner's data with the owner's own
+	// key, so that the owner's data can later be erased by destroying the key.
+	OwnerEncryption bool              `json:"-"`
+	Permissions     PermissionOptions `json:"permissions"`
+	ReadCache       ReadCacheOptions  `json:"readCache"`
+	// RestoreMailboxSettings opts in to restoring each user's automatic
+	// replies and working hours, which are otherwise backed up but left
+	// untouched.
+	RestoreMailboxSettings bool                 `json:"restoreMailboxSettings,omitempty"`
+	RestoreReport          RestoreReportOptions `json:"restoreReport"`
+	Spill                  SpillOptions         `json:"spill"`
+	// Staging tags the backups as staging backups.  Repositories created
+	// with CloneConfig set it whenever they're connected.
+	Staging bool `json:"staging,omitempty"`
+	// StructureOnly limits an Exchange backup to the mailbox's folder
+	// hierarchy, along with the count and size of the items in each folder,
+	// without retrieving any item content.
+	StructureOnly  bool          `json:"structureOnly,omitempty"`
+	ToggleFeatures Toggles       `json:"ToggleFeatures"`
+	Tuning         TuningOptions `json:"tuning"`
+
+	// Deprecated: use Permissions.OneDrive.Restore, which this maps onto.
+	RestorePermissions bool `json:"restorePermissions,omitempty"`
 }
 
 // Defaults provides an Options with the default values set.
 func Defaults() Options {
 	return Options{
 		FailFast:       true,
+		ReadCache:      ReadCacheOptions{MaxMetadataBytes: DefaultMetadataCacheBytes},
 		ToggleFeatures: Toggles{},
 	}
 }
@@ -41,7 +84,8 @@ const (
 // ---------------------------------------------------------------------------
 
 const (
-	defaultRestoreLocation = "Corso_Restore_"
+	defaultRestoreLocation    = "Corso_Restore_"
+	quarantineRestoreLocation = "Corso_Quarantine_Expires_"
 )
 
 // RestoreDestination is a POD that contains an override of the resource owner
@@ -53,8 +97,30 @@ type RestoreDestination struct {
 	// owner of the item.
 	ResourceOwnerOverride string
 	// ContainerName is the name of the root of the restored container hierarchy.
+	// Names of "/"-separated elements create the root beneath the containers
+	// named by the leading elements.  Ex: "Corso Restores/Corso_Restore_1".
 	// This field must be populated for a restore.
 	ContainerName string
+	// DriveName, if populated, redirects drive restores out of the original
+	// drive and into the document library with this name, keeping the
+	// restored items isolated.  The library is created if it does not exist.
+	DriveName string
+	// Transform, if populated, alters each item before it is restored.
+	Transform transform.Hook `json:"-"`
+}
+
+// ContainerElements splits the container name into the elements of the
+// path to the restore's root container, which is the last element.
+func ContainerElements(name string) []string {
+	elems := []string{}
+
+	for _, e := range strings.Split(name, "/") {
+		if len(e) > 0 {
+			elems = append(elems, e)
+		}
+	}
+
+	return elems
 }
 
 func DefaultRestoreDestination(timeFormat common.TimeFormat) RestoreDestination {
@@ -63,6 +129,488 @@ func DefaultRestoreDestination(timeFormat common.TimeFormat) RestoreDestination
 	}
 }
 
+// VerifyConfig directs how a verify operation checks the items in each
+// backup.
+type VerifyConfig struct {
+	// Scrub deserializes each checked item the way a restore would, to catch
+	// corrupted data that storage still reads back without error.
+	Scrub bool
+	// SampleSize, when positive, checks a random sample of at most this many
+	// items in each backup, instead of every item.
+	SampleSize int
+}
+
+// QuarantineRestoreDestination produces a destination whose root container
+// is marked as a quarantine, and tagged with the time at which it expires.
+// Expired quarantine containers can be removed with PurgeExpiredQuarantines.
+func QuarantineRestoreDestination(timeFormat common.TimeFormat, ttl time.Duration) RestoreDestination {
+	return RestoreDestination{
+		ContainerName: quarantineRestoreLocation + common.FormatTimeWith(time.Now().Add(ttl), timeFormat),
+	}
+}
+
+// IsQuarantineContainer returns true if the container name was
+// produced by QuarantineRestoreDestination.
+func IsQuarantineContainer(name string) bool {
+	return strings.HasPrefix(name, quarantineRestoreLocation)
+}
+
+// QuarantineExpired returns true if the container name identifies a
+// quarantine container whose expiry tag is before the boundary time.
+func QuarantineExpired(name string, boundary time.Time) bool {
+	if !IsQuarantineContainer(name) {
+		return false
+	}
+
+	expiry, err := common.ExtractTime(strings.TrimPrefix(name, quarantineRestoreLocation))
+	if err != nil {
+		return false
+	}
+
+	return expiry.Before(boundary)
+}
+
+// ---------------------------------------------------------------------------
+// Disk Spill
+// ---------------------------------------------------------------------------
+
+// SpillOptions configures the disk-backed buffer that holds item data while
+// it waits to be uploaded.  Spilling is disabled unless MaxBytes is positive.
+type SpillOptions struct {
+	// Dir is the parent directory of the buffer.  If empty, the system's
+	// default temp directory is used.
+	Dir string `json:"dir,omitempty"`
+	// MaxBytes bounds the amount of data held on disk at any one time.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+}
+
+// Enabled returns true if the options allow spilling to disk.
+func (so SpillOptions) Enabled() bool {
+	return so.MaxBytes > 0
+}
+
+// ---------------------------------------------------------------------------
+// Read Cache
+// ---------------------------------------------------------------------------
+
+// DefaultMetadataCacheBytes is the default bound of the cache of repository
+// metadata.
+const DefaultMetadataCacheBytes = 512 << 20
+
+// ReadCacheOptions configures the local disk cache of the data read out of
+// the repository's storage.  The cache persists between runs, so restoring
+// or exporting the same backups again reads from disk instead of the object
+// store.  Caching metadata is disabled unless MaxMetadataBytes is positive.
+// Kopia only caches anything when item data has a bound, so if MaxBytes
+// isn't positive, item data shares the metadata's bound.
+type ReadCacheOptions struct {
+	// Dir is the directory that holds the cache.  If empty, the cache is
+	// kept alongside the repository's kopia config.
+	Dir string `json:"dir,omitempty"`
+	// MaxBytes bounds the size of the cache.  The least recently used data
+	// is evicted to stay beneath the bound.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+	// MaxMetadataBytes bounds the cache of the repository's metadata, such
+	// as the manifests that describe each backup.  Along with the indexes
+	// that locate the repository's content, which are always cached in the
+	// same directory, cached metadata spares commands like listing backups
+	// from reloading it out of storage on every run.
+	MaxMetadataBytes int64 `json:"maxMetadataBytes,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Delta Cache
+// ---------------------------------------------------------------------------
+
+// DeltaCacheOptions configures the local disk cache of drive delta pages.
+// The cache persists between runs, so a backup retried shortly after a
+// failure reuses the pages it already enumerated instead of requesting them
+// from M365 again.  Caching is disabled unless MaxAge is positive.
+type DeltaCacheOptions struct {
+	// Dir is the directory that holds the cache.  If empty, the user's
+	// cache directory is used.
+	Dir string `json:"dir,omitempty"`
+	// MaxAge is how long a cached page stays fresh, up to 24 hours.
+	// Changes made to the drive within that window are picked up by the
+	// following backup.
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+}
+
+// Enabled returns true if the options allow caching delta pages.
+func (dco DeltaCacheOptions) Enabled() bool {
+	return dco.MaxAge > 0
+}
+
+// ---------------------------------------------------------------------------
+// Catalog Sync
+// ---------------------------------------------------------------------------
+
+// CatalogKind identifies the external system that mirrors backup records.
+type CatalogKind string
+
+const (
+	// CatalogSQL mirrors backup records to a SQL database table.  The
+	// "pgx" (postgres) and "mysql" drivers are registered by corso; SDK
+	// users can register any other database/sql driver.
+	CatalogSQL CatalogKind = "sql"
+	// CatalogDynamoDB mirrors backup records to a DynamoDB table.
+	CatalogDynamoDB CatalogKind = "dynamodb"
+)
+
+// CatalogOptions configures the external catalog that mirrors the backup
+// records of the repository after each operation, so that the protection
+// status of many repositories can be queried in one place.  An empty Kind
+// disables the catalog.
+type CatalogOptions struct {
+	Kind CatalogKind `json:"kind,omitempty"`
+	// Table receives the backup records.
+	Table string `json:"table,omitempty"`
+	// SQLDriver names the registered database/sql driver (ex: pgx, mysql).
+	SQLDriver string `json:"sqlDriver,omitempty"`
+	// SQLDSN is the data source name used to connect to the database.
+	// It's left out of serialized options, since it may hold credentials.
+	SQLDSN string `json:"-"`
+	// Region of the DynamoDB table.  Defaults to the aws environment.
+	Region string `json:"region,omitempty"`
+	// Endpoint overrides the DynamoDB endpoint (ex: for local testing).
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Enabled returns true if a catalog is configured.
+func (co CatalogOptions) Enabled() bool {
+	return len(co.Kind) > 0
+}
+
+// ---------------------------------------------------------------------------
+// Exchange Web Services
+// ---------------------------------------------------------------------------
+
+// EWSOptions configures the Exchange Web Services (EWS) restore transport.
+// Hybrid tenants can keep mailboxes on-premises, where graph refuses to
+// create folders or items.  With Fallback enabled, Exchange restores create
+// the destination folders and items of those mailboxes through EWS instead.
+type EWSOptions struct {
+	// Fallback restores folders and items through EWS when graph reports that the
+	// mailbox isn't enabled for its api.
+	Fallback bool `json:"fallback,omitempty"`
+	// Endpoint is the url of the EWS service.  Defaults to Exchange Online.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Mail Restore
+// ---------------------------------------------------------------------------
+
+// MailRestoreOptions guards the mailboxes that Exchange restores write
+// into.  Before large mail restores, the destination mailbox's quota is
+// checked, and the restore is warned about, or failed, if the mailbox is
+// full.  Mail writes can also be paced, so that bursts of new mail don't
+// trip the mailbox's transport protections.
+type MailRestoreOptions struct {
+	// FailOverQuota fails restores into a full destination mailbox,
+	// instead of warning about them.
+	FailOverQuota bool `json:"failOverQuota,omitempty"`
+	// SkipQuotaCheck skips the quota check.
+	SkipQuotaCheck bool `json:"skipQuotaCheck,omitempty"`
+	// MessagesPerMinute bounds the rate at which messages are written into
+	// each mailbox.  Zero is unbounded.
+	MessagesPerMinute int `json:"messagesPerMinute,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Permissions
+// ---------------------------------------------------------------------------
+
+// PermissionOptions toggles the backup and restore of permissions for each
+// service that supports them.  Backing up permissions does not imply that
+// they get restored: permissions captured in a backup are only reapplied
+// when restore is toggled on as well.  Both are off by default.
+type PermissionOptions struct {
+	// OneDrive toggles the sharing permissions of OneDrive files and folders.
+	OneDrive PermissionToggles `json:"oneDrive"`
+	// SharePoint toggles the access inventory (groups and role assignments)
+	// of each site's libraries.  The inventory is recorded in the backup for
+	// review, but is not yet reapplied on restore, so only Backup applies.
+	SharePoint PermissionToggles `json:"sharePoint"`
+}
+
+// EffectivePermissions produces the permission options, with the deprecated
+// RestorePermissions and ToggleFeatures.EnablePermissionsBackup fields
+// mapped onto them.  Consumers of the options read permissions through
+// this, rather than the Permissions field, so that callers still setting
+// the deprecated fields keep their behavior.
+func (o Options) EffectivePermissions() PermissionOptions {
+	po := o.Permissions
+
+	if o.RestorePermissions {
+		po.OneDrive.Restore = true
+	}
+
+	if o.ToggleFeatures.EnablePermissionsBackup {
+		po.OneDrive.Backup = true
+		po.SharePoint.Backup = true
+	}
+
+	return po
+}
+
+// PermissionToggles turns permissions on or off for a single service.
+type PermissionToggles struct {
+	// Backup retrieves permissions during backups.  Retrieving permissions
+	// increases graph api call count, so disabling their retrieval when not
+	// needed is advised.
+	Backup bool `json:"backup,omitempty"`
+	// Restore reapplies backed up permissions to the restored items.
+	Restore bool `json:"restore,omitempty"`
+	// RestoreOnly reapplies backed up permissions to the original items,
+	// wherever they still exist, instead of restoring any content.  Used to
+	// undo accidental changes to sharing.
+	RestoreOnly bool `json:"restoreOnly,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Tuning
+// ---------------------------------------------------------------------------
+
+// TuningOptions adjusts the concurrency, paging, and retries used when
+// retrieving data from M365.  Zero values retain each service's defaults.
+type TuningOptions struct {
+	// Profile is the name of the profile the options came from, if any.
+	Profile TuningProfile `json:"profile,omitempty"`
+	// ItemConcurrency is the number of items retrieved at once within each
+	// collection.  Exchange never exceeds 4, Outlook's limit of concurrent
+	// requests per mailbox.
+	ItemConcurrency int `json:"itemConcurrency,omitempty"`
+	// DeltaPageSize is the number of entries requested in each page when
+	// enumerating changes to mail, contacts, and drives.  Drives accept at
+	// most 999.
+	DeltaPageSize int `json:"deltaPageSize,omitempty"`
+	// ItemRetries is the number of times the retrieval of an item is
+	// attempted again after a retriable failure.
+	ItemRetries int `json:"itemRetries,omitempty"`
+	// RetryDelay is the delay before the first retry of an item.  Later
+	// retries back off exponentially.
+	RetryDelay time.Duration `json:"retryDelay,omitempty"`
+	// CategoryWeights shares item retrieval between the categories of an
+	// Exchange backup (ex: "email", "contacts", "events") in proportion to
+	// their weights, so that a large category can't starve the others.
+	// Categories without a weight have a weight of 1.
+	CategoryWeights map[string]int `json:"categoryWeights,omitempty"`
+}
+
+// TuningProfile names a bundle of tuning options suited to a kind of
+// tenant, so that options don't need to be tuned one at a time.
+type TuningProfile string
+
+const (
+	// SmallTenantProfile keeps a light footprint on tenants with few users
+	// and little data.
+	SmallTenantProfile TuningProfile = "small-tenant"
+	// LargeTenantProfile retrieves more data at once from tenants with many
+	// users or large mailboxes and drives.
+	LargeTenantProfile TuningProfile = "large-tenant"
+	// ThrottledProfile favors steady progress over speed on tenants where
+	// graph frequently throttles requests.
+	ThrottledProfile TuningProfile = "throttled"
+)
+
+var tuningProfiles = map[TuningProfile]TuningOptions{
+	SmallTenantProfile: {
+		ItemConcurrency: 2,
+		DeltaPageSize:   100,
+		ItemRetries:     3,
+	},
+	LargeTenantProfile: {
+		ItemConcurrency: 16,
+		DeltaPageSize:   999,
+		ItemRetries:     4,
+	},
+	ThrottledProfile: {
+		ItemConcurrency: 1,
+		DeltaPageSize:   100,
+		ItemRetries:     8,
+		RetryDelay:      10 * time.Second,
+	},
+}
+
+// Tuning produces the options bundled in the named profile.
+func Tuning(profile TuningProfile) (TuningOptions, error) {
+	to, ok := tuningProfiles[profile]
+	if !ok {
+		return TuningOptions{}, clues.New("unknown tuning profile").
+			With("profile", profile, "known_profiles", TuningProfiles())
+	}
+
+	to.Profile = profile
+
+	return to, nil
+}
+
+// TuningProfiles lists the names of all tuning profiles.
+func TuningProfiles() []string {
+	ps := make([]string, 0, len(tuningProfiles))
+
+	for p := range tuningProfiles {
+		ps = append(ps, string(p))
+	}
+
+	sort.Strings(ps)
+
+	return ps
+}
+
+// ---------------------------------------------------------------------------
+// Usage Events
+// ---------------------------------------------------------------------------
+
+// EventsOptions configures the destination of usage events.
+type EventsOptions struct {
+	// LocalOnly restricts usage events to a local sink.  When set, no
+	// events are transmitted to any external service.
+	LocalOnly bool `json:"localOnly,omitempty"`
+	// LocalFile is the file to which local-only events are appended, one
+	// json object per line.  If empty, local-only events are written to
+	// the log.
+	LocalFile string `json:"localFile,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Backup Alerts
+// ---------------------------------------------------------------------------
+
+// AlertOptions sets the thresholds at which backups emit warning events, so
+// that operators learn of anomalies, such as mass changes to a mailbox or
+// drive, without waiting to review the backup.  Zero thresholds are disabled.
+type AlertOptions struct {
+	// MaxDuration warns as soon as a backup runs for longer.
+	MaxDuration time.Duration `json:"maxDuration,omitempty"`
+	// MaxChangedItems warns when an incremental backup finds at least
+	// this many added, changed, or deleted items.
+	MaxChangedItems int `json:"maxChangedItems,omitempty"`
+	// MaxChangedPercent warns when an incremental backup finds at least
+	// this percentage of its items were added, changed, or deleted.
+	MaxChangedPercent float64 `json:"maxChangedPercent,omitempty"`
+	// DetectAnomalies compares the details of incremental backups against
+	// their bases, warning of patterns such as mass deletions, or files
+	// renamed with a new extension, that ransomware tends to produce.
+	DetectAnomalies bool `json:"detectAnomalies,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Backup Heartbeat
+// ---------------------------------------------------------------------------
+
+// HeartbeatOptions configures the watchdog that reports the progress of a
+// backup's collections, and catches collections that stop making progress
+// instead of letting them hang the backup.
+type HeartbeatOptions struct {
+	// Interval between heartbeat events.  Zero disables heartbeats.
+	Interval time.Duration `json:"interval,omitempty"`
+	// StallAfter flags a collection as stalled once its in-flight items
+	// make no progress for this long.  Zero disables stall detection.
+	StallAfter time.Duration `json:"stallAfter,omitempty"`
+	// AbortStalled cancels stalled collections, recording a failure for
+	// each, so that the rest of the backup can complete.
+	AbortStalled bool `json:"abortStalled,omitempty"`
+}
+
+// Enabled returns true if the options produce heartbeats or catch stalls.
+func (ho HeartbeatOptions) Enabled() bool {
+	return ho.Interval > 0 || ho.StallAfter > 0
+}
+
+// ---------------------------------------------------------------------------
+// Bandwidth
+// ---------------------------------------------------------------------------
+
+// BandwidthOptions bounds the rate at which data is transferred to and from
+// M365.  Backups and restores draw on separate budgets, since restores are
+// often urgent, and may be allowed to consume more than routine backups.
+// A budget of zero is unbounded.
+type BandwidthOptions struct {
+	// BackupBytesPerSecond bounds the transfer rate of backups.
+	BackupBytesPerSecond int64 `json:"backupBytesPerSecond,omitempty"`
+	// RestoreBytesPerSecond bounds the transfer rate of restores.  Restores
+	// don't inherit the backup budget.
+	RestoreBytesPerSecond int64 `json:"restoreBytesPerSecond,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Restore Approval
+// ---------------------------------------------------------------------------
+
+// DefaultApprovalTimeout is how long a restore waits for approval when no
+// timeout is configured.
+const DefaultApprovalTimeout = time.Hour
+
+// ApprovalOptions configures how a restore waits on the approval required
+// by the repository's restore policy.  The approval thresholds belong to
+// the policy, so that they can't be bypassed by the person running the
+// restore.
+type ApprovalOptions struct {
+	// Timeout is how long a restore waits for its approval before failing.
+	// A failed restore can be resumed once it's approved.  Zero waits for
+	// the DefaultApprovalTimeout.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Restore Report
+// ---------------------------------------------------------------------------
+
+// RestoreReportOptions produces a summary of each restore, including those
+// that fail before restoring anything: what was restored, where it was
+// restored to, and any failures.  The report is
+// written to a file, mailed from a service mailbox, or both.  No report is
+// produced unless a file or a sender and recipients are set.
+type RestoreReportOptions struct {
+	// File is the path to which the report is written.
+	File string `json:"file,omitempty"`
+	// MailFrom is the mailbox, by principal name or ID, that sends the
+	// report.  Sending requires the Mail.Send permission.
+	MailFrom string `json:"mailFrom,omitempty"`
+	// MailTo are the addresses that receive the report.
+	MailTo []string `json:"mailTo,omitempty"`
+}
+
+// Enabled returns true if the report is written to a file or mailed.
+func (rro RestoreReportOptions) Enabled() bool {
+	return len(rro.File) > 0 || rro.Mails()
+}
+
+// Mails returns true if both a sender and recipients are set.
+func (rro RestoreReportOptions) Mails() bool {
+	return len(rro.MailFrom) > 0 && len(rro.MailTo) > 0
+}
+
+// ---------------------------------------------------------------------------
+// Metadata Retention
+// ---------------------------------------------------------------------------
+
+// DefaultMetadataMinAge is the minimum age of a backup snapshot before it
+// can be pruned by metadata compaction.
+const DefaultMetadataMinAge = 24 * time.Hour
+
+// MetadataRetention describes which backup snapshots, and the metadata
+// collections (delta tokens, previous paths) they hold, are preserved when
+// compacting the repository.
+type MetadataRetention struct {
+	// KeepIncomplete retains incomplete snapshots even after a newer,
+	// complete snapshot replaces them as the incremental base.
+	KeepIncomplete bool
+	// KeepUnreferenced retains complete snapshots that are not referenced
+	// by any backup.
+	KeepUnreferenced bool
+	// MinAge protects snapshots younger than the duration from removal.
+	MinAge time.Duration
+}
+
+// DefaultMetadataRetention provides a MetadataRetention with the default
+// values set.
+func DefaultMetadataRetention() MetadataRetention {
+	return MetadataRetention{MinAge: DefaultMetadataMinAge}
+}
+
 // ---------------------------------------------------------------------------
 // Feature Flags and Toggles
 // ---------------------------------------------------------------------------
@@ -75,9 +623,19 @@ type Toggles struct {
 	// DisableIncrementals prevents backups from using incremental lookups,
 	// forcing a new, complete backup of all data regardless of prior state.
 	DisableIncrementals bool `json:"exchangeIncrementals,omitempty"`
+	// RawDriveEnumeration decodes the pages of OneDrive and SharePoint drive
+	// enumeration directly from their json, rather than through the graph
+	// sdk's models, reducing the cpu and memory spent on very large drives.
+	RawDriveEnumeration bool `json:"rawDriveEnumeration,omitempty"`
+	// SimulateExpiredDeltas treats every previous delta token as expired,
+	// forcing backups down the same fallback path they take when graph
+	// expires a token.  Used to validate the incremental fallbacks.
+	SimulateExpiredDeltas bool `json:"simulateExpiredDeltas,omitempty"`
+	// DisableHashSkip downloads every changed OneDrive and SharePoint file,
+	// even when its content hash shows that only its metadata changed.
+	DisableHashSkip bool `json:"disableHashSkip,omitempty"`
 
-	// EnablePermissionsBackup is used to enable backups of item
-	// permissions. Permission metadata increases graph api call count,
-	// so disabling their retrieval when not needed is advised.
+	// Deprecated: use Permissions.OneDrive.Backup and
+	// Permissions.SharePoint.Backup, which this maps onto.
 	EnablePermissionsBackup bool `json:"enablePermissionsBackup,omitempty"`
 }
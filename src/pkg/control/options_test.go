@@ -0,0 +1,115 @@
+package control_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type OptionsUnitSuite struct {
+	tester.Suite
+}
+
+func TestOptionsUnitSuite(t *testing.T) {
+	suite.Run(t, &OptionsUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *OptionsUnitSuite) TestEffectivePermissions() {
+	table := []struct {
+		name   string
+		opts   control.Options
+		expect control.PermissionOptions
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "permissions",
+			opts: control.Options{
+				Permissions: control.PermissionOptions{
+					OneDrive: control.PermissionToggles{Restore: true},
+				},
+			},
+			expect: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Restore: true},
+			},
+		},
+		{
+			name: "deprecated restore",
+			opts: control.Options{RestorePermissions: true},
+			expect: control.PermissionOptions{
+				OneDrive: control.PermissionToggles{Restore: true},
+			},
+		},
+		{
+			name: "deprecated backup",
+			opts: control.Options{
+				ToggleFeatures: control.Toggles{EnablePermissionsBackup: true},
+			},
+			expect: control.PermissionOptions{
+				OneDrive:   control.PermissionToggles{Backup: true},
+				SharePoint: control.PermissionToggles{Backup: true},
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, test.opts.EffectivePermissions())
+		})
+	}
+}
+
+func (suite *OptionsUnitSuite) TestEffectivePermissions_deprecatedJSON() {
+	t := suite.T()
+
+	var opts control.Options
+
+	err := json.Unmarshal(
+		[]byte(`{"restorePermissions": true, "ToggleFeatures": {"enablePermissionsBackup": true}}`),
+		&opts)
+	require.NoError(t, err)
+
+	po := opts.EffectivePermissions()
+	assert.True(t, po.OneDrive.Backup)
+	assert.True(t, po.OneDrive.Restore)
+	assert.True(t, po.SharePoint.Backup)
+}
+
+func (suite *OptionsUnitSuite) TestContainerElements() {
+	table := []struct {
+		name   string
+		input  string
+		expect []string
+	}{
+		{
+			name:   "single",
+			input:  "Corso_Restore_1",
+			expect: []string{"Corso_Restore_1"},
+		},
+		{
+			name:   "nested",
+			input:  "Corso Restores/Corso_Restore_1",
+			expect: []string{"Corso Restores", "Corso_Restore_1"},
+		},
+		{
+			name:   "extra separators",
+			input:  "/Corso Restores//Corso_Restore_1/",
+			expect: []string{"Corso Restores", "Corso_Restore_1"},
+		},
+		{
+			name:   "empty",
+			expect: []string{},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, control.ContainerElements(test.input))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package control
+
+import (
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/internal/model"
+)
+
+// QuotaOptions bounds the size of the repository's object storage.  A limit
+// of zero is unbounded.
+type QuotaOptions struct {
+	// SoftLimitBytes is the repository size at which backups emit warnings.
+	SoftLimitBytes int64 `json:"softLimitBytes,omitempty"`
+	// HardLimitBytes is the repository size that backups should not exceed.
+	HardLimitBytes int64 `json:"hardLimitBytes,omitempty"`
+	// FailAtHardLimit prevents backups from starting once the repository
+	// has reached the hard limit.  Otherwise, reaching the hard limit only
+	// produces warnings.
+	FailAtHardLimit bool `json:"failAtHardLimit,omitempty"`
+}
+
+// Enabled returns true if either quota limit is set.
+func (qo QuotaOptions) Enabled() bool {
+	return qo.SoftLimitBytes > 0 || qo.HardLimitBytes > 0
+}
+
+// Validate checks that the limits are well formed.
+func (qo QuotaOptions) Validate() error {
+	if qo.SoftLimitBytes < 0 || qo.HardLimitBytes < 0 {
+		return clues.New("quota limits can't be negative")
+	}
+
+	if qo.SoftLimitBytes > 0 && qo.HardLimitBytes > 0 && qo.SoftLimitBytes > qo.HardLimitBytes {
+		return clues.New("the soft quota limit can't exceed the hard limit").
+			With("soft_limit_bytes", qo.SoftLimitBytes, "hard_limit_bytes", qo.HardLimitBytes)
+	}
+
+	return nil
+}
+
+// RepoQuota is the quota stored in the repository, which every backup into
+// the repository is held to.  It also caches the size of the repository,
+// since measuring the size lists every object in the repository's storage.
+type RepoQuota struct {
+	model.BaseModel
+
+	Limits QuotaOptions `json:"limits"`
+	// Size is the last known size of the repository, in bytes.  Backups
+	// add the bytes they upload to the size, and it's measured again once
+	// MeasuredAt is older than RepoSizeTTL.
+	Size       int64     `json:"size,omitempty"`
+	MeasuredAt time.Time `json:"measuredAt,omitempty"`
+}
+
+// RepoSizeTTL is how long a measured repository size is reused before the
+// repository's storage is measured again.
+const RepoSizeTTL = 24 * time.Hour
+
+// SizeExpired returns true if the cached size needs to be measured again.
+func (q RepoQuota) SizeExpired(now time.Time) bool {
+	return q.MeasuredAt.IsZero() || now.Sub(q.MeasuredAt) > RepoSizeTTL
+}
@@ -0,0 +1,51 @@
+package control_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type QuotaUnitSuite struct {
+	tester.Suite
+}
+
+func TestQuotaUnitSuite(t *testing.T) {
+	suite.Run(t, &QuotaUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *QuotaUnitSuite) TestValidate() {
+	table := []struct {
+		name      string
+		opts      control.QuotaOptions
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"no limits", control.QuotaOptions{}, assert.NoError},
+		{"soft limit only", control.QuotaOptions{SoftLimitBytes: 10}, assert.NoError},
+		{"hard limit only", control.QuotaOptions{HardLimitBytes: 10}, assert.NoError},
+		{"soft under hard", control.QuotaOptions{SoftLimitBytes: 5, HardLimitBytes: 10}, assert.NoError},
+		{"soft over hard", control.QuotaOptions{SoftLimitBytes: 20, HardLimitBytes: 10}, assert.Error},
+		{"negative limit", control.QuotaOptions{HardLimitBytes: -1}, assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expectErr(suite.T(), test.opts.Validate())
+		})
+	}
+}
+
+func (suite *QuotaUnitSuite) TestSizeExpired() {
+	var (
+		t   = suite.T()
+		now = time.Now()
+	)
+
+	assert.True(t, control.RepoQuota{}.SizeExpired(now), "never measured")
+	assert.False(t, control.RepoQuota{MeasuredAt: now.Add(-time.Hour)}.SizeExpired(now))
+	assert.True(t, control.RepoQuota{MeasuredAt: now.Add(-2 * control.RepoSizeTTL)}.SizeExpired(now))
+}
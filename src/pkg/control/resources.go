@@ -0,0 +1,170 @@
+package control
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Resource Limits
+// ---------------------------------------------------------------------------
+
+// cgroupRoot is where linux mounts the process's control groups.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroup v1 reports an unbounded memory limit as the largest page-aligned
+// int64, so any limit beyond this is treated as unbounded.
+const unboundedMemory = 1 << 62
+
+const (
+	// fitMemoryPercent is the share of the container's memory that
+	// MaxMemoryMB holds the process beneath, leaving headroom for the
+	// memory that the watchdog doesn't track.
+	fitMemoryPercent = 80
+	// fitItemsPerCPU is the number of items retrieved at once for each
+	// cpu available to containers with less than fitCPUs cpus.
+	fitItemsPerCPU = 2
+	fitCPUs        = 2
+)
+
+// ResourceLimits are the cpu and memory available to the process, as bounded
+// by its container (ex: a kubernetes pod).  Zero values are unbounded.
+type ResourceLimits struct {
+	// CPUs is the container's cpu quota, which may be fractional.
+	CPUs float64 `json:"cpus,omitempty"`
+	// MemoryBytes is the container's memory limit.
+	MemoryBytes int64 `json:"memoryBytes,omitempty"`
+}
+
+// DetectResources reads the cpu and memory limits of the process's
+// container from its control groups, supporting both cgroup v1 and v2.
+// Processes outside of a container, or on platforms without cgroups,
+// are unbounded.
+func DetectResources() ResourceLimits {
+	return DetectResourcesIn(cgroupRoot)
+}
+
+// DetectResourcesIn reads the limits from the control groups mounted at root.
+func DetectResourcesIn(root string) ResourceLimits {
+	// cgroup v2 unifies the controllers within a single hierarchy.
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return ResourceLimits{
+			CPUs:        cgroupV2CPUs(root),
+			MemoryBytes: readLimit(filepath.Join(root, "memory.max")),
+		}
+	}
+
+	return ResourceLimits{
+		CPUs:        cgroupV1CPUs(root),
+		MemoryBytes: readLimit(filepath.Join(root, "memory", "memory.limit_in_bytes")),
+	}
+}
+
+// cgroupV2CPUs reads cpu.max, which holds the quota and period
+// (ex: "150000 100000"), or "max" if the quota is unbounded.
+func cgroupV2CPUs(root string) float64 {
+	bs, err := os.ReadFile(filepath.Join(root, "cpu.max"))
+	if err != nil {
+		return 0
+	}
+
+	fs := strings.Fields(string(bs))
+	if len(fs) != 2 {
+		return 0
+	}
+
+	return quotaCPUs(fs[0], fs[1])
+}
+
+// cgroupV1CPUs reads the cfs quota and period, where a quota of -1 is
+// unbounded.  Some distributions mount the cpu controller alongside
+// cpuacct.
+func cgroupV1CPUs(root string) float64 {
+	for _, dir := range []string{"cpu", "cpu,cpuacct"} {
+		quota, err := os.ReadFile(filepath.Join(root, dir, "cpu.cfs_quota_us"))
+		if err != nil {
+			continue
+		}
+
+		period, err := os.ReadFile(filepath.Join(root, dir, "cpu.cfs_period_us"))
+		if err != nil {
+			continue
+		}
+
+		return quotaCPUs(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+	}
+
+	return 0
+}
+
+func quotaCPUs(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+
+	return q / p
+}
+
+// readLimit reads a memory limit in bytes.  Unbounded and unreadable
+// limits produce zero.
+func readLimit(file string) int64 {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return 0
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(string(bs)), 10, 64)
+	if err != nil || n <= 0 || n >= unboundedMemory {
+		return 0
+	}
+
+	return n
+}
+
+// Bounded returns true if either the cpus or memory are limited.
+func (rl ResourceLimits) Bounded() bool {
+	return rl.CPUs > 0 || rl.MemoryBytes > 0
+}
+
+// MaxProcs is the number of threads that can run go code at once within
+// the cpu quota, or zero if the cpus are unbounded.
+func (rl ResourceLimits) MaxProcs() int {
+	if rl.CPUs <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(rl.CPUs))
+}
+
+// FitTo scales the options' defaults to the resource limits, so that
+// processes in constrained containers behave without manual tuning.  Only
+// unset options are changed:
+//   - MaxMemoryMB holds the process beneath most of the memory limit,
+//     throttling retrieval before the container gets OOM killed.
+//   - Tuning.ItemConcurrency shrinks for containers with fewer than two
+//     cpus, which can't keep up with each service's default concurrency.
+func (o Options) FitTo(rl ResourceLimits) Options {
+	if o.MaxMemoryMB == 0 && rl.MemoryBytes > 0 {
+		mb := int(rl.MemoryBytes / 100 * fitMemoryPercent >> 20)
+		if mb < 1 {
+			mb = 1
+		}
+
+		o.MaxMemoryMB = mb
+	}
+
+	if o.Tuning.ItemConcurrency == 0 && rl.CPUs > 0 && rl.CPUs < fitCPUs {
+		o.Tuning.ItemConcurrency = int(math.Ceil(rl.CPUs * fitItemsPerCPU))
+	}
+
+	return o
+}
@@ -0,0 +1,139 @@
+package control_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type ResourcesUnitSuite struct {
+	tester.Suite
+}
+
+func TestResourcesUnitSuite(t *testing.T) {
+	suite.Run(t, &ResourcesUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ResourcesUnitSuite) TestDetectResources() {
+	table := []struct {
+		name   string
+		files  map[string]string
+		expect control.ResourceLimits
+	}{
+		{
+			name: "no cgroups",
+		},
+		{
+			name: "v2 bounded",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "536870912\n",
+			},
+			expect: control.ResourceLimits{CPUs: 1.5, MemoryBytes: 512 << 20},
+		},
+		{
+			name: "v2 unbounded",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+			},
+		},
+		{
+			name: "v1 bounded",
+			files: map[string]string{
+				"cpu,cpuacct/cpu.cfs_quota_us":  "50000\n",
+				"cpu,cpuacct/cpu.cfs_period_us": "100000\n",
+				"memory/memory.limit_in_bytes":  "1073741824\n",
+			},
+			expect: control.ResourceLimits{CPUs: 0.5, MemoryBytes: 1 << 30},
+		},
+		{
+			name: "v1 unbounded",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			root := t.TempDir()
+
+			for name, content := range test.files {
+				f := filepath.Join(root, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(f), 0o755))
+				require.NoError(t, os.WriteFile(f, []byte(content), 0o600))
+			}
+
+			rl := control.DetectResourcesIn(root)
+			assert.Equal(t, test.expect, rl)
+			assert.Equal(t, len(test.files) > 0 && test.expect != control.ResourceLimits{}, rl.Bounded())
+		})
+	}
+}
+
+func (suite *ResourcesUnitSuite) TestMaxProcs() {
+	assert.Equal(suite.T(), 0, control.ResourceLimits{}.MaxProcs())
+	assert.Equal(suite.T(), 1, control.ResourceLimits{CPUs: 0.25}.MaxProcs())
+	assert.Equal(suite.T(), 2, control.ResourceLimits{CPUs: 1.5}.MaxProcs())
+}
+
+func (suite *ResourcesUnitSuite) TestFitTo() {
+	table := []struct {
+		name              string
+		opts              control.Options
+		limits            control.ResourceLimits
+		expectMemoryMB    int
+		expectConcurrency int
+	}{
+		{
+			name: "unbounded",
+		},
+		{
+			name:              "small pod",
+			limits:            control.ResourceLimits{CPUs: 0.5, MemoryBytes: 1 << 30},
+			expectMemoryMB:    819,
+			expectConcurrency: 1,
+		},
+		{
+			name:              "one cpu",
+			limits:            control.ResourceLimits{CPUs: 1},
+			expectConcurrency: 2,
+		},
+		{
+			name:           "plenty of cpus",
+			limits:         control.ResourceLimits{CPUs: 8, MemoryBytes: 4 << 30},
+			expectMemoryMB: 3276,
+		},
+		{
+			name: "explicit options are kept",
+			opts: control.Options{
+				MaxMemoryMB: 2048,
+				Tuning:      control.TuningOptions{ItemConcurrency: 8},
+			},
+			limits:            control.ResourceLimits{CPUs: 0.5, MemoryBytes: 1 << 30},
+			expectMemoryMB:    2048,
+			expectConcurrency: 8,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			opts := test.opts.FitTo(test.limits)
+			assert.Equal(t, test.expectMemoryMB, opts.MaxMemoryMB)
+			assert.Equal(t, test.expectConcurrency, opts.Tuning.ItemConcurrency)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package control
+
+import (
+	"time"
+
+	"github.com/alcionai/corso/src/internal/model"
+)
+
+// RestoreApproval records a restore that exceeded the approval thresholds
+// of the restore policy, and whether it has been approved.  The restore
+// waits on the record until it's approved, and proceeds without waiting if
+// it's resumed afterward.
+type RestoreApproval struct {
+	model.BaseModel
+
+	RestoreID   model.StableID `json:"restoreID"`
+	BackupID    model.StableID `json:"backupID"`
+	Destination string         `json:"destination"`
+	// InPlace restores write to the original items, rather than to the
+	// destination.
+	InPlace bool `json:"inPlace,omitempty"`
+	// Items and Bytes describe the size of the restore that was requested.
+	Items       int       `json:"items"`
+	Bytes       int64     `json:"bytes"`
+	RequestedAt time.Time `json:"requestedAt"`
+	// RequestedBy and ApprovedBy identify the people who ran and approved
+	// the restore, as user@host.  A restore can't be approved by the
+	// person who requested it.
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	Approved   bool      `json:"approved,omitempty"`
+	ApprovedAt time.Time `json:"approvedAt,omitempty"`
+	ApprovedBy string    `json:"approvedBy,omitempty"`
+}
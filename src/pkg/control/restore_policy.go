@@ -0,0 +1,172 @@
+package control
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/corso/src/internal/model"
+)
+
+// ErrDestinationNotPermitted is produced when a restore destination falls
+// outside of the containers permitted by the repository's restore policy.
+var ErrDestinationNotPermitted = errors.New("restore destination is not permitted by the restore policy")
+
+// RestorePolicy restricts the destinations that restores within the
+// repository can write to.  Every restored container is created beneath
+// the destination's root container, so constraining where that root is
+// created, and its name, confines the restore to an approved subtree, and
+// keeps restores from writing into production folders.  A policy without
+// rules permits every destination.
+type RestorePolicy struct {
+	model.BaseModel
+
+	// ContainerSubtree, if populated, is the "/"-separated path of the
+	// containers that each restore's root container must be created
+	// directly beneath.  Ex: "Corso Restores".  Policies with other rules
+	// but no subtree require root containers at the top of the hierarchy.
+	ContainerSubtree string `json:"containerSubtree,omitempty"`
+	// ContainerPrefix, if populated, is required at the start of the name
+	// of each restore's root container.  Ex: "Corso_Restore_".
+	ContainerPrefix string `json:"containerPrefix,omitempty"`
+	// ContainerPattern, if populated, is a regular expression that must
+	// match the entire name of each restore's root container.
+	ContainerPattern string `json:"containerPattern,omitempty"`
+	// AllowInPlace permits restores that write to the original items
+	// instead of a restore container, such as permissions-only restores.
+	// Policies with destination rules otherwise refuse them.
+	AllowInPlace bool `json:"allowInPlace,omitempty"`
+	// Approval holds restores above a size threshold until they're
+	// approved.
+	Approval ApprovalPolicy `json:"approval,omitempty"`
+}
+
+// IsEmpty returns true if the policy has no destination rules.
+func (p RestorePolicy) IsEmpty() bool {
+	return len(p.ContainerSubtree) == 0 &&
+		len(p.ContainerPrefix) == 0 &&
+		len(p.ContainerPattern) == 0
+}
+
+// PermitsInPlace returns an ErrDestinationNotPermitted error if the policy
+// doesn't allow restores that write to the original items.
+func (p RestorePolicy) PermitsInPlace() error {
+	if p.IsEmpty() || p.AllowInPlace {
+		return nil
+	}
+
+	return clues.Stack(ErrDestinationNotPermitted).With("destination", "in place")
+}
+
+// Validate checks that the policy's rules are well formed.
+func (p RestorePolicy) Validate() error {
+	if _, err := p.pattern(); err != nil {
+		return err
+	}
+
+	return p.Approval.validate()
+}
+
+// Permits returns an ErrDestinationNotPermitted error if the policy
+// doesn't allow restores into the destination.
+func (p RestorePolicy) Permits(dest RestoreDestination) error {
+	if p.IsEmpty() {
+		return nil
+	}
+
+	var (
+		elems   = ContainerElements(dest.ContainerName)
+		subtree = ContainerElements(p.ContainerSubtree)
+	)
+
+	if len(elems) != len(subtree)+1 || !slices.Equal(elems[:len(subtree)], subtree) {
+		return clues.Stack(ErrDestinationNotPermitted).
+			With("destination", dest.ContainerName, "required_subtree", p.ContainerSubtree)
+	}
+
+	name := elems[len(elems)-1]
+
+	if len(p.ContainerPrefix) > 0 && !strings.HasPrefix(name, p.ContainerPrefix) {
+		return clues.Stack(ErrDestinationNotPermitted).
+			With("destination", name, "required_prefix", p.ContainerPrefix)
+	}
+
+	re, err := p.pattern()
+	if err != nil {
+		return err
+	}
+
+	if re != nil && !re.MatchString(name) {
+		return clues.Stack(ErrDestinationNotPermitted).
+			With("destination", name, "required_pattern", p.ContainerPattern)
+	}
+
+	return nil
+}
+
+// pattern compiles the container pattern, anchored so that it matches
+// the entire container name.  Produces nil if the policy has no pattern.
+func (p RestorePolicy) pattern() (*regexp.Regexp, error) {
+	if len(p.ContainerPattern) == 0 {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + p.ContainerPattern + ")$")
+	if err != nil {
+		return nil, clues.Wrap(err, "parsing restore container pattern").
+			With("pattern", p.ContainerPattern)
+	}
+
+	return re, nil
+}
+
+// ApprovalPolicy holds restores above a size threshold until they're
+// approved, so that large restores pass through change-control processes.
+// Zero thresholds are disabled.  While either threshold is set, restores
+// that write to the original items always need approval.
+type ApprovalPolicy struct {
+	// MaxItems requires approval for restores of more items.
+	MaxItems int `json:"maxItems,omitempty"`
+	// MaxBytes requires approval for restores of more bytes.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+	// Webhook, if set, is the url that receives a POST of each approval
+	// request, in addition to the approval request event.
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// Enabled returns true if either approval threshold is set.
+func (ap ApprovalPolicy) Enabled() bool {
+	return ap.MaxItems > 0 || ap.MaxBytes > 0
+}
+
+// Requires returns true if a restore of the items and bytes needs approval.
+func (ap ApprovalPolicy) Requires(items int, bytes int64) bool {
+	return (ap.MaxItems > 0 && items > ap.MaxItems) ||
+		(ap.MaxBytes > 0 && bytes > ap.MaxBytes)
+}
+
+func (ap ApprovalPolicy) validate() error {
+	if ap.MaxItems < 0 || ap.MaxBytes < 0 {
+		return clues.New("approval thresholds can't be negative").
+			With("max_items", ap.MaxItems, "max_bytes", ap.MaxBytes)
+	}
+
+	if len(ap.Webhook) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(ap.Webhook)
+	if err != nil {
+		return clues.Wrap(err, "parsing approval webhook")
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return clues.New("approval webhook must be an http or https url")
+	}
+
+	return nil
+}
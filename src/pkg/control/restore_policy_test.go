@@ -0,0 +1,158 @@
+package control_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+type RestorePolicyUnitSuite struct {
+	tester.Suite
+}
+
+func TestRestorePolicyUnitSuite(t *testing.T) {
+	suite.Run(t, &RestorePolicyUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *RestorePolicyUnitSuite) TestPermits() {
+	table := []struct {
+		name      string
+		policy    control.RestorePolicy
+		container string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "empty policy",
+			container: "Inbox",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "prefix match",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_Restore_"},
+			container: "Corso_Restore_01-Jan-2023_00-00-00",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "prefix mismatch",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_Restore_"},
+			container: "Inbox",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "pattern match",
+			policy:    control.RestorePolicy{ContainerPattern: "Corso_(Restore|Quarantine)_.*"},
+			container: "Corso_Quarantine_Expires_01-Jan-2023",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "pattern matches the whole name",
+			policy:    control.RestorePolicy{ContainerPattern: "Corso_Restore_"},
+			container: "Corso_Restore_01-Jan-2023",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "prefix and pattern",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_", ContainerPattern: ".*_Restore_.*"},
+			container: "Corso_Quarantine_Expires_01-Jan-2023",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "nested beneath a prefix policy",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_Restore_"},
+			container: "Inbox/Corso_Restore_01-Jan-2023",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "subtree match",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores"},
+			container: "Corso Restores/Case 1",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "nested subtree match",
+			policy:    control.RestorePolicy{ContainerSubtree: "/IT/Corso Restores/"},
+			container: "IT/Corso Restores/Case 1",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "outside the subtree",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores"},
+			container: "Inbox/Case 1",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "the subtree itself",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores"},
+			container: "Corso Restores",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "deeper than the subtree",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores"},
+			container: "Corso Restores/Inbox/Case 1",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "subtree and prefix",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores", ContainerPrefix: "Corso_Restore_"},
+			container: "Corso Restores/Corso_Restore_01-Jan-2023",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "subtree and prefix mismatch",
+			policy:    control.RestorePolicy{ContainerSubtree: "Corso Restores", ContainerPrefix: "Corso_Restore_"},
+			container: "Corso Restores/Case 1",
+			expectErr: assert.Error,
+		},
+		{
+			name:      "malformed pattern",
+			policy:    control.RestorePolicy{ContainerPattern: "("},
+			container: "Corso_Restore_01-Jan-2023",
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			err := test.policy.Permits(control.RestoreDestination{ContainerName: test.container})
+			test.expectErr(suite.T(), err)
+		})
+	}
+}
+
+func (suite *RestorePolicyUnitSuite) TestPermits_notPermitted() {
+	p := control.RestorePolicy{ContainerPrefix: "Corso_Restore_"}
+	err := p.Permits(control.RestoreDestination{ContainerName: "Inbox"})
+	assert.ErrorIs(suite.T(), err, control.ErrDestinationNotPermitted)
+}
+
+func (suite *RestorePolicyUnitSuite) TestPermitsInPlace() {
+	table := []struct {
+		name      string
+		policy    control.RestorePolicy
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "empty policy",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "destination rules",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_Restore_"},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "allowed in place",
+			policy:    control.RestorePolicy{ContainerPrefix: "Corso_Restore_", AllowInPlace: true},
+			expectErr: assert.NoError,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expectErr(suite.T(), test.policy.PermitsInPlace())
+		})
+	}
+}
@@ -9,14 +9,21 @@ import (
 
 // envvar consts
 const (
-	AzureClientID     = "AZURE_CLIENT_ID"
-	AzureClientSecret = "AZURE_CLIENT_SECRET"
+	AzureClientID                  = "AZURE_CLIENT_ID"
+	AzureClientSecret              = "AZURE_CLIENT_SECRET"
+	AzureClientCertificatePath     = "AZURE_CLIENT_CERTIFICATE_PATH"
+	AzureClientCertificatePassword = "AZURE_CLIENT_CERTIFICATE_PASSWORD"
 )
 
 // M365 aggregates m365 credentials from flag and env_var values.
 type M365 struct {
 	AzureClientID     string
 	AzureClientSecret string
+	// AzureClientCertificatePath is an optional PEM or PKCS#12 certificate of
+	// the application.  SharePoint's REST API only accepts app-only tokens
+	// issued for a certificate.
+	AzureClientCertificatePath     string
+	AzureClientCertificatePassword string
 }
 
 // M365 is a helper for aggregating m365 secrets and credentials.
@@ -26,6 +33,9 @@ func GetM365() M365 {
 	return M365{
 		AzureClientID:     os.Getenv(AzureClientID),
 		AzureClientSecret: os.Getenv(AzureClientSecret),
+
+		AzureClientCertificatePath:     os.Getenv(AzureClientCertificatePath),
+		AzureClientCertificatePassword: os.Getenv(AzureClientCertificatePassword),
 	}
 }
 
@@ -0,0 +1,179 @@
+package export
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveFormat identifies the encoding of an archive export.
+type ArchiveFormat string
+
+const (
+	ArchiveZip     ArchiveFormat = "zip"
+	ArchiveTarZstd ArchiveFormat = "tar.zst"
+)
+
+// ArchiveFormats lists all supported archive formats.
+var ArchiveFormats = []ArchiveFormat{ArchiveZip, ArchiveTarZstd}
+
+var _ Writer = &ArchiveWriter{}
+
+// ArchiveWriter streams exported items into a single zip or zstd
+// compressed tar archive.  Entry names are the export names of the
+// items, so an archive's contents are determined solely by the
+// items exported into it.
+type ArchiveWriter struct {
+	format ArchiveFormat
+	dst    io.WriteCloser
+
+	zw *zip.Writer
+
+	tw *tar.Writer
+	zs *zstd.Encoder
+}
+
+// NewArchiveWriter produces a writer that encodes all exported items
+// into the destination.  The destination is closed along with the
+// writer.
+func NewArchiveWriter(dst io.WriteCloser, format ArchiveFormat) (*ArchiveWriter, error) {
+	aw := &ArchiveWriter{format: format, dst: dst}
+
+	switch format {
+	case ArchiveZip:
+		aw.zw = zip.NewWriter(dst)
+
+	case ArchiveTarZstd:
+		zs, err := zstd.NewWriter(dst)
+		if err != nil {
+			return nil, clues.Wrap(err, "creating zstd encoder")
+		}
+
+		aw.zs = zs
+		aw.tw = tar.NewWriter(zs)
+
+	default:
+		return nil, clues.New("unsupported archive format").With("archive_format", format)
+	}
+
+	return aw, nil
+}
+
+func (w *ArchiveWriter) Write(ctx context.Context, name string, r io.Reader) error {
+	name, err := archiveName(name)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	ctx = clues.Add(ctx, "archive_format", w.format)
+
+	if w.zw != nil {
+		return w.writeZip(ctx, name, r)
+	}
+
+	return w.writeTar(ctx, name, r)
+}
+
+func (w *ArchiveWriter) writeZip(ctx context.Context, name string, r io.Reader) error {
+	ew, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	})
+	if err != nil {
+		return clues.Wrap(err, "creating archive entry").WithClues(ctx)
+	}
+
+	if _, err := io.Copy(ew, r); err != nil {
+		return clues.Wrap(err, "writing archive entry").WithClues(ctx)
+	}
+
+	return nil
+}
+
+// writeTar streams the item into a tar entry.  Tar headers require the
+// entry size ahead of its contents, so readers that don't implement Sizer
+// are buffered in memory first.
+func (w *ArchiveWriter) writeTar(ctx context.Context, name string, r io.Reader) error {
+	var size int64
+
+	if sz, ok := r.(Sizer); ok {
+		size = sz.Size()
+	} else {
+		buf := &bytes.Buffer{}
+
+		if _, err := io.Copy(buf, r); err != nil {
+			return clues.Wrap(err, "buffering archive entry").WithClues(ctx)
+		}
+
+		size = int64(buf.Len())
+		r = buf
+	}
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     size,
+		Format:   tar.FormatPAX,
+	}
+
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return clues.Wrap(err, "creating archive entry").WithClues(ctx)
+	}
+
+	n, err := io.Copy(w.tw, r)
+	if err != nil {
+		return clues.Wrap(err, "writing archive entry").WithClues(ctx)
+	}
+
+	if n != size {
+		return clues.New("archive entry is shorter than its size").
+			WithClues(ctx).
+			With("entry_size", size, "written_size", n)
+	}
+
+	return nil
+}
+
+// Close finalizes the archive and closes the destination.
+func (w *ArchiveWriter) Close() error {
+	var err error
+
+	switch {
+	case w.zw != nil:
+		err = w.zw.Close()
+	case w.tw != nil:
+		err = w.tw.Close()
+		if zerr := w.zs.Close(); err == nil {
+			err = zerr
+		}
+	}
+
+	if derr := w.dst.Close(); err == nil {
+		err = derr
+	}
+
+	if err != nil {
+		return clues.Wrap(err, "closing archive")
+	}
+
+	return nil
+}
+
+// archiveName normalizes the export name into a relative, slash-separated
+// archive entry name.
+func archiveName(name string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if len(cleaned) == 0 {
+		return "", clues.New("empty export name")
+	}
+
+	return cleaned, nil
+}
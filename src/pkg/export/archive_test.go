@@ -0,0 +1,196 @@
+package export
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type closableBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closableBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+type ArchiveUnitSuite struct {
+	tester.Suite
+}
+
+func TestArchiveUnitSuite(t *testing.T) {
+	suite.Run(t, &ArchiveUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+var archiveTestItems = []struct {
+	name, archiveName, content string
+}{
+	{"email/Inbox/a.json", "email/Inbox/a.json", `{"a":1}`},
+	{"/OneDrive/Docs/plan.docx", "OneDrive/Docs/plan.docx", "plan"},
+	{"../escape.txt", "escape.txt", "esc"},
+}
+
+func writeTestArchive(t *testing.T, format ArchiveFormat) *closableBuffer {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	buf := &closableBuffer{}
+
+	w, err := NewArchiveWriter(buf, format)
+	require.NoError(t, err)
+
+	for _, item := range archiveTestItems {
+		require.NoError(t, w.Write(ctx, item.name, strings.NewReader(item.content)))
+	}
+
+	assert.Error(t, w.Write(ctx, "", strings.NewReader("empty")), "empty name")
+	require.NoError(t, w.Close())
+	assert.True(t, buf.closed, "destination closed")
+
+	return buf
+}
+
+func (suite *ArchiveUnitSuite) TestArchiveWriter_zip() {
+	t := suite.T()
+	buf := writeTestArchive(t, ArchiveZip)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, len(archiveTestItems))
+
+	for i, f := range zr.File {
+		assert.Equal(t, archiveTestItems[i].archiveName, f.Name)
+
+		rc, err := f.Open()
+		require.NoError(t, err)
+
+		bs, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+
+		assert.Equal(t, archiveTestItems[i].content, string(bs))
+	}
+}
+
+func (suite *ArchiveUnitSuite) TestArchiveWriter_tarZstd() {
+	t := suite.T()
+	buf := writeTestArchive(t, ArchiveTarZstd)
+
+	zr, err := zstd.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+
+	for _, item := range archiveTestItems {
+		hdr, err := tr.Next()
+		require.NoError(t, err)
+		assert.Equal(t, item.archiveName, hdr.Name)
+
+		bs, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		assert.Equal(t, item.content, string(bs))
+	}
+
+	_, err = tr.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func (suite *ArchiveUnitSuite) TestArchiveWriter_tarEntrySize() {
+	const content = "streamed"
+
+	// readers without a Size() are hidden behind a MultiReader.
+	table := []struct {
+		name      string
+		reader    io.Reader
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "sized",
+			reader:    SizedReader(io.MultiReader(strings.NewReader(content)), int64(len(content))),
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "unsized",
+			reader:    io.MultiReader(strings.NewReader(content)),
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "shorter than its size",
+			reader:    SizedReader(io.MultiReader(strings.NewReader(content)), int64(len(content)+1)),
+			expectErr: assert.Error,
+		},
+		{
+			name:      "longer than its size",
+			reader:    SizedReader(io.MultiReader(strings.NewReader(content)), int64(len(content)-1)),
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			buf := &closableBuffer{}
+
+			w, err := NewArchiveWriter(buf, ArchiveTarZstd)
+			require.NoError(t, err)
+
+			err = w.Write(ctx, "item.txt", test.reader)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			require.NoError(t, w.Close())
+
+			zr, err := zstd.NewReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+
+			defer zr.Close()
+
+			tr := tar.NewReader(zr)
+
+			_, err = tr.Next()
+			require.NoError(t, err)
+
+			bs, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bs))
+		})
+	}
+}
+
+func (suite *ArchiveUnitSuite) TestArchiveWriter_deterministic() {
+	for _, format := range ArchiveFormats {
+		suite.Run(string(format), func() {
+			t := suite.T()
+
+			a := writeTestArchive(t, format)
+			b := writeTestArchive(t, format)
+
+			assert.Equal(t, a.Bytes(), b.Bytes())
+		})
+	}
+}
+
+func (suite *ArchiveUnitSuite) TestNewArchiveWriter_unknownFormat() {
+	_, err := NewArchiveWriter(&closableBuffer{}, "rar")
+	assert.Error(suite.T(), err)
+}
@@ -0,0 +1,400 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+)
+
+// bundle entry names
+const (
+	BundleContentDir   = "content"
+	BundleBackupName   = "backup.json"
+	BundleDetailsName  = "details.json"
+	BundleManifestName = "manifest.json"
+	BundleSumsName     = "SHA256SUMS"
+)
+
+// bundleVersion identifies the layout of the bundle.  Increment it
+// whenever the layout changes in a way that verification must handle.
+const bundleVersion = 1
+
+// BundleFile records the size and sha256 hash of a single bundle entry.
+type BundleFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BundleManifest describes the complete contents of a bundle.  Every
+// entry in the bundle, other than the manifest itself and the sums
+// file, is listed in the manifest.
+type BundleManifest struct {
+	Version   int          `json:"version"`
+	BackupID  string       `json:"backupID"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Items     int          `json:"items"`
+	Files     []BundleFile `json:"files"`
+}
+
+var _ Writer = &BundleWriter{}
+
+// BundleWriter produces a self-contained zip archive of a backup, which
+// can be verified without access to the repository.  Alongside the
+// exported items, the bundle holds the backup and its details, a
+// manifest with the hash of every entry, and a SHA256SUMS file, so that
+// the extracted bundle can also be checked with standard tools.
+type BundleWriter struct {
+	// ctx is the context the writer was created with, for use in Close,
+	// which receives no context of its own through the Writer interface.
+	ctx         context.Context
+	aw          *ArchiveWriter
+	manifest    BundleManifest
+	fingerprint string
+}
+
+// NewBundleWriter produces a writer that bundles the exported items of
+// the backup into the destination.  The destination is closed along
+// with the writer.
+func NewBundleWriter(
+	ctx context.Context,
+	dst io.WriteCloser,
+	bup *backup.Backup,
+	deets *details.Details,
+) (*BundleWriter, error) {
+	aw, err := NewArchiveWriter(dst, ArchiveZip)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := &BundleWriter{
+		ctx: ctx,
+		aw:  aw,
+		manifest: BundleManifest{
+			Version:   bundleVersion,
+			BackupID:  string(bup.ID),
+			CreatedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := bw.writeJSON(ctx, BundleBackupName, bup); err != nil {
+		return nil, err
+	}
+
+	if err := bw.writeJSON(ctx, BundleDetailsName, deets); err != nil {
+		return nil, err
+	}
+
+	return bw, nil
+}
+
+// Write adds the exported item to the bundle's content.
+func (w *BundleWriter) Write(ctx context.Context, name string, r io.Reader) error {
+	name, err := archiveName(name)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	if err := w.write(ctx, BundleContentDir+"/"+name, r); err != nil {
+		return err
+	}
+
+	w.manifest.Items++
+
+	return nil
+}
+
+// Close writes the manifest and sums into the bundle, then finalizes
+// the archive and closes the destination.
+func (w *BundleWriter) Close() error {
+	ctx := w.ctx
+
+	mbs, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return clues.Wrap(err, "marshalling bundle manifest").WithClues(ctx)
+	}
+
+	if err := w.aw.Write(ctx, BundleManifestName, bytes.NewReader(mbs)); err != nil {
+		return clues.Wrap(err, "writing bundle manifest").WithClues(ctx)
+	}
+
+	w.fingerprint = sum(mbs)
+
+	sums := sumsFile(w.manifest.Files, w.fingerprint)
+	if err := w.aw.Write(ctx, BundleSumsName, strings.NewReader(sums)); err != nil {
+		return clues.Wrap(err, "writing bundle sums").WithClues(ctx)
+	}
+
+	return w.aw.Close()
+}
+
+// Fingerprint produces the sha256 hash of the manifest.  Since the
+// manifest holds the hash of every other entry, the fingerprint
+// identifies the complete contents of the bundle.  Empty until the
+// writer is closed.
+func (w *BundleWriter) Fingerprint() string {
+	return w.fingerprint
+}
+
+func (w *BundleWriter) writeJSON(ctx context.Context, name string, v any) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return clues.Wrap(err, "marshalling bundle entry").WithClues(ctx).With("bundle_entry", name)
+	}
+
+	return w.write(ctx, name, bytes.NewReader(bs))
+}
+
+// write adds the entry to the archive, recording its size and hash
+// in the manifest.
+func (w *BundleWriter) write(ctx context.Context, name string, r io.Reader) error {
+	var (
+		hr = &hashingReader{r: r, h: sha256.New()}
+		ar = io.Reader(hr)
+	)
+
+	if sz, ok := r.(Sizer); ok {
+		ar = SizedReader(hr, sz.Size())
+	}
+
+	if err := w.aw.Write(ctx, name, ar); err != nil {
+		return err
+	}
+
+	w.manifest.Files = append(w.manifest.Files, BundleFile{
+		Name:   name,
+		Size:   hr.n,
+		SHA256: hex.EncodeToString(hr.h.Sum(nil)),
+	})
+
+	return nil
+}
+
+type hashingReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+func (hr *hashingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	hr.n += int64(n)
+
+	return n, err
+}
+
+// ---------------------------------------------------------------------------
+// verification
+// ---------------------------------------------------------------------------
+
+// BundleVerification is the outcome of verifying a bundle.  A bundle is
+// intact only if no problems were found.
+type BundleVerification struct {
+	Manifest    BundleManifest `json:"manifest"`
+	Fingerprint string         `json:"fingerprint"`
+	Problems    []string       `json:"problems,omitempty"`
+}
+
+// Intact is true if verification found no problems with the bundle.
+func (bv BundleVerification) Intact() bool {
+	return len(bv.Problems) == 0
+}
+
+// VerifyBundle checks every entry in the bundle against the bundle's
+// manifest.  Entries that are missing, unexpected, or whose contents
+// don't match the manifest are reported as problems.  An error is only
+// returned if the bundle can't be read at all.
+func VerifyBundle(r io.ReaderAt, size int64) (BundleVerification, error) {
+	bv := BundleVerification{}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return bv, clues.Wrap(err, "reading bundle archive")
+	}
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	mf, ok := entries[BundleManifestName]
+	if !ok {
+		return bv, clues.New("bundle has no manifest")
+	}
+
+	mbs, err := readEntry(mf)
+	if err != nil {
+		return bv, clues.Wrap(err, "reading bundle manifest")
+	}
+
+	if err := json.Unmarshal(mbs, &bv.Manifest); err != nil {
+		return bv, clues.Wrap(err, "decoding bundle manifest")
+	}
+
+	if bv.Manifest.Version > bundleVersion {
+		return bv, clues.New("bundle version is newer than this version of corso supports").
+			With("bundle_version", bv.Manifest.Version)
+	}
+
+	bv.Fingerprint = sum(mbs)
+	bv.Problems = append(bv.Problems, verifyEntries(bv.Manifest, entries)...)
+	bv.Problems = append(bv.Problems, verifySums(bv.Manifest, bv.Fingerprint, entries)...)
+	bv.Problems = append(bv.Problems, verifyBackup(bv.Manifest, entries)...)
+
+	return bv, nil
+}
+
+// verifyEntries compares each archive entry with its manifest record.
+func verifyEntries(m BundleManifest, entries map[string]*zip.File) []string {
+	var (
+		problems []string
+		listed   = map[string]struct{}{
+			BundleManifestName: {},
+			BundleSumsName:     {},
+		}
+		items int
+	)
+
+	for _, bf := range m.Files {
+		listed[bf.Name] = struct{}{}
+
+		if strings.HasPrefix(bf.Name, BundleContentDir+"/") {
+			items++
+		}
+
+		f, ok := entries[bf.Name]
+		if !ok {
+			problems = append(problems, "missing: "+bf.Name)
+			continue
+		}
+
+		h := sha256.New()
+
+		n, err := copyEntry(h, f)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("unreadable: %s: %v", bf.Name, err))
+			continue
+		}
+
+		if n != bf.Size || hex.EncodeToString(h.Sum(nil)) != bf.SHA256 {
+			problems = append(problems, "modified: "+bf.Name)
+		}
+	}
+
+	for name := range entries {
+		if _, ok := listed[name]; !ok {
+			problems = append(problems, "unexpected: "+name)
+		}
+	}
+
+	if items != m.Items {
+		problems = append(problems, fmt.Sprintf("manifest lists %d items, but records %d", items, m.Items))
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
+
+// verifySums ensures the sums file agrees with the manifest.
+func verifySums(m BundleManifest, fingerprint string, entries map[string]*zip.File) []string {
+	f, ok := entries[BundleSumsName]
+	if !ok {
+		return []string{"missing: " + BundleSumsName}
+	}
+
+	bs, err := readEntry(f)
+	if err != nil {
+		return []string{fmt.Sprintf("unreadable: %s: %v", BundleSumsName, err)}
+	}
+
+	if string(bs) != sumsFile(m.Files, fingerprint) {
+		return []string{BundleSumsName + " does not match the manifest"}
+	}
+
+	return nil
+}
+
+// verifyBackup ensures the bundled backup is the one named in the manifest.
+func verifyBackup(m BundleManifest, entries map[string]*zip.File) []string {
+	f, ok := entries[BundleBackupName]
+	if !ok {
+		// already reported as missing when verifying entries.
+		return nil
+	}
+
+	bs, err := readEntry(f)
+	if err != nil {
+		return nil
+	}
+
+	bup := backup.Backup{}
+	if err := json.Unmarshal(bs, &bup); err != nil {
+		return []string{fmt.Sprintf("undecodable: %s: %v", BundleBackupName, err)}
+	}
+
+	if string(bup.ID) != m.BackupID {
+		return []string{fmt.Sprintf(
+			"%s holds backup %s, but the manifest names %s",
+			BundleBackupName,
+			bup.ID,
+			m.BackupID)}
+	}
+
+	return nil
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if _, err := copyEntry(buf, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func copyEntry(w io.Writer, f *zip.File) (int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(w, bufio.NewReader(rc))
+}
+
+// sumsFile produces the hashes of the files, followed by the hash of the
+// manifest, in the format read by `sha256sum -c`.
+func sumsFile(files []BundleFile, manifestSum string) string {
+	sb := strings.Builder{}
+
+	for _, f := range files {
+		sb.WriteString(f.SHA256 + "  " + f.Name + "\n")
+	}
+
+	sb.WriteString(manifestSum + "  " + BundleManifestName + "\n")
+
+	return sb.String()
+}
+
+func sum(bs []byte) string {
+	s := sha256.Sum256(bs)
+	return hex.EncodeToString(s[:])
+}
@@ -0,0 +1,258 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+)
+
+type BundleUnitSuite struct {
+	tester.Suite
+}
+
+func TestBundleUnitSuite(t *testing.T) {
+	suite.Run(t, &BundleUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func writeTestBundle(t *testing.T) (*closableBuffer, string) {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		buf   = &closableBuffer{}
+		bup   = &backup.Backup{BaseModel: model.BaseModel{ID: "bid"}}
+		deets = &details.Details{}
+	)
+
+	w, err := NewBundleWriter(ctx, buf, bup, deets)
+	require.NoError(t, err)
+
+	for _, item := range archiveTestItems {
+		require.NoError(t, w.Write(ctx, item.name, strings.NewReader(item.content)))
+	}
+
+	require.NoError(t, w.Close())
+	assert.True(t, buf.closed, "destination closed")
+	assert.Len(t, w.Fingerprint(), 64)
+
+	return buf, w.Fingerprint()
+}
+
+// rewriteBundle copies the bundle, passing each entry through fn.  Entries
+// are dropped if fn returns nil.
+func rewriteBundle(t *testing.T, bs []byte, fn func(name string, content []byte) []byte) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	require.NoError(t, err)
+
+	var (
+		buf = &bytes.Buffer{}
+		zw  = zip.NewWriter(buf)
+	)
+
+	for _, f := range zr.File {
+		content, err := readEntry(f)
+		require.NoError(t, err)
+
+		content = fn(f.Name, content)
+		if content == nil {
+			continue
+		}
+
+		ew, err := zw.Create(f.Name)
+		require.NoError(t, err)
+
+		_, err = ew.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func (suite *BundleUnitSuite) TestBundleWriter() {
+	t := suite.T()
+	buf, _ := writeTestBundle(t)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			BundleBackupName,
+			BundleDetailsName,
+			"content/email/Inbox/a.json",
+			"content/OneDrive/Docs/plan.docx",
+			"content/escape.txt",
+			BundleManifestName,
+			BundleSumsName,
+		},
+		names)
+
+	sums, err := readEntry(zr.File[len(zr.File)-1])
+	require.NoError(t, err)
+
+	// every entry other than the sums file is listed in the sums file.
+	lines := strings.Split(strings.TrimSpace(string(sums)), "\n")
+	require.Len(t, lines, len(names)-1)
+
+	for i, l := range lines {
+		assert.True(t, strings.HasSuffix(l, "  "+names[i]), l)
+	}
+}
+
+func (suite *BundleUnitSuite) TestVerifyBundle() {
+	t := suite.T()
+	buf, fingerprint := writeTestBundle(t)
+	bs := buf.Bytes()
+
+	table := []struct {
+		name          string
+		rewrite       func(name string, content []byte) []byte
+		expectProblem string
+	}{
+		{
+			name:    "intact",
+			rewrite: func(_ string, content []byte) []byte { return content },
+		},
+		{
+			name: "modified item",
+			rewrite: func(name string, content []byte) []byte {
+				if name == "content/escape.txt" {
+					return []byte("ESC")
+				}
+
+				return content
+			},
+			expectProblem: "modified: content/escape.txt",
+		},
+		{
+			name: "missing item",
+			rewrite: func(name string, content []byte) []byte {
+				if name == "content/escape.txt" {
+					return nil
+				}
+
+				return content
+			},
+			expectProblem: "missing: content/escape.txt",
+		},
+		{
+			name: "modified details",
+			rewrite: func(name string, content []byte) []byte {
+				if name == BundleDetailsName {
+					return []byte(`{"entries":[]}`)
+				}
+
+				return content
+			},
+			expectProblem: "modified: " + BundleDetailsName,
+		},
+		{
+			name: "modified sums",
+			rewrite: func(name string, content []byte) []byte {
+				if name == BundleSumsName {
+					return append(content, []byte("00  extra\n")...)
+				}
+
+				return content
+			},
+			expectProblem: BundleSumsName + " does not match the manifest",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			rewritten := rewriteBundle(t, bs, test.rewrite)
+
+			bv, err := VerifyBundle(bytes.NewReader(rewritten), int64(len(rewritten)))
+			require.NoError(t, err)
+
+			assert.Equal(t, fingerprint, bv.Fingerprint)
+			assert.Equal(t, "bid", bv.Manifest.BackupID)
+			assert.Equal(t, len(archiveTestItems), bv.Manifest.Items)
+
+			if len(test.expectProblem) == 0 {
+				assert.True(t, bv.Intact(), bv.Problems)
+				return
+			}
+
+			assert.False(t, bv.Intact())
+			assert.Contains(t, bv.Problems, test.expectProblem)
+		})
+	}
+}
+
+func (suite *BundleUnitSuite) TestVerifyBundle_unexpectedEntry() {
+	t := suite.T()
+	buf, _ := writeTestBundle(t)
+	bs := buf.Bytes()
+
+	// append an entry to a copy of the bundle.
+	zr, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	zw := zip.NewWriter(out)
+
+	for _, f := range zr.File {
+		require.NoError(t, zw.Copy(f))
+	}
+
+	ew, err := zw.Create("content/extra.txt")
+	require.NoError(t, err)
+	_, err = io.WriteString(ew, "extra")
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	bv, err := VerifyBundle(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unexpected: content/extra.txt"}, bv.Problems)
+}
+
+func (suite *BundleUnitSuite) TestVerifyBundle_unreadable() {
+	table := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "not an archive",
+			input: []byte("not a zip"),
+		},
+		{
+			name: "no manifest",
+			input: func() []byte {
+				buf := &bytes.Buffer{}
+				zw := zip.NewWriter(buf)
+				_, err := zw.Create("content/a.json")
+				require.NoError(suite.T(), err)
+				require.NoError(suite.T(), zw.Close())
+
+				return buf.Bytes()
+			}(),
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			_, err := VerifyBundle(bytes.NewReader(test.input), int64(len(test.input)))
+			assert.Error(suite.T(), err)
+		})
+	}
+}
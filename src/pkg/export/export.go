@@ -0,0 +1,134 @@
+package export
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/alcionai/clues"
+
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// Writer is the destination of an export.  Each exported item gets
+// written exactly once, under a relative, slash-separated name that is
+// unique within the export.
+type Writer interface {
+	// Write consumes the reader in full, storing its contents under
+	// the provided name.
+	Write(ctx context.Context, name string, r io.Reader) error
+	// Close finalizes the export.  No writes may follow a close.
+	Close() error
+}
+
+// ItemFunc receives each item read out of a backup, along with the
+// item's details entry.  The reader is only valid until the func returns.
+// Readers of items whose length is known implement Sizer.
+type ItemFunc func(ctx context.Context, ent details.DetailsEntry, r io.Reader) error
+
+// Sizer is implemented by readers that know the length of their contents
+// before they're read.
+type Sizer interface {
+	Size() int64
+}
+
+// SizedReader attaches the known length of its contents to the reader.
+func SizedReader(r io.Reader, size int64) io.Reader {
+	return sizedReader{Reader: r, size: size}
+}
+
+type sizedReader struct {
+	io.Reader
+	size int64
+}
+
+func (sr sizedReader) Size() int64 {
+	return sr.size
+}
+
+// Serializer converts the items of a single service from their backup
+// representation into their export representation.
+type Serializer interface {
+	// Name produces the relative export name of the item described
+	// by the details entry.
+	Name(ent details.DetailsEntry) (string, error)
+	// Serialize converts the backed up item data into its export format.
+	Serialize(r io.Reader) (io.Reader, error)
+}
+
+// SerializerFor produces the serializer for items of the provided service.
+func SerializerFor(service path.ServiceType) (Serializer, error) {
+	switch service {
+	case path.ExchangeService:
+		return exchangeSerializer{}, nil
+	case path.OneDriveService, path.SharePointService:
+		return driveSerializer{}, nil
+	default:
+		return nil, clues.New("export not supported for service").With("service", service.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// exchange
+// ---------------------------------------------------------------------------
+
+// exchangeSerializer exports each item as the json document stored
+// in the backup.  Items are grouped by their container's display
+// name location, when known, and by their container IDs otherwise.
+type exchangeSerializer struct{}
+
+func (exchangeSerializer) Name(ent details.DetailsEntry) (string, error) {
+	p, err := path.FromDataLayerPath(ent.RepoRef, true)
+	if err != nil {
+		return "", clues.Wrap(err, "parsing item repoRef")
+	}
+
+	folder := ent.LocationRef
+	if len(folder) == 0 {
+		folder = p.Folder(false)
+	}
+
+	return join(p.Category().String(), folder, p.Item()+".json"), nil
+}
+
+func (exchangeSerializer) Serialize(r io.Reader) (io.Reader, error) {
+	return r, nil
+}
+
+// ---------------------------------------------------------------------------
+// onedrive and sharepoint libraries
+// ---------------------------------------------------------------------------
+
+// driveSerializer exports each file with its original name and
+// contents, within its original folder hierarchy.
+type driveSerializer struct{}
+
+func (driveSerializer) Name(ent details.DetailsEntry) (string, error) {
+	switch {
+	case ent.OneDrive != nil:
+		return join(ent.OneDrive.DriveName, ent.OneDrive.ParentPath, ent.OneDrive.ItemName), nil
+	case ent.SharePoint != nil:
+		return join(ent.SharePoint.DriveName, ent.SharePoint.ParentPath, ent.SharePoint.ItemName), nil
+	default:
+		return "", clues.New("details entry is not a drive item").With("repo_ref", ent.RepoRef)
+	}
+}
+
+func (driveSerializer) Serialize(r io.Reader) (io.Reader, error) {
+	return r, nil
+}
+
+// join combines the non-empty elements into a slash-separated name.
+func join(elems ...string) string {
+	ss := make([]string, 0, len(elems))
+
+	for _, e := range elems {
+		e = strings.Trim(e, "/")
+		if len(e) > 0 {
+			ss = append(ss, e)
+		}
+	}
+
+	return strings.Join(ss, "/")
+}
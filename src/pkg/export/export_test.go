@@ -0,0 +1,143 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type ExportUnitSuite struct {
+	tester.Suite
+}
+
+func TestExportUnitSuite(t *testing.T) {
+	suite.Run(t, &ExportUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ExportUnitSuite) TestSerializerNames() {
+	t := suite.T()
+
+	mailPath, err := path.Builder{}.
+		Append("inboxID").
+		ToDataLayerExchangePathForCategory("tenant", "user", path.EmailCategory, false)
+	require.NoError(t, err)
+
+	mailItem, err := mailPath.Append("mailID", true)
+	require.NoError(t, err)
+
+	table := []struct {
+		name      string
+		service   path.ServiceType
+		ent       details.DetailsEntry
+		expect    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "exchange with location",
+			service: path.ExchangeService,
+			ent: details.DetailsEntry{
+				RepoRef:     mailItem.String(),
+				LocationRef: "Inbox/Important",
+			},
+			expect:    "email/Inbox/Important/mailID.json",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "exchange without location",
+			service:   path.ExchangeService,
+			ent:       details.DetailsEntry{RepoRef: mailItem.String()},
+			expect:    "email/inboxID/mailID.json",
+			expectErr: assert.NoError,
+		},
+		{
+			name:    "onedrive",
+			service: path.OneDriveService,
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				DriveName:  "OneDrive",
+				ParentPath: "Documents/Specs",
+				ItemName:   "plan.docx",
+			}}},
+			expect:    "OneDrive/Documents/Specs/plan.docx",
+			expectErr: assert.NoError,
+		},
+		{
+			name:    "onedrive root",
+			service: path.OneDriveService,
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{OneDrive: &details.OneDriveInfo{
+				DriveName: "OneDrive",
+				ItemName:  "plan.docx",
+			}}},
+			expect:    "OneDrive/plan.docx",
+			expectErr: assert.NoError,
+		},
+		{
+			name:    "sharepoint",
+			service: path.SharePointService,
+			ent: details.DetailsEntry{ItemInfo: details.ItemInfo{SharePoint: &details.SharePointInfo{
+				DriveName:  "Documents",
+				ParentPath: "Specs",
+				ItemName:   "plan.docx",
+			}}},
+			expect:    "Documents/Specs/plan.docx",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "drive without info",
+			service:   path.SharePointService,
+			ent:       details.DetailsEntry{},
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			s, err := SerializerFor(test.service)
+			require.NoError(t, err)
+
+			name, err := s.Name(test.ent)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, name)
+		})
+	}
+}
+
+func (suite *ExportUnitSuite) TestSerializerFor_unknownService() {
+	_, err := SerializerFor(path.UnknownService)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ExportUnitSuite) TestFilesystemWriter() {
+	t := suite.T()
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	root := filepath.Join(t.TempDir(), "export")
+
+	w, err := NewFilesystemWriter(root)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(ctx, "a/b/c.txt", strings.NewReader("abc")))
+	require.NoError(t, w.Write(ctx, "../escape.txt", strings.NewReader("esc")))
+	assert.Error(t, w.Write(ctx, "a/b/c.txt", strings.NewReader("dupe")), "duplicate name")
+	assert.Error(t, w.Write(ctx, "", strings.NewReader("empty")), "empty name")
+	require.NoError(t, w.Close())
+
+	bs, err := os.ReadFile(filepath.Join(root, "a", "b", "c.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(bs))
+
+	bs, err = os.ReadFile(filepath.Join(root, "escape.txt"))
+	require.NoError(t, err, "names are contained by the export root")
+	assert.Equal(t, "esc", string(bs))
+}
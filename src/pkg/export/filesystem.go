@@ -0,0 +1,81 @@
+package export
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alcionai/clues"
+)
+
+var _ Writer = &FilesystemWriter{}
+
+// FilesystemWriter exports each item as a file within a local directory.
+type FilesystemWriter struct {
+	root string
+}
+
+// NewFilesystemWriter produces a writer that exports items into the root
+// directory, creating it if necessary.
+func NewFilesystemWriter(root string) (*FilesystemWriter, error) {
+	if len(root) == 0 {
+		return nil, clues.New("missing export directory")
+	}
+
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, clues.Wrap(err, "creating export directory").With("export_dir", root)
+	}
+
+	return &FilesystemWriter{root: root}, nil
+}
+
+func (w *FilesystemWriter) Write(ctx context.Context, name string, r io.Reader) error {
+	fp, err := localPath(w.root, name)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0o700); err != nil {
+		return clues.Wrap(err, "creating export folder").WithClues(ctx)
+	}
+
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return clues.Wrap(err, "creating export file").WithClues(ctx)
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return clues.Wrap(err, "writing export file").WithClues(ctx)
+	}
+
+	if err := f.Close(); err != nil {
+		return clues.Wrap(err, "closing export file").WithClues(ctx)
+	}
+
+	return nil
+}
+
+func (w *FilesystemWriter) Close() error {
+	return nil
+}
+
+// localPath converts the slash-separated export name into a path within
+// the root directory.  Names that would escape the root are rejected.
+func localPath(root, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash("/" + name))
+	if cleaned == string(filepath.Separator) {
+		return "", clues.New("empty export name")
+	}
+
+	fp := filepath.Join(root, cleaned)
+
+	rel, err := filepath.Rel(root, fp)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", clues.New("export name escapes the export directory").With("export_name", name)
+	}
+
+	return fp, nil
+}
@@ -1,11 +1,33 @@
 package fault
 
 import (
+	"sort"
 	"sync"
 
 	"golang.org/x/exp/slices"
 )
 
+const (
+	// MaxSamples is the number of errors kept for each group of
+	// recoverable errors.  Further errors in the group are counted,
+	// but not kept, so that failure storms (ex: a whole drive is
+	// inaccessible) don't grow the errors without bound.
+	MaxSamples = 10
+
+	// MaxGroups is the number of distinct error messages that get
+	// grouped.  Errors with any other message are aggregated in a
+	// single overflow group.
+	MaxGroups = 1000
+
+	// OverflowMessage identifies the group of errors whose messages
+	// arrived after MaxGroups groups were already tracked.
+	OverflowMessage = "other errors"
+
+	// MaxItems is the number of item failures that get recorded.
+	// Further item failures are only counted within their group.
+	MaxItems = 1000
+)
+
 type Errors struct {
 	mu *sync.Mutex
 
@@ -19,9 +41,21 @@ type Errors struct {
 	// errors.  Eg: if a process is retrieving N items, and
 	// 1 of the items fails to be retrieved, but the rest of
 	// them succeed, we'd expect to see 1 error added to this
-	// slice.
+	// slice.  Only the first MaxSamples errors of each group
+	// are kept.
 	errs []error
 
+	// groups aggregates the recoverable errors by message,
+	// counting every error even when its sample isn't kept.
+	groups map[string]*Group
+
+	// count is the total number of recoverable errors.
+	count int
+
+	// items records the items that failed, or were skipped,
+	// along with the context of their failure.
+	items []Item
+
 	// if failFast is true, the first errs addition will
 	// get promoted to the err value.  This signifies a
 	// non-recoverable processing state, causing any running
@@ -34,14 +68,51 @@ type Errors struct {
 type ErrorsData struct {
 	Err      error   `json:"-"`
 	Errs     []error `json:"-"`
+	Count    int     `json:"count"`
+	Groups   []Group `json:"groups,omitempty"`
+	Items    []Item  `json:"items,omitempty"`
 	FailFast bool    `json:"failFast"`
 }
 
+// Group aggregates the recoverable errors which share a message.
+type Group struct {
+	Message string `json:"message"`
+	// Count is the number of errors in the group, including those
+	// that weren't kept as samples.
+	Count int `json:"count"`
+	// Samples holds up to MaxSamples of the group's errors, in the
+	// order they were added.
+	Samples []error `json:"-"`
+}
+
+// Item records the failure of a single item, so that the failure
+// can be explained after the process completes.
+type Item struct {
+	// ID identifies the item within the service.
+	ID string `json:"id"`
+	// Folder is the folder, or other container, holding the item.
+	Folder string `json:"folder,omitempty"`
+	// Cause is the message of the error that failed the item.
+	Cause string `json:"cause"`
+	// Code is the error code reported by the service, if any.
+	Code string `json:"code,omitempty"`
+	// Retries is the number of times the item was retried before
+	// it failed.
+	Retries int `json:"retries,omitempty"`
+	// FinalRetry is true if the item also failed its final retry,
+	// which is made after all other items are handled.
+	FinalRetry bool `json:"finalRetry,omitempty"`
+	// Skipped items failed in a way that can't be resolved by the
+	// process, so they were left out instead of counted as errors.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
 // New constructs a new error with default values in place.
 func New(failFast bool) *Errors {
 	return &Errors{
 		mu:       &sync.Mutex{},
 		errs:     []error{},
+		groups:   map[string]*Group{},
 		failFast: failFast,
 	}
 }
@@ -49,21 +120,91 @@ func New(failFast bool) *Errors {
 // Err returns the primary error.  If not nil, this
 // indicates the operation exited prior to completion.
 func (e *Errors) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	return e.err
 }
 
 // Errs returns the slice of recoverable and
-// iterated errors.
+// iterated errors.  Holds at most MaxSamples
+// errors for each group.
 func (e *Errors) Errs() []error {
-	return e.errs
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.errs)
+}
+
+// Count returns the total number of recoverable and
+// iterated errors, including those beyond the samples
+// kept in Errs().
+func (e *Errors) Count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.count
+}
+
+// Items returns the recorded item failures.
+func (e *Errors) Items() []Item {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.items)
+}
+
+// Groups returns the recoverable errors grouped by
+// message, most frequent first.
+func (e *Errors) Groups() []Group {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.sortedGroups()
+}
+
+// sortedGroups copies the groups, most frequent first.  Callers
+// must hold e.mu.
+func (e *Errors) sortedGroups() []Group {
+	gs := make([]Group, 0, len(e.groups))
+
+	for _, g := range e.groups {
+		gs = append(gs, Group{
+			Message: g.Message,
+			Count:   g.Count,
+			Samples: slices.Clone(g.Samples),
+		})
+	}
+
+	sort.Slice(gs, func(i, j int) bool {
+		if gs[i].Count != gs[j].Count {
+			return gs[i].Count > gs[j].Count
+		}
+
+		return gs[i].Message < gs[j].Message
+	})
+
+	return gs
+}
+
+// FailFast returns true if the first recoverable error
+// fails the process.
+func (e *Errors) FailFast() bool {
+	return e.failFast
 }
 
 // Data returns the plain set of error data
 // without any sync properties.
 func (e *Errors) Data() ErrorsData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	return ErrorsData{
 		Err:      e.err,
 		Errs:     slices.Clone(e.errs),
+		Count:    e.count,
+		Groups:   e.sortedGroups(),
+		Items:    slices.Clone(e.items),
 		FailFast: e.failFast,
 	}
 }
@@ -92,7 +233,7 @@ func (e *Errors) setErr(err error) *Errors {
 		return e
 	}
 
-	e.errs = append(e.errs, err)
+	e.group(err)
 
 	return e
 }
@@ -120,7 +261,50 @@ func (e *Errors) addErr(err error) *Errors {
 		e.setErr(err)
 	}
 
-	e.errs = append(e.errs, err)
+	e.group(err)
+
+	return e
+}
+
+// group counts the error within the group matching its
+// message, and keeps it in errors.errs if the group still
+// has room for samples.  Sync locking gets handled upstream
+// of this call.
+func (e *Errors) group(err error) {
+	msg := err.Error()
+
+	g, ok := e.groups[msg]
+	if !ok {
+		if len(e.groups) >= MaxGroups {
+			msg = OverflowMessage
+			g = e.groups[msg]
+		}
+
+		if g == nil {
+			g = &Group{Message: msg}
+			e.groups[msg] = g
+		}
+	}
+
+	e.count++
+	g.Count++
+
+	if len(g.Samples) < MaxSamples {
+		g.Samples = append(g.Samples, err)
+		e.errs = append(e.errs, err)
+	}
+}
+
+// AddItem records the failure of an item.  Only the first
+// MaxItems failures are recorded.  Recording an item failure
+// doesn't add an error; callers still Add the item's error.
+func (e *Errors) AddItem(it Item) *Errors {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.items) < MaxItems {
+		e.items = append(e.items, it)
+	}
 
 	return e
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -177,6 +178,66 @@ func (suite *FaultErrorsUnitSuite) TestAdd() {
 	assert.Len(t, n.Errs(), 2)
 }
 
+func (suite *FaultErrorsUnitSuite) TestAdd_grouped() {
+	t := suite.T()
+
+	n := fault.New(false)
+	require.NotNil(t, n)
+
+	storm := errors.New("drive inaccessible")
+
+	for i := 0; i < fault.MaxSamples*3; i++ {
+		n.Add(storm)
+	}
+
+	n.Add(errors.New("1"))
+
+	assert.Equal(t, fault.MaxSamples*3+1, n.Count())
+	assert.Len(t, n.Errs(), fault.MaxSamples+1, "only samples are kept")
+
+	gs := n.Groups()
+	require.Len(t, gs, 2)
+	assert.Equal(t, "drive inaccessible", gs[0].Message, "most frequent first")
+	assert.Equal(t, fault.MaxSamples*3, gs[0].Count)
+	assert.Len(t, gs[0].Samples, fault.MaxSamples)
+	assert.Equal(t, "1", gs[1].Message)
+	assert.Equal(t, 1, gs[1].Count)
+}
+
+func (suite *FaultErrorsUnitSuite) TestAdd_overflow() {
+	t := suite.T()
+
+	n := fault.New(false)
+	require.NotNil(t, n)
+
+	for i := 0; i < fault.MaxGroups+5; i++ {
+		n.Add(fmt.Errorf("error %d", i))
+	}
+
+	assert.Equal(t, fault.MaxGroups+5, n.Count())
+
+	gs := n.Groups()
+	assert.Len(t, gs, fault.MaxGroups+1)
+	assert.Equal(t, fault.OverflowMessage, gs[0].Message)
+	assert.Equal(t, 5, gs[0].Count)
+}
+
+func (suite *FaultErrorsUnitSuite) TestAddItem() {
+	t := suite.T()
+
+	n := fault.New(false)
+	require.NotNil(t, n)
+
+	for i := 0; i < fault.MaxItems+1; i++ {
+		n.AddItem(fault.Item{ID: fmt.Sprintf("item-%d", i), Cause: "fail"})
+	}
+
+	assert.Len(t, n.Items(), fault.MaxItems, "failures past the limit aren't recorded")
+	assert.Equal(t, "item-0", n.Items()[0].ID)
+	assert.Empty(t, n.Errs(), "items don't add errors")
+	assert.Equal(t, n.Items(), n.Data().Items)
+}
+
 func (suite *FaultErrorsUnitSuite) TestData() {
 	t := suite.T()
 
@@ -191,6 +252,8 @@ func (suite *FaultErrorsUnitSuite) TestData() {
 	d := n.Data()
 	assert.Equal(t, n.Err(), d.Err)
 	assert.ElementsMatch(t, n.Errs(), d.Errs)
+	assert.Equal(t, 2, d.Count)
+	assert.Equal(t, n.Groups(), d.Groups)
 	assert.False(t, d.FailFast)
 
 	// fail-fast
@@ -207,6 +270,39 @@ func (suite *FaultErrorsUnitSuite) TestData() {
 	assert.True(t, d.FailFast)
 }
 
+func (suite *FaultErrorsUnitSuite) TestConcurrentReads() {
+	var (
+		t  = suite.T()
+		n  = fault.New(false)
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			n.Add(fmt.Errorf("err %d", i%3))
+			n.AddItem(fault.Item{ID: fmt.Sprintf("item-%d", i), Cause: "fail"})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			_ = n.Count()
+			_ = n.Groups()
+			_ = n.Items()
+			_ = n.Data()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, n.Count())
+	assert.Len(t, n.Items(), 10)
+}
+
 func (suite *FaultErrorsUnitSuite) TestMarshalUnmarshal() {
 	t := suite.T()
 
@@ -1,7 +1,9 @@
 package filters
 
 import (
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/alcionai/corso/src/pkg/path"
 )
@@ -38,6 +40,10 @@ const (
 	TargetPathSuffix
 	// "foo/bar/baz" equals the complete path "foo/bar/baz"
 	TargetPathEquals
+	// "fo+-[0-9]" entirely matches "foo-1"
+	TargetPattern
+	// "Foo" is found in ["Foo", "bar"], respecting case
+	TargetExactIn
 )
 
 func norm(s string) string {
@@ -179,6 +185,21 @@ func NotSuffix(target string) Filter {
 	return newFilter(TargetSuffixes, target, true)
 }
 
+// Pattern creates a filter where Compare(v) is true if the regular
+// expression target matches the entirety of v.  Comparisons are case
+// insensitive, so the target should be written in lower case.  Invalid
+// patterns never match.
+func Pattern(target string) Filter {
+	return newFilter(TargetPattern, target, false)
+}
+
+// ExactIn creates a filter where Compare(v) is true if v equals any of
+// the targets.  Unlike all other comparisons, which ignore case, values
+// must match exactly, for values such as IDs where case is significant.
+func ExactIn(targets []string) Filter {
+	return newSliceFilter(TargetExactIn, targets, targets, false)
+}
+
 // PathPrefix creates a filter where Compare(v) is true if
 // target.Prefix(v) &&
 // split(target)[i].Equals(split(v)[i]) for _all_ i in 0..len(target)-1
@@ -381,6 +402,7 @@ func (f Filter) Compare(input string) bool {
 	var (
 		cmp      func(string, string) bool
 		hasSlice bool
+		exact    bool
 	)
 
 	switch f.Comparator {
@@ -398,6 +420,12 @@ func (f Filter) Compare(input string) bool {
 		cmp = prefixed
 	case TargetSuffixes:
 		cmp = suffixed
+	case TargetPattern:
+		cmp = patterned
+	case TargetExactIn:
+		cmp = equals
+		hasSlice = true
+		exact = true
 	case TargetPathPrefix:
 		cmp = pathPrefix
 		hasSlice = true
@@ -422,7 +450,12 @@ func (f Filter) Compare(input string) bool {
 	}
 
 	for _, tgt := range targets {
-		success := cmp(norm(tgt), norm(input))
+		t, i := tgt, input
+		if !exact {
+			t, i = norm(tgt), norm(input)
+		}
+
+		success := cmp(t, i)
 		if f.Negate {
 			success = !success
 		}
@@ -471,6 +504,27 @@ func suffixed(target, input string) bool {
 	return strings.HasSuffix(input, target)
 }
 
+// compiled patterns, by their source, since the same filter gets
+// compared against every item in a backup or restore.
+var patterns sync.Map
+
+// true if target, as a regular expression, matches the entire input.
+func patterned(target, input string) bool {
+	re, ok := patterns.Load(target)
+	if !ok {
+		c, err := regexp.Compile("^(?:" + target + ")$")
+		if err != nil {
+			c = nil
+		}
+
+		re, _ = patterns.LoadOrStore(target, c)
+	}
+
+	c := re.(*regexp.Regexp)
+
+	return c != nil && c.MatchString(input)
+}
+
 // true if target is an _element complete_ prefix match
 // on the input.  Element complete means we do not
 // succeed on partial element matches (ex: "/foo" does
@@ -541,6 +595,8 @@ var prefixString = map[comparator]string{
 	TargetIn:           "in:",
 	TargetPrefixes:     "pfx:",
 	TargetSuffixes:     "sfx:",
+	TargetPattern:      "re:",
+	TargetExactIn:      "exactIn:",
 	TargetPathPrefix:   "pathPfx:",
 	TargetPathContains: "pathCont:",
 	TargetPathSuffix:   "pathSfx:",
@@ -231,6 +231,50 @@ func (suite *FiltersSuite) TestSuffixes() {
 	}
 }
 
+func (suite *FiltersSuite) TestPattern() {
+	f := filters.Pattern(`fo+-[0-9]`)
+
+	table := []struct {
+		name   string
+		input  string
+		expect assert.BoolAssertionFunc
+	}{
+		{"Entire match", "foo-1", assert.True},
+		{"Entire match - different case", "FOOO-2", assert.True},
+		{"Partial match", "foo-12", assert.False},
+		{"Contained match", "a-foo-1", assert.False},
+		{"No match", "bar-1", assert.False},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			test.expect(t, f.Compare(test.input))
+		})
+	}
+
+	assert.False(suite.T(), filters.Pattern(`(`).Compare("("), "invalid pattern")
+}
+
+func (suite *FiltersSuite) TestExactIn() {
+	f := filters.ExactIn([]string{"AAMkAG=", "bar"})
+
+	table := []struct {
+		name   string
+		input  string
+		expect assert.BoolAssertionFunc
+	}{
+		{"Exact match", "AAMkAG=", assert.True},
+		{"Exact match - second target", "bar", assert.True},
+		{"Different case", "AAMKAG=", assert.False},
+		{"Substring", "AAMk", assert.False},
+		{"No match", "baz", assert.False},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			test.expect(t, f.Compare(test.input))
+		})
+	}
+}
+
 func (suite *FiltersSuite) TestPathPrefix() {
 	table := []struct {
 		name     string
@@ -121,6 +121,16 @@ func Example_logger_clues_standards() {
 	// avoid
 	log.Errorw("getting item", "err", err)
 
-	// TODO(keepers): PII
-	// 3. Protect pii in logs.
+	// 3. Protect pii in logs.  Wrap user identifiers, item names, and urls
+	// in logger.PII, which renders them according to the --pii-handling
+	// policy (plaintext, hash, or mask) wherever they get logged.
+	//
+	// preferred
+	ctx = clues.Add(ctx, "user", logger.PII("user@example.com"))
+	log.With("item_name", logger.PII("plan.docx")).Info("getting item")
+	// avoid
+	ctx = clues.Add(ctx, "user", "user@example.com")
+	log.With("item_name", "plan.docx").Info("getting item")
+
+	logger.Ctx(ctx).Info("got item")
 }
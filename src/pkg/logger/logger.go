@@ -67,6 +67,11 @@ func AddLoggingFlags(cmd *cobra.Command) {
 
 	fs.Bool(debugAPIFN, false, "add non-2xx request/response errors to logging")
 
+	fs.StringVar(
+		&piiFlag, piiHandlingFN, string(PIIPlainText),
+		"how to render user identifiers, item names, and urls in logs: plaintext|hash|mask; "+
+			"overrides the repository's pii handling")
+
 	fs.Bool(
 		readableLogsFN, false,
 		"minimizes log output for console readability: removes the file and date, colors the level")
@@ -87,6 +92,7 @@ func PreloadLoggingFlags() (string, string) {
 	fs.String(logFileFN, dlf, "location for writing logs")
 	fs.BoolVar(&DebugAPI, debugAPIFN, false, "add non-2xx request/response errors to logging")
 	fs.BoolVar(&readableOutput, readableLogsFN, false, "minimizes log output: removes the file and date, colors the level")
+	fs.String(piiHandlingFN, string(PIIPlainText), "how to render pii in logs")
 	// prevents overriding the corso/cobra help processor
 	fs.BoolP("help", "h", false, "")
 
@@ -102,6 +108,18 @@ func PreloadLoggingFlags() (string, string) {
 		return "info", dlf
 	}
 
+	// retrieve the user's preferred pii handling
+	// automatically defaults to plaintext.  Unsupported values are
+	// reported when the command runs.
+	piiHandling, err := fs.GetString(piiHandlingFN)
+	if err != nil {
+		return "info", dlf
+	}
+
+	if err := SetPIIHandling(PIIHandling(piiHandling)); err == nil {
+		piiFlagged = fs.Changed(piiHandlingFN)
+	}
+
 	// retrieve the user's preferred log file location
 	// automatically defaults to default log location
 	logfile, err := fs.GetString(logFileFN)
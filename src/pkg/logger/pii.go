@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync/atomic"
+
+	"github.com/alcionai/clues"
+)
+
+// PIIHandling describes how personally identifiable information, such as
+// user emails, item names, and urls, gets rendered in logs and in the
+// clues annotations of errors.
+type PIIHandling string
+
+const (
+	// PIIPlainText leaves pii values unmodified.
+	PIIPlainText PIIHandling = "plaintext"
+	// PIIHash replaces pii values with a truncated sha256 hash.  Equal
+	// values produce equal hashes, so logs remain correlatable.
+	PIIHash PIIHandling = "hash"
+	// PIIMask replaces all pii values with a fixed mask.
+	PIIMask PIIHandling = "mask"
+)
+
+const (
+	piiHandlingFN = "pii-handling"
+
+	// maskedPII replaces pii values under the PIIMask policy.
+	maskedPII = "***"
+	// hashedPIILen is the number of hex characters kept from a pii hash.
+	hashedPIILen = 16
+)
+
+// the pii handling flag value, and whether the flag was provided.
+var (
+	piiFlag    = string(PIIPlainText)
+	piiFlagged bool
+)
+
+// policy is stored atomically, since logging happens concurrently
+// throughout the backup and restore processes.
+var piiPolicy atomic.Value
+
+func init() {
+	piiPolicy.Store(PIIPlainText)
+}
+
+// PIIHandlings lists all supported pii handling policies.
+var PIIHandlings = []PIIHandling{PIIPlainText, PIIHash, PIIMask}
+
+func (h PIIHandling) valid() bool {
+	for _, ph := range PIIHandlings {
+		if h == ph {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePIIHandling returns an error if h isn't a supported policy.
+func ValidatePIIHandling(h PIIHandling) error {
+	if !h.valid() {
+		return clues.New("unsupported pii handling: "+string(h)).
+			With("supported_pii_handling", PIIHandlings)
+	}
+
+	return nil
+}
+
+// ValidatePIIHandlingFlag returns an error if the --pii-handling flag
+// isn't a supported policy.
+func ValidatePIIHandlingFlag() error {
+	return ValidatePIIHandling(PIIHandling(piiFlag))
+}
+
+// SetPIIHandling sets the policy used to render pii values.  Unrecognized
+// policies produce an error, and retain the current policy.  Defaults to
+// PIIPlainText.
+func SetPIIHandling(h PIIHandling) error {
+	if err := ValidatePIIHandling(h); err != nil {
+		return err
+	}
+
+	piiPolicy.Store(h)
+
+	return nil
+}
+
+// SetRepoPIIHandling sets the policy stored in a repository.  The
+// --pii-handling flag takes precedence over the repository's policy, so
+// the policy is left unchanged if the flag was provided, or if the
+// repository doesn't store a policy.
+func SetRepoPIIHandling(h PIIHandling) error {
+	if piiFlagged || len(h) == 0 {
+		return nil
+	}
+
+	return SetPIIHandling(h)
+}
+
+// GetPIIHandling returns the current pii handling policy.
+func GetPIIHandling() PIIHandling {
+	return piiPolicy.Load().(PIIHandling)
+}
+
+// Conceal renders the value according to the current pii handling policy.
+func Conceal(s string) string {
+	switch GetPIIHandling() {
+	case PIIPlainText:
+		return s
+	case PIIMask:
+		return maskedPII
+	default:
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])[:hashedPIILen]
+	}
+}
+
+// PII marks a value, such as a user email, an item name, or a url, as
+// personally identifiable.  PII values are rendered through Conceal
+// wherever they get logged, including when added to ctx or error clues.
+//
+// ex: clues.Add(ctx, "user", logger.PII(userID))
+type PII string
+
+// String produces the concealed value.
+func (p PII) String() string {
+	return Conceal(string(p))
+}
@@ -0,0 +1,110 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/logger"
+)
+
+type PIIUnitSuite struct {
+	tester.Suite
+}
+
+func TestPIIUnitSuite(t *testing.T) {
+	suite.Run(t, &PIIUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *PIIUnitSuite) TestConceal() {
+	const email = "user@example.com"
+
+	table := []struct {
+		name     string
+		handling logger.PIIHandling
+		expect   func(t *testing.T, result string)
+	}{
+		{
+			name:     "plaintext",
+			handling: logger.PIIPlainText,
+			expect: func(t *testing.T, result string) {
+				assert.Equal(t, email, result)
+			},
+		},
+		{
+			name:     "hash",
+			handling: logger.PIIHash,
+			expect: func(t *testing.T, result string) {
+				assert.NotContains(t, result, "user")
+				assert.Len(t, result, 16)
+				assert.Equal(t, result, logger.Conceal(email), "hashes are stable")
+				assert.NotEqual(t, result, logger.Conceal("other@example.com"))
+			},
+		},
+		{
+			name:     "mask",
+			handling: logger.PIIMask,
+			expect: func(t *testing.T, result string) {
+				assert.Equal(t, "***", result)
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			orig := logger.GetPIIHandling()
+			defer logger.SetPIIHandling(orig)
+
+			require.NoError(t, logger.SetPIIHandling(test.handling))
+			assert.Equal(t, test.handling, logger.GetPIIHandling())
+
+			test.expect(t, logger.Conceal(email))
+			test.expect(t, logger.PII(email).String())
+		})
+	}
+}
+
+func (suite *PIIUnitSuite) TestSetPIIHandling_unknown() {
+	t := suite.T()
+
+	orig := logger.GetPIIHandling()
+	defer logger.SetPIIHandling(orig)
+
+	require.NoError(t, logger.SetPIIHandling(logger.PIIMask))
+	assert.Error(t, logger.SetPIIHandling("rot13"))
+	assert.Equal(t, logger.PIIMask, logger.GetPIIHandling())
+}
+
+func (suite *PIIUnitSuite) TestValidatePIIHandling() {
+	t := suite.T()
+
+	for _, h := range logger.PIIHandlings {
+		assert.NoError(t, logger.ValidatePIIHandling(h), h)
+	}
+
+	assert.Error(t, logger.ValidatePIIHandling(""))
+	assert.Error(t, logger.ValidatePIIHandling("Hash"))
+	assert.NoError(t, logger.ValidatePIIHandlingFlag(), "defaults to a supported handling")
+}
+
+func (suite *PIIUnitSuite) TestSetRepoPIIHandling() {
+	t := suite.T()
+
+	orig := logger.GetPIIHandling()
+	defer logger.SetPIIHandling(orig)
+
+	require.NoError(t, logger.SetPIIHandling(logger.PIIPlainText))
+
+	require.NoError(t, logger.SetRepoPIIHandling(""))
+	assert.Equal(t, logger.PIIPlainText, logger.GetPIIHandling(), "repositories without a policy")
+
+	require.NoError(t, logger.SetRepoPIIHandling(logger.PIIHash))
+	assert.Equal(t, logger.PIIHash, logger.GetPIIHandling())
+
+	assert.Error(t, logger.SetRepoPIIHandling("rot13"))
+	assert.Equal(t, logger.PIIHash, logger.GetPIIHandling())
+}
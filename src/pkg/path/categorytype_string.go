@@ -17,11 +17,13 @@ func _() {
 	_ = x[LibrariesCategory-6]
 	_ = x[PagesCategory-7]
 	_ = x[DetailsCategory-8]
+	_ = x[TasksCategory-9]
+	_ = x[SettingsCategory-10]
 }
 
-const _CategoryType_name = "UnknownCategoryemailcontactseventsfileslistslibrariespagesdetails"
+const _CategoryType_name = "UnknownCategoryemailcontactseventsfileslistslibrariespagesdetailstaskssettings"
 
-var _CategoryType_index = [...]uint8{0, 15, 20, 28, 34, 39, 44, 53, 58, 65}
+var _CategoryType_index = [...]uint8{0, 15, 20, 28, 34, 39, 44, 53, 58, 65, 70, 78}
 
 func (i CategoryType) String() string {
 	if i < 0 || i >= CategoryType(len(_CategoryType_index)-1) {
@@ -73,6 +73,8 @@ const (
 	LibrariesCategory              // libraries
 	PagesCategory                  // pages
 	DetailsCategory                // details
+	TasksCategory                  // tasks
+	SettingsCategory               // settings
 )
 
 func ToCategoryType(category string) CategoryType {
@@ -95,6 +97,10 @@ func ToCategoryType(category string) CategoryType {
 		return PagesCategory
 	case strings.ToLower(DetailsCategory.String()):
 		return DetailsCategory
+	case strings.ToLower(TasksCategory.String()):
+		return TasksCategory
+	case strings.ToLower(SettingsCategory.String()):
+		return SettingsCategory
 	default:
 		return UnknownCategory
 	}
@@ -107,6 +113,8 @@ var serviceCategories = map[ServiceType]map[CategoryType]struct{}{
 		EmailCategory:    {},
 		ContactsCategory: {},
 		EventsCategory:   {},
+		TasksCategory:    {},
+		SettingsCategory: {},
 	},
 	OneDriveService: {
 		FilesCategory: {},
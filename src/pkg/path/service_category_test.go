@@ -98,6 +98,22 @@ func (suite *ServiceCategoryUnitSuite) TestValidateServiceAndCategory() {
 			expectedCategory: EventsCategory,
 			check:            assert.NoError,
 		},
+		{
+			name:             "ExchangeTasks",
+			service:          ExchangeService.String(),
+			category:         TasksCategory.String(),
+			expectedService:  ExchangeService,
+			expectedCategory: TasksCategory,
+			check:            assert.NoError,
+		},
+		{
+			name:             "ExchangeSettings",
+			service:          ExchangeService.String(),
+			category:         SettingsCategory.String(),
+			expectedService:  ExchangeService,
+			expectedCategory: SettingsCategory,
+			check:            assert.NoError,
+		},
 		{
 			name:             "OneDriveFiles",
 			service:          OneDriveService.String(),
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"os"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/common"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/storage"
+)
+
+// CloneConfig initializes a staging repository beneath another prefix of the
+// repository's bucket, configured the same way as the repository: owner
+// encryption, when enabled, the restore policy, and the quota limits are
+// carried over.  No backups are copied.  Backups created in the staging
+// repository are tagged as staging backups, so that upgrades and policy
+// changes can be rehearsed against it without being mistaken for
+// production backups.
+//
+// Returns the ID of the staging repository.
+func (r repository) CloneConfig(ctx context.Context, prefix string) (string, error) {
+	ctx = clues.Add(ctx, "staging_prefix", prefix)
+
+	// the staging repository's kopia config is kept apart from the
+	// repository's, which is still connected.
+	cfgDir, err := os.MkdirTemp("", "corso-staging-")
+	if err != nil {
+		return "", clues.Wrap(err, "creating staging config dir").WithClues(ctx)
+	}
+
+	defer os.RemoveAll(cfgDir)
+
+	s, err := stagingStorage(r.Storage, prefix, cfgDir)
+	if err != nil {
+		return "", clues.Stack(err).WithClues(ctx)
+	}
+
+	rm, err := getRepoModel(ctx, r.modelStore)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving repo info")
+	}
+
+	policy, err := r.RestorePolicy(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving restore policy")
+	}
+
+	quota, err := r.Quota(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving repository quota")
+	}
+
+	opts := r.Opts
+	opts.OwnerEncryption = rm.OwnerEncryption
+	opts.Staging = true
+
+	repo, err := Initialize(ctx, r.Account, s, opts)
+	if err != nil {
+		return "", errors.Wrap(err, "initializing staging repository")
+	}
+
+	defer repo.Close(ctx)
+
+	sr := repo.(*repository)
+
+	srm, err := getRepoModel(ctx, sr.modelStore)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving staging repo info")
+	}
+
+	srm.Staging = true
+	srm.ClonedFrom = r.ID
+	srm.PIIHandling = rm.PIIHandling
+
+	if err := sr.modelStore.Update(ctx, model.RepositorySchema, srm); err != nil {
+		return "", errors.Wrap(err, "marking repository as staging")
+	}
+
+	if !policy.IsEmpty() {
+		if err := sr.SetRestorePolicy(ctx, *policy); err != nil {
+			return "", errors.Wrap(err, "copying restore policy")
+		}
+	}
+
+	if quota.Limits.Enabled() {
+		if err := sr.SetQuota(ctx, quota.Limits); err != nil {
+			return "", errors.Wrap(err, "copying repository quota")
+		}
+	}
+
+	return sr.ID, nil
+}
+
+// stagingStorage produces the storage of the repository's staging clone,
+// which only differs from the repository's storage by its prefix.
+func stagingStorage(s storage.Storage, prefix, cfgDir string) (storage.Storage, error) {
+	if s.Provider != storage.ProviderS3 {
+		return storage.Storage{}, clues.New("unsupported storage provider").With("storage_provider", s.Provider)
+	}
+
+	s3, err := s.S3Config()
+	if err != nil {
+		return storage.Storage{}, err
+	}
+
+	cc, err := s.CommonConfig()
+	if err != nil {
+		return storage.Storage{}, err
+	}
+
+	prefix = common.NormalizePrefix(prefix)
+	if len(prefix) == 0 || prefix == common.NormalizePrefix(s3.Prefix) {
+		return storage.Storage{}, clues.New("staging prefix must differ from the repository's prefix")
+	}
+
+	s3.Prefix = prefix
+	cc.KopiaCfgDir = cfgDir
+
+	return storage.NewStorage(storage.ProviderS3, s3, cc)
+}
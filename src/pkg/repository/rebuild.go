@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/kopia"
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/operations"
+	"github.com/alcionai/corso/src/internal/stats"
+	"github.com/alcionai/corso/src/internal/streamstore"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/selectors"
+	"github.com/alcionai/corso/src/pkg/store"
+)
+
+// rebuiltNotes annotates the backup models recreated by RebuildModels.
+const rebuiltNotes = "Rebuilt from the repository's snapshots."
+
+// RebuiltModels describes the outcome of RebuildModels.
+type RebuiltModels struct {
+	// Backups are the backup models recreated from their snapshots.
+	Backups []*backup.Backup
+	// Unrecoverable are the IDs of backups whose snapshots were found, but
+	// whose models couldn't be recreated, usually because their details
+	// couldn't be identified.
+	Unrecoverable []model.StableID
+}
+
+// RebuildModels recreates the backup models missing from the model store
+// using the tags of the repository's backup snapshots, so that a usable
+// backup catalog can be recovered after the model store is damaged.  Each
+// complete backup snapshot without a backup model is paired with the
+// snapshot holding its details.  Details snapshots tagged with the backup's
+// ID are used as-is.  Older, untagged details are only used if every entry
+// in them belongs to the backup's resource owner.
+//
+// Rebuilt backups select all of their owner's data, and are annotated as
+// rebuilt.  Stats not held in the snapshots, such as errors, aren't
+// recovered.  If dryRun is true, the models are produced but not stored.
+func (r repository) RebuildModels(ctx context.Context, dryRun bool) (*RebuiltModels, error) {
+	sw := store.NewKopiaStore(r.modelStore)
+
+	bups, err := sw.GetBackups(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving backups")
+	}
+
+	var (
+		existing = make(map[string]struct{}, len(bups))
+		claimed  = make(map[string]struct{}, len(bups))
+		res      = &RebuiltModels{}
+	)
+
+	for _, b := range bups {
+		existing[string(b.ID)] = struct{}{}
+		claimed[b.DetailsID] = struct{}{}
+	}
+
+	snaps, err := r.dataLayer.FindBackupSnapshots(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding backup snapshots")
+	}
+
+	for _, bs := range snaps {
+		if _, ok := existing[bs.BackupID]; ok {
+			continue
+		}
+
+		ictx := clues.Add(ctx, "backup_id", bs.BackupID, "snapshot_id", bs.SnapshotID)
+
+		b, err := r.rebuildBackup(ictx, bs, claimed)
+		if err != nil {
+			logger.Ctx(ictx).With("err", err).Infow("backup model not rebuilt", clues.InErr(err).Slice()...)
+			res.Unrecoverable = append(res.Unrecoverable, model.StableID(bs.BackupID))
+
+			continue
+		}
+
+		claimed[b.DetailsID] = struct{}{}
+
+		if !dryRun {
+			if err := sw.Put(ictx, model.BackupSchema, b); err != nil {
+				return nil, clues.Wrap(err, "storing rebuilt backup model").WithClues(ictx)
+			}
+		}
+
+		res.Backups = append(res.Backups, b)
+	}
+
+	return res, nil
+}
+
+// rebuildBackup produces the backup model of the backup snapshot.  Details
+// snapshots already claimed by another backup are never used.
+func (r repository) rebuildBackup(
+	ctx context.Context,
+	bs kopia.BackupSnapshot,
+	claimed map[string]struct{},
+) (*backup.Backup, error) {
+	if len(bs.Reasons) == 0 {
+		return nil, clues.New("backup snapshot has no reasons").WithClues(ctx)
+	}
+
+	var (
+		owner      = bs.Reasons[0].ResourceOwner
+		service    = bs.Reasons[0].Service
+		candidates = bs.DetailsCandidates
+		verify     = true
+		ss         = streamstore.New(r.dataLayer, r.Account.ID(), service)
+	)
+
+	if len(bs.DetailsID) > 0 {
+		candidates = []string{bs.DetailsID}
+		verify = false
+	}
+
+	for _, id := range candidates {
+		if _, ok := claimed[id]; ok {
+			continue
+		}
+
+		deets, err := ss.ReadBackupDetails(ctx, id, fault.New(false))
+		if err != nil {
+			// untagged snapshots which don't hold details can't be read.
+			continue
+		}
+
+		if verify && !detailsBelongTo(deets, service, owner) {
+			continue
+		}
+
+		sel, err := rebuiltSelector(service, owner)
+		if err != nil {
+			return nil, clues.Stack(err).WithClues(ctx)
+		}
+
+		b := backup.New(
+			bs.SnapshotID, id, operations.Completed.String(),
+			model.StableID(bs.BackupID),
+			sel,
+			stats.ReadWrites{
+				ItemsWritten:   len(deets.Items()),
+				ResourceOwners: 1,
+			},
+			stats.StartAndEndTime{
+				StartedAt:   bs.StartTime,
+				CompletedAt: bs.EndTime,
+			},
+			fault.New(false))
+		b.CreationTime = bs.EndTime
+		b.Notes = rebuiltNotes
+
+		return b, nil
+	}
+
+	return nil, clues.New("backup details not found").WithClues(ctx)
+}
+
+// detailsBelongTo is true if every entry in the details belongs to the
+// owner's data in the service.  Empty details can't be attributed to any
+// owner.
+func detailsBelongTo(deets *details.Details, service path.ServiceType, owner string) bool {
+	if len(deets.Entries) == 0 {
+		return false
+	}
+
+	for _, ent := range deets.Entries {
+		p, err := path.FromDataLayerPath(ent.RepoRef, false)
+		if err != nil {
+			return false
+		}
+
+		if p.Service() != service || p.ResourceOwner() != owner {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rebuiltSelector produces a selector of all of the owner's data in
+// the service.
+func rebuiltSelector(service path.ServiceType, owner string) (selectors.Selector, error) {
+	switch service {
+	case path.ExchangeService:
+		sel := selectors.NewExchangeBackup([]string{owner})
+		sel.Include(sel.AllData(), sel.TaskFolders(selectors.Any()))
+
+		return sel.Selector, nil
+
+	case path.OneDriveService:
+		sel := selectors.NewOneDriveBackup([]string{owner})
+		sel.Include(sel.AllData())
+
+		return sel.Selector, nil
+
+	case path.SharePointService:
+		sel := selectors.NewSharePointBackup([]string{owner})
+		sel.Include(sel.AllData())
+
+		return sel.Selector, nil
+	}
+
+	return selectors.Selector{}, clues.New("unsupported service").With("service", service.String())
+}
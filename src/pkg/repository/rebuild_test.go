@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type RebuildUnitSuite struct {
+	suite.Suite
+}
+
+func TestRebuildUnitSuite(t *testing.T) {
+	suite.Run(t, new(RebuildUnitSuite))
+}
+
+func (suite *RebuildUnitSuite) TestDetailsBelongTo() {
+	deetsOf := func(refs ...string) *details.Details {
+		d := &details.Details{}
+		for _, r := range refs {
+			d.Entries = append(d.Entries, details.DetailsEntry{RepoRef: r})
+		}
+
+		return d
+	}
+
+	table := []struct {
+		name   string
+		deets  *details.Details
+		expect assert.BoolAssertionFunc
+	}{
+		{
+			name:   "owner's entries",
+			deets:  deetsOf("tid/exchange/uid/email/inbox/item1", "tid/exchange/uid/events/cal/item2"),
+			expect: assert.True,
+		},
+		{
+			name:   "another owner's entry",
+			deets:  deetsOf("tid/exchange/uid/email/inbox/item1", "tid/exchange/other/email/inbox/item2"),
+			expect: assert.False,
+		},
+		{
+			name:   "another service",
+			deets:  deetsOf("tid/onedrive/uid/files/drives/did/root:/item1"),
+			expect: assert.False,
+		},
+		{
+			name:   "unparsable entry",
+			deets:  deetsOf("tid/exchange/uid"),
+			expect: assert.False,
+		},
+		{
+			name:   "empty",
+			deets:  deetsOf(),
+			expect: assert.False,
+		},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			test.expect(t, detailsBelongTo(test.deets, path.ExchangeService, "uid"))
+		})
+	}
+}
+
+func (suite *RebuildUnitSuite) TestRebuiltSelector() {
+	t := suite.T()
+
+	for _, service := range []path.ServiceType{path.ExchangeService, path.OneDriveService, path.SharePointService} {
+		sel, err := rebuiltSelector(service, "owner")
+		require.NoError(t, err, service.String())
+		assert.Equal(t, service, sel.PathService())
+		assert.Equal(t, "owner", sel.DiscreteOwner)
+	}
+
+	_, err := rebuiltSelector(path.UnknownService, "owner")
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -9,7 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/internal/common/crash"
+	"github.com/alcionai/corso/src/internal/connector/exchange"
 	"github.com/alcionai/corso/src/internal/connector/onedrive"
 	"github.com/alcionai/corso/src/internal/events"
 	"github.com/alcionai/corso/src/internal/kopia"
@@ -17,19 +21,30 @@ import (
 	"github.com/alcionai/corso/src/internal/observe"
 	"github.com/alcionai/corso/src/internal/operations"
 	"github.com/alcionai/corso/src/internal/streamstore"
+	"github.com/alcionai/corso/src/internal/update"
 	"github.com/alcionai/corso/src/internal/version"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/backup"
 	"github.com/alcionai/corso/src/pkg/backup/details"
+	"github.com/alcionai/corso/src/pkg/catalog"
 	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/export"
 	"github.com/alcionai/corso/src/pkg/fault"
 	"github.com/alcionai/corso/src/pkg/logger"
+	"github.com/alcionai/corso/src/pkg/path"
 	"github.com/alcionai/corso/src/pkg/selectors"
 	"github.com/alcionai/corso/src/pkg/storage"
 	"github.com/alcionai/corso/src/pkg/store"
 )
 
-var ErrorRepoAlreadyExists = errors.New("a repository was already initialized with that configuration")
+var (
+	ErrorRepoAlreadyExists = errors.New("a repository was already initialized with that configuration")
+	ErrorItemNotFound      = errors.New("item not found in backup")
+	ErrorNotStructureOnly  = errors.New("backup is not a structure-only backup")
+	// ErrorErasureRetained is returned when the repository's bucket keeps
+	// deleted objects, which would keep erased data recoverable.
+	ErrorErasureRetained = errors.New("the repository's bucket retains deleted objects")
+)
 
 // BackupGetter deals with retrieving metadata about backups from the
 // repository.
@@ -37,6 +52,7 @@ type BackupGetter interface {
 	Backup(ctx context.Context, id model.StableID) (*backup.Backup, error)
 	Backups(ctx context.Context, ids []model.StableID) ([]*backup.Backup, *fault.Errors)
 	BackupsByTag(ctx context.Context, fs ...store.FilterOption) ([]*backup.Backup, error)
+	BackupTrend(ctx context.Context, service path.ServiceType, owner string) (*backup.Trend, error)
 	BackupDetails(
 		ctx context.Context,
 		backupID string,
@@ -56,7 +72,46 @@ type Repository interface {
 		sel selectors.Selector,
 		dest control.RestoreDestination,
 	) (operations.RestoreOperation, error)
+	NewExport(
+		ctx context.Context,
+		backupID string,
+		sel selectors.Selector,
+		writer export.Writer,
+	) (operations.ExportOperation, error)
+	StreamBackupItems(
+		ctx context.Context,
+		backupID string,
+		sel selectors.Selector,
+		fn export.ItemFunc,
+	) error
+	NewVerify(
+		ctx context.Context,
+		backupIDs []string,
+		cfg control.VerifyConfig,
+	) (operations.VerifyOperation, error)
+	FetchItem(
+		ctx context.Context,
+		backupID, itemRef string,
+		fn export.ItemFunc,
+	) error
+	BackupStructure(ctx context.Context, backupID string) (*details.TreeNode, error)
 	DeleteBackup(ctx context.Context, id model.StableID) error
+	AnnotateBackup(ctx context.Context, id model.StableID, notes string) error
+	UpdateBackupDetails(ctx context.Context, id model.StableID, fn func(*details.Details) error) error
+	CompactMetadata(ctx context.Context, rules control.MetadataRetention) ([]string, error)
+	WarmCache(ctx context.Context) (*CacheStats, error)
+	SyncCatalog(ctx context.Context) (int, error)
+	ErasureRetention(ctx context.Context) (kopia.Preflight, error)
+	EraseOwner(ctx context.Context, owner string, force bool) (int, kopia.Preflight, error)
+	RestorePolicy(ctx context.Context) (*control.RestorePolicy, error)
+	SetRestorePolicy(ctx context.Context, rules control.RestorePolicy) error
+	Quota(ctx context.Context) (*control.RepoQuota, error)
+	SetQuota(ctx context.Context, limits control.QuotaOptions) error
+	PIIHandling(ctx context.Context) (logger.PIIHandling, error)
+	SetPIIHandling(ctx context.Context, h logger.PIIHandling) error
+	ApproveRestore(ctx context.Context, restoreID string) error
+	RebuildModels(ctx context.Context, dryRun bool) (*RebuiltModels, error)
+	CloneConfig(ctx context.Context, prefix string) (string, error)
 	BackupGetter
 }
 
@@ -71,6 +126,7 @@ type repository struct {
 	Opts    control.Options
 
 	Bus        events.Eventer
+	catalog    *catalog.Catalog
 	dataLayer  *kopia.Wrapper
 	modelStore *kopia.ModelStore
 }
@@ -136,18 +192,27 @@ func Initialize(
 	repoID := newRepoID(s)
 	bus.SetRepoID(repoID)
 
+	if opts.OwnerEncryption {
+		if err := w.EncryptByOwner(); err != nil {
+			return nil, errors.Wrap(err, "enabling owner encryption")
+		}
+	}
+
+	cat := openCatalog(ctx, opts.Catalog, repoID)
+
 	r := &repository{
 		ID:         repoID,
 		Version:    "v1",
 		Account:    acct,
 		Storage:    s,
 		Bus:        bus,
+		catalog:    cat,
 		Opts:       opts,
 		dataLayer:  w,
 		modelStore: ms,
 	}
 
-	if err := newRepoModel(ctx, ms, r.ID); err != nil {
+	if err := newRepoModel(ctx, ms, r.ID, opts.OwnerEncryption); err != nil {
 		return nil, clues.New("setting up repository").WithClues(ctx)
 	}
 
@@ -188,6 +253,9 @@ func Connect(
 	defer close(complete)
 
 	kopiaRef := kopia.NewConn(s)
+	kopiaRef.SetReadCache(opts.ReadCache.Dir, opts.ReadCache.MaxBytes)
+	kopiaRef.SetMetadataCache(opts.ReadCache.MaxMetadataBytes)
+
 	if err := kopiaRef.Connect(ctx); err != nil {
 		return nil, errors.Wrap(err, "connecting kopia client")
 	}
@@ -217,6 +285,26 @@ func Connect(
 
 	bus.SetRepoID(string(rm.ID))
 
+	checkWriterVersion(ctx, ms, rm)
+
+	if err := logger.SetRepoPIIHandling(rm.PIIHandling); err != nil {
+		logger.Ctx(ctx).
+			With("err", err).
+			Infow("applying the repository's pii handling", clues.InErr(err).Slice()...)
+	}
+
+	if rm.Staging {
+		opts.Staging = true
+	}
+
+	if rm.OwnerEncryption {
+		if err := w.EncryptByOwner(); err != nil {
+			return nil, errors.Wrap(err, "enabling owner encryption")
+		}
+	}
+
+	cat := openCatalog(ctx, opts.Catalog, string(rm.ID))
+
 	complete <- struct{}{}
 
 	// todo: ID and CreatedAt should get retrieved from a stored kopia config.
@@ -226,6 +314,7 @@ func Connect(
 		Account:    acct,
 		Storage:    s,
 		Bus:        bus,
+		catalog:    cat,
 		Opts:       opts,
 		dataLayer:  w,
 		modelStore: ms,
@@ -248,11 +337,28 @@ func ConnectAndSendConnectEvent(ctx context.Context,
 	return r, nil
 }
 
+// openCatalog connects to the external backup catalog, if one is configured.
+// The catalog only mirrors the repository, so a catalog that can't be reached
+// is logged and disabled instead of failing the repository connection.
+func openCatalog(ctx context.Context, opts control.CatalogOptions, repoID string) *catalog.Catalog {
+	cat, err := catalog.New(ctx, opts, repoID)
+	if err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("connecting to backup catalog", clues.InErr(err).Slice()...)
+		return nil
+	}
+
+	return cat
+}
+
 func (r *repository) Close(ctx context.Context) error {
 	if err := r.Bus.Close(); err != nil {
 		logger.Ctx(ctx).With("err", err).Debugw("closing the event bus", clues.In(ctx).Slice()...)
 	}
 
+	if err := r.catalog.Close(); err != nil {
+		logger.Ctx(ctx).With("err", err).Debugw("closing the backup catalog", clues.In(ctx).Slice()...)
+	}
+
 	if r.dataLayer != nil {
 		if err := r.dataLayer.Close(ctx); err != nil {
 			logger.Ctx(ctx).With("err", err).Debugw("closing Datalayer", clues.In(ctx).Slice()...)
@@ -277,7 +383,7 @@ func (r repository) NewBackup(
 	ctx context.Context,
 	selector selectors.Selector,
 ) (operations.BackupOperation, error) {
-	return operations.NewBackupOperation(
+	op, err := operations.NewBackupOperation(
 		ctx,
 		r.Opts,
 		r.dataLayer,
@@ -285,6 +391,9 @@ func (r repository) NewBackup(
 		r.Account,
 		selector,
 		r.Bus)
+	op.Catalog = r.catalog
+
+	return op, err
 }
 
 // NewRestore generates a restoreOperation runner.
@@ -306,6 +415,202 @@ func (r repository) NewRestore(
 		r.Bus)
 }
 
+// NewExport generates an exportOperation runner.
+func (r repository) NewExport(
+	ctx context.Context,
+	backupID string,
+	sel selectors.Selector,
+	writer export.Writer,
+) (operations.ExportOperation, error) {
+	return operations.NewExportOperation(
+		ctx,
+		r.Opts,
+		r.dataLayer,
+		store.NewKopiaStore(r.modelStore),
+		r.Account,
+		model.StableID(backupID),
+		sel,
+		writer,
+		r.Bus)
+}
+
+// NewVerify generates a verifyOperation runner.
+func (r repository) NewVerify(
+	ctx context.Context,
+	backupIDs []string,
+	cfg control.VerifyConfig,
+) (operations.VerifyOperation, error) {
+	ids := make([]model.StableID, 0, len(backupIDs))
+	for _, id := range backupIDs {
+		ids = append(ids, model.StableID(id))
+	}
+
+	return operations.NewVerifyOperation(
+		ctx,
+		r.Opts,
+		r.dataLayer,
+		store.NewKopiaStore(r.modelStore),
+		r.Account,
+		ids,
+		cfg,
+		r.Bus)
+}
+
+// StreamBackupItems reads the selected items out of the backup and hands
+// each item's data, along with its details entry, to fn.  Nothing is
+// restored to M365 or written to disk, which lets callers feed backup
+// contents directly into their own indexing pipelines.
+func (r repository) StreamBackupItems(
+	ctx context.Context,
+	backupID string,
+	sel selectors.Selector,
+	fn export.ItemFunc,
+) error {
+	op, err := operations.NewStreamOperation(
+		ctx,
+		r.Opts,
+		r.dataLayer,
+		store.NewKopiaStore(r.modelStore),
+		r.Account,
+		model.StableID(backupID),
+		sel,
+		fn,
+		r.Bus)
+	if err != nil {
+		return errors.Wrap(err, "initializing item stream")
+	}
+
+	if err := op.Run(ctx); err != nil {
+		return err
+	}
+
+	return op.StreamErr()
+}
+
+// FetchItem reads a single item out of the backup, and hands the item's
+// data, along with its details entry, to fn.  The item is identified by
+// the ShortRef or RepoRef of its details entry.  Item data is read from
+// storage as fn consumes it, so callers that only need the head of an
+// item don't retrieve the whole thing.
+func (r repository) FetchItem(
+	ctx context.Context,
+	backupID, itemRef string,
+	fn export.ItemFunc,
+) error {
+	ctx = clues.Add(ctx, "backup_id", backupID, "item_ref", itemRef)
+
+	deets, bup, errs := r.BackupDetails(ctx, backupID)
+	if errs.Err() != nil {
+		return errors.Wrap(errs.Err(), "getting backup details")
+	}
+
+	ent := deets.Item(itemRef)
+	if ent == nil {
+		return clues.Stack(ErrorItemNotFound).WithClues(ctx)
+	}
+
+	p, err := path.FromDataLayerPath(ent.RepoRef, true)
+	if err != nil {
+		return clues.Wrap(err, "parsing item repoRef").WithClues(ctx)
+	}
+
+	dcs, err := r.dataLayer.RestoreMultipleItems(ctx, bup.SnapshotID, []path.Path{p}, nil, errs)
+	if err != nil {
+		return errors.Wrap(err, "retrieving item from repository")
+	}
+
+	for _, dc := range dcs {
+		for item := range dc.Items(ctx, errs) {
+			rc := item.ToReader()
+			err := fn(ctx, *ent, rc)
+
+			rc.Close()
+
+			return err
+		}
+	}
+
+	if errs.Err() != nil {
+		return errors.Wrap(errs.Err(), "reading item from repository")
+	}
+
+	return clues.Stack(ErrorItemNotFound).WithClues(ctx)
+}
+
+// BackupStructure produces the folder tree recorded by a structure-only
+// backup, along with the count and size of the items in each folder.
+func (r repository) BackupStructure(ctx context.Context, backupID string) (*details.TreeNode, error) {
+	ctx = clues.Add(ctx, "backup_id", backupID)
+
+	bup, err := r.Backup(ctx, model.StableID(backupID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !bup.StructureOnly {
+		return nil, clues.Stack(ErrorNotStructureOnly).WithClues(ctx)
+	}
+
+	eb, err := bup.Selector.ToExchangeBackup()
+	if err != nil {
+		return nil, clues.Wrap(err, "reading backup selector").WithClues(ctx)
+	}
+
+	var (
+		paths = []path.Path{}
+		seen  = map[path.CategoryType]struct{}{}
+	)
+
+	for _, scope := range eb.Scopes() {
+		cat := scope.Category().PathType()
+		if _, ok := seen[cat]; ok {
+			continue
+		}
+
+		seen[cat] = struct{}{}
+
+		p, err := exchange.StructurePath(r.Account.ID(), bup.Selector.DiscreteOwner, cat)
+		if err != nil {
+			return nil, clues.Wrap(err, "making structure path").WithClues(ctx)
+		}
+
+		paths = append(paths, p)
+	}
+
+	errs := fault.New(true)
+
+	dcs, err := r.dataLayer.RestoreMultipleItems(ctx, bup.SnapshotID, paths, nil, errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving folder structure from repository")
+	}
+
+	folders := map[path.CategoryType][]exchange.FolderStructure{}
+
+	for _, dc := range dcs {
+		for item := range dc.Items(ctx, errs) {
+			var (
+				fs []exchange.FolderStructure
+				rc = item.ToReader()
+			)
+
+			err := json.NewDecoder(rc).Decode(&fs)
+			rc.Close()
+
+			if err != nil {
+				return nil, clues.Wrap(err, "reading folder structure").WithClues(ctx)
+			}
+
+			folders[path.ToCategoryType(item.UUID())] = fs
+		}
+	}
+
+	if errs.Err() != nil {
+		return nil, errors.Wrap(errs.Err(), "reading folder structure from repository")
+	}
+
+	return exchange.StructureTree(folders)
+}
+
 // backups lists a backup by id
 func (r repository) Backup(ctx context.Context, id model.StableID) (*backup.Backup, error) {
 	sw := store.NewKopiaStore(r.modelStore)
@@ -339,6 +644,17 @@ func (r repository) BackupsByTag(ctx context.Context, fs ...store.FilterOption)
 	return sw.GetBackups(ctx, fs...)
 }
 
+// BackupTrend returns the stats recorded for each backup of the owner
+// within the service.
+func (r repository) BackupTrend(
+	ctx context.Context,
+	service path.ServiceType,
+	owner string,
+) (*backup.Trend, error) {
+	sw := store.NewKopiaStore(r.modelStore)
+	return sw.GetTrend(ctx, service, owner)
+}
+
 // BackupDetails returns the specified backup details object
 func (r repository) BackupDetails(
 	ctx context.Context,
@@ -395,7 +711,291 @@ func (r repository) DeleteBackup(ctx context.Context, id model.StableID) error {
 
 	sw := store.NewKopiaStore(r.modelStore)
 
-	return sw.DeleteBackup(ctx, id)
+	if err := sw.DeleteBackup(ctx, id); err != nil {
+		return err
+	}
+
+	// the backup is already gone, so a stale catalog record is only logged.
+	if err := r.catalog.Delete(ctx, id); err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("syncing backup catalog", clues.InErr(err).Slice()...)
+	}
+
+	return nil
+}
+
+// AnnotateBackup replaces the notes attached to the backup.  Empty notes
+// clear any existing annotation.
+func (r repository) AnnotateBackup(ctx context.Context, id model.StableID, notes string) error {
+	sw := store.NewKopiaStore(r.modelStore)
+	return sw.AnnotateBackup(ctx, id, notes)
+}
+
+// UpdateBackupDetails hands the backup's details to fn, and replaces the
+// stored details with the entries as fn leaves them.  Post-processing,
+// such as classification, uses it to persist the extensions that it
+// attaches to entries with SetExtension.
+func (r repository) UpdateBackupDetails(
+	ctx context.Context,
+	id model.StableID,
+	fn func(*details.Details) error,
+) error {
+	deets, b, errs := r.BackupDetails(ctx, string(id))
+	if errs.Err() != nil {
+		return errs.Err()
+	}
+
+	if err := fn(deets); err != nil {
+		return clues.Wrap(err, "updating backup details").WithClues(ctx)
+	}
+
+	var (
+		sw   = store.NewKopiaStore(r.modelStore)
+		ss   = streamstore.New(r.dataLayer, r.Account.ID(), b.Selector.PathService())
+		prev = b.DetailsID
+	)
+
+	dID, err := ss.WriteBackupDetails(ctx, string(id), deets, fault.New(true))
+	if err != nil {
+		return clues.Wrap(err, "writing backup details").WithClues(ctx)
+	}
+
+	b.DetailsID = dID
+
+	if err := sw.Update(ctx, model.BackupSchema, b); err != nil {
+		// the backup still points at its previous details.
+		if derr := ss.DeleteBackupDetails(ctx, dID); derr != nil {
+			logger.Ctx(ctx).With("err", derr).Errorw("removing unused backup details", clues.InErr(derr).Slice()...)
+		}
+
+		return clues.Wrap(err, "updating backup").WithClues(ctx)
+	}
+
+	// the backup already points at its new details, so stale details are
+	// only logged.
+	if err := ss.DeleteBackupDetails(ctx, prev); err != nil {
+		logger.Ctx(ctx).With("err", err).Errorw("removing replaced backup details", clues.InErr(err).Slice()...)
+	}
+
+	return nil
+}
+
+// CompactMetadata removes backup snapshots that are no longer referenced by
+// a backup, or used as the base of an incremental backup, according to the
+// retention rules.  Returns the IDs of the removed snapshots.
+func (r repository) CompactMetadata(
+	ctx context.Context,
+	rules control.MetadataRetention,
+) ([]string, error) {
+	bups, err := store.NewKopiaStore(r.modelStore).GetBackups(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving backups")
+	}
+
+	referenced := make(map[string]struct{}, len(bups))
+	for _, b := range bups {
+		referenced[string(b.ID)] = struct{}{}
+	}
+
+	pruned, err := r.dataLayer.PruneSnapshots(
+		ctx,
+		referenced,
+		kopia.PruneRules{
+			KeepIncomplete:   rules.KeepIncomplete,
+			KeepUnreferenced: rules.KeepUnreferenced,
+			MinAge:           rules.MinAge,
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "compacting backup metadata")
+	}
+
+	return pruned, nil
+}
+
+// CacheStats describes the repository metadata held in the local cache.
+type CacheStats struct {
+	// Indexes is the number of index blobs held in the cache.
+	Indexes int
+	// RemovedIndexes is the number of cached indexes that failed their
+	// integrity check, and are retrieved from storage again on the next
+	// connection.
+	RemovedIndexes int
+	// Manifests is the number of backup and snapshot manifests loaded.
+	Manifests int
+}
+
+// WarmCache loads the repository's indexes and manifests into the local
+// cache, verifying the indexes already cached, so that later connections,
+// such as listing or describing backups, start without retrieving them
+// from storage.  Requires a connection with a read or metadata cache.
+func (r repository) WarmCache(ctx context.Context) (*CacheStats, error) {
+	ws, err := r.dataLayer.Warm(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "warming the repository cache")
+	}
+
+	return &CacheStats{
+		Indexes:        ws.Indexes,
+		RemovedIndexes: ws.RemovedIndexes,
+		Manifests:      ws.Manifests,
+	}, nil
+}
+
+// SyncCatalog mirrors the records of all of the repository's backups to
+// the external backup catalog.  Use it to fill a newly configured catalog,
+// or to catch up after the catalog was unreachable.  Failed backups keep no
+// record in the repository, so they're only mirrored when they run.
+// Returns the number of records written.
+func (r repository) SyncCatalog(ctx context.Context) (int, error) {
+	bs, err := r.BackupsByTag(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing backups")
+	}
+
+	n, err := r.catalog.Backfill(ctx, bs)
+	if err != nil {
+		return n, errors.Wrap(err, "syncing the backup catalog")
+	}
+
+	return n, nil
+}
+
+// ErasureRetention reports the bucket configurations, such as versioning
+// and object lock retention, which keep the deleted keys of erased owners
+// recoverable.
+func (r repository) ErasureRetention(ctx context.Context) (kopia.Preflight, error) {
+	pf, err := kopia.ErasureRetention(ctx, r.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "checking the bucket's retention")
+	}
+
+	return pf, nil
+}
+
+// EraseOwner destroys the encryption keys of the resource owner's data,
+// which makes the contents of the owner's items in every backup
+// unreadable.  Backup details, such as item names, paths, and subjects,
+// are not owner encrypted and remain readable.  The owner's next backup
+// neither merges nor reuses the delta tokens of earlier backups.  Only
+// available in repositories initialized with owner encryption.  Returns
+// the number of keys destroyed, and the bucket retention checked before
+// erasing.
+//
+// Erasure is refused with ErrorErasureRetained when the bucket retains
+// deleted objects, unless forced.
+func (r repository) EraseOwner(ctx context.Context, owner string, force bool) (int, kopia.Preflight, error) {
+	pf, err := r.ErasureRetention(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, c := range pf {
+		if c.Status == kopia.PreflightSkipped {
+			logger.Ctx(ctx).Warnw("unable to verify that the bucket doesn't retain erased data", "check", c.Name)
+		}
+	}
+
+	if pf.Warned() {
+		reasons := make([]string, 0, len(pf))
+
+		for _, c := range pf {
+			if c.Status == kopia.PreflightWarning {
+				reasons = append(reasons, c.Detail)
+			}
+		}
+
+		if !force {
+			return 0, pf, clues.Stack(ErrorErasureRetained).With("retention", reasons).WithClues(ctx)
+		}
+
+		logger.Ctx(ctx).Warnw("erasing owner data from a bucket that retains deleted objects", "retention", reasons)
+	}
+
+	n, err := r.dataLayer.EraseOwner(ctx, owner)
+	if err != nil {
+		return 0, pf, errors.Wrap(err, "erasing resource owner data")
+	}
+
+	return n, pf, nil
+}
+
+// RestorePolicy retrieves the repository's restore policy.  Repositories
+// without a policy produce an empty policy, which permits every destination.
+func (r repository) RestorePolicy(ctx context.Context) (*control.RestorePolicy, error) {
+	return store.NewKopiaStore(r.modelStore).GetRestorePolicy(ctx)
+}
+
+// SetRestorePolicy replaces the repository's restore policy.  Restores
+// into a destination that the policy doesn't permit fail before anything
+// is restored.  An empty policy removes all restrictions.
+func (r repository) SetRestorePolicy(ctx context.Context, rules control.RestorePolicy) error {
+	return store.NewKopiaStore(r.modelStore).SetRestorePolicy(ctx, rules)
+}
+
+// Quota retrieves the repository's quota, along with the last known size
+// of the repository.  Repositories without a quota produce an empty quota,
+// which leaves the repository unbounded.
+func (r repository) Quota(ctx context.Context) (*control.RepoQuota, error) {
+	return store.NewKopiaStore(r.modelStore).GetRepoQuota(ctx)
+}
+
+// SetQuota replaces the limits of the repository's quota.  Every backup
+// into the repository is held to the limits.  Empty limits remove the
+// quota.
+func (r repository) SetQuota(ctx context.Context, limits control.QuotaOptions) error {
+	return store.NewKopiaStore(r.modelStore).SetRepoQuota(ctx, limits)
+}
+
+// PIIHandling retrieves how the repository renders pii in logs.
+// Repositories without a policy produce an empty policy, which leaves
+// the pii handling to the --pii-handling flag.
+func (r repository) PIIHandling(ctx context.Context) (logger.PIIHandling, error) {
+	rm, err := getRepoModel(ctx, r.modelStore)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving repo info")
+	}
+
+	return rm.PIIHandling, nil
+}
+
+// SetPIIHandling replaces how the repository renders pii in logs.  The
+// policy applies to every later connection to the repository, unless it
+// runs with --pii-handling.  An empty policy removes it.
+func (r repository) SetPIIHandling(ctx context.Context, h logger.PIIHandling) error {
+	if len(h) > 0 {
+		if err := logger.ValidatePIIHandling(h); err != nil {
+			return clues.Stack(err).WithClues(ctx)
+		}
+	}
+
+	rm, err := getRepoModel(ctx, r.modelStore)
+	if err != nil {
+		return errors.Wrap(err, "retrieving repo info")
+	}
+
+	rm.PIIHandling = h
+
+	// repositories created before the model was introduced don't have one.
+	if len(rm.ModelStoreID) == 0 {
+		err = r.modelStore.Put(ctx, model.RepositorySchema, rm)
+	} else {
+		err = r.modelStore.Update(ctx, model.RepositorySchema, rm)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "storing pii handling")
+	}
+
+	return logger.SetRepoPIIHandling(h)
+}
+
+// ApproveRestore approves a restore that is waiting on approval.  A waiting
+// restore proceeds once it observes the approval; a restore that timed out
+// proceeds when it's resumed.  The approval is recorded as the current
+// user@host, and fails with store.ErrSelfApproval if that's who requested
+// the restore.
+func (r repository) ApproveRestore(ctx context.Context, restoreID string) error {
+	return store.NewKopiaStore(r.modelStore).
+		ApproveRestore(ctx, model.StableID(restoreID), common.Identity())
 }
 
 // ---------------------------------------------------------------------------
@@ -405,19 +1005,81 @@ func (r repository) DeleteBackup(ctx context.Context, id model.StableID) error {
 // repositoryModel identifies the current repository
 type repositoryModel struct {
 	model.BaseModel
+	// OwnerEncryption is set when the repository encrypts each resource
+	// owner's data with the owner's own key.
+	OwnerEncryption bool `json:"ownerEncryption,omitempty"`
+	// CorsoVersion is the most recent version of corso to connect to the
+	// repository, and BackupVersion is the backup format it writes.
+	CorsoVersion  string `json:"corsoVersion,omitempty"`
+	BackupVersion int    `json:"backupVersion,omitempty"`
+	// Staging is set on repositories created by CloneConfig, whose
+	// backups are tagged as staging backups.  ClonedFrom is the ID of
+	// the repository whose configuration was cloned.
+	Staging    bool   `json:"staging,omitempty"`
+	ClonedFrom string `json:"clonedFrom,omitempty"`
+	// PIIHandling is how corso renders pii in the logs of every process
+	// connected to the repository, unless overridden by --pii-handling.
+	PIIHandling logger.PIIHandling `json:"piiHandling,omitempty"`
 }
 
 // should only be called on init.
-func newRepoModel(ctx context.Context, ms *kopia.ModelStore, repoID string) error {
+func newRepoModel(ctx context.Context, ms *kopia.ModelStore, repoID string, ownerEncryption bool) error {
 	rm := repositoryModel{
 		BaseModel: model.BaseModel{
 			ID: model.StableID(repoID),
 		},
+		OwnerEncryption: ownerEncryption,
+		CorsoVersion:    version.CurrentVersion(),
+		BackupVersion:   version.Backup,
 	}
 
 	return ms.Put(ctx, model.RepositorySchema, &rm)
 }
 
+// checkWriterVersion warns when a newer version of corso has written to the
+// repository, since this version may not understand what it wrote.  Otherwise
+// the running version is recorded as the repository's latest writer.
+func checkWriterVersion(ctx context.Context, ms *kopia.ModelStore, rm *repositoryModel) {
+	current := version.CurrentVersion()
+
+	if writtenByNewer(*rm, current, version.Backup) {
+		logger.Ctx(ctx).Warnw(
+			"repository written by a newer corso version",
+			"repo_corso_version", rm.CorsoVersion,
+			"repo_backup_version", rm.BackupVersion,
+			"corso_version", current)
+
+		observe.Message(ctx, observe.Safe(fmt.Sprintf(
+			"Warning: this repository was written by corso %s, which is newer than this version (%s). "+
+				"Run 'corso update' to upgrade.",
+			rm.CorsoVersion,
+			current)))
+
+		return
+	}
+
+	// repositories without a model have nothing to update.
+	if len(rm.ModelStoreID) == 0 ||
+		(rm.BackupVersion == version.Backup && !update.Newer(rm.CorsoVersion, current)) {
+		return
+	}
+
+	rm.CorsoVersion = current
+	rm.BackupVersion = version.Backup
+
+	if err := ms.Update(ctx, model.RepositorySchema, rm); err != nil {
+		logger.Ctx(ctx).
+			With("err", err).
+			Infow("recording the repository's corso version", clues.InErr(err).Slice()...)
+	}
+}
+
+// writtenByNewer is true if the repository was written with a later backup
+// format, or a later release of corso, than the running version.
+func writtenByNewer(rm repositoryModel, current string, backupVersion int) bool {
+	return rm.BackupVersion > backupVersion || update.Newer(current, rm.CorsoVersion)
+}
+
 // retrieves the repository info
 func getRepoModel(ctx context.Context, ms *kopia.ModelStore) (*repositoryModel, error) {
 	bms, err := ms.GetIDsForType(ctx, model.RepositorySchema, nil)
@@ -430,7 +1092,9 @@ func getRepoModel(ctx context.Context, ms *kopia.ModelStore) (*repositoryModel,
 		return rm, nil
 	}
 
-	rm.BaseModel = *bms[0]
+	if err := ms.Get(ctx, model.RepositorySchema, bms[0].ID, rm); err != nil {
+		return nil, err
+	}
 
 	return rm, nil
 }
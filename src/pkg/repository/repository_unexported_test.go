@@ -9,8 +9,99 @@ import (
 
 	"github.com/alcionai/corso/src/internal/kopia"
 	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/internal/version"
+	"github.com/alcionai/corso/src/pkg/credentials"
+	"github.com/alcionai/corso/src/pkg/storage"
 )
 
+type RepositoryModelUnitSuite struct {
+	suite.Suite
+}
+
+func TestRepositoryModelUnitSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryModelUnitSuite))
+}
+
+func (suite *RepositoryModelUnitSuite) TestWrittenByNewer() {
+	table := []struct {
+		name    string
+		rm      repositoryModel
+		current string
+		expect  bool
+	}{
+		{
+			name:    "unversioned repository",
+			rm:      repositoryModel{},
+			current: "v0.6.0",
+		},
+		{
+			name:    "same version",
+			rm:      repositoryModel{CorsoVersion: "v0.6.0", BackupVersion: 4},
+			current: "v0.6.0",
+		},
+		{
+			name:    "older corso",
+			rm:      repositoryModel{CorsoVersion: "v0.5.0", BackupVersion: 4},
+			current: "v0.6.0",
+		},
+		{
+			name:    "newer corso",
+			rm:      repositoryModel{CorsoVersion: "v0.7.0", BackupVersion: 4},
+			current: "v0.6.0",
+			expect:  true,
+		},
+		{
+			name:    "newer backup format",
+			rm:      repositoryModel{CorsoVersion: "dev-abc", BackupVersion: 5},
+			current: "dev-def",
+			expect:  true,
+		},
+		{
+			name:    "dev build",
+			rm:      repositoryModel{CorsoVersion: "v0.7.0", BackupVersion: 4},
+			current: "dev-def",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, writtenByNewer(test.rm, test.current, 4))
+		})
+	}
+}
+
+func (suite *RepositoryModelUnitSuite) TestStagingStorage() {
+	t := suite.T()
+
+	s, err := storage.NewStorage(
+		storage.ProviderS3,
+		storage.S3Config{Bucket: "bucket", Prefix: "prod", Endpoint: "s3.example.com"},
+		storage.CommonConfig{
+			Corso:       credentials.Corso{CorsoPassphrase: "pass"},
+			KopiaCfgDir: "/prod",
+		})
+	require.NoError(t, err)
+
+	staged, err := stagingStorage(s, "staging", "/staging")
+	require.NoError(t, err)
+
+	s3, err := staged.S3Config()
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", s3.Bucket)
+	assert.Equal(t, "s3.example.com", s3.Endpoint)
+	assert.Equal(t, "staging/", s3.Prefix)
+
+	cc, err := staged.CommonConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "pass", cc.CorsoPassphrase)
+	assert.Equal(t, "/staging", cc.KopiaCfgDir)
+
+	_, err = stagingStorage(s, "prod/", "/staging")
+	assert.Error(t, err, "same prefix")
+
+	_, err = stagingStorage(s, "", "/staging")
+	assert.Error(t, err, "empty prefix")
+}
+
 type RepositoryModelSuite struct {
 	suite.Suite
 }
@@ -49,9 +140,12 @@ func (suite *RepositoryModelSuite) TestWriteGetModel() {
 
 	defer ms.Close(ctx)
 
-	require.NoError(t, newRepoModel(ctx, ms, "fnords"))
+	require.NoError(t, newRepoModel(ctx, ms, "fnords", true))
 
 	got, err := getRepoModel(ctx, ms)
 	require.NoError(t, err)
 	assert.Equal(t, "fnords", string(got.ID))
+	assert.True(t, got.OwnerEncryption)
+	assert.Equal(t, version.CurrentVersion(), got.CorsoVersion)
+	assert.Equal(t, version.Backup, got.BackupVersion)
 }
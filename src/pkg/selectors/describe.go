@@ -0,0 +1,311 @@
+package selectors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alcionai/corso/src/pkg/filters"
+)
+
+// names of the data matched by each leaf category.  Categories without a
+// name are described by their humanized category.
+var dataTypeNames = map[string]string{
+	ExchangeContact.String():       "contacts",
+	ExchangeEvent.String():         "events",
+	ExchangeMail.String():          "mail",
+	ExchangeTask.String():          "tasks",
+	ExchangeUser.String():          "mailbox data",
+	OneDriveItem.String():          "files",
+	SharePointLibraryItem.String(): "library files",
+	SharePointListItem.String():    "list items",
+	SharePointPage.String():        "pages",
+}
+
+// category prefixes that don't add meaning to a description.
+var categoryPrefixes = []string{"Exchange", "OneDrive", "SharePoint", "File", "Filter"}
+
+// Describe produces a plain-English summary of the selector: the resource
+// owners it covers, followed by its inclusions, filters, exclusions, and
+// groups.  The summary lets users confirm what a selector matches before
+// running an operation with it.
+//
+// Ex:
+//
+//	Exchange data for users: u1
+//	Includes:
+//	  - mail where folder is within "Inbox"
+//	Excludes:
+//	  - mail where subject contains "newsletter"
+func (s Selector) Describe() string {
+	var sb strings.Builder
+
+	sb.WriteString(s.Service.String() + " data for " + describeOwners(s))
+
+	if len(s.Includes) == 0 && len(s.Filters) == 0 && len(s.Groups) == 0 {
+		sb.WriteString("\nIncludes nothing")
+	}
+
+	writeScopes(&sb, "Includes", s.Includes)
+	writeScopes(&sb, "Filters (all must match)", s.Filters)
+	writeScopes(&sb, "Excludes", s.Excludes)
+
+	if len(s.Groups) > 0 {
+		sb.WriteString("\nMatches all of:")
+
+		for _, g := range s.Groups {
+			writeGroup(&sb, g, 1)
+		}
+	}
+
+	return sb.String()
+}
+
+func writeScopes(sb *strings.Builder, title string, ss []scope) {
+	if len(ss) == 0 {
+		return
+	}
+
+	sb.WriteString("\n" + title + ":")
+
+	for _, sc := range ss {
+		sb.WriteString("\n  - " + describeScope(sc))
+	}
+}
+
+func writeGroup(sb *strings.Builder, g Group, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	var op string
+
+	switch g.Op {
+	case GroupAnd:
+		op = "all of"
+	case GroupNot:
+		op = "none of"
+	default:
+		op = "any of"
+	}
+
+	sb.WriteString("\n" + indent + "- " + op + ":")
+
+	for _, sc := range g.Scopes {
+		sb.WriteString("\n" + indent + "  - " + describeScope(sc))
+	}
+
+	for _, child := range g.Groups {
+		writeGroup(sb, child, depth+1)
+	}
+}
+
+// describeOwners names the resource owners, and owner groups, of the selector.
+func describeOwners(s Selector) string {
+	kind := "users"
+	if s.Service == ServiceSharePoint {
+		kind = "sites"
+	}
+
+	var owners string
+
+	switch {
+	case isAnyResourceOwner(s):
+		owners = "all " + kind
+	case !isNoneResourceOwner(s):
+		owners = kind + ": " + strings.Join(split(s.ResourceOwners.Target), ", ")
+	case len(s.OwnerGroups) == 0:
+		return "no " + kind
+	}
+
+	if len(s.OwnerGroups) == 0 {
+		return owners
+	}
+
+	members := "members of groups: " + strings.Join(s.OwnerGroups, ", ")
+
+	if len(owners) == 0 {
+		return members
+	}
+
+	return owners + "; and " + members
+}
+
+// describeScope produces a one line description of the data matched by
+// the scope.
+func describeScope(sc scope) string {
+	desc := describeScopeData(sc)
+
+	if owner := scopeOwner(sc); len(owner) > 0 {
+		desc += " (" + owner + " only)"
+	}
+
+	return desc
+}
+
+func describeScopeData(sc scope) string {
+	var (
+		dataCat  = sc[scopeKeyDataType].Target
+		dataType = dataTypeName(dataCat)
+		dataWord = humanize(dataCat)
+	)
+
+	if fc, ok := sc[scopeKeyInfoFilter]; ok {
+		return dataType + " where " + describeCondition(fc.Target, dataWord, sc[fc.Target])
+	}
+
+	keys := make([]string, 0, len(sc))
+
+	for k, f := range sc {
+		if k == scopeKeyCategory || k == scopeKeyDataType || k == scopeKeyResourceOwner ||
+			f.Comparator == filters.Passes {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		return "all " + dataType
+	}
+
+	// the item itself is described after the containers that hold it.
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == dataCat) != (keys[j] == dataCat) {
+			return keys[j] == dataCat
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	conds := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if sc[k].Comparator == filters.Fails {
+			return "no " + dataType
+		}
+
+		conds = append(conds, describeCondition(k, dataWord, sc[k]))
+	}
+
+	return dataType + " where " + strings.Join(conds, " and ")
+}
+
+// describeCondition describes the filter applied to the category.  Names
+// that repeat the data type are shortened: a mail folder is a folder, when
+// describing mail.
+func describeCondition(cat, dataWord string, f filters.Filter) string {
+	name := strings.TrimSpace(strings.TrimPrefix(humanize(cat), dataWord))
+	if len(name) == 0 {
+		name = "item"
+	}
+
+	return name + " " + describeFilter(f)
+}
+
+// describeFilter describes the comparison made by the filter.
+func describeFilter(f filters.Filter) string {
+	targets := f.Targets
+	if len(targets) == 0 {
+		targets = split(f.Target)
+	}
+
+	var (
+		vs = quoteAll(targets)
+		is = "is "
+	)
+
+	if f.Negate {
+		is = "is not "
+	}
+
+	switch f.Comparator {
+	case filters.Passes:
+		return "is anything"
+	case filters.Fails:
+		return "is nothing"
+	case filters.EqualTo:
+		return is + vs
+	case filters.GreaterThan, filters.LessThan:
+		return vs
+	case filters.TargetContains:
+		return is + "one of " + vs
+	case filters.TargetIn:
+		return negated(f, "contains ", "doesn't contain ") + vs
+	case filters.TargetPrefixes:
+		return negated(f, "starts with ", "doesn't start with ") + vs
+	case filters.TargetSuffixes:
+		return negated(f, "ends with ", "doesn't end with ") + vs
+	case filters.TargetPattern:
+		return negated(f, "matches ", "doesn't match ") + vs
+	case filters.TargetExactIn:
+		return is + "exactly one of " + vs
+	case filters.TargetPathPrefix:
+		return is + "within " + vs
+	case filters.TargetPathContains:
+		return negated(f, "has ", "doesn't have ") + vs + " in its path"
+	case filters.TargetPathSuffix:
+		return negated(f, "ends with ", "doesn't end with ") + vs
+	case filters.TargetPathEquals:
+		return is + "exactly " + vs
+	default:
+		return f.String()
+	}
+}
+
+func negated(f filters.Filter, verb, negVerb string) string {
+	if f.Negate {
+		return negVerb
+	}
+
+	return verb
+}
+
+func quoteAll(vs []string) string {
+	qs := make([]string, 0, len(vs))
+	for _, v := range vs {
+		qs = append(qs, strconv.Quote(v))
+	}
+
+	return strings.Join(qs, " or ")
+}
+
+// dataTypeName names the data matched by the leaf category.
+func dataTypeName(cat string) string {
+	if n, ok := dataTypeNames[cat]; ok {
+		return n
+	}
+
+	return humanize(cat)
+}
+
+// humanize converts the category into lower case words, dropping any
+// service or filter prefixes.
+// Ex: ExchangeFilterMailReceivedAfter => mail received after
+// Ex: SharePointWebURL => web url
+func humanize(cat string) string {
+	for _, p := range categoryPrefixes {
+		cat = strings.TrimPrefix(cat, p)
+	}
+
+	var (
+		words []string
+		rs    = []rune(cat)
+		start int
+	)
+
+	for i := 1; i < len(rs); i++ {
+		lowerBefore := unicode.IsLower(rs[i-1])
+		acronymEnd := unicode.IsUpper(rs[i-1]) && i+1 < len(rs) && unicode.IsLower(rs[i+1])
+
+		if unicode.IsUpper(rs[i]) && (lowerBefore || acronymEnd) {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+
+	if len(rs) > 0 {
+		words = append(words, string(rs[start:]))
+	}
+
+	return strings.ToLower(strings.Join(words, " "))
+}
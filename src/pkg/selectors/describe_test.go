@@ -0,0 +1,146 @@
+package selectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DescribeSuite struct {
+	suite.Suite
+}
+
+func TestDescribeSuite(t *testing.T) {
+	suite.Run(t, new(DescribeSuite))
+}
+
+func (suite *DescribeSuite) TestDescribe() {
+	table := []struct {
+		name   string
+		sel    func() Selector
+		expect string
+	}{
+		{
+			name: "empty",
+			sel: func() Selector {
+				return NewExchangeBackup([]string{"u1"}).Selector
+			},
+			expect: "Exchange data for users: u1\nIncludes nothing",
+		},
+		{
+			name: "all exchange data",
+			sel: func() Selector {
+				sel := NewExchangeBackup(Any())
+				sel.Include(sel.AllData())
+
+				return sel.Selector
+			},
+			expect: "Exchange data for all users\n" +
+				"Includes:\n" +
+				"  - all contacts\n" +
+				"  - all events\n" +
+				"  - all mail",
+		},
+		{
+			name: "exchange folders and filters",
+			sel: func() Selector {
+				sel := NewExchangeRestore([]string{"u1", "u2"})
+				sel.Include(
+					sel.MailFolders([]string{"Inbox"}, PrefixMatch()),
+					sel.Events([]string{"Work"}, []string{"e1", "e2"}))
+				sel.Filter(sel.MailReceivedAfter("2023-01-01T00:00:00Z"))
+				sel.Exclude(sel.MailSubject("newsletter"))
+
+				return sel.Selector
+			},
+			expect: "Exchange data for users: u1, u2\n" +
+				"Includes:\n" +
+				"  - mail where folder is within \"Inbox\"\n" +
+				"  - events where calendar has \"Work\" in its path and item is one of \"e1\" or \"e2\"\n" +
+				"Filters (all must match):\n" +
+				"  - mail where received after \"2023-01-01T00:00:00Z\"\n" +
+				"Excludes:\n" +
+				"  - mail where subject contains \"newsletter\"",
+		},
+		{
+			name: "exchange owner folder pairs",
+			sel: func() Selector {
+				sel := NewExchangeBackup(None())
+				sel.IncludeFor("shared", sel.MailFolders([]string{"Shared/Projects"}, PrefixMatch()))
+				sel.IncludeFor("owner", sel.MailFolders(Any()))
+
+				return sel.Selector
+			},
+			expect: "Exchange data for users: shared, owner\n" +
+				"Includes:\n" +
+				"  - mail where folder is within \"Shared/Projects\" (shared only)\n" +
+				"  - all mail (owner only)",
+		},
+		{
+			name: "onedrive owner groups",
+			sel: func() Selector {
+				sel := NewOneDriveBackup(None())
+				sel.IncludeOwnerGroups("Finance")
+				sel.Include(sel.Folders([]string{"Reports"}))
+
+				return sel.Selector
+			},
+			expect: "OneDrive data for members of groups: Finance\n" +
+				"Includes:\n" +
+				"  - files where folder has \"Reports\" in its path",
+		},
+		{
+			name: "sharepoint none",
+			sel: func() Selector {
+				sel := NewSharePointRestore([]string{"s1"})
+				sel.Include(sel.Libraries(None()))
+
+				return sel.Selector
+			},
+			expect: "SharePoint data for sites: s1\n" +
+				"Includes:\n" +
+				"  - no library files",
+		},
+		{
+			name: "groups",
+			sel: func() Selector {
+				sel := NewOneDriveRestore(Any())
+				sel.Match(Or(
+					Scopes(sel.CreatedBy("u1")),
+					Not(Scopes(sel.Folders([]string{"Private"}, ExactMatch())))))
+
+				return sel.Selector
+			},
+			expect: "OneDrive data for all users\n" +
+				"Matches all of:\n" +
+				"  - any of:\n" +
+				"    - any of:\n" +
+				"      - files where created by contains \"u1\"\n" +
+				"    - none of:\n" +
+				"      - any of:\n" +
+				"        - files where folder is exactly \"Private\"",
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, test.sel().Describe())
+		})
+	}
+}
+
+func (suite *DescribeSuite) TestHumanize() {
+	table := map[string]string{
+		ExchangeFilterMailReceivedAfter.String(): "mail received after",
+		ExchangeMailFolder.String():              "mail folder",
+		FileFilterCreatedBy.String():             "created by",
+		SharePointWebURL.String():                "web url",
+		SharePointLibraryURL.String():            "library url",
+		OneDriveItem.String():                    "item",
+	}
+	for input, expect := range table {
+		suite.Run(input, func() {
+			assert.Equal(suite.T(), expect, humanize(input))
+		})
+	}
+}
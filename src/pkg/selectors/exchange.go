@@ -3,6 +3,7 @@ package selectors
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/alcionai/corso/src/internal/common"
 	"github.com/alcionai/corso/src/pkg/backup/details"
@@ -184,6 +185,47 @@ func (s *exchange) Include(scopes ...[]ExchangeScope) {
 	s.Includes = appendScopes(s.Includes, scopes...)
 }
 
+// IncludeFor appends the provided scopes to the selector's inclusion set,
+// restricting them to a single resource owner, which is added to the
+// selector's resource owners.  Each owner gets its own scoping, so that
+// one selector can pair each mailbox with different folders.
+// Ex: IncludeFor(shared, MailFolders(["Shared/Projects"], PrefixMatch())),
+// plus IncludeFor(owner, AllData()) => backs up only the Projects folder
+// of the shared mailbox, and the whole mailbox of its owner.
+//
+// Scopes added with Include, Filter, or Exclude still apply to every owner.
+// Selectors with owner-scoped scopes must be split by resource owner before
+// running a backup; owners left without any inclusions or filters are
+// dropped from the split.
+func (s *exchange) IncludeFor(owner string, scopes ...[]ExchangeScope) {
+	s.addResourceOwner(owner)
+	s.Includes = appendOwnerScopes(s.Includes, owner, scopes...)
+}
+
+// FilterFor appends the provided scopes to the selector's filter set,
+// restricting them to a single resource owner, as with IncludeFor.
+func (s *exchange) FilterFor(owner string, scopes ...[]ExchangeScope) {
+	s.addResourceOwner(owner)
+	s.Filters = appendOwnerScopes(s.Filters, owner, scopes...)
+}
+
+// ExcludeFor appends the provided scopes to the selector's exclusion set,
+// restricting them to a single resource owner.  Unlike IncludeFor, the
+// owner must already be one of the selector's resource owners.
+func (s *exchange) ExcludeFor(owner string, scopes ...[]ExchangeScope) {
+	s.Excludes = appendOwnerScopes(s.Excludes, owner, scopes...)
+}
+
+// Match appends the provided groups to the selector's group set.
+// Data is retained if it matches ALL groups.  Groups compose scopes with
+// boolean logic, for conditions which the inclusions, filters, and
+// exclusions can't express.
+// Ex: Or(And(Scopes(s1), Scopes(s2)), Scopes(s3)) => retains data which
+// matches both s1 and s2, or which matches s3.
+func (s *exchange) Match(groups ...Group) {
+	s.Groups = append(s.Groups, groups...)
+}
+
 // Scopes retrieves the list of exchangeScopes in the selector.
 func (s *exchange) Scopes() []ExchangeScope {
 	return scopes[ExchangeScope](s.Selector)
@@ -303,8 +345,46 @@ func (s *exchange) MailFolders(folders []string, opts ...option) []ExchangeScope
 	return scopes
 }
 
+// Produces one or more exchange task scopes.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+// options are only applied to the folder scopes.
+func (s *exchange) Tasks(folders, tasks []string, opts ...option) []ExchangeScope {
+	scopes := []ExchangeScope{}
+
+	scopes = append(
+		scopes,
+		makeScope[ExchangeScope](ExchangeTask, tasks).
+			set(ExchangeTaskFolder, folders, opts...),
+	)
+
+	return scopes
+}
+
+// Produces one or more exchange task folder scopes.
+// Task folders are Microsoft To Do task lists.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+// options are only applied to the folder scopes.
+func (s *exchange) TaskFolders(folders []string, opts ...option) []ExchangeScope {
+	var (
+		scopes = []ExchangeScope{}
+		os     = append([]option{pathComparator()}, opts...)
+	)
+
+	scopes = append(
+		scopes,
+		makeScope[ExchangeScope](ExchangeTaskFolder, folders, os...),
+	)
+
+	return scopes
+}
+
 // Retrieves all exchange data.
 // Each user id generates three scopes, one for each data type: contact, event, and mail.
+// Tasks are opt-in, and must be added separately with TaskFolders.
 // If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
 // If any slice contains selectors.None, that slice is reduced to [selectors.None]
 // If any slice is empty, it defaults to [selectors.None]
@@ -411,6 +491,21 @@ func (sr *ExchangeRestore) EventSubject(subject string) []ExchangeScope {
 	}
 }
 
+// TaskSubject produces one or more exchange task subject filter scopes.
+// Matches any task where the task subject contains one of the provided strings.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+func (sr *ExchangeRestore) TaskSubject(subject string) []ExchangeScope {
+	return []ExchangeScope{
+		makeFilterScope[ExchangeScope](
+			ExchangeTask,
+			ExchangeFilterTaskSubject,
+			[]string{subject},
+			wrapFilter(filters.In)),
+	}
+}
+
 // MailReceivedAfter produces an exchange mail received-after filter scope.
 // Matches any mail which was received after the timestring.
 // If the input equals selectors.Any, the scope will match all times.
@@ -439,6 +534,45 @@ func (sr *ExchangeRestore) MailReceivedBefore(timeStrings string) []ExchangeScop
 	}
 }
 
+// MailFolderID produces an exchange mail folder ID filter scope.
+// Matches any mail held directly within one of the folders with the provided
+// IDs.  Unlike folder names, folder IDs don't change when a folder gets
+// renamed, so the scope continues to target the same folder in backups made
+// before and after a rename.  IDs are matched case-sensitively, as graph
+// folder IDs can differ only by case.  Only matches mail in backups that
+// recorded folder IDs in their details.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+func (sr *ExchangeRestore) MailFolderID(folderIDs []string) []ExchangeScope {
+	return []ExchangeScope{
+		makeFilterScope[ExchangeScope](
+			ExchangeMail,
+			ExchangeFilterMailFolderID,
+			folderIDs,
+			wrapSliceFilter(filters.ExactIn)),
+	}
+}
+
+// MailMessageID produces an exchange mail internet Message-ID filter scope.
+// Matches any mail whose Message-ID equals the provided value.  The angle
+// brackets which enclose Message-IDs are optional.
+// If the input equals selectors.Any, the scope will match all mail.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (sr *ExchangeRestore) MailMessageID(messageID string) []ExchangeScope {
+	if messageID != AnyTgt && messageID != NoneTgt && len(messageID) > 0 {
+		messageID = "<" + strings.Trim(messageID, "<> ") + ">"
+	}
+
+	return []ExchangeScope{
+		makeFilterScope[ExchangeScope](
+			ExchangeMail,
+			ExchangeFilterMailMessageID,
+			[]string{messageID},
+			wrapFilter(filters.Equal)),
+	}
+}
+
 // MailSender produces one or more exchange mail sender filter scopes.
 // Matches any mail whose sender contains one of the provided strings.
 // If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
@@ -490,6 +624,8 @@ const (
 	ExchangeMail          exchangeCategory = "ExchangeMail"
 	ExchangeMailFolder    exchangeCategory = "ExchangeMailFolder"
 	ExchangeUser          exchangeCategory = "ExchangeUser"
+	ExchangeTask          exchangeCategory = "ExchangeTask"
+	ExchangeTaskFolder    exchangeCategory = "ExchangeTaskFolder"
 	// append new data cats here
 
 	// filterable topics identified by exchange
@@ -497,12 +633,15 @@ const (
 	ExchangeFilterMailSubject        exchangeCategory = "ExchangeFilterMailSubject"
 	ExchangeFilterMailReceivedAfter  exchangeCategory = "ExchangeFilterMailReceivedAfter"
 	ExchangeFilterMailReceivedBefore exchangeCategory = "ExchangeFilterMailReceivedBefore"
+	ExchangeFilterMailMessageID      exchangeCategory = "ExchangeFilterMailMessageID"
+	ExchangeFilterMailFolderID       exchangeCategory = "ExchangeFilterMailFolderID"
 	ExchangeFilterContactName        exchangeCategory = "ExchangeFilterContactName"
 	ExchangeFilterEventOrganizer     exchangeCategory = "ExchangeFilterEventOrganizer"
 	ExchangeFilterEventRecurs        exchangeCategory = "ExchangeFilterEventRecurs"
 	ExchangeFilterEventStartsAfter   exchangeCategory = "ExchangeFilterEventStartsAfter"
 	ExchangeFilterEventStartsBefore  exchangeCategory = "ExchangeFilterEventStartsBefore"
 	ExchangeFilterEventSubject       exchangeCategory = "ExchangeFilterEventSubject"
+	ExchangeFilterTaskSubject        exchangeCategory = "ExchangeFilterTaskSubject"
 	// append new filter cats here
 )
 
@@ -520,6 +659,10 @@ var exchangeLeafProperties = map[categorizer]leafProperty{
 		pathKeys: []categorizer{ExchangeMailFolder, ExchangeMail},
 		pathType: path.EmailCategory,
 	},
+	ExchangeTask: {
+		pathKeys: []categorizer{ExchangeTaskFolder, ExchangeTask},
+		pathType: path.TasksCategory,
+	},
 	ExchangeUser: { // the root category must be represented, even though it isn't a leaf
 		pathKeys: []categorizer{ExchangeUser},
 		pathType: path.UnknownCategory,
@@ -548,8 +691,12 @@ func (ec exchangeCategory) leafCat() categorizer {
 		return ExchangeEvent
 
 	case ExchangeMail, ExchangeMailFolder, ExchangeFilterMailReceivedAfter,
-		ExchangeFilterMailReceivedBefore, ExchangeFilterMailSender, ExchangeFilterMailSubject:
+		ExchangeFilterMailReceivedBefore, ExchangeFilterMailSender, ExchangeFilterMailSubject,
+		ExchangeFilterMailMessageID, ExchangeFilterMailFolderID:
 		return ExchangeMail
+
+	case ExchangeTask, ExchangeTaskFolder, ExchangeFilterTaskSubject:
+		return ExchangeTask
 	}
 
 	return ec
@@ -593,6 +740,9 @@ func (ec exchangeCategory) pathValues(repo, location path.Path) (map[categorizer
 	case ExchangeMail:
 		folderCat, itemCat = ExchangeMailFolder, ExchangeMail
 
+	case ExchangeTask:
+		folderCat, itemCat = ExchangeTaskFolder, ExchangeTask
+
 	default:
 		return map[categorizer]string{}, map[categorizer]string{}
 	}
@@ -681,7 +831,8 @@ func (s ExchangeScope) Get(cat exchangeCategory) []string {
 // sets a value by category to the scope.  Only intended for internal use.
 func (s ExchangeScope) set(cat exchangeCategory, v []string, opts ...option) ExchangeScope {
 	os := []option{}
-	if cat == ExchangeContactFolder || cat == ExchangeEventCalendar || cat == ExchangeMailFolder {
+	if cat == ExchangeContactFolder || cat == ExchangeEventCalendar ||
+		cat == ExchangeMailFolder || cat == ExchangeTaskFolder {
 		os = append(os, pathComparator())
 	}
 
@@ -701,12 +852,17 @@ func (s ExchangeScope) setDefaults() {
 	case ExchangeMailFolder:
 		s[ExchangeMail.String()] = passAny
 
+	case ExchangeTaskFolder:
+		s[ExchangeTask.String()] = passAny
+
 	case ExchangeUser:
 		s[ExchangeContactFolder.String()] = passAny
 		s[ExchangeContact.String()] = passAny
 		s[ExchangeEvent.String()] = passAny
 		s[ExchangeMailFolder.String()] = passAny
 		s[ExchangeMail.String()] = passAny
+		s[ExchangeTaskFolder.String()] = passAny
+		s[ExchangeTask.String()] = passAny
 	}
 }
 
@@ -729,6 +885,7 @@ func (s exchange) Reduce(
 			path.ContactsCategory: ExchangeContact,
 			path.EventsCategory:   ExchangeEvent,
 			path.EmailCategory:    ExchangeMail,
+			path.TasksCategory:    ExchangeTask,
 		},
 		errs)
 }
@@ -767,6 +924,12 @@ func (s ExchangeScope) matchesInfo(dii details.ItemInfo) bool {
 		i = info.Subject
 	case ExchangeFilterMailReceivedAfter, ExchangeFilterMailReceivedBefore:
 		i = common.FormatTime(info.Received)
+	case ExchangeFilterMailMessageID:
+		i = info.MessageID
+	case ExchangeFilterMailFolderID:
+		i = info.FolderID
+	case ExchangeFilterTaskSubject:
+		i = info.Subject
 	}
 
 	return s.Matches(filterCat, i)
@@ -783,6 +946,8 @@ func categoryFromItemType(pct details.ItemType) exchangeCategory {
 		return ExchangeMail
 	case details.ExchangeEvent:
 		return ExchangeEvent
+	case details.ExchangeTask:
+		return ExchangeTask
 	}
 
 	return ExchangeCategoryUnknown
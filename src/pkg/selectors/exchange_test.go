@@ -1,6 +1,7 @@
 package selectors
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -258,6 +259,59 @@ func (suite *ExchangeSelectorSuite) TestExchangeSelector_Include_EventCalendars(
 	)
 }
 
+func (suite *ExchangeSelectorSuite) TestExchangeSelector_Include_Tasks() {
+	t := suite.T()
+
+	const (
+		user = "user"
+		f1   = "f1"
+		t1   = "t1"
+		t2   = "t2"
+	)
+
+	sel := NewExchangeBackup([]string{user})
+	sel.Include(sel.Tasks([]string{f1}, []string{t1, t2}))
+	scopes := sel.Includes
+	require.Len(t, scopes, 1)
+
+	scopeMustHave(
+		t,
+		ExchangeScope(scopes[0]),
+		map[categorizer]string{
+			ExchangeTaskFolder: f1,
+			ExchangeTask:       join(t1, t2),
+		},
+	)
+
+	assert.Equal(t, sel.Scopes()[0].Category(), ExchangeTask)
+}
+
+func (suite *ExchangeSelectorSuite) TestExchangeSelector_Include_TaskFolders() {
+	t := suite.T()
+
+	const (
+		user = "user"
+		f1   = "f1"
+		f2   = "f2"
+	)
+
+	sel := NewExchangeBackup([]string{user})
+	sel.Include(sel.TaskFolders([]string{f1, f2}))
+	scopes := sel.Includes
+	require.Len(t, scopes, 1)
+
+	scopeMustHave(
+		t,
+		ExchangeScope(scopes[0]),
+		map[categorizer]string{
+			ExchangeTaskFolder: join(f1, f2),
+			ExchangeTask:       AnyTgt,
+		},
+	)
+
+	assert.Equal(t, sel.Scopes()[0].Category(), ExchangeTaskFolder)
+}
+
 func (suite *ExchangeSelectorSuite) TestExchangeSelector_Exclude_Mails() {
 	t := suite.T()
 
@@ -405,6 +459,9 @@ func (suite *ExchangeSelectorSuite) TestExchangeSelector_Exclude_AllData() {
 				},
 			)
 		}
+
+		// tasks are opt-in, and never part of all data.
+		assert.False(t, sc[scopeKeyCategory].Compare(ExchangeTaskFolder.String()))
 	}
 }
 
@@ -453,6 +510,9 @@ func (suite *ExchangeSelectorSuite) TestExchangeSelector_Include_AllData() {
 				},
 			)
 		}
+
+		// tasks are opt-in, and never part of all data.
+		assert.False(t, sc[scopeKeyCategory].Compare(ExchangeTaskFolder.String()))
 	}
 }
 
@@ -475,11 +535,83 @@ func (suite *ExchangeSelectorSuite) TestExchangeBackup_Scopes() {
 			case ExchangeMailFolder:
 				assert.True(t, sc.IsAny(ExchangeMail))
 				assert.True(t, sc.IsAny(ExchangeMailFolder))
+			case ExchangeTaskFolder:
+				assert.True(t, sc.IsAny(ExchangeTask))
+				assert.True(t, sc.IsAny(ExchangeTaskFolder))
 			}
 		})
 	}
 }
 
+func (suite *ExchangeSelectorSuite) TestExchangeBackup_IncludeFor() {
+	t := suite.T()
+
+	eb := NewExchangeBackup([]string{"owner"})
+	eb.IncludeFor("shared", eb.MailFolders([]string{"Shared/Projects"}, PrefixMatch()))
+	eb.IncludeFor("owner", eb.AllData())
+	eb.ExcludeFor("owner", eb.MailFolders([]string{"Junk"}))
+	eb.Exclude(eb.MailFolders([]string{"Deleted Items"}))
+
+	assert.Equal(t, []string{"owner", "shared"}, eb.DiscreteResourceOwners())
+	assert.Empty(t, eb.DiscreteOwner)
+
+	sels := eb.SplitByResourceOwner(nil)
+	require.Len(t, sels, 2)
+
+	owner, shared := sels[0], sels[1]
+
+	assert.Equal(t, "owner", owner.DiscreteOwner)
+	assert.Len(t, owner.Scopes(), 3)
+	assert.Len(t, owner.Excludes, 2)
+
+	assert.Equal(t, "shared", shared.DiscreteOwner)
+	require.Len(t, shared.Scopes(), 1)
+	assert.True(t, shared.Scopes()[0].Matches(ExchangeMailFolder, "Shared/Projects/2023"))
+	assert.False(t, shared.Scopes()[0].Matches(ExchangeMailFolder, "Inbox"))
+	assert.Len(t, shared.Excludes, 1)
+
+	// owners without scopes of their own have nothing to back up.
+	eb = NewExchangeBackup(Any())
+	eb.IncludeFor("shared", eb.MailFolders(Any()))
+
+	sels = eb.SplitByResourceOwner([]string{"shared", "other"})
+	require.Len(t, sels, 1)
+	assert.Equal(t, "shared", sels[0].DiscreteOwner)
+}
+
+func (suite *ExchangeSelectorSuite) TestExchangeRestore_Reduce_ownerScopes() {
+	var (
+		sharedProject = stubRepoRef(path.ExchangeService, path.EmailCategory, "shared", "Shared/Projects", "m1")
+		sharedInbox   = stubRepoRef(path.ExchangeService, path.EmailCategory, "shared", "Inbox", "m2")
+		ownerInbox    = stubRepoRef(path.ExchangeService, path.EmailCategory, "owner", "Inbox", "m3")
+		ownerJunk     = stubRepoRef(path.ExchangeService, path.EmailCategory, "owner", "Junk", "m4")
+		deets         = &details.Details{}
+	)
+
+	for _, ref := range []string{sharedProject, sharedInbox, ownerInbox, ownerJunk} {
+		deets.Entries = append(deets.Entries, details.DetailsEntry{
+			RepoRef: ref,
+			ItemInfo: details.ItemInfo{
+				Exchange: &details.ExchangeInfo{ItemType: details.ExchangeMail},
+			},
+		})
+	}
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	er := NewExchangeRestore(None())
+	er.IncludeFor("shared", er.MailFolders([]string{"Shared/Projects"}, PrefixMatch()))
+	er.IncludeFor("owner", er.AllData())
+	er.ExcludeFor("owner", er.MailFolders([]string{"Junk"}))
+
+	errs := fault.New(true)
+	result := er.Reduce(ctx, deets, errs)
+	require.NoError(suite.T(), errs.Err())
+
+	assert.ElementsMatch(suite.T(), []string{sharedProject, ownerInbox}, result.Paths())
+}
+
 func (suite *ExchangeSelectorSuite) TestExchangeScope_Category() {
 	table := []struct {
 		is     exchangeCategory
@@ -598,6 +730,8 @@ func (suite *ExchangeSelectorSuite) TestExchangeScope_MatchesInfo() {
 		organizer = "cooks@2many.smarf"
 		sender    = "smarf@2many.cooks"
 		subject   = "I have seen the fnords!"
+		messageID = "<fnords@ma.goo>"
+		folderID  = "AAMkAGZmNjNlYjI3"
 	)
 
 	var (
@@ -617,6 +751,8 @@ func (suite *ExchangeSelectorSuite) TestExchangeScope_MatchesInfo() {
 				Sender:      sender,
 				Subject:     subject,
 				Received:    now,
+				MessageID:   messageID,
+				FolderID:    folderID,
 			},
 		}
 	}
@@ -636,6 +772,25 @@ func (suite *ExchangeSelectorSuite) TestExchangeScope_MatchesInfo() {
 		{"mail with a different subject", details.ExchangeMail, es.MailSubject("fancy"), assert.False},
 		{"mail with the matching subject", details.ExchangeMail, es.MailSubject(subject), assert.True},
 		{"mail with a substring subject match", details.ExchangeMail, es.MailSubject(subject[5:9]), assert.True},
+		{"mail with any message id", details.ExchangeMail, es.MailMessageID(AnyTgt), assert.True},
+		{"mail with none message id", details.ExchangeMail, es.MailMessageID(NoneTgt), assert.False},
+		{"mail with a different message id", details.ExchangeMail, es.MailMessageID("<other@ma.goo>"), assert.False},
+		{"mail with the matching message id", details.ExchangeMail, es.MailMessageID(messageID), assert.True},
+		{"mail with an unbracketed message id", details.ExchangeMail, es.MailMessageID("fnords@ma.goo"), assert.True},
+		{"mail with a substring message id", details.ExchangeMail, es.MailMessageID("fnords"), assert.False},
+		{"mail in any folder id", details.ExchangeMail, es.MailFolderID(Any()), assert.True},
+		{"mail in none folder id", details.ExchangeMail, es.MailFolderID(None()), assert.False},
+		{"mail in a different folder id", details.ExchangeMail, es.MailFolderID([]string{"other"}), assert.False},
+		{"mail in the matching folder id", details.ExchangeMail, es.MailFolderID([]string{folderID}), assert.True},
+		{"mail in one of the folder ids", details.ExchangeMail, es.MailFolderID([]string{"other", folderID}), assert.True},
+		{"mail in a substring folder id", details.ExchangeMail, es.MailFolderID([]string{folderID[:4]}), assert.False},
+		{
+			"mail in a folder id differing by case",
+			details.ExchangeMail,
+			es.MailFolderID([]string{strings.ToLower(folderID)}),
+			assert.False,
+		},
+		{"event in the matching folder id", details.ExchangeEvent, es.MailFolderID([]string{folderID}), assert.False},
 		{"mail received after the epoch", details.ExchangeMail, es.MailReceivedAfter(common.FormatTime(epoch)), assert.True},
 		{"mail received after now", details.ExchangeMail, es.MailReceivedAfter(common.FormatTime(now)), assert.False},
 		{
@@ -691,6 +846,11 @@ func (suite *ExchangeSelectorSuite) TestExchangeScope_MatchesInfo() {
 		{"contact with a different name", details.ExchangeContact, es.ContactName("blarps"), assert.False},
 		{"contact with the same name", details.ExchangeContact, es.ContactName(name), assert.True},
 		{"contact with a subname search", details.ExchangeContact, es.ContactName(name[2:5]), assert.True},
+		{"task with any subject", details.ExchangeTask, es.TaskSubject(AnyTgt), assert.True},
+		{"task with none subject", details.ExchangeTask, es.TaskSubject(NoneTgt), assert.False},
+		{"task with a different subject", details.ExchangeTask, es.TaskSubject("fancy"), assert.False},
+		{"task with the matching subject", details.ExchangeTask, es.TaskSubject(subject), assert.True},
+		{"mail with a task subject filter", details.ExchangeMail, es.TaskSubject(subject), assert.False},
 	}
 	for _, test := range table {
 		suite.T().Run(test.name, func(t *testing.T) {
@@ -1455,6 +1615,9 @@ func (suite *ExchangeSelectorSuite) TestExchangeCategory_leafCat() {
 		{ExchangeMail, ExchangeMail},
 		{ExchangeContactFolder, ExchangeContact},
 		{ExchangeEvent, ExchangeEvent},
+		{ExchangeTaskFolder, ExchangeTask},
+		{ExchangeTask, ExchangeTask},
+		{ExchangeFilterTaskSubject, ExchangeTask},
 	}
 	for _, test := range table {
 		suite.T().Run(test.cat.String(), func(t *testing.T) {
@@ -1481,6 +1644,11 @@ func (suite *ExchangeSelectorSuite) TestExchangeCategory_PathValues() {
 		ExchangeMailFolder: mailPath.Folder(false),
 		ExchangeMail:       mailPath.Item(),
 	}
+	taskPath := stubPath(t, "user", []string{"tfolder", "taskitem"}, path.TasksCategory)
+	taskMap := map[categorizer]string{
+		ExchangeTaskFolder: taskPath.Folder(false),
+		ExchangeTask:       taskPath.Item(),
+	}
 
 	table := []struct {
 		cat    exchangeCategory
@@ -1490,6 +1658,7 @@ func (suite *ExchangeSelectorSuite) TestExchangeCategory_PathValues() {
 		{ExchangeContact, contactPath, contactMap},
 		{ExchangeEvent, eventPath, eventMap},
 		{ExchangeMail, mailPath, mailMap},
+		{ExchangeTask, taskPath, taskMap},
 	}
 	for _, test := range table {
 		suite.T().Run(string(test.cat), func(t *testing.T) {
@@ -1504,6 +1673,7 @@ func (suite *ExchangeSelectorSuite) TestExchangeCategory_PathKeys() {
 	contact := []categorizer{ExchangeContactFolder, ExchangeContact}
 	event := []categorizer{ExchangeEventCalendar, ExchangeEvent}
 	mail := []categorizer{ExchangeMailFolder, ExchangeMail}
+	task := []categorizer{ExchangeTaskFolder, ExchangeTask}
 	user := []categorizer{ExchangeUser}
 
 	var empty []categorizer
@@ -1516,6 +1686,7 @@ func (suite *ExchangeSelectorSuite) TestExchangeCategory_PathKeys() {
 		{ExchangeContact, contact},
 		{ExchangeEvent, event},
 		{ExchangeMail, mail},
+		{ExchangeTask, task},
 		{ExchangeUser, user},
 	}
 	for _, test := range table {
@@ -1546,6 +1717,11 @@ func (suite *ExchangeSelectorSuite) TestCategoryFromItemType() {
 			input:  details.ExchangeMail,
 			expect: ExchangeMail,
 		},
+		{
+			name:   "task",
+			input:  details.ExchangeTask,
+			expect: ExchangeTask,
+		},
 		{
 			name:   "unknown",
 			input:  details.UnknownType,
@@ -1577,12 +1753,17 @@ func (suite *ExchangeSelectorSuite) TestCategory_PathType() {
 		{ExchangeFilterMailSubject, path.EmailCategory},
 		{ExchangeFilterMailReceivedAfter, path.EmailCategory},
 		{ExchangeFilterMailReceivedBefore, path.EmailCategory},
+		{ExchangeFilterMailMessageID, path.EmailCategory},
+		{ExchangeFilterMailFolderID, path.EmailCategory},
 		{ExchangeFilterContactName, path.ContactsCategory},
 		{ExchangeFilterEventOrganizer, path.EventsCategory},
 		{ExchangeFilterEventRecurs, path.EventsCategory},
 		{ExchangeFilterEventStartsAfter, path.EventsCategory},
 		{ExchangeFilterEventStartsBefore, path.EventsCategory},
 		{ExchangeFilterEventSubject, path.EventsCategory},
+		{ExchangeTask, path.TasksCategory},
+		{ExchangeTaskFolder, path.TasksCategory},
+		{ExchangeFilterTaskSubject, path.TasksCategory},
 	}
 	for _, test := range table {
 		suite.T().Run(test.cat.String(), func(t *testing.T) {
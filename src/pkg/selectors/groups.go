@@ -0,0 +1,125 @@
+package selectors
+
+import (
+	"github.com/alcionai/corso/src/pkg/backup/details"
+)
+
+// GroupOp is the boolean operator which combines the members of a Group.
+type GroupOp string
+
+const (
+	// GroupAnd matches if all members match.  An empty group matches.
+	GroupAnd GroupOp = "and"
+	// GroupOr matches if any member matches.  An empty group fails.
+	GroupOr GroupOp = "or"
+	// GroupNot matches if no member matches.
+	GroupNot GroupOp = "not"
+)
+
+// Group composes scopes with boolean logic, for conditions which can't be
+// expressed by the selector's inclusions, filters, and exclusions.  The
+// members of a group are its scopes and its nested groups.
+//
+// Ex: (modified after X AND created by u1) OR folder "Contracts":
+//
+//	sel.Match(selectors.Or(
+//		selectors.And(
+//			selectors.Scopes(sel.ModifiedAfter(x)),
+//			selectors.Scopes(sel.CreatedBy("u1"))),
+//		selectors.Scopes(sel.Folders([]string{"Contracts"}))))
+type Group struct {
+	Op     GroupOp `json:"op"`
+	Scopes []scope `json:"scopes,omitempty"`
+	Groups []Group `json:"groups,omitempty"`
+}
+
+// Scopes produces a group which matches data matching ANY of the scopes.
+func Scopes[T scopeT](scopes ...[]T) Group {
+	return Group{
+		Op:     GroupOr,
+		Scopes: appendScopes([]scope{}, scopes...),
+	}
+}
+
+// And produces a group which matches data matching ALL of the groups.
+func And(groups ...Group) Group {
+	return Group{Op: GroupAnd, Groups: groups}
+}
+
+// Or produces a group which matches data matching ANY of the groups.
+func Or(groups ...Group) Group {
+	return Group{Op: GroupOr, Groups: groups}
+}
+
+// Not produces a group which matches data that doesn't match the group.
+func Not(group Group) Group {
+	return Group{Op: GroupNot, Groups: []Group{group}}
+}
+
+// matchesGroups returns true if the entry matches every group.
+func matchesGroups[T scopeT, C categoryT](
+	groups []Group,
+	cat C,
+	repoValues, locationValues map[categorizer]string,
+	entry details.DetailsEntry,
+) bool {
+	for _, g := range groups {
+		if !matchesGroup[T](g, cat, repoValues, locationValues, entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesGroup[T scopeT, C categoryT](
+	g Group,
+	cat C,
+	repoValues, locationValues map[categorizer]string,
+	entry details.DetailsEntry,
+) bool {
+	var matched, total int
+
+	for _, sc := range g.Scopes {
+		total++
+
+		if matchesGroupScope(T(sc), cat, repoValues, locationValues, entry) {
+			matched++
+		}
+	}
+
+	for _, ng := range g.Groups {
+		total++
+
+		if matchesGroup[T](ng, cat, repoValues, locationValues, entry) {
+			matched++
+		}
+	}
+
+	switch g.Op {
+	case GroupAnd:
+		return matched == total
+	case GroupOr:
+		return matched > 0
+	case GroupNot:
+		return matched == 0
+	default:
+		return false
+	}
+}
+
+// matchesGroupScope compares the entry to a single scope within a group.
+// Like filters, info-filter scopes apply to every data category, while
+// other scopes only match entries of their own category.
+func matchesGroupScope[T scopeT, C categoryT](
+	sc T,
+	cat C,
+	repoValues, locationValues map[categorizer]string,
+	entry details.DetailsEntry,
+) bool {
+	if len(getFilterCategory(sc)) == 0 && !typeAndCategoryMatches(cat, sc.categorizer()) {
+		return false
+	}
+
+	return matchesEntry(sc, cat, repoValues, locationValues, entry)
+}
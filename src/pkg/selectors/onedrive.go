@@ -118,6 +118,16 @@ func (s oneDrive) PathCategories() selectorPathCategories {
 	}
 }
 
+// FilterScopes retrieves the list of filter scopes in the selector.
+func (s *oneDrive) FilterScopes() []OneDriveScope {
+	return filterScopes[OneDriveScope](s.Selector)
+}
+
+// ExcludeScopes retrieves the list of exclusion scopes in the selector.
+func (s *oneDrive) ExcludeScopes() []OneDriveScope {
+	return excludeScopes[OneDriveScope](s.Selector)
+}
+
 // -------------------
 // Scope Factories
 
@@ -177,6 +187,16 @@ func (s *oneDrive) Filter(scopes ...[]OneDriveScope) {
 	s.Filters = appendScopes(s.Filters, scopes...)
 }
 
+// Match appends the provided groups to the selector's group set.
+// Data is retained if it matches ALL groups.  Groups compose scopes with
+// boolean logic, for conditions which the inclusions, filters, and
+// exclusions can't express.
+// Ex: Or(And(Scopes(s1), Scopes(s2)), Scopes(s3)) => retains data which
+// matches both s1 and s2, or which matches s3.
+func (s *oneDrive) Match(groups ...Group) {
+	s.Groups = append(s.Groups, groups...)
+}
+
 // Scopes retrieves the list of oneDriveScopes in the selector.
 func (s *oneDrive) Scopes() []OneDriveScope {
 	return scopes[OneDriveScope](s.Selector)
@@ -279,6 +299,58 @@ func (s *oneDrive) ModifiedAfter(timeStrings string) []OneDriveScope {
 	}
 }
 
+// CreatedBy produces a OneDrive item creator filter scope.
+// Matches any item whose creator's email contains the provided string.
+// If the input equals selectors.Any, the scope will match all creators.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *oneDrive) CreatedBy(user string) []OneDriveScope {
+	return []OneDriveScope{
+		makeFilterScope[OneDriveScope](
+			OneDriveItem,
+			FileFilterCreatedBy,
+			[]string{user},
+			wrapFilter(filters.In)),
+	}
+}
+
+// ContentType produces a OneDrive item content type filter scope.
+// Matches any item whose MIME type contains the provided string (ex: "pdf").
+// If the input equals selectors.Any, the scope will match all content types.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *oneDrive) ContentType(mimeType string) []OneDriveScope {
+	return []OneDriveScope{
+		makeFilterScope[OneDriveScope](
+			OneDriveItem,
+			FileFilterContentType,
+			[]string{mimeType},
+			wrapFilter(filters.In)),
+	}
+}
+
+// SensitivityLabel produces a OneDrive item sensitivity label filter scope.
+// Matches any item whose sensitivity label equals the provided label.
+// If the input equals selectors.Any, the scope will match all labels.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *oneDrive) SensitivityLabel(label string) []OneDriveScope {
+	return []OneDriveScope{
+		makeFilterScope[OneDriveScope](
+			OneDriveItem,
+			FileFilterSensitivityLabel,
+			[]string{label},
+			wrapFilter(filters.Equal)),
+	}
+}
+
+// SyncArtifacts produces the built-in set of scopes matching the files left
+// behind by office and the OneDrive sync client: office lock files
+// (~$Report.docx), temp files (*.tmp), and sync-conflict copies
+// (Report-DESKTOP-1A2B3C4.docx).  Exclude the scopes to leave the artifacts out of
+// backups and restores.
+// Ex: sel.Exclude(sel.SyncArtifacts())
+func (s *oneDrive) SyncArtifacts() []OneDriveScope {
+	return syncArtifactScopes[OneDriveScope](OneDriveItem, FileFilterItemName)
+}
+
 // ModifiedBefore produces a OneDrive item modified-before filter scope.
 // Matches any item where the modified time is before the timestring.
 // If the input equals selectors.Any, the scope will match all times.
@@ -312,10 +384,14 @@ const (
 	OneDriveFolder oneDriveCategory = "OneDriveFolder"
 
 	// filterable topics identified by OneDrive
-	FileFilterCreatedAfter   oneDriveCategory = "FileFilterCreatedAfter"
-	FileFilterCreatedBefore  oneDriveCategory = "FileFilterCreatedBefore"
-	FileFilterModifiedAfter  oneDriveCategory = "FileFilterModifiedAfter"
-	FileFilterModifiedBefore oneDriveCategory = "FileFilterModifiedBefore"
+	FileFilterCreatedAfter     oneDriveCategory = "FileFilterCreatedAfter"
+	FileFilterCreatedBefore    oneDriveCategory = "FileFilterCreatedBefore"
+	FileFilterModifiedAfter    oneDriveCategory = "FileFilterModifiedAfter"
+	FileFilterModifiedBefore   oneDriveCategory = "FileFilterModifiedBefore"
+	FileFilterCreatedBy        oneDriveCategory = "FileFilterCreatedBy"
+	FileFilterContentType      oneDriveCategory = "FileFilterContentType"
+	FileFilterSensitivityLabel oneDriveCategory = "FileFilterSensitivityLabel"
+	FileFilterItemName         oneDriveCategory = "FileFilterItemName"
 )
 
 // oneDriveLeafProperties describes common metadata of the leaf categories
@@ -343,7 +419,9 @@ func (c oneDriveCategory) leafCat() categorizer {
 	switch c {
 	case OneDriveFolder, OneDriveItem,
 		FileFilterCreatedAfter, FileFilterCreatedBefore,
-		FileFilterModifiedAfter, FileFilterModifiedBefore:
+		FileFilterModifiedAfter, FileFilterModifiedBefore,
+		FileFilterCreatedBy, FileFilterContentType, FileFilterSensitivityLabel,
+		FileFilterItemName:
 		return OneDriveItem
 	}
 
@@ -527,6 +605,14 @@ func (s OneDriveScope) matchesInfo(dii details.ItemInfo) bool {
 		i = common.FormatTime(info.Created)
 	case FileFilterModifiedAfter, FileFilterModifiedBefore:
 		i = common.FormatTime(info.Modified)
+	case FileFilterCreatedBy:
+		i = info.Owner
+	case FileFilterContentType:
+		i = info.ContentType
+	case FileFilterSensitivityLabel:
+		i = info.SensitivityLabel
+	case FileFilterItemName:
+		i = info.ItemName
 	}
 
 	return s.Matches(filterCat, i)
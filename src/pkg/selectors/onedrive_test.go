@@ -250,6 +250,70 @@ func (suite *OneDriveSelectorSuite) TestOneDriveRestore_Reduce() {
 	}
 }
 
+func (suite *OneDriveSelectorSuite) TestOneDriveRestore_ReduceSyncArtifacts() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t     = suite.T()
+		entry = func(name string) details.DetailsEntry {
+			return details.DetailsEntry{
+				RepoRef: stubRepoRef(path.OneDriveService, path.FilesCategory, "uid", "drive/driveID/root:/folder", name),
+				ItemInfo: details.ItemInfo{
+					OneDrive: &details.OneDriveInfo{
+						ItemType: details.OneDriveItem,
+						ItemName: name,
+					},
+				},
+			}
+		}
+		report = entry("report")
+		deets  = &details.Details{
+			DetailsModel: details.DetailsModel{
+				Entries: []details.DetailsEntry{
+					report,
+					entry("~$report"),
+					entry("report-DESKTOP-1A2B3C4.docx"),
+					entry("save.tmp"),
+				},
+			},
+		}
+	)
+
+	sel := NewOneDriveRestore(Any())
+	sel.Include(sel.AllData())
+	sel.Exclude(sel.SyncArtifacts())
+
+	results := sel.Reduce(ctx, deets, fault.New(true))
+	assert.Equal(t, []string{report.RepoRef}, results.Paths())
+}
+
+func (suite *OneDriveSelectorSuite) TestIsSyncArtifact() {
+	table := []struct {
+		name   string
+		expect assert.BoolAssertionFunc
+	}{
+		{"Report.docx", assert.False},
+		{"~$Report.docx", assert.True},
+		{".~lock.Budget.xlsx#", assert.True},
+		{"Report-DESKTOP-1A2B3C4.docx", assert.True},
+		{"Report-LAPTOP-1A2B3C4D-2.docx", assert.True},
+		{"Budget-desktop-q1w2e3r", assert.True},
+		{"Report-conflict.docx", assert.False},
+		{"Merge-conflict-notes.md", assert.False},
+		{"DESKTOP-1A2B3C4.docx", assert.False},
+		{"Report-DESKTOP-1A2B3C4.docx.bak", assert.False},
+		{"download.TMP", assert.True},
+		{"tmp notes.txt", assert.False},
+		{"", assert.False},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expect(suite.T(), IsSyncArtifact(test.name))
+		})
+	}
+}
+
 func (suite *OneDriveSelectorSuite) TestOneDriveCategory_PathValues() {
 	t := suite.T()
 
@@ -278,13 +342,15 @@ func (suite *OneDriveSelectorSuite) TestOneDriveScope_MatchesInfo() {
 
 	itemInfo := details.ItemInfo{
 		OneDrive: &details.OneDriveInfo{
-			ItemType:   details.OneDriveItem,
-			ParentPath: "folder1/folder2",
-			ItemName:   "file1",
-			Size:       10,
-			Owner:      "user@email.com",
-			Created:    now,
-			Modified:   now,
+			ItemType:         details.OneDriveItem,
+			ParentPath:       "folder1/folder2",
+			ItemName:         "file1",
+			Size:             10,
+			Owner:            "user@email.com",
+			Created:          now,
+			Modified:         now,
+			ContentType:      "application/pdf",
+			SensitivityLabel: "Confidential",
 		},
 	}
 
@@ -305,6 +371,16 @@ func (suite *OneDriveSelectorSuite) TestOneDriveScope_MatchesInfo() {
 		{"file modified before future", ods.ModifiedBefore(common.FormatTime(future)), assert.True},
 		{"file modified before now", ods.ModifiedBefore(common.FormatTime(now)), assert.False},
 		{"file modified before epoch", ods.ModifiedBefore(common.FormatTime(now)), assert.False},
+		{"file created by user", ods.CreatedBy("user@email.com"), assert.True},
+		{"file created by partial email", ods.CreatedBy("user@"), assert.True},
+		{"file created by any", ods.CreatedBy(AnyTgt), assert.True},
+		{"file created by other user", ods.CreatedBy("other@email.com"), assert.False},
+		{"file content type", ods.ContentType("application/pdf"), assert.True},
+		{"file partial content type", ods.ContentType("pdf"), assert.True},
+		{"file other content type", ods.ContentType("text/plain"), assert.False},
+		{"file sensitivity label", ods.SensitivityLabel("Confidential"), assert.True},
+		{"file sensitivity label any", ods.SensitivityLabel(AnyTgt), assert.True},
+		{"file other sensitivity label", ods.SensitivityLabel("Public"), assert.False},
 	}
 	for _, test := range table {
 		suite.T().Run(test.name, func(t *testing.T) {
@@ -329,6 +405,10 @@ func (suite *OneDriveSelectorSuite) TestCategory_PathType() {
 		{FileFilterCreatedBefore, path.FilesCategory},
 		{FileFilterModifiedAfter, path.FilesCategory},
 		{FileFilterModifiedBefore, path.FilesCategory},
+		{FileFilterCreatedBy, path.FilesCategory},
+		{FileFilterContentType, path.FilesCategory},
+		{FileFilterSensitivityLabel, path.FilesCategory},
+		{FileFilterItemName, path.FilesCategory},
 	}
 	for _, test := range table {
 		suite.T().Run(test.cat.String(), func(t *testing.T) {
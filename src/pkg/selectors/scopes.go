@@ -193,6 +193,38 @@ func makeFilterScope[T scopeT](
 	}
 }
 
+// syncConflictPattern matches the copies the OneDrive sync client makes
+// when it can't merge a conflicting change: the name of the computer that
+// made the change gets appended to the file name, and numbered if the copy
+// already exists.  Only windows' generated computer names (DESKTOP-1A2B3C4,
+// LAPTOP-1A2B3C4D) are matched, since a user-chosen computer name can't be
+// told apart from a legitimate file name.
+// ex: Report-DESKTOP-1A2B3C4.docx, Report-LAPTOP-1A2B3C4D-2.docx
+const syncConflictPattern = `.+-(desktop|laptop)-[0-9a-z]{7,8}(-[0-9]+)?(\.[^.]+)?`
+
+// syncArtifactScopes produces filter scopes on the item name that match
+// office lock files, temp files, and sync-conflict copies.
+func syncArtifactScopes[T scopeT](cat, nameCat categorizer) []T {
+	return []T{
+		makeFilterScope[T](cat, nameCat, []string{"~$"}, wrapFilter(filters.Prefix)),
+		makeFilterScope[T](cat, nameCat, []string{".~lock."}, wrapFilter(filters.Prefix)),
+		makeFilterScope[T](cat, nameCat, []string{".tmp"}, wrapFilter(filters.Suffix)),
+		makeFilterScope[T](cat, nameCat, []string{syncConflictPattern}, wrapFilter(filters.Pattern)),
+	}
+}
+
+// IsSyncArtifact returns true if the file name matches the built-in
+// SyncArtifacts scopes.
+func IsSyncArtifact(name string) bool {
+	for _, sc := range syncArtifactScopes[OneDriveScope](OneDriveItem, FileFilterItemName) {
+		if sc.Matches(FileFilterItemName, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ---------------------------------------------------------------------------
 // scope funcs
 // ---------------------------------------------------------------------------
@@ -308,6 +340,12 @@ func reduce[T scopeT, C categoryT](
 	filts := scopesByCategory[T](s.Filters, dataCategories, true)
 	incls := scopesByCategory[T](s.Includes, dataCategories, false)
 
+	// groups act as the inclusion when there are no inclusions or filters.
+	groupsOnly := len(s.Includes)+len(s.Filters) == 0 && len(s.Groups) > 0
+
+	// scopes restricted to a resource owner only apply to that owner's entries.
+	ownerScoped := hasOwnerScopes(s)
+
 	ents := []details.DetailsEntry{}
 
 	// for each entry, compare that entry against the scopes of the same data type
@@ -355,14 +393,28 @@ func reduce[T scopeT, C categoryT](
 
 		e, f, i := excls[dc], filts[dc], incls[dc]
 
-		// at least one filter or inclusion must be presentt
-		if len(f)+len(i) == 0 {
+		if ownerScoped {
+			ro := repoPath.ResourceOwner()
+			e, f, i = scopesForOwner(e, ro), scopesForOwner(f, ro), scopesForOwner(i, ro)
+		}
+
+		// at least one filter or inclusion must be presentt, unless groups are
+		// the only inclusion in the selector.
+		if len(f)+len(i) == 0 && !groupsOnly {
 			continue
 		}
 
 		rv, lv := dc.pathValues(repoPath, locationPath)
 
+		if !matchesGroups[T](s.Groups, dc, rv, lv, *ent) {
+			continue
+		}
+
 		passed := passes(dc, rv, lv, *ent, e, f, i)
+		if groupsOnly {
+			passed = !matchesAny(dc, rv, lv, *ent, e)
+		}
+
 		if passed {
 			ents = append(ents, *ent)
 		}
@@ -442,13 +494,23 @@ func passes[T scopeT, C categoryT](
 	}
 
 	// any matching exclusion means failure
-	for _, exc := range excs {
-		if matchesEntry(exc, cat, repoValues, locationValues, entry) {
-			return false
+	return !matchesAny(cat, repoValues, locationValues, entry, excs)
+}
+
+// matchesAny returns true if the entry matches any of the scopes.
+func matchesAny[T scopeT, C categoryT](
+	cat C,
+	repoValues, locationValues map[categorizer]string,
+	entry details.DetailsEntry,
+	scopes []T,
+) bool {
+	for _, sc := range scopes {
+		if matchesEntry(sc, cat, repoValues, locationValues, entry) {
+			return true
 		}
 	}
 
-	return true
+	return false
 }
 
 // matchesEntry determines whether the category and scope require a path
@@ -41,6 +41,9 @@ const (
 	scopeKeyCategory   = "category"
 	scopeKeyInfoFilter = "info_filter"
 	scopeKeyDataType   = "type"
+	// scopeKeyResourceOwner restricts the scope to a single resource owner.
+	// Scopes without it apply to every resource owner in the selector.
+	scopeKeyResourceOwner = "resource_owner"
 )
 
 // The granularity exprerssed by the scope.  Groups imply non-item granularity,
@@ -99,6 +102,11 @@ type Selector struct {
 	// A record of the resource owners matched by this selector.
 	ResourceOwners filters.Filter `json:"resourceOwners,omitempty"`
 
+	// The IDs or display names of AAD groups whose members are also
+	// resource owners of the selector.  Membership is resolved each time
+	// the selector is run, using ResolveOwnerGroups.
+	OwnerGroups []string `json:"ownerGroups,omitempty"`
+
 	// The single resource owner being observed by the selector.
 	// Selectors are constructed by passing in a list of ResourceOwners,
 	// and those owners represent the "total" data that should be operated
@@ -121,6 +129,11 @@ type Selector struct {
 	// A slice of inclusion scopes.  Comparators must match either one of these,
 	// or all filters, to be included.
 	Includes []scope `json:"includes,omitempty"`
+	// A slice of boolean scope groups.  Data must match ALL groups, in
+	// addition to the inclusions and filters.  With no inclusions or
+	// filters, the groups act as the inclusion.  Groups are only applied
+	// when reducing backup details, such as during restores.
+	Groups []Group `json:"groups,omitempty"`
 }
 
 // helper for specific selector instance constructors.
@@ -146,6 +159,52 @@ func (s Selector) DiscreteResourceOwners() []string {
 	return split(s.ResourceOwners.Target)
 }
 
+// IncludeOwnerGroups adds the members of the AAD groups, identified by ID
+// or display name, to the resource owners of the selector.  The groups
+// are recorded instead of their members, so that the selector follows
+// changes to the groups' membership.  The members must be resolved with
+// ResolveOwnerGroups before the selector is split by resource owner.
+func (s *Selector) IncludeOwnerGroups(groups ...string) {
+	s.OwnerGroups = append(s.OwnerGroups, groups...)
+}
+
+// ResolveOwnerGroups produces a copy of the selector whose resource owners
+// include the current members of its owner groups.  Selectors that already
+// include any resource owner are unchanged.
+func (s Selector) ResolveOwnerGroups(members []string) Selector {
+	if isAnyResourceOwner(s) || len(members) == 0 {
+		return s
+	}
+
+	var (
+		owners = []string{}
+		seen   = map[string]struct{}{}
+	)
+
+	if !isNoneResourceOwner(s) {
+		owners = split(s.ResourceOwners.Target)
+	}
+
+	owners = append(owners, members...)
+	deduped := make([]string, 0, len(owners))
+
+	for _, o := range owners {
+		if _, ok := seen[o]; !ok {
+			seen[o] = struct{}{}
+			deduped = append(deduped, o)
+		}
+	}
+
+	s.ResourceOwners = filterize(scopeConfig{}, deduped...)
+	s.DiscreteOwner = ""
+
+	if len(deduped) == 1 {
+		s.DiscreteOwner = deduped[0]
+	}
+
+	return s
+}
+
 // isAnyResourceOwner returns true if the selector includes all resource owners.
 func isAnyResourceOwner(s Selector) bool {
 	return s.ResourceOwners.Comparator == filters.Passes
@@ -179,17 +238,63 @@ func splitByResourceOwner[T scopeT, C categoryT](s Selector, allOwners []string,
 		targets = split(s.ResourceOwners.Target)
 	}
 
-	ss := make([]Selector, 0, len(targets))
+	var (
+		ss       = make([]Selector, 0, len(targets))
+		selects  = len(s.Includes)+len(s.Filters) > 0
+		ownedSel = hasOwnerScopes(s)
+	)
 
 	for _, ro := range targets {
 		c := s
 		c.DiscreteOwner = ro
+
+		if ownedSel {
+			c.Includes = scopesForOwner(s.Includes, ro)
+			c.Filters = scopesForOwner(s.Filters, ro)
+			c.Excludes = scopesForOwner(s.Excludes, ro)
+
+			// owners whose scopes all belong to other owners have
+			// nothing to select.
+			if selects && len(c.Includes)+len(c.Filters) == 0 {
+				continue
+			}
+		}
+
 		ss = append(ss, c)
 	}
 
 	return ss
 }
 
+// addResourceOwner includes the owner in the selector's resource owners.
+// Selectors that already include any resource owner are unchanged.
+func (s *Selector) addResourceOwner(owner string) {
+	if isAnyResourceOwner(*s) {
+		return
+	}
+
+	owners := []string{}
+
+	if !isNoneResourceOwner(*s) {
+		owners = split(s.ResourceOwners.Target)
+	}
+
+	for _, o := range owners {
+		if o == owner {
+			return
+		}
+	}
+
+	owners = append(owners, owner)
+
+	s.ResourceOwners = filterize(scopeConfig{}, owners...)
+	s.DiscreteOwner = ""
+
+	if len(owners) == 1 {
+		s.DiscreteOwner = owners[0]
+	}
+}
+
 func (s Selector) String() string {
 	bs, err := json.Marshal(s)
 	if err != nil {
@@ -199,6 +304,18 @@ func (s Selector) String() string {
 	return string(bs)
 }
 
+// appendOwnerScopes restricts each scope to the resource owner before
+// appending it, as with appendScopes.
+func appendOwnerScopes[T scopeT](to []scope, owner string, scopes ...[]T) []scope {
+	for _, scopeSl := range scopes {
+		for _, s := range scopeSl {
+			s[scopeKeyResourceOwner] = filters.Identity(owner)
+		}
+	}
+
+	return appendScopes(to, scopes...)
+}
+
 // appendScopes iterates through each scope in the list of scope slices,
 // calling setDefaults() to ensure it is completely populated, and appends
 // those scopes to the `to` slice.
@@ -228,6 +345,28 @@ func scopes[T scopeT](s Selector) []T {
 	return scopes
 }
 
+// filterScopes retrieves the list of filter scopes in the selector.
+func filterScopes[T scopeT](s Selector) []T {
+	scopes := []T{}
+
+	for _, v := range s.Filters {
+		scopes = append(scopes, T(v))
+	}
+
+	return scopes
+}
+
+// excludeScopes retrieves the list of exclusion scopes in the selector.
+func excludeScopes[T scopeT](s Selector) []T {
+	scopes := []T{}
+
+	for _, v := range s.Excludes {
+		scopes = append(scopes, T(v))
+	}
+
+	return scopes
+}
+
 // Returns the path.ServiceType matching the selector service.
 func (s Selector) PathService() path.ServiceType {
 	return serviceToPathType[s.Service]
@@ -311,6 +450,41 @@ func resourceOwnersIn(s []scope, rootCat string) []string {
 	return rs
 }
 
+// scopeOwner returns the resource owner that the scope is restricted to,
+// or an empty string if the scope applies to every resource owner.
+func scopeOwner[T ~map[string]filters.Filter](sc T) string {
+	return sc[scopeKeyResourceOwner].Target
+}
+
+// hasOwnerScopes returns true if any scope in the selector is restricted
+// to a resource owner.
+func hasOwnerScopes(s Selector) bool {
+	for _, ss := range [][]scope{s.Includes, s.Filters, s.Excludes} {
+		for _, sc := range ss {
+			if len(scopeOwner(sc)) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// scopesForOwner produces the scopes that apply to the resource owner:
+// those restricted to that owner, and those that aren't restricted at all.
+// Owners are compared case-insensitively.
+func scopesForOwner[T ~map[string]filters.Filter](ss []T, owner string) []T {
+	rs := make([]T, 0, len(ss))
+
+	for _, sc := range ss {
+		if so := scopeOwner(sc); len(so) == 0 || strings.EqualFold(so, owner) {
+			rs = append(rs, sc)
+		}
+	}
+
+	return rs
+}
+
 // produces the discrete set of path categories in the slice of scopes.
 func pathCategoriesIn[T scopeT, C categoryT](ss []scope) []path.CategoryType {
 	rm := map[path.CategoryType]struct{}{}
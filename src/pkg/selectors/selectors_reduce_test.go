@@ -1,10 +1,12 @@
 package selectors_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/alcionai/corso/src/internal/common"
@@ -261,6 +263,70 @@ func (suite *SelectorReduceSuite) TestReduce() {
 			},
 			expected: testdata.ExchangeEventsItems,
 		},
+		{
+			name: "ExchangeMailGroups",
+			selFunc: func() selectors.Reducer {
+				sel := selectors.NewExchangeRestore(selectors.Any())
+				sel.Match(selectors.Or(
+					selectors.And(
+						selectors.Scopes(sel.MailSender("a-person")),
+						selectors.Not(selectors.Scopes(sel.MailSubject("foo")))),
+					selectors.Scopes(sel.MailSubject("baz"))))
+
+				return sel
+			},
+			expected: []details.DetailsEntry{
+				testdata.ExchangeEmailItems[1],
+				testdata.ExchangeEmailItems[2],
+			},
+		},
+		{
+			name: "ExchangeMailGroupsWithInclusion",
+			selFunc: func() selectors.Reducer {
+				sel := selectors.NewExchangeRestore(selectors.Any())
+				sel.Include(sel.Mails(selectors.Any(), selectors.Any()))
+				sel.Match(selectors.Not(selectors.Scopes(sel.MailSender("another-person"))))
+
+				return sel
+			},
+			expected: []details.DetailsEntry{
+				testdata.ExchangeEmailItems[0],
+				testdata.ExchangeEmailItems[1],
+			},
+		},
+		{
+			name: "ExchangeMailGroupsWithExclusion",
+			selFunc: func() selectors.Reducer {
+				sel := selectors.NewExchangeRestore(selectors.Any())
+				sel.Match(selectors.Scopes(sel.MailSender("a-person")))
+				sel.Exclude(sel.Mails(
+					selectors.Any(),
+					[]string{testdata.ExchangeEmailItemPath2.ShortRef()},
+				))
+
+				return sel
+			},
+			expected: []details.DetailsEntry{testdata.ExchangeEmailItems[0]},
+		},
+		{
+			name: "ExchangeGroupsAcrossCategories",
+			selFunc: func() selectors.Reducer {
+				sel := selectors.NewExchangeRestore(selectors.Any())
+				sel.Match(selectors.Or(
+					selectors.Scopes(sel.EventCalendars(
+						[]string{testdata.ExchangeEventsBasePath.Folder(false)},
+					)),
+					selectors.Scopes(sel.MailFolders(
+						[]string{testdata.ExchangeEmailBasePath.Folder(false)},
+					))))
+
+				return sel
+			},
+			expected: []details.DetailsEntry{
+				testdata.ExchangeEmailItems[0],
+				testdata.ExchangeEventsItems[0],
+			},
+		},
 	}
 
 	for _, test := range table {
@@ -270,3 +336,29 @@ func (suite *SelectorReduceSuite) TestReduce() {
 		})
 	}
 }
+
+func (suite *SelectorReduceSuite) TestReduce_serializedGroups() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	sel := selectors.NewExchangeRestore(selectors.Any())
+	sel.Match(selectors.Or(
+		selectors.Scopes(sel.MailSubject("foo")),
+		selectors.Not(selectors.Scopes(sel.MailSender("a-person")))))
+
+	bs, err := json.Marshal(sel.Selector)
+	require.NoError(t, err)
+
+	var result selectors.Selector
+
+	require.NoError(t, json.Unmarshal(bs, &result))
+
+	reduced, err := result.Reduce(ctx, testdata.GetDetailsSet(), fault.New(true))
+	require.NoError(t, err)
+
+	expected := sel.Reduce(ctx, testdata.GetDetailsSet(), fault.New(true))
+	assert.ElementsMatch(t, expected.Entries, reduced.Entries)
+	assert.NotEmpty(t, reduced.Entries)
+}
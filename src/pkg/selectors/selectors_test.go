@@ -240,6 +240,68 @@ func (suite *SelectorSuite) TestSplitByResourceOnwer() {
 	}
 }
 
+func (suite *SelectorSuite) TestResolveOwnerGroups() {
+	table := []struct {
+		name           string
+		input          []string
+		members        []string
+		expectOwners   []string
+		expectAny      bool
+		expectDiscrete string
+	}{
+		{
+			name:           "groups only",
+			members:        []string{"fnord", "smarf"},
+			expectOwners:   []string{"fnord", "smarf"},
+			expectDiscrete: "",
+		},
+		{
+			name:           "single member",
+			members:        []string{"fnord"},
+			expectOwners:   []string{"fnord"},
+			expectDiscrete: "fnord",
+		},
+		{
+			name:         "owners and members",
+			input:        []string{"fnord"},
+			members:      []string{"smarf", "fnord"},
+			expectOwners: []string{"fnord", "smarf"},
+		},
+		{
+			name:           "no members",
+			input:          []string{"fnord"},
+			expectOwners:   []string{"fnord"},
+			expectDiscrete: "fnord",
+		},
+		{
+			name:      "any owner",
+			input:     Any(),
+			members:   []string{"smarf"},
+			expectAny: true,
+		},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			s := newSelector(ServiceUnknown, test.input)
+			s.IncludeOwnerGroups("Finance Team")
+
+			result := s.ResolveOwnerGroups(test.members)
+			assert.Equal(t, []string{"Finance Team"}, result.OwnerGroups)
+
+			if test.expectAny {
+				assert.True(t, isAnyResourceOwner(result))
+				return
+			}
+
+			assert.Equal(t, test.expectOwners, result.DiscreteResourceOwners())
+			assert.Equal(t, test.expectDiscrete, result.DiscreteOwner)
+
+			split := splitByResourceOwner[mockScope](result, nil, rootCatStub)
+			assert.Len(t, split, len(test.expectOwners))
+		})
+	}
+}
+
 // TestPathCategories verifies that no scope produces a `path.UnknownCategory`
 func (suite *SelectorSuite) TestPathCategories_includes() {
 	users := []string{"someuser@onmicrosoft.com"}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/alcionai/corso/src/pkg/backup/details"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/filters"
 	"github.com/alcionai/corso/src/pkg/path"
 )
 
@@ -116,6 +117,16 @@ func (s sharePoint) PathCategories() selectorPathCategories {
 	}
 }
 
+// FilterScopes retrieves the list of filter scopes in the selector.
+func (s *sharePoint) FilterScopes() []SharePointScope {
+	return filterScopes[SharePointScope](s.Selector)
+}
+
+// ExcludeScopes retrieves the list of exclusion scopes in the selector.
+func (s *sharePoint) ExcludeScopes() []SharePointScope {
+	return excludeScopes[SharePointScope](s.Selector)
+}
+
 // -------------------
 // Scope Factories
 
@@ -175,6 +186,16 @@ func (s *sharePoint) Filter(scopes ...[]SharePointScope) {
 	s.Filters = appendScopes(s.Filters, scopes...)
 }
 
+// Match appends the provided groups to the selector's group set.
+// Data is retained if it matches ALL groups.  Groups compose scopes with
+// boolean logic, for conditions which the inclusions, filters, and
+// exclusions can't express.
+// Ex: Or(And(Scopes(s1), Scopes(s2)), Scopes(s3)) => retains data which
+// matches both s1 and s2, or which matches s3.
+func (s *sharePoint) Match(groups ...Group) {
+	s.Groups = append(s.Groups, groups...)
+}
+
 // Scopes retrieves the list of sharePointScopes in the selector.
 func (s *sharePoint) Scopes() []SharePointScope {
 	return scopes[SharePointScope](s.Selector)
@@ -213,6 +234,58 @@ func (s *SharePointRestore) WebURL(urlSuffixes []string, opts ...option) []Share
 	return scopes
 }
 
+// CreatedBy produces a SharePoint library item creator filter scope.
+// Matches any library item whose creator's email contains the provided string.
+// If the input equals selectors.Any, the scope will match all creators.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *sharePoint) CreatedBy(user string) []SharePointScope {
+	return []SharePointScope{
+		makeFilterScope[SharePointScope](
+			SharePointLibraryItem,
+			SharePointFilterCreatedBy,
+			[]string{user},
+			wrapFilter(filters.In)),
+	}
+}
+
+// ContentType produces a SharePoint library item content type filter scope.
+// Matches any library item whose MIME type contains the provided string (ex: "pdf").
+// If the input equals selectors.Any, the scope will match all content types.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *sharePoint) ContentType(mimeType string) []SharePointScope {
+	return []SharePointScope{
+		makeFilterScope[SharePointScope](
+			SharePointLibraryItem,
+			SharePointFilterContentType,
+			[]string{mimeType},
+			wrapFilter(filters.In)),
+	}
+}
+
+// SensitivityLabel produces a SharePoint library item sensitivity label filter scope.
+// Matches any library item whose sensitivity label equals the provided label.
+// If the input equals selectors.Any, the scope will match all labels.
+// If the input is empty or selectors.None, the scope will always fail comparisons.
+func (s *sharePoint) SensitivityLabel(label string) []SharePointScope {
+	return []SharePointScope{
+		makeFilterScope[SharePointScope](
+			SharePointLibraryItem,
+			SharePointFilterSensitivityLabel,
+			[]string{label},
+			wrapFilter(filters.Equal)),
+	}
+}
+
+// SyncArtifacts produces the built-in set of scopes matching the library
+// files left behind by office and the OneDrive sync client: office lock
+// files (~$Report.docx), temp files (*.tmp), and sync-conflict copies
+// (Report-DESKTOP-1A2B3C4.docx).  Exclude the scopes to leave the artifacts out of
+// backups and restores.
+// Ex: sel.Exclude(sel.SyncArtifacts())
+func (s *sharePoint) SyncArtifacts() []SharePointScope {
+	return syncArtifactScopes[SharePointScope](SharePointLibraryItem, SharePointFilterItemName)
+}
+
 // Produces one or more SharePoint site scopes.
 // One scope is created per site entry.
 // If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
@@ -281,6 +354,40 @@ func (s *sharePoint) Libraries(libraries []string, opts ...option) []SharePointS
 	return scopes
 }
 
+// LibraryFolderURLs produces a SharePoint library scope that identifies
+// folders by their server-relative url (ex: /sites/Eng/Shared Documents/Specs).
+// Each url gets resolved to its library (drive) and folder during backup,
+// which removes the ambiguity between libraries containing identically
+// named folders.  A url that names the library itself selects all of its
+// folders.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+func (s *SharePointBackup) LibraryFolderURLs(urls []string, opts ...option) []SharePointScope {
+	os := append([]option{PrefixMatch()}, opts...)
+
+	return []SharePointScope{
+		makeScope[SharePointScope](SharePointLibrary, Any()).
+			set(SharePointLibraryURL, urls, os...),
+	}
+}
+
+// LibraryItemURLs produces a SharePoint library item scope that identifies
+// the items' folders by their server-relative url, as in LibraryFolderURLs.
+// Only the items within those folders are selected.
+// If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
+// If any slice contains selectors.None, that slice is reduced to [selectors.None]
+// If any slice is empty, it defaults to [selectors.None]
+func (s *SharePointBackup) LibraryItemURLs(urls, items []string, opts ...option) []SharePointScope {
+	os := append([]option{PrefixMatch()}, opts...)
+
+	return []SharePointScope{
+		makeScope[SharePointScope](SharePointLibraryItem, items).
+			set(SharePointLibrary, Any()).
+			set(SharePointLibraryURL, urls, os...),
+	}
+}
+
 // LibraryItems produces one or more SharePoint library item scopes.
 // If any slice contains selectors.Any, that slice is reduced to [selectors.Any]
 // If any slice contains selectors.None, that slice is reduced to [selectors.None]
@@ -357,7 +464,16 @@ const (
 	SharePointPageFolder  sharePointCategory = "SharePointPageFolder"
 	SharePointPage        sharePointCategory = "SharePointPage"
 
+	// SharePointLibraryURL identifies library folders by their server-relative
+	// url.  It is resolved to a library and folder during backup, and is not
+	// used when matching backup details.
+	SharePointLibraryURL sharePointCategory = "SharePointLibraryURL"
+
 	// filterable topics identified by SharePoint
+	SharePointFilterCreatedBy        sharePointCategory = "SharePointFilterCreatedBy"
+	SharePointFilterContentType      sharePointCategory = "SharePointFilterContentType"
+	SharePointFilterSensitivityLabel sharePointCategory = "SharePointFilterSensitivityLabel"
+	SharePointFilterItemName         sharePointCategory = "SharePointFilterItemName"
 )
 
 // sharePointLeafProperties describes common metadata of the leaf categories
@@ -391,7 +507,9 @@ func (c sharePointCategory) String() string {
 // Ex: ServiceUser.leafCat() => ServiceUser
 func (c sharePointCategory) leafCat() categorizer {
 	switch c {
-	case SharePointLibrary, SharePointLibraryItem:
+	case SharePointLibrary, SharePointLibraryItem, SharePointLibraryURL,
+		SharePointFilterCreatedBy, SharePointFilterContentType, SharePointFilterSensitivityLabel,
+		SharePointFilterItemName:
 		return SharePointLibraryItem
 	case SharePointList, SharePointListItem:
 		return SharePointListItem
@@ -529,13 +647,24 @@ func (s SharePointScope) set(cat sharePointCategory, v []string, opts ...option)
 	os := []option{}
 
 	switch cat {
-	case SharePointLibrary, SharePointList, SharePointPage:
+	case SharePointLibrary, SharePointList, SharePointPage, SharePointLibraryURL:
 		os = append(os, pathComparator())
 	}
 
 	return set(s, cat, v, append(os, opts...)...)
 }
 
+// LibraryURLs returns the server-relative library folder urls in the
+// scope.  Returns nil if the scope does not identify folders by url, or
+// if it selects any url.
+func (s SharePointScope) LibraryURLs() []string {
+	if _, ok := s[SharePointLibraryURL.String()]; !ok || s.IsAny(SharePointLibraryURL) {
+		return nil
+	}
+
+	return s.Get(SharePointLibraryURL)
+}
+
 // setDefaults ensures that site scopes express `AnyTgt` for their child category types.
 func (s SharePointScope) setDefaults() {
 	switch s.Category() {
@@ -600,6 +729,14 @@ func (s SharePointScope) matchesInfo(dii details.ItemInfo) bool {
 	switch filterCat {
 	case SharePointWebURL:
 		i = info.WebURL
+	case SharePointFilterCreatedBy:
+		i = info.CreatedBy
+	case SharePointFilterContentType:
+		i = info.ContentType
+	case SharePointFilterSensitivityLabel:
+		i = info.SensitivityLabel
+	case SharePointFilterItemName:
+		i = info.ItemName
 	}
 
 	return s.Matches(filterCat, i)
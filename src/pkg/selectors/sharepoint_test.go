@@ -175,6 +175,63 @@ func (suite *SharePointSelectorSuite) TestSharePointSelector_Exclude_WebURLs() {
 	}
 }
 
+func (suite *SharePointSelectorSuite) TestSharePointSelector_LibraryFolderURLs() {
+	table := []struct {
+		name   string
+		urls   []string
+		expect []string
+	}{
+		{
+			name:   "urls",
+			urls:   []string{"/sites/Eng/Shared Documents/Specs", "/sites/Eng/Specs"},
+			expect: []string{"/sites/Eng/Shared Documents/Specs", "/sites/Eng/Specs"},
+		},
+		{
+			name:   "any",
+			urls:   Any(),
+			expect: nil,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			sel := NewSharePointBackup(Any())
+			sel.Include(sel.LibraryFolderURLs(test.urls))
+
+			scopes := sel.Includes
+			require.Len(t, scopes, 1)
+
+			scope := SharePointScope(scopes[0])
+			assert.Equal(t, SharePointLibrary, scope.Category())
+			assert.True(t, scope.IsAny(SharePointLibrary))
+			assert.Equal(t, test.expect, scope.LibraryURLs())
+		})
+	}
+}
+
+func (suite *SharePointSelectorSuite) TestSharePointSelector_LibraryItemURLs() {
+	t := suite.T()
+	sel := NewSharePointBackup(Any())
+	sel.Include(sel.LibraryItemURLs([]string{"/sites/Eng/Shared Documents/Specs"}, []string{"item-id"}))
+
+	scopes := sel.Includes
+	require.Len(t, scopes, 1)
+
+	scope := SharePointScope(scopes[0])
+	assert.Equal(t, SharePointLibraryItem, scope.Category())
+	assert.True(t, scope.IsAny(SharePointLibrary))
+	assert.Equal(t, []string{"/sites/Eng/Shared Documents/Specs"}, scope.LibraryURLs())
+	assert.True(t, scope.Matches(SharePointLibraryItem, "item-id"))
+	assert.False(t, scope.Matches(SharePointLibraryItem, "other-id"))
+}
+
+func (suite *SharePointSelectorSuite) TestSharePointScope_LibraryURLs_noURLs() {
+	sel := NewSharePointBackup(Any())
+	scope := sel.Libraries(Any())[0]
+
+	assert.Nil(suite.T(), scope.LibraryURLs())
+}
+
 func (suite *SharePointSelectorSuite) TestNewSharePointRestore() {
 	t := suite.T()
 	or := NewSharePointRestore(nil)
@@ -397,6 +454,71 @@ func (suite *SharePointSelectorSuite) TestSharePointScope_MatchesInfo() {
 	}
 }
 
+func (suite *SharePointSelectorSuite) TestSharePointScope_MatchesInfo_createdBy() {
+	var (
+		ods      = NewSharePointRestore(nil)
+		itemInfo = details.ItemInfo{
+			SharePoint: &details.SharePointInfo{
+				ItemType:  details.SharePointItem,
+				CreatedBy: "user@email.com",
+			},
+		}
+	)
+
+	table := []struct {
+		name   string
+		scope  []SharePointScope
+		expect assert.BoolAssertionFunc
+	}{
+		{"created by user", ods.CreatedBy("user@email.com"), assert.True},
+		{"created by partial email", ods.CreatedBy("user@"), assert.True},
+		{"created by other user", ods.CreatedBy("other@email.com"), assert.False},
+		{"created by none", ods.CreatedBy(NoneTgt), assert.False},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			scopes := setScopesToDefault(test.scope)
+			for _, scope := range scopes {
+				test.expect(t, scope.matchesInfo(itemInfo))
+			}
+		})
+	}
+}
+
+func (suite *SharePointSelectorSuite) TestSharePointScope_MatchesInfo_contentFilters() {
+	var (
+		ods      = NewSharePointRestore(nil)
+		itemInfo = details.ItemInfo{
+			SharePoint: &details.SharePointInfo{
+				ItemType:         details.SharePointItem,
+				ContentType:      "application/pdf",
+				SensitivityLabel: "Confidential",
+			},
+		}
+	)
+
+	table := []struct {
+		name   string
+		scope  []SharePointScope
+		expect assert.BoolAssertionFunc
+	}{
+		{"content type", ods.ContentType("application/pdf"), assert.True},
+		{"partial content type", ods.ContentType("pdf"), assert.True},
+		{"other content type", ods.ContentType("text/plain"), assert.False},
+		{"sensitivity label", ods.SensitivityLabel("Confidential"), assert.True},
+		{"other sensitivity label", ods.SensitivityLabel("Public"), assert.False},
+		{"sensitivity label none", ods.SensitivityLabel(NoneTgt), assert.False},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			scopes := setScopesToDefault(test.scope)
+			for _, scope := range scopes {
+				test.expect(t, scope.matchesInfo(itemInfo))
+			}
+		})
+	}
+}
+
 func (suite *SharePointSelectorSuite) TestCategory_PathType() {
 	table := []struct {
 		cat      sharePointCategory
@@ -408,6 +530,10 @@ func (suite *SharePointSelectorSuite) TestCategory_PathType() {
 		{SharePointLibrary, path.LibrariesCategory},
 		{SharePointLibraryItem, path.LibrariesCategory},
 		{SharePointList, path.ListsCategory},
+		{SharePointFilterCreatedBy, path.LibrariesCategory},
+		{SharePointFilterContentType, path.LibrariesCategory},
+		{SharePointFilterSensitivityLabel, path.LibrariesCategory},
+		{SharePointFilterItemName, path.LibrariesCategory},
 	}
 	for _, test := range table {
 		suite.T().Run(test.cat.String(), func(t *testing.T) {
@@ -0,0 +1,164 @@
+package m365
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/alcionai/clues"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/alcionai/corso/src/internal/common/ptr"
+	"github.com/alcionai/corso/src/internal/connector/onedrive/api"
+	"github.com/alcionai/corso/src/pkg/account"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// drive types reported by graph
+const (
+	personalDriveType        = "personal"
+	businessDriveType        = "business"
+	documentLibraryDriveType = "documentLibrary"
+)
+
+// DriveItemRef identifies a OneDrive or SharePoint file or folder.
+type DriveItemRef struct {
+	// Service is either the OneDrive or the SharePoint service.
+	Service path.ServiceType
+	// Owner is the ID of the user (OneDrive) or site (SharePoint) that
+	// owns the item's drive.
+	Owner   string
+	DriveID string
+	// DriveURL is the web url of the item's drive.
+	DriveURL string
+	ItemID   string
+	Name     string
+	// ParentPath is the path of the folder holding the item, relative to
+	// the root of the drive (ex: Documents/Specs).  Empty for items in the
+	// root folder, and for the root folder itself.
+	ParentPath string
+	IsFolder   bool
+	IsRoot     bool
+}
+
+// FolderPath is the path, relative to the root of the drive, of the
+// folder selected by the item: the folder itself, or the folder holding
+// the file.  Empty if that folder is the root of the drive.
+func (ref DriveItemRef) FolderPath() string {
+	if !ref.IsFolder || ref.IsRoot {
+		return ref.ParentPath
+	}
+
+	if len(ref.ParentPath) == 0 {
+		return ref.Name
+	}
+
+	return ref.ParentPath + "/" + ref.Name
+}
+
+// FolderURL is the server-relative url (ex: /sites/Eng/Shared Documents/Specs)
+// of the folder selected by the item.
+func (ref DriveItemRef) FolderURL() (string, error) {
+	u, err := url.Parse(ref.DriveURL)
+	if err != nil {
+		return "", clues.Wrap(err, "parsing drive url")
+	}
+
+	fp := ref.FolderPath()
+	if len(fp) == 0 {
+		return u.Path, nil
+	}
+
+	return strings.TrimSuffix(u.Path, "/") + "/" + fp, nil
+}
+
+// ResolveDriveItemURL identifies the file or folder at the url, which can
+// be a sharing link or the item's web url, by its drive and ID.
+func ResolveDriveItemURL(ctx context.Context, acct account.Account, itemURL string) (*DriveItemRef, error) {
+	m365, err := acct.M365Config()
+	if err != nil {
+		return nil, clues.Wrap(err, "retrieving m365 account configuration").WithClues(ctx)
+	}
+
+	client, err := api.NewClient(m365)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = clues.Add(ctx, "item_url", itemURL)
+
+	item, err := client.Drives().GetSharedItem(ctx, itemURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.GetParentReference() == nil || len(ptr.Val(item.GetParentReference().GetDriveId())) == 0 {
+		return nil, clues.New("item url has no drive").WithClues(ctx)
+	}
+
+	drive, err := client.Drives().GetDrive(ctx, ptr.Val(item.GetParentReference().GetDriveId()))
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := driveItemRef(item, drive)
+	if err != nil {
+		return nil, clues.Stack(err).WithClues(ctx)
+	}
+
+	return ref, nil
+}
+
+// driveItemRef produces the reference to the item within the drive.
+func driveItemRef(item models.DriveItemable, drive models.Driveable) (*DriveItemRef, error) {
+	parent := item.GetParentReference()
+	if parent == nil {
+		return nil, clues.New("item missing parent reference")
+	}
+
+	ref := &DriveItemRef{
+		DriveID:  ptr.Val(parent.GetDriveId()),
+		DriveURL: ptr.Val(drive.GetWebUrl()),
+		ItemID:   ptr.Val(item.GetId()),
+		Name:     ptr.Val(item.GetName()),
+		IsFolder: item.GetFolder() != nil || item.GetRoot() != nil,
+		IsRoot:   item.GetRoot() != nil,
+	}
+
+	if !ref.IsRoot {
+		ref.ParentPath = parentPath(ptr.Val(parent.GetPath()))
+	}
+
+	switch dt := ptr.Val(drive.GetDriveType()); dt {
+	case personalDriveType, businessDriveType:
+		ref.Service = path.OneDriveService
+
+		if drive.GetOwner() != nil && drive.GetOwner().GetUser() != nil {
+			ref.Owner = ptr.Val(drive.GetOwner().GetUser().GetId())
+		}
+
+	case documentLibraryDriveType:
+		ref.Service = path.SharePointService
+		ref.Owner = ptr.Val(parent.GetSiteId())
+
+	default:
+		return nil, clues.New("unsupported drive type").With("drive_type", dt)
+	}
+
+	if len(ref.Owner) == 0 {
+		return nil, clues.New("item's drive has no owner").With("service", ref.Service.String())
+	}
+
+	return ref, nil
+}
+
+// parentPath trims the drive root from the parent reference's path
+// (ex: /drives/{driveID}/root:/Documents/Specs).
+func parentPath(p string) string {
+	_, p, _ = strings.Cut(p, "root:")
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+
+	return strings.Trim(p, "/")
+}
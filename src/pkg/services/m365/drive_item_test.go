@@ -0,0 +1,187 @@
+package m365
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+type DriveItemUnitSuite struct {
+	tester.Suite
+}
+
+func TestDriveItemUnitSuite(t *testing.T) {
+	suite.Run(t, &DriveItemUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func testDrive(driveType, webURL, ownerID string) models.Driveable {
+	d := models.NewDrive()
+	d.SetDriveType(&driveType)
+	d.SetWebUrl(&webURL)
+
+	if len(ownerID) > 0 {
+		u := models.NewIdentity()
+		u.SetId(&ownerID)
+
+		is := models.NewIdentitySet()
+		is.SetUser(u)
+		d.SetOwner(is)
+	}
+
+	return d
+}
+
+func testDriveItem(id, name, parentPath, siteID string, isFolder, isRoot bool) models.DriveItemable {
+	driveID := "drive-id"
+
+	parent := models.NewItemReference()
+	parent.SetDriveId(&driveID)
+
+	if len(parentPath) > 0 {
+		parent.SetPath(&parentPath)
+	}
+
+	if len(siteID) > 0 {
+		parent.SetSiteId(&siteID)
+	}
+
+	item := models.NewDriveItem()
+	item.SetId(&id)
+	item.SetName(&name)
+	item.SetParentReference(parent)
+
+	if isFolder {
+		item.SetFolder(models.NewFolder())
+	}
+
+	if isRoot {
+		item.SetRoot(models.NewRoot())
+	}
+
+	return item
+}
+
+func (suite *DriveItemUnitSuite) TestDriveItemRef() {
+	var (
+		userDrive = testDrive("business", "https://contoso-my.sharepoint.com/personal/alice/Documents", "uid")
+		library   = testDrive("documentLibrary", "https://contoso.sharepoint.com/sites/Eng/Shared%20Documents", "")
+	)
+
+	table := []struct {
+		name      string
+		item      models.DriveItemable
+		drive     models.Driveable
+		expect    DriveItemRef
+		folder    string
+		folderURL string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "onedrive file",
+			item:  testDriveItem("iid", "plan.docx", "/drives/drive-id/root:/Reports/2023", "", false, false),
+			drive: userDrive,
+			expect: DriveItemRef{
+				Service:    path.OneDriveService,
+				Owner:      "uid",
+				DriveID:    "drive-id",
+				DriveURL:   "https://contoso-my.sharepoint.com/personal/alice/Documents",
+				ItemID:     "iid",
+				Name:       "plan.docx",
+				ParentPath: "Reports/2023",
+			},
+			folder:    "Reports/2023",
+			folderURL: "/personal/alice/Documents/Reports/2023",
+			expectErr: assert.NoError,
+		},
+		{
+			name:  "onedrive folder in root",
+			item:  testDriveItem("iid", "Reports", "/drives/drive-id/root:", "", true, false),
+			drive: userDrive,
+			expect: DriveItemRef{
+				Service:  path.OneDriveService,
+				Owner:    "uid",
+				DriveID:  "drive-id",
+				DriveURL: "https://contoso-my.sharepoint.com/personal/alice/Documents",
+				ItemID:   "iid",
+				Name:     "Reports",
+				IsFolder: true,
+			},
+			folder:    "Reports",
+			folderURL: "/personal/alice/Documents/Reports",
+			expectErr: assert.NoError,
+		},
+		{
+			name:  "sharepoint escaped folder",
+			item:  testDriveItem("iid", "Specs", "/drives/drive-id/root:/Design%20Docs", "sid", true, false),
+			drive: library,
+			expect: DriveItemRef{
+				Service:    path.SharePointService,
+				Owner:      "sid",
+				DriveID:    "drive-id",
+				DriveURL:   "https://contoso.sharepoint.com/sites/Eng/Shared%20Documents",
+				ItemID:     "iid",
+				Name:       "Specs",
+				ParentPath: "Design Docs",
+				IsFolder:   true,
+			},
+			folder:    "Design Docs/Specs",
+			folderURL: "/sites/Eng/Shared Documents/Design Docs/Specs",
+			expectErr: assert.NoError,
+		},
+		{
+			name:  "sharepoint library root",
+			item:  testDriveItem("iid", "root", "", "sid", true, true),
+			drive: library,
+			expect: DriveItemRef{
+				Service:  path.SharePointService,
+				Owner:    "sid",
+				DriveID:  "drive-id",
+				DriveURL: "https://contoso.sharepoint.com/sites/Eng/Shared%20Documents",
+				ItemID:   "iid",
+				Name:     "root",
+				IsFolder: true,
+				IsRoot:   true,
+			},
+			folder:    "",
+			folderURL: "/sites/Eng/Shared Documents",
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "sharepoint item without a site",
+			item:      testDriveItem("iid", "plan.docx", "/drives/drive-id/root:", "", false, false),
+			drive:     library,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "unsupported drive",
+			item:      testDriveItem("iid", "plan.docx", "/drives/drive-id/root:", "", false, false),
+			drive:     testDrive("unknown", "https://contoso.sharepoint.com", "uid"),
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			ref, err := driveItemRef(test.item, test.drive)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.expect, *ref)
+			assert.Equal(t, test.folder, ref.FolderPath())
+
+			u, err := ref.FolderURL()
+			require.NoError(t, err)
+			assert.Equal(t, test.folderURL, u)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package m365
 
 import (
 	"context"
+	"time"
 
 	"github.com/alcionai/clues"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -12,6 +13,7 @@ import (
 	"github.com/alcionai/corso/src/internal/connector/graph"
 	"github.com/alcionai/corso/src/pkg/account"
 	"github.com/alcionai/corso/src/pkg/fault"
+	"github.com/alcionai/corso/src/pkg/logger"
 )
 
 type User struct {
@@ -83,17 +85,64 @@ func UserPNs(ctx context.Context, acct account.Account, errs *fault.Errors) ([]s
 		return nil, err
 	}
 
+	return PrincipalNames(users), nil
+}
+
+// PrincipalNames produces the principal names of the users.
+func PrincipalNames(users []*User) []string {
 	ret := make([]string, 0, len(users))
 	for _, u := range users {
 		ret = append(ret, u.PrincipalName)
 	}
 
+	return ret
+}
+
+// GroupMemberPNs retrieves the principal names of every user who is a member
+// of any of the groups, including the members of nested groups.  Groups are
+// identified by ID or display name.  Membership is resolved at the time of
+// the call, so repeated calls follow changes to the groups.
+func GroupMemberPNs(
+	ctx context.Context,
+	acct account.Account,
+	groups []string,
+	errs *fault.Errors,
+) ([]string, error) {
+	gc, err := connector.NewGraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Users, errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing M365 graph connection")
+	}
+
+	var (
+		seen = map[string]struct{}{}
+		ret  = []string{}
+	)
+
+	for _, g := range groups {
+		members, err := discovery.GroupMembers(ctx, gc.Owners.Groups(), g, errs)
+		if err != nil {
+			return nil, clues.Wrap(err, "resolving group members").With("group", g)
+		}
+
+		for _, m := range members {
+			pu, err := parseUser(m)
+			if err != nil {
+				return nil, errors.Wrap(err, "parsing userable")
+			}
+
+			if _, ok := seen[pu.PrincipalName]; !ok {
+				seen[pu.PrincipalName] = struct{}{}
+				ret = append(ret, pu.PrincipalName)
+			}
+		}
+	}
+
 	return ret, nil
 }
 
 // SiteURLs returns a list of SharePoint site WebURLs in the specified M365 tenant
 func SiteURLs(ctx context.Context, acct account.Account, errs *fault.Errors) ([]string, error) {
-	gc, err := connector.NewGraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
+	gc, err := connector.SharedPool().GraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing M365 graph connection")
 	}
@@ -103,7 +152,7 @@ func SiteURLs(ctx context.Context, acct account.Account, errs *fault.Errors) ([]
 
 // SiteURLs returns a list of SharePoint sites IDs in the specified M365 tenant
 func SiteIDs(ctx context.Context, acct account.Account, errs *fault.Errors) ([]string, error) {
-	gc, err := connector.NewGraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
+	gc, err := connector.SharedPool().GraphConnector(ctx, graph.HTTPClient(graph.NoTimeout()), acct, connector.Sites, errs)
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing graph connection")
 	}
@@ -111,11 +160,54 @@ func SiteIDs(ctx context.Context, acct account.Account, errs *fault.Errors) ([]s
 	return gc.GetSiteIDs(), nil
 }
 
+// PurgeExpiredQuarantines deletes, for each user and site, every quarantine
+// restore container whose expiry tag is before the boundary time.  Returns
+// the names of the deleted containers.
+func PurgeExpiredQuarantines(
+	ctx context.Context,
+	acct account.Account,
+	userIDs, siteIDs []string,
+	boundary time.Time,
+	errs *fault.Errors,
+) ([]string, error) {
+	gc, err := connector.NewGraphConnector(
+		ctx,
+		graph.HTTPClient(graph.NoTimeout()),
+		acct,
+		connector.UnknownResource,
+		errs)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing M365 graph connection")
+	}
+
+	purged := []string{}
+
+	for _, uid := range userIDs {
+		ps, err := gc.PurgeExpiredQuarantines(ctx, uid, boundary, errs)
+		if err != nil {
+			return purged, err
+		}
+
+		purged = append(purged, ps...)
+	}
+
+	for _, sid := range siteIDs {
+		ps, err := gc.PurgeExpiredSiteQuarantines(ctx, sid, boundary, errs)
+		if err != nil {
+			return purged, err
+		}
+
+		purged = append(purged, ps...)
+	}
+
+	return purged, nil
+}
+
 // parseUser extracts information from `models.Userable` we care about
 func parseUser(item models.Userable) (*User, error) {
 	if item.GetUserPrincipalName() == nil {
 		return nil, clues.New("user missing principal name").
-			With("user_id", *item.GetId()) // TODO: pii
+			With("user_id", logger.PII(*item.GetId()))
 	}
 
 	u := &User{PrincipalName: *item.GetUserPrincipalName(), ID: *item.GetId()}
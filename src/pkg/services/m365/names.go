@@ -0,0 +1,136 @@
+package m365
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alcionai/corso/src/pkg/selectors"
+)
+
+// Named is a resource owner that can be selected by its display name.
+type Named struct {
+	// ID is the value that selects the resource in place of its name.
+	ID string
+	// Name is the resource's display name.
+	Name string
+	// Ref describes the resource when choosing between resources that
+	// share a name (ex: a principal name or web url).
+	Ref string
+	// Alias is another value that identifies the resource (ex: a user's
+	// ID).  Aliases are replaced with the resource's ID.
+	Alias string
+}
+
+func (n Named) String() string {
+	return fmt.Sprintf("%s (%s)", n.Ref, n.Name)
+}
+
+// AmbiguousNameError is produced when a display name matches more than
+// one resource, and none of them was picked.
+type AmbiguousNameError struct {
+	Name       string
+	Candidates []Named
+}
+
+func (e AmbiguousNameError) Error() string {
+	cs := make([]string, 0, len(e.Candidates))
+
+	for _, c := range e.Candidates {
+		cs = append(cs, c.String())
+	}
+
+	return fmt.Sprintf(
+		"%q matches %d resources, specify one of: %s",
+		e.Name, len(e.Candidates), strings.Join(cs, ", "))
+}
+
+// UnknownNameError is produced when a value matches no resource by its
+// ID, Ref, Alias, or display name.
+type UnknownNameError struct {
+	Name string
+}
+
+func (e UnknownNameError) Error() string {
+	return fmt.Sprintf("%q matches no resource", e.Name)
+}
+
+// PickFunc chooses between the resources whose display names match the
+// name.  Returning an error halts resolution.
+type PickFunc func(name string, candidates []Named) (Named, error)
+
+// UserNames describes the users for resolution by display name.  Users
+// are identified by their principal names, and can also be selected by
+// their IDs.
+func UserNames(users []*User) []Named {
+	ns := make([]Named, 0, len(users))
+
+	for _, u := range users {
+		ns = append(ns, Named{ID: u.PrincipalName, Name: u.Name, Ref: u.PrincipalName, Alias: u.ID})
+	}
+
+	return ns
+}
+
+// ResolveDisplayNames replaces each value that names a resource with the
+// resource's ID.  Values that already identify a resource by its ID or Ref,
+// as well as the wildcard, are left unchanged.  Display names are matched
+// case-insensitively.  If a name matches more than one resource, pick
+// chooses between them; with a nil pick, an AmbiguousNameError lists the
+// candidates.  Values that match no resource produce an UnknownNameError.
+func ResolveDisplayNames(values []string, resources []Named, pick PickFunc) ([]string, error) {
+	var (
+		known   = map[string]struct{}{}
+		aliases = map[string]string{}
+		byName  = map[string][]Named{}
+		results = make([]string, 0, len(values))
+	)
+
+	for _, r := range resources {
+		known[strings.ToLower(r.ID)] = struct{}{}
+		known[strings.ToLower(r.Ref)] = struct{}{}
+
+		if len(r.Alias) > 0 {
+			aliases[strings.ToLower(r.Alias)] = r.ID
+		}
+
+		if len(r.Name) > 0 {
+			k := strings.ToLower(strings.TrimSpace(r.Name))
+			byName[k] = append(byName[k], r)
+		}
+	}
+
+	for _, v := range values {
+		if _, ok := known[strings.ToLower(v)]; ok || v == selectors.AnyTgt {
+			results = append(results, v)
+			continue
+		}
+
+		if id, ok := aliases[strings.ToLower(v)]; ok {
+			results = append(results, id)
+			continue
+		}
+
+		matches := byName[strings.ToLower(strings.TrimSpace(v))]
+
+		switch {
+		case len(matches) == 0:
+			return nil, UnknownNameError{Name: v}
+
+		case len(matches) == 1:
+			results = append(results, matches[0].ID)
+
+		case pick == nil:
+			return nil, AmbiguousNameError{Name: v, Candidates: matches}
+
+		default:
+			picked, err := pick(v, matches)
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, picked.ID)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,124 @@
+package m365
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type NamesUnitSuite struct {
+	tester.Suite
+}
+
+func TestNamesUnitSuite(t *testing.T) {
+	suite.Run(t, &NamesUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *NamesUnitSuite) TestResolveDisplayNames() {
+	users := UserNames([]*User{
+		{PrincipalName: "jane@contoso.com", ID: "id-1", Name: "Jane Doe"},
+		{PrincipalName: "jdoe@contoso.com", ID: "id-2", Name: "Jane Doe"},
+		{PrincipalName: "bob@contoso.com", ID: "id-3", Name: "Bob Smith"},
+		{PrincipalName: "svc@contoso.com", ID: "id-4"},
+	})
+
+	pickSecond := func(name string, cs []Named) (Named, error) { return cs[1], nil }
+	pickFails := func(name string, cs []Named) (Named, error) { return Named{}, errors.New("canceled") }
+
+	table := []struct {
+		name      string
+		values    []string
+		pick      PickFunc
+		expect    []string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:      "principal names and wildcards are unchanged",
+			values:    []string{"jane@contoso.com", "BOB@contoso.com", "*"},
+			expect:    []string{"jane@contoso.com", "BOB@contoso.com", "*"},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "unique name",
+			values:    []string{"bob smith", " Bob Smith "},
+			expect:    []string{"bob@contoso.com", "bob@contoso.com"},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "ids are replaced by principal names",
+			values:    []string{"id-3", "ID-1"},
+			expect:    []string{"bob@contoso.com", "jane@contoso.com"},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "unknown name",
+			values:    []string{"bob smith", "Alice Jones"},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "unknown principal name",
+			values:    []string{"alice@contoso.com"},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "ambiguous name",
+			values:    []string{"Bob Smith", "Jane Doe"},
+			expectErr: assert.Error,
+		},
+		{
+			name:      "ambiguous name picked",
+			values:    []string{"Jane Doe"},
+			pick:      pickSecond,
+			expect:    []string{"jdoe@contoso.com"},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "pick fails",
+			values:    []string{"Jane Doe"},
+			pick:      pickFails,
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			result, err := ResolveDisplayNames(test.values, users, test.pick)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
+
+func (suite *NamesUnitSuite) TestUnknownNameError() {
+	t := suite.T()
+
+	_, err := ResolveDisplayNames([]string{"Jane Dough"}, []Named{{ID: "jane@contoso.com", Name: "Jane Doe"}}, nil)
+	assert.ErrorAs(t, err, &UnknownNameError{})
+	assert.Equal(t, `"Jane Dough" matches no resource`, err.Error())
+}
+
+func (suite *NamesUnitSuite) TestAmbiguousNameError() {
+	t := suite.T()
+
+	_, err := ResolveDisplayNames(
+		[]string{"Jane Doe"},
+		[]Named{
+			{ID: "jane@contoso.com", Name: "Jane Doe", Ref: "jane@contoso.com"},
+			{ID: "jdoe@contoso.com", Name: "Jane Doe", Ref: "jdoe@contoso.com"},
+		},
+		nil)
+
+	var ane AmbiguousNameError
+
+	assert.ErrorAs(t, err, &ane)
+	assert.Len(t, ane.Candidates, 2)
+	assert.Equal(
+		t,
+		`"Jane Doe" matches 2 resources, specify one of: jane@contoso.com (Jane Doe), jdoe@contoso.com (Jane Doe)`,
+		err.Error())
+}
@@ -0,0 +1,281 @@
+package m365
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/alcionai/clues"
+	"github.com/google/uuid"
+	"github.com/microsoftgraph/msgraph-sdk-go/sites"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/corso/src/internal/connector/graph"
+	"github.com/alcionai/corso/src/pkg/account"
+)
+
+// RequiredPermissions are the application permissions that must be
+// consented to within the tenant for Corso to back up and restore all
+// supported services.
+var RequiredPermissions = []string{
+	"Calendars.ReadWrite",
+	"Contacts.ReadWrite",
+	"Files.ReadWrite.All",
+	"GroupMember.Read.All",
+	"Mail.ReadWrite",
+	"Mail.Send",
+	"MailboxSettings.ReadWrite",
+	"Sites.FullControl.All",
+	"Tasks.ReadWrite.All",
+	"User.Read.All",
+}
+
+// Probe capabilities.
+const (
+	ProbeTenantID    = "Tenant ID"
+	ProbeCredentials = "App Credentials"
+	ProbeScopes      = "Consented Permissions"
+	ProbeUsers       = "List Users"
+	ProbeSites       = "List Sites"
+	ProbeDrives      = "Get Drive"
+)
+
+const (
+	graphDefaultScope = "https://graph.microsoft.com/.default"
+	// rootSiteID addresses the tenant's root sharepoint site.
+	rootSiteID = "root"
+)
+
+// ProbeResult describes the outcome of checking a single capability.
+type ProbeResult struct {
+	Capability string `json:"capability"`
+	Passed     bool   `json:"passed"`
+	Skipped    bool   `json:"skipped"`
+	Message    string `json:"message"`
+}
+
+// ProbeReport holds the results of every capability checked by Probe.
+type ProbeReport struct {
+	TenantID string        `json:"tenantID"`
+	Results  []ProbeResult `json:"results"`
+}
+
+// Passed is true if no capability failed.  Skipped capabilities
+// are ignored.
+func (pr ProbeReport) Passed() bool {
+	for _, r := range pr.Results {
+		if !r.Passed && !r.Skipped {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (pr *ProbeReport) pass(capability, msg string) {
+	pr.Results = append(pr.Results, ProbeResult{Capability: capability, Passed: true, Message: msg})
+}
+
+func (pr *ProbeReport) fail(capability string, err error) {
+	pr.Results = append(pr.Results, ProbeResult{Capability: capability, Message: err.Error()})
+}
+
+func (pr *ProbeReport) skip(capabilities ...string) {
+	for _, c := range capabilities {
+		pr.Results = append(pr.Results, ProbeResult{
+			Capability: c,
+			Skipped:    true,
+			Message:    "skipped due to prior failure",
+		})
+	}
+}
+
+// Probe checks the tenant ID, app credentials, consented permissions,
+// and the reachability of each service in the tenant described by the
+// account.  Capability failures are recorded in the report; an error is
+// only returned if the probe itself could not be run.
+func Probe(ctx context.Context, acct account.Account) (ProbeReport, error) {
+	m365, err := acct.M365Config()
+	if err != nil {
+		return ProbeReport{}, clues.Wrap(err, "retrieving m365 account configuration").WithClues(ctx)
+	}
+
+	report := ProbeReport{TenantID: m365.AzureTenantID}
+
+	if err := validateTenantID(m365.AzureTenantID); err != nil {
+		report.fail(ProbeTenantID, err)
+		report.skip(ProbeCredentials, ProbeScopes, ProbeUsers, ProbeSites, ProbeDrives)
+
+		return report, nil
+	}
+
+	report.pass(ProbeTenantID, "")
+
+	cred, err := graph.NewCredential(m365)
+	if err != nil {
+		report.fail(ProbeCredentials, err)
+		report.skip(ProbeScopes, ProbeUsers, ProbeSites, ProbeDrives)
+
+		return report, nil
+	}
+
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{graphDefaultScope}})
+	if err != nil {
+		report.fail(ProbeCredentials, clues.Wrap(err, "acquiring access token"))
+		report.skip(ProbeScopes, ProbeUsers, ProbeSites, ProbeDrives)
+
+		return report, nil
+	}
+
+	report.pass(ProbeCredentials, "")
+
+	roles, err := tokenRoles(token.Token)
+	if err != nil {
+		report.fail(ProbeScopes, err)
+	} else if missing := missingPermissions(roles); len(missing) > 0 {
+		report.fail(ProbeScopes, clues.New("missing permissions: "+strings.Join(missing, ", ")))
+	} else {
+		report.pass(ProbeScopes, strings.Join(RequiredPermissions, ", "))
+	}
+
+	adapter, err := graph.CreateAdapter(m365)
+	if err != nil {
+		return report, clues.Wrap(err, "creating graph adapter").WithClues(ctx)
+	}
+
+	service := graph.NewService(adapter)
+
+	if err := probeUser(ctx, service); err != nil {
+		report.fail(ProbeUsers, err)
+	} else {
+		report.pass(ProbeUsers, "")
+	}
+
+	if err := probeSite(ctx, service); err != nil {
+		report.fail(ProbeSites, err)
+	} else {
+		report.pass(ProbeSites, "")
+	}
+
+	if err := probeDrive(ctx, service); err != nil {
+		report.fail(ProbeDrives, err)
+	} else {
+		report.pass(ProbeDrives, "")
+	}
+
+	return report, nil
+}
+
+// validateTenantID ensures the tenant is either a guid, or a domain
+// name (ex: contoso.onmicrosoft.com).
+func validateTenantID(tenantID string) error {
+	if len(tenantID) == 0 {
+		return clues.New("tenant ID is empty")
+	}
+
+	if _, err := uuid.Parse(tenantID); err == nil {
+		return nil
+	}
+
+	if strings.Contains(tenantID, ".") && !strings.ContainsAny(tenantID, " /\\") {
+		return nil
+	}
+
+	return clues.New("tenant ID must be a GUID or a verified domain name")
+}
+
+// tokenRoles extracts the application roles (ie: the consented app
+// permissions) from the claims of a jwt access token.
+func tokenRoles(token string) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, clues.New("malformed access token")
+	}
+
+	bs, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, clues.Wrap(err, "decoding access token claims")
+	}
+
+	// application tokens list their permissions as roles, while delegated
+	// tokens list the consented permissions as space-separated scopes.
+	claims := struct {
+		Roles  []string `json:"roles"`
+		Scopes string   `json:"scp"`
+	}{}
+
+	if err := json.Unmarshal(bs, &claims); err != nil {
+		return nil, clues.Wrap(err, "unmarshalling access token claims")
+	}
+
+	return append(claims.Roles, strings.Fields(claims.Scopes)...), nil
+}
+
+func missingPermissions(roles []string) []string {
+	missing := []string{}
+
+	for _, p := range RequiredPermissions {
+		if !slices.Contains(roles, p) {
+			missing = append(missing, p)
+		}
+	}
+
+	return missing
+}
+
+func probeUser(ctx context.Context, service graph.Servicer) error {
+	top := int32(1)
+	options := &users.UsersRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.UsersRequestBuilderGetQueryParameters{
+			Select: []string{"id"},
+			Top:    &top,
+		},
+	}
+
+	resp, err := service.Client().Users().Get(ctx, options)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	if len(resp.GetValue()) == 0 {
+		return clues.New("no users found in tenant")
+	}
+
+	return nil
+}
+
+func probeSite(ctx context.Context, service graph.Servicer) error {
+	top := int32(1)
+	options := &sites.SitesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &sites.SitesRequestBuilderGetQueryParameters{
+			Select: []string{"id"},
+			Top:    &top,
+		},
+	}
+
+	resp, err := service.Client().Sites().Get(ctx, options)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	if len(resp.GetValue()) == 0 {
+		return clues.New("no sites found in tenant")
+	}
+
+	return nil
+}
+
+// probeDrive retrieves the drive of the tenant's root site.  Every tenant
+// has a root site, whereas users only have a drive once they're licensed
+// for OneDrive, and have signed in to it.
+func probeDrive(ctx context.Context, service graph.Servicer) error {
+	_, err := service.Client().SitesById(rootSiteID).Drive().Get(ctx, nil)
+	if err != nil {
+		return clues.Stack(err).WithClues(ctx).With(graph.ErrData(err)...)
+	}
+
+	return nil
+}
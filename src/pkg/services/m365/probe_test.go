@@ -0,0 +1,77 @@
+package m365
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+type ProbeUnitSuite struct {
+	tester.Suite
+}
+
+func TestProbeUnitSuite(t *testing.T) {
+	suite.Run(t, &ProbeUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *ProbeUnitSuite) TestValidateTenantID() {
+	table := []struct {
+		name      string
+		tenant    string
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{"empty", "", assert.Error},
+		{"guid", "7a0ba5b1-0a9b-4d3e-9bd2-8f4e2a5c3f11", assert.NoError},
+		{"domain", "contoso.onmicrosoft.com", assert.NoError},
+		{"garbage", "not a tenant", assert.Error},
+		{"no dot", "contoso", assert.Error},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			test.expectErr(suite.T(), validateTenantID(test.tenant))
+		})
+	}
+}
+
+func (suite *ProbeUnitSuite) TestTokenRoles() {
+	t := suite.T()
+
+	enc := base64.RawURLEncoding.EncodeToString
+	token := enc([]byte(`{"alg":"none"}`)) + "." +
+		enc([]byte(`{"roles":["Mail.ReadWrite","User.Read.All"]}`)) + ".sig"
+
+	roles, err := tokenRoles(token)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Mail.ReadWrite", "User.Read.All"}, roles)
+
+	missing := missingPermissions(roles)
+	assert.NotContains(t, missing, "Mail.ReadWrite")
+	assert.Contains(t, missing, "Files.ReadWrite.All")
+
+	delegated := enc([]byte(`{"alg":"none"}`)) + "." +
+		enc([]byte(`{"scp":"Mail.ReadWrite User.Read.All"}`)) + ".sig"
+
+	roles, err = tokenRoles(delegated)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Mail.ReadWrite", "User.Read.All"}, roles)
+
+	_, err = tokenRoles("malformed")
+	assert.Error(t, err)
+}
+
+func (suite *ProbeUnitSuite) TestProbeReport_Passed() {
+	t := suite.T()
+
+	pr := ProbeReport{}
+	pr.pass(ProbeTenantID, "")
+	pr.skip(ProbeDrives)
+	assert.True(t, pr.Passed())
+
+	pr.fail(ProbeUsers, assert.AnError)
+	assert.False(t, pr.Passed())
+}
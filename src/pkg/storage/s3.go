@@ -2,6 +2,7 @@ package storage
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/alcionai/clues"
 	"github.com/pkg/errors"
@@ -15,6 +16,12 @@ type S3Config struct {
 	Prefix         string
 	DoNotUseTLS    bool
 	DoNotVerifyTLS bool
+	// DataStorageClass and MetadataStorageClass set the s3 storage class
+	// of item data blobs and of index and metadata blobs, respectively.
+	// They are only applied when the repository is initialized.  If empty,
+	// blobs use the bucket's default storage class.
+	DataStorageClass     string
+	MetadataStorageClass string
 }
 
 // config key consts
@@ -24,6 +31,8 @@ const (
 	keyS3Prefix         = "s3_prefix"
 	keyS3DoNotUseTLS    = "s3_donotusetls"
 	keyS3DoNotVerifyTLS = "s3_donotverifytls"
+	keyS3DataClass      = "s3_datastorageclass"
+	keyS3MetadataClass  = "s3_metadatastorageclass"
 )
 
 // config exported name consts
@@ -33,6 +42,37 @@ const (
 	Prefix         = "prefix"
 	DoNotUseTLS    = "donotusetls"
 	DoNotVerifyTLS = "donotverifytls"
+
+	DataStorageClass     = "datastorageclass"
+	MetadataStorageClass = "metadatastorageclass"
+)
+
+// s3 storage class consts
+const (
+	StorageClassStandard           = "STANDARD"
+	StorageClassStandardIA         = "STANDARD_IA"
+	StorageClassIntelligentTiering = "INTELLIGENT_TIERING"
+	StorageClassGlacierIR          = "GLACIER_IR"
+)
+
+var (
+	// kopia reads data blobs back at any time, during restores and
+	// maintenance, so their class must allow immediate retrieval.
+	// Archival classes (GLACIER, DEEP_ARCHIVE) are excluded.
+	dataStorageClasses = map[string]struct{}{
+		StorageClassStandard:           {},
+		StorageClassStandardIA:         {},
+		StorageClassIntelligentTiering: {},
+		StorageClassGlacierIR:          {},
+	}
+
+	// index and metadata blobs are read on every repository connection
+	// and are rewritten by each compaction, so classes with retrieval fees
+	// and minimum storage durations are excluded.
+	metadataStorageClasses = map[string]struct{}{
+		StorageClassStandard:           {},
+		StorageClassIntelligentTiering: {},
+	}
 )
 
 func (c S3Config) Normalize() S3Config {
@@ -42,6 +82,9 @@ func (c S3Config) Normalize() S3Config {
 		Prefix:         common.NormalizePrefix(c.Prefix),
 		DoNotUseTLS:    c.DoNotUseTLS,
 		DoNotVerifyTLS: c.DoNotVerifyTLS,
+
+		DataStorageClass:     strings.ToUpper(c.DataStorageClass),
+		MetadataStorageClass: strings.ToUpper(c.MetadataStorageClass),
 	}
 }
 
@@ -58,7 +101,15 @@ func (c S3Config) StringConfig() (map[string]string, error) {
 		keyS3DoNotVerifyTLS: strconv.FormatBool(cn.DoNotVerifyTLS),
 	}
 
-	return cfg, c.validate()
+	if len(cn.DataStorageClass) > 0 {
+		cfg[keyS3DataClass] = cn.DataStorageClass
+	}
+
+	if len(cn.MetadataStorageClass) > 0 {
+		cfg[keyS3MetadataClass] = cn.MetadataStorageClass
+	}
+
+	return cfg, cn.validate()
 }
 
 // S3Config retrieves the S3Config details from the Storage config.
@@ -71,6 +122,8 @@ func (s Storage) S3Config() (S3Config, error) {
 		c.Prefix = orEmptyString(s.Config[keyS3Prefix])
 		c.DoNotUseTLS = common.ParseBool(s.Config[keyS3DoNotUseTLS])
 		c.DoNotVerifyTLS = common.ParseBool(s.Config[keyS3DoNotVerifyTLS])
+		c.DataStorageClass = orEmptyString(s.Config[keyS3DataClass])
+		c.MetadataStorageClass = orEmptyString(s.Config[keyS3MetadataClass])
 	}
 
 	return c, c.validate()
@@ -86,5 +139,15 @@ func (c S3Config) validate() error {
 		}
 	}
 
+	if _, ok := dataStorageClasses[c.DataStorageClass]; len(c.DataStorageClass) > 0 && !ok {
+		return clues.Stack(errInvalidStorageClass, errors.New(DataStorageClass)).
+			With("storage_class", c.DataStorageClass)
+	}
+
+	if _, ok := metadataStorageClasses[c.MetadataStorageClass]; len(c.MetadataStorageClass) > 0 && !ok {
+		return clues.Stack(errInvalidStorageClass, errors.New(MetadataStorageClass)).
+			With("storage_class", c.MetadataStorageClass)
+	}
+
 	return nil
 }
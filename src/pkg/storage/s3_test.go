@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -169,3 +170,41 @@ func (suite *S3CfgSuite) TestStorage_S3Config_Normalize() {
 	assert.Equal(suite.T(), normalBkt, result.Bucket)
 	assert.NotEqual(suite.T(), st.Bucket, result.Bucket)
 }
+
+func (suite *S3CfgSuite) TestS3Config_StorageClasses() {
+	table := []struct {
+		name       string
+		data, meta string
+		expectErr  assert.ErrorAssertionFunc
+	}{
+		{"unset", "", "", assert.NoError},
+		{"standard", StorageClassStandard, StorageClassStandard, assert.NoError},
+		{"infrequent access data", StorageClassStandardIA, "", assert.NoError},
+		{"glacier instant retrieval data", StorageClassGlacierIR, StorageClassIntelligentTiering, assert.NoError},
+		{"lowercase", "standard_ia", "intelligent_tiering", assert.NoError},
+		{"archival data", "GLACIER", "", assert.Error},
+		{"deep archive data", "DEEP_ARCHIVE", "", assert.Error},
+		{"unknown data", "FAST", "", assert.Error},
+		{"infrequent access metadata", "", StorageClassStandardIA, assert.Error},
+		{"glacier instant retrieval metadata", "", StorageClassGlacierIR, assert.Error},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			cfg := goodS3Config
+			cfg.DataStorageClass = test.data
+			cfg.MetadataStorageClass = test.meta
+
+			st, err := NewStorage(ProviderS3, cfg)
+			test.expectErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			out, err := st.S3Config()
+			require.NoError(t, err)
+			assert.Equal(t, strings.ToUpper(test.data), out.DataStorageClass)
+			assert.Equal(t, strings.ToUpper(test.meta), out.MetadataStorageClass)
+		})
+	}
+}
@@ -17,7 +17,8 @@ const (
 
 // storage parsing errors
 var (
-	errMissingRequired = errors.New("missing required storage configuration")
+	errMissingRequired     = errors.New("missing required storage configuration")
+	errInvalidStorageClass = errors.New("unsupported s3 storage class")
 )
 
 // envvar consts
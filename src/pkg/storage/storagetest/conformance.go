@@ -0,0 +1,718 @@
+// Package storagetest holds the conformance suite that every storage
+// provider must pass before Corso can store repositories with it.
+//
+// Providers are exercised through the kopia blob.Storage they produce.
+// A provider's tests run the suite by handing it a constructor of empty,
+// isolated storage:
+//
+//	func TestMyProviderConformance(t *testing.T) {
+//		storagetest.RunConformance(t, func(t *testing.T) blob.Storage {
+//			return newEmptyStorage(t)
+//		}, storagetest.Options{})
+//	}
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/retrying"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+)
+
+const (
+	// defaultLargeObjectSize exceeds the multipart upload thresholds of
+	// the common object stores.
+	defaultLargeObjectSize = 64 << 20
+	// defaultListingCount exceeds the 1000 entry pages of S3 and
+	// S3-compatible listings.
+	defaultListingCount = 1001
+)
+
+// Options tune the conformance suite to the provider.
+type Options struct {
+	// LargeObjectSize is the size of the blob used to test large object
+	// handling.  Defaults to 64MiB.
+	LargeObjectSize int
+	// ListingCount is the number of blobs used to test listings that span
+	// more than one page.  Defaults to 1001.
+	ListingCount int
+	// Short skips the large object and multi-page listing tests.
+	Short bool
+}
+
+// NewStorageFunc produces empty storage for a single test.  Storage must
+// not be shared between tests, and is closed by the suite.
+type NewStorageFunc func(t *testing.T) blob.Storage
+
+// RunConformance runs the conformance suite against the storage produced
+// by newStorage.  The suite checks the guarantees kopia requires of blob
+// storage:
+//
+//   - read-after-write: written blobs are immediately readable and listed.
+//   - atomicity: partial writes are never observed, and overwrites replace
+//     the entire blob.
+//   - listing semantics: listings honor prefixes, report accurate lengths,
+//     and report each blob exactly once across pages.
+//   - large object handling: large blobs round trip intact, including
+//     ranged reads.
+//   - retry behavior: missing blobs and invalid ranges produce errors that
+//     aren't retried, and operations repeated after a lost response succeed.
+func RunConformance(t *testing.T, newStorage NewStorageFunc, opts Options) {
+	if opts.LargeObjectSize == 0 {
+		opts.LargeObjectSize = defaultLargeObjectSize
+	}
+
+	if opts.ListingCount == 0 {
+		opts.ListingCount = defaultListingCount
+	}
+
+	suite.Run(t, &conformanceSuite{newStorage: newStorage, opts: opts})
+}
+
+type conformanceSuite struct {
+	suite.Suite
+	newStorage NewStorageFunc
+	opts       Options
+}
+
+// storage produces empty storage for the current test, closing it when
+// the test completes.
+func (suite *conformanceSuite) storage(ctx context.Context) blob.Storage {
+	t := suite.T()
+
+	st := suite.newStorage(t)
+	require.NotNil(t, st, "storage")
+
+	t.Cleanup(func() {
+		assert.NoError(t, st.Close(ctx), "closing storage")
+	})
+
+	return st
+}
+
+// ---------------------------------------------------------------------------
+// read-after-write
+// ---------------------------------------------------------------------------
+
+func (suite *conformanceSuite) TestPutAndGet() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		st      = suite.storage(ctx)
+		content = randomBytes(t, 1024)
+		id      = blob.ID("conformance-put-get")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(content), blob.PutOptions{}))
+	assert.Equal(t, content, getBlob(ctx, t, st, id, 0, -1))
+
+	md, err := st.GetMetadata(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, md.BlobID)
+	assert.Equal(t, int64(len(content)), md.Length)
+	assert.WithinDuration(t, time.Now(), md.Timestamp, time.Hour, "timestamp within clock skew")
+
+	// empty blobs are valid.
+	empty := blob.ID("conformance-empty")
+	require.NoError(t, st.PutBlob(ctx, empty, Bytes(nil), blob.PutOptions{}))
+	assert.Empty(t, getBlob(ctx, t, st, empty, 0, -1))
+}
+
+func (suite *conformanceSuite) TestGetRange() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		st      = suite.storage(ctx)
+		content = randomBytes(t, 1024)
+		id      = blob.ID("conformance-range")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(content), blob.PutOptions{}))
+
+	table := []struct {
+		name           string
+		offset, length int64
+		expect         []byte
+	}{
+		{"head", 0, 10, content[:10]},
+		{"middle", 100, 200, content[100:300]},
+		{"tail", 1000, 24, content[1000:]},
+		{"entire blob", 0, 1024, content},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			assert.Equal(suite.T(), test.expect, getBlob(ctx, suite.T(), st, id, test.offset, test.length))
+		})
+	}
+}
+
+func (suite *conformanceSuite) TestDelete() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		st = suite.storage(ctx)
+		id = blob.ID("conformance-delete")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes([]byte("content")), blob.PutOptions{}))
+	require.NoError(t, st.DeleteBlob(ctx, id))
+
+	_, err := st.GetMetadata(ctx, id)
+	assert.ErrorIs(t, err, blob.ErrBlobNotFound)
+
+	ids := listIDs(ctx, t, st, "conformance-delete")
+	assert.Empty(t, ids, "deleted blobs aren't listed")
+}
+
+// ---------------------------------------------------------------------------
+// atomicity
+// ---------------------------------------------------------------------------
+
+func (suite *conformanceSuite) TestOverwrite() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		st = suite.storage(ctx)
+		id = blob.ID("conformance-overwrite")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(randomBytes(t, 2048)), blob.PutOptions{}))
+
+	// a shorter blob must not retain the tail of the previous contents.
+	content := randomBytes(t, 100)
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(content), blob.PutOptions{}))
+
+	assert.Equal(t, content, getBlob(ctx, t, st, id, 0, -1))
+	assert.Equal(t, []blob.ID{id}, listIDs(ctx, t, st, id))
+}
+
+func (suite *conformanceSuite) TestFailedPut() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		st       = suite.storage(ctx)
+		id       = blob.ID("conformance-failed-put")
+		previous = randomBytes(t, 1024)
+	)
+
+	table := []struct {
+		name     string
+		existing []byte
+	}{
+		{"new blob", nil},
+		{"existing blob", previous},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+
+			if test.existing != nil {
+				require.NoError(t, st.PutBlob(ctx, id, Bytes(test.existing), blob.PutOptions{}))
+			}
+
+			// cancelling the context when the data fails simulates a client
+			// that dies mid-upload, and keeps providers from retrying.
+			putCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			data := &failingBytes{data: randomBytes(t, 4096), failAt: 2048, onFail: cancel}
+
+			err := st.PutBlob(putCtx, id, data, blob.PutOptions{})
+			require.Error(t, err, "put with failing data")
+
+			if test.existing == nil {
+				_, err := st.GetMetadata(ctx, id)
+				assert.ErrorIs(t, err, blob.ErrBlobNotFound, "partial blob must not be stored")
+				assert.Empty(t, listIDs(ctx, t, st, id), "partial blob must not be listed")
+
+				return
+			}
+
+			assert.Equal(t, test.existing, getBlob(ctx, t, st, id, 0, -1), "previous contents must be intact")
+		})
+	}
+}
+
+func (suite *conformanceSuite) TestConcurrentOverwrites() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t        = suite.T()
+		st       = suite.storage(ctx)
+		id       = blob.ID("conformance-concurrent")
+		versions = [][]byte{
+			bytes.Repeat([]byte("a"), 256<<10),
+			bytes.Repeat([]byte("b"), 256<<10),
+		}
+		wg sync.WaitGroup
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(versions[0]), blob.PutOptions{}))
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func(v []byte) {
+			defer wg.Done()
+			assert.NoError(t, st.PutBlob(ctx, id, Bytes(v), blob.PutOptions{}))
+		}(versions[i%2])
+	}
+
+	// readers may observe either version, but never a mix of the two.
+	for i := 0; i < 8; i++ {
+		got := getBlob(ctx, t, st, id, 0, -1)
+		assert.Truef(
+			t,
+			bytes.Equal(got, versions[0]) || bytes.Equal(got, versions[1]),
+			"read %d observed a partial write", i)
+	}
+
+	wg.Wait()
+}
+
+// ---------------------------------------------------------------------------
+// listing semantics
+// ---------------------------------------------------------------------------
+
+func (suite *conformanceSuite) TestListPrefixes() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		st  = suite.storage(ctx)
+		ids = []blob.ID{"a1", "a2", "ab1", "b1", "ba"}
+	)
+
+	for i, id := range ids {
+		require.NoError(t, st.PutBlob(ctx, id, Bytes(make([]byte, i+1)), blob.PutOptions{}))
+	}
+
+	table := []struct {
+		prefix blob.ID
+		expect []blob.ID
+	}{
+		{"", ids},
+		{"a", []blob.ID{"a1", "a2", "ab1"}},
+		{"ab", []blob.ID{"ab1"}},
+		{"b1", []blob.ID{"b1"}},
+		{"c", []blob.ID{}},
+	}
+	for _, test := range table {
+		suite.Run(fmt.Sprintf("prefix %q", test.prefix), func() {
+			assert.Equal(suite.T(), test.expect, listIDs(ctx, suite.T(), st, test.prefix))
+		})
+	}
+
+	suite.Run("lengths", func() {
+		t := suite.T()
+
+		mds, err := blob.ListAllBlobs(ctx, st, "")
+		require.NoError(t, err)
+
+		for _, md := range mds {
+			for i, id := range ids {
+				if md.BlobID == id {
+					assert.Equal(t, int64(i+1), md.Length, md.BlobID)
+				}
+			}
+		}
+	})
+
+	suite.Run("callback error stops listing", func() {
+		t := suite.T()
+
+		var (
+			stop  = errors.New("stop")
+			calls int
+		)
+
+		err := st.ListBlobs(ctx, "", func(blob.Metadata) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func (suite *conformanceSuite) TestListManyBlobs() {
+	if suite.opts.Short {
+		suite.T().Skip("short conformance run")
+	}
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t      = suite.T()
+		st     = suite.storage(ctx)
+		expect = make([]blob.ID, 0, suite.opts.ListingCount)
+		ids    = make(chan blob.ID)
+		wg     sync.WaitGroup
+	)
+
+	for i := 0; i < suite.opts.ListingCount; i++ {
+		expect = append(expect, blob.ID(fmt.Sprintf("many-%06d", i)))
+	}
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for id := range ids {
+				assert.NoError(t, st.PutBlob(ctx, id, Bytes([]byte(id)), blob.PutOptions{}))
+			}
+		}()
+	}
+
+	for _, id := range expect {
+		ids <- id
+	}
+
+	close(ids)
+	wg.Wait()
+
+	// every blob must be listed exactly once across all pages.
+	assert.Equal(t, expect, listIDs(ctx, t, st, "many-"))
+}
+
+// ---------------------------------------------------------------------------
+// large object handling
+// ---------------------------------------------------------------------------
+
+func (suite *conformanceSuite) TestLargeObject() {
+	if suite.opts.Short {
+		suite.T().Skip("short conformance run")
+	}
+
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t       = suite.T()
+		st      = suite.storage(ctx)
+		size    = suite.opts.LargeObjectSize
+		content = randomBytes(t, size)
+		id      = blob.ID("conformance-large")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(content), blob.PutOptions{}))
+
+	md, err := st.GetMetadata(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), md.Length)
+
+	got := getBlob(ctx, t, st, id, 0, -1)
+	require.Len(t, got, size)
+	assert.True(t, bytes.Equal(content, got), "large blob round trips intact")
+
+	// ranged reads across the end of the blob's first upload part.
+	offset := int64(size/2 - 512)
+	got = getBlob(ctx, t, st, id, offset, 1024)
+	assert.True(t, bytes.Equal(content[offset:offset+1024], got), "ranged read of a large blob")
+}
+
+// ---------------------------------------------------------------------------
+// retry behavior
+// ---------------------------------------------------------------------------
+
+func (suite *conformanceSuite) TestPermanentErrors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t  = suite.T()
+		st = suite.storage(ctx)
+		id = blob.ID("conformance-permanent")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(randomBytes(t, 100)), blob.PutOptions{}))
+
+	// retrying a permanent error delays the failure by many seconds, so
+	// these must be recognized as permanent.
+	table := []struct {
+		name string
+		call func() error
+		// expect, when set, is the error the provider must produce.
+		// Otherwise any error is accepted.
+		expect error
+	}{
+		{
+			name: "get missing blob",
+			call: func() error {
+				return st.GetBlob(ctx, "conformance-missing", 0, -1, &OutputBuffer{})
+			},
+			expect: blob.ErrBlobNotFound,
+		},
+		{
+			name: "metadata of missing blob",
+			call: func() error {
+				_, err := st.GetMetadata(ctx, "conformance-missing")
+				return err
+			},
+			expect: blob.ErrBlobNotFound,
+		},
+		{
+			name: "range past the end",
+			call: func() error {
+				return st.GetBlob(ctx, id, 200, 10, &OutputBuffer{})
+			},
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			start := time.Now()
+			err := test.call()
+
+			require.Error(suite.T(), err)
+
+			if test.expect != nil {
+				assert.ErrorIs(suite.T(), err, test.expect)
+			}
+
+			assert.Less(suite.T(), time.Since(start), 5*time.Second, "error was retried")
+		})
+	}
+
+	suite.Run("delete missing blob", func() {
+		assert.NoError(suite.T(), st.DeleteBlob(ctx, "conformance-missing"))
+	})
+}
+
+func (suite *conformanceSuite) TestRetryAfterLostResponse() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t = suite.T()
+		// each operation reaches the provider, but its first response is
+		// lost, so the retrying wrapper repeats it.
+		lossy   = &lostResponseStorage{Storage: suite.storage(ctx)}
+		st      = retrying.NewWrapper(lossy)
+		content = randomBytes(t, 1024)
+		id      = blob.ID("conformance-retry")
+	)
+
+	require.NoError(t, st.PutBlob(ctx, id, Bytes(content), blob.PutOptions{}), "repeated put")
+	assert.Equal(t, content, getBlob(ctx, t, st, id, 0, -1), "read after repeated put")
+	assert.Equal(t, []blob.ID{id}, listIDs(ctx, t, st, id), "repeated put stores one blob")
+
+	require.NoError(t, st.DeleteBlob(ctx, id), "repeated delete")
+
+	_, err := lossy.Storage.GetMetadata(ctx, id)
+	assert.ErrorIs(t, err, blob.ErrBlobNotFound)
+	assert.Equal(t, 2, lossy.lost, "responses lost")
+}
+
+// ---------------------------------------------------------------------------
+// helpers
+// ---------------------------------------------------------------------------
+
+var errLostResponse = errors.New("response lost")
+
+// lostResponseStorage completes the first put and delete of each blob, but
+// reports them as failed.
+type lostResponseStorage struct {
+	blob.Storage
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+	lost int
+}
+
+func (s *lostResponseStorage) loseFirst(op string, id blob.ID, err error) error {
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen == nil {
+		s.seen = map[string]struct{}{}
+	}
+
+	key := op + string(id)
+	if _, ok := s.seen[key]; ok {
+		return nil
+	}
+
+	s.seen[key] = struct{}{}
+	s.lost++
+
+	return errLostResponse
+}
+
+func (s *lostResponseStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Bytes, opts blob.PutOptions) error {
+	return s.loseFirst("put", id, s.Storage.PutBlob(ctx, id, data, opts))
+}
+
+func (s *lostResponseStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
+	return s.loseFirst("delete", id, s.Storage.DeleteBlob(ctx, id))
+}
+
+func getBlob(
+	ctx context.Context,
+	t *testing.T,
+	st blob.Reader,
+	id blob.ID,
+	offset, length int64,
+) []byte {
+	out := &OutputBuffer{}
+
+	err := st.GetBlob(ctx, id, offset, length, out)
+	require.NoError(t, err, "getting blob", id)
+
+	return out.Bytes()
+}
+
+func listIDs(ctx context.Context, t *testing.T, st blob.Reader, prefix blob.ID) []blob.ID {
+	var (
+		ids  = []blob.ID{}
+		seen = map[blob.ID]struct{}{}
+	)
+
+	err := st.ListBlobs(ctx, prefix, func(md blob.Metadata) error {
+		if _, ok := seen[md.BlobID]; ok {
+			return errors.Errorf("blob %s listed more than once", md.BlobID)
+		}
+
+		seen[md.BlobID] = struct{}{}
+		ids = append(ids, md.BlobID)
+
+		return nil
+	})
+	require.NoError(t, err, "listing blobs")
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	bs := make([]byte, n)
+
+	_, err := rand.Read(bs)
+	require.NoError(t, err)
+
+	return bs
+}
+
+// Bytes implements kopia's blob.Bytes over a byte slice.
+type Bytes []byte
+
+func (b Bytes) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (b Bytes) Length() int {
+	return len(b)
+}
+
+func (b Bytes) Reader() io.ReadSeekCloser {
+	return nopCloser{bytes.NewReader(b)}
+}
+
+// OutputBuffer implements kopia's blob.OutputBuffer.
+type OutputBuffer struct {
+	bytes.Buffer
+}
+
+func (ob *OutputBuffer) Length() int {
+	return ob.Len()
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+var errDataFailed = errors.New("data source failed")
+
+// failingBytes produces data that fails part of the way through.
+type failingBytes struct {
+	data   []byte
+	failAt int
+	onFail func()
+}
+
+func (fb *failingBytes) fail() error {
+	fb.onFail()
+	return errDataFailed
+}
+
+func (fb *failingBytes) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(fb.data[:fb.failAt])
+	if err != nil {
+		return int64(n), err
+	}
+
+	return int64(n), fb.fail()
+}
+
+func (fb *failingBytes) Length() int {
+	return len(fb.data)
+}
+
+func (fb *failingBytes) Reader() io.ReadSeekCloser {
+	return &failingReader{ReadSeeker: bytes.NewReader(fb.data), fb: fb}
+}
+
+type failingReader struct {
+	io.ReadSeeker
+	fb *failingBytes
+}
+
+func (fr *failingReader) Read(p []byte) (int, error) {
+	pos, err := fr.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := int64(fr.fb.failAt) - pos
+	if remaining <= 0 {
+		return 0, fr.fb.fail()
+	}
+
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	return fr.ReadSeeker.Read(p)
+}
+
+func (fr *failingReader) Close() error {
+	return nil
+}
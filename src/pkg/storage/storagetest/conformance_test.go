@@ -0,0 +1,32 @@
+package storagetest_test
+
+import (
+	"testing"
+
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/filesystem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/storage/storagetest"
+)
+
+// TestFilesystemConformance keeps the suite honest by running it against
+// kopia's filesystem provider.
+func TestFilesystemConformance(t *testing.T) {
+	storagetest.RunConformance(
+		t,
+		func(t *testing.T) blob.Storage {
+			ctx, flush := tester.NewContext()
+			defer flush()
+
+			st, err := filesystem.New(ctx, &filesystem.Options{Path: t.TempDir()}, true)
+			require.NoError(t, err)
+
+			return st
+		},
+		storagetest.Options{
+			LargeObjectSize: 8 << 20,
+			Short:           testing.Short(),
+		})
+}
@@ -36,6 +36,14 @@ func Service(pst path.ServiceType) FilterOption {
 	}
 }
 
+// Staging ensures the retrieved backups were all created
+// in staging repositories.
+func Staging() FilterOption {
+	return func(qf *queryFilters) {
+		qf.tags[model.StagingTag] = "true"
+	}
+}
+
 // GetBackup gets a single backup by id.
 func (w Wrapper) GetBackup(ctx context.Context, backupID model.StableID) (*backup.Backup, error) {
 	b := backup.Backup{}
@@ -82,6 +90,22 @@ func (w Wrapper) DeleteBackup(ctx context.Context, backupID model.StableID) erro
 	return w.Delete(ctx, model.BackupSchema, backupID)
 }
 
+// AnnotateBackup replaces the notes attached to the backup.
+func (w Wrapper) AnnotateBackup(ctx context.Context, backupID model.StableID, notes string) error {
+	b, err := w.GetBackup(ctx, backupID)
+	if err != nil {
+		return err
+	}
+
+	b.Notes = notes
+
+	if err := w.Update(ctx, model.BackupSchema, b); err != nil {
+		return errors.Wrap(err, "updating backup notes")
+	}
+
+	return nil
+}
+
 // GetDetailsFromBackupID retrieves the backup.Details within the specified backup.
 func (w Wrapper) GetDetailsIDFromBackupID(
 	ctx context.Context,
@@ -170,3 +170,40 @@ func (suite *StoreBackupUnitSuite) TestGetDetailsIDFromBackupID() {
 		})
 	}
 }
+
+func (suite *StoreBackupUnitSuite) TestAnnotateBackup() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	table := []struct {
+		name   string
+		mock   *storeMock.MockModelStore
+		expect assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "annotates backup",
+			mock:   storeMock.NewMock(&bu, nil),
+			expect: assert.NoError,
+		},
+		{
+			name:   "errors",
+			mock:   storeMock.NewMock(&bu, assert.AnError),
+			expect: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.T().Run(test.name, func(t *testing.T) {
+			sm := &store.Wrapper{Storer: test.mock}
+			err := sm.AnnotateBackup(ctx, bu.ID, "pre-migration snapshot")
+			test.expect(t, err)
+			if err != nil {
+				return
+			}
+
+			result, err := sm.GetBackup(ctx, bu.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "pre-migration snapshot", result.Notes)
+			assert.Empty(t, bu.Notes, "original backup is not modified")
+		})
+	}
+}
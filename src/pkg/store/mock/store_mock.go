@@ -8,6 +8,7 @@ import (
 
 	"github.com/alcionai/corso/src/internal/model"
 	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/control"
 )
 
 // ------------------------------------------------------------
@@ -15,8 +16,12 @@ import (
 // ------------------------------------------------------------
 
 type MockModelStore struct {
-	backup *backup.Backup
-	err    error
+	backup   *backup.Backup
+	trend    *backup.Trend
+	policy   *control.RestorePolicy
+	approval *control.RestoreApproval
+	quota    *control.RepoQuota
+	err      error
 }
 
 func NewMock(b *backup.Backup, err error) *MockModelStore {
@@ -26,6 +31,26 @@ func NewMock(b *backup.Backup, err error) *MockModelStore {
 	}
 }
 
+// Trend produces the backup trend held by the mock, if any.
+func (mms *MockModelStore) Trend() *backup.Trend {
+	return mms.trend
+}
+
+// RestorePolicy produces the restore policy held by the mock, if any.
+func (mms *MockModelStore) RestorePolicy() *control.RestorePolicy {
+	return mms.policy
+}
+
+// RestoreApproval produces the restore approval held by the mock, if any.
+func (mms *MockModelStore) RestoreApproval() *control.RestoreApproval {
+	return mms.approval
+}
+
+// RepoQuota produces the repository quota held by the mock, if any.
+func (mms *MockModelStore) RepoQuota() *control.RepoQuota {
+	return mms.quota
+}
+
 // ------------------------------------------------------------
 // deleter iface
 // ------------------------------------------------------------
@@ -57,6 +82,22 @@ func (mms *MockModelStore) Get(
 		bm := data.(*backup.Backup)
 		*bm = *mms.backup
 
+	case model.BackupTrendSchema:
+		if mms.trend == nil {
+			return errors.New("no trend in mock")
+		}
+
+		tm := data.(*backup.Trend)
+		*tm = *mms.trend
+
+	case model.RestorePolicySchema:
+		if mms.policy == nil {
+			return errors.New("no restore policy in mock")
+		}
+
+		pm := data.(*control.RestorePolicy)
+		*pm = *mms.policy
+
 	default:
 		return errors.Errorf("schema %s not supported by mock Get", s)
 	}
@@ -77,6 +118,42 @@ func (mms *MockModelStore) GetIDsForType(
 	case model.BackupSchema:
 		b := *mms.backup
 		return []*model.BaseModel{&b.BaseModel}, nil
+
+	case model.BackupTrendSchema:
+		if mms.trend == nil {
+			return []*model.BaseModel{}, nil
+		}
+
+		t := *mms.trend
+
+		return []*model.BaseModel{&t.BaseModel}, nil
+
+	case model.RestorePolicySchema:
+		if mms.policy == nil {
+			return []*model.BaseModel{}, nil
+		}
+
+		p := *mms.policy
+
+		return []*model.BaseModel{&p.BaseModel}, nil
+
+	case model.RestoreApprovalSchema:
+		if mms.approval == nil || mms.approval.Tags[model.RestoreIDTag] != tags[model.RestoreIDTag] {
+			return []*model.BaseModel{}, nil
+		}
+
+		a := *mms.approval
+
+		return []*model.BaseModel{&a.BaseModel}, nil
+
+	case model.RepoQuotaSchema:
+		if mms.quota == nil {
+			return []*model.BaseModel{}, nil
+		}
+
+		q := *mms.quota
+
+		return []*model.BaseModel{&q.BaseModel}, nil
 	}
 
 	return nil, errors.Errorf("schema %s not supported by mock GetIDsForType", s)
@@ -97,6 +174,38 @@ func (mms *MockModelStore) GetWithModelStoreID(
 		bm := data.(*backup.Backup)
 		*bm = *mms.backup
 
+	case model.BackupTrendSchema:
+		if mms.trend == nil {
+			return errors.New("no trend in mock")
+		}
+
+		tm := data.(*backup.Trend)
+		*tm = *mms.trend
+
+	case model.RestorePolicySchema:
+		if mms.policy == nil {
+			return errors.New("no restore policy in mock")
+		}
+
+		pm := data.(*control.RestorePolicy)
+		*pm = *mms.policy
+
+	case model.RestoreApprovalSchema:
+		if mms.approval == nil {
+			return errors.New("no restore approval in mock")
+		}
+
+		am := data.(*control.RestoreApproval)
+		*am = *mms.approval
+
+	case model.RepoQuotaSchema:
+		if mms.quota == nil {
+			return errors.New("no repository quota in mock")
+		}
+
+		qm := data.(*control.RepoQuota)
+		*qm = *mms.quota
+
 	default:
 		return errors.Errorf("schema %s not supported by mock GetWithModelStoreID", s)
 	}
@@ -114,6 +223,26 @@ func (mms *MockModelStore) Put(ctx context.Context, s model.Schema, m model.Mode
 		bm := m.(*backup.Backup)
 		mms.backup = bm
 
+	case model.BackupTrendSchema:
+		tm := m.(*backup.Trend)
+		tm.ModelStoreID = manifest.ID("trend")
+		mms.trend = tm
+
+	case model.RestorePolicySchema:
+		pm := m.(*control.RestorePolicy)
+		pm.ModelStoreID = manifest.ID("restore-policy")
+		mms.policy = pm
+
+	case model.RestoreApprovalSchema:
+		am := m.(*control.RestoreApproval)
+		am.ModelStoreID = manifest.ID("restore-approval")
+		mms.approval = am
+
+	case model.RepoQuotaSchema:
+		qm := m.(*control.RepoQuota)
+		qm.ModelStoreID = manifest.ID("repo-quota")
+		mms.quota = qm
+
 	default:
 		return errors.Errorf("schema %s not supported by mock Put", s)
 	}
@@ -127,6 +256,22 @@ func (mms *MockModelStore) Update(ctx context.Context, s model.Schema, m model.M
 		bm := m.(*backup.Backup)
 		mms.backup = bm
 
+	case model.BackupTrendSchema:
+		tm := m.(*backup.Trend)
+		mms.trend = tm
+
+	case model.RestorePolicySchema:
+		pm := m.(*control.RestorePolicy)
+		mms.policy = pm
+
+	case model.RestoreApprovalSchema:
+		am := m.(*control.RestoreApproval)
+		mms.approval = am
+
+	case model.RepoQuotaSchema:
+		qm := m.(*control.RepoQuota)
+		mms.quota = qm
+
 	default:
 		return errors.Errorf("schema %s not supported by mock Update", s)
 	}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+// GetRepoQuota retrieves the repository's quota.  Produces an empty,
+// unsaved quota if none has been set.
+func (w Wrapper) GetRepoQuota(ctx context.Context) (*control.RepoQuota, error) {
+	bms, err := w.GetIDsForType(ctx, model.RepoQuotaSchema, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "looking up repository quota")
+	}
+
+	if len(bms) == 0 {
+		return &control.RepoQuota{}, nil
+	}
+
+	q := &control.RepoQuota{}
+
+	if err := w.GetWithModelStoreID(ctx, model.RepoQuotaSchema, bms[0].ModelStoreID, q); err != nil {
+		return nil, errors.Wrap(err, "getting repository quota")
+	}
+
+	return q, nil
+}
+
+// SetRepoQuota replaces the limits of the repository's quota.  The cached
+// repository size is retained.  Setting empty limits removes the quota.
+func (w Wrapper) SetRepoQuota(ctx context.Context, limits control.QuotaOptions) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	q, err := w.GetRepoQuota(ctx)
+	if err != nil {
+		return err
+	}
+
+	q.Limits = limits
+
+	return w.SaveRepoQuota(ctx, q)
+}
+
+// SaveRepoQuota stores the quota, including its cached repository size.
+func (w Wrapper) SaveRepoQuota(ctx context.Context, q *control.RepoQuota) error {
+	var err error
+
+	if len(q.ModelStoreID) == 0 {
+		err = w.Put(ctx, model.RepoQuotaSchema, q)
+	} else {
+		err = w.Update(ctx, model.RepoQuotaSchema, q)
+	}
+
+	return errors.Wrap(err, "saving repository quota")
+}
@@ -0,0 +1,79 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/store"
+	storeMock "github.com/alcionai/corso/src/pkg/store/mock"
+)
+
+type StoreQuotaUnitSuite struct {
+	tester.Suite
+}
+
+func TestStoreQuotaUnitSuite(t *testing.T) {
+	suite.Run(t, &StoreQuotaUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StoreQuotaUnitSuite) TestSetRepoQuota() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		mms = storeMock.NewMock(&bu, nil)
+		sw  = &store.Wrapper{Storer: mms}
+		now = time.Now()
+	)
+
+	q, err := sw.GetRepoQuota(ctx)
+	require.NoError(t, err)
+	assert.False(t, q.Limits.Enabled(), "no quota set yet")
+	assert.Empty(t, q.ModelStoreID)
+
+	err = sw.SetRepoQuota(ctx, control.QuotaOptions{SoftLimitBytes: 100})
+	require.NoError(t, err)
+	require.NotNil(t, mms.RepoQuota())
+	assert.NotEmpty(t, mms.RepoQuota().ModelStoreID)
+
+	q, err = sw.GetRepoQuota(ctx)
+	require.NoError(t, err)
+
+	q.Size = 50
+	q.MeasuredAt = now
+
+	require.NoError(t, sw.SaveRepoQuota(ctx, q))
+
+	err = sw.SetRepoQuota(ctx, control.QuotaOptions{HardLimitBytes: 200, FailAtHardLimit: true})
+	require.NoError(t, err)
+
+	q, err = sw.GetRepoQuota(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, q.Limits.SoftLimitBytes, "limits are replaced")
+	assert.Equal(t, int64(200), q.Limits.HardLimitBytes)
+	assert.True(t, q.Limits.FailAtHardLimit)
+	assert.Equal(t, int64(50), q.Size, "the cached size is retained")
+	assert.Equal(t, now, q.MeasuredAt)
+}
+
+func (suite *StoreQuotaUnitSuite) TestSetRepoQuota_errors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	sw := &store.Wrapper{Storer: storeMock.NewMock(&bu, nil)}
+	err := sw.SetRepoQuota(ctx, control.QuotaOptions{SoftLimitBytes: 200, HardLimitBytes: 100})
+	assert.Error(t, err, "soft limit over the hard limit")
+
+	sw = &store.Wrapper{Storer: storeMock.NewMock(&bu, assert.AnError)}
+	err = sw.SetRepoQuota(ctx, control.QuotaOptions{SoftLimitBytes: 100})
+	assert.Error(t, err, "store failure")
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/alcionai/clues"
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+var (
+	ErrNoApprovalRequest = errors.New("the restore has no approval request")
+	ErrSelfApproval      = errors.New("a restore can't be approved by the person who requested it")
+)
+
+// refresher is fulfilled by stores that cache their contents, and can
+// reload them to observe the writes of other processes.
+type refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// Refresh reloads the contents of the store, if it caches them, so that
+// models written by other processes become visible.
+func (w Wrapper) Refresh(ctx context.Context) error {
+	if r, ok := w.Storer.(refresher); ok {
+		return errors.Wrap(r.Refresh(ctx), "refreshing model store")
+	}
+
+	return nil
+}
+
+// GetRestoreApproval retrieves the approval request of the restore.
+// Produces nil if the restore never requested approval.
+func (w Wrapper) GetRestoreApproval(
+	ctx context.Context,
+	restoreID model.StableID,
+) (*control.RestoreApproval, error) {
+	bms, err := w.GetIDsForType(
+		ctx,
+		model.RestoreApprovalSchema,
+		map[string]string{model.RestoreIDTag: string(restoreID)})
+	if err != nil {
+		return nil, errors.Wrap(err, "looking up restore approval")
+	}
+
+	if len(bms) == 0 {
+		return nil, nil
+	}
+
+	ra := &control.RestoreApproval{}
+
+	if err := w.GetWithModelStoreID(ctx, model.RestoreApprovalSchema, bms[0].ModelStoreID, ra); err != nil {
+		return nil, errors.Wrap(err, "getting restore approval")
+	}
+
+	return ra, nil
+}
+
+// RequestRestoreApproval stores a new, unapproved approval request.
+func (w Wrapper) RequestRestoreApproval(ctx context.Context, ra *control.RestoreApproval) error {
+	if ra.Tags == nil {
+		ra.Tags = map[string]string{}
+	}
+
+	ra.Tags[model.RestoreIDTag] = string(ra.RestoreID)
+	ra.Approved = false
+	ra.RequestedAt = time.Now()
+
+	return errors.Wrap(w.Put(ctx, model.RestoreApprovalSchema, ra), "storing restore approval")
+}
+
+// ApproveRestore approves the pending approval request of the restore on
+// behalf of the approver.  Approvers can't approve their own requests.
+func (w Wrapper) ApproveRestore(
+	ctx context.Context,
+	restoreID model.StableID,
+	approver string,
+) error {
+	ra, err := w.GetRestoreApproval(ctx, restoreID)
+	if err != nil {
+		return err
+	}
+
+	if ra == nil {
+		return clues.Stack(ErrNoApprovalRequest).With("restore_id", restoreID)
+	}
+
+	if ra.Approved {
+		return nil
+	}
+
+	if len(ra.RequestedBy) > 0 && ra.RequestedBy == approver {
+		return clues.Stack(ErrSelfApproval).With("restore_id", restoreID)
+	}
+
+	ra.Approved = true
+	ra.ApprovedAt = time.Now()
+	ra.ApprovedBy = approver
+
+	return errors.Wrap(w.Update(ctx, model.RestoreApprovalSchema, ra), "approving restore")
+}
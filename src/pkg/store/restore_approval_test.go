@@ -0,0 +1,73 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/store"
+	storeMock "github.com/alcionai/corso/src/pkg/store/mock"
+)
+
+type StoreRestoreApprovalUnitSuite struct {
+	tester.Suite
+}
+
+func TestStoreRestoreApprovalUnitSuite(t *testing.T) {
+	suite.Run(t, &StoreRestoreApprovalUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StoreRestoreApprovalUnitSuite) TestApproveRestore() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		mms = storeMock.NewMock(&bu, nil)
+		sw  = &store.Wrapper{Storer: mms}
+	)
+
+	ra, err := sw.GetRestoreApproval(ctx, "rid")
+	require.NoError(t, err)
+	assert.Nil(t, ra, "no approval requested yet")
+
+	err = sw.ApproveRestore(ctx, "rid", "approver@host")
+	assert.ErrorIs(t, err, store.ErrNoApprovalRequest)
+
+	err = sw.RequestRestoreApproval(ctx, &control.RestoreApproval{
+		BaseModel:   model.BaseModel{ID: "aid"},
+		RestoreID:   "rid",
+		Items:       10,
+		RequestedBy: "requester@host",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mms.RestoreApproval())
+	assert.False(t, mms.RestoreApproval().Approved)
+	assert.False(t, mms.RestoreApproval().RequestedAt.IsZero())
+
+	ra, err = sw.GetRestoreApproval(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, ra, "approvals are looked up by restore")
+
+	err = sw.ApproveRestore(ctx, "rid", "requester@host")
+	assert.ErrorIs(t, err, store.ErrSelfApproval, "requesters can't approve their own restores")
+
+	err = sw.ApproveRestore(ctx, "rid", "approver@host")
+	require.NoError(t, err)
+
+	ra, err = sw.GetRestoreApproval(ctx, "rid")
+	require.NoError(t, err)
+	require.NotNil(t, ra)
+	assert.True(t, ra.Approved)
+	assert.False(t, ra.ApprovedAt.IsZero())
+	assert.Equal(t, 10, ra.Items)
+	assert.Equal(t, "approver@host", ra.ApprovedBy)
+
+	// approving twice is harmless.
+	assert.NoError(t, sw.ApproveRestore(ctx, "rid", "approver@host"))
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/control"
+)
+
+// GetRestorePolicy retrieves the repository's restore policy.  Produces
+// an empty, unsaved policy if none has been set.
+func (w Wrapper) GetRestorePolicy(ctx context.Context) (*control.RestorePolicy, error) {
+	bms, err := w.GetIDsForType(ctx, model.RestorePolicySchema, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "looking up restore policy")
+	}
+
+	if len(bms) == 0 {
+		return &control.RestorePolicy{}, nil
+	}
+
+	p := &control.RestorePolicy{}
+
+	if err := w.GetWithModelStoreID(ctx, model.RestorePolicySchema, bms[0].ModelStoreID, p); err != nil {
+		return nil, errors.Wrap(err, "getting restore policy")
+	}
+
+	return p, nil
+}
+
+// SetRestorePolicy replaces the rules of the repository's restore policy.
+// Setting a policy without rules permits restores to any destination.
+func (w Wrapper) SetRestorePolicy(ctx context.Context, rules control.RestorePolicy) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	p, err := w.GetRestorePolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.ContainerSubtree = rules.ContainerSubtree
+	p.ContainerPrefix = rules.ContainerPrefix
+	p.ContainerPattern = rules.ContainerPattern
+	p.AllowInPlace = rules.AllowInPlace
+	p.Approval = rules.Approval
+
+	if len(p.ModelStoreID) == 0 {
+		err = w.Put(ctx, model.RestorePolicySchema, p)
+	} else {
+		err = w.Update(ctx, model.RestorePolicySchema, p)
+	}
+
+	return errors.Wrap(err, "saving restore policy")
+}
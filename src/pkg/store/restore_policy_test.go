@@ -0,0 +1,79 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/control"
+	"github.com/alcionai/corso/src/pkg/store"
+	storeMock "github.com/alcionai/corso/src/pkg/store/mock"
+)
+
+type StoreRestorePolicyUnitSuite struct {
+	tester.Suite
+}
+
+func TestStoreRestorePolicyUnitSuite(t *testing.T) {
+	suite.Run(t, &StoreRestorePolicyUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StoreRestorePolicyUnitSuite) TestSetRestorePolicy() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		mms = storeMock.NewMock(&bu, nil)
+		sw  = &store.Wrapper{Storer: mms}
+	)
+
+	p, err := sw.GetRestorePolicy(ctx)
+	require.NoError(t, err)
+	assert.True(t, p.IsEmpty(), "no policy set yet")
+	assert.Empty(t, p.ModelStoreID)
+
+	err = sw.SetRestorePolicy(ctx, control.RestorePolicy{ContainerPrefix: "Corso_Restore_"})
+	require.NoError(t, err)
+	require.NotNil(t, mms.RestorePolicy())
+	assert.NotEmpty(t, mms.RestorePolicy().ModelStoreID)
+
+	err = sw.SetRestorePolicy(ctx, control.RestorePolicy{
+		ContainerPattern: "Restores-.*",
+		AllowInPlace:     true,
+		Approval:         control.ApprovalPolicy{MaxItems: 10, Webhook: "https://hooks.example.com/corso"},
+	})
+	require.NoError(t, err)
+
+	p, err = sw.GetRestorePolicy(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, p.ContainerPrefix, "rules are replaced")
+	assert.Equal(t, "Restores-.*", p.ContainerPattern)
+	assert.True(t, p.AllowInPlace)
+	assert.Equal(t, 10, p.Approval.MaxItems)
+	assert.Equal(t, "https://hooks.example.com/corso", p.Approval.Webhook)
+}
+
+func (suite *StoreRestorePolicyUnitSuite) TestSetRestorePolicy_errors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	sw := &store.Wrapper{Storer: storeMock.NewMock(&bu, nil)}
+	err := sw.SetRestorePolicy(ctx, control.RestorePolicy{ContainerPattern: "("})
+	assert.Error(t, err, "malformed pattern")
+
+	err = sw.SetRestorePolicy(ctx, control.RestorePolicy{Approval: control.ApprovalPolicy{MaxItems: -1}})
+	assert.Error(t, err, "negative threshold")
+
+	err = sw.SetRestorePolicy(ctx, control.RestorePolicy{Approval: control.ApprovalPolicy{Webhook: "hooks.example.com"}})
+	assert.Error(t, err, "webhook without a scheme")
+
+	sw = &store.Wrapper{Storer: storeMock.NewMock(&bu, assert.AnError)}
+	err = sw.SetRestorePolicy(ctx, control.RestorePolicy{ContainerPrefix: "Corso_Restore_"})
+	assert.Error(t, err, "store failure")
+}
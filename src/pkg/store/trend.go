@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/corso/src/internal/model"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/path"
+)
+
+// GetTrend retrieves the backup trend of the owner within the service.
+// Produces an empty, unsaved trend if no backups have been recorded for
+// the owner.
+func (w Wrapper) GetTrend(
+	ctx context.Context,
+	service path.ServiceType,
+	owner string,
+) (*backup.Trend, error) {
+	bms, err := w.GetIDsForType(ctx, model.BackupTrendSchema, backup.TrendTags(service, owner))
+	if err != nil {
+		return nil, errors.Wrap(err, "looking up backup trend")
+	}
+
+	if len(bms) == 0 {
+		return backup.NewTrend(service, owner), nil
+	}
+
+	t := &backup.Trend{}
+
+	if err := w.GetWithModelStoreID(ctx, model.BackupTrendSchema, bms[0].ModelStoreID, t); err != nil {
+		return nil, errors.Wrap(err, "getting backup trend")
+	}
+
+	return t, nil
+}
+
+// AddTrendPoint records the point in the backup trend of the owner
+// within the service, creating the trend if it doesn't exist.
+func (w Wrapper) AddTrendPoint(
+	ctx context.Context,
+	service path.ServiceType,
+	owner string,
+	p backup.TrendPoint,
+) error {
+	t, err := w.GetTrend(ctx, service, owner)
+	if err != nil {
+		return err
+	}
+
+	t.Add(p)
+
+	if len(t.ModelStoreID) == 0 {
+		err = w.Put(ctx, model.BackupTrendSchema, t)
+	} else {
+		err = w.Update(ctx, model.BackupTrendSchema, t)
+	}
+
+	return errors.Wrap(err, "saving backup trend")
+}
@@ -0,0 +1,68 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/backup"
+	"github.com/alcionai/corso/src/pkg/path"
+	"github.com/alcionai/corso/src/pkg/store"
+	storeMock "github.com/alcionai/corso/src/pkg/store/mock"
+)
+
+type StoreTrendUnitSuite struct {
+	tester.Suite
+}
+
+func TestStoreTrendUnitSuite(t *testing.T) {
+	suite.Run(t, &StoreTrendUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *StoreTrendUnitSuite) TestAddTrendPoint() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	var (
+		t   = suite.T()
+		now = time.Now()
+		mms = storeMock.NewMock(&bu, nil)
+		sw  = &store.Wrapper{Storer: mms}
+	)
+
+	tr, err := sw.GetTrend(ctx, path.ExchangeService, "owner")
+	require.NoError(t, err)
+	assert.Empty(t, tr.Points, "no trend recorded yet")
+	assert.Empty(t, tr.ModelStoreID)
+
+	err = sw.AddTrendPoint(ctx, path.ExchangeService, "owner", backup.TrendPoint{BackupID: "a", StartedAt: now})
+	require.NoError(t, err)
+	require.NotNil(t, mms.Trend())
+	assert.NotEmpty(t, mms.Trend().ModelStoreID)
+
+	err = sw.AddTrendPoint(
+		ctx,
+		path.ExchangeService,
+		"owner",
+		backup.TrendPoint{BackupID: "b", StartedAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	tr, err = sw.GetTrend(ctx, path.ExchangeService, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, "owner", tr.ResourceOwner)
+	assert.Len(t, tr.Points, 2)
+}
+
+func (suite *StoreTrendUnitSuite) TestAddTrendPoint_errors() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	sw := &store.Wrapper{Storer: storeMock.NewMock(&bu, assert.AnError)}
+
+	err := sw.AddTrendPoint(ctx, path.ExchangeService, "owner", backup.TrendPoint{BackupID: "a"})
+	assert.Error(suite.T(), err)
+}
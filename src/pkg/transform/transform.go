@@ -0,0 +1,142 @@
+// Package transform provides hooks that alter items as they are restored,
+// allowing light-touch migrations (renaming items, reorganizing folders,
+// slimming down mail) without post-processing the restored data in M365.
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/alcionai/clues"
+)
+
+// Hook transforms each item before it is restored.  Hooks are called
+// concurrently, and must not modify their arguments.
+type Hook interface {
+	// RestoreFolders produces the names of the folders, beneath the restore
+	// destination, that hold the restored item.  Receives the item's folders
+	// as they were backed up.
+	RestoreFolders(folders []string) []string
+	// RestoreName produces the name given to the restored item: the subject
+	// of emails, events, and tasks, or the file name of drive items.
+	RestoreName(name string) string
+	// KeepAttachments returns false if the attachments of emails and events
+	// should be left out of the restore.
+	KeepAttachments() bool
+}
+
+var (
+	_ Hook = none{}
+	_ Hook = &Rules{}
+)
+
+// none leaves items unchanged.
+type none struct{}
+
+func (none) RestoreFolders(folders []string) []string { return folders }
+func (none) RestoreName(name string) string           { return name }
+func (none) KeepAttachments() bool                    { return true }
+
+// ---------------------------------------------------------------------------
+// Rules
+// ---------------------------------------------------------------------------
+
+// Rules is a Hook configured by a small set of declarative rules.
+type Rules struct {
+	// NamePrefix is prepended to the name of every restored item.
+	NamePrefix string `json:"namePrefix,omitempty"`
+	// Folders renames restored folders, keyed by their backed up name.
+	// Each folder along the item's path is renamed independently.  Email
+	// folders are backed up by their ID, so they're matched by ID as well.
+	Folders map[string]string `json:"folders,omitempty"`
+	// DropAttachments leaves attachments out of restored emails and events.
+	DropAttachments bool `json:"dropAttachments,omitempty"`
+}
+
+// LoadRules reads the rules from a json file, such as:
+//
+//	{
+//	  "namePrefix": "[migrated] ",
+//	  "folders": {"Inbox": "Old Inbox"},
+//	  "dropAttachments": true
+//	}
+func LoadRules(file string) (*Rules, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, clues.Wrap(err, "opening transform rules").With("file", file)
+	}
+	defer f.Close()
+
+	var (
+		r   = &Rules{}
+		dec = json.NewDecoder(f)
+	)
+
+	// misspelled rules would otherwise be silently ignored.
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(r); err != nil {
+		return nil, clues.Wrap(err, "parsing transform rules").With("file", file)
+	}
+
+	return r, nil
+}
+
+func (r *Rules) RestoreFolders(folders []string) []string {
+	if len(r.Folders) == 0 {
+		return folders
+	}
+
+	fs := make([]string, 0, len(folders))
+
+	for _, f := range folders {
+		if rename, ok := r.Folders[f]; ok && len(rename) > 0 {
+			f = rename
+		}
+
+		fs = append(fs, f)
+	}
+
+	return fs
+}
+
+func (r *Rules) RestoreName(name string) string {
+	return r.NamePrefix + name
+}
+
+func (r *Rules) KeepAttachments() bool {
+	return !r.DropAttachments
+}
+
+// ---------------------------------------------------------------------------
+// context management
+// ---------------------------------------------------------------------------
+
+type hookKey struct{}
+
+// Set embeds the hook within the context.
+func Set(ctx context.Context, h Hook) context.Context {
+	if h == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, hookKey{}, h)
+}
+
+// Ctx retrieves the hook embedded in the context.  Returns a hook which
+// leaves items unchanged if no hook was set.
+func Ctx(ctx context.Context) Hook {
+	h, ok := ctx.Value(hookKey{}).(Hook)
+	if !ok {
+		return none{}
+	}
+
+	return h
+}
+
+// Enabled returns true if the context holds a hook.
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(hookKey{}).(Hook)
+	return ok
+}
@@ -0,0 +1,110 @@
+package transform_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/alcionai/corso/src/internal/tester"
+	"github.com/alcionai/corso/src/pkg/transform"
+)
+
+type TransformUnitSuite struct {
+	tester.Suite
+}
+
+func TestTransformUnitSuite(t *testing.T) {
+	suite.Run(t, &TransformUnitSuite{Suite: tester.NewUnitSuite(t)})
+}
+
+func (suite *TransformUnitSuite) TestLoadRules() {
+	table := []struct {
+		name      string
+		content   string
+		expect    *transform.Rules
+		expectErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "all rules",
+			content: `{"namePrefix": "[old] ", "folders": {"Inbox": "Old Inbox"}, "dropAttachments": true}`,
+			expect: &transform.Rules{
+				NamePrefix:      "[old] ",
+				Folders:         map[string]string{"Inbox": "Old Inbox"},
+				DropAttachments: true,
+			},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "no rules",
+			content:   `{}`,
+			expect:    &transform.Rules{},
+			expectErr: assert.NoError,
+		},
+		{
+			name:      "unknown rule",
+			content:   `{"namePrefx": "[old] "}`,
+			expectErr: assert.Error,
+		},
+		{
+			name:      "not json",
+			content:   `namePrefix = "[old] "`,
+			expectErr: assert.Error,
+		},
+	}
+	for _, test := range table {
+		suite.Run(test.name, func() {
+			t := suite.T()
+			file := filepath.Join(t.TempDir(), "rules.json")
+
+			require.NoError(t, os.WriteFile(file, []byte(test.content), 0o600))
+
+			r, err := transform.LoadRules(file)
+			test.expectErr(t, err)
+			assert.Equal(t, test.expect, r)
+		})
+	}
+
+	_, err := transform.LoadRules(filepath.Join(suite.T().TempDir(), "missing.json"))
+	assert.Error(suite.T(), err, "missing file")
+}
+
+func (suite *TransformUnitSuite) TestRules() {
+	t := suite.T()
+
+	r := &transform.Rules{
+		NamePrefix: "[old] ",
+		Folders:    map[string]string{"Inbox": "Old Inbox", "Drafts": ""},
+	}
+
+	folders := []string{"Inbox", "Drafts", "Inbox"}
+
+	assert.Equal(t, []string{"Old Inbox", "Drafts", "Old Inbox"}, r.RestoreFolders(folders))
+	assert.Equal(t, []string{"Inbox", "Drafts", "Inbox"}, folders, "input is unchanged")
+	assert.Equal(t, "[old] report.pdf", r.RestoreName("report.pdf"))
+	assert.True(t, r.KeepAttachments())
+
+	r.DropAttachments = true
+	assert.False(t, r.KeepAttachments())
+}
+
+func (suite *TransformUnitSuite) TestCtx() {
+	ctx, flush := tester.NewContext()
+	defer flush()
+
+	t := suite.T()
+
+	assert.False(t, transform.Enabled(ctx))
+	assert.Equal(t, "name", transform.Ctx(ctx).RestoreName("name"), "no hook leaves items unchanged")
+	assert.Equal(t, []string{"a"}, transform.Ctx(ctx).RestoreFolders([]string{"a"}))
+	assert.True(t, transform.Ctx(ctx).KeepAttachments())
+	assert.False(t, transform.Enabled(transform.Set(ctx, nil)), "nil hooks are not set")
+
+	ctx = transform.Set(ctx, &transform.Rules{NamePrefix: "x-"})
+
+	assert.True(t, transform.Enabled(ctx))
+	assert.Equal(t, "x-name", transform.Ctx(ctx).RestoreName("name"))
+}